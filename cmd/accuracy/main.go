@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultDays = 7
+
+const (
+	formatMarkdown = "markdown"
+	formatCSV      = "csv"
+)
+
+var (
+	loadEnvFunc = godotenv.Load
+	openPool    = pgxpool.New
+	nowFunc     = time.Now
+)
+
+type options struct {
+	from    time.Time
+	to      time.Time
+	format  string
+	model   string
+	symbol  string
+	outPath string
+}
+
+func main() {
+	loadEnvFunc()
+
+	opts, err := parseOptions(os.Args[1:], nowFunc().UTC())
+	if err != nil {
+		log.Fatalf("parse options: %v", err)
+	}
+
+	dsn := strings.TrimSpace(os.Getenv("DATABASE_URL"))
+	if dsn == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	pool, err := openPool(ctx, dsn)
+	if err != nil {
+		log.Fatalf("connect postgres: %v", err)
+	}
+	defer pool.Close()
+
+	tracer := trace.NewNoopTracerProvider().Tracer("accuracy")
+	rows, err := repository.NewBacktestRepository(pool, tracer).GetAccuracyBreakdown(ctx, opts.from, opts.to)
+	if err != nil {
+		log.Fatalf("load accuracy: %v", err)
+	}
+	rows = filterRows(rows, opts.model, opts.symbol)
+
+	out := io.Writer(os.Stdout)
+	if opts.outPath != "" {
+		f, err := os.Create(opts.outPath)
+		if err != nil {
+			log.Fatalf("create %s: %v", opts.outPath, err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	switch opts.format {
+	case formatCSV:
+		err = writeCSV(out, rows)
+	default:
+		err = writeMarkdown(out, opts, rows)
+	}
+	if err != nil {
+		log.Fatalf("write report: %v", err)
+	}
+}
+
+func parseOptions(args []string, now time.Time) (options, error) {
+	fs := flag.NewFlagSet("accuracy", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	fromRaw := fs.String("from", "", "first candle open time to include (YYYY-MM-DD or RFC3339, default -days before -to)")
+	toRaw := fs.String("to", "", "end of the range, exclusive (YYYY-MM-DD or RFC3339, default now)")
+	days := fs.Int("days", defaultDays, "days to report when -from is not set")
+	format := fs.String("format", formatMarkdown, "output format: markdown or csv")
+	model := fs.String("model", "", "only report this model key")
+	symbol := fs.String("symbol", "", "only report this symbol")
+	outPath := fs.String("out", "", "write the report to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	opts := options{
+		to:      now.UTC(),
+		format:  strings.ToLower(strings.TrimSpace(*format)),
+		model:   strings.TrimSpace(*model),
+		symbol:  strings.ToUpper(strings.TrimSpace(*symbol)),
+		outPath: strings.TrimSpace(*outPath),
+	}
+	if opts.format != formatMarkdown && opts.format != formatCSV {
+		return options{}, fmt.Errorf("unsupported format: %s", *format)
+	}
+	if raw := strings.TrimSpace(*toRaw); raw != "" {
+		to, err := parseTime(raw)
+		if err != nil {
+			return options{}, fmt.Errorf("to: %w", err)
+		}
+		opts.to = to
+	}
+	if raw := strings.TrimSpace(*fromRaw); raw != "" {
+		from, err := parseTime(raw)
+		if err != nil {
+			return options{}, fmt.Errorf("from: %w", err)
+		}
+		opts.from = from
+	} else {
+		if *days <= 0 {
+			return options{}, fmt.Errorf("days must be > 0")
+		}
+		opts.from = opts.to.AddDate(0, 0, -*days)
+	}
+	if !opts.from.Before(opts.to) {
+		return options{}, fmt.Errorf("from must be before to")
+	}
+	return opts, nil
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", raw)
+}
+
+func filterRows(rows []domain.AccuracyBreakdown, model, symbol string) []domain.AccuracyBreakdown {
+	if model == "" && symbol == "" {
+		return rows
+	}
+	kept := rows[:0:0]
+	for _, r := range rows {
+		if (model == "" || r.ModelKey == model) && (symbol == "" || r.Symbol == symbol) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// modelTotals sums each model's rows, in the order the models first appear.
+// AvgReturn is weighted by each row's directional calls.
+func modelTotals(rows []domain.AccuracyBreakdown) []domain.AccuracyBreakdown {
+	var out []domain.AccuracyBreakdown
+	index := map[string]int{}
+	for _, r := range rows {
+		i, ok := index[r.ModelKey]
+		if !ok {
+			i = len(out)
+			index[r.ModelKey] = i
+			out = append(out, domain.AccuracyBreakdown{ModelKey: r.ModelKey})
+		}
+		t := &out[i]
+		t.Total += r.Total
+		t.Correct += r.Correct
+		t.AvgReturn += r.AvgReturn * float64(r.Directional)
+		t.Directional += r.Directional
+	}
+	for i := range out {
+		if out[i].Total > 0 {
+			out[i].HitRate = float64(out[i].Correct) / float64(out[i].Total)
+		}
+		if out[i].Directional > 0 {
+			out[i].AvgReturn /= float64(out[i].Directional)
+		}
+	}
+	return out
+}
+
+func writeMarkdown(w io.Writer, opts options, rows []domain.AccuracyBreakdown) error {
+	const stamp = "2006-01-02 15:04"
+	fmt.Fprintln(w, "# Model accuracy report")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Resolved predictions for candles opened from %s to %s UTC", opts.from.Format(stamp), opts.to.Format(stamp))
+	var filters []string
+	if opts.model != "" {
+		filters = append(filters, "model "+opts.model)
+	}
+	if opts.symbol != "" {
+		filters = append(filters, "symbol "+opts.symbol)
+	}
+	if len(filters) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(filters, ", "))
+	}
+	fmt.Fprintln(w, ".")
+	fmt.Fprintln(w)
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No resolved predictions in this range.")
+		return err
+	}
+
+	fmt.Fprintln(w, "## By model")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Model | Predictions | Correct | Hit rate | Directional | Avg return |")
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---:|")
+	for _, t := range modelTotals(rows) {
+		fmt.Fprintf(w, "| %s | %d | %d | %s | %d | %s |\n",
+			t.ModelKey, t.Total, t.Correct, percent(t.HitRate), t.Directional, signedPercent(t.AvgReturn, t.Directional))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## By model, symbol, risk and horizon")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Model | Symbol | Risk | Horizon | Predictions | Correct | Hit rate | Directional | Avg return |")
+	fmt.Fprintln(w, "|---|---|---:|---:|---:|---:|---:|---:|---:|")
+	for _, r := range rows {
+		fmt.Fprintf(w, "| %s | %s | %d | %dh | %d | %d | %s | %d | %s |\n",
+			r.ModelKey, r.Symbol, r.Risk, r.HorizonHours, r.Total, r.Correct, percent(r.HitRate), r.Directional, signedPercent(r.AvgReturn, r.Directional))
+	}
+	fmt.Fprintln(w)
+	_, err := fmt.Fprintln(w, "Hit rate counts every resolved prediction, holds included. Avg return is the mean return of the long and short calls, signed by the predicted direction.")
+	return err
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v*100)
+}
+
+func signedPercent(v float64, n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.2f%%", v*100)
+}
+
+func writeCSV(w io.Writer, rows []domain.AccuracyBreakdown) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"model_key", "symbol", "risk", "horizon_hours", "total", "correct", "hit_rate", "directional", "avg_return"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := cw.Write([]string{
+			r.ModelKey,
+			r.Symbol,
+			strconv.Itoa(int(r.Risk)),
+			strconv.Itoa(r.HorizonHours),
+			strconv.FormatInt(r.Total, 10),
+			strconv.FormatInt(r.Correct, 10),
+			strconv.FormatFloat(r.HitRate, 'f', 4, 64),
+			strconv.FormatInt(r.Directional, 10),
+			strconv.FormatFloat(r.AvgReturn, 'f', 6, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestParseOptions(t *testing.T) {
+	now := time.Date(2026, 3, 9, 8, 30, 0, 0, time.UTC)
+
+	opts, err := parseOptions(nil, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.to.Equal(now) || !opts.from.Equal(now.AddDate(0, 0, -defaultDays)) || opts.format != formatMarkdown {
+		t.Fatalf("unexpected defaults %+v", opts)
+	}
+
+	opts, err = parseOptions([]string{"-from", "2026-03-02", "-to", "2026-03-09", "-format", "CSV", "-symbol", "btc"}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !opts.from.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) || !opts.to.Equal(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected range %v - %v", opts.from, opts.to)
+	}
+	if opts.format != formatCSV || opts.symbol != "BTC" {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	for _, args := range [][]string{
+		{"-format", "pdf"},
+		{"-days", "0"},
+		{"-from", "2026-03-09", "-to", "2026-03-02"},
+		{"-from", "last week"},
+	} {
+		if _, err := parseOptions(args, now); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func sampleRows() []domain.AccuracyBreakdown {
+	return []domain.AccuracyBreakdown{
+		{ModelKey: "ensemble_v1", Symbol: "BTC", Risk: 2, HorizonHours: 4, Total: 30, Correct: 18, Directional: 10, HitRate: 0.6, AvgReturn: 0.004},
+		{ModelKey: "ensemble_v1", Symbol: "ETH", Risk: 3, HorizonHours: 4, Total: 10, Correct: 8, Directional: 30, HitRate: 0.8, AvgReturn: 0.008},
+		{ModelKey: "logreg", Symbol: "BTC", Risk: 4, HorizonHours: 4, Total: 5, Correct: 1, HitRate: 0.2},
+	}
+}
+
+func TestModelTotalsWeightsReturnsByDirectionalCalls(t *testing.T) {
+	totals := modelTotals(sampleRows())
+	if len(totals) != 2 || totals[0].ModelKey != "ensemble_v1" || totals[1].ModelKey != "logreg" {
+		t.Fatalf("unexpected totals %+v", totals)
+	}
+	ens := totals[0]
+	if ens.Total != 40 || ens.Correct != 26 || ens.HitRate != 0.65 || ens.Directional != 40 {
+		t.Fatalf("unexpected ensemble totals %+v", ens)
+	}
+	if want := 0.007; ens.AvgReturn < want-1e-12 || ens.AvgReturn > want+1e-12 {
+		t.Fatalf("expected avg return %.4f, got %.6f", want, ens.AvgReturn)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	opts := options{from: from, to: from.AddDate(0, 0, 7), symbol: "BTC"}
+	var buf bytes.Buffer
+	if err := writeMarkdown(&buf, opts, filterRows(sampleRows(), "", "BTC")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"from 2026-03-02 00:00 to 2026-03-09 00:00 UTC (symbol BTC).",
+		"| ensemble_v1 | 30 | 18 | 60.0% | 10 | +0.40% |",
+		"| logreg | BTC | 4 | 4h | 5 | 1 | 20.0% | 0 | - |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "ETH") {
+		t.Fatalf("expected ETH rows filtered out:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := writeMarkdown(&buf, opts, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No resolved predictions") {
+		t.Fatalf("expected an empty report note, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, sampleRows()[:1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "model_key,symbol,risk,horizon_hours,total,correct,hit_rate,directional,avg_return\n" +
+		"ensemble_v1,BTC,2,4,30,18,0.6000,10,0.004000\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	formatAuto = "auto"
+	formatCSV  = "csv"
+	formatJSON = "json"
+
+	defaultBatchSize = 1000
+	// maxProblems caps the invalid rows listed before the import gives up.
+	maxProblems = 20
+)
+
+var (
+	loadEnvFunc = godotenv.Load
+	openPool    = pgxpool.New
+	nowFunc     = time.Now
+)
+
+type options struct {
+	files       []string
+	format      string
+	symbol      string
+	interval    string
+	batchSize   int
+	skipInvalid bool
+	dryRun      bool
+}
+
+// candleStore is the part of CandleRepository the import writes through.
+type candleStore interface {
+	IngestCandles(ctx context.Context, candles []*domain.Candle) (int, error)
+}
+
+// importStats counts what happened to the rows read.
+type importStats struct {
+	Read       int
+	Invalid    int
+	Duplicates int
+	Stored     int
+	Unchanged  int
+}
+
+func main() {
+	loadEnvFunc()
+
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		log.Fatalf("parse options: %v", err)
+	}
+
+	var candles []domain.Candle
+	for _, path := range opts.files {
+		read, err := readFile(path, opts)
+		if err != nil {
+			log.Fatalf("read %s: %v", path, err)
+		}
+		log.Printf("read %d candles from %s", len(read), path)
+		candles = append(candles, read...)
+	}
+
+	unique, stats, problems := prepare(candles, nowFunc().UTC())
+	if len(problems) > 0 {
+		for _, p := range problems {
+			log.Printf("invalid: %s", p)
+		}
+		if !opts.skipInvalid {
+			log.Fatalf("%d invalid candles, nothing imported (use -skip-invalid to import the rest)", stats.Invalid)
+		}
+	}
+	if opts.dryRun {
+		log.Printf("dry run: %d read, %d valid, %d invalid, %d duplicates", stats.Read, len(unique), stats.Invalid, stats.Duplicates)
+		return
+	}
+
+	dsn := strings.TrimSpace(os.Getenv("DATABASE_URL"))
+	if dsn == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	pool, err := openPool(ctx, dsn)
+	if err != nil {
+		log.Fatalf("connect postgres: %v", err)
+	}
+	defer pool.Close()
+
+	tracer := trace.NewNoopTracerProvider().Tracer("candleimport")
+	repo := repository.NewCandleRepository(pool, tracer)
+	if err := store(ctx, repo, unique, opts.batchSize, &stats); err != nil {
+		log.Fatalf("import: %v", err)
+	}
+	log.Printf("import complete: %d read, %d stored, %d unchanged, %d duplicates, %d invalid",
+		stats.Read, stats.Stored, stats.Unchanged, stats.Duplicates, stats.Invalid)
+}
+
+func parseOptions(args []string) (options, error) {
+	fs := flag.NewFlagSet("candleimport", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: candleimport [flags] FILE... (- reads stdin)")
+		fs.PrintDefaults()
+	}
+
+	format := fs.String("format", formatAuto, "input format: csv, json, or auto to pick by file extension")
+	symbol := fs.String("symbol", "", "symbol for rows without a symbol column")
+	interval := fs.String("interval", "", "interval for rows without an interval column: "+strings.Join(domain.SupportedIntervals, ", "))
+	batchSize := fs.Int("batch", defaultBatchSize, "candles written per database batch")
+	skipInvalid := fs.Bool("skip-invalid", false, "import the valid rows when some are invalid, instead of nothing")
+	dryRun := fs.Bool("dry-run", false, "validate the files without writing to the database")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	opts := options{
+		files:       fs.Args(),
+		format:      strings.ToLower(strings.TrimSpace(*format)),
+		symbol:      strings.ToUpper(strings.TrimSpace(*symbol)),
+		interval:    strings.TrimSpace(*interval),
+		batchSize:   *batchSize,
+		skipInvalid: *skipInvalid,
+		dryRun:      *dryRun,
+	}
+	if len(opts.files) == 0 {
+		return options{}, errors.New("at least one file is required")
+	}
+	switch opts.format {
+	case formatAuto, formatCSV, formatJSON:
+	default:
+		return options{}, fmt.Errorf("unsupported format: %s", *format)
+	}
+	if opts.interval != "" && domain.IntervalDuration(opts.interval) == 0 {
+		return options{}, fmt.Errorf("unsupported interval: %s", opts.interval)
+	}
+	if opts.batchSize <= 0 {
+		return options{}, errors.New("batch must be > 0")
+	}
+	return opts, nil
+}
+
+func readFile(path string, opts options) ([]domain.Candle, error) {
+	format := opts.format
+	if format == formatAuto {
+		format = formatCSV
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			format = formatJSON
+		}
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var (
+		candles []domain.Candle
+		err     error
+	)
+	if format == formatJSON {
+		candles, err = readJSON(in)
+	} else {
+		candles, err = readCSV(in)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range candles {
+		if candles[i].Symbol == "" {
+			candles[i].Symbol = opts.symbol
+		}
+		if candles[i].Interval == "" {
+			candles[i].Interval = opts.interval
+		}
+	}
+	return candles, nil
+}
+
+// csvColumns maps the header names found in exchange and charting exports
+// onto candle fields.
+var csvColumns = map[string]string{
+	"symbol":     "symbol",
+	"ticker":     "symbol",
+	"pair":       "symbol",
+	"interval":   "interval",
+	"timeframe":  "interval",
+	"open_time":  "open_time",
+	"open time":  "open_time",
+	"time":       "open_time",
+	"timestamp":  "open_time",
+	"date":       "open_time",
+	"datetime":   "open_time",
+	"open":       "open",
+	"o":          "open",
+	"high":       "high",
+	"h":          "high",
+	"low":        "low",
+	"l":          "low",
+	"close":      "close",
+	"c":          "close",
+	"volume":     "volume",
+	"vol":        "volume",
+	"v":          "volume",
+	"volume_usd": "volume",
+}
+
+// readCSV reads candles from a CSV file with a header row. Columns are
+// matched by name, case-insensitively; unknown columns are ignored. A
+// missing volume column reads as 0.
+func readCSV(r io.Reader) ([]domain.Candle, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := csvColumns[name]; ok {
+			if _, seen := index[field]; !seen {
+				index[field] = i
+			}
+		}
+	}
+	for _, field := range []string{"open_time", "open", "high", "low", "close"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("header has no %s column", field)
+		}
+	}
+
+	var candles []domain.Candle
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		c := domain.Candle{Symbol: field("symbol"), Interval: field("interval")}
+		if c.OpenTime, err = parseTime(field("open_time")); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		for _, f := range []struct {
+			name string
+			dst  *float64
+		}{{"open", &c.Open}, {"high", &c.High}, {"low", &c.Low}, {"close", &c.Close}, {"volume", &c.Volume}} {
+			raw := field(f.name)
+			if raw == "" && f.name == "volume" {
+				continue
+			}
+			if *f.dst, err = strconv.ParseFloat(raw, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s %q", line, f.name, raw)
+			}
+		}
+		candles = append(candles, c)
+	}
+	return candles, nil
+}
+
+// jsonCandle is one candle in a JSON export. open_time may be a string or a
+// Unix timestamp in seconds or milliseconds.
+type jsonCandle struct {
+	Symbol   string          `json:"symbol"`
+	Interval string          `json:"interval"`
+	OpenTime json.RawMessage `json:"open_time"`
+	Open     float64         `json:"open"`
+	High     float64         `json:"high"`
+	Low      float64         `json:"low"`
+	Close    float64         `json:"close"`
+	Volume   float64         `json:"volume"`
+}
+
+// readJSON reads an array of candles, or an object with a candles array as
+// POST /api/candles/ingest takes.
+func readJSON(r io.Reader) ([]domain.Candle, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rows []jsonCandle
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		var wrapped struct {
+			Candles []jsonCandle `json:"candles"`
+		}
+		if err := json.Unmarshal(raw, &wrapped); err != nil {
+			return nil, fmt.Errorf("decode json: want an array of candles or {\"candles\": [...]}: %w", err)
+		}
+		rows = wrapped.Candles
+	}
+
+	candles := make([]domain.Candle, 0, len(rows))
+	for i, row := range rows {
+		openTime := strings.Trim(strings.TrimSpace(string(row.OpenTime)), `"`)
+		t, err := parseTime(openTime)
+		if err != nil {
+			return nil, fmt.Errorf("candle %d: %w", i, err)
+		}
+		candles = append(candles, domain.Candle{
+			Symbol:   row.Symbol,
+			Interval: row.Interval,
+			OpenTime: t,
+			Open:     row.Open,
+			High:     row.High,
+			Low:      row.Low,
+			Close:    row.Close,
+			Volume:   row.Volume,
+		})
+	}
+	return candles, nil
+}
+
+// parseTime reads an open time as RFC 3339, "2006-01-02 15:04:05" or a date
+// in UTC, or Unix seconds or milliseconds.
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("missing open_time")
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		if n > 1e11 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid open_time %q", raw)
+}
+
+// prepare validates candles and drops repeats. A repeat with the same
+// values is a duplicate; one with different values is invalid and the
+// first is kept. Problems lists at most maxProblems entries.
+func prepare(candles []domain.Candle, now time.Time) ([]*domain.Candle, importStats, []string) {
+	stats := importStats{Read: len(candles)}
+	var problems []string
+	report := func(format string, args ...any) {
+		stats.Invalid++
+		if len(problems) < maxProblems {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		} else if len(problems) == maxProblems {
+			problems = append(problems, "further problems omitted")
+		}
+	}
+
+	byKey := make(map[string]*domain.Candle, len(candles))
+	unique := make([]*domain.Candle, 0, len(candles))
+	for i := range candles {
+		c := candles[i]
+		c.Symbol = strings.ToUpper(strings.TrimSpace(c.Symbol))
+		c.Interval = strings.TrimSpace(c.Interval)
+		c.OpenTime = c.OpenTime.UTC()
+		if err := domain.ValidateCandle(c, now); err != nil {
+			report("candle %d: %v", i, err)
+			continue
+		}
+		key := c.Symbol + "|" + c.Interval + "|" + c.OpenTime.Format(time.RFC3339)
+		if prev, ok := byKey[key]; ok {
+			if *prev != c {
+				report("candle %d: conflicts with an earlier candle for %s %s at %s", i, c.Symbol, c.Interval, c.OpenTime.Format(time.RFC3339))
+			} else {
+				stats.Duplicates++
+			}
+			continue
+		}
+		byKey[key] = &c
+		unique = append(unique, &c)
+	}
+	return unique, stats, problems
+}
+
+// store upserts candles in batches. Candles already stored with the same
+// values are counted as unchanged.
+func store(ctx context.Context, repo candleStore, candles []*domain.Candle, batchSize int, stats *importStats) error {
+	for start := 0; start < len(candles); start += batchSize {
+		batch := candles[start:min(start+batchSize, len(candles))]
+		stored, err := repo.IngestCandles(ctx, batch)
+		stats.Stored += stored
+		if err != nil {
+			return fmt.Errorf("batch at candle %d: %w", start, err)
+		}
+		stats.Unchanged += len(batch) - stored
+	}
+	return nil
+}
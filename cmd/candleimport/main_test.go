@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestParseOptions(t *testing.T) {
+	opts, err := parseOptions([]string{"-symbol", " pepe ", "-interval", "1h", "-batch", "50", "prices.csv", "more.json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.symbol != "PEPE" || opts.interval != "1h" || opts.batchSize != 50 || opts.format != formatAuto || len(opts.files) != 2 {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	for _, args := range [][]string{
+		nil,
+		{"-format", "xlsx", "a.csv"},
+		{"-interval", "2h", "a.csv"},
+		{"-batch", "0", "a.csv"},
+	} {
+		if _, err := parseOptions(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestReadCSVMatchesExportHeaders(t *testing.T) {
+	in := "\ufeffTimestamp,Open,High,Low,Close,Volume,Trades\n" +
+		"# exported 2026-03-02\n" +
+		"1772323200000,100,110,95,105,12.5,40\n" +
+		"2026-03-01 01:00:00,105,108,101,102,,12\n"
+	candles, err := readCSV(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 2 {
+		t.Fatalf("expected 2 candles, got %+v", candles)
+	}
+	first := candles[0]
+	if !first.OpenTime.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || first.Open != 100 || first.High != 110 ||
+		first.Low != 95 || first.Close != 105 || first.Volume != 12.5 {
+		t.Fatalf("unexpected first candle %+v", first)
+	}
+	if !candles[1].OpenTime.Equal(time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC)) || candles[1].Volume != 0 {
+		t.Fatalf("unexpected second candle %+v", candles[1])
+	}
+
+	if _, err := readCSV(strings.NewReader("time,open,high,close\n")); err == nil || !strings.Contains(err.Error(), "low") {
+		t.Fatalf("expected a missing low column error, got %v", err)
+	}
+	_, err = readCSV(strings.NewReader("symbol,interval,open_time,open,high,low,close\nPEPE,1h,2026-03-01T00:00:00Z,1,2,x,1.5\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2: invalid low") {
+		t.Fatalf("expected the bad row reported by line, got %v", err)
+	}
+}
+
+func TestReadJSONAcceptsArraysAndIngestBodies(t *testing.T) {
+	array := `[{"symbol":"PEPE","interval":"1h","open_time":"2026-03-01T00:00:00Z","open":1,"high":2,"low":0.5,"close":1.5,"volume":10},
+		{"symbol":"PEPE","interval":"1h","open_time":1772326800,"open":1.5,"high":2,"low":1,"close":1.8}]`
+	candles, err := readJSON(strings.NewReader(array))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candles) != 2 || !candles[1].OpenTime.Equal(time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC)) || candles[0].Volume != 10 {
+		t.Fatalf("unexpected candles %+v", candles)
+	}
+
+	body := `{"candles":[{"symbol":"WIF","interval":"4h","open_time":"2026-03-01T04:00:00Z","open":2,"high":3,"low":1,"close":2.5}]}`
+	candles, err = readJSON(strings.NewReader(body))
+	if err != nil || len(candles) != 1 || candles[0].Symbol != "WIF" {
+		t.Fatalf("unexpected ingest body result %+v err=%v", candles, err)
+	}
+
+	if _, err := readJSON(strings.NewReader(`"nope"`)); err == nil {
+		t.Fatal("expected an error for a non-candle document")
+	}
+}
+
+func importCandle(symbol string, openTime time.Time, close float64) domain.Candle {
+	return domain.Candle{Symbol: symbol, Interval: "1h", OpenTime: openTime, Open: 1, High: 2, Low: 0.5, Close: close, Volume: 3}
+}
+
+func TestPrepareValidatesAndDedupes(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	hour := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	candles := []domain.Candle{
+		importCandle("btc", hour, 1.5),
+		importCandle("BTC", hour, 1.5),
+		importCandle("BTC", hour.Add(time.Hour), 1.6),
+		importCandle("BTC", hour.Add(time.Hour), 1.7),
+		importCandle("BTC", hour.Add(time.Minute), 1.5),
+		importCandle("BTC", now.Add(time.Hour), 1.5),
+	}
+	unique, stats, problems := prepare(candles, now)
+	if len(unique) != 2 || unique[0].Symbol != "BTC" {
+		t.Fatalf("expected two valid candles, got %+v", unique)
+	}
+	if stats.Read != 6 || stats.Duplicates != 1 || stats.Invalid != 3 || len(problems) != 3 {
+		t.Fatalf("unexpected stats %+v problems %v", stats, problems)
+	}
+	if !strings.Contains(problems[0], "candle 3: conflicts") || !strings.Contains(problems[1], "not aligned") || !strings.Contains(problems[2], "in the future") {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
+
+type candleStoreStub struct {
+	batches []int
+	fail    int
+}
+
+func (s *candleStoreStub) IngestCandles(_ context.Context, candles []*domain.Candle) (int, error) {
+	s.batches = append(s.batches, len(candles))
+	if len(s.batches) == s.fail {
+		return 0, errors.New("connection reset")
+	}
+	return len(candles) - 1, nil
+}
+
+func TestStoreWritesInBatches(t *testing.T) {
+	hour := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var candles []*domain.Candle
+	for i := 0; i < 5; i++ {
+		c := importCandle("PEPE", hour.Add(time.Duration(i)*time.Hour), 1.5)
+		candles = append(candles, &c)
+	}
+
+	repo := &candleStoreStub{}
+	var stats importStats
+	if err := store(context.Background(), repo, candles, 2, &stats); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.batches) != 3 || repo.batches[2] != 1 || stats.Stored != 2 || stats.Unchanged != 3 {
+		t.Fatalf("unexpected batches %v stats %+v", repo.batches, stats)
+	}
+
+	repo = &candleStoreStub{fail: 2}
+	if err := store(context.Background(), repo, candles, 2, &importStats{}); err == nil || !strings.Contains(err.Error(), "batch at candle 2") {
+		t.Fatalf("expected the failing batch reported, got %v", err)
+	}
+}
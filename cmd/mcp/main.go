@@ -16,12 +16,23 @@ import (
 	"bug-free-umbrella/internal/chart"
 	"bug-free-umbrella/internal/config"
 	"bug-free-umbrella/internal/db"
+	"bug-free-umbrella/internal/httpclient"
 	"bug-free-umbrella/internal/job"
+	"bug-free-umbrella/internal/marketintel"
 	mcpserver "bug-free-umbrella/internal/mcp"
+	"bug-free-umbrella/internal/ml/ensemble"
+	"bug-free-umbrella/internal/ml/features"
+	"bug-free-umbrella/internal/ml/inference"
+	"bug-free-umbrella/internal/ml/predictions"
+	"bug-free-umbrella/internal/ml/registry"
+	"bug-free-umbrella/internal/ml/similarity"
 	"bug-free-umbrella/internal/provider"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/secrets"
 	"bug-free-umbrella/internal/service"
 	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/internal/startup"
+	"bug-free-umbrella/pkg/domain"
 	"bug-free-umbrella/pkg/tracing"
 
 	"github.com/joho/godotenv"
@@ -33,10 +44,13 @@ const defaultMCPHTTPMaxBodyBytes int64 = 1 << 20 // 1MiB
 
 var (
 	loadEnvFunc              = godotenv.Load
+	loadSecretsFunc          = secrets.Bootstrap
 	loadConfigFunc           = config.Load
+	configureHTTPFunc        = httpclient.Configure
 	initPostgresFunc         = db.InitPostgres
 	initRedisFunc            = cache.InitRedis
 	initTracerFunc           = tracing.InitTracer
+	initMeterFunc            = tracing.InitMeter
 	newCandleRepoFunc        = repository.NewCandleRepository
 	newSignalRepoFunc        = repository.NewSignalRepository
 	newSignalImageRepoFunc   = repository.NewSignalImageRepository
@@ -48,8 +62,25 @@ var (
 	newChartRendererFunc     = chart.NewRenderer
 	newSignalImageJobFunc    = job.NewSignalImageMaintenance
 	startSignalImageJobFunc  = func(j *job.SignalImageMaintenance, ctx context.Context) { go j.Start(ctx) }
-	newCoinGeckoProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
-		return provider.NewCoinGeckoProvider(tracer)
+	newCoinGeckoProviderFunc = func(tracer trace.Tracer, apiKey string) service.PriceProvider {
+		coingecko := provider.NewCoinGeckoProvider(tracer)
+		coingecko.SetAPIKey(apiKey)
+		return coingecko
+	}
+	newBinanceProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
+		return provider.NewBinanceProvider(tracer)
+	}
+	newKrakenProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
+		return provider.NewKrakenProvider(tracer)
+	}
+	newCoinbaseProviderFunc = func(tracer trace.Tracer, keyName, privateKey string) service.PriceProvider {
+		coinbase := provider.NewCoinbaseProvider(tracer)
+		if keyName != "" {
+			if err := coinbase.SetCredentials(keyName, privateKey); err != nil {
+				log.Fatalf("invalid Coinbase API key: %v", err)
+			}
+		}
+		return coinbase
 	}
 	runStdioFunc = func(ctx context.Context, server *sdkmcp.Server) error {
 		return server.Run(ctx, &sdkmcp.StdioTransport{})
@@ -62,15 +93,33 @@ var (
 
 func main() {
 	loadEnvFunc()
-	cfg := loadConfigFunc()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Pull managed secrets into the environment before reading config
+	secretsWatcher, err := loadSecretsFunc(ctx)
+	if err != nil {
+		log.Fatalf("failed to load secrets: %v", err)
+	}
+
+	cfg := loadConfigFunc()
+	if err := configureHTTPFunc(cfg.OutboundHTTP); err != nil {
+		log.Fatalf("invalid outbound HTTP settings: %v", err)
+	}
+
 	os.Setenv("DATABASE_URL", cfg.DatabaseURL)
 	os.Setenv("REDIS_URL", cfg.RedisURL)
-	initPostgresFunc(ctx)
-	initRedisFunc(ctx)
+	initCtx, cancelInit := context.WithTimeout(ctx, time.Duration(cfg.StartupConnectTimeoutSecs)*time.Second)
+	initPostgresFunc(initCtx)
+	initRedisFunc(initCtx)
+	cancelInit()
+	secretsWatcher.OnChange("DATABASE_URL", func(dsn string) {
+		if err := db.SetDSN(dsn); err != nil {
+			log.Printf("ignoring rotated DATABASE_URL: %v", err)
+		}
+	})
+	go secretsWatcher.Start(ctx)
 
 	tp, tracer, err := initTracerFunc(ctx)
 	if err != nil {
@@ -81,20 +130,85 @@ func main() {
 			log.Printf("error shutting down tracer provider: %v", err)
 		}
 	}()
+	mp, err := initMeterFunc(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize meter: %v", err)
+	}
+	defer func() {
+		if err := mp.Shutdown(ctx); err != nil {
+			log.Printf("error shutting down meter provider: %v", err)
+		}
+	}()
 
 	candleRepo := newCandleRepoFunc(db.Pool, tracer)
 	signalRepo := newSignalRepoFunc(db.Pool, tracer)
 	signalImageRepo := newSignalImageRepoFunc(db.Pool, tracer)
-	cgProvider := newCoinGeckoProviderFunc(tracer)
-	priceService := newPriceServiceFunc(tracer, cgProvider, candleRepo, cache.Client)
+	var marketProvider service.PriceProvider
+	switch cfg.PriceProvider {
+	case "binance":
+		marketProvider = newBinanceProviderFunc(tracer)
+	case "kraken":
+		marketProvider = newKrakenProviderFunc(tracer)
+	case "coinbase":
+		marketProvider = newCoinbaseProviderFunc(tracer, cfg.CoinbaseAPIKeyName, cfg.CoinbaseAPIPrivateKey)
+	default:
+		marketProvider = newCoinGeckoProviderFunc(tracer, cfg.CoinGeckoAPIKey)
+	}
+	priceService := newPriceServiceFunc(tracer, marketProvider, candleRepo, cache.Client)
+	domain.SetConfiguredPauses(cfg.SymbolPauses)
+	if db.Pool != nil {
+		symbolRegistry := service.NewSymbolRegistryService(tracer, repository.NewAssetRepository(db.Pool, tracer), nil)
+		if err := symbolRegistry.Reload(ctx); err != nil {
+			log.Printf("Warning: stored symbol registry unavailable, tracking built-in symbols: %v", err)
+		}
+		go job.NewSymbolRegistryRefresh(symbolRegistry, job.DefaultSymbolRegistryRefresh).Start(ctx)
+	}
 	signalEngine := newSignalEngineFunc(nil)
+	riskOverrides := cfg.SignalRiskOverrides
+	if db.Pool != nil {
+		if riskOverrides, err = signalengine.LoadRiskOverrides(ctx, riskOverrides, repository.NewRiskOverrideRepository(db.Pool, tracer)); err != nil {
+			log.Printf("Warning: stored signal risk overrides unavailable, using SIGNAL_RISK_OVERRIDES only: %v", err)
+		}
+	}
+	signalEngine.SetRiskOverrides(riskOverrides)
+	signalEngine.SetIchimokuPeriods(cfg.IchimokuPeriods)
 	chartRenderer := newChartRendererFunc()
+	chartRenderer.SetFormat(chart.ImageFormat(cfg.ChartImageFormat))
+	chartRenderer.SetIchimokuPeriods(cfg.IchimokuPeriods)
+	if cfg.MarketEventsBuiltin || cfg.MarketEventsFile != "" {
+		marketEvents := marketintel.NewCalendar(cfg.MarketEventsFile, cfg.MarketEventsBuiltin)
+		if err := marketEvents.Load(); err != nil {
+			log.Printf("Warning: market event calendar: %v", err)
+		}
+		chartRenderer.SetEventSource(marketEvents)
+	}
 	signalService := newSignalServiceFunc(tracer, candleRepo, signalRepo, signalEngine, signalImageRepo, chartRenderer)
+	signalService.SetImageOptions(service.SignalImageOptions{
+		TTL:        time.Duration(cfg.SignalImageTTLHours) * time.Hour,
+		RetryDelay: time.Duration(cfg.SignalImageRetryDelaySecs) * time.Second,
+		MaxRetries: cfg.SignalImageMaxRetries,
+	})
 	imageJob := newSignalImageJobFunc(tracer, signalService)
-	startSignalImageJobFunc(imageJob, ctx)
+	imageJob.SetBatchSizes(cfg.SignalImageRetryBatch, cfg.SignalImageCleanupBatch)
+	imageJob.SetRetryInterval(time.Duration(cfg.SignalImageRetryDelaySecs) * time.Second)
+	// The image job starts once Postgres and Redis answer.
+	var jobDeps []startup.Dependency
+	if db.Pool != nil {
+		jobDeps = append(jobDeps, startup.Dependency{Name: "postgres", Check: db.Ping})
+	}
+	if cache.Client != nil {
+		jobDeps = append(jobDeps, startup.Dependency{Name: "redis", Check: cache.Ping})
+	}
+	jobGate := startup.NewGate(jobDeps...)
+	jobGate.Start(ctx)
+	jobGate.Go(ctx, "signal image maintenance", func() { startSignalImageJobFunc(imageJob, ctx) })
 
 	mcpSrv := newMCPServerFunc(tracer, priceService, signalService, mcpserver.ServerConfig{
 		RequestTimeout: time.Duration(cfg.MCPRequestTimeoutSecs) * time.Second,
+		AdminToken:     cfg.MCPAdminToken,
+		ML:             newMLRunner(tracer, cfg, candleRepo, mlSignalStore(signalRepo, signalService)),
+		Dependencies:   dependencyProbes(),
+		Risk:           signalEngine,
 	})
 
 	transport := strings.ToLower(strings.TrimSpace(cfg.MCPTransport))
@@ -112,6 +226,110 @@ func main() {
 	}
 }
 
+// dependencyProbes reports Postgres/Redis reachability so MCP clients can see
+// which tools are usable and get data_unavailable errors instead of timeouts.
+func dependencyProbes() map[string]mcpserver.DependencyProbe {
+	return map[string]mcpserver.DependencyProbe{
+		mcpserver.DependencyPostgres: func(ctx context.Context) error {
+			if db.Pool == nil {
+				return fmt.Errorf("postgres not configured")
+			}
+			return db.Pool.Ping(ctx)
+		},
+		mcpserver.DependencyRedis: func(ctx context.Context) error {
+			if cache.Client == nil {
+				return fmt.Errorf("redis not configured")
+			}
+			return cache.Client.Ping(ctx).Err()
+		},
+	}
+}
+
+// mlSignalStore prefers the signal service, which renders chart images for
+// ML signals, over writing to the repository directly.
+func mlSignalStore(repo *repository.SignalRepository, svc *service.SignalService) inference.SignalStore {
+	if svc != nil {
+		return svc
+	}
+	return repo
+}
+
+// newMLRunner builds the ML service backing the ML tools, or nil when ML is
+// disabled or Postgres is unavailable.
+func newMLRunner(
+	tracer trace.Tracer,
+	cfg *config.Config,
+	candleRepo *repository.CandleRepository,
+	signals inference.SignalStore,
+) mcpserver.MLInferenceRunner {
+	if !cfg.MLEnabled || db.Pool == nil {
+		return nil
+	}
+	featureRepo := features.NewRepository(db.Pool, tracer)
+	registryRepo := registry.NewRepository(db.Pool, tracer)
+	predictionRepo := predictions.NewRepository(db.Pool, tracer)
+	inferenceSvc := inference.NewService(
+		tracer,
+		featureRepo,
+		registryRepo,
+		predictionRepo,
+		signals,
+		ensemble.NewService(),
+		inference.Config{
+			Interval:              cfg.MLInterval,
+			Intervals:             cfg.MLIntervals,
+			TargetHours:           cfg.MLTargetHours,
+			LongThreshold:         cfg.MLLongThreshold,
+			ShortThreshold:        cfg.MLShortThreshold,
+			EnableIForest:         cfg.MLEnableIForest,
+			AnomalyThreshold:      cfg.MLAnomalyThresh,
+			AnomalyDampMax:        cfg.MLAnomalyDampMax,
+			PinnedVersions:        cfg.MLPinnedModels,
+			RollingVersions:       cfg.MLRollingModels,
+			UncertaintyBandWeight: cfg.MLUncertaintyBandWeight,
+		},
+	)
+	if cfg.MarketIntelEnabled {
+		// Score with the same Fear & Greed history the server's ensemble uses.
+		inferenceSvc.SetFearGreedSource(marketintel.NewRepository(db.Pool, tracer))
+	}
+	mlService := service.NewMLSignalService(
+		tracer,
+		candleRepo,
+		features.NewEngine(nil),
+		featureRepo,
+		nil,
+		inferenceSvc,
+		predictionRepo,
+		service.MLSignalServiceConfig{
+			Interval:        cfg.MLInterval,
+			Intervals:       cfg.MLIntervals,
+			TargetHours:     cfg.MLTargetHours,
+			TrainWindowDays: cfg.MLTrainWindowDays,
+		},
+	)
+	mlService.SetSimilaritySearch(similarity.NewService(tracer, featureRepo, similarity.Config{
+		TargetHours:  cfg.MLTargetHours,
+		LookbackDays: cfg.MLTrainWindowDays,
+	}))
+	if cfg.OrderBookEnabled {
+		// The server stores the snapshots; refreshes here must not zero the
+		// order book features it wrote.
+		mlService.SetOrderBookSource(repository.NewOrderBookRepository(db.Pool, tracer))
+	}
+	if cfg.DerivativesEnabled {
+		mlService.SetDerivativesSource(repository.NewDerivativesRepository(db.Pool, tracer))
+	}
+	if cfg.GlobalMarketEnabled {
+		mlService.SetGlobalMarketSource(repository.NewGlobalMarketRepository(db.Pool, tracer))
+	}
+	if cfg.MarketIntelEnabled && (cfg.MarketEventsBuiltin || cfg.MarketEventsFile != "") {
+		// The server's market intel job stores the calendar.
+		mlService.SetEventSource(marketintel.NewRepository(db.Pool, tracer))
+	}
+	return mlService
+}
+
 func runHTTPMode(ctx context.Context, cancel context.CancelFunc, cfg *config.Config, mcpSrv *sdkmcp.Server) error {
 	if !cfg.MCPHTTPEnabled {
 		return fmt.Errorf("MCP_HTTP_ENABLED must be true when MCP_TRANSPORT=http")
@@ -122,6 +340,7 @@ func runHTTPMode(ctx context.Context, cancel context.CancelFunc, cfg *config.Con
 
 	handler := newMCPHandlerFunc(mcpSrv, mcpserver.HTTPHandlerConfig{
 		AuthToken:       cfg.MCPAuthToken,
+		AdminToken:      cfg.MCPAdminToken,
 		RateLimitPerMin: cfg.MCPRateLimitPerMin,
 		MaxBodyBytes:    defaultMCPHTTPMaxBodyBytes,
 	})
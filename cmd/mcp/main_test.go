@@ -11,14 +11,16 @@ import (
 
 	"bug-free-umbrella/internal/chart"
 	"bug-free-umbrella/internal/config"
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/job"
 	mcpserver "bug-free-umbrella/internal/mcp"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/secrets"
 	"bug-free-umbrella/internal/service"
 	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/pkg/domain"
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -100,10 +102,12 @@ func stubMCPDeps(t *testing.T, transport string) func() {
 	t.Helper()
 
 	origLoadEnv := loadEnvFunc
+	origLoadSecrets := loadSecretsFunc
 	origLoadConfig := loadConfigFunc
 	origInitPostgres := initPostgresFunc
 	origInitRedis := initRedisFunc
 	origInitTracer := initTracerFunc
+	origInitMeter := initMeterFunc
 	origNewSignalRepo := newSignalRepoFunc
 	origNewSignalImageRepo := newSignalImageRepoFunc
 	origNewProvider := newCoinGeckoProviderFunc
@@ -116,6 +120,7 @@ func stubMCPDeps(t *testing.T, transport string) func() {
 	origNewMCPHandler := newMCPHandlerFunc
 
 	loadEnvFunc = func(...string) error { return nil }
+	loadSecretsFunc = func(context.Context) (*secrets.Watcher, error) { return nil, nil }
 	loadConfigFunc = func() *config.Config {
 		return &config.Config{
 			RedisURL:              "",
@@ -136,13 +141,16 @@ func stubMCPDeps(t *testing.T, transport string) func() {
 		tp := sdktrace.NewTracerProvider()
 		return tp, tp.Tracer("test"), nil
 	}
+	initMeterFunc = func(context.Context) (*sdkmetric.MeterProvider, error) {
+		return sdkmetric.NewMeterProvider(), nil
+	}
 	newSignalRepoFunc = func(repository.PgxPool, trace.Tracer) *repository.SignalRepository {
 		return nil
 	}
 	newSignalImageRepoFunc = func(repository.PgxPool, trace.Tracer) *repository.SignalImageRepository {
 		return nil
 	}
-	newCoinGeckoProviderFunc = func(trace.Tracer) service.PriceProvider { return stubMCPPriceProvider{} }
+	newCoinGeckoProviderFunc = func(trace.Tracer, string) service.PriceProvider { return stubMCPPriceProvider{} }
 	newSignalEngineFunc = func(func() time.Time) *signalengine.Engine { return signalengine.NewEngine(nil) }
 	newSignalServiceFunc = func(
 		trace.Tracer,
@@ -166,10 +174,12 @@ func stubMCPDeps(t *testing.T, transport string) func() {
 
 	return func() {
 		loadEnvFunc = origLoadEnv
+		loadSecretsFunc = origLoadSecrets
 		loadConfigFunc = origLoadConfig
 		initPostgresFunc = origInitPostgres
 		initRedisFunc = origInitRedis
 		initTracerFunc = origInitTracer
+		initMeterFunc = origInitMeter
 		newSignalRepoFunc = origNewSignalRepo
 		newSignalImageRepoFunc = origNewSignalImageRepo
 		newCoinGeckoProviderFunc = origNewProvider
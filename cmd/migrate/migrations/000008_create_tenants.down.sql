@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_conversation_messages_tenant_chat_created;
+
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_chat_created
+    ON conversation_messages (chat_id, created_at DESC);
+
+ALTER TABLE conversation_messages DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id          TEXT            PRIMARY KEY,
+    name        TEXT            NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO tenants (id, name) VALUES ('default', 'Default')
+ON CONFLICT (id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id          BIGSERIAL       PRIMARY KEY,
+    tenant_id   TEXT            NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    key_hash    TEXT            NOT NULL UNIQUE,
+    label       TEXT            NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant
+    ON api_keys (tenant_id) WHERE revoked_at IS NULL;
+
+ALTER TABLE conversation_messages
+    ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+DROP INDEX IF EXISTS idx_conversation_messages_chat_created;
+
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_tenant_chat_created
+    ON conversation_messages (tenant_id, chat_id, created_at DESC);
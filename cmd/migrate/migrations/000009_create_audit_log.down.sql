@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_reject_mutation();
+DROP TABLE IF EXISTS audit_log;
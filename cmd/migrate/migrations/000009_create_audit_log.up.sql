@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id           BIGSERIAL       PRIMARY KEY,
+    tenant_id    TEXT            NOT NULL DEFAULT 'default',
+    actor        TEXT            NOT NULL,
+    action       TEXT            NOT NULL,
+    entity_type  TEXT            NOT NULL,
+    entity_id    TEXT            NOT NULL DEFAULT '',
+    before_json  TEXT,
+    after_json   TEXT,
+    created_at   TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created
+    ON audit_log (created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created
+    ON audit_log (tenant_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_action_created
+    ON audit_log (action, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_log_reject_mutation() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+CREATE TRIGGER trg_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_reject_mutation();
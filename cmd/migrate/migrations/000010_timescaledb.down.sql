@@ -0,0 +1,17 @@
+-- Hypertables are left in place; converting them back would rewrite every
+-- chunk. Only the continuous aggregate is replaced with the plain view.
+DROP VIEW IF EXISTS ml_accuracy_daily;
+DROP MATERIALIZED VIEW IF EXISTS ml_accuracy_daily_agg;
+
+CREATE OR REPLACE VIEW ml_accuracy_daily AS
+SELECT
+    model_key,
+    DATE_TRUNC('day', resolved_at AT TIME ZONE 'UTC') AS day_utc,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE is_correct IS TRUE) AS correct,
+    CASE WHEN COUNT(*) = 0 THEN 0
+         ELSE COUNT(*) FILTER (WHERE is_correct IS TRUE)::DOUBLE PRECISION / COUNT(*)::DOUBLE PRECISION
+    END AS accuracy
+FROM ml_predictions
+WHERE resolved_at IS NOT NULL
+GROUP BY model_key, DATE_TRUNC('day', resolved_at AT TIME ZONE 'UTC');
@@ -0,0 +1,67 @@
+-- Optional TimescaleDB integration. Everything below is skipped on servers
+-- where the extension is not available, so plain Postgres installs keep the
+-- regular tables and the ml_accuracy_daily view from 000005.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb') THEN
+        RAISE NOTICE 'timescaledb extension not available; skipping hypertables';
+        RETURN;
+    END IF;
+
+    CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+    PERFORM create_hypertable('candles', 'open_time',
+        chunk_time_interval => INTERVAL '7 days',
+        if_not_exists => TRUE,
+        migrate_data => TRUE);
+
+    PERFORM create_hypertable('ml_feature_rows', 'open_time',
+        chunk_time_interval => INTERVAL '7 days',
+        if_not_exists => TRUE,
+        migrate_data => TRUE);
+
+    -- Hypertable unique constraints must include the partitioning column.
+    ALTER TABLE ml_predictions DROP CONSTRAINT IF EXISTS ml_predictions_pkey;
+    ALTER TABLE ml_predictions ADD PRIMARY KEY (id, open_time);
+
+    PERFORM create_hypertable('ml_predictions', 'open_time',
+        chunk_time_interval => INTERVAL '30 days',
+        if_not_exists => TRUE,
+        migrate_data => TRUE);
+
+    -- Continuous aggregates bucket on the hypertable time column, so daily
+    -- accuracy is grouped by prediction open_time rather than resolved_at.
+    DROP VIEW IF EXISTS ml_accuracy_daily;
+
+    EXECUTE $sql$
+        CREATE MATERIALIZED VIEW ml_accuracy_daily_agg
+        WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+        SELECT
+            model_key,
+            time_bucket(INTERVAL '1 day', open_time) AS day_utc,
+            COUNT(*) AS total,
+            SUM(CASE WHEN is_correct IS TRUE THEN 1 ELSE 0 END) AS correct
+        FROM ml_predictions
+        WHERE resolved_at IS NOT NULL
+        GROUP BY model_key, time_bucket(INTERVAL '1 day', open_time)
+        WITH NO DATA
+    $sql$;
+
+    PERFORM add_continuous_aggregate_policy('ml_accuracy_daily_agg',
+        start_offset => INTERVAL '30 days',
+        end_offset => INTERVAL '1 hour',
+        schedule_interval => INTERVAL '1 hour',
+        if_not_exists => TRUE);
+
+    CREATE VIEW ml_accuracy_daily AS
+    SELECT
+        model_key,
+        day_utc,
+        total,
+        correct,
+        CASE WHEN total = 0 THEN 0
+             ELSE correct::DOUBLE PRECISION / total::DOUBLE PRECISION
+        END AS accuracy
+    FROM ml_accuracy_daily_agg;
+END
+$$;
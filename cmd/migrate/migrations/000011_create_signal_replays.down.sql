@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS signal_replays;
+DROP TABLE IF EXISTS signal_replay_runs;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS signal_replay_runs (
+    id           TEXT        PRIMARY KEY,
+    from_time    TIMESTAMPTZ NOT NULL,
+    to_time      TIMESTAMPTZ NOT NULL,
+    symbols      TEXT[]      NOT NULL,
+    intervals    TEXT[]      NOT NULL,
+    notes        TEXT        NOT NULL DEFAULT '',
+    signal_count BIGINT      NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS signal_replays (
+    id          BIGSERIAL   PRIMARY KEY,
+    run_id      TEXT        NOT NULL REFERENCES signal_replay_runs(id) ON DELETE CASCADE,
+    symbol      TEXT        NOT NULL,
+    interval    TEXT        NOT NULL,
+    indicator   TEXT        NOT NULL,
+    direction   TEXT        NOT NULL,
+    risk        SMALLINT    NOT NULL,
+    timestamp   TIMESTAMPTZ NOT NULL,
+    details     TEXT        NOT NULL DEFAULT '',
+    UNIQUE (run_id, symbol, interval, indicator, timestamp, direction)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_signals_indicator_version;
+
+ALTER TABLE signal_replays DROP COLUMN IF EXISTS version;
+ALTER TABLE signals DROP COLUMN IF EXISTS version;
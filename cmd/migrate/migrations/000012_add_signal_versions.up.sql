@@ -0,0 +1,5 @@
+-- Signals written before versioning keep an empty version.
+ALTER TABLE signals ADD COLUMN IF NOT EXISTS version TEXT NOT NULL DEFAULT '';
+ALTER TABLE signal_replays ADD COLUMN IF NOT EXISTS version TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_signals_indicator_version ON signals (indicator, version);
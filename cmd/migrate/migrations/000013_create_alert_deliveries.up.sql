@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+    id              BIGSERIAL   PRIMARY KEY,
+    chat_id         BIGINT      NOT NULL,
+    signal_id       BIGINT      REFERENCES signals(id) ON DELETE SET NULL,
+    message         TEXT        NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'pending',
+    attempts        INT         NOT NULL DEFAULT 0,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_due
+    ON alert_deliveries (next_attempt_at)
+    WHERE status IN ('pending', 'failed');
+
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_dead
+    ON alert_deliveries (created_at DESC)
+    WHERE status = 'dead';
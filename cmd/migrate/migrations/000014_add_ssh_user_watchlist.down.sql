@@ -0,0 +1 @@
+ALTER TABLE ssh_users DROP COLUMN IF EXISTS watchlist;
@@ -0,0 +1,2 @@
+-- Symbols pinned by the user; empty means the TUI shows every symbol.
+ALTER TABLE ssh_users ADD COLUMN IF NOT EXISTS watchlist TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ml_model_promotions;
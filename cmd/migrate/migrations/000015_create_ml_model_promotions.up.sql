@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS ml_model_promotions (
+    id          BIGSERIAL   PRIMARY KEY,
+    model_key   TEXT        NOT NULL,
+    version     INTEGER     NOT NULL,
+    promoted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ml_model_promotions_promoted_at
+    ON ml_model_promotions (promoted_at DESC);
+
+-- ml_model_versions only keeps activated_at for the active version, so seed
+-- the history with what is active today.
+INSERT INTO ml_model_promotions (model_key, version, promoted_at)
+SELECT model_key, version, activated_at
+FROM ml_model_versions
+WHERE is_active = TRUE AND activated_at IS NOT NULL;
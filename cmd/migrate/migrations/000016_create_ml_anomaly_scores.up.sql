@@ -0,0 +1,23 @@
+-- One narrow row per isolation forest score, kept apart from the wide
+-- ml_predictions rows so the anomaly history stays cheap to scan.
+CREATE TABLE IF NOT EXISTS ml_anomaly_scores (
+    symbol      TEXT             NOT NULL,
+    interval    TEXT             NOT NULL,
+    open_time   TIMESTAMPTZ      NOT NULL,
+    score       DOUBLE PRECISION NOT NULL,
+    damp_factor DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ml_anomaly_scores_open_time
+    ON ml_anomaly_scores (open_time DESC);
+
+-- Seed the series with the scores already stored as iforest predictions.
+INSERT INTO ml_anomaly_scores (symbol, interval, open_time, score, damp_factor)
+SELECT DISTINCT ON (symbol, interval, open_time)
+       symbol, interval, open_time, confidence,
+       COALESCE((details_json::jsonb ->> 'damp_factor')::DOUBLE PRECISION, 1)
+FROM ml_predictions
+WHERE model_key LIKE 'iforest\_%'
+ORDER BY symbol, interval, open_time, model_version DESC
+ON CONFLICT DO NOTHING;
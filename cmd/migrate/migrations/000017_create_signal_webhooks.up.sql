@@ -0,0 +1,11 @@
+-- HTTP endpoints that receive new signals, with an optional Go template for
+-- the request body and extra headers (for example an Authorization token).
+CREATE TABLE IF NOT EXISTS signal_webhooks (
+    id               BIGSERIAL   PRIMARY KEY,
+    label            TEXT        NOT NULL DEFAULT '',
+    url              TEXT        NOT NULL,
+    payload_template TEXT        NOT NULL DEFAULT '',
+    headers          JSONB       NOT NULL DEFAULT '{}'::jsonb,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    disabled_at      TIMESTAMPTZ
+);
@@ -0,0 +1,2 @@
+-- Last TUI screen state (tab, filters, theme) as domain.TUIState JSON.
+ALTER TABLE ssh_users ADD COLUMN IF NOT EXISTS tui_state JSONB NOT NULL DEFAULT '{}';
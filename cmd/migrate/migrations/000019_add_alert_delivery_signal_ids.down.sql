@@ -0,0 +1 @@
+ALTER TABLE alert_deliveries DROP COLUMN IF EXISTS signal_ids;
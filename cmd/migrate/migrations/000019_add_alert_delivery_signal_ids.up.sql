@@ -0,0 +1,3 @@
+-- Every signal of a grouped alert, sent as one album; signal_id keeps the
+-- first. Empty for single-signal alerts and broadcasts.
+ALTER TABLE alert_deliveries ADD COLUMN IF NOT EXISTS signal_ids BIGINT[] NOT NULL DEFAULT '{}';
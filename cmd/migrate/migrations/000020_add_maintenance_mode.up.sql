@@ -0,0 +1,8 @@
+-- Single-row operator maintenance switch, shared by cmd/server and cmd/ssh.
+CREATE TABLE IF NOT EXISTS maintenance_mode (
+    id         SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    enabled    BOOLEAN NOT NULL DEFAULT FALSE,
+    reason     TEXT NOT NULL DEFAULT '',
+    actor      TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- The resolved_at view is the only definition both backends agree on, so it
+-- is kept; 000010's continuous aggregate is not recreated.
+SELECT 1;
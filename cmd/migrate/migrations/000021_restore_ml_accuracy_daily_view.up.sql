@@ -0,0 +1,19 @@
+-- 000010 replaced ml_accuracy_daily with a continuous aggregate on
+-- TimescaleDB, which can only bucket on open_time. Restore the plain view so
+-- daily accuracy is bucketed by resolved_at on every backend. A no-op on
+-- plain Postgres, where the view never changed.
+DROP VIEW IF EXISTS ml_accuracy_daily;
+DROP MATERIALIZED VIEW IF EXISTS ml_accuracy_daily_agg;
+
+CREATE OR REPLACE VIEW ml_accuracy_daily AS
+SELECT
+    model_key,
+    DATE_TRUNC('day', resolved_at AT TIME ZONE 'UTC') AS day_utc,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE is_correct IS TRUE) AS correct,
+    CASE WHEN COUNT(*) = 0 THEN 0
+         ELSE COUNT(*) FILTER (WHERE is_correct IS TRUE)::DOUBLE PRECISION / COUNT(*)::DOUBLE PRECISION
+    END AS accuracy
+FROM ml_predictions
+WHERE resolved_at IS NOT NULL
+GROUP BY model_key, DATE_TRUNC('day', resolved_at AT TIME ZONE 'UTC');
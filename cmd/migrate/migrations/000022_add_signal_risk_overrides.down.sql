@@ -0,0 +1 @@
+DROP TABLE IF EXISTS signal_risk_overrides;
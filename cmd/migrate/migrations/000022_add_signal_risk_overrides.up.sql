@@ -0,0 +1,9 @@
+-- Operator overrides of the signal engine's indicator risk. An empty interval
+-- applies to every interval. Read at startup on top of SIGNAL_RISK_OVERRIDES.
+CREATE TABLE IF NOT EXISTS signal_risk_overrides (
+    indicator  TEXT NOT NULL,
+    interval   TEXT NOT NULL DEFAULT '',
+    risk       SMALLINT NOT NULL CHECK (risk BETWEEN 1 AND 5),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (indicator, interval)
+);
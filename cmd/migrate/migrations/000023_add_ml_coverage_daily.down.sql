@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ml_coverage_daily;
@@ -0,0 +1,35 @@
+-- Daily coverage per model: how many predictions were directional calls and
+-- how many were holds, next to the accuracy of the directional calls alone.
+-- Accuracy can be raised by abstaining, so the two are monitored together.
+-- Bucketed by the candle's open_time and refreshed by the outcome resolver.
+CREATE TABLE IF NOT EXISTS ml_coverage_daily (
+    model_key            TEXT    NOT NULL,
+    day_utc              DATE    NOT NULL,
+    total                INTEGER NOT NULL DEFAULT 0,
+    directional          INTEGER NOT NULL DEFAULT 0,
+    holds                INTEGER NOT NULL DEFAULT 0,
+    held_anomaly         INTEGER NOT NULL DEFAULT 0,
+    held_uncertainty     INTEGER NOT NULL DEFAULT 0,
+    resolved_directional INTEGER NOT NULL DEFAULT 0,
+    correct_directional  INTEGER NOT NULL DEFAULT 0,
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (model_key, day_utc)
+);
+
+INSERT INTO ml_coverage_daily (
+    model_key, day_utc, total, directional, holds, held_anomaly, held_uncertainty,
+    resolved_directional, correct_directional
+)
+SELECT model_key,
+       (open_time AT TIME ZONE 'UTC')::DATE,
+       COUNT(*),
+       COUNT(*) FILTER (WHERE direction IN ('long', 'short')),
+       COUNT(*) FILTER (WHERE direction = 'hold'),
+       COUNT(*) FILTER (WHERE direction = 'hold' AND details_json::jsonb->>'held_reason' = 'anomaly_damping'),
+       COUNT(*) FILTER (WHERE direction = 'hold' AND details_json::jsonb->>'held_reason' = 'uncertainty'),
+       COUNT(*) FILTER (WHERE direction IN ('long', 'short') AND resolved_at IS NOT NULL),
+       COUNT(*) FILTER (WHERE direction IN ('long', 'short') AND is_correct IS TRUE)
+FROM ml_predictions
+WHERE model_key NOT LIKE 'iforest\_%'
+GROUP BY 1, 2
+ON CONFLICT (model_key, day_utc) DO NOTHING;
@@ -0,0 +1,20 @@
+-- Operator corrections of resolved prediction outcomes, one per candle, for
+-- labels produced by bad exchange data. original_actual_up keeps the label
+-- the resolver computed. exclude_from_training drops the candle's feature
+-- row from training while ML_TRAIN_EXCLUDE_FLAGGED is on.
+CREATE TABLE IF NOT EXISTS ml_label_overrides (
+    symbol                TEXT        NOT NULL,
+    interval              TEXT        NOT NULL,
+    open_time             TIMESTAMPTZ NOT NULL,
+    actual_up             BOOLEAN     NOT NULL,
+    original_actual_up    BOOLEAN,
+    exclude_from_training BOOLEAN     NOT NULL DEFAULT TRUE,
+    reason                TEXT        NOT NULL DEFAULT '',
+    actor                 TEXT        NOT NULL DEFAULT '',
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ml_label_overrides_training
+    ON ml_label_overrides (interval, open_time)
+    WHERE exclude_from_training;
@@ -0,0 +1,34 @@
+-- The symbol registry. Every process loads the enabled rows on startup and
+-- reloads them periodically, so assets added or removed through the admin API
+-- are picked up without a rebuild. Removing an asset disables it and keeps
+-- its row; empty pairs mean the asset is not listed on that exchange.
+CREATE TABLE IF NOT EXISTS tracked_assets (
+    symbol         TEXT        PRIMARY KEY,
+    name           TEXT        NOT NULL DEFAULT '',
+    coingecko_id   TEXT        NOT NULL,
+    binance_pair   TEXT        NOT NULL DEFAULT '',
+    kraken_pair    TEXT        NOT NULL DEFAULT '',
+    coinbase_pair  TEXT        NOT NULL DEFAULT '',
+    price_decimals SMALLINT    NOT NULL DEFAULT 0 CHECK (price_decimals BETWEEN 0 AND 12),
+    enabled        BOOLEAN     NOT NULL DEFAULT TRUE,
+    position       INTEGER     NOT NULL DEFAULT 0,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A CoinGecko ID can back only one tracked symbol at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracked_assets_coingecko_id
+    ON tracked_assets (coingecko_id)
+    WHERE enabled;
+
+INSERT INTO tracked_assets (symbol, name, coingecko_id, binance_pair, kraken_pair, coinbase_pair, position) VALUES
+    ('BTC',   'Bitcoin',   'bitcoin',       'BTCUSDT',  'XXBTZUSD', 'BTC-USD',  1),
+    ('ETH',   'Ethereum',  'ethereum',      'ETHUSDT',  'XETHZUSD', 'ETH-USD',  2),
+    ('SOL',   'Solana',    'solana',        'SOLUSDT',  'SOLUSD',   'SOL-USD',  3),
+    ('XRP',   'XRP',       'ripple',        'XRPUSDT',  'XXRPZUSD', 'XRP-USD',  4),
+    ('ADA',   'Cardano',   'cardano',       'ADAUSDT',  'ADAUSD',   'ADA-USD',  5),
+    ('DOGE',  'Dogecoin',  'dogecoin',      'DOGEUSDT', 'XDGUSD',   'DOGE-USD', 6),
+    ('DOT',   'Polkadot',  'polkadot',      'DOTUSDT',  'DOTUSD',   'DOT-USD',  7),
+    ('AVAX',  'Avalanche', 'avalanche-2',   'AVAXUSDT', 'AVAXUSD',  'AVAX-USD', 8),
+    ('LINK',  'Chainlink', 'chainlink',     'LINKUSDT', 'LINKUSD',  'LINK-USD', 9),
+    ('MATIC', 'Polygon',   'matic-network', 'POLUSDT',  'POLUSD',   'POL-USD',  10)
+ON CONFLICT (symbol) DO NOTHING;
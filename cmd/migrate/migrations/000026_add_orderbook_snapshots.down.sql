@@ -0,0 +1,5 @@
+ALTER TABLE ml_feature_rows
+    DROP COLUMN IF EXISTS book_spread_bps,
+    DROP COLUMN IF EXISTS book_imbalance;
+
+DROP TABLE IF EXISTS orderbook_snapshots;
@@ -0,0 +1,24 @@
+-- Periodic top-of-book snapshots per symbol: best bid/ask, spread and the
+-- USD depth resting within depth_band_pct of the mid on each side. Hourly
+-- averages of imbalance and spread feed the ML feature rows below.
+CREATE TABLE IF NOT EXISTS orderbook_snapshots (
+    id              BIGSERIAL        PRIMARY KEY,
+    symbol          TEXT             NOT NULL,
+    source          TEXT             NOT NULL,
+    captured_at     TIMESTAMPTZ      NOT NULL,
+    best_bid        DOUBLE PRECISION NOT NULL,
+    best_ask        DOUBLE PRECISION NOT NULL,
+    spread_bps      DOUBLE PRECISION NOT NULL,
+    depth_band_pct  DOUBLE PRECISION NOT NULL,
+    bid_depth_usd   DOUBLE PRECISION NOT NULL,
+    ask_depth_usd   DOUBLE PRECISION NOT NULL,
+    imbalance       DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_orderbook_snapshots_symbol_captured
+    ON orderbook_snapshots (symbol, captured_at DESC);
+
+-- Order book features. Rows from before snapshots were collected keep 0.
+ALTER TABLE ml_feature_rows
+    ADD COLUMN IF NOT EXISTS book_imbalance  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS book_spread_bps DOUBLE PRECISION NOT NULL DEFAULT 0;
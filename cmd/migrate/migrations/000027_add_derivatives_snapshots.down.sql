@@ -0,0 +1,5 @@
+ALTER TABLE ml_feature_rows
+    DROP COLUMN IF EXISTS oi_change_24h,
+    DROP COLUMN IF EXISTS funding_rate;
+
+DROP TABLE IF EXISTS derivatives_snapshots;
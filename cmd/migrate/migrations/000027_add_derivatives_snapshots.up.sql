@@ -0,0 +1,21 @@
+-- Periodic perpetual futures snapshots per symbol: funding rate, mark price
+-- and open interest. Hourly averages feed the ML feature rows below.
+CREATE TABLE IF NOT EXISTS derivatives_snapshots (
+    id                 BIGSERIAL        PRIMARY KEY,
+    symbol             TEXT             NOT NULL,
+    source             TEXT             NOT NULL,
+    captured_at        TIMESTAMPTZ      NOT NULL,
+    mark_price         DOUBLE PRECISION NOT NULL,
+    funding_rate       DOUBLE PRECISION NOT NULL,
+    next_funding_time  TIMESTAMPTZ,
+    open_interest      DOUBLE PRECISION NOT NULL,
+    open_interest_usd  DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_derivatives_snapshots_symbol_captured
+    ON derivatives_snapshots (symbol, captured_at DESC);
+
+-- Positioning features. Rows from before snapshots were collected keep 0.
+ALTER TABLE ml_feature_rows
+    ADD COLUMN IF NOT EXISTS funding_rate  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS oi_change_24h DOUBLE PRECISION NOT NULL DEFAULT 0;
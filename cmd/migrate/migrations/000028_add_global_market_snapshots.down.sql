@@ -0,0 +1,5 @@
+ALTER TABLE ml_feature_rows
+    DROP COLUMN IF EXISTS mcap_change_24h,
+    DROP COLUMN IF EXISTS btc_dominance;
+
+DROP TABLE IF EXISTS global_market_snapshots;
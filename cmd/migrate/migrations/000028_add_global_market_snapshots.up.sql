@@ -0,0 +1,20 @@
+-- Periodic whole-market snapshots: total market cap and volume and BTC/ETH
+-- dominance. Hourly averages feed the ML feature rows below.
+CREATE TABLE IF NOT EXISTS global_market_snapshots (
+    id                         BIGSERIAL        PRIMARY KEY,
+    source                     TEXT             NOT NULL,
+    captured_at                TIMESTAMPTZ      NOT NULL,
+    total_market_cap_usd       DOUBLE PRECISION NOT NULL,
+    total_volume_usd           DOUBLE PRECISION NOT NULL,
+    btc_dominance_pct          DOUBLE PRECISION NOT NULL,
+    eth_dominance_pct          DOUBLE PRECISION NOT NULL,
+    market_cap_change_24h_pct  DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_global_market_snapshots_captured
+    ON global_market_snapshots (captured_at DESC);
+
+-- Market-wide features. Rows from before snapshots were collected keep 0.
+ALTER TABLE ml_feature_rows
+    ADD COLUMN IF NOT EXISTS btc_dominance   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS mcap_change_24h DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS fear_greed_index;
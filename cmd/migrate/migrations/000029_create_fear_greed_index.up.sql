@@ -0,0 +1,8 @@
+-- Daily Crypto Fear & Greed index values, kept without a retention cutoff
+-- so scoring and replays can read the value of any past day.
+CREATE TABLE IF NOT EXISTS fear_greed_index (
+    observed_at     TIMESTAMPTZ PRIMARY KEY,
+    value           INTEGER     NOT NULL,
+    classification  TEXT        NOT NULL DEFAULT '',
+    fetched_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS cex_dex_spreads;
@@ -0,0 +1,14 @@
+-- On-chain DEX prices next to the exchange price captured at the same time.
+-- spread_pct is (dex - cex) / cex in percent.
+CREATE TABLE IF NOT EXISTS cex_dex_spreads (
+    id             BIGSERIAL        PRIMARY KEY,
+    symbol         TEXT             NOT NULL,
+    venue          TEXT             NOT NULL,
+    captured_at    TIMESTAMPTZ      NOT NULL,
+    dex_price_usd  DOUBLE PRECISION NOT NULL,
+    cex_price_usd  DOUBLE PRECISION NOT NULL,
+    spread_pct     DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cex_dex_spreads_symbol_captured
+    ON cex_dex_spreads (symbol, captured_at DESC);
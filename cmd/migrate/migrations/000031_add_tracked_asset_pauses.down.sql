@@ -0,0 +1,4 @@
+ALTER TABLE tracked_assets
+    DROP COLUMN IF EXISTS alerts_paused,
+    DROP COLUMN IF EXISTS ml_paused,
+    DROP COLUMN IF EXISTS signals_paused;
@@ -0,0 +1,6 @@
+-- Per-symbol pauses: a paused symbol stays tracked and keeps collecting
+-- prices and candles, but skips signal generation, ML inference or alerts.
+ALTER TABLE tracked_assets
+    ADD COLUMN IF NOT EXISTS signals_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS ml_paused      BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS alerts_paused  BOOLEAN NOT NULL DEFAULT FALSE;
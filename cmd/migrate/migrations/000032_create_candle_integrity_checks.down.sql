@@ -0,0 +1 @@
+DROP TABLE IF EXISTS candle_integrity_checks;
@@ -0,0 +1,17 @@
+-- Stored candles re-fetched from the price provider and compared. One row
+-- per symbol and interval per run; mismatches lists the differing values.
+CREATE TABLE IF NOT EXISTS candle_integrity_checks (
+    id            BIGSERIAL        PRIMARY KEY,
+    symbol        TEXT             NOT NULL,
+    interval      TEXT             NOT NULL,
+    provider      TEXT             NOT NULL,
+    checked_at    TIMESTAMPTZ      NOT NULL,
+    sampled       INTEGER          NOT NULL,
+    missing       INTEGER          NOT NULL,
+    mismatched    INTEGER          NOT NULL,
+    max_diff_pct  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    mismatches    JSONB            NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX IF NOT EXISTS idx_candle_integrity_checks_checked
+    ON candle_integrity_checks (checked_at DESC);
@@ -0,0 +1,4 @@
+ALTER TABLE ml_feature_rows
+    DROP COLUMN IF EXISTS event_proximity;
+
+DROP TABLE IF EXISTS market_events;
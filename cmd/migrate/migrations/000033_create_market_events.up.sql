@@ -0,0 +1,21 @@
+-- Scheduled market-moving events: FOMC decisions, CPI prints and token
+-- unlocks. Macro events have an empty symbol; unlocks name the token.
+CREATE TABLE IF NOT EXISTS market_events (
+    id          BIGSERIAL   PRIMARY KEY,
+    kind        TEXT        NOT NULL,
+    symbol      TEXT        NOT NULL DEFAULT '',
+    starts_at   TIMESTAMPTZ NOT NULL,
+    title       TEXT        NOT NULL,
+    importance  SMALLINT    NOT NULL DEFAULT 2,
+    source      TEXT        NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, symbol, starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_market_events_starts_at
+    ON market_events (starts_at);
+
+-- Closeness of the next scheduled event. Rows from before events were
+-- ingested keep 0.
+ALTER TABLE ml_feature_rows
+    ADD COLUMN IF NOT EXISTS event_proximity DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Provider prices that strayed from the quorum of providers fetched in the
+-- same refresh. rejected marks primary prices replaced by the quorum price.
+CREATE TABLE IF NOT EXISTS price_discrepancies (
+    id             BIGSERIAL        PRIMARY KEY,
+    symbol         TEXT             NOT NULL,
+    provider       TEXT             NOT NULL,
+    price_usd      DOUBLE PRECISION NOT NULL,
+    quorum_usd     DOUBLE PRECISION NOT NULL,
+    deviation_pct  DOUBLE PRECISION NOT NULL,
+    quotes         JSONB            NOT NULL DEFAULT '{}',
+    rejected       BOOLEAN          NOT NULL DEFAULT FALSE,
+    observed_at    TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_discrepancies_observed
+    ON price_discrepancies (observed_at DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_signal_webhooks_tenant;
+
+ALTER TABLE signal_webhooks
+    DROP COLUMN IF EXISTS tenant_id;
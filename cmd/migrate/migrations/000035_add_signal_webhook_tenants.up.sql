@@ -0,0 +1,7 @@
+-- Webhooks belong to the tenant that created them. Existing rows were
+-- created by the operator.
+ALTER TABLE signal_webhooks
+    ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_signal_webhooks_tenant
+    ON signal_webhooks (tenant_id, created_at DESC);
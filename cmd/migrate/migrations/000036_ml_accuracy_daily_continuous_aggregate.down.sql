@@ -0,0 +1,18 @@
+DROP VIEW IF EXISTS ml_accuracy_daily;
+DROP MATERIALIZED VIEW IF EXISTS ml_accuracy_daily_agg;
+DROP TRIGGER IF EXISTS trg_ml_prediction_resolutions_sync ON ml_predictions;
+DROP FUNCTION IF EXISTS ml_prediction_resolutions_sync();
+DROP TABLE IF EXISTS ml_prediction_resolutions;
+
+CREATE OR REPLACE VIEW ml_accuracy_daily AS
+SELECT
+    model_key,
+    DATE_TRUNC('day', resolved_at AT TIME ZONE 'UTC') AS day_utc,
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE is_correct IS TRUE) AS correct,
+    CASE WHEN COUNT(*) = 0 THEN 0
+         ELSE COUNT(*) FILTER (WHERE is_correct IS TRUE)::DOUBLE PRECISION / COUNT(*)::DOUBLE PRECISION
+    END AS accuracy
+FROM ml_predictions
+WHERE resolved_at IS NOT NULL
+GROUP BY model_key, DATE_TRUNC('day', resolved_at AT TIME ZONE 'UTC');
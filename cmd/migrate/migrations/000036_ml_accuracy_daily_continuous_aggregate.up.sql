@@ -0,0 +1,90 @@
+-- On TimescaleDB, serve ml_accuracy_daily from a continuous aggregate
+-- bucketed on resolved_at. A continuous aggregate can only bucket on its
+-- hypertable's time column and ml_predictions is partitioned on open_time,
+-- so a trigger copies each resolution into ml_prediction_resolutions, a
+-- hypertable on resolved_at. Plain Postgres keeps the view from 000021.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb') THEN
+        RAISE NOTICE 'timescaledb not installed; keeping the ml_accuracy_daily view';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS ml_prediction_resolutions (
+        prediction_id BIGINT      NOT NULL,
+        model_key     TEXT        NOT NULL,
+        resolved_at   TIMESTAMPTZ NOT NULL,
+        is_correct    BOOLEAN,
+        PRIMARY KEY (prediction_id, resolved_at)
+    );
+
+    PERFORM create_hypertable('ml_prediction_resolutions', 'resolved_at',
+        chunk_time_interval => INTERVAL '30 days',
+        if_not_exists => TRUE);
+
+    CREATE INDEX IF NOT EXISTS idx_ml_prediction_resolutions_prediction
+        ON ml_prediction_resolutions (prediction_id);
+
+    -- Resolutions and label overrides update ml_predictions in place, so the
+    -- copy is replaced rather than upserted: resolved_at is part of its key.
+    CREATE OR REPLACE FUNCTION ml_prediction_resolutions_sync() RETURNS trigger AS $fn$
+    BEGIN
+        IF TG_OP <> 'INSERT' THEN
+            DELETE FROM ml_prediction_resolutions WHERE prediction_id = OLD.id;
+        END IF;
+        IF TG_OP <> 'DELETE' AND NEW.resolved_at IS NOT NULL THEN
+            INSERT INTO ml_prediction_resolutions (prediction_id, model_key, resolved_at, is_correct)
+            VALUES (NEW.id, NEW.model_key, NEW.resolved_at, NEW.is_correct);
+        END IF;
+        RETURN NULL;
+    END
+    $fn$ LANGUAGE plpgsql;
+
+    DROP TRIGGER IF EXISTS trg_ml_prediction_resolutions_sync ON ml_predictions;
+    CREATE TRIGGER trg_ml_prediction_resolutions_sync
+        AFTER INSERT OR UPDATE OF model_key, resolved_at, is_correct OR DELETE ON ml_predictions
+        FOR EACH ROW EXECUTE FUNCTION ml_prediction_resolutions_sync();
+
+    INSERT INTO ml_prediction_resolutions (prediction_id, model_key, resolved_at, is_correct)
+    SELECT id, model_key, resolved_at, is_correct
+    FROM ml_predictions
+    WHERE resolved_at IS NOT NULL
+    ON CONFLICT DO NOTHING;
+
+    DROP VIEW IF EXISTS ml_accuracy_daily;
+    DROP MATERIALIZED VIEW IF EXISTS ml_accuracy_daily_agg;
+
+    EXECUTE $sql$
+        CREATE MATERIALIZED VIEW ml_accuracy_daily_agg
+        WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+        SELECT
+            model_key,
+            time_bucket(INTERVAL '1 day', resolved_at) AS day_utc,
+            COUNT(*) AS total,
+            SUM(CASE WHEN is_correct IS TRUE THEN 1 ELSE 0 END) AS correct
+        FROM ml_prediction_resolutions
+        GROUP BY model_key, time_bucket(INTERVAL '1 day', resolved_at)
+        WITH NO DATA
+    $sql$;
+
+    -- Label overrides can change predictions of any age, so each refresh
+    -- covers the whole range; only invalidated buckets are recomputed.
+    PERFORM add_continuous_aggregate_policy('ml_accuracy_daily_agg',
+        start_offset => NULL,
+        end_offset => INTERVAL '1 hour',
+        schedule_interval => INTERVAL '1 hour',
+        if_not_exists => TRUE);
+
+    -- Same columns and types as the plain view, so readers don't change.
+    CREATE VIEW ml_accuracy_daily AS
+    SELECT
+        model_key,
+        day_utc AT TIME ZONE 'UTC' AS day_utc,
+        total,
+        correct,
+        CASE WHEN total = 0 THEN 0
+             ELSE correct::DOUBLE PRECISION / total::DOUBLE PRECISION
+        END AS accuracy
+    FROM ml_accuracy_daily_agg;
+END
+$$;
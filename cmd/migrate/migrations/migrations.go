@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned SQL schema files so binaries other
+// than cmd/migrate, such as the demo sandbox, can apply them.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
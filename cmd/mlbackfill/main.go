@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/config"
+	"bug-free-umbrella/internal/httpclient"
 	"bug-free-umbrella/internal/provider"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/rollup"
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
@@ -21,8 +26,17 @@ import (
 
 const (
 	defaultDays = 90
+	// coingeckoHourlyDays is the longest market_chart range CoinGecko serves
+	// at hourly granularity; longer ranges come back as daily samples.
+	coingeckoHourlyDays = 90
+	// historyTolerance is how late a source's first candle may start before
+	// the backfill reports its history as short.
+	historyTolerance = 24 * time.Hour
 )
 
+// backfillSources are the candle sources --source accepts.
+var backfillSources = []string{"coingecko", "binance", "kraken", "coinbase"}
+
 var (
 	loadEnvFunc = godotenv.Load
 	openPool    = pgxpool.New
@@ -32,6 +46,7 @@ type options struct {
 	days      int
 	symbols   []string
 	intervals []string
+	source    string
 }
 
 func main() {
@@ -60,20 +75,34 @@ func main() {
 		log.Fatalf("ping postgres: %v", err)
 	}
 
+	if err := httpclient.Configure(config.LoadOutboundHTTP()); err != nil {
+		log.Fatalf("invalid outbound HTTP settings: %v", err)
+	}
+
 	tracer := trace.NewNoopTracerProvider().Tracer("ml-backfill")
 	candleRepo := repository.NewCandleRepository(pool, tracer)
-	cgProvider := provider.NewCoinGeckoProvider(tracer)
+	marketProvider, err := newMarketProvider(opts.source, tracer, os.Getenv)
+	if err != nil {
+		log.Fatalf("configure %s: %v", opts.source, err)
+	}
 
 	log.Printf(
-		"starting candle backfill: days=%d symbols=%s intervals=%s",
+		"starting candle backfill: source=%s days=%d symbols=%s intervals=%s",
+		opts.source,
 		opts.days,
 		strings.Join(opts.symbols, ","),
 		strings.Join(opts.intervals, ","),
 	)
 
+	// Fill the 4h and 1d candles the provider did not return from 1h ones.
+	var deriver *rollup.Deriver
+	if targets := derivedIntervals(opts.intervals); len(targets) > 0 {
+		deriver = rollup.NewDeriver(candleRepo, targets...)
+	}
+
 	totalUpserted := 0
 	for _, symbol := range opts.symbols {
-		candles, err := cgProvider.FetchMarketChart(ctx, symbol, opts.days, opts.intervals)
+		candles, err := marketProvider.FetchMarketChart(ctx, symbol, opts.days, opts.intervals)
 		if err != nil {
 			log.Fatalf("fetch market chart for %s: %v", symbol, err)
 		}
@@ -86,10 +115,24 @@ func main() {
 		}
 		totalUpserted += len(candles)
 		log.Printf("backfilled %s: %d candles", symbol, len(candles))
+		for interval, first := range shortHistory(candles, time.Now().AddDate(0, 0, -opts.days)) {
+			log.Printf("warning: %s returned %s %s candles from %s only", opts.source, symbol, interval, first.Format(time.DateOnly))
+		}
+		if deriver != nil {
+			derived, err := deriver.Derive(ctx, symbol, time.Now().AddDate(0, 0, -opts.days), time.Now())
+			if err != nil {
+				log.Fatalf("derive candles for %s: %v", symbol, err)
+			}
+			if derived > 0 {
+				totalUpserted += derived
+				log.Printf("derived %s: %d candles from %s", symbol, derived, rollup.SourceInterval)
+			}
+		}
 	}
 
 	log.Printf(
-		"backfill complete: symbols=%d total_candles=%d intervals=%s days=%d",
+		"backfill complete: source=%s symbols=%d total_candles=%d intervals=%s days=%d",
+		opts.source,
 		len(opts.symbols),
 		totalUpserted,
 		strings.Join(opts.intervals, ","),
@@ -104,8 +147,9 @@ func parseOptions(args []string, getenv func(string) string) (options, error) {
 	daysDefault := defaultBackfillDays(getenv)
 	intervalsDefault := defaultBackfillIntervals(getenv)
 	days := fs.Int("days", daysDefault, "number of historical days to backfill (default from ML_BACKFILL_DAYS, then ML_TRAIN_WINDOW_DAYS, else 90)")
-	symbolsRaw := fs.String("symbols", strings.Join(domain.SupportedSymbols, ","), "comma-separated symbols to backfill")
+	symbolsRaw := fs.String("symbols", strings.Join(domain.SupportedSymbols(), ","), "comma-separated symbols to backfill")
 	intervalsRaw := fs.String("intervals", strings.Join(intervalsDefault, ","), "comma-separated candle intervals to backfill")
+	sourceRaw := fs.String("source", "", "candle source: coingecko, binance, kraken or coinbase (default from ML_BACKFILL_SOURCE, then an exchange PRICE_PROVIDER, else binance beyond 90 days and coingecko otherwise)")
 
 	if err := fs.Parse(args); err != nil {
 		return options{}, err
@@ -122,14 +166,83 @@ func parseOptions(args []string, getenv func(string) string) (options, error) {
 	if err != nil {
 		return options{}, err
 	}
+	source, err := backfillSource(*sourceRaw, getenv, *days)
+	if err != nil {
+		return options{}, err
+	}
 
 	return options{
 		days:      *days,
 		symbols:   symbols,
 		intervals: intervals,
+		source:    source,
 	}, nil
 }
 
+// backfillSource picks the candle source: the flag, then
+// ML_BACKFILL_SOURCE, then an exchange PRICE_PROVIDER. Otherwise CoinGecko
+// is used unless the range is one it only serves as daily samples, which is
+// fetched as Binance klines instead.
+func backfillSource(flagValue string, getenv func(string) string, days int) (string, error) {
+	for _, candidate := range []string{flagValue, getenv("ML_BACKFILL_SOURCE")} {
+		source := strings.ToLower(strings.TrimSpace(candidate))
+		if source == "" {
+			continue
+		}
+		if !slices.Contains(backfillSources, source) {
+			return "", fmt.Errorf("unsupported source: %s (supported: %s)", source, strings.Join(backfillSources, ", "))
+		}
+		return source, nil
+	}
+	if source := strings.ToLower(strings.TrimSpace(getenv("PRICE_PROVIDER"))); source != "coingecko" && slices.Contains(backfillSources, source) {
+		return source, nil
+	}
+	if days > coingeckoHourlyDays {
+		return "binance", nil
+	}
+	return "coingecko", nil
+}
+
+func newMarketProvider(source string, tracer trace.Tracer, getenv func(string) string) (service.PriceProvider, error) {
+	switch source {
+	case "binance":
+		return provider.NewBinanceProvider(tracer), nil
+	case "kraken":
+		return provider.NewKrakenProvider(tracer), nil
+	case "coinbase":
+		coinbase := provider.NewCoinbaseProvider(tracer)
+		if keyName := strings.TrimSpace(getenv("COINBASE_API_KEY_NAME")); keyName != "" {
+			if err := coinbase.SetCredentials(keyName, getenv("COINBASE_API_PRIVATE_KEY")); err != nil {
+				return nil, fmt.Errorf("invalid Coinbase API key: %w", err)
+			}
+		}
+		return coinbase, nil
+	default:
+		coingecko := provider.NewCoinGeckoProvider(tracer)
+		coingecko.SetAPIKey(getenv("COINGECKO_API_KEY"))
+		return coingecko, nil
+	}
+}
+
+// shortHistory returns, per interval, the first candle's open time when it
+// starts more than historyTolerance after from. Kraken only serves its
+// latest 720 candles, and pairs listed after from start at the listing.
+func shortHistory(candles []*domain.Candle, from time.Time) map[string]time.Time {
+	first := make(map[string]time.Time)
+	for _, c := range candles {
+		if t, ok := first[c.Interval]; !ok || c.OpenTime.Before(t) {
+			first[c.Interval] = c.OpenTime
+		}
+	}
+	out := make(map[string]time.Time)
+	for interval, t := range first {
+		if t.Sub(from) > historyTolerance {
+			out[interval] = t
+		}
+	}
+	return out
+}
+
 func defaultBackfillDays(getenv func(string) string) int {
 	for _, key := range []string{"ML_BACKFILL_DAYS", "ML_TRAIN_WINDOW_DAYS"} {
 		v := strings.TrimSpace(getenv(key))
@@ -174,7 +287,7 @@ func normalizeSymbols(raw string) ([]string, error) {
 		if s == "" {
 			continue
 		}
-		if _, ok := domain.CoinGeckoID[s]; !ok {
+		if !domain.IsSupportedSymbol(s) {
 			return nil, fmt.Errorf("unsupported symbol: %s", s)
 		}
 		if _, exists := seen[s]; exists {
@@ -219,3 +332,17 @@ func normalizeIntervals(raw string) ([]string, error) {
 	}
 	return out, nil
 }
+
+// derivedIntervals returns the requested intervals that are rolled up from
+// 1h candles.
+func derivedIntervals(intervals []string) []string {
+	var out []string
+	for _, interval := range intervals {
+		for _, target := range rollup.DefaultTargets {
+			if interval == target {
+				out = append(out, interval)
+			}
+		}
+	}
+	return out
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestDefaultBackfillDays(t *testing.T) {
@@ -124,3 +127,55 @@ func TestDefaultBackfillIntervals(t *testing.T) {
 		t.Fatalf("expected fallback [4h], got %v", got)
 	}
 }
+
+func TestDerivedIntervals(t *testing.T) {
+	if got := derivedIntervals([]string{"1h", "4h", "1d"}); !reflect.DeepEqual(got, []string{"4h", "1d"}) {
+		t.Fatalf("expected [4h 1d], got %v", got)
+	}
+	if got := derivedIntervals([]string{"5m", "1h"}); len(got) != 0 {
+		t.Fatalf("expected nothing to derive, got %v", got)
+	}
+}
+
+func TestBackfillSource(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	tests := []struct {
+		name string
+		flag string
+		env  map[string]string
+		days int
+		want string
+	}{
+		{name: "short range", days: 90, want: "coingecko"},
+		{name: "long range", days: 720, want: "binance"},
+		{name: "coingecko provider long range", env: map[string]string{"PRICE_PROVIDER": "coingecko"}, days: 720, want: "binance"},
+		{name: "exchange provider", env: map[string]string{"PRICE_PROVIDER": "coinbase"}, days: 720, want: "coinbase"},
+		{name: "unknown provider", env: map[string]string{"PRICE_PROVIDER": "bitstamp"}, days: 30, want: "coingecko"},
+		{name: "env override", env: map[string]string{"ML_BACKFILL_SOURCE": "Kraken", "PRICE_PROVIDER": "coinbase"}, days: 30, want: "kraken"},
+		{name: "flag wins", flag: "coingecko", env: map[string]string{"ML_BACKFILL_SOURCE": "kraken"}, days: 720, want: "coingecko"},
+	}
+	for _, tt := range tests {
+		got, err := backfillSource(tt.flag, env(tt.env), tt.days)
+		if err != nil || got != tt.want {
+			t.Fatalf("%s: expected %s, got %s (%v)", tt.name, tt.want, got, err)
+		}
+	}
+	if _, err := backfillSource("bitstamp", env(nil), 30); err == nil {
+		t.Fatal("expected unsupported source error")
+	}
+}
+
+func TestShortHistory(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := []*domain.Candle{
+		{Interval: "1h", OpenTime: from.Add(2 * time.Hour)},
+		{Interval: "1h", OpenTime: from.Add(time.Hour)},
+		{Interval: "4h", OpenTime: from.AddDate(1, 0, 0)},
+	}
+	got := shortHistory(candles, from)
+	if len(got) != 1 || !got["4h"].Equal(from.AddDate(1, 0, 0)) {
+		t.Fatalf("expected only 4h reported short, got %v", got)
+	}
+}
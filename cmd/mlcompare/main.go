@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/features"
+	"bug-free-umbrella/internal/ml/registry"
+	"bug-free-umbrella/internal/ml/training"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const defaultDays = 30
+
+var (
+	loadEnvFunc = godotenv.Load
+	openPool    = pgxpool.New
+)
+
+type options struct {
+	modelKey string
+	versionA int
+	versionB int
+	days     int
+	interval string
+}
+
+func main() {
+	loadEnvFunc()
+
+	opts, err := parseOptions(os.Args[1:], os.Getenv)
+	if err != nil {
+		log.Fatalf("parse options: %v", err)
+	}
+
+	dsn := strings.TrimSpace(os.Getenv("DATABASE_URL"))
+	if dsn == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	pool, err := openPool(ctx, dsn)
+	if err != nil {
+		log.Fatalf("connect postgres: %v", err)
+	}
+	defer pool.Close()
+
+	if err := pool.Ping(ctx); err != nil {
+		log.Fatalf("ping postgres: %v", err)
+	}
+
+	tracer := trace.NewNoopTracerProvider().Tracer("ml-compare")
+	svc := training.NewService(
+		tracer,
+		features.NewRepository(pool, tracer),
+		registry.NewRepository(pool, tracer),
+		training.Config{Interval: opts.interval},
+	)
+
+	now := time.Now().UTC()
+	result, err := svc.CompareVersions(ctx, opts.modelKey, opts.versionA, opts.versionB, now.AddDate(0, 0, -opts.days), now)
+	if err != nil {
+		log.Fatalf("compare %s v%d and v%d: %v", opts.modelKey, opts.versionA, opts.versionB, err)
+	}
+	printComparison(os.Stdout, result)
+}
+
+func parseOptions(args []string, getenv func(string) string) (options, error) {
+	fs := flag.NewFlagSet("mlcompare", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	intervalDefault := strings.TrimSpace(getenv("ML_INTERVAL"))
+	if intervalDefault == "" {
+		intervalDefault = "1h"
+	}
+	modelKey := fs.String("model", common.ModelKeyLogReg, "directional model key to compare (logreg or xgboost)")
+	versionA := fs.Int("a", 0, "first registry version")
+	versionB := fs.Int("b", 0, "second registry version")
+	days := fs.Int("days", defaultDays, "number of days of labeled feature rows to re-score")
+	interval := fs.String("interval", intervalDefault, "feature interval the model was trained on (default from ML_INTERVAL, else 1h)")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+	if *versionA <= 0 || *versionB <= 0 {
+		return options{}, fmt.Errorf("-a and -b must be positive model versions")
+	}
+	if *days <= 0 {
+		return options{}, fmt.Errorf("days must be > 0")
+	}
+	key := strings.TrimSpace(*modelKey)
+	if key != common.ModelKeyLogReg && key != common.ModelKeyXGBoost {
+		return options{}, fmt.Errorf("unsupported model: %s", key)
+	}
+
+	return options{
+		modelKey: key,
+		versionA: *versionA,
+		versionB: *versionB,
+		days:     *days,
+		interval: strings.TrimSpace(*interval),
+	}, nil
+}
+
+func printComparison(w io.Writer, c *training.VersionComparison) {
+	fmt.Fprintf(w, "%s %s: %d labeled rows from %s to %s\n\n",
+		c.ModelKey, c.Interval, c.Samples, c.From.Format("2006-01-02"), c.To.Format("2006-01-02"))
+	fmt.Fprintf(w, "%-8s %-8s %-10s %9s %7s %7s\n", "version", "active", "trained_to", "accuracy", "auc", "brier")
+	for _, s := range []training.VersionScore{c.A, c.B} {
+		active := ""
+		if s.IsActive {
+			active = "yes"
+		}
+		fmt.Fprintf(w, "%-8s %-8s %-10s %8.1f%% %7.3f %7.3f\n",
+			"v"+strconv.Itoa(s.Version), active, s.TrainedTo.Format("2006-01-02"), s.Accuracy*100, s.AUC, s.Brier)
+	}
+	fmt.Fprintf(w, "\ndisagreement: %.1f%% of rows called in opposite directions\n", c.DisagreementRate*100)
+	if c.From.Before(c.A.TrainedTo) || c.From.Before(c.B.TrainedTo) {
+		fmt.Fprintln(w, "note: rows before a version's trained_to were part of its training window and flatter it")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/ml/training"
+)
+
+func TestParseOptions(t *testing.T) {
+	getenv := func(key string) string {
+		if key == "ML_INTERVAL" {
+			return "4h"
+		}
+		return ""
+	}
+	opts, err := parseOptions([]string{"-model", "xgboost", "-a", "3", "-b", "5"}, getenv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.modelKey != "xgboost" || opts.versionA != 3 || opts.versionB != 5 || opts.days != defaultDays || opts.interval != "4h" {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	for _, args := range [][]string{
+		{"-a", "1"},
+		{"-a", "1", "-b", "2", "-days", "0"},
+		{"-model", "iforest_1h", "-a", "1", "-b", "2"},
+	} {
+		if _, err := parseOptions(args, getenv); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestPrintComparison(t *testing.T) {
+	to := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	printComparison(&buf, &training.VersionComparison{
+		ModelKey:         "logreg",
+		Interval:         "1h",
+		From:             to.AddDate(0, 0, -30),
+		To:               to,
+		Samples:          720,
+		A:                training.VersionScore{Version: 3, IsActive: true, TrainedTo: to.AddDate(0, 0, -40), Accuracy: 0.55, AUC: 0.58, Brier: 0.24},
+		B:                training.VersionScore{Version: 4, TrainedTo: to.AddDate(0, 0, -10), Accuracy: 0.57, AUC: 0.61, Brier: 0.23},
+		DisagreementRate: 0.125,
+	})
+	out := buf.String()
+	for _, want := range []string{"720 labeled rows", "v3       yes", "55.0%", "0.610", "disagreement: 12.5%", "flatter it"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
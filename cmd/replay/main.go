@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/config"
+	"bug-free-umbrella/internal/repository"
+	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultDays     = 30
+	defaultLookback = domain.SignalLookbackCandles
+	dateLayout      = "2006-01-02"
+)
+
+var (
+	loadEnvFunc = godotenv.Load
+	openPool    = pgxpool.New
+	nowFunc     = time.Now
+)
+
+type options struct {
+	runID     string
+	from      time.Time
+	to        time.Time
+	symbols   []string
+	intervals []string
+	lookback  int
+	notes     string
+}
+
+func main() {
+	loadEnvFunc()
+
+	opts, err := parseOptions(os.Args[1:], nowFunc().UTC())
+	if err != nil {
+		log.Fatalf("parse options: %v", err)
+	}
+
+	dsn := strings.TrimSpace(os.Getenv("DATABASE_URL"))
+	if dsn == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	pool, err := openPool(ctx, dsn)
+	if err != nil {
+		log.Fatalf("connect postgres: %v", err)
+	}
+	defer pool.Close()
+
+	if err := pool.Ping(ctx); err != nil {
+		log.Fatalf("ping postgres: %v", err)
+	}
+
+	tracer := trace.NewNoopTracerProvider().Tracer("signal-replay")
+	candleRepo := repository.NewCandleRepository(pool, tracer)
+	replayRepo := repository.NewReplayRepository(pool, tracer)
+	engine := signalengine.NewEngine(nil)
+	// Replays are compared with live signals, so they detect Ichimoku with
+	// the live periods.
+	engine.SetIchimokuPeriods(config.LoadIchimokuPeriods())
+
+	if err := replayRepo.CreateRun(ctx, domain.ReplayRun{
+		ID:        opts.runID,
+		From:      opts.from,
+		To:        opts.to,
+		Symbols:   opts.symbols,
+		Intervals: opts.intervals,
+		Notes:     opts.notes,
+	}); err != nil {
+		log.Fatalf("create replay run: %v", err)
+	}
+
+	log.Printf(
+		"starting signal replay: run=%s from=%s to=%s symbols=%s intervals=%s lookback=%d",
+		opts.runID,
+		opts.from.Format(time.RFC3339),
+		opts.to.Format(time.RFC3339),
+		strings.Join(opts.symbols, ","),
+		strings.Join(opts.intervals, ","),
+		opts.lookback,
+	)
+
+	for _, symbol := range opts.symbols {
+		for _, interval := range opts.intervals {
+			warmup := time.Duration(opts.lookback) * domain.IntervalDuration(interval)
+			candles, err := candleRepo.GetCandlesInRange(ctx, symbol, interval, opts.from.Add(-warmup), opts.to)
+			if err != nil {
+				log.Fatalf("load candles for %s %s: %v", symbol, interval, err)
+			}
+			signals := engine.Replay(candles, opts.lookback, opts.from, opts.to)
+			if err := replayRepo.InsertSignals(ctx, opts.runID, signals); err != nil {
+				log.Fatalf("store replayed signals for %s %s: %v", symbol, interval, err)
+			}
+			log.Printf("replayed %s %s: %d candles, %d signals", symbol, interval, len(candles), len(signals))
+		}
+	}
+
+	count, err := replayRepo.FinishRun(ctx, opts.runID)
+	if err != nil {
+		log.Fatalf("finish replay run: %v", err)
+	}
+
+	comparison, err := replayRepo.CompareWithLive(ctx, opts.runID, signalengine.EngineIndicators)
+	if err != nil {
+		log.Fatalf("compare replay with live signals: %v", err)
+	}
+
+	log.Printf("replay complete: run=%s signals=%d", opts.runID, count)
+	fmt.Printf("%-14s %8s %12s %10s\n", "INDICATOR", "MATCHED", "REPLAY_ONLY", "LIVE_ONLY")
+	for _, c := range comparison {
+		fmt.Printf("%-14s %8d %12d %10d\n", c.Indicator, c.Matched, c.ReplayOnly, c.LiveOnly)
+	}
+}
+
+func parseOptions(args []string, now time.Time) (options, error) {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	runID := fs.String("run-id", "replay-"+now.Format("20060102T150405Z"), "identifier the replayed signals are stored under; reusing one replaces that run")
+	fromRaw := fs.String("from", now.AddDate(0, 0, -defaultDays).Format(dateLayout), "start of the replay range (YYYY-MM-DD or RFC3339)")
+	toRaw := fs.String("to", "", "end of the replay range (YYYY-MM-DD or RFC3339, default now)")
+	symbolsRaw := fs.String("symbols", strings.Join(domain.SupportedSymbols(), ","), "comma-separated symbols to replay")
+	intervalsRaw := fs.String("intervals", strings.Join(domain.SupportedIntervals, ","), "comma-separated candle intervals to replay")
+	lookback := fs.Int("lookback", defaultLookback, "candles the engine sees at each step")
+	notes := fs.String("notes", "", "free-form description stored with the run")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	id := strings.TrimSpace(*runID)
+	if id == "" {
+		return options{}, fmt.Errorf("run-id cannot be empty")
+	}
+	if *lookback <= 0 {
+		return options{}, fmt.Errorf("lookback must be > 0")
+	}
+
+	from, err := parseTime(*fromRaw)
+	if err != nil {
+		return options{}, fmt.Errorf("from: %w", err)
+	}
+	to := now
+	if strings.TrimSpace(*toRaw) != "" {
+		to, err = parseTime(*toRaw)
+		if err != nil {
+			return options{}, fmt.Errorf("to: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return options{}, fmt.Errorf("from must be before to")
+	}
+
+	symbols, err := normalizeSymbols(*symbolsRaw)
+	if err != nil {
+		return options{}, err
+	}
+	intervals, err := normalizeIntervals(*intervalsRaw)
+	if err != nil {
+		return options{}, err
+	}
+
+	return options{
+		runID:     id,
+		from:      from,
+		to:        to,
+		symbols:   symbols,
+		intervals: intervals,
+		lookback:  *lookback,
+		notes:     strings.TrimSpace(*notes),
+	}, nil
+}
+
+func parseTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", raw)
+	}
+	return t.UTC(), nil
+}
+
+func normalizeSymbols(raw string) ([]string, error) {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, p := range strings.Split(raw, ",") {
+		s := strings.ToUpper(strings.TrimSpace(p))
+		if s == "" {
+			continue
+		}
+		if !domain.IsSupportedSymbol(s) {
+			return nil, fmt.Errorf("unsupported symbol: %s", s)
+		}
+		if _, exists := seen[s]; exists {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("symbols cannot be empty")
+	}
+	return out, nil
+}
+
+func normalizeIntervals(raw string) ([]string, error) {
+	allowed := make(map[string]struct{}, len(domain.SupportedIntervals))
+	for _, interval := range domain.SupportedIntervals {
+		allowed[interval] = struct{}{}
+	}
+	seen := make(map[string]struct{})
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		interval := strings.TrimSpace(part)
+		if interval == "" {
+			continue
+		}
+		if _, ok := allowed[interval]; !ok {
+			return nil, fmt.Errorf("unsupported interval: %s", interval)
+		}
+		if _, exists := seen[interval]; exists {
+			continue
+		}
+		seen[interval] = struct{}{}
+		out = append(out, interval)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("intervals cannot be empty")
+	}
+	return out, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestParseOptionsDefaults(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	opts, err := parseOptions(nil, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.runID != "replay-20260310T120000Z" {
+		t.Fatalf("unexpected run id %q", opts.runID)
+	}
+	if !opts.from.Equal(time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC)) || !opts.to.Equal(now) {
+		t.Fatalf("unexpected range %s - %s", opts.from, opts.to)
+	}
+	if opts.lookback != defaultLookback {
+		t.Fatalf("expected lookback %d, got %d", defaultLookback, opts.lookback)
+	}
+	if !reflect.DeepEqual(opts.symbols, domain.SupportedSymbols()) || !reflect.DeepEqual(opts.intervals, domain.SupportedIntervals) {
+		t.Fatalf("unexpected defaults: %v %v", opts.symbols, opts.intervals)
+	}
+}
+
+func TestParseOptionsFlags(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	opts, err := parseOptions([]string{
+		"--run-id", "rsi-v2",
+		"--from", "2026-01-01",
+		"--to", "2026-02-01T06:00:00Z",
+		"--symbols", "btc,ETH,btc",
+		"--intervals", "1h,4h",
+		"--lookback", "100",
+		"--notes", " new rsi bands ",
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.runID != "rsi-v2" || opts.lookback != 100 || opts.notes != "new rsi bands" {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+	if !opts.to.Equal(time.Date(2026, 2, 1, 6, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected to %s", opts.to)
+	}
+	if !reflect.DeepEqual(opts.symbols, []string{"BTC", "ETH"}) || !reflect.DeepEqual(opts.intervals, []string{"1h", "4h"}) {
+		t.Fatalf("unexpected filters: %v %v", opts.symbols, opts.intervals)
+	}
+}
+
+func TestParseOptionsRejectsInvalid(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cases := [][]string{
+		{"--from", "2026-03-11"},
+		{"--from", "yesterday"},
+		{"--lookback", "0"},
+		{"--run-id", " "},
+		{"--symbols", "XYZ"},
+		{"--intervals", "2h"},
+	}
+	for _, args := range cases {
+		if _, err := parseOptions(args, now); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"log"
 	"net/http"
 	"os"
@@ -11,12 +12,15 @@ import (
 	"time"
 
 	"bug-free-umbrella/internal/advisor"
+	"bug-free-umbrella/internal/alerttemplate"
 	"bug-free-umbrella/internal/bot"
 	"bug-free-umbrella/internal/cache"
 	"bug-free-umbrella/internal/chart"
 	"bug-free-umbrella/internal/config"
 	"bug-free-umbrella/internal/db"
+	"bug-free-umbrella/internal/events"
 	"bug-free-umbrella/internal/handler"
+	"bug-free-umbrella/internal/httpclient"
 	"bug-free-umbrella/internal/job"
 	"bug-free-umbrella/internal/marketintel"
 	"bug-free-umbrella/internal/ml/ensemble"
@@ -24,17 +28,26 @@ import (
 	"bug-free-umbrella/internal/ml/inference"
 	"bug-free-umbrella/internal/ml/predictions"
 	"bug-free-umbrella/internal/ml/registry"
+	"bug-free-umbrella/internal/ml/similarity"
 	"bug-free-umbrella/internal/ml/training"
 	"bug-free-umbrella/internal/provider"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/rollup"
+	"bug-free-umbrella/internal/sandbox"
+	"bug-free-umbrella/internal/secrets"
 	"bug-free-umbrella/internal/service"
 	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/internal/startup"
+	"bug-free-umbrella/internal/storage"
+	"bug-free-umbrella/internal/storage/sqlite"
+	"bug-free-umbrella/internal/synthetic"
 	"bug-free-umbrella/internal/webconsole"
+	"bug-free-umbrella/internal/workqueue"
+	"bug-free-umbrella/pkg/domain"
 	"bug-free-umbrella/pkg/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
@@ -44,18 +57,53 @@ import (
 )
 
 var (
-	loadEnvFunc              = godotenv.Load
+	loadEnvFunc              = config.LoadEnvFile
+	loadSecretsFunc          = secrets.Bootstrap
 	loadConfigFunc           = config.Load
+	configureHTTPFunc        = httpclient.Configure
 	initPostgresFunc         = db.InitPostgres
 	initRedisFunc            = cache.InitRedis
 	initTracerFunc           = tracing.InitTracer
+	initMeterFunc            = tracing.InitMeter
 	newCandleRepoFunc        = repository.NewCandleRepository
+	detectTimescaleFunc      = repository.DetectTimescale
 	newSignalRepoFunc        = repository.NewSignalRepository
 	newSignalImageRepoFunc   = repository.NewSignalImageRepository
 	newBacktestRepoFunc      = repository.NewBacktestRepository
-	newCoinGeckoProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
-		return provider.NewCoinGeckoProvider(tracer)
+	newAPIKeyRepoFunc        = repository.NewAPIKeyRepository
+	newAuditRepoFunc         = repository.NewAuditRepository
+	newAlertDeliveryRepoFunc = repository.NewAlertDeliveryRepository
+	newWebhookRepoFunc       = repository.NewWebhookRepository
+	newAuditServiceFunc      = service.NewAuditService
+	newAPIKeyServiceFunc     = service.NewAPIKeyService
+	newWebhookServiceFunc    = service.NewWebhookService
+	newStreamPublisherFunc   = events.NewStreamPublisher
+	newCoinGeckoProviderFunc = func(tracer trace.Tracer, apiKey string) service.PriceProvider {
+		coingecko := provider.NewCoinGeckoProvider(tracer)
+		coingecko.SetAPIKey(apiKey)
+		return coingecko
 	}
+	newBinanceProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
+		return provider.NewBinanceProvider(tracer)
+	}
+	newKrakenProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
+		return provider.NewKrakenProvider(tracer)
+	}
+	newCoinbaseProviderFunc = func(tracer trace.Tracer, keyName, privateKey string) service.PriceProvider {
+		coinbase := provider.NewCoinbaseProvider(tracer)
+		if keyName != "" {
+			if err := coinbase.SetCredentials(keyName, privateKey); err != nil {
+				log.Fatalf("invalid Coinbase API key: %v", err)
+			}
+		}
+		return coinbase
+	}
+	newDemoProviderFunc = func(seed int64, history time.Duration) service.PriceProvider {
+		return synthetic.NewProvider(seed, history, nil)
+	}
+	startDemoStorageFunc           = sandbox.StartStorage
+	openSQLiteFunc                 = sqlite.Open
+	newDemoLLMClientFunc           = func() advisor.LLMClient { return sandbox.NewLLMClient() }
 	newSignalEngineFunc            = signalengine.NewEngine
 	newPriceServiceFunc            = service.NewPriceService
 	newSignalServiceWithImagesFunc = service.NewSignalServiceWithImages
@@ -67,6 +115,10 @@ var (
 	startPollerFunc                = func(p *job.PricePoller, ctx context.Context) { go p.Start(ctx) }
 	startSignalPollerFunc          = func(p *job.SignalPoller, ctx context.Context) { go p.Start(ctx) }
 	startSignalImageJobFunc        = func(j *job.SignalImageMaintenance, ctx context.Context) { go j.Start(ctx) }
+	newAlertRedeliveryJobFunc      = job.NewAlertRedelivery
+	startAlertRedeliveryJobFunc    = func(j *job.AlertRedelivery, ctx context.Context) { go j.Start(ctx) }
+	newConversationExpiryJobFunc   = job.NewConversationExpiry
+	startConversationExpiryJobFunc = func(j *job.ConversationExpiry, ctx context.Context) { go j.Start(ctx) }
 	newConversationRepoFunc        = repository.NewConversationRepository
 	newOpenAIClientFunc            = advisor.NewOpenAIClient
 	newAdvisorServiceFunc          = advisor.NewAdvisorService
@@ -98,16 +150,62 @@ var (
 func main() {
 	loadEnvFunc()
 
-	cfg := loadConfigFunc()
-
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Pull managed secrets into the environment before reading config
+	secretsWatcher, err := loadSecretsFunc(ctx)
+	if err != nil {
+		log.Fatalf("failed to load secrets: %v", err)
+	}
+	for _, key := range secrets.ManagedKeys {
+		if secretsWatcher.Get(key) != "" {
+			config.MarkSource(config.SourceSecrets, key)
+		}
+	}
+
+	cfg := loadConfigFunc()
+	if err := configureHTTPFunc(cfg.OutboundHTTP); err != nil {
+		log.Fatalf("invalid outbound HTTP settings: %v", err)
+	}
+	alertText, err := alerttemplate.Load(cfg.AlertTemplate, cfg.AlertTemplateFile, cfg.AlertMessagesFile)
+	if err != nil {
+		log.Fatalf("invalid alert template: %v", err)
+	}
+
+	// Demo mode runs embedded stores for whatever is not configured
+	embedPostgres := cfg.DatabaseURL == "" && cfg.StorageBackend != "sqlite"
+	if cfg.DemoMode && (embedPostgres || cfg.RedisURL == "") {
+		demoStorage, err := startDemoStorageFunc(ctx, sandbox.StorageConfig{
+			Postgres: embedPostgres,
+			Redis:    cfg.RedisURL == "",
+			DataDir:  cfg.DemoDataDir,
+		})
+		if err != nil {
+			log.Fatalf("failed to start demo storage: %v", err)
+		}
+		defer demoStorage.Stop()
+		if embedPostgres {
+			cfg.DatabaseURL = demoStorage.DatabaseURL
+		}
+		if cfg.RedisURL == "" {
+			cfg.RedisURL = demoStorage.RedisURL
+		}
+	}
+
 	// Init Postgres and Redis
 	os.Setenv("DATABASE_URL", cfg.DatabaseURL)
 	os.Setenv("REDIS_URL", cfg.RedisURL)
-	initPostgresFunc(ctx)
-	initRedisFunc(ctx)
+	initCtx, cancelInit := context.WithTimeout(ctx, time.Duration(cfg.StartupConnectTimeoutSecs)*time.Second)
+	initPostgresFunc(initCtx)
+	initRedisFunc(initCtx)
+	cancelInit()
+	secretsWatcher.OnChange("DATABASE_URL", func(dsn string) {
+		if err := db.SetDSN(dsn); err != nil {
+			log.Printf("ignoring rotated DATABASE_URL: %v", err)
+		}
+	})
+	go secretsWatcher.Start(ctx)
 
 	// Init tracing
 	tp, tracer, err := initTracerFunc(ctx)
@@ -119,49 +217,476 @@ func main() {
 			log.Printf("error shutting down tracer provider: %v", err)
 		}
 	}()
+	mp, err := initMeterFunc(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize meter: %v", err)
+	}
+	defer func() {
+		if err := mp.Shutdown(ctx); err != nil {
+			log.Printf("error shutting down meter provider: %v", err)
+		}
+	}()
+
+	// Background jobs start once Postgres and Redis answer, so a slow
+	// recovery at boot delays them instead of leaving them failing.
+	var jobDeps []startup.Dependency
+	if db.Pool != nil {
+		jobDeps = append(jobDeps, startup.Dependency{Name: "postgres", Check: db.Ping})
+	}
+	if cache.Client != nil {
+		jobDeps = append(jobDeps, startup.Dependency{Name: "redis", Check: cache.Ping})
+	}
+	jobGate := startup.NewGate(jobDeps...)
+	jobGate.Start(ctx)
+
+	// SQLite replaces Postgres for candles, signals and ML storage
+	var sqliteDB *sql.DB
+	if cfg.StorageBackend == "sqlite" {
+		sqliteDB, err = openSQLiteFunc(ctx, cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("failed to open SQLite database: %v", err)
+		}
+		defer sqliteDB.Close()
+		log.Printf("Using SQLite storage at %s (signal images disabled)", cfg.SQLitePath)
+	}
 
 	// Create repositories
-	candleRepo := newCandleRepoFunc(db.Pool, tracer)
-	signalRepo := newSignalRepoFunc(db.Pool, tracer)
-	signalImageRepo := newSignalImageRepoFunc(db.Pool, tracer)
+	var candleRepo storage.CandleStore
+	var signalRepo storage.SignalStore
+	var signalImageRepo service.SignalImageRepository
+	if sqliteDB != nil {
+		candleRepo = sqlite.NewCandleRepository(sqliteDB, tracer)
+		signalRepo = sqlite.NewSignalRepository(sqliteDB, tracer)
+	} else {
+		pgCandleRepo := newCandleRepoFunc(db.Pool, tracer)
+		if db.Pool != nil && detectTimescaleFunc(ctx, db.Pool) {
+			pgCandleRepo.SetTimescale(true)
+			log.Println("TimescaleDB detected: candle aggregation uses time_bucket")
+		}
+		candleRepo = pgCandleRepo
+		signalRepo = newSignalRepoFunc(db.Pool, tracer)
+		signalImageRepo = newSignalImageRepoFunc(db.Pool, tracer)
+	}
 	backtestRepo := newBacktestRepoFunc(db.Pool, tracer)
+	var auditService *service.AuditService
+	var apiKeyService *service.APIKeyService
+	var webhookService *service.WebhookService
+	if db.Pool != nil {
+		auditService = newAuditServiceFunc(tracer, newAuditRepoFunc(db.Pool, tracer))
+		apiKeyService = newAPIKeyServiceFunc(tracer, newAPIKeyRepoFunc(db.Pool, tracer), auditService)
+		webhookService = newWebhookServiceFunc(tracer, newWebhookRepoFunc(db.Pool, tracer), auditService)
+		webhookService.SetAlertText(alertText)
+	}
+	// The maintenance switch is shared through Postgres so the SSH TUI can
+	// flip it too; without a database it only lives in this process.
+	var maintenanceStore service.MaintenanceStore
+	if db.Pool != nil {
+		maintenanceStore = repository.NewMaintenanceRepository(db.Pool, tracer)
+	}
+	maintenanceService := service.NewMaintenanceService(tracer, maintenanceStore, auditService)
+	// The symbol registry is shared through Postgres too; without a database
+	// the built-in symbols are tracked.
+	domain.SetConfiguredPauses(cfg.SymbolPauses)
+	var symbolRegistry *service.SymbolRegistryService
+	if db.Pool != nil {
+		symbolRegistry = service.NewSymbolRegistryService(tracer, repository.NewAssetRepository(db.Pool, tracer), auditService)
+		if err := symbolRegistry.Reload(ctx); err != nil {
+			log.Printf("Warning: stored symbol registry unavailable, tracking built-in symbols: %v", err)
+		}
+	}
 
 	// Create providers and services
-	cgProvider := newCoinGeckoProviderFunc(tracer)
-	priceService := newPriceServiceFunc(tracer, cgProvider, candleRepo, cache.Client)
+	coingeckoQuota := provider.CoinGeckoQuota()
+	coingeckoQuota.SetLimits(cfg.CoinGeckoQuotaPerMin, cfg.CoinGeckoQuotaPerDay)
+	var marketProvider service.PriceProvider
+	if cfg.DemoMode {
+		marketProvider = newDemoProviderFunc(cfg.DemoSeed, time.Duration(cfg.MLTrainWindowDays)*24*time.Hour)
+		log.Println("Demo mode enabled: serving synthetic market data")
+	} else if cfg.PriceProvider == "binance" {
+		marketProvider = newBinanceProviderFunc(tracer)
+	} else if cfg.PriceProvider == "kraken" {
+		marketProvider = newKrakenProviderFunc(tracer)
+	} else if cfg.PriceProvider == "coinbase" {
+		marketProvider = newCoinbaseProviderFunc(tracer, cfg.CoinbaseAPIKeyName, cfg.CoinbaseAPIPrivateKey)
+	} else {
+		marketProvider = newCoinGeckoProviderFunc(tracer, cfg.CoinGeckoAPIKey)
+	}
+	priceService := newPriceServiceFunc(tracer, marketProvider, candleRepo, cache.Client)
+	// Quotes in EUR, GBP and JPY use the ECB rates, fetched on first use.
+	if cfg.DemoMode {
+		priceService.SetFXSource(synthetic.NewFXRates(cfg.DemoSeed, nil))
+	} else {
+		priceService.SetFXSource(provider.NewFrankfurterProvider(tracer))
+	}
+	if cfg.DemoMode && (db.Pool != nil || sqliteDB != nil) {
+		seedDemoHistory(ctx, priceService, cfg.MLTrainWindowDays)
+	}
+	var priceQuorum *service.PriceQuorum
+	if len(cfg.PriceQuorumProviders) > 0 && !cfg.DemoMode {
+		var discrepancies service.PriceDiscrepancyStore
+		if db.Pool != nil {
+			discrepancies = repository.NewPriceDiscrepancyRepository(db.Pool, tracer)
+		}
+		priceQuorum = service.NewPriceQuorum(tracer, cfg.PriceProvider, cfg.PriceQuorumDeviationPct, discrepancies,
+			time.Duration(cfg.PriceQuorumRetentionDays)*24*time.Hour)
+		for _, name := range cfg.PriceQuorumProviders {
+			switch name {
+			case "binance":
+				priceQuorum.AddSource(name, newBinanceProviderFunc(tracer))
+			case "kraken":
+				priceQuorum.AddSource(name, newKrakenProviderFunc(tracer))
+			case "coinbase":
+				priceQuorum.AddSource(name, newCoinbaseProviderFunc(tracer, cfg.CoinbaseAPIKeyName, cfg.CoinbaseAPIPrivateKey))
+			default:
+				priceQuorum.AddSource(name, newCoinGeckoProviderFunc(tracer, cfg.CoinGeckoAPIKey))
+			}
+		}
+		priceService.SetQuorum(priceQuorum)
+		log.Printf("Price quorum enabled: %s cross-checked against %s, %.2f%% deviation",
+			cfg.PriceProvider, strings.Join(cfg.PriceQuorumProviders, ", "), cfg.PriceQuorumDeviationPct)
+	}
 	signalEngine := newSignalEngineFunc(nil)
+	riskOverrides := cfg.SignalRiskOverrides
+	if db.Pool != nil {
+		if riskOverrides, err = signalengine.LoadRiskOverrides(ctx, riskOverrides, repository.NewRiskOverrideRepository(db.Pool, tracer)); err != nil {
+			log.Printf("Warning: stored signal risk overrides unavailable, using SIGNAL_RISK_OVERRIDES only: %v", err)
+		}
+	}
+	signalEngine.SetRiskOverrides(riskOverrides)
+	signalEngine.SetIchimokuPeriods(cfg.IchimokuPeriods)
 	chartRenderer := newChartRendererFunc()
+	chartRenderer.SetFormat(chart.ImageFormat(cfg.ChartImageFormat))
+	chartRenderer.SetIchimokuPeriods(cfg.IchimokuPeriods)
+	// The event calendar marks charts and /signals; the market intel job
+	// re-reads it each cycle and stores it for the ML features.
+	var marketEvents *marketintel.Calendar
+	if cfg.MarketEventsBuiltin || cfg.MarketEventsFile != "" {
+		marketEvents = marketintel.NewCalendar(cfg.MarketEventsFile, cfg.MarketEventsBuiltin)
+		if err := marketEvents.Load(); err != nil {
+			log.Printf("Warning: market event calendar: %v", err)
+		}
+		chartRenderer.SetEventSource(marketEvents)
+	}
 	signalService := newSignalServiceWithImagesFunc(tracer, candleRepo, signalRepo, signalEngine, signalImageRepo, chartRenderer)
+	signalService.SetImageOptions(service.SignalImageOptions{
+		TTL:        time.Duration(cfg.SignalImageTTLHours) * time.Hour,
+		RetryDelay: time.Duration(cfg.SignalImageRetryDelaySecs) * time.Second,
+		MaxRetries: cfg.SignalImageMaxRetries,
+	})
+	// The work queue takes chart renders and Telegram alerts off the
+	// pollers' goroutines onto a Redis stream, so a restart does not lose
+	// them.
+	var workQueue *workqueue.Queue
+	if cfg.WorkQueueEnabled {
+		if cache.Client == nil {
+			log.Println("Work queue disabled: Redis is not configured")
+		} else {
+			consumer, _ := os.Hostname()
+			if consumer == "" {
+				consumer = "server"
+			}
+			workQueue = workqueue.New(cache.Client, cfg.WorkQueueStream, consumer)
+			workQueue.SetClaimAfter(time.Duration(cfg.WorkQueueClaimAfterSecs) * time.Second)
+			workQueue.SetMaxDeliveries(cfg.WorkQueueMaxDeliveries)
+			signalService.SetRenderQueue(workqueue.NewSignalImages(workQueue, signalService))
+			log.Printf("Work queue enabled stream=%s consumer=%s", cfg.WorkQueueStream, consumer)
+		}
+	}
 
 	// Create conversation repository and advisor
 	convRepo := newConversationRepoFunc(db.Pool, tracer)
 	var advisorSvc *advisor.AdvisorService
+	var llmClient advisor.LLMClient
 	if cfg.OpenAIAPIKey != "" {
-		llmClient := newOpenAIClientFunc(cfg.OpenAIAPIKey)
+		llmClient = newOpenAIClientFunc(cfg.OpenAIAPIKey)
+		if rotator, ok := llmClient.(advisor.APIKeyRotator); ok {
+			secretsWatcher.OnChange("OPENAI_API_KEY", rotator.SetAPIKey)
+		}
+	} else if cfg.DemoMode {
+		llmClient = newDemoLLMClientFunc()
+	}
+	if llmClient != nil {
 		advisorSvc = newAdvisorServiceFunc(tracer, llmClient, priceService, signalService,
 			convRepo, cfg.OpenAIModel, cfg.AdvisorMaxHistory)
+		if advisorSvc != nil {
+			advisorSvc.SetAnswerCacheTTL(time.Duration(cfg.AdvisorCacheTTLSecs) * time.Second)
+			guardMode, _ := advisor.ParseGuardrailMode(cfg.AdvisorGuardrails)
+			advisorSvc.SetGuardrails(advisor.GuardrailPolicy{
+				Mode:              guardMode,
+				PriceTolerancePct: cfg.AdvisorPriceTolerancePct,
+			})
+		}
 		log.Println("Advisor service enabled")
 	}
+	if db.Pool != nil {
+		idleFor := time.Duration(cfg.AdvisorConversationIdleHours) * time.Hour
+		expiryJob := newConversationExpiryJobFunc(tracer, convRepo, idleFor)
+		jobGate.Go(ctx, "conversation expiry", func() { startConversationExpiryJobFunc(expiryJob, ctx) })
+	}
 
-	// Start Telegram bot
-	os.Setenv("TELEGRAM_BOT_TOKEN", cfg.TelegramBotToken)
-	alertDispatcher := startTelegramBotFunc(priceService, signalService, advisorSvc)
+	// The market intel job keeps the Fear & Greed history that /signals and
+	// the ML ensemble read.
+	var marketIntelRepo *marketintel.Repository
+	if cfg.MarketIntelEnabled && db.Pool != nil {
+		marketIntelRepo = marketintel.NewRepository(db.Pool, tracer)
+	}
 
-	// Start background pollers (stopped by ctx cancel)
+	// Start Telegram bot; demo mode logs alerts instead
+	var alertSink job.SignalAlertSink
+	var broadcaster handler.Broadcaster
+	var deadLetters handler.AlertDeadLetters
+	if cfg.DemoMode {
+		demoAlerts := sandbox.NewAlertLog()
+		demoAlerts.SetAlertText(alertText)
+		alertSink, broadcaster = demoAlerts, demoAlerts
+		log.Println("Demo mode: Telegram bot disabled, alerts are logged")
+	} else {
+		os.Setenv("TELEGRAM_BOT_TOKEN", cfg.TelegramBotToken)
+		// Self-service /token keys need the api_keys table.
+		var tokenIssuer bot.APITokenIssuer
+		if apiKeyService != nil {
+			tokenIssuer = apiKeyService
+		}
+		var botAdvisor bot.Advisor
+		if advisorSvc != nil {
+			botAdvisor = advisorSvc
+		}
+		var fearGreed bot.FearGreedReader
+		if marketIntelRepo != nil {
+			fearGreed = marketIntelRepo
+		}
+		var events bot.EventCalendar
+		if marketEvents != nil {
+			events = marketEvents
+		}
+		alertDispatcher := startTelegramBotFunc(priceService, signalService, botAdvisor, tokenIssuer, fearGreed, events, bot.RateLimits{
+			ChatCommandsPerMinute: cfg.TelegramChatCommandsPerMin,
+			SendsPerSecond:        cfg.TelegramSendsPerSec,
+		})
+		alertSink = alertDispatcher
+		if alertDispatcher != nil && workQueue != nil {
+			alertSink = workqueue.NewSignalAlerts(workQueue, alertDispatcher)
+		}
+		if alertDispatcher != nil {
+			alertDispatcher.SetAlertText(alertText)
+			broadcaster = alertDispatcher
+			// Persist deliveries so failed sends are retried and end up
+			// as dead letters instead of being dropped.
+			if db.Pool != nil {
+				alertDispatcher.SetDeliveryStore(newAlertDeliveryRepoFunc(db.Pool, tracer))
+				deadLetters = alertDispatcher
+				redelivery := newAlertRedeliveryJobFunc(tracer, alertDispatcher)
+				redelivery.SetPauser(maintenanceService)
+				jobGate.Go(ctx, "alert redelivery", func() { startAlertRedeliveryJobFunc(redelivery, ctx) })
+			}
+		}
+		secretsWatcher.OnChange("TELEGRAM_BOT_TOKEN", func(string) {
+			log.Println("TELEGRAM_BOT_TOKEN rotated; restart required for the bot to use it")
+		})
+	}
+
+	// Start background pollers (stopped by ctx cancel). Unless disabled, the
+	// price poller publishes candle-closed events that drive signal and ML
+	// generation in place of their timers.
+	candleEvents := events.NewBus()
+	defer candleEvents.Close()
+	// Relay candle closes and new signals to Redis so SSH sessions, which
+	// run in another process, update without waiting for their poll.
+	if cache.Client != nil {
+		relay := events.NewRedisRelay(cache.Client)
+		go relay.RelayCandles(ctx, candleEvents.Subscribe("redis-relay", 256))
+		if alertSink != nil {
+			alertSink = job.SignalAlertSinks{alertSink, relay}
+		} else {
+			alertSink = relay
+		}
+	}
+	// The overview is cached briefly and dropped whenever the signal poller
+	// or ML inference stores something new.
+	overviewService := service.NewOverviewService(tracer, priceService, signalService)
+	if cache.Client != nil {
+		overviewService.SetCache(cache.Client)
+		if alertSink != nil {
+			alertSink = job.SignalAlertSinks{alertSink, overviewService}
+		} else {
+			alertSink = overviewService
+		}
+	}
+	// Signal webhooks are managed at runtime through /api/admin/webhooks.
+	if webhookService != nil {
+		if alertSink != nil {
+			alertSink = job.SignalAlertSinks{alertSink, webhookService}
+		} else {
+			alertSink = webhookService
+		}
+	}
+	// New signals and predictions are exported to NATS or Kafka for
+	// downstream consumers that should not poll the API.
+	var streamExporter *events.StreamExporter
+	if cfg.StreamBackend != "" {
+		streamPublisher, err := newStreamPublisherFunc(cfg.StreamBackend, cfg.StreamURL)
+		if err != nil {
+			log.Printf("Stream export disabled: %v", err)
+		} else {
+			streamExporter = events.NewStreamExporter(streamPublisher, events.StreamSubjects{
+				Signals:     cfg.StreamSignalsSubject,
+				Predictions: cfg.StreamPredictionsSubject,
+			}, 0)
+			go streamExporter.Run(ctx)
+			if alertSink != nil {
+				alertSink = job.SignalAlertSinks{alertSink, streamExporter}
+			} else {
+				alertSink = streamExporter
+			}
+			log.Printf("Stream export enabled backend=%s signals=%s predictions=%s", cfg.StreamBackend, cfg.StreamSignalsSubject, cfg.StreamPredictionsSubject)
+		}
+	}
+	if alertSink != nil {
+		alertSink = job.PausableAlertSink{Sink: alertSink, Pauser: maintenanceService}
+	}
+	if workQueue != nil {
+		jobGate.Go(ctx, "work queue", func() { go workQueue.Run(ctx) })
+		jobGate.Go(ctx, "work queue monitor", func() { go workQueue.MonitorPending(ctx, 0) })
+	}
 	poller := newPricePollerFunc(tracer, priceService, cfg.CoinGeckoPollSecs)
-	startPollerFunc(poller, ctx)
-	signalPoller := newSignalPollerFunc(tracer, signalService, alertDispatcher)
-	startSignalPollerFunc(signalPoller, ctx)
+	if poller != nil {
+		poller.SetPauser(maintenanceService)
+		poller.SetMinInterval(time.Duration(cfg.CoinGeckoPollMinSecs) * time.Second)
+		poller.SetVolatilitySource(priceService)
+		if !cfg.DemoMode && cfg.PriceProvider == "coingecko" {
+			poller.SetQuotaBudget(coingeckoQuota)
+		}
+		if db.Pool != nil || sqliteDB != nil {
+			poller.SetCandleDeriver(rollup.NewDeriver(candleRepo))
+		}
+		if cfg.CandleEventsEnabled {
+			poller.SetCandleEvents(priceService, candleEvents)
+		}
+	}
+	// The WebSocket stream bypasses the HTTP clients, so replay mode would
+	// still reach the network through it.
+	replaying := cfg.OutboundHTTP.RecordMode == httpclient.Replay
+	if cfg.PriceStreamEnabled && replaying {
+		log.Println("Price stream disabled: provider responses are replayed")
+	}
+	if cfg.PriceStreamEnabled && !cfg.DemoMode && !replaying {
+		priceStream := job.NewPriceStreamJob(provider.NewBinanceStream(tracer, cfg.PriceStreamURL), priceService)
+		priceStream.SetPauser(maintenanceService)
+		if poller != nil {
+			poller.SetPriceStream(priceStream)
+		}
+		jobGate.Go(ctx, "price stream", func() { go priceStream.Start(ctx) })
+		log.Println("Price stream enabled: Binance tickers update the price cache")
+	}
+	var orderBookRepo *repository.OrderBookRepository
+	var orderBookService *service.OrderBookService
+	if cfg.OrderBookEnabled && !cfg.DemoMode && db.Pool != nil {
+		orderBookRepo = repository.NewOrderBookRepository(db.Pool, tracer)
+		orderBookService = service.NewOrderBookService(tracer, provider.NewBinanceProvider(tracer), orderBookRepo, cfg.OrderBookDepthBandPct)
+		orderBookPoller := job.NewSnapshotPoller(tracer, "orderbook", orderBookService,
+			time.Duration(cfg.OrderBookPollSecs)*time.Second,
+			time.Duration(cfg.OrderBookRetentionDays)*24*time.Hour)
+		orderBookPoller.SetPauser(maintenanceService)
+		jobGate.Go(ctx, "order book poller", func() { go orderBookPoller.Start(ctx) })
+		log.Println("Order book snapshots enabled: Binance depth feeds /api/orderbook and ML features")
+	}
+	var derivativesRepo *repository.DerivativesRepository
+	if cfg.DerivativesEnabled && !cfg.DemoMode && db.Pool != nil {
+		derivativesRepo = repository.NewDerivativesRepository(db.Pool, tracer)
+		derivativesService := service.NewDerivativesService(tracer, provider.NewBinanceFuturesProvider(tracer), derivativesRepo)
+		derivativesPoller := job.NewSnapshotPoller(tracer, "derivatives", derivativesService,
+			time.Duration(cfg.DerivativesPollSecs)*time.Second,
+			time.Duration(cfg.DerivativesRetentionDays)*24*time.Hour)
+		derivativesPoller.SetPauser(maintenanceService)
+		jobGate.Go(ctx, "derivatives poller", func() { go derivativesPoller.Start(ctx) })
+		log.Println("Derivatives snapshots enabled: Binance funding and open interest feed ML features")
+	}
+	var globalMarketRepo *repository.GlobalMarketRepository
+	if cfg.GlobalMarketEnabled && !cfg.DemoMode && db.Pool != nil {
+		// Share the price provider's rate limit when it is CoinGecko.
+		coingecko, ok := marketProvider.(*provider.CoinGeckoProvider)
+		if !ok {
+			coingecko = provider.NewCoinGeckoProvider(tracer)
+			coingecko.SetAPIKey(cfg.CoinGeckoAPIKey)
+		}
+		globalMarketRepo = repository.NewGlobalMarketRepository(db.Pool, tracer)
+		globalMarketService := service.NewGlobalMarketService(tracer, coingecko, globalMarketRepo)
+		globalMarketPoller := job.NewSnapshotPoller(tracer, "global-market", globalMarketService,
+			time.Duration(cfg.GlobalMarketPollSecs)*time.Second,
+			time.Duration(cfg.GlobalMarketRetentionDays)*24*time.Hour)
+		globalMarketPoller.SetPauser(maintenanceService)
+		globalMarketPoller.SetQuotaBudget(coingeckoQuota)
+		jobGate.Go(ctx, "global market poller", func() { go globalMarketPoller.Start(ctx) })
+		if advisorSvc != nil {
+			advisorSvc.SetGlobalMarket(globalMarketService)
+		}
+		log.Println("Global market snapshots enabled: CoinGecko market cap and BTC dominance feed the advisor and ML features")
+	}
+	if cfg.DexSpreadEnabled && !cfg.DemoMode && db.Pool != nil {
+		dexSpreadService := service.NewDexSpreadService(tracer,
+			provider.NewDexProvider(tracer, cfg.DexEthRPCURL), priceService,
+			repository.NewDexSpreadRepository(db.Pool, tracer), signalRepo, cfg.DexSpreadThresholdPct)
+		if alertSink != nil {
+			dexSpreadService.SetAlertSink(alertSink)
+		}
+		dexSpreadPoller := job.NewSnapshotPoller(tracer, "dex-spread", dexSpreadService,
+			time.Duration(cfg.DexSpreadPollSecs)*time.Second,
+			time.Duration(cfg.DexSpreadRetentionDays)*24*time.Hour)
+		dexSpreadPoller.SetPauser(maintenanceService)
+		jobGate.Go(ctx, "dex spread poller", func() { go dexSpreadPoller.Start(ctx) })
+		log.Printf("CEX-DEX spread signals enabled: threshold %.2f%%", cfg.DexSpreadThresholdPct)
+	}
+	var candleIntegrityService *service.CandleIntegrityService
+	if cfg.CandleIntegrityEnabled && !cfg.DemoMode && db.Pool != nil {
+		candleIntegrityService = service.NewCandleIntegrityService(tracer, candleRepo, marketProvider, cfg.PriceProvider,
+			repository.NewCandleIntegrityRepository(db.Pool, tracer), service.CandleIntegrityConfig{
+				SampleSize:   cfg.CandleIntegritySampleSize,
+				LookbackDays: cfg.CandleIntegrityLookbackDays,
+				TolerancePct: cfg.CandleIntegrityTolerancePct,
+				AlertPct:     cfg.CandleIntegrityAlertPct,
+			})
+		if broadcaster != nil {
+			candleIntegrityService.SetAlerter(broadcaster)
+		}
+		candleIntegrityPoller := job.NewSnapshotPoller(tracer, "candle-integrity", candleIntegrityService,
+			time.Duration(cfg.CandleIntegrityPollSecs)*time.Second,
+			time.Duration(cfg.CandleIntegrityRetentionDays)*24*time.Hour)
+		candleIntegrityPoller.SetPauser(maintenanceService)
+		jobGate.Go(ctx, "candle integrity poller", func() { go candleIntegrityPoller.Start(ctx) })
+		log.Printf("Candle integrity checks enabled: %d candles per symbol and interval against %s", cfg.CandleIntegritySampleSize, cfg.PriceProvider)
+	}
+	signalPoller := newSignalPollerFunc(tracer, signalService, alertSink)
+	if signalPoller != nil {
+		signalPoller.SetPauser(maintenanceService)
+		signalPoller.SetConcurrency(cfg.SignalPollConcurrency)
+		if cfg.CandleEventsEnabled {
+			signalPoller.SetCandleEvents(candleEvents.Subscribe("signals", 256))
+		}
+	}
 	signalImageJob := newSignalImageJobFunc(tracer, signalService)
-	startSignalImageJobFunc(signalImageJob, ctx)
+	signalImageJob.SetBatchSizes(cfg.SignalImageRetryBatch, cfg.SignalImageCleanupBatch)
+	signalImageJob.SetRetryInterval(time.Duration(cfg.SignalImageRetryDelaySecs) * time.Second)
+	jobGate.Go(ctx, "signal image maintenance", func() { startSignalImageJobFunc(signalImageJob, ctx) })
 	var mlService *service.MLSignalService
+	var modelEvents service.ModelEventSource
 	if cfg.MLEnabled {
-		if db.Pool == nil {
-			log.Println("ML jobs disabled: DATABASE_URL is required for ML feature/model storage")
+		if db.Pool == nil && sqliteDB == nil {
+			log.Println("ML jobs disabled: DATABASE_URL or STORAGE_BACKEND=sqlite is required for ML feature/model storage")
 		} else {
-			mlFeatureRepo := features.NewRepository(db.Pool, tracer)
-			mlRegistryRepo := registry.NewRepository(db.Pool, tracer)
-			mlPredictionRepo := predictions.NewRepository(db.Pool, tracer)
+			var mlFeatureRepo storage.FeatureStore
+			var mlRegistryRepo storage.ModelRegistry
+			var mlPredictionRepo storage.PredictionStore
+			if sqliteDB != nil {
+				mlFeatureRepo = sqlite.NewFeatureRepository(sqliteDB, tracer)
+				mlRegistryRepo = sqlite.NewRegistryRepository(sqliteDB, tracer)
+				mlPredictionRepo = sqlite.NewPredictionRepository(sqliteDB, tracer)
+			} else {
+				mlFeatureRepo = features.NewRepository(db.Pool, tracer)
+				mlRegistryRepo = registry.NewRepository(db.Pool, tracer)
+				mlPredictionRepo = predictions.NewRepository(db.Pool, tracer)
+			}
+			modelEvents, _ = mlRegistryRepo.(service.ModelEventSource)
+			overviewService.SetPredictions(mlPredictionRepo)
 			mlTrainingSvc := training.NewService(tracer, mlFeatureRepo, mlRegistryRepo, training.Config{
 				Interval:          cfg.MLInterval,
 				Intervals:         cfg.MLIntervals,
@@ -171,24 +696,46 @@ func main() {
 				IForestTrees:      cfg.MLIForestTrees,
 				IForestSampleSize: cfg.MLIForestSample,
 			})
+			mlTrainingSvc.SetAuditRecorder(auditService)
+			if db.Pool != nil && cfg.MLTrainExcludeFlagged {
+				mlTrainingSvc.SetLabelExclusions(repository.NewLabelOverrideRepository(db.Pool, tracer))
+			}
+			// ML signals go through the signal service so they get chart
+			// images like engine signals.
+			var mlSignalStore inference.SignalStore = signalRepo
+			if signalService != nil {
+				mlSignalStore = signalService
+			}
 			mlInferenceSvc := inference.NewService(
 				tracer,
 				mlFeatureRepo,
 				mlRegistryRepo,
 				mlPredictionRepo,
-				signalRepo,
+				mlSignalStore,
 				ensemble.NewService(),
 				inference.Config{
-					Interval:         cfg.MLInterval,
-					Intervals:        cfg.MLIntervals,
-					TargetHours:      cfg.MLTargetHours,
-					LongThreshold:    cfg.MLLongThreshold,
-					ShortThreshold:   cfg.MLShortThreshold,
-					EnableIForest:    cfg.MLEnableIForest,
-					AnomalyThreshold: cfg.MLAnomalyThresh,
-					AnomalyDampMax:   cfg.MLAnomalyDampMax,
+					Interval:              cfg.MLInterval,
+					Intervals:             cfg.MLIntervals,
+					TargetHours:           cfg.MLTargetHours,
+					LongThreshold:         cfg.MLLongThreshold,
+					ShortThreshold:        cfg.MLShortThreshold,
+					EnableIForest:         cfg.MLEnableIForest,
+					AnomalyThreshold:      cfg.MLAnomalyThresh,
+					AnomalyDampMax:        cfg.MLAnomalyDampMax,
+					PinnedVersions:        cfg.MLPinnedModels,
+					RollingVersions:       cfg.MLRollingModels,
+					UncertaintyBandWeight: cfg.MLUncertaintyBandWeight,
 				},
 			)
+			if broadcaster != nil {
+				mlInferenceSvc.SetHeldAlerts(broadcaster)
+			}
+			if streamExporter != nil {
+				mlInferenceSvc.SetPredictionPublisher(streamExporter)
+			}
+			if marketIntelRepo != nil {
+				mlInferenceSvc.SetFearGreedSource(marketIntelRepo)
+			}
 			mlService = service.NewMLSignalService(
 				tracer,
 				candleRepo,
@@ -204,18 +751,51 @@ func main() {
 					TrainWindowDays: cfg.MLTrainWindowDays,
 				},
 			)
-			go job.NewMLFeatureInferenceJob(
+			mlService.SetSimilaritySearch(similarity.NewService(tracer, mlFeatureRepo, similarity.Config{
+				TargetHours:  cfg.MLTargetHours,
+				LookbackDays: cfg.MLTrainWindowDays,
+			}))
+			if orderBookRepo != nil {
+				mlService.SetOrderBookSource(orderBookRepo)
+			}
+			if derivativesRepo != nil {
+				mlService.SetDerivativesSource(derivativesRepo)
+			}
+			if globalMarketRepo != nil {
+				mlService.SetGlobalMarketSource(globalMarketRepo)
+			}
+			if marketIntelRepo != nil && marketEvents != nil {
+				mlService.SetEventSource(marketIntelRepo)
+			}
+			if advisorSvc != nil {
+				advisorSvc.SetSimilarSetups(mlService, cfg.MLInterval)
+			}
+			mlInferenceJob := job.NewMLFeatureInferenceJob(
 				tracer,
 				mlService,
 				time.Duration(cfg.MLInferPollSecs)*time.Second,
-			).Start(ctx)
-			go job.NewMLTrainingJob(tracer, mlService, cfg.MLTrainHourUTC).Start(ctx)
-			go job.NewMLOutcomeResolverJob(
+			)
+			if cfg.CandleEventsEnabled {
+				mlInferenceJob.SetCandleEvents(candleEvents.Subscribe("ml-feature-inference", 256), cfg.MLIntervals)
+			}
+			mlInferenceJob.SetPredictionListener(overviewService)
+			mlInferenceJob.SetPauser(maintenanceService)
+			jobGate.Go(ctx, "ML feature inference", func() { go mlInferenceJob.Start(ctx) })
+			mlTrainingJob := job.NewMLTrainingJob(tracer, mlService, cfg.MLTrainHourUTC)
+			mlTrainingJob.SetPauser(maintenanceService)
+			jobGate.Go(ctx, "ML training", func() { go mlTrainingJob.Start(ctx) })
+			mlResolverJob := job.NewMLOutcomeResolverJob(
 				tracer,
 				mlService,
 				time.Duration(cfg.MLResolvePollSecs)*time.Second,
 				200,
-			).Start(ctx)
+			)
+			mlResolverJob.SetPauser(maintenanceService)
+			if sqliteDB == nil {
+				// Backtest stats live in Postgres next to the predictions.
+				mlResolverJob.SetCoverageRefresher(backtestRepo)
+			}
+			jobGate.Go(ctx, "ML outcome resolver", func() { go mlResolverJob.Start(ctx) })
 			log.Printf(
 				"ML jobs enabled intervals=%v directional_interval=%s target_hours=%d train_window_days=%d iforest=%v",
 				cfg.MLIntervals, cfg.MLInterval, cfg.MLTargetHours, cfg.MLTrainWindowDays, cfg.MLEnableIForest,
@@ -223,12 +803,18 @@ func main() {
 		}
 	}
 
+	if symbolRegistry != nil {
+		registryRefresh := job.NewSymbolRegistryRefresh(symbolRegistry, job.DefaultSymbolRegistryRefresh)
+		jobGate.Go(ctx, "symbol registry refresh", func() { go registryRefresh.Start(ctx) })
+	}
+	jobGate.Go(ctx, "price poller", func() { startPollerFunc(poller, ctx) })
+	jobGate.Go(ctx, "signal poller", func() { startSignalPollerFunc(signalPoller, ctx) })
+
 	var marketIntelService *service.MarketIntelService
 	if cfg.MarketIntelEnabled {
 		if db.Pool == nil {
 			log.Println("Market intel job disabled: DATABASE_URL is required")
 		} else {
-			marketIntelRepo := marketintel.NewRepository(db.Pool, tracer)
 			marketIntelScorer := marketintel.NewScorer(
 				marketintel.NewOpenAIScorer(cfg.OpenAIAPIKey, cfg.MarketIntelScoringModel),
 				cfg.MarketIntelScoringBatchSize,
@@ -264,12 +850,21 @@ func main() {
 					NewsFeedItemLimit: 40,
 				},
 			)
+			if streamExporter != nil {
+				rawMarketIntelSvc.SetSignalPublisher(streamExporter)
+			}
+			rawMarketIntelSvc.SetFearGreedHistory(marketIntelRepo)
+			if marketEvents != nil {
+				rawMarketIntelSvc.SetEventCalendar(marketEvents, marketIntelRepo)
+			}
 			marketIntelService = service.NewMarketIntelService(tracer, rawMarketIntelSvc)
-			go job.NewMarketIntelJob(
+			marketIntelJob := job.NewMarketIntelJob(
 				tracer,
 				marketIntelService,
 				time.Duration(cfg.MarketIntelPollSecs)*time.Second,
-			).Start(ctx)
+			)
+			marketIntelJob.SetPauser(maintenanceService)
+			jobGate.Go(ctx, "market intel", func() { go marketIntelJob.Start(ctx) })
 			log.Printf(
 				"Market intel job enabled intervals=%v poll_secs=%d onchain=%v symbols=%v",
 				cfg.MarketIntelIntervals,
@@ -283,21 +878,90 @@ func main() {
 	// Create handlers and routes
 	workService := newWorkServiceFunc(tracer)
 	h := newHandlerFunc(tracer, workService, priceService, signalService)
+	h.SetSignalRiskMapper(signalEngine)
 	backtestService := newBacktestServiceFunc(tracer, backtestRepo)
+	if chartRenderer != nil {
+		backtestService.SetCalibrationRenderer(chartRenderer)
+	}
 	h.SetBacktestService(backtestService)
+	scheduleService := service.NewScheduleService(tracer, service.ScheduleConfig{
+		TrainingEnabled: mlService != nil,
+		TrainHourUTC:    cfg.MLTrainHourUTC,
+		TrainWindowDays: cfg.MLTrainWindowDays,
+		Maintenance:     cfg.MaintenanceWindows,
+	})
+	if modelEvents != nil {
+		scheduleService.SetModelEvents(modelEvents)
+	}
+	h.SetScheduleService(scheduleService)
+	h.SetOverviewService(overviewService)
+	if ingestStore, ok := candleRepo.(service.CandleIngestStore); ok {
+		candleIngestService := service.NewCandleIngestService(tracer, ingestStore)
+		candleIngestService.SetCandleEvents(candleEvents)
+		h.SetCandleIngestService(candleIngestService)
+	}
+	h.SetSeasonalityService(service.NewSeasonalityService(tracer, candleRepo))
+	h.SetOrderBookService(orderBookService)
+	if priceQuorum != nil && db.Pool != nil {
+		h.SetPriceDiscrepancies(priceQuorum)
+	}
+	if workQueue != nil {
+		h.SetWorkQueue(workQueue)
+	}
+	if candleIntegrityService != nil {
+		h.SetCandleIntegrity(candleIntegrityService)
+	}
+	if signalPoller != nil {
+		h.SetSignalGenerator(signalPoller)
+	}
 	if mlService != nil {
 		h.SetMLTrainingRunner(mlService)
 	}
 	if marketIntelService != nil {
 		h.SetMarketIntelRunner(marketIntelService)
 	}
+	if marketEvents != nil {
+		h.SetMarketEventCalendar(marketEvents)
+	}
+	h.SetAuditService(auditService)
+	if db.Pool != nil {
+		h.SetLabelReviewService(service.NewLabelReviewService(tracer, repository.NewLabelOverrideRepository(db.Pool, tracer), auditService))
+	}
+	h.SetMaintenanceService(maintenanceService)
+	if symbolRegistry != nil {
+		h.SetSymbolRegistryService(symbolRegistry)
+	}
+	h.SetReadiness(jobGate)
+	h.SetEffectiveConfig(config.Effective(cfg))
+	if apiKeyService != nil {
+		h.SetAPIKeyService(apiKeyService)
+	}
+	if webhookService != nil {
+		h.SetWebhookService(webhookService)
+	}
+	if broadcaster != nil {
+		h.SetBroadcaster(broadcaster)
+	}
+	if deadLetters != nil {
+		h.SetAlertDeadLetters(deadLetters)
+	}
+	h.SetRequestTimeouts(
+		time.Duration(cfg.HTTPRequestTimeoutSecs)*time.Second,
+		time.Duration(cfg.HTTPLongRequestTimeoutSecs)*time.Second,
+	)
+	if cache.Client != nil {
+		h.SetIdempotencyStore(
+			handler.NewRedisIdempotencyStore(cache.Client),
+			time.Duration(cfg.IdempotencyTTLSecs)*time.Second,
+		)
+	}
 
 	r := newRouterFunc()
 	r.Use(otelgin.Middleware("bug-free-umbrella"))
 	corsConfig := cors.Config{
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"X-API-Key", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"X-API-Key", "Content-Type", "Authorization", handler.IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", handler.IdempotentReplayedHeader},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}
@@ -310,13 +974,26 @@ func main() {
 
 	// Public routes — no auth required
 	r.GET("/health", h.Health)
+	r.GET("/readyz", h.Ready)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Protected routes — require X-API-Key header
+	// Protected routes — require X-API-Key header; the key selects the tenant
 	protected := r.Group("")
-	protected.Use(handler.APIKeyAuth(cfg.RESTAPIKey))
+	tenantAuth := handler.TenantAuthConfig{
+		AdminKey:   cfg.RESTAPIKey,
+		StaticKeys: cfg.RESTAPITenantKeys,
+	}
+	if db.Pool != nil {
+		tenantAuth.Resolver = newAPIKeyRepoFunc(db.Pool, tracer)
+	}
+	protected.Use(handler.TenantAPIKeyAuth(tenantAuth))
 	h.RegisterRoutes(protected)
 
+	// Calendar feeds also accept the key as ?api_key=, since calendar apps
+	// cannot set headers
+	calendarFeeds := r.Group("", handler.APIKeyFromQuery("api_key"), handler.TenantAPIKeyAuth(tenantAuth))
+	h.RegisterCalendarRoutes(calendarFeeds)
+
 	if cfg.WebConsoleEnabled {
 		sessionTTL := time.Duration(cfg.WebConsoleSessionTTLSecs) * time.Second
 		heartbeat := time.Duration(cfg.WebConsoleHeartbeatSecs) * time.Second
@@ -359,6 +1036,27 @@ func main() {
 	log.Println("Server exiting")
 }
 
+// seedDemoHistory stores enough synthetic history for the signal engine and
+// ML training to have something to work with on first start.
+func seedDemoHistory(ctx context.Context, prices *service.PriceService, days int) {
+	total := 0
+	for _, symbol := range domain.SupportedSymbols() {
+		n, err := prices.BackfillCandles(ctx, symbol, days, []string{"1h", "4h", "1d"})
+		if err != nil {
+			log.Printf("demo backfill %s: %v", symbol, err)
+			continue
+		}
+		total += n
+		n, err = prices.BackfillCandles(ctx, symbol, 2, []string{"5m", "15m"})
+		if err != nil {
+			log.Printf("demo backfill %s: %v", symbol, err)
+			continue
+		}
+		total += n
+	}
+	log.Printf("Demo history seeded (%d candles)", total)
+}
+
 func httpAddrFromEnv() string {
 	port := strings.TrimSpace(os.Getenv("PORT"))
 	if port == "" {
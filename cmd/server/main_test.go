@@ -11,13 +11,15 @@ import (
 	"bug-free-umbrella/internal/bot"
 	"bug-free-umbrella/internal/chart"
 	"bug-free-umbrella/internal/config"
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/job"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/secrets"
 	"bug-free-umbrella/internal/service"
 	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -59,10 +61,12 @@ func TestHTTPAddrFromEnv(t *testing.T) {
 
 func stubServerDeps() func() {
 	origLoadEnv := loadEnvFunc
+	origLoadSecrets := loadSecretsFunc
 	origLoadConfig := loadConfigFunc
 	origInitPostgres := initPostgresFunc
 	origInitRedis := initRedisFunc
 	origInitTracer := initTracerFunc
+	origInitMeter := initMeterFunc
 	origNewSignalRepo := newSignalRepoFunc
 	origNewSignalImageRepo := newSignalImageRepoFunc
 	origNewProvider := newCoinGeckoProviderFunc
@@ -85,6 +89,7 @@ func stubServerDeps() func() {
 	origShutdownHTTP := shutdownHTTPServerFunc
 
 	loadEnvFunc = func(...string) error { return nil }
+	loadSecretsFunc = func(context.Context) (*secrets.Watcher, error) { return nil, nil }
 	loadConfigFunc = func() *config.Config {
 		return &config.Config{RedisURL: "", DatabaseURL: "", CoinGeckoPollSecs: 1}
 	}
@@ -94,13 +99,16 @@ func stubServerDeps() func() {
 		tp := sdktrace.NewTracerProvider()
 		return tp, tp.Tracer("test"), nil
 	}
+	initMeterFunc = func(context.Context) (*sdkmetric.MeterProvider, error) {
+		return sdkmetric.NewMeterProvider(), nil
+	}
 	newSignalRepoFunc = func(repository.PgxPool, trace.Tracer) *repository.SignalRepository {
 		return nil
 	}
 	newSignalImageRepoFunc = func(repository.PgxPool, trace.Tracer) *repository.SignalImageRepository {
 		return nil
 	}
-	newCoinGeckoProviderFunc = func(trace.Tracer) service.PriceProvider { return stubPriceProvider{} }
+	newCoinGeckoProviderFunc = func(trace.Tracer, string) service.PriceProvider { return stubPriceProvider{} }
 	newSignalEngineFunc = func(func() time.Time) *signalengine.Engine { return signalengine.NewEngine(nil) }
 	newSignalServiceWithImagesFunc = func(
 		trace.Tracer,
@@ -130,7 +138,9 @@ func stubServerDeps() func() {
 	) *advisor.AdvisorService {
 		return nil
 	}
-	startTelegramBotFunc = func(bot.PriceQuerier, bot.SignalLister, bot.Advisor) *bot.AlertDispatcher { return nil }
+	startTelegramBotFunc = func(bot.PriceQuerier, bot.SignalLister, bot.Advisor, bot.APITokenIssuer, bot.FearGreedReader, bot.EventCalendar, bot.RateLimits) *bot.AlertDispatcher {
+		return nil
+	}
 	newRouterFunc = func(...gin.OptionFunc) *gin.Engine { return gin.New() }
 	setupSignalNotify = func(c chan<- os.Signal, sig ...os.Signal) {}
 	waitForSignalFunc = func(<-chan os.Signal) {}
@@ -139,10 +149,12 @@ func stubServerDeps() func() {
 
 	return func() {
 		loadEnvFunc = origLoadEnv
+		loadSecretsFunc = origLoadSecrets
 		loadConfigFunc = origLoadConfig
 		initPostgresFunc = origInitPostgres
 		initRedisFunc = origInitRedis
 		initTracerFunc = origInitTracer
+		initMeterFunc = origInitMeter
 		newSignalRepoFunc = origNewSignalRepo
 		newSignalImageRepoFunc = origNewSignalImageRepo
 		newCoinGeckoProviderFunc = origNewProvider
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/config"
+	"bug-free-umbrella/internal/repository"
+	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultLookback matches the candles the live signal poller loads.
+const defaultLookback = 250
+
+var (
+	loadEnvFunc = godotenv.Load
+	openPool    = pgxpool.New
+)
+
+type options struct {
+	symbol   string
+	interval string
+	csvPath  string
+	at       time.Time
+	lookback int
+}
+
+func main() {
+	loadEnvFunc()
+
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		log.Fatalf("parse options: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	engine := signalengine.NewEngine(nil)
+	engine.SetIchimokuPeriods(config.LoadIchimokuPeriods())
+	overrides := config.LoadSignalRiskOverrides()
+
+	var candles []*domain.Candle
+	if opts.csvPath != "" {
+		f, err := os.Open(opts.csvPath)
+		if err != nil {
+			log.Fatalf("open csv: %v", err)
+		}
+		candles, err = readCSV(f, opts.symbol, opts.interval)
+		f.Close()
+		if err != nil {
+			log.Fatalf("read csv: %v", err)
+		}
+	} else {
+		dsn := strings.TrimSpace(os.Getenv("DATABASE_URL"))
+		if dsn == "" {
+			log.Fatal("DATABASE_URL is required without --csv")
+		}
+		pool, err := openPool(ctx, dsn)
+		if err != nil {
+			log.Fatalf("connect postgres: %v", err)
+		}
+		defer pool.Close()
+
+		tracer := trace.NewNoopTracerProvider().Tracer("signalcheck")
+		if overrides, err = signalengine.LoadRiskOverrides(ctx, overrides, repository.NewRiskOverrideRepository(pool, tracer)); err != nil {
+			log.Printf("Warning: stored signal risk overrides unavailable, using SIGNAL_RISK_OVERRIDES only: %v", err)
+		}
+		candles, err = loadCandles(ctx, repository.NewCandleRepository(pool, tracer), opts)
+		if err != nil {
+			log.Fatalf("load candles: %v", err)
+		}
+	}
+	engine.SetRiskOverrides(overrides)
+
+	candles = window(candles, opts.at, opts.lookback)
+	if len(candles) == 0 {
+		log.Fatalf("no %s %s candles found", opts.symbol, opts.interval)
+	}
+	printExplanation(os.Stdout, engine.Explain(candles))
+}
+
+func parseOptions(args []string) (options, error) {
+	fs := flag.NewFlagSet("signalcheck", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+
+	symbol := fs.String("symbol", "", "symbol to check, e.g. BTC")
+	interval := fs.String("interval", "1h", "candle interval")
+	csvPath := fs.String("csv", "", "read candles from this CSV (open_time,open,high,low,close,volume) instead of DATABASE_URL")
+	atRaw := fs.String("at", "", "check the candle open at or before this time (YYYY-MM-DD or RFC3339, default latest)")
+	lookback := fs.Int("lookback", defaultLookback, "candles the engine sees, ending at the checked one")
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	opts := options{
+		symbol:   strings.ToUpper(strings.TrimSpace(*symbol)),
+		interval: strings.TrimSpace(*interval),
+		csvPath:  strings.TrimSpace(*csvPath),
+		lookback: *lookback,
+	}
+	if opts.symbol == "" {
+		return options{}, fmt.Errorf("symbol is required")
+	}
+	if domain.IntervalDuration(opts.interval) == 0 {
+		return options{}, fmt.Errorf("unsupported interval: %s", opts.interval)
+	}
+	if opts.lookback <= 1 {
+		return options{}, fmt.Errorf("lookback must be > 1")
+	}
+	if raw := strings.TrimSpace(*atRaw); raw != "" {
+		at, err := parseTime(raw)
+		if err != nil {
+			return options{}, fmt.Errorf("at: %w", err)
+		}
+		opts.at = at
+	}
+	return opts, nil
+}
+
+type candleStore interface {
+	GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error)
+	GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error)
+}
+
+// loadCandles reads the lookback candles ending at opts.at, or the latest
+// ones as the live poller does.
+func loadCandles(ctx context.Context, store candleStore, opts options) ([]*domain.Candle, error) {
+	if opts.at.IsZero() {
+		return store.GetCandles(ctx, opts.symbol, opts.interval, opts.lookback)
+	}
+	span := time.Duration(opts.lookback) * domain.IntervalDuration(opts.interval)
+	return store.GetCandlesInRange(ctx, opts.symbol, opts.interval, opts.at.Add(-span), opts.at)
+}
+
+// window keeps the last lookback candles that opened at or before at, in
+// time order. A zero at keeps the latest ones.
+func window(candles []*domain.Candle, at time.Time, lookback int) []*domain.Candle {
+	kept := make([]*domain.Candle, 0, len(candles))
+	for _, c := range candles {
+		if c != nil && (at.IsZero() || !c.OpenTime.After(at)) {
+			kept = append(kept, c)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].OpenTime.Before(kept[j].OpenTime) })
+	if len(kept) > lookback {
+		kept = kept[len(kept)-lookback:]
+	}
+	return kept
+}
+
+// readCSV parses candles with a header row naming open_time (or time or
+// timestamp), open, high, low, close and volume in any order. Times are
+// RFC3339, YYYY-MM-DD or Unix seconds or milliseconds. Missing open, high
+// and low default to the close, and a missing volume to 0.
+func readCSV(r io.Reader, symbol, interval string) ([]*domain.Candle, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "time", "timestamp":
+			name = "open_time"
+		}
+		cols[name] = i
+	}
+	if _, ok := cols["open_time"]; !ok {
+		return nil, errors.New("header has no open_time column")
+	}
+	if _, ok := cols["close"]; !ok {
+		return nil, errors.New("header has no close column")
+	}
+
+	var out []*domain.Candle
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		field := func(name string) (float64, bool, error) {
+			i, ok := cols[name]
+			if !ok || i >= len(record) || strings.TrimSpace(record[i]) == "" {
+				return 0, false, nil
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			if err != nil {
+				return 0, false, fmt.Errorf("line %d: %s: %w", line, name, err)
+			}
+			return v, true, nil
+		}
+
+		openTime, err := parseTime(record[cols["open_time"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: open_time: %w", line, err)
+		}
+		closeVal, ok, err := field("close")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %d: close is empty", line)
+		}
+		c := &domain.Candle{Symbol: symbol, Interval: interval, OpenTime: openTime, Close: closeVal}
+		for _, f := range []struct {
+			name string
+			dst  *float64
+		}{{"open", &c.Open}, {"high", &c.High}, {"low", &c.Low}} {
+			v, ok, err := field(f.name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				v = closeVal
+			}
+			*f.dst = v
+		}
+		if c.Volume, _, err = field("volume"); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+}
+
+func parseTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t.UTC(), nil
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		// Seconds would not reach 1e11 until the year 5138.
+		if n > 1e11 {
+			return time.UnixMilli(n).UTC(), nil
+		}
+		return time.Unix(n, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", raw)
+}
+
+func printExplanation(w io.Writer, ex signalengine.Explanation) {
+	fmt.Fprintf(w, "%s %s candle %s close %s (%d candles)\n",
+		ex.Symbol, ex.Interval, ex.OpenTime.Format(time.RFC3339), formatValue(ex.Close), ex.Candles)
+	for _, c := range ex.Checks {
+		fmt.Fprintln(w)
+		if c.Fired {
+			fmt.Fprintf(w, "%-20s FIRES %s risk %d: %s\n", c.Indicator, strings.ToUpper(string(c.Direction)), c.Risk, c.Details)
+		} else {
+			fmt.Fprintf(w, "%-20s no signal: %s\n", c.Indicator, c.Reason)
+		}
+		if len(c.Values) == 0 {
+			continue
+		}
+		parts := make([]string, 0, len(c.Values))
+		for _, v := range c.Values {
+			parts = append(parts, v.Name+"="+formatValue(v.Value))
+		}
+		fmt.Fprintf(w, "  %s\n", strings.Join(parts, "  "))
+	}
+}
+
+func formatValue(v float64) string {
+	if math.IsNaN(v) {
+		return "n/a"
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestParseOptions(t *testing.T) {
+	opts, err := parseOptions([]string{"--symbol", " btc ", "--interval", "4h", "--at", "2026-03-01T12:00:00Z", "--lookback", "100"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.symbol != "BTC" || opts.interval != "4h" || opts.lookback != 100 ||
+		!opts.at.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected options: %+v", opts)
+	}
+
+	opts, err = parseOptions([]string{"--symbol", "eth"})
+	if err != nil || opts.interval != "1h" || opts.lookback != defaultLookback || !opts.at.IsZero() {
+		t.Fatalf("unexpected defaults: %+v %v", opts, err)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"--symbol", "BTC", "--interval", "2h"},
+		{"--symbol", "BTC", "--lookback", "1"},
+		{"--symbol", "BTC", "--at", "yesterday"},
+	} {
+		if _, err := parseOptions(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	in := "timestamp, close, volume, high\n" +
+		"1772323200,100,5,101\n" +
+		"1772326800000,101.5,,\n" +
+		"2026-03-01T02:00:00Z,99,7,100\n"
+	candles, err := readCSV(strings.NewReader(in), "BTC", "1h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if len(candles) != 3 {
+		t.Fatalf("expected 3 candles, got %d", len(candles))
+	}
+	for i, c := range candles {
+		if !c.OpenTime.Equal(base.Add(time.Duration(i)*time.Hour)) || c.Symbol != "BTC" || c.Interval != "1h" {
+			t.Fatalf("candle %d: unexpected %+v", i, c)
+		}
+	}
+	if c := candles[0]; c.Open != 100 || c.High != 101 || c.Low != 100 || c.Volume != 5 {
+		t.Fatalf("expected missing columns to default to the close, got %+v", c)
+	}
+	if candles[1].Volume != 0 || candles[1].High != 101.5 {
+		t.Fatalf("expected empty fields to default, got %+v", candles[1])
+	}
+
+	for _, bad := range []string{
+		"open,close\n1,2\n",
+		"open_time,volume\n2026-03-01,2\n",
+		"open_time,close\nsoon,2\n",
+		"open_time,close\n2026-03-01,abc\n",
+	} {
+		if _, err := readCSV(strings.NewReader(bad), "BTC", "1h"); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestWindowEndsAtTheCheckedCandle(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var candles []*domain.Candle
+	for i := 9; i >= 0; i-- {
+		candles = append(candles, &domain.Candle{OpenTime: base.Add(time.Duration(i) * time.Hour)})
+	}
+	got := window(candles, base.Add(6*time.Hour+30*time.Minute), 4)
+	if len(got) != 4 || !got[0].OpenTime.Equal(base.Add(3*time.Hour)) || !got[3].OpenTime.Equal(base.Add(6*time.Hour)) {
+		t.Fatalf("unexpected window: %v .. %v (%d)", got[0].OpenTime, got[len(got)-1].OpenTime, len(got))
+	}
+	if got := window(candles, time.Time{}, 3); !got[2].OpenTime.Equal(base.Add(9 * time.Hour)) {
+		t.Fatalf("expected the latest candles without --at, got %v", got[2].OpenTime)
+	}
+}
+
+func TestPrintExplanation(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var in strings.Builder
+	in.WriteString("open_time,close,volume\n")
+	for i := 0; i < 40; i++ {
+		vol := 100 + i%3
+		if i == 39 {
+			vol = 900
+		}
+		fmt.Fprintf(&in, "%s,%d,%d\n", base.Add(time.Duration(i)*time.Hour).Format(time.RFC3339), 100+i%4, vol)
+	}
+	candles, err := readCSV(strings.NewReader(in.String()), "BTC", "1h")
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+
+	var out bytes.Buffer
+	printExplanation(&out, signalengine.NewEngine(nil).Explain(candles))
+	got := out.String()
+	for _, want := range []string{
+		"BTC 1h candle 2026-03-02T15:00:00Z close 103 (40 candles)",
+		"volume_zscore        FIRES LONG risk 3: volume z-score",
+		"rsi                  no signal:",
+		"prev_rsi=",
+		"prev_width=",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output:\n%s", want, got)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
 	ossignal "os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,11 +17,20 @@ import (
 	"bug-free-umbrella/internal/cache"
 	"bug-free-umbrella/internal/config"
 	"bug-free-umbrella/internal/db"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/internal/job"
+	"bug-free-umbrella/internal/ml/features"
+	"bug-free-umbrella/internal/ml/similarity"
 	"bug-free-umbrella/internal/provider"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/sandbox"
+	"bug-free-umbrella/internal/secrets"
 	"bug-free-umbrella/internal/service"
 	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/internal/synthetic"
 	"bug-free-umbrella/internal/tui"
+	"bug-free-umbrella/pkg/domain"
 	"bug-free-umbrella/pkg/tracing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,8 +39,8 @@ import (
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
 	"github.com/joho/godotenv"
-	gossh "golang.org/x/crypto/ssh"
 	"go.opentelemetry.io/otel/trace"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 // ctxKey is a typed context key to avoid collisions.
@@ -36,19 +49,46 @@ type ctxKey string
 const sshUserKey ctxKey = "ssh_user"
 
 var (
-	loadEnvFunc      = godotenv.Load
-	loadConfigFunc   = config.Load
-	initPostgresFunc = db.InitPostgres
-	initRedisFunc    = cache.InitRedis
-	initTracerFunc   = tracing.InitTracer
+	loadEnvFunc              = godotenv.Load
+	loadSecretsFunc          = secrets.Bootstrap
+	loadConfigFunc           = config.Load
+	configureHTTPFunc        = httpclient.Configure
+	initPostgresFunc         = db.InitPostgres
+	initRedisFunc            = cache.InitRedis
+	initTracerFunc           = tracing.InitTracer
+	initMeterFunc            = tracing.InitMeter
 	newCandleRepoFunc        = repository.NewCandleRepository
 	newSignalRepoFunc        = repository.NewSignalRepository
 	newSSHUserRepoFunc       = repository.NewSSHUserRepository
 	newBacktestRepoFunc      = repository.NewBacktestRepository
 	newConversationRepoFunc  = repository.NewConversationRepository
-	newCoinGeckoProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
-		return provider.NewCoinGeckoProvider(tracer)
+	newMaintenanceRepoFunc   = repository.NewMaintenanceRepository
+	newAuditRepoFunc         = repository.NewAuditRepository
+	newCoinGeckoProviderFunc = func(tracer trace.Tracer, apiKey string) service.PriceProvider {
+		coingecko := provider.NewCoinGeckoProvider(tracer)
+		coingecko.SetAPIKey(apiKey)
+		return coingecko
+	}
+	newBinanceProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
+		return provider.NewBinanceProvider(tracer)
+	}
+	newKrakenProviderFunc = func(tracer trace.Tracer) service.PriceProvider {
+		return provider.NewKrakenProvider(tracer)
+	}
+	newCoinbaseProviderFunc = func(tracer trace.Tracer, keyName, privateKey string) service.PriceProvider {
+		coinbase := provider.NewCoinbaseProvider(tracer)
+		if keyName != "" {
+			if err := coinbase.SetCredentials(keyName, privateKey); err != nil {
+				log.Fatalf("invalid Coinbase API key: %v", err)
+			}
+		}
+		return coinbase
+	}
+	newDemoProviderFunc = func(seed int64, history time.Duration) service.PriceProvider {
+		return synthetic.NewProvider(seed, history, nil)
 	}
+	startDemoStorageFunc           = sandbox.StartStorage
+	newDemoLLMClientFunc           = func() advisor.LLMClient { return sandbox.NewLLMClient() }
 	newSignalEngineFunc            = signalengine.NewEngine
 	newPriceServiceFunc            = service.NewPriceService
 	newSignalServiceWithImagesFunc = service.NewSignalServiceWithImages
@@ -57,20 +97,64 @@ var (
 	newWishServerFunc              = wish.NewServer
 	setupSignalNotify              = ossignal.Notify
 	waitForSignalFunc              = func(quit <-chan os.Signal) { <-quit }
+	argsFunc                       = func() []string { return os.Args[1:] }
 )
 
 func main() {
 	loadEnvFunc()
-	cfg := loadConfigFunc()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Pull managed secrets into the environment before reading config
+	secretsWatcher, err := loadSecretsFunc(ctx)
+	if err != nil {
+		log.Fatalf("failed to load secrets: %v", err)
+	}
+
+	cfg := loadConfigFunc()
+	if err := configureHTTPFunc(cfg.OutboundHTTP); err != nil {
+		log.Fatalf("invalid outbound HTTP settings: %v", err)
+	}
+
+	theme, err := resolveTheme(argsFunc(), cfg)
+	if err != nil {
+		log.Fatalf("invalid TUI theme: %v", err)
+	}
+	tui.ApplyTheme(theme)
+
+	// Demo mode shares the embedded stores cmd/server started, or starts them
+	if cfg.DemoMode && (cfg.DatabaseURL == "" || cfg.RedisURL == "") {
+		storage, err := startDemoStorageFunc(ctx, sandbox.StorageConfig{
+			Postgres: cfg.DatabaseURL == "",
+			Redis:    cfg.RedisURL == "",
+			DataDir:  cfg.DemoDataDir,
+		})
+		if err != nil {
+			log.Fatalf("failed to start demo storage: %v", err)
+		}
+		defer storage.Stop()
+		if cfg.DatabaseURL == "" {
+			cfg.DatabaseURL = storage.DatabaseURL
+		}
+		if cfg.RedisURL == "" {
+			cfg.RedisURL = storage.RedisURL
+		}
+	}
+
 	// Init Postgres and Redis
 	os.Setenv("DATABASE_URL", cfg.DatabaseURL)
 	os.Setenv("REDIS_URL", cfg.RedisURL)
-	initPostgresFunc(ctx)
-	initRedisFunc(ctx)
+	initCtx, cancelInit := context.WithTimeout(ctx, time.Duration(cfg.StartupConnectTimeoutSecs)*time.Second)
+	initPostgresFunc(initCtx)
+	initRedisFunc(initCtx)
+	cancelInit()
+	secretsWatcher.OnChange("DATABASE_URL", func(dsn string) {
+		if err := db.SetDSN(dsn); err != nil {
+			log.Printf("ignoring rotated DATABASE_URL: %v", err)
+		}
+	})
+	go secretsWatcher.Start(ctx)
 
 	// Init tracing
 	tp, tracer, err := initTracerFunc(ctx)
@@ -82,6 +166,15 @@ func main() {
 			log.Printf("error shutting down tracer provider: %v", err)
 		}
 	}()
+	mp, err := initMeterFunc(ctx)
+	if err != nil {
+		log.Fatalf("failed to initialize meter: %v", err)
+	}
+	defer func() {
+		if err := mp.Shutdown(ctx); err != nil {
+			log.Printf("error shutting down meter provider: %v", err)
+		}
+	}()
 
 	// Create repositories
 	candleRepo := newCandleRepoFunc(db.Pool, tracer)
@@ -90,18 +183,91 @@ func main() {
 	backtestRepo := newBacktestRepoFunc(db.Pool, tracer)
 	convRepo := newConversationRepoFunc(db.Pool, tracer)
 
+	// The maintenance switch lives in Postgres, shared with cmd/server.
+	var maintenanceService *service.MaintenanceService
+	if db.Pool != nil {
+		maintenanceService = service.NewMaintenanceService(tracer,
+			newMaintenanceRepoFunc(db.Pool, tracer),
+			service.NewAuditService(tracer, newAuditRepoFunc(db.Pool, tracer)))
+	}
+
+	// Similar past setups read the feature rows cmd/server keeps up to date.
+	var similarSvc *similarity.Service
+	if cfg.MLEnabled && db.Pool != nil {
+		similarSvc = similarity.NewService(tracer, features.NewRepository(db.Pool, tracer), similarity.Config{
+			TargetHours:  cfg.MLTargetHours,
+			LookbackDays: cfg.MLTrainWindowDays,
+		})
+	}
+
+	// Global market snapshots are captured by cmd/server; read them here.
+	var globalMarketSvc *service.GlobalMarketService
+	if cfg.GlobalMarketEnabled && !cfg.DemoMode && db.Pool != nil {
+		globalMarketSvc = service.NewGlobalMarketService(tracer, nil, repository.NewGlobalMarketRepository(db.Pool, tracer))
+	}
+
 	// Create services
-	cgProvider := newCoinGeckoProviderFunc(tracer)
-	priceService := newPriceServiceFunc(tracer, cgProvider, candleRepo, cache.Client)
+	var marketProvider service.PriceProvider
+	if cfg.DemoMode {
+		marketProvider = newDemoProviderFunc(cfg.DemoSeed, time.Duration(cfg.MLTrainWindowDays)*24*time.Hour)
+		log.Println("Demo mode: using synthetic market data")
+	} else if cfg.PriceProvider == "binance" {
+		marketProvider = newBinanceProviderFunc(tracer)
+	} else if cfg.PriceProvider == "kraken" {
+		marketProvider = newKrakenProviderFunc(tracer)
+	} else if cfg.PriceProvider == "coinbase" {
+		marketProvider = newCoinbaseProviderFunc(tracer, cfg.CoinbaseAPIKeyName, cfg.CoinbaseAPIPrivateKey)
+	} else {
+		marketProvider = newCoinGeckoProviderFunc(tracer, cfg.CoinGeckoAPIKey)
+	}
+	priceService := newPriceServiceFunc(tracer, marketProvider, candleRepo, cache.Client)
+	domain.SetConfiguredPauses(cfg.SymbolPauses)
+	if db.Pool != nil {
+		symbolRegistry := service.NewSymbolRegistryService(tracer, repository.NewAssetRepository(db.Pool, tracer), nil)
+		if err := symbolRegistry.Reload(ctx); err != nil {
+			log.Printf("Warning: stored symbol registry unavailable, tracking built-in symbols: %v", err)
+		}
+		go job.NewSymbolRegistryRefresh(symbolRegistry, job.DefaultSymbolRegistryRefresh).Start(ctx)
+	}
 	signalEngine := newSignalEngineFunc(nil)
+	riskOverrides := cfg.SignalRiskOverrides
+	if db.Pool != nil {
+		if riskOverrides, err = signalengine.LoadRiskOverrides(ctx, riskOverrides, repository.NewRiskOverrideRepository(db.Pool, tracer)); err != nil {
+			log.Printf("Warning: stored signal risk overrides unavailable, using SIGNAL_RISK_OVERRIDES only: %v", err)
+		}
+	}
+	signalEngine.SetRiskOverrides(riskOverrides)
+	signalEngine.SetIchimokuPeriods(cfg.IchimokuPeriods)
 	signalService := newSignalServiceWithImagesFunc(tracer, candleRepo, signalRepo, signalEngine, nil, nil)
 
 	// Advisor (optional)
 	var advisorSvc *advisor.AdvisorService
+	var llmClient advisor.LLMClient
 	if cfg.OpenAIAPIKey != "" {
-		llmClient := newOpenAIClientFunc(cfg.OpenAIAPIKey)
+		llmClient = newOpenAIClientFunc(cfg.OpenAIAPIKey)
+		if rotator, ok := llmClient.(advisor.APIKeyRotator); ok {
+			secretsWatcher.OnChange("OPENAI_API_KEY", rotator.SetAPIKey)
+		}
+	} else if cfg.DemoMode {
+		llmClient = newDemoLLMClientFunc()
+	}
+	if llmClient != nil {
 		advisorSvc = newAdvisorServiceFunc(tracer, llmClient, priceService, signalService,
 			convRepo, cfg.OpenAIModel, cfg.AdvisorMaxHistory)
+		if advisorSvc != nil {
+			advisorSvc.SetAnswerCacheTTL(time.Duration(cfg.AdvisorCacheTTLSecs) * time.Second)
+			guardMode, _ := advisor.ParseGuardrailMode(cfg.AdvisorGuardrails)
+			advisorSvc.SetGuardrails(advisor.GuardrailPolicy{
+				Mode:              guardMode,
+				PriceTolerancePct: cfg.AdvisorPriceTolerancePct,
+			})
+			if similarSvc != nil {
+				advisorSvc.SetSimilarSetups(similarSvc, cfg.MLInterval)
+			}
+			if globalMarketSvc != nil {
+				advisorSvc.SetGlobalMarket(globalMarketSvc)
+			}
+		}
 		log.Println("SSH advisor service enabled")
 	}
 
@@ -113,6 +279,11 @@ func main() {
 		wish.WithHostKeyPath(cfg.SSHHostKeyPath),
 		wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
 			fingerprint := gossh.FingerprintSHA256(key)
+			if cfg.DemoMode {
+				// Any key gets in; there are no registered users in a demo.
+				ctx.SetValue(sshUserKey, &repository.SSHUser{Username: "demo", Fingerprint: fingerprint, IsActive: true})
+				return true
+			}
 			user, err := sshUserRepo.FindByFingerprint(context.Background(), fingerprint)
 			if err != nil || user == nil {
 				log.Printf("SSH auth denied: fingerprint=%s err=%v", fingerprint, err)
@@ -146,6 +317,31 @@ func main() {
 					Backtest: backtestRepo,
 					UserID:   userID,
 					Username: username,
+					Location: sessionTimezone(s.Environ()),
+				}
+				// Demo logins have no user row; give each its own conversation.
+				if userID == 0 {
+					svc.SessionID = rand.Int64N(1<<40) + 1
+				}
+				// Demo users have no row to store pins or state on.
+				if !cfg.DemoMode && sshUserRepo != nil {
+					svc.Watchlist = sshUserRepo
+					svc.State = sshUserRepo
+				}
+				if similarSvc != nil {
+					svc.Similar = similarSvc
+					svc.SimilarInterval = cfg.MLInterval
+				}
+				if globalMarketSvc != nil {
+					svc.GlobalMarket = globalMarketSvc
+				}
+				if maintenanceService != nil {
+					svc.Maintenance = maintenanceService
+					svc.CanToggleMaintenance = slices.Contains(cfg.SSHOperators, username)
+				}
+				// Events published by cmd/server; closed with the session.
+				if cache.Client != nil {
+					svc.MarketEvents = events.SubscribeMarket(s.Context(), cache.Client, 64)
 				}
 
 				model := tui.NewAppModel(svc)
@@ -189,3 +385,40 @@ func main() {
 
 	log.Println("SSH server exited")
 }
+
+// resolveTheme picks the TUI theme from --theme, then TUI_THEME. NO_COLOR
+// selects the no-color theme unless --theme is given explicitly.
+// sessionTimezone reads the TZ a client sent, for example with
+// ssh -o SetEnv=TZ=Europe/Berlin. Without one, or with an unknown zone,
+// times are shown in UTC.
+func sessionTimezone(environ []string) *time.Location {
+	for _, kv := range environ {
+		name, ok := strings.CutPrefix(kv, "TZ=")
+		if !ok {
+			continue
+		}
+		loc, err := domain.LoadTimezone(name)
+		if err != nil {
+			log.Printf("SSH session: %v, using UTC", err)
+			return time.UTC
+		}
+		return loc
+	}
+	return time.UTC
+}
+
+func resolveTheme(args []string, cfg *config.Config) (tui.Theme, error) {
+	fs := flag.NewFlagSet("ssh", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	name := fs.String("theme", cfg.TUITheme, "TUI color theme: "+strings.Join(tui.ThemeNames(), ", "))
+	if err := fs.Parse(args); err != nil {
+		return tui.Theme{}, err
+	}
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "theme" {
+			explicit = true
+		}
+	})
+	return tui.LookupTheme(*name, cfg.NoColor && !explicit)
+}
@@ -2,23 +2,31 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"bug-free-umbrella/internal/advisor"
 	"bug-free-umbrella/internal/config"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/secrets"
 	"bug-free-umbrella/internal/service"
 	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/internal/tui"
 
 	"github.com/charmbracelet/ssh"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func TestMainBootstrap(t *testing.T) {
-	restore := stubSSHDeps()
+	restore := stubSSHDeps(writeTestHostKey(t))
 	defer restore()
 
 	done := make(chan struct{})
@@ -34,12 +42,36 @@ func TestMainBootstrap(t *testing.T) {
 	}
 }
 
-func stubSSHDeps() func() {
+// writeTestHostKey writes a fresh ed25519 host key under t.TempDir and
+// returns its path, so no key is kept in the repository.
+func writeTestHostKey(t *testing.T) string {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key: %v", err)
+	}
+	if _, err := gossh.NewSignerFromKey(priv); err != nil {
+		t.Fatalf("host key signer: %v", err)
+	}
+	block, err := gossh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatalf("marshal host key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "test_key")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("write host key: %v", err)
+	}
+	return path
+}
+
+func stubSSHDeps(hostKeyPath string) func() {
 	origLoadEnv := loadEnvFunc
+	origLoadSecrets := loadSecretsFunc
 	origLoadConfig := loadConfigFunc
 	origInitPostgres := initPostgresFunc
 	origInitRedis := initRedisFunc
 	origInitTracer := initTracerFunc
+	origInitMeter := initMeterFunc
 	origNewCandleRepo := newCandleRepoFunc
 	origNewSignalRepo := newSignalRepoFunc
 	origNewSSHUserRepo := newSSHUserRepoFunc
@@ -54,14 +86,16 @@ func stubSSHDeps() func() {
 	origNewWishServer := newWishServerFunc
 	origSetupSignal := setupSignalNotify
 	origWait := waitForSignalFunc
+	origArgs := argsFunc
 
 	loadEnvFunc = func(...string) error { return nil }
+	loadSecretsFunc = func(context.Context) (*secrets.Watcher, error) { return nil, nil }
 	loadConfigFunc = func() *config.Config {
 		return &config.Config{
 			RedisURL:       "",
 			DatabaseURL:    "",
 			SSHPort:        2222,
-			SSHHostKeyPath: ".ssh/test_key",
+			SSHHostKeyPath: hostKeyPath,
 		}
 	}
 	initPostgresFunc = func(context.Context) {}
@@ -70,6 +104,9 @@ func stubSSHDeps() func() {
 		tp := sdktrace.NewTracerProvider()
 		return tp, tp.Tracer("test"), nil
 	}
+	initMeterFunc = func(context.Context) (*sdkmetric.MeterProvider, error) {
+		return sdkmetric.NewMeterProvider(), nil
+	}
 	newCandleRepoFunc = func(repository.PgxPool, trace.Tracer) *repository.CandleRepository {
 		return nil
 	}
@@ -85,7 +122,7 @@ func stubSSHDeps() func() {
 	newConversationRepoFunc = func(repository.PgxPool, trace.Tracer) *repository.ConversationRepository {
 		return nil
 	}
-	newCoinGeckoProviderFunc = func(trace.Tracer) service.PriceProvider { return nil }
+	newCoinGeckoProviderFunc = func(trace.Tracer, string) service.PriceProvider { return nil }
 	newSignalEngineFunc = func(func() time.Time) *signalengine.Engine { return signalengine.NewEngine(nil) }
 	newPriceServiceFunc = func(
 		trace.Tracer,
@@ -117,13 +154,16 @@ func stubSSHDeps() func() {
 	}
 	setupSignalNotify = func(c chan<- os.Signal, sig ...os.Signal) {}
 	waitForSignalFunc = func(<-chan os.Signal) {}
+	argsFunc = func() []string { return nil }
 
 	return func() {
 		loadEnvFunc = origLoadEnv
+		loadSecretsFunc = origLoadSecrets
 		loadConfigFunc = origLoadConfig
 		initPostgresFunc = origInitPostgres
 		initRedisFunc = origInitRedis
 		initTracerFunc = origInitTracer
+		initMeterFunc = origInitMeter
 		newCandleRepoFunc = origNewCandleRepo
 		newSignalRepoFunc = origNewSignalRepo
 		newSSHUserRepoFunc = origNewSSHUserRepo
@@ -138,5 +178,36 @@ func stubSSHDeps() func() {
 		newWishServerFunc = origNewWishServer
 		setupSignalNotify = origSetupSignal
 		waitForSignalFunc = origWait
+		argsFunc = origArgs
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	theme, err := resolveTheme(nil, &config.Config{TUITheme: "light"})
+	if err != nil || theme.Name != "light" {
+		t.Fatalf("expected light theme from config, got %q %v", theme.Name, err)
+	}
+	theme, err = resolveTheme(nil, &config.Config{TUITheme: "light", NoColor: true})
+	if err != nil || theme.Name != tui.NoColorThemeName {
+		t.Fatalf("expected NO_COLOR to win over TUI_THEME, got %q %v", theme.Name, err)
+	}
+	theme, err = resolveTheme([]string{"--theme", "high-contrast"}, &config.Config{NoColor: true})
+	if err != nil || theme.Name != "high-contrast" {
+		t.Fatalf("expected explicit flag to win, got %q %v", theme.Name, err)
+	}
+	if _, err := resolveTheme([]string{"--theme", "neon"}, &config.Config{}); err == nil {
+		t.Fatal("expected unknown theme error")
+	}
+}
+
+func TestSessionTimezone(t *testing.T) {
+	if loc := sessionTimezone([]string{"TERM=xterm", "TZ=America/Chicago"}); loc.String() != "America/Chicago" {
+		t.Fatalf("expected the client's zone, got %s", loc)
+	}
+	if loc := sessionTimezone([]string{"TERM=xterm"}); loc != time.UTC {
+		t.Fatalf("expected UTC without TZ, got %s", loc)
+	}
+	if loc := sessionTimezone([]string{"TZ=Nowhere/Special"}); loc != time.UTC {
+		t.Fatalf("expected UTC for an unknown zone, got %s", loc)
 	}
 }
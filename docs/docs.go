@@ -22,7 +22,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Returns historical candle data for a given asset and interval",
+                "description": "Returns historical candle data for a given asset and interval, with prices rounded to the symbol's precision",
                 "produces": [
                     "application/json"
                 ],
@@ -168,7 +168,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Returns latest cached prices for all 10 tracked cryptocurrencies",
+                "description": "Returns latest cached prices for all 10 tracked cryptocurrencies, each rounded to its symbol's precision",
                 "produces": [
                     "application/json"
                 ],
@@ -194,7 +194,7 @@ const docTemplate = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "Returns the latest cached price, 24h volume, and 24h change",
+                "description": "Returns the latest cached price, 24h volume, and 24h change. The price is rounded to the symbol's precision, given in price_decimals",
                 "produces": [
                     "application/json"
                 ],
@@ -260,7 +260,7 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Indicator key (rsi, macd, bollinger, volume_zscore, ml_logreg_up4h, ml_xgboost_up4h, ml_ensemble_up4h, fund_sentiment_composite)",
+                        "description": "Indicator key (rsi, macd, bollinger, volume_zscore, ichimoku, ml_logreg_up4h, ml_xgboost_up4h, ml_ensemble_up4h, fund_sentiment_composite, cex_dex_spread)",
                         "name": "indicator",
                         "in": "query"
                     },
@@ -396,6 +396,9 @@ const docTemplate = `{
                 "last_updated_unix": {
                     "type": "integer"
                 },
+                "price_decimals": {
+                    "type": "integer"
+                },
                 "price_usd": {
                     "type": "number"
                 },
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -35,6 +39,25 @@ type ConversationStore interface {
 	RecentMessages(ctx context.Context, chatID int64, limit int) ([]domain.ConversationMessage, error)
 }
 
+// ConversationEraser is implemented by conversation stores that can delete a
+// chat's history.
+type ConversationEraser interface {
+	DeleteConversation(ctx context.Context, chatID int64) (int64, error)
+}
+
+// SimilarSetupFinder finds past market states like a symbol's current one.
+// With it, questions that name a symbol also get how similar setups played
+// out.
+type SimilarSetupFinder interface {
+	FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error)
+}
+
+// GlobalMarketQuerier provides the latest total market cap and BTC
+// dominance. With it, every market context starts with the whole market.
+type GlobalMarketQuerier interface {
+	LatestGlobalMarket(ctx context.Context) (*domain.GlobalMarketSnapshot, error)
+}
+
 type AdvisorService struct {
 	tracer     trace.Tracer
 	llm        LLMClient
@@ -43,6 +66,12 @@ type AdvisorService struct {
 	convStore  ConversationStore
 	model      string
 	maxHistory int
+	answers    *answerCache
+	guardrails GuardrailPolicy
+
+	similar         SimilarSetupFinder
+	similarInterval string
+	globalMarket    GlobalMarketQuerier
 }
 
 func NewAdvisorService(
@@ -68,6 +97,36 @@ func NewAdvisorService(
 	}
 }
 
+// SetAnswerCacheTTL enables sharing answers across chats for ttl when the
+// question and the latest candle times match. It has no effect unless the
+// price source implements CandleQuerier; ttl <= 0 disables caching.
+func (s *AdvisorService) SetAnswerCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		s.answers = nil
+		return
+	}
+	s.answers = newAnswerCache(ttl)
+}
+
+// SetGuardrails sets the content policy applied to LLM replies before they
+// are cached, stored and returned.
+func (s *AdvisorService) SetGuardrails(policy GuardrailPolicy) {
+	s.guardrails = policy
+}
+
+// SetSimilarSetups adds the past states most similar to each mentioned
+// symbol's latest features on interval to the market context.
+func (s *AdvisorService) SetSimilarSetups(finder SimilarSetupFinder, interval string) {
+	s.similar = finder
+	s.similarInterval = interval
+}
+
+// SetGlobalMarket adds the latest whole-market snapshot to the market
+// context.
+func (s *AdvisorService) SetGlobalMarket(q GlobalMarketQuerier) {
+	s.globalMarket = q
+}
+
 func (s *AdvisorService) Ask(ctx context.Context, chatID int64, userMessage string) (string, error) {
 	ctx, span := s.tracer.Start(ctx, "advisor.ask")
 	defer span.End()
@@ -81,34 +140,64 @@ func (s *AdvisorService) Ask(ctx context.Context, chatID int64, userMessage stri
 	// 2. Extract mentioned symbols for targeted context
 	mentionedSymbols := ExtractSymbols(userMessage)
 
-	// 3. Gather market context
+	// 3. Load conversation history
+	history, err := s.convStore.RecentMessages(ctx, chatID, s.maxHistory)
+	cacheable := err == nil
+	if err != nil {
+		log.Printf("failed to load conversation history: %v", err)
+		history = nil
+	}
+
+	// 4. Serve a cached answer when the same question was asked against the
+	// same market state. Replies depend on the chat's history, so only
+	// questions that open a conversation share the cache.
+	cacheKey := ""
+	if cacheable && !hasPriorMessages(history, userMessage) {
+		cacheKey = s.answerCacheKey(ctx, userMessage, mentionedSymbols)
+	}
+	if cacheKey != "" {
+		if reply, ok := s.answers.get(cacheKey); ok {
+			span.SetAttributes(attribute.Bool("advisor.cache_hit", true))
+			if err := s.convStore.AppendMessage(ctx, chatID, "assistant", reply); err != nil {
+				log.Printf("failed to store assistant reply: %v", err)
+			}
+			return reply, nil
+		}
+	}
+
+	// 5. Gather market context
 	marketContext, err := s.gatherContext(ctx, mentionedSymbols)
 	if err != nil {
 		log.Printf("failed to gather market context: %v", err)
 		marketContext = "Market data temporarily unavailable."
 	}
 
-	// 4. Build system prompt with live data
+	// 6. Build system prompt with live data
 	systemPrompt := BuildSystemPrompt(marketContext)
 
-	// 5. Load conversation history
-	history, err := s.convStore.RecentMessages(ctx, chatID, s.maxHistory)
-	if err != nil {
-		log.Printf("failed to load conversation history: %v", err)
-		history = nil
-	}
-
-	// 6. Construct messages array
+	// 7. Construct messages array
 	messages := s.buildMessages(systemPrompt, history)
 
-	// 7. Call LLM
+	// 8. Call LLM
 	reply, err := s.callLLM(ctx, messages)
 	if err != nil {
 		span.RecordError(err)
 		return "", fmt.Errorf("advisor unavailable: %w", err)
 	}
 
-	// 8. Persist the assistant reply
+	// 9. Enforce the content policy
+	reply, findings := s.applyGuardrails(ctx, reply)
+	if len(findings) > 0 {
+		span.SetAttributes(attribute.Int("advisor.guardrail_findings", len(findings)))
+		for _, f := range findings {
+			log.Printf("advisor guardrail (%s) for chat %d: %s", f.kind, chatID, f.detail)
+		}
+	}
+	if cacheKey != "" {
+		s.answers.put(cacheKey, reply)
+	}
+
+	// 10. Persist the assistant reply
 	if err := s.convStore.AppendMessage(ctx, chatID, "assistant", reply); err != nil {
 		log.Printf("failed to store assistant reply: %v", err)
 	}
@@ -116,12 +205,99 @@ func (s *AdvisorService) Ask(ctx context.Context, chatID int64, userMessage stri
 	return reply, nil
 }
 
+// hasPriorMessages reports whether history holds anything besides the
+// question just appended to it.
+func hasPriorMessages(history []domain.ConversationMessage, question string) bool {
+	if n := len(history); n > 0 && history[n-1].Role == "user" && history[n-1].Content == question {
+		history = history[:n-1]
+	}
+	return len(history) > 0
+}
+
+// answerCacheKey returns the cache key for question, or "" when caching is
+// disabled or the market state cannot be fingerprinted.
+func (s *AdvisorService) answerCacheKey(ctx context.Context, question string, symbols []string) string {
+	if s.answers == nil {
+		return ""
+	}
+	candles, ok := s.prices.(CandleQuerier)
+	if !ok {
+		return ""
+	}
+	normalized := normalizeQuestion(question)
+	if normalized == "" {
+		return ""
+	}
+	fingerprint, ok := marketFingerprint(ctx, candles, symbols)
+	if !ok {
+		return ""
+	}
+	return normalized + "|" + fingerprint
+}
+
+// ResetConversation deletes the chat's conversation history so the next
+// question starts fresh.
+func (s *AdvisorService) ResetConversation(ctx context.Context, chatID int64) error {
+	ctx, span := s.tracer.Start(ctx, "advisor.reset-conversation")
+	defer span.End()
+	span.SetAttributes(attribute.Int64("chat_id", chatID))
+
+	eraser, ok := s.convStore.(ConversationEraser)
+	if !ok {
+		return fmt.Errorf("conversation reset not supported")
+	}
+	_, err := eraser.DeleteConversation(ctx, chatID)
+	return err
+}
+
+// History returns up to limit of the chat's most recent messages, oldest
+// first, so a client can show the conversation it is continuing.
+func (s *AdvisorService) History(ctx context.Context, chatID int64, limit int) ([]domain.ConversationMessage, error) {
+	ctx, span := s.tracer.Start(ctx, "advisor.history")
+	defer span.End()
+	span.SetAttributes(attribute.Int64("chat_id", chatID))
+
+	return s.convStore.RecentMessages(ctx, chatID, limit)
+}
+
+// SummarizeConversation asks the LLM for a short recap of the chat's recent
+// messages. It returns "" when there is no history. The recap is not stored.
+func (s *AdvisorService) SummarizeConversation(ctx context.Context, chatID int64) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "advisor.summarize-conversation")
+	defer span.End()
+	span.SetAttributes(attribute.Int64("chat_id", chatID))
+
+	history, err := s.convStore.RecentMessages(ctx, chatID, s.maxHistory)
+	if err != nil {
+		return "", err
+	}
+	if len(history) == 0 {
+		return "", nil
+	}
+
+	var transcript strings.Builder
+	for _, msg := range history {
+		transcript.WriteString(fmt.Sprintf("%s (%s): %s\n",
+			msg.Role, msg.CreatedAt.UTC().Format(time.RFC822), msg.Content))
+	}
+	reply, err := s.callLLM(ctx, []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(summaryPrompt),
+		openai.UserMessage(transcript.String()),
+	})
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("advisor unavailable: %w", err)
+	}
+	return reply, nil
+}
+
 func (s *AdvisorService) gatherContext(ctx context.Context, symbols []string) (string, error) {
 	ctx, span := s.tracer.Start(ctx, "advisor.gather-context")
 	defer span.End()
 
 	var prices []*domain.PriceSnapshot
 	var signals []domain.Signal
+	var setups []*domain.SimilarSetups
 
 	if len(symbols) > 0 {
 		for _, sym := range symbols {
@@ -141,6 +317,12 @@ func (s *AdvisorService) gatherContext(ctx context.Context, symbols []string) (s
 			if err == nil {
 				signals = append(signals, composite...)
 			}
+			if s.similar != nil {
+				similar, err := s.similar.FindSimilar(ctx, sym, s.similarInterval, similarSetupsInContext)
+				if err == nil && similar != nil {
+					setups = append(setups, similar)
+				}
+			}
 		}
 	} else {
 		var err error
@@ -158,8 +340,15 @@ func (s *AdvisorService) gatherContext(ctx context.Context, symbols []string) (s
 		}
 	}
 
+	var global string
+	if s.globalMarket != nil {
+		if snap, err := s.globalMarket.LatestGlobalMarket(ctx); err == nil {
+			global = FormatGlobalMarket(snap)
+		}
+	}
+
 	signals = uniqueSignals(signals)
-	return FormatMarketContext(prices, signals), nil
+	return global + FormatMarketContext(prices, signals) + FormatSimilarSetups(setups), nil
 }
 
 func (s *AdvisorService) buildMessages(
@@ -236,19 +425,36 @@ func uniqueSignals(in []domain.Signal) []domain.Signal {
 	return out
 }
 
+// APIKeyRotator is implemented by LLM clients that can swap credentials at
+// runtime, e.g. when a secrets backend rotates OPENAI_API_KEY.
+type APIKeyRotator interface {
+	SetAPIKey(apiKey string)
+}
+
 // openaiClient wraps the official SDK's chat completions service.
 type openaiClient struct {
+	mu     sync.RWMutex
 	client openai.Client
 }
 
 func NewOpenAIClient(apiKey string) LLMClient {
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	client := openai.NewClient(option.WithAPIKey(apiKey), option.WithHTTPClient(httpclient.New(httpclient.OpenAI, 0)))
 	return &openaiClient{client: client}
 }
 
+func (c *openaiClient) SetAPIKey(apiKey string) {
+	client := openai.NewClient(option.WithAPIKey(apiKey), option.WithHTTPClient(httpclient.New(httpclient.OpenAI, 0)))
+	c.mu.Lock()
+	c.client = client
+	c.mu.Unlock()
+}
+
 func (c *openaiClient) CreateChatCompletion(
 	ctx context.Context,
 	params openai.ChatCompletionNewParams,
 ) (*openai.ChatCompletion, error) {
-	return c.client.Chat.Completions.New(ctx, params)
+	c.mu.RLock()
+	client := c.client
+	c.mu.RUnlock()
+	return client.Chat.Completions.New(ctx, params)
 }
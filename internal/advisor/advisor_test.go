@@ -3,10 +3,11 @@ package advisor
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/openai/openai-go"
 	"go.opentelemetry.io/otel/trace"
@@ -50,6 +51,78 @@ func TestAskHappyPath(t *testing.T) {
 	}
 }
 
+func TestGatherContextIncludesSimilarSetups(t *testing.T) {
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&stubLLMClient{}, &stubPrices{price: &domain.PriceSnapshot{Symbol: "BTC", PriceUSD: 50000}}, &stubSignals{}, &stubConvStore{}, "gpt-4o-mini", 20,
+	)
+	got, err := svc.gatherContext(context.Background(), []string{"BTC"})
+	if err != nil || strings.Contains(got, "Similar Past Setups") {
+		t.Fatalf("expected no analogues without a finder, got %q (%v)", got, err)
+	}
+
+	finder := &stubSimilarFinder{}
+	svc.SetSimilarSetups(finder, "4h")
+	got, err = svc.gatherContext(context.Background(), []string{"BTC"})
+	if err != nil {
+		t.Fatalf("gather context: %v", err)
+	}
+	if !strings.Contains(got, "BTC 4h: 1 matches") || finder.interval != "4h" || finder.k != similarSetupsInContext {
+		t.Fatalf("expected analogues for BTC on 4h, got %q (interval=%s k=%d)", got, finder.interval, finder.k)
+	}
+}
+
+func TestGatherContextIncludesGlobalMarket(t *testing.T) {
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&stubLLMClient{}, &stubPrices{allPrices: []*domain.PriceSnapshot{{Symbol: "BTC", PriceUSD: 50000}}}, &stubSignals{}, &stubConvStore{}, "gpt-4o-mini", 20,
+	)
+	svc.SetGlobalMarket(stubGlobalMarket{snap: &domain.GlobalMarketSnapshot{
+		CapturedAt:            time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		TotalMarketCapUSD:     2.41e12,
+		TotalVolumeUSD:        9.1e10,
+		BTCDominancePct:       54.23,
+		ETHDominancePct:       17.3,
+		MarketCapChange24hPct: -1.25,
+	}})
+	got, err := svc.gatherContext(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("gather context: %v", err)
+	}
+	if !strings.Contains(got, "Total market cap: $2.4T (24h: -1.25%), 24h volume: $91.0B") ||
+		!strings.Contains(got, "Dominance: BTC 54.2%, ETH 17.3%") {
+		t.Fatalf("expected global market figures, got %q", got)
+	}
+
+	svc.SetGlobalMarket(stubGlobalMarket{})
+	if got, _ := svc.gatherContext(context.Background(), nil); strings.Contains(got, "Global Market") {
+		t.Fatalf("expected no global section before the first snapshot, got %q", got)
+	}
+}
+
+type stubGlobalMarket struct {
+	snap *domain.GlobalMarketSnapshot
+}
+
+func (s stubGlobalMarket) LatestGlobalMarket(context.Context) (*domain.GlobalMarketSnapshot, error) {
+	return s.snap, nil
+}
+
+type stubSimilarFinder struct {
+	interval string
+	k        int
+}
+
+func (s *stubSimilarFinder) FindSimilar(_ context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error) {
+	s.interval, s.k = interval, k
+	return &domain.SimilarSetups{
+		Symbol:   symbol,
+		Interval: interval,
+		Matches:  []domain.SimilarSetup{{Symbol: "ETH", Similarity: 0.9, WentUp: true}},
+		UpRate:   1,
+	}, nil
+}
+
 func TestAskLLMError(t *testing.T) {
 	llm := &stubLLMClient{err: errors.New("api down")}
 	store := &stubConvStore{}
@@ -160,6 +233,74 @@ func TestAskDefaultMaxHistory(t *testing.T) {
 	}
 }
 
+func TestResetConversationDeletesChatHistory(t *testing.T) {
+	store := &stubConvStore{}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&stubLLMClient{}, &stubPrices{}, &stubSignals{}, store, "gpt-4o-mini", 20,
+	)
+	_ = store.AppendMessage(context.Background(), 7, "user", "hi")
+	_ = store.AppendMessage(context.Background(), 8, "user", "other chat")
+
+	if err := svc.ResetConversation(context.Background(), 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.messages) != 1 || store.messages[0].chatID != 8 {
+		t.Fatalf("expected only chat 7 cleared, got %+v", store.messages)
+	}
+}
+
+func TestHistoryReturnsOnlyThatChat(t *testing.T) {
+	store := &stubConvStore{}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&stubLLMClient{}, &stubPrices{}, &stubSignals{}, store, "gpt-4o-mini", 20,
+	)
+	_ = store.AppendMessage(context.Background(), 7, "user", "hi")
+	_ = store.AppendMessage(context.Background(), 8, "user", "other chat")
+	_ = store.AppendMessage(context.Background(), 7, "assistant", "hello")
+
+	history, err := svc.History(context.Background(), 7, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 2 || history[0].Content != "hi" || history[1].Content != "hello" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+}
+
+func TestSummarizeConversation(t *testing.T) {
+	llm := &stubLLMClient{
+		response: &openai.ChatCompletion{
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: "- Discussed BTC"}},
+			},
+		},
+	}
+	store := &stubConvStore{}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		llm, &stubPrices{}, &stubSignals{}, store, "gpt-4o-mini", 20,
+	)
+
+	recap, err := svc.SummarizeConversation(context.Background(), 7)
+	if err != nil || recap != "" {
+		t.Fatalf("expected empty recap without history, got %q %v", recap, err)
+	}
+
+	_ = store.AppendMessage(context.Background(), 7, "user", "What about BTC?")
+	recap, err = svc.SummarizeConversation(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if recap != "- Discussed BTC" {
+		t.Fatalf("unexpected recap %q", recap)
+	}
+	if len(store.messages) != 1 {
+		t.Fatalf("recap must not be stored, got %d messages", len(store.messages))
+	}
+}
+
 // --- stubs ---
 
 type stubLLMClient struct {
@@ -192,6 +333,20 @@ func (s *stubConvStore) AppendMessage(ctx context.Context, chatID int64, role, c
 	return nil
 }
 
+func (s *stubConvStore) DeleteConversation(ctx context.Context, chatID int64) (int64, error) {
+	kept := s.messages[:0]
+	var deleted int64
+	for _, m := range s.messages {
+		if m.chatID == chatID {
+			deleted++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	s.messages = kept
+	return deleted, nil
+}
+
 func (s *stubConvStore) RecentMessages(ctx context.Context, chatID int64, limit int) ([]domain.ConversationMessage, error) {
 	if s.recentErr != nil {
 		return nil, s.recentErr
@@ -0,0 +1,106 @@
+package advisor
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// fingerprintInterval is the candle interval whose latest open times identify
+// the market state an answer was generated against.
+const fingerprintInterval = "5m"
+
+// maxCachedAnswers bounds the answer cache; expired entries are pruned first.
+const maxCachedAnswers = 1000
+
+// CandleQuerier is implemented by price sources that expose stored candles.
+// The advisor uses it to fingerprint market state for answer caching.
+type CandleQuerier interface {
+	GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error)
+}
+
+type cachedAnswer struct {
+	reply     string
+	expiresAt time.Time
+}
+
+// answerCache holds advisor replies for a short TTL so the same question
+// asked from many chats against unchanged market data costs one LLM call.
+type answerCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedAnswer
+	now     func() time.Time
+}
+
+func newAnswerCache(ttl time.Duration) *answerCache {
+	return &answerCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedAnswer),
+		now:     time.Now,
+	}
+}
+
+func (c *answerCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.reply, true
+}
+
+func (c *answerCache) put(key, reply string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxCachedAnswers {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if len(c.entries) >= maxCachedAnswers {
+		// Still full of live entries: drop an arbitrary one.
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = cachedAnswer{reply: reply, expiresAt: now.Add(c.ttl)}
+}
+
+// normalizeQuestion folds case, whitespace and trailing punctuation so trivially
+// different phrasings of the same question share a cache entry.
+func normalizeQuestion(question string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(question)), " ")
+	return strings.TrimRight(normalized, "?!. ")
+}
+
+// marketFingerprint returns the latest fingerprintInterval candle open time of
+// each symbol. The second result is false when any lookup fails, in which case
+// the answer must not be cached.
+func marketFingerprint(ctx context.Context, candles CandleQuerier, symbols []string) (string, bool) {
+	if len(symbols) == 0 {
+		symbols = domain.SupportedSymbols()
+	}
+	parts := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		latest, err := candles.GetCandles(ctx, symbol, fingerprintInterval, 1)
+		if err != nil || len(latest) == 0 || latest[0] == nil {
+			return "", false
+		}
+		parts = append(parts, symbol+"@"+strconv.FormatInt(latest[0].OpenTime.Unix(), 10))
+	}
+	return strings.Join(parts, ","), true
+}
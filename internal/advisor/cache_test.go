@@ -0,0 +1,154 @@
+package advisor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/openai/openai-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAskServesCachedAnswerAcrossChats(t *testing.T) {
+	llm := &countingLLMClient{reply: "BTC looks bullish"}
+	store := &stubConvStore{}
+	prices := &stubCandlePrices{latest: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		llm, prices, &stubSignals{}, store, "gpt-4o-mini", 20,
+	)
+	svc.SetAnswerCacheTTL(time.Minute)
+
+	for i, question := range []string{"What do you think about BTC?", "  what do you think about   btc "} {
+		reply, err := svc.Ask(context.Background(), int64(100+i), question)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reply != "BTC looks bullish" {
+			t.Fatalf("unexpected reply %q", reply)
+		}
+	}
+	if llm.calls != 1 {
+		t.Fatalf("expected one LLM call, got %d", llm.calls)
+	}
+	if len(store.messages) != 4 || store.messages[3].chatID != 101 || store.messages[3].role != "assistant" {
+		t.Fatalf("expected cached reply stored in the second chat, got %+v", store.messages)
+	}
+
+	prices.latest = prices.latest.Add(5 * time.Minute)
+	if _, err := svc.Ask(context.Background(), 102, "What do you think about BTC?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.calls != 2 {
+		t.Fatalf("expected a new candle to bypass the cache, got %d calls", llm.calls)
+	}
+}
+
+func TestAskDoesNotShareAnswersThatDependOnHistory(t *testing.T) {
+	llm := &countingLLMClient{reply: "BTC looks bullish"}
+	store := &stubConvStore{messages: []storedMsg{
+		{chatID: 200, role: "user", content: "I hold 3 BTC bought at 20k"},
+		{chatID: 200, role: "assistant", content: "Noted"},
+	}}
+	prices := &stubCandlePrices{latest: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		llm, prices, &stubSignals{}, store, "gpt-4o-mini", 20,
+	)
+	svc.SetAnswerCacheTTL(time.Minute)
+
+	if _, err := svc.Ask(context.Background(), 200, "What do you think about BTC?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Ask(context.Background(), 201, "What do you think about BTC?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.calls != 2 {
+		t.Fatalf("expected a reply built on chat history not to be cached, got %d calls", llm.calls)
+	}
+
+	// Chat 201 opened with the question, so its reply is shared, but not
+	// with chat 200, whose history would change the answer.
+	if _, err := svc.Ask(context.Background(), 202, "What do you think about BTC?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.calls != 2 {
+		t.Fatalf("expected the history-free reply to be served from cache, got %d calls", llm.calls)
+	}
+	if _, err := svc.Ask(context.Background(), 200, "What do you think about BTC?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if llm.calls != 3 {
+		t.Fatalf("expected a chat with history to bypass the cache, got %d calls", llm.calls)
+	}
+}
+
+func TestAskSkipsCacheWithoutFingerprint(t *testing.T) {
+	llm := &countingLLMClient{reply: "ok"}
+	prices := &stubCandlePrices{err: errors.New("db down")}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		llm, prices, &stubSignals{}, &stubConvStore{}, "gpt-4o-mini", 20,
+	)
+	svc.SetAnswerCacheTTL(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := svc.Ask(context.Background(), 1, "BTC?"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if llm.calls != 2 {
+		t.Fatalf("expected no caching when candles are unavailable, got %d calls", llm.calls)
+	}
+}
+
+func TestAnswerCacheExpires(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	cache := newAnswerCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.put("q", "a")
+	if reply, ok := cache.get("q"); !ok || reply != "a" {
+		t.Fatalf("expected cached answer, got %q %v", reply, ok)
+	}
+	now = now.Add(time.Minute)
+	if _, ok := cache.get("q"); ok {
+		t.Fatal("expected entry to expire after ttl")
+	}
+}
+
+func TestNormalizeQuestion(t *testing.T) {
+	if got := normalizeQuestion("  What about   BTC?! "); got != "what about btc" {
+		t.Fatalf("unexpected normalized question %q", got)
+	}
+}
+
+type countingLLMClient struct {
+	reply string
+	calls int
+}
+
+func (c *countingLLMClient) CreateChatCompletion(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	c.calls++
+	return &openai.ChatCompletion{
+		Choices: []openai.ChatCompletionChoice{
+			{Message: openai.ChatCompletionMessage{Content: c.reply}},
+		},
+	}, nil
+}
+
+type stubCandlePrices struct {
+	stubPrices
+	latest time.Time
+	err    error
+}
+
+func (s *stubCandlePrices) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*domain.Candle{{Symbol: symbol, Interval: interval, OpenTime: s.latest}}, nil
+}
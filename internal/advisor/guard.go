@@ -0,0 +1,260 @@
+package advisor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// GuardrailMode selects what happens to an advisor reply that breaks the
+// content policy.
+type GuardrailMode string
+
+const (
+	// GuardrailsOff returns replies unchanged.
+	GuardrailsOff GuardrailMode = "off"
+	// GuardrailsRewrite drops offending sentences and corrects stated prices.
+	GuardrailsRewrite GuardrailMode = "rewrite"
+	// GuardrailsBlock replaces the whole reply with blockedReply.
+	GuardrailsBlock GuardrailMode = "block"
+)
+
+// DefaultPriceTolerancePct is how far a price stated in a reply may be from
+// the live price before it is treated as hallucinated.
+const DefaultPriceTolerancePct = 5.0
+
+const (
+	blockedReply = "I can share market data and signal interpretation, but not personal trade instructions. " +
+		"Try /price or /signals for the raw data."
+	rewriteNotice = "(Parts of this answer were removed: it is market information, not financial advice.)"
+)
+
+// GuardrailPolicy configures the post-processing applied to advisor replies.
+type GuardrailPolicy struct {
+	Mode              GuardrailMode
+	PriceTolerancePct float64
+}
+
+// ParseGuardrailMode validates a configured mode name.
+func ParseGuardrailMode(raw string) (GuardrailMode, bool) {
+	switch mode := GuardrailMode(strings.ToLower(strings.TrimSpace(raw))); mode {
+	case GuardrailsOff, GuardrailsRewrite, GuardrailsBlock:
+		return mode, true
+	default:
+		return "", false
+	}
+}
+
+const (
+	findingAdvice   = "financial_advice"
+	findingLeverage = "leverage"
+	findingPrice    = "price"
+)
+
+type guardFinding struct {
+	kind   string
+	detail string
+}
+
+var (
+	advicePatterns = compileAll(
+		`(?i)\byou (?:should|must|need to|have to) (?:definitely |really )?(?:buy|sell|short|long|go long|go short|invest|get in|get out|ape)\b`,
+		`(?i)\b(?:i|we) (?:strongly )?(?:recommend|advise|suggest) (?:that you |you )?(?:buy|sell|short|buying|selling|shorting|investing|going long|going short)\b`,
+		`(?i)\b(?:buy|sell) (?:it |this |some )?(?:now|immediately|today)\b`,
+		`(?i)\bguaranteed (?:profits?|returns?|gains?)\b`,
+		`(?i)\b(?:can't|cannot|won't) (?:lose|go wrong)\b`,
+		`(?i)\bgo all[- ]in\b`,
+	)
+	leveragePatterns = compileAll(
+		`(?i)\b\d+(?:\.\d+)?\s?x\s+(?:leverage|long|short|margin)\b`,
+		`(?i)\b(?:use|using|add|adding|take|open|with)\s+(?:some\s+|high\s+|more\s+)?(?:leverage|margin)\b`,
+		`(?i)\bleveraged (?:long|short|position|trade|bet)\b`,
+	)
+	currentPriceCue = regexp.MustCompile(`(?i)\b(?:price|trading|priced|currently|now|sits|sitting|hovering|is at|at around)\b`)
+	// Levels, targets and hypotheticals legitimately differ from spot.
+	priceLevelCue = regexp.MustCompile(`(?i)\b(?:target|support|resistance|stop|level|if|could|might|would|reach|break|above|below|from|high|low|ath)\b`)
+)
+
+// priceClaimCache holds the price claim pattern for one version of the
+// tracked assets.
+var priceClaimCache struct {
+	sync.Mutex
+	version uint64
+	pattern *regexp.Regexp
+}
+
+// priceClaimPattern finds "<SYMBOL> ... $<amount>" within a sentence; the
+// text between them decides whether it states the current price. It is
+// rebuilt when the tracked assets change.
+func priceClaimPattern() *regexp.Regexp {
+	priceClaimCache.Lock()
+	defer priceClaimCache.Unlock()
+	version := domain.AssetsVersion()
+	if priceClaimCache.pattern == nil || priceClaimCache.version != version {
+		symbols := domain.SupportedSymbols()
+		for i, symbol := range symbols {
+			symbols[i] = regexp.QuoteMeta(symbol)
+		}
+		priceClaimCache.pattern = regexp.MustCompile(
+			`\b(` + strings.Join(symbols, "|") + `)\b([^$\n.!?]{0,30})\$([0-9][0-9,]*(?:\.[0-9]+)?)([kK]\b)?`,
+		)
+		priceClaimCache.version = version
+	}
+	return priceClaimCache.pattern
+}
+
+func compileAll(patterns ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		out = append(out, regexp.MustCompile(p))
+	}
+	return out
+}
+
+// applyGuardrails checks reply against the policy and returns the reply to
+// send along with what was found.
+func (s *AdvisorService) applyGuardrails(ctx context.Context, reply string) (string, []guardFinding) {
+	if s.guardrails.Mode == "" || s.guardrails.Mode == GuardrailsOff {
+		return reply, nil
+	}
+
+	reply, findings := s.checkPrices(ctx, reply)
+
+	var kept []string
+	removed := false
+	for _, sentence := range splitSentences(reply) {
+		if kind := policyViolation(sentence); kind != "" {
+			findings = append(findings, guardFinding{kind: kind, detail: strings.TrimSpace(sentence)})
+			removed = true
+			continue
+		}
+		kept = append(kept, sentence)
+	}
+	if len(findings) == 0 {
+		return reply, nil
+	}
+	if s.guardrails.Mode == GuardrailsBlock {
+		return blockedReply, findings
+	}
+	if !removed {
+		return reply, findings
+	}
+	rewritten := strings.TrimSpace(strings.Join(kept, ""))
+	if rewritten == "" {
+		return blockedReply, findings
+	}
+	return rewritten + "\n\n" + rewriteNotice, findings
+}
+
+func policyViolation(sentence string) string {
+	for _, p := range advicePatterns {
+		if p.MatchString(sentence) {
+			return findingAdvice
+		}
+	}
+	for _, p := range leveragePatterns {
+		if p.MatchString(sentence) {
+			return findingLeverage
+		}
+	}
+	return ""
+}
+
+// checkPrices compares current prices stated in reply with the live price and
+// replaces those outside the tolerance.
+func (s *AdvisorService) checkPrices(ctx context.Context, reply string) (string, []guardFinding) {
+	tolerance := s.guardrails.PriceTolerancePct
+	if tolerance <= 0 {
+		tolerance = DefaultPriceTolerancePct
+	}
+
+	var findings []guardFinding
+	live := make(map[string]float64)
+	var sb strings.Builder
+	last := 0
+	for _, m := range priceClaimPattern().FindAllStringSubmatchIndex(reply, -1) {
+		symbol := reply[m[2]:m[3]]
+		between := reply[m[4]:m[5]]
+		if !currentPriceCue.MatchString(between) || priceLevelCue.MatchString(between) {
+			continue
+		}
+		claimed, err := strconv.ParseFloat(strings.ReplaceAll(reply[m[6]:m[7]], ",", ""), 64)
+		if err != nil {
+			continue
+		}
+		if m[8] >= 0 {
+			claimed *= 1000
+		}
+
+		actual, ok := live[symbol]
+		if !ok {
+			snapshot, err := s.prices.GetCurrentPrice(ctx, symbol)
+			if err != nil || snapshot == nil || snapshot.PriceUSD <= 0 {
+				continue
+			}
+			actual = snapshot.PriceUSD
+			live[symbol] = actual
+		}
+		if math.Abs(claimed-actual)/actual*100 <= tolerance {
+			continue
+		}
+
+		end := m[7]
+		if m[8] >= 0 {
+			end = m[9]
+		}
+		findings = append(findings, guardFinding{
+			kind:   findingPrice,
+			detail: fmt.Sprintf("%s stated $%s, live %s", symbol, reply[m[6]:end], domain.FormatPrice(symbol, actual)),
+		})
+		// The dollar sign sits just before the amount group.
+		sb.WriteString(reply[last : m[6]-1])
+		sb.WriteString(domain.FormatPrice(symbol, actual))
+		last = end
+	}
+	if len(findings) == 0 {
+		return reply, nil
+	}
+	sb.WriteString(reply[last:])
+	return sb.String(), findings
+}
+
+// splitSentences cuts text after sentence punctuation followed by whitespace
+// and after newlines. Joining the pieces gives back text unchanged.
+func splitSentences(text string) []string {
+	var out []string
+	runes := []rune(text)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		end := -1
+		switch {
+		case r == '\n':
+			end = i + 1
+		case r == '.' || r == '!' || r == '?':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				end = i + 1
+				for end < len(runes) && unicode.IsSpace(runes[end]) {
+					end++
+				}
+			}
+		}
+		if end < 0 {
+			continue
+		}
+		out = append(out, string(runes[start:end]))
+		start = end
+		i = end - 1
+	}
+	if start < len(runes) {
+		out = append(out, string(runes[start:]))
+	}
+	return out
+}
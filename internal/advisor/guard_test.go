@@ -0,0 +1,112 @@
+package advisor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/openai/openai-go"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newGuardedService(mode GuardrailMode, prices *stubPrices) *AdvisorService {
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&stubLLMClient{}, prices, &stubSignals{}, &stubConvStore{}, "gpt-4o-mini", 20,
+	)
+	svc.SetGuardrails(GuardrailPolicy{Mode: mode})
+	return svc
+}
+
+func TestGuardrailsRewriteDropsAdviceAndLeverage(t *testing.T) {
+	svc := newGuardedService(GuardrailsRewrite, &stubPrices{})
+	reply := "BTC has two bullish RSI signals. You should buy now. Consider 10x leverage on the breakout. Risk level 3."
+
+	got, findings := svc.applyGuardrails(context.Background(), reply)
+	if len(findings) != 2 || findings[0].kind != findingAdvice || findings[1].kind != findingLeverage {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+	if strings.Contains(got, "buy now") || strings.Contains(got, "leverage") {
+		t.Fatalf("offending sentences kept: %q", got)
+	}
+	if !strings.HasPrefix(got, "BTC has two bullish RSI signals. Risk level 3.") || !strings.HasSuffix(got, rewriteNotice) {
+		t.Fatalf("unexpected rewrite: %q", got)
+	}
+}
+
+func TestGuardrailsBlockReplacesReply(t *testing.T) {
+	svc := newGuardedService(GuardrailsBlock, &stubPrices{})
+	got, findings := svc.applyGuardrails(context.Background(), "Guaranteed profits if you hold ETH.")
+	if len(findings) != 1 || got != blockedReply {
+		t.Fatalf("expected blocked reply, got %q %+v", got, findings)
+	}
+}
+
+func TestGuardrailsCorrectHallucinatedPrice(t *testing.T) {
+	prices := &stubPrices{price: &domain.PriceSnapshot{Symbol: "BTC", PriceUSD: 50000}}
+	svc := newGuardedService(GuardrailsRewrite, prices)
+
+	got, findings := svc.applyGuardrails(context.Background(), "BTC is currently trading at $64,000. Resistance at $52k.")
+	if len(findings) != 1 || findings[0].kind != findingPrice {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+	if got != "BTC is currently trading at $50,000.00. Resistance at $52k." {
+		t.Fatalf("unexpected rewrite: %q", got)
+	}
+
+	got, findings = svc.applyGuardrails(context.Background(), "BTC is now around $50.9k.")
+	if len(findings) != 0 || got != "BTC is now around $50.9k." {
+		t.Fatalf("price within tolerance should pass, got %q %+v", got, findings)
+	}
+}
+
+func TestGuardrailsOffLeavesReply(t *testing.T) {
+	svc := newGuardedService(GuardrailsOff, &stubPrices{})
+	reply := "You should buy now with 20x leverage."
+	if got, findings := svc.applyGuardrails(context.Background(), reply); got != reply || findings != nil {
+		t.Fatalf("expected reply unchanged, got %q %+v", got, findings)
+	}
+}
+
+func TestAskStoresGuardedReply(t *testing.T) {
+	llm := &stubLLMClient{
+		response: &openai.ChatCompletion{
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: "You should short ETH today."}},
+			},
+		},
+	}
+	store := &stubConvStore{}
+	svc := NewAdvisorService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		llm, &stubPrices{}, &stubSignals{}, store, "gpt-4o-mini", 20,
+	)
+	svc.SetGuardrails(GuardrailPolicy{Mode: GuardrailsRewrite})
+
+	reply, err := svc.Ask(context.Background(), 1, "ETH?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply != blockedReply || store.messages[1].content != blockedReply {
+		t.Fatalf("expected fully removed reply to fall back, got %q", reply)
+	}
+}
+
+func TestParseGuardrailMode(t *testing.T) {
+	if mode, ok := ParseGuardrailMode(" Block "); !ok || mode != GuardrailsBlock {
+		t.Fatalf("unexpected mode %q %v", mode, ok)
+	}
+	if _, ok := ParseGuardrailMode("strict"); ok {
+		t.Fatal("expected unknown mode to be rejected")
+	}
+}
+
+func TestSplitSentencesRoundTrips(t *testing.T) {
+	text := "BTC at $50.5k looks firm. ETH?\nSOL lags!  Done"
+	parts := splitSentences(text)
+	if strings.Join(parts, "") != text || len(parts) != 4 {
+		t.Fatalf("unexpected split: %q", parts)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const tradingPhilosophy = `You are a crypto trading advisor bot. Your role is to interpret technical analysis signals and market data, NOT to generate signals yourself.
@@ -24,7 +24,18 @@ Rules:
 - Do not provide financial advice disclaimers on every message. The user understands this is informational.
 - When asked about an asset, summarize: current price, recent signals, and your interpretation.
 - If no signals exist for an asset, say so honestly rather than speculating.
-- If fundamentals/sentiment composite signals are present, include them in your interpretation.`
+- If fundamentals/sentiment composite signals are present, include them in your interpretation.
+- Similar past setups are historical analogues, not forecasts. Mention how many there were when citing them.`
+
+// Past analogues the advisor fetches per symbol, and how many of the closest
+// it lists individually.
+const (
+	similarSetupsInContext = 10
+	similarSetupsListed    = 3
+)
+
+const summaryPrompt = `Summarize this conversation between a user and a crypto trading advisor bot in at most 5 short bullet points.
+Cover the assets discussed, the signals and views mentioned, and any open questions. Do not add new analysis.`
 
 func BuildSystemPrompt(marketContext string) string {
 	var sb strings.Builder
@@ -42,8 +53,8 @@ func FormatMarketContext(prices []*domain.PriceSnapshot, signals []domain.Signal
 	if len(prices) > 0 {
 		sb.WriteString("\nCurrent Prices:\n")
 		for _, p := range prices {
-			sb.WriteString(fmt.Sprintf("  %s: $%.2f (24h: %+.2f%%, vol: $%.0f)\n",
-				p.Symbol, p.PriceUSD, p.Change24hPct, p.Volume24h))
+			sb.WriteString(fmt.Sprintf("  %s: %s (24h: %s, vol: %s)\n",
+				p.Symbol, domain.FormatPrice(p.Symbol, p.PriceUSD), p.Change24hPct, p.Volume24h.Compact()))
 		}
 	}
 
@@ -63,3 +74,51 @@ func FormatMarketContext(prices []*domain.PriceSnapshot, signals []domain.Signal
 	}
 	return sb.String()
 }
+
+// FormatGlobalMarket renders the whole-market snapshot for the market
+// context. It returns "" for a nil snapshot.
+func FormatGlobalMarket(snap *domain.GlobalMarketSnapshot) string {
+	if snap == nil {
+		return ""
+	}
+	return fmt.Sprintf("\nGlobal Market (as of %s):\n  Total market cap: %s (24h: %s), 24h volume: %s\n  Dominance: BTC %.1f%%, ETH %.1f%%\n",
+		snap.CapturedAt.UTC().Format(time.RFC822),
+		domain.Money(snap.TotalMarketCapUSD).Compact(),
+		domain.Percent(snap.MarketCapChange24hPct),
+		domain.Money(snap.TotalVolumeUSD).Compact(),
+		snap.BTCDominancePct, snap.ETHDominancePct)
+}
+
+// FormatSimilarSetups renders past analogues of the mentioned symbols for the
+// market context. It returns "" when there are none.
+func FormatSimilarSetups(setups []*domain.SimilarSetups) string {
+	var sb strings.Builder
+	for _, set := range setups {
+		if set == nil || len(set.Matches) == 0 {
+			continue
+		}
+		if sb.Len() == 0 {
+			sb.WriteString("\nSimilar Past Setups (nearest historical feature states, outcome over the target horizon):\n")
+		}
+		sb.WriteString(fmt.Sprintf("  %s %s: %d matches, %.0f%% went up", set.Symbol, set.Interval, len(set.Matches), set.UpRate*100))
+		if set.MeanReturn != nil {
+			sb.WriteString(fmt.Sprintf(", mean return %+.2f%% over %d bars", *set.MeanReturn*100, set.TargetHours))
+		}
+		sb.WriteString("\n")
+		for i, m := range set.Matches {
+			if i >= similarSetupsListed {
+				break
+			}
+			outcome := "DOWN"
+			if m.WentUp {
+				outcome = "UP"
+			}
+			sb.WriteString(fmt.Sprintf("    %s %s similarity=%.2f %s", m.Symbol, m.OpenTime.UTC().Format("2006-01-02 15:04"), m.Similarity, outcome))
+			if m.RealizedReturn != nil {
+				sb.WriteString(fmt.Sprintf(" %+.2f%%", *m.RealizedReturn*100))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
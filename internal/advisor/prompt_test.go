@@ -3,8 +3,9 @@ package advisor
 import (
 	"strings"
 	"testing"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestBuildSystemPromptContainsPhilosophy(t *testing.T) {
@@ -32,7 +33,7 @@ func TestFormatMarketContextWithPricesAndSignals(t *testing.T) {
 	}
 
 	ctx := FormatMarketContext(prices, signals)
-	if !strings.Contains(ctx, "BTC: $50000.00") {
+	if !strings.Contains(ctx, "BTC: $50,000.00") {
 		t.Fatal("expected BTC price in context")
 	}
 	if !strings.Contains(ctx, "RSI") {
@@ -58,10 +59,40 @@ func TestFormatMarketContextPricesOnly(t *testing.T) {
 		{Symbol: "ETH", PriceUSD: 3000, Change24hPct: -1.2, Volume24h: 5e8},
 	}
 	ctx := FormatMarketContext(prices, nil)
-	if !strings.Contains(ctx, "ETH: $3000.00") {
+	if !strings.Contains(ctx, "ETH: $3,000.00") {
 		t.Fatal("expected ETH price")
 	}
 	if strings.Contains(ctx, "Active Signals") {
 		t.Fatal("should not contain signals section when no signals")
 	}
 }
+
+func TestFormatSimilarSetups(t *testing.T) {
+	if got := FormatSimilarSetups([]*domain.SimilarSetups{nil, {Symbol: "ETH"}}); got != "" {
+		t.Fatalf("expected nothing without matches, got %q", got)
+	}
+
+	ret := 0.0152
+	mean := 0.008
+	got := FormatSimilarSetups([]*domain.SimilarSetups{{
+		Symbol:      "BTC",
+		Interval:    "1h",
+		TargetHours: 4,
+		UpRate:      0.75,
+		MeanReturn:  &mean,
+		Matches: []domain.SimilarSetup{
+			{Symbol: "ETH", OpenTime: time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC), Similarity: 0.97, WentUp: true, RealizedReturn: &ret},
+			{Symbol: "BTC", Similarity: 0.9},
+			{Symbol: "SOL", Similarity: 0.8, WentUp: true},
+			{Symbol: "XRP", Similarity: 0.7, WentUp: true},
+		},
+	}})
+	for _, want := range []string{"Similar Past Setups", "BTC 1h: 4 matches, 75% went up, mean return +0.80% over 4 bars", "ETH 2026-03-02 14:00 similarity=0.97 UP +1.52%", "BTC 0001-01-01 00:00 similarity=0.90 DOWN"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "XRP") {
+		t.Fatalf("expected only the closest matches listed, got:\n%s", got)
+	}
+}
@@ -3,7 +3,7 @@ package advisor
 import (
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 // ExtractSymbols scans the user message for mentions of supported crypto symbols.
@@ -17,7 +17,7 @@ func ExtractSymbols(text string) []string {
 	seen := make(map[string]bool)
 	var result []string
 	for _, w := range words {
-		if _, ok := domain.CoinGeckoID[w]; ok && !seen[w] {
+		if domain.IsSupportedSymbol(w) && !seen[w] {
 			seen[w] = true
 			result = append(result, w)
 		}
@@ -0,0 +1,265 @@
+// Package alerttemplate renders the text of signal alerts from Go
+// templates, so a deployment can change the wording, add emoji or translate
+// Telegram alerts and webhook payloads without a code change.
+package alerttemplate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// DefaultSignalTemplate renders one signal as a single alert line.
+const DefaultSignalTemplate = `#{{.ID}} {{.Symbol}} {{.Interval}} {{upper .Indicator}} {{direction .Direction}} risk {{.Risk}} at {{time .Timestamp}}`
+
+// Messages maps message keys to localized text. Templates look keys up with
+// {{t "key"}}; alert.header heads every Telegram alert and direction.<dir>
+// names each direction.
+type Messages map[string]string
+
+// DefaultMessages are the built-in English messages. A messages file only
+// needs the keys it changes.
+var DefaultMessages = Messages{
+	"alert.header":    "Proactive signal alert:",
+	"direction.long":  "LONG",
+	"direction.short": "SHORT",
+	"direction.hold":  "HOLD",
+}
+
+// Sample is the signal templates are test-rendered with when they are
+// loaded, so mistakes surface at startup rather than on the first alert.
+var Sample = domain.Signal{
+	ID:        1,
+	Symbol:    "BTC",
+	Interval:  "1h",
+	Indicator: domain.IndicatorRSI,
+	Timestamp: time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC),
+	Risk:      domain.RiskLevel3,
+	Direction: domain.DirectionLong,
+	Details:   "rsi 28.10 crossed below 30",
+}
+
+// Data is what templates are executed with. Signal fields are promoted, so
+// templates use {{.Symbol}}. Prediction holds the key=value pairs ML signals
+// carry in Details, such as prob_up and model_version; it is empty for
+// classic signals. Text is the signal's rendered alert line, set for webhook
+// payloads only.
+type Data struct {
+	domain.Signal
+	Prediction map[string]string
+	Text       string
+}
+
+// Renderer renders alert text with one signal template and one set of
+// messages.
+type Renderer struct {
+	signal   *template.Template
+	messages Messages
+}
+
+// New parses signalTemplate, or DefaultSignalTemplate when it is blank, with
+// messages layered over DefaultMessages.
+func New(signalTemplate string, messages Messages) (*Renderer, error) {
+	merged := make(Messages, len(DefaultMessages)+len(messages))
+	for k, v := range DefaultMessages {
+		merged[k] = v
+	}
+	for k, v := range messages {
+		merged[k] = v
+	}
+	r := &Renderer{messages: merged}
+	if strings.TrimSpace(signalTemplate) == "" {
+		signalTemplate = DefaultSignalTemplate
+	}
+	tmpl, err := r.Parse("signal", signalTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parse alert template: %w", err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, Data{Signal: Sample, Prediction: PredictionFields(Sample.Details)}); err != nil {
+		return nil, fmt.Errorf("render alert template: %w", err)
+	}
+	r.signal = tmpl
+	return r, nil
+}
+
+// Default returns a renderer with the built-in template and messages.
+func Default() *Renderer {
+	r, err := New("", nil)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Load builds a renderer from deployment settings. The template is read from
+// templateFile when set, else taken from inline. messagesFile, when set, is
+// a JSON object of message keys to text.
+func Load(inline, templateFile, messagesFile string) (*Renderer, error) {
+	text := inline
+	if templateFile != "" {
+		b, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("read alert template: %w", err)
+		}
+		text = string(b)
+	}
+	var messages Messages
+	if messagesFile != "" {
+		b, err := os.ReadFile(messagesFile)
+		if err != nil {
+			return nil, fmt.Errorf("read alert messages: %w", err)
+		}
+		if err := json.Unmarshal(b, &messages); err != nil {
+			return nil, fmt.Errorf("parse alert messages: %w", err)
+		}
+	}
+	return New(text, messages)
+}
+
+// Parse parses a template with the alert functions, treating missing map
+// keys as empty:
+//   - json, upper and lower: as for webhook payloads
+//   - t: the message for a key, formatted with any further arguments
+//   - direction: the localized name of a direction
+//   - emoji: 🟢 for long, 🔴 for short and ⚪ otherwise
+//   - riskEmoji: 🟢 for risk 1-2, 🟡 for 3 and 🔴 for 4-5
+//   - time: a time in UTC, formatted like "02 Jan 26 15:04 UTC". Alerts
+//     rendered with AlertIn use the chat's time zone instead.
+func (r *Renderer) Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(r.funcs()).Option("missingkey=zero").Parse(text)
+}
+
+func (r *Renderer) funcs() template.FuncMap {
+	return template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+		"lower": func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+		"t":     r.Message,
+		"direction": func(v any) string {
+			d := strings.ToLower(fmt.Sprint(v))
+			if msg, ok := r.messages["direction."+d]; ok {
+				return msg
+			}
+			return strings.ToUpper(d)
+		},
+		"emoji": func(v any) string {
+			switch domain.SignalDirection(strings.ToLower(fmt.Sprint(v))) {
+			case domain.DirectionLong:
+				return "🟢"
+			case domain.DirectionShort:
+				return "🔴"
+			}
+			return "⚪"
+		},
+		"riskEmoji": func(risk domain.RiskLevel) string {
+			switch {
+			case risk <= domain.RiskLevel2:
+				return "🟢"
+			case risk == domain.RiskLevel3:
+				return "🟡"
+			}
+			return "🔴"
+		},
+		"time": func(t time.Time) string { return t.UTC().Format(time.RFC822) },
+	}
+}
+
+// Message returns the text for key, formatted with args when there are any.
+// An unknown key comes back as itself.
+func (r *Renderer) Message(key string, args ...any) string {
+	msg, ok := r.messages[key]
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Data returns the template data for sig, including its alert line.
+func (r *Renderer) Data(sig domain.Signal) Data {
+	return Data{Signal: sig, Prediction: PredictionFields(sig.Details), Text: r.Signal(sig)}
+}
+
+// Signal renders sig's alert line. A template that fails on this signal is
+// logged and the built-in line is used instead, so an alert is never lost
+// to a template mistake.
+func (r *Renderer) Signal(sig domain.Signal) string {
+	return r.signalIn(r.signal, sig, time.UTC)
+}
+
+func (r *Renderer) signalIn(tmpl *template.Template, sig domain.Signal, loc *time.Location) string {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, Data{Signal: sig, Prediction: PredictionFields(sig.Details)}); err != nil {
+		log.Printf("alert template failed for signal %d: %v", sig.ID, err)
+		return fallbackLine(sig, loc)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// Alert renders a Telegram alert for signals: the header, then a line per
+// signal.
+func (r *Renderer) Alert(signals []domain.Signal) string {
+	return r.AlertIn(signals, time.UTC)
+}
+
+// AlertIn renders an alert like Alert, with times shown in loc. A nil loc
+// is UTC.
+func (r *Renderer) AlertIn(signals []domain.Signal, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	tmpl := r.signal
+	if loc != time.UTC {
+		if clone, err := r.signal.Clone(); err == nil {
+			tmpl = clone.Funcs(template.FuncMap{"time": func(t time.Time) string { return t.In(loc).Format(time.RFC822) }})
+		}
+	}
+	lines := make([]string, 0, len(signals)+1)
+	lines = append(lines, r.Message("alert.header"))
+	for _, s := range signals {
+		lines = append(lines, r.signalIn(tmpl, s, loc))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func fallbackLine(sig domain.Signal, loc *time.Location) string {
+	return fmt.Sprintf(
+		"#%d %s %s %s %s risk %d at %s",
+		sig.ID,
+		sig.Symbol,
+		sig.Interval,
+		strings.ToUpper(sig.Indicator),
+		strings.ToUpper(string(sig.Direction)),
+		sig.Risk,
+		sig.Timestamp.In(loc).Format(time.RFC822),
+	)
+}
+
+// PredictionFields parses the model_key=...;prob_up=... details of ML
+// signals. Details without a model_key are not ML details.
+func PredictionFields(details string) map[string]string {
+	fields := map[string]string{}
+	for _, part := range strings.Split(details, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if _, ok := fields["model_key"]; !ok {
+		return map[string]string{}
+	}
+	return fields
+}
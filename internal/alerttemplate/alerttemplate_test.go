@@ -0,0 +1,115 @@
+package alerttemplate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestDefaultRendersBuiltInLine(t *testing.T) {
+	sig := domain.Signal{
+		ID:        42,
+		Symbol:    "ETH",
+		Interval:  "4h",
+		Indicator: domain.IndicatorMACD,
+		Direction: domain.DirectionShort,
+		Risk:      domain.RiskLevel4,
+		Timestamp: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	r := Default()
+	if got, want := r.Signal(sig), fallbackLine(sig, time.UTC); got != want {
+		t.Fatalf("default line = %q, want %q", got, want)
+	}
+	alert := r.Alert([]domain.Signal{sig, sig})
+	lines := strings.Split(alert, "\n")
+	if len(lines) != 3 || lines[0] != "Proactive signal alert:" {
+		t.Fatalf("unexpected alert: %q", alert)
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("load zone: %v", err)
+	}
+	if got := r.AlertIn([]domain.Signal{sig}, tokyo); !strings.HasSuffix(got, "at 01 Mar 26 19:00 JST") {
+		t.Fatalf("expected the alert in Tokyo time, got %q", got)
+	}
+	if got := r.Signal(sig); !strings.HasSuffix(got, "at 01 Mar 26 10:00 UTC") {
+		t.Fatalf("expected the shared template to stay in UTC, got %q", got)
+	}
+}
+
+func TestCustomTemplateAndMessages(t *testing.T) {
+	r, err := New(
+		`{{emoji .Direction}} {{.Symbol}} {{direction .Direction}} {{riskEmoji .Risk}}{{with .Prediction.prob_up}} {{t "alert.prob" .}}{{end}}`,
+		Messages{"alert.header": "Neues Signal:", "direction.short": "VERKAUFEN", "alert.prob": "p=%s"},
+	)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	sig := domain.Signal{
+		Symbol:    "ETH",
+		Direction: domain.DirectionShort,
+		Risk:      domain.RiskLevel5,
+		Details:   "model_key=ensemble_v1;model_version=3;prob_up=0.3100",
+	}
+	if got := r.Alert([]domain.Signal{sig}); got != "Neues Signal:\n🔴 ETH VERKAUFEN 🔴 p=0.3100" {
+		t.Fatalf("unexpected alert: %q", got)
+	}
+	sig.Direction, sig.Risk, sig.Details = domain.DirectionLong, domain.RiskLevel1, ""
+	if got := r.Signal(sig); got != "🟢 ETH LONG 🟢" {
+		t.Fatalf("expected untranslated keys to keep their defaults, got %q", got)
+	}
+}
+
+func TestNewRejectsBrokenTemplates(t *testing.T) {
+	for _, tmpl := range []string{`{{.Symbol`, `{{.Nope}}`, `{{nope .Symbol}}`} {
+		if _, err := New(tmpl, nil); err == nil {
+			t.Fatalf("expected %q to be rejected", tmpl)
+		}
+	}
+}
+
+func TestSignalFallsBackWhenTemplateFails(t *testing.T) {
+	r, err := New(`{{if .Timestamp.IsZero}}{{call .Details}}{{end}}{{.Symbol}}`, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	sig := domain.Signal{ID: 3, Symbol: "SOL", Interval: "1h", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong, Risk: domain.RiskLevel2}
+	if got := r.Signal(sig); got != fallbackLine(sig, time.UTC) {
+		t.Fatalf("expected the built-in line, got %q", got)
+	}
+}
+
+func TestLoadReadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	tmplFile := filepath.Join(dir, "alert.tmpl")
+	msgFile := filepath.Join(dir, "messages.json")
+	if err := os.WriteFile(tmplFile, []byte("{{t \"label\"}} {{.Symbol}}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(msgFile, []byte(`{"label":"Señal","alert.header":"Alerta:"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Load("ignored {{.Symbol}}", tmplFile, msgFile)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := r.Alert([]domain.Signal{{Symbol: "BTC"}}); got != "Alerta:\nSeñal BTC" {
+		t.Fatalf("unexpected alert: %q", got)
+	}
+
+	if err := os.WriteFile(msgFile, []byte(`["not","an","object"]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load("", "", msgFile); err == nil {
+		t.Fatal("expected a malformed messages file to be rejected")
+	}
+	if _, err := Load("", filepath.Join(dir, "missing.tmpl"), ""); err == nil {
+		t.Fatal("expected a missing template file to be rejected")
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/alerttemplate"
+	"bug-free-umbrella/pkg/domain"
 
 	tele "gopkg.in/telebot.v3"
 )
@@ -17,24 +19,54 @@ type messageSender interface {
 	Send(to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error)
 }
 
+// albumSender is implemented by senders that can post a media group. Without
+// it a grouped alert's charts go out one by one.
+type albumSender interface {
+	SendAlbum(to tele.Recipient, a tele.Album, opts ...interface{}) ([]tele.Message, error)
+}
+
+const (
+	// maxAlbumSize is Telegram's limit on items in one media group. Larger
+	// batches of signals are split across several alerts.
+	maxAlbumSize = 10
+	// maxCaptionLen is Telegram's limit on a photo caption, in characters.
+	maxCaptionLen = 1024
+)
+
 type SignalImageFetcher interface {
 	GetSignalImage(ctx context.Context, signalID int64) (*domain.SignalImageData, error)
 }
 
 // AlertDispatcher broadcasts newly-generated signals to subscribed chats.
 type AlertDispatcher struct {
-	sender messageSender
-	images SignalImageFetcher
+	sender     messageSender
+	images     SignalImageFetcher
+	deliveries AlertDeliveryStore
+	text       *alerttemplate.Renderer
+	now        func() time.Time
 
 	mu          sync.RWMutex
 	subscribers map[int64]struct{}
+	// timezones holds the chats that chose a time zone with /tz; the
+	// others see UTC.
+	timezones map[int64]*time.Location
 }
 
 func NewAlertDispatcher(sender messageSender, images SignalImageFetcher) *AlertDispatcher {
 	return &AlertDispatcher{
 		sender:      sender,
 		images:      images,
+		text:        alerttemplate.Default(),
+		now:         time.Now,
 		subscribers: make(map[int64]struct{}),
+		timezones:   make(map[int64]*time.Location),
+	}
+}
+
+// SetAlertText sets the templates and messages alerts are written with.
+func (d *AlertDispatcher) SetAlertText(r *alerttemplate.Renderer) {
+	if d != nil && r != nil {
+		d.text = r
 	}
 }
 
@@ -68,6 +100,32 @@ func (d *AlertDispatcher) IsSubscribed(chatID int64) bool {
 	return exists
 }
 
+// SetTimezone shows times to chatID in loc. UTC or nil resets it.
+func (d *AlertDispatcher) SetTimezone(chatID int64, loc *time.Location) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if loc == nil || loc == time.UTC {
+		delete(d.timezones, chatID)
+		return
+	}
+	d.timezones[chatID] = loc
+}
+
+// Timezone returns the time zone chatID sees times in, UTC by default.
+func (d *AlertDispatcher) Timezone(chatID int64) *time.Location {
+	if d == nil {
+		return time.UTC
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if loc, ok := d.timezones[chatID]; ok {
+		return loc
+	}
+	return time.UTC
+}
+
 func (d *AlertDispatcher) SubscriberCount() int {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -85,11 +143,14 @@ func (d *AlertDispatcher) NotifySignals(ctx context.Context, signals []domain.Si
 		return nil
 	}
 
+	// Signals from one cycle go out together: one message, with an album
+	// of their charts, instead of a notification per signal.
 	var failures []string
 	for _, chatID := range chatIDs {
-		for _, s := range signals {
-			if err := d.sendSignalToChat(ctx, chatID, s); err != nil {
-				failures = append(failures, fmt.Sprintf("chat %d signal %d: %v", chatID, s.ID, err))
+		for start := 0; start < len(signals); start += maxAlbumSize {
+			group := signals[start:min(start+maxAlbumSize, len(signals))]
+			if err := d.sendSignalsToChat(ctx, chatID, group); err != nil {
+				failures = append(failures, fmt.Sprintf("chat %d signal %d: %v", chatID, group[0].ID, err))
 			}
 		}
 	}
@@ -99,6 +160,30 @@ func (d *AlertDispatcher) NotifySignals(ctx context.Context, signals []domain.Si
 	return nil
 }
 
+// Broadcast sends a plain-text operator message to every subscribed chat and
+// returns the number of chats it was delivered to.
+func (d *AlertDispatcher) Broadcast(ctx context.Context, message string) (int, error) {
+	_ = ctx
+	message = strings.TrimSpace(message)
+	if d == nil || d.sender == nil || message == "" {
+		return 0, nil
+	}
+
+	delivered := 0
+	var failures []string
+	for _, chatID := range d.snapshotSubscribers() {
+		if err := d.deliver(ctx, domain.AlertDelivery{ChatID: chatID, Message: message}); err != nil {
+			failures = append(failures, fmt.Sprintf("chat %d: %v", chatID, err))
+			continue
+		}
+		delivered++
+	}
+	if len(failures) > 0 {
+		return delivered, fmt.Errorf("failed broadcasting to %d chats: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return delivered, nil
+}
+
 func (d *AlertDispatcher) snapshotSubscribers() []int64 {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
@@ -111,25 +196,81 @@ func (d *AlertDispatcher) snapshotSubscribers() []int64 {
 	return chatIDs
 }
 
-func (d *AlertDispatcher) sendSignalToChat(ctx context.Context, chatID int64, s domain.Signal) error {
-	caption := "Proactive signal alert:\n" + formatSignal(s)
-	if d.images == nil || s.ID <= 0 {
-		_, err := d.sender.Send(&tele.Chat{ID: chatID}, caption)
+func (d *AlertDispatcher) sendSignalsToChat(ctx context.Context, chatID int64, signals []domain.Signal) error {
+	delivery := domain.AlertDelivery{ChatID: chatID, SignalID: signals[0].ID, Message: d.text.AlertIn(signals, d.Timezone(chatID))}
+	if len(signals) > 1 {
+		for _, s := range signals {
+			delivery.SignalIDs = append(delivery.SignalIDs, s.ID)
+		}
+	}
+	return d.deliver(ctx, delivery)
+}
+
+// send delivers the message, attaching the charts of its signals that have
+// one: a single chart as a photo, several as an album captioned with the
+// message.
+func (d *AlertDispatcher) send(ctx context.Context, delivery domain.AlertDelivery) error {
+	to := &tele.Chat{ID: delivery.ChatID}
+	signalIDs := delivery.SignalIDs
+	if len(signalIDs) == 0 {
+		signalIDs = []int64{delivery.SignalID}
+	}
+	photos := d.charts(ctx, signalIDs)
+
+	switch {
+	case len(photos) == 0:
+		_, err := d.sender.Send(to, delivery.Message)
+		return err
+	case len(photos) == 1:
+		photos[0].Caption = truncateCaption(delivery.Message)
+		_, err := d.sender.Send(to, photos[0])
 		return err
 	}
 
-	imageData, err := d.images.GetSignalImage(ctx, s.ID)
-	if err != nil || imageData == nil || len(imageData.Bytes) == 0 {
-		_, sendErr := d.sender.Send(&tele.Chat{ID: chatID}, caption)
-		return sendErr
+	album := make(tele.Album, 0, len(photos))
+	for _, p := range photos {
+		album = append(album, p)
 	}
+	album.SetCaption(truncateCaption(delivery.Message))
+	if albums, ok := d.sender.(albumSender); ok {
+		_, err := albums.SendAlbum(to, album)
+		return err
+	}
+	for _, p := range photos {
+		if _, err := d.sender.Send(to, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	photo := &tele.Photo{
-		File:    tele.FromReader(bytes.NewReader(imageData.Bytes)),
-		Caption: caption,
+// charts returns a photo for each signal with a stored chart, in order.
+// Signals whose chart is missing or fails to load are left out.
+func (d *AlertDispatcher) charts(ctx context.Context, signalIDs []int64) []*tele.Photo {
+	if d.images == nil {
+		return nil
+	}
+	var photos []*tele.Photo
+	for _, id := range signalIDs {
+		if id <= 0 {
+			continue
+		}
+		imageData, err := d.images.GetSignalImage(ctx, id)
+		if err != nil || imageData == nil || len(imageData.Bytes) == 0 {
+			continue
+		}
+		photos = append(photos, &tele.Photo{File: tele.FromReader(bytes.NewReader(imageData.Bytes))})
 	}
-	_, sendErr := d.sender.Send(&tele.Chat{ID: chatID}, photo)
-	return sendErr
+	return photos
+}
+
+// truncateCaption shortens message to fit a photo caption.
+func truncateCaption(message string) string {
+	runes := []rune(message)
+	if len(runes) <= maxCaptionLen {
+		return message
+	}
+	return string(runes[:maxCaptionLen-1]) + "…"
 }
 
 func parseAlertMode(args []string) (string, error) {
@@ -148,12 +289,3 @@ func parseAlertMode(args []string) (string, error) {
 		return "", fmt.Errorf("invalid mode")
 	}
 }
-
-func formatAlertMessage(signals []domain.Signal) string {
-	lines := make([]string, 0, len(signals)+1)
-	lines = append(lines, "Proactive signal alert:")
-	for _, s := range signals {
-		lines = append(lines, formatSignal(s))
-	}
-	return strings.Join(lines, "\n")
-}
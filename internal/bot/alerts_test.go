@@ -7,7 +7,8 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/alerttemplate"
+	"bug-free-umbrella/pkg/domain"
 
 	tele "gopkg.in/telebot.v3"
 )
@@ -67,6 +68,61 @@ func TestAlertDispatcherNotifySignals(t *testing.T) {
 	}
 }
 
+func TestAlertDispatcherUsesAlertTemplate(t *testing.T) {
+	sender := &fakeSender{}
+	dispatcher := NewAlertDispatcher(sender, nil)
+	dispatcher.Subscribe(10)
+
+	text, err := alerttemplate.New(`{{emoji .Direction}} {{.Symbol}} {{direction .Direction}}`, alerttemplate.Messages{
+		"alert.header":   "Nuevo aviso:",
+		"direction.long": "COMPRA",
+	})
+	if err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	dispatcher.SetAlertText(text)
+
+	signals := []domain.Signal{{Symbol: "BTC", Interval: "1h", Direction: domain.DirectionLong}}
+	if err := dispatcher.NotifySignals(context.Background(), signals); err != nil {
+		t.Fatalf("unexpected notify error: %v", err)
+	}
+	if got := sender.messages[10][0]; got != "Nuevo aviso:\n🟢 BTC COMPRA" {
+		t.Fatalf("unexpected alert body: %q", got)
+	}
+}
+
+func TestAlertDispatcherShowsEachChatItsTimezone(t *testing.T) {
+	sender := &fakeSender{}
+	dispatcher := NewAlertDispatcher(sender, nil)
+	dispatcher.Subscribe(10)
+	dispatcher.Subscribe(20)
+	berlin, _ := domain.LoadTimezone("Europe/Berlin")
+	dispatcher.SetTimezone(20, berlin)
+
+	signals := []domain.Signal{{
+		Symbol:    "ETH",
+		Interval:  "4h",
+		Indicator: domain.IndicatorMACD,
+		Direction: domain.DirectionShort,
+		Risk:      domain.RiskLevel3,
+		Timestamp: time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC),
+	}}
+	if err := dispatcher.NotifySignals(context.Background(), signals); err != nil {
+		t.Fatalf("unexpected notify error: %v", err)
+	}
+	if got := sender.messages[10][0]; !strings.HasSuffix(got, "at 01 Jul 26 08:00 UTC") {
+		t.Fatalf("expected UTC for chat 10, got %q", got)
+	}
+	if got := sender.messages[20][0]; !strings.HasSuffix(got, "at 01 Jul 26 10:00 CEST") {
+		t.Fatalf("expected Berlin time for chat 20, got %q", got)
+	}
+
+	dispatcher.SetTimezone(20, time.UTC)
+	if dispatcher.Timezone(20) != time.UTC {
+		t.Fatal("expected /tz UTC to reset the chat")
+	}
+}
+
 func TestAlertDispatcherUnsubscribe(t *testing.T) {
 	sender := &fakeSender{}
 	dispatcher := NewAlertDispatcher(sender, nil)
@@ -130,9 +186,131 @@ func TestAlertDispatcherSendsPhotoWhenImageAvailable(t *testing.T) {
 	}
 }
 
+func chartFetcher(ids ...int64) fakeImageFetcher {
+	f := fakeImageFetcher{bySignalID: map[int64]*domain.SignalImageData{}}
+	for _, id := range ids {
+		f.bySignalID[id] = &domain.SignalImageData{Bytes: []byte{0x89, 0x50, 0x4e, 0x47}}
+	}
+	return f
+}
+
+func testSignals(ids ...int64) []domain.Signal {
+	out := make([]domain.Signal, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, domain.Signal{
+			ID:        id,
+			Symbol:    "BTC",
+			Interval:  "1h",
+			Indicator: domain.IndicatorRSI,
+			Direction: domain.DirectionLong,
+			Risk:      domain.RiskLevel2,
+			Timestamp: time.Unix(0, 0).UTC(),
+		})
+	}
+	return out
+}
+
+func TestAlertDispatcherGroupsChartsIntoAlbum(t *testing.T) {
+	sender := &fakeSender{}
+	dispatcher := NewAlertDispatcher(sender, chartFetcher(1, 2, 3))
+	dispatcher.Subscribe(99)
+
+	// Signal 4 has no chart; it is still listed in the caption.
+	if err := dispatcher.NotifySignals(context.Background(), testSignals(1, 2, 3, 4)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.kinds[99]) != 1 || sender.kinds[99][0] != "album" {
+		t.Fatalf("expected a single album, got %v", sender.kinds[99])
+	}
+	if sender.albumSizes[99][0] != 3 {
+		t.Fatalf("expected 3 charts in the album, got %d", sender.albumSizes[99][0])
+	}
+	caption := sender.messages[99][0]
+	if !strings.HasPrefix(caption, "Proactive signal alert:") || strings.Count(caption, "#") != 4 {
+		t.Fatalf("expected combined caption for all signals, got %q", caption)
+	}
+}
+
+func TestAlertDispatcherSplitsLargeBatches(t *testing.T) {
+	sender := &fakeSender{}
+	dispatcher := NewAlertDispatcher(sender, nil)
+	dispatcher.Subscribe(1)
+
+	ids := make([]int64, 0, 12)
+	for id := int64(1); id <= 12; id++ {
+		ids = append(ids, id)
+	}
+	if err := dispatcher.NotifySignals(context.Background(), testSignals(ids...)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.messages[1]) != 2 {
+		t.Fatalf("expected 12 signals in 2 messages, got %d", len(sender.messages[1]))
+	}
+}
+
+func TestAlertDispatcherAlbumFallsBackToPhotos(t *testing.T) {
+	sender := &scriptedSender{}
+	dispatcher := NewAlertDispatcher(sender, chartFetcher(1, 2))
+	dispatcher.Subscribe(1)
+
+	if err := dispatcher.NotifySignals(context.Background(), testSignals(1, 2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sender.calls != 2 {
+		t.Fatalf("expected one photo per chart without album support, got %d sends", sender.calls)
+	}
+}
+
+func TestTruncateCaption(t *testing.T) {
+	long := strings.Repeat("é", maxCaptionLen+10)
+	got := truncateCaption(long)
+	if n := len([]rune(got)); n != maxCaptionLen || !strings.HasSuffix(got, "…") {
+		t.Fatalf("expected %d runes ending in an ellipsis, got %d", maxCaptionLen, n)
+	}
+	if truncateCaption("short") != "short" {
+		t.Fatal("short captions must be unchanged")
+	}
+}
+
+func TestAlertDispatcherBroadcast(t *testing.T) {
+	sender := &fakeSender{}
+	dispatcher := NewAlertDispatcher(sender, nil)
+	dispatcher.Subscribe(1)
+	dispatcher.Subscribe(2)
+
+	delivered, err := dispatcher.Broadcast(context.Background(), "  maintenance at 02:00 UTC ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivered != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", delivered)
+	}
+	if sender.messages[1][0] != "maintenance at 02:00 UTC" {
+		t.Fatalf("unexpected broadcast body: %q", sender.messages[1][0])
+	}
+
+	if delivered, _ := dispatcher.Broadcast(context.Background(), "   "); delivered != 0 {
+		t.Fatalf("expected empty broadcast to be skipped, got %d", delivered)
+	}
+}
+
 type fakeSender struct {
-	messages map[int64][]string
-	kinds    map[int64][]string
+	messages   map[int64][]string
+	kinds      map[int64][]string
+	albumSizes map[int64][]int
+}
+
+func (f *fakeSender) SendAlbum(to tele.Recipient, a tele.Album, opts ...interface{}) ([]tele.Message, error) {
+	if f.albumSizes == nil {
+		f.albumSizes = make(map[int64][]int)
+	}
+	chat := to.(*tele.Chat)
+	f.albumSizes[chat.ID] = append(f.albumSizes[chat.ID], len(a))
+	if _, err := f.Send(to, a[0]); err != nil {
+		return nil, err
+	}
+	f.kinds[chat.ID][len(f.kinds[chat.ID])-1] = "album"
+	return make([]tele.Message, len(a)), nil
 }
 
 func (f *fakeSender) Send(to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error) {
@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"context"
+	"log"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// ConversationManager is implemented by advisors that can clear and recap a
+// chat's conversation.
+type ConversationManager interface {
+	ResetConversation(ctx context.Context, chatID int64) error
+	SummarizeConversation(ctx context.Context, chatID int64) (string, error)
+}
+
+// chatContext scopes ctx to the chat's tenant, so its advisor conversation
+// is stored apart from other front ends and other tenants that reuse the ID.
+func chatContext(ctx context.Context, chatID int64) context.Context {
+	return domain.WithTenant(ctx, domain.TelegramTenantID(chatID))
+}
+
+func resetReply(ctx context.Context, mgr ConversationManager, chatID int64) string {
+	ctx = chatContext(ctx, chatID)
+	if err := mgr.ResetConversation(ctx, chatID); err != nil {
+		log.Printf("reset conversation for chat %d: %v", chatID, err)
+		return "Could not clear the conversation right now. Try again later."
+	}
+	return "Conversation cleared. The advisor will start fresh."
+}
+
+func historyReply(ctx context.Context, mgr ConversationManager, chatID int64) string {
+	ctx = chatContext(ctx, chatID)
+	recap, err := mgr.SummarizeConversation(ctx, chatID)
+	if err != nil {
+		log.Printf("summarize conversation for chat %d: %v", chatID, err)
+		return "Could not summarize the conversation right now. Try again later."
+	}
+	if recap == "" {
+		return "No conversation history yet. Ask me something with /ask."
+	}
+	if len(recap) > 4000 {
+		recap = recap[:4000] + "\n\n[truncated]"
+	}
+	return "Conversation so far:\n" + recap
+}
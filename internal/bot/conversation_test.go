@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+type conversationManagerStub struct {
+	recap    string
+	err      error
+	resetIDs []int64
+	tenants  []string
+}
+
+func (s *conversationManagerStub) ResetConversation(ctx context.Context, chatID int64) error {
+	s.resetIDs = append(s.resetIDs, chatID)
+	s.tenants = append(s.tenants, domain.TenantFromContext(ctx))
+	return s.err
+}
+
+func (s *conversationManagerStub) SummarizeConversation(ctx context.Context, chatID int64) (string, error) {
+	s.tenants = append(s.tenants, domain.TenantFromContext(ctx))
+	return s.recap, s.err
+}
+
+func TestResetReply(t *testing.T) {
+	mgr := &conversationManagerStub{}
+	if reply := resetReply(context.Background(), mgr, 42); !strings.Contains(reply, "cleared") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+	if len(mgr.resetIDs) != 1 || mgr.resetIDs[0] != 42 {
+		t.Fatalf("expected chat 42 reset, got %v", mgr.resetIDs)
+	}
+	if mgr.tenants[0] != domain.TelegramTenantID(42) {
+		t.Fatalf("expected the chat's tenant, got %v", mgr.tenants)
+	}
+	mgr.err = errors.New("db down")
+	if reply := resetReply(context.Background(), mgr, 42); strings.Contains(reply, "db down") {
+		t.Fatalf("internal error leaked: %q", reply)
+	}
+}
+
+func TestHistoryReply(t *testing.T) {
+	if reply := historyReply(context.Background(), &conversationManagerStub{}, 1); !strings.Contains(reply, "No conversation") {
+		t.Fatalf("unexpected empty reply %q", reply)
+	}
+	mgr := &conversationManagerStub{recap: "- BTC"}
+	reply := historyReply(context.Background(), mgr, 1)
+	if reply != "Conversation so far:\n- BTC" {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+	if len(mgr.tenants) != 1 || mgr.tenants[0] != domain.TelegramTenantID(1) {
+		t.Fatalf("expected the chat's tenant, got %v", mgr.tenants)
+	}
+}
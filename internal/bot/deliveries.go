@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	maxAlertDeliveryAttempts = 5
+	alertRetryBaseDelay      = 30 * time.Second
+	alertRetryMaxDelay       = 30 * time.Minute
+	// alertInFlightGrace keeps the retry job away from a delivery while its
+	// first attempt is still in progress.
+	alertInFlightGrace = time.Minute
+)
+
+// AlertDeliveryStore persists outgoing notifications for retry and
+// dead-letter inspection.
+type AlertDeliveryStore interface {
+	CreateAlertDelivery(ctx context.Context, delivery domain.AlertDelivery) (int64, error)
+	RecordAlertDeliveryAttempt(ctx context.Context, id int64, status domain.AlertDeliveryStatus, lastError string, nextAttemptAt time.Time) error
+	ListDueAlertDeliveries(ctx context.Context, limit int) ([]domain.AlertDelivery, error)
+	ListDeadAlertDeliveries(ctx context.Context, limit int) ([]domain.AlertDelivery, error)
+	RequeueAlertDelivery(ctx context.Context, id int64) (*domain.AlertDelivery, error)
+}
+
+// SetDeliveryStore enables persistent delivery tracking. Without a store,
+// sends are attempted once as before.
+func (d *AlertDispatcher) SetDeliveryStore(store AlertDeliveryStore) {
+	d.deliveries = store
+}
+
+// RetryDueDeliveries re-sends failed deliveries whose backoff has elapsed and
+// returns how many succeeded.
+func (d *AlertDispatcher) RetryDueDeliveries(ctx context.Context, limit int) (int, error) {
+	if d == nil || d.deliveries == nil || d.sender == nil {
+		return 0, nil
+	}
+	due, err := d.deliveries.ListDueAlertDeliveries(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, delivery := range due {
+		if _, err := d.attempt(ctx, delivery); err == nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// ListDeadLetters returns deliveries that exhausted their retries or failed
+// permanently.
+func (d *AlertDispatcher) ListDeadLetters(ctx context.Context, limit int) ([]domain.AlertDelivery, error) {
+	if d == nil || d.deliveries == nil {
+		return nil, fmt.Errorf("alert delivery tracking is not enabled")
+	}
+	return d.deliveries.ListDeadAlertDeliveries(ctx, limit)
+}
+
+// Redeliver requeues a dead letter with a fresh retry budget and sends it
+// immediately. It returns nil when id is not a dead letter. A failed send is
+// returned as the error and left to the retry job.
+func (d *AlertDispatcher) Redeliver(ctx context.Context, id int64) (*domain.AlertDelivery, error) {
+	if d == nil || d.deliveries == nil || d.sender == nil {
+		return nil, fmt.Errorf("alert delivery tracking is not enabled")
+	}
+	delivery, err := d.deliveries.RequeueAlertDelivery(ctx, id)
+	if err != nil || delivery == nil {
+		return nil, err
+	}
+	status, sendErr := d.attempt(ctx, *delivery)
+	delivery.Status = status
+	delivery.Attempts++
+	return delivery, sendErr
+}
+
+// deliver records a new delivery, when tracking is enabled, and makes the
+// first attempt.
+func (d *AlertDispatcher) deliver(ctx context.Context, delivery domain.AlertDelivery) error {
+	if d.deliveries != nil {
+		delivery.NextAttemptAt = d.now().Add(alertInFlightGrace)
+		id, err := d.deliveries.CreateAlertDelivery(ctx, delivery)
+		if err != nil {
+			log.Printf("alert delivery record error for chat %d: %v", delivery.ChatID, err)
+		}
+		delivery.ID = id
+	}
+	_, err := d.attempt(ctx, delivery)
+	return err
+}
+
+func (d *AlertDispatcher) attempt(ctx context.Context, delivery domain.AlertDelivery) (domain.AlertDeliveryStatus, error) {
+	sendErr := d.send(ctx, delivery)
+
+	attempts := delivery.Attempts + 1
+	status := domain.AlertDeliverySent
+	next := d.now()
+	errText := ""
+	if sendErr != nil {
+		errText = sendErr.Error()
+		status = domain.AlertDeliveryFailed
+		next = next.Add(alertRetryDelay(attempts, sendErr))
+		if permanentSendError(sendErr) || attempts >= maxAlertDeliveryAttempts {
+			status = domain.AlertDeliveryDead
+		}
+	}
+
+	if d.deliveries == nil || delivery.ID <= 0 {
+		return status, sendErr
+	}
+	if err := d.deliveries.RecordAlertDeliveryAttempt(ctx, delivery.ID, status, errText, next); err != nil {
+		log.Printf("alert delivery %d status update error: %v", delivery.ID, err)
+	}
+	if status == domain.AlertDeliveryDead {
+		log.Printf("alert delivery %d to chat %d moved to dead letters after %d attempt(s): %v", delivery.ID, delivery.ChatID, attempts, sendErr)
+	}
+	return status, sendErr
+}
+
+// alertRetryDelay doubles from alertRetryBaseDelay per attempt, capped at
+// alertRetryMaxDelay, and honors Telegram's flood-control wait.
+func alertRetryDelay(attempts int, err error) time.Duration {
+	delay := alertRetryMaxDelay
+	if attempts <= 6 {
+		delay = min(alertRetryBaseDelay<<(attempts-1), alertRetryMaxDelay)
+	}
+	var flood tele.FloodError
+	if errors.As(err, &flood) {
+		delay = max(delay, time.Duration(flood.RetryAfter)*time.Second)
+	}
+	return delay
+}
+
+// permanentSendError reports Telegram rejections that retrying cannot fix,
+// such as a blocked bot or a deleted chat. Flood control is transient.
+func permanentSendError(err error) bool {
+	var flood tele.FloodError
+	if errors.As(err, &flood) {
+		return false
+	}
+	var tgErr *tele.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.Code >= 400 && tgErr.Code < 500 && tgErr.Code != 429
+	}
+	return false
+}
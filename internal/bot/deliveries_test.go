@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+func TestNotifySignalsRecordsSuccessfulDelivery(t *testing.T) {
+	store := newMemoryDeliveryStore()
+	dispatcher := NewAlertDispatcher(&fakeSender{}, nil)
+	dispatcher.SetDeliveryStore(store)
+	dispatcher.Subscribe(10)
+
+	if err := dispatcher.NotifySignals(context.Background(), []domain.Signal{{ID: 4, Symbol: "BTC", Interval: "1h"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := store.rows[1]
+	if d == nil || d.Status != domain.AlertDeliverySent || d.Attempts != 1 || d.SignalID != 4 || d.ChatID != 10 {
+		t.Fatalf("expected one sent delivery, got %+v", d)
+	}
+}
+
+func TestTransientFailureIsRetriedWithBackoff(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := newMemoryDeliveryStore()
+	sender := &scriptedSender{errs: []error{errors.New("connection reset"), nil}}
+	dispatcher := NewAlertDispatcher(sender, nil)
+	dispatcher.now = func() time.Time { return now }
+	dispatcher.SetDeliveryStore(store)
+	dispatcher.Subscribe(10)
+
+	if _, err := dispatcher.Broadcast(context.Background(), "hello"); err == nil {
+		t.Fatal("expected first send to fail")
+	}
+	d := store.rows[1]
+	if d.Status != domain.AlertDeliveryFailed || !d.NextAttemptAt.Equal(now.Add(alertRetryBaseDelay)) {
+		t.Fatalf("expected failed delivery due after base delay, got %+v", d)
+	}
+
+	if sent, err := dispatcher.RetryDueDeliveries(context.Background(), 10); err != nil || sent != 1 {
+		t.Fatalf("expected one successful retry, got sent=%d err=%v", sent, err)
+	}
+	if d.Status != domain.AlertDeliverySent || d.Attempts != 2 || sender.calls != 2 {
+		t.Fatalf("expected delivery sent on second attempt, got %+v calls=%d", d, sender.calls)
+	}
+}
+
+func TestPermanentFailureIsDeadLettered(t *testing.T) {
+	store := newMemoryDeliveryStore()
+	sender := &scriptedSender{errs: []error{tele.ErrBlockedByUser, nil}}
+	dispatcher := NewAlertDispatcher(sender, nil)
+	dispatcher.SetDeliveryStore(store)
+	dispatcher.Subscribe(10)
+
+	_ = dispatcher.NotifySignals(context.Background(), []domain.Signal{{ID: 4, Symbol: "BTC", Interval: "1h"}})
+
+	dead, err := dispatcher.ListDeadLetters(context.Background(), 10)
+	if err != nil || len(dead) != 1 || dead[0].Attempts != 1 {
+		t.Fatalf("expected one dead letter after a single attempt, got %+v err=%v", dead, err)
+	}
+
+	redelivered, err := dispatcher.Redeliver(context.Background(), dead[0].ID)
+	if err != nil {
+		t.Fatalf("unexpected redelivery error: %v", err)
+	}
+	if redelivered == nil || redelivered.Status != domain.AlertDeliverySent || store.rows[1].Status != domain.AlertDeliverySent {
+		t.Fatalf("expected redelivered letter to be sent, got %+v", redelivered)
+	}
+	if missing, err := dispatcher.Redeliver(context.Background(), 1); err != nil || missing != nil {
+		t.Fatalf("expected nil for a delivery that is no longer dead, got %+v err=%v", missing, err)
+	}
+}
+
+func TestRetriesStopAfterMaxAttempts(t *testing.T) {
+	store := newMemoryDeliveryStore()
+	errs := make([]error, maxAlertDeliveryAttempts)
+	for i := range errs {
+		errs[i] = errors.New("timeout")
+	}
+	dispatcher := NewAlertDispatcher(&scriptedSender{errs: errs}, nil)
+	dispatcher.SetDeliveryStore(store)
+	dispatcher.Subscribe(10)
+
+	_, _ = dispatcher.Broadcast(context.Background(), "hello")
+	for i := 1; i < maxAlertDeliveryAttempts; i++ {
+		_, _ = dispatcher.RetryDueDeliveries(context.Background(), 10)
+	}
+	d := store.rows[1]
+	if d.Status != domain.AlertDeliveryDead || d.Attempts != maxAlertDeliveryAttempts {
+		t.Fatalf("expected dead letter after %d attempts, got %+v", maxAlertDeliveryAttempts, d)
+	}
+}
+
+func TestAlertRetryDelay(t *testing.T) {
+	if got := alertRetryDelay(1, errors.New("x")); got != alertRetryBaseDelay {
+		t.Fatalf("expected base delay, got %s", got)
+	}
+	if got := alertRetryDelay(3, errors.New("x")); got != 4*alertRetryBaseDelay {
+		t.Fatalf("expected doubled delay, got %s", got)
+	}
+	if got := alertRetryDelay(40, errors.New("x")); got != alertRetryMaxDelay {
+		t.Fatalf("expected capped delay, got %s", got)
+	}
+	flood := tele.FloodError{RetryAfter: 120}
+	if got := alertRetryDelay(1, flood); got != 2*time.Minute {
+		t.Fatalf("expected flood wait, got %s", got)
+	}
+	if permanentSendError(flood) || !permanentSendError(tele.ErrChatNotFound) || permanentSendError(errors.New("eof")) {
+		t.Fatal("unexpected permanent error classification")
+	}
+}
+
+type scriptedSender struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedSender) Send(to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error) {
+	s.calls++
+	if s.calls <= len(s.errs) && s.errs[s.calls-1] != nil {
+		return nil, s.errs[s.calls-1]
+	}
+	return &tele.Message{}, nil
+}
+
+type memoryDeliveryStore struct {
+	rows   map[int64]*domain.AlertDelivery
+	nextID int64
+}
+
+func newMemoryDeliveryStore() *memoryDeliveryStore {
+	return &memoryDeliveryStore{rows: make(map[int64]*domain.AlertDelivery)}
+}
+
+func (s *memoryDeliveryStore) CreateAlertDelivery(ctx context.Context, delivery domain.AlertDelivery) (int64, error) {
+	s.nextID++
+	delivery.ID = s.nextID
+	delivery.Status = domain.AlertDeliveryPending
+	s.rows[delivery.ID] = &delivery
+	return delivery.ID, nil
+}
+
+func (s *memoryDeliveryStore) RecordAlertDeliveryAttempt(ctx context.Context, id int64, status domain.AlertDeliveryStatus, lastError string, nextAttemptAt time.Time) error {
+	d := s.rows[id]
+	d.Status = status
+	d.Attempts++
+	d.LastError = lastError
+	d.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+// ListDueAlertDeliveries treats every pending or failed row as due.
+func (s *memoryDeliveryStore) ListDueAlertDeliveries(ctx context.Context, limit int) ([]domain.AlertDelivery, error) {
+	return s.byStatus(domain.AlertDeliveryPending, domain.AlertDeliveryFailed), nil
+}
+
+func (s *memoryDeliveryStore) ListDeadAlertDeliveries(ctx context.Context, limit int) ([]domain.AlertDelivery, error) {
+	return s.byStatus(domain.AlertDeliveryDead), nil
+}
+
+func (s *memoryDeliveryStore) RequeueAlertDelivery(ctx context.Context, id int64) (*domain.AlertDelivery, error) {
+	d, ok := s.rows[id]
+	if !ok || d.Status != domain.AlertDeliveryDead {
+		return nil, nil
+	}
+	d.Status = domain.AlertDeliveryPending
+	d.Attempts = 0
+	d.LastError = ""
+	out := *d
+	return &out, nil
+}
+
+func (s *memoryDeliveryStore) byStatus(statuses ...domain.AlertDeliveryStatus) []domain.AlertDelivery {
+	var out []domain.AlertDelivery
+	for id := int64(1); id <= s.nextID; id++ {
+		for _, status := range statuses {
+			if s.rows[id].Status == status {
+				out = append(out, *s.rows[id])
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,260 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// Telegram's documented send limits: about 30 messages per second overall,
+// one per second to the same chat and 20 per minute to the same group.
+const (
+	MaxSendsPerSecond = 30
+	chatSendInterval  = time.Second
+	groupSendInterval = 3 * time.Second
+
+	// maxTrackedChats is how many chats the throttle and limiter remember
+	// before forgetting idle ones.
+	maxTrackedChats = 1000
+)
+
+// RateLimits bounds how fast the bot reacts to chats and talks to Telegram.
+type RateLimits struct {
+	// ChatCommandsPerMinute is how many messages one chat may send the bot
+	// per minute before it is asked to slow down; 0 disables the limit.
+	ChatCommandsPerMinute int
+	// SendsPerSecond caps outgoing messages across all chats. 0 or values
+	// above MaxSendsPerSecond use MaxSendsPerSecond.
+	SendsPerSecond int
+}
+
+// sendThrottle spaces outgoing messages to stay under Telegram's limits, so
+// a /signals reply with many charts or an alert fan-out does not trigger
+// flood control. Callers block until their slot.
+type sendThrottle struct {
+	interval time.Duration // between any two sends
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+
+	mu       sync.Mutex
+	nextAny  time.Time
+	nextChat map[int64]time.Time
+}
+
+func newSendThrottle(perSecond int) *sendThrottle {
+	if perSecond <= 0 || perSecond > MaxSendsPerSecond {
+		perSecond = MaxSendsPerSecond
+	}
+	return &sendThrottle{
+		interval: time.Second / time.Duration(perSecond),
+		now:      time.Now,
+		sleep:    sleepContext,
+		nextChat: make(map[int64]time.Time),
+	}
+}
+
+// wait blocks until a message may go to chatID. The chat's slot is taken
+// first, then a global one, so a busy chat does not hold up the others.
+func (t *sendThrottle) wait(ctx context.Context, chatID int64) error {
+	interval := chatSendInterval
+	if chatID < 0 {
+		interval = groupSendInterval
+	}
+	t.mu.Lock()
+	now := t.now()
+	at := laterOf(now, t.nextChat[chatID])
+	t.nextChat[chatID] = at.Add(interval)
+	if len(t.nextChat) > maxTrackedChats {
+		for id, next := range t.nextChat {
+			if next.Before(now) {
+				delete(t.nextChat, id)
+			}
+		}
+	}
+	t.mu.Unlock()
+	if err := t.sleep(ctx, at.Sub(now)); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	now = t.now()
+	at = laterOf(now, t.nextAny)
+	t.nextAny = at.Add(t.interval)
+	t.mu.Unlock()
+	return t.sleep(ctx, at.Sub(now))
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttledSender sends through a sendThrottle. It wraps the bot for the
+// alert dispatcher.
+type throttledSender struct {
+	next     messageSender
+	throttle *sendThrottle
+}
+
+func (s throttledSender) Send(to tele.Recipient, what interface{}, opts ...interface{}) (*tele.Message, error) {
+	if err := s.throttle.wait(context.Background(), recipientID(to)); err != nil {
+		return nil, err
+	}
+	return s.next.Send(to, what, opts...)
+}
+
+func (s throttledSender) SendAlbum(to tele.Recipient, a tele.Album, opts ...interface{}) ([]tele.Message, error) {
+	albums, ok := s.next.(albumSender)
+	if !ok {
+		return nil, errors.New("sender cannot send albums")
+	}
+	if err := s.throttle.wait(context.Background(), recipientID(to)); err != nil {
+		return nil, err
+	}
+	return albums.SendAlbum(to, a, opts...)
+}
+
+func recipientID(to tele.Recipient) int64 {
+	if chat, ok := to.(*tele.Chat); ok {
+		return chat.ID
+	}
+	id, _ := strconv.ParseInt(to.Recipient(), 10, 64)
+	return id
+}
+
+// throttledContext routes a handler's replies through a sendThrottle.
+type throttledContext struct {
+	tele.Context
+	throttle *sendThrottle
+}
+
+func (c throttledContext) Send(what interface{}, opts ...interface{}) error {
+	if err := c.wait(); err != nil {
+		return err
+	}
+	return c.Context.Send(what, opts...)
+}
+
+func (c throttledContext) Reply(what interface{}, opts ...interface{}) error {
+	if err := c.wait(); err != nil {
+		return err
+	}
+	return c.Context.Reply(what, opts...)
+}
+
+func (c throttledContext) wait() error {
+	var chatID int64
+	if chat := c.Chat(); chat != nil {
+		chatID = chat.ID
+	}
+	return c.throttle.wait(context.Background(), chatID)
+}
+
+// chatLimiter is a token bucket per chat: a chat may send perMinute
+// messages in a burst, refilled evenly over the minute.
+type chatLimiter struct {
+	perMinute int
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[int64]*chatBucket
+}
+
+type chatBucket struct {
+	tokens float64
+	last   time.Time
+	warned bool // the chat was told to slow down since it last got through
+}
+
+func newChatLimiter(perMinute int) *chatLimiter {
+	return &chatLimiter{perMinute: perMinute, now: time.Now, buckets: make(map[int64]*chatBucket)}
+}
+
+// allow spends one of chatID's tokens. When none is left it returns how
+// long until the next one, and whether this is the first refusal, so the
+// chat is warned once rather than on every message.
+func (l *chatLimiter) allow(chatID int64) (ok bool, retryIn time.Duration, warn bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	capacity := float64(l.perMinute)
+	b, found := l.buckets[chatID]
+	if !found {
+		b = &chatBucket{tokens: capacity, last: now}
+		l.buckets[chatID] = b
+		if len(l.buckets) > maxTrackedChats {
+			l.prune(now)
+		}
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Minutes()*capacity)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.warned = false
+		return true, 0, false
+	}
+	retryIn = time.Duration((1 - b.tokens) / capacity * float64(time.Minute))
+	warn = !b.warned
+	b.warned = true
+	return false, retryIn, warn
+}
+
+// prune forgets chats idle for a full refill: their buckets are full again,
+// the same as a chat never seen.
+func (l *chatLimiter) prune(now time.Time) {
+	for id, b := range l.buckets {
+		if now.Sub(b.last) >= time.Minute {
+			delete(l.buckets, id)
+		}
+	}
+}
+
+// rateLimitMiddleware drops messages from chats over their command limit,
+// with one polite notice per cooldown, and throttles every reply.
+func rateLimitMiddleware(limiter *chatLimiter, throttle *sendThrottle) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			tc := throttledContext{Context: c, throttle: throttle}
+			chat := c.Chat()
+			if limiter == nil || limiter.perMinute <= 0 || chat == nil {
+				return next(tc)
+			}
+			ok, retryIn, warn := limiter.allow(chat.ID)
+			if ok {
+				return next(tc)
+			}
+			if !warn {
+				return nil
+			}
+			return tc.Send(cooldownMessage(retryIn))
+		}
+	}
+}
+
+func cooldownMessage(retryIn time.Duration) string {
+	secs := max(1, int(math.Ceil(retryIn.Seconds())))
+	return fmt.Sprintf("You're sending commands faster than I can keep up. Please wait %ds and try again.", secs)
+}
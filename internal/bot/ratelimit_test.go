@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// fakeClock backs a sendThrottle or chatLimiter: sleeping advances it.
+type fakeClock struct {
+	t      time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) sleep(ctx context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	if d > 0 {
+		c.t = c.t.Add(d)
+	}
+	return nil
+}
+
+func newTestThrottle(perSecond int) (*sendThrottle, *fakeClock) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	t := newSendThrottle(perSecond)
+	t.now = clock.now
+	t.sleep = clock.sleep
+	return t, clock
+}
+
+func TestSendThrottleSpacesMessagesToOneChat(t *testing.T) {
+	throttle, clock := newTestThrottle(30)
+	start := clock.t
+
+	for i := 0; i < 3; i++ {
+		if err := throttle.wait(context.Background(), 42); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := clock.t.Sub(start); got != 2*time.Second {
+		t.Fatalf("expected three sends over 2s, took %v", got)
+	}
+}
+
+func TestSendThrottleSpacesGroupsLonger(t *testing.T) {
+	throttle, clock := newTestThrottle(30)
+	start := clock.t
+
+	_ = throttle.wait(context.Background(), -100)
+	_ = throttle.wait(context.Background(), -100)
+	if got := clock.t.Sub(start); got != groupSendInterval {
+		t.Fatalf("expected group sends %v apart, got %v", groupSendInterval, got)
+	}
+}
+
+func TestSendThrottleCapsGlobalRate(t *testing.T) {
+	throttle, clock := newTestThrottle(10)
+	start := clock.t
+
+	// Distinct chats only wait on the global slot.
+	for chat := int64(1); chat <= 5; chat++ {
+		_ = throttle.wait(context.Background(), chat)
+	}
+	if got := clock.t.Sub(start); got != 400*time.Millisecond {
+		t.Fatalf("expected 5 sends at 10/s over 400ms, took %v", got)
+	}
+}
+
+func TestNewSendThrottleClampsToTelegramLimit(t *testing.T) {
+	if got := newSendThrottle(100).interval; got != time.Second/MaxSendsPerSecond {
+		t.Fatalf("expected interval clamped to %v, got %v", time.Second/MaxSendsPerSecond, got)
+	}
+	if got := newSendThrottle(0).interval; got != time.Second/MaxSendsPerSecond {
+		t.Fatalf("expected default interval, got %v", got)
+	}
+}
+
+func TestSendThrottleStopsOnCancelledContext(t *testing.T) {
+	throttle := newSendThrottle(1)
+	_ = throttle.wait(context.Background(), 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := throttle.wait(ctx, 1); err == nil {
+		t.Fatal("expected cancelled wait to fail")
+	}
+}
+
+func TestThrottledSenderForwards(t *testing.T) {
+	sender := &fakeSender{}
+	throttle, _ := newTestThrottle(30)
+	s := throttledSender{next: sender, throttle: throttle}
+
+	if _, err := s.Send(&tele.Chat{ID: 7}, "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sender.messages[7]) != 1 {
+		t.Fatalf("expected message forwarded, got %v", sender.messages)
+	}
+}
+
+func TestChatLimiterWarnsOncePerCooldown(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := newChatLimiter(2)
+	l.now = clock.now
+
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.allow(1); !ok {
+			t.Fatalf("expected message %d allowed", i+1)
+		}
+	}
+	ok, retryIn, warn := l.allow(1)
+	if ok || !warn || retryIn != 30*time.Second {
+		t.Fatalf("expected refusal with warning and 30s wait, got ok=%v warn=%v retry=%v", ok, warn, retryIn)
+	}
+	if _, _, warn = l.allow(1); warn {
+		t.Fatal("expected a single warning per cooldown")
+	}
+	if ok, _, _ := l.allow(2); !ok {
+		t.Fatal("other chats must not be limited")
+	}
+
+	clock.t = clock.t.Add(30 * time.Second)
+	if ok, _, _ := l.allow(1); !ok {
+		t.Fatal("expected a token after the cooldown")
+	}
+}
+
+func TestChatLimiterForgetsIdleChats(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := newChatLimiter(2)
+	l.now = clock.now
+
+	for id := int64(1); id <= maxTrackedChats; id++ {
+		l.allow(id)
+	}
+	clock.t = clock.t.Add(30 * time.Second)
+	l.allow(1)
+	l.allow(1)
+
+	clock.t = clock.t.Add(40 * time.Second)
+	l.allow(maxTrackedChats + 1)
+	if len(l.buckets) != 2 {
+		t.Fatalf("expected only chats active within the last minute kept, got %d", len(l.buckets))
+	}
+	if ok, _, _ := l.allow(1); !ok {
+		t.Fatal("expected the kept chat to have refilled a token")
+	}
+}
+
+// stubContext is a tele.Context for one chat that records what is sent.
+type stubContext struct {
+	tele.Context
+	chat *tele.Chat
+	sent []string
+}
+
+func (c *stubContext) Chat() *tele.Chat { return c.chat }
+
+func (c *stubContext) Send(what interface{}, opts ...interface{}) error {
+	c.sent = append(c.sent, what.(string))
+	return nil
+}
+
+func TestRateLimitMiddlewareSendsOneCooldownNotice(t *testing.T) {
+	throttle, _ := newTestThrottle(30)
+	handled := 0
+	handler := rateLimitMiddleware(newChatLimiter(1), throttle)(func(c tele.Context) error {
+		handled++
+		return c.Send("pong")
+	})
+
+	c := &stubContext{chat: &tele.Chat{ID: 5}}
+	for i := 0; i < 3; i++ {
+		if err := handler(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if handled != 1 {
+		t.Fatalf("expected one handled command, got %d", handled)
+	}
+	if len(c.sent) != 2 || c.sent[0] != "pong" || !strings.Contains(c.sent[1], "Please wait 60s") {
+		t.Fatalf("unexpected replies %q", c.sent)
+	}
+}
+
+func TestRateLimitMiddlewareDisabled(t *testing.T) {
+	throttle, _ := newTestThrottle(30)
+	handled := 0
+	handler := rateLimitMiddleware(newChatLimiter(0), throttle)(func(c tele.Context) error {
+		handled++
+		return nil
+	})
+
+	c := &stubContext{chat: &tele.Chat{ID: 5}}
+	for i := 0; i < 50; i++ {
+		_ = handler(c)
+	}
+	if handled != 50 {
+		t.Fatalf("expected every command handled, got %d", handled)
+	}
+}
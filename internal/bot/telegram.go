@@ -11,13 +11,15 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
 
 	tele "gopkg.in/telebot.v3"
 )
 
 type PriceQuerier interface {
 	GetCurrentPrice(ctx context.Context, symbol string) (*domain.PriceSnapshot, error)
+	GetCurrentPriceIn(ctx context.Context, symbol, currency string) (*domain.PriceSnapshot, error)
 }
 
 type SignalLister interface {
@@ -25,11 +27,34 @@ type SignalLister interface {
 	GetSignalImage(ctx context.Context, signalID int64) (*domain.SignalImageData, error)
 }
 
+// FearGreedReader reads the newest stored Crypto Fear & Greed value. The
+// market intel repository satisfies it.
+type FearGreedReader interface {
+	LatestFearGreed(ctx context.Context) (*domain.FearGreedReading, error)
+}
+
+// EventCalendar lists scheduled market events starting in a range. The
+// market intel calendar satisfies it.
+type EventCalendar interface {
+	EventsBetween(from, to time.Time) []domain.MarketEvent
+}
+
+// Upcoming events in the /signals header: those starting in the next three
+// days, at most three of them.
+const (
+	headerEventLookahead = 72 * time.Hour
+	headerEventMax       = 3
+)
+
+// fearGreedMaxAge is how old the newest index value may be and still be
+// shown; the index updates daily.
+const fearGreedMaxAge = 48 * time.Hour
+
 type Advisor interface {
 	Ask(ctx context.Context, chatID int64, message string) (string, error)
 }
 
-func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, advisorService Advisor) *AlertDispatcher {
+func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, advisorService Advisor, tokenIssuer APITokenIssuer, fearGreed FearGreedReader, events EventCalendar, limits RateLimits) *AlertDispatcher {
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	if token == "" {
 		log.Println("TELEGRAM_BOT_TOKEN not set, skipping Telegram bot startup")
@@ -38,12 +63,17 @@ func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, adv
 	pref := tele.Settings{
 		Token:  token,
 		Poller: &tele.LongPoller{Timeout: 10 * time.Second},
+		Client: httpclient.New(httpclient.Telegram, time.Minute),
 	}
 	b, err := tele.NewBot(pref)
 	if err != nil {
 		log.Fatalf("failed to create Telegram bot: %v", err)
 	}
-	alerts := NewAlertDispatcher(b, signalService)
+	// Replies and alerts share one throttle so together they stay under
+	// Telegram's send limits.
+	throttle := newSendThrottle(limits.SendsPerSecond)
+	b.Use(rateLimitMiddleware(newChatLimiter(limits.ChatCommandsPerMinute), throttle))
+	alerts := NewAlertDispatcher(throttledSender{next: b, throttle: throttle}, signalService)
 
 	b.Handle("/ping", func(c tele.Context) error {
 		return c.Send("pong")
@@ -52,39 +82,43 @@ func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, adv
 	b.Handle("/price", func(c tele.Context) error {
 		args := c.Args()
 		if len(args) == 0 {
-			return c.Send(fmt.Sprintf("Usage: /price BTC\nSupported: %s", strings.Join(domain.SupportedSymbols, ", ")))
+			return c.Send(fmt.Sprintf("Usage: /price BTC [EUR|GBP|JPY]\nSupported: %s", strings.Join(domain.SupportedSymbols(), ", ")))
 		}
 		symbol := strings.ToUpper(args[0])
-		if _, ok := domain.CoinGeckoID[symbol]; !ok {
-			return c.Send(fmt.Sprintf("Unknown symbol: %s\nSupported: %s", symbol, strings.Join(domain.SupportedSymbols, ", ")))
+		if !domain.IsSupportedSymbol(symbol) {
+			return c.Send(fmt.Sprintf("Unknown symbol: %s\nSupported: %s", symbol, strings.Join(domain.SupportedSymbols(), ", ")))
 		}
-		snapshot, err := priceService.GetCurrentPrice(context.Background(), symbol)
+		currency := domain.CurrencyUSD
+		if len(args) > 1 {
+			code, ok := domain.NormalizeCurrency(args[1])
+			if !ok {
+				return c.Send(fmt.Sprintf("Unknown currency: %s\nSupported: %s", code, strings.Join(domain.QuoteCurrencies(), ", ")))
+			}
+			currency = code
+		}
+		snapshot, err := priceService.GetCurrentPriceIn(context.Background(), symbol, currency)
 		if err != nil {
 			return c.Send(fmt.Sprintf("Error fetching price for %s: %v", symbol, err))
 		}
-		msg := fmt.Sprintf(
-			"%s\nPrice: $%.2f\n24h Change: %.2f%%\n24h Volume: $%.0f",
-			symbol, snapshot.PriceUSD, snapshot.Change24hPct, snapshot.Volume24h,
-		)
-		return c.Send(msg)
+		return c.Send(formatPriceReply(symbol, snapshot))
 	})
 
 	b.Handle("/volume", func(c tele.Context) error {
 		args := c.Args()
 		if len(args) == 0 {
-			return c.Send(fmt.Sprintf("Usage: /volume SOL\nSupported: %s", strings.Join(domain.SupportedSymbols, ", ")))
+			return c.Send(fmt.Sprintf("Usage: /volume SOL\nSupported: %s", strings.Join(domain.SupportedSymbols(), ", ")))
 		}
 		symbol := strings.ToUpper(args[0])
-		if _, ok := domain.CoinGeckoID[symbol]; !ok {
-			return c.Send(fmt.Sprintf("Unknown symbol: %s\nSupported: %s", symbol, strings.Join(domain.SupportedSymbols, ", ")))
+		if !domain.IsSupportedSymbol(symbol) {
+			return c.Send(fmt.Sprintf("Unknown symbol: %s\nSupported: %s", symbol, strings.Join(domain.SupportedSymbols(), ", ")))
 		}
 		snapshot, err := priceService.GetCurrentPrice(context.Background(), symbol)
 		if err != nil {
 			return c.Send(fmt.Sprintf("Error fetching volume for %s: %v", symbol, err))
 		}
 		msg := fmt.Sprintf(
-			"%s 24h Trading Volume\nVolume: $%.0f\nPrice: $%.2f\n24h Change: %.2f%%",
-			symbol, snapshot.Volume24h, snapshot.PriceUSD, snapshot.Change24hPct,
+			"%s 24h Trading Volume\nVolume: %s\nPrice: %s\n24h Change: %s",
+			symbol, snapshot.Volume24h.Compact(), domain.FormatPrice(symbol, snapshot.PriceUSD), snapshot.Change24hPct,
 		)
 		return c.Send(msg)
 	})
@@ -107,11 +141,12 @@ func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, adv
 			return c.Send("No matching signals right now.")
 		}
 
-		if err := c.Send("Latest signals:"); err != nil {
+		loc := alerts.Timezone(c.Chat().ID)
+		if err := c.Send(signalsHeader(context.Background(), fearGreed, events, time.Now(), loc)); err != nil {
 			return err
 		}
 		for _, s := range signals {
-			if err := sendSignalWithOptionalImage(c, signalService, s); err != nil {
+			if err := sendSignalWithOptionalImage(c, signalService, s, loc); err != nil {
 				return err
 			}
 		}
@@ -148,6 +183,18 @@ func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, adv
 		}
 	})
 
+	b.Handle("/tz", func(c tele.Context) error {
+		chat := c.Chat()
+		if chat == nil {
+			return c.Send("Unable to detect chat")
+		}
+		return c.Send(timezoneReply(alerts, chat.ID, c.Args(), time.Now()))
+	})
+
+	b.Handle("/token", func(c tele.Context) error {
+		return handleTokenCommand(c, tokenIssuer)
+	})
+
 	b.Handle("/ask", func(c tele.Context) error {
 		if advisorService == nil {
 			return c.Send("Advisor not configured. Set OPENAI_API_KEY to enable.")
@@ -159,6 +206,23 @@ func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, adv
 		return handleAdvisorQuery(c, advisorService, question)
 	})
 
+	b.Handle("/reset", func(c tele.Context) error {
+		mgr, ok := advisorService.(ConversationManager)
+		if !ok {
+			return c.Send("Advisor not configured. Set OPENAI_API_KEY to enable.")
+		}
+		return c.Send(resetReply(context.Background(), mgr, c.Chat().ID))
+	})
+
+	b.Handle("/history", func(c tele.Context) error {
+		mgr, ok := advisorService.(ConversationManager)
+		if !ok {
+			return c.Send("Advisor not configured. Set OPENAI_API_KEY to enable.")
+		}
+		_ = c.Notify(tele.Typing)
+		return c.Send(historyReply(context.Background(), mgr, c.Chat().ID))
+	})
+
 	b.Handle(tele.OnText, func(c tele.Context) error {
 		if advisorService == nil {
 			return nil
@@ -178,7 +242,7 @@ func StartTelegramBot(priceService PriceQuerier, signalService SignalLister, adv
 func handleAdvisorQuery(c tele.Context, adv Advisor, question string) error {
 	_ = c.Notify(tele.Typing)
 
-	reply, err := adv.Ask(context.Background(), c.Chat().ID, question)
+	reply, err := adv.Ask(chatContext(context.Background(), c.Chat().ID), c.Chat().ID, question)
 	if err != nil {
 		log.Printf("advisor error for chat %d: %v", c.Chat().ID, err)
 		return c.Send("Sorry, I'm having trouble right now. Try /price or /signals for raw data.")
@@ -237,7 +301,7 @@ func parseSignalArgs(args []string) (domain.SignalFilter, error) {
 			return domain.SignalFilter{}, errors.New("multiple symbols provided")
 		}
 		symbol := strings.ToUpper(arg)
-		if _, ok := domain.CoinGeckoID[symbol]; !ok {
+		if !domain.IsSupportedSymbol(symbol) {
 			return domain.SignalFilter{}, errors.New("unsupported symbol")
 		}
 		filter.Symbol = symbol
@@ -246,7 +310,85 @@ func parseSignalArgs(args []string) (domain.SignalFilter, error) {
 	return filter, nil
 }
 
-func formatSignal(s domain.Signal) string {
+// signalsHeader opens a /signals reply, with the scheduled events of the
+// next three days in loc and the Fear & Greed index as
+// market context when a recent value is stored.
+func signalsHeader(ctx context.Context, fearGreed FearGreedReader, events EventCalendar, now time.Time, loc *time.Location) string {
+	header := "Latest signals:"
+	if fearGreed != nil {
+		reading, err := fearGreed.LatestFearGreed(ctx)
+		if err != nil {
+			log.Printf("fear & greed lookup: %v", err)
+		} else if reading != nil && now.Sub(reading.Timestamp) <= fearGreedMaxAge {
+			line := fmt.Sprintf("Fear & Greed: %d", reading.Value)
+			if reading.Classification != "" {
+				line += fmt.Sprintf(" (%s)", reading.Classification)
+			}
+			header += "\n" + line
+		}
+	}
+	if events != nil {
+		upcoming := events.EventsBetween(now, now.Add(headerEventLookahead))
+		for i, e := range upcoming {
+			if i == headerEventMax {
+				break
+			}
+			header += "\n" + upcomingEventLine(e, now, loc)
+		}
+	}
+	return header
+}
+
+// upcomingEventLine renders an event as "Upcoming: FOMC rate decision, Wed
+// 18 Mar 18:00 UTC (in 1d 6h)", prefixing unlocks with their token.
+func upcomingEventLine(e domain.MarketEvent, now time.Time, loc *time.Location) string {
+	title := e.Title
+	if e.Symbol != "" && !strings.Contains(strings.ToUpper(title), e.Symbol) {
+		title = e.Symbol + " " + title
+	}
+	left := e.StartsAt.Sub(now).Round(time.Hour)
+	days, hours := int(left/(24*time.Hour)), int(left%(24*time.Hour)/time.Hour)
+	in := fmt.Sprintf("%dh", hours)
+	if days > 0 {
+		in = fmt.Sprintf("%dd %dh", days, hours)
+	}
+	return fmt.Sprintf("Upcoming: %s, %s (in %s)", title, e.StartsAt.In(loc).Format("Mon 02 Jan 15:04 MST"), in)
+}
+
+// formatPriceReply renders a /price reply. A price quoted in another
+// currency is shown with the dollar price and the rate used.
+func formatPriceReply(symbol string, snapshot *domain.PriceSnapshot) string {
+	price := domain.FormatPrice(symbol, snapshot.PriceUSD)
+	var rate string
+	if q := snapshot.Quote; q != nil {
+		price = fmt.Sprintf("%s (%s)", domain.FormatPriceIn(symbol, q.Price, q.Currency), price)
+		rate = fmt.Sprintf("\n1 USD = %s %s", strconv.FormatFloat(q.Rate, 'f', -1, 64), q.Currency)
+		if q.RateDate != "" {
+			rate += fmt.Sprintf(" (ECB %s)", q.RateDate)
+		}
+	}
+	return fmt.Sprintf(
+		"%s\nPrice: %s\n24h Change: %s\n24h Volume: %s%s",
+		symbol, price, snapshot.Change24hPct, snapshot.Volume24h.Compact(), rate,
+	)
+}
+
+// timezoneReply handles /tz: no argument shows the chat's time zone, a
+// name such as Europe/Berlin sets it, and UTC resets it.
+func timezoneReply(alerts *AlertDispatcher, chatID int64, args []string, now time.Time) string {
+	if len(args) == 0 {
+		loc := alerts.Timezone(chatID)
+		return fmt.Sprintf("Times are shown in %s (now %s).\nUsage: /tz Europe/Berlin | /tz UTC", loc, now.In(loc).Format(time.RFC822))
+	}
+	loc, err := domain.LoadTimezone(args[0])
+	if err != nil {
+		return fmt.Sprintf("Unknown time zone: %s\nUse an IANA name such as Europe/Berlin, America/New_York or Asia/Tokyo.", args[0])
+	}
+	alerts.SetTimezone(chatID, loc)
+	return fmt.Sprintf("Alerts and signals will show times in %s (now %s).", loc, now.In(loc).Format(time.RFC822))
+}
+
+func formatSignal(s domain.Signal, loc *time.Location) string {
 	return fmt.Sprintf(
 		"#%d %s %s %s %s risk %d at %s",
 		s.ID,
@@ -255,12 +397,12 @@ func formatSignal(s domain.Signal) string {
 		strings.ToUpper(s.Indicator),
 		strings.ToUpper(string(s.Direction)),
 		s.Risk,
-		s.Timestamp.UTC().Format(time.RFC822),
+		s.Timestamp.In(loc).Format(time.RFC822),
 	)
 }
 
-func sendSignalWithOptionalImage(c tele.Context, signalService SignalLister, s domain.Signal) error {
-	caption := formatSignal(s)
+func sendSignalWithOptionalImage(c tele.Context, signalService SignalLister, s domain.Signal, loc *time.Location) error {
+	caption := formatSignal(s, loc)
 	if signalService == nil || s.ID <= 0 {
 		return c.Send(caption)
 	}
@@ -1,14 +1,18 @@
 package bot
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestStartTelegramBotSkipsWithoutToken(t *testing.T) {
 	t.Setenv("TELEGRAM_BOT_TOKEN", "")
-	StartTelegramBot(nil, nil, nil)
+	StartTelegramBot(nil, nil, nil, nil, nil, nil, RateLimits{})
 }
 
 func TestParseSignalArgsSymbolAndRisk(t *testing.T) {
@@ -32,3 +36,97 @@ func TestParseSignalArgsRejectsInvalidRisk(t *testing.T) {
 		t.Fatal("expected risk parsing error")
 	}
 }
+
+func TestSignalsHeaderAddsRecentFearGreed(t *testing.T) {
+	now := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	recent := fearGreedReaderStub{reading: &domain.FearGreedReading{Timestamp: now.Add(-12 * time.Hour), Value: 72, Classification: "Greed"}}
+	if got := signalsHeader(context.Background(), recent, nil, now, time.UTC); got != "Latest signals:\nFear & Greed: 72 (Greed)" {
+		t.Fatalf("unexpected header %q", got)
+	}
+
+	for name, reader := range map[string]FearGreedReader{
+		"none":   nil,
+		"empty":  fearGreedReaderStub{},
+		"stale":  fearGreedReaderStub{reading: &domain.FearGreedReading{Timestamp: now.Add(-72 * time.Hour), Value: 72}},
+		"failed": fearGreedReaderStub{err: errors.New("down")},
+	} {
+		if got := signalsHeader(context.Background(), reader, nil, now, time.UTC); got != "Latest signals:" {
+			t.Fatalf("%s: expected the plain header, got %q", name, got)
+		}
+	}
+}
+
+type eventCalendarStub []domain.MarketEvent
+
+func (s eventCalendarStub) EventsBetween(from, to time.Time) []domain.MarketEvent {
+	var out []domain.MarketEvent
+	for _, e := range s {
+		if !e.StartsAt.Before(from) && !e.StartsAt.After(to) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestSignalsHeaderListsUpcomingEvents(t *testing.T) {
+	now := time.Date(2026, 3, 17, 10, 0, 0, 0, time.UTC)
+	calendar := eventCalendarStub{
+		{Kind: domain.MarketEventUnlock, Symbol: "SUI", StartsAt: now.Add(3 * time.Hour), Title: "token unlock"},
+		{Kind: domain.MarketEventFOMC, StartsAt: time.Date(2026, 3, 18, 18, 0, 0, 0, time.UTC), Title: "FOMC rate decision"},
+		{Kind: domain.MarketEventCPI, StartsAt: now.Add(5 * 24 * time.Hour), Title: "US CPI release"},
+	}
+	berlin, err := domain.LoadTimezone("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Latest signals:\n" +
+		"Upcoming: SUI token unlock, Tue 17 Mar 14:00 CET (in 3h)\n" +
+		"Upcoming: FOMC rate decision, Wed 18 Mar 19:00 CET (in 1d 8h)"
+	if got := signalsHeader(context.Background(), nil, calendar, now, berlin); got != want {
+		t.Fatalf("unexpected header %q", got)
+	}
+}
+
+func TestFormatPriceReply(t *testing.T) {
+	snap := domain.PriceSnapshot{Symbol: "BTC", PriceUSD: 97012.5, Change24hPct: 2.5, Volume24h: 45.1e9}
+	if got := formatPriceReply("BTC", &snap); got != "BTC\nPrice: $97,012.50\n24h Change: +2.50%\n24h Volume: $45.1B" {
+		t.Fatalf("unexpected USD reply %q", got)
+	}
+
+	eur := snap.In("EUR", 0.92, time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	want := "BTC\nPrice: €89,251.50 ($97,012.50)\n24h Change: +2.50%\n24h Volume: $45.1B\n1 USD = 0.92 EUR (ECB 2026-03-02)"
+	if got := formatPriceReply("BTC", eur); got != want {
+		t.Fatalf("unexpected EUR reply %q", got)
+	}
+}
+
+type fearGreedReaderStub struct {
+	reading *domain.FearGreedReading
+	err     error
+}
+
+func (s fearGreedReaderStub) LatestFearGreed(context.Context) (*domain.FearGreedReading, error) {
+	return s.reading, s.err
+}
+
+func TestTimezoneReply(t *testing.T) {
+	alerts := NewAlertDispatcher(nil, nil)
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	if got := timezoneReply(alerts, 7, nil, now); !strings.HasPrefix(got, "Times are shown in UTC (now 15 Jan 26 12:00 UTC)") {
+		t.Fatalf("unexpected status reply %q", got)
+	}
+	if got := timezoneReply(alerts, 7, []string{"Atlantis/Lost"}, now); !strings.HasPrefix(got, "Unknown time zone: Atlantis/Lost") {
+		t.Fatalf("unexpected error reply %q", got)
+	}
+	if got := timezoneReply(alerts, 7, []string{"Asia/Tokyo"}, now); got != "Alerts and signals will show times in Asia/Tokyo (now 15 Jan 26 21:00 JST)." {
+		t.Fatalf("unexpected set reply %q", got)
+	}
+	if alerts.Timezone(7).String() != "Asia/Tokyo" || alerts.Timezone(8) != time.UTC {
+		t.Fatalf("expected only chat 7 in Tokyo time")
+	}
+	sig := domain.Signal{ID: 3, Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong, Risk: domain.RiskLevel2, Timestamp: now}
+	if got := formatSignal(sig, alerts.Timezone(7)); got != "#3 BTC 1h RSI LONG risk 2 at 15 Jan 26 21:00 JST" {
+		t.Fatalf("unexpected signal line %q", got)
+	}
+}
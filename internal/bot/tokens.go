@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// telegramTokenLabel labels keys issued through /token so rotating one does
+// not touch keys an operator issued to the same tenant.
+const telegramTokenLabel = "telegram"
+
+// APITokenIssuer issues self-service REST API keys bound to a chat's tenant.
+type APITokenIssuer interface {
+	Rotate(ctx context.Context, tenantID, label string) (string, *repository.APIKey, error)
+	RevokeTenant(ctx context.Context, tenantID string) (int, error)
+}
+
+func parseTokenMode(args []string) (string, error) {
+	if len(args) == 0 {
+		return "issue", nil
+	}
+	if len(args) > 1 {
+		return "", errors.New("too many arguments")
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(args[0])); mode {
+	case "new", "issue":
+		return "issue", nil
+	case "revoke":
+		return mode, nil
+	default:
+		return "", errors.New("unknown mode")
+	}
+}
+
+// tokenReply runs a /token request for a private chat and returns the reply.
+// Keys are bound to domain.TelegramTenantID(chatID). They read shared data
+// such as signals and predictions, and the chat's own tenant-owned data, but
+// cannot reach operator routes that trigger work.
+func tokenReply(ctx context.Context, issuer APITokenIssuer, chatID int64, mode string) string {
+	tenantID := domain.TelegramTenantID(chatID)
+	ctx = domain.WithTenant(ctx, tenantID)
+	ctx = domain.WithActor(ctx, fmt.Sprintf("telegram:%d", chatID))
+
+	switch mode {
+	case "revoke":
+		revoked, err := issuer.RevokeTenant(ctx, tenantID)
+		if err != nil {
+			log.Printf("revoke api keys for chat %d: %v", chatID, err)
+			return "Could not revoke your API token right now. Try again later."
+		}
+		if revoked == 0 {
+			return "You have no active API token."
+		}
+		return "Your API token has been revoked."
+	default:
+		rawKey, _, err := issuer.Rotate(ctx, tenantID, telegramTokenLabel)
+		if err != nil {
+			log.Printf("issue api key for chat %d: %v", chatID, err)
+			return "Could not issue an API token right now. Try again later."
+		}
+		return fmt.Sprintf(
+			"Your API token:\n%s\n\nSend it as the X-API-Key header, e.g. GET /api/signals. "+
+				"It is shown only once and replaces any token issued before. Use /token revoke to disable it.",
+			rawKey,
+		)
+	}
+}
+
+func handleTokenCommand(c tele.Context, issuer APITokenIssuer) error {
+	if issuer == nil {
+		return c.Send("API tokens are not available on this deployment.")
+	}
+	chat := c.Chat()
+	if chat == nil {
+		return c.Send("Unable to detect chat")
+	}
+	if chat.Type != tele.ChatPrivate {
+		return c.Send("Use /token in a private chat with the bot so your token stays private.")
+	}
+	mode, err := parseTokenMode(c.Args())
+	if err != nil {
+		return c.Send("Usage: /token | /token revoke")
+	}
+	return c.Send(tokenReply(context.Background(), issuer, chat.ID, mode))
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+)
+
+type tokenIssuerStub struct {
+	tenants []string
+	labels  []string
+	actors  []string
+	revoked int
+	err     error
+}
+
+func (s *tokenIssuerStub) Rotate(ctx context.Context, tenantID, label string) (string, *repository.APIKey, error) {
+	s.tenants = append(s.tenants, tenantID)
+	s.labels = append(s.labels, label)
+	s.actors = append(s.actors, domain.ActorFromContext(ctx))
+	if s.err != nil {
+		return "", nil, s.err
+	}
+	return "bfu_secret", &repository.APIKey{ID: 1, TenantID: tenantID, Label: label}, nil
+}
+
+func (s *tokenIssuerStub) RevokeTenant(ctx context.Context, tenantID string) (int, error) {
+	s.tenants = append(s.tenants, tenantID)
+	return s.revoked, s.err
+}
+
+func TestParseTokenMode(t *testing.T) {
+	cases := map[string]string{"": "issue", "new": "issue", "Revoke": "revoke"}
+	for arg, want := range cases {
+		var args []string
+		if arg != "" {
+			args = []string{arg}
+		}
+		got, err := parseTokenMode(args)
+		if err != nil || got != want {
+			t.Fatalf("parseTokenMode(%q) = %q, %v; want %q", arg, got, err, want)
+		}
+	}
+	if _, err := parseTokenMode([]string{"delete"}); err == nil {
+		t.Fatal("expected error for unknown mode")
+	}
+	if _, err := parseTokenMode([]string{"revoke", "now"}); err == nil {
+		t.Fatal("expected error for extra arguments")
+	}
+}
+
+func TestTokenReplyIssuesKeyBoundToChat(t *testing.T) {
+	issuer := &tokenIssuerStub{}
+	reply := tokenReply(context.Background(), issuer, 4242, "issue")
+	if !strings.Contains(reply, "bfu_secret") {
+		t.Fatalf("expected raw key in reply, got %q", reply)
+	}
+	if issuer.tenants[0] != "telegram-4242" || issuer.labels[0] != telegramTokenLabel {
+		t.Fatalf("unexpected issue call: %+v", issuer)
+	}
+	if issuer.actors[0] != "telegram:4242" {
+		t.Fatalf("expected telegram actor, got %q", issuer.actors[0])
+	}
+}
+
+func TestTokenReplyRevoke(t *testing.T) {
+	issuer := &tokenIssuerStub{revoked: 1}
+	if reply := tokenReply(context.Background(), issuer, 7, "revoke"); !strings.Contains(reply, "revoked") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+	issuer = &tokenIssuerStub{}
+	if reply := tokenReply(context.Background(), issuer, 7, "revoke"); !strings.Contains(reply, "no active") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+}
+
+func TestTokenReplyHidesErrors(t *testing.T) {
+	issuer := &tokenIssuerStub{err: errors.New("db down")}
+	if reply := tokenReply(context.Background(), issuer, 7, "issue"); strings.Contains(reply, "db down") {
+		t.Fatalf("internal error leaked to chat: %q", reply)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"bug-free-umbrella/internal/startup"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -21,6 +23,9 @@ var (
 	parseRedisURL = redis.ParseURL
 )
 
+// InitRedis connects to REDIS_URL, retrying with backoff until ctx ends.
+// If Redis still does not answer, the client is kept so later commands
+// reconnect, and callers hold background work back until Ping succeeds.
 func InitRedis(ctx context.Context) {
 	addr := os.Getenv("REDIS_URL")
 	if addr == "" {
@@ -37,8 +42,21 @@ func InitRedis(ctx context.Context) {
 	}
 
 	Client = newRedisClient(opts)
-	if err := pingRedis(ctx, Client); err != nil {
-		log.Fatalf("failed to connect to Redis: %v", err)
+	client := Client
+	err := startup.Retry(ctx, "Redis", func(ctx context.Context) error {
+		return pingRedis(ctx, client)
+	})
+	if err != nil {
+		log.Printf("Redis not reachable at startup: %v; continuing, background jobs wait for it", err)
+		return
 	}
 	log.Println("Connected to Redis")
 }
+
+// Ping reports whether Redis answers. It is nil when no client was set up.
+func Ping(ctx context.Context) error {
+	if Client == nil {
+		return nil
+	}
+	return pingRedis(ctx, Client)
+}
@@ -2,7 +2,9 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -111,3 +113,29 @@ func TestInitRedisWithURL(t *testing.T) {
 		t.Fatalf("unexpected parsed options: addr=%s user=%s password=%s", capturedAddr, capturedUser, capturedPassword)
 	}
 }
+
+func TestInitRedisKeepsClientWhenUnreachable(t *testing.T) {
+	t.Setenv("REDIS_URL", "redis:9999")
+
+	origPing := pingRedis
+	t.Cleanup(func() {
+		pingRedis = origPing
+		Client = nil
+	})
+
+	calls := 0
+	pingRedis = func(ctx context.Context, client *redis.Client) error {
+		calls++
+		return errors.New("connection refused")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	InitRedis(ctx)
+	if Client == nil || calls == 0 {
+		t.Fatalf("expected the client to be kept after failed pings, calls=%d", calls)
+	}
+	if err := Ping(context.Background()); err == nil {
+		t.Fatal("expected Ping to report the outage")
+	}
+}
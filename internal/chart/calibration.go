@@ -0,0 +1,72 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+const calibrationChartSize = 640
+
+// RenderCalibrationChart draws a reliability diagram: realized frequency
+// against mean predicted probability per bucket, over the perfect-calibration
+// diagonal, with bucket counts as bars along the bottom.
+func (r *Renderer) RenderCalibrationChart(cal domain.Calibration) ([]byte, error) {
+	if len(cal.Buckets) == 0 {
+		return nil, fmt.Errorf("no resolved predictions to plot")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, calibrationChartSize, calibrationChartSize))
+	fillRect(img, img.Bounds(), colBackground)
+
+	plot := image.Rect(60, 20, calibrationChartSize-20, (calibrationChartSize*78)/100)
+	countRect := image.Rect(60, plot.Max.Y+16, calibrationChartSize-20, calibrationChartSize-30)
+	drawGrid(img, plot, 10, 10)
+	drawGrid(img, countRect, 10, 2)
+
+	mapX := func(p float64) int {
+		return plot.Min.X + int(clamp01(p)*float64(plot.Dx()-1))
+	}
+	drawLine(img, mapX(0), mapValueToY(0, 0, 1, plot), mapX(1), mapValueToY(1, 0, 1, plot), colBand)
+
+	maxCount := 0
+	for _, b := range cal.Buckets {
+		maxCount = max(maxCount, b.Count)
+	}
+	for _, b := range cal.Buckets {
+		x0 := countRect.Min.X + int(clamp01(b.Lower)*float64(countRect.Dx()))
+		x1 := countRect.Min.X + int(clamp01(b.Upper)*float64(countRect.Dx())) - 2
+		y := mapValueToY(float64(b.Count), 0, float64(maxCount), countRect)
+		fillRect(img, image.Rect(x0+1, y, max(x0+2, x1), countRect.Max.Y), colVolume)
+	}
+
+	prevX, prevY := -1, -1
+	for _, b := range cal.Buckets {
+		x := mapX(b.MeanPredicted)
+		y := mapValueToY(b.RealizedFrequency, 0, 1, plot)
+		if prevX >= 0 {
+			drawLine(img, prevX, prevY, x, y, colLineA)
+		}
+		fillRect(img, image.Rect(x-3, y-3, x+4, y+4), colLineA)
+		prevX, prevY = x, y
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
@@ -0,0 +1,35 @@
+package chart
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestRenderCalibrationChart(t *testing.T) {
+	cal := domain.NewCalibration("ensemble_v1", []domain.CalibrationBucket{
+		{Lower: 0.3, Upper: 0.4, Count: 12, MeanPredicted: 0.36, RealizedFrequency: 0.3},
+		{Lower: 0.5, Upper: 0.6, Count: 40, MeanPredicted: 0.55, RealizedFrequency: 0.58},
+		{Lower: 0.9, Upper: 1.0, Count: 3, MeanPredicted: 0.95, RealizedFrequency: 1},
+	})
+
+	data, err := NewRenderer().RenderCalibrationChart(cal)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if img.Bounds().Dx() != calibrationChartSize {
+		t.Fatalf("expected width %d, got %d", calibrationChartSize, img.Bounds().Dx())
+	}
+}
+
+func TestRenderCalibrationChartEmpty(t *testing.T) {
+	if _, err := NewRenderer().RenderCalibrationChart(domain.Calibration{}); err == nil {
+		t.Fatal("expected error without buckets")
+	}
+}
@@ -0,0 +1,71 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ImageFormat is how signal charts are encoded, as named by
+// CHART_IMAGE_FORMAT.
+type ImageFormat string
+
+const (
+	// FormatPNG is full-colour PNG, the default.
+	FormatPNG ImageFormat = "png"
+	// FormatPalettePNG is PNG with a colour palette, falling back to full
+	// colour when a chart has more than 256 colours.
+	FormatPalettePNG ImageFormat = "png-palette"
+	// FormatWebP is lossless WebP.
+	FormatWebP ImageFormat = "webp"
+)
+
+// encodeImage encodes img in format and returns the bytes and MIME type.
+func encodeImage(img *image.RGBA, format ImageFormat) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatWebP:
+		if err := encodeWebP(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/webp", nil
+	case FormatPalettePNG:
+		if paletted, ok := toPaletted(img); ok {
+			enc := png.Encoder{CompressionLevel: png.BestCompression}
+			if err := enc.Encode(&buf, paletted); err != nil {
+				return nil, "", err
+			}
+			return buf.Bytes(), "image/png", nil
+		}
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/png", nil
+}
+
+// toPaletted copies img into a paletted image when it has no more than 256
+// distinct colours. Charts are drawn without anti-aliasing, so they
+// normally have a couple of dozen at most.
+func toPaletted(img *image.RGBA) (*image.Paletted, bool) {
+	b := img.Bounds()
+	out := image.NewPaletted(b, nil)
+	index := make(map[color.RGBA]uint8, 32)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			i, ok := index[c]
+			if !ok {
+				if len(out.Palette) == 256 {
+					return nil, false
+				}
+				i = uint8(len(out.Palette))
+				index[c] = i
+				out.Palette = append(out.Palette, c)
+			}
+			out.SetColorIndex(x, y, i)
+		}
+	}
+	return out, true
+}
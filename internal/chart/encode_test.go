@@ -0,0 +1,96 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"golang.org/x/image/webp"
+)
+
+func TestRenderSignalChartFormatsDecodeToTheSamePixels(t *testing.T) {
+	candles := buildTestCandles(160)
+	signal := domain.Signal{Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorMACD, Timestamp: time.Now().UTC()}
+
+	renderer := NewRenderer()
+	full, err := renderer.RenderSignalChart(candles, signal)
+	if err != nil {
+		t.Fatalf("render png: %v", err)
+	}
+	want, err := png.Decode(bytes.NewReader(full.Bytes))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+
+	for _, tc := range []struct {
+		format ImageFormat
+		mime   string
+		decode func([]byte) (image.Image, error)
+	}{
+		{FormatPalettePNG, "image/png", func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }},
+		{FormatWebP, "image/webp", func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) }},
+	} {
+		t.Run(string(tc.format), func(t *testing.T) {
+			renderer.SetFormat(tc.format)
+			got, err := renderer.RenderSignalChart(candles, signal)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if got.Ref.MimeType != tc.mime {
+				t.Fatalf("expected %s, got %s", tc.mime, got.Ref.MimeType)
+			}
+			if len(got.Bytes) >= len(full.Bytes)/2 {
+				t.Fatalf("expected well under half the %d PNG bytes, got %d", len(full.Bytes), len(got.Bytes))
+			}
+			img, err := tc.decode(got.Bytes)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			assertSamePixels(t, want, img)
+		})
+	}
+}
+
+func TestEncodeWebPRoundTrips(t *testing.T) {
+	// Palettes of each packing width, more than 256 colours, odd sizes and
+	// transparency.
+	for _, colours := range []int{1, 2, 3, 9, 100, 4096} {
+		img := image.NewRGBA(image.Rect(0, 0, 37, 23))
+		for y := 0; y < 23; y++ {
+			for x := 0; x < 37; x++ {
+				v := (x*3 + y*5) % colours
+				img.SetRGBA(x, y, color.RGBA{R: uint8(v), G: uint8(v >> 4), B: uint8(v * 7), A: 255})
+			}
+		}
+		img.SetRGBA(36, 22, color.RGBA{R: 40, G: 20, B: 10, A: 128})
+		var buf bytes.Buffer
+		if err := encodeWebP(&buf, img); err != nil {
+			t.Fatalf("%d colours: encode: %v", colours, err)
+		}
+		got, err := webp.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%d colours: decode: %v", colours, err)
+		}
+		assertSamePixels(t, img, got)
+	}
+}
+
+func assertSamePixels(t *testing.T, want, got image.Image) {
+	t.Helper()
+	if want.Bounds() != got.Bounds() {
+		t.Fatalf("bounds differ: %v vs %v", want.Bounds(), got.Bounds())
+	}
+	b := want.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if color.NRGBAModel.Convert(want.At(x, y)) != color.NRGBAModel.Convert(got.At(x, y)) {
+				t.Fatalf("pixel (%d,%d) differs: %v vs %v", x, y, want.At(x, y), got.At(x, y))
+			}
+		}
+	}
+}
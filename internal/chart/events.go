@@ -0,0 +1,78 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// eventLookahead is how far past the last candle upcoming events are listed.
+const eventLookahead = 72 * time.Hour
+
+var colEvent = color.RGBA{R: 148, G: 62, B: 160, A: 255}
+
+// EventSource lists the scheduled market events starting in a range. The
+// market intel calendar satisfies it.
+type EventSource interface {
+	EventsBetween(from, to time.Time) []domain.MarketEvent
+}
+
+// drawEvents marks the events concerning symbol that start within the
+// chart with a dashed line at their candle, labelled with the kind's
+// initial near the bottom of the price pane, and lists those starting in
+// the next three days in its top right corner with the hours until each.
+func drawEvents(img *image.RGBA, mainRect image.Rectangle, candles []domain.Candle, symbol string, events []domain.MarketEvent) {
+	if len(candles) == 0 || len(events) == 0 {
+		return
+	}
+	step := domain.IntervalDuration(candles[0].Interval)
+	end := candles[len(candles)-1].OpenTime.Add(step)
+	labelY := mainRect.Max.Y - 5*glyphScale - 4
+	upcomingY := mainRect.Min.Y + 3
+	for _, e := range events {
+		if !e.AppliesTo(symbol) {
+			continue
+		}
+		initial := eventInitial(e.Kind)
+		if !e.StartsAt.Before(end) {
+			label := fmt.Sprintf("%s %dH", initial, int(math.Ceil(e.StartsAt.Sub(end).Hours())))
+			drawText(img, mainRect.Max.X-4-textWidth(label), upcomingY, label, colEvent)
+			upcomingY += 5*glyphScale + 4
+			continue
+		}
+		i := eventCandle(candles, e.StartsAt)
+		if i < 0 {
+			continue
+		}
+		x := mapIndexToX(i, len(candles), mainRect)
+		drawDashedVLine(img, x, mainRect.Min.Y, mainRect.Max.Y, colEvent)
+		drawText(img, x+3, labelY, initial, colEvent)
+	}
+}
+
+// eventCandle returns the index of the candle open at t, or -1 when t is
+// before the first.
+func eventCandle(candles []domain.Candle, t time.Time) int {
+	idx := -1
+	for i, c := range candles {
+		if c.OpenTime.After(t) {
+			break
+		}
+		idx = i
+	}
+	return idx
+}
+
+func eventInitial(kind string) string {
+	switch kind {
+	case domain.MarketEventFOMC, domain.MarketEventCPI, domain.MarketEventUnlock:
+		return strings.ToUpper(kind[:1])
+	default:
+		return "E"
+	}
+}
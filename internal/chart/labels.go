@@ -0,0 +1,79 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+const (
+	glyphScale   = 2
+	glyphSpacing = 1
+)
+
+// glyphs is a 3x5 pixel font covering what price, session and event labels
+// need. Narrow punctuation is one column wide.
+var glyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	'.': {".", ".", ".", ".", "#"},
+	',': {".", ".", ".", "#", "#"},
+	' ': {"..", "..", "..", "..", ".."},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'C': {"###", "#..", "#..", "#..", "###"},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+}
+
+// drawPriceAxis labels the horizontal grid lines of the price pane, right
+// aligned in the left margin, at the symbol's price precision.
+func drawPriceAxis(img *image.RGBA, rect image.Rectangle, symbol string, minPrice, maxPrice float64, lines int) {
+	for i := 0; i <= lines; i++ {
+		value := maxPrice - (maxPrice-minPrice)*float64(i)/float64(lines)
+		y := rect.Min.Y + (rect.Dy()*i)/max(1, lines)
+		label := domain.FormatPriceValue(symbol, value)
+		drawText(img, rect.Min.X-4-textWidth(label), y-(5*glyphScale)/2, label, colWick)
+	}
+}
+
+func textWidth(text string) int {
+	width := 0
+	for _, ch := range text {
+		if g, ok := glyphs[ch]; ok {
+			width += len(g[0])*glyphScale + glyphSpacing
+		}
+	}
+	return max(0, width-glyphSpacing)
+}
+
+func drawText(img *image.RGBA, x, y int, text string, col color.RGBA) {
+	for _, ch := range text {
+		g, ok := glyphs[ch]
+		if !ok {
+			continue
+		}
+		for row, bits := range g {
+			for colIdx := range bits {
+				if bits[colIdx] != '#' {
+					continue
+				}
+				px := x + colIdx*glyphScale
+				py := y + row*glyphScale
+				fillRect(img, image.Rect(px, py, px+glyphScale, py+glyphScale), col)
+			}
+		}
+		x += len(g[0])*glyphScale + glyphSpacing
+	}
+}
@@ -1,15 +1,14 @@
 package chart
 
 import (
-	"bytes"
 	"fmt"
 	"image"
 	"image/color"
-	"image/png"
 	"math"
 	"sort"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/ta"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const (
@@ -29,12 +28,44 @@ var (
 	colLineB      = color.RGBA{R: 255, G: 149, B: 0, A: 255}
 	colBand       = color.RGBA{R: 104, G: 122, B: 146, A: 255}
 	colVolume     = color.RGBA{R: 120, G: 139, B: 164, A: 255}
+	colCloudUp    = color.RGBA{R: 200, G: 232, B: 226, A: 255}
+	colCloudDown  = color.RGBA{R: 244, G: 206, B: 213, A: 255}
 )
 
-type Renderer struct{}
+type Renderer struct {
+	format   ImageFormat
+	events   EventSource
+	ichimoku domain.IchimokuPeriods
+}
 
 func NewRenderer() *Renderer {
-	return &Renderer{}
+	return &Renderer{format: FormatPNG, ichimoku: domain.DefaultIchimokuPeriods}
+}
+
+// SetIchimokuPeriods sets the periods of the Ichimoku panel, which should
+// match the signal engine's. Invalid periods are ignored.
+func (r *Renderer) SetIchimokuPeriods(p domain.IchimokuPeriods) {
+	if r == nil || !p.Valid() {
+		return
+	}
+	r.ichimoku = p
+}
+
+// SetFormat sets how signal charts are encoded. Calibration charts stay
+// PNG.
+func (r *Renderer) SetFormat(format ImageFormat) {
+	if r == nil {
+		return
+	}
+	r.format = format
+}
+
+// SetEventSource marks scheduled market events from src on signal charts.
+func (r *Renderer) SetEventSource(src EventSource) {
+	if r == nil {
+		return
+	}
+	r.events = src
 }
 
 func (r *Renderer) RenderSignalChart(candles []*domain.Candle, signal domain.Signal) (*domain.SignalImageData, error) {
@@ -42,6 +73,7 @@ func (r *Renderer) RenderSignalChart(candles []*domain.Candle, signal domain.Sig
 	if len(series) < 2 {
 		return nil, fmt.Errorf("need at least 2 candles to render chart")
 	}
+	history := series
 	if len(series) > maxChartCandles {
 		series = series[len(series)-maxChartCandles:]
 	}
@@ -49,14 +81,23 @@ func (r *Renderer) RenderSignalChart(candles []*domain.Candle, signal domain.Sig
 	img := image.NewRGBA(image.Rect(0, 0, defaultChartWidth, defaultChartHeight))
 	fillRect(img, img.Bounds(), colBackground)
 
-	mainRect := image.Rect(60, 20, defaultChartWidth-20, (defaultChartHeight*72)/100)
-	auxRect := image.Rect(60, mainRect.Max.Y+16, defaultChartWidth-20, defaultChartHeight-30)
+	// The left margin holds the price axis; six-figure prices need 62px.
+	mainRect := image.Rect(72, 20, defaultChartWidth-20, (defaultChartHeight*72)/100)
+	auxRect := image.Rect(72, mainRect.Max.Y+16, defaultChartWidth-20, defaultChartHeight-30)
+	drawSessions(img, mainRect, []image.Rectangle{mainRect, auxRect}, series)
+	if r.events != nil {
+		last := series[len(series)-1]
+		to := last.OpenTime.Add(domain.IntervalDuration(last.Interval) + eventLookahead)
+		drawEvents(img, mainRect, series, signal.Symbol, r.events.EventsBetween(series[0].OpenTime, to))
+	}
 	drawGrid(img, mainRect, 8, 6)
 	drawGrid(img, auxRect, 8, 3)
 
 	if err := drawCandles(img, mainRect, series); err != nil {
 		return nil, err
 	}
+	minPrice, maxPrice := priceBounds(series)
+	drawPriceAxis(img, mainRect, signal.Symbol, minPrice, maxPrice, 6)
 
 	markerX := mapIndexToX(len(series)-1, len(series), mainRect)
 	drawLine(img, markerX, mainRect.Min.Y, markerX, mainRect.Max.Y, colMarker)
@@ -71,22 +112,30 @@ func (r *Renderer) RenderSignalChart(candles []*domain.Candle, signal domain.Sig
 		drawPriceDeltaBars(img, auxRect, series)
 	case domain.IndicatorVolumeZ:
 		drawVolumeZ(img, auxRect, series)
+	case domain.IndicatorIchimoku:
+		// The cloud lags by Kijun+Senkou B candles, so the lines are
+		// computed on every candle given and drawn for the visible ones.
+		drawIchimoku(img, auxRect, history, len(series), r.ichimoku)
+	case domain.IndicatorMLLogRegUp4H, domain.IndicatorMLXGBoostUp4H, domain.IndicatorMLEnsembleUp4H:
+		// Model features are not candle-derived series, so show the recent
+		// price action the prediction was made on.
+		drawPriceDeltaBars(img, auxRect, series)
 	default:
 		return nil, fmt.Errorf("unsupported indicator: %s", signal.Indicator)
 	}
 
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, img); err != nil {
+	data, mimeType, err := encodeImage(img, r.format)
+	if err != nil {
 		return nil, err
 	}
 
 	return &domain.SignalImageData{
 		Ref: domain.SignalImageRef{
-			MimeType: "image/png",
+			MimeType: mimeType,
 			Width:    defaultChartWidth,
 			Height:   defaultChartHeight,
 		},
-		Bytes: buf.Bytes(),
+		Bytes: data,
 	}, nil
 }
 
@@ -107,20 +156,7 @@ func drawCandles(img *image.RGBA, rect image.Rectangle, candles []domain.Candle)
 		return fmt.Errorf("no candles")
 	}
 
-	minPrice := candles[0].Low
-	maxPrice := candles[0].High
-	for _, c := range candles {
-		if c.Low < minPrice {
-			minPrice = c.Low
-		}
-		if c.High > maxPrice {
-			maxPrice = c.High
-		}
-	}
-	if maxPrice <= minPrice {
-		maxPrice = minPrice + 1
-	}
-
+	minPrice, maxPrice := priceBounds(candles)
 	candleWidth := max(3, (rect.Dx()-10)/len(candles)-1)
 	for i, c := range candles {
 		x := mapIndexToX(i, len(candles), rect)
@@ -146,6 +182,24 @@ func drawCandles(img *image.RGBA, rect image.Rectangle, candles []domain.Candle)
 	return nil
 }
 
+// priceBounds is the low-high range of candles, widened when flat.
+func priceBounds(candles []domain.Candle) (float64, float64) {
+	minPrice := candles[0].Low
+	maxPrice := candles[0].High
+	for _, c := range candles {
+		if c.Low < minPrice {
+			minPrice = c.Low
+		}
+		if c.High > maxPrice {
+			maxPrice = c.High
+		}
+	}
+	if maxPrice <= minPrice {
+		maxPrice = minPrice + 1
+	}
+	return minPrice, maxPrice
+}
+
 func drawRSI(img *image.RGBA, rect image.Rectangle, candles []domain.Candle) {
 	closes := extractCloses(candles)
 	rsi := rsiSeries(closes, 14)
@@ -227,6 +281,44 @@ func drawVolumeZ(img *image.RGBA, rect image.Rectangle, candles []domain.Candle)
 	drawBars(img, rect, zscores, minV, maxV, colVolume)
 }
 
+// drawIchimoku draws the cloud under the close, Tenkan and Kijun lines for
+// the last visible candles.
+func drawIchimoku(img *image.RGBA, rect image.Rectangle, candles []domain.Candle, visible int, p domain.IchimokuPeriods) {
+	tenkan, kijun, spanA, spanB := ta.IchimokuSeries(extractHighs(candles), extractLows(candles), p.Tenkan, p.Kijun, p.SenkouB)
+	from := len(candles) - visible
+	closes := extractCloses(candles)[from:]
+	tenkan, kijun, spanA, spanB = tenkan[from:], kijun[from:], spanA[from:], spanB[from:]
+
+	all := make([]float64, 0, 5*visible)
+	for _, line := range [][]float64{closes, tenkan, kijun, spanA, spanB} {
+		all = append(all, line...)
+	}
+	minV, maxV := finiteBounds(all)
+	// Fill the cloud column by column, interpolating the spans between
+	// candles, green where Senkou A is on top and red where B is.
+	for i := 0; i+1 < len(spanA); i++ {
+		if math.IsNaN(spanA[i]) || math.IsNaN(spanB[i]) || math.IsNaN(spanA[i+1]) || math.IsNaN(spanB[i+1]) {
+			continue
+		}
+		x0, x1 := mapIndexToX(i, len(spanA), rect), mapIndexToX(i+1, len(spanA), rect)
+		for x := x0; x < x1; x++ {
+			f := float64(x-x0) / float64(x1-x0)
+			a := spanA[i] + f*(spanA[i+1]-spanA[i])
+			b := spanB[i] + f*(spanB[i+1]-spanB[i])
+			col := colCloudUp
+			if a < b {
+				col = colCloudDown
+			}
+			drawLine(img, x, mapValueToY(a, minV, maxV, rect), x, mapValueToY(b, minV, maxV, rect), col)
+		}
+	}
+	drawSeries(img, rect, spanA, minV, maxV, colBull)
+	drawSeries(img, rect, spanB, minV, maxV, colBear)
+	drawSeries(img, rect, closes, minV, maxV, colWick)
+	drawSeries(img, rect, tenkan, minV, maxV, colLineA)
+	drawSeries(img, rect, kijun, minV, maxV, colLineB)
+}
+
 func drawPriceDeltaBars(img *image.RGBA, rect image.Rectangle, candles []domain.Candle) {
 	if len(candles) < 2 {
 		return
@@ -338,6 +430,22 @@ func extractCloses(candles []domain.Candle) []float64 {
 	return out
 }
 
+func extractHighs(candles []domain.Candle) []float64 {
+	values := make([]float64, len(candles))
+	for i := range candles {
+		values[i] = candles[i].High
+	}
+	return values
+}
+
+func extractLows(candles []domain.Candle) []float64 {
+	values := make([]float64, len(candles))
+	for i := range candles {
+		values[i] = candles[i].Low
+	}
+	return values
+}
+
 func extractVolumes(candles []domain.Candle) []float64 {
 	out := make([]float64, len(candles))
 	for i := range candles {
@@ -0,0 +1,37 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/loadtest"
+	"bug-free-umbrella/pkg/domain"
+)
+
+// renderBudget is the per-image ceiling for the signal image jobs, which
+// render synchronously after each signal batch.
+const renderBudget = 150 * time.Millisecond
+
+func BenchmarkRenderSignalChart(b *testing.B) {
+	renderer := NewRenderer()
+	candles := buildTestCandles(160)
+	signal := domain.Signal{
+		Symbol:    "BTC",
+		Interval:  "1h",
+		Indicator: domain.IndicatorBollinger,
+		Direction: domain.DirectionLong,
+		Timestamp: time.Now().UTC(),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := renderer.RenderSignalChart(candles, signal); err != nil {
+			b.Fatalf("render: %v", err)
+		}
+	}
+}
+
+func TestRenderSignalChartPerformanceBudget(t *testing.T) {
+	loadtest.EnforceBudget(t, renderBudget, BenchmarkRenderSignalChart)
+}
@@ -1,31 +1,33 @@
 package chart
 
 import (
+	"bytes"
+	"image/color"
+	"image/png"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestRenderSignalChartByIndicator(t *testing.T) {
 	renderer := NewRenderer()
 	candles := buildTestCandles(160)
-	indicators := []string{
-		domain.IndicatorRSI,
-		domain.IndicatorMACD,
-		domain.IndicatorBollinger,
-		domain.IndicatorVolumeZ,
-	}
-
-	for _, indicator := range indicators {
-		t.Run(indicator, func(t *testing.T) {
+	for _, info := range domain.Indicators {
+		t.Run(info.Key, func(t *testing.T) {
 			image, err := renderer.RenderSignalChart(candles, domain.Signal{
 				Symbol:    "BTC",
 				Interval:  "1h",
-				Indicator: indicator,
+				Indicator: info.Key,
 				Direction: domain.DirectionLong,
 				Timestamp: time.Now().UTC(),
 			})
+			if !info.Charts {
+				if err == nil {
+					t.Fatal("expected render to fail for an indicator without chart support")
+				}
+				return
+			}
 			if err != nil {
 				t.Fatalf("render failed: %v", err)
 			}
@@ -81,3 +83,78 @@ func maxFloat(a, b float64) float64 {
 	}
 	return b
 }
+
+func TestRenderSignalChartLabelsPriceAxis(t *testing.T) {
+	candles := buildTestCandles(40)
+	for _, c := range candles {
+		c.Symbol = "DOGE"
+		c.Open, c.High, c.Low, c.Close = c.Open/3e5, c.High/3e5, c.Low/3e5, c.Close/3e5
+	}
+	data, err := NewRenderer().RenderSignalChart(candles, domain.Signal{Symbol: "DOGE", Indicator: domain.IndicatorRSI})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data.Bytes))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	inked := 0
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := 0; x < 68; x++ {
+			if img.At(x, y) == color.Color(colWick) {
+				inked++
+			}
+		}
+	}
+	if inked == 0 {
+		t.Fatal("expected price labels in the left margin")
+	}
+
+	for _, label := range []string{domain.FormatPriceValue("BTC", 123456.78), domain.FormatPriceValue("DOGE", -0.16234)} {
+		for _, ch := range label {
+			if _, ok := glyphs[ch]; !ok {
+				t.Fatalf("no glyph for %q in %s", ch, label)
+			}
+		}
+		if w := textWidth(label); w > 68 {
+			t.Fatalf("label %s is %dpx, wider than the margin", label, w)
+		}
+	}
+}
+
+func TestRenderIchimokuCloudUsesHiddenHistory(t *testing.T) {
+	cloudPixels := func(count int) int {
+		t.Helper()
+		// A steady uptrend opens the cloud; the test candles alone repeat
+		// every nine candles, which leaves it flat.
+		candles := buildTestCandles(count)
+		for i, c := range candles {
+			drift := 40 * float64(i)
+			c.Open, c.High, c.Low, c.Close = c.Open+drift, c.High+drift, c.Low+drift, c.Close+drift
+		}
+		data, err := NewRenderer().RenderSignalChart(candles, domain.Signal{Symbol: "BTC", Indicator: domain.IndicatorIchimoku})
+		if err != nil {
+			t.Fatalf("render failed: %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data.Bytes))
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		// The first candles of the aux panel.
+		n := 0
+		for y := 476; y < 610; y++ {
+			for x := 72; x < 100; x++ {
+				if c := img.At(x, y); c == color.Color(colCloudUp) || c == color.Color(colCloudDown) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	if n := cloudPixels(maxChartCandles); n != 0 {
+		t.Fatalf("expected no cloud before 78 candles of history, got %d pixels", n)
+	}
+	if n := cloudPixels(maxChartCandles + 80); n == 0 {
+		t.Fatal("expected the cloud from the first visible candle when older candles are given")
+	}
+}
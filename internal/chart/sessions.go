@@ -0,0 +1,65 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+var (
+	colWeekend    = color.RGBA{R: 236, G: 239, B: 244, A: 255}
+	sessionColors = map[string]color.RGBA{
+		domain.SessionAsia: {R: 196, G: 120, B: 40, A: 255},
+		domain.SessionEU:   {R: 92, G: 84, B: 196, A: 255},
+		domain.SessionUS:   {R: 36, G: 128, B: 70, A: 255},
+	}
+)
+
+// drawSessions shades weekend candles across every pane and, for intraday
+// candles, marks where each trading session opens with a dashed line in the
+// price pane, labelled with the session's initial. It draws before the grid
+// and candles so they stay on top.
+func drawSessions(img *image.RGBA, mainRect image.Rectangle, panes []image.Rectangle, candles []domain.Candle) {
+	if len(candles) == 0 {
+		return
+	}
+	step := domain.IntervalDuration(candles[0].Interval)
+	if step == 0 && len(candles) > 1 {
+		step = candles[1].OpenTime.Sub(candles[0].OpenTime)
+	}
+	half := max(1, mainRect.Dx()/max(1, 2*(len(candles)-1)))
+
+	var prev map[string]bool
+	for i, c := range candles {
+		active := make(map[string]bool, len(domain.TradingSessions)+1)
+		for _, name := range domain.CandleSessions(c.OpenTime, step) {
+			active[name] = true
+		}
+		x := mapIndexToX(i, len(candles), mainRect)
+		if active[domain.SessionWeekend] {
+			for _, pane := range panes {
+				fillRect(img, image.Rect(x-half, pane.Min.Y, x+half+1, pane.Max.Y).Intersect(pane), colWeekend)
+			}
+		}
+		if step < 24*time.Hour && prev != nil {
+			left := x - half
+			for _, s := range domain.TradingSessions {
+				if active[s.Name] && !prev[s.Name] {
+					col := sessionColors[s.Name]
+					drawDashedVLine(img, left, mainRect.Min.Y, mainRect.Max.Y, col)
+					drawText(img, left+3, mainRect.Min.Y+3, strings.ToUpper(s.Name[:1]), col)
+				}
+			}
+		}
+		prev = active
+	}
+}
+
+func drawDashedVLine(img *image.RGBA, x, y0, y1 int, col color.RGBA) {
+	for y := y0; y < y1; y += 6 {
+		drawLine(img, x, y, x, min(y+3, y1), col)
+	}
+}
@@ -0,0 +1,126 @@
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func renderSessionChart(t *testing.T, interval string, start time.Time, count int) image.Image {
+	t.Helper()
+	step := domain.IntervalDuration(interval)
+	candles := buildTestCandles(count)
+	for i, c := range candles {
+		c.Interval = interval
+		c.OpenTime = start.Add(time.Duration(i) * step)
+	}
+	data, err := NewRenderer().RenderSignalChart(candles, domain.Signal{Symbol: "BTC", Interval: interval, Indicator: domain.IndicatorRSI})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data.Bytes))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return img
+}
+
+func countColor(img image.Image, rect image.Rectangle, col color.RGBA) int {
+	n := 0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if img.At(x, y) == color.Color(col) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestRenderSignalChartMarksSessions(t *testing.T) {
+	// 96 hourly candles from Friday 00:00 UTC cover a weekday, the weekend
+	// and Monday.
+	friday := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	img := renderSessionChart(t, "1h", friday, 96)
+
+	mainRect := image.Rect(72, 20, defaultChartWidth-20, (defaultChartHeight*72)/100)
+	auxRect := image.Rect(72, mainRect.Max.Y+16, defaultChartWidth-20, defaultChartHeight-30)
+	saturdayNoon := mapIndexToX(36, 96, mainRect)
+	if img.At(saturdayNoon, mainRect.Max.Y-2) != color.Color(colWeekend) || img.At(saturdayNoon, auxRect.Min.Y+1) != color.Color(colWeekend) {
+		t.Fatal("expected weekend candles shaded in both panes")
+	}
+	if fridayNoon := mapIndexToX(12, 96, mainRect); img.At(fridayNoon, mainRect.Max.Y-2) == color.Color(colWeekend) {
+		t.Fatal("expected weekday candles unshaded")
+	}
+	for name, col := range sessionColors {
+		if countColor(img, mainRect, col) == 0 {
+			t.Fatalf("expected %s session markers", name)
+		}
+	}
+}
+
+func TestRenderSignalChartDailyOnlyShadesWeekends(t *testing.T) {
+	img := renderSessionChart(t, "1d", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 60)
+	if countColor(img, img.Bounds(), colWeekend) == 0 {
+		t.Fatal("expected weekend shading on a daily chart")
+	}
+	for name, col := range sessionColors {
+		if countColor(img, img.Bounds(), col) != 0 {
+			t.Fatalf("expected no %s session markers on a daily chart", name)
+		}
+	}
+}
+
+type eventSourceStub []domain.MarketEvent
+
+func (s eventSourceStub) EventsBetween(from, to time.Time) []domain.MarketEvent {
+	var out []domain.MarketEvent
+	for _, e := range s {
+		if !e.StartsAt.Before(from) && !e.StartsAt.After(to) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestRenderSignalChartMarksMarketEvents(t *testing.T) {
+	start := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+	candles := buildTestCandles(48)
+	for i, c := range candles {
+		c.Interval = "1h"
+		c.OpenTime = start.Add(time.Duration(i) * time.Hour)
+	}
+	r := NewRenderer()
+	r.SetEventSource(eventSourceStub{
+		{Kind: domain.MarketEventCPI, StartsAt: start.Add(12*time.Hour + 30*time.Minute), Importance: domain.EventImportanceHigh},
+		{Kind: domain.MarketEventFOMC, StartsAt: start.Add(66 * time.Hour), Importance: domain.EventImportanceHigh},
+		{Kind: domain.MarketEventUnlock, Symbol: "SOL", StartsAt: start.Add(20 * time.Hour), Importance: domain.EventImportanceMedium},
+	})
+	data, err := r.RenderSignalChart(candles, domain.Signal{Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorRSI})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data.Bytes))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	mainRect := image.Rect(72, 20, defaultChartWidth-20, (defaultChartHeight*72)/100)
+	cpiX := mapIndexToX(12, 48, mainRect)
+	if countColor(img, image.Rect(cpiX, mainRect.Min.Y, cpiX+1, mainRect.Max.Y), colEvent) == 0 {
+		t.Fatal("expected the CPI print marked at its candle")
+	}
+	unlockX := mapIndexToX(20, 48, mainRect)
+	if countColor(img, image.Rect(unlockX, mainRect.Min.Y, unlockX+1, mainRect.Max.Y), colEvent) != 0 {
+		t.Fatal("expected another token's unlock left off a BTC chart")
+	}
+	corner := image.Rect(mainRect.Max.X-80, mainRect.Min.Y, mainRect.Max.X, mainRect.Min.Y+20)
+	if countColor(img, corner, colEvent) == 0 {
+		t.Fatal("expected the upcoming FOMC decision listed in the corner")
+	}
+}
@@ -0,0 +1,562 @@
+package chart
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+)
+
+// encodeWebP writes img as a lossless WebP (VP8L). Charts are flat colours
+// on a flat background, so the encoder keeps to the parts of the format
+// that pay off for them: a colour-indexing transform that packs several
+// pixels per code when the chart has at most 16 colours, and LZ77 copies
+// from the left and the row above. There is no colour cache, predictor or
+// per-tile entropy coding.
+func encodeWebP(w io.Writer, img *image.RGBA) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > 1<<14 || height > 1<<14 {
+		return errors.New("webp: image size out of range")
+	}
+
+	argb := make([]uint32, 0, width*height)
+	alpha := false
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			c := color.NRGBA{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: img.Pix[i+3]}
+			if c.A != 0xff {
+				// VP8L stores unpremultiplied colour.
+				alpha = true
+				c = color.NRGBAModel.Convert(img.RGBAAt(x, y)).(color.NRGBA)
+			}
+			argb = append(argb, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.writeBits(0x2f, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if alpha {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3)
+
+	pixels, xsize := argb, width
+	if palette, ok := webpPalette(argb); ok {
+		bw.writeBits(1, 1)
+		bw.writeBits(3, 2) // colour-indexing transform
+		bw.writeBits(uint32(len(palette)-1), 8)
+		deltas := make([]uint32, len(palette))
+		for i, c := range palette {
+			if i == 0 {
+				deltas[i] = c
+				continue
+			}
+			deltas[i] = subPixels(c, palette[i-1])
+		}
+		writeWebPImage(bw, deltas, len(deltas), false)
+		pixels, xsize = bundlePixels(argb, width, height, palette)
+	}
+	bw.writeBits(0, 1) // no further transforms
+	writeWebPImage(bw, pixels, xsize, true)
+	data := bw.flush()
+
+	pad := len(data) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+len(data)+pad))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if pad == 1 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// webpPalette returns the sorted distinct colours of argb when there are no
+// more than 256 of them.
+func webpPalette(argb []uint32) ([]uint32, bool) {
+	seen := make(map[uint32]struct{}, 32)
+	for _, c := range argb {
+		if _, ok := seen[c]; ok {
+			continue
+		}
+		if len(seen) == 256 {
+			return nil, false
+		}
+		seen[c] = struct{}{}
+	}
+	palette := make([]uint32, 0, len(seen))
+	for c := range seen {
+		palette = append(palette, c)
+	}
+	sort.Slice(palette, func(i, j int) bool { return palette[i] < palette[j] })
+	return palette, true
+}
+
+// bundlePixels replaces each pixel with its palette index, packing 2, 4 or
+// 8 indices into the green channel of one pixel when the palette is small
+// enough. It returns the packed pixels and the packed row width.
+func bundlePixels(argb []uint32, width, height int, palette []uint32) ([]uint32, int) {
+	index := make(map[uint32]uint32, len(palette))
+	for i, c := range palette {
+		index[c] = uint32(i)
+	}
+	widthBits := 0
+	switch {
+	case len(palette) <= 2:
+		widthBits = 3
+	case len(palette) <= 4:
+		widthBits = 2
+	case len(palette) <= 16:
+		widthBits = 1
+	}
+	bitsPerPixel := uint(8 >> widthBits)
+	mask := 1<<widthBits - 1
+	xsize := (width + mask) >> widthBits
+
+	out := make([]uint32, xsize*height)
+	for y := 0; y < height; y++ {
+		row := out[y*xsize : (y+1)*xsize]
+		for x := 0; x < width; x++ {
+			idx := index[argb[y*width+x]]
+			row[x>>widthBits] |= idx << (8 + bitsPerPixel*uint(x&mask))
+		}
+		for i := range row {
+			row[i] |= 0xff000000
+		}
+	}
+	return out, xsize
+}
+
+func subPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		out |= uint32(uint8(a>>shift)-uint8(b>>shift)) << shift
+	}
+	return out
+}
+
+const (
+	webpLengthCodes  = 24
+	webpDistCodes    = 40
+	webpMaxCopy      = 4096
+	webpMaxDistance  = 1<<20 - 120
+	webpMinCopy      = 3
+	webpHashBits     = 16
+	webpChainLimit   = 16
+	webpMaxCodeLen   = 15
+	webpMaxCodeLenCL = 7
+)
+
+// webpCodeLengthOrder is the order code length code lengths are written in.
+var webpCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// webpToken is a literal pixel when length is 0, otherwise a copy of length
+// pixels from distCode.
+type webpToken struct {
+	argb     uint32
+	length   int
+	distCode int
+}
+
+// writeWebPImage entropy-codes pixels with a single set of prefix codes.
+// Only the main image carries the meta prefix code bit.
+func writeWebPImage(bw *bitWriter, pixels []uint32, xsize int, main bool) {
+	bw.writeBits(0, 1) // no colour cache
+	if main {
+		bw.writeBits(0, 1) // one prefix code group for the whole image
+	}
+
+	tokens := webpLZ77(pixels, xsize)
+	green := make([]int, 256+webpLengthCodes)
+	red := make([]int, 256)
+	blue := make([]int, 256)
+	alpha := make([]int, 256)
+	dist := make([]int, webpDistCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			alpha[t.argb>>24]++
+			red[t.argb>>16&0xff]++
+			green[t.argb>>8&0xff]++
+			blue[t.argb&0xff]++
+			continue
+		}
+		sym, _, _ := webpPrefix(t.length)
+		green[256+sym]++
+		sym, _, _ = webpPrefix(t.distCode)
+		dist[sym]++
+	}
+
+	codes := [5]prefixCode{}
+	for i, h := range [][]int{green, red, blue, alpha, dist} {
+		codes[i] = newPrefixCode(h, webpMaxCodeLen)
+		codes[i].writeHeader(bw)
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].write(bw, int(t.argb>>8&0xff))
+			codes[1].write(bw, int(t.argb>>16&0xff))
+			codes[2].write(bw, int(t.argb&0xff))
+			codes[3].write(bw, int(t.argb>>24))
+			continue
+		}
+		sym, n, extra := webpPrefix(t.length)
+		codes[0].write(bw, 256+sym)
+		bw.writeBits(extra, n)
+		sym, n, extra = webpPrefix(t.distCode)
+		codes[4].write(bw, sym)
+		bw.writeBits(extra, n)
+	}
+}
+
+// webpLZ77 greedily replaces repeated runs with copies, trying the pixel to
+// the left, the pixel above and earlier positions with the same two-pixel
+// hash.
+func webpLZ77(pixels []uint32, xsize int) []webpToken {
+	n := len(pixels)
+	head := make([]int32, 1<<webpHashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	prev := make([]int32, n)
+	hash := func(i int) uint32 {
+		return (pixels[i]*0x1e35a7bd ^ pixels[i+1]*0x9e3779b1) >> (32 - webpHashBits)
+	}
+	insert := func(i int) {
+		if i+1 >= n {
+			return
+		}
+		h := hash(i)
+		prev[i] = head[h]
+		head[h] = int32(i)
+	}
+	matchLen := func(i, j int) int {
+		limit := min(webpMaxCopy, n-i)
+		l := 0
+		for l < limit && pixels[i+l] == pixels[j+l] {
+			l++
+		}
+		return l
+	}
+
+	var tokens []webpToken
+	for i := 0; i < n; {
+		bestLen, bestDist := 0, 0
+		for _, d := range [2]int{1, xsize} {
+			if d <= i {
+				if l := matchLen(i, i-d); l > bestLen {
+					bestLen, bestDist = l, d
+				}
+			}
+		}
+		if i+1 < n && bestLen < webpMaxCopy {
+			for j, steps := head[hash(i)], 0; j >= 0 && steps < webpChainLimit && i-int(j) <= webpMaxDistance; j, steps = prev[j], steps+1 {
+				if l := matchLen(i, int(j)); l > bestLen {
+					bestLen, bestDist = l, i-int(j)
+				}
+			}
+		}
+		if bestLen < webpMinCopy {
+			tokens = append(tokens, webpToken{argb: pixels[i]})
+			insert(i)
+			i++
+			continue
+		}
+		tokens = append(tokens, webpToken{length: bestLen, distCode: webpDistCode(bestDist, xsize)})
+		for k := i; k < i+bestLen; k++ {
+			insert(k)
+		}
+		i += bestLen
+	}
+	return tokens
+}
+
+// webpDistCode maps a backward distance to its distance code. Codes 1 and 2
+// are the pixel above and the pixel to the left; other short codes name
+// further neighbours and are not used.
+func webpDistCode(dist, xsize int) int {
+	switch dist {
+	case xsize:
+		return 1
+	case 1:
+		return 2
+	}
+	return dist + 120
+}
+
+// webpPrefix splits a length or distance code into its prefix symbol and
+// extra bits.
+func webpPrefix(v int) (int, uint, uint32) {
+	if v <= 4 {
+		return v - 1, 0, 0
+	}
+	d := v - 1
+	high := 0
+	for d>>(high+1) != 0 {
+		high++
+	}
+	second := d >> (high - 1) & 1
+	extraBits := high - 1
+	return 2*high + second, uint(extraBits), uint32(d & (1<<extraBits - 1))
+}
+
+// prefixCode is a canonical prefix code. When only one symbol is used the
+// decoder reads no bits for it, so emit is 0 while the header still gives
+// it a length.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+	single  bool
+}
+
+func newPrefixCode(freq []int, maxLen int) prefixCode {
+	lengths := huffmanLengths(freq, maxLen)
+	pc := prefixCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+	used := 0
+	var count [16]int
+	for _, l := range lengths {
+		if l > 0 {
+			used++
+			count[l]++
+		}
+	}
+	pc.single = used == 1
+	var next [16]uint32
+	code := uint32(0)
+	for l := 1; l < 16; l++ {
+		code = (code + uint32(count[l-1])) << 1
+		next[l] = code
+	}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		pc.codes[s] = reverseBits(next[l], l)
+		next[l]++
+	}
+	return pc
+}
+
+func (pc prefixCode) write(bw *bitWriter, sym int) {
+	if pc.single {
+		return
+	}
+	bw.writeBits(pc.codes[sym], uint(pc.lengths[sym]))
+}
+
+func (pc prefixCode) writeHeader(bw *bitWriter) {
+	var used []int
+	for s, l := range pc.lengths {
+		if l > 0 {
+			used = append(used, s)
+		}
+	}
+	if len(used) == 0 || (len(used) <= 2 && used[len(used)-1] < 256) {
+		// Simple code: one or two symbols below 256, given directly.
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		bw.writeBits(1, 1)
+		bw.writeBits(uint32(len(used)-1), 1)
+		if used[0] <= 1 {
+			bw.writeBits(0, 1)
+			bw.writeBits(uint32(used[0]), 1)
+		} else {
+			bw.writeBits(1, 1)
+			bw.writeBits(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			bw.writeBits(uint32(used[1]), 8)
+		}
+		return
+	}
+
+	bw.writeBits(0, 1)
+	tokens := rleCodeLengths(pc.lengths)
+	freq := make([]int, 19)
+	for _, t := range tokens {
+		freq[t.sym]++
+	}
+	clc := newPrefixCode(freq, webpMaxCodeLenCL)
+	count := 4
+	for i, s := range webpCodeLengthOrder {
+		if clc.lengths[s] > 0 {
+			count = max(count, i+1)
+		}
+	}
+	bw.writeBits(uint32(count-4), 4)
+	for _, s := range webpCodeLengthOrder[:count] {
+		bw.writeBits(uint32(clc.lengths[s]), 3)
+	}
+	bw.writeBits(0, 1) // lengths follow for the whole alphabet
+	for _, t := range tokens {
+		clc.write(bw, t.sym)
+		switch t.sym {
+		case 16:
+			bw.writeBits(t.extra, 2)
+		case 17:
+			bw.writeBits(t.extra, 3)
+		case 18:
+			bw.writeBits(t.extra, 7)
+		}
+	}
+}
+
+type codeLengthToken struct {
+	sym   int
+	extra uint32
+}
+
+// rleCodeLengths run-length codes lengths with the code length alphabet:
+// 0-15 literally, 16 repeating the last non-zero length (initially 8) 3-6
+// times, 17 and 18 for runs of 3-10 and 11-138 zeros.
+func rleCodeLengths(lengths []uint8) []codeLengthToken {
+	var out []codeLengthToken
+	prevNonZero := uint8(8)
+	for i := 0; i < len(lengths); {
+		v := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == v {
+			run++
+		}
+		i += run
+		if v == 0 {
+			for run >= 11 {
+				n := min(run, 138)
+				out = append(out, codeLengthToken{sym: 18, extra: uint32(n - 11)})
+				run -= n
+			}
+			if run >= 3 {
+				out = append(out, codeLengthToken{sym: 17, extra: uint32(run - 3)})
+				run = 0
+			}
+			for ; run > 0; run-- {
+				out = append(out, codeLengthToken{sym: 0})
+			}
+			continue
+		}
+		if v != prevNonZero {
+			out = append(out, codeLengthToken{sym: int(v)})
+			prevNonZero = v
+			run--
+		}
+		for run >= 3 {
+			n := min(run, 6)
+			out = append(out, codeLengthToken{sym: 16, extra: uint32(n - 3)})
+			run -= n
+		}
+		for ; run > 0; run-- {
+			out = append(out, codeLengthToken{sym: int(v)})
+		}
+	}
+	return out
+}
+
+// huffmanLengths returns code lengths no longer than maxLen for freq. Rare
+// symbols are made more common until the tree is shallow enough.
+func huffmanLengths(freq []int, maxLen int) []uint8 {
+	lengths := make([]uint8, len(freq))
+	var symbols []int
+	for s, f := range freq {
+		if f > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	switch len(symbols) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[symbols[0]] = 1
+		return lengths
+	}
+
+	type node struct {
+		weight      int
+		sym         int
+		left, right int
+	}
+	for floor := 1; ; floor *= 2 {
+		nodes := make([]node, 0, 2*len(symbols))
+		for _, s := range symbols {
+			nodes = append(nodes, node{weight: max(freq[s], floor), sym: s, left: -1, right: -1})
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].weight < nodes[j].weight })
+
+		// Two-queue construction: leaves in weight order, then internal
+		// nodes, which are created in non-decreasing weight order.
+		leaf, internal := 0, len(nodes)
+		pick := func() int {
+			if leaf < len(symbols) && (internal >= len(nodes) || nodes[leaf].weight <= nodes[internal].weight) {
+				leaf++
+				return leaf - 1
+			}
+			internal++
+			return internal - 1
+		}
+		for len(nodes) < 2*len(symbols)-1 {
+			a, b := pick(), pick()
+			nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, sym: -1, left: a, right: b})
+		}
+
+		depth := make([]int, len(nodes))
+		deepest := 0
+		for i := len(nodes) - 1; i >= 0; i-- {
+			if nodes[i].sym >= 0 {
+				lengths[nodes[i].sym] = uint8(depth[i])
+				deepest = max(deepest, depth[i])
+				continue
+			}
+			depth[nodes[i].left] = depth[i] + 1
+			depth[nodes[i].right] = depth[i] + 1
+		}
+		if deepest <= maxLen {
+			return lengths
+		}
+	}
+}
+
+func reverseBits(v uint32, n uint8) uint32 {
+	var out uint32
+	for i := uint8(0); i < n; i++ {
+		out = out<<1 | v&1
+		v >>= 1
+	}
+	return out
+}
+
+// bitWriter packs bits least significant first, as VP8L reads them.
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	bits uint
+}
+
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.acc |= uint64(v) << w.bits
+	w.bits += n
+	for w.bits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.bits -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.bits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.bits = 0, 0
+	}
+	return w.buf
+}
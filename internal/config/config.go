@@ -1,11 +1,13 @@
 package config
 
 import (
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
 	"log"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,36 +15,106 @@ type Config struct {
 	DatabaseURL       string
 	RedisURL          string
 	CoinGeckoPollSecs int
+	// CoinGeckoPollMinSecs is the fastest the current-price poller may run
+	// during high volatility.
+	CoinGeckoPollMinSecs int
+	// CoinGeckoQuotaPerMin and CoinGeckoQuotaPerDay are the CoinGecko
+	// plan's call quota, tracked in /api/admin/providers. Pollers stretch
+	// their intervals as the daily quota runs low; zero means no daily cap.
+	CoinGeckoQuotaPerMin int
+	CoinGeckoQuotaPerDay int
+	// SignalPollConcurrency bounds how many symbols the signal poller
+	// generates in parallel.
+	SignalPollConcurrency int
+	// CandleEventsEnabled drives signal generation and ML feature/inference
+	// from candle-closed events instead of their own timers.
+	CandleEventsEnabled bool
+	// SignalRiskOverrides replace the signal engine's default risk for an
+	// indicator, on one interval or on all of them.
+	SignalRiskOverrides []domain.RiskOverride
+	// IchimokuPeriods are the Tenkan, Kijun and Senkou B periods of the
+	// Ichimoku signals and charts.
+	IchimokuPeriods domain.IchimokuPeriods
+	// SymbolPauses pause signal generation, ML inference or alerts for a
+	// symbol on top of the pauses stored in the symbol registry.
+	SymbolPauses map[string]domain.AssetPauses
+	// SignalImage* tune how signal chart images are kept and re-rendered.
+	// Failed renders are retried every SignalImageRetryDelaySecs, up to
+	// SignalImageMaxRetries times, SignalImageRetryBatch signals per pass.
+	// Expired images are deleted SignalImageCleanupBatch rows at a time.
+	SignalImageTTLHours       int
+	SignalImageRetryDelaySecs int
+	SignalImageMaxRetries     int
+	SignalImageRetryBatch     int
+	SignalImageCleanupBatch   int
+	// ChartImageFormat is how signal charts are encoded: png, png-palette
+	// or webp.
+	ChartImageFormat string
+	// AlertTemplate is the Go template for one signal's alert line, used
+	// for Telegram alerts and as {{.Text}} in webhook payloads.
+	// AlertTemplateFile, when set, holds the template instead, and
+	// AlertMessagesFile is a JSON object of localized messages.
+	AlertTemplate     string
+	AlertTemplateFile string
+	AlertMessagesFile string
+
+	// TelegramChatCommandsPerMin is how many messages one chat may send the
+	// bot per minute; 0 disables the limit.
+	TelegramChatCommandsPerMin int
+	// TelegramSendsPerSec caps the bot's outgoing messages across chats.
+	TelegramSendsPerSec int
 
 	MCPTransport          string
 	MCPHTTPEnabled        bool
 	MCPHTTPBind           string
 	MCPHTTPPort           int
 	MCPAuthToken          string
+	MCPAdminToken         string
 	MCPRequestTimeoutSecs int
 	MCPRateLimitPerMin    int
 
 	OpenAIAPIKey      string
 	OpenAIModel       string
 	AdvisorMaxHistory int
+	// AdvisorCacheTTLSecs is how long an advisor answer is reused for the same
+	// question against unchanged candles; 0 disables the cache.
+	AdvisorCacheTTLSecs int
+	// AdvisorGuardrails is the reply content policy: off, rewrite or block.
+	AdvisorGuardrails        string
+	AdvisorPriceTolerancePct float64
+	// AdvisorConversationIdleHours deletes conversations with no message for
+	// that long; 0 keeps them forever.
+	AdvisorConversationIdleHours int
 
 	MLEnabled         bool
 	MLInterval        string
 	MLIntervals       []string
 	MLTargetHours     int
 	MLTrainWindowDays int
-	MLInferPollSecs   int
-	MLResolvePollSecs int
-	MLTrainHourUTC    int
-	MLLongThreshold   float64
-	MLShortThreshold  float64
-	MLMinTrainSamples int
+	// MLTrainExcludeFlagged drops candles whose label an operator overrode
+	// with exclude_from_training from directional training.
+	MLTrainExcludeFlagged bool
+	MLInferPollSecs       int
+	MLResolvePollSecs     int
+	MLTrainHourUTC        int
+	MLLongThreshold       float64
+	MLShortThreshold      float64
+	MLMinTrainSamples     int
+	// MLPinnedModels maps model keys to the registry version inference uses
+	// regardless of which version is active.
+	MLPinnedModels map[string]int
+	// MLRollingModels maps model keys to how many of their most recently
+	// promoted versions inference averages instead of scoring with one.
+	MLRollingModels map[string]int
 
 	MLEnableIForest  bool
 	MLAnomalyThresh  float64
 	MLAnomalyDampMax float64
-	MLIForestTrees   int
-	MLIForestSample  int
+	// MLUncertaintyBandWeight widens the ensemble hold band by this much per
+	// unit of disagreement between the models behind a call.
+	MLUncertaintyBandWeight float64
+	MLIForestTrees          int
+	MLIForestSample         int
 
 	MarketIntelEnabled          bool
 	MarketIntelIntervals        []string
@@ -64,167 +136,596 @@ type Config struct {
 	OnChainADAKoiosBaseURL      string
 	OnChainXRPAPIBaseURL        string
 
+	// MarketEventsBuiltin puts the built-in FOMC and CPI schedule on the
+	// market event calendar, and MarketEventsFile adds the events in a JSON
+	// file. Charts and /signals show upcoming events; the market intel job
+	// stores them for the event proximity feature.
+	MarketEventsBuiltin bool
+	MarketEventsFile    string
+
 	SSHEnabled     bool
 	SSHPort        int
 	SSHHostKeyPath string
 	SSHIdleTimeout int
+	// SSHOperators lists SSH usernames allowed to toggle maintenance mode
+	// from the TUI.
+	SSHOperators []string
+	// TUITheme names the SSH TUI color theme; NoColor reflects the NO_COLOR
+	// convention and forces the no-color theme.
+	TUITheme string
+	NoColor  bool
 
 	RESTAPIKey         string
+	RESTAPITenantKeys  map[string]string
 	CORSAllowedOrigins []string
+	// IdempotencyTTLSecs is how long responses to keyed POST requests are
+	// replayed to retries.
+	IdempotencyTTLSecs int
+	// HTTPRequestTimeoutSecs bounds each REST request's context;
+	// HTTPLongRequestTimeoutSecs applies instead to the operator routes that
+	// run a cycle of work inline, such as training.
+	HTTPRequestTimeoutSecs     int
+	HTTPLongRequestTimeoutSecs int
+
+	// StartupConnectTimeoutSecs is how long startup retries Postgres and
+	// Redis before continuing without them.
+	StartupConnectTimeoutSecs int
+
+	// MaintenanceWindows are announced in the schedule calendar feed.
+	MaintenanceWindows []domain.MaintenanceWindow
+
+	// StreamBackend exports new signals and predictions to "nats" or
+	// "kafka"; empty disables the export. StreamURL is the NATS server, or
+	// for Kafka the REST Proxy base URL.
+	StreamBackend            string
+	StreamURL                string
+	StreamSignalsSubject     string
+	StreamPredictionsSubject string
+
+	// WorkQueueEnabled runs chart renders and Telegram alerts through a
+	// Redis stream so work in flight survives a restart. The stream is
+	// WorkQueueStream; tasks pending longer than WorkQueueClaimAfterSecs are
+	// claimed by a live consumer, and dead-lettered after
+	// WorkQueueMaxDeliveries deliveries.
+	WorkQueueEnabled        bool
+	WorkQueueStream         string
+	WorkQueueClaimAfterSecs int
+	WorkQueueMaxDeliveries  int
 
 	WebConsoleEnabled        bool
 	WebConsoleCookieSecret   string
 	WebConsoleSessionTTLSecs int
 	WebConsoleHeartbeatSecs  int
 	WebConsoleStaticDir      string
+
+	DemoMode    bool
+	DemoSeed    int64
+	DemoDataDir string
+
+	StorageBackend string
+	SQLitePath     string
+
+	// PriceProvider is where prices and candles come from: "coingecko",
+	// "binance", "kraken" or "coinbase".
+	PriceProvider string
+
+	// CoinGeckoAPIKey switches the coingecko provider to the Pro API.
+	CoinGeckoAPIKey string
+
+	// CoinbaseAPIKeyName and CoinbaseAPIPrivateKey are an optional CDP API
+	// key for the coinbase provider; without one it uses public endpoints.
+	CoinbaseAPIKeyName    string
+	CoinbaseAPIPrivateKey string
+
+	// PriceStreamEnabled pushes Binance WebSocket ticks into the price cache.
+	// The poller's current-price tier stands down while ticks arrive.
+	PriceStreamEnabled bool
+	// PriceStreamURL overrides the Binance combined-stream endpoint.
+	PriceStreamURL string
+
+	// OrderBookEnabled stores a Binance order book snapshot per symbol every
+	// OrderBookPollSecs and feeds hourly averages to the ML features.
+	OrderBookEnabled  bool
+	OrderBookPollSecs int
+	// OrderBookDepthBandPct is how far from the mid depth is summed.
+	OrderBookDepthBandPct float64
+	// OrderBookRetentionDays is how long snapshots are kept.
+	OrderBookRetentionDays int
+
+	// DerivativesEnabled stores Binance perpetual funding rates and open
+	// interest per symbol every DerivativesPollSecs for the ML features.
+	DerivativesEnabled  bool
+	DerivativesPollSecs int
+	// DerivativesRetentionDays is how long snapshots are kept.
+	DerivativesRetentionDays int
+
+	// GlobalMarketEnabled stores CoinGecko's total market cap and BTC
+	// dominance every GlobalMarketPollSecs for the advisor, the SSH
+	// dashboard and the ML features.
+	GlobalMarketEnabled  bool
+	GlobalMarketPollSecs int
+	// GlobalMarketRetentionDays is how long snapshots are kept.
+	GlobalMarketRetentionDays int
+
+	// DexSpreadEnabled compares on-chain DEX prices with the exchange price
+	// every DexSpreadPollSecs and emits a cex_dex_spread signal when they
+	// differ by at least DexSpreadThresholdPct percent.
+	DexSpreadEnabled      bool
+	DexSpreadPollSecs     int
+	DexSpreadThresholdPct float64
+	// DexSpreadRetentionDays is how long comparisons are kept.
+	DexSpreadRetentionDays int
+	// DexEthRPCURL is the Ethereum JSON-RPC endpoint Uniswap pools are read
+	// from.
+	DexEthRPCURL string
+
+	// CandleIntegrityEnabled re-fetches CandleIntegritySampleSize random
+	// stored candles per symbol and interval from the price provider every
+	// CandleIntegrityPollSecs and compares them. Short-tier candles are
+	// sampled from the last day, long-tier ones from the last
+	// CandleIntegrityLookbackDays.
+	CandleIntegrityEnabled      bool
+	CandleIntegrityPollSecs     int
+	CandleIntegritySampleSize   int
+	CandleIntegrityLookbackDays int
+	// CandleIntegrityTolerancePct is the largest OHLC difference, in percent,
+	// that still counts as a match.
+	CandleIntegrityTolerancePct float64
+	// CandleIntegrityAlertPct is the share of compared candles, in percent,
+	// that must differ in one run to alert operators.
+	CandleIntegrityAlertPct float64
+	// CandleIntegrityRetentionDays is how long check results are kept.
+	CandleIntegrityRetentionDays int
+
+	// PriceQuorumProviders are the providers whose current prices are
+	// fetched alongside PriceProvider's and cross-checked against them.
+	// PriceQuorumDeviationPct is how far, in percent, a price may stray from
+	// the quorum before it is recorded; a PriceProvider price outvoted by
+	// two or more others is replaced. Discrepancies are kept for
+	// PriceQuorumRetentionDays.
+	PriceQuorumProviders     []string
+	PriceQuorumDeviationPct  float64
+	PriceQuorumRetentionDays int
+
+	// OutboundHTTP is the proxy and TLS setup for calls to third-party APIs.
+	OutboundHTTP httpclient.Config
 }
 
 func Load() *Config {
+	return load(os.Getenv, log.Printf)
+}
+
+// load builds the config from getenv, reporting ignored or missing values
+// through warnf. Effective calls it with an empty environment to find the
+// defaults.
+func load(getenv func(string) string, warnf func(string, ...any)) *Config {
 	cfg := &Config{
-		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
-		DatabaseURL:      os.Getenv("DATABASE_URL"),
-		RedisURL:         os.Getenv("REDIS_URL"),
-		MCPAuthToken:     os.Getenv("MCP_AUTH_TOKEN"),
+		TelegramBotToken: getenv("TELEGRAM_BOT_TOKEN"),
+		DatabaseURL:      getenv("DATABASE_URL"),
+		RedisURL:         getenv("REDIS_URL"),
+		MCPAuthToken:     getenv("MCP_AUTH_TOKEN"),
+		MCPAdminToken:    getenv("MCP_ADMIN_TOKEN"),
 	}
 
 	if cfg.TelegramBotToken == "" {
-		log.Println("Warning: TELEGRAM_BOT_TOKEN not set")
+		warnf("Warning: TELEGRAM_BOT_TOKEN not set")
 	}
 	if cfg.DatabaseURL == "" {
-		log.Println("Warning: DATABASE_URL not set")
+		warnf("Warning: DATABASE_URL not set")
 	}
 	if cfg.RedisURL == "" {
-		log.Println("Warning: REDIS_URL not set, defaulting to localhost:6379")
+		warnf("Warning: REDIS_URL not set, defaulting to localhost:6379")
 		cfg.RedisURL = "localhost:6379"
 	}
 
+	cfg.StartupConnectTimeoutSecs = 60
+	if v := strings.TrimSpace(getenv("STARTUP_CONNECT_TIMEOUT_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.StartupConnectTimeoutSecs = n
+		}
+	}
+
+	cfg.PriceProvider = strings.ToLower(strings.TrimSpace(getenv("PRICE_PROVIDER")))
+	if cfg.PriceProvider == "" {
+		cfg.PriceProvider = "coingecko"
+	}
+	switch cfg.PriceProvider {
+	case "coingecko", "binance", "kraken", "coinbase":
+	default:
+		warnf("Warning: unsupported PRICE_PROVIDER=%q, defaulting to coingecko", cfg.PriceProvider)
+		cfg.PriceProvider = "coingecko"
+	}
+	cfg.CoinGeckoAPIKey = strings.TrimSpace(getenv("COINGECKO_API_KEY"))
+	cfg.CoinbaseAPIKeyName = strings.TrimSpace(getenv("COINBASE_API_KEY_NAME"))
+	cfg.CoinbaseAPIPrivateKey = strings.TrimSpace(getenv("COINBASE_API_PRIVATE_KEY"))
+	cfg.PriceStreamEnabled = strings.EqualFold(strings.TrimSpace(getenv("PRICE_STREAM_ENABLED")), "true")
+	cfg.PriceStreamURL = strings.TrimSpace(getenv("PRICE_STREAM_URL"))
+	cfg.OrderBookEnabled = strings.EqualFold(strings.TrimSpace(getenv("ORDERBOOK_ENABLED")), "true")
+	cfg.OrderBookPollSecs = 60
+	if v := strings.TrimSpace(getenv("ORDERBOOK_POLL_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.OrderBookPollSecs = n
+		}
+	}
+	cfg.OrderBookDepthBandPct = 1
+	if v := strings.TrimSpace(getenv("ORDERBOOK_DEPTH_BAND_PCT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 && n <= 10 {
+			cfg.OrderBookDepthBandPct = n
+		}
+	}
+	cfg.OrderBookRetentionDays = 30
+	if v := strings.TrimSpace(getenv("ORDERBOOK_RETENTION_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.OrderBookRetentionDays = n
+		}
+	}
+	cfg.DerivativesEnabled = strings.EqualFold(strings.TrimSpace(getenv("DERIVATIVES_ENABLED")), "true")
+	cfg.DerivativesPollSecs = 300
+	if v := strings.TrimSpace(getenv("DERIVATIVES_POLL_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.DerivativesPollSecs = n
+		}
+	}
+	cfg.DerivativesRetentionDays = 90
+	if v := strings.TrimSpace(getenv("DERIVATIVES_RETENTION_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.DerivativesRetentionDays = n
+		}
+	}
+	cfg.GlobalMarketEnabled = strings.EqualFold(strings.TrimSpace(getenv("GLOBAL_MARKET_ENABLED")), "true")
+	cfg.GlobalMarketPollSecs = 600
+	if v := strings.TrimSpace(getenv("GLOBAL_MARKET_POLL_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.GlobalMarketPollSecs = n
+		}
+	}
+	cfg.GlobalMarketRetentionDays = 90
+	if v := strings.TrimSpace(getenv("GLOBAL_MARKET_RETENTION_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.GlobalMarketRetentionDays = n
+		}
+	}
+	cfg.DexSpreadEnabled = strings.EqualFold(strings.TrimSpace(getenv("DEX_SPREAD_ENABLED")), "true")
+	cfg.DexSpreadPollSecs = 300
+	if v := strings.TrimSpace(getenv("DEX_SPREAD_POLL_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.DexSpreadPollSecs = n
+		}
+	}
+	cfg.DexSpreadThresholdPct = 1
+	if v := strings.TrimSpace(getenv("DEX_SPREAD_THRESHOLD_PCT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 {
+			cfg.DexSpreadThresholdPct = n
+		}
+	}
+	cfg.DexSpreadRetentionDays = 30
+	if v := strings.TrimSpace(getenv("DEX_SPREAD_RETENTION_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.DexSpreadRetentionDays = n
+		}
+	}
+	cfg.DexEthRPCURL = strings.TrimSpace(getenv("DEX_ETH_RPC_URL"))
+	if cfg.DexEthRPCURL == "" {
+		cfg.DexEthRPCURL = "https://ethereum-rpc.publicnode.com"
+	}
+	cfg.CandleIntegrityEnabled = strings.EqualFold(strings.TrimSpace(getenv("CANDLE_INTEGRITY_ENABLED")), "true")
+	cfg.CandleIntegrityPollSecs = 3600
+	if v := strings.TrimSpace(getenv("CANDLE_INTEGRITY_POLL_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CandleIntegrityPollSecs = n
+		}
+	}
+	cfg.CandleIntegritySampleSize = 20
+	if v := strings.TrimSpace(getenv("CANDLE_INTEGRITY_SAMPLE_SIZE")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CandleIntegritySampleSize = n
+		}
+	}
+	cfg.CandleIntegrityLookbackDays = 30
+	if v := strings.TrimSpace(getenv("CANDLE_INTEGRITY_LOOKBACK_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CandleIntegrityLookbackDays = n
+		}
+	}
+	cfg.CandleIntegrityTolerancePct = 1
+	if v := strings.TrimSpace(getenv("CANDLE_INTEGRITY_TOLERANCE_PCT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			cfg.CandleIntegrityTolerancePct = n
+		}
+	}
+	cfg.CandleIntegrityAlertPct = 10
+	if v := strings.TrimSpace(getenv("CANDLE_INTEGRITY_ALERT_PCT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 {
+			cfg.CandleIntegrityAlertPct = n
+		}
+	}
+	cfg.CandleIntegrityRetentionDays = 90
+	if v := strings.TrimSpace(getenv("CANDLE_INTEGRITY_RETENTION_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CandleIntegrityRetentionDays = n
+		}
+	}
+	cfg.PriceQuorumProviders = parseQuorumProviders(getenv("PRICE_QUORUM_PROVIDERS"), cfg.PriceProvider, warnf)
+	cfg.PriceQuorumDeviationPct = 2
+	if v := strings.TrimSpace(getenv("PRICE_QUORUM_DEVIATION_PCT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 {
+			cfg.PriceQuorumDeviationPct = n
+		}
+	}
+	cfg.PriceQuorumRetentionDays = 90
+	if v := strings.TrimSpace(getenv("PRICE_QUORUM_RETENTION_DAYS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.PriceQuorumRetentionDays = n
+		}
+	}
+
 	cfg.CoinGeckoPollSecs = 60
-	if v := os.Getenv("COINGECKO_POLL_SECS"); v != "" {
+	if v := getenv("COINGECKO_POLL_SECS"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.CoinGeckoPollSecs = n
 		}
 	}
+	cfg.CoinGeckoPollMinSecs = 15
+	if v := strings.TrimSpace(getenv("COINGECKO_POLL_MIN_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CoinGeckoPollMinSecs = n
+		}
+	}
+	// The minute quota defaults to the pace the provider keeps itself to.
+	cfg.CoinGeckoQuotaPerMin = 8
+	if cfg.CoinGeckoAPIKey != "" {
+		cfg.CoinGeckoQuotaPerMin = 250
+	}
+	if v := strings.TrimSpace(getenv("COINGECKO_QUOTA_PER_MIN")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CoinGeckoQuotaPerMin = n
+		}
+	}
+	if v := strings.TrimSpace(getenv("COINGECKO_QUOTA_PER_DAY")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.CoinGeckoQuotaPerDay = n
+		}
+	}
+	cfg.CandleEventsEnabled = true
+	if v := strings.TrimSpace(getenv("CANDLE_EVENTS_ENABLED")); strings.EqualFold(v, "false") {
+		cfg.CandleEventsEnabled = false
+	}
+	cfg.SignalRiskOverrides = parseRiskOverrides(getenv("SIGNAL_RISK_OVERRIDES"), warnf)
+	cfg.IchimokuPeriods = parseIchimokuPeriods(getenv("ICHIMOKU_PERIODS"), warnf)
+	cfg.SymbolPauses = parseSymbolPauses(getenv("SYMBOL_PAUSES"), warnf)
+	cfg.SignalPollConcurrency = 4
+	if v := strings.TrimSpace(getenv("SIGNAL_POLL_CONCURRENCY")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SignalPollConcurrency = n
+		}
+	}
+	cfg.SignalImageTTLHours = 24
+	if v := strings.TrimSpace(getenv("SIGNAL_IMAGE_TTL_HOURS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SignalImageTTLHours = n
+		}
+	}
+	cfg.SignalImageRetryDelaySecs = 300
+	if v := strings.TrimSpace(getenv("SIGNAL_IMAGE_RETRY_DELAY_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SignalImageRetryDelaySecs = n
+		}
+	}
+	cfg.SignalImageMaxRetries = 3
+	if v := strings.TrimSpace(getenv("SIGNAL_IMAGE_MAX_RETRIES")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SignalImageMaxRetries = n
+		}
+	}
+	cfg.SignalImageRetryBatch = 20
+	if v := strings.TrimSpace(getenv("SIGNAL_IMAGE_RETRY_BATCH")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SignalImageRetryBatch = n
+		}
+	}
+	cfg.SignalImageCleanupBatch = 1000
+	if v := strings.TrimSpace(getenv("SIGNAL_IMAGE_CLEANUP_BATCH")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SignalImageCleanupBatch = n
+		}
+	}
+	cfg.ChartImageFormat = "png"
+	switch v := strings.ToLower(strings.TrimSpace(getenv("CHART_IMAGE_FORMAT"))); v {
+	case "png", "png-palette", "webp":
+		cfg.ChartImageFormat = v
+	case "":
+	default:
+		warnf("Warning: unknown CHART_IMAGE_FORMAT %q, using png", v)
+	}
+	cfg.AlertTemplate = getenv("ALERT_TEMPLATE")
+	cfg.AlertTemplateFile = strings.TrimSpace(getenv("ALERT_TEMPLATE_FILE"))
+	cfg.AlertMessagesFile = strings.TrimSpace(getenv("ALERT_MESSAGES_FILE"))
+
+	cfg.StorageBackend = strings.ToLower(strings.TrimSpace(getenv("STORAGE_BACKEND")))
+	if cfg.StorageBackend == "" {
+		cfg.StorageBackend = "postgres"
+	}
+	if cfg.StorageBackend != "postgres" && cfg.StorageBackend != "sqlite" {
+		warnf("Warning: unsupported STORAGE_BACKEND=%q, defaulting to postgres", cfg.StorageBackend)
+		cfg.StorageBackend = "postgres"
+	}
+	cfg.SQLitePath = strings.TrimSpace(getenv("SQLITE_PATH"))
+	if cfg.SQLitePath == "" {
+		cfg.SQLitePath = "data/bug-free-umbrella.db"
+	}
 
-	cfg.MCPTransport = strings.ToLower(strings.TrimSpace(os.Getenv("MCP_TRANSPORT")))
+	cfg.MCPTransport = strings.ToLower(strings.TrimSpace(getenv("MCP_TRANSPORT")))
 	if cfg.MCPTransport == "" {
 		cfg.MCPTransport = "stdio"
 	}
 	if cfg.MCPTransport != "stdio" && cfg.MCPTransport != "http" {
-		log.Printf("Warning: unsupported MCP_TRANSPORT=%q, defaulting to stdio", cfg.MCPTransport)
+		warnf("Warning: unsupported MCP_TRANSPORT=%q, defaulting to stdio", cfg.MCPTransport)
 		cfg.MCPTransport = "stdio"
 	}
 
-	cfg.MCPHTTPEnabled = strings.EqualFold(strings.TrimSpace(os.Getenv("MCP_HTTP_ENABLED")), "true")
+	cfg.MCPHTTPEnabled = strings.EqualFold(strings.TrimSpace(getenv("MCP_HTTP_ENABLED")), "true")
 
-	cfg.MCPHTTPBind = strings.TrimSpace(os.Getenv("MCP_HTTP_BIND"))
+	cfg.MCPHTTPBind = strings.TrimSpace(getenv("MCP_HTTP_BIND"))
 	if cfg.MCPHTTPBind == "" {
 		cfg.MCPHTTPBind = "127.0.0.1"
 	}
 
 	cfg.MCPHTTPPort = 8090
-	if v := strings.TrimSpace(os.Getenv("MCP_HTTP_PORT")); v != "" {
+	if v := strings.TrimSpace(getenv("MCP_HTTP_PORT")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MCPHTTPPort = n
 		}
 	}
 
 	cfg.MCPRequestTimeoutSecs = 5
-	if v := strings.TrimSpace(os.Getenv("MCP_REQUEST_TIMEOUT_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("MCP_REQUEST_TIMEOUT_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MCPRequestTimeoutSecs = n
 		}
 	}
 
 	cfg.MCPRateLimitPerMin = 60
-	if v := strings.TrimSpace(os.Getenv("MCP_RATE_LIMIT_PER_MIN")); v != "" {
+	if v := strings.TrimSpace(getenv("MCP_RATE_LIMIT_PER_MIN")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MCPRateLimitPerMin = n
 		}
 	}
 
-	cfg.OpenAIAPIKey = os.Getenv("OPENAI_API_KEY")
+	cfg.OpenAIAPIKey = getenv("OPENAI_API_KEY")
 	if cfg.OpenAIAPIKey == "" {
-		log.Println("Warning: OPENAI_API_KEY not set, advisor will be disabled")
+		warnf("Warning: OPENAI_API_KEY not set, advisor will be disabled")
 	}
 
-	cfg.OpenAIModel = strings.TrimSpace(os.Getenv("OPENAI_MODEL"))
+	cfg.OpenAIModel = strings.TrimSpace(getenv("OPENAI_MODEL"))
 	if cfg.OpenAIModel == "" {
 		cfg.OpenAIModel = "gpt-4o-mini"
 	}
 
+	cfg.TelegramChatCommandsPerMin = 20
+	if v := getenv("TELEGRAM_CHAT_COMMANDS_PER_MIN"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.TelegramChatCommandsPerMin = n
+		}
+	}
+
+	cfg.TelegramSendsPerSec = 25
+	if v := getenv("TELEGRAM_SENDS_PER_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 30 {
+			cfg.TelegramSendsPerSec = n
+		}
+	}
+
 	cfg.AdvisorMaxHistory = 20
-	if v := os.Getenv("ADVISOR_MAX_HISTORY"); v != "" {
+	if v := getenv("ADVISOR_MAX_HISTORY"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.AdvisorMaxHistory = n
 		}
 	}
 
-	cfg.MLEnabled = strings.EqualFold(strings.TrimSpace(os.Getenv("ML_ENABLED")), "true")
+	cfg.AdvisorCacheTTLSecs = 300
+	if v := getenv("ADVISOR_CACHE_TTL_SECS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.AdvisorCacheTTLSecs = n
+		}
+	}
+
+	cfg.AdvisorGuardrails = "rewrite"
+	switch v := strings.ToLower(strings.TrimSpace(getenv("ADVISOR_GUARDRAILS"))); v {
+	case "off", "rewrite", "block":
+		cfg.AdvisorGuardrails = v
+	case "":
+	default:
+		warnf("Warning: unknown ADVISOR_GUARDRAILS %q, using rewrite", v)
+	}
 
-	cfg.MLInterval = strings.TrimSpace(os.Getenv("ML_INTERVAL"))
+	cfg.AdvisorPriceTolerancePct = 5
+	if v := strings.TrimSpace(getenv("ADVISOR_PRICE_TOLERANCE_PCT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 {
+			cfg.AdvisorPriceTolerancePct = n
+		}
+	}
+
+	cfg.AdvisorConversationIdleHours = 168
+	if v := getenv("ADVISOR_CONVERSATION_IDLE_HOURS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.AdvisorConversationIdleHours = n
+		}
+	}
+
+	cfg.MLEnabled = strings.EqualFold(strings.TrimSpace(getenv("ML_ENABLED")), "true")
+
+	cfg.MLInterval = strings.TrimSpace(getenv("ML_INTERVAL"))
 	if cfg.MLInterval == "" {
 		cfg.MLInterval = "1h"
 	}
-	cfg.MLIntervals = parseMLIntervals(strings.TrimSpace(os.Getenv("ML_INTERVALS")), cfg.MLInterval)
+	cfg.MLIntervals = parseMLIntervals(strings.TrimSpace(getenv("ML_INTERVALS")), cfg.MLInterval)
 
 	cfg.MLTargetHours = 4
-	if v := strings.TrimSpace(os.Getenv("ML_TARGET_HOURS")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_TARGET_HOURS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLTargetHours = n
 		}
 	}
 
 	cfg.MLTrainWindowDays = 90
-	if v := strings.TrimSpace(os.Getenv("ML_TRAIN_WINDOW_DAYS")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_TRAIN_WINDOW_DAYS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLTrainWindowDays = n
 		}
 	}
 
+	cfg.MLTrainExcludeFlagged = true
+	if v := strings.TrimSpace(getenv("ML_TRAIN_EXCLUDE_FLAGGED")); strings.EqualFold(v, "false") {
+		cfg.MLTrainExcludeFlagged = false
+	}
+
 	cfg.MLInferPollSecs = 900
-	if v := strings.TrimSpace(os.Getenv("ML_INFER_POLL_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_INFER_POLL_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLInferPollSecs = n
 		}
 	}
 
 	cfg.MLResolvePollSecs = 1800
-	if v := strings.TrimSpace(os.Getenv("ML_RESOLVE_POLL_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_RESOLVE_POLL_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLResolvePollSecs = n
 		}
 	}
 
 	cfg.MLTrainHourUTC = 0
-	if v := strings.TrimSpace(os.Getenv("ML_TRAIN_HOUR_UTC")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_TRAIN_HOUR_UTC")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 23 {
 			cfg.MLTrainHourUTC = n
 		}
 	}
 
 	cfg.MLLongThreshold = 0.55
-	if v := strings.TrimSpace(os.Getenv("ML_LONG_THRESHOLD")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_LONG_THRESHOLD")); v != "" {
 		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 && n < 1 {
 			cfg.MLLongThreshold = n
 		}
 	}
 
 	cfg.MLShortThreshold = 0.45
-	if v := strings.TrimSpace(os.Getenv("ML_SHORT_THRESHOLD")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_SHORT_THRESHOLD")); v != "" {
 		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 && n < 1 {
 			cfg.MLShortThreshold = n
 		}
 	}
 
 	cfg.MLMinTrainSamples = 1000
-	if v := strings.TrimSpace(os.Getenv("ML_MIN_TRAIN_SAMPLES")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_MIN_TRAIN_SAMPLES")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLMinTrainSamples = n
 		}
 	}
 
+	cfg.MLPinnedModels = parsePinnedModels(getenv("ML_PIN_MODELS"), warnf)
+	cfg.MLRollingModels = parseRollingModels(getenv("ML_ROLLING_MODELS"), warnf)
+
 	cfg.MLEnableIForest = true
-	if v := strings.TrimSpace(os.Getenv("ML_ENABLE_IFOREST")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_ENABLE_IFOREST")); v != "" {
 		if strings.EqualFold(v, "true") {
 			cfg.MLEnableIForest = true
 		} else if strings.EqualFold(v, "false") {
@@ -233,52 +734,59 @@ func Load() *Config {
 	}
 
 	cfg.MLAnomalyThresh = 0.62
-	if v := strings.TrimSpace(os.Getenv("ML_ANOMALY_THRESHOLD")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_ANOMALY_THRESHOLD")); v != "" {
 		if n, err := strconv.ParseFloat(v, 64); err == nil && n > 0 && n < 1 {
 			cfg.MLAnomalyThresh = n
 		}
 	}
 
 	cfg.MLAnomalyDampMax = 0.65
-	if v := strings.TrimSpace(os.Getenv("ML_ANOMALY_DAMP_MAX")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_ANOMALY_DAMP_MAX")); v != "" {
 		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 && n <= 1 {
 			cfg.MLAnomalyDampMax = n
 		}
 	}
 
+	cfg.MLUncertaintyBandWeight = 0.5
+	if v := strings.TrimSpace(getenv("ML_UNCERTAINTY_BAND_WEIGHT")); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			cfg.MLUncertaintyBandWeight = n
+		}
+	}
+
 	cfg.MLIForestTrees = 200
-	if v := strings.TrimSpace(os.Getenv("ML_IFOREST_TREES")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_IFOREST_TREES")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLIForestTrees = n
 		}
 	}
 
 	cfg.MLIForestSample = 256
-	if v := strings.TrimSpace(os.Getenv("ML_IFOREST_SAMPLE_SIZE")); v != "" {
+	if v := strings.TrimSpace(getenv("ML_IFOREST_SAMPLE_SIZE")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MLIForestSample = n
 		}
 	}
 
-	cfg.MarketIntelEnabled = strings.EqualFold(strings.TrimSpace(os.Getenv("MARKET_INTEL_ENABLED")), "true")
-	cfg.MarketIntelIntervals = parseIntervalList(strings.TrimSpace(os.Getenv("MARKET_INTEL_INTERVALS")), []string{"1h", "4h"})
+	cfg.MarketIntelEnabled = strings.EqualFold(strings.TrimSpace(getenv("MARKET_INTEL_ENABLED")), "true")
+	cfg.MarketIntelIntervals = parseIntervalList(strings.TrimSpace(getenv("MARKET_INTEL_INTERVALS")), []string{"1h", "4h"})
 
 	cfg.MarketIntelPollSecs = 900
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_POLL_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_POLL_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MarketIntelPollSecs = n
 		}
 	}
 
 	cfg.MarketIntelLongThreshold = 0.20
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_LONG_THRESHOLD")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_LONG_THRESHOLD")); v != "" {
 		if n, err := strconv.ParseFloat(v, 64); err == nil && n > -1 && n < 1 {
 			cfg.MarketIntelLongThreshold = n
 		}
 	}
 
 	cfg.MarketIntelShortThreshold = -0.20
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_SHORT_THRESHOLD")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_SHORT_THRESHOLD")); v != "" {
 		if n, err := strconv.ParseFloat(v, 64); err == nil && n > -1 && n < 1 {
 			cfg.MarketIntelShortThreshold = n
 		}
@@ -289,39 +797,39 @@ func Load() *Config {
 	}
 
 	cfg.MarketIntelLookbackHours1H = 12
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_LOOKBACK_HOURS_1H")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_LOOKBACK_HOURS_1H")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MarketIntelLookbackHours1H = n
 		}
 	}
 
 	cfg.MarketIntelLookbackHours4H = 24
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_LOOKBACK_HOURS_4H")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_LOOKBACK_HOURS_4H")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MarketIntelLookbackHours4H = n
 		}
 	}
 
 	cfg.MarketIntelNewsFeeds = parseCSVWithDefault(
-		os.Getenv("MARKET_INTEL_NEWS_FEEDS"),
+		getenv("MARKET_INTEL_NEWS_FEEDS"),
 		[]string{
 			"https://www.coindesk.com/arc/outboundfeeds/rss/",
 			"https://cointelegraph.com/rss",
 		},
 	)
 	cfg.MarketIntelRedditSubs = parseCSVWithDefault(
-		os.Getenv("MARKET_INTEL_REDDIT_SUBS"),
+		getenv("MARKET_INTEL_REDDIT_SUBS"),
 		[]string{"CryptoCurrency", "Bitcoin", "Ethereum", "Cardano", "Ripple"},
 	)
 
 	cfg.MarketIntelRedditPostLimit = 40
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_REDDIT_POST_LIMIT")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_REDDIT_POST_LIMIT")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MarketIntelRedditPostLimit = n
 		}
 	}
 
-	cfg.MarketIntelScoringModel = strings.TrimSpace(os.Getenv("MARKET_INTEL_SCORING_MODEL"))
+	cfg.MarketIntelScoringModel = strings.TrimSpace(getenv("MARKET_INTEL_SCORING_MODEL"))
 	if cfg.MarketIntelScoringModel == "" {
 		cfg.MarketIntelScoringModel = cfg.OpenAIModel
 	}
@@ -330,21 +838,21 @@ func Load() *Config {
 	}
 
 	cfg.MarketIntelScoringBatchSize = 24
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_SCORING_BATCH_SIZE")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_SCORING_BATCH_SIZE")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MarketIntelScoringBatchSize = n
 		}
 	}
 
 	cfg.MarketIntelRetentionDays = 90
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_RETENTION_DAYS")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_RETENTION_DAYS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.MarketIntelRetentionDays = n
 		}
 	}
 
 	cfg.MarketIntelEnableOnChain = true
-	if v := strings.TrimSpace(os.Getenv("MARKET_INTEL_ENABLE_ONCHAIN")); v != "" {
+	if v := strings.TrimSpace(getenv("MARKET_INTEL_ENABLE_ONCHAIN")); v != "" {
 		if strings.EqualFold(v, "true") {
 			cfg.MarketIntelEnableOnChain = true
 		} else if strings.EqualFold(v, "false") {
@@ -353,54 +861,111 @@ func Load() *Config {
 	}
 
 	cfg.MarketIntelOnChainSymbols = parseSymbolListWithDefault(
-		os.Getenv("MARKET_INTEL_ONCHAIN_SYMBOLS"),
+		getenv("MARKET_INTEL_ONCHAIN_SYMBOLS"),
 		[]string{"BTC", "ETH", "ADA", "XRP"},
 	)
 
-	cfg.OnChainBTCMempoolBaseURL = strings.TrimSpace(os.Getenv("ONCHAIN_BTC_MEMPOOL_BASE_URL"))
+	cfg.MarketEventsBuiltin = !strings.EqualFold(strings.TrimSpace(getenv("MARKET_EVENTS_BUILTIN")), "false")
+	cfg.MarketEventsFile = strings.TrimSpace(getenv("MARKET_EVENTS_FILE"))
+
+	cfg.OnChainBTCMempoolBaseURL = strings.TrimSpace(getenv("ONCHAIN_BTC_MEMPOOL_BASE_URL"))
 	if cfg.OnChainBTCMempoolBaseURL == "" {
 		cfg.OnChainBTCMempoolBaseURL = "https://mempool.space"
 	}
-	cfg.OnChainETHBlockscoutBaseURL = strings.TrimSpace(os.Getenv("ONCHAIN_ETH_BLOCKSCOUT_BASE_URL"))
+	cfg.OnChainETHBlockscoutBaseURL = strings.TrimSpace(getenv("ONCHAIN_ETH_BLOCKSCOUT_BASE_URL"))
 	if cfg.OnChainETHBlockscoutBaseURL == "" {
 		cfg.OnChainETHBlockscoutBaseURL = "https://eth.blockscout.com"
 	}
-	cfg.OnChainADAKoiosBaseURL = strings.TrimSpace(os.Getenv("ONCHAIN_ADA_KOIOS_BASE_URL"))
+	cfg.OnChainADAKoiosBaseURL = strings.TrimSpace(getenv("ONCHAIN_ADA_KOIOS_BASE_URL"))
 	if cfg.OnChainADAKoiosBaseURL == "" {
 		cfg.OnChainADAKoiosBaseURL = "https://api.koios.rest"
 	}
-	cfg.OnChainXRPAPIBaseURL = strings.TrimSpace(os.Getenv("ONCHAIN_XRP_API_BASE_URL"))
+	cfg.OnChainXRPAPIBaseURL = strings.TrimSpace(getenv("ONCHAIN_XRP_API_BASE_URL"))
 	if cfg.OnChainXRPAPIBaseURL == "" {
 		cfg.OnChainXRPAPIBaseURL = "https://api.xrpscan.com"
 	}
 
-	cfg.SSHEnabled = strings.EqualFold(strings.TrimSpace(os.Getenv("SSH_ENABLED")), "true")
+	cfg.SSHEnabled = strings.EqualFold(strings.TrimSpace(getenv("SSH_ENABLED")), "true")
 
 	cfg.SSHPort = 2222
-	if v := strings.TrimSpace(os.Getenv("SSH_PORT")); v != "" {
+	if v := strings.TrimSpace(getenv("SSH_PORT")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.SSHPort = n
 		}
 	}
 
-	cfg.SSHHostKeyPath = strings.TrimSpace(os.Getenv("SSH_HOST_KEY_PATH"))
+	cfg.SSHHostKeyPath = strings.TrimSpace(getenv("SSH_HOST_KEY_PATH"))
 	if cfg.SSHHostKeyPath == "" {
 		cfg.SSHHostKeyPath = ".ssh/id_ed25519"
 	}
 
 	cfg.SSHIdleTimeout = 300
-	if v := strings.TrimSpace(os.Getenv("SSH_IDLE_TIMEOUT_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("SSH_IDLE_TIMEOUT_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
 			cfg.SSHIdleTimeout = n
 		}
 	}
 
-	cfg.RESTAPIKey = strings.TrimSpace(os.Getenv("REST_API_KEY"))
+	for _, name := range strings.Split(getenv("SSH_OPERATORS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.SSHOperators = append(cfg.SSHOperators, name)
+		}
+	}
+
+	cfg.TUITheme = strings.ToLower(strings.TrimSpace(getenv("TUI_THEME")))
+	cfg.NoColor = getenv("NO_COLOR") != ""
+
+	cfg.MaintenanceWindows = parseMaintenanceWindows(getenv("MAINTENANCE_WINDOWS"))
+
+	switch v := strings.ToLower(strings.TrimSpace(getenv("STREAM_BACKEND"))); v {
+	case "nats", "kafka":
+		cfg.StreamBackend = v
+	case "":
+	default:
+		warnf("Warning: unknown STREAM_BACKEND %q, stream export disabled", v)
+	}
+	cfg.StreamURL = strings.TrimSpace(getenv("STREAM_URL"))
+	if cfg.StreamBackend != "" && cfg.StreamURL == "" {
+		warnf("Warning: STREAM_BACKEND=%s needs STREAM_URL, stream export disabled", cfg.StreamBackend)
+		cfg.StreamBackend = ""
+	}
+	cfg.StreamSignalsSubject = "bug-free-umbrella.signals"
+	if v := strings.TrimSpace(getenv("STREAM_SIGNALS_SUBJECT")); v != "" {
+		cfg.StreamSignalsSubject = v
+	}
+	cfg.StreamPredictionsSubject = "bug-free-umbrella.predictions"
+	if v := strings.TrimSpace(getenv("STREAM_PREDICTIONS_SUBJECT")); v != "" {
+		cfg.StreamPredictionsSubject = v
+	}
+
+	cfg.WorkQueueEnabled = strings.EqualFold(strings.TrimSpace(getenv("WORK_QUEUE_ENABLED")), "true")
+	cfg.WorkQueueStream = "work-queue"
+	if v := strings.TrimSpace(getenv("WORK_QUEUE_STREAM")); v != "" {
+		cfg.WorkQueueStream = v
+	}
+	cfg.WorkQueueClaimAfterSecs = 60
+	if v := getenv("WORK_QUEUE_CLAIM_AFTER_SECS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.WorkQueueClaimAfterSecs = n
+		}
+	}
+	cfg.WorkQueueMaxDeliveries = 5
+	if v := getenv("WORK_QUEUE_MAX_DELIVERIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.WorkQueueMaxDeliveries = n
+		}
+	}
+
+	cfg.RESTAPIKey = strings.TrimSpace(getenv("REST_API_KEY"))
 	if cfg.RESTAPIKey == "" {
-		log.Println("Warning: REST_API_KEY not set, REST API will be unauthenticated")
+		warnf("Warning: REST_API_KEY not set, REST API will be unauthenticated")
 	}
 
-	raw := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	cfg.RESTAPITenantKeys = parseTenantKeys(getenv("REST_API_KEYS"), warnf)
+
+	cfg.OutboundHTTP = loadOutboundHTTP(getenv)
+
+	raw := strings.TrimSpace(getenv("CORS_ALLOWED_ORIGINS"))
 	if raw == "" {
 		cfg.CORSAllowedOrigins = []string{"*"}
 	} else {
@@ -411,32 +976,72 @@ func Load() *Config {
 		}
 	}
 
-	cfg.WebConsoleEnabled = strings.EqualFold(strings.TrimSpace(os.Getenv("WEB_CONSOLE_ENABLED")), "true")
+	cfg.IdempotencyTTLSecs = 24 * 60 * 60
+	if v := strings.TrimSpace(getenv("IDEMPOTENCY_TTL_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.IdempotencyTTLSecs = n
+		}
+	}
+
+	cfg.HTTPRequestTimeoutSecs = 30
+	if v := strings.TrimSpace(getenv("HTTP_REQUEST_TIMEOUT_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.HTTPRequestTimeoutSecs = n
+		}
+	}
+	cfg.HTTPLongRequestTimeoutSecs = 600
+	if v := strings.TrimSpace(getenv("HTTP_LONG_REQUEST_TIMEOUT_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.HTTPLongRequestTimeoutSecs = n
+		}
+	}
+
+	cfg.WebConsoleEnabled = strings.EqualFold(strings.TrimSpace(getenv("WEB_CONSOLE_ENABLED")), "true")
 
-	cfg.WebConsoleCookieSecret = strings.TrimSpace(os.Getenv("WEB_CONSOLE_COOKIE_SECRET"))
+	cfg.WebConsoleCookieSecret = strings.TrimSpace(getenv("WEB_CONSOLE_COOKIE_SECRET"))
 	if cfg.WebConsoleCookieSecret == "" {
 		cfg.WebConsoleCookieSecret = "web-console-dev-secret"
 	}
 
 	cfg.WebConsoleSessionTTLSecs = 24 * 60 * 60
-	if v := strings.TrimSpace(os.Getenv("WEB_CONSOLE_SESSION_TTL_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("WEB_CONSOLE_SESSION_TTL_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.WebConsoleSessionTTLSecs = n
 		}
 	}
 
 	cfg.WebConsoleHeartbeatSecs = 20
-	if v := strings.TrimSpace(os.Getenv("WEB_CONSOLE_WS_HEARTBEAT_SECS")); v != "" {
+	if v := strings.TrimSpace(getenv("WEB_CONSOLE_WS_HEARTBEAT_SECS")); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			cfg.WebConsoleHeartbeatSecs = n
 		}
 	}
 
-	cfg.WebConsoleStaticDir = strings.TrimSpace(os.Getenv("WEB_CONSOLE_STATIC_DIR"))
+	cfg.WebConsoleStaticDir = strings.TrimSpace(getenv("WEB_CONSOLE_STATIC_DIR"))
 	if cfg.WebConsoleStaticDir == "" {
 		cfg.WebConsoleStaticDir = "web/dist"
 	}
 
+	// SANDBOX_MODE is another name for DEMO_MODE.
+	cfg.DemoMode = strings.EqualFold(strings.TrimSpace(getenv("DEMO_MODE")), "true") ||
+		strings.EqualFold(strings.TrimSpace(getenv("SANDBOX_MODE")), "true")
+
+	cfg.DemoSeed = 1
+	if v := strings.TrimSpace(getenv("DEMO_SEED")); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			cfg.DemoSeed = n
+		}
+	}
+	cfg.DemoDataDir = strings.TrimSpace(getenv("DEMO_DATA_DIR"))
+	if cfg.DemoMode && strings.TrimSpace(getenv("REDIS_URL")) == "" {
+		// Demo mode starts an embedded Redis instead of assuming localhost.
+		cfg.RedisURL = ""
+	}
+	if cfg.DemoMode && cfg.MarketIntelEnabled {
+		warnf("Warning: MARKET_INTEL_ENABLED ignored in DEMO_MODE, it needs external feeds")
+		cfg.MarketIntelEnabled = false
+	}
+
 	return cfg
 }
 
@@ -520,6 +1125,308 @@ func parseCSVWithDefault(raw string, fallback []string) []string {
 	return out
 }
 
+// LoadOutboundHTTP reads the outbound proxy and TLS settings on their own,
+// for tools that call third-party APIs without loading the full config.
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY are honored by default;
+// OUTBOUND_PROXY replaces them and OUTBOUND_PROXY_OVERRIDES sets a proxy or
+// "direct" per provider, e.g. "telegram=http://tg-proxy:8080,coingecko=direct".
+// PROVIDER_BREAKER_THRESHOLD and PROVIDER_BREAKER_COOLDOWN_SECS tune the
+// per-provider circuit breakers.
+// OUTBOUND_RECORD_MODE=record|replay with OUTBOUND_RECORD_DIR captures or
+// replays provider responses; PROVIDER_REPLAY=true is short for replay.
+func LoadOutboundHTTP() httpclient.Config {
+	return loadOutboundHTTP(os.Getenv)
+}
+
+func loadOutboundHTTP(getenv func(string) string) httpclient.Config {
+	cfg := httpclient.Config{
+		ProxyURL:        strings.TrimSpace(getenv("OUTBOUND_PROXY")),
+		ProviderProxies: parseProxyOverrides(getenv("OUTBOUND_PROXY_OVERRIDES")),
+		CABundle:        strings.TrimSpace(getenv("OUTBOUND_CA_BUNDLE")),
+		TLSMinVersion:   strings.TrimSpace(getenv("OUTBOUND_TLS_MIN_VERSION")),
+		RecordMode:      strings.ToLower(strings.TrimSpace(getenv("OUTBOUND_RECORD_MODE"))),
+		RecordDir:       strings.TrimSpace(getenv("OUTBOUND_RECORD_DIR")),
+	}
+	if strings.EqualFold(strings.TrimSpace(getenv("PROVIDER_REPLAY")), "true") {
+		cfg.RecordMode = httpclient.Replay
+	}
+	if cfg.RecordMode != "" && cfg.RecordDir == "" {
+		cfg.RecordDir = DefaultRecordDir
+	}
+	cfg.BreakerThreshold = 5
+	if v := strings.TrimSpace(getenv("PROVIDER_BREAKER_THRESHOLD")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.BreakerThreshold = n
+		}
+	}
+	cfg.BreakerCooldownSecs = 30
+	if v := strings.TrimSpace(getenv("PROVIDER_BREAKER_COOLDOWN_SECS")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.BreakerCooldownSecs = n
+		}
+	}
+	return cfg
+}
+
+// DefaultRecordDir is where provider responses are recorded and replayed
+// from when OUTBOUND_RECORD_DIR is not set.
+const DefaultRecordDir = "recordings"
+
+// parseProxyOverrides parses provider=proxy entries. Malformed entries are
+// skipped with a warning; proxy URLs are validated by httpclient.Configure.
+func parseProxyOverrides(raw string) map[string]string {
+	out := make(map[string]string)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, proxy, ok := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		proxy = strings.TrimSpace(proxy)
+		if !ok || name == "" || proxy == "" {
+			log.Printf("Warning: ignoring OUTBOUND_PROXY_OVERRIDES entry %q, want provider=proxy", part)
+			continue
+		}
+		out[name] = proxy
+	}
+	return out
+}
+
+// parseTenantKeys parses REST_API_KEYS entries of the form key=tenant,key2=tenant2.
+// Entries without a tenant are bound to the legacy tenant rather than the
+// default one, so a bare key never gets operator rights; key=default does.
+func parseTenantKeys(raw string, warnf func(string, ...any)) map[string]string {
+	out := make(map[string]string)
+	bare := 0
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, tenantID, _ := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if strings.TrimSpace(tenantID) == "" {
+			bare++
+			tenantID = domain.LegacyKeyTenantID
+		}
+		out[key] = domain.NormalizeTenantID(tenantID)
+	}
+	if bare > 0 {
+		warnf("Warning: %d REST_API_KEYS entries have no tenant and are bound to %q without operator access; use key=%s for operator keys",
+			bare, domain.LegacyKeyTenantID, domain.DefaultTenantID)
+	}
+	return out
+}
+
+// parsePinnedModels parses ML_PIN_MODELS entries of the form
+// model_key=version, e.g. "xgboost=12,logreg=9". Malformed entries are
+// skipped with a warning.
+func parsePinnedModels(raw string, warnf func(string, ...any)) map[string]int {
+	out := make(map[string]int)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, version, _ := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		n, err := strconv.Atoi(strings.TrimSpace(version))
+		if key == "" || err != nil || n <= 0 {
+			warnf("Warning: ignoring ML_PIN_MODELS entry %q, want model_key=version", part)
+			continue
+		}
+		out[key] = n
+	}
+	return out
+}
+
+// parseRollingModels parses ML_ROLLING_MODELS entries of the form
+// model_key=count, e.g. "xgboost=3,logreg=3". A count of one is the same as
+// no entry. Malformed entries are skipped with a warning.
+func parseRollingModels(raw string, warnf func(string, ...any)) map[string]int {
+	out := make(map[string]int)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, count, _ := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if key == "" || err != nil || n <= 0 {
+			warnf("Warning: ignoring ML_ROLLING_MODELS entry %q, want model_key=count", part)
+			continue
+		}
+		if n > 1 {
+			out[key] = n
+		}
+	}
+	return out
+}
+
+// parseMaintenanceWindows parses MAINTENANCE_WINDOWS entries of the form
+// start/duration/note separated by semicolons, where start is RFC 3339 and the
+// note is optional, e.g. "2026-11-01T02:00:00Z/2h/Postgres upgrade".
+// Malformed entries are skipped with a warning.
+func parseMaintenanceWindows(raw string) []domain.MaintenanceWindow {
+	var out []domain.MaintenanceWindow
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.SplitN(part, "/", 3)
+		if len(fields) < 2 {
+			log.Printf("Warning: ignoring MAINTENANCE_WINDOWS entry %q, want start/duration[/note]", part)
+			continue
+		}
+		start, err := time.Parse(time.RFC3339, strings.TrimSpace(fields[0]))
+		if err != nil {
+			log.Printf("Warning: ignoring MAINTENANCE_WINDOWS entry %q: %v", part, err)
+			continue
+		}
+		dur, err := time.ParseDuration(strings.TrimSpace(fields[1]))
+		if err != nil || dur <= 0 {
+			log.Printf("Warning: ignoring MAINTENANCE_WINDOWS entry %q: invalid duration", part)
+			continue
+		}
+		w := domain.MaintenanceWindow{Start: start.UTC(), Duration: dur}
+		if len(fields) == 3 {
+			w.Note = strings.TrimSpace(fields[2])
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// LoadSignalRiskOverrides reads only SIGNAL_RISK_OVERRIDES, for tools that
+// run the signal engine without the rest of the configuration.
+func LoadSignalRiskOverrides() []domain.RiskOverride {
+	return parseRiskOverrides(os.Getenv("SIGNAL_RISK_OVERRIDES"), log.Printf)
+}
+
+// LoadIchimokuPeriods reads only ICHIMOKU_PERIODS, for tools that run the
+// signal engine without the rest of the configuration.
+func LoadIchimokuPeriods() domain.IchimokuPeriods {
+	return parseIchimokuPeriods(os.Getenv("ICHIMOKU_PERIODS"), log.Printf)
+}
+
+// parseIchimokuPeriods parses ICHIMOKU_PERIODS as tenkan,kijun,senkou_b,
+// e.g. "9,26,52". Malformed or out-of-order periods, and periods whose
+// cloud does not fit in the signal lookback, fall back to the defaults with
+// a warning.
+func parseIchimokuPeriods(raw string, warnf func(string, ...any)) domain.IchimokuPeriods {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return domain.DefaultIchimokuPeriods
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) != 3 {
+		warnf("Warning: ignoring ICHIMOKU_PERIODS %q, want tenkan,kijun,senkou_b", raw)
+		return domain.DefaultIchimokuPeriods
+	}
+	var n [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			warnf("Warning: ignoring ICHIMOKU_PERIODS %q, want tenkan,kijun,senkou_b", raw)
+			return domain.DefaultIchimokuPeriods
+		}
+		n[i] = v
+	}
+	p := domain.IchimokuPeriods{Tenkan: n[0], Kijun: n[1], SenkouB: n[2]}
+	if !p.Valid() {
+		warnf("Warning: ignoring ICHIMOKU_PERIODS %q: periods must be positive and increasing", raw)
+		return domain.DefaultIchimokuPeriods
+	}
+	// One of the loaded candles may be in progress and is not evaluated.
+	if limit := domain.SignalLookbackCandles - 1; p.Lookback() > limit {
+		warnf("Warning: ignoring ICHIMOKU_PERIODS %q: breakouts need %d candles, signals see at most %d", raw, p.Lookback(), limit)
+		return domain.DefaultIchimokuPeriods
+	}
+	return p
+}
+
+// parseRiskOverrides parses SIGNAL_RISK_OVERRIDES entries of the form
+// indicator[/interval]=risk separated by commas, e.g. "rsi/5m=3,macd=4".
+// Without an interval the risk applies to every interval. Malformed entries
+// and indicators the engine does not produce are skipped with a warning.
+func parseRiskOverrides(raw string, warnf func(string, ...any)) []domain.RiskOverride {
+	var out []domain.RiskOverride
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			warnf("Warning: ignoring SIGNAL_RISK_OVERRIDES entry %q, want indicator[/interval]=risk", part)
+			continue
+		}
+		indicator, interval, _ := strings.Cut(strings.ToLower(strings.TrimSpace(key)), "/")
+		indicator, interval = strings.TrimSpace(indicator), strings.TrimSpace(interval)
+		if !domain.IsClassicIndicator(indicator) {
+			warnf("Warning: ignoring SIGNAL_RISK_OVERRIDES entry %q: %q is not a classic indicator", part, indicator)
+			continue
+		}
+		if interval != "" && domain.IntervalDuration(interval) == 0 {
+			warnf("Warning: ignoring SIGNAL_RISK_OVERRIDES entry %q: unsupported interval", part)
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if risk := domain.RiskLevel(n); err != nil || !risk.IsValid() {
+			warnf("Warning: ignoring SIGNAL_RISK_OVERRIDES entry %q: risk must be 1-5", part)
+			continue
+		}
+		out = append(out, domain.RiskOverride{Indicator: indicator, Interval: interval, Risk: domain.RiskLevel(n)})
+	}
+	return out
+}
+
+// parseSymbolPauses parses SYMBOL_PAUSES entries of the form
+// SYMBOL=part[+part...], where a part is signals, ml, alerts or all.
+func parseSymbolPauses(raw string, warnf func(string, ...any)) map[string]domain.AssetPauses {
+	out := make(map[string]domain.AssetPauses)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		symbol, parts, ok := strings.Cut(entry, "=")
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if !ok || symbol == "" {
+			warnf("Warning: ignoring SYMBOL_PAUSES entry %q, want SYMBOL=signals+ml+alerts", entry)
+			continue
+		}
+		p := out[symbol]
+		valid := true
+		for _, part := range strings.Split(parts, "+") {
+			switch strings.ToLower(strings.TrimSpace(part)) {
+			case "signals":
+				p.SignalsPaused = true
+			case "ml":
+				p.MLPaused = true
+			case "alerts":
+				p.AlertsPaused = true
+			case "all":
+				p = domain.AssetPauses{SignalsPaused: true, MLPaused: true, AlertsPaused: true}
+			default:
+				valid = false
+			}
+		}
+		if !valid {
+			warnf("Warning: ignoring SYMBOL_PAUSES entry %q: parts must be signals, ml, alerts or all", entry)
+			continue
+		}
+		out[symbol] = p
+	}
+	return out
+}
+
 func parseSymbolListWithDefault(raw string, fallback []string) []string {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -533,7 +1440,7 @@ func parseSymbolListWithDefault(raw string, fallback []string) []string {
 		if symbol == "" {
 			continue
 		}
-		if _, ok := domain.CoinGeckoID[symbol]; !ok {
+		if !domain.IsSupportedSymbol(symbol) {
 			continue
 		}
 		if _, ok := seen[symbol]; ok {
@@ -547,3 +1454,25 @@ func parseSymbolListWithDefault(raw string, fallback []string) []string {
 	}
 	return out
 }
+
+// parseQuorumProviders reads PRICE_QUORUM_PROVIDERS, a comma-separated list
+// of price providers to cross-check primary against. The primary itself,
+// duplicates and unknown names are dropped.
+func parseQuorumProviders(raw, primary string, warnf func(string, ...any)) []string {
+	var out []string
+	seen := map[string]bool{primary: true}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		switch name {
+		case "coingecko", "binance", "kraken", "coinbase":
+			seen[name] = true
+			out = append(out, name)
+		default:
+			warnf("Warning: ignoring PRICE_QUORUM_PROVIDERS entry %q, want coingecko, binance, kraken or coinbase", name)
+		}
+	}
+	return out
+}
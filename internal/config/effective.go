@@ -0,0 +1,309 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/joho/godotenv"
+)
+
+// Sources of a configuration value.
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceSecrets = "secrets"
+	SourceDefault = "default"
+)
+
+const redacted = "[redacted]"
+
+type redaction int
+
+const (
+	showValue redaction = iota
+	hideValue
+	// hideURLPassword keeps URLs readable but masks their passwords.
+	hideURLPassword
+)
+
+// setting maps an environment variable to the Config field it sets.
+type setting struct {
+	key    string
+	field  string
+	redact redaction
+}
+
+// settings lists every variable Load reads, in the order of Config.
+var settings = []setting{
+	{"TELEGRAM_BOT_TOKEN", "TelegramBotToken", hideValue},
+	{"DATABASE_URL", "DatabaseURL", hideURLPassword},
+	{"REDIS_URL", "RedisURL", hideURLPassword},
+	{"COINGECKO_POLL_SECS", "CoinGeckoPollSecs", showValue},
+	{"COINGECKO_POLL_MIN_SECS", "CoinGeckoPollMinSecs", showValue},
+	{"COINGECKO_QUOTA_PER_MIN", "CoinGeckoQuotaPerMin", showValue},
+	{"COINGECKO_QUOTA_PER_DAY", "CoinGeckoQuotaPerDay", showValue},
+	{"SIGNAL_POLL_CONCURRENCY", "SignalPollConcurrency", showValue},
+	{"SIGNAL_IMAGE_TTL_HOURS", "SignalImageTTLHours", showValue},
+	{"SIGNAL_IMAGE_RETRY_DELAY_SECS", "SignalImageRetryDelaySecs", showValue},
+	{"SIGNAL_IMAGE_MAX_RETRIES", "SignalImageMaxRetries", showValue},
+	{"SIGNAL_IMAGE_RETRY_BATCH", "SignalImageRetryBatch", showValue},
+	{"SIGNAL_IMAGE_CLEANUP_BATCH", "SignalImageCleanupBatch", showValue},
+	{"CHART_IMAGE_FORMAT", "ChartImageFormat", showValue},
+	{"ALERT_TEMPLATE", "AlertTemplate", showValue},
+	{"ALERT_TEMPLATE_FILE", "AlertTemplateFile", showValue},
+	{"ALERT_MESSAGES_FILE", "AlertMessagesFile", showValue},
+	{"CANDLE_EVENTS_ENABLED", "CandleEventsEnabled", showValue},
+	{"SIGNAL_RISK_OVERRIDES", "SignalRiskOverrides", showValue},
+	{"ICHIMOKU_PERIODS", "IchimokuPeriods", showValue},
+	{"SYMBOL_PAUSES", "SymbolPauses", showValue},
+	{"TELEGRAM_CHAT_COMMANDS_PER_MIN", "TelegramChatCommandsPerMin", showValue},
+	{"TELEGRAM_SENDS_PER_SEC", "TelegramSendsPerSec", showValue},
+	{"MCP_TRANSPORT", "MCPTransport", showValue},
+	{"MCP_HTTP_ENABLED", "MCPHTTPEnabled", showValue},
+	{"MCP_HTTP_BIND", "MCPHTTPBind", showValue},
+	{"MCP_HTTP_PORT", "MCPHTTPPort", showValue},
+	{"MCP_AUTH_TOKEN", "MCPAuthToken", hideValue},
+	{"MCP_ADMIN_TOKEN", "MCPAdminToken", hideValue},
+	{"MCP_REQUEST_TIMEOUT_SECS", "MCPRequestTimeoutSecs", showValue},
+	{"MCP_RATE_LIMIT_PER_MIN", "MCPRateLimitPerMin", showValue},
+	{"OPENAI_API_KEY", "OpenAIAPIKey", hideValue},
+	{"OPENAI_MODEL", "OpenAIModel", showValue},
+	{"ADVISOR_MAX_HISTORY", "AdvisorMaxHistory", showValue},
+	{"ADVISOR_CACHE_TTL_SECS", "AdvisorCacheTTLSecs", showValue},
+	{"ADVISOR_GUARDRAILS", "AdvisorGuardrails", showValue},
+	{"ADVISOR_PRICE_TOLERANCE_PCT", "AdvisorPriceTolerancePct", showValue},
+	{"ADVISOR_CONVERSATION_IDLE_HOURS", "AdvisorConversationIdleHours", showValue},
+	{"ML_ENABLED", "MLEnabled", showValue},
+	{"ML_INTERVAL", "MLInterval", showValue},
+	{"ML_INTERVALS", "MLIntervals", showValue},
+	{"ML_TARGET_HOURS", "MLTargetHours", showValue},
+	{"ML_TRAIN_WINDOW_DAYS", "MLTrainWindowDays", showValue},
+	{"ML_TRAIN_EXCLUDE_FLAGGED", "MLTrainExcludeFlagged", showValue},
+	{"ML_INFER_POLL_SECS", "MLInferPollSecs", showValue},
+	{"ML_RESOLVE_POLL_SECS", "MLResolvePollSecs", showValue},
+	{"ML_TRAIN_HOUR_UTC", "MLTrainHourUTC", showValue},
+	{"ML_LONG_THRESHOLD", "MLLongThreshold", showValue},
+	{"ML_SHORT_THRESHOLD", "MLShortThreshold", showValue},
+	{"ML_MIN_TRAIN_SAMPLES", "MLMinTrainSamples", showValue},
+	{"ML_PIN_MODELS", "MLPinnedModels", showValue},
+	{"ML_ROLLING_MODELS", "MLRollingModels", showValue},
+	{"ML_ENABLE_IFOREST", "MLEnableIForest", showValue},
+	{"ML_ANOMALY_THRESHOLD", "MLAnomalyThresh", showValue},
+	{"ML_ANOMALY_DAMP_MAX", "MLAnomalyDampMax", showValue},
+	{"ML_UNCERTAINTY_BAND_WEIGHT", "MLUncertaintyBandWeight", showValue},
+	{"ML_IFOREST_TREES", "MLIForestTrees", showValue},
+	{"ML_IFOREST_SAMPLE_SIZE", "MLIForestSample", showValue},
+	{"MARKET_INTEL_ENABLED", "MarketIntelEnabled", showValue},
+	{"MARKET_INTEL_INTERVALS", "MarketIntelIntervals", showValue},
+	{"MARKET_INTEL_POLL_SECS", "MarketIntelPollSecs", showValue},
+	{"MARKET_INTEL_LONG_THRESHOLD", "MarketIntelLongThreshold", showValue},
+	{"MARKET_INTEL_SHORT_THRESHOLD", "MarketIntelShortThreshold", showValue},
+	{"MARKET_INTEL_LOOKBACK_HOURS_1H", "MarketIntelLookbackHours1H", showValue},
+	{"MARKET_INTEL_LOOKBACK_HOURS_4H", "MarketIntelLookbackHours4H", showValue},
+	{"MARKET_INTEL_NEWS_FEEDS", "MarketIntelNewsFeeds", showValue},
+	{"MARKET_INTEL_REDDIT_SUBS", "MarketIntelRedditSubs", showValue},
+	{"MARKET_INTEL_REDDIT_POST_LIMIT", "MarketIntelRedditPostLimit", showValue},
+	{"MARKET_INTEL_SCORING_MODEL", "MarketIntelScoringModel", showValue},
+	{"MARKET_INTEL_SCORING_BATCH_SIZE", "MarketIntelScoringBatchSize", showValue},
+	{"MARKET_INTEL_RETENTION_DAYS", "MarketIntelRetentionDays", showValue},
+	{"MARKET_INTEL_ENABLE_ONCHAIN", "MarketIntelEnableOnChain", showValue},
+	{"MARKET_INTEL_ONCHAIN_SYMBOLS", "MarketIntelOnChainSymbols", showValue},
+	{"ONCHAIN_BTC_MEMPOOL_BASE_URL", "OnChainBTCMempoolBaseURL", showValue},
+	{"ONCHAIN_ETH_BLOCKSCOUT_BASE_URL", "OnChainETHBlockscoutBaseURL", showValue},
+	{"ONCHAIN_ADA_KOIOS_BASE_URL", "OnChainADAKoiosBaseURL", showValue},
+	{"ONCHAIN_XRP_API_BASE_URL", "OnChainXRPAPIBaseURL", showValue},
+	{"MARKET_EVENTS_BUILTIN", "MarketEventsBuiltin", showValue},
+	{"MARKET_EVENTS_FILE", "MarketEventsFile", showValue},
+	{"SSH_ENABLED", "SSHEnabled", showValue},
+	{"SSH_PORT", "SSHPort", showValue},
+	{"SSH_HOST_KEY_PATH", "SSHHostKeyPath", showValue},
+	{"SSH_IDLE_TIMEOUT_SECS", "SSHIdleTimeout", showValue},
+	{"SSH_OPERATORS", "SSHOperators", showValue},
+	{"TUI_THEME", "TUITheme", showValue},
+	{"NO_COLOR", "NoColor", showValue},
+	{"REST_API_KEY", "RESTAPIKey", hideValue},
+	{"REST_API_KEYS", "RESTAPITenantKeys", hideValue},
+	{"CORS_ALLOWED_ORIGINS", "CORSAllowedOrigins", showValue},
+	{"IDEMPOTENCY_TTL_SECS", "IdempotencyTTLSecs", showValue},
+	{"HTTP_REQUEST_TIMEOUT_SECS", "HTTPRequestTimeoutSecs", showValue},
+	{"HTTP_LONG_REQUEST_TIMEOUT_SECS", "HTTPLongRequestTimeoutSecs", showValue},
+	{"STARTUP_CONNECT_TIMEOUT_SECS", "StartupConnectTimeoutSecs", showValue},
+	{"MAINTENANCE_WINDOWS", "MaintenanceWindows", showValue},
+	{"STREAM_BACKEND", "StreamBackend", showValue},
+	{"STREAM_URL", "StreamURL", hideURLPassword},
+	{"STREAM_SIGNALS_SUBJECT", "StreamSignalsSubject", showValue},
+	{"STREAM_PREDICTIONS_SUBJECT", "StreamPredictionsSubject", showValue},
+	{"WORK_QUEUE_ENABLED", "WorkQueueEnabled", showValue},
+	{"WORK_QUEUE_STREAM", "WorkQueueStream", showValue},
+	{"WORK_QUEUE_CLAIM_AFTER_SECS", "WorkQueueClaimAfterSecs", showValue},
+	{"WORK_QUEUE_MAX_DELIVERIES", "WorkQueueMaxDeliveries", showValue},
+	{"WEB_CONSOLE_ENABLED", "WebConsoleEnabled", showValue},
+	{"WEB_CONSOLE_COOKIE_SECRET", "WebConsoleCookieSecret", hideValue},
+	{"WEB_CONSOLE_SESSION_TTL_SECS", "WebConsoleSessionTTLSecs", showValue},
+	{"WEB_CONSOLE_WS_HEARTBEAT_SECS", "WebConsoleHeartbeatSecs", showValue},
+	{"WEB_CONSOLE_STATIC_DIR", "WebConsoleStaticDir", showValue},
+	{"DEMO_MODE", "DemoMode", showValue},
+	{"SANDBOX_MODE", "DemoMode", showValue},
+	{"DEMO_SEED", "DemoSeed", showValue},
+	{"DEMO_DATA_DIR", "DemoDataDir", showValue},
+	{"STORAGE_BACKEND", "StorageBackend", showValue},
+	{"SQLITE_PATH", "SQLitePath", showValue},
+	{"PRICE_PROVIDER", "PriceProvider", showValue},
+	{"COINGECKO_API_KEY", "CoinGeckoAPIKey", hideValue},
+	{"COINBASE_API_KEY_NAME", "CoinbaseAPIKeyName", showValue},
+	{"COINBASE_API_PRIVATE_KEY", "CoinbaseAPIPrivateKey", hideValue},
+	{"PRICE_STREAM_ENABLED", "PriceStreamEnabled", showValue},
+	{"PRICE_STREAM_URL", "PriceStreamURL", showValue},
+	{"ORDERBOOK_ENABLED", "OrderBookEnabled", showValue},
+	{"ORDERBOOK_POLL_SECS", "OrderBookPollSecs", showValue},
+	{"ORDERBOOK_DEPTH_BAND_PCT", "OrderBookDepthBandPct", showValue},
+	{"ORDERBOOK_RETENTION_DAYS", "OrderBookRetentionDays", showValue},
+	{"DERIVATIVES_ENABLED", "DerivativesEnabled", showValue},
+	{"DERIVATIVES_POLL_SECS", "DerivativesPollSecs", showValue},
+	{"DERIVATIVES_RETENTION_DAYS", "DerivativesRetentionDays", showValue},
+	{"GLOBAL_MARKET_ENABLED", "GlobalMarketEnabled", showValue},
+	{"GLOBAL_MARKET_POLL_SECS", "GlobalMarketPollSecs", showValue},
+	{"GLOBAL_MARKET_RETENTION_DAYS", "GlobalMarketRetentionDays", showValue},
+	{"DEX_SPREAD_ENABLED", "DexSpreadEnabled", showValue},
+	{"DEX_SPREAD_POLL_SECS", "DexSpreadPollSecs", showValue},
+	{"DEX_SPREAD_THRESHOLD_PCT", "DexSpreadThresholdPct", showValue},
+	{"DEX_SPREAD_RETENTION_DAYS", "DexSpreadRetentionDays", showValue},
+	// Hosted RPC URLs usually carry the API key in the path.
+	{"DEX_ETH_RPC_URL", "DexEthRPCURL", hideValue},
+	{"CANDLE_INTEGRITY_ENABLED", "CandleIntegrityEnabled", showValue},
+	{"CANDLE_INTEGRITY_POLL_SECS", "CandleIntegrityPollSecs", showValue},
+	{"CANDLE_INTEGRITY_SAMPLE_SIZE", "CandleIntegritySampleSize", showValue},
+	{"CANDLE_INTEGRITY_LOOKBACK_DAYS", "CandleIntegrityLookbackDays", showValue},
+	{"CANDLE_INTEGRITY_TOLERANCE_PCT", "CandleIntegrityTolerancePct", showValue},
+	{"CANDLE_INTEGRITY_ALERT_PCT", "CandleIntegrityAlertPct", showValue},
+	{"CANDLE_INTEGRITY_RETENTION_DAYS", "CandleIntegrityRetentionDays", showValue},
+	{"PRICE_QUORUM_PROVIDERS", "PriceQuorumProviders", showValue},
+	{"PRICE_QUORUM_DEVIATION_PCT", "PriceQuorumDeviationPct", showValue},
+	{"PRICE_QUORUM_RETENTION_DAYS", "PriceQuorumRetentionDays", showValue},
+	{"OUTBOUND_PROXY", "OutboundHTTP.ProxyURL", hideURLPassword},
+	{"OUTBOUND_PROXY_OVERRIDES", "OutboundHTTP.ProviderProxies", hideURLPassword},
+	{"OUTBOUND_CA_BUNDLE", "OutboundHTTP.CABundle", showValue},
+	{"OUTBOUND_TLS_MIN_VERSION", "OutboundHTTP.TLSMinVersion", showValue},
+	{"OUTBOUND_RECORD_MODE", "OutboundHTTP.RecordMode", showValue},
+	{"OUTBOUND_RECORD_DIR", "OutboundHTTP.RecordDir", showValue},
+	{"PROVIDER_REPLAY", "OutboundHTTP.RecordMode", showValue},
+	{"PROVIDER_BREAKER_THRESHOLD", "OutboundHTTP.BreakerThreshold", showValue},
+	{"PROVIDER_BREAKER_COOLDOWN_SECS", "OutboundHTTP.BreakerCooldownSecs", showValue},
+}
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = make(map[string]string)
+)
+
+// LoadEnvFile loads .env files like godotenv.Load and remembers the keys
+// they set, so Effective reports them as coming from a file. Variables
+// already in the environment win, as with godotenv.Load.
+func LoadEnvFile(filenames ...string) error {
+	before := envKeys()
+	err := godotenv.Load(filenames...)
+	var added []string
+	for key := range envKeys() {
+		if !before[key] {
+			added = append(added, key)
+		}
+	}
+	MarkSource(SourceFile, added...)
+	return err
+}
+
+// MarkSource records that keys were exported into the environment by
+// source, such as SourceSecrets for values fetched from a secrets backend.
+func MarkSource(source string, keys ...string) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	for _, key := range keys {
+		sources[key] = source
+	}
+}
+
+// Effective reports every setting of cfg with its default and where its
+// value came from. Secrets are redacted and URL passwords masked.
+func Effective(cfg *Config) []domain.ConfigSetting {
+	defaults := load(func(string) string { return "" }, func(string, ...any) {})
+	current := reflect.ValueOf(cfg).Elem()
+	base := reflect.ValueOf(defaults).Elem()
+
+	out := make([]domain.ConfigSetting, 0, len(settings))
+	for _, s := range settings {
+		value := fieldByPath(current, s.field).Interface()
+		def := fieldByPath(base, s.field).Interface()
+		out = append(out, domain.ConfigSetting{
+			Key:      s.key,
+			Value:    s.redactValue(value),
+			Default:  s.redactValue(def),
+			Source:   sourceOf(s.key),
+			Changed:  !reflect.DeepEqual(value, def),
+			Redacted: s.redact == hideValue,
+		})
+	}
+	return out
+}
+
+func sourceOf(key string) string {
+	if strings.TrimSpace(os.Getenv(key)) == "" {
+		return SourceDefault
+	}
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	if src, ok := sources[key]; ok {
+		return src
+	}
+	return SourceEnv
+}
+
+func (s setting) redactValue(v any) any {
+	switch s.redact {
+	case hideValue:
+		if reflect.ValueOf(v).Len() == 0 {
+			return nil
+		}
+		return redacted
+	case hideURLPassword:
+		switch v := v.(type) {
+		case string:
+			return maskURLPassword(v)
+		case map[string]string:
+			masked := make(map[string]string, len(v))
+			for name, raw := range v {
+				masked[name] = maskURLPassword(raw)
+			}
+			return masked
+		}
+	}
+	return v
+}
+
+func maskURLPassword(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	return u.Redacted()
+}
+
+func fieldByPath(v reflect.Value, path string) reflect.Value {
+	for _, name := range strings.Split(path, ".") {
+		v = v.FieldByName(name)
+	}
+	return v
+}
+
+func envKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		keys[key] = true
+	}
+	return keys
+}
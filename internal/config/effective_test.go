@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestSettingsCoverEveryVariable(t *testing.T) {
+	src, err := os.ReadFile("config.go")
+	if err != nil {
+		t.Fatalf("read config.go: %v", err)
+	}
+	listed := make(map[string]bool, len(settings))
+	cfg := reflect.ValueOf(&Config{}).Elem()
+	for _, s := range settings {
+		if listed[s.key] {
+			t.Fatalf("%s is listed twice", s.key)
+		}
+		listed[s.key] = true
+		if !fieldByPath(cfg, s.field).IsValid() {
+			t.Fatalf("%s maps to unknown field %s", s.key, s.field)
+		}
+	}
+	for _, m := range regexp.MustCompile(`getenv\("([A-Z0-9_]+)"\)`).FindAllStringSubmatch(string(src), -1) {
+		if !listed[m[1]] {
+			t.Fatalf("%s is read by Load but missing from settings", m[1])
+		}
+	}
+}
+
+func forgetSource(key string) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	delete(sources, key)
+}
+
+func settingByKey(t *testing.T, all []domain.ConfigSetting, key string) domain.ConfigSetting {
+	t.Helper()
+	for _, s := range all {
+		if s.Key == key {
+			return s
+		}
+	}
+	t.Fatalf("setting %s not reported", key)
+	return domain.ConfigSetting{}
+}
+
+func TestEffectiveReportsSourcesAndRedacts(t *testing.T) {
+	t.Setenv("COINGECKO_POLL_SECS", "30")
+	t.Setenv("ML_TARGET_HOURS", "")
+	t.Setenv("OPENAI_API_KEY", "sk-live-123")
+	t.Setenv("DATABASE_URL", "postgres://app:hunter2@db:5432/app")
+	t.Setenv("REST_API_KEYS", "")
+	t.Setenv("TELEGRAM_BOT_TOKEN", "bot-token")
+	MarkSource(SourceSecrets, "TELEGRAM_BOT_TOKEN")
+	t.Cleanup(func() { forgetSource("TELEGRAM_BOT_TOKEN") })
+
+	all := Effective(Load())
+
+	poll := settingByKey(t, all, "COINGECKO_POLL_SECS")
+	if poll.Value != 30 || poll.Default != 60 || poll.Source != SourceEnv || !poll.Changed {
+		t.Fatalf("unexpected poll setting %+v", poll)
+	}
+	target := settingByKey(t, all, "ML_TARGET_HOURS")
+	if target.Value != 4 || target.Source != SourceDefault || target.Changed {
+		t.Fatalf("unexpected default setting %+v", target)
+	}
+	key := settingByKey(t, all, "OPENAI_API_KEY")
+	if key.Value != redacted || key.Default != nil || !key.Redacted || !key.Changed {
+		t.Fatalf("expected a redacted API key, got %+v", key)
+	}
+	if keys := settingByKey(t, all, "REST_API_KEYS"); keys.Value != nil {
+		t.Fatalf("expected unset tenant keys to report null, got %+v", keys)
+	}
+	dsn := settingByKey(t, all, "DATABASE_URL")
+	if s, _ := dsn.Value.(string); strings.Contains(s, "hunter2") || !strings.Contains(s, "db:5432") {
+		t.Fatalf("expected the password masked and the host kept, got %v", dsn.Value)
+	}
+	if token := settingByKey(t, all, "TELEGRAM_BOT_TOKEN"); token.Source != SourceSecrets {
+		t.Fatalf("expected the secrets source, got %+v", token)
+	}
+}
+
+func TestLoadEnvFileMarksFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("ML_TRAIN_WINDOW_DAYS=30\nML_TARGET_HOURS=8\n"), 0o600); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	t.Setenv("ML_TARGET_HOURS", "2")
+	t.Setenv("ML_TRAIN_WINDOW_DAYS", "")
+	os.Unsetenv("ML_TRAIN_WINDOW_DAYS")
+	t.Cleanup(func() { forgetSource("ML_TRAIN_WINDOW_DAYS") })
+
+	if err := LoadEnvFile(path); err != nil {
+		t.Fatalf("load env file: %v", err)
+	}
+	all := Effective(Load())
+	if days := settingByKey(t, all, "ML_TRAIN_WINDOW_DAYS"); days.Value != 30 || days.Source != SourceFile {
+		t.Fatalf("expected the file value, got %+v", days)
+	}
+	if hours := settingByKey(t, all, "ML_TARGET_HOURS"); hours.Value != 2 || hours.Source != SourceEnv {
+		t.Fatalf("expected the environment to win over the file, got %+v", hours)
+	}
+}
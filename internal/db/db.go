@@ -4,25 +4,85 @@ import (
 	"context"
 	"log"
 	"os"
+	"sync"
 
+	"bug-free-umbrella/internal/startup"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var Pool *pgxpool.Pool
 
+var (
+	dsnMu      sync.RWMutex
+	currentDSN *pgx.ConnConfig
+)
+
+var (
+	newPool  = pgxpool.NewWithConfig
+	pingPool = func(ctx context.Context, pool *pgxpool.Pool) error { return pool.Ping(ctx) }
+)
+
+// InitPostgres connects to DATABASE_URL, retrying with backoff until ctx
+// ends. If Postgres still does not answer, the pool is kept so later queries
+// reconnect, and callers hold background work back until Ping succeeds.
 func InitPostgres(ctx context.Context) {
 	dsn := os.Getenv("DATABASE_URL")
 	if dsn == "" {
 		log.Println("DATABASE_URL not set, skipping Postgres connection")
 		return
 	}
-	pool, err := pgxpool.New(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		log.Fatalf("failed to connect to Postgres: %v", err)
+		log.Fatalf("failed to parse DATABASE_URL: %v", err)
 	}
-	if err := pool.Ping(ctx); err != nil {
-		log.Fatalf("failed to ping Postgres: %v", err)
+	poolCfg.BeforeConnect = applyRotatedCredentials
+	pool, err := newPool(context.WithoutCancel(ctx), poolCfg)
+	if err != nil {
+		log.Fatalf("failed to create Postgres pool: %v", err)
 	}
 	Pool = pool
+	err = startup.Retry(ctx, "Postgres", func(ctx context.Context) error {
+		return pingPool(ctx, pool)
+	})
+	if err != nil {
+		log.Printf("Postgres not reachable at startup: %v; continuing, background jobs wait for it", err)
+		return
+	}
 	log.Println("Connected to Postgres")
 }
+
+// Ping reports whether Postgres answers. It is nil when Postgres is not
+// configured.
+func Ping(ctx context.Context) error {
+	if Pool == nil {
+		return nil
+	}
+	return pingPool(ctx, Pool)
+}
+
+// SetDSN records rotated credentials. Connections opened afterwards use the
+// new user and password; existing connections are left to expire naturally.
+func SetDSN(dsn string) error {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return err
+	}
+	dsnMu.Lock()
+	currentDSN = cfg
+	dsnMu.Unlock()
+	return nil
+}
+
+func applyRotatedCredentials(_ context.Context, cfg *pgx.ConnConfig) error {
+	dsnMu.RLock()
+	rotated := currentDSN
+	dsnMu.RUnlock()
+	if rotated == nil {
+		return nil
+	}
+	cfg.User = rotated.User
+	cfg.Password = rotated.Password
+	return nil
+}
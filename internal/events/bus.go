@@ -0,0 +1,81 @@
+// Package events is an in-process publish/subscribe bus for market events.
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// CandleClosed reports that the candle opened at OpenTime for Symbol and
+// Interval has closed and is stored.
+type CandleClosed struct {
+	Symbol    string
+	Interval  string
+	OpenTime  time.Time
+	CloseTime time.Time
+}
+
+// Bus fans candle-closed events out to subscribers. Publishing never blocks:
+// a subscriber whose buffer is full misses the event and it is logged.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   []*subscription
+	closed bool
+}
+
+type subscription struct {
+	name string
+	ch   chan CandleClosed
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a named subscriber with the given buffer size. The
+// returned channel is closed by Close.
+func (b *Bus) Subscribe(name string, buffer int) <-chan CandleClosed {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	sub := &subscription{name: name, ch: make(chan CandleClosed, buffer)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub.ch
+	}
+	b.subs = append(b.subs, sub)
+	return sub.ch
+}
+
+// PublishCandleClosed delivers evt to every subscriber.
+func (b *Bus) PublishCandleClosed(evt CandleClosed) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, sub := range b.subs {
+		select {
+		case sub.ch <- evt:
+		default:
+			log.Printf("event bus: subscriber %s is full, dropped %s %s candle close", sub.name, evt.Symbol, evt.Interval)
+		}
+	}
+}
+
+// Close closes every subscriber channel. Later publishes are ignored.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, sub := range b.subs {
+		close(sub.ch)
+	}
+}
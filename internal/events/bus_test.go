@@ -0,0 +1,58 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusFansOutToSubscribers(t *testing.T) {
+	bus := NewBus()
+	a := bus.Subscribe("a", 4)
+	b := bus.Subscribe("b", 4)
+
+	evt := CandleClosed{Symbol: "BTC", Interval: "1h", OpenTime: time.Unix(0, 0).UTC()}
+	bus.PublishCandleClosed(evt)
+
+	for name, ch := range map[string]<-chan CandleClosed{"a": a, "b": b} {
+		select {
+		case got := <-ch:
+			if got != evt {
+				t.Fatalf("subscriber %s got %+v", name, got)
+			}
+		default:
+			t.Fatalf("subscriber %s received nothing", name)
+		}
+	}
+}
+
+func TestBusDropsWhenSubscriberIsFull(t *testing.T) {
+	bus := NewBus()
+	ch := bus.Subscribe("slow", 1)
+
+	bus.PublishCandleClosed(CandleClosed{Symbol: "BTC"})
+	bus.PublishCandleClosed(CandleClosed{Symbol: "ETH"})
+
+	if got := <-ch; got.Symbol != "BTC" {
+		t.Fatalf("expected the first event to be kept, got %+v", got)
+	}
+	select {
+	case got := <-ch:
+		t.Fatalf("expected overflow to be dropped, got %+v", got)
+	default:
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	bus := NewBus()
+	ch := bus.Subscribe("a", 1)
+	bus.Close()
+	bus.Close()
+	bus.PublishCandleClosed(CandleClosed{Symbol: "BTC"})
+
+	if _, ok := <-ch; ok {
+		t.Fatal("expected closed channel")
+	}
+	if _, ok := <-bus.Subscribe("late", 1); ok {
+		t.Fatal("expected late subscription to be closed")
+	}
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+)
+
+const kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaRESTPublisher produces to Kafka through a Kafka REST Proxy (v2 API),
+// which keeps the broker protocol out of this service.
+type KafkaRESTPublisher struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewKafkaRESTPublisher takes the proxy's base URL, e.g.
+// http://kafka-rest:8082.
+func NewKafkaRESTPublisher(baseURL string) (*KafkaRESTPublisher, error) {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("kafka rest proxy url must be http(s)://host[:port], got %q", baseURL)
+	}
+	return &KafkaRESTPublisher{
+		baseURL: baseURL,
+		client:  httpclient.New(httpclient.KafkaREST, 10*time.Second),
+	}, nil
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces payload, which must be JSON, to the topic named subject.
+func (p *KafkaRESTPublisher) Publish(ctx context.Context, subject, key string, payload []byte) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: key, Value: payload}},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(subject), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	var out kafkaProduceResponse
+	if err := json.Unmarshal(raw, &out); err == nil {
+		for _, off := range out.Offsets {
+			if off.ErrorCode != nil || off.Error != "" {
+				return fmt.Errorf("kafka rest proxy rejected record: %s", off.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Close is a no-op; the HTTP client's connections are shared.
+func (p *KafkaRESTPublisher) Close() error { return nil }
@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKafkaRESTPublisherProduces(t *testing.T) {
+	var gotPath, gotType string
+	var gotBody map[string][]kafkaRecord
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotType = r.Header.Get("Content-Type")
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &gotBody)
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":12,"error_code":null,"error":null}]}`))
+	}))
+	defer srv.Close()
+
+	p, err := NewKafkaRESTPublisher(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("new publisher: %v", err)
+	}
+	if err := p.Publish(context.Background(), "bug-free-umbrella.predictions", "ETH", []byte(`{"type":"prediction"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if gotPath != "/topics/bug-free-umbrella.predictions" || gotType != kafkaRESTContentType {
+		t.Fatalf("unexpected request path=%s type=%s", gotPath, gotType)
+	}
+	records := gotBody["records"]
+	if len(records) != 1 || records[0].Key != "ETH" || string(records[0].Value) != `{"type":"prediction"}` {
+		t.Fatalf("unexpected records %+v", records)
+	}
+}
+
+func TestKafkaRESTPublisherReportsErrors(t *testing.T) {
+	status := http.StatusNotFound
+	body := `{"error_code":40401,"message":"Topic not found."}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	p, _ := NewKafkaRESTPublisher(srv.URL)
+	if err := p.Publish(context.Background(), "missing", "", []byte(`{}`)); err == nil {
+		t.Fatal("expected http error")
+	}
+
+	status = http.StatusOK
+	body = `{"offsets":[{"error_code":50002,"error":"Kafka error"}]}`
+	if err := p.Publish(context.Background(), "t", "", []byte(`{}`)); err == nil {
+		t.Fatal("expected per-record error")
+	}
+}
+
+func TestNewKafkaRESTPublisherValidatesURL(t *testing.T) {
+	if _, err := NewKafkaRESTPublisher("kafka:9092"); err == nil {
+		t.Fatal("expected broker address rejected in favour of a REST proxy url")
+	}
+}
@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MarketChannel is the Redis pub/sub channel that carries MarketEvents to
+// other processes, such as the SSH server.
+const MarketChannel = "market-events"
+
+// Market event types.
+const (
+	MarketCandleClosed = "candle_closed"
+	MarketSignals      = "signals"
+)
+
+// MarketEvent tells listeners that market data changed and is worth
+// refetching. It carries no data itself.
+type MarketEvent struct {
+	Type     string    `json:"type"`
+	Symbol   string    `json:"symbol,omitempty"`
+	Interval string    `json:"interval,omitempty"`
+	Signals  int       `json:"signals,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// RedisRelay publishes market events on MarketChannel. Publishing is best
+// effort: listeners fall back to polling, so failures are only logged.
+type RedisRelay struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+func NewRedisRelay(client *redis.Client) *RedisRelay {
+	return &RedisRelay{client: client, now: time.Now}
+}
+
+// RelayCandles publishes each candle close read from ch until ch is closed or
+// ctx is done.
+func (r *RedisRelay) RelayCandles(ctx context.Context, ch <-chan CandleClosed) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			r.publish(ctx, MarketEvent{
+				Type:     MarketCandleClosed,
+				Symbol:   evt.Symbol,
+				Interval: evt.Interval,
+				At:       evt.CloseTime,
+			})
+		}
+	}
+}
+
+// NotifySignals publishes one event per batch of new signals. It lets the
+// relay sit next to the Telegram alerts as a signal poller sink.
+func (r *RedisRelay) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	evt := MarketEvent{Type: MarketSignals, Signals: len(signals), At: r.now().UTC()}
+	symbol := signals[0].Symbol
+	for _, s := range signals[1:] {
+		if s.Symbol != symbol {
+			symbol = ""
+			break
+		}
+	}
+	evt.Symbol = symbol
+	r.publish(ctx, evt)
+	return nil
+}
+
+func (r *RedisRelay) publish(ctx context.Context, evt MarketEvent) {
+	if r == nil || r.client == nil {
+		return
+	}
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return
+	}
+	if err := r.client.Publish(ctx, MarketChannel, payload).Err(); err != nil {
+		log.Printf("market events: publish %s failed: %v", evt.Type, err)
+	}
+}
+
+// SubscribeMarket listens on MarketChannel until ctx is done, then closes the
+// returned channel. Like the Bus, it drops events for a slow reader rather
+// than block.
+func SubscribeMarket(ctx context.Context, client *redis.Client, buffer int) <-chan MarketEvent {
+	if buffer <= 0 {
+		buffer = 16
+	}
+	out := make(chan MarketEvent, buffer)
+	if client == nil {
+		close(out)
+		return out
+	}
+	pubsub := client.Subscribe(ctx, MarketChannel)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+		msgs := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				var evt MarketEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &evt); err != nil {
+					continue
+				}
+				select {
+				case out <- evt:
+				default:
+				}
+			}
+		}
+	}()
+	return out
+}
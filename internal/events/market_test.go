@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(mini.Close)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func receive(t *testing.T, ch <-chan MarketEvent) MarketEvent {
+	t.Helper()
+	select {
+	case evt := <-ch:
+		return evt
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for market event")
+		return MarketEvent{}
+	}
+}
+
+func TestRedisRelayDeliversToSubscribers(t *testing.T) {
+	client := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := SubscribeMarket(ctx, client, 4)
+	// Subscribing is asynchronous; wait until the channel has a listener.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		counts, err := client.PubSubNumSub(ctx, MarketChannel).Result()
+		if err == nil && counts[MarketChannel] > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("subscriber never registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	relay := NewRedisRelay(client)
+	if err := relay.NotifySignals(ctx, []domain.Signal{{Symbol: "BTC"}, {Symbol: "BTC"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	evt := receive(t, updates)
+	if evt.Type != MarketSignals || evt.Symbol != "BTC" || evt.Signals != 2 {
+		t.Fatalf("unexpected signals event %+v", evt)
+	}
+
+	candles := make(chan CandleClosed, 1)
+	candles <- CandleClosed{Symbol: "ETH", Interval: "1h"}
+	close(candles)
+	relay.RelayCandles(ctx, candles)
+	evt = receive(t, updates)
+	if evt.Type != MarketCandleClosed || evt.Symbol != "ETH" || evt.Interval != "1h" {
+		t.Fatalf("unexpected candle event %+v", evt)
+	}
+
+	cancel()
+	for range updates {
+	}
+}
+
+func TestSubscribeMarketWithoutClient(t *testing.T) {
+	if _, ok := <-SubscribeMarket(context.Background(), nil, 1); ok {
+		t.Fatal("expected closed channel without a client")
+	}
+}
@@ -0,0 +1,265 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	natsDefaultPort    = "4222"
+	natsConnectTimeout = 5 * time.Second
+	// natsWriteTimeout bounds a publish when ctx has no earlier deadline, so
+	// a stalled broker fails the publish instead of blocking every caller.
+	natsWriteTimeout = 5 * time.Second
+)
+
+// NATSPublisher publishes with the NATS core text protocol. It connects on
+// first use and reconnects after a failure, so a broker restart costs the
+// messages sent while it was down, not the export.
+type NATSPublisher struct {
+	addr   string
+	tls    bool
+	user   string
+	pass   string
+	token  string
+	dialer func(ctx context.Context, network, addr string) (net.Conn, error)
+	// tlsConfig overrides the client TLS settings; nil verifies the server
+	// against the system roots.
+	tlsConfig *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+// NewNATSPublisher parses a URL of the form nats://[user:pass@]host[:port].
+// A user without a password is sent as a token. tls:// requires TLS; a
+// nats:// connection also upgrades when the server's INFO asks for it.
+func NewNATSPublisher(rawURL string) (*NATSPublisher, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("parse nats url: %w", err)
+	}
+	if u.Scheme != "nats" && u.Scheme != "tls" {
+		return nil, fmt.Errorf("nats url must start with nats:// or tls://, got %q", rawURL)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("nats url %q has no host", rawURL)
+	}
+	p := &NATSPublisher{
+		addr:   u.Host,
+		tls:    u.Scheme == "tls",
+		dialer: (&net.Dialer{Timeout: natsConnectTimeout}).DialContext,
+	}
+	if u.Port() == "" {
+		p.addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			p.user, p.pass = u.User.Username(), pass
+		} else {
+			p.token = u.User.Username()
+		}
+	}
+	return p, nil
+}
+
+// Publish sends payload on subject, retrying once on a fresh connection.
+func (p *NATSPublisher) Publish(ctx context.Context, subject, key string, payload []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("invalid nats subject %q", subject)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if p.conn == nil {
+			if err = p.connect(ctx); err != nil {
+				continue
+			}
+		}
+		if err = p.writePub(ctx, subject, payload); err == nil {
+			return nil
+		}
+		p.dropLocked(p.conn)
+	}
+	return err
+}
+
+// writePub sends one PUB. The write deadline comes from ctx, capped at
+// natsWriteTimeout. Callers hold mu.
+func (p *NATSPublisher) writePub(ctx context.Context, subject string, payload []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(natsWriteTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := p.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(p.w, "PUB %s %d\r\n", subject, len(payload)); err != nil {
+		return err
+	}
+	if _, err := p.w.Write(payload); err != nil {
+		return err
+	}
+	if _, err := p.w.WriteString("\r\n"); err != nil {
+		return err
+	}
+	return p.w.Flush()
+}
+
+// connect dials, reads the plaintext INFO, upgrades to TLS when either side
+// requires it, then completes the CONNECT handshake and waits for the PONG
+// that confirms the server accepted it. Callers hold mu.
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, natsConnectTimeout)
+	defer cancel()
+
+	conn, err := p.dialer(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("dial nats: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("read nats info: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO") {
+		conn.Close()
+		return fmt.Errorf("unexpected nats greeting %q", strings.TrimSpace(line))
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	_ = json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "INFO"))), &info)
+	if p.tls || info.TLSRequired {
+		if r.Buffered() > 0 {
+			conn.Close()
+			return errors.New("nats server sent data before the TLS upgrade")
+		}
+		cfg := p.tlsConfig.Clone()
+		if cfg == nil {
+			host, _, _ := net.SplitHostPort(p.addr)
+			cfg = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return fmt.Errorf("nats tls handshake: %w", err)
+		}
+		conn = tlsConn
+		r = bufio.NewReader(conn)
+	}
+	w := bufio.NewWriter(conn)
+
+	opts, _ := json.Marshal(map[string]any{
+		"verbose":    false,
+		"pedantic":   false,
+		"name":       "bug-free-umbrella",
+		"lang":       "go",
+		"version":    "1.0.0",
+		"user":       p.user,
+		"pass":       p.pass,
+		"auth_token": p.token,
+	})
+	if _, err := fmt.Fprintf(w, "CONNECT %s\r\nPING\r\n", opts); err != nil {
+		conn.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		conn.Close()
+		return err
+	}
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return fmt.Errorf("nats handshake: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return fmt.Errorf("nats handshake: %s", line)
+		}
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	p.conn, p.w = conn, w
+	go p.readLoop(conn, r)
+	return nil
+}
+
+// readLoop answers server PINGs and logs protocol errors until conn fails.
+func (p *NATSPublisher) readLoop(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			p.mu.Lock()
+			p.dropLocked(conn)
+			p.mu.Unlock()
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PING":
+			p.mu.Lock()
+			if p.conn == conn {
+				_ = conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+				_, _ = p.w.WriteString("PONG\r\n")
+				_ = p.w.Flush()
+			}
+			p.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			log.Printf("stream export: nats %s", line)
+		}
+	}
+}
+
+// dropLocked closes conn and forgets it if it is still current.
+func (p *NATSPublisher) dropLocked(conn net.Conn) {
+	if conn == nil {
+		return
+	}
+	conn.Close()
+	if p.conn == conn {
+		p.conn, p.w = nil, nil
+	}
+}
+
+// Close flushes and closes the connection.
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	_ = p.conn.SetWriteDeadline(time.Now().Add(natsWriteTimeout))
+	err := p.w.Flush()
+	p.dropLocked(p.conn)
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
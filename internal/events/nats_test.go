@@ -0,0 +1,229 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeNATS accepts connections, completes the handshake and reports each
+// CONNECT line and published payload. With cert set it announces
+// tls_required in INFO and upgrades like nats-server; with stall set it stops
+// reading after the handshake.
+type fakeNATS struct {
+	ln       net.Listener
+	connects chan string
+	pubs     chan string
+	cert     *tls.Certificate
+	stall    bool
+}
+
+func startFakeNATS(t *testing.T) *fakeNATS {
+	return startFakeNATSWith(t, func(*fakeNATS) {})
+}
+
+func startFakeNATSWith(t *testing.T, configure func(*fakeNATS)) *fakeNATS {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeNATS{ln: ln, connects: make(chan string, 4), pubs: make(chan string, 16)}
+	configure(s)
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeNATS) serve(conn net.Conn) {
+	defer conn.Close()
+	if s.cert != nil {
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"tls_required\":true}\r\n")
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{*s.cert}})
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	} else {
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+	}
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "CONNECT "):
+			s.connects <- strings.TrimPrefix(line, "CONNECT ")
+		case line == "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+			if s.stall {
+				time.Sleep(5 * time.Second)
+				return
+			}
+		case strings.HasPrefix(line, "PUB "):
+			var subject string
+			var size int
+			if _, err := fmt.Sscanf(line, "PUB %s %d", &subject, &size); err != nil {
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			s.pubs <- subject + " " + string(payload[:size])
+		}
+	}
+}
+
+func (s *fakeNATS) url(userinfo string) string {
+	return "nats://" + userinfo + s.ln.Addr().String()
+}
+
+func waitFor(t *testing.T, ch <-chan string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for fake nats")
+		return ""
+	}
+}
+
+func TestNATSPublisherPublishes(t *testing.T) {
+	srv := startFakeNATS(t)
+	p, err := NewNATSPublisher(srv.url("quant:secret@"))
+	if err != nil {
+		t.Fatalf("new publisher: %v", err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), "bug-free-umbrella.signals", "BTC", []byte(`{"type":"signal"}`)); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if connect := waitFor(t, srv.connects); !strings.Contains(connect, `"user":"quant"`) || !strings.Contains(connect, `"pass":"secret"`) {
+		t.Fatalf("expected credentials in CONNECT, got %s", connect)
+	}
+	if got := waitFor(t, srv.pubs); got != `bug-free-umbrella.signals {"type":"signal"}` {
+		t.Fatalf("unexpected publish %q", got)
+	}
+}
+
+func TestNATSPublisherReconnects(t *testing.T) {
+	srv := startFakeNATS(t)
+	p, err := NewNATSPublisher(srv.url(""))
+	if err != nil {
+		t.Fatalf("new publisher: %v", err)
+	}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), "s", "", []byte("1")); err != nil {
+		t.Fatalf("first publish: %v", err)
+	}
+	waitFor(t, srv.pubs)
+
+	// Simulate the server dropping the connection.
+	p.mu.Lock()
+	p.conn.Close()
+	p.mu.Unlock()
+
+	if err := p.Publish(context.Background(), "s", "", []byte("2")); err != nil {
+		t.Fatalf("publish after drop: %v", err)
+	}
+	if got := waitFor(t, srv.pubs); got != "s 2" {
+		t.Fatalf("unexpected publish %q", got)
+	}
+}
+
+func TestNATSPublisherUpgradesToTLSAfterInfo(t *testing.T) {
+	// Borrow httptest's self-signed certificate for 127.0.0.1.
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	cert := https.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(https.Certificate())
+	https.Close()
+
+	srv := startFakeNATSWith(t, func(s *fakeNATS) { s.cert = &cert })
+	p, err := NewNATSPublisher("tls://" + srv.ln.Addr().String())
+	if err != nil {
+		t.Fatalf("new publisher: %v", err)
+	}
+	p.tlsConfig = &tls.Config{RootCAs: roots, ServerName: "127.0.0.1"}
+	defer p.Close()
+
+	if err := p.Publish(context.Background(), "s", "", []byte("secure")); err != nil {
+		t.Fatalf("publish over tls: %v", err)
+	}
+	if got := waitFor(t, srv.pubs); got != "s secure" {
+		t.Fatalf("unexpected publish %q", got)
+	}
+}
+
+func TestNATSPublisherStalledBrokerHonoursContext(t *testing.T) {
+	srv := startFakeNATSWith(t, func(s *fakeNATS) { s.stall = true })
+	p, err := NewNATSPublisher(srv.url(""))
+	if err != nil {
+		t.Fatalf("new publisher: %v", err)
+	}
+	defer p.Close()
+
+	// Larger than any socket buffer, so the write blocks on the broker.
+	payload := make([]byte, 32<<20)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = p.Publish(ctx, "s", "", payload)
+	if err == nil {
+		t.Fatal("expected publish to a stalled broker to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("publish blocked for %s, want it bounded by ctx", elapsed)
+	}
+	var netErr net.Error
+	if !errors.Is(err, context.DeadlineExceeded) && !(errors.As(err, &netErr) && netErr.Timeout()) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+}
+
+func TestNewNATSPublisherValidatesURL(t *testing.T) {
+	for _, raw := range []string{"http://localhost:4222", "nats://", "::bad"} {
+		if _, err := NewNATSPublisher(raw); err == nil {
+			t.Fatalf("expected %q rejected", raw)
+		}
+	}
+	p, err := NewNATSPublisher("nats://token@broker")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.addr != "broker:4222" || p.token != "token" {
+		t.Fatalf("unexpected parse: addr=%s token=%s", p.addr, p.token)
+	}
+}
+
+func TestNATSPublisherRejectsBadSubject(t *testing.T) {
+	p, _ := NewNATSPublisher("nats://localhost:4222")
+	if err := p.Publish(context.Background(), "has space", "", nil); err == nil {
+		t.Fatal("expected subject with whitespace rejected")
+	}
+}
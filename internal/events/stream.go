@@ -0,0 +1,213 @@
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// Stream event types.
+const (
+	StreamSignal     = "signal"
+	StreamPrediction = "prediction"
+)
+
+// Default subjects (NATS) or topics (Kafka) for the stream export.
+const (
+	DefaultSignalsSubject     = "bug-free-umbrella.signals"
+	DefaultPredictionsSubject = "bug-free-umbrella.predictions"
+)
+
+// StreamPublisher sends one message to a broker subject or topic. key
+// groups related messages; Kafka partitions by it and NATS ignores it.
+type StreamPublisher interface {
+	Publish(ctx context.Context, subject, key string, payload []byte) error
+	Close() error
+}
+
+// NewStreamPublisher connects the publisher for backend, "nats" or "kafka".
+// For Kafka, url is the base URL of a Kafka REST Proxy.
+func NewStreamPublisher(backend, url string) (StreamPublisher, error) {
+	switch strings.ToLower(strings.TrimSpace(backend)) {
+	case "nats":
+		return NewNATSPublisher(url)
+	case "kafka":
+		return NewKafkaRESTPublisher(url)
+	default:
+		return nil, fmt.Errorf("unknown stream backend %q", backend)
+	}
+}
+
+// StreamSubjects names where signals and predictions are published.
+type StreamSubjects struct {
+	Signals     string
+	Predictions string
+}
+
+// StreamEvent is the JSON message published for each new signal or
+// prediction. Exactly one of Signal and Prediction is set, matching Type.
+type StreamEvent struct {
+	Type       string                `json:"type"`
+	At         time.Time             `json:"at"`
+	Signal     *domain.Signal        `json:"signal,omitempty"`
+	Prediction *StreamPredictionData `json:"prediction,omitempty"`
+}
+
+// StreamPredictionData is the published form of an ML prediction.
+type StreamPredictionData struct {
+	ID           int64                  `json:"id"`
+	Symbol       string                 `json:"symbol"`
+	Interval     string                 `json:"interval"`
+	OpenTime     time.Time              `json:"open_time"`
+	TargetTime   time.Time              `json:"target_time"`
+	ModelKey     string                 `json:"model_key"`
+	ModelVersion int                    `json:"model_version"`
+	ProbUp       float64                `json:"prob_up"`
+	Confidence   float64                `json:"confidence"`
+	Direction    domain.SignalDirection `json:"direction"`
+	Risk         domain.RiskLevel       `json:"risk"`
+	SignalID     *int64                 `json:"signal_id,omitempty"`
+	Details      json.RawMessage        `json:"details,omitempty"`
+}
+
+type streamMessage struct {
+	subject string
+	key     string
+	id      string // stable identity of the signal or prediction
+	event   StreamEvent
+}
+
+// streamSeenLimit bounds the record of published messages; past it the
+// record is reset and a rerun may publish a row once more.
+const streamSeenLimit = 10000
+
+// StreamExporter forwards new signals and predictions to a broker so other
+// systems can consume the feed without polling the API. Like the Bus,
+// publishing never blocks the caller: messages are queued and sent from
+// Run, and a full queue drops the message with a log line.
+type StreamExporter struct {
+	pub      StreamPublisher
+	subjects StreamSubjects
+	now      func() time.Time
+	queue    chan streamMessage
+	seen     map[string][sha256.Size]byte // id -> digest last published, owned by Run
+}
+
+func NewStreamExporter(pub StreamPublisher, subjects StreamSubjects, buffer int) *StreamExporter {
+	if buffer <= 0 {
+		buffer = 1024
+	}
+	if subjects.Signals == "" {
+		subjects.Signals = DefaultSignalsSubject
+	}
+	if subjects.Predictions == "" {
+		subjects.Predictions = DefaultPredictionsSubject
+	}
+	return &StreamExporter{pub: pub, subjects: subjects, now: time.Now, queue: make(chan streamMessage, buffer), seen: make(map[string][sha256.Size]byte)}
+}
+
+// Run publishes queued messages until ctx is done, then closes the
+// publisher. Failed publishes are logged and not retried. Inference and
+// sentiment runs upsert the same rows on every pass, so a signal or
+// prediction is published again only when its content changed.
+func (e *StreamExporter) Run(ctx context.Context) {
+	defer e.pub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-e.queue:
+			e.publish(ctx, msg)
+		}
+	}
+}
+
+func (e *StreamExporter) publish(ctx context.Context, msg streamMessage) {
+	data, err := json.Marshal(StreamEvent{Signal: msg.event.Signal, Prediction: msg.event.Prediction})
+	if err != nil {
+		return
+	}
+	digest := sha256.Sum256(data)
+	if prev, ok := e.seen[msg.id]; ok && prev == digest {
+		return
+	}
+	payload, err := json.Marshal(msg.event)
+	if err != nil {
+		return
+	}
+	if err := e.pub.Publish(ctx, msg.subject, msg.key, payload); err != nil {
+		log.Printf("stream export: publish %s to %s failed: %v", msg.event.Type, msg.subject, err)
+		return
+	}
+	if len(e.seen) >= streamSeenLimit {
+		clear(e.seen)
+	}
+	e.seen[msg.id] = digest
+}
+
+// NotifySignals queues signals for export. It lets the exporter sit next to
+// the Telegram alerts as a signal poller sink.
+func (e *StreamExporter) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	e.PublishSignals(ctx, signals)
+	return nil
+}
+
+// PublishSignals queues one message per signal.
+func (e *StreamExporter) PublishSignals(ctx context.Context, signals []domain.Signal) {
+	for i := range signals {
+		sig := signals[i]
+		e.enqueue(streamMessage{
+			subject: e.subjects.Signals,
+			key:     sig.Symbol,
+			id:      signalStreamID(sig),
+			event:   StreamEvent{Type: StreamSignal, At: e.now().UTC(), Signal: &sig},
+		})
+	}
+}
+
+// PublishPrediction queues a stored ML prediction.
+func (e *StreamExporter) PublishPrediction(ctx context.Context, pred domain.MLPrediction) {
+	data := &StreamPredictionData{
+		ID:           pred.ID,
+		Symbol:       pred.Symbol,
+		Interval:     pred.Interval,
+		OpenTime:     pred.OpenTime.UTC(),
+		TargetTime:   pred.TargetTime.UTC(),
+		ModelKey:     pred.ModelKey,
+		ModelVersion: pred.ModelVersion,
+		ProbUp:       pred.ProbUp,
+		Confidence:   pred.Confidence,
+		Direction:    pred.Direction,
+		Risk:         pred.Risk,
+		SignalID:     pred.SignalID,
+	}
+	if json.Valid([]byte(pred.DetailsJSON)) {
+		data.Details = json.RawMessage(pred.DetailsJSON)
+	}
+	e.enqueue(streamMessage{
+		subject: e.subjects.Predictions,
+		key:     pred.Symbol,
+		id:      fmt.Sprintf("prediction:%s:%s:%d:%s:%d", pred.Symbol, pred.Interval, pred.OpenTime.Unix(), pred.ModelKey, pred.ModelVersion),
+		event:   StreamEvent{Type: StreamPrediction, At: e.now().UTC(), Prediction: data},
+	})
+}
+
+// signalStreamID follows the signals table's unique key, so it is stable
+// before and after the row has an ID.
+func signalStreamID(sig domain.Signal) string {
+	return fmt.Sprintf("signal:%s:%s:%s:%d:%s", sig.Symbol, sig.Interval, sig.Indicator, sig.Timestamp.Unix(), sig.Direction)
+}
+
+func (e *StreamExporter) enqueue(msg streamMessage) {
+	select {
+	case e.queue <- msg:
+	default:
+		log.Printf("stream export: queue is full, dropped %s for %s", msg.event.Type, msg.key)
+	}
+}
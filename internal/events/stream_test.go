@@ -0,0 +1,160 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+type publishedMessage struct {
+	subject string
+	key     string
+	event   StreamEvent
+}
+
+type recordingPublisher struct {
+	messages []publishedMessage
+	err      error
+	closed   bool
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, subject, key string, payload []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	var evt StreamEvent
+	if err := json.Unmarshal(payload, &evt); err != nil {
+		return err
+	}
+	p.messages = append(p.messages, publishedMessage{subject: subject, key: key, event: evt})
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+// drain publishes everything queued, as Run would.
+func drain(e *StreamExporter) {
+	for {
+		select {
+		case msg := <-e.queue:
+			e.publish(context.Background(), msg)
+		default:
+			return
+		}
+	}
+}
+
+func TestStreamExporterPublishesSignalsAndPredictions(t *testing.T) {
+	pub := &recordingPublisher{}
+	e := NewStreamExporter(pub, StreamSubjects{Signals: "quant.signals"}, 8)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return at }
+	signalID := int64(42)
+
+	_ = e.NotifySignals(context.Background(), []domain.Signal{{ID: 7, Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong}})
+	e.PublishPrediction(context.Background(), domain.MLPrediction{
+		ID:          9,
+		Symbol:      "ETH",
+		Interval:    "1h",
+		ModelKey:    "ensemble_v1",
+		ProbUp:      0.71,
+		Direction:   domain.DirectionLong,
+		SignalID:    &signalID,
+		DetailsJSON: `{"ensemble_score":0.42}`,
+	})
+	drain(e)
+
+	if len(pub.messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(pub.messages))
+	}
+	sig := pub.messages[0]
+	if sig.subject != "quant.signals" || sig.key != "BTC" || sig.event.Type != StreamSignal || sig.event.Signal.ID != 7 || !sig.event.At.Equal(at) {
+		t.Fatalf("unexpected signal message %+v", sig)
+	}
+	pred := pub.messages[1]
+	if pred.subject != DefaultPredictionsSubject || pred.key != "ETH" || pred.event.Type != StreamPrediction {
+		t.Fatalf("unexpected prediction message %+v", pred)
+	}
+	if p := pred.event.Prediction; p.ID != 9 || p.ProbUp != 0.71 || p.SignalID == nil || *p.SignalID != 42 || string(p.Details) != `{"ensemble_score":0.42}` {
+		t.Fatalf("unexpected prediction payload %+v", p)
+	}
+}
+
+func TestStreamExporterSkipsUnchangedReruns(t *testing.T) {
+	pub := &recordingPublisher{}
+	e := NewStreamExporter(pub, StreamSubjects{}, 8)
+	pred := domain.MLPrediction{ID: 1, Symbol: "BTC", Interval: "1h", ModelKey: "logreg", ModelVersion: 3, ProbUp: 0.6}
+
+	e.PublishPrediction(context.Background(), pred)
+	e.PublishPrediction(context.Background(), pred)
+	pred.ProbUp = 0.65
+	e.PublishPrediction(context.Background(), pred)
+	drain(e)
+
+	if len(pub.messages) != 2 {
+		t.Fatalf("expected the unchanged rerun skipped, got %d messages", len(pub.messages))
+	}
+}
+
+func TestStreamExporterRetriesAfterFailedPublish(t *testing.T) {
+	pub := &recordingPublisher{err: errors.New("broker down")}
+	e := NewStreamExporter(pub, StreamSubjects{}, 8)
+	sig := domain.Signal{Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorMACD}
+
+	e.PublishSignals(context.Background(), []domain.Signal{sig})
+	drain(e)
+	pub.err = nil
+	e.PublishSignals(context.Background(), []domain.Signal{sig})
+	drain(e)
+
+	if len(pub.messages) != 1 {
+		t.Fatalf("expected the signal published once the broker is back, got %d", len(pub.messages))
+	}
+}
+
+func TestStreamExporterDropsWhenQueueFull(t *testing.T) {
+	e := NewStreamExporter(&recordingPublisher{}, StreamSubjects{}, 1)
+	e.PublishSignals(context.Background(), []domain.Signal{{Symbol: "BTC"}, {Symbol: "ETH"}})
+	if len(e.queue) != 1 {
+		t.Fatalf("expected one queued message, got %d", len(e.queue))
+	}
+}
+
+func TestStreamExporterRunClosesPublisher(t *testing.T) {
+	pub := &recordingPublisher{}
+	e := NewStreamExporter(pub, StreamSubjects{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not stop")
+	}
+	if !pub.closed {
+		t.Fatal("expected publisher closed")
+	}
+}
+
+func TestNewStreamPublisher(t *testing.T) {
+	if _, err := NewStreamPublisher("nats", "nats://localhost:4222"); err != nil {
+		t.Fatalf("unexpected nats error: %v", err)
+	}
+	if _, err := NewStreamPublisher("kafka", "http://localhost:8082"); err != nil {
+		t.Fatalf("unexpected kafka error: %v", err)
+	}
+	if _, err := NewStreamPublisher("rabbitmq", "amqp://localhost"); err == nil {
+		t.Fatal("expected unknown backend rejected")
+	}
+}
@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/internal/workqueue"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Broadcaster interface {
+	Broadcast(ctx context.Context, message string) (int, error)
+}
+
+// AlertDeadLetters exposes alert deliveries that could not be sent.
+type AlertDeadLetters interface {
+	ListDeadLetters(ctx context.Context, limit int) ([]domain.AlertDelivery, error)
+	Redeliver(ctx context.Context, id int64) (*domain.AlertDelivery, error)
+}
+
+// CandleIntegrityReporter reports how often stored candles differ from the
+// price provider.
+type CandleIntegrityReporter interface {
+	Report(ctx context.Context) (*service.CandleIntegrityReport, error)
+}
+
+// PriceDiscrepancyReader lists the prices the price quorum found out of line.
+type PriceDiscrepancyReader interface {
+	Discrepancies(ctx context.Context, symbol string, window time.Duration, limit int) ([]domain.PriceDiscrepancy, error)
+}
+
+// WorkQueueStats reports the work queue's backlog.
+type WorkQueueStats interface {
+	Stats(ctx context.Context) (workqueue.Stats, error)
+}
+
+const (
+	maxDiscrepancyHours = 24 * 30
+	discrepancyLimit    = 200
+)
+
+type createAPIKeyRequest struct {
+	TenantID string `json:"tenant_id"`
+	Label    string `json:"label"`
+}
+
+type broadcastRequest struct {
+	Message string `json:"message"`
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys for a tenant
+// @Description  Returns issued API keys (never the raw key) for a tenant
+// @Tags         admin
+// @Produce      json
+// @Param        tenant  query  string  false  "Tenant ID (default: default)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/api-keys [get]
+func (h *Handler) ListAPIKeys(c *gin.Context) {
+	if h.apiKeyService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "api key service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.list-api-keys")
+	defer span.End()
+
+	keys, err := h.apiKeyService.List(ctx, domain.NormalizeTenantID(c.Query("tenant")))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey godoc
+// @Summary      Issue an API key
+// @Description  Issues a new API key bound to a tenant. The raw key is only returned once.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  createAPIKeyRequest  true  "Tenant and label"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/api-keys [post]
+func (h *Handler) CreateAPIKey(c *gin.Context) {
+	if h.apiKeyService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "api key service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.create-api-key")
+	defer span.End()
+
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	rawKey, key, err := h.apiKeyService.Issue(ctx, domain.NormalizeTenantID(req.TenantID), req.Label)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"api_key": key, "key": rawKey})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Tags         admin
+// @Produce      json
+// @Param        id  path  int  true  "API key ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(c *gin.Context) {
+	if h.apiKeyService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "api key service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.revoke-api-key")
+	defer span.End()
+
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+		return
+	}
+	if err := h.apiKeyService.Revoke(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
+
+// SendBroadcast godoc
+// @Summary      Broadcast a message to alert subscribers
+// @Description  Sends an operator message to every Telegram chat with alerts enabled
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  broadcastRequest  true  "Message"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/broadcast [post]
+func (h *Handler) SendBroadcast(c *gin.Context) {
+	if h.broadcaster == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "broadcast unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.send-broadcast")
+	defer span.End()
+
+	var req broadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Message) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message is required"})
+		return
+	}
+
+	delivered, sendErr := h.broadcaster.Broadcast(ctx, req.Message)
+	after := gin.H{"message": strings.TrimSpace(req.Message), "delivered": delivered}
+	if sendErr != nil {
+		after["error"] = sendErr.Error()
+	}
+	if err := h.auditService.Record(ctx, domain.AuditActionBroadcastSend, domain.AuditEntityBroadcast, "", nil, after); err != nil {
+		span.RecordError(err)
+	}
+	if sendErr != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": sendErr.Error(), "delivered": delivered})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "delivered": delivered})
+}
+
+// ListAlertDeadLetters godoc
+// @Summary      List undeliverable alerts
+// @Description  Returns Telegram alert deliveries that failed permanently or exhausted their retries
+// @Tags         admin
+// @Produce      json
+// @Param        limit  query  int  false  "Number of deliveries (default 50, max 500)"  default(50)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/alerts/dead-letters [get]
+func (h *Handler) ListAlertDeadLetters(c *gin.Context) {
+	if h.alertDeadLetters == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "alert delivery tracking unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.list-alert-dead-letters")
+	defer span.End()
+
+	limit := 50
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.alertDeadLetters.ListDeadLetters(ctx, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"dead_letters": deliveries})
+}
+
+// RedeliverAlert godoc
+// @Summary      Redeliver an undeliverable alert
+// @Description  Requeues a dead-lettered alert with a fresh retry budget and sends it immediately
+// @Tags         admin
+// @Produce      json
+// @Param        id  path  int  true  "Alert delivery ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      502  {object}  map[string]interface{}
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/alerts/dead-letters/{id}/redeliver [post]
+func (h *Handler) RedeliverAlert(c *gin.Context) {
+	if h.alertDeadLetters == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "alert delivery tracking unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.redeliver-alert")
+	defer span.End()
+
+	rawID := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+		return
+	}
+
+	delivery, sendErr := h.alertDeadLetters.Redeliver(ctx, id)
+	if delivery == nil {
+		if sendErr != nil {
+			respondError(c, sendErr)
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "dead letter not found"})
+		return
+	}
+
+	after := gin.H{"status": delivery.Status}
+	if sendErr != nil {
+		after["error"] = sendErr.Error()
+	}
+	if err := h.auditService.Record(ctx, domain.AuditActionAlertRedeliver, domain.AuditEntityAlertDelivery, rawID, nil, after); err != nil {
+		span.RecordError(err)
+	}
+	if sendErr != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": sendErr.Error(), "delivery": delivery})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"delivery": delivery})
+}
+
+// GetCandleIntegrity godoc
+// @Summary      Report candle integrity checks
+// @Description  Returns, per symbol and interval, how many sampled stored candles were re-fetched from the price provider over the last week and how many differed, with the latest checks that found differences
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/candle-integrity [get]
+func (h *Handler) GetCandleIntegrity(c *gin.Context) {
+	if h.candleIntegrity == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "candle integrity checks unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-candle-integrity")
+	defer span.End()
+
+	report, err := h.candleIntegrity.Report(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetPriceDiscrepancies godoc
+// @Summary      List price quorum discrepancies
+// @Description  Returns, newest first, the provider prices that strayed from the quorum of providers fetched in the same refresh by more than PRICE_QUORUM_DEVIATION_PCT, with every provider's quote. rejected marks PRICE_PROVIDER prices that were replaced by the quorum price. At most 200 are returned.
+// @Tags         admin
+// @Produce      json
+// @Param        hours   query  int     false  "Look back this many hours (default 24, max 720)"
+// @Param        symbol  query  string  false  "Only this symbol"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/price-discrepancies [get]
+func (h *Handler) GetPriceDiscrepancies(c *gin.Context) {
+	if h.priceDiscrepancies == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "price quorum unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-price-discrepancies")
+	defer span.End()
+
+	hours := 24
+	if raw := strings.TrimSpace(c.Query("hours")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxDiscrepancyHours {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be between 1 and 720"})
+			return
+		}
+		hours = n
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+
+	list, err := h.priceDiscrepancies.Discrepancies(ctx, symbol, time.Duration(hours)*time.Hour, discrepancyLimit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"discrepancies": list})
+}
+
+// GetWorkQueue godoc
+// @Summary      Work queue backlog
+// @Description  Returns the Redis stream behind the work queue (WORK_QUEUE_ENABLED), which runs chart renders and Telegram alerts: tasks in the stream, tasks delivered but not yet acknowledged in total and by consumer, and tasks moved to the dead-letter stream after WORK_QUEUE_MAX_DELIVERIES deliveries.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/work-queue [get]
+func (h *Handler) GetWorkQueue(c *gin.Context) {
+	if h.workQueue == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "work queue unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-work-queue")
+	defer span.End()
+
+	stats, err := h.workQueue.Stats(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
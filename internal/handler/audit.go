@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditLog godoc
+// @Summary      List audit log entries
+// @Description  Returns append-only audit entries for admin operations, newest first. Non-default tenants only see their own entries.
+// @Tags         admin
+// @Produce      json
+// @Param        from         query  string  false  "Start time (RFC3339, inclusive)"
+// @Param        to           query  string  false  "End time (RFC3339, exclusive)"
+// @Param        action       query  string  false  "Action (e.g. model.activate, api_key.create, broadcast.send)"
+// @Param        entity_type  query  string  false  "Entity type (ml_model, symbol, broadcast, api_key)"
+// @Param        actor        query  string  false  "Actor"
+// @Param        limit        query  int     false  "Number of entries (default 100, max 500)"  default(100)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/audit [get]
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	if h.auditService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "audit service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-audit-log")
+	defer span.End()
+
+	filter := domain.AuditFilter{
+		Action:     strings.TrimSpace(c.Query("action")),
+		EntityType: strings.TrimSpace(c.Query("entity_type")),
+		Actor:      strings.TrimSpace(c.Query("actor")),
+	}
+	if tenantID := TenantFromGin(c); tenantID != domain.DefaultTenantID {
+		filter.TenantID = tenantID
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := strings.TrimSpace(c.Query(param.name))
+		if raw == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param.name + " must be an RFC3339 timestamp"})
+			return
+		}
+		ts = ts.UTC()
+		*param.dst = &ts
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	filter.Limit = 100
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		n, err := strconv.Atoi(rawLimit)
+		if err != nil || n <= 0 || n > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := h.auditService.List(ctx, filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/internal/workqueue"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGetAuditLogParsesTimeFilters(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerAuditStoreStub{}
+	h := &Handler{tracer: tracer}
+	h.SetAuditService(service.NewAuditService(tracer, store))
+
+	router := gin.New()
+	router.GET("/api/audit", h.GetAuditLog)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/audit?from=2026-01-01T00:00:00Z&to=2026-01-02T00:00:00Z&action=model.activate&limit=5", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.filter.From == nil || store.filter.To == nil || store.filter.Action != "model.activate" || store.filter.Limit != 5 {
+		t.Fatalf("unexpected filter: %+v", store.filter)
+	}
+	if store.filter.TenantID != "" {
+		t.Fatalf("expected operator to see all tenants, got %q", store.filter.TenantID)
+	}
+}
+
+func TestGetAuditLogScopesNonDefaultTenant(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerAuditStoreStub{}
+	h := &Handler{tracer: tracer}
+	h.SetAuditService(service.NewAuditService(tracer, store))
+
+	router := gin.New()
+	router.Use(TenantAPIKeyAuth(TenantAuthConfig{StaticKeys: map[string]string{"k": "acme"}}))
+	router.GET("/api/audit", h.GetAuditLog)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/audit", nil)
+	req.Header.Set("X-API-Key", "k")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || store.filter.TenantID != "acme" {
+		t.Fatalf("expected acme-scoped query, got %d %+v", w.Code, store.filter)
+	}
+}
+
+func TestGetAuditLogRejectsBadTimestamp(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	h.SetAuditService(service.NewAuditService(tracer, &handlerAuditStoreStub{}))
+
+	router := gin.New()
+	router.GET("/api/audit", h.GetAuditLog)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/audit?from=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestSendBroadcastRecordsAudit(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerAuditStoreStub{}
+	h := &Handler{tracer: tracer}
+	h.SetAuditService(service.NewAuditService(tracer, store))
+	h.SetBroadcaster(broadcasterStub{delivered: 3})
+
+	router := gin.New()
+	router.POST("/api/admin/broadcast", h.SendBroadcast)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/broadcast", bytes.NewBufferString(`{"message":"hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if len(store.entries) != 1 || store.entries[0].Action != domain.AuditActionBroadcastSend {
+		t.Fatalf("expected broadcast audit entry, got %+v", store.entries)
+	}
+}
+
+func TestAdminRoutesRequireOperator(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	h.SetBroadcaster(broadcasterStub{err: errors.New("should not be called")})
+
+	router := gin.New()
+	protected := router.Group("")
+	protected.Use(TenantAPIKeyAuth(TenantAuthConfig{StaticKeys: map[string]string{"k": "acme"}}))
+	h.RegisterRoutes(protected)
+
+	for _, path := range []string{
+		"/api/admin/broadcast",
+		"/api/signals/generate",
+		"/api/ml/infer-at",
+		"/api/ml/train",
+		"/api/market-intel/run",
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(`{"message":"hello"}`))
+		req.Header.Set("X-API-Key", "k")
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403 for tenant key on %s, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestRedeliverAlertRecordsAudit(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerAuditStoreStub{}
+	letters := &deadLettersStub{delivery: &domain.AlertDelivery{ID: 7, ChatID: 10, Status: domain.AlertDeliverySent}}
+	h := &Handler{tracer: tracer}
+	h.SetAuditService(service.NewAuditService(tracer, store))
+	h.SetAlertDeadLetters(letters)
+
+	router := gin.New()
+	router.POST("/api/admin/alerts/dead-letters/:id/redeliver", h.RedeliverAlert)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/alerts/dead-letters/7/redeliver", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if letters.lastID != 7 {
+		t.Fatalf("expected redelivery of 7, got %d", letters.lastID)
+	}
+	if len(store.entries) != 1 || store.entries[0].Action != domain.AuditActionAlertRedeliver || store.entries[0].EntityID != "7" {
+		t.Fatalf("expected redeliver audit entry, got %+v", store.entries)
+	}
+
+	letters.delivery = nil
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/alerts/dead-letters/8/redeliver", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown dead letter, got %d", w.Code)
+	}
+}
+
+func TestListAlertDeadLetters(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	letters := &deadLettersStub{dead: []domain.AlertDelivery{{ID: 1, Status: domain.AlertDeliveryDead}}}
+	h := &Handler{tracer: tracer}
+
+	router := gin.New()
+	router.GET("/api/admin/alerts/dead-letters", h.ListAlertDeadLetters)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/alerts/dead-letters", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without delivery tracking, got %d", w.Code)
+	}
+
+	h.SetAlertDeadLetters(letters)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/alerts/dead-letters?limit=5", nil))
+	if w.Code != http.StatusOK || letters.lastLimit != 5 {
+		t.Fatalf("expected 200 with limit 5, got %d limit=%d", w.Code, letters.lastLimit)
+	}
+}
+
+func TestGetCandleIntegrity(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/admin/candle-integrity", h.GetCandleIntegrity)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/candle-integrity", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without integrity checks, got %d", w.Code)
+	}
+
+	h.SetCandleIntegrity(integrityReporterStub{report: &service.CandleIntegrityReport{
+		Rates: []domain.CandleIntegrityRate{{Symbol: "BTC", Interval: "1h", Checks: 3, Compared: 60, Mismatched: 3, MismatchRate: 0.05}},
+	}})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/candle-integrity", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"mismatch_rate":0.05`) {
+		t.Fatalf("expected the report, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetPriceDiscrepancies(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/admin/price-discrepancies", h.GetPriceDiscrepancies)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/price-discrepancies", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a price quorum, got %d", w.Code)
+	}
+
+	reader := &discrepancyReaderStub{list: []domain.PriceDiscrepancy{{Symbol: "BTC", Provider: "kraken", DeviationPct: 3.5}}}
+	h.SetPriceDiscrepancies(reader)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/price-discrepancies?hours=48&symbol=btc", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"deviation_pct":3.5`) {
+		t.Fatalf("expected the discrepancies, got %d %s", w.Code, w.Body.String())
+	}
+	if reader.symbol != "BTC" || reader.window != 48*time.Hour || reader.limit != discrepancyLimit {
+		t.Fatalf("unexpected query %+v", reader)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/price-discrepancies?hours=1000", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 beyond 30 days, got %d", w.Code)
+	}
+}
+
+func TestGetWorkQueue(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/admin/work-queue", h.GetWorkQueue)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/work-queue", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a work queue, got %d", w.Code)
+	}
+
+	h.SetWorkQueue(workQueueStub{stats: workqueue.Stats{Stream: "work-queue", Pending: 3, Dead: 1}})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/work-queue", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"pending":3`) || !strings.Contains(w.Body.String(), `"dead_letters":1`) {
+		t.Fatalf("expected the queue stats, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+type workQueueStub struct {
+	stats workqueue.Stats
+}
+
+func (s workQueueStub) Stats(context.Context) (workqueue.Stats, error) {
+	return s.stats, nil
+}
+
+type discrepancyReaderStub struct {
+	list   []domain.PriceDiscrepancy
+	symbol string
+	window time.Duration
+	limit  int
+}
+
+func (s *discrepancyReaderStub) Discrepancies(_ context.Context, symbol string, window time.Duration, limit int) ([]domain.PriceDiscrepancy, error) {
+	s.symbol, s.window, s.limit = symbol, window, limit
+	return s.list, nil
+}
+
+type integrityReporterStub struct {
+	report *service.CandleIntegrityReport
+}
+
+func (s integrityReporterStub) Report(context.Context) (*service.CandleIntegrityReport, error) {
+	return s.report, nil
+}
+
+type deadLettersStub struct {
+	dead      []domain.AlertDelivery
+	delivery  *domain.AlertDelivery
+	lastID    int64
+	lastLimit int
+}
+
+func (s *deadLettersStub) ListDeadLetters(ctx context.Context, limit int) ([]domain.AlertDelivery, error) {
+	s.lastLimit = limit
+	return s.dead, nil
+}
+
+func (s *deadLettersStub) Redeliver(ctx context.Context, id int64) (*domain.AlertDelivery, error) {
+	s.lastID = id
+	return s.delivery, nil
+}
+
+type handlerAuditStoreStub struct {
+	entries []domain.AuditEntry
+	filter  domain.AuditFilter
+}
+
+func (s *handlerAuditStoreStub) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) (*domain.AuditEntry, error) {
+	s.entries = append(s.entries, entry)
+	return &entry, nil
+}
+
+func (s *handlerAuditStoreStub) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	s.filter = filter
+	return s.entries, nil
+}
+
+type broadcasterStub struct {
+	delivered int
+	err       error
+}
+
+func (b broadcasterStub) Broadcast(ctx context.Context, message string) (int, error) {
+	return b.delivered, b.err
+}
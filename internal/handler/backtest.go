@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/internal/storage"
+	"bug-free-umbrella/pkg/domain"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -27,7 +32,7 @@ func (h *Handler) GetBacktestSummary(c *gin.Context) {
 
 	summary, err := h.backtestService.GetSummary(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"summary": summary})
@@ -65,7 +70,7 @@ func (h *Handler) GetBacktestDaily(c *gin.Context) {
 
 	daily, err := h.backtestService.GetDaily(ctx, model, days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"daily": daily})
@@ -101,8 +106,277 @@ func (h *Handler) GetBacktestPredictions(c *gin.Context) {
 
 	preds, err := h.backtestService.GetPredictions(ctx, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"predictions": preds})
 }
+
+// GetBacktestRisk godoc
+// @Summary      Get ML performance by risk level
+// @Description  Returns hit rate and average directional return grouped by model and risk level
+// @Tags         backtest
+// @Produce      json
+// @Param        days  query  int  false  "Days of history; all time when omitted"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/backtest/risk [get]
+func (h *Handler) GetBacktestRisk(c *gin.Context) {
+	if h.backtestService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-backtest-risk")
+	defer span.End()
+
+	days := 0
+	if rawDays := strings.TrimSpace(c.Query("days")); rawDays != "" {
+		n, err := strconv.Atoi(rawDays)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		days = n
+	}
+
+	stats, err := h.backtestService.GetRiskStats(ctx, days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if stats == nil {
+		stats = []domain.RiskLevelStats{}
+	}
+	c.JSON(http.StatusOK, gin.H{"risk": stats})
+}
+
+// GetBacktestCoverage godoc
+// @Summary      Get ML directional coverage
+// @Description  Returns per-day counts of directional calls and holds by model, with the accuracy of directional calls alone
+// @Tags         backtest
+// @Produce      json
+// @Param        model  query  string  false  "Model key; all models when empty"
+// @Param        days   query  int     false  "Days of history" default(30)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/backtest/coverage [get]
+func (h *Handler) GetBacktestCoverage(c *gin.Context) {
+	if h.backtestService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-backtest-coverage")
+	defer span.End()
+
+	model := strings.TrimSpace(c.Query("model"))
+	days := 30
+	if rawDays := strings.TrimSpace(c.Query("days")); rawDays != "" {
+		n, err := strconv.Atoi(rawDays)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a positive integer"})
+			return
+		}
+		days = n
+	}
+
+	coverage, err := h.backtestService.GetCoverage(ctx, model, days)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if coverage == nil {
+		coverage = []domain.CoverageDay{}
+	}
+	c.JSON(http.StatusOK, gin.H{"coverage": coverage})
+}
+
+// calibrationParams reads model_key and buckets, writing a 400 when buckets
+// is invalid.
+func calibrationParams(c *gin.Context) (modelKey string, buckets int, ok bool) {
+	modelKey = strings.TrimSpace(c.Query("model_key"))
+	buckets = domain.DefaultCalibrationBuckets
+	if raw := strings.TrimSpace(c.Query("buckets")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 2 || n > 20 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "buckets must be between 2 and 20"})
+			return "", 0, false
+		}
+		buckets = n
+	}
+	return modelKey, buckets, true
+}
+
+// GetMLCalibration godoc
+// @Summary      Get ML calibration
+// @Description  Returns predicted-probability vs realized-frequency buckets from resolved predictions
+// @Tags         ml
+// @Produce      json
+// @Param        model_key  query  string  false  "Model key; all models when empty"
+// @Param        buckets    query  int     false  "Number of probability buckets" default(10)
+// @Success      200  {object}  domain.Calibration
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/calibration [get]
+func (h *Handler) GetMLCalibration(c *gin.Context) {
+	if h.backtestService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-ml-calibration")
+	defer span.End()
+
+	modelKey, buckets, ok := calibrationParams(c)
+	if !ok {
+		return
+	}
+
+	cal, err := h.backtestService.GetCalibration(ctx, modelKey, buckets)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if cal.Buckets == nil {
+		cal.Buckets = []domain.CalibrationBucket{}
+	}
+	c.JSON(http.StatusOK, cal)
+}
+
+// GetMLCalibrationImage godoc
+// @Summary      Get ML calibration chart
+// @Description  Returns the reliability chart for resolved predictions as a PNG
+// @Tags         ml
+// @Produce      png
+// @Param        model_key  query  string  false  "Model key; all models when empty"
+// @Param        buckets    query  int     false  "Number of probability buckets" default(10)
+// @Success      200  {file}  binary
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/calibration/image [get]
+func (h *Handler) GetMLCalibrationImage(c *gin.Context) {
+	if h.backtestService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-ml-calibration-image")
+	defer span.End()
+
+	modelKey, buckets, ok := calibrationParams(c)
+	if !ok {
+		return
+	}
+
+	cal, err := h.backtestService.GetCalibration(ctx, modelKey, buckets)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if cal.Total == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no resolved predictions"})
+		return
+	}
+
+	png, err := h.backtestService.RenderCalibration(cal)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// GetMLAnomalies godoc
+// @Summary      Get anomaly intensity over time
+// @Description  Returns the isolation forest score series summarised per symbol and UTC day, with the strongest damping applied to ensemble predictions that day
+// @Tags         ml
+// @Produce      json
+// @Param        days      query  int     false  "Days of history (default 14, max 90)"  default(14)
+// @Param        interval  query  string  false  "Candle interval; all intervals when omitted"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/anomalies [get]
+func (h *Handler) GetMLAnomalies(c *gin.Context) {
+	if h.backtestService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-ml-anomalies")
+	defer span.End()
+
+	days := 14
+	if rawDays := strings.TrimSpace(c.Query("days")); rawDays != "" {
+		n, err := strconv.Atoi(rawDays)
+		if err != nil || n <= 0 || n > 90 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = n
+	}
+	interval := strings.TrimSpace(c.Query("interval"))
+	if interval != "" && domain.IntervalDuration(interval) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":               "unsupported interval: " + interval,
+			"supported_intervals": domain.SupportedIntervals,
+		})
+		return
+	}
+
+	cells, err := h.backtestService.GetAnomalyHeat(ctx, days, interval)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if cells == nil {
+		cells = []domain.AnomalyCell{}
+	}
+	c.JSON(http.StatusOK, gin.H{"days": days, "interval": interval, "cells": cells})
+}
+
+// GetPredictionBreakdown godoc
+// @Summary      Get ensemble prediction breakdown
+// @Description  Returns each component's raw value, weight and contribution to an ensemble prediction's score: classic, logreg, xgboost, sentiment (Fear & Greed, when known) and anomaly_damp. The contributions sum to the score. Predictions stored before components were recorded, and single-model predictions, return 422.
+// @Tags         backtest
+// @Produce      json
+// @Param        id  path  int  true  "Prediction ID"
+// @Success      200  {object}  service.PredictionBreakdown
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      422  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/predictions/{id}/breakdown [get]
+func (h *Handler) GetPredictionBreakdown(c *gin.Context) {
+	if h.backtestService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "backtest service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-prediction-breakdown")
+	defer span.End()
+
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+		return
+	}
+
+	breakdown, err := h.backtestService.GetPredictionBreakdown(ctx, id)
+	switch {
+	case storage.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "prediction not found"})
+		return
+	case errors.Is(err, service.ErrBreakdownUnavailable):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
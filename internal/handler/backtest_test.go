@@ -7,11 +7,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/repository"
 	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -29,6 +30,43 @@ func (backtestRepoForHandler) ListRecentPredictions(ctx context.Context, limit i
 	return []domain.MLPrediction{{ModelKey: "ml_logreg_up4h", Symbol: "BTC"}}, nil
 }
 
+func (backtestRepoForHandler) GetCalibration(ctx context.Context, modelKey string, buckets int) ([]domain.CalibrationBucket, error) {
+	if modelKey == "unused" {
+		return nil, nil
+	}
+	return []domain.CalibrationBucket{
+		{Lower: 0.5, Upper: 0.6, Count: 8, MeanPredicted: 0.55, RealizedFrequency: 0.5},
+	}, nil
+}
+
+func (backtestRepoForHandler) GetRiskLevelStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error) {
+	return []domain.RiskLevelStats{{ModelKey: "ml_logreg_up4h", Risk: domain.RiskLevel2, Total: 10, Correct: 7, HitRate: 0.7}}, nil
+}
+
+func (backtestRepoForHandler) GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error) {
+	return []domain.AnomalyCell{{Symbol: "BTC", Samples: 24, MaxScore: 0.8, MeanScore: 0.4, MinDampFactor: 0.48}}, nil
+}
+
+func (backtestRepoForHandler) GetDailyCoverage(ctx context.Context, modelKey string, days int) ([]domain.CoverageDay, error) {
+	return []domain.CoverageDay{{ModelKey: "ml_ensemble_up4h", Total: 24, Directional: 6, Holds: 18, Coverage: 0.25, DirectionalAccuracy: 0.8}}, nil
+}
+
+func (backtestRepoForHandler) GetPrediction(ctx context.Context, id int64) (*domain.MLPrediction, error) {
+	switch id {
+	case 1:
+		return &domain.MLPrediction{ID: 1, ModelKey: "ensemble_v1", DetailsJSON: `{"classic_score":0.4,"logreg_prob":0.65,"xgboost_prob":0.7}`}, nil
+	case 2:
+		return &domain.MLPrediction{ID: 2, ModelKey: "ml_logreg_up4h", DetailsJSON: `{}`}, nil
+	}
+	return nil, pgx.ErrNoRows
+}
+
+type calibrationRendererForHandler struct{}
+
+func (calibrationRendererForHandler) RenderCalibrationChart(cal domain.Calibration) ([]byte, error) {
+	return []byte("\x89PNG"), nil
+}
+
 func TestGetBacktestSummary(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
@@ -51,3 +89,182 @@ func TestGetBacktestSummary(t *testing.T) {
 		t.Fatalf("expected summary field")
 	}
 }
+
+func TestGetMLCalibration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer, backtestService: service.NewBacktestService(tracer, backtestRepoForHandler{})}
+	r := gin.New()
+	r.GET("/api/ml/calibration", h.GetMLCalibration)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/calibration?model_key=ensemble_v1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var payload domain.Calibration
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if payload.ModelKey != "ensemble_v1" || payload.Total != 8 || len(payload.Buckets) != 1 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/calibration?buckets=50", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestGetMLCalibrationImage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	svc := service.NewBacktestService(tracer, backtestRepoForHandler{})
+	h := &Handler{tracer: tracer, backtestService: svc}
+	r := gin.New()
+	r.GET("/api/ml/calibration/image", h.GetMLCalibrationImage)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/calibration/image", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a renderer, got %d", w.Code)
+	}
+
+	svc.SetCalibrationRenderer(calibrationRendererForHandler{})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/calibration/image", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("expected png, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/calibration/image?model_key=unused", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 without predictions, got %d", w.Code)
+	}
+}
+
+func TestGetBacktestRisk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer, backtestService: service.NewBacktestService(tracer, backtestRepoForHandler{})}
+	r := gin.New()
+	r.GET("/api/backtest/risk", h.GetBacktestRisk)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/backtest/risk?days=30", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var payload struct {
+		Risk []domain.RiskLevelStats `json:"risk"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(payload.Risk) != 1 || payload.Risk[0].HitRate != 0.7 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/backtest/risk?days=abc", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestGetBacktestCoverage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer, backtestService: service.NewBacktestService(tracer, backtestRepoForHandler{})}
+	r := gin.New()
+	r.GET("/api/backtest/coverage", h.GetBacktestCoverage)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/backtest/coverage?model=ml_ensemble_up4h&days=7", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var payload struct {
+		Coverage []domain.CoverageDay `json:"coverage"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(payload.Coverage) != 1 || payload.Coverage[0].Coverage != 0.25 || payload.Coverage[0].Holds != 18 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/backtest/coverage?days=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestGetMLAnomalies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer, backtestService: service.NewBacktestService(tracer, backtestRepoForHandler{})}
+	r := gin.New()
+	r.GET("/api/ml/anomalies", h.GetMLAnomalies)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/anomalies?days=7&interval=1h", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var payload struct {
+		Days     int                  `json:"days"`
+		Interval string               `json:"interval"`
+		Cells    []domain.AnomalyCell `json:"cells"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if payload.Days != 7 || payload.Interval != "1h" || len(payload.Cells) != 1 || payload.Cells[0].MaxScore != 0.8 {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	for _, q := range []string{"days=0", "days=91", "interval=2h"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/anomalies?"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", q, w.Code)
+		}
+	}
+}
+
+func TestGetPredictionBreakdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer, backtestService: service.NewBacktestService(tracer, backtestRepoForHandler{})}
+	r := gin.New()
+	r.GET("/api/predictions/:id/breakdown", h.GetPredictionBreakdown)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/predictions/1/breakdown", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var payload service.PredictionBreakdown
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if payload.Prediction.ID != 1 || len(payload.Components) != 4 || payload.Components[0].Component != "classic" {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	for path, want := range map[string]int{
+		"/api/predictions/abc/breakdown": http.StatusBadRequest,
+		"/api/predictions/2/breakdown":   http.StatusUnprocessableEntity,
+		"/api/predictions/9/breakdown":   http.StatusNotFound,
+	} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type ingestCandlesRequest struct {
+	Candles []domain.Candle `json:"candles"`
+}
+
+// IngestCandles godoc
+// @Summary      Push OHLCV candles from an external collector
+// @Description  Stores candles for symbols the built-in price provider does not track, on the supported intervals (5m, 15m, 1h, 4h, 1d). open_time must be aligned to the interval and not in the future, prices positive with high and low bounding open and close. A batch holds at most 1000 candles and is rejected as a whole if any candle is invalid. Candles already stored with the same values count as duplicates. Newly closed candles trigger signal generation and ML inference like polled ones.
+// @Tags         prices
+// @Accept       json
+// @Produce      json
+// @Param        body  body  ingestCandlesRequest  true  "Candles to store"
+// @Success      200  {object}  service.CandleIngestResult
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/candles/ingest [post]
+func (h *Handler) IngestCandles(c *gin.Context) {
+	if h.candleIngest == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "candle ingest unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.ingest-candles")
+	defer span.End()
+
+	var req ingestCandlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	span.SetAttributes(attribute.Int("candles", len(req.Candles)))
+
+	result, err := h.candleIngest.Ingest(ctx, req.Candles)
+	if errors.Is(err, service.ErrInvalidCandles) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type candleIngestStoreStub struct {
+	candles []*domain.Candle
+}
+
+func (s *candleIngestStoreStub) IngestCandles(ctx context.Context, candles []*domain.Candle) (int, error) {
+	s.candles = append(s.candles, candles...)
+	return len(candles), nil
+}
+
+func TestIngestCandles(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.POST("/api/candles/ingest", h.IngestCandles)
+	do := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/candles/ingest", bytes.NewBufferString(body)))
+		return w
+	}
+
+	if w := do(`{"candles":[]}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an ingest service, got %d", w.Code)
+	}
+
+	store := &candleIngestStoreStub{}
+	h.SetCandleIngestService(service.NewCandleIngestService(tracer, store))
+
+	if w := do(`{"candles":`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a malformed body, got %d", w.Code)
+	}
+	if w := do(`{"candles":[{"symbol":"BTC","interval":"1h","open_time":"2026-03-01T01:00:00Z","open":1,"high":2,"low":0.5,"close":1.5,"volume":3}]}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a tracked symbol, got %d", w.Code)
+	}
+
+	w := do(`{"candles":[
+		{"symbol":"PEPE","interval":"1h","open_time":"2026-03-01T01:00:00Z","open":1,"high":2,"low":0.5,"close":1.5,"volume":3},
+		{"symbol":"PEPE","interval":"1h","open_time":"2026-03-01T01:00:00Z","open":1,"high":2,"low":0.5,"close":1.5,"volume":3}
+	]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result service.CandleIngestResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if result.Received != 2 || result.Stored != 1 || result.Duplicates != 1 || len(store.candles) != 1 {
+		t.Fatalf("unexpected result %+v stored=%d", result, len(store.candles))
+	}
+}
+
+func TestIngestCandlesRequiresOperator(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &candleIngestStoreStub{}
+	h := &Handler{tracer: tracer}
+	h.SetCandleIngestService(service.NewCandleIngestService(tracer, store))
+
+	router := gin.New()
+	protected := router.Group("")
+	protected.Use(TenantAPIKeyAuth(TenantAuthConfig{StaticKeys: map[string]string{"k": "acme"}}))
+	h.RegisterRoutes(protected)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/candles/ingest", bytes.NewBufferString(`{"candles":[]}`))
+	req.Header.Set("X-API-Key", "k")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for tenant key, got %d", w.Code)
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetConfig godoc
+// @Summary      Effective runtime configuration
+// @Description  Returns every setting with its effective value, its default and its source (env, file, secrets or default). Secrets are redacted and URL passwords masked. Compare the changed=true output of two deployments to find config drift.
+// @Tags         admin
+// @Produce      json
+// @Param        changed  query  bool  false  "Only settings that differ from their default"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/config [get]
+func (h *Handler) GetConfig(c *gin.Context) {
+	if h.configSettings == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "config report unavailable"})
+		return
+	}
+	settings := h.configSettings
+	if c.Query("changed") == "true" {
+		settings = make([]domain.ConfigSetting, 0, len(h.configSettings))
+		for _, s := range h.configSettings {
+			if s.Changed {
+				settings = append(settings, s)
+			}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"settings": settings})
+}
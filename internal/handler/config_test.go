@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGetConfig(t *testing.T) {
+	h := &Handler{tracer: trace.NewNoopTracerProvider().Tracer("handler-test")}
+	router := gin.New()
+	router.GET("/api/admin/config", h.GetConfig)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/api/admin/config"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a config report, got %d", w.Code)
+	}
+
+	h.SetEffectiveConfig([]domain.ConfigSetting{
+		{Key: "COINGECKO_POLL_SECS", Value: 30, Default: 60, Source: "env", Changed: true},
+		{Key: "ML_TARGET_HOURS", Value: 4, Default: 4, Source: "default"},
+	})
+	for path, want := range map[string]int{"/api/admin/config": 2, "/api/admin/config?changed=true": 1} {
+		w := get(path)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", path, w.Code)
+		}
+		var body struct {
+			Settings []domain.ConfigSetting `json:"settings"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("parse error: %v", err)
+		}
+		if len(body.Settings) != want {
+			t.Fatalf("%s: expected %d settings, got %d", path, want, len(body.Settings))
+		}
+	}
+}
@@ -1,20 +1,48 @@
 package handler
 
 import (
+	"time"
+
 	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
 )
 
 type Handler struct {
-	tracer            trace.Tracer
-	workService       *service.WorkService
-	priceService      *service.PriceService
-	signalService     *service.SignalService
-	backtestService   *service.BacktestService
-	mlTrainer         MLTrainingRunner
-	marketIntelRunner MarketIntelRunner
+	tracer             trace.Tracer
+	workService        *service.WorkService
+	priceService       *service.PriceService
+	signalService      *service.SignalService
+	signalGenerator    SignalBatchGenerator
+	riskMapper         SignalRiskMapper
+	backtestService    *service.BacktestService
+	mlTrainer          MLTrainingRunner
+	marketIntelRunner  MarketIntelRunner
+	marketEvents       MarketEventCalendar
+	auditService       *service.AuditService
+	apiKeyService      *service.APIKeyService
+	webhookService     *service.WebhookService
+	broadcaster        Broadcaster
+	alertDeadLetters   AlertDeadLetters
+	scheduleService    *service.ScheduleService
+	overviewService    *service.OverviewService
+	candleIngest       *service.CandleIngestService
+	seasonality        *service.SeasonalityService
+	maintenance        *service.MaintenanceService
+	symbolRegistry     *service.SymbolRegistryService
+	labelReview        *service.LabelReviewService
+	orderBook          *service.OrderBookService
+	candleIntegrity    CandleIntegrityReporter
+	priceDiscrepancies PriceDiscrepancyReader
+	workQueue          WorkQueueStats
+	readiness          Readiness
+	configSettings     []domain.ConfigSetting
+	idempotencyStore   IdempotencyStore
+	idempotencyTTL     time.Duration
+	requestTimeout     time.Duration
+	longTimeout        time.Duration
 }
 
 func New(
@@ -31,6 +59,16 @@ func New(
 	}
 }
 
+func (h *Handler) SetSignalGenerator(g SignalBatchGenerator) {
+	h.signalGenerator = g
+}
+
+// SetSignalRiskMapper makes GET /api/indicators report the risk the signal
+// engine assigns per interval, including deployment overrides.
+func (h *Handler) SetSignalRiskMapper(m SignalRiskMapper) {
+	h.riskMapper = m
+}
+
 func (h *Handler) SetMLTrainingRunner(runner MLTrainingRunner) {
 	h.mlTrainer = runner
 }
@@ -39,19 +77,187 @@ func (h *Handler) SetMarketIntelRunner(runner MarketIntelRunner) {
 	h.marketIntelRunner = runner
 }
 
+// SetMarketEventCalendar serves GET /api/market-events from cal.
+func (h *Handler) SetMarketEventCalendar(cal MarketEventCalendar) {
+	h.marketEvents = cal
+}
+
 func (h *Handler) SetBacktestService(svc *service.BacktestService) {
 	h.backtestService = svc
 }
 
+func (h *Handler) SetAuditService(svc *service.AuditService) {
+	h.auditService = svc
+}
+
+func (h *Handler) SetAPIKeyService(svc *service.APIKeyService) {
+	h.apiKeyService = svc
+}
+
+func (h *Handler) SetWebhookService(svc *service.WebhookService) {
+	h.webhookService = svc
+}
+
+func (h *Handler) SetBroadcaster(b Broadcaster) {
+	h.broadcaster = b
+}
+
+func (h *Handler) SetAlertDeadLetters(d AlertDeadLetters) {
+	h.alertDeadLetters = d
+}
+
+func (h *Handler) SetScheduleService(svc *service.ScheduleService) {
+	h.scheduleService = svc
+}
+
+func (h *Handler) SetOverviewService(svc *service.OverviewService) {
+	h.overviewService = svc
+}
+
+func (h *Handler) SetCandleIngestService(svc *service.CandleIngestService) {
+	h.candleIngest = svc
+}
+
+func (h *Handler) SetSeasonalityService(svc *service.SeasonalityService) {
+	h.seasonality = svc
+}
+
+// SetMaintenanceService enables the maintenance switch routes, /readyz
+// reporting and the 503 on manual triggers while maintenance is on.
+func (h *Handler) SetMaintenanceService(svc *service.MaintenanceService) {
+	h.maintenance = svc
+}
+
+// SetSymbolRegistryService enables the admin routes that add and remove
+// tracked symbols.
+func (h *Handler) SetSymbolRegistryService(svc *service.SymbolRegistryService) {
+	h.symbolRegistry = svc
+}
+
+// SetLabelReviewService enables listing and overriding resolved prediction
+// labels.
+func (h *Handler) SetLabelReviewService(svc *service.LabelReviewService) {
+	h.labelReview = svc
+}
+
+// SetOrderBookService enables GET /api/orderbook/:symbol.
+func (h *Handler) SetOrderBookService(svc *service.OrderBookService) {
+	h.orderBook = svc
+}
+
+// SetCandleIntegrity enables GET /api/admin/candle-integrity.
+func (h *Handler) SetCandleIntegrity(r CandleIntegrityReporter) {
+	h.candleIntegrity = r
+}
+
+// SetPriceDiscrepancies enables GET /api/admin/price-discrepancies.
+func (h *Handler) SetPriceDiscrepancies(r PriceDiscrepancyReader) {
+	h.priceDiscrepancies = r
+}
+
+// SetWorkQueue enables GET /api/admin/work-queue.
+func (h *Handler) SetWorkQueue(q WorkQueueStats) {
+	h.workQueue = q
+}
+
+// SetReadiness makes /readyz answer 503 until r has no pending
+// dependencies.
+func (h *Handler) SetReadiness(r Readiness) {
+	h.readiness = r
+}
+
+// SetEffectiveConfig enables GET /api/admin/config with the settings the
+// process started with.
+func (h *Handler) SetEffectiveConfig(settings []domain.ConfigSetting) {
+	h.configSettings = settings
+}
+
+// SetIdempotencyStore enables Idempotency-Key handling on POST routes. Call
+// it before RegisterRoutes.
+func (h *Handler) SetIdempotencyStore(store IdempotencyStore, ttl time.Duration) {
+	h.idempotencyStore = store
+	h.idempotencyTTL = ttl
+}
+
+// SetRequestTimeouts bounds each request's context: long applies to the
+// operator routes that run a cycle of work inline, d to every other route.
+// Call it before RegisterRoutes.
+func (h *Handler) SetRequestTimeouts(d, long time.Duration) {
+	h.requestTimeout = d
+	h.longTimeout = long
+}
+
 func (h *Handler) RegisterRoutes(r gin.IRouter) {
+	idem := Idempotency(h.idempotencyStore, h.idempotencyTTL)
+	paused := h.blockDuringMaintenance
+	slow := r.Group("", LocalTime(), RequestTimeout(h.longTimeout))
+	r = r.Group("", LocalTime(), RequestTimeout(h.requestTimeout))
+
+	r.GET("/api/overview", h.GetOverview)
 	r.GET("/api/prices", h.GetAllPrices)
 	r.GET("/api/prices/:symbol", h.GetPrice)
 	r.GET("/api/candles/:symbol", h.GetCandles)
+	slow.POST("/api/candles/ingest", RequireOperator(), paused, idem, h.IngestCandles)
+	r.GET("/api/seasonality/:symbol", h.GetSeasonality)
+	r.GET("/api/orderbook/:symbol", h.GetOrderBook)
+	r.GET("/api/indicators", h.GetIndicators)
+	r.GET("/api/intervals", h.GetIntervals)
+	r.GET("/api/status/providers", h.GetProviderStatus)
 	r.GET("/api/signals", h.GetSignals)
+	r.GET("/api/signals/win-rates", h.GetSignalWinRates)
 	r.GET("/api/signals/:id/image", h.GetSignalImage)
+	slow.POST("/api/signals/generate", RequireOperator(), paused, idem, h.GenerateSignals)
 	r.GET("/api/backtest/summary", h.GetBacktestSummary)
 	r.GET("/api/backtest/daily", h.GetBacktestDaily)
 	r.GET("/api/backtest/predictions", h.GetBacktestPredictions)
-	r.POST("/api/ml/train", h.TriggerMLTraining)
-	r.POST("/api/market-intel/run", h.TriggerMarketIntelRun)
+	r.GET("/api/backtest/risk", h.GetBacktestRisk)
+	r.GET("/api/backtest/coverage", h.GetBacktestCoverage)
+	r.GET("/api/predictions/:id/breakdown", h.GetPredictionBreakdown)
+	r.GET("/api/ml/calibration", h.GetMLCalibration)
+	r.GET("/api/ml/calibration/image", h.GetMLCalibrationImage)
+	r.GET("/api/ml/anomalies", h.GetMLAnomalies)
+	r.GET("/api/ml/compare", h.CompareMLModelVersions)
+	r.POST("/api/ml/infer-at", RequireOperator(), idem, h.InferMLAt)
+	r.GET("/api/ml/similar/:symbol", h.GetMLSimilarSetups)
+	r.GET("/api/ml/labels", h.ListMLLabels)
+	r.POST("/api/ml/labels/override", RequireOperator(), idem, h.OverrideMLLabel)
+	slow.POST("/api/ml/train", RequireOperator(), paused, idem, h.TriggerMLTraining)
+	slow.POST("/api/market-intel/run", RequireOperator(), paused, idem, h.TriggerMarketIntelRun)
+	r.GET("/api/market-events", h.GetMarketEvents)
+	r.GET("/api/audit", h.GetAuditLog)
+	r.GET("/api/webhooks", h.ListWebhooks)
+	r.POST("/api/webhooks", idem, h.CreateWebhook)
+	r.DELETE("/api/webhooks/:id", h.DisableWebhook)
+
+	admin := r.Group("/api/admin", RequireOperator())
+	admin.GET("/api-keys", h.ListAPIKeys)
+	admin.POST("/api-keys", idem, h.CreateAPIKey)
+	admin.DELETE("/api-keys/:id", h.RevokeAPIKey)
+	admin.GET("/webhooks", h.ListWebhooks)
+	admin.POST("/webhooks", idem, h.CreateWebhook)
+	admin.DELETE("/webhooks/:id", h.DisableWebhook)
+	admin.GET("/symbols", h.ListSymbols)
+	admin.POST("/symbols", idem, h.AddSymbol)
+	admin.DELETE("/symbols/:symbol", h.RemoveSymbol)
+	admin.PUT("/symbols/:symbol/pauses", h.SetSymbolPauses)
+	admin.GET("/alerts/dead-letters", h.ListAlertDeadLetters)
+	admin.GET("/candle-integrity", h.GetCandleIntegrity)
+	admin.GET("/price-discrepancies", h.GetPriceDiscrepancies)
+	admin.GET("/work-queue", h.GetWorkQueue)
+	admin.GET("/maintenance", h.GetMaintenance)
+	admin.GET("/config", h.GetConfig)
+	admin.GET("/providers", h.GetProviderQuotas)
+	admin.POST("/maintenance", idem, h.SetMaintenance)
+	admin.POST("/signal-images/purge", idem, h.PurgeSignalImages)
+
+	slowAdmin := slow.Group("/api/admin", RequireOperator())
+	slowAdmin.POST("/broadcast", idem, h.SendBroadcast)
+	slowAdmin.POST("/alerts/dead-letters/:id/redeliver", paused, idem, h.RedeliverAlert)
+}
+
+// RegisterCalendarRoutes registers feeds meant for calendar subscriptions.
+// Calendar apps cannot send X-API-Key, so r should accept the key in the
+// query string (see APIKeyFromQuery) as well as enforce auth.
+func (h *Handler) RegisterCalendarRoutes(r gin.IRouter) {
+	r.GET("/api/schedule.ics", RequestTimeout(h.requestTimeout), h.GetScheduleCalendar)
 }
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// IdempotencyKeyHeader lets clients retry a POST without repeating its
+	// side effects.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response served from the cache.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL is how long a keyed response is replayed.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyLockTTL bounds how long a reservation outlives a process
+	// that died mid-request. The lock is refreshed while the handler runs.
+	idempotencyLockTTL = 30 * time.Second
+
+	maxIdempotencyKeyLen = 255
+	idempotencyKeyPrefix = "idempotency:"
+)
+
+// IdempotentResponse is a cached reply to a keyed request. Fingerprint
+// hashes the request body so a reused key with a different payload is
+// rejected instead of replayed.
+type IdempotentResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// IdempotencyStore holds keyed responses. Reserve claims a key for a request
+// in flight and reports false when the key is already taken; Refresh extends
+// that claim; Load returns nil while the key is missing or still in flight.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	Refresh(ctx context.Context, key string, ttl time.Duration) error
+	Load(ctx context.Context, key string) (*IdempotentResponse, error)
+	Save(ctx context.Context, key string, resp IdempotentResponse, ttl time.Duration) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the stored response when a request repeats an
+// Idempotency-Key already seen for the same tenant and route. Requests
+// without the header pass through. Server errors and panics are not stored,
+// so a client may retry them with the same key. The in-flight reservation
+// only lives for idempotencyLockTTL past its last refresh, so a crashed
+// process does not hold the key for the full ttl.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return func(c *gin.Context) {
+		idemKey := strings.TrimSpace(c.GetHeader(IdempotencyKeyHeader))
+		if store == nil || idemKey == "" {
+			c.Next()
+			return
+		}
+		if len(idemKey) > maxIdempotencyKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+
+		ctx := c.Request.Context()
+		key := TenantFromGin(c) + ":" + c.Request.Method + " " + c.FullPath() + ":" + idemKey
+
+		cached, err := store.Load(ctx, key)
+		if err != nil {
+			log.Printf("idempotency lookup failed: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "idempotency store unavailable"})
+			return
+		}
+		if cached != nil {
+			if cached.Fingerprint != fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request body"})
+				return
+			}
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(cached.Status, cached.ContentType, cached.Body)
+			c.Abort()
+			return
+		}
+
+		reserved, err := store.Reserve(ctx, key, idempotencyLockTTL)
+		if err != nil {
+			log.Printf("idempotency reserve failed: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "idempotency store unavailable"})
+			return
+		}
+		if !reserved {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is in progress"})
+			return
+		}
+
+		// Store with a fresh context: the client may have gone away, and its
+		// retry is exactly what this entry is for.
+		storeCtx := context.WithoutCancel(ctx)
+		stopRefresh := refreshIdempotencyLock(storeCtx, store, key)
+		finished := false
+		defer func() {
+			stopRefresh()
+			// Releases on server errors and while a handler panic unwinds
+			// to gin's recovery.
+			if !finished {
+				if err := store.Release(storeCtx, key); err != nil {
+					log.Printf("idempotency release failed: %v", err)
+				}
+			}
+		}()
+
+		rec := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+
+		status := rec.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		stopRefresh()
+		finished = true
+		resp := IdempotentResponse{
+			Status:      status,
+			ContentType: rec.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+			Fingerprint: fingerprint,
+		}
+		if err := store.Save(storeCtx, key, resp, ttl); err != nil {
+			log.Printf("idempotency save failed: %v", err)
+		}
+	}
+}
+
+// refreshIdempotencyLock keeps key reserved until the returned stop func is
+// called. stop is safe to call more than once.
+func refreshIdempotencyLock(ctx context.Context, store IdempotencyStore, key string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(idempotencyLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := store.Refresh(ctx, key, idempotencyLockTTL); err != nil {
+					log.Printf("idempotency refresh failed: %v", err)
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// RedisIdempotencyStore keeps keyed responses in Redis so retries are
+// recognized across server instances.
+type RedisIdempotencyStore struct {
+	redis *redis.Client
+}
+
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{redis: client}
+}
+
+// Reserve writes an empty placeholder with SET NX.
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.redis.SetNX(ctx, idempotencyKeyPrefix+key, "", ttl).Result()
+}
+
+// Refresh extends the placeholder's TTL. It does not touch a stored
+// response, so a refresh racing Save cannot shorten the replay window.
+func (s *RedisIdempotencyStore) Refresh(ctx context.Context, key string, ttl time.Duration) error {
+	return refreshPlaceholder.Run(ctx, s.redis, []string{idempotencyKeyPrefix + key}, ttl.Milliseconds()).Err()
+}
+
+// refreshPlaceholder extends a key only while it still holds the empty
+// in-flight placeholder.
+var refreshPlaceholder = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == "" then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return 0
+`)
+
+func (s *RedisIdempotencyStore) Load(ctx context.Context, key string) (*IdempotentResponse, error) {
+	raw, err := s.redis.Get(ctx, idempotencyKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) || (err == nil && len(raw) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resp IdempotentResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (s *RedisIdempotencyStore) Save(ctx context.Context, key string, resp IdempotentResponse, ttl time.Duration) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return s.redis.Set(ctx, idempotencyKeyPrefix+key, raw, ttl).Err()
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.redis.Del(ctx, idempotencyKeyPrefix+key).Err()
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func newIdempotencyRouter(t *testing.T, status int) (*gin.Engine, *int, *miniredis.Miniredis) {
+	t.Helper()
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(mini.Close)
+	store := NewRedisIdempotencyStore(redis.NewClient(&redis.Options{Addr: mini.Addr()}))
+
+	calls := 0
+	r := gin.New()
+	r.Use(APIKeyAuth(""))
+	r.POST("/api/ml/train", Idempotency(store, time.Hour), func(c *gin.Context) {
+		calls++
+		c.JSON(status, gin.H{"run": calls})
+	})
+	return r, &calls, mini
+}
+
+func postWithKey(r http.Handler, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/ml/train", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysKeyedResponse(t *testing.T) {
+	r, calls, _ := newIdempotencyRouter(t, http.StatusAccepted)
+
+	first := postWithKey(r, "retry-1", `{}`)
+	second := postWithKey(r, "retry-1", `{}`)
+
+	if *calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", *calls)
+	}
+	if second.Code != http.StatusAccepted || second.Body.String() != first.Body.String() {
+		t.Fatalf("expected replay of %d %s, got %d %s", first.Code, first.Body, second.Code, second.Body)
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatal("expected replayed header on second response")
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatal("expected no replayed header on first response")
+	}
+
+	postWithKey(r, "retry-2", `{}`)
+	postWithKey(r, "", `{}`)
+	if *calls != 3 {
+		t.Fatalf("expected new and missing keys to reach the handler, ran %d times", *calls)
+	}
+}
+
+func TestIdempotencyRejectsReusedKeyWithDifferentBody(t *testing.T) {
+	r, _, _ := newIdempotencyRouter(t, http.StatusOK)
+
+	postWithKey(r, "k", `{"a":1}`)
+	w := postWithKey(r, "k", `{"a":2}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyConflictWhileInFlight(t *testing.T) {
+	r, calls, mini := newIdempotencyRouter(t, http.StatusOK)
+	if err := mini.Set(idempotencyKeyPrefix+"default:POST /api/ml/train:k", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	w := postWithKey(r, "k", `{}`)
+	if w.Code != http.StatusConflict || *calls != 0 {
+		t.Fatalf("expected 409 without running handler, got %d (calls=%d)", w.Code, *calls)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	r, calls, _ := newIdempotencyRouter(t, http.StatusServiceUnavailable)
+
+	postWithKey(r, "k", `{}`)
+	postWithKey(r, "k", `{}`)
+	if *calls != 2 {
+		t.Fatalf("expected retry after server error to run again, ran %d times", *calls)
+	}
+}
+
+func TestIdempotencyReservationIsShortLived(t *testing.T) {
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer mini.Close()
+	store := NewRedisIdempotencyStore(redis.NewClient(&redis.Options{Addr: mini.Addr()}))
+	key := idempotencyKeyPrefix + "default:POST /api/ml/train:k"
+
+	r := gin.New()
+	r.Use(APIKeyAuth(""))
+	r.POST("/api/ml/train", Idempotency(store, time.Hour), func(c *gin.Context) {
+		if ttl := mini.TTL(key); ttl <= 0 || ttl > idempotencyLockTTL {
+			t.Errorf("expected the in-flight lock to expire within %s, got %s", idempotencyLockTTL, ttl)
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	postWithKey(r, "k", `{}`)
+	if ttl := mini.TTL(key); ttl != time.Hour {
+		t.Fatalf("expected the stored response to keep the full ttl, got %s", ttl)
+	}
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer mini.Close()
+	store := NewRedisIdempotencyStore(redis.NewClient(&redis.Options{Addr: mini.Addr()}))
+
+	calls := 0
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.Use(APIKeyAuth(""))
+	r.POST("/api/ml/train", Idempotency(store, time.Hour), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusOK, gin.H{"run": calls})
+	})
+
+	if w := postWithKey(r, "k", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected recovered 500, got %d", w.Code)
+	}
+	if w := postWithKey(r, "k", `{}`); w.Code != http.StatusOK || calls != 2 {
+		t.Fatalf("expected the retry to run after a panic, got %d (calls=%d)", w.Code, calls)
+	}
+}
+
+func TestRedisIdempotencyRefreshKeepsStoredResponses(t *testing.T) {
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer mini.Close()
+	store := NewRedisIdempotencyStore(redis.NewClient(&redis.Options{Addr: mini.Addr()}))
+	ctx := context.Background()
+
+	if ok, err := store.Reserve(ctx, "a", time.Second); err != nil || !ok {
+		t.Fatalf("reserve: %v %v", ok, err)
+	}
+	if err := store.Refresh(ctx, "a", time.Minute); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if ttl := mini.TTL(idempotencyKeyPrefix + "a"); ttl != time.Minute {
+		t.Fatalf("expected refreshed placeholder ttl, got %s", ttl)
+	}
+
+	if err := store.Save(ctx, "b", IdempotentResponse{Status: 200}, time.Hour); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := store.Refresh(ctx, "b", time.Minute); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if ttl := mini.TTL(idempotencyKeyPrefix + "b"); ttl != time.Hour {
+		t.Fatalf("expected a stored response to keep its ttl, got %s", ttl)
+	}
+}
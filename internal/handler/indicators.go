@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SignalRiskMapper interface {
+	RiskFor(indicator, interval string) domain.RiskLevel
+}
+
+// GetIndicators godoc
+// @Summary      List known indicators
+// @Description  Returns every indicator key a signal may carry (classic, ML and sentiment) with its description, direction semantics, typical risk range and whether charts are supported. Classic indicators also carry the risk assigned on each interval, including deployment overrides from SIGNAL_RISK_OVERRIDES
+// @Tags         signals
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Security     ApiKeyAuth
+// @Router       /api/indicators [get]
+func (h *Handler) GetIndicators(c *gin.Context) {
+	if h.riskMapper == nil {
+		c.JSON(http.StatusOK, gin.H{"indicators": domain.Indicators})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"indicators": domain.IndicatorsWithRisk(h.riskMapper.RiskFor)})
+}
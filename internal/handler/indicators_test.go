@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetIndicators(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{}
+	r.GET("/api/indicators", h.GetIndicators)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/indicators", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var body struct {
+		Indicators []domain.IndicatorInfo `json:"indicators"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Indicators) != len(domain.Indicators) {
+		t.Fatalf("expected %d indicators, got %d", len(domain.Indicators), len(body.Indicators))
+	}
+	rsi := body.Indicators[0]
+	if rsi.Key != domain.IndicatorRSI || rsi.Kind != domain.IndicatorKindClassic || !rsi.Charts {
+		t.Fatalf("unexpected rsi entry %+v", rsi)
+	}
+}
+
+type riskMapperStub map[string]domain.RiskLevel
+
+func (s riskMapperStub) RiskFor(indicator, interval string) domain.RiskLevel {
+	if risk, ok := s[indicator+"/"+interval]; ok {
+		return risk
+	}
+	return domain.RiskLevel3
+}
+
+func TestGetIndicatorsReportsActiveRisk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{}
+	h.SetSignalRiskMapper(riskMapperStub{"rsi/5m": domain.RiskLevel1, "rsi/1d": domain.RiskLevel5})
+	r.GET("/api/indicators", h.GetIndicators)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/indicators", nil)
+	r.ServeHTTP(w, req)
+
+	var body struct {
+		Indicators []domain.IndicatorInfo `json:"indicators"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	rsi := body.Indicators[0]
+	if rsi.RiskByInterval["5m"] != domain.RiskLevel1 || rsi.RiskByInterval["1h"] != domain.RiskLevel3 || len(rsi.RiskByInterval) != len(domain.SupportedIntervals) {
+		t.Fatalf("unexpected rsi risk by interval %v", rsi.RiskByInterval)
+	}
+	if rsi.MinRisk != domain.RiskLevel1 || rsi.MaxRisk != domain.RiskLevel5 {
+		t.Fatalf("expected the risk range to follow the mapping, got %d-%d", rsi.MinRisk, rsi.MaxRisk)
+	}
+	for _, info := range body.Indicators {
+		if info.Kind != domain.IndicatorKindClassic && info.RiskByInterval != nil {
+			t.Fatalf("expected no risk mapping for %s", info.Key)
+		}
+	}
+	if domain.Indicators[0].RiskByInterval != nil || domain.Indicators[0].MinRisk != domain.RiskLevel2 {
+		t.Fatal("expected the registry left untouched")
+	}
+}
+
+func TestGetIntervals(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{}
+	r.GET("/api/intervals", h.GetIntervals)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/intervals", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var body struct {
+		Intervals []struct {
+			Name            string `json:"name"`
+			DurationSeconds int64  `json:"duration_seconds"`
+			PointsPerDay    int    `json:"points_per_day"`
+			Tier            string `json:"tier"`
+			AggregateFrom   string `json:"aggregate_from"`
+		} `json:"intervals"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Intervals) != len(domain.SupportedIntervals) {
+		t.Fatalf("expected %d intervals, got %d", len(domain.SupportedIntervals), len(body.Intervals))
+	}
+	h4 := body.Intervals[3]
+	if h4.Name != "4h" || h4.DurationSeconds != 14400 || h4.PointsPerDay != 6 || h4.Tier != domain.IntervalTierLong || h4.AggregateFrom != "1h" {
+		t.Fatalf("unexpected 4h entry %+v", h4)
+	}
+}
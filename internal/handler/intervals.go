@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetIntervals godoc
+// @Summary      List supported candle intervals
+// @Description  Returns the candle interval registry, shortest first: each interval's name, label, length in seconds, candles per day, polling tier and the finer interval it is aggregated from when none are stored
+// @Tags         prices
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Security     ApiKeyAuth
+// @Router       /api/intervals [get]
+func (h *Handler) GetIntervals(c *gin.Context) {
+	intervals := make([]gin.H, 0, len(domain.Intervals))
+	for _, info := range domain.Intervals {
+		entry := gin.H{
+			"name":             info.Name,
+			"label":            info.Label,
+			"duration_seconds": int64(info.Duration.Seconds()),
+			"points_per_day":   info.PointsPerDay(),
+			"tier":             info.Tier,
+		}
+		if info.AggregateFrom != "" {
+			entry["aggregate_from"] = info.AggregateFrom
+		}
+		intervals = append(intervals, entry)
+	}
+	c.JSON(http.StatusOK, gin.H{"intervals": intervals})
+}
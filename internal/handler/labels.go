@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/internal/storage"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type overrideLabelRequest struct {
+	Symbol              string    `json:"symbol"`
+	Interval            string    `json:"interval"`
+	OpenTime            time.Time `json:"open_time"`
+	ActualUp            *bool     `json:"actual_up"`
+	ExcludeFromTraining *bool     `json:"exclude_from_training"`
+	Reason              string    `json:"reason"`
+}
+
+// ListMLLabels godoc
+// @Summary      List resolved prediction labels for review
+// @Description  Returns resolved ML predictions, newest candle first, with any operator override of the candle's label
+// @Tags         ml
+// @Produce      json
+// @Param        symbol      query  string  false  "Symbol"
+// @Param        interval    query  string  false  "Candle interval"
+// @Param        model       query  string  false  "Model key"
+// @Param        overridden  query  bool    false  "Only candles with an override"
+// @Param        limit       query  int     false  "Number of predictions (max 500)"  default(50)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/labels [get]
+func (h *Handler) ListMLLabels(c *gin.Context) {
+	if h.labelReview == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "label review unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.list-ml-labels")
+	defer span.End()
+
+	filter := domain.LabelReviewFilter{
+		Symbol:   strings.ToUpper(strings.TrimSpace(c.Query("symbol"))),
+		Interval: strings.TrimSpace(c.Query("interval")),
+		ModelKey: strings.TrimSpace(c.Query("model")),
+		Limit:    50,
+	}
+	if raw := strings.TrimSpace(c.Query("overridden")); raw != "" {
+		overridden, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "overridden must be true or false"})
+			return
+		}
+		filter.OverriddenOnly = overridden
+	}
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	labels, err := h.labelReview.List(ctx, filter)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if labels == nil {
+		labels = []domain.LabelReview{}
+	}
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+// OverrideMLLabel godoc
+// @Summary      Override a resolved prediction label
+// @Description  Sets actual_up for every resolved prediction on one candle and recomputes is_correct, for labels produced by bad exchange data. exclude_from_training (default true) keeps the candle out of training while ML_TRAIN_EXCLUDE_FLAGGED is on. The change is recorded in the audit log.
+// @Tags         ml
+// @Accept       json
+// @Produce      json
+// @Param        body  body  overrideLabelRequest  true  "Candle, corrected label and reason"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/labels/override [post]
+func (h *Handler) OverrideMLLabel(c *gin.Context) {
+	if h.labelReview == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "label review unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.override-ml-label")
+	defer span.End()
+
+	var req overrideLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ActualUp == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "request body must include symbol, interval, open_time, actual_up and reason"})
+		return
+	}
+	exclude := true
+	if req.ExcludeFromTraining != nil {
+		exclude = *req.ExcludeFromTraining
+	}
+
+	saved, updated, err := h.labelReview.Override(ctx, domain.LabelOverride{
+		Symbol:              req.Symbol,
+		Interval:            req.Interval,
+		OpenTime:            req.OpenTime,
+		ActualUp:            *req.ActualUp,
+		ExcludeFromTraining: exclude,
+		Reason:              req.Reason,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidLabelOverride):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case storage.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "no resolved prediction for that candle"})
+		return
+	case err != nil:
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"override": saved, "predictions_updated": updated})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type handlerLabelStoreStub struct {
+	filter    domain.LabelReviewFilter
+	reviews   []domain.LabelReview
+	saved     domain.LabelOverride
+	overrideE error
+}
+
+func (s *handlerLabelStoreStub) ListLabelReviews(_ context.Context, filter domain.LabelReviewFilter) ([]domain.LabelReview, error) {
+	s.filter = filter
+	return s.reviews, nil
+}
+
+func (s *handlerLabelStoreStub) GetLabelOverride(context.Context, string, string, time.Time) (*domain.LabelOverride, error) {
+	return nil, nil
+}
+
+func (s *handlerLabelStoreStub) OverrideLabel(_ context.Context, o domain.LabelOverride) (domain.LabelOverride, int64, error) {
+	if s.overrideE != nil {
+		return domain.LabelOverride{}, 0, s.overrideE
+	}
+	s.saved = o
+	return o, 2, nil
+}
+
+func newLabelTestRouter(store *handlerLabelStoreStub) *gin.Engine {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	h.SetLabelReviewService(service.NewLabelReviewService(tracer, store, nil))
+
+	router := gin.New()
+	router.GET("/api/ml/labels", h.ListMLLabels)
+	router.POST("/api/ml/labels/override", h.OverrideMLLabel)
+	return router
+}
+
+func TestListMLLabelsParsesFilter(t *testing.T) {
+	store := &handlerLabelStoreStub{}
+	router := newLabelTestRouter(store)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/labels?symbol=btc&interval=1h&overridden=true&limit=10", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.filter.Symbol != "BTC" || store.filter.Interval != "1h" || !store.filter.OverriddenOnly || store.filter.Limit != 10 {
+		t.Fatalf("unexpected filter: %+v", store.filter)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"labels":[]`)) {
+		t.Fatalf("expected empty labels array, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/labels?limit=1000", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for oversized limit, got %d", w.Code)
+	}
+}
+
+func TestOverrideMLLabel(t *testing.T) {
+	store := &handlerLabelStoreStub{}
+	router := newLabelTestRouter(store)
+
+	body := `{"symbol":"BTC","interval":"1h","open_time":"2026-03-01T12:00:00Z","actual_up":false,"reason":"bad print"}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ml/labels/override", bytes.NewBufferString(body)))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.saved.ActualUp || !store.saved.ExcludeFromTraining || store.saved.Reason != "bad print" {
+		t.Fatalf("unexpected override: %+v", store.saved)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"predictions_updated":2`)) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestOverrideMLLabelErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		err   error
+		wants int
+	}{
+		{"missing label", `{"symbol":"BTC","interval":"1h","open_time":"2026-03-01T12:00:00Z","reason":"x"}`, nil, http.StatusBadRequest},
+		{"missing reason", `{"symbol":"BTC","interval":"1h","open_time":"2026-03-01T12:00:00Z","actual_up":true}`, nil, http.StatusBadRequest},
+		{"unresolved candle", `{"symbol":"BTC","interval":"1h","open_time":"2026-03-01T12:00:00Z","actual_up":true,"reason":"x"}`, pgx.ErrNoRows, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		router := newLabelTestRouter(&handlerLabelStoreStub{overrideE: tc.err})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/ml/labels/override", bytes.NewBufferString(tc.body)))
+		if w.Code != tc.wants {
+			t.Fatalf("%s: expected %d, got %d: %s", tc.name, tc.wants, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bug-free-umbrella/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readiness reports the backing services that have not answered since
+// startup.
+type Readiness interface {
+	Pending() []string
+}
+
+type setMaintenanceRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// Ready godoc
+// @Summary      Readiness check
+// @Description  Reports whether the service is ready and whether maintenance mode is on. It answers 503 while Postgres or Redis have not answered since startup. It answers 200 during maintenance, because read APIs keep serving; check status or maintenance.enabled to tell the two apart.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /readyz [get]
+func (h *Handler) Ready(c *gin.Context) {
+	if h.readiness != nil {
+		if pending := h.readiness.Pending(); len(pending) > 0 {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "starting", "waiting_for": pending})
+			return
+		}
+	}
+	if h.maintenance == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+		return
+	}
+	state, _ := h.maintenance.State(c.Request.Context())
+	status := "ready"
+	if state.Enabled {
+		status = "maintenance"
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status, "maintenance": state})
+}
+
+// GetMaintenance godoc
+// @Summary      Get maintenance mode
+// @Description  Returns whether maintenance mode is on, with its reason, who set it and when.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  domain.MaintenanceState
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/maintenance [get]
+func (h *Handler) GetMaintenance(c *gin.Context) {
+	if h.maintenance == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "maintenance switch unavailable"})
+		return
+	}
+	state, err := h.maintenance.State(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// SetMaintenance godoc
+// @Summary      Turn maintenance mode on or off
+// @Description  While on, price and signal pollers, ML jobs, market intel and alert dispatch skip their cycles, and manual triggers answer 503. Read APIs keep serving. Use it during provider incidents or migrations instead of stopping the process.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  setMaintenanceRequest  true  "enabled and an optional reason"
+// @Success      200  {object}  domain.MaintenanceState
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/maintenance [post]
+func (h *Handler) SetMaintenance(c *gin.Context) {
+	if h.maintenance == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "maintenance switch unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.set-maintenance")
+	defer span.End()
+
+	var req setMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "request body must include enabled"})
+		return
+	}
+	state, err := h.maintenance.Set(ctx, *req.Enabled, req.Reason)
+	if errors.Is(err, service.ErrInvalidMaintenance) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// blockDuringMaintenance answers 503 on routes that start background-style
+// work, such as generating signals or training, while maintenance is on.
+func (h *Handler) blockDuringMaintenance(c *gin.Context) {
+	if h.maintenance == nil {
+		c.Next()
+		return
+	}
+	state, _ := h.maintenance.State(c.Request.Context())
+	if !state.Enabled {
+		c.Next()
+		return
+	}
+	c.Header("Retry-After", "300")
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+		"error":       "maintenance mode is on",
+		"maintenance": state,
+	})
+}
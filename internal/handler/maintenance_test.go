@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type readinessStub []string
+
+func (r readinessStub) Pending() []string { return r }
+
+func TestReadyWaitsForDependencies(t *testing.T) {
+	h := &Handler{tracer: trace.NewNoopTracerProvider().Tracer("handler-test")}
+	router := gin.New()
+	router.GET("/readyz", h.Ready)
+
+	h.SetReadiness(readinessStub{"postgres"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), `"waiting_for":["postgres"]`) {
+		t.Fatalf("expected 503 waiting for postgres, got %d %s", w.Code, w.Body.String())
+	}
+
+	h.SetReadiness(readinessStub{})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 once dependencies answer, got %d", w.Code)
+	}
+}
+
+func TestMaintenanceRoutes(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/readyz", h.Ready)
+	router.GET("/api/admin/maintenance", h.GetMaintenance)
+	router.POST("/api/admin/maintenance", h.SetMaintenance)
+	router.POST("/api/signals/generate", h.blockDuringMaintenance, func(c *gin.Context) { c.Status(http.StatusAccepted) })
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	if w := do(http.MethodGet, "/api/admin/maintenance", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a maintenance service, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/readyz", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"ready"`) {
+		t.Fatalf("expected ready without a maintenance service, got %d %s", w.Code, w.Body.String())
+	}
+
+	h.SetMaintenanceService(service.NewMaintenanceService(tracer, nil, nil))
+
+	if w := do(http.MethodPost, "/api/admin/maintenance", `{"reason":"x"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without enabled, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/admin/maintenance", `{"enabled":true,"reason":"`+strings.Repeat("x", 501)+`"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a long reason, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/signals/generate", ""); w.Code != http.StatusAccepted {
+		t.Fatalf("expected trigger to run while maintenance is off, got %d", w.Code)
+	}
+
+	w := do(http.MethodPost, "/api/admin/maintenance", `{"enabled":true,"reason":"provider incident"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var state domain.MaintenanceState
+	if err := json.Unmarshal(w.Body.Bytes(), &state); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if !state.Enabled || state.Reason != "provider incident" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	w = do(http.MethodGet, "/readyz", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status":"maintenance"`) {
+		t.Fatalf("expected readyz to report maintenance, got %d %s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodPost, "/api/signals/generate", "")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected trigger to be blocked during maintenance, got %d", w.Code)
+	}
+
+	if w := do(http.MethodPost, "/api/admin/maintenance", `{"enabled":false}`); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 turning maintenance off, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/signals/generate", ""); w.Code != http.StatusAccepted {
+		t.Fatalf("expected trigger to run after maintenance, got %d", w.Code)
+	}
+}
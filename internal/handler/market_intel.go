@@ -3,8 +3,11 @@ package handler
 import (
 	"context"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +16,14 @@ type MarketIntelRunner interface {
 	RunMarketIntel(ctx context.Context) (domain.MarketIntelRunResult, error)
 }
 
+// MarketEventCalendar lists scheduled market events starting in a range.
+type MarketEventCalendar interface {
+	EventsBetween(from, to time.Time) []domain.MarketEvent
+}
+
+// maxMarketEventDays bounds how far ahead GET /api/market-events looks.
+const maxMarketEventDays = 90
+
 // TriggerMarketIntelRun godoc
 // @Summary      Trigger fundamentals and sentiment ingestion/scoring manually
 // @Description  Runs one Phase 7 market-intel cycle and returns ingest/score/composite counters
@@ -34,7 +45,7 @@ func (h *Handler) TriggerMarketIntelRun(c *gin.Context) {
 
 	result, err := h.marketIntelRunner.RunMarketIntel(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -45,6 +56,56 @@ func (h *Handler) TriggerMarketIntelRun(c *gin.Context) {
 		"onchain_snapshots":  result.OnChainSnapshots,
 		"composites_written": result.CompositesWritten,
 		"signals_written":    result.SignalsWritten,
+		"events_ingested":    result.EventsIngested,
 		"errors":             result.Errors,
 	})
 }
+
+// GetMarketEvents godoc
+// @Summary      List upcoming market events
+// @Description  Returns the scheduled FOMC decisions, CPI prints, token unlocks and other calendar events starting in the next days, oldest first. With a symbol, events for other tokens are left out; macro events are kept.
+// @Tags         market-intel
+// @Produce      json
+// @Param        days    query  int     false  "Days ahead (default 7, max 90)"  default(7)
+// @Param        symbol  query  string  false  "Only events concerning this symbol"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/market-events [get]
+func (h *Handler) GetMarketEvents(c *gin.Context) {
+	if h.marketEvents == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "market event calendar unavailable"})
+		return
+	}
+
+	_, span := h.tracer.Start(c.Request.Context(), "handler.get-market-events")
+	defer span.End()
+
+	days := 7
+	if raw := strings.TrimSpace(c.Query("days")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxMarketEventDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = n
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(c.Query("symbol")))
+	if symbol != "" && !domain.IsSupportedSymbol(symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "unsupported symbol: " + symbol,
+			"supported_symbols": domain.SupportedSymbols(),
+		})
+		return
+	}
+
+	now := time.Now().UTC()
+	events := []domain.MarketEvent{}
+	for _, e := range h.marketEvents.EventsBetween(now, now.AddDate(0, 0, days)) {
+		if symbol == "" || e.AppliesTo(symbol) {
+			events = append(events, e)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
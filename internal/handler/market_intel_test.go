@@ -7,9 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
@@ -99,3 +100,59 @@ func (s marketIntelRunnerStub) RunMarketIntel(ctx context.Context) (domain.Marke
 	}
 	return s.result, nil
 }
+
+type marketEventCalendarStub struct {
+	events   []domain.MarketEvent
+	from, to time.Time
+}
+
+func (s *marketEventCalendarStub) EventsBetween(from, to time.Time) []domain.MarketEvent {
+	s.from, s.to = from, to
+	return s.events
+}
+
+func TestGetMarketEventsFiltersBySymbol(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/market-events", h.GetMarketEvents)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/market-events", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a calendar, got %d", w.Code)
+	}
+
+	starts := time.Now().UTC().Add(24 * time.Hour)
+	cal := &marketEventCalendarStub{events: []domain.MarketEvent{
+		{Kind: domain.MarketEventFOMC, StartsAt: starts, Title: "FOMC rate decision", Importance: domain.EventImportanceHigh},
+		{Kind: domain.MarketEventUnlock, Symbol: "SOL", StartsAt: starts, Title: "SOL unlock", Importance: domain.EventImportanceMedium},
+	}}
+	h.SetMarketEventCalendar(cal)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/market-events?days=3&symbol=btc", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Events []domain.MarketEvent `json:"events"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(body.Events) != 1 || body.Events[0].Kind != domain.MarketEventFOMC {
+		t.Fatalf("expected only the macro event for BTC, got %+v", body.Events)
+	}
+	if got := cal.to.Sub(cal.from); got != 72*time.Hour {
+		t.Fatalf("expected a three day window, got %v", got)
+	}
+
+	for _, query := range []string{"days=0", "days=91", "symbol=NOPE"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/market-events?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
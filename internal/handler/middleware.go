@@ -1,17 +1,47 @@
 package handler
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"strings"
 
+	"bug-free-umbrella/pkg/domain"
+
 	"github.com/gin-gonic/gin"
 )
 
+// TenantContextKey is the gin context key holding the resolved tenant ID.
+const TenantContextKey = "tenant_id"
+
+// TenantKeyResolver resolves a raw API key to the tenant it belongs to.
+type TenantKeyResolver interface {
+	ResolveTenant(ctx context.Context, rawKey string) (string, bool, error)
+}
+
+// TenantAuthConfig configures TenantAPIKeyAuth. AdminKey is bound to the
+// default tenant, StaticKeys maps config-provided keys to tenants, and
+// Resolver looks up keys issued at runtime.
+type TenantAuthConfig struct {
+	AdminKey   string
+	StaticKeys map[string]string
+	Resolver   TenantKeyResolver
+}
+
 // APIKeyAuth returns a Gin middleware that enforces X-API-Key header validation.
 // If key is empty, the middleware is a no-op (auth disabled).
 func APIKeyAuth(key string) gin.HandlerFunc {
+	return TenantAPIKeyAuth(TenantAuthConfig{AdminKey: key})
+}
+
+// TenantAPIKeyAuth validates X-API-Key and scopes the request context to the
+// tenant bound to that key. When no keys are configured at all, auth is
+// disabled and requests run as the default tenant.
+func TenantAPIKeyAuth(cfg TenantAuthConfig) gin.HandlerFunc {
+	authDisabled := cfg.AdminKey == "" && len(cfg.StaticKeys) == 0 && cfg.Resolver == nil
 	return func(c *gin.Context) {
-		if key == "" {
+		if authDisabled {
+			setTenant(c, domain.DefaultTenantID)
 			c.Next()
 			return
 		}
@@ -20,10 +50,69 @@ func APIKeyAuth(key string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing X-API-Key header"})
 			return
 		}
-		if provided != key {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid API key"})
+		if cfg.AdminKey != "" && provided == cfg.AdminKey {
+			setTenant(c, domain.DefaultTenantID)
+			c.Next()
+			return
+		}
+		if tenantID, ok := cfg.StaticKeys[provided]; ok {
+			setTenant(c, tenantID)
+			c.Next()
+			return
+		}
+		if cfg.Resolver != nil {
+			tenantID, ok, err := cfg.Resolver.ResolveTenant(c.Request.Context(), provided)
+			if err != nil {
+				log.Printf("api key lookup failed: %v", err)
+				c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "api key lookup unavailable"})
+				return
+			}
+			if ok {
+				setTenant(c, tenantID)
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid API key"})
+	}
+}
+
+// APIKeyFromQuery lets clients that cannot set headers, such as calendar
+// apps subscribing to a feed, pass the API key as a query parameter. Put it
+// before TenantAPIKeyAuth; an X-API-Key header still takes precedence.
+func APIKeyFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") == "" {
+			if key := strings.TrimSpace(c.Query(param)); key != "" {
+				c.Request.Header.Set("X-API-Key", key)
+			}
+		}
+		c.Next()
+	}
+}
+
+// TenantFromGin returns the tenant resolved by TenantAPIKeyAuth.
+func TenantFromGin(c *gin.Context) string {
+	return domain.TenantFromContext(c.Request.Context())
+}
+
+// RequireOperator restricts a route group to callers of the default tenant,
+// which owns deployment-wide administration: REST_API_KEY and keys bound to
+// it explicitly. Bare REST_API_KEYS entries belong to the legacy tenant.
+func RequireOperator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if TenantFromGin(c) != domain.DefaultTenantID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "operator access required"})
 			return
 		}
 		c.Next()
 	}
 }
+
+func setTenant(c *gin.Context, tenantID string) {
+	tenantID = domain.NormalizeTenantID(tenantID)
+	c.Set(TenantContextKey, tenantID)
+	ctx := domain.WithTenant(c.Request.Context(), tenantID)
+	ctx = domain.WithActor(ctx, "api:"+tenantID)
+	c.Request = c.Request.WithContext(ctx)
+}
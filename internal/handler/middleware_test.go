@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTenantAPIKeyAuthResolvesTenants(t *testing.T) {
+	resolver := tenantResolverStub{keys: map[string]string{"issued": "globex"}}
+	cases := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "missing key", key: "", wantStatus: http.StatusUnauthorized},
+		{name: "admin key", key: "admin", wantStatus: http.StatusOK, wantTenant: "default"},
+		{name: "static key", key: "acme-key", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "issued key", key: "issued", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "unknown key", key: "nope", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotTenant string
+			r := gin.New()
+			r.Use(TenantAPIKeyAuth(TenantAuthConfig{
+				AdminKey:   "admin",
+				StaticKeys: map[string]string{"acme-key": "acme"},
+				Resolver:   resolver,
+			}))
+			r.GET("/x", func(c *gin.Context) {
+				gotTenant = TenantFromGin(c)
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/x", nil)
+			if tc.key != "" {
+				req.Header.Set("X-API-Key", tc.key)
+			}
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected %d, got %d", tc.wantStatus, w.Code)
+			}
+			if gotTenant != tc.wantTenant {
+				t.Fatalf("expected tenant %q, got %q", tc.wantTenant, gotTenant)
+			}
+		})
+	}
+}
+
+func TestTenantAPIKeyAuthDisabledUsesDefaultTenant(t *testing.T) {
+	var gotTenant string
+	r := gin.New()
+	r.Use(APIKeyAuth(""))
+	r.GET("/x", func(c *gin.Context) {
+		gotTenant = TenantFromGin(c)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if w.Code != http.StatusOK || gotTenant != "default" {
+		t.Fatalf("expected open access as default tenant, got %d %q", w.Code, gotTenant)
+	}
+}
+
+func TestTenantAPIKeyAuthResolverError(t *testing.T) {
+	r := gin.New()
+	r.Use(TenantAPIKeyAuth(TenantAuthConfig{Resolver: tenantResolverStub{err: errors.New("db down")}}))
+	r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/x", nil)
+	req.Header.Set("X-API-Key", "any")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+}
+
+type tenantResolverStub struct {
+	keys map[string]string
+	err  error
+}
+
+func (s tenantResolverStub) ResolveTenant(ctx context.Context, rawKey string) (string, bool, error) {
+	if s.err != nil {
+		return "", false, s.err
+	}
+	tenantID, ok := s.keys[rawKey]
+	return tenantID, ok, nil
+}
+
+func TestAPIKeyFromQuery(t *testing.T) {
+	r := gin.New()
+	r.Use(APIKeyFromQuery("api_key"), TenantAPIKeyAuth(TenantAuthConfig{AdminKey: "admin"}))
+	r.GET("/feed.ics", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		name       string
+		url        string
+		header     string
+		wantStatus int
+	}{
+		{name: "query key", url: "/feed.ics?api_key=admin", wantStatus: http.StatusOK},
+		{name: "wrong query key", url: "/feed.ics?api_key=nope", wantStatus: http.StatusForbidden},
+		{name: "header wins", url: "/feed.ics?api_key=admin", header: "nope", wantStatus: http.StatusForbidden},
+		{name: "no key", url: "/feed.ics", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.header != "" {
+				req.Header.Set("X-API-Key", tc.header)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tc.wantStatus {
+				t.Fatalf("expected %d, got %d", tc.wantStatus, w.Code)
+			}
+		})
+	}
+}
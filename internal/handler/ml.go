@@ -2,9 +2,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"bug-free-umbrella/internal/ml/inference"
+	"bug-free-umbrella/internal/ml/similarity"
 	"bug-free-umbrella/internal/ml/training"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +20,21 @@ type MLTrainingRunner interface {
 	RunTraining(ctx context.Context) ([]training.ModelTrainResult, error)
 }
 
+// MLPointInTimeInferer is optionally implemented by the training runner.
+type MLPointInTimeInferer interface {
+	InferAt(ctx context.Context, symbol, interval string, at time.Time) (*inference.PointInTime, error)
+}
+
+// MLVersionComparer is optionally implemented by the training runner.
+type MLVersionComparer interface {
+	CompareModelVersions(ctx context.Context, modelKey string, versionA, versionB, days int) (*training.VersionComparison, error)
+}
+
+// MLSimilarSetupFinder is optionally implemented by the training runner.
+type MLSimilarSetupFinder interface {
+	FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error)
+}
+
 // TriggerMLTraining godoc
 // @Summary      Trigger ML model training manually
 // @Description  Runs an immediate ML training cycle and returns model training outcomes
@@ -34,7 +56,7 @@ func (h *Handler) TriggerMLTraining(c *gin.Context) {
 
 	results, err := h.mlTrainer.RunTraining(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -44,3 +66,184 @@ func (h *Handler) TriggerMLTraining(c *gin.Context) {
 		"results": results,
 	})
 }
+
+// CompareMLModelVersions godoc
+// @Summary      Compare two versions of a model
+// @Description  Re-scores recent labeled feature rows with two registry versions of a directional model and reports accuracy, AUC, Brier score and how often they disagree
+// @Tags         ml
+// @Produce      json
+// @Param        model_key  query  string  true   "Directional model key (logreg or xgboost)"
+// @Param        a          query  int     true   "First version"
+// @Param        b          query  int     true   "Second version"
+// @Param        days       query  int     false  "Days of feature rows to re-score (default 30, max 365)"  default(30)
+// @Success      200  {object}  training.VersionComparison
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/compare [get]
+func (h *Handler) CompareMLModelVersions(c *gin.Context) {
+	comparer, ok := h.mlTrainer.(MLVersionComparer)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ml training service unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.compare-ml-model-versions")
+	defer span.End()
+
+	modelKey := strings.TrimSpace(c.Query("model_key"))
+	if modelKey == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "model_key is required"})
+		return
+	}
+	versionA, errA := strconv.Atoi(c.Query("a"))
+	versionB, errB := strconv.Atoi(c.Query("b"))
+	if errA != nil || errB != nil || versionA <= 0 || versionB <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a and b must be positive model versions"})
+		return
+	}
+	days := 30
+	if rawDays := strings.TrimSpace(c.Query("days")); rawDays != "" {
+		n, err := strconv.Atoi(rawDays)
+		if err != nil || n <= 0 || n > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return
+		}
+		days = n
+	}
+
+	result, err := comparer.CompareModelVersions(ctx, modelKey, versionA, versionB, days)
+	switch {
+	case errors.Is(err, training.ErrNotComparable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, training.ErrModelVersionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+type inferAtRequest struct {
+	Symbol    string    `json:"symbol"`
+	Interval  string    `json:"interval"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// InferMLAt godoc
+// @Summary      Replay inference for a past candle
+// @Description  Scores the candle containing timestamp with the model versions that were active at that time and the candle's stored features. Nothing is persisted.
+// @Tags         ml
+// @Accept       json
+// @Produce      json
+// @Param        request  body  inferAtRequest  true  "Symbol, interval and RFC 3339 timestamp"
+// @Success      200  {object}  inference.PointInTime
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/infer-at [post]
+func (h *Handler) InferMLAt(c *gin.Context) {
+	inferer, ok := h.mlTrainer.(MLPointInTimeInferer)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ml inference service unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.infer-ml-at")
+	defer span.End()
+
+	var req inferAtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be JSON with symbol, interval and an RFC 3339 timestamp"})
+		return
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(req.Symbol))
+	if !domain.IsSupportedSymbol(symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported symbol: " + req.Symbol})
+		return
+	}
+	interval := strings.TrimSpace(req.Interval)
+	if interval == "" {
+		interval = "1h"
+	}
+	if domain.IntervalDuration(interval) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported interval: " + interval})
+		return
+	}
+	if req.Timestamp.IsZero() || req.Timestamp.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timestamp must be in the past"})
+		return
+	}
+
+	result, err := inferer.InferAt(ctx, symbol, interval, req.Timestamp)
+	if errors.Is(err, inference.ErrNoFeatureRow) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetMLSimilarSetups godoc
+// @Summary      Find similar past market states
+// @Description  Embeds the latest feature row of a symbol and returns the k most similar labeled states of any symbol, with what price did over the following target horizon
+// @Tags         ml
+// @Produce      json
+// @Param        symbol    path   string  true   "Asset symbol"
+// @Param        interval  query  string  false  "Feature interval (default 1h)"  default(1h)
+// @Param        k         query  int     false  "Number of matches (default 10, max 50)"  default(10)
+// @Success      200  {object}  domain.SimilarSetups
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/ml/similar/{symbol} [get]
+func (h *Handler) GetMLSimilarSetups(c *gin.Context) {
+	finder, ok := h.mlTrainer.(MLSimilarSetupFinder)
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ml similarity search unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-ml-similar-setups")
+	defer span.End()
+
+	symbol := strings.ToUpper(strings.TrimSpace(c.Param("symbol")))
+	if !domain.IsSupportedSymbol(symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported symbol: " + c.Param("symbol")})
+		return
+	}
+	interval := strings.TrimSpace(c.DefaultQuery("interval", "1h"))
+	if domain.IntervalDuration(interval) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported interval: " + interval})
+		return
+	}
+	k := 10
+	if rawK := strings.TrimSpace(c.Query("k")); rawK != "" {
+		n, err := strconv.Atoi(rawK)
+		if err != nil || n <= 0 || n > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "k must be between 1 and 50"})
+			return
+		}
+		k = n
+	}
+
+	result, err := finder.FindSimilar(ctx, symbol, interval, k)
+	if errors.Is(err, similarity.ErrNoFeatureRow) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
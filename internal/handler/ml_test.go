@@ -6,10 +6,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"bug-free-umbrella/internal/ml/inference"
+	"bug-free-umbrella/internal/ml/similarity"
 	"bug-free-umbrella/internal/ml/training"
 	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
@@ -88,3 +93,197 @@ func (s mlTrainingRunnerStub) RunTraining(ctx context.Context) ([]training.Model
 	}
 	return append([]training.ModelTrainResult(nil), s.results...), nil
 }
+
+func TestCompareMLModelVersions(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/ml/compare", h.CompareMLModelVersions)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/ml/compare?"+query, nil))
+		return w
+	}
+
+	if w := get("model_key=logreg&a=1&b=2"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a comparer, got %d", w.Code)
+	}
+
+	comparer := &mlComparerStub{}
+	h.SetMLTrainingRunner(comparer)
+	w := get("model_key=logreg&a=1&b=2&days=14")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body training.VersionComparison
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if body.A.Version != 1 || body.B.Version != 2 || body.DisagreementRate != 0.25 || comparer.days != 14 {
+		t.Fatalf("unexpected comparison %+v (days=%d)", body, comparer.days)
+	}
+
+	for query, want := range map[string]int{
+		"a=1&b=2":                         http.StatusBadRequest,
+		"model_key=logreg&a=x&b=2":        http.StatusBadRequest,
+		"model_key=logreg&a=1&b=2&days=0": http.StatusBadRequest,
+		"model_key=iforest_1h&a=1&b=2":    http.StatusBadRequest,
+		"model_key=logreg&a=1&b=9":        http.StatusNotFound,
+	} {
+		if w := get(query); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", query, want, w.Code)
+		}
+	}
+}
+
+type mlComparerStub struct {
+	mlTrainingRunnerStub
+	days int
+}
+
+func (s *mlComparerStub) CompareModelVersions(ctx context.Context, modelKey string, versionA, versionB, days int) (*training.VersionComparison, error) {
+	s.days = days
+	if modelKey != "logreg" {
+		return nil, training.ErrNotComparable
+	}
+	if versionB == 9 {
+		return nil, training.ErrModelVersionNotFound
+	}
+	return &training.VersionComparison{
+		ModelKey:         modelKey,
+		Samples:          100,
+		A:                training.VersionScore{Version: versionA, AUC: 0.61},
+		B:                training.VersionScore{Version: versionB, AUC: 0.58},
+		DisagreementRate: 0.25,
+	}, nil
+}
+
+func TestInferMLAt(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.POST("/api/ml/infer-at", h.InferMLAt)
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/ml/infer-at", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	valid := `{"symbol":"btc","interval":"1h","timestamp":"2026-03-01T10:30:00Z"}`
+	if w := post(valid); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an inferer, got %d", w.Code)
+	}
+
+	inferer := &mlInfererStub{}
+	h.SetMLTrainingRunner(inferer)
+	w := post(valid)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body inference.PointInTime
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if body.Symbol != "BTC" || body.Ensemble == nil || body.Ensemble.Score != 0.4 {
+		t.Fatalf("unexpected result %+v", body)
+	}
+	if !inferer.at.Equal(time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected the request timestamp to be passed through, got %s", inferer.at)
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	for body, want := range map[string]int{
+		`not json`: http.StatusBadRequest,
+		`{"symbol":"NOPE","interval":"1h","timestamp":"2026-03-01T10:30:00Z"}`: http.StatusBadRequest,
+		`{"symbol":"BTC","interval":"7m","timestamp":"2026-03-01T10:30:00Z"}`:  http.StatusBadRequest,
+		`{"symbol":"BTC","interval":"1h"}`:                                     http.StatusBadRequest,
+		`{"symbol":"BTC","interval":"1h","timestamp":"` + future + `"}`:        http.StatusBadRequest,
+		`{"symbol":"ETH","interval":"1h","timestamp":"2026-03-01T10:30:00Z"}`:  http.StatusNotFound,
+	} {
+		if w := post(body); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", body, want, w.Code)
+		}
+	}
+}
+
+type mlInfererStub struct {
+	mlTrainingRunnerStub
+	at time.Time
+}
+
+func (s *mlInfererStub) InferAt(ctx context.Context, symbol, interval string, at time.Time) (*inference.PointInTime, error) {
+	s.at = at
+	if symbol == "ETH" {
+		return nil, inference.ErrNoFeatureRow
+	}
+	return &inference.PointInTime{
+		Symbol:   symbol,
+		Interval: interval,
+		At:       at,
+		Ensemble: &inference.EnsembleOutput{Score: 0.4},
+	}, nil
+}
+
+func TestGetMLSimilarSetups(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/ml/similar/:symbol", h.GetMLSimilarSetups)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/api/ml/similar/BTC"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a finder, got %d", w.Code)
+	}
+
+	finder := &mlSimilarFinderStub{}
+	h.SetMLTrainingRunner(finder)
+	w := get("/api/ml/similar/btc?interval=4h&k=3")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body domain.SimilarSetups
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if body.Symbol != "BTC" || body.Interval != "4h" || len(body.Matches) != 1 || finder.k != 3 {
+		t.Fatalf("unexpected result %+v (k=%d)", body, finder.k)
+	}
+	if w := get("/api/ml/similar/BTC"); w.Code != http.StatusOK || finder.k != 10 {
+		t.Fatalf("expected default k of 10, got %d (k=%d)", w.Code, finder.k)
+	}
+
+	for path, want := range map[string]int{
+		"/api/ml/similar/NOPE":            http.StatusBadRequest,
+		"/api/ml/similar/BTC?interval=7m": http.StatusBadRequest,
+		"/api/ml/similar/BTC?k=0":         http.StatusBadRequest,
+		"/api/ml/similar/BTC?k=51":        http.StatusBadRequest,
+		"/api/ml/similar/ETH":             http.StatusNotFound,
+	} {
+		if w := get(path); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+type mlSimilarFinderStub struct {
+	mlTrainingRunnerStub
+	k int
+}
+
+func (s *mlSimilarFinderStub) FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error) {
+	s.k = k
+	if symbol == "ETH" {
+		return nil, similarity.ErrNoFeatureRow
+	}
+	return &domain.SimilarSetups{
+		Symbol:   symbol,
+		Interval: interval,
+		Matches:  []domain.SimilarSetup{{Symbol: "SOL", Similarity: 0.93, WentUp: true}},
+	}, nil
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetOrderBook godoc
+// @Summary      Get order book depth for a symbol
+// @Description  Returns the latest stored top-of-book snapshot and the recent history: best bid and ask, spread in basis points, USD depth within the depth band on each side, and bid/ask imbalance from -1 to 1
+// @Tags         prices
+// @Produce      json
+// @Param        symbol  path   string  true   "Asset symbol"
+// @Param        limit   query  int     false  "Snapshots, newest first (default 60, max 1440)"  default(60)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/orderbook/{symbol} [get]
+func (h *Handler) GetOrderBook(c *gin.Context) {
+	if h.orderBook == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "order book snapshots unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-order-book")
+	defer span.End()
+
+	symbol := strings.ToUpper(c.Param("symbol"))
+	span.SetAttributes(attribute.String("symbol", symbol))
+	if !domain.IsSupportedSymbol(symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "unsupported symbol: " + symbol,
+			"supported_symbols": domain.SupportedSymbols(),
+		})
+		return
+	}
+
+	limit := 60
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > service.MaxOrderBookHistory {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1440"})
+			return
+		}
+		limit = n
+	}
+
+	snapshots, err := h.orderBook.History(ctx, symbol, limit)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if snapshots == nil {
+		snapshots = []domain.OrderBookSnapshot{}
+	}
+	var latest *domain.OrderBookSnapshot
+	if len(snapshots) > 0 {
+		latest = &snapshots[0]
+	}
+	c.JSON(http.StatusOK, gin.H{"symbol": symbol, "latest": latest, "snapshots": snapshots})
+}
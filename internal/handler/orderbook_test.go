@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type handlerOrderBookStoreStub struct {
+	snapshots []domain.OrderBookSnapshot
+}
+
+func (s *handlerOrderBookStoreStub) InsertSnapshots(context.Context, []domain.OrderBookSnapshot) error {
+	return nil
+}
+
+func (s *handlerOrderBookStoreStub) ListSnapshots(_ context.Context, _ string, limit int) ([]domain.OrderBookSnapshot, error) {
+	if limit < len(s.snapshots) {
+		return s.snapshots[:limit], nil
+	}
+	return s.snapshots, nil
+}
+
+func (s *handlerOrderBookStoreStub) DeleteSnapshotsBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestGetOrderBook(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerOrderBookStoreStub{snapshots: []domain.OrderBookSnapshot{
+		{Symbol: "BTC", Imbalance: 0.3, SpreadBps: 1.2},
+		{Symbol: "BTC", Imbalance: -0.1, SpreadBps: 1.5},
+	}}
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/orderbook/:symbol", h.GetOrderBook)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/orderbook/BTC", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a service, got %d", w.Code)
+	}
+
+	h.SetOrderBookService(service.NewOrderBookService(tracer, nil, store, 1))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/orderbook/btc?limit=1", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"latest":{"symbol":"BTC"`) ||
+		strings.Contains(w.Body.String(), `-0.1`) {
+		t.Fatalf("expected the newest snapshot only, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, path := range []string{"/api/orderbook/NOPE", "/api/orderbook/BTC?limit=0"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", path, w.Code)
+		}
+	}
+}
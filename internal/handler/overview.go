@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOverview godoc
+// @Summary      Get the latest view of every symbol
+// @Description  Returns, per supported symbol, the latest price, newest classic indicator signals, newest ensemble prediction and anomaly score
+// @Tags         overview
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/overview [get]
+func (h *Handler) GetOverview(c *gin.Context) {
+	if h.overviewService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "overview service unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-overview")
+	defer span.End()
+
+	overview, err := h.overviewService.Overview(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"symbols": overview})
+}
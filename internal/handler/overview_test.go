@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type overviewPricesStub struct{}
+
+func (overviewPricesStub) GetCurrentPrices(ctx context.Context) ([]*domain.PriceSnapshot, error) {
+	return []*domain.PriceSnapshot{{Symbol: "BTC", PriceUSD: 65000}}, nil
+}
+
+type overviewSignalsStub struct{}
+
+func (overviewSignalsStub) ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error) {
+	return []domain.Signal{{Symbol: filter.Symbol, Indicator: domain.IndicatorRSI}}, nil
+}
+
+func TestGetOverview(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/overview", h.GetOverview)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/overview", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a service, got %d", w.Code)
+	}
+
+	h.SetOverviewService(service.NewOverviewService(tracer, overviewPricesStub{}, overviewSignalsStub{}))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/overview", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Symbols []domain.SymbolOverview `json:"symbols"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(body.Symbols) != len(domain.SupportedSymbols()) {
+		t.Fatalf("expected %d symbols, got %d", len(domain.SupportedSymbols()), len(body.Symbols))
+	}
+	if body.Symbols[0].Price == nil || len(body.Symbols[0].Signals) != 1 {
+		t.Fatalf("unexpected BTC entry %+v", body.Symbols[0])
+	}
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/attribute"
@@ -13,12 +16,14 @@ import (
 
 // GetPrice godoc
 // @Summary      Get current price for a crypto asset
-// @Description  Returns the latest cached price, 24h volume, and 24h change
+// @Description  Returns the latest cached price, 24h volume, and 24h change. The price is rounded to the symbol's precision, given in price_decimals. With currency, the price and volume are also quoted in EUR, GBP or JPY at the latest ECB reference rate
 // @Tags         prices
 // @Produce      json
-// @Param        symbol  path  string  true  "Asset symbol (e.g., BTC, ETH)"
+// @Param        symbol    path   string  true   "Asset symbol (e.g., BTC, ETH)"
+// @Param        currency  query  string  false  "Quote currency (USD, EUR, GBP, JPY)"  default(USD)
 // @Success      200  {object}  domain.PriceSnapshot
 // @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
 // @Security     ApiKeyAuth
 // @Router       /api/prices/{symbol} [get]
 func (h *Handler) GetPrice(c *gin.Context) {
@@ -28,47 +33,54 @@ func (h *Handler) GetPrice(c *gin.Context) {
 	symbol := strings.ToUpper(c.Param("symbol"))
 	span.SetAttributes(attribute.String("symbol", symbol))
 
-	if _, ok := domain.CoinGeckoID[symbol]; !ok {
+	if !domain.IsSupportedSymbol(symbol) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":             "unsupported symbol: " + symbol,
-			"supported_symbols": domain.SupportedSymbols,
+			"supported_symbols": domain.SupportedSymbols(),
 		})
 		return
 	}
 
-	snapshot, err := h.priceService.GetCurrentPrice(ctx, symbol)
+	snapshot, err := h.priceService.GetCurrentPriceIn(ctx, symbol, c.Query("currency"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPriceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, snapshot)
+	c.JSON(http.StatusOK, presentSnapshot(snapshot))
 }
 
 // GetAllPrices godoc
 // @Summary      Get current prices for all supported assets
-// @Description  Returns latest cached prices for all 10 tracked cryptocurrencies
+// @Description  Returns latest cached prices for all 10 tracked cryptocurrencies, each rounded to its symbol's precision. With currency, each price and volume is also quoted in EUR, GBP or JPY at the latest ECB reference rate
 // @Tags         prices
 // @Produce      json
+// @Param        currency  query  string  false  "Quote currency (USD, EUR, GBP, JPY)"  default(USD)
 // @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
 // @Security     ApiKeyAuth
 // @Router       /api/prices [get]
 func (h *Handler) GetAllPrices(c *gin.Context) {
 	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-all-prices")
 	defer span.End()
 
-	snapshots, err := h.priceService.GetCurrentPrices(ctx)
+	snapshots, err := h.priceService.GetCurrentPricesIn(ctx, c.Query("currency"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondPriceError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"prices": snapshots})
+	presented := make([]*domain.PriceSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		presented = append(presented, presentSnapshot(snapshot))
+	}
+	c.JSON(http.StatusOK, gin.H{"prices": presented})
 }
 
 // GetCandles godoc
 // @Summary      Get historical OHLCV candles
-// @Description  Returns historical candle data for a given asset and interval
+// @Description  Returns historical candle data for a given asset and interval, with prices rounded to the symbol's precision and the trading sessions (asia, eu, us, weekend) each candle overlaps. Symbols pushed through /api/candles/ingest are served too
 // @Tags         prices
 // @Produce      json
 // @Param        symbol    path   string  true   "Asset symbol (e.g., BTC, ETH)"
@@ -85,10 +97,10 @@ func (h *Handler) GetCandles(c *gin.Context) {
 	symbol := strings.ToUpper(c.Param("symbol"))
 	span.SetAttributes(attribute.String("symbol", symbol))
 
-	if _, ok := domain.CoinGeckoID[symbol]; !ok {
+	if !h.knownSymbol(ctx, symbol) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":             "unsupported symbol: " + symbol,
-			"supported_symbols": domain.SupportedSymbols,
+			"supported_symbols": domain.SupportedSymbols(),
 		})
 		return
 	}
@@ -118,13 +130,77 @@ func (h *Handler) GetCandles(c *gin.Context) {
 
 	candles, err := h.priceService.GetCandles(ctx, symbol, interval, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
+	rounded := make([]sessionCandle, 0, len(candles))
+	for _, candle := range candles {
+		if candle == nil {
+			continue
+		}
+		out := *candle
+		out.Open = domain.RoundPrice(symbol, candle.Open)
+		out.High = domain.RoundPrice(symbol, candle.High)
+		out.Low = domain.RoundPrice(symbol, candle.Low)
+		out.Close = domain.RoundPrice(symbol, candle.Close)
+		rounded = append(rounded, sessionCandle{
+			Candle:   out,
+			Sessions: domain.CandleSessions(candle.OpenTime, domain.IntervalDuration(interval)),
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"symbol":   symbol,
-		"interval": interval,
-		"candles":  candles,
+		"symbol":         symbol,
+		"interval":       interval,
+		"price_decimals": domain.PricePrecision(symbol),
+		"candles":        rounded,
 	})
 }
+
+// sessionCandle is a candle as GetCandles serves it, with the trading
+// sessions it overlaps.
+type sessionCandle struct {
+	domain.Candle
+	Sessions []string `json:"sessions"`
+}
+
+// presentSnapshot returns a copy of snapshot with the price rounded to the
+// symbol's precision, so clients can display it as-is.
+func presentSnapshot(snapshot *domain.PriceSnapshot) *domain.PriceSnapshot {
+	if snapshot == nil {
+		return nil
+	}
+	out := *snapshot
+	out.PriceDecimals = domain.PricePrecision(out.Symbol)
+	out.PriceUSD = domain.RoundPrice(out.Symbol, out.PriceUSD)
+	if out.Quote != nil {
+		quote := *out.Quote
+		quote.Price = domain.RoundPrice(out.Symbol, quote.Price)
+		out.Quote = &quote
+	}
+	return &out
+}
+
+// respondPriceError maps quote currency errors to 400 and 503.
+func respondPriceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnsupportedCurrency):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":                err.Error(),
+			"supported_currencies": domain.QuoteCurrencies(),
+		})
+	case errors.Is(err, service.ErrFXUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		respondError(c, err)
+	}
+}
+
+// knownSymbol reports whether symbol is tracked or has ingested candles.
+func (h *Handler) knownSymbol(ctx context.Context, symbol string) bool {
+	if domain.IsSupportedSymbol(symbol) {
+		return true
+	}
+	return h.priceService != nil && h.priceService.KnownSymbol(ctx, symbol)
+}
@@ -9,8 +9,8 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
@@ -22,7 +22,7 @@ func init() {
 
 func TestGetPriceSuccess(t *testing.T) {
 	handler := newTestHandler(map[string]*domain.PriceSnapshot{
-		"BTC": {Symbol: "BTC", PriceUSD: 99.5},
+		"BTC": {Symbol: "BTC", PriceUSD: 99.5049},
 	}, nil, nil)
 
 	w := httptest.NewRecorder()
@@ -43,6 +43,9 @@ func TestGetPriceSuccess(t *testing.T) {
 	if snapshot.Symbol != "BTC" {
 		t.Fatalf("expected BTC snapshot, got %s", snapshot.Symbol)
 	}
+	if snapshot.PriceUSD != 99.5 || snapshot.PriceDecimals != 2 {
+		t.Fatalf("expected price rounded to 2 decimals, got %v (%d)", snapshot.PriceUSD, snapshot.PriceDecimals)
+	}
 }
 
 func TestGetPriceInvalidSymbol(t *testing.T) {
@@ -77,7 +80,7 @@ func TestGetPriceProviderError(t *testing.T) {
 
 func TestGetAllPrices(t *testing.T) {
 	prices := make(map[string]*domain.PriceSnapshot)
-	for _, symbol := range domain.SupportedSymbols {
+	for _, symbol := range domain.SupportedSymbols() {
 		prices[symbol] = &domain.PriceSnapshot{Symbol: symbol, PriceUSD: float64(len(symbol))}
 	}
 	handler := newTestHandler(prices, nil, nil)
@@ -99,8 +102,48 @@ func TestGetAllPrices(t *testing.T) {
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("parse error: %v", err)
 	}
-	if len(resp.Prices) != len(domain.SupportedSymbols) {
-		t.Fatalf("expected %d prices, got %d", len(domain.SupportedSymbols), len(resp.Prices))
+	if len(resp.Prices) != len(domain.SupportedSymbols()) {
+		t.Fatalf("expected %d prices, got %d", len(domain.SupportedSymbols()), len(resp.Prices))
+	}
+}
+
+type stubFXSource struct{}
+
+func (stubFXSource) FetchUSDRates(ctx context.Context, currencies []string) (*domain.FXRates, error) {
+	return &domain.FXRates{Rates: map[string]float64{"EUR": 0.9, "GBP": 0.8, "JPY": 150}}, nil
+}
+
+func TestGetPriceInCurrency(t *testing.T) {
+	handler := newTestHandler(map[string]*domain.PriceSnapshot{
+		"BTC": {Symbol: "BTC", PriceUSD: 100.005},
+	}, nil, nil)
+	router := gin.New()
+	router.GET("/api/prices/:symbol", handler.GetPrice)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/prices/BTC?currency=EUR", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an FX source, got %d", w.Code)
+	}
+
+	handler.priceService.SetFXSource(stubFXSource{})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/prices/BTC?currency=eur", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var snapshot domain.PriceSnapshot
+	if err := json.Unmarshal(w.Body.Bytes(), &snapshot); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if snapshot.Quote == nil || snapshot.Quote.Currency != "EUR" || snapshot.Quote.Price != 90 || snapshot.Quote.Rate != 0.9 {
+		t.Fatalf("unexpected EUR quote: %+v", snapshot.Quote)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/prices/BTC?currency=CHF", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unsupported currency, got %d", w.Code)
 	}
 }
 
@@ -127,7 +170,7 @@ func TestGetCandlesSuccess(t *testing.T) {
 		Open:     10,
 		High:     12,
 		Low:      9,
-		Close:    11,
+		Close:    11.004,
 		Volume:   1000,
 	}}
 	repo := &stubRepo{candles: candles}
@@ -145,9 +188,10 @@ func TestGetCandlesSuccess(t *testing.T) {
 	}
 
 	var resp struct {
-		Symbol   string          `json:"symbol"`
-		Interval string          `json:"interval"`
-		Candles  []domain.Candle `json:"candles"`
+		Symbol        string          `json:"symbol"`
+		Interval      string          `json:"interval"`
+		PriceDecimals int             `json:"price_decimals"`
+		Candles       []sessionCandle `json:"candles"`
 	}
 	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("parse error: %v", err)
@@ -155,6 +199,13 @@ func TestGetCandlesSuccess(t *testing.T) {
 	if resp.Symbol != "ETH" || resp.Interval != "1h" || len(resp.Candles) != 1 {
 		t.Fatalf("unexpected payload: %+v", resp)
 	}
+	if resp.PriceDecimals != 2 || resp.Candles[0].Close != 11 || candles[0].Close != 11.004 {
+		t.Fatalf("expected rounded copies of the candles, got %+v", resp)
+	}
+	// 1970-01-01 was a Thursday; midnight UTC is in the Asian session.
+	if got := resp.Candles[0].Sessions; len(got) != 1 || got[0] != domain.SessionAsia {
+		t.Fatalf("expected the asia session label, got %v", got)
+	}
 	if repo.lastLimit != 1 {
 		t.Fatalf("expected limit=1, got %d", repo.lastLimit)
 	}
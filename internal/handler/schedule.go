@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetScheduleCalendar godoc
+// @Summary      Get schedule calendar feed
+// @Description  Returns an iCal feed of the ML training schedule, planned maintenance, and the last 30 days of training runs and model promotions
+// @Tags         schedule
+// @Produce      text/calendar
+// @Param        api_key  query  string  false  "API key, for calendar apps that cannot send X-API-Key"
+// @Success      200  {file}  binary
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/schedule.ics [get]
+func (h *Handler) GetScheduleCalendar(c *gin.Context) {
+	if h.scheduleService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "schedule feed unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-schedule-calendar")
+	defer span.End()
+
+	feed, err := h.scheduleService.Calendar(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="schedule.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", feed)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestGetScheduleCalendar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	r := gin.New()
+	h.RegisterCalendarRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/schedule.ics", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a schedule service, got %d", w.Code)
+	}
+
+	h.SetScheduleService(service.NewScheduleService(tracer, service.ScheduleConfig{TrainingEnabled: true}))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/schedule.ics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Fatalf("expected text/calendar, got %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "SUMMARY:ML training") {
+		t.Fatalf("expected training event in feed:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetSeasonality godoc
+// @Summary      Get return seasonality for a symbol
+// @Description  Averages the close-to-close return of stored candles per UTC hour of day, day of week and month. Hour buckets are empty for daily candles.
+// @Tags         prices
+// @Produce      json
+// @Param        symbol    path   string  true   "Asset symbol"
+// @Param        interval  query  string  false  "Candle interval (default 1h)"  default(1h)
+// @Param        days      query  int     false  "Days of history (default 365, max 1095)"  default(365)
+// @Success      200  {object}  domain.Seasonality
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/seasonality/{symbol} [get]
+func (h *Handler) GetSeasonality(c *gin.Context) {
+	if h.seasonality == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "seasonality service unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-seasonality")
+	defer span.End()
+
+	symbol := strings.ToUpper(c.Param("symbol"))
+	span.SetAttributes(attribute.String("symbol", symbol))
+	if !h.knownSymbol(ctx, symbol) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             "unsupported symbol: " + symbol,
+			"supported_symbols": domain.SupportedSymbols(),
+		})
+		return
+	}
+
+	days := service.DefaultSeasonalityDays
+	if raw := strings.TrimSpace(c.Query("days")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be an integer"})
+			return
+		}
+		days = n
+	}
+
+	result, err := h.seasonality.Seasonality(ctx, symbol, c.DefaultQuery("interval", "1h"), days)
+	if errors.Is(err, service.ErrInvalidSeasonalityQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
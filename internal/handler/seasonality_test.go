@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type seasonalityCandlesStub struct{}
+
+func (seasonalityCandlesStub) GetCandlesInRange(_ context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error) {
+	start := to.Truncate(time.Hour).Add(-3 * time.Hour)
+	return []*domain.Candle{
+		{Symbol: symbol, Interval: interval, OpenTime: start, Close: 100},
+		{Symbol: symbol, Interval: interval, OpenTime: start.Add(time.Hour), Close: 101},
+	}, nil
+}
+
+func TestGetSeasonality(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/seasonality/:symbol", h.GetSeasonality)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := get("/api/seasonality/BTC"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without the service, got %d", w.Code)
+	}
+
+	h.SetSeasonalityService(service.NewSeasonalityService(tracer, seasonalityCandlesStub{}))
+	w := get("/api/seasonality/btc")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body domain.Seasonality
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if body.Symbol != "BTC" || body.Interval != "1h" || body.Samples != 1 || len(body.HourOfDay) != 24 {
+		t.Fatalf("unexpected seasonality %+v", body)
+	}
+	if days := body.To.Sub(body.From).Hours() / 24; days != float64(service.DefaultSeasonalityDays) {
+		t.Fatalf("expected the default window, got %.1f days", days)
+	}
+
+	for path, want := range map[string]int{
+		"/api/seasonality/NOPE":            http.StatusBadRequest,
+		"/api/seasonality/BTC?interval=2h": http.StatusBadRequest,
+		"/api/seasonality/BTC?days=abc":    http.StatusBadRequest,
+		"/api/seasonality/BTC?days=5000":   http.StatusBadRequest,
+		"/api/seasonality/BTC?days=30":     http.StatusOK,
+	} {
+		if w := get(path); w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
@@ -1,16 +1,38 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// maxGenerateSymbols caps one bulk generation request.
+const maxGenerateSymbols = 50
+
+// SignalBatchGenerator generates signals for several symbols at once,
+// reporting each symbol's outcome separately.
+type SignalBatchGenerator interface {
+	GenerateSymbols(ctx context.Context, symbols []string, intervals []string) []domain.SignalGenerationResult
+}
+
+type generateSignalsRequest struct {
+	Symbols   []string `json:"symbols"`
+	Intervals []string `json:"intervals"`
+}
+
+type purgeSignalImagesRequest struct {
+	All bool `json:"all"`
+}
+
 // GetSignals godoc
 // @Summary      Get generated trading signals
 // @Description  Returns recent signals, optionally filtered by symbol/risk/indicator
@@ -18,7 +40,8 @@ import (
 // @Produce      json
 // @Param        symbol     query  string  false  "Asset symbol (e.g., BTC, ETH)"
 // @Param        risk       query  int     false  "Risk level (1-5)"
-// @Param        indicator  query  string  false  "Indicator key (rsi, macd, bollinger, volume_zscore, ml_logreg_up4h, ml_xgboost_up4h, ml_ensemble_up4h, fund_sentiment_composite)"
+// @Param        indicator  query  string  false  "Indicator key (rsi, macd, bollinger, volume_zscore, ichimoku, ml_logreg_up4h, ml_xgboost_up4h, ml_ensemble_up4h, fund_sentiment_composite, cex_dex_spread)"
+// @Param        version    query  string  false  "Detection logic version (e.g., ta-1)"
 // @Param        limit      query  int     false  "Number of signals (default 50, max 200)"  default(50)
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
@@ -37,14 +60,15 @@ func (h *Handler) GetSignals(c *gin.Context) {
 	filter := domain.SignalFilter{
 		Symbol:    strings.ToUpper(strings.TrimSpace(c.Query("symbol"))),
 		Indicator: strings.ToLower(strings.TrimSpace(c.Query("indicator"))),
+		Version:   strings.TrimSpace(c.Query("version")),
 	}
 
 	if filter.Symbol != "" {
 		span.SetAttributes(attribute.String("symbol", filter.Symbol))
-		if _, ok := domain.CoinGeckoID[filter.Symbol]; !ok {
+		if !h.knownSymbol(ctx, filter.Symbol) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":             "unsupported symbol: " + filter.Symbol,
-				"supported_symbols": domain.SupportedSymbols,
+				"supported_symbols": domain.SupportedSymbols(),
 			})
 			return
 		}
@@ -77,18 +101,58 @@ func (h *Handler) GetSignals(c *gin.Context) {
 
 	signals, err := h.signalService.ListSignals(ctx, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"signals": signals})
 }
 
+// GetSignalWinRates godoc
+// @Summary      Get signal win rates per version
+// @Description  Scores stored long/short signals against the close a number of candles later, grouped by indicator and detection version
+// @Tags         signals
+// @Produce      json
+// @Param        indicator  query  string  false  "Indicator key (default all)"
+// @Param        horizon    query  int     false  "Candles after the signal to score against (default 4, max 100)"  default(4)
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/signals/win-rates [get]
+func (h *Handler) GetSignalWinRates(c *gin.Context) {
+	if h.signalService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signal service unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.get-signal-win-rates")
+	defer span.End()
+
+	horizon := service.DefaultWinRateHorizon
+	if raw := strings.TrimSpace(c.Query("horizon")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > service.MaxWinRateHorizon {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "horizon must be between 1 and 100"})
+			return
+		}
+		horizon = n
+	}
+
+	rates, err := h.signalService.WinRatesByVersion(ctx, c.Query("indicator"), horizon)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"horizon": horizon, "win_rates": rates})
+}
+
 // GetSignalImage godoc
 // @Summary      Get signal chart image
-// @Description  Returns the rendered PNG chart image for a signal id
+// @Description  Returns the rendered chart image for a signal id, as PNG or WebP depending on CHART_IMAGE_FORMAT when it was rendered
 // @Tags         signals
-// @Produce      png
+// @Produce      png,image/webp
 // @Param        id  path  int  true  "Signal ID"
 // @Success      200  {file}  binary
 // @Failure      400  {object}  map[string]string
@@ -113,7 +177,7 @@ func (h *Handler) GetSignalImage(c *gin.Context) {
 
 	imageData, err := h.signalService.GetSignalImage(ctx, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 	if imageData == nil || len(imageData.Bytes) == 0 {
@@ -123,3 +187,118 @@ func (h *Handler) GetSignalImage(c *gin.Context) {
 
 	c.Data(http.StatusOK, imageData.Ref.MimeType, imageData.Bytes)
 }
+
+// PurgeSignalImages godoc
+// @Summary      Purge signal chart images
+// @Description  Deletes expired signal images now instead of at the next hourly cleanup, or every stored image with {"all":true}. Purged images are not rendered again. Meant for emergencies such as a full disk.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  purgeSignalImagesRequest  false  "Set all to delete every image"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/signal-images/purge [post]
+func (h *Handler) PurgeSignalImages(c *gin.Context) {
+	if h.signalService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signal service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.purge-signal-images")
+	defer span.End()
+
+	var req purgeSignalImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	deleted, err := h.signalService.PurgeSignalImages(ctx, req.All)
+	if errors.Is(err, service.ErrSignalImagesUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	span.SetAttributes(attribute.Int64("signal_images.deleted", deleted))
+	if err := h.auditService.Record(ctx, domain.AuditActionImagesPurge, domain.AuditEntitySignalImages, "",
+		nil, gin.H{"all": req.All, "deleted": deleted}); err != nil {
+		span.RecordError(err)
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted, "all": req.All})
+}
+
+// GenerateSignals godoc
+// @Summary      Generate signals for several symbols
+// @Description  Runs signal detection now for each symbol on the given intervals (default all). Each symbol reports its own signals or error, so one failure does not fail the request.
+// @Tags         signals
+// @Accept       json
+// @Produce      json
+// @Param        body  body  generateSignalsRequest  true  "Symbols and optional intervals"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/signals/generate [post]
+func (h *Handler) GenerateSignals(c *gin.Context) {
+	if h.signalGenerator == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "signal generation unavailable"})
+		return
+	}
+
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.generate-signals")
+	defer span.End()
+
+	var req generateSignalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	symbols := make([]string, 0, len(req.Symbols))
+	seen := make(map[string]bool, len(req.Symbols))
+	for _, raw := range req.Symbols {
+		symbol := strings.ToUpper(strings.TrimSpace(raw))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	if len(symbols) == 0 || len(symbols) > maxGenerateSymbols {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbols must list between 1 and 50 symbols"})
+		return
+	}
+
+	intervals := make([]string, 0, len(req.Intervals))
+	for _, raw := range req.Intervals {
+		interval := strings.TrimSpace(raw)
+		if domain.IntervalDuration(interval) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":               "unsupported interval: " + interval,
+				"supported_intervals": domain.SupportedIntervals,
+			})
+			return
+		}
+		intervals = append(intervals, interval)
+	}
+	span.SetAttributes(attribute.Int("symbols", len(symbols)))
+
+	results := h.signalGenerator.GenerateSymbols(ctx, symbols, intervals)
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
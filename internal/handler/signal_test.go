@@ -9,8 +9,8 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/trace"
@@ -112,6 +112,29 @@ func TestGetSignalsBadParams(t *testing.T) {
 	}
 }
 
+func TestGetSignalsIndicatorFilter(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerSignalStoreStub{}
+	h := &Handler{
+		tracer:        tracer,
+		signalService: service.NewSignalService(tracer, &stubRepo{}, store, stubSignalEngine{}),
+	}
+	router := gin.New()
+	router.GET("/api/signals", h.GetSignals)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/signals?indicator=Ichimoku", nil))
+	if w.Code != http.StatusOK || store.lastFilter.Indicator != domain.IndicatorIchimoku {
+		t.Fatalf("expected the ichimoku filter passed through, got %d %+v", w.Code, store.lastFilter)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/signals?indicator=stochastic", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected an unknown indicator to match nothing, got %d %s", w.Code, w.Body.String())
+	}
+}
+
 func TestGetSignalImageSuccess(t *testing.T) {
 	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
 	imageRepo := &handlerSignalImageRepoStub{
@@ -182,6 +205,57 @@ func TestGetSignalImageNotFound(t *testing.T) {
 	}
 }
 
+func TestGetSignalWinRates(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	repo := &handlerSignalStatsStub{rates: []domain.SignalWinRate{
+		{Indicator: domain.IndicatorRSI, Version: "ta-1", Total: 10, Wins: 6, WinRate: 0.6},
+	}}
+	h := &Handler{
+		tracer:        tracer,
+		signalService: service.NewSignalService(tracer, &stubRepo{}, repo, stubSignalEngine{}),
+	}
+	router := gin.New()
+	router.GET("/api/signals/win-rates", h.GetSignalWinRates)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/signals/win-rates?indicator=rsi&horizon=12", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.lastIndicator != "rsi" || repo.lastHorizon != 12 {
+		t.Fatalf("unexpected query: %q %d", repo.lastIndicator, repo.lastHorizon)
+	}
+	var body struct {
+		Horizon  int                    `json:"horizon"`
+		WinRates []domain.SignalWinRate `json:"win_rates"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if body.Horizon != 12 || len(body.WinRates) != 1 || body.WinRates[0].Version != "ta-1" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/signals/win-rates?horizon=0", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid horizon, got %d", w.Code)
+	}
+}
+
+type handlerSignalStatsStub struct {
+	handlerSignalStoreStub
+	rates         []domain.SignalWinRate
+	lastIndicator string
+	lastHorizon   int
+}
+
+func (s *handlerSignalStatsStub) WinRatesByVersion(ctx context.Context, indicator string, horizon int) ([]domain.SignalWinRate, error) {
+	s.lastIndicator = indicator
+	s.lastHorizon = horizon
+	return s.rates, nil
+}
+
 type handlerSignalStoreStub struct {
 	lastFilter domain.SignalFilter
 	resp       []domain.Signal
@@ -198,6 +272,7 @@ func (s *handlerSignalStoreStub) ListSignals(ctx context.Context, filter domain.
 
 type handlerSignalImageRepoStub struct {
 	imageBySignalID map[int64]*domain.SignalImageData
+	purged          bool
 }
 
 func (s *handlerSignalImageRepoStub) UpsertSignalImageReady(
@@ -237,6 +312,104 @@ func (s *handlerSignalImageRepoStub) ListRetryCandidates(ctx context.Context, li
 	return nil, nil
 }
 
-func (s *handlerSignalImageRepoStub) DeleteExpiredSignalImages(ctx context.Context) (int64, error) {
-	return 0, nil
+func (s *handlerSignalImageRepoStub) DeleteExpiredSignalImages(ctx context.Context, limit int) (int64, error) {
+	return 2, nil
+}
+
+func (s *handlerSignalImageRepoStub) DeleteAllSignalImages(ctx context.Context) (int64, error) {
+	s.purged = true
+	return 9, nil
+}
+
+func TestPurgeSignalImages(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	imageRepo := &handlerSignalImageRepoStub{}
+	h := &Handler{
+		tracer: tracer,
+		signalService: service.NewSignalServiceWithImages(
+			tracer, &stubRepo{}, &handlerSignalStoreStub{}, stubSignalEngine{}, imageRepo, nil,
+		),
+	}
+	router := gin.New()
+	router.POST("/api/admin/signal-images/purge", h.PurgeSignalImages)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signal-images/purge", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"deleted":2`) || imageRepo.purged {
+		t.Fatalf("expected expired-only purge, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signal-images/purge", strings.NewReader(`{"all":true}`)))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"deleted":9`) || !imageRepo.purged {
+		t.Fatalf("expected full purge, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signal-images/purge", strings.NewReader(`{"all":`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a broken body, got %d", w.Code)
+	}
+
+	h.signalService = service.NewSignalService(tracer, &stubRepo{}, &handlerSignalStoreStub{}, stubSignalEngine{})
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/signal-images/purge", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an image store, got %d", w.Code)
+	}
+}
+
+func TestGenerateSignalsReportsPerSymbol(t *testing.T) {
+	gen := &handlerSignalGeneratorStub{}
+	h := &Handler{tracer: trace.NewNoopTracerProvider().Tracer("handler-test"), signalGenerator: gen}
+	router := gin.New()
+	router.POST("/api/signals/generate", h.GenerateSignals)
+
+	w := httptest.NewRecorder()
+	body := `{"symbols":["btc"," BTC","nope"],"intervals":["1h"]}`
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/signals/generate", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Join(gen.symbols, ",") != "BTC,NOPE" || strings.Join(gen.intervals, ",") != "1h" {
+		t.Fatalf("unexpected generator input %v %v", gen.symbols, gen.intervals)
+	}
+	var resp struct {
+		Results   []domain.SignalGenerationResult `json:"results"`
+		Succeeded int                             `json:"succeeded"`
+		Failed    int                             `json:"failed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Succeeded != 1 || resp.Failed != 1 || resp.Results[1].Error == "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	for _, bad := range []string{`{"symbols":[]}`, `{"symbols":["BTC"],"intervals":["2h"]}`, `not json`} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/signals/generate", strings.NewReader(bad)))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", bad, w.Code)
+		}
+	}
+}
+
+type handlerSignalGeneratorStub struct {
+	symbols   []string
+	intervals []string
+}
+
+func (s *handlerSignalGeneratorStub) GenerateSymbols(ctx context.Context, symbols []string, intervals []string) []domain.SignalGenerationResult {
+	s.symbols, s.intervals = symbols, intervals
+	results := make([]domain.SignalGenerationResult, len(symbols))
+	for i, symbol := range symbols {
+		results[i].Symbol = symbol
+		if !domain.IsSupportedSymbol(symbol) {
+			results[i].Error = "unsupported symbol: " + symbol
+			continue
+		}
+		results[i].Signals = []domain.Signal{{Symbol: symbol, Interval: "1h"}}
+	}
+	return results
 }
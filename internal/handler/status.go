@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/internal/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProviderStatus godoc
+// @Summary      Outbound provider health
+// @Description  Returns the circuit breaker of every third-party provider this process has called: its state (closed, open or half_open), consecutive failures, when it opened, and the last error and last success. Empty when breakers are off (PROVIDER_BREAKER_THRESHOLD=0).
+// @Tags         status
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Security     ApiKeyAuth
+// @Router       /api/status/providers [get]
+func (h *Handler) GetProviderStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": httpclient.Statuses()})
+}
+
+// GetProviderQuotas godoc
+// @Summary      Provider quota usage
+// @Description  Returns the calls made to each metered provider (CoinGecko) in the current minute and UTC day against its plan quota (COINGECKO_QUOTA_PER_MIN, COINGECKO_QUOTA_PER_DAY), the calls left, when the daily count resets, and the factor pollers currently stretch their intervals by. A zero limit means none is configured. Circuit breaker states are included as on /api/status/providers.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/providers [get]
+func (h *Handler) GetProviderQuotas(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"quotas": provider.QuotaUsages(), "breakers": httpclient.Statuses()})
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/internal/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetProviderStatus(t *testing.T) {
+	t.Cleanup(func() { _ = httpclient.Configure(httpclient.Config{}) })
+	if err := httpclient.Configure(httpclient.Config{BreakerThreshold: 1, BreakerCooldownSecs: 60}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+	resp, err := httpclient.New(httpclient.FearGreed, 0).Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("upstream request: %v", err)
+	}
+	resp.Body.Close()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{}
+	r.GET("/api/status/providers", h.GetProviderStatus)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/status/providers", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var body struct {
+		Providers []struct {
+			Provider    string  `json:"provider"`
+			State       string  `json:"state"`
+			Failures    int     `json:"consecutive_failures"`
+			LastError   string  `json:"last_error"`
+			LastErrorAt *string `json:"last_error_at"`
+			LastSuccess *string `json:"last_success_at"`
+		} `json:"providers"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	for _, p := range body.Providers {
+		if p.Provider != httpclient.FearGreed {
+			continue
+		}
+		if p.State != httpclient.StateOpen || p.Failures != 1 || p.LastError != "HTTP 503" || p.LastErrorAt == nil || p.LastSuccess != nil {
+			t.Fatalf("unexpected provider status %+v", p)
+		}
+		return
+	}
+	t.Fatalf("expected a feargreed entry, got %s", w.Body.String())
+}
+
+func TestGetProviderQuotas(t *testing.T) {
+	quota := provider.CoinGeckoQuota()
+	quota.SetLimits(30, 1000)
+	t.Cleanup(func() { quota.SetLimits(0, 0) })
+	quota.Record(context.Background())
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := &Handler{}
+	r.GET("/api/admin/providers", h.GetProviderQuotas)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/admin/providers", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var body struct {
+		Quotas []provider.QuotaUsage `json:"quotas"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	for _, q := range body.Quotas {
+		if q.Provider != httpclient.CoinGecko {
+			continue
+		}
+		if q.MinuteLimit != 30 || q.DayLimit != 1000 || q.DayCalls < 1 || q.DayRemaining != 1000-q.DayCalls || q.Stretch != 1 {
+			t.Fatalf("unexpected quota %+v", q)
+		}
+		return
+	}
+	t.Fatalf("expected a coingecko quota, got %s", w.Body.String())
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/internal/storage"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type addSymbolRequest struct {
+	Symbol        string `json:"symbol"`
+	Name          string `json:"name"`
+	CoinGeckoID   string `json:"coingecko_id"`
+	BinancePair   string `json:"binance_pair"`
+	KrakenPair    string `json:"kraken_pair"`
+	CoinbasePair  string `json:"coinbase_pair"`
+	PriceDecimals int    `json:"price_decimals"`
+}
+
+// ListSymbols godoc
+// @Summary      List tracked symbols
+// @Description  Returns the symbol registry in order, with each asset's identifiers at every price source and whether its signals, ML inference or alerts are paused
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/symbols [get]
+func (h *Handler) ListSymbols(c *gin.Context) {
+	if h.symbolRegistry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "symbol registry unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"symbols": h.symbolRegistry.List()})
+}
+
+// AddSymbol godoc
+// @Summary      Track a symbol
+// @Description  Adds an asset to the symbol registry, or updates its identifiers. coingecko_id is required; an empty exchange pair means the asset is not listed there. Price polling, signal generation, ML jobs and the bot pick the asset up without a restart; other processes follow within a minute.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  addSymbolRequest  true  "Symbol and its identifiers"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/symbols [post]
+func (h *Handler) AddSymbol(c *gin.Context) {
+	if h.symbolRegistry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "symbol registry unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.add-symbol")
+	defer span.End()
+
+	var req addSymbolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	asset, err := h.symbolRegistry.Add(ctx, domain.Asset{
+		Symbol:        req.Symbol,
+		Name:          req.Name,
+		CoinGeckoID:   req.CoinGeckoID,
+		BinancePair:   req.BinancePair,
+		KrakenPair:    req.KrakenPair,
+		CoinbasePair:  req.CoinbasePair,
+		PriceDecimals: req.PriceDecimals,
+	})
+	if errors.Is(err, service.ErrInvalidAsset) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"symbol": asset})
+}
+
+// RemoveSymbol godoc
+// @Summary      Stop tracking a symbol
+// @Description  Removes an asset from the symbol registry. Its stored candles, signals and predictions are kept, and adding it again resumes tracking.
+// @Tags         admin
+// @Produce      json
+// @Param        symbol  path  string  true  "Symbol"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/symbols/{symbol} [delete]
+func (h *Handler) RemoveSymbol(c *gin.Context) {
+	if h.symbolRegistry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "symbol registry unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.remove-symbol")
+	defer span.End()
+
+	err := h.symbolRegistry.Remove(ctx, c.Param("symbol"))
+	switch {
+	case errors.Is(err, service.ErrInvalidAsset):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case storage.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "symbol is not tracked"})
+		return
+	case err != nil:
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "removed"})
+}
+
+// SetSymbolPauses godoc
+// @Summary      Pause parts of the pipeline for a symbol
+// @Description  Replaces the stored pauses of a tracked symbol: signals_paused stops signal generation, ml_paused stops ML inference and alerts_paused stops alerts while signals are still stored. Prices and candles keep being collected. Pauses from SYMBOL_PAUSES stay in force whatever is stored. Other processes follow within a minute.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        symbol  path  string              true  "Symbol"
+// @Param        body    body  domain.AssetPauses  true  "Pauses; omitted flags are cleared"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/admin/symbols/{symbol}/pauses [put]
+func (h *Handler) SetSymbolPauses(c *gin.Context) {
+	if h.symbolRegistry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "symbol registry unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.set-symbol-pauses")
+	defer span.End()
+
+	var req domain.AssetPauses
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	asset, err := h.symbolRegistry.SetPauses(ctx, c.Param("symbol"), req)
+	switch {
+	case storage.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "symbol is not tracked"})
+		return
+	case err != nil:
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"symbol": asset})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type handlerAssetStoreStub struct {
+	assets []domain.Asset
+}
+
+func (s *handlerAssetStoreStub) ListAssets(context.Context) ([]domain.Asset, error) {
+	return s.assets, nil
+}
+
+func (s *handlerAssetStoreStub) UpsertAsset(_ context.Context, a domain.Asset) (domain.Asset, error) {
+	s.assets = append(s.assets, a)
+	return a, nil
+}
+
+func (s *handlerAssetStoreStub) DisableAsset(context.Context, string) error {
+	return pgx.ErrNoRows
+}
+
+func (s *handlerAssetStoreStub) SetAssetPauses(_ context.Context, symbol string, p domain.AssetPauses) (domain.Asset, error) {
+	for i, a := range s.assets {
+		if a.Symbol == symbol {
+			s.assets[i].AssetPauses = p
+			return s.assets[i], nil
+		}
+	}
+	return domain.Asset{}, pgx.ErrNoRows
+}
+
+func TestSymbolAdminRoutes(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &handlerAssetStoreStub{assets: append([]domain.Asset(nil), domain.DefaultAssets...)}
+	h := &Handler{tracer: tracer}
+	h.SetSymbolRegistryService(service.NewSymbolRegistryService(tracer, store, nil))
+
+	router := gin.New()
+	router.GET("/api/admin/symbols", h.ListSymbols)
+	router.POST("/api/admin/symbols", h.AddSymbol)
+	router.DELETE("/api/admin/symbols/:symbol", h.RemoveSymbol)
+	router.PUT("/api/admin/symbols/:symbol/pauses", h.SetSymbolPauses)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/symbols",
+		bytes.NewBufferString(`{"symbol":"PEPE","coingecko_id":"pepe","binance_pair":"PEPEUSDT"}`)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/admin/symbols", nil))
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"symbol":"PEPE"`)) {
+		t.Fatalf("expected PEPE in the registry, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/symbols", bytes.NewBufferString(`{"symbol":"X"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/admin/symbols/NOPE", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/admin/symbols/pepe/pauses",
+		bytes.NewBufferString(`{"ml_paused":true}`)))
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"ml_paused":true`)) || !domain.MLPaused("PEPE") {
+		t.Fatalf("expected PEPE ML paused, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/admin/symbols/NOPE/pauses", bytes.NewBufferString(`{}`)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
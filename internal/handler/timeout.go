@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default request budgets. Long-running routes are the operator triggers
+// that do a full cycle of work inline, such as training or generating
+// signals for many symbols.
+const (
+	DefaultRequestTimeout     = 30 * time.Second
+	DefaultLongRequestTimeout = 10 * time.Minute
+)
+
+// RequestTimeout bounds the request context to d, so services, repositories
+// and provider calls made with it give up instead of holding the worker. A
+// non-positive d leaves the request unbounded.
+func RequestTimeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// respondError answers 504 when err or the request ran out of time, and 500
+// otherwise, so clients can tell a slow dependency from a failure.
+func respondError(c *gin.Context, err error) {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
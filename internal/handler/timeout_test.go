@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeoutAnswersGatewayTimeout(t *testing.T) {
+	r := gin.New()
+	r.GET("/slow", RequestTimeout(10*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		respondError(c, fmt.Errorf("query: %w", c.Request.Context().Err()))
+	})
+	r.GET("/silent", RequestTimeout(10*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	r.GET("/fails", RequestTimeout(time.Second), func(c *gin.Context) {
+		respondError(c, context.Canceled)
+	})
+
+	for path, want := range map[string]int{
+		"/slow":   http.StatusGatewayTimeout,
+		"/silent": http.StatusGatewayTimeout,
+		"/fails":  http.StatusInternalServerError,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Fatalf("%s: expected %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestRequestTimeoutZeroLeavesRequestUnbounded(t *testing.T) {
+	r := gin.New()
+	r.GET("/", RequestTimeout(0), func(c *gin.Context) {
+		if _, ok := c.Request.Context().Deadline(); ok {
+			t.Fatal("expected no deadline")
+		}
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timestampPattern matches JSON strings holding an RFC 3339 timestamp, the
+// form encoding/json writes time.Time in.
+var timestampPattern = regexp.MustCompile(`"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d{1,9})?(?:Z|[+-]\d{2}:\d{2})"`)
+
+// LocalTime shows the timestamps of JSON responses in the IANA time zone
+// named by the tz query parameter, such as ?tz=Europe/Berlin, with its UTC
+// offset. Storage and time filters in the request stay UTC. An unknown
+// zone is answered with 400.
+func LocalTime() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := strings.TrimSpace(c.Query("tz"))
+		if name == "" {
+			c.Next()
+			return
+		}
+		loc, err := domain.LoadTimezone(name)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if loc == time.UTC {
+			c.Next()
+			return
+		}
+
+		w := &localTimeWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		body := w.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			body = localizeTimestamps(body, loc)
+			w.Header().Del("Content-Length")
+		}
+		if w.wrote {
+			_, _ = w.ResponseWriter.Write(body)
+		}
+	}
+}
+
+// localizeTimestamps rewrites the RFC 3339 timestamps in a JSON body to loc.
+func localizeTimestamps(body []byte, loc *time.Location) []byte {
+	return timestampPattern.ReplaceAllFunc(body, func(quoted []byte) []byte {
+		t, err := time.Parse(time.RFC3339Nano, string(quoted[1:len(quoted)-1]))
+		if err != nil {
+			return quoted
+		}
+		return []byte(`"` + t.In(loc).Format(time.RFC3339Nano) + `"`)
+	})
+}
+
+// localTimeWriter holds the response body until the handler is done, so
+// its timestamps can be rewritten.
+type localTimeWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	wrote bool
+}
+
+func (w *localTimeWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.body.Write(b)
+}
+
+func (w *localTimeWriter) WriteString(s string) (int, error) {
+	w.wrote = true
+	return w.body.WriteString(s)
+}
+
+func (w *localTimeWriter) Written() bool {
+	return w.wrote || w.ResponseWriter.Written()
+}
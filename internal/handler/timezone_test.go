@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLocalTimeRewritesResponseTimestamps(t *testing.T) {
+	r := gin.New()
+	r.GET("/signals", LocalTime(), RequestTimeout(time.Second), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"timestamp":  time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+			"created_at": time.Date(2026, 7, 1, 8, 30, 0, 500, time.UTC),
+			"day":        "2026-01-15",
+			"details":    "crossed at 12:00",
+		})
+	})
+	r.GET("/empty", LocalTime(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := serve("/signals?tz=America/New_York")
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `"timestamp":"2026-01-15T07:00:00-05:00"`) ||
+		!strings.Contains(body, `"created_at":"2026-07-01T04:30:00.0000005-04:00"`) || !strings.Contains(body, `"day":"2026-01-15"`) {
+		t.Fatalf("unexpected localized body %d %s", w.Code, body)
+	}
+
+	if body := serve("/signals").Body.String(); !strings.Contains(body, `"timestamp":"2026-01-15T12:00:00Z"`) {
+		t.Fatalf("expected UTC without tz, got %s", body)
+	}
+	if w := serve("/signals?tz=Mars/Base"); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unknown time zone") {
+		t.Fatalf("expected 400 for an unknown zone, got %d %s", w.Code, w.Body.String())
+	}
+	if w := serve("/empty?tz=Asia/Tokyo"); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("expected an empty 204, got %d %q", w.Code, w.Body.String())
+	}
+}
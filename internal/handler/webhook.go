@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookRequest struct {
+	Label           string            `json:"label"`
+	URL             string            `json:"url"`
+	PayloadTemplate string            `json:"payload_template"`
+	Headers         map[string]string `json:"headers"`
+}
+
+// ListWebhooks godoc
+// @Summary      List signal webhooks
+// @Description  Returns the caller's tenant's HTTP endpoints that receive new signals. Header values are redacted. /api/admin/webhooks is the same list for the operator.
+// @Tags         admin
+// @Produce      json
+// @Param        include_disabled  query  bool  false  "Include disabled webhooks"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/webhooks [get]
+// @Router       /api/admin/webhooks [get]
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	if h.webhookService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "webhook service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.list-webhooks")
+	defer span.End()
+
+	includeDisabled := strings.EqualFold(strings.TrimSpace(c.Query("include_disabled")), "true")
+	webhooks, err := h.webhookService.List(ctx, includeDisabled)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+// CreateWebhook godoc
+// @Summary      Add a signal webhook
+// @Description  Registers an HTTP endpoint, owned by the caller's tenant, that receives a POST for every new signal. payload_template is a Go text/template over the signal (for example {{.Symbol}}, {{.Direction}}, {{.Prediction.prob_up}}) with json, upper and lower functions; without one the signal is sent as JSON. headers are added to every request.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        body  body  createWebhookRequest  true  "URL, optional template and headers"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/webhooks [post]
+// @Router       /api/admin/webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	if h.webhookService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "webhook service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.create-webhook")
+	defer span.End()
+
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	webhook, err := h.webhookService.Create(ctx, domain.SignalWebhook{
+		Label:           req.Label,
+		URL:             req.URL,
+		PayloadTemplate: req.PayloadTemplate,
+		Headers:         req.Headers,
+	})
+	if errors.Is(err, service.ErrInvalidWebhook) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"webhook": webhook})
+}
+
+// DisableWebhook godoc
+// @Summary      Disable a signal webhook
+// @Description  Disables one of the caller's tenant's webhooks.
+// @Tags         admin
+// @Produce      json
+// @Param        id  path  int  true  "Webhook ID"
+// @Success      200  {object}  map[string]string
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      503  {object}  map[string]string
+// @Security     ApiKeyAuth
+// @Router       /api/webhooks/{id} [delete]
+// @Router       /api/admin/webhooks/{id} [delete]
+func (h *Handler) DisableWebhook(c *gin.Context) {
+	if h.webhookService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "webhook service unavailable"})
+		return
+	}
+	ctx, span := h.tracer.Start(c.Request.Context(), "handler.disable-webhook")
+	defer span.End()
+
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a positive integer"})
+		return
+	}
+	if err := h.webhookService.Disable(ctx, id); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "disabled"})
+}
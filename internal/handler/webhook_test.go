@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type webhookStoreStub struct {
+	webhooks []domain.SignalWebhook
+	disabled []int64
+}
+
+func (s *webhookStoreStub) CreateWebhook(ctx context.Context, webhook domain.SignalWebhook) (*domain.SignalWebhook, error) {
+	webhook.ID = int64(len(s.webhooks) + 1)
+	webhook.TenantID = domain.TenantFromContext(ctx)
+	s.webhooks = append(s.webhooks, webhook)
+	return &webhook, nil
+}
+
+func (s *webhookStoreStub) DisableWebhook(ctx context.Context, id int64) error {
+	s.disabled = append(s.disabled, id)
+	return nil
+}
+
+func (s *webhookStoreStub) ListWebhooks(ctx context.Context, includeDisabled bool) ([]domain.SignalWebhook, error) {
+	return append([]domain.SignalWebhook(nil), s.webhooks...), nil
+}
+
+func (s *webhookStoreStub) ListActiveWebhooks(ctx context.Context) ([]domain.SignalWebhook, error) {
+	return append([]domain.SignalWebhook(nil), s.webhooks...), nil
+}
+
+func TestWebhookAdminRoutes(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	h := &Handler{tracer: tracer}
+	router := gin.New()
+	router.GET("/api/admin/webhooks", h.ListWebhooks)
+	router.POST("/api/admin/webhooks", h.CreateWebhook)
+	router.DELETE("/api/admin/webhooks/:id", h.DisableWebhook)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return w
+	}
+
+	if w := do(http.MethodGet, "/api/admin/webhooks", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without a webhook service, got %d", w.Code)
+	}
+
+	store := &webhookStoreStub{}
+	h.SetWebhookService(service.NewWebhookService(tracer, store, nil))
+
+	if w := do(http.MethodPost, "/api/admin/webhooks", `{"url":"https://example.com","payload_template":"{{.Symbol"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a broken template, got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/api/admin/webhooks", `{"label":"discord","url":"https://discord.example/api/webhooks/1","payload_template":"{\"content\":{{json .Symbol}}}","headers":{"X-Token":"secret"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if bytes.Contains(w.Body.Bytes(), []byte("secret")) {
+		t.Fatalf("header values must be redacted: %s", w.Body.String())
+	}
+
+	w = do(http.MethodGet, "/api/admin/webhooks", "")
+	var body struct {
+		Webhooks []domain.SignalWebhook `json:"webhooks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(body.Webhooks) != 1 || body.Webhooks[0].Label != "discord" || body.Webhooks[0].Headers["X-Token"] != "[redacted]" {
+		t.Fatalf("unexpected webhooks: %s", w.Body.String())
+	}
+
+	if w := do(http.MethodDelete, "/api/admin/webhooks/x", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad id, got %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/api/admin/webhooks/1", ""); w.Code != http.StatusOK || len(store.disabled) != 1 {
+		t.Fatalf("expected webhook 1 to be disabled, got %d (%v)", w.Code, store.disabled)
+	}
+}
+
+func TestWebhookRoutesAreOpenToTenants(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("handler-test")
+	store := &webhookStoreStub{}
+	h := &Handler{tracer: tracer}
+	h.SetWebhookService(service.NewWebhookService(tracer, store, nil))
+
+	router := gin.New()
+	protected := router.Group("")
+	protected.Use(TenantAPIKeyAuth(TenantAuthConfig{StaticKeys: map[string]string{"k": "acme"}}))
+	h.RegisterRoutes(protected)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", "k")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/api/admin/webhooks", `{"url":"https://example.com/hook"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a tenant on the admin route, got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/api/webhooks", `{"url":"https://example.com/hook"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.webhooks) != 1 || store.webhooks[0].TenantID != "acme" {
+		t.Fatalf("expected the webhook stored for acme, got %+v", store.webhooks)
+	}
+}
@@ -0,0 +1,190 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit breaker states.
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// ErrCircuitOpen is returned, wrapped, for requests to a provider whose
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit open")
+
+// Status is a provider's circuit breaker state for GET
+// /api/status/providers. Failures counts consecutive failed requests.
+type Status struct {
+	Provider      string     `json:"provider"`
+	State         string     `json:"state"`
+	Failures      int        `json:"consecutive_failures"`
+	OpenedAt      *time.Time `json:"opened_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*breaker{}
+	breakerNow = time.Now
+)
+
+// breaker trips after threshold consecutive failures and rejects requests
+// for the cooldown. Then one probe request is let through: success closes
+// the circuit, failure opens it for another cooldown.
+type breaker struct {
+	mu            sync.Mutex
+	provider      string
+	state         string
+	failures      int
+	probing       bool
+	openedAt      time.Time
+	lastError     string
+	lastErrorAt   time.Time
+	lastSuccessAt time.Time
+}
+
+func breakerFor(provider string) *breaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[provider]
+	if !ok {
+		b = &breaker{provider: provider, state: StateClosed}
+		breakers[provider] = b
+	}
+	return b
+}
+
+// allow reports whether a request may be sent. An open circuit whose
+// cooldown has passed turns half-open and admits a single probe.
+func (b *breaker) allow(cooldown time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if breakerNow().Sub(b.openedAt) < cooldown {
+			return fmt.Errorf("%s %w since %s: %s", b.provider, ErrCircuitOpen, b.openedAt.UTC().Format(time.RFC3339), b.lastError)
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s %w, probe in flight: %s", b.provider, ErrCircuitOpen, b.lastError)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state != StateClosed {
+		log.Printf("provider circuit for %s closed", b.provider)
+	}
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+	b.lastSuccessAt = breakerNow()
+}
+
+func (b *breaker) failure(threshold int, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := breakerNow()
+	b.failures++
+	b.lastError = reason
+	b.lastErrorAt = now
+	b.probing = false
+	if b.state == StateHalfOpen || (b.state == StateClosed && b.failures >= threshold) {
+		if b.state == StateClosed {
+			log.Printf("provider circuit for %s opened after %d failures: %s", b.provider, b.failures, reason)
+		}
+		b.state = StateOpen
+		b.openedAt = now
+	}
+}
+
+// release gives up a probe whose caller went away before an answer.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := Status{Provider: b.provider, State: b.state, Failures: b.failures, LastError: b.lastError}
+	if b.state != StateClosed {
+		t := b.openedAt.UTC()
+		s.OpenedAt = &t
+	}
+	if !b.lastErrorAt.IsZero() {
+		t := b.lastErrorAt.UTC()
+		s.LastErrorAt = &t
+	}
+	if !b.lastSuccessAt.IsZero() {
+		t := b.lastSuccessAt.UTC()
+		s.LastSuccessAt = &t
+	}
+	return s
+}
+
+// Statuses returns the breaker state of every provider a client was built
+// for while breakers were on, by name.
+func Statuses() []Status {
+	breakersMu.Lock()
+	list := make([]*breaker, 0, len(breakers))
+	for _, b := range breakers {
+		list = append(list, b)
+	}
+	breakersMu.Unlock()
+
+	out := make([]Status, 0, len(list))
+	for _, b := range list {
+		out = append(out, b.status())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// breakerTransport sends requests through next while the provider's
+// circuit is closed. Transport errors and 5xx answers count as failures;
+// a request cancelled by its caller counts as neither.
+type breakerTransport struct {
+	breaker   *breaker
+	threshold int
+	cooldown  time.Duration
+	next      http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.allow(t.cooldown); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		t.breaker.release()
+	case err != nil:
+		t.breaker.failure(t.threshold, err.Error())
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.breaker.failure(t.threshold, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	default:
+		t.breaker.success()
+	}
+	return resp, err
+}
@@ -0,0 +1,117 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensThenProbesAndCloses(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	breakerNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		breakerNow = time.Now
+		_ = Configure(Config{})
+		breakersMu.Lock()
+		delete(breakers, Kraken)
+		breakersMu.Unlock()
+	})
+
+	var status, calls atomic.Int32
+	status.Store(http.StatusBadGateway)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	if err := Configure(Config{BreakerThreshold: 2, BreakerCooldownSecs: 30}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	client := New(Kraken, time.Second)
+	get := func() error {
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := get(); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if err := get(); !errors.Is(err, ErrCircuitOpen) || calls.Load() != 2 {
+		t.Fatalf("expected the third request rejected without a call, got %v after %d calls", err, calls.Load())
+	}
+	st := providerStatus(t, Kraken)
+	if st.State != StateOpen || st.Failures != 2 || st.LastError != "HTTP 502" || st.OpenedAt == nil || st.LastSuccessAt != nil {
+		t.Fatalf("unexpected open status: %+v", st)
+	}
+
+	// After the cooldown a failing probe reopens the circuit.
+	now = now.Add(31 * time.Second)
+	if err := get(); err != nil || calls.Load() != 3 {
+		t.Fatalf("expected a probe request, got %v after %d calls", err, calls.Load())
+	}
+	if st := providerStatus(t, Kraken); st.State != StateOpen || !st.OpenedAt.Equal(now) {
+		t.Fatalf("expected the failed probe to reopen the circuit, got %+v", st)
+	}
+
+	// A successful probe closes it.
+	now = now.Add(31 * time.Second)
+	status.Store(http.StatusOK)
+	if err := get(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	st = providerStatus(t, Kraken)
+	if st.State != StateClosed || st.Failures != 0 || st.OpenedAt != nil || st.LastSuccessAt == nil || !st.LastSuccessAt.Equal(now) ||
+		st.LastError != "HTTP 502" {
+		t.Fatalf("unexpected closed status: %+v", st)
+	}
+}
+
+func TestBreakerAdmitsOneProbeAtATime(t *testing.T) {
+	b := &breaker{provider: "test", state: StateOpen, openedAt: time.Now().Add(-time.Minute)}
+	if err := b.allow(time.Second); err != nil {
+		t.Fatalf("expected a probe after the cooldown, got %v", err)
+	}
+	if err := b.allow(time.Second); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a second request rejected while probing, got %v", err)
+	}
+	b.release()
+	if err := b.allow(time.Second); err != nil || b.state != StateHalfOpen {
+		t.Fatalf("expected a released probe to be retried, got %v in %s", err, b.state)
+	}
+}
+
+func TestWebhooksHaveNoBreaker(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	if err := Configure(Config{BreakerThreshold: 1, BreakerCooldownSecs: 1}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if _, ok := New(Webhooks, 0).Transport.(*breakerTransport); ok {
+		t.Fatal("expected webhooks without a breaker")
+	}
+	if _, ok := New(CoinGecko, 0).Transport.(*breakerTransport); !ok {
+		t.Fatal("expected providers behind a breaker")
+	}
+	if err := Configure(Config{BreakerThreshold: 3}); err == nil {
+		t.Fatal("expected a threshold without a cooldown to fail")
+	}
+}
+
+func providerStatus(t *testing.T, provider string) Status {
+	t.Helper()
+	for _, st := range Statuses() {
+		if st.Provider == provider {
+			return st
+		}
+	}
+	t.Fatalf("no status for %s", provider)
+	return Status{}
+}
@@ -0,0 +1,250 @@
+// Package httpclient builds the HTTP clients used for outbound calls to
+// third-party APIs, so proxy and TLS settings for locked-down networks are
+// applied in one place. Call Configure once at startup; until then clients
+// behave like net/http defaults, honoring HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY. Responses can also be recorded to golden files and replayed, for
+// deterministic tests and offline development. Each provider's requests go
+// through a circuit breaker that stops calling it after repeated failures.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the outbound integrations, used as keys for per-provider proxy
+// overrides.
+const (
+	CoinGecko = "coingecko"
+	Binance   = "binance"
+	// BinanceFutures is the USDT-margined futures API, a separate host
+	// from spot that some networks route differently.
+	BinanceFutures = "binancefutures"
+	Kraken         = "kraken"
+	Coinbase       = "coinbase"
+	OpenAI         = "openai"
+	Telegram       = "telegram"
+	Mempool        = "mempool"
+	Blockscout     = "blockscout"
+	Koios          = "koios"
+	XRPScan        = "xrpscan"
+	Reddit         = "reddit"
+	RSS            = "rss"
+	FearGreed      = "feargreed"
+	Webhooks       = "webhooks"
+	KafkaREST      = "kafka"
+	// EthereumRPC is the Ethereum JSON-RPC endpoint used for on-chain DEX
+	// pool prices.
+	EthereumRPC = "ethrpc"
+	Jupiter     = "jupiter"
+	// Frankfurter serves the ECB reference FX rates prices are converted
+	// with.
+	Frankfurter = "frankfurter"
+)
+
+// Direct as a proxy override sends that provider's requests without a
+// proxy, even when HTTPS_PROXY is set.
+const Direct = "direct"
+
+// Config holds the outbound network settings.
+type Config struct {
+	// ProxyURL replaces the proxy from the environment for every provider
+	// without an override. Empty keeps HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+	ProxyURL string
+	// ProviderProxies maps a provider name to a proxy URL or Direct.
+	ProviderProxies map[string]string
+	// CABundle is a PEM file of extra trusted roots, added to the system
+	// pool.
+	CABundle string
+	// TLSMinVersion is "1.2" or "1.3". Empty keeps the Go default.
+	TLSMinVersion string
+	// RecordMode is Record or Replay to capture or replay provider
+	// responses in RecordDir, one subdirectory per provider. Empty calls
+	// providers normally.
+	RecordMode string
+	RecordDir  string
+	// BreakerThreshold is how many consecutive failed requests open a
+	// provider's circuit breaker. 0 turns the breakers off.
+	BreakerThreshold int
+	// BreakerCooldownSecs is how long an open circuit rejects requests
+	// before a probe is let through.
+	BreakerCooldownSecs int
+}
+
+var (
+	mu         sync.Mutex
+	current    settings
+	transports = map[string]*http.Transport{}
+	recorders  = map[string]*Recorder{}
+)
+
+type settings struct {
+	proxy     *url.URL
+	overrides map[string]*url.URL // nil value means Direct
+	rootCAs   *x509.CertPool
+	minTLS    uint16
+	recMode   string
+	recDir    string
+
+	breakerThreshold int
+	breakerCooldown  time.Duration
+}
+
+// Configure validates cfg and applies it to clients created afterwards.
+func Configure(cfg Config) error {
+	next := settings{overrides: map[string]*url.URL{}}
+	if raw := strings.TrimSpace(cfg.ProxyURL); raw != "" {
+		u, err := parseProxyURL(raw)
+		if err != nil {
+			return err
+		}
+		next.proxy = u
+	}
+	for name, raw := range cfg.ProviderProxies {
+		name = strings.ToLower(strings.TrimSpace(name))
+		raw = strings.TrimSpace(raw)
+		if strings.EqualFold(raw, Direct) {
+			next.overrides[name] = nil
+			continue
+		}
+		u, err := parseProxyURL(raw)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		next.overrides[name] = u
+	}
+	if path := strings.TrimSpace(cfg.CABundle); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("CA bundle %s contains no PEM certificates", path)
+		}
+		next.rootCAs = pool
+	}
+	switch strings.TrimSpace(cfg.TLSMinVersion) {
+	case "":
+	case "1.2":
+		next.minTLS = tls.VersionTLS12
+	case "1.3":
+		next.minTLS = tls.VersionTLS13
+	default:
+		return fmt.Errorf("unsupported TLS min version %q, want 1.2 or 1.3", cfg.TLSMinVersion)
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(cfg.RecordMode)); mode {
+	case "":
+	case Record, Replay:
+		if strings.TrimSpace(cfg.RecordDir) == "" {
+			return fmt.Errorf("%s mode needs a recording directory", mode)
+		}
+		next.recMode = mode
+		next.recDir = strings.TrimSpace(cfg.RecordDir)
+	default:
+		return fmt.Errorf("unsupported record mode %q, want record or replay", cfg.RecordMode)
+	}
+
+	if cfg.BreakerThreshold < 0 || (cfg.BreakerThreshold > 0 && cfg.BreakerCooldownSecs <= 0) {
+		return fmt.Errorf("circuit breaker needs a threshold of 0 or more and a positive cooldown")
+	}
+	next.breakerThreshold = cfg.BreakerThreshold
+	next.breakerCooldown = time.Duration(cfg.BreakerCooldownSecs) * time.Second
+
+	mu.Lock()
+	current = next
+	transports = map[string]*http.Transport{}
+	recorders = map[string]*Recorder{}
+	mu.Unlock()
+	return nil
+}
+
+// New returns a client for provider with the given timeout. Clients for the
+// same provider share a transport and its connection pool, and in record or
+// replay mode a Recorder. When breakers are configured they also share a
+// circuit breaker; webhooks have none, since each one is a different host.
+func New(provider string, timeout time.Duration) *http.Client {
+	rt := roundTripper(provider)
+	mu.Lock()
+	threshold, cooldown := current.breakerThreshold, current.breakerCooldown
+	mu.Unlock()
+	if name := strings.ToLower(strings.TrimSpace(provider)); threshold > 0 && name != Webhooks {
+		rt = &breakerTransport{breaker: breakerFor(name), threshold: threshold, cooldown: cooldown, next: rt}
+	}
+	return &http.Client{Transport: rt, Timeout: timeout}
+}
+
+func roundTripper(provider string) http.RoundTripper {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	t := Transport(provider)
+	mu.Lock()
+	defer mu.Unlock()
+	if current.recMode == "" {
+		return t
+	}
+	if r, ok := recorders[provider]; ok {
+		return r
+	}
+	r := NewRecorder(current.recMode, filepath.Join(current.recDir, provider), t)
+	recorders[provider] = r
+	return r
+}
+
+// Transport returns the transport for provider under the current settings.
+func Transport(provider string) *http.Transport {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	mu.Lock()
+	defer mu.Unlock()
+	if t, ok := transports[provider]; ok {
+		return t
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = current.proxyFunc(provider)
+	if current.rootCAs != nil || current.minTLS != 0 {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = current.rootCAs
+		t.TLSClientConfig.MinVersion = current.minTLS
+	}
+	transports[provider] = t
+	return t
+}
+
+func (s settings) proxyFunc(provider string) func(*http.Request) (*url.URL, error) {
+	if u, ok := s.overrides[provider]; ok {
+		if u == nil {
+			return nil
+		}
+		return http.ProxyURL(u)
+	}
+	if s.proxy != nil {
+		return http.ProxyURL(s.proxy)
+	}
+	return http.ProxyFromEnvironment
+}
+
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https or socks5", raw)
+	}
+}
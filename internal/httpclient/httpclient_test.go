@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTransportProxySelection(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	err := Configure(Config{
+		ProxyURL: "http://corp-proxy:3128",
+		ProviderProxies: map[string]string{
+			"Telegram":  "http://tg-proxy:8080",
+			"coingecko": "direct",
+		},
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.example.com", nil)
+
+	for provider, want := range map[string]string{
+		OpenAI:    "http://corp-proxy:3128",
+		Telegram:  "http://tg-proxy:8080",
+		CoinGecko: "",
+	} {
+		proxy := Transport(provider).Proxy
+		got := ""
+		if proxy != nil {
+			u, err := proxy(req)
+			if err != nil {
+				t.Fatalf("%s: proxy: %v", provider, err)
+			}
+			if u != nil {
+				got = u.String()
+			}
+		}
+		if got != want {
+			t.Fatalf("%s: expected proxy %q, got %q", provider, want, got)
+		}
+	}
+	if Transport(OpenAI) != Transport(OpenAI) {
+		t.Fatal("expected clients of one provider to share a transport")
+	}
+}
+
+func TestConfigureRejectsBadSettings(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	for name, cfg := range map[string]Config{
+		"proxy without host":  {ProxyURL: "corp-proxy"},
+		"ftp proxy":           {ProxyURL: "ftp://corp-proxy:21"},
+		"bad override":        {ProviderProxies: map[string]string{"reddit": "::"}},
+		"missing bundle":      {CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+		"unsupported version": {TLSMinVersion: "1.1"},
+	} {
+		if err := Configure(cfg); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCABundleTrustsPrivateRoot(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if _, err := New(CoinGecko, 5*time.Second).Get(server.URL); err == nil {
+		t.Fatal("expected the test server's certificate to be untrusted by default")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, pemBytes, 0o600); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	if err := Configure(Config{CABundle: bundle, TLSMinVersion: "1.2"}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if got := Transport(CoinGecko).TLSClientConfig.MinVersion; got != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2 minimum, got %x", got)
+	}
+	resp, err := New(CoinGecko, 5*time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the bundle to be trusted: %v", err)
+	}
+	resp.Body.Close()
+}
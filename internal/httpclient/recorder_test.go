@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRecorderRecordsThenReplaysInOrder(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"price":` + strconv.Itoa(100+calls) + `}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recording := &http.Client{Transport: NewRecorder(Record, dir, nil)}
+	for i := 0; i < 2; i++ {
+		if got := mustGet(t, recording, server.URL+"/price?ids=bitcoin"); got != `{"price":`+strconv.Itoa(101+i)+`}` {
+			t.Fatalf("record pass %d returned %s", i, got)
+		}
+	}
+	server.Close()
+
+	replaying := &http.Client{Transport: NewRecorder(Replay, dir, nil)}
+	for _, want := range []string{`{"price":101}`, `{"price":102}`, `{"price":102}`} {
+		if got := mustGet(t, replaying, server.URL+"/price?ids=bitcoin"); got != want {
+			t.Fatalf("expected replayed %s, got %s", want, got)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("replay must not reach the network, got %d calls", calls)
+	}
+
+	_, err := replaying.Get(server.URL + "/price?ids=ethereum")
+	if !errors.Is(err, ErrNoRecording) {
+		t.Fatalf("expected ErrNoRecording for an unrecorded URL, got %v", err)
+	}
+}
+
+func TestRecorderReplaysTimeWindowedPagesOnLaterRuns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`page-` + r.URL.Query().Get("startTime")))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recording := &http.Client{Transport: NewRecorder(Record, dir, nil)}
+	mustGet(t, recording, server.URL+"/klines?symbol=BTCUSDT&startTime=1000&limit=2")
+	mustGet(t, recording, server.URL+"/klines?symbol=BTCUSDT&startTime=3000&limit=2")
+	server.Close()
+
+	// A later run asks for windows relative to its own clock.
+	replaying := &http.Client{Transport: NewRecorder(Replay, dir, nil)}
+	for _, want := range []string{"page-1000", "page-3000"} {
+		if got := mustGet(t, replaying, server.URL+"/klines?symbol=BTCUSDT&startTime=9000&limit=2"); got != want {
+			t.Fatalf("expected replayed %s, got %s", want, got)
+		}
+	}
+	if _, err := replaying.Get(server.URL + "/klines?symbol=ETHUSDT&startTime=9000&limit=2"); !errors.Is(err, ErrNoRecording) {
+		t.Fatalf("expected other parameters to still select the recording, got %v", err)
+	}
+}
+
+func TestRecordingKeyIncludesBody(t *testing.T) {
+	a := RecordingKey(http.MethodPost, "https://api.example.com/chat", []byte(`{"q":"a"}`))
+	b := RecordingKey(http.MethodPost, "https://api.example.com/chat", []byte(`{"q":"b"}`))
+	get := RecordingKey(http.MethodGet, "https://api.example.com/chat", nil)
+	if a == b || a == get || len(a) != 32 {
+		t.Fatalf("expected distinct 32-char keys, got %s %s %s", a, b, get)
+	}
+}
+
+func TestConfigureRecordMode(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(Config{}) })
+	if err := Configure(Config{RecordMode: Replay}); err == nil {
+		t.Fatal("expected replay without a directory to fail")
+	}
+	if err := Configure(Config{RecordMode: "rewind", RecordDir: t.TempDir()}); err == nil {
+		t.Fatal("expected an unknown mode to fail")
+	}
+	if err := Configure(Config{RecordMode: "Replay", RecordDir: t.TempDir()}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if _, ok := New(CoinGecko, 0).Transport.(*Recorder); !ok {
+		t.Fatal("expected clients to record or replay once configured")
+	}
+	if New(CoinGecko, 0).Transport != New(CoinGecko, 0).Transport {
+		t.Fatal("expected clients of one provider to share a recorder")
+	}
+}
+
+func mustGet(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,113 @@
+// Package ical writes the subset of RFC 5545 needed to publish read-only
+// event feeds: VEVENTs with an optional recurrence rule.
+package ical
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	timeLayout = "20060102T150405Z"
+	// lineLimit is the longest content line in octets before folding.
+	lineLimit = 75
+)
+
+// Event is one VEVENT. A zero End makes the event instantaneous.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	// RRule is a recurrence rule value such as "FREQ=DAILY", without the
+	// "RRULE:" prefix.
+	RRule      string
+	Categories []string
+}
+
+// Calendar is a VCALENDAR. Stamp is written as every event's DTSTAMP.
+type Calendar struct {
+	ProdID string
+	Name   string
+	Stamp  time.Time
+	Events []Event
+}
+
+// Encode renders the calendar with CRLF line endings and folded long lines.
+func (c Calendar) Encode() []byte {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeFolded(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", c.ProdID)
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", formatTime(c.Stamp))
+		line("DTSTART", formatTime(e.Start))
+		end := e.End
+		if end.IsZero() {
+			end = e.Start
+		}
+		line("DTEND", formatTime(end))
+		if e.RRule != "" {
+			line("RRULE", e.RRule)
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			cats := make([]string, len(e.Categories))
+			for i, cat := range e.Categories {
+				cats[i] = escapeText(cat)
+			}
+			line("CATEGORIES", strings.Join(cats, ","))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return []byte(b.String())
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeFolded writes one content line, folding it into continuation lines
+// that start with a space. It never splits a UTF-8 sequence.
+func writeFolded(b *strings.Builder, s string) {
+	limit := lineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts toward the next line's limit.
+		limit = lineLimit - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
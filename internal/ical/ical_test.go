@@ -0,0 +1,64 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	start := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		ProdID: "-//test//EN",
+		Name:   "Ops",
+		Stamp:  start,
+		Events: []Event{{
+			UID:         "train@test",
+			Summary:     "ML training; daily",
+			Description: "line one\nline two, more",
+			Start:       start,
+			End:         start.Add(time.Hour),
+			RRule:       "FREQ=DAILY",
+			Categories:  []string{"ml-training"},
+		}},
+	}
+	out := string(cal.Encode())
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"DTSTART:20261016T020000Z\r\n",
+		"DTEND:20261016T030000Z\r\n",
+		"RRULE:FREQ=DAILY\r\n",
+		`SUMMARY:ML training\; daily` + "\r\n",
+		`DESCRIPTION:line one\nline two\, more` + "\r\n",
+		"CATEGORIES:ml-training\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	cal := Calendar{ProdID: "-//test//EN", Events: []Event{{
+		UID:         "long@test",
+		Summary:     "x",
+		Description: strings.Repeat("é", 100),
+	}}}
+	out := string(cal.Encode())
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > lineLimit {
+			t.Fatalf("line longer than %d octets: %q", lineLimit, line)
+		}
+		if !utf8.ValidString(line) {
+			t.Fatalf("fold split a UTF-8 sequence: %q", line)
+		}
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	if !strings.Contains(unfolded, "DESCRIPTION:"+strings.Repeat("é", 100)+"\r\n") {
+		t.Fatal("expected description to survive unfolding")
+	}
+}
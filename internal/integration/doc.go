@@ -0,0 +1,8 @@
+// Package integration holds end-to-end tests that run the repositories,
+// services and HTTP handlers against real Postgres and Redis containers.
+//
+// The suite is excluded from the default build. Run it with Docker
+// available:
+//
+//	go test -tags integration ./internal/integration -v
+package integration
@@ -0,0 +1,187 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/synthetic"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	postgresImage = "postgres:15-alpine"
+	redisImage    = "redis:7-alpine"
+	migrationsDir = "../../cmd/migrate/migrations"
+)
+
+// env is shared by every test in the package. TestMain starts the containers
+// once and each test truncates the tables it writes to.
+var env struct {
+	pool   *pgxpool.Pool
+	redis  *redis.Client
+	tracer trace.Tracer
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	ctx := context.Background()
+
+	pg, err := tcpostgres.Run(ctx, postgresImage,
+		tcpostgres.WithDatabase("umbrella"),
+		tcpostgres.WithUsername("umbrella"),
+		tcpostgres.WithPassword("umbrella"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(90*time.Second),
+		),
+	)
+	if err != nil {
+		log.Printf("start postgres container: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(pg); err != nil {
+			log.Printf("terminate postgres container: %v", err)
+		}
+	}()
+
+	rc, err := tcredis.Run(ctx, redisImage)
+	if err != nil {
+		log.Printf("start redis container: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := testcontainers.TerminateContainer(rc); err != nil {
+			log.Printf("terminate redis container: %v", err)
+		}
+	}()
+
+	dsn, err := pg.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		log.Printf("postgres connection string: %v", err)
+		return 1
+	}
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		log.Printf("connect to postgres: %v", err)
+		return 1
+	}
+	defer pool.Close()
+
+	if err := migrateUp(ctx, pool, migrationsDir); err != nil {
+		log.Printf("apply migrations: %v", err)
+		return 1
+	}
+
+	redisURL, err := rc.ConnectionString(ctx)
+	if err != nil {
+		log.Printf("redis connection string: %v", err)
+		return 1
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		log.Printf("parse redis url: %v", err)
+		return 1
+	}
+	redisClient := redis.NewClient(opts)
+	defer redisClient.Close()
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.Printf("ping redis: %v", err)
+		return 1
+	}
+
+	env.pool = pool
+	env.redis = redisClient
+	env.tracer = trace.NewNoopTracerProvider().Tracer("integration")
+	return m.Run()
+}
+
+// migrateUp applies every *.up.sql file in dir in version order, the same
+// files cmd/migrate embeds, so schema drift shows up here first.
+func migrateUp(ctx context.Context, pool *pgxpool.Pool, dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no migrations found in %s", dir)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		sqlBytes, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if _, err := pool.Exec(ctx, string(sqlBytes)); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(p), err)
+		}
+	}
+	return nil
+}
+
+// resetDB truncates every application table except the seeded tenants and
+// flushes Redis so tests do not observe each other's rows.
+func resetDB(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+
+	rows, err := env.pool.Query(ctx, `
+SELECT tablename FROM pg_tables
+WHERE schemaname = 'public' AND tablename NOT IN ('schema_migrations', 'tenants')`)
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			t.Fatalf("scan table name: %v", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+
+	if len(tables) > 0 {
+		if _, err := env.pool.Exec(ctx, "TRUNCATE "+strings.Join(tables, ", ")+" RESTART IDENTITY"); err != nil {
+			t.Fatalf("truncate tables: %v", err)
+		}
+	}
+	if err := env.redis.FlushDB(ctx).Err(); err != nil {
+		t.Fatalf("flush redis: %v", err)
+	}
+}
+
+// syntheticCandles returns n hourly candles for symbol ending at the most
+// recent completed hour. The final candle carries a volume spike so the
+// classic engine always has a volume_zscore event to emit.
+func syntheticCandles(symbol string, n int, seed int64) []*domain.Candle {
+	end := time.Now().UTC().Add(-time.Hour)
+	candles := synthetic.SeriesEndingAt(synthetic.Config{Symbol: symbol, Interval: "1h", Seed: seed}, end, n)
+	candles[len(candles)-1].Volume *= 20
+	return candles
+}
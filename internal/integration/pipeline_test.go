@@ -0,0 +1,339 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/chart"
+	"bug-free-umbrella/internal/handler"
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/ensemble"
+	"bug-free-umbrella/internal/ml/features"
+	"bug-free-umbrella/internal/ml/inference"
+	"bug-free-umbrella/internal/ml/predictions"
+	"bug-free-umbrella/internal/ml/registry"
+	"bug-free-umbrella/internal/ml/training"
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/service"
+	signalengine "bug-free-umbrella/internal/signal"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	backfillHours = 60 * 24
+	testAPIKey    = "integration-key"
+)
+
+type stubPriceProvider struct {
+	prices map[string]*domain.PriceSnapshot
+	calls  int
+}
+
+func (p *stubPriceProvider) FetchPrices(context.Context) (map[string]*domain.PriceSnapshot, error) {
+	p.calls++
+	return p.prices, nil
+}
+
+func (p *stubPriceProvider) FetchMarketChart(context.Context, string, int, []string) ([]*domain.Candle, error) {
+	return nil, nil
+}
+
+func backfill(t *testing.T, repo *repository.CandleRepository, symbols ...string) {
+	t.Helper()
+	for i, symbol := range symbols {
+		if err := repo.UpsertCandles(context.Background(), syntheticCandles(symbol, backfillHours, int64(i+1))); err != nil {
+			t.Fatalf("backfill %s: %v", symbol, err)
+		}
+	}
+}
+
+func TestCandleUpsertIsIdempotent(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	repo := repository.NewCandleRepository(env.pool, env.tracer)
+
+	candles := syntheticCandles("BTC", 48, 1)
+	if err := repo.UpsertCandles(ctx, candles); err != nil {
+		t.Fatalf("first upsert: %v", err)
+	}
+	candles[len(candles)-1].Close = 12345
+	if err := repo.UpsertCandles(ctx, candles); err != nil {
+		t.Fatalf("second upsert: %v", err)
+	}
+
+	got, err := repo.GetCandles(ctx, "BTC", "1h", 100)
+	if err != nil {
+		t.Fatalf("get candles: %v", err)
+	}
+	if len(got) != len(candles) {
+		t.Fatalf("expected %d candles, got %d", len(candles), len(got))
+	}
+	if got[0].Close != 12345 {
+		t.Fatalf("expected latest close to be updated, got %v", got[0].Close)
+	}
+
+	from := candles[10].OpenTime
+	to := candles[19].OpenTime
+	ranged, err := repo.GetCandlesInRange(ctx, "BTC", "1h", from, to)
+	if err != nil {
+		t.Fatalf("get candles in range: %v", err)
+	}
+	if len(ranged) != 10 {
+		t.Fatalf("expected 10 candles in range, got %d", len(ranged))
+	}
+}
+
+func TestSignalGenerationPersistsSignalsAndImages(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	candleRepo := repository.NewCandleRepository(env.pool, env.tracer)
+	backfill(t, candleRepo, "BTC")
+
+	signalRepo := repository.NewSignalRepository(env.pool, env.tracer)
+	svc := service.NewSignalServiceWithImages(
+		env.tracer,
+		candleRepo,
+		signalRepo,
+		signalengine.NewEngine(nil),
+		repository.NewSignalImageRepository(env.pool, env.tracer),
+		chart.NewRenderer(),
+	)
+
+	generated, err := svc.GenerateForSymbol(ctx, "BTC", []string{"1h"})
+	if err != nil {
+		t.Fatalf("generate signals: %v", err)
+	}
+	if len(generated) == 0 {
+		t.Fatal("expected the volume spike to produce at least one signal")
+	}
+
+	listed, err := svc.ListSignals(ctx, domain.SignalFilter{Symbol: "BTC", Limit: 50})
+	if err != nil {
+		t.Fatalf("list signals: %v", err)
+	}
+	if len(listed) != len(generated) {
+		t.Fatalf("expected %d persisted signals, got %d", len(generated), len(listed))
+	}
+
+	img, err := svc.GetSignalImage(ctx, generated[0].ID)
+	if err != nil {
+		t.Fatalf("get signal image: %v", err)
+	}
+	if img == nil || len(img.Bytes) == 0 {
+		t.Fatalf("expected rendered image for signal %d", generated[0].ID)
+	}
+}
+
+func TestMLTrainingAndInference(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	candleRepo := repository.NewCandleRepository(env.pool, env.tracer)
+	backfill(t, candleRepo, "BTC", "ETH")
+
+	featureRepo := features.NewRepository(env.pool, env.tracer)
+	registryRepo := registry.NewRepository(env.pool, env.tracer)
+	predictionRepo := predictions.NewRepository(env.pool, env.tracer)
+	signalRepo := repository.NewSignalRepository(env.pool, env.tracer)
+
+	trainingSvc := training.NewService(env.tracer, featureRepo, registryRepo, training.Config{
+		Interval:        "1h",
+		TrainWindowDays: 60,
+		MinTrainSamples: 500,
+	})
+	inferenceSvc := inference.NewService(
+		env.tracer,
+		featureRepo,
+		registryRepo,
+		predictionRepo,
+		signalRepo,
+		ensemble.NewService(),
+		inference.Config{Interval: "1h"},
+	)
+	mlSvc := service.NewMLSignalService(
+		env.tracer,
+		candleRepo,
+		features.NewEngine(nil),
+		featureRepo,
+		trainingSvc,
+		inferenceSvc,
+		predictionRepo,
+		service.MLSignalServiceConfig{Interval: "1h", TrainWindowDays: 60},
+	)
+
+	rows, err := mlSvc.RefreshFeatures(ctx)
+	if err != nil {
+		t.Fatalf("refresh features: %v", err)
+	}
+	if rows == 0 {
+		t.Fatal("expected feature rows from backfilled candles")
+	}
+
+	results, err := mlSvc.RunTraining(ctx)
+	if err != nil {
+		t.Fatalf("run training: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("expected training results")
+	}
+	for _, res := range results {
+		if res.PromoteError != nil {
+			t.Fatalf("promote %s: %v", res.ModelKey, res.PromoteError)
+		}
+		if !res.Promoted {
+			t.Fatalf("expected first %s version to be promoted", res.ModelKey)
+		}
+	}
+
+	active, err := registryRepo.GetActiveModel(ctx, common.ModelKeyLogReg)
+	if err != nil {
+		t.Fatalf("get active model: %v", err)
+	}
+	if active == nil || len(active.ArtifactBlob) == 0 {
+		t.Fatal("expected active logreg model with artifact")
+	}
+
+	run, err := mlSvc.RunInference(ctx)
+	if err != nil {
+		t.Fatalf("run inference: %v", err)
+	}
+	if run.Predictions == 0 {
+		t.Fatal("expected inference to store predictions")
+	}
+
+	stored, err := predictionRepo.ListLatestBySymbol(ctx, "BTC", 10)
+	if err != nil {
+		t.Fatalf("list predictions: %v", err)
+	}
+	if len(stored) == 0 {
+		t.Fatal("expected stored predictions for BTC")
+	}
+}
+
+func TestAPIEndpoints(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	candleRepo := repository.NewCandleRepository(env.pool, env.tracer)
+	backfill(t, candleRepo, "BTC")
+
+	provider := &stubPriceProvider{prices: map[string]*domain.PriceSnapshot{
+		"BTC": {Symbol: "BTC", PriceUSD: 64000, LastUpdatedUnix: time.Now().Unix()},
+	}}
+	priceSvc := service.NewPriceService(env.tracer, provider, candleRepo, env.redis)
+	signalSvc := service.NewSignalServiceWithImages(
+		env.tracer,
+		candleRepo,
+		repository.NewSignalRepository(env.pool, env.tracer),
+		signalengine.NewEngine(nil),
+		repository.NewSignalImageRepository(env.pool, env.tracer),
+		chart.NewRenderer(),
+	)
+	generated, err := signalSvc.GenerateForSymbol(ctx, "BTC", []string{"1h"})
+	if err != nil {
+		t.Fatalf("generate signals: %v", err)
+	}
+	if len(generated) == 0 {
+		t.Fatal("expected at least one generated signal")
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := handler.New(env.tracer, service.NewWorkService(env.tracer), priceSvc, signalSvc)
+	h.SetBacktestService(service.NewBacktestService(env.tracer, repository.NewBacktestRepository(env.pool, env.tracer)))
+	protected := r.Group("")
+	protected.Use(handler.TenantAPIKeyAuth(handler.TenantAuthConfig{
+		AdminKey: testAPIKey,
+		Resolver: repository.NewAPIKeyRepository(env.pool, env.tracer),
+	}))
+	h.RegisterRoutes(protected)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", testAPIKey)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("prices served from redis after first fetch", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := get("/api/prices/BTC")
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+		if provider.calls != 1 {
+			t.Fatalf("expected a single provider call, got %d", provider.calls)
+		}
+		if n, err := env.redis.Exists(ctx, "price:BTC").Result(); err != nil || n != 1 {
+			t.Fatalf("expected price:BTC cached in redis, exists=%d err=%v", n, err)
+		}
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		w := get("/api/candles/BTC?interval=1h&limit=24")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Candles []domain.Candle `json:"candles"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode candles: %v", err)
+		}
+		if len(body.Candles) != 24 {
+			t.Fatalf("expected 24 candles, got %d", len(body.Candles))
+		}
+	})
+
+	t.Run("signals", func(t *testing.T) {
+		w := get("/api/signals?symbol=BTC")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var body struct {
+			Signals []domain.Signal `json:"signals"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode signals: %v", err)
+		}
+		if len(body.Signals) != len(generated) {
+			t.Fatalf("expected %d signals, got %d", len(generated), len(body.Signals))
+		}
+	})
+
+	t.Run("signal image", func(t *testing.T) {
+		w := get(fmt.Sprintf("/api/signals/%d/image", generated[0].ID))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+			t.Fatalf("expected image/png, got %q", ct)
+		}
+	})
+
+	t.Run("backtest summary", func(t *testing.T) {
+		w := get("/api/backtest/summary")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("rejects unknown api key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/signals", nil)
+		req.Header.Set("X-API-Key", "not-a-key")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d", w.Code)
+		}
+	})
+}
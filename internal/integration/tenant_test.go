@@ -0,0 +1,52 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestConversationsAreIsolatedPerTenant(t *testing.T) {
+	resetDB(t)
+	repo := repository.NewConversationRepository(env.pool, env.tracer)
+	const chatID = 42
+	acme := domain.WithTenant(context.Background(), "acme")
+	telegram := domain.WithTenant(context.Background(), domain.TelegramTenantID(chatID))
+
+	if err := repo.AppendMessage(acme, chatID, "user", "acme question"); err != nil {
+		t.Fatalf("append acme message: %v", err)
+	}
+	if err := repo.AppendMessage(telegram, chatID, "user", "telegram question"); err != nil {
+		t.Fatalf("append telegram message: %v", err)
+	}
+
+	for _, tc := range []struct {
+		ctx  context.Context
+		want string
+	}{
+		{acme, "acme question"},
+		{telegram, "telegram question"},
+	} {
+		msgs, err := repo.RecentMessages(tc.ctx, chatID, 10)
+		if err != nil {
+			t.Fatalf("recent messages: %v", err)
+		}
+		if len(msgs) != 1 || msgs[0].Content != tc.want {
+			t.Fatalf("expected only %q for tenant %s, got %+v", tc.want, domain.TenantFromContext(tc.ctx), msgs)
+		}
+	}
+	if msgs, err := repo.RecentMessages(context.Background(), chatID, 10); err != nil || len(msgs) != 0 {
+		t.Fatalf("expected nothing in the default tenant, got %+v err=%v", msgs, err)
+	}
+
+	if deleted, err := repo.DeleteConversation(acme, chatID); err != nil || deleted != 1 {
+		t.Fatalf("expected one acme message deleted, got %d err=%v", deleted, err)
+	}
+	if msgs, err := repo.RecentMessages(telegram, chatID, 10); err != nil || len(msgs) != 1 {
+		t.Fatalf("expected the telegram conversation to survive, got %+v err=%v", msgs, err)
+	}
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultAlertRetryBatchSize = 50
+	alertRetryTick             = time.Minute
+)
+
+type AlertRetrier interface {
+	RetryDueDeliveries(ctx context.Context, limit int) (int, error)
+}
+
+// AlertRedelivery periodically retries alert deliveries whose backoff has
+// elapsed.
+type AlertRedelivery struct {
+	pauseGate
+
+	tracer  trace.Tracer
+	retrier AlertRetrier
+}
+
+func NewAlertRedelivery(tracer trace.Tracer, retrier AlertRetrier) *AlertRedelivery {
+	return &AlertRedelivery{
+		tracer:  tracer,
+		retrier: retrier,
+	}
+}
+
+func (j *AlertRedelivery) Start(ctx context.Context) {
+	if j == nil || j.retrier == nil {
+		<-ctx.Done()
+		return
+	}
+
+	log.Println("Alert redelivery starting...")
+	ticker := time.NewTicker(alertRetryTick)
+	defer ticker.Stop()
+
+	j.runRetry(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Alert redelivery stopped")
+			return
+		case <-ticker.C:
+			j.runRetry(ctx)
+		}
+	}
+}
+
+func (j *AlertRedelivery) runRetry(ctx context.Context) {
+	if j.paused(ctx) {
+		return
+	}
+	if j.tracer != nil {
+		_, span := j.tracer.Start(ctx, "alert-redelivery-job.retry")
+		defer span.End()
+	}
+	sent, err := j.retrier.RetryDueDeliveries(ctx, defaultAlertRetryBatchSize)
+	if err != nil {
+		log.Printf("alert redelivery error: %v", err)
+		return
+	}
+	if sent > 0 {
+		log.Printf("alert redelivery sent %d alert(s)", sent)
+	}
+}
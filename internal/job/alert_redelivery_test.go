@@ -0,0 +1,49 @@
+package job
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAlertRedeliveryStartRetriesUntilCancelled(t *testing.T) {
+	stub := &stubAlertRetrier{}
+	job := NewAlertRedelivery(trace.NewNoopTracerProvider().Tracer("test"), stub)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		job.Start(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("alert redelivery job did not stop")
+	}
+
+	if atomic.LoadInt32(&stub.calls) == 0 {
+		t.Fatal("expected retry to run at least once")
+	}
+	if got := atomic.LoadInt32(&stub.lastLimit); got != defaultAlertRetryBatchSize {
+		t.Fatalf("expected batch size %d, got %d", defaultAlertRetryBatchSize, got)
+	}
+}
+
+type stubAlertRetrier struct {
+	calls     int32
+	lastLimit int32
+}
+
+func (s *stubAlertRetrier) RetryDueDeliveries(ctx context.Context, limit int) (int, error) {
+	atomic.AddInt32(&s.calls, 1)
+	atomic.StoreInt32(&s.lastLimit, int32(limit))
+	return 0, nil
+}
@@ -0,0 +1,70 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const conversationExpiryTick = time.Hour
+
+type ConversationPruner interface {
+	DeleteInactiveConversations(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ConversationExpiry periodically deletes advisor conversations that have
+// been inactive for longer than the configured idle period.
+type ConversationExpiry struct {
+	tracer  trace.Tracer
+	pruner  ConversationPruner
+	idleFor time.Duration
+	now     func() time.Time
+}
+
+func NewConversationExpiry(tracer trace.Tracer, pruner ConversationPruner, idleFor time.Duration) *ConversationExpiry {
+	return &ConversationExpiry{
+		tracer:  tracer,
+		pruner:  pruner,
+		idleFor: idleFor,
+		now:     time.Now,
+	}
+}
+
+func (j *ConversationExpiry) Start(ctx context.Context) {
+	if j == nil || j.pruner == nil || j.idleFor <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	log.Printf("Conversation expiry starting (idle after %s)...", j.idleFor)
+	ticker := time.NewTicker(conversationExpiryTick)
+	defer ticker.Stop()
+
+	j.runCleanup(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Conversation expiry stopped")
+			return
+		case <-ticker.C:
+			j.runCleanup(ctx)
+		}
+	}
+}
+
+func (j *ConversationExpiry) runCleanup(ctx context.Context) {
+	if j.tracer != nil {
+		_, span := j.tracer.Start(ctx, "conversation-expiry-job.cleanup")
+		defer span.End()
+	}
+	deleted, err := j.pruner.DeleteInactiveConversations(ctx, j.now().Add(-j.idleFor))
+	if err != nil {
+		log.Printf("conversation expiry error: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("conversation expiry removed %d message(s)", deleted)
+	}
+}
@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestConversationExpiryDeletesIdleConversations(t *testing.T) {
+	stub := &stubConversationPruner{}
+	job := NewConversationExpiry(trace.NewNoopTracerProvider().Tracer("test"), stub, 48*time.Hour)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	job.now = func() time.Time { return now }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		job.Start(ctx)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("conversation expiry job did not stop")
+	}
+
+	cutoffs := stub.calls()
+	if len(cutoffs) == 0 {
+		t.Fatal("expected cleanup to run at least once")
+	}
+	if want := now.Add(-48 * time.Hour); !cutoffs[0].Equal(want) {
+		t.Fatalf("expected cutoff %s, got %s", want, cutoffs[0])
+	}
+}
+
+func TestConversationExpiryDisabledWithoutIdlePeriod(t *testing.T) {
+	stub := &stubConversationPruner{}
+	job := NewConversationExpiry(trace.NewNoopTracerProvider().Tracer("test"), stub, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	job.Start(ctx)
+
+	if len(stub.calls()) != 0 {
+		t.Fatal("expected no cleanup when expiry is disabled")
+	}
+}
+
+type stubConversationPruner struct {
+	mu      sync.Mutex
+	cutoffs []time.Time
+}
+
+func (s *stubConversationPruner) DeleteInactiveConversations(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cutoffs = append(s.cutoffs, cutoff)
+	return 0, nil
+}
+
+func (s *stubConversationPruner) calls() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Time(nil), s.cutoffs...)
+}
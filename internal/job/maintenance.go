@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"log"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// Pauser reports whether background work is paused, as it is while the
+// operator maintenance switch is on.
+type Pauser interface {
+	Paused(ctx context.Context) bool
+}
+
+// pauseGate is embedded by jobs that stand down during maintenance. Their
+// loops keep running and skip each cycle while paused, so work resumes on
+// the first tick after maintenance ends.
+type pauseGate struct {
+	pauser Pauser
+}
+
+// SetPauser makes the job skip its cycles while p reports paused.
+func (g *pauseGate) SetPauser(p Pauser) {
+	g.pauser = p
+}
+
+func (g *pauseGate) paused(ctx context.Context) bool {
+	return g.pauser != nil && g.pauser.Paused(ctx)
+}
+
+// PausableAlertSink holds back signal alerts while Pauser reports paused,
+// and alerts for symbols with alerts paused in the registry. Skipped alerts
+// are logged, not queued.
+type PausableAlertSink struct {
+	Sink   SignalAlertSink
+	Pauser Pauser
+}
+
+func (s PausableAlertSink) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	if s.Pauser != nil && s.Pauser.Paused(ctx) {
+		log.Printf("maintenance mode: skipped alerts for %d signal(s)", len(signals))
+		return nil
+	}
+	kept := signals[:0:0]
+	for _, sig := range signals {
+		if domain.AlertsPaused(sig.Symbol) {
+			log.Printf("alerts paused for %s: skipped %s %s alert", sig.Symbol, sig.Interval, sig.Indicator)
+			continue
+		}
+		kept = append(kept, sig)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return s.Sink.NotifySignals(ctx, kept)
+}
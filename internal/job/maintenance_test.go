@@ -0,0 +1,115 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type stubPauser struct {
+	paused bool
+}
+
+func (s *stubPauser) Paused(ctx context.Context) bool { return s.paused }
+
+func TestJobsSkipCyclesWhilePaused(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	ctx := context.Background()
+	pauser := &stubPauser{paused: true}
+
+	prices := &stubPriceService{}
+	poller := NewPricePoller(tracer, prices, 60)
+	poller.SetPauser(pauser)
+	idx := 0
+	poller.fetchShortBatch(ctx, &idx, 2)
+	poller.fetchLongBatch(ctx, &idx)
+
+	signals := &stubSignalService{}
+	signalPoller := NewSignalPoller(tracer, signals, nil)
+	signalPoller.SetPauser(pauser)
+	signalPoller.generateAndLog(ctx, "short", "BTC", []string{"1h"}, time.Time{})
+
+	ml := &stubMLInferencer{}
+	mlJob := NewMLFeatureInferenceJob(tracer, ml, 0)
+	mlJob.SetPauser(pauser)
+	mlJob.runOnce(ctx)
+
+	var intelCalls int32
+	intelJob := NewMarketIntelJob(tracer, &marketIntelRunnerTestStub{calls: &intelCalls}, 0)
+	intelJob.SetPauser(pauser)
+	intelJob.runOnce(ctx)
+
+	retrier := &stubAlertRetrier{}
+	redelivery := NewAlertRedelivery(tracer, retrier)
+	redelivery.SetPauser(pauser)
+	redelivery.runRetry(ctx)
+
+	if len(prices.shortSymbols) != 0 || len(prices.longSymbols) != 0 || signals.callCount() != 0 ||
+		ml.runs() != 0 || intelCalls != 0 || retrier.calls != 0 {
+		t.Fatal("expected every job to skip its cycle while paused")
+	}
+	if idx != 0 {
+		t.Fatalf("expected the round-robin position kept while paused, got %d", idx)
+	}
+
+	pauser.paused = false
+	poller.fetchShortBatch(ctx, &idx, 2)
+	mlJob.runOnce(ctx)
+	if len(prices.shortSymbols) != 2 || ml.runs() != 1 {
+		t.Fatal("expected work to resume once maintenance ends")
+	}
+}
+
+func TestPausableAlertSink(t *testing.T) {
+	alerts := &stubSignalAlerter{}
+	pauser := &stubPauser{paused: true}
+	sink := PausableAlertSink{Sink: alerts, Pauser: pauser}
+	signals := []domain.Signal{{Symbol: "BTC", Interval: "1h"}}
+
+	if err := sink.NotifySignals(context.Background(), signals); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alerts.notifyCalls != 0 {
+		t.Fatal("expected alerts held back during maintenance")
+	}
+	pauser.paused = false
+	_ = sink.NotifySignals(context.Background(), signals)
+	if alerts.notifyCalls != 1 {
+		t.Fatalf("expected alerts dispatched after maintenance, got %d", alerts.notifyCalls)
+	}
+}
+
+func TestSymbolPausesSkipSignalsAndAlerts(t *testing.T) {
+	t.Cleanup(func() { domain.SetConfiguredPauses(nil) })
+	domain.SetConfiguredPauses(map[string]domain.AssetPauses{
+		"DOGE": {SignalsPaused: true},
+		"XRP":  {AlertsPaused: true},
+	})
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	ctx := context.Background()
+
+	signals := &stubSignalService{}
+	poller := NewSignalPoller(tracer, signals, nil)
+	poller.generateAndLog(ctx, "short", "DOGE", []string{"1h"}, time.Time{})
+	if signals.callCount() != 0 {
+		t.Fatal("expected no generation for a symbol with signals paused")
+	}
+	results := poller.GenerateSymbols(ctx, []string{"DOGE", "BTC"}, []string{"1h"})
+	if results[0].Error == "" || results[1].Error != "" || signals.callCount() != 1 {
+		t.Fatalf("expected DOGE refused and BTC generated, got %+v", results)
+	}
+
+	alerts := &stubSignalAlerter{}
+	sink := PausableAlertSink{Sink: alerts}
+	if err := sink.NotifySignals(ctx, []domain.Signal{{Symbol: "XRP"}}); err != nil || alerts.notifyCalls != 0 {
+		t.Fatalf("expected XRP alerts held back, got %d calls err=%v", alerts.notifyCalls, err)
+	}
+	_ = sink.NotifySignals(ctx, []domain.Signal{{Symbol: "XRP"}, {Symbol: "BTC"}})
+	if alerts.notifyCalls != 1 || len(alerts.lastSignals) != 1 || alerts.lastSignals[0].Symbol != "BTC" {
+		t.Fatalf("expected only the BTC alert sent, got %+v", alerts)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"log"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -15,6 +15,8 @@ type MarketIntelRunner interface {
 }
 
 type MarketIntelJob struct {
+	pauseGate
+
 	tracer       trace.Tracer
 	runner       MarketIntelRunner
 	pollInterval time.Duration
@@ -49,6 +51,9 @@ func (j *MarketIntelJob) Start(ctx context.Context) {
 }
 
 func (j *MarketIntelJob) runOnce(ctx context.Context) {
+	if j.paused(ctx) {
+		return
+	}
 	_, span := j.tracer.Start(ctx, "market-intel-job.run-once")
 	defer span.End()
 
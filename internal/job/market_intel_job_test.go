@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
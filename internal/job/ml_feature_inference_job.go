@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"bug-free-umbrella/internal/events"
 	"bug-free-umbrella/internal/ml/inference"
 
 	"go.opentelemetry.io/otel/trace"
@@ -15,17 +16,49 @@ type MLFeatureInferencer interface {
 	RunInference(ctx context.Context) (inference.RunResult, error)
 }
 
+// PredictionListener is told when an inference run stored new predictions.
+type PredictionListener interface {
+	PredictionsUpdated(ctx context.Context)
+}
+
+// mlEventDebounce collects the burst of candle closes that follows each
+// boundary (one per symbol) into a single refresh.
+const mlEventDebounce = 30 * time.Second
+
 type MLFeatureInferenceJob struct {
+	pauseGate
+
 	tracer       trace.Tracer
 	service      MLFeatureInferencer
 	pollInterval time.Duration
+
+	candleEvents <-chan events.CandleClosed
+	intervals    map[string]struct{}
+	debounce     time.Duration
+	listener     PredictionListener
 }
 
 func NewMLFeatureInferenceJob(tracer trace.Tracer, service MLFeatureInferencer, pollInterval time.Duration) *MLFeatureInferenceJob {
 	if pollInterval <= 0 {
 		pollInterval = 15 * time.Minute
 	}
-	return &MLFeatureInferenceJob{tracer: tracer, service: service, pollInterval: pollInterval}
+	return &MLFeatureInferenceJob{tracer: tracer, service: service, pollInterval: pollInterval, debounce: mlEventDebounce}
+}
+
+// SetCandleEvents replaces the poll timer with candle-closed events for the
+// given intervals. Events arriving within the debounce window share one run.
+func (j *MLFeatureInferenceJob) SetCandleEvents(ch <-chan events.CandleClosed, intervals []string) {
+	j.candleEvents = ch
+	j.intervals = make(map[string]struct{}, len(intervals))
+	for _, interval := range intervals {
+		j.intervals[interval] = struct{}{}
+	}
+}
+
+// SetPredictionListener registers l to hear about runs that stored
+// predictions.
+func (j *MLFeatureInferenceJob) SetPredictionListener(l PredictionListener) {
+	j.listener = l
 }
 
 func (j *MLFeatureInferenceJob) Start(ctx context.Context) {
@@ -36,6 +69,11 @@ func (j *MLFeatureInferenceJob) Start(ctx context.Context) {
 	}
 
 	j.runOnce(ctx)
+	if j.candleEvents != nil {
+		j.consumeCandleEvents(ctx)
+		return
+	}
+
 	ticker := time.NewTicker(j.pollInterval)
 	defer ticker.Stop()
 
@@ -49,7 +87,39 @@ func (j *MLFeatureInferenceJob) Start(ctx context.Context) {
 	}
 }
 
+func (j *MLFeatureInferenceJob) consumeCandleEvents(ctx context.Context) {
+	var timer *time.Timer
+	var due <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-j.candleEvents:
+			if !ok {
+				return
+			}
+			if _, wanted := j.intervals[evt.Interval]; !wanted || timer != nil {
+				continue
+			}
+			timer = time.NewTimer(j.debounce)
+			due = timer.C
+		case <-due:
+			timer, due = nil, nil
+			j.runOnce(ctx)
+		}
+	}
+}
+
 func (j *MLFeatureInferenceJob) runOnce(ctx context.Context) {
+	if j.paused(ctx) {
+		return
+	}
 	_, span := j.tracer.Start(ctx, "ml-feature-inference-job.run-once")
 	defer span.End()
 
@@ -58,11 +128,14 @@ func (j *MLFeatureInferenceJob) runOnce(ctx context.Context) {
 		log.Printf("ML feature refresh error: %v", err)
 		return
 	}
-	_, err = j.service.RunInference(ctx)
+	result, err := j.service.RunInference(ctx)
 	if err != nil {
 		log.Printf("ML inference error: %v", err)
 		return
 	}
+	if result.Predictions > 0 && j.listener != nil {
+		j.listener.PredictionsUpdated(ctx)
+	}
 	if rows > 0 {
 		log.Printf("ML feature/inference cycle complete (%d feature rows refreshed)", rows)
 	}
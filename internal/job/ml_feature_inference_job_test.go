@@ -0,0 +1,93 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/internal/ml/inference"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMLFeatureInferenceJobDebouncesCandleEvents(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	svc := &stubMLInferencer{}
+	job := NewMLFeatureInferenceJob(tracer, svc, time.Hour)
+	job.debounce = 20 * time.Millisecond
+
+	ch := make(chan events.CandleClosed, 8)
+	job.SetCandleEvents(ch, []string{"1h"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go job.Start(ctx)
+	eventually(t, func() bool { return svc.runs() == 1 })
+
+	ch <- events.CandleClosed{Symbol: "BTC", Interval: "1h"}
+	ch <- events.CandleClosed{Symbol: "ETH", Interval: "1h"}
+	ch <- events.CandleClosed{Symbol: "SOL", Interval: "5m"}
+	eventually(t, func() bool { return svc.runs() == 2 })
+
+	time.Sleep(3 * job.debounce)
+	if svc.runs() != 2 {
+		t.Fatalf("expected one debounced run, got %d", svc.runs()-1)
+	}
+
+	ch <- events.CandleClosed{Symbol: "SOL", Interval: "5m"}
+	time.Sleep(3 * job.debounce)
+	if svc.runs() != 2 {
+		t.Fatal("expected intervals outside the ML set to be ignored")
+	}
+}
+
+func TestMLFeatureInferenceJobNotifiesListener(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	svc := &stubMLInferencer{}
+	job := NewMLFeatureInferenceJob(tracer, svc, time.Hour)
+	listener := &stubPredictionListener{}
+	job.SetPredictionListener(listener)
+
+	job.runOnce(context.Background())
+	if listener.calls != 0 {
+		t.Fatal("expected no notification for a run without predictions")
+	}
+	svc.result = inference.RunResult{Predictions: 3}
+	job.runOnce(context.Background())
+	if listener.calls != 1 {
+		t.Fatalf("expected one notification, got %d", listener.calls)
+	}
+}
+
+type stubPredictionListener struct {
+	calls int
+}
+
+func (s *stubPredictionListener) PredictionsUpdated(ctx context.Context) {
+	s.calls++
+}
+
+type stubMLInferencer struct {
+	mu        sync.Mutex
+	inference int
+	result    inference.RunResult
+}
+
+func (s *stubMLInferencer) RefreshFeatures(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (s *stubMLInferencer) RunInference(ctx context.Context) (inference.RunResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inference++
+	return s.result, nil
+}
+
+func (s *stubMLInferencer) runs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.inference
+}
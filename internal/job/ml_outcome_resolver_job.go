@@ -12,11 +12,23 @@ type MLOutcomeResolver interface {
 	ResolveOutcomes(ctx context.Context, limit int) (int, error)
 }
 
+// CoverageRefresher recomputes stored daily coverage stats for recent days.
+type CoverageRefresher interface {
+	RefreshDailyCoverage(ctx context.Context, days int) error
+}
+
+// coverageRefreshDays covers predictions resolved late, after the target
+// horizon and a missed poll or two, whose open day is no longer today.
+const coverageRefreshDays = 3
+
 type MLOutcomeResolverJob struct {
+	pauseGate
+
 	tracer       trace.Tracer
 	service      MLOutcomeResolver
 	pollInterval time.Duration
 	batchSize    int
+	coverage     CoverageRefresher
 }
 
 func NewMLOutcomeResolverJob(tracer trace.Tracer, service MLOutcomeResolver, pollInterval time.Duration, batchSize int) *MLOutcomeResolverJob {
@@ -29,6 +41,11 @@ func NewMLOutcomeResolverJob(tracer trace.Tracer, service MLOutcomeResolver, pol
 	return &MLOutcomeResolverJob{tracer: tracer, service: service, pollInterval: pollInterval, batchSize: batchSize}
 }
 
+// SetCoverageRefresher refreshes daily coverage stats after every run.
+func (j *MLOutcomeResolverJob) SetCoverageRefresher(c CoverageRefresher) {
+	j.coverage = c
+}
+
 func (j *MLOutcomeResolverJob) Start(ctx context.Context) {
 	if j.service == nil {
 		log.Println("ML outcome resolver job disabled: no service")
@@ -49,6 +66,9 @@ func (j *MLOutcomeResolverJob) Start(ctx context.Context) {
 }
 
 func (j *MLOutcomeResolverJob) runOnce(ctx context.Context) {
+	if j.paused(ctx) {
+		return
+	}
 	_, span := j.tracer.Start(ctx, "ml-outcome-resolver-job.run-once")
 	defer span.End()
 
@@ -60,4 +80,9 @@ func (j *MLOutcomeResolverJob) runOnce(ctx context.Context) {
 	if resolved > 0 {
 		log.Printf("ML outcome resolver updated %d predictions", resolved)
 	}
+	if j.coverage != nil {
+		if err := j.coverage.RefreshDailyCoverage(ctx, coverageRefreshDays); err != nil {
+			log.Printf("ML coverage refresh error: %v", err)
+		}
+	}
 }
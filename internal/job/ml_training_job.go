@@ -15,6 +15,8 @@ type MLTrainer interface {
 }
 
 type MLTrainingJob struct {
+	pauseGate
+
 	tracer    trace.Tracer
 	service   MLTrainer
 	trainHour int
@@ -51,6 +53,10 @@ func (j *MLTrainingJob) Start(ctx context.Context) {
 }
 
 func (j *MLTrainingJob) runOnce(ctx context.Context) {
+	if j.paused(ctx) {
+		log.Println("ML training skipped: maintenance mode is on")
+		return
+	}
 	_, span := j.tracer.Start(ctx, "ml-training-job.run-once")
 	defer span.End()
 
@@ -3,31 +3,160 @@ package job
 import (
 	"context"
 	"log"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/internal/ta"
+	"bug-free-umbrella/pkg/domain"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	// DefaultMinPriceInterval keeps the current-price tier within the
+	// CoinGecko budget (8 calls/min) while leaving room for the candle tiers.
+	DefaultMinPriceInterval = 15 * time.Second
+
+	// deriveLookback is how far back each refresh fills missing 4h and 1d
+	// candles from 1h ones; it covers the last complete day.
+	deriveLookback = 48 * time.Hour
+
+	// shortCandleEvery and longCandleEvery space the candle tiers' refreshes
+	// before any quota stretch.
+	shortCandleEvery = 5 * time.Minute
+	longCandleEvery  = 30 * time.Minute
+
+	highVolatilityPercentile = 0.8
+	volatilityATRPeriod      = 14
+	volatilityLookback       = 168
+)
+
+var (
+	shortCandleIntervals = domain.IntervalsInTier(domain.IntervalTierShort)
+	longCandleIntervals  = domain.IntervalsInTier(domain.IntervalTierLong)
+)
+
 // PricePoller runs background goroutines that periodically fetch and store price data.
 type PricePoller struct {
+	pauseGate
+	quotaGate
+
 	tracer       trace.Tracer
 	priceService PriceDataRefresher
 	pollInterval time.Duration
+	minInterval  time.Duration
+	candles      CandleReader
+	now          func() time.Time
+
+	currentInterval atomic.Int64
+
+	stream PriceStreamStatus
+
+	closeReader CandleReader
+	publisher   CandleEventPublisher
+	deriver     CandleDeriver
+	closeMu     sync.Mutex
+	lastClosed  map[string]time.Time
+}
+
+// CandleEventPublisher receives the candle-closed events detected after each
+// candle refresh.
+type CandleEventPublisher interface {
+	PublishCandleClosed(evt events.CandleClosed)
+}
+
+// PriceStreamStatus reports whether streamed prices are keeping a symbol's
+// cached price fresh.
+type PriceStreamStatus interface {
+	Live(symbol string) bool
+}
+
+// CandleReader supplies the hourly candles used to gauge market volatility.
+type CandleReader interface {
+	GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error)
+}
+
+// CandleDeriver builds missing higher-interval candles from stored 1h ones.
+type CandleDeriver interface {
+	Derive(ctx context.Context, symbol string, from, to time.Time) (int, error)
 }
 
 type PriceDataRefresher interface {
 	RefreshPrices(ctx context.Context) error
+	RefreshPricesFor(ctx context.Context, symbols []string) error
 	RefreshShortCandles(ctx context.Context, symbol string) error
 	RefreshLongCandles(ctx context.Context, symbol string) error
 }
 
 func NewPricePoller(tracer trace.Tracer, priceService PriceDataRefresher, pollIntervalSecs int) *PricePoller {
-	return &PricePoller{
+	p := &PricePoller{
 		tracer:       tracer,
 		priceService: priceService,
 		pollInterval: time.Duration(pollIntervalSecs) * time.Second,
+		minInterval:  DefaultMinPriceInterval,
+		now:          time.Now,
+		lastClosed:   make(map[string]time.Time),
+	}
+	p.currentInterval.Store(int64(p.pollInterval))
+	p.registerMetrics()
+	return p
+}
+
+// SetVolatilitySource enables faster price polling while the latest hourly
+// ATR sits in the top of its recent range for any supported symbol.
+func (p *PricePoller) SetVolatilitySource(candles CandleReader) {
+	p.candles = candles
+}
+
+// SetCandleEvents makes the poller read back the newest candles after each
+// candle refresh and publish an event for every newly closed one.
+func (p *PricePoller) SetCandleEvents(candles CandleReader, publisher CandleEventPublisher) {
+	p.closeReader = candles
+	p.publisher = publisher
+}
+
+// SetCandleDeriver fills 4h and 1d gaps the provider leaves from 1h
+// candles after each candle refresh.
+func (p *PricePoller) SetCandleDeriver(d CandleDeriver) {
+	p.deriver = d
+}
+
+// SetPriceStream makes the current-price tier skip the symbols stream is
+// live for. A symbol is polled again as soon as its ticks stop.
+func (p *PricePoller) SetPriceStream(stream PriceStreamStatus) {
+	p.stream = stream
+}
+
+// SetMinInterval sets the fastest cadence the current-price tier may use.
+func (p *PricePoller) SetMinInterval(d time.Duration) {
+	if d > 0 {
+		p.minInterval = d
+	}
+}
+
+// CurrentInterval returns the cadence chosen for the next price refresh.
+func (p *PricePoller) CurrentInterval() time.Duration {
+	return time.Duration(p.currentInterval.Load())
+}
+
+func (p *PricePoller) registerMetrics() {
+	meter := otel.Meter("bug-free-umbrella/job")
+	_, err := meter.Float64ObservableGauge("price_poller.interval",
+		metric.WithDescription("Current cadence of the current-price poller"),
+		metric.WithUnit("s"),
+		metric.WithFloat64Callback(func(_ context.Context, o metric.Float64Observer) error {
+			o.Observe(p.CurrentInterval().Seconds())
+			return nil
+		}),
+	)
+	if err != nil {
+		log.Printf("price poller interval gauge: %v", err)
 	}
 }
 
@@ -35,10 +164,8 @@ func NewPricePoller(tracer trace.Tracer, priceService PriceDataRefresher, pollIn
 func (p *PricePoller) Start(ctx context.Context) {
 	log.Println("Price poller starting...")
 
-	// Tier 1: Current prices every pollInterval (default 60s)
-	go p.pollLoop(ctx, "current-prices", p.pollInterval, func(ctx context.Context) error {
-		return p.priceService.RefreshPrices(ctx)
-	})
+	// Tier 1: Current prices around pollInterval (default 60s), adjusted for volatility and off-hours
+	go p.pollPrices(ctx)
 
 	// Tier 2: Short candles (5m, 15m, 1h) — 2 coins every 5 minutes, round-robin
 	go p.pollShortCandles(ctx)
@@ -50,25 +177,140 @@ func (p *PricePoller) Start(ctx context.Context) {
 	log.Println("Price poller stopped")
 }
 
-func (p *PricePoller) pollLoop(ctx context.Context, name string, interval time.Duration, fn func(context.Context) error) {
-	// Run immediately on start
-	if err := fn(ctx); err != nil {
-		log.Printf("poller %s initial run error: %v", name, err)
+func (p *PricePoller) pollPrices(ctx context.Context) {
+	// Run immediately on start, unless paused for maintenance
+	if !p.paused(ctx) {
+		if err := p.refreshPrices(ctx); err != nil {
+			log.Printf("poller current-prices initial run error: %v", err)
+		}
 	}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(p.scheduleNext(ctx))
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			if err := fn(ctx); err != nil {
-				log.Printf("poller %s error: %v", name, err)
+		case <-timer.C:
+			if p.paused(ctx) {
+				timer.Reset(p.pollInterval)
+				continue
+			}
+			if err := p.refreshPrices(ctx); err != nil {
+				log.Printf("poller current-prices error: %v", err)
 			}
+			timer.Reset(p.scheduleNext(ctx))
+		}
+	}
+}
+
+// refreshPrices polls current prices for the symbols a live stream does not
+// keep fresh: ones without a stream pair and ones whose ticks have stopped.
+func (p *PricePoller) refreshPrices(ctx context.Context) error {
+	if p.stream == nil {
+		return p.priceService.RefreshPrices(ctx)
+	}
+	var stale []string
+	for _, symbol := range domain.SupportedSymbols() {
+		if !p.stream.Live(symbol) {
+			stale = append(stale, symbol)
 		}
 	}
+	if len(stale) == 0 {
+		return nil
+	}
+	return p.priceService.RefreshPricesFor(ctx, stale)
+}
+
+// scheduleNext picks the delay before the next price refresh and records it
+// as the current cadence.
+func (p *PricePoller) scheduleNext(ctx context.Context) time.Duration {
+	next := p.nextInterval(ctx)
+	if prev := p.CurrentInterval(); prev != next {
+		log.Printf("price poller cadence %s -> %s", prev, next)
+	}
+	p.currentInterval.Store(int64(next))
+	return next
+}
+
+// nextInterval halves pollInterval during high volatility, never going below
+// minInterval, and doubles it overnight and at weekends when markets are calm.
+// The result is stretched as the provider quota runs low.
+func (p *PricePoller) nextInterval(ctx context.Context) time.Duration {
+	next := p.pollInterval
+	if pct, ok := p.volatilityPercentile(ctx); ok && pct >= highVolatilityPercentile {
+		next = max(p.pollInterval/2, min(p.minInterval, p.pollInterval))
+	} else if isOffHours(p.now()) {
+		next = p.pollInterval * 2
+	}
+	return p.stretched(next)
+}
+
+// volatilityPercentile returns the highest percentile rank, across supported
+// symbols, of the latest hourly ATR (relative to price) within the last week.
+func (p *PricePoller) volatilityPercentile(ctx context.Context) (float64, bool) {
+	if p.candles == nil {
+		return 0, false
+	}
+	best, found := 0.0, false
+	for _, symbol := range domain.SupportedSymbols() {
+		candles, err := p.candles.GetCandles(ctx, symbol, "1h", volatilityLookback+volatilityATRPeriod+1)
+		if err != nil {
+			log.Printf("price poller volatility read error for %s: %v", symbol, err)
+			continue
+		}
+		if pct, ok := atrPercentile(candles); ok {
+			best, found = math.Max(best, pct), true
+		}
+	}
+	return best, found
+}
+
+// atrPercentile ranks the newest normalized ATR against the earlier values.
+// Candles arrive newest first, as returned by the candle repository.
+func atrPercentile(candles []*domain.Candle) (float64, bool) {
+	sorted := make([]*domain.Candle, len(candles))
+	copy(sorted, candles)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OpenTime.Before(sorted[j].OpenTime) })
+
+	highs := make([]float64, len(sorted))
+	lows := make([]float64, len(sorted))
+	closes := make([]float64, len(sorted))
+	for i, c := range sorted {
+		highs[i], lows[i], closes[i] = c.High, c.Low, c.Close
+	}
+
+	atr := ta.ATRSeries(highs, lows, closes, volatilityATRPeriod)
+	values := make([]float64, 0, len(atr))
+	for i, v := range atr {
+		if !math.IsNaN(v) && closes[i] > 0 {
+			values = append(values, v/closes[i])
+		}
+	}
+	if len(values) < 2 {
+		return 0, false
+	}
+
+	latest := values[len(values)-1]
+	below := 0
+	for _, v := range values[:len(values)-1] {
+		if v < latest {
+			below++
+		}
+	}
+	return float64(below) / float64(len(values)-1), true
+}
+
+// isOffHours reports weekends and 00:00-06:00 UTC, when crypto volume is
+// typically thinnest.
+func isOffHours(t time.Time) bool {
+	t = t.UTC()
+	switch t.Weekday() {
+	case time.Saturday, time.Sunday:
+		return true
+	}
+	return t.Hour() < 6
 }
 
 func (p *PricePoller) pollShortCandles(ctx context.Context) {
@@ -79,7 +321,7 @@ func (p *PricePoller) pollShortCandles(ctx context.Context) {
 	case <-time.After(10 * time.Second):
 	}
 
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(p.stretched(shortCandleEvery))
 	defer ticker.Stop()
 
 	coinIndex := 0
@@ -94,18 +336,27 @@ func (p *PricePoller) pollShortCandles(ctx context.Context) {
 			return
 		case <-ticker.C:
 			p.fetchShortBatch(ctx, &coinIndex, coinsPerTick)
+			ticker.Reset(p.stretched(shortCandleEvery))
 		}
 	}
 }
 
 func (p *PricePoller) fetchShortBatch(ctx context.Context, coinIndex *int, count int) {
-	symbols := domain.SupportedSymbols
+	if p.paused(ctx) {
+		return
+	}
+	symbols := domain.SupportedSymbols()
 	for i := 0; i < count; i++ {
 		symbol := symbols[*coinIndex%len(symbols)]
 		*coinIndex++
 
 		if err := p.priceService.RefreshShortCandles(ctx, symbol); err != nil {
 			log.Printf("short candle refresh error for %s: %v", symbol, err)
+			continue
+		}
+		p.publishClosedCandles(ctx, symbol, shortCandleIntervals)
+		if p.deriveLongCandles(ctx, symbol) {
+			p.publishClosedCandles(ctx, symbol, longCandleIntervals)
 		}
 	}
 }
@@ -118,7 +369,7 @@ func (p *PricePoller) pollLongCandles(ctx context.Context) {
 	case <-time.After(30 * time.Second):
 	}
 
-	ticker := time.NewTicker(30 * time.Minute)
+	ticker := time.NewTicker(p.stretched(longCandleEvery))
 	defer ticker.Stop()
 
 	coinIndex := 0
@@ -132,16 +383,92 @@ func (p *PricePoller) pollLongCandles(ctx context.Context) {
 			return
 		case <-ticker.C:
 			p.fetchLongBatch(ctx, &coinIndex)
+			ticker.Reset(p.stretched(longCandleEvery))
 		}
 	}
 }
 
 func (p *PricePoller) fetchLongBatch(ctx context.Context, coinIndex *int) {
-	symbols := domain.SupportedSymbols
+	if p.paused(ctx) {
+		return
+	}
+	symbols := domain.SupportedSymbols()
 	symbol := symbols[*coinIndex%len(symbols)]
 	*coinIndex++
 
-	if err := p.priceService.RefreshLongCandles(ctx, symbol); err != nil {
+	err := p.priceService.RefreshLongCandles(ctx, symbol)
+	if err != nil {
 		log.Printf("long candle refresh error for %s: %v", symbol, err)
 	}
+	if derived := p.deriveLongCandles(ctx, symbol); err != nil && !derived {
+		return
+	}
+	p.publishClosedCandles(ctx, symbol, longCandleIntervals)
+}
+
+// deriveLongCandles fills missing 4h and 1d candles of symbol from 1h ones
+// and reports whether any were stored.
+func (p *PricePoller) deriveLongCandles(ctx context.Context, symbol string) bool {
+	if p.deriver == nil {
+		return false
+	}
+	now := p.now().UTC()
+	n, err := p.deriver.Derive(ctx, symbol, now.Add(-deriveLookback), now)
+	if err != nil {
+		log.Printf("candle derive error for %s: %v", symbol, err)
+		return false
+	}
+	return n > 0
+}
+
+// publishClosedCandles emits one event per interval whose newest closed
+// candle is later than the last one published. The first observation of each
+// symbol/interval after startup is published too, so subscribers catch up.
+func (p *PricePoller) publishClosedCandles(ctx context.Context, symbol string, intervals []string) {
+	if p.closeReader == nil || p.publisher == nil {
+		return
+	}
+	now := p.now()
+	for _, interval := range intervals {
+		length := domain.IntervalDuration(interval)
+		candles, err := p.closeReader.GetCandles(ctx, symbol, interval, 2)
+		if err != nil {
+			log.Printf("candle close check error for %s %s: %v", symbol, interval, err)
+			continue
+		}
+		openTime, ok := latestClosedOpenTime(candles, length, now)
+		if !ok || !p.advanceClosed(symbol, interval, openTime) {
+			continue
+		}
+		p.publisher.PublishCandleClosed(events.CandleClosed{
+			Symbol:    symbol,
+			Interval:  interval,
+			OpenTime:  openTime,
+			CloseTime: openTime.Add(length),
+		})
+	}
+}
+
+func (p *PricePoller) advanceClosed(symbol, interval string, openTime time.Time) bool {
+	p.closeMu.Lock()
+	defer p.closeMu.Unlock()
+	key := symbol + "|" + interval
+	if !openTime.After(p.lastClosed[key]) {
+		return false
+	}
+	p.lastClosed[key] = openTime
+	return true
+}
+
+func latestClosedOpenTime(candles []*domain.Candle, length time.Duration, now time.Time) (time.Time, bool) {
+	if length <= 0 {
+		return time.Time{}, false
+	}
+	var latest time.Time
+	for _, c := range candles {
+		if !c.OpenTime.Add(length).After(now) && c.OpenTime.After(latest) {
+			latest = c.OpenTime
+		}
+	}
+	return latest, !latest.IsZero()
 }
@@ -2,10 +2,13 @@ package job
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -43,7 +46,7 @@ func TestFetchShortBatch(t *testing.T) {
 	if len(stub.shortSymbols) != 3 {
 		t.Fatalf("expected 3 symbols, got %d", len(stub.shortSymbols))
 	}
-	if stub.shortSymbols[0] != domain.SupportedSymbols[0] {
+	if stub.shortSymbols[0] != domain.SupportedSymbols()[0] {
 		t.Fatalf("unexpected symbol order: %+v", stub.shortSymbols)
 	}
 }
@@ -59,11 +62,201 @@ func TestFetchLongBatch(t *testing.T) {
 	if len(stub.longSymbols) != 1 {
 		t.Fatalf("expected 1 symbol, got %d", len(stub.longSymbols))
 	}
-	if stub.longSymbols[0] != domain.SupportedSymbols[0] {
+	if stub.longSymbols[0] != domain.SupportedSymbols()[0] {
 		t.Fatalf("unexpected symbol: %+v", stub.longSymbols)
 	}
 }
 
+func TestPricePollerNextInterval(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	weekdayNoon := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	saturday := time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name    string
+		now     time.Time
+		candles CandleReader
+		stretch float64
+		want    time.Duration
+	}{
+		{name: "baseline", now: weekdayNoon, want: 60 * time.Second},
+		{name: "weekend", now: saturday, want: 120 * time.Second},
+		{name: "overnight", now: weekdayNoon.Add(-9 * time.Hour), want: 120 * time.Second},
+		{name: "calm market", now: weekdayNoon, candles: stubCandleReader{spike: false}, want: 60 * time.Second},
+		{name: "volatile market", now: weekdayNoon, candles: stubCandleReader{spike: true}, want: 30 * time.Second},
+		{name: "volatile weekend", now: saturday, candles: stubCandleReader{spike: true}, want: 30 * time.Second},
+		{name: "quota running low", now: weekdayNoon, stretch: 2.5, want: 150 * time.Second},
+		{name: "quota half used", now: saturday, stretch: 1, want: 120 * time.Second},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			poller := NewPricePoller(tracer, &stubPriceService{}, 60)
+			poller.now = func() time.Time { return tc.now }
+			if tc.candles != nil {
+				poller.SetVolatilitySource(tc.candles)
+			}
+			if tc.stretch != 0 {
+				poller.SetQuotaBudget(stubQuota(tc.stretch))
+			}
+			if got := poller.scheduleNext(context.Background()); got != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			if poller.CurrentInterval() != tc.want {
+				t.Fatalf("expected current interval %v, got %v", tc.want, poller.CurrentInterval())
+			}
+		})
+	}
+}
+
+type stubQuota float64
+
+func (s stubQuota) Stretch() float64 { return float64(s) }
+
+func TestPricePollerNextIntervalRespectsMinimum(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	poller := NewPricePoller(tracer, &stubPriceService{}, 20)
+	poller.now = func() time.Time { return time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC) }
+	poller.SetVolatilitySource(stubCandleReader{spike: true})
+
+	if got := poller.nextInterval(context.Background()); got != DefaultMinPriceInterval {
+		t.Fatalf("expected %v floor, got %v", DefaultMinPriceInterval, got)
+	}
+
+	poller.pollInterval = 5 * time.Second
+	if got := poller.nextInterval(context.Background()); got != 5*time.Second {
+		t.Fatalf("expected configured interval below the floor to be kept, got %v", got)
+	}
+}
+
+func TestPricePollerPublishesClosedCandles(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	hour := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	reader := &fixedCandleReader{candles: []*domain.Candle{
+		{OpenTime: hour},
+		{OpenTime: hour.Add(-time.Hour)},
+	}}
+	published := &recordingPublisher{}
+	poller := NewPricePoller(tracer, &stubPriceService{}, 60)
+	poller.SetCandleEvents(reader, published)
+	now := hour.Add(30 * time.Minute)
+	poller.now = func() time.Time { return now }
+
+	poller.publishClosedCandles(context.Background(), "BTC", []string{"1h"})
+	poller.publishClosedCandles(context.Background(), "BTC", []string{"1h"})
+	if len(published.events) != 1 {
+		t.Fatalf("expected one event for the closed candle, got %+v", published.events)
+	}
+	if evt := published.events[0]; !evt.OpenTime.Equal(hour.Add(-time.Hour)) || !evt.CloseTime.Equal(hour) || evt.Interval != "1h" {
+		t.Fatalf("unexpected event: %+v", evt)
+	}
+
+	now = hour.Add(time.Hour)
+	poller.publishClosedCandles(context.Background(), "BTC", []string{"1h"})
+	if len(published.events) != 2 || !published.events[1].OpenTime.Equal(hour) {
+		t.Fatalf("expected the next close to be published, got %+v", published.events)
+	}
+}
+
+func TestFetchShortBatchPublishesAfterRefresh(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	bus := events.NewBus()
+	ch := bus.Subscribe("test", 16)
+	poller := NewPricePoller(tracer, &stubPriceService{}, 60)
+	poller.SetCandleEvents(stubCandleReader{}, bus)
+
+	idx := 0
+	poller.fetchShortBatch(context.Background(), &idx, 1)
+
+	if got := len(ch); got != len(shortCandleIntervals) {
+		t.Fatalf("expected %d candle events, got %d", len(shortCandleIntervals), got)
+	}
+}
+
+type stubCandleDeriver struct {
+	symbols []string
+	derived int
+}
+
+func (d *stubCandleDeriver) Derive(ctx context.Context, symbol string, from, to time.Time) (int, error) {
+	d.symbols = append(d.symbols, symbol)
+	return d.derived, nil
+}
+
+func TestFetchLongBatchDerivesWhenRefreshFails(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	bus := events.NewBus()
+	ch := bus.Subscribe("test", 16)
+	poller := NewPricePoller(tracer, &stubPriceService{longErr: errors.New("no 4h data")}, 60)
+	poller.SetCandleEvents(stubCandleReader{}, bus)
+	deriver := &stubCandleDeriver{}
+	poller.SetCandleDeriver(deriver)
+
+	idx := 0
+	poller.fetchLongBatch(context.Background(), &idx)
+	if len(deriver.symbols) != 1 || len(ch) != 0 {
+		t.Fatalf("expected a derive attempt and no events, got %v and %d events", deriver.symbols, len(ch))
+	}
+
+	deriver.derived = 2
+	poller.fetchLongBatch(context.Background(), &idx)
+	if got := len(ch); got != len(longCandleIntervals) {
+		t.Fatalf("expected %d candle events after deriving, got %d", len(longCandleIntervals), got)
+	}
+
+	poller.fetchShortBatch(context.Background(), &idx, 1)
+	if len(deriver.symbols) != 3 {
+		t.Fatalf("expected the short refresh to derive too, got %v", deriver.symbols)
+	}
+}
+
+type fixedCandleReader struct {
+	candles []*domain.Candle
+}
+
+func (r *fixedCandleReader) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
+	return r.candles, nil
+}
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []events.CandleClosed
+}
+
+func (r *recordingPublisher) PublishCandleClosed(evt events.CandleClosed) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, evt)
+}
+
+func TestATRPercentileNeedsHistory(t *testing.T) {
+	if _, ok := atrPercentile(stubCandleReader{}.series(10)); ok {
+		t.Fatal("expected too few candles to be rejected")
+	}
+}
+
+// stubCandleReader returns a week of flat hourly candles, optionally ending
+// in a wide-range candle.
+type stubCandleReader struct {
+	spike bool
+}
+
+func (s stubCandleReader) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
+	return s.series(limit), nil
+}
+
+func (s stubCandleReader) series(n int) []*domain.Candle {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]*domain.Candle, n)
+	for i := 0; i < n; i++ {
+		c := &domain.Candle{OpenTime: base.Add(time.Duration(i) * time.Hour), Open: 100, High: 101, Low: 99, Close: 100}
+		if s.spike && i == n-1 {
+			c.High, c.Low = 130, 80
+		}
+		// Newest first, as the repository returns them.
+		out[n-1-i] = c
+	}
+	return out
+}
+
 func eventually(t *testing.T, cond func() bool) {
 	t.Helper()
 	deadline := time.Now().Add(100 * time.Millisecond)
@@ -78,8 +271,10 @@ func eventually(t *testing.T, cond func() bool) {
 
 type stubPriceService struct {
 	refreshPricesCalls int
+	refreshedFor       [][]string
 	shortSymbols       []string
 	longSymbols        []string
+	longErr            error
 }
 
 func (s *stubPriceService) RefreshPrices(ctx context.Context) error {
@@ -87,6 +282,11 @@ func (s *stubPriceService) RefreshPrices(ctx context.Context) error {
 	return nil
 }
 
+func (s *stubPriceService) RefreshPricesFor(ctx context.Context, symbols []string) error {
+	s.refreshedFor = append(s.refreshedFor, symbols)
+	return nil
+}
+
 func (s *stubPriceService) RefreshShortCandles(ctx context.Context, symbol string) error {
 	s.shortSymbols = append(s.shortSymbols, symbol)
 	return nil
@@ -94,5 +294,5 @@ func (s *stubPriceService) RefreshShortCandles(ctx context.Context, symbol strin
 
 func (s *stubPriceService) RefreshLongCandles(ctx context.Context, symbol string) error {
 	s.longSymbols = append(s.longSymbols, symbol)
-	return nil
+	return s.longErr
 }
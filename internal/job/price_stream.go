@@ -0,0 +1,78 @@
+package job
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// DefaultPriceStreamStaleAfter is how long a symbol may go without a tick
+// before the price poller resumes polling its current price.
+const DefaultPriceStreamStaleAfter = 2 * time.Minute
+
+// PriceTickSource pushes price updates until ctx is cancelled, reconnecting
+// as needed.
+type PriceTickSource interface {
+	Run(ctx context.Context, onTick func(*domain.PriceSnapshot))
+}
+
+// PriceTickSink stores a streamed price update.
+type PriceTickSink interface {
+	ApplyPriceTick(ctx context.Context, snapshot *domain.PriceSnapshot) error
+}
+
+// PriceStreamJob feeds streamed ticks into the price cache. The price poller
+// skips the symbols it is receiving ticks for.
+type PriceStreamJob struct {
+	pauseGate
+
+	source     PriceTickSource
+	sink       PriceTickSink
+	staleAfter time.Duration
+	now        func() time.Time
+
+	mu       sync.Mutex
+	lastTick map[string]time.Time
+}
+
+func NewPriceStreamJob(source PriceTickSource, sink PriceTickSink) *PriceStreamJob {
+	return &PriceStreamJob{
+		source:     source,
+		sink:       sink,
+		staleAfter: DefaultPriceStreamStaleAfter,
+		now:        time.Now,
+		lastTick:   make(map[string]time.Time),
+	}
+}
+
+// Start runs the stream. Blocks until ctx is cancelled.
+func (j *PriceStreamJob) Start(ctx context.Context) {
+	log.Println("Price stream starting...")
+	j.source.Run(ctx, func(snap *domain.PriceSnapshot) { j.apply(ctx, snap) })
+	log.Println("Price stream stopped")
+}
+
+func (j *PriceStreamJob) apply(ctx context.Context, snap *domain.PriceSnapshot) {
+	if j.paused(ctx) {
+		return
+	}
+	if err := j.sink.ApplyPriceTick(ctx, snap); err != nil {
+		log.Printf("price stream cache write error for %s: %v", snap.Symbol, err)
+		return
+	}
+	j.mu.Lock()
+	j.lastTick[snap.Symbol] = j.now()
+	j.mu.Unlock()
+}
+
+// Live reports whether a tick for symbol was stored within the staleness
+// window. Symbols the stream does not cover are never live.
+func (j *PriceStreamJob) Live(symbol string) bool {
+	j.mu.Lock()
+	last, ok := j.lastTick[symbol]
+	j.mu.Unlock()
+	return ok && j.now().Sub(last) < j.staleAfter
+}
@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type stubTickSource struct{ ticks []*domain.PriceSnapshot }
+
+func (s stubTickSource) Run(ctx context.Context, onTick func(*domain.PriceSnapshot)) {
+	for _, tick := range s.ticks {
+		onTick(tick)
+	}
+}
+
+type stubTickSink struct {
+	applied []string
+	err     error
+}
+
+func (s *stubTickSink) ApplyPriceTick(ctx context.Context, snapshot *domain.PriceSnapshot) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.applied = append(s.applied, snapshot.Symbol)
+	return nil
+}
+
+func TestPriceStreamJobLiveness(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	sink := &stubTickSink{}
+	j := NewPriceStreamJob(stubTickSource{ticks: []*domain.PriceSnapshot{{Symbol: "BTC"}, {Symbol: "ETH"}}}, sink)
+	j.now = func() time.Time { return now }
+
+	if j.Live("BTC") {
+		t.Fatal("expected the stream to start out not live")
+	}
+	j.Start(context.Background())
+	if len(sink.applied) != 2 || !j.Live("BTC") || !j.Live("ETH") {
+		t.Fatalf("expected two ticks applied and both symbols live, got %v", sink.applied)
+	}
+	if j.Live("SOL") {
+		t.Fatal("expected a symbol without ticks not to be live")
+	}
+
+	now = now.Add(time.Minute)
+	j.apply(context.Background(), &domain.PriceSnapshot{Symbol: "BTC"})
+	now = now.Add(DefaultPriceStreamStaleAfter - time.Second)
+	if !j.Live("BTC") || j.Live("ETH") {
+		t.Fatal("expected ETH to go stale on its own while BTC keeps ticking")
+	}
+
+	failing := NewPriceStreamJob(stubTickSource{ticks: []*domain.PriceSnapshot{{Symbol: "BTC"}}}, &stubTickSink{err: errors.New("redis down")})
+	failing.Start(context.Background())
+	if failing.Live("BTC") {
+		t.Fatal("expected failed cache writes not to count as live")
+	}
+}
+
+type liveSymbols map[string]bool
+
+func (s liveSymbols) Live(symbol string) bool { return s[symbol] }
+
+func TestPricePollerPollsSymbolsTheStreamMisses(t *testing.T) {
+	stub := &stubPriceService{}
+	poller := NewPricePoller(trace.NewNoopTracerProvider().Tracer("test"), stub, 60)
+
+	all := liveSymbols{}
+	for _, symbol := range domain.SupportedSymbols() {
+		all[symbol] = true
+	}
+	poller.SetPriceStream(all)
+	if err := poller.refreshPrices(context.Background()); err != nil || stub.refreshPricesCalls != 0 || len(stub.refreshedFor) != 0 {
+		t.Fatalf("expected no poll while every symbol is live, got %d/%d calls err=%v", stub.refreshPricesCalls, len(stub.refreshedFor), err)
+	}
+
+	// ETH has gone stale, or has no stream pair.
+	all["ETH"] = false
+	if err := poller.refreshPrices(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stub.refreshPricesCalls != 0 || len(stub.refreshedFor) != 1 || len(stub.refreshedFor[0]) != 1 || stub.refreshedFor[0][0] != "ETH" {
+		t.Fatalf("expected only ETH polled, got %v", stub.refreshedFor)
+	}
+
+	poller.SetPriceStream(nil)
+	if err := poller.refreshPrices(context.Background()); err != nil || stub.refreshPricesCalls != 1 {
+		t.Fatalf("expected a full poll without a stream, got %d calls", stub.refreshPricesCalls)
+	}
+}
@@ -0,0 +1,32 @@
+package job
+
+import "time"
+
+// QuotaBudget reports how far to stretch polling intervals so a metered
+// provider's remaining plan quota lasts; 1 means no stretch.
+type QuotaBudget interface {
+	Stretch() float64
+}
+
+// quotaGate is embedded by pollers whose calls count against a provider
+// quota.
+type quotaGate struct {
+	quota QuotaBudget
+}
+
+// SetQuotaBudget makes the job space its provider calls out further as q's
+// budget depletes.
+func (g *quotaGate) SetQuotaBudget(q QuotaBudget) {
+	g.quota = q
+}
+
+// stretched returns d lengthened by the current quota stretch.
+func (g *quotaGate) stretched(d time.Duration) time.Duration {
+	if g.quota == nil {
+		return d
+	}
+	if s := g.quota.Stretch(); s > 1 {
+		return time.Duration(float64(d) * s)
+	}
+	return d
+}
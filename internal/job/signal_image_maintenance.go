@@ -9,25 +9,52 @@ import (
 )
 
 const (
-	defaultImageRetryBatchSize = 20
-	imageRetryTick             = 5 * time.Minute
-	imageCleanupTick           = time.Hour
+	defaultImageRetryBatchSize   = 20
+	defaultImageCleanupBatchSize = 1000
+	defaultImageRetryTick        = 5 * time.Minute
+	imageCleanupTick             = time.Hour
 )
 
 type SignalImageMaintainer interface {
 	RetryFailedImages(ctx context.Context, limit int) (int, error)
-	DeleteExpiredSignalImages(ctx context.Context) (int64, error)
+	DeleteExpiredSignalImages(ctx context.Context, batch int) (int64, error)
 }
 
 type SignalImageMaintenance struct {
-	tracer   trace.Tracer
-	maintain SignalImageMaintainer
+	tracer       trace.Tracer
+	maintain     SignalImageMaintainer
+	retryBatch   int
+	cleanupBatch int
+	retryEvery   time.Duration
 }
 
 func NewSignalImageMaintenance(tracer trace.Tracer, maintain SignalImageMaintainer) *SignalImageMaintenance {
 	return &SignalImageMaintenance{
-		tracer:   tracer,
-		maintain: maintain,
+		tracer:       tracer,
+		maintain:     maintain,
+		retryBatch:   defaultImageRetryBatchSize,
+		cleanupBatch: defaultImageCleanupBatchSize,
+		retryEvery:   defaultImageRetryTick,
+	}
+}
+
+// SetBatchSizes sets how many failed renders one retry pass picks up and
+// how many expired images one cleanup statement deletes. Non-positive
+// values keep the current sizes.
+func (j *SignalImageMaintenance) SetBatchSizes(retry, cleanup int) {
+	if retry > 0 {
+		j.retryBatch = retry
+	}
+	if cleanup > 0 {
+		j.cleanupBatch = cleanup
+	}
+}
+
+// SetRetryInterval sets how often failed renders are retried. Call it
+// before Start.
+func (j *SignalImageMaintenance) SetRetryInterval(d time.Duration) {
+	if d > 0 {
+		j.retryEvery = d
 	}
 }
 
@@ -38,7 +65,7 @@ func (j *SignalImageMaintenance) Start(ctx context.Context) {
 	}
 
 	log.Println("Signal image maintenance starting...")
-	retryTicker := time.NewTicker(imageRetryTick)
+	retryTicker := time.NewTicker(j.retryEvery)
 	cleanupTicker := time.NewTicker(imageCleanupTick)
 	defer retryTicker.Stop()
 	defer cleanupTicker.Stop()
@@ -64,7 +91,7 @@ func (j *SignalImageMaintenance) runRetry(ctx context.Context) {
 		_, span := j.tracer.Start(ctx, "signal-image-job.retry")
 		defer span.End()
 	}
-	count, err := j.maintain.RetryFailedImages(ctx, defaultImageRetryBatchSize)
+	count, err := j.maintain.RetryFailedImages(ctx, j.retryBatch)
 	if err != nil {
 		log.Printf("signal image retry error: %v", err)
 		return
@@ -79,7 +106,7 @@ func (j *SignalImageMaintenance) runCleanup(ctx context.Context) {
 		_, span := j.tracer.Start(ctx, "signal-image-job.cleanup")
 		defer span.End()
 	}
-	deleted, err := j.maintain.DeleteExpiredSignalImages(ctx)
+	deleted, err := j.maintain.DeleteExpiredSignalImages(ctx, j.cleanupBatch)
 	if err != nil {
 		log.Printf("signal image cleanup error: %v", err)
 		return
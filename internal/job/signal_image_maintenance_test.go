@@ -12,6 +12,7 @@ import (
 func TestSignalImageMaintenanceStartRunsRetryAndCleanup(t *testing.T) {
 	stub := &stubSignalImageMaintainer{}
 	job := NewSignalImageMaintenance(trace.NewNoopTracerProvider().Tracer("test"), stub)
+	job.SetBatchSizes(5, 250)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	done := make(chan struct{})
@@ -35,11 +36,15 @@ func TestSignalImageMaintenanceStartRunsRetryAndCleanup(t *testing.T) {
 	if atomic.LoadInt32(&stub.cleanupCalls) == 0 {
 		t.Fatal("expected cleanup to run at least once")
 	}
+	if got := atomic.LoadInt32(&stub.cleanupBatch); got != 250 {
+		t.Fatalf("expected cleanup batch 250, got %d", got)
+	}
 }
 
 type stubSignalImageMaintainer struct {
 	retryCalls   int32
 	cleanupCalls int32
+	cleanupBatch int32
 }
 
 func (s *stubSignalImageMaintainer) RetryFailedImages(ctx context.Context, limit int) (int, error) {
@@ -47,7 +52,8 @@ func (s *stubSignalImageMaintainer) RetryFailedImages(ctx context.Context, limit
 	return 0, nil
 }
 
-func (s *stubSignalImageMaintainer) DeleteExpiredSignalImages(ctx context.Context) (int64, error) {
+func (s *stubSignalImageMaintainer) DeleteExpiredSignalImages(ctx context.Context, batch int) (int64, error) {
 	atomic.AddInt32(&s.cleanupCalls, 1)
+	atomic.StoreInt32(&s.cleanupBatch, int32(batch))
 	return 0, nil
 }
@@ -7,23 +7,37 @@ import (
 	"sync"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
 
 var (
-	shortSignalIntervals = []string{"5m", "15m", "1h"}
-	longSignalIntervals  = []string{"4h", "1d"}
+	shortSignalIntervals = domain.IntervalsInTier(domain.IntervalTierShort)
+	longSignalIntervals  = domain.IntervalsInTier(domain.IntervalTierLong)
 )
 
-const maxSeenAlertSignals = 10000
+const (
+	maxSeenAlertSignals = 10000
+
+	// DefaultSignalConcurrency bounds how many symbols generate at once.
+	DefaultSignalConcurrency = 4
+	// signalSymbolTimeout stops one slow symbol from holding a worker for
+	// the rest of the cycle.
+	signalSymbolTimeout = 2 * time.Minute
+)
 
 // SignalPoller periodically computes and stores technical signals.
 type SignalPoller struct {
+	pauseGate
+
 	tracer        trace.Tracer
 	signalService SignalGenerator
 	alertSink     SignalAlertSink
+	concurrency   int
+	symbolTimeout time.Duration
+	candleEvents  <-chan events.CandleClosed
 
 	alertMu        sync.Mutex
 	seenAlertKeys  map[string]struct{}
@@ -31,22 +45,66 @@ type SignalPoller struct {
 }
 
 type SignalGenerator interface {
+	GenerateForNewCandles(ctx context.Context, symbol string, intervals []string, through time.Time) ([]domain.Signal, error)
+}
+
+// ForcedSignalGenerator re-evaluates every requested interval, whether or not
+// a candle closed since the last run. GenerateSymbols uses it when the signal
+// service provides it.
+type ForcedSignalGenerator interface {
 	GenerateForSymbol(ctx context.Context, symbol string, intervals []string) ([]domain.Signal, error)
 }
 
+// SignalSymbolLister lists the symbols to poll, including ones with candles
+// ingested from external collectors. Without it the poller covers the
+// tracked symbols only.
+type SignalSymbolLister interface {
+	Symbols(ctx context.Context) []string
+}
+
 type SignalAlertSink interface {
 	NotifySignals(ctx context.Context, signals []domain.Signal) error
 }
 
+// SignalAlertSinks notifies every sink in order. All sinks are called even
+// if one fails; the first error is returned.
+type SignalAlertSinks []SignalAlertSink
+
+func (s SignalAlertSinks) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	var first error
+	for _, sink := range s {
+		if err := sink.NotifySignals(ctx, signals); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
 func NewSignalPoller(tracer trace.Tracer, signalService SignalGenerator, alertSink SignalAlertSink) *SignalPoller {
 	return &SignalPoller{
 		tracer:        tracer,
 		signalService: signalService,
 		alertSink:     alertSink,
+		concurrency:   DefaultSignalConcurrency,
+		symbolTimeout: signalSymbolTimeout,
 		seenAlertKeys: make(map[string]struct{}),
 	}
 }
 
+// SetConcurrency sets how many symbols are generated in parallel per batch.
+func (p *SignalPoller) SetConcurrency(n int) {
+	if n > 0 {
+		p.concurrency = n
+	}
+}
+
+// SetCandleEvents switches generation from fixed timers to candle-closed
+// events: each event regenerates that symbol and interval up to the candle
+// that closed.
+func (p *SignalPoller) SetCandleEvents(ch <-chan events.CandleClosed) {
+	p.candleEvents = ch
+}
+
 // Start launches background signal generation goroutines. Blocks until ctx is cancelled.
 func (p *SignalPoller) Start(ctx context.Context) {
 	if p.signalService == nil {
@@ -56,13 +114,47 @@ func (p *SignalPoller) Start(ctx context.Context) {
 	}
 
 	log.Println("Signal poller starting...")
-	go p.pollShortSignals(ctx)
-	go p.pollLongSignals(ctx)
+	if p.candleEvents != nil {
+		go p.consumeCandleEvents(ctx)
+	} else {
+		go p.pollShortSignals(ctx)
+		go p.pollLongSignals(ctx)
+	}
 
 	<-ctx.Done()
 	log.Println("Signal poller stopped")
 }
 
+// consumeCandleEvents generates signals for each closed candle, using the
+// same worker bound as the timed batches.
+func (p *SignalPoller) consumeCandleEvents(ctx context.Context) {
+	sem := make(chan struct{}, max(p.concurrency, 1))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-p.candleEvents:
+			if !ok {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				p.generateAndLog(ctx, "candle-close", evt.Symbol, []string{evt.Interval}, evt.OpenTime)
+			}()
+		}
+	}
+}
+
 func (p *SignalPoller) pollShortSignals(ctx context.Context) {
 	coinIndex := 0
 	coinsPerTick := 2
@@ -83,20 +175,117 @@ func (p *SignalPoller) pollShortSignals(ctx context.Context) {
 }
 
 func (p *SignalPoller) fetchShortBatch(ctx context.Context, coinIndex *int, count int) {
-	symbols := domain.SupportedSymbols
+	symbols := p.symbols(ctx)
+	batch := make([]string, 0, count)
 	for i := 0; i < count; i++ {
-		symbol := symbols[*coinIndex%len(symbols)]
+		batch = append(batch, symbols[*coinIndex%len(symbols)])
 		*coinIndex++
+	}
+	p.generateBatch(ctx, "short", batch, shortSignalIntervals)
+}
+
+// generateBatch runs symbols through a bounded worker pool and returns once
+// all of them have finished. Errors and panics are logged per symbol.
+func (p *SignalPoller) generateBatch(ctx context.Context, tier string, symbols []string, intervals []string) {
+	p.forEachBounded(ctx, len(symbols), func(i int) {
+		p.generateAndLog(ctx, tier, symbols[i], intervals, time.Time{})
+	})
+}
 
-		signals, err := p.signalService.GenerateForSymbol(ctx, symbol, shortSignalIntervals)
+// GenerateSymbols generates signals for symbols on request, through the same
+// bounded worker pool as the polling batches. Results are in request order
+// and a failing symbol only fails its own entry.
+func (p *SignalPoller) GenerateSymbols(ctx context.Context, symbols []string, intervals []string) []domain.SignalGenerationResult {
+	results := make([]domain.SignalGenerationResult, len(symbols))
+	ran := make([]bool, len(symbols))
+	p.forEachBounded(ctx, len(symbols), func(i int) {
+		ran[i] = true
+		results[i].Symbol = symbols[i]
+		if domain.SignalsPaused(symbols[i]) {
+			results[i].Error = "signal generation is paused for " + symbols[i]
+			return
+		}
+		signals, err := p.generateSymbol(ctx, symbols[i], intervals, true, time.Time{})
 		if err != nil {
-			log.Printf("short signal generation error for %s: %v", symbol, err)
-			continue
+			results[i].Error = err.Error()
+			return
+		}
+		if signals == nil {
+			signals = []domain.Signal{}
+		}
+		results[i].Signals = signals
+	})
+	for i := range results {
+		if !ran[i] {
+			results[i] = domain.SignalGenerationResult{Symbol: symbols[i], Error: ctx.Err().Error()}
 		}
-		p.notifySignals(ctx, signals)
+	}
+	return results
+}
+
+// forEachBounded calls fn for 0..n-1 with at most p.concurrency calls in
+// flight. It stops scheduling once ctx is done and waits for running calls.
+func (p *SignalPoller) forEachBounded(ctx context.Context, n int, fn func(i int)) {
+	sem := make(chan struct{}, max(p.concurrency, 1))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+}
+
+func (p *SignalPoller) generateAndLog(ctx context.Context, tier, symbol string, intervals []string, through time.Time) {
+	if p.paused(ctx) || domain.SignalsPaused(symbol) {
+		return
+	}
+	if _, err := p.generateSymbol(ctx, symbol, intervals, false, through); err != nil {
+		log.Printf("%s signal generation error for %s: %v", tier, symbol, err)
 	}
 }
 
+// generateSymbol generates one symbol under the per-symbol timeout and
+// alerts on new signals. force re-evaluates intervals without a newly closed
+// candle. A non-zero through limits evaluation to candles opened by then. A
+// panic in the generator is returned as an error.
+func (p *SignalPoller) generateSymbol(ctx context.Context, symbol string, intervals []string, force bool, through time.Time) (signals []domain.Signal, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			signals, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	genCtx := ctx
+	if p.symbolTimeout > 0 {
+		var cancel context.CancelFunc
+		genCtx, cancel = context.WithTimeout(ctx, p.symbolTimeout)
+		defer cancel()
+	}
+
+	if forced, ok := p.signalService.(ForcedSignalGenerator); ok && force {
+		signals, err = forced.GenerateForSymbol(genCtx, symbol, intervals)
+	} else {
+		signals, err = p.signalService.GenerateForNewCandles(genCtx, symbol, intervals, through)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.notifySignals(ctx, signals)
+	return signals, nil
+}
+
 func (p *SignalPoller) notifySignals(ctx context.Context, generated []domain.Signal) {
 	if p.alertSink == nil || len(generated) == 0 {
 		return
@@ -155,16 +344,20 @@ func (p *SignalPoller) pollLongSignals(ctx context.Context) {
 }
 
 func (p *SignalPoller) fetchLongBatch(ctx context.Context, coinIndex *int) {
-	symbols := domain.SupportedSymbols
+	symbols := p.symbols(ctx)
 	symbol := symbols[*coinIndex%len(symbols)]
 	*coinIndex++
 
-	signals, err := p.signalService.GenerateForSymbol(ctx, symbol, longSignalIntervals)
-	if err != nil {
-		log.Printf("long signal generation error for %s: %v", symbol, err)
-		return
+	p.generateBatch(ctx, "long", []string{symbol}, longSignalIntervals)
+}
+
+func (p *SignalPoller) symbols(ctx context.Context) []string {
+	if lister, ok := p.signalService.(SignalSymbolLister); ok {
+		if symbols := lister.Symbols(ctx); len(symbols) > 0 {
+			return symbols
+		}
 	}
-	p.notifySignals(ctx, signals)
+	return domain.SupportedSymbols()
 }
 
 func signalAlertKey(s domain.Signal) string {
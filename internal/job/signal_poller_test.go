@@ -2,10 +2,14 @@ package job
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -21,7 +25,7 @@ func TestSignalPollerStart(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	go poller.Start(ctx)
 
-	eventuallySignal(t, func() bool { return stub.calls > 0 })
+	eventuallySignal(t, func() bool { return stub.callCount() > 0 })
 	cancel()
 }
 
@@ -46,8 +50,17 @@ func TestSignalPollerFetchShortBatch(t *testing.T) {
 	if len(stub.symbols) != 3 {
 		t.Fatalf("expected 3 symbols, got %d", len(stub.symbols))
 	}
-	if stub.symbols[0] != domain.SupportedSymbols[0] {
-		t.Fatalf("unexpected symbol order: %+v", stub.symbols)
+	got := append([]string(nil), stub.symbols...)
+	want := append([]string(nil), domain.SupportedSymbols()[:3]...)
+	sort.Strings(got)
+	sort.Strings(want)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected the first 3 supported symbols, got %+v", stub.symbols)
+		}
+	}
+	if idx != 3 {
+		t.Fatalf("expected coin index 3, got %d", idx)
 	}
 	if len(stub.intervals) == 0 || len(stub.intervals[0]) != 3 {
 		t.Fatalf("unexpected interval set: %+v", stub.intervals)
@@ -73,6 +86,26 @@ func TestSignalPollerFetchLongBatch(t *testing.T) {
 	}
 }
 
+type listingSignalService struct {
+	stubSignalService
+	listed []string
+}
+
+func (s *listingSignalService) Symbols(ctx context.Context) []string { return s.listed }
+
+func TestSignalPollerPollsListedSymbols(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	stub := &listingSignalService{listed: []string{"BTC", "PEPE"}}
+	poller := NewSignalPoller(tracer, stub, nil)
+
+	idx := 1
+	poller.fetchLongBatch(context.Background(), &idx)
+
+	if len(stub.symbols) != 1 || stub.symbols[0] != "PEPE" {
+		t.Fatalf("expected the ingested symbol polled, got %+v", stub.symbols)
+	}
+}
+
 func TestSignalPollerDedupeAlerts(t *testing.T) {
 	tracer := trace.NewNoopTracerProvider().Tracer("test")
 	alerts := &stubSignalAlerter{}
@@ -95,26 +128,239 @@ func TestSignalPollerDedupeAlerts(t *testing.T) {
 	}
 }
 
+func TestSignalPollerIsolatesFailingSymbols(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	symbols := domain.SupportedSymbols()[:4]
+	stub := &stubSignalService{
+		errFor:   map[string]error{symbols[0]: errors.New("boom")},
+		panicFor: symbols[1],
+		perSymbol: func(symbol string) []domain.Signal {
+			return []domain.Signal{{Symbol: symbol, Interval: "5m", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong}}
+		},
+	}
+	alerts := &stubSignalAlerter{}
+	poller := NewSignalPoller(tracer, stub, alerts)
+
+	poller.generateBatch(context.Background(), "short", symbols, shortSignalIntervals)
+
+	if stub.callCount() != 4 {
+		t.Fatalf("expected every symbol to run, got %d calls", stub.callCount())
+	}
+	if alerts.notifyCalls != 2 {
+		t.Fatalf("expected alerts for the 2 healthy symbols, got %d", alerts.notifyCalls)
+	}
+}
+
+func TestSignalPollerBoundsConcurrency(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	stub := &stubSignalService{delay: 20 * time.Millisecond}
+	poller := NewSignalPoller(tracer, stub, nil)
+	poller.SetConcurrency(2)
+
+	start := time.Now()
+	poller.generateBatch(context.Background(), "short", domain.SupportedSymbols()[:6], shortSignalIntervals)
+	elapsed := time.Since(start)
+
+	if stub.callCount() != 6 {
+		t.Fatalf("expected 6 calls, got %d", stub.callCount())
+	}
+	if stub.maxInFlight != 2 {
+		t.Fatalf("expected at most 2 symbols in flight, got %d", stub.maxInFlight)
+	}
+	if elapsed >= 6*stub.delay {
+		t.Fatalf("expected parallel generation, took %v", elapsed)
+	}
+}
+
+func TestSignalPollerSymbolTimeout(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	stub := &stubSignalService{waitForCancel: true}
+	poller := NewSignalPoller(tracer, stub, nil)
+	poller.symbolTimeout = 10 * time.Millisecond
+
+	done := make(chan struct{})
+	go func() {
+		poller.generateBatch(context.Background(), "long", []string{"BTC"}, longSignalIntervals)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the per-symbol timeout to release the batch")
+	}
+}
+
+func TestSignalPollerConsumesCandleEvents(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	stub := &stubSignalService{}
+	poller := NewSignalPoller(tracer, stub, nil)
+
+	open := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	ch := make(chan events.CandleClosed, 2)
+	ch <- events.CandleClosed{Symbol: "BTC", Interval: "1h", OpenTime: open}
+	ch <- events.CandleClosed{Symbol: "ETH", Interval: "1d", OpenTime: open}
+	close(ch)
+	poller.SetCandleEvents(ch)
+
+	poller.consumeCandleEvents(context.Background())
+
+	if stub.callCount() != 2 {
+		t.Fatalf("expected one generation per event, got %d", stub.callCount())
+	}
+	for i, intervals := range stub.intervals {
+		if len(intervals) != 1 {
+			t.Fatalf("expected only the closed interval, got %+v", stub.intervals)
+		}
+		if !stub.through[i].Equal(open) {
+			t.Fatalf("expected evaluation up to the closed candle, got %v", stub.through[i])
+		}
+	}
+}
+
+func TestSignalPollerGenerateSymbolsReportsPartialFailure(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	stub := &stubForcedSignalService{stubSignalService: stubSignalService{
+		errFor:   map[string]error{"ETH": errors.New("no candles")},
+		panicFor: "SOL",
+		perSymbol: func(symbol string) []domain.Signal {
+			return []domain.Signal{{Symbol: symbol, Interval: "1h", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong}}
+		},
+	}}
+	alerts := &stubSignalAlerter{}
+	poller := NewSignalPoller(tracer, stub, alerts)
+	poller.SetConcurrency(2)
+
+	results := poller.GenerateSymbols(context.Background(), []string{"BTC", "ETH", "SOL", "ADA"}, []string{"1h"})
+
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for i, want := range []string{"BTC", "ETH", "SOL", "ADA"} {
+		if results[i].Symbol != want {
+			t.Fatalf("expected results in request order, got %+v", results)
+		}
+	}
+	if len(results[0].Signals) != 1 || results[0].Error != "" {
+		t.Fatalf("unexpected BTC result %+v", results[0])
+	}
+	if results[1].Error != "no candles" || results[2].Error == "" {
+		t.Fatalf("expected ETH and SOL to fail, got %+v", results[1:3])
+	}
+	if stub.forcedCalls != 4 || stub.callCount() != 4 {
+		t.Fatalf("expected forced generation for every symbol, got %d/%d", stub.forcedCalls, stub.callCount())
+	}
+	if alerts.notifyCalls != 2 {
+		t.Fatalf("expected alerts for the 2 healthy symbols, got %d", alerts.notifyCalls)
+	}
+}
+
+func TestSignalPollerGenerateSymbolsCancelled(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	stub := &stubSignalService{}
+	poller := NewSignalPoller(tracer, stub, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := poller.GenerateSymbols(ctx, []string{"BTC"}, nil)
+
+	if stub.callCount() != 0 {
+		t.Fatalf("expected no generation after cancel, got %d calls", stub.callCount())
+	}
+	if results[0].Symbol != "BTC" || results[0].Error != context.Canceled.Error() {
+		t.Fatalf("expected cancelled result, got %+v", results[0])
+	}
+}
+
 type stubSignalService struct {
-	calls     int
-	symbols   []string
-	intervals [][]string
-	toReturn  []domain.Signal
+	mu            sync.Mutex
+	calls         int
+	symbols       []string
+	intervals     [][]string
+	through       []time.Time
+	toReturn      []domain.Signal
+	perSymbol     func(symbol string) []domain.Signal
+	errFor        map[string]error
+	panicFor      string
+	delay         time.Duration
+	waitForCancel bool
+	inFlight      int
+	maxInFlight   int
 }
 
-func (s *stubSignalService) GenerateForSymbol(ctx context.Context, symbol string, intervals []string) ([]domain.Signal, error) {
+func (s *stubSignalService) GenerateForNewCandles(ctx context.Context, symbol string, intervals []string, through time.Time) ([]domain.Signal, error) {
+	s.mu.Lock()
 	s.calls++
 	s.symbols = append(s.symbols, symbol)
 	s.intervals = append(s.intervals, append([]string(nil), intervals...))
+	s.through = append(s.through, through)
+	s.inFlight++
+	s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.inFlight--
+		s.mu.Unlock()
+	}()
+
+	if s.delay > 0 {
+		time.Sleep(s.delay)
+	}
+	if s.waitForCancel {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if symbol == s.panicFor {
+		panic("generator exploded")
+	}
+	if err := s.errFor[symbol]; err != nil {
+		return nil, err
+	}
+	if s.perSymbol != nil {
+		return s.perSymbol(symbol), nil
+	}
 	return append([]domain.Signal(nil), s.toReturn...), nil
 }
 
+type stubForcedSignalService struct {
+	stubSignalService
+	forcedCalls int
+}
+
+func (s *stubForcedSignalService) GenerateForSymbol(ctx context.Context, symbol string, intervals []string) ([]domain.Signal, error) {
+	s.mu.Lock()
+	s.forcedCalls++
+	s.mu.Unlock()
+	return s.GenerateForNewCandles(ctx, symbol, intervals, time.Time{})
+}
+
+func (s *stubSignalService) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestSignalAlertSinksNotifiesAll(t *testing.T) {
+	first, second := &stubSignalAlerter{}, &stubSignalAlerter{}
+	sinks := SignalAlertSinks{first, second}
+
+	if err := sinks.NotifySignals(context.Background(), []domain.Signal{{Symbol: "BTC"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.notifyCalls != 1 || second.notifyCalls != 1 {
+		t.Fatalf("expected both sinks notified, got %d and %d", first.notifyCalls, second.notifyCalls)
+	}
+}
+
 type stubSignalAlerter struct {
+	mu          sync.Mutex
 	notifyCalls int
 	lastSignals []domain.Signal
 }
 
 func (s *stubSignalAlerter) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.notifyCalls++
 	s.lastSignals = append([]domain.Signal(nil), signals...)
 	return nil
@@ -0,0 +1,97 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultSnapshotPoll = time.Minute
+	snapshotPruneTick   = time.Hour
+)
+
+// SnapshotCapturer stores one round of market snapshots and drops old ones.
+type SnapshotCapturer interface {
+	Capture(ctx context.Context) (int, error)
+	Prune(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// SnapshotPoller captures snapshots (order book depth, derivatives
+// positioning) every poll interval and drops those older than the retention
+// window hourly. The name labels its logs and spans.
+type SnapshotPoller struct {
+	pauseGate
+	quotaGate
+
+	tracer    trace.Tracer
+	name      string
+	capturer  SnapshotCapturer
+	every     time.Duration
+	retention time.Duration
+}
+
+func NewSnapshotPoller(tracer trace.Tracer, name string, capturer SnapshotCapturer, every, retention time.Duration) *SnapshotPoller {
+	if every <= 0 {
+		every = defaultSnapshotPoll
+	}
+	return &SnapshotPoller{tracer: tracer, name: name, capturer: capturer, every: every, retention: retention}
+}
+
+func (j *SnapshotPoller) Start(ctx context.Context) {
+	if j == nil || j.capturer == nil {
+		<-ctx.Done()
+		return
+	}
+
+	log.Printf("%s poller starting...", j.name)
+	captureTicker := time.NewTicker(j.stretched(j.every))
+	pruneTicker := time.NewTicker(snapshotPruneTick)
+	defer captureTicker.Stop()
+	defer pruneTicker.Stop()
+
+	j.runCapture(ctx)
+	j.runPrune(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("%s poller stopped", j.name)
+			return
+		case <-captureTicker.C:
+			j.runCapture(ctx)
+			captureTicker.Reset(j.stretched(j.every))
+		case <-pruneTicker.C:
+			j.runPrune(ctx)
+		}
+	}
+}
+
+func (j *SnapshotPoller) runCapture(ctx context.Context) {
+	if j.paused(ctx) {
+		return
+	}
+	if j.tracer != nil {
+		_, span := j.tracer.Start(ctx, j.name+"-job.capture")
+		defer span.End()
+	}
+	if _, err := j.capturer.Capture(ctx); err != nil {
+		log.Printf("%s capture error: %v", j.name, err)
+	}
+}
+
+func (j *SnapshotPoller) runPrune(ctx context.Context) {
+	if j.tracer != nil {
+		_, span := j.tracer.Start(ctx, j.name+"-job.prune")
+		defer span.End()
+	}
+	deleted, err := j.capturer.Prune(ctx, j.retention)
+	if err != nil {
+		log.Printf("%s prune error: %v", j.name, err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("%s prune removed %d snapshot(s)", j.name, deleted)
+	}
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultSymbolRegistryRefresh is how often processes reload the symbol
+// registry to pick up assets another process added or removed.
+const DefaultSymbolRegistryRefresh = time.Minute
+
+// SymbolRegistryReloader replaces the tracked assets with the stored ones.
+type SymbolRegistryReloader interface {
+	Reload(ctx context.Context) error
+}
+
+// SymbolRegistryRefresh periodically reloads the symbol registry. Pollers,
+// the bot and the API read the registry on every use, so a reload is all it
+// takes for them to follow an added or removed asset.
+type SymbolRegistryRefresh struct {
+	reloader SymbolRegistryReloader
+	every    time.Duration
+}
+
+func NewSymbolRegistryRefresh(reloader SymbolRegistryReloader, every time.Duration) *SymbolRegistryRefresh {
+	if every <= 0 {
+		every = DefaultSymbolRegistryRefresh
+	}
+	return &SymbolRegistryRefresh{reloader: reloader, every: every}
+}
+
+// Start reloads on every tick. Blocks until ctx is cancelled. A failed
+// reload keeps the current registry.
+func (j *SymbolRegistryRefresh) Start(ctx context.Context) {
+	ticker := time.NewTicker(j.every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.reloader.Reload(ctx); err != nil {
+				log.Printf("symbol registry reload error: %v", err)
+			}
+		}
+	}
+}
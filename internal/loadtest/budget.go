@@ -0,0 +1,27 @@
+package loadtest
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// EnforceBudget runs bench with testing.Benchmark and fails t when the mean
+// time per operation exceeds budget. Wall-clock budgets flake on loaded or
+// -race runs, so they only run when PERF_BUDGETS=on, and never in -short mode.
+func EnforceBudget(t *testing.T, budget time.Duration, bench func(b *testing.B)) {
+	t.Helper()
+	if testing.Short() || os.Getenv("PERF_BUDGETS") != "on" {
+		t.Skip("performance budgets disabled; set PERF_BUDGETS=on to enforce them")
+	}
+
+	result := testing.Benchmark(bench)
+	if result.N == 0 {
+		t.Fatal("benchmark did not run")
+	}
+	perOp := time.Duration(result.NsPerOp())
+	t.Logf("%s/op (budget %s), %d allocs/op", perOp, budget, result.AllocsPerOp())
+	if perOp > budget {
+		t.Fatalf("performance budget exceeded: %s/op > %s", perOp, budget)
+	}
+}
@@ -0,0 +1,238 @@
+// Package loadtest provides a small constant-rate HTTP load generator (in the
+// spirit of vegeta/k6) and helpers for enforcing performance budgets from Go
+// tests and benchmarks.
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Target is a single request template replayed by the attacker.
+type Target struct {
+	Name   string
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Attack configures a constant-rate run. Targets are used round-robin.
+type Attack struct {
+	Rate     int // requests per second
+	Duration time.Duration
+	Workers  int
+	Timeout  time.Duration
+	Client   *http.Client
+}
+
+// Budget is the performance envelope a run must stay within.
+type Budget struct {
+	MaxP95       time.Duration
+	MaxP99       time.Duration
+	MaxErrorRate float64
+}
+
+// Metrics summarises a run.
+type Metrics struct {
+	Requests   int
+	Errors     int
+	StatusCode map[int]int
+	Mean       time.Duration
+	P50        time.Duration
+	P95        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+	Elapsed    time.Duration
+}
+
+func (m Metrics) ErrorRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+	return float64(m.Errors) / float64(m.Requests)
+}
+
+func (m Metrics) Throughput() float64 {
+	if m.Elapsed <= 0 {
+		return 0
+	}
+	return float64(m.Requests) / m.Elapsed.Seconds()
+}
+
+func (m Metrics) String() string {
+	return fmt.Sprintf(
+		"requests=%d errors=%d (%.2f%%) rps=%.1f mean=%s p50=%s p95=%s p99=%s max=%s",
+		m.Requests, m.Errors, m.ErrorRate()*100, m.Throughput(), m.Mean, m.P50, m.P95, m.P99, m.Max,
+	)
+}
+
+// Check returns an error describing every budget the run exceeded.
+func (m Metrics) Check(b Budget) error {
+	var violations []string
+	if b.MaxP95 > 0 && m.P95 > b.MaxP95 {
+		violations = append(violations, fmt.Sprintf("p95 %s > %s", m.P95, b.MaxP95))
+	}
+	if b.MaxP99 > 0 && m.P99 > b.MaxP99 {
+		violations = append(violations, fmt.Sprintf("p99 %s > %s", m.P99, b.MaxP99))
+	}
+	if m.ErrorRate() > b.MaxErrorRate {
+		violations = append(violations, fmt.Sprintf("error rate %.4f > %.4f", m.ErrorRate(), b.MaxErrorRate))
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("performance budget exceeded: %v", violations)
+}
+
+type sample struct {
+	latency time.Duration
+	status  int
+	err     error
+}
+
+// Run fires requests at a constant rate until Duration elapses or ctx is
+// cancelled, then returns latency and error metrics. Non-2xx responses count
+// as errors.
+func Run(ctx context.Context, a Attack, targets []Target) (Metrics, error) {
+	if len(targets) == 0 {
+		return Metrics{}, fmt.Errorf("at least one target is required")
+	}
+	if a.Rate <= 0 || a.Duration <= 0 {
+		return Metrics{}, fmt.Errorf("rate and duration must be positive")
+	}
+	if a.Workers <= 0 {
+		a.Workers = 16
+	}
+	client := a.Client
+	if client == nil {
+		timeout := a.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, a.Duration)
+	defer cancel()
+
+	jobs := make(chan Target)
+	results := make(chan sample, a.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < a.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range jobs {
+				results <- hit(client, target)
+			}
+		}()
+	}
+
+	var samples []sample
+	collected := make(chan struct{})
+	go func() {
+		for s := range results {
+			samples = append(samples, s)
+		}
+		close(collected)
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(a.Rate))
+	defer ticker.Stop()
+	sent := 0
+dispatch:
+	for {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case <-ticker.C:
+			select {
+			case jobs <- targets[sent%len(targets)]:
+				sent++
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(results)
+	<-collected
+
+	return summarize(samples, time.Since(start)), nil
+}
+
+func hit(client *http.Client, target Target) sample {
+	method := target.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequest(method, target.URL, bytes.NewReader(target.Body))
+	if err != nil {
+		return sample{err: err}
+	}
+	for k, values := range target.Header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return sample{latency: time.Since(start), err: err}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return sample{latency: time.Since(start), status: resp.StatusCode}
+}
+
+func summarize(samples []sample, elapsed time.Duration) Metrics {
+	m := Metrics{Requests: len(samples), StatusCode: make(map[int]int), Elapsed: elapsed}
+	if len(samples) == 0 {
+		return m
+	}
+
+	latencies := make([]time.Duration, 0, len(samples))
+	var total time.Duration
+	for _, s := range samples {
+		if s.err != nil || s.status < 200 || s.status >= 300 {
+			m.Errors++
+		}
+		if s.status != 0 {
+			m.StatusCode[s.status]++
+		}
+		latencies = append(latencies, s.latency)
+		total += s.latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	m.Mean = total / time.Duration(len(latencies))
+	m.P50 = percentile(latencies, 0.50)
+	m.P95 = percentile(latencies, 0.95)
+	m.P99 = percentile(latencies, 0.99)
+	m.Max = latencies[len(latencies)-1]
+	return m
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
@@ -0,0 +1,54 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunCollectsMetrics(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1)%10 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	m, err := Run(context.Background(), Attack{Rate: 200, Duration: 250 * time.Millisecond, Workers: 4}, []Target{
+		{Name: "health", URL: srv.URL + "/health"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Requests < 10 {
+		t.Fatalf("expected requests to be issued, got %d", m.Requests)
+	}
+	if m.Errors == 0 || m.StatusCode[http.StatusServiceUnavailable] != m.Errors {
+		t.Fatalf("expected 503s to be counted as errors: %s", m)
+	}
+	if m.P50 > m.P95 || m.P95 > m.P99 || m.P99 > m.Max {
+		t.Fatalf("percentiles out of order: %s", m)
+	}
+}
+
+func TestMetricsCheck(t *testing.T) {
+	m := Metrics{Requests: 100, Errors: 2, P95: 40 * time.Millisecond, P99: 90 * time.Millisecond}
+	if err := m.Check(Budget{MaxP95: 50 * time.Millisecond, MaxP99: 100 * time.Millisecond, MaxErrorRate: 0.05}); err != nil {
+		t.Fatalf("expected budget to pass, got %v", err)
+	}
+	if err := m.Check(Budget{MaxP95: 30 * time.Millisecond, MaxErrorRate: 0.01}); err == nil {
+		t.Fatal("expected budget violation")
+	}
+}
+
+func TestRunRequiresTargets(t *testing.T) {
+	if _, err := Run(context.Background(), Attack{Rate: 1, Duration: time.Millisecond}, nil); err == nil {
+		t.Fatal("expected error without targets")
+	}
+}
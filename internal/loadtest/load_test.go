@@ -0,0 +1,154 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Live load suites. They only run when pointed at a running deployment:
+//
+//	LOAD_TEST_API_URL=http://localhost:8080 LOAD_TEST_API_KEY=... \
+//	LOAD_TEST_MCP_URL=http://127.0.0.1:8090/mcp LOAD_TEST_MCP_TOKEN=... \
+//	go test ./internal/loadtest -run Load -v
+//
+// LOAD_TEST_RATE (req/s, default 50) and LOAD_TEST_DURATION (default 30s)
+// tune the attack.
+
+// Budgets for a single API/MCP instance backed by warm Redis and Postgres.
+var (
+	apiBudget = Budget{MaxP95: 150 * time.Millisecond, MaxP99: 400 * time.Millisecond, MaxErrorRate: 0.01}
+	mcpBudget = Budget{MaxP95: 250 * time.Millisecond, MaxP99: 600 * time.Millisecond, MaxErrorRate: 0.01}
+)
+
+func TestAPILoad(t *testing.T) {
+	base := strings.TrimRight(os.Getenv("LOAD_TEST_API_URL"), "/")
+	if base == "" {
+		t.Skip("LOAD_TEST_API_URL not set")
+	}
+	header := http.Header{}
+	if key := os.Getenv("LOAD_TEST_API_KEY"); key != "" {
+		header.Set("X-API-Key", key)
+	}
+
+	targets := []Target{
+		{Name: "health", URL: base + "/health"},
+		{Name: "prices", URL: base + "/api/prices", Header: header},
+		{Name: "price-btc", URL: base + "/api/prices/BTC", Header: header},
+		{Name: "signals", URL: base + "/api/signals?limit=50", Header: header},
+		{Name: "candles", URL: base + "/api/candles/BTC?interval=1h&limit=100", Header: header},
+	}
+	runAndCheck(t, targets, apiBudget)
+}
+
+func TestMCPHTTPLoad(t *testing.T) {
+	endpoint := os.Getenv("LOAD_TEST_MCP_URL")
+	if endpoint == "" {
+		t.Skip("LOAD_TEST_MCP_URL not set")
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("Accept", "application/json, text/event-stream")
+	if token := os.Getenv("LOAD_TEST_MCP_TOKEN"); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	sessionID := initializeMCPSession(t, endpoint, header)
+	header.Set("Mcp-Session-Id", sessionID)
+
+	targets := []Target{
+		{Name: "tools/list", Method: http.MethodPost, URL: endpoint, Header: header, Body: rpcBody(t, 2, "tools/list", map[string]any{})},
+		{Name: "prices_list_latest", Method: http.MethodPost, URL: endpoint, Header: header, Body: rpcBody(t, 3, "tools/call", map[string]any{
+			"name": "prices_list_latest", "arguments": map[string]any{},
+		})},
+		{Name: "signals_list", Method: http.MethodPost, URL: endpoint, Header: header, Body: rpcBody(t, 4, "tools/call", map[string]any{
+			"name": "signals_list", "arguments": map[string]any{"limit": 50},
+		})},
+	}
+	runAndCheck(t, targets, mcpBudget)
+}
+
+func runAndCheck(t *testing.T, targets []Target, budget Budget) {
+	t.Helper()
+	attack := Attack{
+		Rate:     envInt("LOAD_TEST_RATE", 50),
+		Duration: envDuration("LOAD_TEST_DURATION", 30*time.Second),
+		Workers:  32,
+	}
+	metrics, err := Run(context.Background(), attack, targets)
+	if err != nil {
+		t.Fatalf("load run failed: %v", err)
+	}
+	t.Log(metrics.String())
+	if err := metrics.Check(budget); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func initializeMCPSession(t *testing.T, endpoint string, header http.Header) string {
+	t.Helper()
+	body := rpcBody(t, 1, "initialize", map[string]any{
+		"protocolVersion": "2025-06-18",
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "loadtest", "version": "1.0.0"},
+	})
+	resp := postMCP(t, endpoint, header, body)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if sessionID == "" {
+		t.Fatalf("initialize did not return a session id (status %d)", resp.StatusCode)
+	}
+
+	notify, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"})
+	header = header.Clone()
+	header.Set("Mcp-Session-Id", sessionID)
+	postMCP(t, endpoint, header, notify)
+	return sessionID
+}
+
+func postMCP(t *testing.T, endpoint string, header http.Header, body []byte) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("build request: %v", err)
+	}
+	req.Header = header.Clone()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("mcp request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		t.Fatalf("mcp request returned %d", resp.StatusCode)
+	}
+	return resp
+}
+
+func rpcBody(t *testing.T, id int, method string, params any) []byte {
+	t.Helper()
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		t.Fatalf("marshal rpc body: %v", err)
+	}
+	return body
+}
+
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv(key))); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key))); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,161 @@
+package marketintel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+const (
+	calendarSourceBuiltin = "builtin"
+	calendarSourceFile    = "file"
+)
+
+// Release times on the published schedules, US Eastern: FOMC statements at
+// 14:00 and CPI prints at 08:30.
+var (
+	fomcDecisionDates = []string{
+		"2026-01-28", "2026-03-18", "2026-04-29", "2026-06-17",
+		"2026-07-29", "2026-09-16", "2026-10-28", "2026-12-09",
+	}
+	cpiReleaseDates = []string{
+		"2026-01-13", "2026-02-11", "2026-03-11", "2026-04-10", "2026-05-12", "2026-06-10",
+		"2026-07-14", "2026-08-12", "2026-09-11", "2026-10-14", "2026-11-10", "2026-12-10",
+	}
+)
+
+// Calendar is the economic and token event calendar: the built-in FOMC and
+// CPI schedule and the events in an operator's JSON file, which is where
+// token unlocks and later years' macro dates go. The file is re-read on every
+// FetchEvents so edits apply without a restart; EventsBetween serves the
+// last successful read from memory.
+type Calendar struct {
+	path    string
+	builtin bool
+
+	mu     sync.RWMutex
+	events []domain.MarketEvent
+}
+
+// NewCalendar returns a calendar of the built-in schedule, when builtin is
+// set, and the events in the file at path, when path is not empty. Call
+// Load or FetchEvents before EventsBetween.
+func NewCalendar(path string, builtin bool) *Calendar {
+	return &Calendar{path: strings.TrimSpace(path), builtin: builtin}
+}
+
+// Load reads the calendar. When the file cannot be read or holds an invalid
+// event, the events from the last good read are kept and the error returned.
+func (c *Calendar) Load() error {
+	var events []domain.MarketEvent
+	if c.builtin {
+		events = builtinEvents()
+	}
+	if c.path != "" {
+		fileEvents, err := readEventFile(c.path)
+		if err != nil {
+			return fmt.Errorf("market events file %s: %w", c.path, err)
+		}
+		events = append(events, fileEvents...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].StartsAt.Before(events[j].StartsAt) })
+
+	c.mu.Lock()
+	c.events = events
+	c.mu.Unlock()
+	return nil
+}
+
+// FetchEvents reloads the calendar and returns every event on it. On a
+// failed reload the previous events are returned with the error.
+func (c *Calendar) FetchEvents(ctx context.Context) ([]domain.MarketEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	err := c.Load()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]domain.MarketEvent(nil), c.events...), err
+}
+
+// EventsBetween returns the loaded events starting in [from, to], oldest
+// first.
+func (c *Calendar) EventsBetween(from, to time.Time) []domain.MarketEvent {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var out []domain.MarketEvent
+	for _, e := range c.events {
+		if !e.StartsAt.Before(from) && !e.StartsAt.After(to) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func builtinEvents() []domain.MarketEvent {
+	// The domain package embeds the zone database, so this always loads.
+	eastern, err := domain.LoadTimezone("America/New_York")
+	if err != nil {
+		return nil
+	}
+	var events []domain.MarketEvent
+	add := func(dates []string, hour, minute int, kind, title string) {
+		for _, d := range dates {
+			day, err := time.ParseInLocation("2006-01-02", d, eastern)
+			if err != nil {
+				continue
+			}
+			events = append(events, domain.MarketEvent{
+				Kind:       kind,
+				StartsAt:   day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute).UTC(),
+				Title:      title,
+				Importance: domain.EventImportanceHigh,
+				Source:     calendarSourceBuiltin,
+			})
+		}
+	}
+	add(fomcDecisionDates, 14, 0, domain.MarketEventFOMC, "FOMC rate decision")
+	add(cpiReleaseDates, 8, 30, domain.MarketEventCPI, "US CPI release")
+	return events
+}
+
+// readEventFile parses a JSON array of events. Kind defaults to other and
+// importance to medium; symbols are upper-cased.
+func readEventFile(path string) ([]domain.MarketEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var events []domain.MarketEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	for i := range events {
+		e := &events[i]
+		e.Kind = strings.ToLower(strings.TrimSpace(e.Kind))
+		if e.Kind == "" {
+			e.Kind = domain.MarketEventOther
+		}
+		e.Symbol = strings.ToUpper(strings.TrimSpace(e.Symbol))
+		e.Title = strings.TrimSpace(e.Title)
+		e.StartsAt = e.StartsAt.UTC()
+		if e.Importance == 0 {
+			e.Importance = domain.EventImportanceMedium
+		}
+		e.Source = calendarSourceFile
+		if err := domain.ValidateMarketEvent(*e); err != nil {
+			return nil, fmt.Errorf("event %d: %w", i+1, err)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,67 @@
+package marketintel
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestCalendarMergesBuiltinScheduleAndFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	if err := os.WriteFile(path, []byte(`[
+		{"kind":"unlock","symbol":"sol","starts_at":"2026-03-18T00:00:00Z","title":"SOL validator unlock"},
+		{"title":"ETH upgrade","starts_at":"2026-03-17T12:00:00Z","importance":3}
+	]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cal := NewCalendar(path, true)
+	events, err := cal.FetchEvents(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != len(fomcDecisionDates)+len(cpiReleaseDates)+2 {
+		t.Fatalf("expected the built-in schedule and both file events, got %d", len(events))
+	}
+
+	got := cal.EventsBetween(time.Date(2026, 3, 17, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 19, 0, 0, 0, 0, time.UTC))
+	if len(got) != 3 {
+		t.Fatalf("expected three events around the March FOMC, got %+v", got)
+	}
+	if got[0].Kind != domain.MarketEventOther || got[0].Source != calendarSourceFile {
+		t.Fatalf("expected the upgrade first with defaults applied, got %+v", got[0])
+	}
+	if got[1].Symbol != "SOL" || got[1].Importance != domain.EventImportanceMedium {
+		t.Fatalf("expected the unlock normalised, got %+v", got[1])
+	}
+	// 14:00 New York is 18:00 UTC during daylight saving time.
+	if got[2].Kind != domain.MarketEventFOMC || !got[2].StartsAt.Equal(time.Date(2026, 3, 18, 18, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the FOMC decision at 18:00 UTC, got %+v", got[2])
+	}
+}
+
+func TestCalendarKeepsLastGoodReadOnBadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	if err := os.WriteFile(path, []byte(`[{"kind":"unlock","starts_at":"2026-05-01T00:00:00Z","title":"SUI unlock","symbol":"SUI"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cal := NewCalendar(path, false)
+	if err := cal.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"kind":"unlock","starts_at":"2026-05-01T00:00:00Z","title":"no symbol"}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	events, err := cal.FetchEvents(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "event 1: unlock events need a symbol") {
+		t.Fatalf("expected the invalid event reported, got %v", err)
+	}
+	if len(events) != 1 || events[0].Symbol != "SUI" {
+		t.Fatalf("expected the previous events kept, got %+v", events)
+	}
+}
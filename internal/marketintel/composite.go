@@ -6,7 +6,7 @@ import (
 	"sort"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const modelKeyFundSentV1 = "fund_sent_v1"
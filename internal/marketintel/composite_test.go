@@ -3,7 +3,7 @@ package marketintel
 import (
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestBuildCompositeDirectionAndRisk(t *testing.T) {
@@ -3,11 +3,12 @@ package marketintel
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sort"
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -409,6 +410,133 @@ func (r *Repository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int
 	return total, nil
 }
 
+// UpsertFearGreed stores daily index values, replacing any already stored
+// for the same day.
+func (r *Repository) UpsertFearGreed(ctx context.Context, readings []domain.FearGreedReading) error {
+	if len(readings) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "market-intel-repo.upsert-fear-greed")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, reading := range readings {
+		batch.Queue(`
+INSERT INTO fear_greed_index (observed_at, value, classification, fetched_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (observed_at) DO UPDATE SET
+	value = EXCLUDED.value,
+	classification = EXCLUDED.classification,
+	fetched_at = EXCLUDED.fetched_at`, reading.Timestamp.UTC(), reading.Value, strings.TrimSpace(reading.Classification))
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range readings {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LatestFearGreed returns the newest stored index value, or nil when none
+// is stored.
+func (r *Repository) LatestFearGreed(ctx context.Context) (*domain.FearGreedReading, error) {
+	_, span := r.tracer.Start(ctx, "market-intel-repo.latest-fear-greed")
+	defer span.End()
+
+	return scanFearGreed(r.pool.QueryRow(ctx, `
+SELECT observed_at, value, classification
+FROM fear_greed_index
+ORDER BY observed_at DESC
+LIMIT 1`))
+}
+
+// FearGreedAt returns the newest index value published at or before at, or
+// nil when none is stored.
+func (r *Repository) FearGreedAt(ctx context.Context, at time.Time) (*domain.FearGreedReading, error) {
+	_, span := r.tracer.Start(ctx, "market-intel-repo.fear-greed-at")
+	defer span.End()
+
+	return scanFearGreed(r.pool.QueryRow(ctx, `
+SELECT observed_at, value, classification
+FROM fear_greed_index
+WHERE observed_at <= $1
+ORDER BY observed_at DESC
+LIMIT 1`, at.UTC()))
+}
+
+// UpsertMarketEvents stores scheduled events, updating the title,
+// importance and source of any already stored for the same kind, symbol and
+// start.
+func (r *Repository) UpsertMarketEvents(ctx context.Context, events []domain.MarketEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "market-intel-repo.upsert-market-events")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, e := range events {
+		batch.Queue(`
+INSERT INTO market_events (kind, symbol, starts_at, title, importance, source, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+ON CONFLICT (kind, symbol, starts_at) DO UPDATE SET
+	title = EXCLUDED.title,
+	importance = EXCLUDED.importance,
+	source = EXCLUDED.source,
+	updated_at = EXCLUDED.updated_at`, e.Kind, e.Symbol, e.StartsAt.UTC(), e.Title, e.Importance, e.Source)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range events {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListMarketEvents returns the stored events starting in [from, to], oldest
+// first.
+func (r *Repository) ListMarketEvents(ctx context.Context, from, to time.Time) ([]domain.MarketEvent, error) {
+	_, span := r.tracer.Start(ctx, "market-intel-repo.list-market-events")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx, `
+SELECT kind, symbol, starts_at, title, importance, source
+FROM market_events
+WHERE starts_at >= $1 AND starts_at <= $2
+ORDER BY starts_at ASC, kind ASC, symbol ASC`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MarketEvent
+	for rows.Next() {
+		var e domain.MarketEvent
+		if err := rows.Scan(&e.Kind, &e.Symbol, &e.StartsAt, &e.Title, &e.Importance, &e.Source); err != nil {
+			return nil, err
+		}
+		e.StartsAt = e.StartsAt.UTC()
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func scanFearGreed(row pgx.Row) (*domain.FearGreedReading, error) {
+	var out domain.FearGreedReading
+	if err := row.Scan(&out.Timestamp, &out.Value, &out.Classification); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out.Timestamp = out.Timestamp.UTC()
+	return &out, nil
+}
+
 func scanMarketIntelItemRow(s interface{ Scan(dest ...any) error }) (domain.MarketIntelItem, error) {
 	var out domain.MarketIntelItem
 	var score pgtype.Float8
@@ -480,7 +608,7 @@ func normalizeSymbol(symbol string) string {
 	if symbol == "" {
 		return ""
 	}
-	if _, ok := domain.CoinGeckoID[symbol]; !ok {
+	if !domain.IsSupportedSymbol(symbol) {
 		return ""
 	}
 	return symbol
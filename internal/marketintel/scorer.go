@@ -7,7 +7,8 @@ import (
 	"sort"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -168,7 +169,7 @@ func NewOpenAIScorer(apiKey string, model string) *OpenAIScorer {
 	if strings.TrimSpace(model) == "" {
 		model = "gpt-4o-mini"
 	}
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	client := openai.NewClient(option.WithAPIKey(apiKey), option.WithHTTPClient(httpclient.New(httpclient.OpenAI, 0)))
 	return &OpenAIScorer{
 		client: &openAIClient{client: client},
 		model:  model,
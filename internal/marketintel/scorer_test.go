@@ -5,7 +5,7 @@ import (
 	"errors"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestScorerHeuristicFallback(t *testing.T) {
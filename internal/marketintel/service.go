@@ -7,8 +7,8 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/provider"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -17,6 +17,29 @@ type FearGreedReader interface {
 	FetchLatest(ctx context.Context) (*provider.FearGreedPoint, error)
 }
 
+// FearGreedHistoryReader is implemented by Fear & Greed readers that also
+// serve past daily values, newest first. The history store backfills from it.
+type FearGreedHistoryReader interface {
+	FetchHistory(ctx context.Context, limit int) ([]provider.FearGreedPoint, error)
+}
+
+// FearGreedHistoryStore keeps every daily Fear & Greed value.
+type FearGreedHistoryStore interface {
+	UpsertFearGreed(ctx context.Context, readings []domain.FearGreedReading) error
+	LatestFearGreed(ctx context.Context) (*domain.FearGreedReading, error)
+}
+
+// EventCalendarReader lists the scheduled market events on a calendar.
+type EventCalendarReader interface {
+	FetchEvents(ctx context.Context) ([]domain.MarketEvent, error)
+}
+
+// MarketEventStore keeps scheduled market events, the history the event
+// proximity feature is built from.
+type MarketEventStore interface {
+	UpsertMarketEvents(ctx context.Context, events []domain.MarketEvent) error
+}
+
 type RedditReader interface {
 	FetchHot(ctx context.Context, subreddit string, limit int) ([]provider.ContentItem, error)
 }
@@ -33,6 +56,12 @@ type SignalStore interface {
 	InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error)
 }
 
+// SignalPublisher receives each stored sentiment signal for export to a
+// message broker.
+type SignalPublisher interface {
+	PublishSignals(ctx context.Context, signals []domain.Signal)
+}
+
 type Store interface {
 	UpsertItems(ctx context.Context, items []domain.MarketIntelItem) ([]domain.MarketIntelItem, error)
 	UpsertItemSymbols(ctx context.Context, itemID int64, symbols []string) error
@@ -71,6 +100,11 @@ type Service struct {
 	reddit    RedditReader
 	rss       RSSReader
 	onchain   map[string]OnChainReader
+	publisher SignalPublisher
+
+	fearGreedHistory FearGreedHistoryStore
+	calendar         EventCalendarReader
+	eventStore       MarketEventStore
 
 	cfg Config
 }
@@ -137,6 +171,23 @@ func NewService(
 	}
 }
 
+// SetSignalPublisher streams every stored sentiment signal to p.
+func (s *Service) SetSignalPublisher(p SignalPublisher) {
+	s.publisher = p
+}
+
+// SetFearGreedHistory keeps each Fear & Greed value the cycle fetches in
+// store, backfilling the days since the newest stored one.
+func (s *Service) SetFearGreedHistory(store FearGreedHistoryStore) {
+	s.fearGreedHistory = store
+}
+
+// SetEventCalendar stores the events on calendar in store every cycle.
+func (s *Service) SetEventCalendar(calendar EventCalendarReader, store MarketEventStore) {
+	s.calendar = calendar
+	s.eventStore = store
+}
+
 func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketIntelRunResult, error) {
 	_, span := s.tracer.Start(ctx, "market-intel.run-cycle")
 	defer span.End()
@@ -157,6 +208,9 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 		} else if fg != nil {
 			v := fg.Value
 			fearGreedValue = &v
+			if err := s.storeFearGreed(ctx, *fg); err != nil {
+				result.Errors = append(result.Errors, "fear_greed_history: "+err.Error())
+			}
 			score := clamp((float64(fg.Value)-50.0)/50.0, -1, 1)
 			confidence := clamp(0.4+(0.6*absFloat(score)), 0, 1)
 			label := "neutral"
@@ -193,7 +247,7 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 				ScoredAt:            &now,
 			}
 			items = append(items, item)
-			symbolSets = append(symbolSets, append([]string(nil), domain.SupportedSymbols...))
+			symbolSets = append(symbolSets, domain.SupportedSymbols())
 		}
 	}
 
@@ -227,6 +281,20 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 		}
 	}
 
+	if s.calendar != nil && s.eventStore != nil {
+		events, err := s.calendar.FetchEvents(ctx)
+		if err != nil {
+			result.Errors = append(result.Errors, "calendar: "+err.Error())
+		}
+		if len(events) > 0 {
+			if err := s.eventStore.UpsertMarketEvents(ctx, events); err != nil {
+				result.Errors = append(result.Errors, "calendar_store: "+err.Error())
+			} else {
+				result.EventsIngested = len(events)
+			}
+		}
+	}
+
 	persisted, err := s.repo.UpsertItems(ctx, items)
 	if err != nil {
 		return result, err
@@ -300,7 +368,7 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 		lookbackHours := s.lookbackHours(interval)
 		from := bucket.Add(-time.Duration(lookbackHours) * time.Hour)
 
-		for _, symbol := range domain.SupportedSymbols {
+		for _, symbol := range domain.SupportedSymbols() {
 			stats, err := s.repo.GetSentimentAverages(ctx, symbol, from, bucket)
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("aggregate:%s:%s: %v", symbol, interval, err))
@@ -354,7 +422,7 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 			}
 			result.CompositesWritten++
 
-			if computed.Direction == domain.DirectionHold || s.signals == nil {
+			if computed.Direction == domain.DirectionHold || s.signals == nil || domain.SignalsPaused(symbol) {
 				continue
 			}
 			persisted, err := s.signals.InsertSignals(ctx, []domain.Signal{{
@@ -365,6 +433,7 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 				Risk:      computed.Risk,
 				Direction: computed.Direction,
 				Details:   computed.DetailsText,
+				Version:   modelKeyFundSentV1,
 			}})
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("signal_store:%s:%s: %v", symbol, interval, err))
@@ -375,6 +444,9 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 					result.Errors = append(result.Errors, fmt.Sprintf("signal_attach:%s:%s:%d: %v", symbol, interval, persisted[0].ID, err))
 				}
 			}
+			if s.publisher != nil {
+				s.publisher.PublishSignals(ctx, persisted)
+			}
 			result.SignalsWritten++
 			_ = stored
 		}
@@ -390,6 +462,49 @@ func (s *Service) RunCycle(ctx context.Context, now time.Time) (domain.MarketInt
 	return result, nil
 }
 
+// storeFearGreed saves latest to the history store. When the newest stored
+// value is more than a day older, or none is stored, the missing days are
+// fetched too, up to provider.FearGreedMaxHistory of them.
+func (s *Service) storeFearGreed(ctx context.Context, latest provider.FearGreedPoint) error {
+	if s.fearGreedHistory == nil {
+		return nil
+	}
+	points := []provider.FearGreedPoint{latest}
+	var backfillErr error
+	if history, ok := s.fearGreed.(FearGreedHistoryReader); ok {
+		stored, err := s.fearGreedHistory.LatestFearGreed(ctx)
+		if err != nil {
+			return err
+		}
+		missing := provider.FearGreedMaxHistory
+		if stored != nil {
+			missing = int(latest.Timestamp.Sub(stored.Timestamp) / (24 * time.Hour))
+		}
+		if missing > 1 {
+			if backfill, err := history.FetchHistory(ctx, min(missing+1, provider.FearGreedMaxHistory)); err != nil {
+				backfillErr = fmt.Errorf("backfill: %w", err)
+			} else {
+				points = append(points, backfill...)
+			}
+		}
+	}
+
+	readings := make([]domain.FearGreedReading, 0, len(points))
+	seen := make(map[int64]bool, len(points))
+	for _, p := range points {
+		ts := p.Timestamp.UTC()
+		if seen[ts.Unix()] {
+			continue
+		}
+		seen[ts.Unix()] = true
+		readings = append(readings, domain.FearGreedReading{Timestamp: ts, Value: p.Value, Classification: p.Classification})
+	}
+	if err := s.fearGreedHistory.UpsertFearGreed(ctx, readings); err != nil {
+		return err
+	}
+	return backfillErr
+}
+
 func providerContentToItem(now time.Time, row provider.ContentItem) (domain.MarketIntelItem, []string) {
 	meta, _ := json.Marshal(row.Metadata)
 	symbols := ExtractSymbolsFromContent(row.Source, row.Title, row.Excerpt, row.Metadata)
@@ -423,13 +538,8 @@ func ptrIfAvailable(component CompositeComponent) *float64 {
 
 func closedBucket(now time.Time, interval string) time.Time {
 	now = now.UTC()
-	d := time.Hour
-	switch interval {
-	case "4h":
-		d = 4 * time.Hour
-	case "1h":
-		d = time.Hour
-	default:
+	d := domain.IntervalDuration(interval)
+	if d == 0 {
 		d = time.Hour
 	}
 	return now.Truncate(d).Add(-d)
@@ -5,8 +5,8 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/provider"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/trace"
@@ -24,6 +24,7 @@ func TestServiceRunCycleWritesDirectionalSignalsOnly(t *testing.T) {
 		},
 	}
 	signals := &signalStoreStub{}
+	publisher := &signalPublisherStub{}
 	svc := NewService(
 		trace.NewNoopTracerProvider().Tracer("test"),
 		store,
@@ -39,12 +40,13 @@ func TestServiceRunCycleWritesDirectionalSignalsOnly(t *testing.T) {
 			ShortThreshold: -0.20,
 		},
 	)
+	svc.SetSignalPublisher(publisher)
 
 	res, err := svc.RunCycle(context.Background(), now)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if res.CompositesWritten != len(domain.SupportedSymbols) {
+	if res.CompositesWritten != len(domain.SupportedSymbols()) {
 		t.Fatalf("expected one composite per symbol, got %d", res.CompositesWritten)
 	}
 	if res.SignalsWritten != 1 {
@@ -56,6 +58,20 @@ func TestServiceRunCycleWritesDirectionalSignalsOnly(t *testing.T) {
 	if signals.inserted[0].Indicator != domain.IndicatorFundSentimentComposite {
 		t.Fatalf("unexpected indicator %s", signals.inserted[0].Indicator)
 	}
+	if signals.inserted[0].Version != modelKeyFundSentV1 {
+		t.Fatalf("unexpected version %q", signals.inserted[0].Version)
+	}
+	if len(publisher.signals) != 1 || publisher.signals[0].Symbol != "BTC" {
+		t.Fatalf("expected the stored signal published, got %+v", publisher.signals)
+	}
+}
+
+type signalPublisherStub struct {
+	signals []domain.Signal
+}
+
+func (s *signalPublisherStub) PublishSignals(_ context.Context, signals []domain.Signal) {
+	s.signals = append(s.signals, signals...)
 }
 
 func TestServiceRunCycleDoesNotFailOnOnChainErrors(t *testing.T) {
@@ -167,3 +183,122 @@ var _ Store = (*marketStoreStub)(nil)
 var _ SignalStore = (*signalStoreStub)(nil)
 var _ OnChainReader = (onchainReaderStub{})
 var _ = pgx.ErrNoRows
+
+func TestServiceRunCycleBackfillsFearGreedHistory(t *testing.T) {
+	now := time.Date(2026, 2, 13, 19, 30, 0, 0, time.UTC)
+	today := time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC)
+	reader := &fearGreedReaderStub{
+		latest: provider.FearGreedPoint{Value: 63, Classification: "Greed", Timestamp: today},
+		history: []provider.FearGreedPoint{
+			{Value: 63, Classification: "Greed", Timestamp: today},
+			{Value: 55, Classification: "Greed", Timestamp: today.AddDate(0, 0, -1)},
+			{Value: 40, Classification: "Fear", Timestamp: today.AddDate(0, 0, -2)},
+		},
+	}
+	history := &fearGreedHistoryStub{latest: &domain.FearGreedReading{Value: 30, Timestamp: today.AddDate(0, 0, -3)}}
+	svc := NewService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&marketStoreStub{},
+		NewScorer(nil, 8),
+		nil,
+		reader,
+		nil,
+		nil,
+		nil,
+		Config{Intervals: []string{"1h"}},
+	)
+	svc.SetFearGreedHistory(history)
+
+	res, err := svc.RunCycle(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", res.Errors)
+	}
+	if reader.historyLimit != 4 {
+		t.Fatalf("expected the 3 missing days plus today requested, got limit %d", reader.historyLimit)
+	}
+	if len(history.upserted) != 3 {
+		t.Fatalf("expected 3 distinct days stored, got %+v", history.upserted)
+	}
+	if history.upserted[0].Value != 63 || !history.upserted[0].Timestamp.Equal(today) {
+		t.Fatalf("expected the latest value stored first, got %+v", history.upserted[0])
+	}
+
+	// Once caught up, a cycle stores only the latest value.
+	history.latest = &domain.FearGreedReading{Value: 63, Timestamp: today}
+	history.upserted = nil
+	reader.historyLimit = 0
+	if _, err := svc.RunCycle(context.Background(), now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reader.historyLimit != 0 || len(history.upserted) != 1 {
+		t.Fatalf("expected no backfill, got limit %d and %+v", reader.historyLimit, history.upserted)
+	}
+}
+
+type fearGreedReaderStub struct {
+	latest       provider.FearGreedPoint
+	history      []provider.FearGreedPoint
+	historyLimit int
+}
+
+func (s *fearGreedReaderStub) FetchLatest(context.Context) (*provider.FearGreedPoint, error) {
+	latest := s.latest
+	return &latest, nil
+}
+
+func (s *fearGreedReaderStub) FetchHistory(_ context.Context, limit int) ([]provider.FearGreedPoint, error) {
+	s.historyLimit = limit
+	return s.history, nil
+}
+
+type fearGreedHistoryStub struct {
+	latest   *domain.FearGreedReading
+	upserted []domain.FearGreedReading
+}
+
+func (s *fearGreedHistoryStub) UpsertFearGreed(_ context.Context, readings []domain.FearGreedReading) error {
+	s.upserted = append(s.upserted, readings...)
+	return nil
+}
+
+func (s *fearGreedHistoryStub) LatestFearGreed(context.Context) (*domain.FearGreedReading, error) {
+	return s.latest, nil
+}
+
+type eventCalendarStub struct {
+	events []domain.MarketEvent
+}
+
+func (s eventCalendarStub) FetchEvents(context.Context) ([]domain.MarketEvent, error) {
+	return s.events, nil
+}
+
+type marketEventStoreStub struct {
+	stored []domain.MarketEvent
+}
+
+func (s *marketEventStoreStub) UpsertMarketEvents(_ context.Context, events []domain.MarketEvent) error {
+	s.stored = append(s.stored, events...)
+	return nil
+}
+
+func TestServiceRunCycleStoresCalendarEvents(t *testing.T) {
+	now := time.Date(2026, 3, 16, 10, 0, 0, 0, time.UTC)
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("test"), &marketStoreStub{}, NewScorer(nil, 8), nil, nil, nil, nil, nil,
+		Config{Intervals: []string{"1h"}})
+	events := &marketEventStoreStub{}
+	svc.SetEventCalendar(eventCalendarStub{events: []domain.MarketEvent{
+		{Kind: domain.MarketEventFOMC, StartsAt: now.Add(56 * time.Hour), Title: "FOMC rate decision", Importance: domain.EventImportanceHigh},
+	}}, events)
+
+	res, err := svc.RunCycle(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.EventsIngested != 1 || len(events.stored) != 1 {
+		t.Fatalf("expected the calendar event stored, got %d ingested %+v", res.EventsIngested, events.stored)
+	}
+}
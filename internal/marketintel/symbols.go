@@ -5,7 +5,7 @@ import (
 	"sort"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 var symbolTokenRx = regexp.MustCompile(`\$?[A-Za-z]{2,10}`)
@@ -35,7 +35,7 @@ var subredditSymbolHint = map[string]string{
 func ExtractSymbolsFromContent(source, title, excerpt string, metadata map[string]any) []string {
 	source = strings.TrimSpace(strings.ToLower(source))
 	if source == "fear_greed" {
-		return append([]string(nil), domain.SupportedSymbols...)
+		return domain.SupportedSymbols()
 	}
 
 	text := strings.ToLower(strings.Join([]string{title, excerpt}, " "))
@@ -43,7 +43,7 @@ func ExtractSymbolsFromContent(source, title, excerpt string, metadata map[strin
 
 	for _, raw := range symbolTokenRx.FindAllString(text, -1) {
 		token := strings.TrimSpace(strings.TrimPrefix(strings.ToUpper(raw), "$"))
-		if _, ok := domain.CoinGeckoID[token]; ok {
+		if domain.IsSupportedSymbol(token) {
 			matched[token] = struct{}{}
 		}
 	}
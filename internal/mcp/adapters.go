@@ -3,7 +3,8 @@ package mcp
 import (
 	"context"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
 )
 
 // PriceReader exposes read operations for market data.
@@ -18,3 +19,25 @@ type SignalReaderWriter interface {
 	ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error)
 	GenerateForSymbol(ctx context.Context, symbol string, intervals []string) ([]domain.Signal, error)
 }
+
+// SignalImageReader exposes rendered chart images for signals. Signal services
+// that do not store images simply omit it.
+type SignalImageReader interface {
+	GetSignalImage(ctx context.Context, signalID int64) (*domain.SignalImageData, error)
+}
+
+// MLInferenceRunner refreshes ML features and runs inference on demand.
+type MLInferenceRunner interface {
+	RefreshAndInfer(ctx context.Context, symbol string, limit int) (service.MLRefreshResult, error)
+}
+
+// SignalRiskMapper reports the risk the signal engine assigns to an
+// indicator on an interval, including deployment overrides.
+type SignalRiskMapper interface {
+	RiskFor(indicator, interval string) domain.RiskLevel
+}
+
+// MLSimilarSetupFinder finds past market states similar to the current one.
+type MLSimilarSetupFinder interface {
+	FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error)
+}
@@ -2,17 +2,21 @@ package mcp
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const defaultMCPMaxBodyBytes int64 = 1 << 20 // 1MiB
 
 type HTTPHandlerConfig struct {
 	AuthToken       string
+	AdminToken      string
 	RateLimitPerMin int
 	MaxBodyBytes    int64
 }
@@ -20,26 +24,39 @@ type HTTPHandlerConfig struct {
 func wrapHTTPHandler(base http.Handler, cfg HTTPHandlerConfig) http.Handler {
 	h := withBodyLimit(base, cfg.MaxBodyBytes)
 	h = withRateLimit(h, newHTTPRateLimiter(cfg.RateLimitPerMin))
-	h = withBearerAuth(h, cfg.AuthToken)
+	h = withBearerAuth(h, cfg.AuthToken, cfg.AdminToken)
 	return h
 }
 
-func withBearerAuth(next http.Handler, token string) http.Handler {
+func withBearerAuth(next http.Handler, token, adminToken string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authz := strings.TrimSpace(r.Header.Get("Authorization"))
 		if !strings.HasPrefix(authz, "Bearer ") {
-			writeJSONError(w, http.StatusUnauthorized, "missing bearer token")
+			writeJSONError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
 			return
 		}
 		provided := strings.TrimSpace(strings.TrimPrefix(authz, "Bearer "))
-		if token == "" || provided == "" || provided != token {
-			writeJSONError(w, http.StatusForbidden, "invalid bearer token")
+		if provided == "" || (provided != token && provided != adminToken) {
+			writeJSONError(w, http.StatusForbidden, "forbidden", "invalid bearer token")
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
 
+// requireAdmin allows admin-scoped tools over stdio (a local, trusted
+// transport) and over HTTP only when the caller presented the admin token.
+func requireAdmin(req *sdkmcp.CallToolRequest, adminToken string) error {
+	if req == nil || req.Extra == nil || req.Extra.Header == nil {
+		return nil
+	}
+	provided := strings.TrimSpace(strings.TrimPrefix(req.Extra.Header.Get("Authorization"), "Bearer "))
+	if adminToken == "" || provided != adminToken {
+		return fmt.Errorf("admin token required")
+	}
+	return nil
+}
+
 func withBodyLimit(next http.Handler, limit int64) http.Handler {
 	if limit <= 0 {
 		limit = defaultMCPMaxBodyBytes
@@ -60,7 +77,7 @@ func withRateLimit(next http.Handler, limiter *httpRateLimiter) http.Handler {
 			return
 		}
 		if !limiter.Allow(rateLimitKey(r)) {
-			writeJSONError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			writeJSONError(w, http.StatusTooManyRequests, ErrCodeRateLimited, "rate limit exceeded")
 			return
 		}
 		next.ServeHTTP(w, r)
@@ -139,8 +156,8 @@ func (l *httpRateLimiter) Allow(key string) bool {
 	return true
 }
 
-func writeJSONError(w http.ResponseWriter, status int, message string) {
+func writeJSONError(w http.ResponseWriter, status int, code, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message, "code": code})
 }
@@ -71,3 +71,17 @@ func TestRateLimiterMiddleware(t *testing.T) {
 		t.Fatalf("expected second request to be rate-limited, got %d", w2.Code)
 	}
 }
+
+func TestHTTPAuthMiddlewareAcceptsAdminToken(t *testing.T) {
+	h := wrapHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), HTTPHandlerConfig{AuthToken: "secret", AdminToken: "admin", RateLimitPerMin: 60})
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for admin token, got %d", rec.Code)
+	}
+}
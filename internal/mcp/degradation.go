@@ -0,0 +1,227 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Structured error codes returned in tool error results so clients can
+// degrade gracefully instead of parsing free-form messages.
+const (
+	ErrCodeDataUnavailable = "data_unavailable"
+	ErrCodeRateLimited     = "rate_limited"
+	ErrCodeInternal        = "internal_error"
+)
+
+const (
+	DependencyPostgres = "postgres"
+	DependencyRedis    = "redis"
+
+	// capabilitiesKey is the experimental capability carrying per-tool
+	// availability in the initialize response.
+	capabilitiesKey = "bugFreeUmbrella/tools"
+
+	dependencyProbeTTL = 5 * time.Second
+)
+
+// DependencyProbe reports whether a backing dependency is reachable.
+type DependencyProbe func(ctx context.Context) error
+
+type toolRequirements struct {
+	required []string
+	optional []string
+}
+
+// toolMatrix lists the dependencies each tool needs. Required dependencies
+// make the tool unavailable when down; optional ones only degrade it (e.g.
+// prices fall back to the upstream API without the Redis cache).
+var toolMatrix = map[string]toolRequirements{
+	"prices_list_latest":   {optional: []string{DependencyRedis}},
+	"prices_get_by_symbol": {optional: []string{DependencyRedis}},
+	"candles_list":         {required: []string{DependencyPostgres}},
+	"signals_list":         {required: []string{DependencyPostgres}},
+	"signals_generate":     {required: []string{DependencyPostgres}},
+	"ml_refresh_and_infer": {required: []string{DependencyPostgres}},
+	"ml_similar_setups":    {required: []string{DependencyPostgres}},
+}
+
+type toolCapability struct {
+	Available bool     `json:"available"`
+	Degraded  bool     `json:"degraded"`
+	Requires  []string `json:"requires,omitempty"`
+	Missing   []string `json:"missing,omitempty"`
+}
+
+type toolErrorPayload struct {
+	Error toolError `json:"error"`
+}
+
+type toolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type dependencyMonitor struct {
+	probes map[string]DependencyProbe
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	checked map[string]time.Time
+	healthy map[string]bool
+}
+
+func newDependencyMonitor(probes map[string]DependencyProbe) *dependencyMonitor {
+	return &dependencyMonitor{
+		probes:  probes,
+		ttl:     dependencyProbeTTL,
+		now:     time.Now,
+		checked: make(map[string]time.Time),
+		healthy: make(map[string]bool),
+	}
+}
+
+// Healthy reports whether name is reachable. Dependencies without a probe
+// are assumed healthy. Results are cached briefly to keep tool calls cheap.
+func (m *dependencyMonitor) Healthy(ctx context.Context, name string) bool {
+	if m == nil {
+		return true
+	}
+	probe, ok := m.probes[name]
+	if !ok || probe == nil {
+		return true
+	}
+
+	m.mu.Lock()
+	if at, ok := m.checked[name]; ok && m.now().Sub(at) < m.ttl {
+		healthy := m.healthy[name]
+		m.mu.Unlock()
+		return healthy
+	}
+	m.mu.Unlock()
+
+	healthy := probe(ctx) == nil
+
+	m.mu.Lock()
+	m.checked[name] = m.now()
+	m.healthy[name] = healthy
+	m.mu.Unlock()
+	return healthy
+}
+
+func (m *dependencyMonitor) toolCapabilities(ctx context.Context, tools []string) map[string]toolCapability {
+	out := make(map[string]toolCapability, len(tools))
+	for _, name := range tools {
+		req := toolMatrix[name]
+		capability := toolCapability{Available: true, Requires: req.required}
+		for _, dep := range req.required {
+			if !m.Healthy(ctx, dep) {
+				capability.Available = false
+				capability.Missing = append(capability.Missing, dep)
+			}
+		}
+		for _, dep := range req.optional {
+			if !m.Healthy(ctx, dep) {
+				capability.Degraded = true
+				capability.Missing = append(capability.Missing, dep)
+			}
+		}
+		out[name] = capability
+	}
+	return out
+}
+
+func (m *dependencyMonitor) missingRequired(ctx context.Context, tool string) []string {
+	var missing []string
+	for _, dep := range toolMatrix[tool].required {
+		if !m.Healthy(ctx, dep) {
+			missing = append(missing, dep)
+		}
+	}
+	return missing
+}
+
+// degradationMiddleware advertises per-tool availability on initialize,
+// short-circuits tool calls whose required dependencies are down, and tags
+// tool errors with a structured code.
+func degradationMiddleware(monitor *dependencyMonitor, tools []string) sdkmcp.Middleware {
+	return func(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+		return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+			if callReq, ok := req.(*sdkmcp.CallToolRequest); ok && method == "tools/call" {
+				if missing := monitor.missingRequired(ctx, callReq.Params.Name); len(missing) > 0 {
+					return toolErrorResult(ErrCodeDataUnavailable, "dependency unavailable: "+strings.Join(missing, ", ")), nil
+				}
+			}
+
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+
+			switch res := result.(type) {
+			case *sdkmcp.InitializeResult:
+				attachToolCapabilities(res, monitor.toolCapabilities(ctx, tools))
+			case *sdkmcp.CallToolResult:
+				if res.IsError && res.StructuredContent == nil {
+					message := toolResultText(res)
+					tagged := toolErrorResult(classifyToolError(message), message)
+					res.Content = tagged.Content
+					res.StructuredContent = tagged.StructuredContent
+				}
+			}
+			return result, nil
+		}
+	}
+}
+
+func attachToolCapabilities(res *sdkmcp.InitializeResult, tools map[string]toolCapability) {
+	if res.Capabilities == nil {
+		res.Capabilities = &sdkmcp.ServerCapabilities{}
+	}
+	experimental := make(map[string]any, len(res.Capabilities.Experimental)+1)
+	for k, v := range res.Capabilities.Experimental {
+		experimental[k] = v
+	}
+	experimental[capabilitiesKey] = tools
+	res.Capabilities.Experimental = experimental
+}
+
+func toolErrorResult(code, message string) *sdkmcp.CallToolResult {
+	payload := toolErrorPayload{Error: toolError{Code: code, Message: message}}
+	body, _ := json.Marshal(payload)
+	return &sdkmcp.CallToolResult{
+		IsError:           true,
+		Content:           []sdkmcp.Content{&sdkmcp.TextContent{Text: string(body)}},
+		StructuredContent: payload,
+	}
+}
+
+func toolResultText(res *sdkmcp.CallToolResult) string {
+	parts := make([]string, 0, len(res.Content))
+	for _, c := range res.Content {
+		if text, ok := c.(*sdkmcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func classifyToolError(message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "rate limit"), strings.Contains(lower, "error 429"):
+		return ErrCodeRateLimited
+	case strings.Contains(lower, "unavailable"),
+		strings.Contains(lower, "connection refused"),
+		strings.Contains(lower, "not initialized"),
+		strings.Contains(lower, "deadline exceeded"):
+		return ErrCodeDataUnavailable
+	default:
+		return ErrCodeInternal
+	}
+}
@@ -0,0 +1,153 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestInitializeAdvertisesToolAvailability(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	srv := NewServer(nil, &stubPriceService{}, &stubSignalService{}, ServerConfig{
+		RequestTimeout: time.Second,
+		Dependencies: map[string]DependencyProbe{
+			DependencyPostgres: func(context.Context) error { return errors.New("connection refused") },
+			DependencyRedis:    func(context.Context) error { return errors.New("connection refused") },
+		},
+	})
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	raw, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[capabilitiesKey])
+	if err != nil {
+		t.Fatalf("marshal capabilities failed: %v", err)
+	}
+	var caps map[string]toolCapability
+	if err := json.Unmarshal(raw, &caps); err != nil {
+		t.Fatalf("decode capabilities failed: %v", err)
+	}
+	if caps["signals_list"].Available {
+		t.Fatal("expected signals_list to be unavailable without postgres")
+	}
+	prices := caps["prices_list_latest"]
+	if !prices.Available || !prices.Degraded {
+		t.Fatalf("expected prices_list_latest available but degraded, got %+v", prices)
+	}
+}
+
+func TestToolCallReturnsDataUnavailableCode(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	signals := &stubSignalService{}
+	srv := NewServer(nil, &stubPriceService{}, signals, ServerConfig{
+		RequestTimeout: time.Second,
+		Dependencies: map[string]DependencyProbe{
+			DependencyPostgres: func(context.Context) error { return errors.New("connection refused") },
+		},
+	})
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "signals_list", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("call tool failed: %v", err)
+	}
+	if !res.IsError {
+		t.Fatal("expected tool error result")
+	}
+	if code := decodeToolErrorCode(t, res); code != ErrCodeDataUnavailable {
+		t.Fatalf("expected %s, got %s", ErrCodeDataUnavailable, code)
+	}
+	if signals.lastFilter.Limit != 0 {
+		t.Fatal("expected signal service not to be called")
+	}
+}
+
+func TestToolErrorsAreTagged(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	srv := NewServer(nil, nil, &stubSignalService{}, ServerConfig{RequestTimeout: time.Second})
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "prices_list_latest", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("call tool failed: %v", err)
+	}
+	if code := decodeToolErrorCode(t, res); code != ErrCodeDataUnavailable {
+		t.Fatalf("expected %s for missing price service, got %s", ErrCodeDataUnavailable, code)
+	}
+}
+
+func TestClassifyToolError(t *testing.T) {
+	cases := map[string]string{
+		"coingecko API error 429: slow down": ErrCodeRateLimited,
+		"rate limit wait: context canceled":  ErrCodeRateLimited,
+		"signal service unavailable":         ErrCodeDataUnavailable,
+		"dial tcp: connection refused":       ErrCodeDataUnavailable,
+		"unsupported symbol: DOGE":           ErrCodeInternal,
+	}
+	for msg, want := range cases {
+		if got := classifyToolError(msg); got != want {
+			t.Fatalf("classifyToolError(%q) = %s, want %s", msg, got, want)
+		}
+	}
+}
+
+func TestRateLimitResponseIncludesCode(t *testing.T) {
+	h := withRateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), newHTTPRateLimiter(1))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if i == 0 {
+			continue
+		}
+		var body map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode body failed: %v", err)
+		}
+		if body["code"] != ErrCodeRateLimited {
+			t.Fatalf("expected rate_limited code, got %v", body)
+		}
+	}
+}
+
+func decodeToolErrorCode(t *testing.T, res *sdkmcp.CallToolResult) string {
+	t.Helper()
+	raw, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatalf("marshal structured content failed: %v", err)
+	}
+	var payload toolErrorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		t.Fatalf("decode structured content failed: %v", err)
+	}
+	return payload.Error.Code
+}
@@ -8,12 +8,12 @@ import (
 	"strconv"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-func registerResources(server *mcp.Server, prices PriceReader, signals SignalReaderWriter) {
+func registerResources(server *mcp.Server, prices PriceReader, signals SignalReaderWriter, risk SignalRiskMapper) {
 	server.AddResource(&mcp.Resource{
 		URI:         "market://supported-symbols",
 		Name:        "supported-symbols",
@@ -21,7 +21,7 @@ func registerResources(server *mcp.Server, prices PriceReader, signals SignalRea
 		MIMEType:    "application/json",
 	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 		_ = ctx
-		return jsonResource(req.Params.URI, domain.SupportedSymbols)
+		return jsonResource(req.Params.URI, domain.SupportedSymbols())
 	})
 
 	server.AddResource(&mcp.Resource{
@@ -34,6 +34,19 @@ func registerResources(server *mcp.Server, prices PriceReader, signals SignalRea
 		return jsonResource(req.Params.URI, domain.SupportedIntervals)
 	})
 
+	server.AddResource(&mcp.Resource{
+		URI:         "market://indicators",
+		Name:        "indicators",
+		Description: "Known signal indicators with descriptions, direction semantics, typical risk range and chart support",
+		MIMEType:    "application/json",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		_ = ctx
+		if risk == nil {
+			return jsonResource(req.Params.URI, domain.Indicators)
+		}
+		return jsonResource(req.Params.URI, domain.IndicatorsWithRisk(risk.RiskFor))
+	})
+
 	server.AddResource(&mcp.Resource{
 		URI:         "prices://latest",
 		Name:        "prices-latest",
@@ -172,6 +185,49 @@ func registerResources(server *mcp.Server, prices PriceReader, signals SignalRea
 		}
 		return jsonResource(req.Params.URI, signalsListOutput{Signals: list})
 	})
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "signals://{id}/image",
+		Name:        "signal-image",
+		Description: "Rendered chart image for a signal id, returned as base64 blob contents in the image's own MIME type (PNG or WebP)",
+		MIMEType:    "image/png",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		images, ok := signals.(SignalImageReader)
+		if !ok || images == nil {
+			return nil, fmt.Errorf("signal image service unavailable")
+		}
+
+		parsed, err := url.Parse(req.Params.URI)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+		if parsed.Scheme != "signals" || strings.Trim(parsed.Path, "/") != "image" {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+		id, err := strconv.ParseInt(parsed.Host, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+
+		image, err := images.GetSignalImage(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if image == nil || len(image.Bytes) == 0 {
+			return nil, mcp.ResourceNotFoundError(req.Params.URI)
+		}
+		mimeType := image.Ref.MimeType
+		if mimeType == "" {
+			mimeType = "image/png"
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      req.Params.URI,
+				MIMEType: mimeType,
+				Blob:     image.Bytes,
+			}},
+		}, nil
+	})
 }
 
 func jsonResource(uri string, payload any) (*mcp.ReadResourceResult, error) {
@@ -2,9 +2,12 @@ package mcp
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
+	"bug-free-umbrella/pkg/domain"
+
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -84,3 +87,99 @@ func TestRemovedSignalImageResource(t *testing.T) {
 		t.Fatal("expected resource not found error for signal-image://2")
 	}
 }
+
+func TestSignalImageResourceReturnsBlob(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	srv, _, _ := testServer()
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	readRes, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "signals://1/image"})
+	if err != nil {
+		t.Fatalf("read signal image failed: %v", err)
+	}
+	if len(readRes.Contents) != 1 {
+		t.Fatalf("expected one content item, got %d", len(readRes.Contents))
+	}
+	content := readRes.Contents[0]
+	if content.MIMEType != "image/png" {
+		t.Fatalf("expected image/png, got %s", content.MIMEType)
+	}
+	if string(content.Blob) != "\x89PNG" {
+		t.Fatalf("unexpected blob contents %q", content.Blob)
+	}
+
+	if _, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "signals://99/image"}); err == nil {
+		t.Fatal("expected not found for signal without image")
+	}
+}
+
+func TestIndicatorsResourceListsRegistry(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	srv, _, _ := testServer()
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	readRes, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "market://indicators"})
+	if err != nil {
+		t.Fatalf("read indicators resource failed: %v", err)
+	}
+	var indicators []domain.IndicatorInfo
+	if err := decodeResourceJSON(readRes, &indicators); err != nil {
+		t.Fatalf("decode indicators failed: %v", err)
+	}
+	if len(indicators) != len(domain.Indicators) {
+		t.Fatalf("expected %d indicators, got %d", len(domain.Indicators), len(indicators))
+	}
+	if !reflect.DeepEqual(indicators[0], domain.Indicators[0]) {
+		t.Fatalf("unexpected first indicator %+v", indicators[0])
+	}
+}
+
+type riskMapperStub struct{}
+
+func (riskMapperStub) RiskFor(indicator, interval string) domain.RiskLevel {
+	if indicator == domain.IndicatorRSI && interval == "5m" {
+		return domain.RiskLevel5
+	}
+	return domain.RiskLevel1
+}
+
+func TestIndicatorsResourceReportsActiveRisk(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, prices, signals := testServer()
+	srv := NewServer(nil, prices, signals, ServerConfig{RequestTimeout: time.Second, Risk: riskMapperStub{}})
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	readRes, err := session.ReadResource(ctx, &sdkmcp.ReadResourceParams{URI: "market://indicators"})
+	if err != nil {
+		t.Fatalf("read indicators resource failed: %v", err)
+	}
+	var indicators []domain.IndicatorInfo
+	if err := decodeResourceJSON(readRes, &indicators); err != nil {
+		t.Fatalf("decode indicators failed: %v", err)
+	}
+	rsi := indicators[0]
+	if rsi.RiskByInterval["5m"] != domain.RiskLevel5 || rsi.MinRisk != domain.RiskLevel1 || rsi.MaxRisk != domain.RiskLevel5 {
+		t.Fatalf("expected the active risk mapping, got %+v", rsi)
+	}
+}
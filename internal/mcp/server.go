@@ -16,6 +16,18 @@ const defaultRequestTimeout = 5 * time.Second
 
 type ServerConfig struct {
 	RequestTimeout time.Duration
+	// AdminToken gates admin-scoped tools on the HTTP transport.
+	AdminToken string
+	// ML enables the ml_refresh_and_infer tool when set, and
+	// ml_similar_setups when it also implements MLSimilarSetupFinder.
+	ML MLInferenceRunner
+	// Dependencies are named health probes (see DependencyPostgres and
+	// DependencyRedis) used to advertise tool availability and to fail fast
+	// with structured error codes when a backing store is down.
+	Dependencies map[string]DependencyProbe
+	// Risk fills in the per-interval risk of classic indicators in
+	// market://indicators when set.
+	Risk SignalRiskMapper
 }
 
 func NewServer(tracer trace.Tracer, prices PriceReader, signals SignalReaderWriter, cfg ServerConfig) *sdkmcp.Server {
@@ -38,7 +50,17 @@ func NewServer(tracer trace.Tracer, prices PriceReader, signals SignalReaderWrit
 	}
 
 	registerTools(srv, prices, signals)
-	registerResources(srv, prices, signals)
+	toolNames := []string{"prices_list_latest", "prices_get_by_symbol", "candles_list", "signals_list", "signals_generate"}
+	if cfg.ML != nil {
+		registerAdminTools(srv, cfg.ML, cfg.AdminToken)
+		toolNames = append(toolNames, "ml_refresh_and_infer")
+		if finder, ok := cfg.ML.(MLSimilarSetupFinder); ok {
+			registerSimilarityTools(srv, finder)
+			toolNames = append(toolNames, "ml_similar_setups")
+		}
+	}
+	srv.AddReceivingMiddleware(degradationMiddleware(newDependencyMonitor(cfg.Dependencies), toolNames))
+	registerResources(srv, prices, signals, cfg.Risk)
 	return srv
 }
 
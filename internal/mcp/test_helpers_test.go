@@ -6,7 +6,7 @@ import (
 	"net/http"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -42,6 +42,8 @@ type stubSignalService struct {
 	listed    []domain.Signal
 	generated []domain.Signal
 
+	images map[int64]*domain.SignalImageData
+
 	lastGenerateSymbol    string
 	lastGenerateIntervals []string
 	lastFilter            domain.SignalFilter
@@ -58,6 +60,10 @@ func (s *stubSignalService) GenerateForSymbol(ctx context.Context, symbol string
 	return append([]domain.Signal(nil), s.generated...), nil
 }
 
+func (s *stubSignalService) GetSignalImage(ctx context.Context, signalID int64) (*domain.SignalImageData, error) {
+	return s.images[signalID], nil
+}
+
 func testServer() (*sdkmcp.Server, *stubPriceService, *stubSignalService) {
 	prices := &stubPriceService{
 		prices: []*domain.PriceSnapshot{
@@ -79,6 +85,9 @@ func testServer() (*sdkmcp.Server, *stubPriceService, *stubSignalService) {
 			ID: 2, Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorMACD,
 			Direction: domain.DirectionLong, Risk: domain.RiskLevel4, Timestamp: time.Unix(1, 0).UTC(),
 		}},
+		images: map[int64]*domain.SignalImageData{
+			1: {Ref: domain.SignalImageRef{ImageID: 7, MimeType: "image/png"}, Bytes: []byte{0x89, 'P', 'N', 'G'}},
+		},
 	}
 
 	srv := NewServer(nil, prices, signals, ServerConfig{RequestTimeout: time.Second})
@@ -105,3 +105,55 @@ func registerTools(server *mcp.Server, prices PriceReader, signals SignalReaderW
 		return nil, signalsGenerateOutput{GeneratedCount: len(generated), Signals: generated}, nil
 	})
 }
+
+func registerAdminTools(server *mcp.Server, ml MLInferenceRunner, adminToken string) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "ml_refresh_and_infer",
+		Description: "Admin: rebuild ML features for a symbol, run inference and return the latest predictions",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, in mlRefreshAndInferInput) (*mcp.CallToolResult, mlRefreshAndInferOutput, error) {
+		if err := requireAdmin(req, adminToken); err != nil {
+			return nil, mlRefreshAndInferOutput{}, err
+		}
+		symbol, err := normalizeSymbol(in.Symbol)
+		if err != nil {
+			return nil, mlRefreshAndInferOutput{}, err
+		}
+
+		result, err := ml.RefreshAndInfer(ctx, symbol, normalizeMLLimit(in.Limit))
+		if err != nil {
+			return nil, mlRefreshAndInferOutput{}, err
+		}
+		return nil, mlRefreshAndInferOutput{
+			Symbol:             symbol,
+			FeatureRows:        result.FeatureRows,
+			PredictionsWritten: result.Inference.Predictions,
+			SignalsWritten:     result.Inference.Signals,
+			Predictions:        toMLPredictionOutputs(result.Predictions),
+		}, nil
+	})
+}
+
+func registerSimilarityTools(server *mcp.Server, finder MLSimilarSetupFinder) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "ml_similar_setups",
+		Description: "Find the past market states most similar to a symbol's latest ML features and what price did next",
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, in mlSimilarSetupsInput) (*mcp.CallToolResult, mlSimilarSetupsOutput, error) {
+		symbol, err := normalizeSymbol(in.Symbol)
+		if err != nil {
+			return nil, mlSimilarSetupsOutput{}, err
+		}
+		interval := "1h"
+		if in.Interval != "" {
+			interval, err = normalizeInterval(in.Interval)
+			if err != nil {
+				return nil, mlSimilarSetupsOutput{}, err
+			}
+		}
+
+		result, err := finder.FindSimilar(ctx, symbol, interval, normalizeSimilarLimit(in.K))
+		if err != nil {
+			return nil, mlSimilarSetupsOutput{}, err
+		}
+		return nil, mlSimilarSetupsOutput{Setups: result}, nil
+	})
+}
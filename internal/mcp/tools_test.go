@@ -2,9 +2,15 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"testing"
 	"time"
 
+	"bug-free-umbrella/internal/ml/inference"
+	"bug-free-umbrella/internal/service"
+	"bug-free-umbrella/pkg/domain"
+
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -103,3 +109,135 @@ func TestRemovedSignalImageTool(t *testing.T) {
 		t.Fatal("expected missing tool error for signal_image_get")
 	}
 }
+
+func TestMLRefreshAndInferTool(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	prices := &stubPriceService{}
+	ml := &stubMLRunner{result: service.MLRefreshResult{
+		FeatureRows: 12,
+		Inference:   inference.RunResult{Predictions: 3, Signals: 1},
+		Predictions: []domain.MLPrediction{{ID: 9, Symbol: "ETH", Interval: "1h", ModelKey: "xgboost", ProbUp: 0.61}},
+	}}
+	srv := NewServer(nil, prices, &stubSignalService{}, ServerConfig{RequestTimeout: time.Second, ML: ml})
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "ml_refresh_and_infer", Arguments: map[string]any{"symbol": "eth"}})
+	if err != nil {
+		t.Fatalf("call tool failed: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected tool error: %+v", res.Content)
+	}
+	if ml.lastSymbol != "ETH" || ml.lastLimit != defaultMLLimit {
+		t.Fatalf("unexpected runner args symbol=%s limit=%d", ml.lastSymbol, ml.lastLimit)
+	}
+	var out mlRefreshAndInferOutput
+	raw, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode output failed: %v", err)
+	}
+	if out.PredictionsWritten != 3 || len(out.Predictions) != 1 || out.Predictions[0].ModelKey != "xgboost" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+}
+
+func TestMLSimilarSetupsTool(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ml := &stubMLSimilarFinder{result: &domain.SimilarSetups{
+		Symbol:   "BTC",
+		Interval: "4h",
+		Features: map[string]float64{"rsi_14": 71},
+		Matches:  []domain.SimilarSetup{{Symbol: "SOL", Similarity: 0.91, WentUp: true}},
+		UpRate:   1,
+	}}
+	srv := NewServer(nil, &stubPriceService{}, &stubSignalService{}, ServerConfig{RequestTimeout: time.Second, ML: ml})
+	session, shutdown, err := connectInMemory(ctx, srv)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer shutdown()
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &sdkmcp.CallToolParams{Name: "ml_similar_setups", Arguments: map[string]any{"symbol": "btc", "interval": "4h", "k": 500}})
+	if err != nil {
+		t.Fatalf("call tool failed: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("unexpected tool error: %+v", res.Content)
+	}
+	if ml.lastSymbol != "BTC" || ml.lastInterval != "4h" || ml.lastK != maxSimilarK {
+		t.Fatalf("unexpected finder args symbol=%s interval=%s k=%d", ml.lastSymbol, ml.lastInterval, ml.lastK)
+	}
+	var out mlSimilarSetupsOutput
+	raw, _ := json.Marshal(res.StructuredContent)
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode output failed: %v", err)
+	}
+	if out.Setups == nil || len(out.Setups.Matches) != 1 || out.Setups.Matches[0].Symbol != "SOL" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+
+	// A runner without similarity search does not get the tool.
+	plain := NewServer(nil, &stubPriceService{}, &stubSignalService{}, ServerConfig{RequestTimeout: time.Second, ML: &stubMLRunner{}})
+	plainSession, plainShutdown, err := connectInMemory(ctx, plain)
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer plainShutdown()
+	defer plainSession.Close()
+	if _, err := plainSession.CallTool(ctx, &sdkmcp.CallToolParams{Name: "ml_similar_setups", Arguments: map[string]any{"symbol": "BTC"}}); err == nil {
+		t.Fatal("expected missing tool error for ml_similar_setups")
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	if err := requireAdmin(&sdkmcp.CallToolRequest{}, ""); err != nil {
+		t.Fatalf("stdio calls should be allowed, got %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer user-token")
+	req := &sdkmcp.CallToolRequest{Extra: &sdkmcp.RequestExtra{Header: header}}
+	if err := requireAdmin(req, "admin-token"); err == nil {
+		t.Fatal("expected non-admin HTTP token to be rejected")
+	}
+
+	header.Set("Authorization", "Bearer admin-token")
+	if err := requireAdmin(req, "admin-token"); err != nil {
+		t.Fatalf("expected admin token to be accepted, got %v", err)
+	}
+}
+
+type stubMLRunner struct {
+	result     service.MLRefreshResult
+	lastSymbol string
+	lastLimit  int
+}
+
+func (s *stubMLRunner) RefreshAndInfer(_ context.Context, symbol string, limit int) (service.MLRefreshResult, error) {
+	s.lastSymbol = symbol
+	s.lastLimit = limit
+	return s.result, nil
+}
+
+type stubMLSimilarFinder struct {
+	stubMLRunner
+	result       *domain.SimilarSetups
+	lastSymbol   string
+	lastInterval string
+	lastK        int
+}
+
+func (s *stubMLSimilarFinder) FindSimilar(_ context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error) {
+	s.lastSymbol, s.lastInterval, s.lastK = symbol, interval, k
+	return s.result, nil
+}
@@ -3,8 +3,9 @@ package mcp
 import (
 	"fmt"
 	"strings"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const (
@@ -12,6 +13,10 @@ const (
 	maxCandleLimit     = 500
 	defaultSignalLimit = 50
 	maxSignalLimit     = 200
+	defaultMLLimit     = 20
+	maxMLLimit         = 100
+	defaultSimilarK    = 10
+	maxSimilarK        = 50
 )
 
 type pricesListLatestInput struct{}
@@ -43,7 +48,7 @@ type candlesListOutput struct {
 type signalsListInput struct {
 	Symbol    string `json:"symbol,omitempty" jsonschema:"optional asset symbol (e.g. BTC, ETH)"`
 	Risk      *int   `json:"risk,omitempty" jsonschema:"optional risk level 1-5"`
-	Indicator string `json:"indicator,omitempty" jsonschema:"optional indicator: rsi, macd, bollinger, volume_zscore, ml_logreg_up4h, ml_xgboost_up4h, ml_ensemble_up4h, fund_sentiment_composite"`
+	Indicator string `json:"indicator,omitempty" jsonschema:"optional indicator: rsi, macd, bollinger, volume_zscore, ichimoku, ml_logreg_up4h, ml_xgboost_up4h, ml_ensemble_up4h, fund_sentiment_composite, cex_dex_spread"`
 	Limit     int    `json:"limit,omitempty" jsonschema:"number of signals to return, max 200"`
 }
 
@@ -61,12 +66,71 @@ type signalsGenerateOutput struct {
 	Signals        []domain.Signal `json:"signals"`
 }
 
+type mlRefreshAndInferInput struct {
+	Symbol string `json:"symbol" jsonschema:"asset symbol (e.g. BTC, ETH)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"number of latest predictions to return, max 100"`
+}
+
+type mlPredictionOutput struct {
+	ID           int64     `json:"id"`
+	Symbol       string    `json:"symbol"`
+	Interval     string    `json:"interval"`
+	OpenTime     time.Time `json:"open_time"`
+	TargetTime   time.Time `json:"target_time"`
+	ModelKey     string    `json:"model_key"`
+	ModelVersion int       `json:"model_version"`
+	ProbUp       float64   `json:"prob_up"`
+	Confidence   float64   `json:"confidence"`
+	Direction    string    `json:"direction"`
+	Risk         int       `json:"risk"`
+	SignalID     *int64    `json:"signal_id,omitempty"`
+}
+
+type mlRefreshAndInferOutput struct {
+	Symbol             string               `json:"symbol"`
+	FeatureRows        int                  `json:"feature_rows"`
+	PredictionsWritten int                  `json:"predictions_written"`
+	SignalsWritten     int                  `json:"signals_written"`
+	Predictions        []mlPredictionOutput `json:"predictions"`
+}
+
+type mlSimilarSetupsInput struct {
+	Symbol   string `json:"symbol" jsonschema:"asset symbol (e.g. BTC, ETH)"`
+	Interval string `json:"interval,omitempty" jsonschema:"feature interval: 5m, 15m, 1h, 4h, 1d (default 1h)"`
+	K        int    `json:"k,omitempty" jsonschema:"number of similar past states to return, max 50"`
+}
+
+type mlSimilarSetupsOutput struct {
+	Setups *domain.SimilarSetups `json:"setups"`
+}
+
+func toMLPredictionOutputs(list []domain.MLPrediction) []mlPredictionOutput {
+	out := make([]mlPredictionOutput, 0, len(list))
+	for _, p := range list {
+		out = append(out, mlPredictionOutput{
+			ID:           p.ID,
+			Symbol:       p.Symbol,
+			Interval:     p.Interval,
+			OpenTime:     p.OpenTime,
+			TargetTime:   p.TargetTime,
+			ModelKey:     p.ModelKey,
+			ModelVersion: p.ModelVersion,
+			ProbUp:       p.ProbUp,
+			Confidence:   p.Confidence,
+			Direction:    string(p.Direction),
+			Risk:         int(p.Risk),
+			SignalID:     p.SignalID,
+		})
+	}
+	return out
+}
+
 func normalizeSymbol(symbol string) (string, error) {
 	symbol = strings.ToUpper(strings.TrimSpace(symbol))
 	if symbol == "" {
 		return "", fmt.Errorf("symbol is required")
 	}
-	if _, ok := domain.CoinGeckoID[symbol]; !ok {
+	if !domain.IsSupportedSymbol(symbol) {
 		return "", fmt.Errorf("unsupported symbol: %s", symbol)
 	}
 	return symbol, nil
@@ -105,25 +169,36 @@ func normalizeSignalLimit(limit int) int {
 	return limit
 }
 
+func normalizeMLLimit(limit int) int {
+	if limit <= 0 {
+		return defaultMLLimit
+	}
+	if limit > maxMLLimit {
+		return maxMLLimit
+	}
+	return limit
+}
+
+func normalizeSimilarLimit(k int) int {
+	if k <= 0 {
+		return defaultSimilarK
+	}
+	if k > maxSimilarK {
+		return maxSimilarK
+	}
+	return k
+}
+
 func normalizeIndicator(indicator string) (string, error) {
 	indicator = strings.ToLower(strings.TrimSpace(indicator))
 	if indicator == "" {
 		return "", nil
 	}
 
-	switch indicator {
-	case domain.IndicatorRSI,
-		domain.IndicatorMACD,
-		domain.IndicatorBollinger,
-		domain.IndicatorVolumeZ,
-		domain.IndicatorMLLogRegUp4H,
-		domain.IndicatorMLXGBoostUp4H,
-		domain.IndicatorMLEnsembleUp4H,
-		domain.IndicatorFundSentimentComposite:
-		return indicator, nil
-	default:
+	if _, ok := domain.LookupIndicator(indicator); !ok {
 		return "", fmt.Errorf("unsupported indicator: %s", indicator)
 	}
+	return indicator, nil
 }
 
 func normalizeSignalFilter(in signalsListInput) (domain.SignalFilter, error) {
@@ -1,9 +1,11 @@
 package mcp
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestNormalizeSymbol(t *testing.T) {
@@ -94,3 +96,24 @@ func TestNormalizeIndicatorML(t *testing.T) {
 		t.Fatalf("expected phase7 indicator, got %s", got)
 	}
 }
+
+func TestNormalizeIndicatorAcceptsRegistry(t *testing.T) {
+	for _, key := range domain.IndicatorKeys() {
+		if _, err := normalizeIndicator(strings.ToUpper(key)); err != nil {
+			t.Fatalf("expected %s accepted: %v", key, err)
+		}
+	}
+	if _, err := normalizeIndicator("stochastic"); err == nil {
+		t.Fatal("expected unknown indicator rejected")
+	}
+}
+
+func TestSignalsListInputDocumentsEveryIndicator(t *testing.T) {
+	field, _ := reflect.TypeOf(signalsListInput{}).FieldByName("Indicator")
+	schema := field.Tag.Get("jsonschema")
+	for _, key := range domain.IndicatorKeys() {
+		if !strings.Contains(schema, key) {
+			t.Fatalf("indicator %s missing from jsonschema %q", key, schema)
+		}
+	}
+}
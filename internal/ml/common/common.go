@@ -2,8 +2,9 @@ package common
 
 import (
 	"math"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const (
@@ -27,9 +28,38 @@ var FeatureNames = []string{
 	"macd_hist",
 	"bb_pos",
 	"bb_width",
+	// Seasonality, from the candle's UTC open time. They come last so models
+	// trained before they existed can still score a prefix of the vector
+	// (see ForFeatureCount).
+	"hour_sin",
+	"hour_cos",
+	"dow_sin",
+	"dow_cos",
+	"month_sin",
+	"month_cos",
+	// Order book, averaged over the candle. Appended after seasonality for
+	// the same reason; rows without snapshots carry 0.
+	"book_imbalance",
+	"book_spread_bps",
+	// Perpetual futures positioning, appended after the order book.
+	"funding_rate",
+	"oi_change_24h",
+	// Whole-market context, the same for every symbol at a given time.
+	"btc_dominance",
+	"mcap_change_24h",
+	// Scheduled macro and unlock events ahead of the candle.
+	"event_proximity",
 }
 
+// MarketFeatureCount is the number of leading features that describe price
+// and volume; the rest are calendar position, order book, derivatives,
+// global market and event proximity.
+const MarketFeatureCount = 13
+
 func FeatureVector(row domain.MLFeatureRow) []float64 {
+	hourSin, hourCos := cyclical(float64(row.OpenTime.UTC().Hour()), 24)
+	dowSin, dowCos := cyclical(float64(row.OpenTime.UTC().Weekday()), 7)
+	monthSin, monthCos := cyclical(float64(row.OpenTime.UTC().Month()-time.January), 12)
 	return []float64{
 		row.Ret1H,
 		row.Ret4H,
@@ -44,6 +74,41 @@ func FeatureVector(row domain.MLFeatureRow) []float64 {
 		row.MACDHist,
 		row.BBPos,
 		row.BBWidth,
+		hourSin,
+		hourCos,
+		dowSin,
+		dowCos,
+		monthSin,
+		monthCos,
+		row.BookImbalance,
+		row.BookSpreadBps,
+		row.FundingRate,
+		row.OIChange24H,
+		row.BTCDominance,
+		row.MarketCapChange24H,
+		row.EventProximity,
+	}
+}
+
+// cyclical places v on a circle of the given period, so the last bucket sits
+// next to the first (23:00 next to 00:00, Sunday next to Monday).
+func cyclical(v, period float64) (float64, float64) {
+	angle := 2 * math.Pi * v / period
+	return math.Sin(angle), math.Cos(angle)
+}
+
+// ForFeatureCount adapts predict to a model trained on the first n features,
+// as models from before later features were appended are. n <= 0 means the
+// model did not record its features and gets the full vector.
+func ForFeatureCount(n int, predict func([]float64) float64) func([]float64) float64 {
+	if predict == nil || n <= 0 || n >= len(FeatureNames) {
+		return predict
+	}
+	return func(vector []float64) float64 {
+		if len(vector) > n {
+			vector = vector[:n]
+		}
+		return predict(vector)
 	}
 }
 
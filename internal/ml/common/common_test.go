@@ -0,0 +1,73 @@
+package common
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestFeatureVectorEncodesSeasonality(t *testing.T) {
+	// A Monday in January, 06:00 UTC.
+	row := domain.MLFeatureRow{OpenTime: time.Date(2026, 1, 5, 6, 0, 0, 0, time.UTC), RSI14: 55}
+	vector := FeatureVector(row)
+	if len(vector) != len(FeatureNames) {
+		t.Fatalf("expected %d features, got %d", len(FeatureNames), len(vector))
+	}
+	feature := func(name string) float64 {
+		for i, n := range FeatureNames {
+			if n == name {
+				return vector[i]
+			}
+		}
+		t.Fatalf("missing feature %s", name)
+		return 0
+	}
+	want := map[string]float64{
+		"rsi_14":    55,
+		"hour_sin":  1,
+		"hour_cos":  0,
+		"dow_sin":   math.Sin(2 * math.Pi / 7),
+		"dow_cos":   math.Cos(2 * math.Pi / 7),
+		"month_sin": 0,
+		"month_cos": 1,
+	}
+	for name, v := range want {
+		if math.Abs(feature(name)-v) > 1e-9 {
+			t.Fatalf("%s: expected %f, got %f", name, v, feature(name))
+		}
+	}
+
+	// 23:00 sits next to 00:00, not at the far end of the scale.
+	late := FeatureVector(domain.MLFeatureRow{OpenTime: time.Date(2026, 1, 5, 23, 0, 0, 0, time.UTC)})
+	early := FeatureVector(domain.MLFeatureRow{OpenTime: time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)})
+	hour := MarketFeatureCount
+	gap := math.Hypot(late[hour]-early[hour], late[hour+1]-early[hour+1])
+	if gap > 0.3 {
+		t.Fatalf("expected adjacent hours to stay close, distance %f", gap)
+	}
+}
+
+func TestForFeatureCount(t *testing.T) {
+	var seen int
+	predict := func(v []float64) float64 {
+		seen = len(v)
+		return 0.7
+	}
+	vector := make([]float64, len(FeatureNames))
+
+	ForFeatureCount(MarketFeatureCount, predict)(vector)
+	if seen != MarketFeatureCount {
+		t.Fatalf("expected the first %d features, got %d", MarketFeatureCount, seen)
+	}
+	for _, n := range []int{0, len(FeatureNames)} {
+		ForFeatureCount(n, predict)(vector)
+		if seen != len(FeatureNames) {
+			t.Fatalf("n=%d: expected the full vector, got %d", n, seen)
+		}
+	}
+	if ForFeatureCount(MarketFeatureCount, nil) != nil {
+		t.Fatal("expected a nil predictor to stay nil")
+	}
+}
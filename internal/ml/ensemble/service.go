@@ -1,13 +1,31 @@
 package ensemble
 
-import "bug-free-umbrella/internal/domain"
+import (
+	"math"
+
+	"bug-free-umbrella/pkg/domain"
+)
 
 type Components struct {
 	ClassicScore float64
 	LogRegProb   float64
 	XGBoostProb  float64
+	// FearGreed is the Crypto Fear & Greed index (0-100) at the candle, or
+	// nil when no recent value is known.
+	FearGreed *int
 }
 
+// Weights of the model components in the score.
+const (
+	ClassicWeight = 0.30
+	LogRegWeight  = 0.35
+	XGBoostWeight = 0.35
+)
+
+// FearGreedWeight is the share of the score the Fear & Greed index gets
+// when it is known; the other components are scaled down to make room.
+const FearGreedWeight = 0.10
+
 type Service struct{}
 
 func NewService() *Service { return &Service{} }
@@ -15,14 +33,98 @@ func NewService() *Service { return &Service{} }
 func (s *Service) Score(c Components) float64 {
 	logRegScore := 2*c.LogRegProb - 1
 	xgbScore := 2*c.XGBoostProb - 1
-	return 0.30*c.ClassicScore + 0.35*logRegScore + 0.35*xgbScore
+	score := ClassicWeight*c.ClassicScore + LogRegWeight*logRegScore + XGBoostWeight*xgbScore
+	if c.FearGreed == nil {
+		return score
+	}
+	return (1-FearGreedWeight)*score + FearGreedWeight*FearGreedScore(*c.FearGreed)
+}
+
+// Damp scales score by the anomaly damp factor and clamps it to [-1, 1].
+func Damp(score, dampFactor float64) float64 {
+	return math.Max(-1, math.Min(1, score*dampFactor))
+}
+
+// Component names in a Breakdown.
+const (
+	ComponentClassic     = "classic"
+	ComponentLogReg      = "logreg"
+	ComponentXGBoost     = "xgboost"
+	ComponentSentiment   = "sentiment"
+	ComponentAnomalyDamp = "anomaly_damp"
+)
+
+// Contribution is one component's share of a score. Value is the raw input:
+// the classic score, a model's up probability, the Fear & Greed index or the
+// anomaly score. Weight is the effective weight, or the damp factor for
+// anomaly damping, and Contribution is what the component added to Score.
+type Contribution struct {
+	Component    string  `json:"component"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// ScoreBreakdown explains a damped score. The contributions sum to Score.
+type ScoreBreakdown struct {
+	Components    []Contribution `json:"components"`
+	UndampedScore float64        `json:"undamped_score"`
+	Score         float64        `json:"score"`
+}
+
+// Breakdown splits the damped score of c into per-component contributions.
+// Anomaly damping contributes the difference between the damped and the
+// undamped score.
+func (s *Service) Breakdown(c Components, anomalyScore, dampFactor float64) ScoreBreakdown {
+	scale := 1.0
+	if c.FearGreed != nil {
+		scale = 1 - FearGreedWeight
+	}
+	out := ScoreBreakdown{Components: []Contribution{
+		{Component: ComponentClassic, Value: c.ClassicScore, Weight: scale * ClassicWeight, Contribution: scale * ClassicWeight * c.ClassicScore},
+		{Component: ComponentLogReg, Value: c.LogRegProb, Weight: scale * LogRegWeight, Contribution: scale * LogRegWeight * (2*c.LogRegProb - 1)},
+		{Component: ComponentXGBoost, Value: c.XGBoostProb, Weight: scale * XGBoostWeight, Contribution: scale * XGBoostWeight * (2*c.XGBoostProb - 1)},
+	}}
+	if c.FearGreed != nil {
+		out.Components = append(out.Components, Contribution{
+			Component:    ComponentSentiment,
+			Value:        float64(*c.FearGreed),
+			Weight:       FearGreedWeight,
+			Contribution: FearGreedWeight * FearGreedScore(*c.FearGreed),
+		})
+	}
+	out.UndampedScore = s.Score(c)
+	out.Score = Damp(out.UndampedScore, dampFactor)
+	out.Components = append(out.Components, Contribution{
+		Component:    ComponentAnomalyDamp,
+		Value:        anomalyScore,
+		Weight:       dampFactor,
+		Contribution: out.Score - out.UndampedScore,
+	})
+	return out
 }
 
+// FearGreedScore maps an index value onto [-1, 1]: extreme fear is -1,
+// neutral 0 and extreme greed 1, as the market intel composite reads it.
+func FearGreedScore(value int) float64 {
+	return math.Max(-1, math.Min(1, (float64(value)-50)/50))
+}
+
+// HoldBand is how far from zero a score must be for Direction to call it
+// long or short.
+const HoldBand = 0.15
+
 func Direction(score float64) domain.SignalDirection {
-	if score > 0.15 {
+	return DirectionWithBand(score, HoldBand)
+}
+
+// DirectionWithBand is Direction with a wider hold band, used when the
+// models behind the score disagree.
+func DirectionWithBand(score, band float64) domain.SignalDirection {
+	if score > band {
 		return domain.DirectionLong
 	}
-	if score < -0.15 {
+	if score < -band {
 		return domain.DirectionShort
 	}
 	return domain.DirectionHold
@@ -1,9 +1,10 @@
 package ensemble
 
 import (
+	"math"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestScoreAndDirection(t *testing.T) {
@@ -32,3 +33,75 @@ func TestScoreAndDirection(t *testing.T) {
 		t.Fatalf("expected short direction, got %s", dir)
 	}
 }
+
+func TestDirectionWithBandHoldsInsideWiderBand(t *testing.T) {
+	if dir := DirectionWithBand(0.25, HoldBand); dir != domain.DirectionLong {
+		t.Fatalf("expected long with the default band, got %s", dir)
+	}
+	if dir := DirectionWithBand(0.25, 0.3); dir != domain.DirectionHold {
+		t.Fatalf("expected hold inside a widened band, got %s", dir)
+	}
+	if dir := DirectionWithBand(-0.35, 0.3); dir != domain.DirectionShort {
+		t.Fatalf("expected short outside a widened band, got %s", dir)
+	}
+}
+
+func TestScoreBlendsFearGreed(t *testing.T) {
+	s := NewService()
+	base := Components{ClassicScore: 0.2, LogRegProb: 0.6, XGBoostProb: 0.6}
+	without := s.Score(base)
+
+	greed := 100
+	base.FearGreed = &greed
+	want := (1-FearGreedWeight)*without + FearGreedWeight
+	if got := s.Score(base); math.Abs(got-want) > 1e-9 {
+		t.Fatalf("expected %.4f with extreme greed, got %.4f", want, got)
+	}
+
+	neutral := 50
+	base.FearGreed = &neutral
+	if got := s.Score(base); math.Abs(got-(1-FearGreedWeight)*without) > 1e-9 {
+		t.Fatalf("expected a neutral index to only scale the score, got %.4f", got)
+	}
+}
+
+func TestFearGreedScoreClamps(t *testing.T) {
+	for value, want := range map[int]float64{0: -1, 25: -0.5, 50: 0, 75: 0.5, 100: 1, 120: 1} {
+		if got := FearGreedScore(value); got != want {
+			t.Fatalf("FearGreedScore(%d) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestBreakdownSumsToDampedScore(t *testing.T) {
+	s := NewService()
+	fear := 20
+	c := Components{ClassicScore: 0.6, LogRegProb: 0.8, XGBoostProb: 0.75, FearGreed: &fear}
+
+	b := s.Breakdown(c, 0.9, 0.5)
+	if want := s.Score(c); math.Abs(b.UndampedScore-want) > 1e-9 {
+		t.Fatalf("expected undamped score %.4f, got %.4f", want, b.UndampedScore)
+	}
+	if want := Damp(b.UndampedScore, 0.5); math.Abs(b.Score-want) > 1e-9 {
+		t.Fatalf("expected damped score %.4f, got %.4f", want, b.Score)
+	}
+	sum, weights := 0.0, 0.0
+	for _, entry := range b.Components {
+		sum += entry.Contribution
+		if entry.Component != ComponentAnomalyDamp {
+			weights += entry.Weight
+		}
+	}
+	if math.Abs(sum-b.Score) > 1e-9 || math.Abs(weights-1) > 1e-9 {
+		t.Fatalf("expected contributions to sum to the score and weights to 1, got %.4f and %.4f", sum, weights)
+	}
+	if sentiment := b.Components[3]; sentiment.Component != ComponentSentiment || sentiment.Value != 20 || sentiment.Contribution >= 0 {
+		t.Fatalf("unexpected sentiment entry: %+v", sentiment)
+	}
+
+	// A large undamped score is clamped, and damping takes the difference.
+	b = s.Breakdown(Components{ClassicScore: 1, LogRegProb: 1, XGBoostProb: 1}, 0, 1.5)
+	if b.Score != 1 || math.Abs(b.Components[len(b.Components)-1].Contribution) > 1e-9 {
+		t.Fatalf("expected a clamped score of 1 with no damping contribution, got %+v", b)
+	}
+}
@@ -0,0 +1,72 @@
+package features
+
+import (
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// changeLookback is how far back open interest and market cap are compared.
+const changeLookback = 24 * time.Hour
+
+// DerivativesWindow returns the range of hourly derivatives averages that
+// rows built from candles opening in [from, to] may use, including the day
+// before for the open interest change.
+func DerivativesWindow(interval string, from, to time.Time) (time.Time, time.Time) {
+	return changeWindow(interval, from, to)
+}
+
+// ApplyDerivatives sets each row's funding rate to the snapshot-weighted
+// average over the hours its candle was open, and its open interest change
+// to that window's average open interest against the same window a day
+// earlier. Hours are chosen as for the order book features, so nothing
+// comes from after the candle closed. A feature without snapshots to
+// compute it from keeps 0.
+func ApplyDerivatives(rows []domain.MLFeatureRow, hourly []domain.DerivativesFeatures) {
+	if len(hourly) == 0 {
+		return
+	}
+	byHour := make(map[time.Time]domain.DerivativesFeatures, len(hourly))
+	for _, h := range hourly {
+		byHour[h.Hour.UTC()] = h
+	}
+	for i := range rows {
+		start, end := candleHours(rows[i].Interval, rows[i].OpenTime)
+		funding, oi, n := averageDerivatives(byHour, start, end)
+		if n == 0 {
+			continue
+		}
+		rows[i].FundingRate = funding
+		if _, prior, m := averageDerivatives(byHour, start.Add(-changeLookback), end.Add(-changeLookback)); m > 0 && prior > 0 {
+			rows[i].OIChange24H = oi/prior - 1
+		}
+	}
+}
+
+// averageDerivatives returns the snapshot-weighted funding rate and open
+// interest over the hours [start, end), and the snapshot count.
+func averageDerivatives(byHour map[time.Time]domain.DerivativesFeatures, start, end time.Time) (float64, float64, int) {
+	var funding, oi float64
+	var n int
+	for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+		h, ok := byHour[hour]
+		if !ok || h.Snapshots <= 0 {
+			continue
+		}
+		funding += h.FundingRate * float64(h.Snapshots)
+		oi += h.OpenInterest * float64(h.Snapshots)
+		n += h.Snapshots
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return funding / float64(n), oi / float64(n), n
+}
+
+// changeWindow returns the hours rows built from candles opening in
+// [from, to] read, plus changeLookback before them.
+func changeWindow(interval string, from, to time.Time) (time.Time, time.Time) {
+	start, _ := candleHours(interval, from)
+	_, end := candleHours(interval, to)
+	return start.Add(-changeLookback), end
+}
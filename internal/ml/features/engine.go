@@ -5,12 +5,15 @@ import (
 	"sort"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/ta"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const (
-	featureSpecVersion = "v1"
+	// v2 appended the seasonality features to the model vector, v3 the
+	// order book features, v4 the derivatives features, v5 the global
+	// market features and v6 event proximity.
+	featureSpecVersion = "v6"
 	rsiPeriod          = 14
 	macdFast           = 12
 	macdSlow           = 26
@@ -1,10 +1,11 @@
 package features
 
 import (
+	"math"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestEngineBuildRowsDeterministic(t *testing.T) {
@@ -60,3 +61,122 @@ func makeCandles(n int) []*domain.Candle {
 	}
 	return out
 }
+
+func TestApplyOrderBookAveragesTheCandleWithoutLookahead(t *testing.T) {
+	base := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	hourly := []domain.OrderBookFeatures{
+		{Hour: base, Imbalance: 0.2, SpreadBps: 2, Snapshots: 60},
+		{Hour: base.Add(time.Hour), Imbalance: -0.4, SpreadBps: 4, Snapshots: 30},
+		{Hour: base.Add(4 * time.Hour), Imbalance: 0.9, SpreadBps: 9, Snapshots: 60},
+	}
+	rows := []domain.MLFeatureRow{
+		{Interval: "1h", OpenTime: base},
+		{Interval: "4h", OpenTime: base},
+		{Interval: "15m", OpenTime: base.Add(time.Hour + 15*time.Minute)},
+		{Interval: "1h", OpenTime: base.Add(2 * time.Hour)},
+	}
+	ApplyOrderBook(rows, hourly)
+
+	if rows[0].BookImbalance != 0.2 || rows[0].BookSpreadBps != 2 {
+		t.Fatalf("1h row should use its own hour, got %+v", rows[0])
+	}
+	// 60 snapshots at 0.2 and 30 at -0.4; the 12:00 hour is after the close.
+	if math.Abs(rows[1].BookImbalance-0) > 1e-9 || math.Abs(rows[1].BookSpreadBps-8.0/3) > 1e-9 {
+		t.Fatalf("4h row should weight its hours by snapshots, got %+v", rows[1])
+	}
+	if rows[2].BookImbalance != 0.2 {
+		t.Fatalf("15m row should use the previous full hour, got %+v", rows[2])
+	}
+	if rows[3].BookImbalance != 0 || rows[3].BookSpreadBps != 0 {
+		t.Fatalf("row without snapshots should keep 0, got %+v", rows[3])
+	}
+
+	from, to := OrderBookWindow("1h", base, base.Add(2*time.Hour))
+	if !from.Equal(base) || !to.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("unexpected window %s - %s", from, to)
+	}
+}
+
+func TestApplyDerivativesComparesOpenInterestWithTheDayBefore(t *testing.T) {
+	base := time.Date(2026, 2, 2, 8, 0, 0, 0, time.UTC)
+	hourly := []domain.DerivativesFeatures{
+		{Hour: base.Add(-24 * time.Hour), FundingRate: 0.0003, OpenInterest: 1000, Snapshots: 12},
+		{Hour: base, FundingRate: 0.0001, OpenInterest: 1100, Snapshots: 12},
+		{Hour: base.Add(time.Hour), FundingRate: -0.0002, OpenInterest: 900, Snapshots: 12},
+	}
+	rows := []domain.MLFeatureRow{
+		{Interval: "1h", OpenTime: base},
+		{Interval: "1h", OpenTime: base.Add(time.Hour)},
+		{Interval: "1h", OpenTime: base.Add(2 * time.Hour)},
+	}
+	ApplyDerivatives(rows, hourly)
+
+	if rows[0].FundingRate != 0.0001 || math.Abs(rows[0].OIChange24H-0.1) > 1e-9 {
+		t.Fatalf("expected funding and a 10%% open interest rise, got %+v", rows[0])
+	}
+	if rows[1].FundingRate != -0.0002 || rows[1].OIChange24H != 0 {
+		t.Fatalf("row without a day-earlier snapshot should keep a 0 change, got %+v", rows[1])
+	}
+	if rows[2].FundingRate != 0 || rows[2].OIChange24H != 0 {
+		t.Fatalf("row without snapshots should keep 0, got %+v", rows[2])
+	}
+
+	from, to := DerivativesWindow("1h", base, base.Add(2*time.Hour))
+	if !from.Equal(base.Add(-24*time.Hour)) || !to.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("unexpected window %s - %s", from, to)
+	}
+}
+
+func TestApplyGlobalMarketUsesDominanceFractionAndDayOverDayCap(t *testing.T) {
+	base := time.Date(2026, 2, 2, 8, 0, 0, 0, time.UTC)
+	hourly := []domain.GlobalMarketFeatures{
+		{Hour: base.Add(-24 * time.Hour), BTCDominancePct: 55, TotalMarketCapUSD: 2.0e12, Snapshots: 6},
+		{Hour: base, BTCDominancePct: 54, TotalMarketCapUSD: 2.1e12, Snapshots: 2},
+		{Hour: base.Add(time.Hour), BTCDominancePct: 50, TotalMarketCapUSD: 2.2e12, Snapshots: 2},
+	}
+	rows := []domain.MLFeatureRow{
+		{Interval: "1h", OpenTime: base},
+		{Interval: "4h", OpenTime: base},
+		{Interval: "1h", OpenTime: base.Add(3 * time.Hour)},
+	}
+	ApplyGlobalMarket(rows, hourly)
+
+	if math.Abs(rows[0].BTCDominance-0.54) > 1e-9 || math.Abs(rows[0].MarketCapChange24H-0.05) > 1e-9 {
+		t.Fatalf("expected 54%% dominance and a 5%% cap rise, got %+v", rows[0])
+	}
+	if math.Abs(rows[1].BTCDominance-0.52) > 1e-9 || math.Abs(rows[1].MarketCapChange24H-0.075) > 1e-9 {
+		t.Fatalf("expected the 4h candle to average both hours, got %+v", rows[1])
+	}
+	if rows[2].BTCDominance != 0 || rows[2].MarketCapChange24H != 0 {
+		t.Fatalf("row without snapshots should keep 0, got %+v", rows[2])
+	}
+}
+
+func TestApplyEventProximityScalesByTimeLeftAndImportance(t *testing.T) {
+	base := time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)
+	events := []domain.MarketEvent{
+		{Kind: domain.MarketEventFOMC, StartsAt: base.Add(42 * time.Hour), Importance: domain.EventImportanceHigh},
+		{Kind: domain.MarketEventUnlock, Symbol: "SOL", StartsAt: base.Add(30 * time.Minute), Importance: domain.EventImportanceMedium},
+	}
+	rows := []domain.MLFeatureRow{
+		{Symbol: "BTC", Interval: "1h", OpenTime: base.Add(5 * time.Hour)},
+		{Symbol: "SOL", Interval: "1h", OpenTime: base},
+		{Symbol: "BTC", Interval: "1h", OpenTime: base.Add(42 * time.Hour)},
+		{Symbol: "BTC", Interval: "1h", OpenTime: base.Add(-40 * time.Hour)},
+		{Symbol: "BTC", Interval: "1h", OpenTime: base.Add(43 * time.Hour)},
+	}
+	ApplyEventProximity(rows, events)
+
+	if math.Abs(rows[0].EventProximity-0.5) > 1e-9 {
+		t.Fatalf("expected FOMC 36h past the close to score 0.5, got %v", rows[0].EventProximity)
+	}
+	if math.Abs(rows[1].EventProximity-2.0/3) > 1e-9 {
+		t.Fatalf("expected the SOL unlock inside the candle to score its importance, got %v", rows[1].EventProximity)
+	}
+	if rows[2].EventProximity != 1 {
+		t.Fatalf("expected a high importance event inside the candle to score 1, got %v", rows[2].EventProximity)
+	}
+	if rows[3].EventProximity != 0 || rows[4].EventProximity != 0 {
+		t.Fatalf("events out of range or already started should score 0, got %+v %+v", rows[3], rows[4])
+	}
+}
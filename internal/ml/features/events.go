@@ -0,0 +1,52 @@
+package features
+
+import (
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// EventHorizon is how far ahead of a candle's close scheduled events count
+// toward its event proximity.
+const EventHorizon = 72 * time.Hour
+
+// EventWindow returns the range of event start times that rows built from
+// candles opening in [from, to] may use.
+func EventWindow(interval string, from, to time.Time) (time.Time, time.Time) {
+	return from.UTC(), to.UTC().Add(domain.IntervalDuration(interval) + EventHorizon)
+}
+
+// ApplyEventProximity sets each row's event proximity from the events that
+// concern its symbol and start from its candle's open to EventHorizon past
+// its close. An event scores its importance as a fraction of high, scaled
+// down linearly with the time left after the close, so a high importance
+// event inside the candle scores 1; the row keeps the highest score, or 0
+// when no event is in range.
+func ApplyEventProximity(rows []domain.MLFeatureRow, events []domain.MarketEvent) {
+	if len(events) == 0 {
+		return
+	}
+	for i := range rows {
+		open := rows[i].OpenTime.UTC()
+		closeAt := open.Add(domain.IntervalDuration(rows[i].Interval))
+		var best float64
+		for _, e := range events {
+			if !e.AppliesTo(rows[i].Symbol) || e.StartsAt.Before(open) {
+				continue
+			}
+			ahead := e.StartsAt.Sub(closeAt)
+			if ahead > EventHorizon {
+				continue
+			}
+			closeness := 1.0
+			if ahead > 0 {
+				closeness -= float64(ahead) / float64(EventHorizon)
+			}
+			score := closeness * float64(e.Importance) / domain.EventImportanceHigh
+			if score > best {
+				best = score
+			}
+		}
+		rows[i].EventProximity = best
+	}
+}
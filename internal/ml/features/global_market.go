@@ -0,0 +1,60 @@
+package features
+
+import (
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// GlobalMarketWindow returns the range of hourly global market averages
+// that rows built from candles opening in [from, to] may use, including the
+// day before for the market cap change.
+func GlobalMarketWindow(interval string, from, to time.Time) (time.Time, time.Time) {
+	return changeWindow(interval, from, to)
+}
+
+// ApplyGlobalMarket sets each row's BTC dominance, as a fraction, to the
+// snapshot-weighted average over the hours its candle was open, and its
+// market cap change to that window's average total market cap against the
+// same window a day earlier. Hours are chosen as for the derivatives
+// features. A feature without snapshots to compute it from keeps 0.
+func ApplyGlobalMarket(rows []domain.MLFeatureRow, hourly []domain.GlobalMarketFeatures) {
+	if len(hourly) == 0 {
+		return
+	}
+	byHour := make(map[time.Time]domain.GlobalMarketFeatures, len(hourly))
+	for _, h := range hourly {
+		byHour[h.Hour.UTC()] = h
+	}
+	for i := range rows {
+		start, end := candleHours(rows[i].Interval, rows[i].OpenTime)
+		dominance, mcap, n := averageGlobalMarket(byHour, start, end)
+		if n == 0 {
+			continue
+		}
+		rows[i].BTCDominance = dominance / 100
+		if _, prior, m := averageGlobalMarket(byHour, start.Add(-changeLookback), end.Add(-changeLookback)); m > 0 && prior > 0 {
+			rows[i].MarketCapChange24H = mcap/prior - 1
+		}
+	}
+}
+
+// averageGlobalMarket returns the snapshot-weighted BTC dominance and total
+// market cap over the hours [start, end), and the snapshot count.
+func averageGlobalMarket(byHour map[time.Time]domain.GlobalMarketFeatures, start, end time.Time) (float64, float64, int) {
+	var dominance, mcap float64
+	var n int
+	for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+		h, ok := byHour[hour]
+		if !ok || h.Snapshots <= 0 {
+			continue
+		}
+		dominance += h.BTCDominancePct * float64(h.Snapshots)
+		mcap += h.TotalMarketCapUSD * float64(h.Snapshots)
+		n += h.Snapshots
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return dominance / float64(n), mcap / float64(n), n
+}
@@ -0,0 +1,61 @@
+package features
+
+import (
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// OrderBookWindow returns the range of hourly order book averages that
+// rows built from candles opening in [from, to] may use.
+func OrderBookWindow(interval string, from, to time.Time) (time.Time, time.Time) {
+	start, _ := candleHours(interval, from)
+	_, end := candleHours(interval, to)
+	return start, end
+}
+
+// ApplyOrderBook sets each row's order book features to the snapshot-
+// weighted average of the hourly buckets captured while its candle was
+// open. Candles shorter than an hour use the previous full hour, since the
+// hour they open in is not over when they close. Rows with no snapshots in
+// their window keep 0.
+func ApplyOrderBook(rows []domain.MLFeatureRow, hourly []domain.OrderBookFeatures) {
+	if len(hourly) == 0 {
+		return
+	}
+	byHour := make(map[time.Time]domain.OrderBookFeatures, len(hourly))
+	for _, h := range hourly {
+		byHour[h.Hour.UTC()] = h
+	}
+	for i := range rows {
+		start, end := candleHours(rows[i].Interval, rows[i].OpenTime)
+		var imbalance, spread float64
+		var n int
+		for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+			h, ok := byHour[hour]
+			if !ok || h.Snapshots <= 0 {
+				continue
+			}
+			imbalance += h.Imbalance * float64(h.Snapshots)
+			spread += h.SpreadBps * float64(h.Snapshots)
+			n += h.Snapshots
+		}
+		if n == 0 {
+			continue
+		}
+		rows[i].BookImbalance = imbalance / float64(n)
+		rows[i].BookSpreadBps = spread / float64(n)
+	}
+}
+
+// candleHours returns the hourly buckets [start, end) whose data was known
+// when a candle of interval opening at openTime closed.
+func candleHours(interval string, openTime time.Time) (time.Time, time.Time) {
+	openTime = openTime.UTC()
+	step := domain.IntervalDuration(interval)
+	if step < time.Hour {
+		hour := openTime.Truncate(time.Hour)
+		return hour.Add(-time.Hour), hour
+	}
+	return openTime.Truncate(time.Hour), openTime.Add(step)
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -41,13 +41,13 @@ INSERT INTO ml_feature_rows (
     ret_1h, ret_4h, ret_12h, ret_24h,
     volatility_6h, volatility_24h, volume_z_24h,
     rsi_14, macd_line, macd_signal, macd_hist,
-    bb_pos, bb_width, target_up_4h, updated_at
+    bb_pos, bb_width, book_imbalance, book_spread_bps, funding_rate, oi_change_24h, btc_dominance, mcap_change_24h, event_proximity, target_up_4h, updated_at
 ) VALUES (
     $1, $2, $3,
     $4, $5, $6, $7,
     $8, $9, $10,
     $11, $12, $13, $14,
-    $15, $16, $17, NOW()
+    $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, NOW()
 )
 ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
     ret_1h = EXCLUDED.ret_1h,
@@ -63,6 +63,13 @@ ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
     macd_hist = EXCLUDED.macd_hist,
     bb_pos = EXCLUDED.bb_pos,
     bb_width = EXCLUDED.bb_width,
+    book_imbalance = EXCLUDED.book_imbalance,
+    book_spread_bps = EXCLUDED.book_spread_bps,
+    funding_rate = EXCLUDED.funding_rate,
+    oi_change_24h = EXCLUDED.oi_change_24h,
+    btc_dominance = EXCLUDED.btc_dominance,
+    mcap_change_24h = EXCLUDED.mcap_change_24h,
+    event_proximity = EXCLUDED.event_proximity,
     target_up_4h = EXCLUDED.target_up_4h,
     updated_at = NOW()`,
 			row.Symbol,
@@ -81,6 +88,13 @@ ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
 			row.MACDHist,
 			row.BBPos,
 			row.BBWidth,
+			row.BookImbalance,
+			row.BookSpreadBps,
+			row.FundingRate,
+			row.OIChange24H,
+			row.BTCDominance,
+			row.MarketCapChange24H,
+			row.EventProximity,
 			row.TargetUp4H,
 		)
 		if err != nil {
@@ -99,7 +113,7 @@ SELECT symbol, interval, open_time,
        ret_1h, ret_4h, ret_12h, ret_24h,
        volatility_6h, volatility_24h, volume_z_24h,
        rsi_14, macd_line, macd_signal, macd_hist,
-       bb_pos, bb_width, target_up_4h, created_at, updated_at
+       bb_pos, bb_width, book_imbalance, book_spread_bps, funding_rate, oi_change_24h, btc_dominance, mcap_change_24h, event_proximity, target_up_4h, created_at, updated_at
 FROM ml_feature_rows
 WHERE interval = $1
   AND open_time >= $2
@@ -123,7 +137,7 @@ SELECT symbol, interval, open_time,
        ret_1h, ret_4h, ret_12h, ret_24h,
        volatility_6h, volatility_24h, volume_z_24h,
        rsi_14, macd_line, macd_signal, macd_hist,
-       bb_pos, bb_width, target_up_4h, created_at, updated_at
+       bb_pos, bb_width, book_imbalance, book_spread_bps, funding_rate, oi_change_24h, btc_dominance, mcap_change_24h, event_proximity, target_up_4h, created_at, updated_at
 FROM ml_feature_rows
 WHERE interval = $1
   AND open_time >= $2
@@ -147,7 +161,7 @@ SELECT DISTINCT ON (symbol)
        ret_1h, ret_4h, ret_12h, ret_24h,
        volatility_6h, volatility_24h, volume_z_24h,
        rsi_14, macd_line, macd_signal, macd_hist,
-       bb_pos, bb_width, target_up_4h, created_at, updated_at
+       bb_pos, bb_width, book_imbalance, book_spread_bps, funding_rate, oi_change_24h, btc_dominance, mcap_change_24h, event_proximity, target_up_4h, created_at, updated_at
 FROM ml_feature_rows
 WHERE interval = $1
 ORDER BY symbol, open_time DESC`, interval)
@@ -181,6 +195,13 @@ func scanFeatureRows(rows pgx.Rows) ([]domain.MLFeatureRow, error) {
 			&row.MACDHist,
 			&row.BBPos,
 			&row.BBWidth,
+			&row.BookImbalance,
+			&row.BookSpreadBps,
+			&row.FundingRate,
+			&row.OIChange24H,
+			&row.BTCDominance,
+			&row.MarketCapChange24H,
+			&row.EventProximity,
 			&target,
 			&row.CreatedAt,
 			&row.UpdatedAt,
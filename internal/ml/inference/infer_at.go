@@ -0,0 +1,247 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/ensemble"
+	iforestmodel "bug-free-umbrella/internal/ml/models/iforest"
+	"bug-free-umbrella/internal/ml/models/logreg"
+	"bug-free-umbrella/internal/ml/models/xgboost"
+	"bug-free-umbrella/pkg/domain"
+)
+
+// ErrNoFeatureRow is returned by InferAt when no feature row was computed
+// for the requested candle.
+var ErrNoFeatureRow = errors.New("no feature row for that candle")
+
+// HistoricalFeatureReader is implemented by feature stores that can read
+// past rows. InferAt needs it.
+type HistoricalFeatureReader interface {
+	ListRows(ctx context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error)
+}
+
+// HistoricalModelRegistry is implemented by registries that keep the
+// promotion history. InferAt needs it.
+type HistoricalModelRegistry interface {
+	GetModelActiveAt(ctx context.Context, modelKey string, at time.Time) (*domain.MLModelVersion, error)
+}
+
+// ModelOutput is one model's score in a point-in-time inference.
+type ModelOutput struct {
+	ModelKey  string                 `json:"model_key"`
+	Version   int                    `json:"version"`
+	ProbUp    float64                `json:"prob_up"`
+	Direction domain.SignalDirection `json:"direction"`
+}
+
+// EnsembleOutput is the ensemble call a point-in-time inference arrives at.
+type EnsembleOutput struct {
+	Score      float64                `json:"score"`
+	ProbUp     float64                `json:"prob_up"`
+	Confidence float64                `json:"confidence"`
+	Direction  domain.SignalDirection `json:"direction"`
+	Risk       domain.RiskLevel       `json:"risk"`
+	HeldReason string                 `json:"held_reason,omitempty"`
+	// Uncertainty is the spread of the directional models' scores, and
+	// HoldBand the hold band it widened.
+	Uncertainty float64 `json:"uncertainty"`
+	HoldBand    float64 `json:"hold_band"`
+}
+
+// PointInTime is what inference would have produced for one candle, using
+// the models that were active when At passed and the candle's stored
+// features. Models not yet promoted at At are missing from Models; Ensemble
+// is nil when neither directional model was.
+type PointInTime struct {
+	Symbol       string             `json:"symbol"`
+	Interval     string             `json:"interval"`
+	At           time.Time          `json:"at"`
+	OpenTime     time.Time          `json:"open_time"`
+	TargetTime   time.Time          `json:"target_time"`
+	Features     map[string]float64 `json:"features"`
+	Models       []ModelOutput      `json:"models"`
+	AnomalyScore *float64           `json:"anomaly_score,omitempty"`
+	DampFactor   float64            `json:"damp_factor"`
+	ClassicScore float64            `json:"classic_score"`
+	FearGreed    *int               `json:"fear_greed,omitempty"`
+	Ensemble     *EnsembleOutput    `json:"ensemble,omitempty"`
+}
+
+// InferAt replays inference for the candle of symbol and interval that
+// contains at. Nothing is persisted and no alerts are sent. Only the
+// configured directional interval gets directional models and an ensemble,
+// as in RunLatest.
+func (s *Service) InferAt(ctx context.Context, symbol, interval string, at time.Time) (*PointInTime, error) {
+	ctx, span := s.tracer.Start(ctx, "ml-inference.infer-at")
+	defer span.End()
+
+	features, ok := s.features.(HistoricalFeatureReader)
+	if !ok {
+		return nil, errors.New("feature store cannot read past rows")
+	}
+	registry, ok := s.registry.(HistoricalModelRegistry)
+	if !ok {
+		return nil, errors.New("model registry has no promotion history")
+	}
+	step := domain.IntervalDuration(interval)
+	if step == 0 {
+		return nil, fmt.Errorf("unsupported interval: %s", interval)
+	}
+
+	at = at.UTC()
+	openTime := at.Truncate(step)
+	rows, err := features.ListRows(ctx, interval, openTime, openTime)
+	if err != nil {
+		return nil, err
+	}
+	var row *domain.MLFeatureRow
+	for i := range rows {
+		if rows[i].Symbol == symbol && rows[i].OpenTime.Equal(openTime) {
+			row = &rows[i]
+			break
+		}
+	}
+	if row == nil {
+		return nil, fmt.Errorf("%w: %s %s at %s", ErrNoFeatureRow, symbol, interval, openTime.Format(time.RFC3339))
+	}
+
+	vector := common.FeatureVector(*row)
+	out := &PointInTime{
+		Symbol:     symbol,
+		Interval:   interval,
+		At:         at,
+		OpenTime:   openTime,
+		TargetTime: openTime.Add(time.Duration(s.cfg.TargetHours) * time.Hour),
+		Features:   make(map[string]float64, len(vector)),
+		Models:     []ModelOutput{},
+		DampFactor: 1,
+	}
+	for i, name := range common.FeatureNames {
+		if i < len(vector) {
+			out.Features[name] = vector[i]
+		}
+	}
+
+	if s.cfg.EnableIForest {
+		version, predict, err := loadActiveAt(ctx, registry, common.IForestModelKey(interval), at)
+		if err != nil {
+			return nil, err
+		}
+		if predict != nil {
+			score := common.Clamp01(predict(vector))
+			out.AnomalyScore = &score
+			out.DampFactor = s.dampFactor(score)
+			out.Models = append(out.Models, ModelOutput{ModelKey: common.IForestModelKey(interval), Version: version, ProbUp: score, Direction: domain.DirectionHold})
+		}
+	}
+	if interval != s.cfg.Interval {
+		return out, nil
+	}
+
+	probs := map[string]float64{common.ModelKeyLogReg: 0.5, common.ModelKeyXGBoost: 0.5}
+	var scored []float64
+	directional := 0
+	for _, key := range []string{common.ModelKeyLogReg, common.ModelKeyXGBoost} {
+		version, predict, err := loadActiveAt(ctx, registry, key, at)
+		if err != nil {
+			return nil, err
+		}
+		if predict == nil {
+			continue
+		}
+		prob := common.Clamp01(predict(vector))
+		probs[key] = prob
+		scored = append(scored, prob)
+		directional++
+		out.Models = append(out.Models, ModelOutput{
+			ModelKey:  key,
+			Version:   version,
+			ProbUp:    prob,
+			Direction: common.DirectionFromProb(prob, s.cfg.LongThreshold, s.cfg.ShortThreshold),
+		})
+	}
+	if directional == 0 {
+		return out, nil
+	}
+
+	anomalyScore := 0.0
+	if out.AnomalyScore != nil {
+		anomalyScore = *out.AnomalyScore
+	}
+	out.ClassicScore = s.classicScore(ctx, *row)
+	out.FearGreed = s.fearGreedAt(ctx, row.OpenTime)
+	undamped := s.ensemble.Score(ensemble.Components{
+		ClassicScore: out.ClassicScore,
+		LogRegProb:   probs[common.ModelKeyLogReg],
+		XGBoostProb:  probs[common.ModelKeyXGBoost],
+		FearGreed:    out.FearGreed,
+	})
+	score := undamped * out.DampFactor
+	if score > 1 {
+		score = 1
+	}
+	if score < -1 {
+		score = -1
+	}
+	prob := common.Clamp01((score + 1) / 2)
+	confidence := common.Confidence(prob)
+	risk := common.RiskFromConfidence(confidence)
+	if anomalyScore >= s.cfg.AnomalyThreshold {
+		risk = riskBump(risk, 1)
+	}
+	spread := uncertainty(scored)
+	band := s.holdBand(spread)
+	out.Ensemble = &EnsembleOutput{
+		Score:       score,
+		ProbUp:      prob,
+		Confidence:  confidence,
+		Direction:   ensemble.DirectionWithBand(score, band),
+		Risk:        risk,
+		Uncertainty: spread,
+		HoldBand:    band,
+	}
+	if out.Ensemble.Direction == domain.DirectionHold {
+		switch {
+		case ensemble.Direction(score) != domain.DirectionHold:
+			out.Ensemble.HeldReason = domain.HeldReasonUncertainty
+		case ensemble.Direction(undamped) != domain.DirectionHold:
+			out.Ensemble.HeldReason = domain.HeldReasonAnomalyDamping
+		}
+	}
+	return out, nil
+}
+
+// loadActiveAt returns the scoring function of the version of modelKey that
+// was active at the given time, or a nil function when none was.
+func loadActiveAt(ctx context.Context, registry HistoricalModelRegistry, modelKey string, at time.Time) (int, func([]float64) float64, error) {
+	model, err := registry.GetModelActiveAt(ctx, modelKey, at)
+	if err != nil || model == nil {
+		return 0, nil, err
+	}
+	switch {
+	case modelKey == common.ModelKeyLogReg:
+		m, err := logreg.UnmarshalBinary(model.ArtifactBlob)
+		if err != nil {
+			return 0, nil, fmt.Errorf("load %s v%d: %w", modelKey, model.Version, err)
+		}
+		return model.Version, common.ForFeatureCount(len(m.FeatureNames()), m.PredictProb), nil
+	case modelKey == common.ModelKeyXGBoost:
+		m, err := xgboost.UnmarshalBinary(model.ArtifactBlob)
+		if err != nil {
+			return 0, nil, fmt.Errorf("load %s v%d: %w", modelKey, model.Version, err)
+		}
+		return model.Version, common.ForFeatureCount(len(m.FeatureNames()), m.PredictProb), nil
+	case common.IsIForestModelKey(modelKey):
+		m, err := iforestmodel.UnmarshalBinary(model.ArtifactBlob)
+		if err != nil {
+			return 0, nil, fmt.Errorf("load %s v%d: %w", modelKey, model.Version, err)
+		}
+		return model.Version, common.ForFeatureCount(len(m.FeatureNames()), m.PredictScore), nil
+	default:
+		return 0, nil, fmt.Errorf("unknown model key: %s", modelKey)
+	}
+}
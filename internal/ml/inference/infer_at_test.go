@@ -0,0 +1,157 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/models/logreg"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInferAtUsesModelsActiveAtTheTime(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	promoted := rowTS.Add(-48 * time.Hour)
+	features := &historicalFeatureStub{rows: []domain.MLFeatureRow{
+		makeFeatureRow("ETH", "1h", rowTS, -1.5),
+		makeFeatureRow("BTC", "1h", rowTS, 2.5),
+	}}
+	registry := &historicalRegistryStub{promotions: []historicalPromotion{
+		{at: promoted, model: domain.MLModelVersion{ModelKey: common.ModelKeyLogReg, Version: 1, ArtifactBlob: mustTrainLogRegBlob(t)}},
+		{at: promoted, model: domain.MLModelVersion{ModelKey: common.ModelKeyXGBoost, Version: 1, ArtifactBlob: mustTrainXGBBlob(t)}},
+		{at: promoted, model: domain.MLModelVersion{ModelKey: common.IForestModelKey("1h"), Version: 1, ArtifactBlob: mustTrainIForestBlob(t, "iforest_1h", "1h")}},
+		// Promoted after the candle, so it must not be used.
+		{at: rowTS.Add(24 * time.Hour), model: domain.MLModelVersion{ModelKey: common.ModelKeyLogReg, Version: 2, ArtifactBlob: []byte("not a model")}},
+	}}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, newPredictionStoreStub(), &signalStoreStub{}, nil, Config{
+		Interval:      "1h",
+		Intervals:     []string{"1h", "4h"},
+		EnableIForest: true,
+	})
+
+	out, err := svc.InferAt(context.Background(), "BTC", "1h", rowTS.Add(25*time.Minute))
+	if err != nil {
+		t.Fatalf("infer at: %v", err)
+	}
+	if !out.OpenTime.Equal(rowTS) || !out.TargetTime.Equal(rowTS.Add(4*time.Hour)) {
+		t.Fatalf("expected the containing candle, got open=%s target=%s", out.OpenTime, out.TargetTime)
+	}
+	if len(out.Models) != 3 || out.AnomalyScore == nil || out.Ensemble == nil {
+		t.Fatalf("expected iforest, logreg, xgboost and an ensemble, got %+v", out)
+	}
+	for _, m := range out.Models {
+		if m.Version != 1 {
+			t.Fatalf("expected the version active at the time, got %+v", m)
+		}
+	}
+	if out.Features["ret_1h"] != 2.5 || len(out.Features) != len(common.FeatureNames) {
+		t.Fatalf("expected the stored feature row, got %v", out.Features)
+	}
+	if len(features.rows) != 2 || len(registry.lookups) == 0 || !registry.lookups[0].Equal(rowTS.Add(25*time.Minute)) {
+		t.Fatalf("expected registry lookups at the requested time, got %v", registry.lookups)
+	}
+
+	if _, err := svc.InferAt(context.Background(), "BTC", "1h", rowTS.Add(-time.Hour)); !errors.Is(err, ErrNoFeatureRow) {
+		t.Fatalf("expected ErrNoFeatureRow, got %v", err)
+	}
+
+}
+
+func TestInferAtBeforeAnyPromotion(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	features := &historicalFeatureStub{rows: []domain.MLFeatureRow{makeFeatureRow("BTC", "4h", rowTS, 1)}}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, &historicalRegistryStub{}, newPredictionStoreStub(), &signalStoreStub{}, nil, Config{
+		Interval:      "1h",
+		EnableIForest: true,
+	})
+
+	out, err := svc.InferAt(context.Background(), "BTC", "4h", rowTS)
+	if err != nil {
+		t.Fatalf("infer at: %v", err)
+	}
+	if len(out.Models) != 0 || out.AnomalyScore != nil || out.Ensemble != nil || out.DampFactor != 1 {
+		t.Fatalf("expected features only, got %+v", out)
+	}
+
+	plain := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), &featureReaderStub{}, &modelRegistryStub{}, newPredictionStoreStub(), &signalStoreStub{}, nil, Config{})
+	if _, err := plain.InferAt(context.Background(), "BTC", "1h", rowTS); err == nil {
+		t.Fatal("expected an error without historical stores")
+	}
+}
+
+func TestInferAtScoresModelsTrainedBeforeSeasonality(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	samples, labels := directionalDataset()
+	for i := range samples {
+		samples[i] = samples[i][:common.MarketFeatureCount]
+	}
+	model, err := logreg.Train(samples, labels, common.FeatureNames[:common.MarketFeatureCount], logreg.DefaultTrainOptions())
+	if err != nil {
+		t.Fatalf("train logreg: %v", err)
+	}
+	blob, err := model.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal logreg: %v", err)
+	}
+	features := &historicalFeatureStub{rows: []domain.MLFeatureRow{makeFeatureRow("BTC", "1h", rowTS, 2.5)}}
+	registry := &historicalRegistryStub{promotions: []historicalPromotion{
+		{at: rowTS.Add(-time.Hour), model: domain.MLModelVersion{ModelKey: common.ModelKeyLogReg, Version: 1, ArtifactBlob: blob}},
+	}}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, newPredictionStoreStub(), &signalStoreStub{}, nil, Config{Interval: "1h"})
+
+	out, err := svc.InferAt(context.Background(), "BTC", "1h", rowTS)
+	if err != nil {
+		t.Fatalf("infer at: %v", err)
+	}
+	if len(out.Models) != 1 || out.Models[0].ProbUp <= 0.5 {
+		t.Fatalf("expected the older model to score the market features, got %+v", out.Models)
+	}
+}
+
+type historicalFeatureStub struct {
+	rows []domain.MLFeatureRow
+}
+
+func (s *historicalFeatureStub) ListLatestByInterval(_ context.Context, interval string) ([]domain.MLFeatureRow, error) {
+	return nil, nil
+}
+
+func (s *historicalFeatureStub) ListRows(_ context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error) {
+	var out []domain.MLFeatureRow
+	for _, r := range s.rows {
+		if r.Interval == interval && !r.OpenTime.Before(from) && !r.OpenTime.After(to) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+type historicalPromotion struct {
+	at    time.Time
+	model domain.MLModelVersion
+}
+
+type historicalRegistryStub struct {
+	promotions []historicalPromotion
+	lookups    []time.Time
+}
+
+func (s *historicalRegistryStub) GetActiveModel(_ context.Context, modelKey string) (*domain.MLModelVersion, error) {
+	return nil, nil
+}
+
+func (s *historicalRegistryStub) GetModelActiveAt(_ context.Context, modelKey string, at time.Time) (*domain.MLModelVersion, error) {
+	s.lookups = append(s.lookups, at)
+	var found *domain.MLModelVersion
+	for i := range s.promotions {
+		p := s.promotions[i]
+		if p.model.ModelKey == modelKey && !p.at.After(at) {
+			found = &p.model
+		}
+	}
+	return found, nil
+}
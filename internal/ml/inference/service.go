@@ -4,15 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math"
+	"strings"
+	"sync"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/ml/common"
 	"bug-free-umbrella/internal/ml/ensemble"
 	iforestmodel "bug-free-umbrella/internal/ml/models/iforest"
 	"bug-free-umbrella/internal/ml/models/logreg"
 	"bug-free-umbrella/internal/ml/models/xgboost"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -30,6 +33,47 @@ type PredictionStore interface {
 	AttachSignalID(ctx context.Context, predictionID, signalID int64) error
 }
 
+// PinnedModelReader is implemented by registries that load any stored
+// version. Pinned models need it.
+type PinnedModelReader interface {
+	GetModelVersion(ctx context.Context, modelKey string, version int) (*domain.MLModelVersion, error)
+}
+
+// PromotedModelLister is implemented by registries that keep the promotion
+// history. Rolling model ensembles need it.
+type PromotedModelLister interface {
+	ListPromotedModels(ctx context.Context, modelKey string, limit int) ([]domain.MLModelVersion, error)
+}
+
+// AnomalyScoreRecorder is implemented by prediction stores that also keep
+// the compact anomaly score series.
+type AnomalyScoreRecorder interface {
+	RecordAnomalyScore(ctx context.Context, score domain.AnomalyScore) error
+}
+
+// HeldAlerter announces ensemble calls that anomaly damping held back. The
+// Telegram alert dispatcher satisfies it.
+type HeldAlerter interface {
+	Broadcast(ctx context.Context, message string) (int, error)
+}
+
+// PredictionPublisher receives each stored prediction and ML signal for
+// export to a message broker.
+type PredictionPublisher interface {
+	PublishPrediction(ctx context.Context, pred domain.MLPrediction)
+	PublishSignals(ctx context.Context, signals []domain.Signal)
+}
+
+// FearGreedSource reads the stored Crypto Fear & Greed history. The market
+// intel repository satisfies it.
+type FearGreedSource interface {
+	FearGreedAt(ctx context.Context, at time.Time) (*domain.FearGreedReading, error)
+}
+
+// FearGreedMaxAge is how old the newest index value may be, relative to the
+// candle, and still count toward the ensemble. The index updates daily.
+const FearGreedMaxAge = 48 * time.Hour
+
 type SignalStore interface {
 	InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error)
 	ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error)
@@ -44,6 +88,15 @@ type Config struct {
 	EnableIForest    bool
 	AnomalyThreshold float64
 	AnomalyDampMax   float64
+	// PinnedVersions maps model keys to the version to score with instead
+	// of the registry's active one.
+	PinnedVersions map[string]int
+	// RollingVersions maps model keys to how many of their most recently
+	// promoted versions to average. A pinned version takes precedence.
+	RollingVersions map[string]int
+	// UncertaintyBandWeight widens the ensemble's hold band by this much per
+	// unit of model disagreement; 0 keeps the fixed band.
+	UncertaintyBandWeight float64
 }
 
 type Service struct {
@@ -54,6 +107,15 @@ type Service struct {
 	signals     SignalStore
 	ensemble    *ensemble.Service
 	cfg         Config
+
+	heldAlerts HeldAlerter
+	publisher  PredictionPublisher
+	fearGreed  FearGreedSource
+	heldMu     sync.Mutex
+	heldSeen   map[string]time.Time // symbol/interval -> open time last announced
+
+	rollingMu sync.Mutex
+	rolling   map[string][]int // model key -> versions averaged in the last load
 }
 
 type RunResult struct {
@@ -91,6 +153,9 @@ func NewService(
 	if cfg.AnomalyDampMax < 0 || cfg.AnomalyDampMax > 1 {
 		cfg.AnomalyDampMax = 0.65
 	}
+	if cfg.UncertaintyBandWeight < 0 {
+		cfg.UncertaintyBandWeight = 0
+	}
 	if ensembleSvc == nil {
 		ensembleSvc = ensemble.NewService()
 	}
@@ -105,6 +170,23 @@ func NewService(
 	}
 }
 
+// SetHeldAlerts announces each candle whose ensemble call was held by anomaly
+// damping, once per symbol and interval.
+func (s *Service) SetHeldAlerts(alerts HeldAlerter) {
+	s.heldAlerts = alerts
+}
+
+// SetPredictionPublisher streams every stored prediction and ML signal to p.
+func (s *Service) SetPredictionPublisher(p PredictionPublisher) {
+	s.publisher = p
+}
+
+// SetFearGreedSource blends the Fear & Greed value at each candle into the
+// ensemble score.
+func (s *Service) SetFearGreedSource(source FearGreedSource) {
+	s.fearGreed = source
+}
+
 func (s *Service) RunLatest(ctx context.Context, now time.Time) (RunResult, error) {
 	_, span := s.tracer.Start(ctx, "ml-inference.run-latest")
 	defer span.End()
@@ -140,13 +222,16 @@ func (s *Service) RunLatest(ctx context.Context, now time.Time) (RunResult, erro
 
 		for i := range rows {
 			row := rows[i]
+			if domain.MLPaused(row.Symbol) {
+				continue
+			}
 			targetTime := row.OpenTime.UTC().Add(time.Duration(s.cfg.TargetHours) * time.Hour)
 			features := common.FeatureVector(row)
 			anomalyScore := 0.0
 			dampFactor := 1.0
 
 			if iforestPredict != nil {
-				anomalyScore = common.Clamp01(iforestPredict(features))
+				anomalyScore, _ = meanSpread(iforestPredict(features))
 				dampFactor = s.dampFactor(anomalyScore)
 				pred, err := s.persistAnomalyPrediction(ctx, row, iforestVersion, anomalyScore, targetTime, dampFactor)
 				if err != nil {
@@ -155,6 +240,7 @@ func (s *Service) RunLatest(ctx context.Context, now time.Time) (RunResult, erro
 				if pred != nil {
 					result.Predictions++
 				}
+				s.recordAnomalyScore(ctx, row, anomalyScore, dampFactor)
 			}
 
 			if row.Interval != s.cfg.Interval || (logPredict == nil && xgbPredict == nil) {
@@ -162,12 +248,15 @@ func (s *Service) RunLatest(ctx context.Context, now time.Time) (RunResult, erro
 			}
 
 			classicScore := s.classicScore(ctx, row)
+			fearGreed := s.fearGreedAt(ctx, row.OpenTime)
 			logProb := 0.5
 			xgbProb := 0.5
+			var logScores, xgbScores []float64
 
 			if logPredict != nil {
-				logProb = common.Clamp01(logPredict(features))
-				pred, hasSignal, err := s.persistModelPrediction(ctx, row, common.ModelKeyLogReg, logVersion, logProb, targetTime, 0, anomalyScore, dampFactor)
+				logScores = logPredict(features)
+				logProb, _ = meanSpread(logScores)
+				pred, hasSignal, err := s.persistModelPrediction(ctx, row, common.ModelKeyLogReg, logVersion, logProb, targetTime, 0, 0, anomalyScore, dampFactor, uncertainty(logScores), nil)
 				if err != nil {
 					return result, err
 				}
@@ -180,8 +269,9 @@ func (s *Service) RunLatest(ctx context.Context, now time.Time) (RunResult, erro
 			}
 
 			if xgbPredict != nil {
-				xgbProb = common.Clamp01(xgbPredict(features))
-				pred, hasSignal, err := s.persistModelPrediction(ctx, row, common.ModelKeyXGBoost, xgbVersion, xgbProb, targetTime, 0, anomalyScore, dampFactor)
+				xgbScores = xgbPredict(features)
+				xgbProb, _ = meanSpread(xgbScores)
+				pred, hasSignal, err := s.persistModelPrediction(ctx, row, common.ModelKeyXGBoost, xgbVersion, xgbProb, targetTime, 0, 0, anomalyScore, dampFactor, uncertainty(xgbScores), nil)
 				if err != nil {
 					return result, err
 				}
@@ -193,24 +283,20 @@ func (s *Service) RunLatest(ctx context.Context, now time.Time) (RunResult, erro
 				}
 			}
 
-			ensembleScore := s.ensemble.Score(ensemble.Components{
+			components := ensemble.Components{
 				ClassicScore: classicScore,
 				LogRegProb:   logProb,
 				XGBoostProb:  xgbProb,
-			})
-			ensembleScore *= dampFactor
-			if ensembleScore > 1 {
-				ensembleScore = 1
-			}
-			if ensembleScore < -1 {
-				ensembleScore = -1
+				FearGreed:    fearGreed,
 			}
+			undampedScore := s.ensemble.Score(components)
+			ensembleScore := ensemble.Damp(undampedScore, dampFactor)
 			ensembleProb := common.Clamp01((ensembleScore + 1) / 2)
 			version := max(logVersion, xgbVersion)
 			if version <= 0 {
 				version = 1
 			}
-			pred, hasSignal, err := s.persistModelPrediction(ctx, row, common.ModelKeyEnsembleV1, version, ensembleProb, targetTime, ensembleScore, anomalyScore, dampFactor)
+			pred, hasSignal, err := s.persistModelPrediction(ctx, row, common.ModelKeyEnsembleV1, version, ensembleProb, targetTime, ensembleScore, undampedScore, anomalyScore, dampFactor, uncertainty(logScores, xgbScores), &components)
 			if err != nil {
 				return result, err
 			}
@@ -234,19 +320,59 @@ func (s *Service) persistModelPrediction(
 	probUp float64,
 	targetTime time.Time,
 	ensembleScore float64,
+	undampedScore float64,
 	anomalyScore float64,
 	dampFactor float64,
+	uncertainty float64,
+	components *ensemble.Components,
 ) (*domain.MLPrediction, bool, error) {
 	confidence := common.Confidence(probUp)
 	direction := common.DirectionFromProb(probUp, s.cfg.LongThreshold, s.cfg.ShortThreshold)
+	holdBand := s.holdBand(uncertainty)
 	if modelKey == common.ModelKeyEnsembleV1 {
-		direction = ensemble.Direction(ensembleScore)
+		direction = ensemble.DirectionWithBand(ensembleScore, holdBand)
 	}
 	risk := common.RiskFromConfidence(confidence)
 	if modelKey == common.ModelKeyEnsembleV1 && anomalyScore >= s.cfg.AnomalyThreshold {
 		risk = riskBump(risk, 1)
 	}
-	detailsJSON := s.buildDetailsJSON(modelKey, modelVersion, probUp, confidence, ensembleScore, anomalyScore, dampFactor)
+	// A Hold the fixed band or the undamped score would have called
+	// directional is a suppressed call, not an absent one.
+	var undampedDirection, uncertainDirection domain.SignalDirection
+	if modelKey == common.ModelKeyEnsembleV1 && direction == domain.DirectionHold {
+		if d := ensemble.Direction(ensembleScore); d != domain.DirectionHold {
+			uncertainDirection = d
+		} else if d := ensemble.Direction(undampedScore); d != domain.DirectionHold {
+			undampedDirection = d
+		}
+	}
+	detailsJSON := s.buildDetailsJSON(modelKey, modelVersion, probUp, confidence, ensembleScore, anomalyScore, dampFactor, uncertainty, holdBand)
+	if components != nil {
+		// The raw inputs let GET /api/predictions/{id}/breakdown rebuild the
+		// score later.
+		detailsJSON = withDetails(detailsJSON, map[string]any{
+			"classic_score": roundFloat(components.ClassicScore),
+			"logreg_prob":   roundFloat(components.LogRegProb),
+			"xgboost_prob":  roundFloat(components.XGBoostProb),
+			"damp_factor":   roundFloat(dampFactor),
+		})
+		if components.FearGreed != nil {
+			detailsJSON = withDetails(detailsJSON, map[string]any{"fear_greed": *components.FearGreed})
+		}
+	}
+	switch {
+	case uncertainDirection != "":
+		detailsJSON = withDetails(detailsJSON, map[string]any{
+			"held_reason":    domain.HeldReasonUncertainty,
+			"held_direction": string(uncertainDirection),
+		})
+	case undampedDirection != "":
+		detailsJSON = withDetails(detailsJSON, map[string]any{
+			"held_reason":        domain.HeldReasonAnomalyDamping,
+			"undamped_direction": string(undampedDirection),
+			"undamped_score":     roundFloat(undampedScore),
+		})
+	}
 
 	pred, err := s.predictions.UpsertPrediction(ctx, domain.MLPrediction{
 		Symbol:       row.Symbol,
@@ -266,10 +392,14 @@ func (s *Service) persistModelPrediction(
 	}
 
 	if direction == domain.DirectionHold {
+		if undampedDirection != "" {
+			s.announceHeld(ctx, row, undampedDirection, anomalyScore, dampFactor)
+		}
+		s.publishPrediction(ctx, pred)
 		return pred, false, nil
 	}
 	indicator := indicatorForModelKey(modelKey)
-	signalDetails := signalDetails(modelKey, modelVersion, probUp, confidence, ensembleScore, anomalyScore, dampFactor)
+	signalDetails := signalDetails(modelKey, modelVersion, probUp, confidence, ensembleScore, anomalyScore, dampFactor, uncertainty)
 	persistedSignals, err := s.signals.InsertSignals(ctx, []domain.Signal{{
 		Symbol:    row.Symbol,
 		Interval:  row.Interval,
@@ -278,6 +408,7 @@ func (s *Service) persistModelPrediction(
 		Risk:      risk,
 		Direction: direction,
 		Details:   signalDetails,
+		Version:   signalVersion(modelKey, modelVersion),
 	}})
 	if err != nil {
 		return pred, false, err
@@ -286,10 +417,21 @@ func (s *Service) persistModelPrediction(
 		if err := s.predictions.AttachSignalID(ctx, pred.ID, persistedSignals[0].ID); err != nil {
 			return pred, false, err
 		}
+		pred.SignalID = &persistedSignals[0].ID
+	}
+	s.publishPrediction(ctx, pred)
+	if s.publisher != nil {
+		s.publisher.PublishSignals(ctx, persistedSignals)
 	}
 	return pred, true, nil
 }
 
+func (s *Service) publishPrediction(ctx context.Context, pred *domain.MLPrediction) {
+	if s.publisher != nil && pred != nil {
+		s.publisher.PublishPrediction(ctx, *pred)
+	}
+}
+
 func (s *Service) persistAnomalyPrediction(
 	ctx context.Context,
 	row domain.MLFeatureRow,
@@ -301,7 +443,7 @@ func (s *Service) persistAnomalyPrediction(
 	risk := riskFromAnomalyScore(anomalyScore)
 	detailsJSON := s.buildAnomalyDetailsJSON(row.Interval, modelVersion, anomalyScore, dampFactor)
 
-	return s.predictions.UpsertPrediction(ctx, domain.MLPrediction{
+	pred, err := s.predictions.UpsertPrediction(ctx, domain.MLPrediction{
 		Symbol:       row.Symbol,
 		Interval:     row.Interval,
 		OpenTime:     row.OpenTime.UTC(),
@@ -314,49 +456,232 @@ func (s *Service) persistAnomalyPrediction(
 		Risk:         risk,
 		DetailsJSON:  detailsJSON,
 	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishPrediction(ctx, pred)
+	return pred, nil
 }
 
-func (s *Service) loadLogReg(ctx context.Context) (int, func([]float64) float64, error) {
-	active, err := s.registry.GetActiveModel(ctx, common.ModelKeyLogReg)
-	if err != nil || active == nil {
-		return 0, nil, err
+// recordAnomalyScore appends to the anomaly series when the store keeps one.
+// The series only feeds history views, so failures are logged.
+func (s *Service) recordAnomalyScore(ctx context.Context, row domain.MLFeatureRow, anomalyScore, dampFactor float64) {
+	recorder, ok := s.predictions.(AnomalyScoreRecorder)
+	if !ok {
+		return
+	}
+	err := recorder.RecordAnomalyScore(ctx, domain.AnomalyScore{
+		Symbol:     row.Symbol,
+		Interval:   row.Interval,
+		OpenTime:   row.OpenTime.UTC(),
+		Score:      anomalyScore,
+		DampFactor: dampFactor,
+	})
+	if err != nil {
+		log.Printf("record anomaly score for %s %s: %v", row.Symbol, row.Interval, err)
+	}
+}
+
+// loadModel returns the pinned version of modelKey when one is configured
+// and the active version otherwise. A missing pinned version is an error
+// rather than a silent fallback to the active one.
+func (s *Service) loadModel(ctx context.Context, modelKey string) (*domain.MLModelVersion, error) {
+	version, ok := s.cfg.PinnedVersions[modelKey]
+	if !ok {
+		return s.registry.GetActiveModel(ctx, modelKey)
+	}
+	reader, ok := s.registry.(PinnedModelReader)
+	if !ok {
+		return nil, fmt.Errorf("%s is pinned to v%d but the model registry cannot load stored versions", modelKey, version)
 	}
-	model, err := logreg.UnmarshalBinary(active.ArtifactBlob)
+	model, err := reader.GetModelVersion(ctx, modelKey, version)
 	if err != nil {
-		return 0, nil, err
+		return nil, err
+	}
+	if model == nil {
+		return nil, fmt.Errorf("%s is pinned to v%d, which is not in the registry", modelKey, version)
 	}
-	return active.Version, model.PredictProb, nil
+	return model, nil
 }
 
-func (s *Service) loadXGBoost(ctx context.Context) (int, func([]float64) float64, error) {
-	active, err := s.registry.GetActiveModel(ctx, common.ModelKeyXGBoost)
-	if err != nil || active == nil {
-		return 0, nil, err
+// loadModels returns the versions of modelKey to score with, newest first.
+// With a rolling ensemble configured and no pin, that is the last N promoted
+// versions; a model never promoted falls back to the single loadModel pick.
+func (s *Service) loadModels(ctx context.Context, modelKey string) ([]domain.MLModelVersion, error) {
+	n := s.cfg.RollingVersions[modelKey]
+	if _, pinned := s.cfg.PinnedVersions[modelKey]; pinned || n <= 1 {
+		model, err := s.loadModel(ctx, modelKey)
+		if err != nil || model == nil {
+			return nil, err
+		}
+		return []domain.MLModelVersion{*model}, nil
 	}
-	model, err := xgboost.UnmarshalBinary(active.ArtifactBlob)
+	lister, ok := s.registry.(PromotedModelLister)
+	if !ok {
+		return nil, fmt.Errorf("%s averages %d versions but the model registry has no promotion history", modelKey, n)
+	}
+	models, err := lister.ListPromotedModels(ctx, modelKey, n)
 	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		model, err := s.loadModel(ctx, modelKey)
+		if err != nil || model == nil {
+			return nil, err
+		}
+		return []domain.MLModelVersion{*model}, nil
+	}
+	return models, nil
+}
+
+// scorer returns the score of each version a model averages, newest first.
+type scorer func(features []float64) []float64
+
+// loadScorer decodes every version loadModels picks for modelKey and returns
+// the newest version with a function scoring each of them.
+func (s *Service) loadScorer(ctx context.Context, modelKey string, decode func(blob []byte) (func([]float64) float64, error)) (int, scorer, error) {
+	models, err := s.loadModels(ctx, modelKey)
+	if err != nil || len(models) == 0 {
+		s.setRolling(modelKey, nil)
 		return 0, nil, err
 	}
-	return active.Version, model.PredictProb, nil
+	scorers := make([]func([]float64) float64, 0, len(models))
+	versions := make([]int, 0, len(models))
+	for i := range models {
+		scorer, err := decode(models[i].ArtifactBlob)
+		if err != nil {
+			return 0, nil, fmt.Errorf("load %s v%d: %w", modelKey, models[i].Version, err)
+		}
+		scorers = append(scorers, scorer)
+		versions = append(versions, models[i].Version)
+	}
+	if len(scorers) == 1 {
+		s.setRolling(modelKey, nil)
+	} else {
+		s.setRolling(modelKey, versions)
+	}
+	return versions[0], func(features []float64) []float64 {
+		scores := make([]float64, len(scorers))
+		for i, score := range scorers {
+			scores[i] = common.Clamp01(score(features))
+		}
+		return scores
+	}, nil
 }
 
-func (s *Service) loadIForest(ctx context.Context, interval string) (int, func([]float64) float64, error) {
+// meanSpread returns the mean of scores and their standard deviation.
+func meanSpread(scores []float64) (mean, spread float64) {
+	if len(scores) == 0 {
+		return 0.5, 0
+	}
+	for _, v := range scores {
+		mean += v
+	}
+	mean /= float64(len(scores))
+	for _, v := range scores {
+		spread += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(spread / float64(len(scores)))
+}
+
+// uncertainty measures how much the given model scores disagree: the
+// standard deviation of the probabilities mapped to the ensemble's -1..1
+// score scale. One score alone has no disagreement to measure.
+func uncertainty(probs ...[]float64) float64 {
+	var all []float64
+	for _, p := range probs {
+		all = append(all, p...)
+	}
+	_, spread := meanSpread(all)
+	return 2 * spread
+}
+
+// holdBand is the ensemble hold band widened by model disagreement.
+func (s *Service) holdBand(uncertainty float64) float64 {
+	return ensemble.HoldBand + s.cfg.UncertaintyBandWeight*uncertainty
+}
+
+func (s *Service) setRolling(modelKey string, versions []int) {
+	s.rollingMu.Lock()
+	defer s.rollingMu.Unlock()
+	if s.rolling == nil {
+		s.rolling = make(map[string][]int)
+	}
+	if len(versions) == 0 {
+		delete(s.rolling, modelKey)
+		return
+	}
+	s.rolling[modelKey] = versions
+}
+
+// rollingVersions returns the versions averaged for modelKey, or nil when
+// it scored with a single version.
+func (s *Service) rollingVersions(modelKey string) []int {
+	s.rollingMu.Lock()
+	defer s.rollingMu.Unlock()
+	return s.rolling[modelKey]
+}
+
+// isPinned reports whether version is the pinned version of modelKey.
+func (s *Service) isPinned(modelKey string, version int) bool {
+	pinned, ok := s.cfg.PinnedVersions[modelKey]
+	return ok && pinned == version
+}
+
+func (s *Service) loadLogReg(ctx context.Context) (int, scorer, error) {
+	return s.loadScorer(ctx, common.ModelKeyLogReg, func(blob []byte) (func([]float64) float64, error) {
+		model, err := logreg.UnmarshalBinary(blob)
+		if err != nil {
+			return nil, err
+		}
+		return common.ForFeatureCount(len(model.FeatureNames()), model.PredictProb), nil
+	})
+}
+
+func (s *Service) loadXGBoost(ctx context.Context) (int, scorer, error) {
+	return s.loadScorer(ctx, common.ModelKeyXGBoost, func(blob []byte) (func([]float64) float64, error) {
+		model, err := xgboost.UnmarshalBinary(blob)
+		if err != nil {
+			return nil, err
+		}
+		return common.ForFeatureCount(len(model.FeatureNames()), model.PredictProb), nil
+	})
+}
+
+func (s *Service) loadIForest(ctx context.Context, interval string) (int, scorer, error) {
 	if !s.cfg.EnableIForest {
 		return 0, nil, nil
 	}
-	active, err := s.registry.GetActiveModel(ctx, common.IForestModelKey(interval))
-	if err != nil || active == nil {
-		return 0, nil, err
+	return s.loadScorer(ctx, common.IForestModelKey(interval), func(blob []byte) (func([]float64) float64, error) {
+		model, err := iforestmodel.UnmarshalBinary(blob)
+		if err != nil {
+			return nil, err
+		}
+		return common.ForFeatureCount(len(model.FeatureNames()), model.PredictScore), nil
+	})
+}
+
+// fearGreedAt returns the Fear & Greed value published at or before at, or
+// nil without a source or a value within FearGreedMaxAge. Errors are logged
+// and score as unknown.
+func (s *Service) fearGreedAt(ctx context.Context, at time.Time) *int {
+	if s.fearGreed == nil {
+		return nil
 	}
-	model, err := iforestmodel.UnmarshalBinary(active.ArtifactBlob)
+	reading, err := s.fearGreed.FearGreedAt(ctx, at)
 	if err != nil {
-		return 0, nil, err
+		log.Printf("ml inference: fear & greed lookup: %v", err)
+		return nil
+	}
+	if reading == nil || at.Sub(reading.Timestamp) > FearGreedMaxAge {
+		return nil
 	}
-	return active.Version, model.PredictScore, nil
+	value := reading.Value
+	return &value
 }
 
 func (s *Service) classicScore(ctx context.Context, row domain.MLFeatureRow) float64 {
-	signals, err := s.signals.ListSignals(ctx, domain.SignalFilter{Symbol: row.Symbol, Limit: 100})
+	signals, err := s.signals.ListSignals(ctx, domain.SignalFilter{Symbol: row.Symbol, Until: row.OpenTime, Limit: 100})
 	if err != nil {
 		return 0
 	}
@@ -368,7 +693,7 @@ func (s *Service) classicScore(ctx context.Context, row domain.MLFeatureRow) flo
 		if sig.Interval != row.Interval || sig.Timestamp.UTC().Unix() != targetTS {
 			continue
 		}
-		if !isClassicIndicator(sig.Indicator) {
+		if !domain.IsClassicIndicator(sig.Indicator) {
 			continue
 		}
 		dir := 0.0
@@ -400,7 +725,7 @@ func (s *Service) classicScore(ctx context.Context, row domain.MLFeatureRow) flo
 	return score
 }
 
-func (s *Service) buildDetailsJSON(modelKey string, version int, probUp, confidence, ensembleScore, anomalyScore, dampFactor float64) string {
+func (s *Service) buildDetailsJSON(modelKey string, version int, probUp, confidence, ensembleScore, anomalyScore, dampFactor, uncertainty, holdBand float64) string {
 	payload := map[string]any{
 		"model_key":     modelKey,
 		"model_version": version,
@@ -410,6 +735,31 @@ func (s *Service) buildDetailsJSON(modelKey string, version int, probUp, confide
 	}
 	if modelKey == common.ModelKeyEnsembleV1 {
 		payload["ensemble_score"] = roundFloat(ensembleScore)
+		payload["uncertainty"] = roundFloat(uncertainty)
+		payload["hold_band"] = roundFloat(holdBand)
+		pinned := map[string]int{}
+		for _, key := range []string{common.ModelKeyLogReg, common.ModelKeyXGBoost} {
+			if v, ok := s.cfg.PinnedVersions[key]; ok {
+				pinned[key] = v
+			}
+		}
+		if len(pinned) > 0 {
+			payload["pinned_versions"] = pinned
+		}
+		rolling := map[string][]int{}
+		for _, key := range []string{common.ModelKeyLogReg, common.ModelKeyXGBoost} {
+			if versions := s.rollingVersions(key); len(versions) > 0 {
+				rolling[key] = versions
+			}
+		}
+		if len(rolling) > 0 {
+			payload["rolling_versions"] = rolling
+		}
+	} else if s.isPinned(modelKey, version) {
+		payload["pinned"] = true
+	} else if versions := s.rollingVersions(modelKey); len(versions) > 0 {
+		payload["rolling_versions"] = versions
+		payload["uncertainty"] = roundFloat(uncertainty)
 	}
 	if anomalyScore > 0 {
 		payload["anomaly_score"] = roundFloat(anomalyScore)
@@ -422,6 +772,51 @@ func (s *Service) buildDetailsJSON(modelKey string, version int, probUp, confide
 	return string(b)
 }
 
+// withDetails adds fields, such as the held_reason and the call a Hold
+// suppressed, to a prediction's details.
+func withDetails(detailsJSON string, fields map[string]any) string {
+	payload := map[string]any{}
+	if err := json.Unmarshal([]byte(detailsJSON), &payload); err != nil {
+		return detailsJSON
+	}
+	for k, v := range fields {
+		payload[k] = v
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return detailsJSON
+	}
+	return string(b)
+}
+
+// announceHeld broadcasts a held call once per candle. Inference reruns on
+// the same candle are not announced again.
+func (s *Service) announceHeld(ctx context.Context, row domain.MLFeatureRow, undamped domain.SignalDirection, anomalyScore, dampFactor float64) {
+	if s.heldAlerts == nil {
+		return
+	}
+	seenKey := row.Symbol + "/" + row.Interval
+	openTime := row.OpenTime.UTC()
+	s.heldMu.Lock()
+	if s.heldSeen == nil {
+		s.heldSeen = make(map[string]time.Time)
+	}
+	if last, ok := s.heldSeen[seenKey]; ok && !openTime.After(last) {
+		s.heldMu.Unlock()
+		return
+	}
+	s.heldSeen[seenKey] = openTime
+	s.heldMu.Unlock()
+
+	message := fmt.Sprintf(
+		"%s %s: ensemble %s call held by anomaly damping (anomaly score %.2f, confidence scaled by %.2f) for the candle at %s.",
+		row.Symbol, row.Interval, strings.ToUpper(string(undamped)), anomalyScore, dampFactor, openTime.Format("2006-01-02 15:04 UTC"),
+	)
+	if _, err := s.heldAlerts.Broadcast(ctx, message); err != nil {
+		log.Printf("held call alert for %s: %v", seenKey, err)
+	}
+}
+
 func (s *Service) buildAnomalyDetailsJSON(interval string, version int, anomalyScore, dampFactor float64) string {
 	payload := map[string]any{
 		"model_key":     common.IForestModelKey(interval),
@@ -431,6 +826,11 @@ func (s *Service) buildAnomalyDetailsJSON(interval string, version int, anomalyS
 		"damp_factor":   roundFloat(dampFactor),
 		"target":        "4h",
 	}
+	if s.isPinned(common.IForestModelKey(interval), version) {
+		payload["pinned"] = true
+	} else if versions := s.rollingVersions(common.IForestModelKey(interval)); len(versions) > 0 {
+		payload["rolling_versions"] = versions
+	}
 	b, err := json.Marshal(payload)
 	if err != nil {
 		return "{}"
@@ -438,17 +838,17 @@ func (s *Service) buildAnomalyDetailsJSON(interval string, version int, anomalyS
 	return string(b)
 }
 
-func signalDetails(modelKey string, version int, probUp, confidence, ensembleScore, anomalyScore, dampFactor float64) string {
+func signalDetails(modelKey string, version int, probUp, confidence, ensembleScore, anomalyScore, dampFactor, uncertainty float64) string {
 	if modelKey == common.ModelKeyEnsembleV1 {
 		if anomalyScore > 0 {
 			return fmt.Sprintf(
-				"model_key=%s;model_version=%d;prob_up=%.4f;confidence=%.4f;target=4h;ensemble_score=%.4f;anomaly_score=%.4f;damp_factor=%.4f",
-				modelKey, version, probUp, confidence, ensembleScore, anomalyScore, dampFactor,
+				"model_key=%s;model_version=%d;prob_up=%.4f;confidence=%.4f;target=4h;ensemble_score=%.4f;uncertainty=%.4f;anomaly_score=%.4f;damp_factor=%.4f",
+				modelKey, version, probUp, confidence, ensembleScore, uncertainty, anomalyScore, dampFactor,
 			)
 		}
 		return fmt.Sprintf(
-			"model_key=%s;model_version=%d;prob_up=%.4f;confidence=%.4f;target=4h;ensemble_score=%.4f",
-			modelKey, version, probUp, confidence, ensembleScore,
+			"model_key=%s;model_version=%d;prob_up=%.4f;confidence=%.4f;target=4h;ensemble_score=%.4f;uncertainty=%.4f",
+			modelKey, version, probUp, confidence, ensembleScore, uncertainty,
 		)
 	}
 	return fmt.Sprintf(
@@ -457,6 +857,11 @@ func signalDetails(modelKey string, version int, probUp, confidence, ensembleSco
 	)
 }
 
+// signalVersion ties an ML signal to the registry version that scored it.
+func signalVersion(modelKey string, version int) string {
+	return fmt.Sprintf("%s/v%d", modelKey, version)
+}
+
 func indicatorForModelKey(modelKey string) string {
 	switch modelKey {
 	case common.ModelKeyLogReg:
@@ -468,15 +873,6 @@ func indicatorForModelKey(modelKey string) string {
 	}
 }
 
-func isClassicIndicator(indicator string) bool {
-	switch indicator {
-	case domain.IndicatorRSI, domain.IndicatorMACD, domain.IndicatorBollinger, domain.IndicatorVolumeZ:
-		return true
-	default:
-		return false
-	}
-}
-
 func (s *Service) dampFactor(anomalyScore float64) float64 {
 	factor := 1 - (s.cfg.AnomalyDampMax * common.Clamp01(anomalyScore))
 	if factor < 0 {
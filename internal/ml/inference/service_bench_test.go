@@ -0,0 +1,67 @@
+package inference
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/loadtest"
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// inferenceRunBudget bounds one RunLatest pass over every supported symbol
+// on two intervals with all models active; the inference job runs it every
+// ML_INFER_POLL_SECS and must stay well clear of the poll period.
+const inferenceRunBudget = 50 * time.Millisecond
+
+func BenchmarkRunLatest(b *testing.B) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	byInterval := map[string][]domain.MLFeatureRow{}
+	for i, symbol := range domain.SupportedSymbols() {
+		for _, interval := range []string{"1h", "4h"} {
+			byInterval[interval] = append(byInterval[interval], makeFeatureRow(symbol, interval, rowTS, float64(i)*0.3-1))
+		}
+	}
+
+	registry := &modelRegistryStub{
+		active: map[string]*domain.MLModelVersion{
+			common.ModelKeyLogReg:        {ModelKey: common.ModelKeyLogReg, Version: 1, ArtifactBlob: mustTrainLogRegBlob(b), IsActive: true},
+			common.ModelKeyXGBoost:       {ModelKey: common.ModelKeyXGBoost, Version: 1, ArtifactBlob: mustTrainXGBBlob(b), IsActive: true},
+			common.IForestModelKey("1h"): {ModelKey: common.IForestModelKey("1h"), Version: 1, ArtifactBlob: mustTrainIForestBlob(b, "iforest_1h", "1h"), IsActive: true},
+			common.IForestModelKey("4h"): {ModelKey: common.IForestModelKey("4h"), Version: 1, ArtifactBlob: mustTrainIForestBlob(b, "iforest_4h", "4h"), IsActive: true},
+		},
+	}
+	svc := NewService(
+		trace.NewNoopTracerProvider().Tracer("inference-bench"),
+		&featureReaderStub{byInterval: byInterval},
+		registry,
+		newPredictionStoreStub(),
+		&signalStoreStub{},
+		nil,
+		Config{
+			Interval:         "1h",
+			Intervals:        []string{"1h", "4h"},
+			TargetHours:      4,
+			LongThreshold:    0.55,
+			ShortThreshold:   0.45,
+			EnableIForest:    true,
+			AnomalyThreshold: 0.62,
+			AnomalyDampMax:   0.65,
+		},
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err != nil {
+			b.Fatalf("run latest: %v", err)
+		}
+	}
+}
+
+func TestRunLatestPerformanceBudget(t *testing.T) {
+	loadtest.EnforceBudget(t, inferenceRunBudget, BenchmarkRunLatest)
+}
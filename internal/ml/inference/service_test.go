@@ -4,16 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/ml/common"
 	iforestmodel "bug-free-umbrella/internal/ml/models/iforest"
 	"bug-free-umbrella/internal/ml/models/logreg"
 	"bug-free-umbrella/internal/ml/models/xgboost"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -92,6 +93,18 @@ func TestRunLatestPersistsAnomalyAndSkipsAnomalySignals(t *testing.T) {
 		t.Fatalf("iforest prediction should be hold with no signal id, got direction=%s signal_id=%v", iforest1h.Direction, iforest1h.SignalID)
 	}
 
+	if len(predictions.anomalies) != 2 {
+		t.Fatalf("expected one anomaly series point per interval, got %+v", predictions.anomalies)
+	}
+	for _, a := range predictions.anomalies {
+		if a.Symbol != "BTC" || !a.OpenTime.Equal(rowTS) || a.DampFactor <= 0 || a.DampFactor > 1 {
+			t.Fatalf("unexpected anomaly point %+v", a)
+		}
+		if a.Interval == "1h" && a.Score != iforest1h.Confidence {
+			t.Fatalf("expected series score %v to match the iforest prediction %v", a.Score, iforest1h.Confidence)
+		}
+	}
+
 	iforest4h := predictions.findByKey(common.IForestModelKey("4h"), "4h")
 	if iforest4h == nil {
 		t.Fatal("expected iforest 4h prediction")
@@ -104,6 +117,9 @@ func TestRunLatestPersistsAnomalyAndSkipsAnomalySignals(t *testing.T) {
 		if strings.HasPrefix(sig.Indicator, "iforest") {
 			t.Fatalf("anomaly should not emit standalone signals: %+v", sig)
 		}
+		if !strings.Contains(sig.Version, "/v") {
+			t.Fatalf("expected model version on ML signal, got %q", sig.Version)
+		}
 	}
 
 	ensemblePred := predictions.findByKey(common.ModelKeyEnsembleV1, "1h")
@@ -120,6 +136,382 @@ func TestRunLatestPersistsAnomalyAndSkipsAnomalySignals(t *testing.T) {
 	if _, ok := details["damp_factor"]; !ok {
 		t.Fatalf("expected damp_factor in ensemble details: %s", ensemblePred.DetailsJSON)
 	}
+	for _, key := range []string{"classic_score", "logreg_prob", "xgboost_prob"} {
+		if _, ok := details[key]; !ok {
+			t.Fatalf("expected %s in ensemble details for the breakdown: %s", key, ensemblePred.DetailsJSON)
+		}
+	}
+}
+
+func TestPersistModelPredictionRecordsDampingHold(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	predictions := newPredictionStoreStub()
+	signals := &signalStoreStub{}
+	alerts := &heldAlerterStub{}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), &featureReaderStub{}, &modelRegistryStub{}, predictions, signals, nil, Config{})
+	svc.SetHeldAlerts(alerts)
+	row := makeFeatureRow("BTC", "1h", rowTS, 2.5)
+	target := rowTS.Add(4 * time.Hour)
+
+	// 0.40 undamped is a long call; damped by 0.3 it is 0.12, a Hold.
+	pred, hasSignal, err := svc.persistModelPrediction(context.Background(), row, common.ModelKeyEnsembleV1, 1, 0.56, target, 0.12, 0.40, 0.9, 0.3, 0, nil)
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if hasSignal || pred.Direction != domain.DirectionHold {
+		t.Fatalf("expected a hold without signal, got %s (signal=%v)", pred.Direction, hasSignal)
+	}
+	if pred.HeldReason() != domain.HeldReasonAnomalyDamping {
+		t.Fatalf("expected held reason in details, got %s", pred.DetailsJSON)
+	}
+	var details map[string]any
+	if err := json.Unmarshal([]byte(pred.DetailsJSON), &details); err != nil {
+		t.Fatalf("parse details: %v", err)
+	}
+	if details["undamped_direction"] != "long" || details["undamped_score"] != 0.4 {
+		t.Fatalf("expected suppressed call in details, got %s", pred.DetailsJSON)
+	}
+	if len(alerts.messages) != 1 || !strings.Contains(alerts.messages[0], "BTC 1h: ensemble LONG call held by anomaly damping") {
+		t.Fatalf("expected one held alert, got %q", alerts.messages)
+	}
+
+	// A rerun on the same candle is not announced again.
+	if _, _, err := svc.persistModelPrediction(context.Background(), row, common.ModelKeyEnsembleV1, 1, 0.56, target, 0.12, 0.40, 0.9, 0.3, 0, nil); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if len(alerts.messages) != 1 {
+		t.Fatalf("expected rerun to stay quiet, got %q", alerts.messages)
+	}
+
+	// A Hold the undamped score agrees with is not a suppressed call.
+	quiet := makeFeatureRow("ETH", "1h", rowTS, 2.5)
+	pred, _, err = svc.persistModelPrediction(context.Background(), quiet, common.ModelKeyEnsembleV1, 1, 0.52, target, 0.03, 0.10, 0.9, 0.3, 0, nil)
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if pred.HeldReason() != "" || len(alerts.messages) != 1 {
+		t.Fatalf("expected no held reason for a genuine hold, got %s", pred.DetailsJSON)
+	}
+}
+
+func TestPersistModelPredictionWidensHoldBandWhenModelsDisagree(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	predictions := newPredictionStoreStub()
+	alerts := &heldAlerterStub{}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), &featureReaderStub{}, &modelRegistryStub{}, predictions, &signalStoreStub{}, nil, Config{UncertaintyBandWeight: 0.5})
+	svc.SetHeldAlerts(alerts)
+	row := makeFeatureRow("BTC", "1h", rowTS, 2.5)
+	target := rowTS.Add(4 * time.Hour)
+
+	// logreg at 0.8 and xgboost at 0.3 disagree by 0.5 on the score scale,
+	// widening the band to 0.4: a 0.25 score that would be long is held.
+	spread := uncertainty([]float64{0.8}, []float64{0.3})
+	if math.Abs(spread-0.5) > 1e-9 {
+		t.Fatalf("expected uncertainty 0.5, got %.4f", spread)
+	}
+	pred, hasSignal, err := svc.persistModelPrediction(context.Background(), row, common.ModelKeyEnsembleV1, 1, 0.625, target, 0.25, 0.25, 0, 1, spread, nil)
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if hasSignal || pred.Direction != domain.DirectionHold || pred.HeldReason() != domain.HeldReasonUncertainty {
+		t.Fatalf("expected a hold for uncertainty, got %s %s", pred.Direction, pred.DetailsJSON)
+	}
+	if pred.Uncertainty() != 0.5 || !strings.Contains(pred.DetailsJSON, `"hold_band":0.4`) || !strings.Contains(pred.DetailsJSON, `"held_direction":"long"`) {
+		t.Fatalf("expected uncertainty and band in details, got %s", pred.DetailsJSON)
+	}
+	if len(alerts.messages) != 0 {
+		t.Fatalf("uncertainty holds are not anomaly alerts, got %q", alerts.messages)
+	}
+
+	// With agreeing models the fixed band applies.
+	pred, hasSignal, err = svc.persistModelPrediction(context.Background(), makeFeatureRow("ETH", "1h", rowTS, 2.5), common.ModelKeyEnsembleV1, 1, 0.625, target, 0.25, 0.25, 0, 1, 0, nil)
+	if err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if !hasSignal || pred.Direction != domain.DirectionLong {
+		t.Fatalf("expected a long call without disagreement, got %s", pred.Direction)
+	}
+}
+
+func TestPersistModelPredictionPublishes(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	publisher := &predictionPublisherStub{}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), &featureReaderStub{}, &modelRegistryStub{}, newPredictionStoreStub(), &signalStoreStub{}, nil, Config{LongThreshold: 0.55, ShortThreshold: 0.45})
+	svc.SetPredictionPublisher(publisher)
+	row := makeFeatureRow("BTC", "1h", rowTS, 2.5)
+
+	if _, _, err := svc.persistModelPrediction(context.Background(), row, common.ModelKeyLogReg, 2, 0.8, rowTS.Add(4*time.Hour), 0, 0, 0, 1, 0, nil); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if len(publisher.predictions) != 1 || len(publisher.signals) != 1 {
+		t.Fatalf("expected one prediction and one signal published, got %d and %d", len(publisher.predictions), len(publisher.signals))
+	}
+	pred := publisher.predictions[0]
+	if pred.SignalID == nil || *pred.SignalID != publisher.signals[0].ID {
+		t.Fatalf("expected published prediction linked to signal %d, got %v", publisher.signals[0].ID, pred.SignalID)
+	}
+
+	// A hold is published as a prediction without a signal.
+	if _, _, err := svc.persistModelPrediction(context.Background(), row, common.ModelKeyXGBoost, 2, 0.5, rowTS.Add(4*time.Hour), 0, 0, 0, 1, 0, nil); err != nil {
+		t.Fatalf("persist: %v", err)
+	}
+	if len(publisher.predictions) != 2 || len(publisher.signals) != 1 {
+		t.Fatalf("expected hold published without a signal, got %d and %d", len(publisher.predictions), len(publisher.signals))
+	}
+}
+
+func TestRunLatestUsesPinnedVersions(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	features := &featureReaderStub{
+		byInterval: map[string][]domain.MLFeatureRow{"1h": {makeFeatureRow("BTC", "1h", rowTS, 2.5)}},
+	}
+	logModelBlob := mustTrainLogRegBlob(t)
+	xgbModelBlob := mustTrainXGBBlob(t)
+	registry := &pinnedRegistryStub{
+		modelRegistryStub: modelRegistryStub{
+			active: map[string]*domain.MLModelVersion{
+				common.ModelKeyLogReg:  {ModelKey: common.ModelKeyLogReg, Version: 9, ArtifactBlob: logModelBlob, IsActive: true},
+				common.ModelKeyXGBoost: {ModelKey: common.ModelKeyXGBoost, Version: 13, ArtifactBlob: xgbModelBlob, IsActive: true},
+			},
+		},
+		stored: map[string]*domain.MLModelVersion{
+			"xgboost/12": {ModelKey: common.ModelKeyXGBoost, Version: 12, ArtifactBlob: xgbModelBlob},
+		},
+	}
+	predictions := newPredictionStoreStub()
+	cfg := Config{Interval: "1h", PinnedVersions: map[string]int{common.ModelKeyXGBoost: 12}}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, predictions, &signalStoreStub{}, nil, cfg)
+
+	if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err != nil {
+		t.Fatalf("run latest failed: %v", err)
+	}
+	xgb := predictions.findByKey(common.ModelKeyXGBoost, "1h")
+	if xgb == nil || xgb.ModelVersion != 12 || !strings.Contains(xgb.DetailsJSON, `"pinned":true`) {
+		t.Fatalf("expected pinned xgboost v12 prediction, got %+v", xgb)
+	}
+	logPred := predictions.findByKey(common.ModelKeyLogReg, "1h")
+	if logPred == nil || logPred.ModelVersion != 9 || strings.Contains(logPred.DetailsJSON, "pinned") {
+		t.Fatalf("expected unpinned active logreg v9 prediction, got %+v", logPred)
+	}
+	ensemblePred := predictions.findByKey(common.ModelKeyEnsembleV1, "1h")
+	if ensemblePred == nil || !strings.Contains(ensemblePred.DetailsJSON, `"pinned_versions":{"xgboost":12}`) {
+		t.Fatalf("expected pinned versions in ensemble details, got %+v", ensemblePred)
+	}
+
+	cfg.PinnedVersions = map[string]int{common.ModelKeyXGBoost: 40}
+	svc = NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, newPredictionStoreStub(), &signalStoreStub{}, nil, cfg)
+	if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err == nil || !strings.Contains(err.Error(), "pinned to v40") {
+		t.Fatalf("expected a missing pinned version to fail, got %v", err)
+	}
+}
+
+func TestRunLatestAveragesRollingVersions(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	row := makeFeatureRow("BTC", "1h", rowTS, 2.5)
+	features := &featureReaderStub{byInterval: map[string][]domain.MLFeatureRow{"1h": {row}}}
+	newBlob := mustTrainLogRegBlob(t)
+	samples, labels := directionalDataset()
+	for i := range labels {
+		labels[i] = 1 - labels[i]
+	}
+	flipped, err := logreg.Train(samples, labels, common.FeatureNames, logreg.DefaultTrainOptions())
+	if err != nil {
+		t.Fatalf("train flipped logreg: %v", err)
+	}
+	oldBlob, err := flipped.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal flipped logreg: %v", err)
+	}
+	registry := &rollingRegistryStub{
+		modelRegistryStub: modelRegistryStub{
+			active: map[string]*domain.MLModelVersion{
+				common.ModelKeyLogReg: {ModelKey: common.ModelKeyLogReg, Version: 5, ArtifactBlob: newBlob, IsActive: true},
+			},
+		},
+		promoted: map[string][]domain.MLModelVersion{
+			common.ModelKeyLogReg: {
+				{ModelKey: common.ModelKeyLogReg, Version: 5, ArtifactBlob: newBlob},
+				{ModelKey: common.ModelKeyLogReg, Version: 4, ArtifactBlob: oldBlob},
+				{ModelKey: common.ModelKeyLogReg, Version: 2, ArtifactBlob: oldBlob},
+			},
+		},
+	}
+	predictions := newPredictionStoreStub()
+	cfg := Config{Interval: "1h", RollingVersions: map[string]int{common.ModelKeyLogReg: 2}}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, predictions, &signalStoreStub{}, nil, cfg)
+
+	if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err != nil {
+		t.Fatalf("run latest failed: %v", err)
+	}
+	if registry.limit != 2 {
+		t.Fatalf("expected the last 2 promoted versions to be listed, got limit %d", registry.limit)
+	}
+	vector := common.FeatureVector(row)
+	newModel, _ := logreg.UnmarshalBinary(newBlob)
+	want := (newModel.PredictProb(vector) + flipped.PredictProb(vector)) / 2
+	logPred := predictions.findByKey(common.ModelKeyLogReg, "1h")
+	if logPred == nil || logPred.ModelVersion != 5 || math.Abs(logPred.ProbUp-common.Clamp01(want)) > 1e-9 {
+		t.Fatalf("expected v5 prediction averaging v5 and v4 to %.4f, got %+v", want, logPred)
+	}
+	if !strings.Contains(logPred.DetailsJSON, `"rolling_versions":[5,4]`) {
+		t.Fatalf("expected rolling versions in details, got %s", logPred.DetailsJSON)
+	}
+	wantSpread := math.Abs(common.Clamp01(newModel.PredictProb(vector)) - common.Clamp01(flipped.PredictProb(vector)))
+	if math.Abs(logPred.Uncertainty()-roundFloat(wantSpread)) > 1e-9 {
+		t.Fatalf("expected the versions' disagreement %.4f as uncertainty, got %s", wantSpread, logPred.DetailsJSON)
+	}
+	ensemblePred := predictions.findByKey(common.ModelKeyEnsembleV1, "1h")
+	if ensemblePred == nil || !strings.Contains(ensemblePred.DetailsJSON, `"rolling_versions":{"logreg":[5,4]}`) {
+		t.Fatalf("expected rolling versions in ensemble details, got %+v", ensemblePred)
+	}
+
+	cfg.PinnedVersions = map[string]int{common.ModelKeyLogReg: 5}
+	pinned := &pinnedRegistryStub{
+		modelRegistryStub: registry.modelRegistryStub,
+		stored:            map[string]*domain.MLModelVersion{"logreg/5": registry.active[common.ModelKeyLogReg]},
+	}
+	predictions = newPredictionStoreStub()
+	svc = NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, pinned, predictions, &signalStoreStub{}, nil, cfg)
+	if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err != nil {
+		t.Fatalf("run latest failed: %v", err)
+	}
+	if logPred := predictions.findByKey(common.ModelKeyLogReg, "1h"); logPred == nil || strings.Contains(logPred.DetailsJSON, "rolling_versions") {
+		t.Fatalf("expected a pin to override the rolling ensemble, got %+v", logPred)
+	}
+}
+
+func TestRunLatestBlendsRecentFearGreed(t *testing.T) {
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	features := &featureReaderStub{
+		byInterval: map[string][]domain.MLFeatureRow{"1h": {makeFeatureRow("BTC", "1h", rowTS, 2.5)}},
+	}
+	registry := &modelRegistryStub{
+		active: map[string]*domain.MLModelVersion{
+			common.ModelKeyLogReg:  {ModelKey: common.ModelKeyLogReg, Version: 1, ArtifactBlob: mustTrainLogRegBlob(t), IsActive: true},
+			common.ModelKeyXGBoost: {ModelKey: common.ModelKeyXGBoost, Version: 1, ArtifactBlob: mustTrainXGBBlob(t), IsActive: true},
+		},
+	}
+	run := func(source FearGreedSource) *domain.MLPrediction {
+		predictions := newPredictionStoreStub()
+		svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, predictions, &signalStoreStub{}, nil, Config{Interval: "1h"})
+		if source != nil {
+			svc.SetFearGreedSource(source)
+		}
+		if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err != nil {
+			t.Fatalf("run latest failed: %v", err)
+		}
+		pred := predictions.findByKey(common.ModelKeyEnsembleV1, "1h")
+		if pred == nil {
+			t.Fatal("expected an ensemble prediction")
+		}
+		return pred
+	}
+
+	base := run(nil)
+	fearful := run(fearGreedSourceStub{reading: &domain.FearGreedReading{Timestamp: rowTS.Add(-12 * time.Hour), Value: 10}})
+	if !strings.Contains(fearful.DetailsJSON, `"fear_greed":10`) {
+		t.Fatalf("expected the index value in the ensemble details, got %s", fearful.DetailsJSON)
+	}
+	if fearful.ProbUp >= base.ProbUp {
+		t.Fatalf("expected extreme fear to lower the ensemble, got %.4f vs %.4f", fearful.ProbUp, base.ProbUp)
+	}
+
+	stale := run(fearGreedSourceStub{reading: &domain.FearGreedReading{Timestamp: rowTS.Add(-FearGreedMaxAge - time.Hour), Value: 10}})
+	if strings.Contains(stale.DetailsJSON, "fear_greed") || stale.ProbUp != base.ProbUp {
+		t.Fatalf("expected a stale value to be ignored, got %+v", stale)
+	}
+}
+
+func TestRunLatestSkipsSymbolsWithMLPaused(t *testing.T) {
+	t.Cleanup(func() { domain.SetConfiguredPauses(nil) })
+	domain.SetConfiguredPauses(map[string]domain.AssetPauses{"ETH": {MLPaused: true}})
+	rowTS := time.Date(2026, 2, 13, 12, 0, 0, 0, time.UTC)
+	features := &featureReaderStub{
+		byInterval: map[string][]domain.MLFeatureRow{"1h": {
+			makeFeatureRow("BTC", "1h", rowTS, 2.5),
+			makeFeatureRow("ETH", "1h", rowTS, 2.5),
+		}},
+	}
+	registry := &modelRegistryStub{
+		active: map[string]*domain.MLModelVersion{
+			common.ModelKeyLogReg: {ModelKey: common.ModelKeyLogReg, Version: 1, ArtifactBlob: mustTrainLogRegBlob(t), IsActive: true},
+		},
+	}
+	predictions := newPredictionStoreStub()
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("inference-test"), features, registry, predictions, &signalStoreStub{}, nil, Config{Interval: "1h"})
+	if _, err := svc.RunLatest(context.Background(), rowTS.Add(5*time.Minute)); err != nil {
+		t.Fatalf("run latest failed: %v", err)
+	}
+	for _, pred := range predictions.rows {
+		if pred.Symbol == "ETH" {
+			t.Fatalf("expected no predictions for ETH while ML is paused, got %+v", pred)
+		}
+	}
+	if len(predictions.rows) == 0 {
+		t.Fatal("expected BTC predictions")
+	}
+}
+
+type fearGreedSourceStub struct {
+	reading *domain.FearGreedReading
+}
+
+func (s fearGreedSourceStub) FearGreedAt(_ context.Context, at time.Time) (*domain.FearGreedReading, error) {
+	if s.reading == nil || s.reading.Timestamp.After(at) {
+		return nil, nil
+	}
+	return s.reading, nil
+}
+
+type rollingRegistryStub struct {
+	modelRegistryStub
+	promoted map[string][]domain.MLModelVersion
+	limit    int
+}
+
+func (s *rollingRegistryStub) ListPromotedModels(_ context.Context, modelKey string, limit int) ([]domain.MLModelVersion, error) {
+	s.limit = limit
+	models := s.promoted[modelKey]
+	if len(models) > limit {
+		models = models[:limit]
+	}
+	return append([]domain.MLModelVersion(nil), models...), nil
+}
+
+type pinnedRegistryStub struct {
+	modelRegistryStub
+	stored map[string]*domain.MLModelVersion // "key/version"
+}
+
+func (s *pinnedRegistryStub) GetModelVersion(_ context.Context, modelKey string, version int) (*domain.MLModelVersion, error) {
+	model := s.stored[fmt.Sprintf("%s/%d", modelKey, version)]
+	if model == nil {
+		return nil, nil
+	}
+	copyModel := *model
+	return &copyModel, nil
+}
+
+type heldAlerterStub struct {
+	messages []string
+}
+
+func (s *heldAlerterStub) Broadcast(_ context.Context, message string) (int, error) {
+	s.messages = append(s.messages, message)
+	return 1, nil
+}
+
+type predictionPublisherStub struct {
+	predictions []domain.MLPrediction
+	signals     []domain.Signal
+}
+
+func (s *predictionPublisherStub) PublishPrediction(_ context.Context, pred domain.MLPrediction) {
+	s.predictions = append(s.predictions, pred)
+}
+
+func (s *predictionPublisherStub) PublishSignals(_ context.Context, signals []domain.Signal) {
+	s.signals = append(s.signals, signals...)
 }
 
 type featureReaderStub struct {
@@ -144,9 +536,10 @@ func (s *modelRegistryStub) GetActiveModel(_ context.Context, modelKey string) (
 }
 
 type predictionStoreStub struct {
-	mu     sync.Mutex
-	nextID int64
-	rows   map[string]domain.MLPrediction
+	mu        sync.Mutex
+	nextID    int64
+	rows      map[string]domain.MLPrediction
+	anomalies []domain.AnomalyScore
 }
 
 func newPredictionStoreStub() *predictionStoreStub {
@@ -188,6 +581,13 @@ func (s *predictionStoreStub) AttachSignalID(_ context.Context, predictionID, si
 	return fmt.Errorf("prediction id not found: %d", predictionID)
 }
 
+func (s *predictionStoreStub) RecordAnomalyScore(_ context.Context, score domain.AnomalyScore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.anomalies = append(s.anomalies, score)
+	return nil
+}
+
 func (s *predictionStoreStub) findByKey(modelKey, interval string) *domain.MLPrediction {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -261,7 +661,7 @@ func makeFeatureRow(symbol, interval string, ts time.Time, base float64) domain.
 	}
 }
 
-func mustTrainLogRegBlob(t *testing.T) []byte {
+func mustTrainLogRegBlob(t testing.TB) []byte {
 	t.Helper()
 	samples, labels := directionalDataset()
 	model, err := logreg.Train(samples, labels, common.FeatureNames, logreg.DefaultTrainOptions())
@@ -275,7 +675,7 @@ func mustTrainLogRegBlob(t *testing.T) []byte {
 	return blob
 }
 
-func mustTrainXGBBlob(t *testing.T) []byte {
+func mustTrainXGBBlob(t testing.TB) []byte {
 	t.Helper()
 	samples, labels := directionalDataset()
 	model, err := xgboost.Train(samples, labels, common.FeatureNames, xgboost.DefaultTrainOptions())
@@ -289,7 +689,7 @@ func mustTrainXGBBlob(t *testing.T) []byte {
 	return blob
 }
 
-func mustTrainIForestBlob(t *testing.T, modelKey, interval string) []byte {
+func mustTrainIForestBlob(t testing.TB, modelKey, interval string) []byte {
 	t.Helper()
 	model, err := iforestmodel.Train(
 		anomalyDataset(),
@@ -6,7 +6,7 @@ import (
 	"errors"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -85,6 +85,22 @@ RETURNING id, symbol, interval, open_time, target_time,
 	return out, nil
 }
 
+// RecordAnomalyScore adds one point to the anomaly score series, replacing
+// an earlier score for the same candle.
+func (r *Repository) RecordAnomalyScore(ctx context.Context, score domain.AnomalyScore) error {
+	_, span := r.tracer.Start(ctx, "ml-predictions.record-anomaly-score")
+	defer span.End()
+
+	_, err := r.pool.Exec(ctx, `
+INSERT INTO ml_anomaly_scores (symbol, interval, open_time, score, damp_factor)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+    score = EXCLUDED.score,
+    damp_factor = EXCLUDED.damp_factor`,
+		score.Symbol, score.Interval, score.OpenTime.UTC(), score.Score, score.DampFactor)
+	return err
+}
+
 func (r *Repository) AttachSignalID(ctx context.Context, predictionID, signalID int64) error {
 	_, span := r.tracer.Start(ctx, "ml-predictions.attach-signal")
 	defer span.End()
@@ -133,6 +149,39 @@ LIMIT $2`, cutoff.UTC(), limit)
 	return out, rows.Err()
 }
 
+func (r *Repository) ListLatestBySymbol(ctx context.Context, symbol string, limit int) ([]domain.MLPrediction, error) {
+	_, span := r.tracer.Start(ctx, "ml-predictions.list-latest-by-symbol")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := r.pool.Query(ctx, `
+SELECT id, symbol, interval, open_time, target_time,
+       model_key, model_version,
+       prob_up, confidence, direction, risk,
+       signal_id, details_json,
+       created_at, resolved_at, actual_up, is_correct, realized_return
+FROM ml_predictions
+WHERE symbol = $1
+ORDER BY open_time DESC, interval ASC, model_key ASC
+LIMIT $2`, symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.MLPrediction, 0, limit)
+	for rows.Next() {
+		p, err := scanPredictionRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
 func (r *Repository) ResolvePrediction(ctx context.Context, predictionID int64, actualUp bool, isCorrect bool, realizedReturn float64) error {
 	_, span := r.tracer.Start(ctx, "ml-predictions.resolve")
 	defer span.End()
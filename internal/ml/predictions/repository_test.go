@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -84,9 +84,29 @@ func TestAttachSignalID(t *testing.T) {
 	}
 }
 
+func TestRecordAnomalyScore(t *testing.T) {
+	pool := newPredictionPoolStub()
+	repo := NewRepository(pool, trace.NewNoopTracerProvider().Tracer("predictions-test"))
+
+	openTime := time.Date(2026, 2, 13, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	err := repo.RecordAnomalyScore(context.Background(), domain.AnomalyScore{
+		Symbol: "BTC", Interval: "1h", OpenTime: openTime, Score: 0.8, DampFactor: 0.48,
+	})
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if len(pool.lastExec) != 5 || pool.lastExec[0] != "BTC" || pool.lastExec[3] != 0.8 {
+		t.Fatalf("unexpected exec args %v", pool.lastExec)
+	}
+	if ts := pool.lastExec[2].(time.Time); ts.Location() != time.UTC || !ts.Equal(openTime) {
+		t.Fatalf("expected open time in UTC, got %v", ts)
+	}
+}
+
 type predictionPoolStub struct {
-	nextID int64
-	rows   map[string]predictionRecord
+	nextID   int64
+	rows     map[string]predictionRecord
+	lastExec []any
 }
 
 type predictionRecord struct {
@@ -118,6 +138,7 @@ func newPredictionPoolStub() *predictionPoolStub {
 }
 
 func (s *predictionPoolStub) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	s.lastExec = args
 	if len(args) >= 2 && len(sql) > 0 {
 		predID, ok := args[0].(int64)
 		if ok {
@@ -5,7 +5,7 @@ import (
 	"errors"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -25,6 +25,7 @@ type tx interface {
 
 type pool interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Begin(ctx context.Context) (pgx.Tx, error)
 }
@@ -141,6 +142,81 @@ ORDER BY version DESC
 LIMIT 1`, modelKey)
 }
 
+// GetModelVersion loads one stored version, active or not. It returns nil
+// when the version does not exist.
+func (r *Repository) GetModelVersion(ctx context.Context, modelKey string, version int) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "ml-model-registry.get-version")
+	defer span.End()
+
+	return r.getOne(ctx, `
+SELECT id, model_key, version, feature_spec_version,
+       trained_from, trained_to, trained_at,
+       hyperparams_json, metrics_json,
+       artifact_format, artifact_blob,
+       is_active, activated_at, created_at
+FROM ml_model_versions
+WHERE model_key = $1 AND version = $2`, modelKey, version)
+}
+
+// GetModelActiveAt returns the version of modelKey that was active at the
+// given time, from the promotion history. It returns nil when no promotion
+// of the key precedes at.
+func (r *Repository) GetModelActiveAt(ctx context.Context, modelKey string, at time.Time) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "ml-model-registry.get-active-at")
+	defer span.End()
+
+	return r.getOne(ctx, `
+SELECT id, model_key, version, feature_spec_version,
+       trained_from, trained_to, trained_at,
+       hyperparams_json, metrics_json,
+       artifact_format, artifact_blob,
+       is_active, activated_at, created_at
+FROM ml_model_versions
+WHERE model_key = $1
+  AND version = (
+      SELECT version FROM ml_model_promotions
+      WHERE model_key = $1 AND promoted_at <= $2
+      ORDER BY promoted_at DESC, id DESC
+      LIMIT 1)`, modelKey, at.UTC())
+}
+
+// ListPromotedModels returns the last limit distinct versions of modelKey
+// that were promoted, newest version first.
+func (r *Repository) ListPromotedModels(ctx context.Context, modelKey string, limit int) ([]domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "ml-model-registry.list-promoted")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx, `
+SELECT id, model_key, version, feature_spec_version,
+       trained_from, trained_to, trained_at,
+       hyperparams_json, metrics_json,
+       artifact_format, artifact_blob,
+       is_active, activated_at, created_at
+FROM ml_model_versions
+WHERE model_key = $1
+  AND version IN (
+      SELECT version FROM ml_model_promotions
+      WHERE model_key = $1
+      GROUP BY version
+      ORDER BY MAX(promoted_at) DESC, MAX(id) DESC
+      LIMIT $2)
+ORDER BY version DESC`, modelKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MLModelVersion
+	for rows.Next() {
+		var model domain.MLModelVersion
+		if err := scanModel(rows, &model); err != nil {
+			return nil, err
+		}
+		out = append(out, model)
+	}
+	return out, rows.Err()
+}
+
 func (r *Repository) ActivateModel(ctx context.Context, modelKey string, version int) error {
 	_, span := r.tracer.Start(ctx, "ml-model-registry.activate")
 	defer span.End()
@@ -161,12 +237,58 @@ func (r *Repository) ActivateModel(ctx context.Context, modelKey string, version
 	if tag.RowsAffected() == 0 {
 		return pgx.ErrNoRows
 	}
+	if _, err := tx.Exec(ctx, `INSERT INTO ml_model_promotions (model_key, version) VALUES ($1, $2)`, modelKey, version); err != nil {
+		return err
+	}
 	return tx.Commit(ctx)
 }
 
-func (r *Repository) getOne(ctx context.Context, query string, arg any) (*domain.MLModelVersion, error) {
+// ListModelEvents returns training runs and promotions at or after since,
+// oldest first.
+func (r *Repository) ListModelEvents(ctx context.Context, since time.Time) ([]domain.ModelEvent, error) {
+	_, span := r.tracer.Start(ctx, "ml-model-registry.list-events")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx, `
+SELECT 'trained', model_key, version, trained_at, trained_from, trained_to
+FROM ml_model_versions
+WHERE trained_at >= $1
+UNION ALL
+SELECT 'promoted', p.model_key, p.version, p.promoted_at, v.trained_from, v.trained_to
+FROM ml_model_promotions p
+JOIN ml_model_versions v ON v.model_key = p.model_key AND v.version = p.version
+WHERE p.promoted_at >= $1
+ORDER BY 4 ASC`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ModelEvent
+	for rows.Next() {
+		var e domain.ModelEvent
+		if err := rows.Scan(&e.Kind, &e.ModelKey, &e.Version, &e.At, &e.TrainedFrom, &e.TrainedTo); err != nil {
+			return nil, err
+		}
+		e.At, e.TrainedFrom, e.TrainedTo = e.At.UTC(), e.TrainedFrom.UTC(), e.TrainedTo.UTC()
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) getOne(ctx context.Context, query string, args ...any) (*domain.MLModelVersion, error) {
 	var out domain.MLModelVersion
-	err := r.pool.QueryRow(ctx, query, arg).Scan(
+	if err := scanModel(r.pool.QueryRow(ctx, query, args...), &out); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &out, nil
+}
+
+func scanModel(src row, out *domain.MLModelVersion) error {
+	err := src.Scan(
 		&out.ID,
 		&out.ModelKey,
 		&out.Version,
@@ -183,13 +305,10 @@ func (r *Repository) getOne(ctx context.Context, query string, arg any) (*domain
 		&out.CreatedAt,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
+		return err
 	}
-	normalizeModelTimes(&out)
-	return &out, nil
+	normalizeModelTimes(out)
+	return nil
 }
 
 func normalizeModelTimes(model *domain.MLModelVersion) {
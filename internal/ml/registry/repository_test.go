@@ -3,7 +3,11 @@ package registry
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -33,6 +37,7 @@ func TestActivateModel(t *testing.T) {
 		execResults: []pgconn.CommandTag{
 			pgconn.NewCommandTag("UPDATE 2"),
 			pgconn.NewCommandTag("UPDATE 1"),
+			pgconn.NewCommandTag("INSERT 0 1"),
 		},
 	}
 	pool.beginTx = tx
@@ -44,6 +49,32 @@ func TestActivateModel(t *testing.T) {
 	if !tx.committed {
 		t.Fatal("expected transaction commit")
 	}
+	if tx.execCalls != 3 {
+		t.Fatalf("expected promotion to be recorded, got %d execs", tx.execCalls)
+	}
+}
+
+func TestListModelEvents(t *testing.T) {
+	at := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	from := at.AddDate(0, 0, -90)
+	pool := &registryPoolStub{
+		rows: [][]any{
+			{"trained", "ml_logreg_up4h", 4, at, from, at},
+			{"promoted", "ml_logreg_up4h", 4, at.Add(time.Minute), from, at},
+		},
+	}
+	repo := NewRepository(pool, trace.NewNoopTracerProvider().Tracer("registry-test"))
+
+	events, err := repo.ListModelEvents(context.Background(), at.AddDate(0, 0, -30))
+	if err != nil {
+		t.Fatalf("list events failed: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[1].Kind != domain.ModelEventPromoted || events[1].Version != 4 || !events[1].TrainedFrom.Equal(from) {
+		t.Fatalf("unexpected promotion event %+v", events[1])
+	}
 }
 
 func TestActivateModelNoRows(t *testing.T) {
@@ -63,8 +94,55 @@ func TestActivateModelNoRows(t *testing.T) {
 	}
 }
 
+func TestGetModelVersion(t *testing.T) {
+	var gotArgs []any
+	pool := &registryPoolStub{
+		queryRowFunc: func(_ context.Context, _ string, args ...any) pgx.Row {
+			gotArgs = args
+			if args[1] == 9 {
+				return registryRowStub{err: pgx.ErrNoRows}
+			}
+			return registryRowStub{values: []any{nil, nil, 3}}
+		},
+	}
+	repo := NewRepository(pool, trace.NewNoopTracerProvider().Tracer("registry-test"))
+
+	model, err := repo.GetModelVersion(context.Background(), "logreg", 3)
+	if err != nil || model == nil || model.Version != 3 {
+		t.Fatalf("unexpected model %+v err=%v", model, err)
+	}
+	if len(gotArgs) != 2 || gotArgs[0] != "logreg" || gotArgs[1] != 3 {
+		t.Fatalf("expected model key and version as query args, got %v", gotArgs)
+	}
+	if missing, err := repo.GetModelVersion(context.Background(), "logreg", 9); err != nil || missing != nil {
+		t.Fatalf("expected nil for a missing version, got %+v err=%v", missing, err)
+	}
+}
+
+func TestGetModelActiveAt(t *testing.T) {
+	at := time.Date(2026, 3, 2, 8, 30, 0, 0, time.FixedZone("CET", 3600))
+	var gotSQL string
+	var gotArgs []any
+	pool := &registryPoolStub{
+		queryRowFunc: func(_ context.Context, sql string, args ...any) pgx.Row {
+			gotSQL, gotArgs = sql, args
+			return registryRowStub{values: []any{nil, nil, 4}}
+		},
+	}
+	repo := NewRepository(pool, trace.NewNoopTracerProvider().Tracer("registry-test"))
+
+	model, err := repo.GetModelActiveAt(context.Background(), "xgboost", at)
+	if err != nil || model == nil || model.Version != 4 {
+		t.Fatalf("unexpected model %+v err=%v", model, err)
+	}
+	if !strings.Contains(gotSQL, "ml_model_promotions") || gotArgs[0] != "xgboost" || gotArgs[1] != at.UTC() {
+		t.Fatalf("expected promotion lookup at %s, got %s %v", at.UTC(), gotSQL, gotArgs)
+	}
+}
+
 type registryPoolStub struct {
 	beginTx      pgx.Tx
+	rows         [][]any
 	queryRowFunc func(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
@@ -79,6 +157,10 @@ func (s *registryPoolStub) QueryRow(ctx context.Context, sql string, args ...any
 	return registryRowStub{}
 }
 
+func (s *registryPoolStub) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	return &registryRowsStub{data: s.rows}, nil
+}
+
 func (s *registryPoolStub) Begin(_ context.Context) (pgx.Tx, error) {
 	return s.beginTx, nil
 }
@@ -140,3 +222,39 @@ func (r registryRowStub) Scan(dest ...any) error {
 	}
 	return nil
 }
+
+type registryRowsStub struct {
+	data [][]any
+	idx  int
+}
+
+func (r *registryRowsStub) Close()                                       {}
+func (r *registryRowsStub) Err() error                                   { return nil }
+func (r *registryRowsStub) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *registryRowsStub) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *registryRowsStub) Values() ([]any, error)                       { return nil, nil }
+func (r *registryRowsStub) RawValues() [][]byte                          { return nil }
+func (r *registryRowsStub) Conn() *pgx.Conn                              { return nil }
+
+func (r *registryRowsStub) Next() bool {
+	if r.idx >= len(r.data) {
+		return false
+	}
+	r.idx++
+	return true
+}
+
+func (r *registryRowsStub) Scan(dest ...any) error {
+	row := r.data[r.idx-1]
+	for i := range dest {
+		switch d := dest[i].(type) {
+		case *string:
+			*d = row[i].(string)
+		case *int:
+			*d = row[i].(int)
+		case *time.Time:
+			*d = row[i].(time.Time)
+		}
+	}
+	return nil
+}
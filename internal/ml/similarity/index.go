@@ -0,0 +1,130 @@
+package similarity
+
+import (
+	"math"
+	"sort"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/pkg/domain"
+)
+
+// Index is an exact nearest-neighbour index over the market features of
+// feature rows; calendar position is left out so a state matches regardless
+// of when it happened. Each row is z-scored with the index's own per-feature
+// mean and deviation, then scaled to unit length, so the dot product of two
+// embeddings is their cosine similarity. A linear scan over a few months of
+// rows is fast enough that an approximate index would not pay for itself.
+type Index struct {
+	mean    []float64
+	std     []float64
+	rows    []domain.MLFeatureRow
+	vectors [][]float64
+}
+
+// Neighbour is one search hit: the position of the row in the index and its
+// cosine similarity to the query.
+type Neighbour struct {
+	Pos        int
+	Similarity float64
+}
+
+// NewIndex fits the scaler on rows and embeds each of them.
+func NewIndex(rows []domain.MLFeatureRow) *Index {
+	dims := common.MarketFeatureCount
+	ix := &Index{
+		mean:    make([]float64, dims),
+		std:     make([]float64, dims),
+		rows:    rows,
+		vectors: make([][]float64, len(rows)),
+	}
+	if len(rows) == 0 {
+		return ix
+	}
+	raw := make([][]float64, len(rows))
+	for i := range rows {
+		raw[i] = marketVector(rows[i])
+		for d, v := range raw[i] {
+			ix.mean[d] += v
+		}
+	}
+	n := float64(len(rows))
+	for d := range ix.mean {
+		ix.mean[d] /= n
+	}
+	for i := range raw {
+		for d, v := range raw[i] {
+			diff := v - ix.mean[d]
+			ix.std[d] += diff * diff
+		}
+	}
+	for d := range ix.std {
+		ix.std[d] = math.Sqrt(ix.std[d] / n)
+	}
+	for i := range raw {
+		ix.vectors[i] = ix.normalize(raw[i])
+	}
+	return ix
+}
+
+// Len returns the number of indexed rows.
+func (ix *Index) Len() int {
+	return len(ix.rows)
+}
+
+// Row returns the indexed row at pos.
+func (ix *Index) Row(pos int) domain.MLFeatureRow {
+	return ix.rows[pos]
+}
+
+// Embed returns the unit-length embedding of row under the index's scaler.
+func (ix *Index) Embed(row domain.MLFeatureRow) []float64 {
+	return ix.normalize(marketVector(row))
+}
+
+// Rank returns every row that keep accepts, most similar to query first.
+func (ix *Index) Rank(query []float64, keep func(pos int) bool) []Neighbour {
+	out := make([]Neighbour, 0, len(ix.vectors))
+	for pos, vector := range ix.vectors {
+		if keep != nil && !keep(pos) {
+			continue
+		}
+		out = append(out, Neighbour{Pos: pos, Similarity: dot(query, vector)})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Similarity > out[j].Similarity })
+	return out
+}
+
+func (ix *Index) normalize(raw []float64) []float64 {
+	out := make([]float64, len(raw))
+	norm := 0.0
+	for d, v := range raw {
+		if d >= len(ix.std) || ix.std[d] == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		out[d] = (v - ix.mean[d]) / ix.std[d]
+		norm += out[d] * out[d]
+	}
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for d := range out {
+		out[d] /= norm
+	}
+	return out
+}
+
+func marketVector(row domain.MLFeatureRow) []float64 {
+	return common.FeatureVector(row)[:common.MarketFeatureCount]
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		sum += a[i] * b[i]
+	}
+	return sum
+}
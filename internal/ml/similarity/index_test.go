@@ -0,0 +1,54 @@
+package similarity
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestIndexEmbedsUnitVectorsAndRanksByCosine(t *testing.T) {
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := []domain.MLFeatureRow{
+		{Symbol: "BTC", OpenTime: at, RSI14: 70, MACDHist: 2, BBPos: 0.9},
+		{Symbol: "BTC", OpenTime: at.Add(time.Hour), RSI14: 30, MACDHist: -2, BBPos: 0.1},
+		{Symbol: "ETH", OpenTime: at, RSI14: 68, MACDHist: 1.8, BBPos: 0.85},
+		{Symbol: "ETH", OpenTime: at.Add(time.Hour), RSI14: 50, MACDHist: 0, BBPos: 0.5},
+	}
+	ix := NewIndex(rows)
+	if ix.Len() != len(rows) {
+		t.Fatalf("expected %d rows, got %d", len(rows), ix.Len())
+	}
+	for i := range rows {
+		v := ix.Embed(rows[i])
+		norm := math.Sqrt(dot(v, v))
+		if norm != 0 && math.Abs(norm-1) > 1e-9 {
+			t.Fatalf("expected unit length embedding for row %d, got %f", i, norm)
+		}
+	}
+
+	ranked := ix.Rank(ix.Embed(rows[0]), func(pos int) bool { return pos != 0 })
+	if len(ranked) != 3 {
+		t.Fatalf("expected the query row filtered out, got %+v", ranked)
+	}
+	if ranked[0].Pos != 2 || ranked[len(ranked)-1].Pos != 1 {
+		t.Fatalf("expected the overbought ETH row first and the oversold BTC row last, got %+v", ranked)
+	}
+	if ranked[0].Similarity <= ranked[1].Similarity {
+		t.Fatalf("expected descending similarity, got %+v", ranked)
+	}
+}
+
+func TestIndexIgnoresConstantFeatures(t *testing.T) {
+	rows := []domain.MLFeatureRow{{RSI14: 50}, {RSI14: 50}}
+	ix := NewIndex(rows)
+	for _, v := range ix.Embed(rows[0]) {
+		if v != 0 || math.IsNaN(v) {
+			t.Fatalf("expected a zero embedding when nothing varies, got %v", ix.Embed(rows[0]))
+		}
+	}
+	if got := NewIndex(nil).Rank(nil, nil); len(got) != 0 {
+		t.Fatalf("expected no neighbours from an empty index, got %+v", got)
+	}
+}
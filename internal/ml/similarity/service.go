@@ -0,0 +1,193 @@
+package similarity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrNoFeatureRow is returned by FindSimilar when the symbol has no recent
+// feature row to use as the query.
+var ErrNoFeatureRow = errors.New("no recent feature row")
+
+// FeatureReader reads stored feature rows.
+type FeatureReader interface {
+	ListRows(ctx context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error)
+}
+
+type Config struct {
+	// TargetHours is the outcome horizon, in bars, the feature rows are
+	// labeled with.
+	TargetHours int
+	// LookbackDays bounds the history searched.
+	LookbackDays int
+}
+
+// Service finds past market states similar to the current one.
+type Service struct {
+	tracer   trace.Tracer
+	features FeatureReader
+	cfg      Config
+	now      func() time.Time
+}
+
+func NewService(tracer trace.Tracer, features FeatureReader, cfg Config) *Service {
+	if cfg.TargetHours <= 0 {
+		cfg.TargetHours = 4
+	}
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = 90
+	}
+	return &Service{tracer: tracer, features: features, cfg: cfg, now: time.Now}
+}
+
+// FindSimilar embeds the latest feature row of symbol and returns the k most
+// similar labeled rows of any symbol within the lookback, with their
+// outcomes. Rows of the same symbol whose outcome window reaches the query
+// candle are left out, and matches of one symbol are kept at least
+// TargetHours bars apart so a single episode does not fill the list.
+func (s *Service) FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error) {
+	ctx, span := s.tracer.Start(ctx, "ml-similarity.find-similar")
+	defer span.End()
+
+	step := domain.IntervalDuration(interval)
+	if step == 0 {
+		return nil, fmt.Errorf("unsupported interval: %s", interval)
+	}
+	if k <= 0 {
+		k = 10
+	}
+	now := s.now().UTC()
+	rows, err := s.features.ListRows(ctx, interval, now.AddDate(0, 0, -s.cfg.LookbackDays), now)
+	if err != nil {
+		return nil, err
+	}
+
+	query := -1
+	for i := range rows {
+		if rows[i].Symbol == symbol && (query < 0 || rows[i].OpenTime.After(rows[query].OpenTime)) {
+			query = i
+		}
+	}
+	if query < 0 {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoFeatureRow, symbol, interval)
+	}
+	queryRow := rows[query]
+
+	horizon := time.Duration(s.cfg.TargetHours) * step
+	returns := realizedReturns(rows, step, s.cfg.TargetHours)
+	index := NewIndex(rows)
+	candidates := 0
+	ranked := index.Rank(index.Embed(queryRow), func(pos int) bool {
+		row := index.Row(pos)
+		if row.TargetUp4H == nil {
+			return false
+		}
+		if row.Symbol == symbol && row.OpenTime.Add(horizon).After(queryRow.OpenTime) {
+			return false
+		}
+		candidates++
+		return true
+	})
+
+	out := &domain.SimilarSetups{
+		Symbol:      symbol,
+		Interval:    interval,
+		OpenTime:    queryRow.OpenTime,
+		TargetHours: s.cfg.TargetHours,
+		Features:    make(map[string]float64, len(common.FeatureNames)),
+		Candidates:  candidates,
+		Matches:     []domain.SimilarSetup{},
+	}
+	for i, v := range common.FeatureVector(queryRow) {
+		out.Features[common.FeatureNames[i]] = v
+	}
+
+	up, withReturn, sumReturn := 0, 0, 0.0
+	for _, n := range ranked {
+		if len(out.Matches) >= k {
+			break
+		}
+		row := index.Row(n.Pos)
+		if overlapsMatch(out.Matches, row, horizon) {
+			continue
+		}
+		match := domain.SimilarSetup{
+			Symbol:         row.Symbol,
+			OpenTime:       row.OpenTime,
+			Similarity:     n.Similarity,
+			WentUp:         *row.TargetUp4H,
+			RealizedReturn: returns[n.Pos],
+		}
+		if match.WentUp {
+			up++
+		}
+		if match.RealizedReturn != nil {
+			withReturn++
+			sumReturn += *match.RealizedReturn
+		}
+		out.Matches = append(out.Matches, match)
+	}
+	if len(out.Matches) > 0 {
+		out.UpRate = float64(up) / float64(len(out.Matches))
+	}
+	if withReturn > 0 {
+		mean := sumReturn / float64(withReturn)
+		out.MeanReturn = &mean
+	}
+	return out, nil
+}
+
+func overlapsMatch(matches []domain.SimilarSetup, row domain.MLFeatureRow, horizon time.Duration) bool {
+	for _, m := range matches {
+		if m.Symbol != row.Symbol {
+			continue
+		}
+		gap := row.OpenTime.Sub(m.OpenTime)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap < horizon {
+			return true
+		}
+	}
+	return false
+}
+
+// realizedReturns compounds the one-bar returns of the targetBars rows that
+// follow each row of the same symbol, which is the close-to-close return
+// over the outcome horizon. It is nil where any of those rows is missing.
+func realizedReturns(rows []domain.MLFeatureRow, step time.Duration, targetBars int) []*float64 {
+	bySymbol := make(map[string]map[int64]int)
+	for i := range rows {
+		if bySymbol[rows[i].Symbol] == nil {
+			bySymbol[rows[i].Symbol] = make(map[int64]int)
+		}
+		bySymbol[rows[i].Symbol][rows[i].OpenTime.Unix()] = i
+	}
+	out := make([]*float64, len(rows))
+	for i := range rows {
+		positions := bySymbol[rows[i].Symbol]
+		growth := 1.0
+		complete := true
+		for bar := 1; bar <= targetBars; bar++ {
+			next, ok := positions[rows[i].OpenTime.Add(time.Duration(bar)*step).Unix()]
+			if !ok {
+				complete = false
+				break
+			}
+			growth *= 1 + rows[next].Ret1H
+		}
+		if complete {
+			ret := growth - 1
+			out[i] = &ret
+		}
+	}
+	return out
+}
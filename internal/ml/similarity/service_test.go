@@ -0,0 +1,99 @@
+package similarity
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type featureReaderStub struct {
+	rows     []domain.MLFeatureRow
+	from, to time.Time
+}
+
+func (s *featureReaderStub) ListRows(_ context.Context, _ string, from, to time.Time) ([]domain.MLFeatureRow, error) {
+	s.from, s.to = from, to
+	return s.rows, nil
+}
+
+func labeled(up bool) *bool { return &up }
+
+func TestFindSimilarReturnsNearestLabeledStatesWithOutcomes(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 30, 0, 0, time.UTC)
+	episode := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	var rows []domain.MLFeatureRow
+	// An overbought ETH episode that looked exactly like BTC does now.
+	rows = append(rows,
+		domain.MLFeatureRow{Symbol: "ETH", Interval: "1h", OpenTime: episode, RSI14: 80, BBPos: 0.97, Ret1H: 0.015, TargetUp4H: labeled(true)},
+		domain.MLFeatureRow{Symbol: "ETH", Interval: "1h", OpenTime: episode.Add(time.Hour), RSI14: 79, BBPos: 0.96, Ret1H: 0.015, TargetUp4H: labeled(true)},
+		domain.MLFeatureRow{Symbol: "ETH", Interval: "1h", OpenTime: episode.Add(2 * time.Hour), RSI14: 50, BBPos: 0.5, Ret1H: 0.01, TargetUp4H: labeled(false)},
+	)
+	// An oversold BTC state long ago.
+	rows = append(rows, domain.MLFeatureRow{Symbol: "BTC", Interval: "1h", OpenTime: episode.Add(-24 * time.Hour), RSI14: 20, BBPos: 0.05, Ret1H: -0.03, TargetUp4H: labeled(false)})
+	// BTC right before the query: similar, but its outcome window reaches now.
+	latest := time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC)
+	rows = append(rows,
+		domain.MLFeatureRow{Symbol: "BTC", Interval: "1h", OpenTime: latest.Add(-time.Hour), RSI14: 79, BBPos: 0.96, TargetUp4H: labeled(true)},
+		domain.MLFeatureRow{Symbol: "BTC", Interval: "1h", OpenTime: latest, RSI14: 80, BBPos: 0.97, Ret1H: 0.015},
+	)
+	store := &featureReaderStub{rows: rows}
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("similarity-test"), store, Config{TargetHours: 2, LookbackDays: 30})
+	svc.now = func() time.Time { return now }
+
+	out, err := svc.FindSimilar(context.Background(), "BTC", "1h", 3)
+	if err != nil {
+		t.Fatalf("find similar: %v", err)
+	}
+	if !store.from.Equal(now.AddDate(0, 0, -30)) || !store.to.Equal(now) {
+		t.Fatalf("expected a 30 day lookback, got %s..%s", store.from, store.to)
+	}
+	if !out.OpenTime.Equal(latest) || out.Features["rsi_14"] != 80 || out.TargetHours != 2 {
+		t.Fatalf("expected the latest BTC row as the query, got %+v", out)
+	}
+	if out.Candidates != 4 {
+		t.Fatalf("expected the unlabeled and overlapping BTC rows left out, got %d candidates", out.Candidates)
+	}
+	if len(out.Matches) != 3 {
+		t.Fatalf("expected 3 matches, got %+v", out.Matches)
+	}
+	first := out.Matches[0]
+	if first.Symbol != "ETH" || !first.OpenTime.Equal(episode) || !first.WentUp {
+		t.Fatalf("expected the overbought ETH state first, got %+v", first)
+	}
+	// The next ETH row is within the horizon of the first match, so the
+	// episode only counts once.
+	for _, m := range out.Matches[1:] {
+		if m.Symbol == "ETH" && m.OpenTime.Equal(episode.Add(time.Hour)) {
+			t.Fatalf("expected overlapping matches skipped, got %+v", out.Matches)
+		}
+	}
+	if first.Similarity < 0.999999 {
+		t.Fatalf("expected an identical state to score 1, got %f", first.Similarity)
+	}
+	if first.RealizedReturn == nil || math.Abs(*first.RealizedReturn-(1.015*1.01-1)) > 1e-9 {
+		t.Fatalf("expected the compounded return of the next two bars, got %v", first.RealizedReturn)
+	}
+	last := out.Matches[2]
+	if last.Symbol != "BTC" || last.RealizedReturn != nil {
+		t.Fatalf("expected the oversold BTC state last without a realized return, got %+v", last)
+	}
+	if math.Abs(out.UpRate-1.0/3) > 1e-9 || out.MeanReturn == nil {
+		t.Fatalf("expected outcome summary, got up_rate=%f mean=%v", out.UpRate, out.MeanReturn)
+	}
+}
+
+func TestFindSimilarErrors(t *testing.T) {
+	svc := NewService(trace.NewNoopTracerProvider().Tracer("similarity-test"), &featureReaderStub{}, Config{})
+	if _, err := svc.FindSimilar(context.Background(), "BTC", "1h", 5); !errors.Is(err, ErrNoFeatureRow) {
+		t.Fatalf("expected ErrNoFeatureRow, got %v", err)
+	}
+	if _, err := svc.FindSimilar(context.Background(), "BTC", "2h", 5); err == nil {
+		t.Fatal("expected unsupported interval error")
+	}
+}
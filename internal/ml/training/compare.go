@@ -0,0 +1,146 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/models/logreg"
+	"bug-free-umbrella/internal/ml/models/xgboost"
+	"bug-free-umbrella/pkg/domain"
+)
+
+var (
+	// ErrModelVersionNotFound is returned when a compared version is not in
+	// the registry.
+	ErrModelVersionNotFound = errors.New("model version not found")
+	// ErrNotComparable is returned for model keys without a directional
+	// probability, such as the isolation forests.
+	ErrNotComparable = errors.New("only directional models can be compared")
+)
+
+// ModelVersionReader is implemented by registries that load any stored
+// version, not only the active one. Version comparison needs it.
+type ModelVersionReader interface {
+	GetModelVersion(ctx context.Context, modelKey string, version int) (*domain.MLModelVersion, error)
+}
+
+// VersionScore is one registry version's metrics over a comparison window.
+// TrainedTo shows whether the window overlaps the version's training data,
+// which flatters it.
+type VersionScore struct {
+	Version   int       `json:"version"`
+	IsActive  bool      `json:"is_active"`
+	TrainedTo time.Time `json:"trained_to"`
+	Accuracy  float64   `json:"accuracy"`
+	AUC       float64   `json:"auc"`
+	Brier     float64   `json:"brier"`
+}
+
+// VersionComparison reports two versions of one model key re-scored on the
+// same labeled feature rows. DisagreementRate is the share of rows on which
+// the versions call opposite directions at the 0.5 cut.
+type VersionComparison struct {
+	ModelKey         string       `json:"model_key"`
+	Interval         string       `json:"interval"`
+	From             time.Time    `json:"from"`
+	To               time.Time    `json:"to"`
+	Samples          int          `json:"samples"`
+	A                VersionScore `json:"a"`
+	B                VersionScore `json:"b"`
+	DisagreementRate float64      `json:"disagreement_rate"`
+}
+
+// CompareVersions re-scores the labeled feature rows between from and to
+// with two versions of a directional model. Nothing is written; the result
+// supports a manual ActivateModel decision.
+func (s *Service) CompareVersions(ctx context.Context, modelKey string, versionA, versionB int, from, to time.Time) (*VersionComparison, error) {
+	_, span := s.tracer.Start(ctx, "ml-training.compare-versions")
+	defer span.End()
+
+	if modelKey != common.ModelKeyLogReg && modelKey != common.ModelKeyXGBoost {
+		return nil, fmt.Errorf("%w: %s", ErrNotComparable, modelKey)
+	}
+	reader, ok := s.registry.(ModelVersionReader)
+	if !ok {
+		return nil, errors.New("model registry cannot load stored versions")
+	}
+
+	a, predictA, err := loadDirectional(ctx, reader, modelKey, versionA)
+	if err != nil {
+		return nil, err
+	}
+	b, predictB, err := loadDirectional(ctx, reader, modelKey, versionB)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.features.ListLabeledRows(ctx, s.cfg.Interval, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	samples, labels := buildDataset(rows)
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no labeled %s feature rows between %s and %s", s.cfg.Interval, from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	}
+
+	probsA := make([]float64, len(samples))
+	probsB := make([]float64, len(samples))
+	disagree := 0
+	for i, x := range samples {
+		probsA[i] = predictA(x)
+		probsB[i] = predictB(x)
+		if (probsA[i] >= 0.5) != (probsB[i] >= 0.5) {
+			disagree++
+		}
+	}
+
+	return &VersionComparison{
+		ModelKey:         modelKey,
+		Interval:         s.cfg.Interval,
+		From:             from.UTC(),
+		To:               to.UTC(),
+		Samples:          len(samples),
+		A:                versionScore(a, labels, probsA),
+		B:                versionScore(b, labels, probsB),
+		DisagreementRate: float64(disagree) / float64(len(samples)),
+	}, nil
+}
+
+func loadDirectional(ctx context.Context, reader ModelVersionReader, modelKey string, version int) (*domain.MLModelVersion, func([]float64) float64, error) {
+	model, err := reader.GetModelVersion(ctx, modelKey, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if model == nil {
+		return nil, nil, fmt.Errorf("%w: %s v%d", ErrModelVersionNotFound, modelKey, version)
+	}
+	switch modelKey {
+	case common.ModelKeyLogReg:
+		m, err := logreg.UnmarshalBinary(model.ArtifactBlob)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load %s v%d: %w", modelKey, version, err)
+		}
+		return model, common.ForFeatureCount(len(m.FeatureNames()), m.PredictProb), nil
+	default:
+		m, err := xgboost.UnmarshalBinary(model.ArtifactBlob)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load %s v%d: %w", modelKey, version, err)
+		}
+		return model, common.ForFeatureCount(len(m.FeatureNames()), m.PredictProb), nil
+	}
+}
+
+func versionScore(model *domain.MLModelVersion, labels, probs []float64) VersionScore {
+	metrics := computeMetrics(labels, probs)
+	return VersionScore{
+		Version:   model.Version,
+		IsActive:  model.IsActive,
+		TrainedTo: model.TrainedTo.UTC(),
+		Accuracy:  metrics["accuracy"],
+		AUC:       metrics["auc"],
+		Brier:     metrics["brier"],
+	}
+}
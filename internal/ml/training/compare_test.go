@@ -0,0 +1,71 @@
+package training
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/models/logreg"
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestCompareVersions(t *testing.T) {
+	now := time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC)
+	rows := makeRows("1h", 420, true)
+	features := &stubFeatureStore{labeled: map[string][]domain.MLFeatureRow{"1h": rows}}
+	registry := newStubRegistry()
+	svc := NewService(nilTracer(), features, registry, Config{Interval: "1h", MinTrainSamples: 200})
+	if _, err := svc.TrainAll(context.Background(), now); err != nil {
+		t.Fatalf("train all failed: %v", err)
+	}
+
+	// Version 2 learns the inverted labels, so it disagrees with version 1
+	// nearly everywhere.
+	samples, labels := buildDataset(rows)
+	for i := range labels {
+		labels[i] = 1 - labels[i]
+	}
+	inverted, err := logreg.Train(samples, labels, common.FeatureNames, logreg.DefaultTrainOptions())
+	if err != nil {
+		t.Fatalf("train inverted: %v", err)
+	}
+	blob, err := inverted.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal inverted: %v", err)
+	}
+	registry.InsertModelVersion(context.Background(), domain.MLModelVersion{ModelKey: common.ModelKeyLogReg, Version: 2, ArtifactBlob: blob})
+
+	cmp, err := svc.CompareVersions(context.Background(), common.ModelKeyLogReg, 1, 2, now.AddDate(0, 0, -90), now)
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	if cmp.Samples != 420 || cmp.A.Version != 1 || cmp.B.Version != 2 || !cmp.A.IsActive || cmp.B.IsActive {
+		t.Fatalf("unexpected comparison %+v", cmp)
+	}
+	if cmp.A.AUC <= cmp.B.AUC || cmp.A.Accuracy <= cmp.B.Accuracy {
+		t.Fatalf("expected version 1 to score better, got a=%+v b=%+v", cmp.A, cmp.B)
+	}
+	if cmp.DisagreementRate < 0.5 {
+		t.Fatalf("expected most calls to differ, got %.2f", cmp.DisagreementRate)
+	}
+
+	same, err := svc.CompareVersions(context.Background(), common.ModelKeyLogReg, 1, 1, now.AddDate(0, 0, -90), now)
+	if err != nil || same.DisagreementRate != 0 || same.A != same.B {
+		t.Fatalf("expected identical scores for the same version, got %+v err=%v", same, err)
+	}
+}
+
+func TestCompareVersionsErrors(t *testing.T) {
+	svc := NewService(nilTracer(), &stubFeatureStore{}, newStubRegistry(), Config{Interval: "1h"})
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, err := svc.CompareVersions(ctx, common.IForestModelKey("1h"), 1, 2, now, now); !errors.Is(err, ErrNotComparable) {
+		t.Fatalf("expected ErrNotComparable, got %v", err)
+	}
+	if _, err := svc.CompareVersions(ctx, common.ModelKeyXGBoost, 1, 2, now, now); !errors.Is(err, ErrModelVersionNotFound) {
+		t.Fatalf("expected ErrModelVersionNotFound, got %v", err)
+	}
+}
@@ -5,16 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"math"
 	"sort"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/ml/common"
 	"bug-free-umbrella/internal/ml/features"
 	"bug-free-umbrella/internal/ml/models/iforest"
 	"bug-free-umbrella/internal/ml/models/logreg"
 	"bug-free-umbrella/internal/ml/models/xgboost"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -31,6 +32,17 @@ type ModelRegistry interface {
 	ActivateModel(ctx context.Context, modelKey string, version int) error
 }
 
+// LabelExclusions lists candles whose labels an operator flagged as bad data
+// to keep out of training.
+type LabelExclusions interface {
+	ListExcludedLabels(ctx context.Context, interval string, from, to time.Time) ([]domain.LabelOverride, error)
+}
+
+// AuditRecorder records model activations in the admin audit log.
+type AuditRecorder interface {
+	Record(ctx context.Context, action, entityType, entityID string, before, after any) error
+}
+
 type Config struct {
 	Interval          string
 	Intervals         []string
@@ -46,6 +58,8 @@ type Service struct {
 	features FeatureRowStore
 	registry ModelRegistry
 	cfg      Config
+	audit    AuditRecorder
+	excluded LabelExclusions
 }
 
 type ModelTrainResult struct {
@@ -110,6 +124,9 @@ func (s *Service) trainDirectional(ctx context.Context, from, now time.Time) ([]
 	if err != nil {
 		return nil, err
 	}
+	if rows, err = s.dropExcluded(ctx, rows, from, now); err != nil {
+		return nil, err
+	}
 	samples, labels := buildDataset(rows)
 	if len(samples) < s.cfg.MinTrainSamples {
 		return nil, fmt.Errorf("not enough labeled samples: got %d need >= %d", len(samples), s.cfg.MinTrainSamples)
@@ -277,7 +294,7 @@ func (s *Service) persistAndMaybePromote(
 		return result, nil
 	}
 	if promote {
-		if err := s.registry.ActivateModel(ctx, modelKey, inserted.Version); err != nil {
+		if err := s.activate(ctx, modelKey, inserted.Version); err != nil {
 			result.PromoteError = err
 			return result, nil
 		}
@@ -333,7 +350,7 @@ func (s *Service) persistAndMaybePromoteAnomaly(
 		return result, nil
 	}
 	if promote {
-		if err := s.registry.ActivateModel(ctx, modelKey, inserted.Version); err != nil {
+		if err := s.activate(ctx, modelKey, inserted.Version); err != nil {
 			result.PromoteError = err
 			return result, nil
 		}
@@ -342,6 +359,64 @@ func (s *Service) persistAndMaybePromoteAnomaly(
 	return result, nil
 }
 
+// SetAuditRecorder enables audit records for model activations.
+func (s *Service) SetAuditRecorder(audit AuditRecorder) {
+	s.audit = audit
+}
+
+// SetLabelExclusions drops flagged candles from directional training.
+func (s *Service) SetLabelExclusions(excluded LabelExclusions) {
+	s.excluded = excluded
+}
+
+// dropExcluded removes the rows of candles flagged to stay out of training.
+func (s *Service) dropExcluded(ctx context.Context, rows []domain.MLFeatureRow, from, to time.Time) ([]domain.MLFeatureRow, error) {
+	if s.excluded == nil {
+		return rows, nil
+	}
+	flagged, err := s.excluded.ListExcludedLabels(ctx, s.cfg.Interval, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list excluded labels: %w", err)
+	}
+	if len(flagged) == 0 {
+		return rows, nil
+	}
+	skip := make(map[string]struct{}, len(flagged))
+	for _, o := range flagged {
+		skip[o.Symbol+"|"+o.OpenTime.UTC().Format(time.RFC3339)] = struct{}{}
+	}
+	kept := rows[:0:0]
+	for _, row := range rows {
+		if _, ok := skip[row.Symbol+"|"+row.OpenTime.UTC().Format(time.RFC3339)]; ok {
+			continue
+		}
+		kept = append(kept, row)
+	}
+	if dropped := len(rows) - len(kept); dropped > 0 {
+		log.Printf("ML training skipped %d flagged %s rows", dropped, s.cfg.Interval)
+	}
+	return kept, nil
+}
+
+func (s *Service) activate(ctx context.Context, modelKey string, version int) error {
+	var before any
+	if s.audit != nil {
+		if active, err := s.registry.GetActiveModel(ctx, modelKey); err == nil && active != nil {
+			before = map[string]any{"model_key": modelKey, "version": active.Version}
+		}
+	}
+	if err := s.registry.ActivateModel(ctx, modelKey, version); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		after := map[string]any{"model_key": modelKey, "version": version}
+		if err := s.audit.Record(ctx, domain.AuditActionModelActivate, domain.AuditEntityModel, modelKey, before, after); err != nil {
+			log.Printf("ml training: audit model activation %s v%d: %v", modelKey, version, err)
+		}
+	}
+	return nil
+}
+
 func (s *Service) shouldPromote(ctx context.Context, modelKey string, newAUC float64, testCount int, newVersion int) (bool, error) {
 	active, err := s.registry.GetActiveModel(ctx, modelKey)
 	if err != nil {
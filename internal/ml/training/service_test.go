@@ -7,7 +7,7 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -90,6 +90,66 @@ func TestShouldPromoteAnomaly(t *testing.T) {
 	}
 }
 
+func TestActivateRecordsAudit(t *testing.T) {
+	registry := newStubRegistry()
+	registry.models[registryModelKey("xgboost", 1)] = &domain.MLModelVersion{ModelKey: "xgboost", Version: 1}
+	registry.models[registryModelKey("xgboost", 2)] = &domain.MLModelVersion{ModelKey: "xgboost", Version: 2}
+	registry.active["xgboost"] = &domain.MLModelVersion{ModelKey: "xgboost", Version: 1, IsActive: true}
+	audit := &stubAuditRecorder{}
+	svc := NewService(nilTracer(), &stubFeatureStore{}, registry, Config{})
+	svc.SetAuditRecorder(audit)
+
+	if err := svc.activate(context.Background(), "xgboost", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(audit.actions) != 1 || audit.actions[0] != domain.AuditActionModelActivate {
+		t.Fatalf("expected one model activation audit, got %v", audit.actions)
+	}
+	before, _ := audit.before[0].(map[string]any)
+	if before["version"] != 1 {
+		t.Fatalf("expected previous active version in before state, got %v", audit.before[0])
+	}
+}
+
+func TestDropExcludedSkipsFlaggedCandles(t *testing.T) {
+	rows := makeRows("1h", 5, true)
+	svc := NewService(nilTracer(), &stubFeatureStore{}, newStubRegistry(), Config{Interval: "1h"})
+	svc.SetLabelExclusions(stubLabelExclusions{
+		{Symbol: "BTC", Interval: "1h", OpenTime: rows[1].OpenTime},
+		{Symbol: "ETH", Interval: "1h", OpenTime: rows[2].OpenTime},
+	})
+
+	kept, err := svc.dropExcluded(context.Background(), rows, rows[0].OpenTime, rows[4].OpenTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 4 || len(rows) != 5 {
+		t.Fatalf("expected 4 kept rows of 5, got %d of %d", len(kept), len(rows))
+	}
+	for _, row := range kept {
+		if row.OpenTime.Equal(rows[1].OpenTime) {
+			t.Fatalf("flagged BTC candle was kept")
+		}
+	}
+}
+
+type stubLabelExclusions []domain.LabelOverride
+
+func (s stubLabelExclusions) ListExcludedLabels(context.Context, string, time.Time, time.Time) ([]domain.LabelOverride, error) {
+	return s, nil
+}
+
+type stubAuditRecorder struct {
+	actions []string
+	before  []any
+}
+
+func (s *stubAuditRecorder) Record(_ context.Context, action, _, _ string, before, _ any) error {
+	s.actions = append(s.actions, action)
+	s.before = append(s.before, before)
+	return nil
+}
+
 type stubFeatureStore struct {
 	labeled map[string][]domain.MLFeatureRow
 	rows    map[string][]domain.MLFeatureRow
@@ -144,6 +204,18 @@ func (s *stubRegistry) GetActiveModel(_ context.Context, modelKey string) (*doma
 	return nil, nil
 }
 
+func (s *stubRegistry) GetModelVersion(_ context.Context, modelKey string, version int) (*domain.MLModelVersion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if model, ok := s.models[registryModelKey(modelKey, version)]; ok {
+		copyModel := *model
+		active, ok := s.active[modelKey]
+		copyModel.IsActive = ok && active.Version == version
+		return &copyModel, nil
+	}
+	return nil, nil
+}
+
 func (s *stubRegistry) ActivateModel(_ context.Context, modelKey string, version int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	binanceBaseURL = "https://api.binance.com"
+	// binanceKlineLimit is the most klines Binance returns per request.
+	binanceKlineLimit = 1000
+	// binanceMaxAttempts bounds how often one call is retried after 429s.
+	binanceMaxAttempts = 3
+)
+
+// BinanceProvider fetches prices and klines from the Binance spot API. Pairs
+// are quoted in USDT, which is treated as USD.
+type BinanceProvider struct {
+	client  *http.Client
+	baseURL string
+	tracer  trace.Tracer
+	limiter *RateLimiter
+	backoff *Backoff
+	now     func() time.Time
+}
+
+// NewBinanceProvider creates a new provider with built-in rate limiting.
+// Binance allows far more than CoinGecko; 10 requests per second with a
+// burst of 20 stays well inside the request weight limit.
+func NewBinanceProvider(tracer trace.Tracer) *BinanceProvider {
+	return &BinanceProvider{
+		client:  httpclient.New(httpclient.Binance, 30*time.Second),
+		baseURL: binanceBaseURL,
+		tracer:  tracer,
+		limiter: NewRateLimiter(20, 100*time.Millisecond),
+		backoff: NewBackoff(5*time.Second, 2*time.Minute),
+		now:     time.Now,
+	}
+}
+
+// FetchPrices fetches the 24h tickers of all supported assets in a single API call.
+func (p *BinanceProvider) FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error) {
+	_, span := p.tracer.Start(ctx, "binance.fetch-prices")
+	defer span.End()
+
+	tracked := domain.BinancePairs()
+	symbolByPair := make(map[string]string, len(tracked))
+	pairs := make([]string, 0, len(tracked))
+	for symbol, pair := range tracked {
+		symbolByPair[pair] = symbol
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	list, err := json.Marshal(pairs)
+	if err != nil {
+		return nil, fmt.Errorf("encode pairs: %w", err)
+	}
+
+	body, err := p.doRequest(ctx, fmt.Sprintf("%s/api/v3/ticker/24hr?symbols=%s", p.baseURL, url.QueryEscape(string(list))))
+	if err != nil {
+		return nil, fmt.Errorf("fetch prices: %w", err)
+	}
+
+	// Response shape: [{"symbol":"BTCUSDT","lastPrice":"97000.01","priceChangePercent":"2.340","quoteVolume":"45000000000.5",...}, ...]
+	var raw []struct {
+		Symbol             string `json:"symbol"`
+		LastPrice          string `json:"lastPrice"`
+		PriceChangePercent string `json:"priceChangePercent"`
+		QuoteVolume        string `json:"quoteVolume"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse prices: %w", err)
+	}
+
+	now := p.now().Unix()
+	result := make(map[string]*domain.PriceSnapshot, len(raw))
+	for _, t := range raw {
+		symbol, ok := symbolByPair[t.Symbol]
+		if !ok {
+			continue
+		}
+		price, err := strconv.ParseFloat(t.LastPrice, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price for %s: %w", symbol, err)
+		}
+		change, _ := strconv.ParseFloat(t.PriceChangePercent, 64)
+		volume, _ := strconv.ParseFloat(t.QuoteVolume, 64)
+		result[symbol] = &domain.PriceSnapshot{
+			Symbol:          symbol,
+			PriceUSD:        price,
+			Volume24h:       domain.Money(volume),
+			Change24hPct:    domain.Percent(change),
+			LastUpdatedUnix: now,
+		}
+	}
+
+	return result, nil
+}
+
+// FetchMarketChart fetches the last days of klines for each interval. Unlike
+// CoinGecko, Binance serves every interval at its native resolution, so the
+// candles are exchange OHLCV rather than rebuilt from price samples. Volume
+// is the quote (USDT) volume, matching the USD volumes CoinGecko reports.
+// Klines are paged binanceKlineLimit at a time, so any number of days can be
+// fetched; history starts at the pair's listing.
+func (p *BinanceProvider) FetchMarketChart(ctx context.Context, symbol string, days int, intervals []string) ([]*domain.Candle, error) {
+	ctx, span := p.tracer.Start(ctx, "binance.fetch-market-chart")
+	defer span.End()
+
+	asset, _ := domain.LookupAsset(symbol)
+	pair := asset.BinancePair
+	if pair == "" {
+		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+
+	now := p.now()
+	from := now.AddDate(0, 0, -days)
+	var allCandles []*domain.Candle
+	for _, interval := range intervals {
+		step := domain.IntervalDuration(interval)
+		if step == 0 {
+			continue
+		}
+		for start := from; start.Before(now); {
+			batch, err := p.fetchKlines(ctx, symbol, pair, interval, start)
+			if err != nil {
+				return nil, fmt.Errorf("fetch klines for %s %s: %w", symbol, interval, err)
+			}
+			allCandles = append(allCandles, batch...)
+			if len(batch) < binanceKlineLimit {
+				break
+			}
+			start = batch[len(batch)-1].OpenTime.Add(step)
+		}
+	}
+
+	return allCandles, nil
+}
+
+func (p *BinanceProvider) fetchKlines(ctx context.Context, symbol, pair, interval string, start time.Time) ([]*domain.Candle, error) {
+	endpoint := fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&startTime=%d&limit=%d",
+		p.baseURL, pair, interval, start.UnixMilli(), binanceKlineLimit)
+
+	body, err := p.doRequest(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each kline is [openTime, "open", "high", "low", "close", "volume",
+	// closeTime, "quoteVolume", trades, ...].
+	var raw [][]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse klines: %w", err)
+	}
+
+	candles := make([]*domain.Candle, 0, len(raw))
+	for _, k := range raw {
+		if len(k) < 8 {
+			return nil, fmt.Errorf("parse klines: short kline with %d fields", len(k))
+		}
+		var openMs int64
+		if err := json.Unmarshal(k[0], &openMs); err != nil {
+			return nil, fmt.Errorf("parse kline open time: %w", err)
+		}
+		var values [5]float64
+		for i, field := range []json.RawMessage{k[1], k[2], k[3], k[4], k[7]} {
+			var s string
+			if err := json.Unmarshal(field, &s); err != nil {
+				return nil, fmt.Errorf("parse kline: %w", err)
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse kline: %w", err)
+			}
+			values[i] = v
+		}
+		candles = append(candles, &domain.Candle{
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: time.UnixMilli(openMs).UTC(),
+			Open:     values[0],
+			High:     values[1],
+			Low:      values[2],
+			Close:    values[3],
+			Volume:   values[4],
+		})
+	}
+	return candles, nil
+}
+
+// doRequest waits for a rate-limit token and any backoff, then sends the
+// request. A 429, or the 418 Binance sends to clients that keep going after
+// one, starts or extends the backoff and the request is retried up to
+// binanceMaxAttempts times.
+func (p *BinanceProvider) doRequest(ctx context.Context, endpoint string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		if err := p.backoff.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit backoff: %w", err)
+		}
+		if err := p.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+			p.backoff.Throttled(parseRetryAfter(resp.Header, time.Now()))
+			if attempt < binanceMaxAttempts {
+				continue
+			}
+			return nil, fmt.Errorf("binance API error %d after %d attempts: %s", resp.StatusCode, attempt, string(body))
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("binance API error %d: %s", resp.StatusCode, string(body))
+		}
+		p.backoff.Recovered()
+		return body, nil
+	}
+}
@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const binanceFuturesBaseURL = "https://fapi.binance.com"
+
+// BinanceFuturesProvider fetches funding rates and open interest from the
+// Binance USDT-margined perpetual futures API. Perpetuals share the spot
+// pair names, so symbols resolve through the same BinancePair.
+type BinanceFuturesProvider struct {
+	api *BinanceProvider
+}
+
+// NewBinanceFuturesProvider creates a provider for the futures host. Each
+// snapshot costs two requests of weight 1, far inside the futures limits.
+func NewBinanceFuturesProvider(tracer trace.Tracer) *BinanceFuturesProvider {
+	return &BinanceFuturesProvider{api: &BinanceProvider{
+		client:  httpclient.New(httpclient.BinanceFutures, 30*time.Second),
+		baseURL: binanceFuturesBaseURL,
+		tracer:  tracer,
+		limiter: NewRateLimiter(10, 100*time.Millisecond),
+		now:     time.Now,
+	}}
+}
+
+// FetchDerivatives fetches symbol's current funding rate, mark price and
+// open interest.
+func (p *BinanceFuturesProvider) FetchDerivatives(ctx context.Context, symbol string) (*domain.DerivativesSnapshot, error) {
+	ctx, span := p.api.tracer.Start(ctx, "binance-futures.fetch-derivatives")
+	defer span.End()
+
+	asset, _ := domain.LookupAsset(symbol)
+	if asset.BinancePair == "" {
+		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+
+	body, err := p.api.doRequest(ctx, fmt.Sprintf("%s/fapi/v1/premiumIndex?symbol=%s", p.api.baseURL, asset.BinancePair))
+	if err != nil {
+		return nil, fmt.Errorf("fetch premium index: %w", err)
+	}
+	// Response shape: {"symbol":"BTCUSDT","markPrice":"97000.1","lastFundingRate":"0.0001","nextFundingTime":1700006400000,...}
+	var premium struct {
+		MarkPrice       string `json:"markPrice"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := json.Unmarshal(body, &premium); err != nil {
+		return nil, fmt.Errorf("parse premium index: %w", err)
+	}
+	mark, err := strconv.ParseFloat(premium.MarkPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse mark price: %w", err)
+	}
+	funding, err := strconv.ParseFloat(premium.LastFundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse funding rate: %w", err)
+	}
+
+	body, err = p.api.doRequest(ctx, fmt.Sprintf("%s/fapi/v1/openInterest?symbol=%s", p.api.baseURL, asset.BinancePair))
+	if err != nil {
+		return nil, fmt.Errorf("fetch open interest: %w", err)
+	}
+	// Response shape: {"symbol":"BTCUSDT","openInterest":"81234.567","time":1700000000000}
+	var oi struct {
+		OpenInterest string `json:"openInterest"`
+	}
+	if err := json.Unmarshal(body, &oi); err != nil {
+		return nil, fmt.Errorf("parse open interest: %w", err)
+	}
+	openInterest, err := strconv.ParseFloat(oi.OpenInterest, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse open interest: %w", err)
+	}
+
+	out := &domain.DerivativesSnapshot{
+		Symbol:          symbol,
+		Source:          "binance",
+		CapturedAt:      p.api.now().UTC(),
+		MarkPrice:       mark,
+		FundingRate:     funding,
+		OpenInterest:    openInterest,
+		OpenInterestUSD: openInterest * mark,
+	}
+	if premium.NextFundingTime > 0 {
+		out.NextFundingTime = time.UnixMilli(premium.NextFundingTime).UTC()
+	}
+	return out, nil
+}
@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBinanceFuturesProviderFetchDerivatives(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	spot := newTestBinanceProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Query().Get("symbol") != "BTCUSDT" {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		switch req.URL.Path {
+		case "/fapi/v1/premiumIndex":
+			return jsonResponse(`{"symbol":"BTCUSDT","markPrice":"100.00","indexPrice":"99.9","lastFundingRate":"-0.00025","nextFundingTime":1700006400000,"time":1700000000000}`), nil
+		case "/fapi/v1/openInterest":
+			return jsonResponse(`{"symbol":"BTCUSDT","openInterest":"1500.5","time":1700000000000}`), nil
+		}
+		t.Fatalf("unexpected path: %s", req.URL.Path)
+		return nil, nil
+	}, now)
+	provider := &BinanceFuturesProvider{api: spot}
+
+	snap, err := provider.FetchDerivatives(context.Background(), "BTC")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap.Symbol != "BTC" || snap.Source != "binance" || !snap.CapturedAt.Equal(now) {
+		t.Fatalf("unexpected snapshot identity: %+v", snap)
+	}
+	if snap.FundingRate != -0.00025 || snap.MarkPrice != 100 || snap.OpenInterest != 1500.5 {
+		t.Fatalf("unexpected snapshot values: %+v", snap)
+	}
+	if math.Abs(snap.OpenInterestUSD-150050) > 1e-6 || !snap.NextFundingTime.Equal(time.UnixMilli(1700006400000)) {
+		t.Fatalf("unexpected derived values: %+v", snap)
+	}
+
+	if _, err := provider.FetchDerivatives(context.Background(), "NOPE"); err == nil {
+		t.Fatal("expected unsupported symbol error")
+	}
+}
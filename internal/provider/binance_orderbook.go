@@ -0,0 +1,74 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// binanceDepthLimit is how many levels per side one depth request returns.
+// 100 levels cover well over 1% either side of the mid on every tracked
+// pair and cost a request weight of 5.
+const binanceDepthLimit = 100
+
+// FetchOrderBook fetches the top of symbol's order book and summarizes the
+// depth within bandPct of the mid price.
+func (p *BinanceProvider) FetchOrderBook(ctx context.Context, symbol string, bandPct float64) (*domain.OrderBookSnapshot, error) {
+	ctx, span := p.tracer.Start(ctx, "binance.fetch-order-book")
+	defer span.End()
+
+	asset, _ := domain.LookupAsset(symbol)
+	if asset.BinancePair == "" {
+		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+
+	body, err := p.doRequest(ctx, fmt.Sprintf("%s/api/v3/depth?symbol=%s&limit=%d", p.baseURL, asset.BinancePair, binanceDepthLimit))
+	if err != nil {
+		return nil, fmt.Errorf("fetch order book: %w", err)
+	}
+
+	// Response shape: {"lastUpdateId":1,"bids":[["97000.01","0.5"],...],"asks":[["97000.02","1.2"],...]}
+	var raw struct {
+		Bids [][2]string `json:"bids"`
+		Asks [][2]string `json:"asks"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse order book: %w", err)
+	}
+	bids, err := parseBinanceLevels(raw.Bids)
+	if err != nil {
+		return nil, fmt.Errorf("parse order book bids: %w", err)
+	}
+	asks, err := parseBinanceLevels(raw.Asks)
+	if err != nil {
+		return nil, fmt.Errorf("parse order book asks: %w", err)
+	}
+
+	snapshot, ok := domain.SummarizeOrderBook(bids, asks, bandPct)
+	if !ok {
+		return nil, fmt.Errorf("order book for %s is empty or crossed", symbol)
+	}
+	snapshot.Symbol = symbol
+	snapshot.Source = "binance"
+	snapshot.CapturedAt = p.now().UTC()
+	return &snapshot, nil
+}
+
+func parseBinanceLevels(raw [][2]string) ([]domain.OrderBookLevel, error) {
+	levels := make([]domain.OrderBookLevel, 0, len(raw))
+	for _, l := range raw {
+		price, err := strconv.ParseFloat(l[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		qty, err := strconv.ParseFloat(l[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, domain.OrderBookLevel{Price: price, Quantity: qty})
+	}
+	return levels, nil
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBinanceProviderFetchOrderBook(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestBinanceProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/v3/depth" || req.URL.Query().Get("symbol") != "BTCUSDT" {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		return jsonResponse(`{"lastUpdateId":1,
+			"bids":[["99.90","3"],["99.50","1"],["90.00","100"]],
+			"asks":[["100.10","1"],["100.50","1"],["110.00","100"]]}`), nil
+	}, time.Unix(1700000000, 0))
+
+	book, err := provider.FetchOrderBook(context.Background(), "BTC", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if book.Symbol != "BTC" || book.Source != "binance" || !book.CapturedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected snapshot identity: %+v", book)
+	}
+	if book.BestBid != 99.9 || book.BestAsk != 100.1 || math.Abs(book.SpreadBps-20) > 1e-9 {
+		t.Fatalf("unexpected top of book: %+v", book)
+	}
+	// Levels outside 1% of the 100 mid are ignored.
+	if math.Abs(book.BidDepthUSD-399.2) > 1e-9 || math.Abs(book.AskDepthUSD-200.6) > 1e-9 {
+		t.Fatalf("unexpected depth: %+v", book)
+	}
+	if book.Imbalance <= 0 {
+		t.Fatalf("expected bid-heavy imbalance, got %f", book.Imbalance)
+	}
+
+	if _, err := provider.FetchOrderBook(context.Background(), "NOPE", 1); err == nil {
+		t.Fatal("expected unsupported symbol error")
+	}
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	binanceStreamURL = "wss://stream.binance.com:9443/stream"
+
+	// binanceStreamReadTimeout drops a connection that has gone quiet. Mini
+	// tickers arrive about once a second while any pair trades.
+	binanceStreamReadTimeout = time.Minute
+	binanceStreamMinBackoff  = time.Second
+	binanceStreamMaxBackoff  = time.Minute
+)
+
+// BinanceStream pushes 24h mini-ticker updates for the supported pairs from
+// the Binance combined WebSocket stream, as a real-time complement to
+// polling. Like BinanceProvider it treats USDT as USD.
+type BinanceStream struct {
+	url        string
+	dialer     *websocket.Dialer
+	tracer     trace.Tracer
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewBinanceStream creates a stream client. An empty url uses the public
+// Binance endpoint. Connections honor the outbound proxy and TLS settings for
+// the binance provider.
+func NewBinanceStream(tracer trace.Tracer, url string) *BinanceStream {
+	if url == "" {
+		url = binanceStreamURL
+	}
+	transport := httpclient.Transport(httpclient.Binance)
+	return &BinanceStream{
+		url: url,
+		dialer: &websocket.Dialer{
+			Proxy:            transport.Proxy,
+			TLSClientConfig:  transport.TLSClientConfig,
+			HandshakeTimeout: 15 * time.Second,
+		},
+		tracer:     tracer,
+		minBackoff: binanceStreamMinBackoff,
+		maxBackoff: binanceStreamMaxBackoff,
+	}
+}
+
+// Run streams ticks to onTick until ctx is cancelled. Dropped or failed
+// connections are retried with exponential backoff and jitter; the backoff
+// resets once a connection delivers a tick.
+func (s *BinanceStream) Run(ctx context.Context, onTick func(*domain.PriceSnapshot)) {
+	backoff := s.minBackoff
+	for ctx.Err() == nil {
+		delivered, err := s.streamOnce(ctx, onTick)
+		if ctx.Err() != nil {
+			return
+		}
+		if delivered {
+			backoff = s.minBackoff
+		}
+		wait := backoff/2 + rand.N(backoff/2+1)
+		log.Printf("binance stream disconnected, reconnecting in %s: %v", wait.Round(time.Millisecond), err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, s.maxBackoff)
+	}
+}
+
+// streamOnce holds one connection open until it fails or ctx is cancelled,
+// reporting whether any tick was delivered.
+func (s *BinanceStream) streamOnce(ctx context.Context, onTick func(*domain.PriceSnapshot)) (bool, error) {
+	_, span := s.tracer.Start(ctx, "binance-stream.connect")
+	version := domain.AssetsVersion()
+	tracked := domain.BinancePairs()
+	symbolByPair := make(map[string]string, len(tracked))
+	streams := make([]string, 0, len(tracked))
+	for symbol, pair := range tracked {
+		symbolByPair[pair] = symbol
+		streams = append(streams, strings.ToLower(pair)+"@miniTicker")
+	}
+	sort.Strings(streams)
+	span.SetAttributes(attribute.Int("binance_stream.streams", len(streams)))
+
+	conn, resp, err := s.dialer.DialContext(ctx, s.url+"?streams="+strings.Join(streams, "/"), http.Header{})
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.End()
+		return false, fmt.Errorf("dial: %w", err)
+	}
+	span.End()
+	defer conn.Close()
+
+	// Unblock ReadMessage when the caller stops the stream.
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	conn.SetPingHandler(func(data string) error {
+		_ = conn.SetReadDeadline(time.Now().Add(binanceStreamReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second))
+	})
+
+	delivered := false
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(binanceStreamReadTimeout)); err != nil {
+			return delivered, err
+		}
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return delivered, fmt.Errorf("read: %w", err)
+		}
+		// Resubscribe when symbols are added or removed.
+		if domain.AssetsVersion() != version {
+			return delivered, errors.New("tracked assets changed")
+		}
+		snap, err := parseBinanceMiniTicker(msg, symbolByPair)
+		if err != nil {
+			log.Printf("binance stream: %v", err)
+			continue
+		}
+		if snap == nil {
+			continue
+		}
+		delivered = true
+		onTick(snap)
+	}
+}
+
+// parseBinanceMiniTicker decodes one combined-stream message. It returns nil
+// for pairs that are not tracked.
+func parseBinanceMiniTicker(msg []byte, symbolByPair map[string]string) (*domain.PriceSnapshot, error) {
+	// Message shape: {"stream":"btcusdt@miniTicker","data":{"E":1700000000000,"s":"BTCUSDT","c":"97000.01","o":"95000.00","q":"45000000000.5",...}}
+	var envelope struct {
+		Data struct {
+			EventTime   int64  `json:"E"`
+			Symbol      string `json:"s"`
+			Close       string `json:"c"`
+			Open        string `json:"o"`
+			QuoteVolume string `json:"q"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return nil, fmt.Errorf("parse mini ticker: %w", err)
+	}
+	t := envelope.Data
+	symbol, ok := symbolByPair[t.Symbol]
+	if !ok {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(t.Close, 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("parse price for %s: %q", symbol, t.Close)
+	}
+	open, _ := strconv.ParseFloat(t.Open, 64)
+	volume, _ := strconv.ParseFloat(t.QuoteVolume, 64)
+	change := 0.0
+	if open > 0 {
+		change = (price/open - 1) * 100
+	}
+	return &domain.PriceSnapshot{
+		Symbol:          symbol,
+		PriceUSD:        price,
+		Volume24h:       domain.Money(volume),
+		Change24hPct:    domain.Percent(change),
+		LastUpdatedUnix: t.EventTime / 1000,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestBinanceStreamReconnectsAndParsesTicks(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("streams"), "btcusdt@miniTicker") {
+			t.Errorf("unexpected streams %q", r.URL.Query().Get("streams"))
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := connections.Add(1)
+		msgs := []string{
+			`{"stream":"shibusdt@miniTicker","data":{"E":1700000000000,"s":"SHIBUSDT","c":"0.00001","o":"0.00001","q":"1"}}`,
+			`{"stream":"btcusdt@miniTicker","data":{"E":1700000000000,"s":"BTCUSDT","c":"102000.00","o":"100000.00","q":"45000000000.5"}}`,
+		}
+		if n > 1 {
+			msgs = []string{`{"stream":"ethusdt@miniTicker","data":{"E":1700000060000,"s":"ETHUSDT","c":"3000","o":"3000","q":"1"}}`}
+		}
+		for _, m := range msgs {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(m)); err != nil {
+				return
+			}
+		}
+		// Dropping the first connection forces a reconnect.
+		if n > 1 {
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+
+	stream := NewBinanceStream(trace.NewNoopTracerProvider().Tracer("test"), "ws"+strings.TrimPrefix(srv.URL, "http"))
+	stream.minBackoff = time.Millisecond
+	stream.maxBackoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ticks := make(chan *domain.PriceSnapshot, 4)
+	done := make(chan struct{})
+	go func() {
+		stream.Run(ctx, func(s *domain.PriceSnapshot) { ticks <- s })
+		close(done)
+	}()
+
+	btc := <-ticks
+	if btc.Symbol != "BTC" || btc.PriceUSD != 102000 || btc.LastUpdatedUnix != 1700000000 {
+		t.Fatalf("unexpected BTC tick %+v", btc)
+	}
+	if got := float64(btc.Change24hPct); got < 1.999 || got > 2.001 {
+		t.Fatalf("expected +2%% change, got %v", got)
+	}
+	select {
+	case eth := <-ticks:
+		if eth.Symbol != "ETH" {
+			t.Fatalf("expected ETH after reconnect, got %+v", eth)
+		}
+	case <-ctx.Done():
+		t.Fatal("no tick after reconnect")
+	}
+	cancel()
+	<-done
+}
+
+func TestParseBinanceMiniTickerRejectsBadPrice(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseBinanceMiniTicker([]byte(`{"data":{"s":"BTCUSDT","c":"oops"}}`), map[string]string{"BTCUSDT": "BTC"})
+	if err == nil {
+		t.Fatal("expected an error for an unparseable price")
+	}
+}
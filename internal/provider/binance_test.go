@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestBinanceProvider(rt roundTripFunc, now time.Time) *BinanceProvider {
+	provider := NewBinanceProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.baseURL = "http://example"
+	provider.client = &http.Client{Transport: rt}
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+	provider.backoff = NewBackoff(time.Millisecond, 5*time.Millisecond)
+	provider.now = func() time.Time { return now }
+	return provider
+}
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+		Header:     make(http.Header),
+	}
+}
+
+func TestBinanceProviderFetchPrices(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestBinanceProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/v3/ticker/24hr" {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		if symbols := req.URL.Query().Get("symbols"); !strings.Contains(symbols, `"BTCUSDT"`) || !strings.Contains(symbols, `"POLUSDT"`) {
+			t.Fatalf("expected every supported pair, got %s", symbols)
+		}
+		return jsonResponse(`[
+			{"symbol":"BTCUSDT","lastPrice":"97000.50","priceChangePercent":"-1.25","quoteVolume":"1234.5"},
+			{"symbol":"POLUSDT","lastPrice":"0.42","priceChangePercent":"3.0","quoteVolume":"10"},
+			{"symbol":"BNBUSDT","lastPrice":"600","priceChangePercent":"0","quoteVolume":"1"}
+		]`), nil
+	}, time.Unix(1700000000, 0))
+
+	result, err := provider.FetchPrices(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected unknown pairs dropped, got %+v", result)
+	}
+	btc := result["BTC"]
+	if btc == nil || btc.PriceUSD != 97000.5 || btc.Change24hPct != -1.25 || btc.Volume24h != 1234.5 || btc.LastUpdatedUnix != 1700000000 {
+		t.Fatalf("unexpected BTC snapshot: %+v", btc)
+	}
+	if matic := result["MATIC"]; matic == nil || matic.PriceUSD != 0.42 {
+		t.Fatalf("expected POLUSDT reported as MATIC, got %+v", matic)
+	}
+}
+
+func TestBinanceProviderFetchMarketChartPaginates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	var starts []int64
+	provider := newTestBinanceProvider(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if req.URL.Path != "/api/v3/klines" || q.Get("symbol") != "ETHUSDT" || q.Get("interval") != "5m" {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+		starts = append(starts, start)
+		// Serve every 5m kline from startTime to now, at most a page at a time.
+		var rows []string
+		for ts := start; ts < now.UnixMilli() && len(rows) < binanceKlineLimit; ts += (5 * time.Minute).Milliseconds() {
+			rows = append(rows, fmt.Sprintf(`[%d,"10","12","9","11","100",%d,"1100",5,"50","550","0"]`, ts, ts+299999))
+		}
+		return jsonResponse("[" + strings.Join(rows, ",") + "]"), nil
+	}, now)
+
+	candles, err := provider.FetchMarketChart(context.Background(), "ETH", 7, []string{"5m"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 7 days of 5m klines is 2016, fetched in three pages.
+	if len(candles) != 2016 || len(starts) != 3 {
+		t.Fatalf("expected 2016 candles over 3 requests, got %d over %d", len(candles), len(starts))
+	}
+	if starts[0] != now.AddDate(0, 0, -7).UnixMilli() || starts[1] != candles[binanceKlineLimit].OpenTime.UnixMilli() {
+		t.Fatalf("unexpected page starts %v", starts)
+	}
+	first := candles[0]
+	if first.Symbol != "ETH" || first.Interval != "5m" || first.Open != 10 || first.High != 12 || first.Low != 9 || first.Close != 11 || first.Volume != 1100 {
+		t.Fatalf("unexpected candle: %+v", first)
+	}
+	if !candles[len(candles)-1].OpenTime.Equal(now.Add(-5 * time.Minute)) {
+		t.Fatalf("unexpected last open time %s", candles[len(candles)-1].OpenTime)
+	}
+}
+
+func TestBinanceProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestBinanceProvider(func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       io.NopCloser(strings.NewReader(`{"code":-1003}`)),
+			Header:     make(http.Header),
+		}, nil
+	}, time.Now())
+
+	if _, err := provider.FetchMarketChart(context.Background(), "SHIB", 1, []string{"1h"}); err == nil || !strings.Contains(err.Error(), "unsupported symbol") {
+		t.Fatalf("expected unsupported symbol error, got %v", err)
+	}
+	if _, err := provider.FetchPrices(context.Background()); err == nil || !strings.Contains(err.Error(), "binance API error 429") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
+
+func TestBinanceProviderRetriesAfterThrottling(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	provider := newTestBinanceProvider(func(*http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return &http.Response{
+				StatusCode: http.StatusTeapot,
+				Body:       io.NopCloser(strings.NewReader(`{"code":-1003}`)),
+				Header:     make(http.Header),
+			}, nil
+		}
+		return jsonResponse(`[[1700000000000,"10","12","9","11","100",1700003599999,"1100",5,"50","550","0"]]`), nil
+	}, time.UnixMilli(1700003600000))
+
+	candles, err := provider.FetchMarketChart(context.Background(), "BTC", 1, []string{"1h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || len(candles) != 1 {
+		t.Fatalf("expected one retry and one candle, got %d calls %d candles", calls, len(candles))
+	}
+	if strikes, _ := provider.backoff.State(); strikes != 0 {
+		t.Fatalf("expected the backoff to clear after a success, got %d strikes", strikes)
+	}
+}
@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	coinbaseBaseURL = "https://api.coinbase.com"
+	// coinbaseCandleLimit is the most candles Coinbase returns per request.
+	coinbaseCandleLimit = 350
+	// coinbaseJWTLifetime is how long a request token stays valid; Coinbase
+	// rejects tokens that live longer than two minutes.
+	coinbaseJWTLifetime = 2 * time.Minute
+)
+
+// coinbaseGranularity maps supported intervals to Coinbase candle
+// granularities.
+var coinbaseGranularity = map[string]string{
+	"5m":  "FIVE_MINUTE",
+	"15m": "FIFTEEN_MINUTE",
+	"1h":  "ONE_HOUR",
+	"4h":  "FOUR_HOUR",
+	"1d":  "ONE_DAY",
+}
+
+// CoinbaseProvider fetches prices and candles from the Coinbase Advanced
+// Trade API. Without credentials it uses the public market endpoints; with a
+// CDP API key it signs requests to the brokerage endpoints, which have higher
+// rate limits.
+type CoinbaseProvider struct {
+	client  *http.Client
+	baseURL string
+	tracer  trace.Tracer
+	limiter *RateLimiter
+	now     func() time.Time
+
+	keyName string
+	key     *ecdsa.PrivateKey
+}
+
+// NewCoinbaseProvider creates a new provider with built-in rate limiting.
+// Coinbase allows 10 public requests per second.
+func NewCoinbaseProvider(tracer trace.Tracer) *CoinbaseProvider {
+	return &CoinbaseProvider{
+		client:  httpclient.New(httpclient.Coinbase, 30*time.Second),
+		baseURL: coinbaseBaseURL,
+		tracer:  tracer,
+		limiter: NewRateLimiter(10, 100*time.Millisecond),
+		now:     time.Now,
+	}
+}
+
+// SetCredentials signs subsequent requests with a CDP API key. keyName is
+// the key's full name ("organizations/.../apiKeys/...") and privateKeyPEM its
+// EC private key; escaped "\n" sequences, as env files tend to hold them,
+// are accepted.
+func (p *CoinbaseProvider) SetCredentials(keyName, privateKeyPEM string) error {
+	keyName = strings.TrimSpace(keyName)
+	if keyName == "" {
+		return errors.New("coinbase API key name is empty")
+	}
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(strings.TrimSpace(privateKeyPEM), `\n`, "\n")))
+	if block == nil {
+		return errors.New("coinbase API private key is not PEM encoded")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		parsed, pkcs8Err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if pkcs8Err != nil {
+			return fmt.Errorf("parse coinbase API private key: %w", err)
+		}
+		ecKey, ok := parsed.(*ecdsa.PrivateKey)
+		if !ok {
+			return errors.New("coinbase API private key is not an EC key")
+		}
+		key = ecKey
+	}
+	if key.Curve != elliptic.P256() {
+		return errors.New("coinbase API private key must use P-256")
+	}
+	p.keyName = keyName
+	p.key = key
+	return nil
+}
+
+// productsPath returns the products endpoint, public or authenticated.
+func (p *CoinbaseProvider) productsPath() string {
+	if p.key != nil {
+		return "/api/v3/brokerage/products"
+	}
+	return "/api/v3/brokerage/market/products"
+}
+
+// FetchPrices fetches the products of all supported assets in a single API
+// call. Coinbase reports base volumes, which are converted to USD at the
+// current price.
+func (p *CoinbaseProvider) FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error) {
+	_, span := p.tracer.Start(ctx, "coinbase.fetch-prices")
+	defer span.End()
+
+	tracked := domain.CoinbasePairs()
+	symbolByPair := make(map[string]string, len(tracked))
+	pairs := make([]string, 0, len(tracked))
+	for symbol, pair := range tracked {
+		symbolByPair[pair] = symbol
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+	query := url.Values{"product_ids": pairs}
+
+	body, err := p.doRequest(ctx, p.baseURL+p.productsPath()+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("fetch prices: %w", err)
+	}
+
+	// Response shape: {"products":[{"product_id":"BTC-USD","price":"97000.01","price_percentage_change_24h":"2.34","volume_24h":"12000.5",...}, ...]}
+	var raw struct {
+		Products []struct {
+			ProductID string `json:"product_id"`
+			Price     string `json:"price"`
+			Change24h string `json:"price_percentage_change_24h"`
+			Volume24h string `json:"volume_24h"`
+		} `json:"products"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse prices: %w", err)
+	}
+
+	now := p.now().Unix()
+	result := make(map[string]*domain.PriceSnapshot, len(raw.Products))
+	for _, t := range raw.Products {
+		symbol, ok := symbolByPair[t.ProductID]
+		if !ok {
+			continue
+		}
+		price, err := strconv.ParseFloat(t.Price, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price for %s: %w", symbol, err)
+		}
+		change, _ := strconv.ParseFloat(t.Change24h, 64)
+		volume, _ := strconv.ParseFloat(t.Volume24h, 64)
+		result[symbol] = &domain.PriceSnapshot{
+			Symbol:          symbol,
+			PriceUSD:        price,
+			Volume24h:       domain.Money(volume * price),
+			Change24hPct:    domain.Percent(change),
+			LastUpdatedUnix: now,
+		}
+	}
+
+	return result, nil
+}
+
+// FetchMarketChart fetches the last days of candles for each interval at the
+// interval's native resolution, paging through Coinbase's 350-candle limit.
+// Volume is converted to USD at each candle's close.
+func (p *CoinbaseProvider) FetchMarketChart(ctx context.Context, symbol string, days int, intervals []string) ([]*domain.Candle, error) {
+	ctx, span := p.tracer.Start(ctx, "coinbase.fetch-market-chart")
+	defer span.End()
+
+	asset, _ := domain.LookupAsset(symbol)
+	pair := asset.CoinbasePair
+	if pair == "" {
+		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+
+	now := p.now()
+	from := now.AddDate(0, 0, -days)
+	var allCandles []*domain.Candle
+	for _, interval := range intervals {
+		granularity, ok := coinbaseGranularity[interval]
+		if !ok {
+			continue
+		}
+		window := domain.IntervalDuration(interval) * coinbaseCandleLimit
+		for start := from; start.Before(now); start = start.Add(window) {
+			end := start.Add(window)
+			if end.After(now) {
+				end = now
+			}
+			batch, err := p.fetchCandles(ctx, symbol, pair, interval, granularity, start, end)
+			if err != nil {
+				return nil, fmt.Errorf("fetch candles for %s %s: %w", symbol, interval, err)
+			}
+			allCandles = append(allCandles, batch...)
+		}
+	}
+
+	return allCandles, nil
+}
+
+func (p *CoinbaseProvider) fetchCandles(ctx context.Context, symbol, pair, interval, granularity string, start, end time.Time) ([]*domain.Candle, error) {
+	endpoint := fmt.Sprintf("%s%s/%s/candles?start=%d&end=%d&granularity=%s",
+		p.baseURL, p.productsPath(), pair, start.Unix(), end.Unix(), granularity)
+
+	body, err := p.doRequest(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Response shape: {"candles":[{"start":"1700000000","low":"9","high":"12","open":"10","close":"11","volume":"100"}, ...]}, newest first.
+	var raw struct {
+		Candles []struct {
+			Start  string `json:"start"`
+			Low    string `json:"low"`
+			High   string `json:"high"`
+			Open   string `json:"open"`
+			Close  string `json:"close"`
+			Volume string `json:"volume"`
+		} `json:"candles"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse candles: %w", err)
+	}
+
+	candles := make([]*domain.Candle, 0, len(raw.Candles))
+	for _, c := range raw.Candles {
+		openSec, err := strconv.ParseInt(c.Start, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse candle start: %w", err)
+		}
+		var values [5]float64
+		for i, s := range []string{c.Open, c.High, c.Low, c.Close, c.Volume} {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse candle: %w", err)
+			}
+			values[i] = v
+		}
+		candles = append(candles, &domain.Candle{
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: time.Unix(openSec, 0).UTC(),
+			Open:     values[0],
+			High:     values[1],
+			Low:      values[2],
+			Close:    values[3],
+			Volume:   values[4] * values[3],
+		})
+	}
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime.Before(candles[j].OpenTime) })
+	return candles, nil
+}
+
+func (p *CoinbaseProvider) doRequest(ctx context.Context, endpoint string) ([]byte, error) {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.key != nil {
+		token, err := p.signJWT(req.Method, req.URL)
+		if err != nil {
+			return nil, fmt.Errorf("sign request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coinbase API error %d: %s", resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// signJWT builds the short-lived ES256 token Coinbase expects for each
+// authenticated request, bound to its method, host and path.
+func (p *CoinbaseProvider) signJWT(method string, u *url.URL) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	now := p.now()
+	header, err := json.Marshal(map[string]string{
+		"alg":   "ES256",
+		"typ":   "JWT",
+		"kid":   p.keyName,
+		"nonce": hex.EncodeToString(nonce),
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": "cdp",
+		"sub": p.keyName,
+		"nbf": now.Unix(),
+		"exp": now.Add(coinbaseJWTLifetime).Unix(),
+		"uri": method + " " + u.Host + u.Path,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, p.key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// JWS wants the raw 32-byte r and s, not ASN.1.
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,211 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestCoinbaseProvider(rt roundTripFunc, now time.Time) *CoinbaseProvider {
+	provider := NewCoinbaseProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.baseURL = "http://example"
+	provider.client = &http.Client{Transport: rt}
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+	provider.now = func() time.Time { return now }
+	return provider
+}
+
+func TestCoinbaseProviderFetchPrices(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestCoinbaseProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/v3/brokerage/market/products" || req.Header.Get("Authorization") != "" {
+			t.Fatalf("expected an unauthenticated public request, got %s", req.URL)
+		}
+		if ids := req.URL.Query()["product_ids"]; len(ids) != 10 || ids[0] != "ADA-USD" {
+			t.Fatalf("expected every supported product, got %v", ids)
+		}
+		return jsonResponse(`{"products":[
+			{"product_id":"BTC-USD","price":"97000.5","price_percentage_change_24h":"-1.25","volume_24h":"2"},
+			{"product_id":"POL-USD","price":"0.42","price_percentage_change_24h":"3","volume_24h":"100"},
+			{"product_id":"BTC-EUR","price":"90000","price_percentage_change_24h":"0","volume_24h":"1"}
+		]}`), nil
+	}, time.Unix(1700000000, 0))
+
+	result, err := provider.FetchPrices(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected unknown products dropped, got %+v", result)
+	}
+	btc := result["BTC"]
+	if btc == nil || btc.PriceUSD != 97000.5 || btc.Change24hPct != -1.25 || btc.Volume24h != 194001 || btc.LastUpdatedUnix != 1700000000 {
+		t.Fatalf("unexpected BTC snapshot: %+v", btc)
+	}
+	if matic := result["MATIC"]; matic == nil || matic.PriceUSD != 0.42 {
+		t.Fatalf("expected POL-USD reported as MATIC, got %+v", matic)
+	}
+}
+
+func TestCoinbaseProviderFetchMarketChartPaginates(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	var windows [][2]int64
+	provider := newTestCoinbaseProvider(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if req.URL.Path != "/api/v3/brokerage/market/products/ETH-USD/candles" || q.Get("granularity") != "ONE_HOUR" {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+		end, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+		windows = append(windows, [2]int64{start, end})
+		// Serve the window's hourly candles newest first, as Coinbase does.
+		var rows []string
+		for ts := end - 3600; ts >= start; ts -= 3600 {
+			rows = append(rows, fmt.Sprintf(`{"start":"%d","low":"9","high":"12","open":"10","close":"11","volume":"100"}`, ts))
+		}
+		return jsonResponse(`{"candles":[` + strings.Join(rows, ",") + `]}`), nil
+	}, now)
+
+	candles, err := provider.FetchMarketChart(context.Background(), "ETH", 30, []string{"1h", "2h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 30 days of hourly candles is 720, fetched in three windows of at most 350.
+	if len(candles) != 720 || len(windows) != 3 {
+		t.Fatalf("expected 720 candles over 3 requests, got %d over %d", len(candles), len(windows))
+	}
+	if windows[0][0] != now.AddDate(0, 0, -30).Unix() || windows[1][0] != windows[0][1] || windows[2][1] != now.Unix() {
+		t.Fatalf("unexpected windows %v", windows)
+	}
+	first := candles[0]
+	if first.Symbol != "ETH" || first.Interval != "1h" || !first.OpenTime.Equal(now.AddDate(0, 0, -30)) ||
+		first.Open != 10 || first.High != 12 || first.Low != 9 || first.Close != 11 || first.Volume != 1100 {
+		t.Fatalf("unexpected candle: %+v", first)
+	}
+	if !candles[349].OpenTime.Before(candles[350].OpenTime) {
+		t.Fatal("expected candles in ascending order")
+	}
+}
+
+func TestCoinbaseProviderSignsRequestsWithCredentials(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	const keyName = "organizations/org/apiKeys/key"
+
+	now := time.Unix(1700000000, 0)
+	provider := newTestCoinbaseProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/api/v3/brokerage/products" {
+			t.Fatalf("expected the authenticated endpoint, got %s", req.URL.Path)
+		}
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			t.Fatalf("expected a bearer token, got %q", req.Header.Get("Authorization"))
+		}
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			t.Fatalf("expected a JWT, got %q", token)
+		}
+		var header map[string]string
+		var claims map[string]any
+		decodeJWTPart(t, parts[0], &header)
+		decodeJWTPart(t, parts[1], &claims)
+		if header["alg"] != "ES256" || header["kid"] != keyName || header["nonce"] == "" {
+			t.Fatalf("unexpected header %v", header)
+		}
+		if claims["sub"] != keyName || claims["iss"] != "cdp" || claims["uri"] != "GET example/api/v3/brokerage/products" ||
+			claims["nbf"] != float64(now.Unix()) || claims["exp"] != float64(now.Add(2*time.Minute).Unix()) {
+			t.Fatalf("unexpected claims %v", claims)
+		}
+		sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil || len(sig) != 64 {
+			t.Fatalf("unexpected signature %q: %v", parts[2], err)
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+			t.Fatal("expected the token to verify against the key")
+		}
+		return jsonResponse(`{"products":[]}`), nil
+	}, now)
+
+	// Env files usually hold the key on one line with escaped newlines.
+	if err := provider.SetCredentials(keyName, strings.ReplaceAll(keyPEM, "\n", `\n`)); err != nil {
+		t.Fatalf("set credentials: %v", err)
+	}
+	if _, err := provider.FetchPrices(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCoinbaseProviderSetCredentialsRejectsBadKeys(t *testing.T) {
+	t.Parallel()
+
+	provider := NewCoinbaseProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	if err := provider.SetCredentials("", "key"); err == nil {
+		t.Fatal("expected an empty key name to be rejected")
+	}
+	if err := provider.SetCredentials("name", "not a key"); err == nil {
+		t.Fatal("expected a non-PEM key to be rejected")
+	}
+	if provider.key != nil {
+		t.Fatal("expected rejected credentials to leave the provider unauthenticated")
+	}
+}
+
+func TestCoinbaseProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestCoinbaseProvider(func(*http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Body:       io.NopCloser(strings.NewReader(`{"error":"rate limited"}`)),
+			Header:     make(http.Header),
+		}, nil
+	}, time.Now())
+
+	if _, err := provider.FetchMarketChart(context.Background(), "SHIB", 1, []string{"1h"}); err == nil || !strings.Contains(err.Error(), "unsupported symbol") {
+		t.Fatalf("expected unsupported symbol error, got %v", err)
+	}
+	if _, err := provider.FetchPrices(context.Background()); err == nil || !strings.Contains(err.Error(), "coinbase API error 429") {
+		t.Fatalf("expected API error, got %v", err)
+	}
+}
+
+func decodeJWTPart(t *testing.T, part string, out any) {
+	t.Helper()
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatalf("decode JWT part: %v", err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		t.Fatalf("parse JWT part: %v", err)
+	}
+}
@@ -11,41 +11,70 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
-const coingeckoBaseURL = "https://api.coingecko.com/api/v3"
+const (
+	coingeckoBaseURL    = "https://api.coingecko.com/api/v3"
+	coingeckoProBaseURL = "https://pro-api.coingecko.com/api/v3"
 
-// CoinGeckoProvider fetches price and OHLC data from the CoinGecko free API.
+	// coingeckoMaxAttempts bounds how often one call is retried after 429s.
+	coingeckoMaxAttempts = 3
+)
+
+// CoinGeckoProvider fetches price and OHLC data from the CoinGecko API: the
+// free public API by default, or the Pro API when an API key is set.
 type CoinGeckoProvider struct {
 	client  *http.Client
 	baseURL string
+	apiKey  string
 	tracer  trace.Tracer
 	limiter *RateLimiter
+	backoff *Backoff
+	quota   *Quota
 }
 
 // NewCoinGeckoProvider creates a new provider with built-in rate limiting.
 // Rate limited to 8 requests per minute (one token every 7.5 seconds).
 func NewCoinGeckoProvider(tracer trace.Tracer) *CoinGeckoProvider {
 	return &CoinGeckoProvider{
-		client:  &http.Client{Timeout: 30 * time.Second},
+		client:  httpclient.New(httpclient.CoinGecko, 30*time.Second),
 		baseURL: coingeckoBaseURL,
 		tracer:  tracer,
 		limiter: NewRateLimiter(8, 7500*time.Millisecond),
+		backoff: NewBackoff(15*time.Second, 5*time.Minute),
+		quota:   CoinGeckoQuota(),
+	}
+}
+
+// SetAPIKey switches the provider to the Pro API, authenticated with key,
+// and raises the rate limit to 250 requests per minute, the lowest paid
+// plan. An empty key keeps the free API.
+func (p *CoinGeckoProvider) SetAPIKey(key string) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return
 	}
+	p.apiKey = key
+	p.baseURL = coingeckoProBaseURL
+	p.limiter = NewRateLimiter(30, 240*time.Millisecond)
 }
 
 // FetchPrices fetches current prices for all supported assets in a single API call.
 func (p *CoinGeckoProvider) FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error) {
-	_, span := p.tracer.Start(ctx, "coingecko.fetch-prices")
+	ctx, span := p.tracer.Start(ctx, "coingecko.fetch-prices")
 	defer span.End()
 
-	ids := make([]string, 0, len(domain.CoinGeckoID))
-	for _, id := range domain.CoinGeckoID {
-		ids = append(ids, id)
+	assets := domain.Assets()
+	ids := make([]string, 0, len(assets))
+	for _, a := range assets {
+		ids = append(ids, a.CoinGeckoID)
 	}
+	sort.Strings(ids)
 
 	url := fmt.Sprintf("%s/simple/price?ids=%s&vs_currencies=usd&include_24hr_vol=true&include_24hr_change=true",
 		p.baseURL, strings.Join(ids, ","))
@@ -64,15 +93,15 @@ func (p *CoinGeckoProvider) FetchPrices(ctx context.Context) (map[string]*domain
 	now := time.Now().Unix()
 	result := make(map[string]*domain.PriceSnapshot, len(raw))
 	for cgID, data := range raw {
-		symbol, ok := domain.CoinGeckoIDToSymbol[cgID]
+		symbol, ok := domain.SymbolForCoinGeckoID(cgID)
 		if !ok {
 			continue
 		}
 		result[symbol] = &domain.PriceSnapshot{
 			Symbol:          symbol,
 			PriceUSD:        data["usd"],
-			Volume24h:       data["usd_24h_vol"],
-			Change24hPct:    data["usd_24h_change"],
+			Volume24h:       domain.Money(data["usd_24h_vol"]),
+			Change24hPct:    domain.Percent(data["usd_24h_change"]),
 			LastUpdatedUnix: now,
 		}
 	}
@@ -84,10 +113,10 @@ func (p *CoinGeckoProvider) FetchPrices(ctx context.Context) (map[string]*domain
 // days=1 gives ~5min granularity (for 5m, 15m, 1h candles).
 // days=30 gives ~1h granularity (for 4h, 1d candles).
 func (p *CoinGeckoProvider) FetchMarketChart(ctx context.Context, symbol string, days int, intervals []string) ([]*domain.Candle, error) {
-	_, span := p.tracer.Start(ctx, "coingecko.fetch-market-chart")
+	ctx, span := p.tracer.Start(ctx, "coingecko.fetch-market-chart")
 	defer span.End()
 
-	cgID, ok := domain.CoinGeckoID[symbol]
+	cgID, ok := domain.CoinGeckoIDFor(symbol)
 	if !ok {
 		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
 	}
@@ -117,29 +146,104 @@ func (p *CoinGeckoProvider) FetchMarketChart(ctx context.Context, symbol string,
 	return allCandles, nil
 }
 
-func (p *CoinGeckoProvider) doRequest(ctx context.Context, url string) ([]byte, error) {
-	if err := p.limiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait: %w", err)
-	}
+// FetchGlobal fetches the total market capitalisation, volume and BTC and
+// ETH dominance from the /global endpoint.
+func (p *CoinGeckoProvider) FetchGlobal(ctx context.Context) (*domain.GlobalMarketSnapshot, error) {
+	ctx, span := p.tracer.Start(ctx, "coingecko.fetch-global")
+	defer span.End()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	body, err := p.doRequest(ctx, p.baseURL+"/global")
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("fetch global market: %w", err)
 	}
-	req.Header.Set("Accept", "application/json")
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, err
+	var raw struct {
+		Data struct {
+			TotalMarketCap      map[string]float64 `json:"total_market_cap"`
+			TotalVolume         map[string]float64 `json:"total_volume"`
+			MarketCapPercentage map[string]float64 `json:"market_cap_percentage"`
+			MarketCapChange24h  float64            `json:"market_cap_change_percentage_24h_usd"`
+		} `json:"data"`
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("coingecko API error %d: %s", resp.StatusCode, string(body))
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse global market: %w", err)
 	}
+	if raw.Data.TotalMarketCap["usd"] <= 0 {
+		return nil, fmt.Errorf("global market response has no USD market cap")
+	}
+	return &domain.GlobalMarketSnapshot{
+		Source:                httpclient.CoinGecko,
+		CapturedAt:            time.Now().UTC(),
+		TotalMarketCapUSD:     raw.Data.TotalMarketCap["usd"],
+		TotalVolumeUSD:        raw.Data.TotalVolume["usd"],
+		BTCDominancePct:       raw.Data.MarketCapPercentage["btc"],
+		ETHDominancePct:       raw.Data.MarketCapPercentage["eth"],
+		MarketCapChange24hPct: raw.Data.MarketCapChange24h,
+	}, nil
+}
+
+// doRequest waits for a rate-limit token and any 429 backoff, then sends
+// the request. A 429 starts or extends the backoff and the request is
+// retried up to coingeckoMaxAttempts times. The throttle state is recorded
+// on the caller's span.
+func (p *CoinGeckoProvider) doRequest(ctx context.Context, url string) ([]byte, error) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Bool("coingecko.pro", p.apiKey != ""))
+
+	for attempt := 1; ; attempt++ {
+		if strikes, remaining := p.backoff.State(); remaining > 0 {
+			span.SetAttributes(
+				attribute.Int("coingecko.throttle_strikes", strikes),
+				attribute.Int64("coingecko.throttle_wait_ms", remaining.Milliseconds()),
+			)
+		}
+		if err := p.backoff.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit backoff: %w", err)
+		}
+		if err := p.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("rate limit wait: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if p.apiKey != "" {
+			req.Header.Set("x-cg-pro-api-key", p.apiKey)
+		}
 
-	return io.ReadAll(resp.Body)
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		p.quota.Record(ctx)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		span.SetAttributes(attribute.Int("coingecko.attempts", attempt))
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			pause := p.backoff.Throttled(parseRetryAfter(resp.Header, time.Now()))
+			strikes, _ := p.backoff.State()
+			span.SetAttributes(
+				attribute.Bool("coingecko.throttled", true),
+				attribute.Int("coingecko.throttle_strikes", strikes),
+				attribute.Int64("coingecko.backoff_ms", pause.Milliseconds()),
+			)
+			if attempt < coingeckoMaxAttempts {
+				continue
+			}
+			return nil, fmt.Errorf("coingecko API error %d after %d attempts: %s", resp.StatusCode, attempt, string(body))
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("coingecko API error %d: %s", resp.StatusCode, string(body))
+		}
+		p.backoff.Recovered()
+		return body, nil
+	}
 }
 
 type volumePoint struct {
@@ -154,7 +258,7 @@ func buildCandlesFromMarketChart(symbol, interval string, prices, volumes [][]fl
 		return nil
 	}
 
-	intervalDuration := intervalToDuration(interval)
+	intervalDuration := domain.IntervalDuration(interval)
 	if intervalDuration == 0 {
 		return nil
 	}
@@ -257,20 +361,3 @@ func findClosestVolume(volumes []volumePoint, targetMs int64) float64 {
 	}
 	return closest.vol
 }
-
-func intervalToDuration(interval string) time.Duration {
-	switch interval {
-	case "5m":
-		return 5 * time.Minute
-	case "15m":
-		return 15 * time.Minute
-	case "1h":
-		return time.Hour
-	case "4h":
-		return 4 * time.Hour
-	case "1d":
-		return 24 * time.Hour
-	default:
-		return 0
-	}
-}
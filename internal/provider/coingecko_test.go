@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -60,22 +62,6 @@ func TestFindClosestVolume(t *testing.T) {
 	}
 }
 
-func TestIntervalToDuration(t *testing.T) {
-	tests := map[string]time.Duration{
-		"5m":  5 * time.Minute,
-		"15m": 15 * time.Minute,
-		"1h":  time.Hour,
-		"4h":  4 * time.Hour,
-		"1d":  24 * time.Hour,
-		"bad": 0,
-	}
-	for interval, expected := range tests {
-		if got := intervalToDuration(interval); got != expected {
-			t.Fatalf("%s expected %v, got %v", interval, expected, got)
-		}
-	}
-}
-
 type roundTripFunc func(*http.Request) (*http.Response, error)
 
 func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -118,6 +104,39 @@ func TestCoinGeckoProviderFetchPrices(t *testing.T) {
 	}
 }
 
+func TestCoinGeckoProviderFetchGlobal(t *testing.T) {
+	t.Parallel()
+
+	provider := NewCoinGeckoProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.baseURL = "http://example"
+	provider.client = &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/global" {
+				t.Fatalf("unexpected path: %s", req.URL.Path)
+			}
+			body := `{"data":{"total_market_cap":{"usd":2.4e12,"btc":38e6},"total_volume":{"usd":9.1e10},` +
+				`"market_cap_percentage":{"btc":54.2,"eth":17.3},"market_cap_change_percentage_24h_usd":-1.25}}`
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+
+	snap, err := provider.FetchGlobal(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snap.TotalMarketCapUSD != 2.4e12 || snap.TotalVolumeUSD != 9.1e10 || snap.Source != httpclient.CoinGecko {
+		t.Fatalf("unexpected totals: %+v", snap)
+	}
+	if snap.BTCDominancePct != 54.2 || snap.ETHDominancePct != 17.3 || snap.MarketCapChange24hPct != -1.25 {
+		t.Fatalf("unexpected dominance: %+v", snap)
+	}
+}
+
 func TestCoinGeckoProviderFetchMarketChart(t *testing.T) {
 	t.Parallel()
 
@@ -158,3 +177,105 @@ func TestCoinGeckoProviderFetchMarketChart(t *testing.T) {
 		t.Fatalf("expected BTC candles, got %+v", candles[0])
 	}
 }
+
+func TestCoinGeckoProviderUsesProKeyAndRetriesAfter429(t *testing.T) {
+	t.Parallel()
+
+	provider := NewCoinGeckoProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.SetAPIKey("cg-key")
+	if provider.baseURL != coingeckoProBaseURL {
+		t.Fatalf("expected the pro endpoint, got %s", provider.baseURL)
+	}
+	provider.baseURL = "http://example"
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+	provider.backoff = NewBackoff(time.Millisecond, 5*time.Millisecond)
+
+	calls := 0
+	provider.client = &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if got := req.Header.Get("x-cg-pro-api-key"); got != "cg-key" {
+				t.Fatalf("expected the pro API key header, got %q", got)
+			}
+			if calls == 1 {
+				return &http.Response{
+					StatusCode: http.StatusTooManyRequests,
+					Body:       io.NopCloser(strings.NewReader(`{"status":{"error_code":429}}`)),
+					Header:     make(http.Header),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"bitcoin":{"usd":100}}`)),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	result, err := provider.FetchPrices(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || result["BTC"] == nil {
+		t.Fatalf("expected one retry after the 429, got %d calls and %+v", calls, result)
+	}
+	if strikes, _ := provider.backoff.State(); strikes != 0 {
+		t.Fatalf("expected the backoff to clear after a success, got %d strikes", strikes)
+	}
+}
+
+func TestCoinGeckoProviderGivesUpAfterRepeated429s(t *testing.T) {
+	t.Parallel()
+
+	provider := NewCoinGeckoProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.baseURL = "http://example"
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+	provider.backoff = NewBackoff(time.Millisecond, 5*time.Millisecond)
+	calls := 0
+	provider.client = &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			if req.Header.Get("x-cg-pro-api-key") != "" {
+				t.Fatal("expected no API key header on the free API")
+			}
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Body:       io.NopCloser(strings.NewReader("slow down")),
+				Header:     make(http.Header),
+			}, nil
+		}),
+	}
+
+	if _, err := provider.FetchPrices(context.Background()); err == nil {
+		t.Fatal("expected an error after repeated 429s")
+	}
+	if calls != coingeckoMaxAttempts {
+		t.Fatalf("expected %d attempts, got %d", coingeckoMaxAttempts, calls)
+	}
+	if strikes, _ := provider.backoff.State(); strikes != coingeckoMaxAttempts {
+		t.Fatalf("expected the backoff to keep its strikes, got %d", strikes)
+	}
+}
+
+func TestCoinGeckoProviderFetchPricesReplaysRecording(t *testing.T) {
+	t.Parallel()
+
+	// The golden file is keyed by the price URL, so it needs re-recording
+	// when the tracked assets change.
+	provider := NewCoinGeckoProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.client = &http.Client{Transport: httpclient.NewRecorder(httpclient.Replay, "testdata/recordings/coingecko", nil)}
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+
+	for _, want := range []float64{97012.5, 97240.1, 97240.1} {
+		result, err := provider.FetchPrices(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if snap := result["BTC"]; snap == nil || snap.PriceUSD != want {
+			t.Fatalf("expected replayed BTC price %v, got %+v", want, snap)
+		}
+		if eth := result["ETH"]; eth == nil || eth.Change24hPct >= 0 {
+			t.Fatalf("expected replayed ETH snapshot, got %+v", eth)
+		}
+	}
+}
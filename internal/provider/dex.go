@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultEthereumRPCURL is a free public Ethereum JSON-RPC endpoint.
+	DefaultEthereumRPCURL = "https://ethereum-rpc.publicnode.com"
+	jupiterBaseURL        = "https://lite-api.jup.ag"
+
+	DexVenueUniswapV3 = "uniswap_v3"
+	DexVenueJupiter   = "jupiter"
+)
+
+// slot0Selector is the ABI selector of UniswapV3Pool.slot0(). The first
+// returned word is sqrtPriceX96.
+const slot0Selector = "0x3850c7bd"
+
+// uniswapV3Pool is a pool quoting a tracked symbol against USDC.
+type uniswapV3Pool struct {
+	address   string
+	decimals0 int
+	decimals1 int
+	// baseIsToken0 is true when the tracked asset is token0 and USDC is
+	// token1.
+	baseIsToken0 bool
+}
+
+// uniswapV3Pools are the deepest Ethereum mainnet USDC pools.
+var uniswapV3Pools = map[string]uniswapV3Pool{
+	// USDC/WETH 0.05%: token0 USDC (6), token1 WETH (18).
+	"ETH": {address: "0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640", decimals0: 6, decimals1: 18},
+	// WBTC/USDC 0.3%: token0 WBTC (8), token1 USDC (6).
+	"BTC": {address: "0x99ac8cA7087fA4A2A1FB6357269965A2014ABc35", decimals0: 8, decimals1: 6, baseIsToken0: true},
+}
+
+// jupiterMints are the Solana token mints priced through Jupiter.
+var jupiterMints = map[string]string{
+	"SOL": "So11111111111111111111111111111111111111112",
+}
+
+// DexProvider reads on-chain prices: Uniswap v3 pool state over Ethereum
+// JSON-RPC, and Jupiter's aggregated price for Solana tokens.
+type DexProvider struct {
+	rpcClient  *http.Client
+	rpcURL     string
+	jupClient  *http.Client
+	jupBaseURL string
+	tracer     trace.Tracer
+	now        func() time.Time
+}
+
+// NewDexProvider creates a provider that calls rpcURL for Ethereum pool
+// state. An empty rpcURL uses DefaultEthereumRPCURL.
+func NewDexProvider(tracer trace.Tracer, rpcURL string) *DexProvider {
+	rpcURL = strings.TrimSpace(rpcURL)
+	if rpcURL == "" {
+		rpcURL = DefaultEthereumRPCURL
+	}
+	return &DexProvider{
+		rpcClient:  httpclient.New(httpclient.EthereumRPC, 15*time.Second),
+		rpcURL:     rpcURL,
+		jupClient:  httpclient.New(httpclient.Jupiter, 15*time.Second),
+		jupBaseURL: jupiterBaseURL,
+		tracer:     tracer,
+		now:        time.Now,
+	}
+}
+
+// HasDexSource reports whether symbol has a pool or mint to read.
+func (p *DexProvider) HasDexSource(symbol string) bool {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	_, pool := uniswapV3Pools[symbol]
+	_, mint := jupiterMints[symbol]
+	return pool || mint
+}
+
+// FetchDexQuote returns symbol's current on-chain USD price.
+func (p *DexProvider) FetchDexQuote(ctx context.Context, symbol string) (*domain.DexQuote, error) {
+	ctx, span := p.tracer.Start(ctx, "dex.fetch-quote")
+	defer span.End()
+
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	span.SetAttributes(attribute.String("symbol", symbol))
+
+	var (
+		venue string
+		price float64
+		err   error
+	)
+	if pool, ok := uniswapV3Pools[symbol]; ok {
+		venue = DexVenueUniswapV3
+		price, err = p.uniswapV3Price(ctx, pool)
+	} else if mint, ok := jupiterMints[symbol]; ok {
+		venue = DexVenueJupiter
+		price, err = p.jupiterPrice(ctx, mint)
+	} else {
+		return nil, fmt.Errorf("no dex source for symbol: %s", symbol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return nil, fmt.Errorf("invalid %s price for %s: %v", venue, symbol, price)
+	}
+	return &domain.DexQuote{Symbol: symbol, Venue: venue, PriceUSD: price, CapturedAt: p.now().UTC()}, nil
+}
+
+func (p *DexProvider) uniswapV3Price(ctx context.Context, pool uniswapV3Pool) (float64, error) {
+	payload, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "eth_call",
+		"params": []any{
+			map[string]string{"to": pool.address, "data": slot0Selector},
+			"latest",
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.rpcURL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.rpcClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("ethereum rpc error %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Response shape: {"jsonrpc":"2.0","id":1,"result":"0x<7 ABI words>"}
+	var rpc struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpc); err != nil {
+		return 0, fmt.Errorf("decode ethereum rpc response: %w", err)
+	}
+	if rpc.Error != nil {
+		return 0, fmt.Errorf("ethereum rpc error %d: %s", rpc.Error.Code, rpc.Error.Message)
+	}
+	raw, err := hex.DecodeString(strings.TrimPrefix(rpc.Result, "0x"))
+	if err != nil {
+		return 0, fmt.Errorf("decode slot0 result: %w", err)
+	}
+	if len(raw) < 32 {
+		return 0, fmt.Errorf("slot0 result too short: %d bytes", len(raw))
+	}
+	sqrtPriceX96 := new(big.Int).SetBytes(raw[:32])
+	if sqrtPriceX96.Sign() == 0 {
+		return 0, fmt.Errorf("pool %s is not initialized", pool.address)
+	}
+	return uniswapV3PoolPrice(sqrtPriceX96, pool), nil
+}
+
+// uniswapV3PoolPrice converts sqrtPriceX96 to the USD price of the pool's
+// tracked asset. (sqrtPriceX96 / 2^96)^2 is token1 per token0 in raw units.
+func uniswapV3PoolPrice(sqrtPriceX96 *big.Int, pool uniswapV3Pool) float64 {
+	sqrt := new(big.Float).SetInt(sqrtPriceX96)
+	sqrt.Quo(sqrt, new(big.Float).SetMantExp(big.NewFloat(1), 96))
+	ratio, _ := new(big.Float).Mul(sqrt, sqrt).Float64()
+	token0InToken1 := ratio * math.Pow10(pool.decimals0-pool.decimals1)
+	if pool.baseIsToken0 {
+		return token0InToken1
+	}
+	if token0InToken1 == 0 {
+		return 0
+	}
+	return 1 / token0InToken1
+}
+
+func (p *DexProvider) jupiterPrice(ctx context.Context, mint string) (float64, error) {
+	endpoint := fmt.Sprintf("%s/price/v3?ids=%s", strings.TrimRight(p.jupBaseURL, "/"), url.QueryEscape(mint))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.jupClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("jupiter price API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Response shape: {"So111...112":{"usdPrice":147.48,"decimals":9,...}}
+	var payload map[string]struct {
+		USDPrice float64 `json:"usdPrice"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return 0, fmt.Errorf("decode jupiter price response: %w", err)
+	}
+	row, ok := payload[mint]
+	if !ok {
+		return 0, fmt.Errorf("jupiter has no price for %s", mint)
+	}
+	return row.USDPrice, nil
+}
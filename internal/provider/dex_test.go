@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sqrtPriceX96For inverts uniswapV3PoolPrice for a test price.
+func sqrtPriceX96For(priceUSD float64, pool uniswapV3Pool) *big.Int {
+	token0InToken1 := priceUSD
+	if !pool.baseIsToken0 {
+		token0InToken1 = 1 / priceUSD
+	}
+	ratio := token0InToken1 / math.Pow10(pool.decimals0-pool.decimals1)
+	sqrt := new(big.Float).SetFloat64(math.Sqrt(ratio))
+	sqrt.Mul(sqrt, new(big.Float).SetMantExp(big.NewFloat(1), 96))
+	out, _ := sqrt.Int(nil)
+	return out
+}
+
+func newTestDexProvider(rt roundTripFunc, now time.Time) *DexProvider {
+	p := NewDexProvider(trace.NewNoopTracerProvider().Tracer("test"), "http://rpc.example")
+	p.rpcClient = &http.Client{Transport: rt}
+	p.jupClient = &http.Client{Transport: rt}
+	p.jupBaseURL = "http://jup.example"
+	p.now = func() time.Time { return now }
+	return p
+}
+
+func TestDexProviderFetchUniswapV3Quote(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	prices := map[string]float64{
+		strings.ToLower(uniswapV3Pools["ETH"].address): 3200.5,
+		strings.ToLower(uniswapV3Pools["BTC"].address): 97000,
+	}
+	p := newTestDexProvider(func(req *http.Request) (*http.Response, error) {
+		if req.Method != http.MethodPost || req.URL.Host != "rpc.example" {
+			t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+		}
+		var call struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		body, _ := io.ReadAll(req.Body)
+		if err := json.Unmarshal(body, &call); err != nil || call.Method != "eth_call" || len(call.Params) != 2 {
+			t.Fatalf("unexpected rpc body: %s", body)
+		}
+		var tx struct {
+			To   string `json:"to"`
+			Data string `json:"data"`
+		}
+		_ = json.Unmarshal(call.Params[0], &tx)
+		if tx.Data != slot0Selector {
+			t.Fatalf("unexpected call data: %s", tx.Data)
+		}
+		price := prices[strings.ToLower(tx.To)]
+		var pool uniswapV3Pool
+		for _, candidate := range uniswapV3Pools {
+			if strings.EqualFold(candidate.address, tx.To) {
+				pool = candidate
+			}
+		}
+		word := fmt.Sprintf("%064x", sqrtPriceX96For(price, pool))
+		result := "0x" + word + strings.Repeat("0", 64*6)
+		return jsonResponse(`{"jsonrpc":"2.0","id":1,"result":"` + result + `"}`), nil
+	}, now)
+
+	for symbol, want := range map[string]float64{"ETH": 3200.5, "btc": 97000} {
+		quote, err := p.FetchDexQuote(context.Background(), symbol)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", symbol, err)
+		}
+		if quote.Venue != DexVenueUniswapV3 || quote.Symbol != strings.ToUpper(symbol) || !quote.CapturedAt.Equal(now) {
+			t.Fatalf("%s: unexpected quote identity: %+v", symbol, quote)
+		}
+		if math.Abs(quote.PriceUSD-want)/want > 1e-9 {
+			t.Fatalf("%s: expected price %.4f, got %.4f", symbol, want, quote.PriceUSD)
+		}
+	}
+}
+
+func TestDexProviderFetchJupiterQuote(t *testing.T) {
+	t.Parallel()
+
+	mint := jupiterMints["SOL"]
+	p := newTestDexProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/price/v3" || req.URL.Query().Get("ids") != mint {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		return jsonResponse(`{"` + mint + `":{"usdPrice":147.48,"decimals":9}}`), nil
+	}, time.Unix(1700000000, 0))
+
+	quote, err := p.FetchDexQuote(context.Background(), "SOL")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quote.Venue != DexVenueJupiter || quote.PriceUSD != 147.48 {
+		t.Fatalf("unexpected quote: %+v", quote)
+	}
+}
+
+func TestDexProviderRejectsUnsupportedAndBadResponses(t *testing.T) {
+	t.Parallel()
+
+	p := newTestDexProvider(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(`{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"execution reverted"}}`), nil
+	}, time.Unix(1700000000, 0))
+
+	if p.HasDexSource("XRP") || !p.HasDexSource("eth") || !p.HasDexSource("SOL") {
+		t.Fatal("unexpected dex source coverage")
+	}
+	if _, err := p.FetchDexQuote(context.Background(), "XRP"); err == nil {
+		t.Fatal("expected unsupported symbol error")
+	}
+	if _, err := p.FetchDexQuote(context.Background(), "ETH"); err == nil || !strings.Contains(err.Error(), "execution reverted") {
+		t.Fatalf("expected rpc error, got %v", err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -23,17 +25,39 @@ type FearGreedProvider struct {
 
 func NewFearGreedProvider(tracer trace.Tracer) *FearGreedProvider {
 	return &FearGreedProvider{
-		client:  &http.Client{Timeout: 15 * time.Second},
+		client:  httpclient.New(httpclient.FearGreed, 15*time.Second),
 		baseURL: fearGreedBaseURL,
 		tracer:  tracer,
 	}
 }
 
+// FearGreedMaxHistory caps the daily values one FetchHistory call asks for.
+const FearGreedMaxHistory = 365
+
 func (p *FearGreedProvider) FetchLatest(ctx context.Context) (*FearGreedPoint, error) {
 	_, span := p.tracer.Start(ctx, "feargreed.fetch-latest")
 	defer span.End()
 
-	url := strings.TrimRight(p.baseURL, "/") + "/fng/?limit=1"
+	points, err := p.fetch(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &points[0], nil
+}
+
+// FetchHistory returns up to limit daily values, newest first.
+func (p *FearGreedProvider) FetchHistory(ctx context.Context, limit int) ([]FearGreedPoint, error) {
+	_, span := p.tracer.Start(ctx, "feargreed.fetch-history")
+	defer span.End()
+
+	if limit <= 0 || limit > FearGreedMaxHistory {
+		limit = FearGreedMaxHistory
+	}
+	return p.fetch(ctx, limit)
+}
+
+func (p *FearGreedProvider) fetch(ctx context.Context, limit int) ([]FearGreedPoint, error) {
+	url := fmt.Sprintf("%s/fng/?limit=%d", strings.TrimRight(p.baseURL, "/"), limit)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -66,29 +90,31 @@ func (p *FearGreedProvider) FetchLatest(ctx context.Context) (*FearGreedPoint, e
 		return nil, fmt.Errorf("fear & greed response has no rows")
 	}
 
-	row := payload.Data[0]
-	value, err := strconv.Atoi(strings.TrimSpace(row.Value))
-	if err != nil {
-		return nil, fmt.Errorf("parse fear & greed value: %w", err)
-	}
-	ts, err := strconv.ParseInt(strings.TrimSpace(row.Timestamp), 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("parse fear & greed timestamp: %w", err)
-	}
-	if ts > 1_000_000_000_000 {
-		ts = ts / 1000
-	}
-	updateS := 0
-	if row.TimeUntilUpdateS != "" {
-		if n, err := strconv.Atoi(strings.TrimSpace(row.TimeUntilUpdateS)); err == nil && n >= 0 {
-			updateS = n
+	points := make([]FearGreedPoint, 0, len(payload.Data))
+	for _, row := range payload.Data {
+		value, err := strconv.Atoi(strings.TrimSpace(row.Value))
+		if err != nil {
+			return nil, fmt.Errorf("parse fear & greed value: %w", err)
+		}
+		ts, err := strconv.ParseInt(strings.TrimSpace(row.Timestamp), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse fear & greed timestamp: %w", err)
+		}
+		if ts > 1_000_000_000_000 {
+			ts = ts / 1000
 		}
+		updateS := 0
+		if row.TimeUntilUpdateS != "" {
+			if n, err := strconv.Atoi(strings.TrimSpace(row.TimeUntilUpdateS)); err == nil && n >= 0 {
+				updateS = n
+			}
+		}
+		points = append(points, FearGreedPoint{
+			Value:            value,
+			Classification:   row.Classification,
+			Timestamp:        time.Unix(ts, 0).UTC(),
+			TimeUntilUpdateS: updateS,
+		})
 	}
-
-	return &FearGreedPoint{
-		Value:            value,
-		Classification:   row.Classification,
-		Timestamp:        time.Unix(ts, 0).UTC(),
-		TimeUntilUpdateS: updateS,
-	}, nil
+	return points, nil
 }
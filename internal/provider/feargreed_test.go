@@ -37,3 +37,36 @@ func TestFearGreedFetchLatest(t *testing.T) {
 		t.Fatalf("unexpected timestamp: %v", point.Timestamp)
 	}
 }
+
+func TestFearGreedFetchHistory(t *testing.T) {
+	p := NewFearGreedProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	p.baseURL = "https://example.com"
+	p.client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if got := req.URL.Query().Get("limit"); got != "30" {
+			t.Fatalf("expected limit=30, got %q", got)
+		}
+		body := `{"data":[
+			{"value":"63","value_classification":"Greed","timestamp":"1771027200"},
+			{"value":"22","value_classification":"Extreme Fear","timestamp":"1770940800"}
+		]}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+			Header:     make(http.Header),
+		}, nil
+	})}
+
+	points, err := p.FetchHistory(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("expected 2 points, got %d", len(points))
+	}
+	if points[1].Value != 22 || points[1].Classification != "Extreme Fear" {
+		t.Fatalf("unexpected oldest point: %+v", points[1])
+	}
+	if !points[1].Timestamp.Equal(time.Unix(1770940800, 0).UTC()) {
+		t.Fatalf("unexpected timestamp: %v", points[1].Timestamp)
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const frankfurterBaseURL = "https://api.frankfurter.app"
+
+// FrankfurterProvider reads the European Central Bank reference rates from
+// the keyless Frankfurter API. The ECB publishes once per working day.
+type FrankfurterProvider struct {
+	client  *http.Client
+	baseURL string
+	tracer  trace.Tracer
+}
+
+func NewFrankfurterProvider(tracer trace.Tracer) *FrankfurterProvider {
+	return &FrankfurterProvider{
+		client:  httpclient.New(httpclient.Frankfurter, 15*time.Second),
+		baseURL: frankfurterBaseURL,
+		tracer:  tracer,
+	}
+}
+
+// FetchUSDRates returns the units of each currency one US dollar buys.
+func (p *FrankfurterProvider) FetchUSDRates(ctx context.Context, currencies []string) (*domain.FXRates, error) {
+	_, span := p.tracer.Start(ctx, "frankfurter.fetch-usd-rates")
+	defer span.End()
+	span.SetAttributes(attribute.StringSlice("currencies", currencies))
+
+	endpoint := fmt.Sprintf("%s/latest?from=%s&to=%s",
+		strings.TrimRight(p.baseURL, "/"), domain.CurrencyUSD, url.QueryEscape(strings.Join(currencies, ",")))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("frankfurter API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Response shape: {"amount":1.0,"base":"USD","date":"2026-03-02","rates":{"EUR":0.92,...}}
+	var payload struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode frankfurter response: %w", err)
+	}
+	if payload.Base != domain.CurrencyUSD {
+		return nil, fmt.Errorf("frankfurter returned base %q, want %s", payload.Base, domain.CurrencyUSD)
+	}
+	for code, rate := range payload.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid frankfurter rate for %s: %v", code, rate)
+		}
+	}
+	out := &domain.FXRates{Rates: payload.Rates}
+	if asOf, err := time.Parse("2006-01-02", payload.Date); err == nil {
+		out.AsOf = asOf
+	}
+	return out, nil
+}
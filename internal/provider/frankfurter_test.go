@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFrankfurterFetchUSDRates(t *testing.T) {
+	t.Parallel()
+
+	p := NewFrankfurterProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	p.baseURL = "http://fx.example"
+	p.client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/latest" || req.URL.Query().Get("from") != "USD" || req.URL.Query().Get("to") != "EUR,JPY" {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		return jsonResponse(`{"amount":1.0,"base":"USD","date":"2026-03-02","rates":{"EUR":0.9214,"JPY":149.62}}`), nil
+	})}
+
+	rates, err := p.FetchUSDRates(context.Background(), []string{"EUR", "JPY"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rates.Rates["EUR"] != 0.9214 || rates.Rates["JPY"] != 149.62 {
+		t.Fatalf("unexpected rates: %+v", rates.Rates)
+	}
+	if !rates.AsOf.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected rate date: %v", rates.AsOf)
+	}
+}
+
+func TestFrankfurterRejectsBadResponses(t *testing.T) {
+	t.Parallel()
+
+	for _, body := range []string{
+		`{"amount":1.0,"base":"EUR","date":"2026-03-02","rates":{"GBP":0.85}}`,
+		`{"amount":1.0,"base":"USD","date":"2026-03-02","rates":{"GBP":0}}`,
+	} {
+		p := NewFrankfurterProvider(trace.NewNoopTracerProvider().Tracer("test"))
+		p.client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(body), nil
+		})}
+		if _, err := p.FetchUSDRates(context.Background(), []string{"GBP"}); err == nil || !strings.Contains(err.Error(), "frankfurter") {
+			t.Fatalf("expected an error for %s, got %v", body, err)
+		}
+	}
+}
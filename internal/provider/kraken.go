@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const krakenBaseURL = "https://api.kraken.com"
+
+// krakenIntervalMinutes maps supported intervals to Kraken's OHLC interval
+// parameter.
+var krakenIntervalMinutes = map[string]int{
+	"5m":  5,
+	"15m": 15,
+	"1h":  60,
+	"4h":  240,
+	"1d":  1440,
+}
+
+// KrakenProvider fetches prices and OHLC data from the Kraken public API.
+// Kraken only serves the latest 720 candles of each interval, so history
+// beyond that is cut off, for example 2.5 days of 5m candles.
+type KrakenProvider struct {
+	client  *http.Client
+	baseURL string
+	tracer  trace.Tracer
+	limiter *RateLimiter
+	now     func() time.Time
+}
+
+// NewKrakenProvider creates a new provider with built-in rate limiting.
+// Kraken asks public API clients for about one request per second.
+func NewKrakenProvider(tracer trace.Tracer) *KrakenProvider {
+	return &KrakenProvider{
+		client:  httpclient.New(httpclient.Kraken, 30*time.Second),
+		baseURL: krakenBaseURL,
+		tracer:  tracer,
+		limiter: NewRateLimiter(3, time.Second),
+		now:     time.Now,
+	}
+}
+
+// FetchPrices fetches the tickers of all supported assets in a single API
+// call. Kraken has no rolling 24h change, so Change24hPct is measured from
+// the UTC day's open; the 24h volume is converted to USD at the 24h VWAP.
+func (p *KrakenProvider) FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error) {
+	_, span := p.tracer.Start(ctx, "kraken.fetch-prices")
+	defer span.End()
+
+	tracked := domain.KrakenPairs()
+	symbolByPair := make(map[string]string, len(tracked))
+	pairs := make([]string, 0, len(tracked))
+	for symbol, pair := range tracked {
+		symbolByPair[pair] = symbol
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	// Result shape: {"XXBTZUSD": {"c": ["97000.1", "0.01"], "v": ["120.5", "1500.2"], "p": ["96800.0", "96500.3"], "o": "95000.0", ...}, ...}
+	var result map[string]struct {
+		Close  []string `json:"c"`
+		Volume []string `json:"v"`
+		VWAP   []string `json:"p"`
+		Open   string   `json:"o"`
+	}
+	if err := p.get(ctx, fmt.Sprintf("%s/0/public/Ticker?pair=%s", p.baseURL, strings.Join(pairs, ",")), &result); err != nil {
+		return nil, fmt.Errorf("fetch prices: %w", err)
+	}
+
+	now := p.now().Unix()
+	out := make(map[string]*domain.PriceSnapshot, len(result))
+	for pair, t := range result {
+		symbol, ok := symbolByPair[pair]
+		if !ok || len(t.Close) == 0 {
+			continue
+		}
+		price, err := strconv.ParseFloat(t.Close[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price for %s: %w", symbol, err)
+		}
+		var volume, vwap float64
+		if len(t.Volume) > 1 && len(t.VWAP) > 1 {
+			volume, _ = strconv.ParseFloat(t.Volume[1], 64)
+			vwap, _ = strconv.ParseFloat(t.VWAP[1], 64)
+		}
+		change := 0.0
+		if open, _ := strconv.ParseFloat(t.Open, 64); open > 0 {
+			change = (price/open - 1) * 100
+		}
+		out[symbol] = &domain.PriceSnapshot{
+			Symbol:          symbol,
+			PriceUSD:        price,
+			Volume24h:       domain.Money(volume * vwap),
+			Change24hPct:    domain.Percent(change),
+			LastUpdatedUnix: now,
+		}
+	}
+
+	return out, nil
+}
+
+// FetchMarketChart fetches the last days of OHLC candles for each interval,
+// at the interval's native resolution. Volume is converted to USD at each
+// candle's VWAP.
+func (p *KrakenProvider) FetchMarketChart(ctx context.Context, symbol string, days int, intervals []string) ([]*domain.Candle, error) {
+	ctx, span := p.tracer.Start(ctx, "kraken.fetch-market-chart")
+	defer span.End()
+
+	asset, _ := domain.LookupAsset(symbol)
+	pair := asset.KrakenPair
+	if pair == "" {
+		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+
+	since := p.now().AddDate(0, 0, -days)
+	var allCandles []*domain.Candle
+	for _, interval := range intervals {
+		minutes, ok := krakenIntervalMinutes[interval]
+		if !ok {
+			continue
+		}
+		candles, err := p.fetchOHLC(ctx, symbol, pair, interval, minutes, since)
+		if err != nil {
+			return nil, fmt.Errorf("fetch ohlc for %s %s: %w", symbol, interval, err)
+		}
+		allCandles = append(allCandles, candles...)
+	}
+
+	return allCandles, nil
+}
+
+func (p *KrakenProvider) fetchOHLC(ctx context.Context, symbol, pair, interval string, minutes int, since time.Time) ([]*domain.Candle, error) {
+	// Result shape: {"XXBTZUSD": [[time, "open", "high", "low", "close", "vwap", "volume", count], ...], "last": 1700000000}
+	var result map[string]json.RawMessage
+	endpoint := fmt.Sprintf("%s/0/public/OHLC?pair=%s&interval=%d&since=%d", p.baseURL, pair, minutes, since.Unix())
+	if err := p.get(ctx, endpoint, &result); err != nil {
+		return nil, err
+	}
+	rows, ok := result[pair]
+	if !ok {
+		return nil, fmt.Errorf("no ohlc for pair %s", pair)
+	}
+	var raw [][]json.RawMessage
+	if err := json.Unmarshal(rows, &raw); err != nil {
+		return nil, fmt.Errorf("parse ohlc: %w", err)
+	}
+
+	candles := make([]*domain.Candle, 0, len(raw))
+	for _, row := range raw {
+		if len(row) < 7 {
+			return nil, fmt.Errorf("parse ohlc: short row with %d fields", len(row))
+		}
+		var openSec int64
+		if err := json.Unmarshal(row[0], &openSec); err != nil {
+			return nil, fmt.Errorf("parse ohlc time: %w", err)
+		}
+		var values [6]float64
+		for i, field := range row[1:7] {
+			var s string
+			if err := json.Unmarshal(field, &s); err != nil {
+				return nil, fmt.Errorf("parse ohlc: %w", err)
+			}
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parse ohlc: %w", err)
+			}
+			values[i] = v
+		}
+		candles = append(candles, &domain.Candle{
+			Symbol:   symbol,
+			Interval: interval,
+			OpenTime: time.Unix(openSec, 0).UTC(),
+			Open:     values[0],
+			High:     values[1],
+			Low:      values[2],
+			Close:    values[3],
+			Volume:   values[5] * values[4],
+		})
+	}
+	return candles, nil
+}
+
+// get calls a public endpoint and decodes the result of Kraken's
+// {"error": [...], "result": ...} envelope into out. Kraken reports most
+// failures in the error list with a 200 status.
+func (p *KrakenProvider) get(ctx context.Context, endpoint string, out any) error {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kraken API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	var envelope struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	if len(envelope.Error) > 0 {
+		return fmt.Errorf("kraken API error: %s", strings.Join(envelope.Error, "; "))
+	}
+	if err := json.Unmarshal(envelope.Result, out); err != nil {
+		return fmt.Errorf("parse result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestKrakenProvider(rt roundTripFunc, now time.Time) *KrakenProvider {
+	provider := NewKrakenProvider(trace.NewNoopTracerProvider().Tracer("test"))
+	provider.baseURL = "http://example"
+	provider.client = &http.Client{Transport: rt}
+	provider.limiter = NewRateLimiter(10, time.Millisecond)
+	provider.now = func() time.Time { return now }
+	return provider
+}
+
+func TestKrakenProviderFetchPrices(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestKrakenProvider(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/0/public/Ticker" {
+			t.Fatalf("unexpected path: %s", req.URL.Path)
+		}
+		if pairs := req.URL.Query().Get("pair"); !strings.Contains(pairs, "XXBTZUSD") || !strings.Contains(pairs, "POLUSD") {
+			t.Fatalf("expected every supported pair, got %s", pairs)
+		}
+		return jsonResponse(`{"error":[],"result":{
+			"XXBTZUSD":{"c":["97000.5","0.1"],"v":["10","200"],"p":["96000","95000"],"o":"95000.5"},
+			"POLUSD":{"c":["0.42","5"],"v":["1","100"],"p":["0.4","0.41"],"o":"0.5"},
+			"XTZUSD":{"c":["1","1"],"v":["1","1"],"p":["1","1"],"o":"1"}
+		}}`), nil
+	}, time.Unix(1700000000, 0))
+
+	result, err := provider.FetchPrices(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("expected unknown pairs dropped, got %+v", result)
+	}
+	btc := result["BTC"]
+	if btc == nil || btc.PriceUSD != 97000.5 || btc.Volume24h != 200*95000 || btc.LastUpdatedUnix != 1700000000 {
+		t.Fatalf("unexpected BTC snapshot: %+v", btc)
+	}
+	if math.Abs(float64(btc.Change24hPct)-(97000.5/95000.5-1)*100) > 1e-9 {
+		t.Fatalf("expected the change since the day's open, got %v", btc.Change24hPct)
+	}
+	if matic := result["MATIC"]; matic == nil || matic.PriceUSD != 0.42 {
+		t.Fatalf("expected POLUSD reported as MATIC, got %+v", matic)
+	}
+}
+
+func TestKrakenProviderFetchMarketChart(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	var intervals []string
+	provider := newTestKrakenProvider(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if req.URL.Path != "/0/public/OHLC" || q.Get("pair") != "XETHZUSD" {
+			t.Fatalf("unexpected request: %s", req.URL)
+		}
+		if want := strconv.FormatInt(now.AddDate(0, 0, -30).Unix(), 10); q.Get("since") != want {
+			t.Fatalf("unexpected since %s", q.Get("since"))
+		}
+		intervals = append(intervals, q.Get("interval"))
+		return jsonResponse(`{"error":[],"result":{
+			"XETHZUSD":[[1773014400,"10","12","9","11","10.5","100",42],[1773028800,"11","13","10","12","11.5","50",7]],
+			"last":1773028800
+		}}`), nil
+	}, now)
+
+	candles, err := provider.FetchMarketChart(context.Background(), "ETH", 30, []string{"4h", "1d", "2h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(intervals, ",") != "240,1440" {
+		t.Fatalf("expected 4h and 1d requested in minutes and 2h skipped, got %v", intervals)
+	}
+	if len(candles) != 4 {
+		t.Fatalf("expected 4 candles, got %d", len(candles))
+	}
+	first := candles[0]
+	if first.Symbol != "ETH" || first.Interval != "4h" || !first.OpenTime.Equal(time.Unix(1773014400, 0)) ||
+		first.Open != 10 || first.High != 12 || first.Low != 9 || first.Close != 11 || first.Volume != 1050 {
+		t.Fatalf("unexpected candle: %+v", first)
+	}
+	if candles[2].Interval != "1d" {
+		t.Fatalf("expected 1d candles after 4h, got %+v", candles[2])
+	}
+}
+
+func TestKrakenProviderErrors(t *testing.T) {
+	t.Parallel()
+
+	provider := newTestKrakenProvider(func(*http.Request) (*http.Response, error) {
+		return jsonResponse(`{"error":["EGeneral:Too many requests"],"result":{}}`), nil
+	}, time.Now())
+
+	if _, err := provider.FetchMarketChart(context.Background(), "SHIB", 1, []string{"1h"}); err == nil || !strings.Contains(err.Error(), "unsupported symbol") {
+		t.Fatalf("expected unsupported symbol error, got %v", err)
+	}
+	if _, err := provider.FetchPrices(context.Background()); err == nil || !strings.Contains(err.Error(), "EGeneral:Too many requests") {
+		t.Fatalf("expected the API error list, got %v", err)
+	}
+	if _, err := provider.FetchMarketChart(context.Background(), "BTC", 1, []string{"1h"}); err == nil || !strings.Contains(err.Error(), "EGeneral:Too many requests") {
+		t.Fatalf("expected the API error list, got %v", err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -25,7 +27,7 @@ func NewADAKoiosOnChainProvider(tracer trace.Tracer, baseURL string) *ADAKoiosOn
 		baseURL = "https://api.koios.rest"
 	}
 	return &ADAKoiosOnChainProvider{
-		client:  &http.Client{Timeout: 20 * time.Second},
+		client:  httpclient.New(httpclient.Koios, 20*time.Second),
 		baseURL: strings.TrimRight(baseURL, "/"),
 		tracer:  tracer,
 	}
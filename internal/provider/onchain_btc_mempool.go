@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -24,7 +26,7 @@ func NewBTCMempoolOnChainProvider(tracer trace.Tracer, baseURL string) *BTCMempo
 		baseURL = "https://mempool.space"
 	}
 	return &BTCMempoolOnChainProvider{
-		client:  &http.Client{Timeout: 20 * time.Second},
+		client:  httpclient.New(httpclient.Mempool, 20*time.Second),
 		baseURL: strings.TrimRight(baseURL, "/"),
 		tracer:  tracer,
 	}
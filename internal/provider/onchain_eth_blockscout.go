@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -24,7 +26,7 @@ func NewETHBlockscoutOnChainProvider(tracer trace.Tracer, baseURL string) *ETHBl
 		baseURL = "https://eth.blockscout.com"
 	}
 	return &ETHBlockscoutOnChainProvider{
-		client:  &http.Client{Timeout: 20 * time.Second},
+		client:  httpclient.New(httpclient.Blockscout, 20*time.Second),
 		baseURL: strings.TrimRight(baseURL, "/"),
 		tracer:  tracer,
 	}
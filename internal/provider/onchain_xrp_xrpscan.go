@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -24,7 +26,7 @@ func NewXRPScanOnChainProvider(tracer trace.Tracer, baseURL string) *XRPScanOnCh
 		baseURL = "https://api.xrpscan.com"
 	}
 	return &XRPScanOnChainProvider{
-		client:  &http.Client{Timeout: 20 * time.Second},
+		client:  httpclient.New(httpclient.XRPScan, 20*time.Second),
 		baseURL: strings.TrimRight(baseURL, "/"),
 		tracer:  tracer,
 	}
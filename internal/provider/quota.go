@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/internal/httpclient"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// quotaStretchFrom is the share of the daily budget left below which
+	// pollers start stretching their intervals.
+	quotaStretchFrom = 0.5
+	// maxQuotaStretch caps how far pollers stretch as the budget runs out.
+	maxQuotaStretch = 8.0
+)
+
+// QuotaUsage is a provider's call count against its plan quota in the
+// current minute and UTC day. A zero limit means the plan sets none.
+type QuotaUsage struct {
+	Provider        string    `json:"provider"`
+	MinuteCalls     int       `json:"minute_calls"`
+	MinuteLimit     int       `json:"minute_limit"`
+	MinuteRemaining int       `json:"minute_remaining"`
+	DayCalls        int       `json:"day_calls"`
+	DayLimit        int       `json:"day_limit"`
+	DayRemaining    int       `json:"day_remaining"`
+	DayResetsAt     time.Time `json:"day_resets_at"`
+	Stretch         float64   `json:"stretch"`
+}
+
+// Quota counts calls to one provider in fixed minute and UTC-day windows.
+// Every client of a provider in the process shares its quota, since plans
+// meter the key or address rather than the client.
+type Quota struct {
+	provider string
+	now      func() time.Time
+
+	mu          sync.Mutex
+	perMinute   int
+	perDay      int
+	minuteStart time.Time
+	minuteCalls int
+	dayStart    time.Time
+	dayCalls    int
+}
+
+var (
+	quotasMu sync.Mutex
+	quotas   = map[string]*Quota{}
+
+	quotaCallsOnce sync.Once
+	quotaCalls     metric.Int64Counter
+)
+
+// SharedQuota returns the quota of the named provider, creating it without
+// limits on first use.
+func SharedQuota(provider string) *Quota {
+	quotasMu.Lock()
+	defer quotasMu.Unlock()
+	if q, ok := quotas[provider]; ok {
+		return q
+	}
+	q := newQuota(provider, time.Now)
+	quotas[provider] = q
+	q.registerMetrics()
+	return q
+}
+
+// CoinGeckoQuota is the quota every CoinGecko client records its calls in.
+func CoinGeckoQuota() *Quota {
+	return SharedQuota(httpclient.CoinGecko)
+}
+
+// QuotaUsages returns the usage of every provider quota in the process, by
+// name.
+func QuotaUsages() []QuotaUsage {
+	quotasMu.Lock()
+	list := make([]*Quota, 0, len(quotas))
+	for _, q := range quotas {
+		list = append(list, q)
+	}
+	quotasMu.Unlock()
+
+	out := make([]QuotaUsage, 0, len(list))
+	for _, q := range list {
+		out = append(out, q.Usage())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+func newQuota(provider string, now func() time.Time) *Quota {
+	return &Quota{provider: provider, now: now}
+}
+
+// SetLimits sets the plan's calls per minute and per day; zero leaves a
+// window unlimited.
+func (q *Quota) SetLimits(perMinute, perDay int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.perMinute = max(perMinute, 0)
+	q.perDay = max(perDay, 0)
+}
+
+// Record counts one call made now.
+func (q *Quota) Record(ctx context.Context) {
+	q.mu.Lock()
+	q.roll()
+	q.minuteCalls++
+	q.dayCalls++
+	q.mu.Unlock()
+	if quotaCalls != nil {
+		quotaCalls.Add(ctx, 1, metric.WithAttributes(attribute.String("provider", q.provider)))
+	}
+}
+
+// Usage returns the calls made in the current windows.
+func (q *Quota) Usage() QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	return QuotaUsage{
+		Provider:        q.provider,
+		MinuteCalls:     q.minuteCalls,
+		MinuteLimit:     q.perMinute,
+		MinuteRemaining: remainingCalls(q.perMinute, q.minuteCalls),
+		DayCalls:        q.dayCalls,
+		DayLimit:        q.perDay,
+		DayRemaining:    remainingCalls(q.perDay, q.dayCalls),
+		DayResetsAt:     q.dayStart.Add(24 * time.Hour),
+		Stretch:         q.stretch(),
+	}
+}
+
+// Stretch returns the factor pollers multiply their intervals by to make
+// the rest of the daily budget last: 1 while at least half of it is left,
+// then rising as it depletes, to 8 once it is spent.
+func (q *Quota) Stretch() float64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.roll()
+	return q.stretch()
+}
+
+func (q *Quota) stretch() float64 {
+	if q.perDay == 0 {
+		return 1
+	}
+	left := 1 - float64(q.dayCalls)/float64(q.perDay)
+	if left >= quotaStretchFrom {
+		return 1
+	}
+	if left <= quotaStretchFrom/maxQuotaStretch {
+		return maxQuotaStretch
+	}
+	return quotaStretchFrom / left
+}
+
+// roll starts new windows once the current ones are over.
+func (q *Quota) roll() {
+	now := q.now().UTC()
+	if minute := now.Truncate(time.Minute); !minute.Equal(q.minuteStart) {
+		q.minuteStart, q.minuteCalls = minute, 0
+	}
+	if day := now.Truncate(24 * time.Hour); !day.Equal(q.dayStart) {
+		q.dayStart, q.dayCalls = day, 0
+	}
+}
+
+func remainingCalls(limit, calls int) int {
+	if limit == 0 {
+		return 0
+	}
+	return max(limit-calls, 0)
+}
+
+func (q *Quota) registerMetrics() {
+	meter := otel.Meter("bug-free-umbrella/provider")
+	quotaCallsOnce.Do(func() {
+		var err error
+		if quotaCalls, err = meter.Int64Counter("provider.calls",
+			metric.WithDescription("Calls made to a metered provider"),
+		); err != nil {
+			log.Printf("provider calls counter: %v", err)
+		}
+	})
+	_, err := meter.Int64ObservableGauge("provider.quota.remaining",
+		metric.WithDescription("Calls left in the provider's plan quota for the current window"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			u := q.Usage()
+			if u.MinuteLimit > 0 {
+				o.Observe(int64(u.MinuteRemaining), metric.WithAttributes(
+					attribute.String("provider", u.Provider), attribute.String("window", "minute")))
+			}
+			if u.DayLimit > 0 {
+				o.Observe(int64(u.DayRemaining), metric.WithAttributes(
+					attribute.String("provider", u.Provider), attribute.String("window", "day")))
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		log.Printf("provider quota gauge: %v", err)
+	}
+}
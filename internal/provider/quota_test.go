@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQuotaCountsWindowsAndStretches(t *testing.T) {
+	now := time.Date(2026, 3, 4, 23, 59, 10, 0, time.UTC)
+	q := newQuota("coingecko", func() time.Time { return now })
+	q.SetLimits(30, 100)
+	ctx := context.Background()
+
+	for i := 0; i < 40; i++ {
+		q.Record(ctx)
+	}
+	u := q.Usage()
+	if u.MinuteCalls != 40 || u.MinuteRemaining != 0 || u.DayCalls != 40 || u.DayRemaining != 60 {
+		t.Fatalf("unexpected usage %+v", u)
+	}
+	if !u.DayResetsAt.Equal(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)) || u.Stretch != 1 {
+		t.Fatalf("expected no stretch with 60%% left and a midnight reset, got %+v", u)
+	}
+
+	for i := 0; i < 35; i++ {
+		q.Record(ctx)
+	}
+	if got := q.Stretch(); got != 2 {
+		t.Fatalf("expected a stretch of 2 with a quarter left, got %v", got)
+	}
+	for i := 0; i < 30; i++ {
+		q.Record(ctx)
+	}
+	if got := q.Stretch(); got != maxQuotaStretch {
+		t.Fatalf("expected the maximum stretch once spent, got %v", got)
+	}
+
+	now = now.Add(time.Minute)
+	u = q.Usage()
+	if u.MinuteCalls != 0 || u.DayCalls != 0 || u.Stretch != 1 {
+		t.Fatalf("expected both windows to reset at midnight, got %+v", u)
+	}
+
+	q.SetLimits(0, 0)
+	q.Record(ctx)
+	if u := q.Usage(); u.MinuteRemaining != 0 || u.DayRemaining != 0 || u.Stretch != 1 {
+		t.Fatalf("expected an unmetered quota never to stretch, got %+v", u)
+	}
+}
@@ -2,6 +2,11 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -57,3 +62,96 @@ func (r *RateLimiter) refill() {
 		r.lastRefill = r.lastRefill.Add(time.Duration(newTokens) * r.refillInterval)
 	}
 }
+
+// Backoff spaces out requests to an API that answered 429 Too Many Requests.
+// Each consecutive 429 doubles the pause, from base up to max, and never
+// waits less than the server's Retry-After. Up to a quarter of the pause is
+// added as jitter so restarted workers do not retry in lockstep. A successful
+// request clears it.
+type Backoff struct {
+	mu      sync.Mutex
+	base    time.Duration
+	max     time.Duration
+	strikes int
+	until   time.Time
+	now     func() time.Time
+	jitter  func(time.Duration) time.Duration
+}
+
+func NewBackoff(base, max time.Duration) *Backoff {
+	return &Backoff{
+		base: base,
+		max:  max,
+		now:  time.Now,
+		jitter: func(d time.Duration) time.Duration {
+			return rand.N(d/4 + 1)
+		},
+	}
+}
+
+// Throttled records a 429 and returns the pause before the next request.
+func (b *Backoff) Throttled(retryAfter time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.strikes++
+	d := b.base
+	for i := 1; i < b.strikes && d < b.max; i++ {
+		d *= 2
+	}
+	d = max(min(d, b.max), retryAfter)
+	d += b.jitter(d)
+	b.until = b.now().Add(d)
+	return d
+}
+
+// Recovered clears the backoff after a successful request.
+func (b *Backoff) Recovered() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.strikes = 0
+	b.until = time.Time{}
+}
+
+// State reports the consecutive 429s and the time left before requests may
+// resume.
+func (b *Backoff) State() (strikes int, remaining time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.strikes, max(b.until.Sub(b.now()), 0)
+}
+
+// Wait blocks until the current pause is over or ctx is cancelled. It fails
+// at once when ctx would expire before the pause ends.
+func (b *Backoff) Wait(ctx context.Context) error {
+	_, remaining := b.State()
+	if remaining <= 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < remaining {
+		return fmt.Errorf("throttled for another %s", remaining.Round(time.Second))
+	}
+	t := time.NewTimer(remaining)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is missing or unreadable.
+func parseRetryAfter(h http.Header, now time.Time) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -51,3 +52,52 @@ func TestRateLimiterHonorsContext(t *testing.T) {
 		t.Fatalf("wait should stop after context cancellation")
 	}
 }
+
+func TestBackoffDoublesAndHonorsRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	b := NewBackoff(time.Second, 5*time.Second)
+	b.now = func() time.Time { return now }
+	b.jitter = func(time.Duration) time.Duration { return 0 }
+
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := b.Throttled(0); got != want {
+			t.Fatalf("strike %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+	if got := b.Throttled(30 * time.Second); got != 30*time.Second {
+		t.Fatalf("expected Retry-After to win, got %v", got)
+	}
+	if strikes, remaining := b.State(); strikes != 5 || remaining != 30*time.Second {
+		t.Fatalf("unexpected state strikes=%d remaining=%v", strikes, remaining)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := b.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to fail fast when ctx expires before the pause ends")
+	}
+
+	b.Recovered()
+	if strikes, remaining := b.State(); strikes != 0 || remaining != 0 {
+		t.Fatalf("expected a cleared backoff, got strikes=%d remaining=%v", strikes, remaining)
+	}
+	if got := b.Throttled(0); got != time.Second {
+		t.Fatalf("expected the pause to restart at base, got %v", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	h := http.Header{}
+	if got := parseRetryAfter(h, now); got != 0 {
+		t.Fatalf("expected 0 without a header, got %v", got)
+	}
+	h.Set("Retry-After", "12")
+	if got := parseRetryAfter(h, now); got != 12*time.Second {
+		t.Fatalf("expected 12s, got %v", got)
+	}
+	h.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+	if got := parseRetryAfter(h, now); got != time.Minute {
+		t.Fatalf("expected 1m from an HTTP date, got %v", got)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -28,7 +30,7 @@ type RedditProvider struct {
 
 func NewRedditProvider(tracer trace.Tracer) *RedditProvider {
 	return &RedditProvider{
-		client:    &http.Client{Timeout: 20 * time.Second},
+		client:    httpclient.New(httpclient.Reddit, 20*time.Second),
 		baseURL:   redditBaseURL,
 		userAgent: defaultRedditUA,
 		tracer:    tracer,
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"bug-free-umbrella/internal/httpclient"
+
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -21,7 +23,7 @@ type RSSProvider struct {
 
 func NewRSSProvider(tracer trace.Tracer) *RSSProvider {
 	return &RSSProvider{
-		client: &http.Client{Timeout: 20 * time.Second},
+		client: httpclient.New(httpclient.RSS, 20*time.Second),
 		tracer: tracer,
 	}
 }
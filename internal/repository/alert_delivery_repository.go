@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const alertDeliveryColumns = `id, chat_id, COALESCE(signal_id, 0), message, status, attempts, last_error,
+	next_attempt_at, created_at, delivered_at, signal_ids`
+
+// AlertDeliveryRepository persists outgoing Telegram notifications so failed
+// sends can be retried and inspected as dead letters.
+type AlertDeliveryRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewAlertDeliveryRepository(pool PgxPool, tracer trace.Tracer) *AlertDeliveryRepository {
+	return &AlertDeliveryRepository{pool: pool, tracer: tracer}
+}
+
+// CreateAlertDelivery records a pending delivery and returns its ID.
+func (r *AlertDeliveryRepository) CreateAlertDelivery(ctx context.Context, delivery domain.AlertDelivery) (int64, error) {
+	_, span := r.tracer.Start(ctx, "alert-delivery-repo.create")
+	defer span.End()
+
+	// NULL would violate the NOT NULL column.
+	signalIDs := delivery.SignalIDs
+	if signalIDs == nil {
+		signalIDs = []int64{}
+	}
+	var id int64
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO alert_deliveries (chat_id, signal_id, message, status, next_attempt_at, signal_ids)
+		 VALUES ($1, NULLIF($2, 0), $3, 'pending', $4, $5)
+		 RETURNING id`,
+		delivery.ChatID, delivery.SignalID, delivery.Message, delivery.NextAttemptAt.UTC(), signalIDs,
+	).Scan(&id)
+	return id, err
+}
+
+// RecordAlertDeliveryAttempt counts one send attempt and moves the delivery
+// to status.
+func (r *AlertDeliveryRepository) RecordAlertDeliveryAttempt(
+	ctx context.Context,
+	id int64,
+	status domain.AlertDeliveryStatus,
+	lastError string,
+	nextAttemptAt time.Time,
+) error {
+	_, span := r.tracer.Start(ctx, "alert-delivery-repo.record-attempt")
+	defer span.End()
+
+	_, err := r.pool.Exec(ctx,
+		`UPDATE alert_deliveries
+		 SET status = $2,
+		     attempts = attempts + 1,
+		     last_error = $3,
+		     next_attempt_at = $4,
+		     delivered_at = CASE WHEN $2 = 'sent' THEN NOW() ELSE delivered_at END
+		 WHERE id = $1`,
+		id, string(status), lastError, nextAttemptAt.UTC(),
+	)
+	return err
+}
+
+// ListDueAlertDeliveries returns pending or failed deliveries whose next
+// attempt is due, oldest first.
+func (r *AlertDeliveryRepository) ListDueAlertDeliveries(ctx context.Context, limit int) ([]domain.AlertDelivery, error) {
+	_, span := r.tracer.Start(ctx, "alert-delivery-repo.list-due")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+alertDeliveryColumns+`
+		 FROM alert_deliveries
+		 WHERE status IN ('pending', 'failed') AND next_attempt_at <= NOW()
+		 ORDER BY next_attempt_at
+		 LIMIT $1`,
+		normalizeAlertDeliveryLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAlertDeliveries(rows)
+}
+
+// ListDeadAlertDeliveries returns dead letters, newest first.
+func (r *AlertDeliveryRepository) ListDeadAlertDeliveries(ctx context.Context, limit int) ([]domain.AlertDelivery, error) {
+	_, span := r.tracer.Start(ctx, "alert-delivery-repo.list-dead")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+alertDeliveryColumns+`
+		 FROM alert_deliveries
+		 WHERE status = 'dead'
+		 ORDER BY created_at DESC
+		 LIMIT $1`,
+		normalizeAlertDeliveryLimit(limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAlertDeliveries(rows)
+}
+
+// RequeueAlertDelivery resets a dead letter to pending with a fresh retry
+// budget. It returns nil when id is not a dead letter.
+func (r *AlertDeliveryRepository) RequeueAlertDelivery(ctx context.Context, id int64) (*domain.AlertDelivery, error) {
+	_, span := r.tracer.Start(ctx, "alert-delivery-repo.requeue")
+	defer span.End()
+
+	d, err := scanAlertDelivery(r.pool.QueryRow(ctx,
+		`UPDATE alert_deliveries
+		 SET status = 'pending', attempts = 0, last_error = '', next_attempt_at = NOW()
+		 WHERE id = $1 AND status = 'dead'
+		 RETURNING `+alertDeliveryColumns,
+		id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func normalizeAlertDeliveryLimit(limit int) int {
+	if limit <= 0 {
+		return 50
+	}
+	return min(limit, 500)
+}
+
+func scanAlertDeliveries(rows pgx.Rows) ([]domain.AlertDelivery, error) {
+	defer rows.Close()
+
+	var out []domain.AlertDelivery
+	for rows.Next() {
+		d, err := scanAlertDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func scanAlertDelivery(row pgx.Row) (domain.AlertDelivery, error) {
+	var d domain.AlertDelivery
+	var status string
+	if err := row.Scan(
+		&d.ID,
+		&d.ChatID,
+		&d.SignalID,
+		&d.Message,
+		&status,
+		&d.Attempts,
+		&d.LastError,
+		&d.NextAttemptAt,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+		&d.SignalIDs,
+	); err != nil {
+		return domain.AlertDelivery{}, err
+	}
+	if len(d.SignalIDs) == 0 {
+		d.SignalIDs = nil
+	}
+	d.Status = domain.AlertDeliveryStatus(status)
+	return d, nil
+}
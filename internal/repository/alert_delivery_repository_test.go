@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAlertDeliveryCreate(t *testing.T) {
+	pool := &alertDeliveryStubPool{queryRowData: []any{int64(7)}}
+	repo := NewAlertDeliveryRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	id, err := repo.CreateAlertDelivery(context.Background(), domain.AlertDelivery{ChatID: 10, SignalID: 3, Message: "hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 7 {
+		t.Fatalf("expected id 7, got %d", id)
+	}
+	if !strings.Contains(pool.lastSQL, "NULLIF($2, 0)") || pool.lastArgs[0] != int64(10) {
+		t.Fatalf("unexpected insert: %s %v", pool.lastSQL, pool.lastArgs)
+	}
+}
+
+func TestAlertDeliveryCreateStoresGroupedSignals(t *testing.T) {
+	pool := &alertDeliveryStubPool{queryRowData: []any{int64(8)}}
+	repo := NewAlertDeliveryRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	if _, err := repo.CreateAlertDelivery(context.Background(), domain.AlertDelivery{ChatID: 10, Message: "hi"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// NULL would violate the NOT NULL column.
+	if got, ok := pool.lastArgs[4].([]int64); !ok || got == nil {
+		t.Fatalf("expected non-nil empty slice, got %#v", pool.lastArgs[4])
+	}
+
+	grouped := domain.AlertDelivery{ChatID: 10, SignalID: 3, SignalIDs: []int64{3, 4}, Message: "hi"}
+	if _, err := repo.CreateAlertDelivery(context.Background(), grouped); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := pool.lastArgs[4].([]int64); len(got) != 2 || got[1] != 4 {
+		t.Fatalf("unexpected signal ids %v", got)
+	}
+}
+
+func TestAlertDeliveryRecordAttempt(t *testing.T) {
+	pool := &alertDeliveryStubPool{}
+	repo := NewAlertDeliveryRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	if err := repo.RecordAlertDeliveryAttempt(context.Background(), 4, domain.AlertDeliveryFailed, "timeout", time.Unix(60, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(pool.lastSQL, "attempts = attempts + 1") || pool.lastArgs[1] != "failed" {
+		t.Fatalf("unexpected update: %s %v", pool.lastSQL, pool.lastArgs)
+	}
+}
+
+func TestAlertDeliveryListDead(t *testing.T) {
+	delivered := time.Unix(120, 0).UTC()
+	pool := &alertDeliveryStubPool{rowsData: [][]any{
+		{int64(1), int64(10), int64(0), "maintenance", "dead", 5, "telegram: blocked (403)", time.Unix(0, 0), time.Unix(0, 0), nil, []int64{}},
+		{int64(2), int64(11), int64(9), "BTC 1h RSI LONG", "dead", 1, "chat not found", time.Unix(0, 0), time.Unix(0, 0), delivered, []int64{9, 12}},
+	}}
+	repo := NewAlertDeliveryRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	got, err := repo.ListDeadAlertDeliveries(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Status != domain.AlertDeliveryDead || got[0].Attempts != 5 || got[0].DeliveredAt != nil {
+		t.Fatalf("unexpected dead letters: %+v", got)
+	}
+	if got[0].SignalIDs != nil {
+		t.Fatalf("expected no grouped signals, got %v", got[0].SignalIDs)
+	}
+	if got[1].SignalID != 9 || got[1].DeliveredAt == nil || len(got[1].SignalIDs) != 2 {
+		t.Fatalf("unexpected second row: %+v", got[1])
+	}
+	if !strings.Contains(pool.lastSQL, "status = 'dead'") || pool.lastArgs[0] != 50 {
+		t.Fatalf("unexpected query: %s %v", pool.lastSQL, pool.lastArgs)
+	}
+}
+
+func TestAlertDeliveryRequeueMissingReturnsNil(t *testing.T) {
+	pool := &alertDeliveryStubPool{}
+	repo := NewAlertDeliveryRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	got, err := repo.RequeueAlertDelivery(context.Background(), 99)
+	if err != nil || got != nil {
+		t.Fatalf("expected nil for non-dead delivery, got %+v err=%v", got, err)
+	}
+}
+
+type alertDeliveryStubPool struct {
+	queryRowData []any
+	rowsData     [][]any
+	lastSQL      string
+	lastArgs     []any
+}
+
+func (s *alertDeliveryStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return pgconn.CommandTag{}, nil
+}
+
+func (s *alertDeliveryStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &stubBatchResults{}
+}
+
+func (s *alertDeliveryStubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return &sshStubRows{data: s.rowsData}, nil
+}
+
+func (s *alertDeliveryStubPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return &sshStubRow{data: s.queryRowData}
+}
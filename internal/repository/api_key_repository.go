@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type APIKey struct {
+	ID        int64      `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	Label     string     `json:"label"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type APIKeyRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewAPIKeyRepository(pool PgxPool, tracer trace.Tracer) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool, tracer: tracer}
+}
+
+// HashAPIKey returns the storage hash for a raw API key. Raw keys are never persisted.
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(rawKey)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ResolveTenant returns the tenant bound to an active API key. The boolean is
+// false when the key is unknown or revoked.
+func (r *APIKeyRepository) ResolveTenant(ctx context.Context, rawKey string) (string, bool, error) {
+	_, span := r.tracer.Start(ctx, "api-key-repo.resolve-tenant")
+	defer span.End()
+
+	var tenantID string
+	err := r.pool.QueryRow(ctx,
+		`SELECT tenant_id FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`,
+		HashAPIKey(rawKey),
+	).Scan(&tenantID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return tenantID, true, nil
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, tenantID, label, rawKey string) (*APIKey, error) {
+	_, span := r.tracer.Start(ctx, "api-key-repo.create-api-key")
+	defer span.End()
+
+	tenantID = domain.NormalizeTenantID(tenantID)
+	if _, err := r.pool.Exec(ctx,
+		`INSERT INTO tenants (id, name) VALUES ($1, $1) ON CONFLICT (id) DO NOTHING`,
+		tenantID,
+	); err != nil {
+		return nil, err
+	}
+
+	key := &APIKey{TenantID: tenantID, Label: strings.TrimSpace(label)}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO api_keys (tenant_id, key_hash, label)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, created_at`,
+		tenantID, HashAPIKey(rawKey), key.Label,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	key.CreatedAt = key.CreatedAt.UTC()
+	return key, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	_, span := r.tracer.Start(ctx, "api-key-repo.revoke-api-key")
+	defer span.End()
+
+	_, err := r.pool.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`,
+		id,
+	)
+	return err
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, tenantID string) ([]APIKey, error) {
+	_, span := r.tracer.Start(ctx, "api-key-repo.list-api-keys")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, tenant_id, label, created_at, revoked_at
+		 FROM api_keys
+		 WHERE tenant_id = $1
+		 ORDER BY created_at DESC`,
+		domain.NormalizeTenantID(tenantID),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		var k APIKey
+		var revokedAt *time.Time
+		if err := rows.Scan(&k.ID, &k.TenantID, &k.Label, &k.CreatedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		k.CreatedAt = k.CreatedAt.UTC()
+		k.RevokedAt = revokedAt
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAPIKeyResolveTenantHashesKey(t *testing.T) {
+	pool := &apiKeyStubPool{queryRowData: []any{"acme"}}
+	repo := NewAPIKeyRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	tenantID, ok, err := repo.ResolveTenant(context.Background(), "secret-key")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok || tenantID != "acme" {
+		t.Fatalf("expected acme tenant, got %q ok=%v", tenantID, ok)
+	}
+	if len(pool.queryRowArgs) != 1 || pool.queryRowArgs[0] != HashAPIKey("secret-key") {
+		t.Fatalf("expected hashed key lookup, got %v", pool.queryRowArgs)
+	}
+	if pool.queryRowArgs[0] == "secret-key" {
+		t.Fatal("raw key must not be sent to the database")
+	}
+}
+
+func TestAPIKeyResolveTenantUnknownKey(t *testing.T) {
+	pool := &apiKeyStubPool{queryRowErr: pgx.ErrNoRows}
+	repo := NewAPIKeyRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	tenantID, ok, err := repo.ResolveTenant(context.Background(), "nope")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok || tenantID != "" {
+		t.Fatalf("expected unknown key, got %q ok=%v", tenantID, ok)
+	}
+}
+
+func TestAPIKeyCreateEnsuresTenant(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pool := &apiKeyStubPool{queryRowData: []any{int64(7), now}}
+	repo := NewAPIKeyRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	key, err := repo.CreateAPIKey(context.Background(), " Acme ", "ci", "raw")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.execCount != 1 {
+		t.Fatalf("expected tenant upsert exec, got %d", pool.execCount)
+	}
+	if key.ID != 7 || key.TenantID != "acme" || key.Label != "ci" {
+		t.Fatalf("unexpected key: %+v", key)
+	}
+}
+
+type apiKeyStubPool struct {
+	execCount    int
+	queryRowData []any
+	queryRowErr  error
+	queryRowArgs []any
+	queryArgs    []any
+	rowsData     [][]any
+}
+
+func (s *apiKeyStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	s.execCount++
+	return pgconn.CommandTag{}, nil
+}
+
+func (s *apiKeyStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &sshStubBatchResults{}
+}
+
+func (s *apiKeyStubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.queryArgs = args
+	return &sshStubRows{data: s.rowsData}, nil
+}
+
+func (s *apiKeyStubPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	s.queryRowArgs = args
+	return &sshStubRow{data: s.queryRowData, err: s.queryRowErr}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AssetRepository stores the symbol registry in the tracked_assets table.
+type AssetRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewAssetRepository(pool PgxPool, tracer trace.Tracer) *AssetRepository {
+	return &AssetRepository{pool: pool, tracer: tracer}
+}
+
+// ListAssets returns the enabled assets in registry order.
+func (r *AssetRepository) ListAssets(ctx context.Context) ([]domain.Asset, error) {
+	_, span := r.tracer.Start(ctx, "asset-repo.list")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, name, coingecko_id, binance_pair, kraken_pair, coinbase_pair, price_decimals,
+		        signals_paused, ml_paused, alerts_paused
+		 FROM tracked_assets
+		 WHERE enabled
+		 ORDER BY position ASC, symbol ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Asset
+	for rows.Next() {
+		a, err := scanAsset(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// UpsertAsset adds a or updates its identifiers, enabling it again if it was
+// removed. New assets go to the end of the registry; existing ones keep
+// their place and their pauses.
+func (r *AssetRepository) UpsertAsset(ctx context.Context, a domain.Asset) (domain.Asset, error) {
+	_, span := r.tracer.Start(ctx, "asset-repo.upsert")
+	defer span.End()
+
+	return scanAsset(r.pool.QueryRow(ctx,
+		`INSERT INTO tracked_assets (
+		     symbol, name, coingecko_id, binance_pair, kraken_pair, coinbase_pair,
+		     price_decimals, enabled, position, updated_at
+		 )
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, TRUE,
+		         (SELECT COALESCE(MAX(position), 0) + 1 FROM tracked_assets), NOW())
+		 ON CONFLICT (symbol) DO UPDATE SET
+		     name = EXCLUDED.name,
+		     coingecko_id = EXCLUDED.coingecko_id,
+		     binance_pair = EXCLUDED.binance_pair,
+		     kraken_pair = EXCLUDED.kraken_pair,
+		     coinbase_pair = EXCLUDED.coinbase_pair,
+		     price_decimals = EXCLUDED.price_decimals,
+		     enabled = TRUE,
+		     updated_at = EXCLUDED.updated_at
+		 RETURNING symbol, name, coingecko_id, binance_pair, kraken_pair, coinbase_pair, price_decimals,
+		           signals_paused, ml_paused, alerts_paused`,
+		a.Symbol, a.Name, a.CoinGeckoID, a.BinancePair, a.KrakenPair, a.CoinbasePair, a.PriceDecimals,
+	))
+}
+
+// DisableAsset removes symbol from the registry, keeping its row and stored
+// history. It returns pgx.ErrNoRows when the symbol is not tracked.
+func (r *AssetRepository) DisableAsset(ctx context.Context, symbol string) error {
+	_, span := r.tracer.Start(ctx, "asset-repo.disable")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE tracked_assets SET enabled = FALSE, updated_at = NOW()
+		 WHERE symbol = $1 AND enabled`,
+		symbol,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// SetAssetPauses replaces the pauses of the tracked symbol and returns the
+// updated asset. It returns pgx.ErrNoRows when the symbol is not tracked.
+func (r *AssetRepository) SetAssetPauses(ctx context.Context, symbol string, p domain.AssetPauses) (domain.Asset, error) {
+	_, span := r.tracer.Start(ctx, "asset-repo.set-pauses")
+	defer span.End()
+
+	return scanAsset(r.pool.QueryRow(ctx,
+		`UPDATE tracked_assets
+		 SET signals_paused = $2, ml_paused = $3, alerts_paused = $4, updated_at = NOW()
+		 WHERE symbol = $1 AND enabled
+		 RETURNING symbol, name, coingecko_id, binance_pair, kraken_pair, coinbase_pair, price_decimals,
+		           signals_paused, ml_paused, alerts_paused`,
+		symbol, p.SignalsPaused, p.MLPaused, p.AlertsPaused,
+	))
+}
+
+func scanAsset(row pgx.Row) (domain.Asset, error) {
+	var a domain.Asset
+	if err := row.Scan(&a.Symbol, &a.Name, &a.CoinGeckoID, &a.BinancePair, &a.KrakenPair, &a.CoinbasePair, &a.PriceDecimals,
+		&a.SignalsPaused, &a.MLPaused, &a.AlertsPaused); err != nil {
+		return domain.Asset{}, err
+	}
+	return a, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuditRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewAuditRepository(pool PgxPool, tracer trace.Tracer) *AuditRepository {
+	return &AuditRepository{pool: pool, tracer: tracer}
+}
+
+func (r *AuditRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) (*domain.AuditEntry, error) {
+	_, span := r.tracer.Start(ctx, "audit-repo.append-audit-entry")
+	defer span.End()
+
+	out := entry
+	out.TenantID = domain.NormalizeTenantID(entry.TenantID)
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO audit_log (tenant_id, actor, action, entity_type, entity_id, before_json, after_json)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING id, created_at`,
+		out.TenantID,
+		out.Actor,
+		out.Action,
+		out.EntityType,
+		out.EntityID,
+		nullIfEmpty(out.BeforeJSON),
+		nullIfEmpty(out.AfterJSON),
+	).Scan(&out.ID, &out.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	out.CreatedAt = out.CreatedAt.UTC()
+	return &out, nil
+}
+
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	_, span := r.tracer.Start(ctx, "audit-repo.list-audit-entries")
+	defer span.End()
+
+	args := make([]any, 0, 7)
+	var sb strings.Builder
+	sb.WriteString(`SELECT id, tenant_id, actor, action, entity_type, entity_id,
+	       COALESCE(before_json, ''), COALESCE(after_json, ''), created_at
+	FROM audit_log
+	WHERE 1=1`)
+
+	if filter.TenantID != "" {
+		args = append(args, domain.NormalizeTenantID(filter.TenantID))
+		sb.WriteString(fmt.Sprintf(" AND tenant_id = $%d", len(args)))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		sb.WriteString(fmt.Sprintf(" AND actor = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		sb.WriteString(fmt.Sprintf(" AND action = $%d", len(args)))
+	}
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		sb.WriteString(fmt.Sprintf(" AND entity_type = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, filter.From.UTC())
+		sb.WriteString(fmt.Sprintf(" AND created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, filter.To.UTC())
+		sb.WriteString(fmt.Sprintf(" AND created_at < $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	args = append(args, limit)
+	sb.WriteString(fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args)))
+
+	rows, err := r.pool.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0, limit)
+	for rows.Next() {
+		var e domain.AuditEntry
+		var createdAt time.Time
+		if err := rows.Scan(
+			&e.ID,
+			&e.TenantID,
+			&e.Actor,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityID,
+			&e.BeforeJSON,
+			&e.AfterJSON,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = createdAt.UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func nullIfEmpty(v string) any {
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	return v
+}
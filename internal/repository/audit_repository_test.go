@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAuditAppendAuditEntryReturnsID(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pool := &auditStubPool{queryRowData: []any{int64(5), now}}
+	repo := NewAuditRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	entry, err := repo.AppendAuditEntry(context.Background(), domain.AuditEntry{
+		Actor:      "api:default",
+		Action:     domain.AuditActionAPIKeyCreate,
+		EntityType: domain.AuditEntityAPIKey,
+		EntityID:   "12",
+		AfterJSON:  `{"label":"ci"}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.ID != 5 || entry.TenantID != domain.DefaultTenantID || !entry.CreatedAt.Equal(now) {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if pool.lastArgs[5] != nil {
+		t.Fatalf("expected empty before_json to be stored as NULL, got %v", pool.lastArgs[5])
+	}
+}
+
+func TestAuditListAuditEntriesAppliesFilters(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pool := &auditStubPool{rowsData: [][]any{
+		{int64(1), "default", "system", domain.AuditActionModelActivate, domain.AuditEntityModel, "ml_xgboost_up4h", `{"version":2}`, `{"version":3}`, now},
+	}}
+	repo := NewAuditRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	from := now.Add(-time.Hour)
+	to := now.Add(time.Hour)
+	entries, err := repo.ListAuditEntries(context.Background(), domain.AuditFilter{
+		Action: domain.AuditActionModelActivate,
+		From:   &from,
+		To:     &to,
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EntityID != "ml_xgboost_up4h" || entries[0].AfterJSON != `{"version":3}` {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if !strings.Contains(pool.lastSQL, "created_at >= $2") || !strings.Contains(pool.lastSQL, "created_at < $3") {
+		t.Fatalf("expected time filters in query, got %s", pool.lastSQL)
+	}
+	if len(pool.lastArgs) != 4 || pool.lastArgs[3] != 10 {
+		t.Fatalf("unexpected args: %v", pool.lastArgs)
+	}
+}
+
+type auditStubPool struct {
+	queryRowData []any
+	rowsData     [][]any
+	lastSQL      string
+	lastArgs     []any
+}
+
+func (s *auditStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (s *auditStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &sshStubBatchResults{}
+}
+
+func (s *auditStubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return &sshStubRows{data: s.rowsData}, nil
+}
+
+func (s *auditStubPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return &sshStubRow{data: s.queryRowData}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -17,6 +17,12 @@ type DailyAccuracy struct {
 	Accuracy float64
 }
 
+// AccuracySeries is one model's daily accuracy, oldest day first.
+type AccuracySeries struct {
+	ModelKey string
+	Days     []DailyAccuracy
+}
+
 type BacktestRepository struct {
 	pool   PgxPool
 	tracer trace.Tracer
@@ -59,6 +65,295 @@ func (r *BacktestRepository) GetDailyAccuracy(ctx context.Context, modelKey stri
 	return out, rows.Err()
 }
 
+// GetDailyAccuracySeries returns the last days of daily accuracy for every
+// model in one query, grouped by model key.
+func (r *BacktestRepository) GetDailyAccuracySeries(ctx context.Context, days int) ([]AccuracySeries, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-daily-accuracy-series")
+	defer span.End()
+
+	if days <= 0 {
+		days = 30
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT model_key, day_utc, total, correct, accuracy
+		 FROM ml_accuracy_daily
+		 WHERE day_utc >= DATE_TRUNC('day', NOW() AT TIME ZONE 'UTC') - ($1::INT - 1) * INTERVAL '1 day'
+		 ORDER BY model_key ASC, day_utc ASC`,
+		days,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []AccuracySeries
+	for rows.Next() {
+		var d DailyAccuracy
+		if err := rows.Scan(&d.ModelKey, &d.DayUTC, &d.Total, &d.Correct, &d.Accuracy); err != nil {
+			return nil, err
+		}
+		d.DayUTC = d.DayUTC.UTC()
+		if n := len(out); n == 0 || out[n-1].ModelKey != d.ModelKey {
+			out = append(out, AccuracySeries{ModelKey: d.ModelKey})
+		}
+		last := &out[len(out)-1]
+		last.Days = append(last.Days, d)
+	}
+	return out, rows.Err()
+}
+
+// GetCalibration buckets resolved predictions by prob_up into equal-width
+// bins over [0, 1] and reports how often each bin actually rose. An empty
+// modelKey pools every model. Empty bins are omitted.
+func (r *BacktestRepository) GetCalibration(ctx context.Context, modelKey string, buckets int) ([]domain.CalibrationBucket, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-calibration")
+	defer span.End()
+
+	if buckets <= 0 {
+		buckets = domain.DefaultCalibrationBuckets
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT LEAST(WIDTH_BUCKET(prob_up, 0, 1, $2), $2) AS bucket,
+		        COUNT(*)::INT AS total,
+		        AVG(prob_up) AS mean_predicted,
+		        AVG(CASE WHEN actual_up THEN 1.0 ELSE 0.0 END)::DOUBLE PRECISION AS realized
+		 FROM ml_predictions
+		 WHERE resolved_at IS NOT NULL
+		   AND actual_up IS NOT NULL
+		   AND ($1 = '' OR model_key = $1)
+		 GROUP BY bucket
+		 ORDER BY bucket ASC`,
+		modelKey, buckets,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	width := 1.0 / float64(buckets)
+	var out []domain.CalibrationBucket
+	for rows.Next() {
+		var idx int
+		var b domain.CalibrationBucket
+		if err := rows.Scan(&idx, &b.Count, &b.MeanPredicted, &b.RealizedFrequency); err != nil {
+			return nil, err
+		}
+		// WIDTH_BUCKET puts prob_up < 0 in bucket 0; clamp it into the first.
+		if idx < 1 {
+			idx = 1
+		}
+		b.Lower = float64(idx-1) * width
+		b.Upper = float64(idx) * width
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// GetRiskLevelStats groups resolved predictions resolved within the last days
+// (all time when days <= 0) by model and risk level. Returns are signed by
+// the predicted direction; holds count toward the hit rate only.
+func (r *BacktestRepository) GetRiskLevelStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-risk-level-stats")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT model_key, risk, COUNT(*),
+		        COUNT(*) FILTER (WHERE is_correct IS TRUE),
+		        COALESCE(AVG(CASE WHEN direction = 'short' THEN -realized_return ELSE realized_return END)
+		                 FILTER (WHERE direction IN ('long', 'short') AND realized_return IS NOT NULL), 0)
+		 FROM ml_predictions
+		 WHERE resolved_at IS NOT NULL
+		   AND ($1::INT <= 0 OR resolved_at >= NOW() - $1::INT * INTERVAL '1 day')
+		 GROUP BY model_key, risk
+		 ORDER BY model_key ASC, risk ASC`,
+		days,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.RiskLevelStats
+	for rows.Next() {
+		var s domain.RiskLevelStats
+		var risk int16
+		if err := rows.Scan(&s.ModelKey, &risk, &s.Total, &s.Correct, &s.AvgReturn); err != nil {
+			return nil, err
+		}
+		s.Risk = domain.RiskLevel(risk)
+		if s.Total > 0 {
+			s.HitRate = float64(s.Correct) / float64(s.Total)
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetAccuracyBreakdown reports resolved predictions for candles opened in
+// [from, to) by model, symbol, risk level and horizon in hours.
+func (r *BacktestRepository) GetAccuracyBreakdown(ctx context.Context, from, to time.Time) ([]domain.AccuracyBreakdown, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-accuracy-breakdown")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT model_key, symbol, risk,
+		        ROUND(EXTRACT(EPOCH FROM target_time - open_time) / 3600)::INT AS horizon_h,
+		        COUNT(*),
+		        COUNT(*) FILTER (WHERE is_correct IS TRUE),
+		        COUNT(*) FILTER (WHERE direction IN ('long', 'short') AND realized_return IS NOT NULL),
+		        COALESCE(AVG(CASE WHEN direction = 'short' THEN -realized_return ELSE realized_return END)
+		                 FILTER (WHERE direction IN ('long', 'short') AND realized_return IS NOT NULL), 0)
+		 FROM ml_predictions
+		 WHERE resolved_at IS NOT NULL
+		   AND open_time >= $1 AND open_time < $2
+		 GROUP BY model_key, symbol, risk, horizon_h
+		 ORDER BY model_key ASC, symbol ASC, risk ASC, horizon_h ASC`,
+		from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AccuracyBreakdown
+	for rows.Next() {
+		var b domain.AccuracyBreakdown
+		var risk int16
+		if err := rows.Scan(&b.ModelKey, &b.Symbol, &risk, &b.HorizonHours, &b.Total, &b.Correct, &b.Directional, &b.AvgReturn); err != nil {
+			return nil, err
+		}
+		b.Risk = domain.RiskLevel(risk)
+		if b.Total > 0 {
+			b.HitRate = float64(b.Correct) / float64(b.Total)
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// GetAnomalyHeat summarises the anomaly score series per symbol and UTC day
+// over the last days days. An empty interval pools every interval. Days
+// without scores are omitted.
+func (r *BacktestRepository) GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-anomaly-heat")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, date_trunc('day', open_time AT TIME ZONE 'UTC') AS day_utc,
+		        COUNT(*), MAX(score), AVG(score), MIN(damp_factor)
+		 FROM ml_anomaly_scores
+		 WHERE open_time >= (date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC') - ($1::INT - 1) * INTERVAL '1 day'
+		   AND ($2 = '' OR interval = $2)
+		 GROUP BY symbol, day_utc
+		 ORDER BY symbol ASC, day_utc ASC`,
+		days, interval,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AnomalyCell
+	for rows.Next() {
+		var c domain.AnomalyCell
+		if err := rows.Scan(&c.Symbol, &c.Day, &c.Samples, &c.MaxScore, &c.MeanScore, &c.MinDampFactor); err != nil {
+			return nil, err
+		}
+		c.Day = c.Day.UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// RefreshDailyCoverage recomputes ml_coverage_daily for the last days UTC
+// days (by candle open time) from ml_predictions. Anomaly models are left
+// out; they never make directional calls.
+func (r *BacktestRepository) RefreshDailyCoverage(ctx context.Context, days int) error {
+	_, span := r.tracer.Start(ctx, "backtest-repo.refresh-daily-coverage")
+	defer span.End()
+
+	if days <= 0 {
+		days = 3
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO ml_coverage_daily (
+		     model_key, day_utc, total, directional, holds, held_anomaly, held_uncertainty,
+		     resolved_directional, correct_directional, updated_at
+		 )
+		 SELECT model_key,
+		        (open_time AT TIME ZONE 'UTC')::DATE,
+		        COUNT(*),
+		        COUNT(*) FILTER (WHERE direction IN ('long', 'short')),
+		        COUNT(*) FILTER (WHERE direction = 'hold'),
+		        COUNT(*) FILTER (WHERE direction = 'hold' AND details_json::jsonb->>'held_reason' = $2),
+		        COUNT(*) FILTER (WHERE direction = 'hold' AND details_json::jsonb->>'held_reason' = $3),
+		        COUNT(*) FILTER (WHERE direction IN ('long', 'short') AND resolved_at IS NOT NULL),
+		        COUNT(*) FILTER (WHERE direction IN ('long', 'short') AND is_correct IS TRUE),
+		        NOW()
+		 FROM ml_predictions
+		 WHERE model_key NOT LIKE 'iforest\_%'
+		   AND open_time >= (date_trunc('day', NOW() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC') - ($1::INT - 1) * INTERVAL '1 day'
+		 GROUP BY 1, 2
+		 ON CONFLICT (model_key, day_utc) DO UPDATE SET
+		     total = EXCLUDED.total,
+		     directional = EXCLUDED.directional,
+		     holds = EXCLUDED.holds,
+		     held_anomaly = EXCLUDED.held_anomaly,
+		     held_uncertainty = EXCLUDED.held_uncertainty,
+		     resolved_directional = EXCLUDED.resolved_directional,
+		     correct_directional = EXCLUDED.correct_directional,
+		     updated_at = EXCLUDED.updated_at`,
+		days, domain.HeldReasonAnomalyDamping, domain.HeldReasonUncertainty,
+	)
+	return err
+}
+
+// GetDailyCoverage returns stored daily coverage for the last days UTC days,
+// newest first. An empty modelKey returns every model.
+func (r *BacktestRepository) GetDailyCoverage(ctx context.Context, modelKey string, days int) ([]domain.CoverageDay, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-daily-coverage")
+	defer span.End()
+
+	if days <= 0 {
+		days = 30
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT model_key, day_utc::TIMESTAMPTZ, total, directional, holds, held_anomaly, held_uncertainty,
+		        resolved_directional, correct_directional
+		 FROM ml_coverage_daily
+		 WHERE day_utc >= (NOW() AT TIME ZONE 'UTC')::DATE - ($1::INT - 1)
+		   AND ($2 = '' OR model_key = $2)
+		 ORDER BY day_utc DESC, model_key ASC`,
+		days, modelKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.CoverageDay
+	for rows.Next() {
+		var d domain.CoverageDay
+		if err := rows.Scan(&d.ModelKey, &d.Day, &d.Total, &d.Directional, &d.Holds, &d.HeldAnomaly,
+			&d.HeldUncertainty, &d.ResolvedDirectional, &d.CorrectDirectional); err != nil {
+			return nil, err
+		}
+		d.Day = d.Day.UTC()
+		if d.Total > 0 {
+			d.Coverage = float64(d.Directional) / float64(d.Total)
+		}
+		if d.ResolvedDirectional > 0 {
+			d.DirectionalAccuracy = float64(d.CorrectDirectional) / float64(d.ResolvedDirectional)
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
 func (r *BacktestRepository) GetAccuracySummary(ctx context.Context) ([]DailyAccuracy, error) {
 	_, span := r.tracer.Start(ctx, "backtest-repo.get-accuracy-summary")
 	defer span.End()
@@ -137,3 +432,33 @@ func (r *BacktestRepository) ListRecentPredictions(ctx context.Context, limit in
 	}
 	return out, rows.Err()
 }
+
+// GetPrediction returns one prediction by id, or pgx.ErrNoRows.
+func (r *BacktestRepository) GetPrediction(ctx context.Context, id int64) (*domain.MLPrediction, error) {
+	_, span := r.tracer.Start(ctx, "backtest-repo.get-prediction")
+	defer span.End()
+
+	var p domain.MLPrediction
+	var direction string
+	var risk int16
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, symbol, interval, open_time, target_time,
+		        model_key, model_version, prob_up, confidence,
+		        direction, risk, signal_id, details_json, created_at,
+		        resolved_at, actual_up, is_correct, realized_return
+		 FROM ml_predictions
+		 WHERE id = $1`,
+		id,
+	).Scan(
+		&p.ID, &p.Symbol, &p.Interval, &p.OpenTime, &p.TargetTime,
+		&p.ModelKey, &p.ModelVersion, &p.ProbUp, &p.Confidence,
+		&direction, &risk, &p.SignalID, &p.DetailsJSON, &p.CreatedAt,
+		&p.ResolvedAt, &p.ActualUp, &p.IsCorrect, &p.RealizedReturn,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.Direction = domain.SignalDirection(direction)
+	p.Risk = domain.RiskLevel(risk)
+	return &p, nil
+}
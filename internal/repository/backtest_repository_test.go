@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -48,6 +49,159 @@ func TestBacktestGetDailyAccuracyEmpty(t *testing.T) {
 	}
 }
 
+func TestBacktestGetDailyAccuracySeriesGroupsByModel(t *testing.T) {
+	day := time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC)
+	pool := &btStubPool{
+		rowsData: [][]any{
+			{"ml_logreg_up4h", day, 10, 6, 0.6},
+			{"ml_logreg_up4h", day.AddDate(0, 0, 1), 10, 7, 0.7},
+			{"ml_xgboost_up4h", day, 8, 6, 0.75},
+		},
+	}
+	repo := NewBacktestRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	series, err := repo.GetDailyAccuracySeries(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(series) != 2 {
+		t.Fatalf("expected 2 models, got %d", len(series))
+	}
+	if series[0].ModelKey != "ml_logreg_up4h" || len(series[0].Days) != 2 {
+		t.Fatalf("unexpected first series %+v", series[0])
+	}
+	if series[0].Days[1].Accuracy != 0.7 {
+		t.Fatalf("expected days oldest first, got %+v", series[0].Days)
+	}
+	if series[1].ModelKey != "ml_xgboost_up4h" || len(series[1].Days) != 1 {
+		t.Fatalf("unexpected second series %+v", series[1])
+	}
+}
+
+func TestBacktestGetCalibrationBucketBounds(t *testing.T) {
+	pool := &btStubPool{
+		rowsData: [][]any{
+			{1, 4, 0.08, 0.25},
+			{7, 20, 0.64, 0.6},
+		},
+	}
+	repo := NewBacktestRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	buckets, err := repo.GetCalibration(context.Background(), "ensemble_v1", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(buckets))
+	}
+	if buckets[0].Lower != 0 || buckets[0].Count != 4 {
+		t.Fatalf("unexpected first bucket %+v", buckets[0])
+	}
+	if math.Abs(buckets[1].Lower-0.6) > 1e-9 || math.Abs(buckets[1].Upper-0.7) > 1e-9 {
+		t.Fatalf("expected [0.6, 0.7), got %+v", buckets[1])
+	}
+	if buckets[1].RealizedFrequency != 0.6 {
+		t.Fatalf("expected realized 0.6, got %v", buckets[1].RealizedFrequency)
+	}
+}
+
+func TestBacktestGetRiskLevelStatsComputesHitRate(t *testing.T) {
+	pool := &btStubPool{
+		rowsData: [][]any{
+			{"ml_logreg_up4h", 2, int64(40), int64(28), 0.004},
+			{"ml_logreg_up4h", 5, int64(10), int64(4), -0.002},
+		},
+	}
+	repo := NewBacktestRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	stats, err := repo.GetRiskLevelStats(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(stats))
+	}
+	if stats[0].Risk != 2 || stats[0].HitRate != 0.7 || stats[0].AvgReturn != 0.004 {
+		t.Fatalf("unexpected first row %+v", stats[0])
+	}
+	if stats[1].Risk != 5 || stats[1].HitRate != 0.4 {
+		t.Fatalf("unexpected second row %+v", stats[1])
+	}
+}
+
+func TestBacktestGetAccuracyBreakdownComputesHitRate(t *testing.T) {
+	pool := &btStubPool{
+		rowsData: [][]any{
+			{"ensemble_v1", "BTC", 2, 4, int64(20), int64(15), int64(12), 0.006},
+			{"ensemble_v1", "ETH", 3, 4, int64(0), int64(0), int64(0), 0.0},
+		},
+	}
+	repo := NewBacktestRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	rows, err := repo.GetAccuracyBreakdown(context.Background(), from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Symbol != "BTC" || rows[0].Risk != 2 || rows[0].HorizonHours != 4 || rows[0].HitRate != 0.75 || rows[0].Directional != 12 {
+		t.Fatalf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].HitRate != 0 {
+		t.Fatalf("expected an empty group to have a 0 hit rate, got %+v", rows[1])
+	}
+}
+
+func TestBacktestGetAnomalyHeat(t *testing.T) {
+	day := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	pool := &btStubPool{
+		rowsData: [][]any{
+			{"BTC", day, int64(24), 0.91, 0.42, 0.41},
+			{"BTC", day.AddDate(0, 0, 1), int64(24), 0.3, 0.2, 0.8},
+		},
+	}
+	repo := NewBacktestRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	cells, err := repo.GetAnomalyHeat(context.Background(), 14, "1h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cells) != 2 {
+		t.Fatalf("expected 2 cells, got %d", len(cells))
+	}
+	if cells[0].Symbol != "BTC" || !cells[0].Day.Equal(day) || cells[0].Samples != 24 ||
+		cells[0].MaxScore != 0.91 || cells[0].MinDampFactor != 0.41 {
+		t.Fatalf("unexpected first cell %+v", cells[0])
+	}
+}
+
+func TestBacktestGetDailyCoverageComputesRates(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	pool := &btStubPool{
+		rowsData: [][]any{
+			{"ml_ensemble_up4h", day, int64(24), int64(6), int64(18), int64(2), int64(3), int64(5), int64(4)},
+			{"ml_logreg_up4h", day, int64(0), int64(0), int64(0), int64(0), int64(0), int64(0), int64(0)},
+		},
+	}
+	repo := NewBacktestRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	days, err := repo.GetDailyCoverage(context.Background(), "", 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(days))
+	}
+	if days[0].Coverage != 0.25 || days[0].DirectionalAccuracy != 0.8 || days[0].HeldUncertainty != 3 {
+		t.Fatalf("unexpected first row %+v", days[0])
+	}
+	if days[1].Coverage != 0 || days[1].DirectionalAccuracy != 0 {
+		t.Fatalf("expected zero rates without predictions, got %+v", days[1])
+	}
+}
+
 func TestBacktestGetAccuracySummary(t *testing.T) {
 	now := time.Now().UTC()
 	pool := &btStubPool{
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CandleIntegrityRepository stores candle verification results in
+// candle_integrity_checks.
+type CandleIntegrityRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewCandleIntegrityRepository(pool PgxPool, tracer trace.Tracer) *CandleIntegrityRepository {
+	return &CandleIntegrityRepository{pool: pool, tracer: tracer}
+}
+
+func (r *CandleIntegrityRepository) InsertChecks(ctx context.Context, checks []domain.CandleIntegrityCheck) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "candle-integrity-repo.insert")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, c := range checks {
+		mismatches := c.Mismatches
+		if mismatches == nil {
+			mismatches = []domain.CandleMismatch{}
+		}
+		raw, err := json.Marshal(mismatches)
+		if err != nil {
+			return err
+		}
+		batch.Queue(
+			`INSERT INTO candle_integrity_checks (
+			     symbol, interval, provider, checked_at, sampled, missing,
+			     mismatched, max_diff_pct, mismatches
+			 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::jsonb)`,
+			c.Symbol, c.Interval, c.Provider, c.CheckedAt.UTC(), c.Sampled, c.Missing,
+			c.Mismatched, c.MaxDiffPct, string(raw),
+		)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range checks {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRates sums the checks since the given time per symbol and interval.
+func (r *CandleIntegrityRepository) ListRates(ctx context.Context, since time.Time) ([]domain.CandleIntegrityRate, error) {
+	_, span := r.tracer.Start(ctx, "candle-integrity-repo.list-rates")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, interval, COUNT(*), SUM(sampled - missing), SUM(mismatched),
+		        MAX(checked_at), MAX(checked_at) FILTER (WHERE mismatched > 0)
+		 FROM candle_integrity_checks
+		 WHERE checked_at >= $1
+		 GROUP BY symbol, interval
+		 ORDER BY symbol, interval`,
+		since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.CandleIntegrityRate
+	for rows.Next() {
+		var rate domain.CandleIntegrityRate
+		if err := rows.Scan(&rate.Symbol, &rate.Interval, &rate.Checks, &rate.Compared, &rate.Mismatched,
+			&rate.LastCheckedAt, &rate.LastMismatchAt); err != nil {
+			return nil, err
+		}
+		rate.LastCheckedAt = rate.LastCheckedAt.UTC()
+		if rate.LastMismatchAt != nil {
+			t := rate.LastMismatchAt.UTC()
+			rate.LastMismatchAt = &t
+		}
+		if rate.Compared > 0 {
+			rate.MismatchRate = float64(rate.Mismatched) / float64(rate.Compared)
+		}
+		out = append(out, rate)
+	}
+	return out, rows.Err()
+}
+
+// ListMismatchedChecks returns up to limit checks since the given time that
+// found mismatches, newest first.
+func (r *CandleIntegrityRepository) ListMismatchedChecks(ctx context.Context, since time.Time, limit int) ([]domain.CandleIntegrityCheck, error) {
+	_, span := r.tracer.Start(ctx, "candle-integrity-repo.list-mismatched")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, interval, provider, checked_at, sampled, missing,
+		        mismatched, max_diff_pct, mismatches::text
+		 FROM candle_integrity_checks
+		 WHERE checked_at >= $1 AND mismatched > 0
+		 ORDER BY checked_at DESC
+		 LIMIT $2`,
+		since.UTC(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.CandleIntegrityCheck
+	for rows.Next() {
+		var (
+			c   domain.CandleIntegrityCheck
+			raw string
+		)
+		if err := rows.Scan(&c.Symbol, &c.Interval, &c.Provider, &c.CheckedAt, &c.Sampled, &c.Missing,
+			&c.Mismatched, &c.MaxDiffPct, &raw); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(raw), &c.Mismatches); err != nil {
+			return nil, err
+		}
+		c.CheckedAt = c.CheckedAt.UTC()
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// DeleteChecksBefore removes checks made before cutoff.
+func (r *CandleIntegrityRepository) DeleteChecksBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "candle-integrity-repo.delete-before")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM candle_integrity_checks WHERE checked_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -2,9 +2,10 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -19,14 +20,21 @@ type PgxPool interface {
 }
 
 type CandleRepository struct {
-	pool   PgxPool
-	tracer trace.Tracer
+	pool      PgxPool
+	tracer    trace.Tracer
+	timescale bool
 }
 
 func NewCandleRepository(pool PgxPool, tracer trace.Tracer) *CandleRepository {
 	return &CandleRepository{pool: pool, tracer: tracer}
 }
 
+// SetTimescale switches aggregation queries to TimescaleDB's time_bucket,
+// first and last functions. Call it once DetectTimescale has succeeded.
+func (r *CandleRepository) SetTimescale(enabled bool) {
+	r.timescale = enabled
+}
+
 func (r *CandleRepository) UpsertCandles(ctx context.Context, candles []*domain.Candle) error {
 	if len(candles) == 0 {
 		return nil
@@ -61,6 +69,80 @@ func (r *CandleRepository) UpsertCandles(ctx context.Context, candles []*domain.
 	return nil
 }
 
+// IngestCandles upserts externally supplied candles and reports how many
+// rows were inserted or changed. Rows identical to what is already stored
+// are left untouched, so a collector re-sending a window is a no-op.
+func (r *CandleRepository) IngestCandles(ctx context.Context, candles []*domain.Candle) (int, error) {
+	if len(candles) == 0 {
+		return 0, nil
+	}
+
+	_, span := r.tracer.Start(ctx, "candle-repo.ingest-candles")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, c := range candles {
+		batch.Queue(
+			`INSERT INTO candles (symbol, interval, open_time, open, high, low, close, volume)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			 ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+			     open = EXCLUDED.open,
+			     high = EXCLUDED.high,
+			     low = EXCLUDED.low,
+			     close = EXCLUDED.close,
+			     volume = EXCLUDED.volume
+			 WHERE (candles.open, candles.high, candles.low, candles.close, candles.volume)
+			       IS DISTINCT FROM (EXCLUDED.open, EXCLUDED.high, EXCLUDED.low, EXCLUDED.close, EXCLUDED.volume)`,
+			c.Symbol, c.Interval, c.OpenTime, c.Open, c.High, c.Low, c.Close, c.Volume,
+		)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	stored := 0
+	for range candles {
+		tag, err := br.Exec()
+		if err != nil {
+			return stored, err
+		}
+		stored += int(tag.RowsAffected())
+	}
+	return stored, nil
+}
+
+// HasSymbol reports whether any candles are stored for symbol.
+func (r *CandleRepository) HasSymbol(ctx context.Context, symbol string) (bool, error) {
+	_, span := r.tracer.Start(ctx, "candle-repo.has-symbol")
+	defer span.End()
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM candles WHERE symbol = $1)`, symbol).Scan(&exists)
+	return exists, err
+}
+
+// ListSymbols returns every symbol with stored candles, sorted.
+func (r *CandleRepository) ListSymbols(ctx context.Context) ([]string, error) {
+	_, span := r.tracer.Start(ctx, "candle-repo.list-symbols")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT symbol FROM candles ORDER BY symbol`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
 func (r *CandleRepository) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
 	_, span := r.tracer.Start(ctx, "candle-repo.get-candles")
 	defer span.End()
@@ -115,3 +197,62 @@ func (r *CandleRepository) GetCandlesInRange(ctx context.Context, symbol, interv
 	}
 	return candles, rows.Err()
 }
+
+// AggregateCandles rolls stored sourceInterval candles up into buckets of
+// targetInterval and returns the newest limit buckets, newest first. The
+// returned candles carry targetInterval as their interval.
+func (r *CandleRepository) AggregateCandles(ctx context.Context, symbol, sourceInterval, targetInterval string, limit int) ([]*domain.Candle, error) {
+	_, span := r.tracer.Start(ctx, "candle-repo.aggregate-candles")
+	defer span.End()
+
+	bucket := domain.IntervalDuration(targetInterval)
+	if bucket == 0 {
+		return nil, fmt.Errorf("unsupported target interval %q", targetInterval)
+	}
+	if domain.IntervalDuration(sourceInterval) == 0 {
+		return nil, fmt.Errorf("unsupported source interval %q", sourceInterval)
+	}
+
+	query := aggregateCandlesPostgresSQL
+	if r.timescale {
+		query = aggregateCandlesTimescaleSQL
+	}
+	width := fmt.Sprintf("%d seconds", int64(bucket.Seconds()))
+
+	rows, err := r.pool.Query(ctx, query, symbol, sourceInterval, width, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles []*domain.Candle
+	for rows.Next() {
+		c := &domain.Candle{Symbol: symbol, Interval: targetInterval}
+		if err := rows.Scan(&c.OpenTime, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume); err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
+const aggregateCandlesTimescaleSQL = `SELECT time_bucket($3::interval, open_time) AS bucket,
+	        first(open, open_time), MAX(high), MIN(low), last(close, open_time), SUM(volume)
+	 FROM candles
+	 WHERE symbol = $1 AND interval = $2
+	   AND open_time >= NOW() - $3::interval * $4
+	 GROUP BY bucket
+	 ORDER BY bucket DESC
+	 LIMIT $4`
+
+// aggregateCandlesPostgresSQL is the plain Postgres equivalent; date_bin
+// needs an origin, and the epoch keeps buckets aligned with time_bucket.
+const aggregateCandlesPostgresSQL = `SELECT date_bin($3::interval, open_time, TIMESTAMPTZ '1970-01-01') AS bucket,
+	        (array_agg(open ORDER BY open_time ASC))[1], MAX(high), MIN(low),
+	        (array_agg(close ORDER BY open_time DESC))[1], SUM(volume)
+	 FROM candles
+	 WHERE symbol = $1 AND interval = $2
+	   AND open_time >= NOW() - $3::interval * $4
+	 GROUP BY bucket
+	 ORDER BY bucket DESC
+	 LIMIT $4`
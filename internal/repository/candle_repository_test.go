@@ -3,10 +3,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -33,6 +34,40 @@ func TestUpsertCandlesBatchesStatements(t *testing.T) {
 	}
 }
 
+func TestIngestCandlesCountsChangedRows(t *testing.T) {
+	batchResults := &stubBatchResults{tag: pgconn.NewCommandTag("INSERT 0 1")}
+	pool := &stubPool{batchResults: batchResults}
+	repo := NewCandleRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	candles := []*domain.Candle{
+		{Symbol: "PEPE", Interval: "1h", OpenTime: time.Unix(0, 0)},
+		{Symbol: "PEPE", Interval: "1h", OpenTime: time.Unix(3600, 0)},
+	}
+	stored, err := repo.IngestCandles(context.Background(), candles)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored != 2 || batchResults.execCalls != 2 {
+		t.Fatalf("expected 2 stored rows from 2 statements, got stored=%d exec=%d", stored, batchResults.execCalls)
+	}
+	if sql := pool.queuedBatch.QueuedQueries[0].SQL; !strings.Contains(sql, "IS DISTINCT FROM") {
+		t.Fatalf("expected unchanged rows skipped, got %s", sql)
+	}
+}
+
+func TestListSymbols(t *testing.T) {
+	pool := &stubPool{rowsData: [][]any{{"BTC"}, {"PEPE"}}}
+	repo := NewCandleRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	symbols, err := repo.ListSymbols(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(symbols) != 2 || symbols[1] != "PEPE" {
+		t.Fatalf("unexpected symbols %v", symbols)
+	}
+}
+
 func TestGetCandlesReturnsRows(t *testing.T) {
 	rows := [][]any{{
 		"BTC", "1h", time.Unix(0, 0), 1.0, 2.0, 0.5, 1.5, 100.0,
@@ -66,10 +101,51 @@ func TestGetCandlesInRange(t *testing.T) {
 	}
 }
 
+func TestCandleRepositoryAggregateCandles(t *testing.T) {
+	bucket := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name      string
+		timescale bool
+		want      string
+	}{
+		{name: "postgres", want: "date_bin("},
+		{name: "timescale", timescale: true, want: "time_bucket("},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := &stubPool{rowsData: [][]any{{bucket, 100.0, 120.0, 90.0, 110.0, 42.0}}}
+			repo := NewCandleRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+			repo.SetTimescale(tc.timescale)
+
+			candles, err := repo.AggregateCandles(context.Background(), "BTC", "1h", "4h", 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(pool.lastSQL, tc.want) {
+				t.Fatalf("expected query using %s, got %s", tc.want, pool.lastSQL)
+			}
+			if len(candles) != 1 || candles[0].Interval != "4h" || candles[0].Symbol != "BTC" || candles[0].Close != 110 {
+				t.Fatalf("unexpected candles: %+v", candles[0])
+			}
+		})
+	}
+}
+
+func TestCandleRepositoryAggregateCandlesRejectsUnknownInterval(t *testing.T) {
+	pool := &stubPool{}
+	repo := NewCandleRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+	if _, err := repo.AggregateCandles(context.Background(), "BTC", "1h", "3w", 10); err == nil {
+		t.Fatal("expected error for unsupported interval")
+	}
+	if pool.lastSQL != "" {
+		t.Fatalf("expected no query, got %s", pool.lastSQL)
+	}
+}
+
 type stubPool struct {
 	batchResults pgx.BatchResults
 	queuedBatch  *pgx.Batch
 	rowsData     [][]any
+	lastSQL      string
 }
 
 func (s *stubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
@@ -85,6 +161,7 @@ func (s *stubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
 }
 
 func (s *stubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.lastSQL = sql
 	if s.rowsData == nil {
 		return &stubRows{}, nil
 	}
@@ -103,11 +180,12 @@ func (s *stubPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Ro
 
 type stubBatchResults struct {
 	execCalls int
+	tag       pgconn.CommandTag
 }
 
 func (s *stubBatchResults) Exec() (pgconn.CommandTag, error) {
 	s.execCalls++
-	return pgconn.CommandTag{}, nil
+	return s.tag, nil
 }
 
 func (s *stubBatchResults) Query() (pgx.Rows, error) { return &stubRows{}, nil }
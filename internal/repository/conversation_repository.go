@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -23,8 +23,8 @@ func (r *ConversationRepository) AppendMessage(ctx context.Context, chatID int64
 	defer span.End()
 
 	_, err := r.pool.Exec(ctx,
-		`INSERT INTO conversation_messages (chat_id, role, content) VALUES ($1, $2, $3)`,
-		chatID, role, content,
+		`INSERT INTO conversation_messages (tenant_id, chat_id, role, content) VALUES ($1, $2, $3, $4)`,
+		domain.TenantFromContext(ctx), chatID, role, content,
 	)
 	return err
 }
@@ -36,10 +36,10 @@ func (r *ConversationRepository) RecentMessages(ctx context.Context, chatID int6
 	rows, err := r.pool.Query(ctx,
 		`SELECT role, content, created_at
 		 FROM conversation_messages
-		 WHERE chat_id = $1
+		 WHERE tenant_id = $1 AND chat_id = $2
 		 ORDER BY created_at DESC
-		 LIMIT $2`,
-		chatID, limit,
+		 LIMIT $3`,
+		domain.TenantFromContext(ctx), chatID, limit,
 	)
 	if err != nil {
 		return nil, err
@@ -67,3 +67,43 @@ func (r *ConversationRepository) RecentMessages(ctx context.Context, chatID int6
 
 	return messages, nil
 }
+
+// DeleteConversation removes the chat's messages in the tenant bound to ctx
+// and returns how many were deleted.
+func (r *ConversationRepository) DeleteConversation(ctx context.Context, chatID int64) (int64, error) {
+	_, span := r.tracer.Start(ctx, "conversation-repo.delete-conversation")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM conversation_messages WHERE tenant_id = $1 AND chat_id = $2`,
+		domain.TenantFromContext(ctx), chatID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteInactiveConversations removes, across all tenants, every conversation
+// whose newest message is older than cutoff and returns how many messages were
+// deleted.
+func (r *ConversationRepository) DeleteInactiveConversations(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "conversation-repo.delete-inactive")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM conversation_messages m
+		 USING (
+		     SELECT tenant_id, chat_id
+		     FROM conversation_messages
+		     GROUP BY tenant_id, chat_id
+		     HAVING MAX(created_at) < $1
+		 ) idle
+		 WHERE m.tenant_id = idle.tenant_id AND m.chat_id = idle.chat_id`,
+		cutoff.UTC(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"bug-free-umbrella/pkg/domain"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"go.opentelemetry.io/otel/trace"
@@ -21,6 +24,28 @@ func TestConversationAppendMessageExecsInsert(t *testing.T) {
 	if pool.execCount != 1 {
 		t.Fatalf("expected 1 exec call, got %d", pool.execCount)
 	}
+	if len(pool.lastArgs) == 0 || pool.lastArgs[0] != domain.DefaultTenantID {
+		t.Fatalf("expected default tenant arg, got %v", pool.lastArgs)
+	}
+}
+
+func TestConversationScopesQueriesByTenant(t *testing.T) {
+	pool := &convStubPool{}
+	repo := NewConversationRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	if err := repo.AppendMessage(ctx, 123, "user", "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.lastArgs[0] != "acme" {
+		t.Fatalf("expected acme tenant on insert, got %v", pool.lastArgs)
+	}
+	if _, err := repo.RecentMessages(ctx, 123, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.lastArgs[0] != "acme" {
+		t.Fatalf("expected acme tenant on select, got %v", pool.lastArgs)
+	}
 }
 
 func TestConversationRecentMessagesReturnsChronological(t *testing.T) {
@@ -63,16 +88,57 @@ func TestConversationRecentMessagesEmptyResult(t *testing.T) {
 	}
 }
 
+func TestConversationDeleteConversationScopedByTenant(t *testing.T) {
+	pool := &convStubPool{execTag: pgconn.NewCommandTag("DELETE 4")}
+	repo := NewConversationRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	deleted, err := repo.DeleteConversation(domain.WithTenant(context.Background(), "acme"), 123)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 4 {
+		t.Fatalf("expected 4 deleted, got %d", deleted)
+	}
+	if pool.lastArgs[0] != "acme" || pool.lastArgs[1] != int64(123) {
+		t.Fatalf("unexpected args %v", pool.lastArgs)
+	}
+}
+
+func TestConversationDeleteInactiveConversationsUsesCutoff(t *testing.T) {
+	pool := &convStubPool{execTag: pgconn.NewCommandTag("DELETE 9")}
+	repo := NewConversationRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+	cutoff := time.Date(2026, 3, 1, 0, 0, 0, 0, time.FixedZone("x", 3600))
+
+	deleted, err := repo.DeleteInactiveConversations(context.Background(), cutoff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 9 {
+		t.Fatalf("expected 9 deleted, got %d", deleted)
+	}
+	if !strings.Contains(pool.lastSQL, "HAVING MAX(created_at) < $1") {
+		t.Fatalf("unexpected sql %q", pool.lastSQL)
+	}
+	if got := pool.lastArgs[0].(time.Time); !got.Equal(cutoff) || got.Location() != time.UTC {
+		t.Fatalf("expected UTC cutoff, got %v", got)
+	}
+}
+
 // --- stubs ---
 
 type convStubPool struct {
 	execCount int
+	execTag   pgconn.CommandTag
 	rowsData  [][]any
+	lastSQL   string
+	lastArgs  []any
 }
 
 func (s *convStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
 	s.execCount++
-	return pgconn.CommandTag{}, nil
+	s.lastSQL = sql
+	s.lastArgs = args
+	return s.execTag, nil
 }
 
 func (s *convStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
@@ -80,6 +146,7 @@ func (s *convStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchRes
 }
 
 func (s *convStubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.lastArgs = args
 	if s.rowsData == nil {
 		return &convStubRows{}, nil
 	}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DerivativesRepository stores funding and open interest snapshots in
+// derivatives_snapshots.
+type DerivativesRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewDerivativesRepository(pool PgxPool, tracer trace.Tracer) *DerivativesRepository {
+	return &DerivativesRepository{pool: pool, tracer: tracer}
+}
+
+func (r *DerivativesRepository) InsertSnapshots(ctx context.Context, snapshots []domain.DerivativesSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "derivatives-repo.insert")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, s := range snapshots {
+		var nextFunding *time.Time
+		if !s.NextFundingTime.IsZero() {
+			t := s.NextFundingTime.UTC()
+			nextFunding = &t
+		}
+		batch.Queue(
+			`INSERT INTO derivatives_snapshots (
+			     symbol, source, captured_at, mark_price, funding_rate,
+			     next_funding_time, open_interest, open_interest_usd
+			 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			s.Symbol, s.Source, s.CapturedAt.UTC(), s.MarkPrice, s.FundingRate,
+			nextFunding, s.OpenInterest, s.OpenInterestUSD,
+		)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range snapshots {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HourlyDerivativesFeatures averages symbol's snapshots per UTC hour in
+// [from, to), oldest hour first. Hours without snapshots are left out.
+func (r *DerivativesRepository) HourlyDerivativesFeatures(ctx context.Context, symbol string, from, to time.Time) ([]domain.DerivativesFeatures, error) {
+	_, span := r.tracer.Start(ctx, "derivatives-repo.hourly-features")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT date_trunc('hour', captured_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour,
+		        AVG(funding_rate), AVG(open_interest), COUNT(*)
+		 FROM derivatives_snapshots
+		 WHERE symbol = $1 AND captured_at >= $2 AND captured_at < $3
+		 GROUP BY hour
+		 ORDER BY hour ASC`,
+		symbol, from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.DerivativesFeatures
+	for rows.Next() {
+		var f domain.DerivativesFeatures
+		if err := rows.Scan(&f.Hour, &f.FundingRate, &f.OpenInterest, &f.Snapshots); err != nil {
+			return nil, err
+		}
+		f.Hour = f.Hour.UTC()
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSnapshotsBefore removes snapshots captured before cutoff.
+func (r *DerivativesRepository) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "derivatives-repo.delete-before")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM derivatives_snapshots WHERE captured_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
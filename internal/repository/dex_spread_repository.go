@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DexSpreadRepository stores DEX versus exchange price comparisons in
+// cex_dex_spreads.
+type DexSpreadRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewDexSpreadRepository(pool PgxPool, tracer trace.Tracer) *DexSpreadRepository {
+	return &DexSpreadRepository{pool: pool, tracer: tracer}
+}
+
+func (r *DexSpreadRepository) InsertSpreads(ctx context.Context, spreads []domain.CexDexSpread) error {
+	if len(spreads) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "dex-spread-repo.insert")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, s := range spreads {
+		batch.Queue(
+			`INSERT INTO cex_dex_spreads (
+			     symbol, venue, captured_at, dex_price_usd, cex_price_usd, spread_pct
+			 ) VALUES ($1, $2, $3, $4, $5, $6)`,
+			s.Symbol, s.Venue, s.CapturedAt.UTC(), s.DexPriceUSD, s.CexPriceUSD, s.SpreadPct,
+		)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range spreads {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSpreadsBefore removes comparisons captured before cutoff.
+func (r *DexSpreadRepository) DeleteSpreadsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "dex-spread-repo.delete-before")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM cex_dex_spreads WHERE captured_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
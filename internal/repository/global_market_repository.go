@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GlobalMarketRepository stores whole-market snapshots in
+// global_market_snapshots.
+type GlobalMarketRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewGlobalMarketRepository(pool PgxPool, tracer trace.Tracer) *GlobalMarketRepository {
+	return &GlobalMarketRepository{pool: pool, tracer: tracer}
+}
+
+func (r *GlobalMarketRepository) InsertSnapshot(ctx context.Context, s domain.GlobalMarketSnapshot) error {
+	_, span := r.tracer.Start(ctx, "global-market-repo.insert")
+	defer span.End()
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO global_market_snapshots (
+		     source, captured_at, total_market_cap_usd, total_volume_usd,
+		     btc_dominance_pct, eth_dominance_pct, market_cap_change_24h_pct
+		 ) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		s.Source, s.CapturedAt.UTC(), s.TotalMarketCapUSD, s.TotalVolumeUSD,
+		s.BTCDominancePct, s.ETHDominancePct, s.MarketCapChange24hPct,
+	)
+	return err
+}
+
+// LatestSnapshot returns the newest snapshot, or nil when there is none.
+func (r *GlobalMarketRepository) LatestSnapshot(ctx context.Context) (*domain.GlobalMarketSnapshot, error) {
+	_, span := r.tracer.Start(ctx, "global-market-repo.latest")
+	defer span.End()
+
+	var s domain.GlobalMarketSnapshot
+	err := r.pool.QueryRow(ctx,
+		`SELECT source, captured_at, total_market_cap_usd, total_volume_usd,
+		        btc_dominance_pct, eth_dominance_pct, market_cap_change_24h_pct
+		 FROM global_market_snapshots
+		 ORDER BY captured_at DESC
+		 LIMIT 1`,
+	).Scan(&s.Source, &s.CapturedAt, &s.TotalMarketCapUSD, &s.TotalVolumeUSD,
+		&s.BTCDominancePct, &s.ETHDominancePct, &s.MarketCapChange24hPct)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.CapturedAt = s.CapturedAt.UTC()
+	return &s, nil
+}
+
+// HourlyGlobalMarketFeatures averages snapshots per UTC hour in [from, to),
+// oldest hour first. Hours without snapshots are left out.
+func (r *GlobalMarketRepository) HourlyGlobalMarketFeatures(ctx context.Context, from, to time.Time) ([]domain.GlobalMarketFeatures, error) {
+	_, span := r.tracer.Start(ctx, "global-market-repo.hourly-features")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT date_trunc('hour', captured_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour,
+		        AVG(btc_dominance_pct), AVG(total_market_cap_usd), COUNT(*)
+		 FROM global_market_snapshots
+		 WHERE captured_at >= $1 AND captured_at < $2
+		 GROUP BY hour
+		 ORDER BY hour ASC`,
+		from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.GlobalMarketFeatures
+	for rows.Next() {
+		var f domain.GlobalMarketFeatures
+		if err := rows.Scan(&f.Hour, &f.BTCDominancePct, &f.TotalMarketCapUSD, &f.Snapshots); err != nil {
+			return nil, err
+		}
+		f.Hour = f.Hour.UTC()
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSnapshotsBefore removes snapshots captured before cutoff.
+func (r *GlobalMarketRepository) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "global-market-repo.delete-before")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM global_market_snapshots WHERE captured_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
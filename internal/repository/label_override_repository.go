@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LabelOverrideRepository stores operator corrections of resolved prediction
+// outcomes in ml_label_overrides and applies them to ml_predictions.
+type LabelOverrideRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewLabelOverrideRepository(pool PgxPool, tracer trace.Tracer) *LabelOverrideRepository {
+	return &LabelOverrideRepository{pool: pool, tracer: tracer}
+}
+
+// ListLabelReviews returns resolved predictions, newest candle first, each
+// with its candle's override if one exists.
+func (r *LabelOverrideRepository) ListLabelReviews(ctx context.Context, filter domain.LabelReviewFilter) ([]domain.LabelReview, error) {
+	_, span := r.tracer.Start(ctx, "label-override-repo.list-reviews")
+	defer span.End()
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT p.id, p.symbol, p.interval, p.open_time, p.target_time,
+		        p.model_key, p.model_version, p.prob_up, p.direction,
+		        p.resolved_at, COALESCE(p.actual_up, FALSE), COALESCE(p.is_correct, FALSE), p.realized_return,
+		        o.actual_up, o.original_actual_up, o.exclude_from_training, o.reason, o.actor, o.updated_at
+		 FROM ml_predictions p
+		 LEFT JOIN ml_label_overrides o
+		   ON o.symbol = p.symbol AND o.interval = p.interval AND o.open_time = p.open_time
+		 WHERE p.resolved_at IS NOT NULL
+		   AND ($1 = '' OR p.symbol = $1)
+		   AND ($2 = '' OR p.interval = $2)
+		   AND ($3 = '' OR p.model_key = $3)
+		   AND (NOT $4 OR o.symbol IS NOT NULL)
+		 ORDER BY p.open_time DESC, p.model_key ASC
+		 LIMIT $5`,
+		filter.Symbol, filter.Interval, filter.ModelKey, filter.OverriddenOnly, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.LabelReview
+	for rows.Next() {
+		var v domain.LabelReview
+		var direction string
+		var (
+			overrideUp *bool
+			originalUp *bool
+			exclude    *bool
+			reason     *string
+			actor      *string
+			updatedAt  *time.Time
+		)
+		if err := rows.Scan(
+			&v.ID, &v.Symbol, &v.Interval, &v.OpenTime, &v.TargetTime,
+			&v.ModelKey, &v.ModelVersion, &v.ProbUp, &direction,
+			&v.ResolvedAt, &v.ActualUp, &v.IsCorrect, &v.RealizedReturn,
+			&overrideUp, &originalUp, &exclude, &reason, &actor, &updatedAt,
+		); err != nil {
+			return nil, err
+		}
+		v.Direction = domain.SignalDirection(direction)
+		if overrideUp != nil {
+			v.Override = &domain.LabelOverride{
+				Symbol:              v.Symbol,
+				Interval:            v.Interval,
+				OpenTime:            v.OpenTime,
+				ActualUp:            *overrideUp,
+				OriginalActualUp:    originalUp,
+				ExcludeFromTraining: exclude != nil && *exclude,
+			}
+			if reason != nil {
+				v.Override.Reason = *reason
+			}
+			if actor != nil {
+				v.Override.Actor = *actor
+			}
+			if updatedAt != nil {
+				v.Override.UpdatedAt = *updatedAt
+			}
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// GetLabelOverride returns the override for one candle, or nil when there is
+// none.
+func (r *LabelOverrideRepository) GetLabelOverride(ctx context.Context, symbol, interval string, openTime time.Time) (*domain.LabelOverride, error) {
+	_, span := r.tracer.Start(ctx, "label-override-repo.get")
+	defer span.End()
+
+	o, err := scanLabelOverride(r.pool.QueryRow(ctx,
+		`SELECT symbol, interval, open_time, actual_up, original_actual_up,
+		        exclude_from_training, reason, actor, updated_at
+		 FROM ml_label_overrides
+		 WHERE symbol = $1 AND interval = $2 AND open_time = $3`,
+		symbol, interval, openTime,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// OverrideLabel saves o and rewrites actual_up and is_correct on every
+// resolved prediction for its candle, in one statement. The label computed by
+// the resolver is kept from the first override. It returns the saved
+// override and the number of predictions changed, or pgx.ErrNoRows when the
+// candle has no resolved prediction.
+func (r *LabelOverrideRepository) OverrideLabel(ctx context.Context, o domain.LabelOverride) (domain.LabelOverride, int64, error) {
+	_, span := r.tracer.Start(ctx, "label-override-repo.override")
+	defer span.End()
+
+	var updated int64
+	saved, err := scanLabelOverride(r.pool.QueryRow(ctx,
+		`WITH original AS (
+		     SELECT actual_up
+		     FROM ml_predictions
+		     WHERE symbol = $1 AND interval = $2 AND open_time = $3 AND resolved_at IS NOT NULL
+		     ORDER BY id ASC
+		     LIMIT 1
+		 ), saved AS (
+		     INSERT INTO ml_label_overrides (
+		         symbol, interval, open_time, actual_up, original_actual_up,
+		         exclude_from_training, reason, actor, updated_at
+		     )
+		     SELECT $1, $2, $3, $4, original.actual_up, $5, $6, $7, NOW()
+		     FROM original
+		     ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+		         actual_up = EXCLUDED.actual_up,
+		         exclude_from_training = EXCLUDED.exclude_from_training,
+		         reason = EXCLUDED.reason,
+		         actor = EXCLUDED.actor,
+		         updated_at = EXCLUDED.updated_at
+		     RETURNING symbol, interval, open_time, actual_up, original_actual_up,
+		               exclude_from_training, reason, actor, updated_at
+		 ), changed AS (
+		     UPDATE ml_predictions
+		     SET actual_up = $4,
+		         is_correct = CASE direction
+		             WHEN 'long' THEN $4
+		             WHEN 'short' THEN NOT $4
+		             ELSE (prob_up >= 0.5) = $4
+		         END
+		     WHERE symbol = $1 AND interval = $2 AND open_time = $3 AND resolved_at IS NOT NULL
+		       AND EXISTS (SELECT 1 FROM saved)
+		     RETURNING 1
+		 )
+		 SELECT saved.*, (SELECT COUNT(*) FROM changed)
+		 FROM saved`,
+		o.Symbol, o.Interval, o.OpenTime, o.ActualUp, o.ExcludeFromTraining, o.Reason, o.Actor,
+	), &updated)
+	if err != nil {
+		return domain.LabelOverride{}, 0, err
+	}
+	return saved, updated, nil
+}
+
+// ListExcludedLabels returns the overrides flagged to keep their candle out
+// of training, for candles of interval opened in [from, to].
+func (r *LabelOverrideRepository) ListExcludedLabels(ctx context.Context, interval string, from, to time.Time) ([]domain.LabelOverride, error) {
+	_, span := r.tracer.Start(ctx, "label-override-repo.list-excluded")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, interval, open_time, actual_up, original_actual_up,
+		        exclude_from_training, reason, actor, updated_at
+		 FROM ml_label_overrides
+		 WHERE exclude_from_training AND interval = $1 AND open_time BETWEEN $2 AND $3
+		 ORDER BY open_time ASC`,
+		interval, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.LabelOverride
+	for rows.Next() {
+		o, err := scanLabelOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func scanLabelOverride(row pgx.Row, extra ...any) (domain.LabelOverride, error) {
+	var o domain.LabelOverride
+	dest := append([]any{
+		&o.Symbol, &o.Interval, &o.OpenTime, &o.ActualUp, &o.OriginalActualUp,
+		&o.ExcludeFromTraining, &o.Reason, &o.Actor, &o.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return domain.LabelOverride{}, err
+	}
+	o.OpenTime = o.OpenTime.UTC()
+	o.UpdatedAt = o.UpdatedAt.UTC()
+	return o, nil
+}
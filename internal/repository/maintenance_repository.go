@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaintenanceRepository stores the maintenance switch in its single-row
+// table, so every process sharing the database sees the same state.
+type MaintenanceRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewMaintenanceRepository(pool PgxPool, tracer trace.Tracer) *MaintenanceRepository {
+	return &MaintenanceRepository{pool: pool, tracer: tracer}
+}
+
+// GetMaintenance returns the stored state, or the zero state if it was
+// never set.
+func (r *MaintenanceRepository) GetMaintenance(ctx context.Context) (domain.MaintenanceState, error) {
+	_, span := r.tracer.Start(ctx, "maintenance-repo.get")
+	defer span.End()
+
+	var state domain.MaintenanceState
+	err := r.pool.QueryRow(ctx,
+		`SELECT enabled, reason, actor, updated_at FROM maintenance_mode WHERE id = 1`,
+	).Scan(&state.Enabled, &state.Reason, &state.Actor, &state.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.MaintenanceState{}, nil
+	}
+	return state, err
+}
+
+// SetMaintenance replaces the stored state and returns it as written.
+func (r *MaintenanceRepository) SetMaintenance(ctx context.Context, state domain.MaintenanceState) (domain.MaintenanceState, error) {
+	_, span := r.tracer.Start(ctx, "maintenance-repo.set")
+	defer span.End()
+
+	var out domain.MaintenanceState
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO maintenance_mode (id, enabled, reason, actor, updated_at)
+		 VALUES (1, $1, $2, $3, NOW())
+		 ON CONFLICT (id) DO UPDATE SET
+		     enabled = EXCLUDED.enabled,
+		     reason = EXCLUDED.reason,
+		     actor = EXCLUDED.actor,
+		     updated_at = EXCLUDED.updated_at
+		 RETURNING enabled, reason, actor, updated_at`,
+		state.Enabled, state.Reason, state.Actor,
+	).Scan(&out.Enabled, &out.Reason, &out.Actor, &out.UpdatedAt)
+	return out, err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMaintenanceGetDefaultsToOff(t *testing.T) {
+	repo := NewMaintenanceRepository(&sshStubPool{}, trace.NewNoopTracerProvider().Tracer("test"))
+
+	state, err := repo.GetMaintenance(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != (domain.MaintenanceState{}) {
+		t.Fatalf("expected zero state without a row, got %+v", state)
+	}
+}
+
+func TestMaintenanceSetReturnsStoredRow(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pool := &sshStubPool{queryRowData: []any{true, "provider incident", "api:default", now}}
+	repo := NewMaintenanceRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	state, err := repo.SetMaintenance(context.Background(), domain.MaintenanceState{Enabled: true, Reason: "provider incident", Actor: "api:default"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.Enabled || state.Reason != "provider incident" || !state.UpdatedAt.Equal(now) {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
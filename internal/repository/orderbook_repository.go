@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OrderBookRepository stores order book snapshots in orderbook_snapshots.
+type OrderBookRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewOrderBookRepository(pool PgxPool, tracer trace.Tracer) *OrderBookRepository {
+	return &OrderBookRepository{pool: pool, tracer: tracer}
+}
+
+func (r *OrderBookRepository) InsertSnapshots(ctx context.Context, snapshots []domain.OrderBookSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "orderbook-repo.insert")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, s := range snapshots {
+		batch.Queue(
+			`INSERT INTO orderbook_snapshots (
+			     symbol, source, captured_at, best_bid, best_ask, spread_bps,
+			     depth_band_pct, bid_depth_usd, ask_depth_usd, imbalance
+			 ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			s.Symbol, s.Source, s.CapturedAt.UTC(), s.BestBid, s.BestAsk, s.SpreadBps,
+			s.DepthBandPct, s.BidDepthUSD, s.AskDepthUSD, s.Imbalance,
+		)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range snapshots {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListSnapshots returns up to limit of symbol's snapshots, newest first.
+func (r *OrderBookRepository) ListSnapshots(ctx context.Context, symbol string, limit int) ([]domain.OrderBookSnapshot, error) {
+	_, span := r.tracer.Start(ctx, "orderbook-repo.list")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, source, captured_at, best_bid, best_ask, spread_bps,
+		        depth_band_pct, bid_depth_usd, ask_depth_usd, imbalance
+		 FROM orderbook_snapshots
+		 WHERE symbol = $1
+		 ORDER BY captured_at DESC
+		 LIMIT $2`,
+		symbol, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.OrderBookSnapshot
+	for rows.Next() {
+		var s domain.OrderBookSnapshot
+		if err := rows.Scan(&s.Symbol, &s.Source, &s.CapturedAt, &s.BestBid, &s.BestAsk, &s.SpreadBps,
+			&s.DepthBandPct, &s.BidDepthUSD, &s.AskDepthUSD, &s.Imbalance); err != nil {
+			return nil, err
+		}
+		s.CapturedAt = s.CapturedAt.UTC()
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// HourlyOrderBookFeatures averages symbol's snapshots per UTC hour in
+// [from, to), oldest hour first. Hours without snapshots are left out.
+func (r *OrderBookRepository) HourlyOrderBookFeatures(ctx context.Context, symbol string, from, to time.Time) ([]domain.OrderBookFeatures, error) {
+	_, span := r.tracer.Start(ctx, "orderbook-repo.hourly-features")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT date_trunc('hour', captured_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS hour,
+		        AVG(imbalance), AVG(spread_bps), COUNT(*)
+		 FROM orderbook_snapshots
+		 WHERE symbol = $1 AND captured_at >= $2 AND captured_at < $3
+		 GROUP BY hour
+		 ORDER BY hour ASC`,
+		symbol, from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.OrderBookFeatures
+	for rows.Next() {
+		var f domain.OrderBookFeatures
+		if err := rows.Scan(&f.Hour, &f.Imbalance, &f.SpreadBps, &f.Snapshots); err != nil {
+			return nil, err
+		}
+		f.Hour = f.Hour.UTC()
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSnapshotsBefore removes snapshots captured before cutoff.
+func (r *OrderBookRepository) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "orderbook-repo.delete-before")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM orderbook_snapshots WHERE captured_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PriceDiscrepancyRepository stores price quorum outliers in
+// price_discrepancies.
+type PriceDiscrepancyRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewPriceDiscrepancyRepository(pool PgxPool, tracer trace.Tracer) *PriceDiscrepancyRepository {
+	return &PriceDiscrepancyRepository{pool: pool, tracer: tracer}
+}
+
+func (r *PriceDiscrepancyRepository) InsertDiscrepancies(ctx context.Context, discrepancies []domain.PriceDiscrepancy) error {
+	if len(discrepancies) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "price-discrepancy-repo.insert")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, d := range discrepancies {
+		quotes := d.Quotes
+		if quotes == nil {
+			quotes = map[string]float64{}
+		}
+		raw, err := json.Marshal(quotes)
+		if err != nil {
+			return err
+		}
+		batch.Queue(
+			`INSERT INTO price_discrepancies (
+			     symbol, provider, price_usd, quorum_usd, deviation_pct,
+			     quotes, rejected, observed_at
+			 ) VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8)`,
+			d.Symbol, d.Provider, d.PriceUSD, d.QuorumUSD, d.DeviationPct,
+			string(raw), d.Rejected, d.ObservedAt.UTC(),
+		)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	for range discrepancies {
+		if _, err := results.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDiscrepancies returns up to limit discrepancies observed since the
+// given time, newest first. An empty symbol matches every symbol.
+func (r *PriceDiscrepancyRepository) ListDiscrepancies(ctx context.Context, since time.Time, symbol string, limit int) ([]domain.PriceDiscrepancy, error) {
+	_, span := r.tracer.Start(ctx, "price-discrepancy-repo.list")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT symbol, provider, price_usd, quorum_usd, deviation_pct,
+		        quotes::text, rejected, observed_at
+		 FROM price_discrepancies
+		 WHERE observed_at >= $1 AND ($2 = '' OR symbol = $2)
+		 ORDER BY observed_at DESC
+		 LIMIT $3`,
+		since.UTC(), symbol, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.PriceDiscrepancy
+	for rows.Next() {
+		var (
+			d   domain.PriceDiscrepancy
+			raw string
+		)
+		if err := rows.Scan(&d.Symbol, &d.Provider, &d.PriceUSD, &d.QuorumUSD, &d.DeviationPct,
+			&raw, &d.Rejected, &d.ObservedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(raw), &d.Quotes); err != nil {
+			return nil, err
+		}
+		d.ObservedAt = d.ObservedAt.UTC()
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// DeleteDiscrepanciesBefore removes discrepancies observed before cutoff.
+func (r *PriceDiscrepancyRepository) DeleteDiscrepanciesBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	_, span := r.tracer.Start(ctx, "price-discrepancy-repo.delete-before")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM price_discrepancies WHERE observed_at < $1`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
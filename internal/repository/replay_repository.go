@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ReplayRepository stores replayed signal runs next to, not in, the live
+// signals table.
+type ReplayRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewReplayRepository(pool PgxPool, tracer trace.Tracer) *ReplayRepository {
+	return &ReplayRepository{pool: pool, tracer: tracer}
+}
+
+// CreateRun registers a replay run. Re-using an ID replaces the earlier
+// run and its signals.
+func (r *ReplayRepository) CreateRun(ctx context.Context, run domain.ReplayRun) error {
+	_, span := r.tracer.Start(ctx, "replay-repo.create-run")
+	defer span.End()
+
+	if _, err := r.pool.Exec(ctx, `DELETE FROM signal_replay_runs WHERE id = $1`, run.ID); err != nil {
+		return err
+	}
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO signal_replay_runs (id, from_time, to_time, symbols, intervals, notes)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		run.ID, run.From.UTC(), run.To.UTC(), run.Symbols, run.Intervals, run.Notes,
+	)
+	return err
+}
+
+func (r *ReplayRepository) InsertSignals(ctx context.Context, runID string, signals []domain.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+
+	_, span := r.tracer.Start(ctx, "replay-repo.insert-signals")
+	defer span.End()
+
+	batch := &pgx.Batch{}
+	for _, s := range signals {
+		batch.Queue(
+			`INSERT INTO signal_replays (run_id, symbol, interval, indicator, direction, risk, timestamp, details, version)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 ON CONFLICT (run_id, symbol, interval, indicator, timestamp, direction) DO UPDATE SET
+			     risk = EXCLUDED.risk,
+			     details = EXCLUDED.details,
+			     version = EXCLUDED.version`,
+			runID,
+			s.Symbol,
+			s.Interval,
+			s.Indicator,
+			string(s.Direction),
+			int16(s.Risk),
+			s.Timestamp.UTC(),
+			s.Details,
+			s.Version,
+		)
+	}
+
+	br := r.pool.SendBatch(ctx, batch)
+	defer br.Close()
+
+	for range signals {
+		if _, err := br.Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinishRun records the number of stored signals and marks the run complete.
+func (r *ReplayRepository) FinishRun(ctx context.Context, runID string) (int64, error) {
+	_, span := r.tracer.Start(ctx, "replay-repo.finish-run")
+	defer span.End()
+
+	var count int64
+	err := r.pool.QueryRow(ctx,
+		`UPDATE signal_replay_runs
+		 SET signal_count = (SELECT COUNT(*) FROM signal_replays WHERE run_id = $1),
+		     finished_at = NOW()
+		 WHERE id = $1
+		 RETURNING signal_count`,
+		runID,
+	).Scan(&count)
+	return count, err
+}
+
+// CompareWithLive matches a run's signals against live signals of the given
+// indicators for the run's symbols, intervals and time range. Signals match
+// when symbol, interval, indicator, timestamp and direction are equal.
+func (r *ReplayRepository) CompareWithLive(ctx context.Context, runID string, indicators []string) ([]domain.ReplayComparison, error) {
+	_, span := r.tracer.Start(ctx, "replay-repo.compare-with-live")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`WITH run AS (
+		     SELECT from_time, to_time, symbols, intervals FROM signal_replay_runs WHERE id = $1
+		 ), replayed AS (
+		     SELECT symbol, interval, indicator, direction, timestamp
+		     FROM signal_replays
+		     WHERE run_id = $1
+		 ), live AS (
+		     SELECT s.symbol, s.interval, s.indicator, s.direction, s.timestamp
+		     FROM signals s, run
+		     WHERE s.timestamp >= run.from_time AND s.timestamp <= run.to_time
+		       AND s.symbol = ANY(run.symbols)
+		       AND s.interval = ANY(run.intervals)
+		       AND s.indicator = ANY($2)
+		 )
+		 SELECT COALESCE(r.indicator, l.indicator) AS indicator,
+		        COUNT(*) FILTER (WHERE r.indicator IS NOT NULL AND l.indicator IS NOT NULL),
+		        COUNT(*) FILTER (WHERE l.indicator IS NULL),
+		        COUNT(*) FILTER (WHERE r.indicator IS NULL)
+		 FROM replayed r
+		 FULL OUTER JOIN live l
+		   ON r.symbol = l.symbol AND r.interval = l.interval AND r.indicator = l.indicator
+		  AND r.timestamp = l.timestamp AND r.direction = l.direction
+		 GROUP BY 1
+		 ORDER BY 1`,
+		runID, indicators,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ReplayComparison
+	for rows.Next() {
+		var c domain.ReplayComparison
+		if err := rows.Scan(&c.Indicator, &c.Matched, &c.ReplayOnly, &c.LiveOnly); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestReplayInsertSignalsBatchesUnderRunID(t *testing.T) {
+	batchResults := &stubBatchResults{}
+	pool := &stubPool{batchResults: batchResults}
+	repo := NewReplayRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	signals := []domain.Signal{
+		{Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong, Timestamp: time.Unix(0, 0)},
+		{Symbol: "ETH", Interval: "4h", Indicator: domain.IndicatorMACD, Direction: domain.DirectionShort, Timestamp: time.Unix(3600, 0)},
+	}
+	if err := repo.InsertSignals(context.Background(), "run-1", signals); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.queuedBatch == nil || pool.queuedBatch.Len() != 2 || batchResults.execCalls != 2 {
+		t.Fatalf("expected 2 queued inserts, got %+v", pool.queuedBatch)
+	}
+	first := pool.queuedBatch.QueuedQueries[0]
+	if !strings.Contains(first.SQL, "signal_replays") || first.Arguments[0] != "run-1" {
+		t.Fatalf("expected replay insert under run id, got %s %v", first.SQL, first.Arguments)
+	}
+}
+
+func TestReplayCreateRunReplacesExisting(t *testing.T) {
+	pool := &replayStubPool{}
+	repo := NewReplayRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	err := repo.CreateRun(context.Background(), domain.ReplayRun{
+		ID: "run-1", From: time.Unix(0, 0), To: time.Unix(3600, 0),
+		Symbols: []string{"BTC"}, Intervals: []string{"1h"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pool.execSQL) != 2 || !strings.HasPrefix(pool.execSQL[0], "DELETE FROM signal_replay_runs") {
+		t.Fatalf("expected delete then insert, got %v", pool.execSQL)
+	}
+}
+
+func TestReplayCompareWithLive(t *testing.T) {
+	pool := &replayStubPool{rowsData: [][]any{
+		{"macd", int64(3), int64(1), int64(0)},
+		{"rsi", int64(5), int64(0), int64(2)},
+	}}
+	repo := NewReplayRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	got, err := repo.CompareWithLive(context.Background(), "run-1", []string{"rsi", "macd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[1].Indicator != "rsi" || got[1].Matched != 5 || got[1].LiveOnly != 2 || got[0].ReplayOnly != 1 {
+		t.Fatalf("unexpected comparison: %+v", got)
+	}
+	if !strings.Contains(pool.lastSQL, "FULL OUTER JOIN") || pool.lastArgs[0] != "run-1" {
+		t.Fatalf("unexpected query: %s %v", pool.lastSQL, pool.lastArgs)
+	}
+}
+
+type replayStubPool struct {
+	rowsData [][]any
+	execSQL  []string
+	lastSQL  string
+	lastArgs []any
+}
+
+func (s *replayStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	s.execSQL = append(s.execSQL, strings.TrimSpace(sql))
+	return pgconn.CommandTag{}, nil
+}
+
+func (s *replayStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &stubBatchResults{}
+}
+
+func (s *replayStubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return &sshStubRows{data: s.rowsData}, nil
+}
+
+func (s *replayStubPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	s.lastSQL = sql
+	s.lastArgs = args
+	return &sshStubRow{}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RiskOverrideRepository reads the signal risk overrides operators keep in
+// the signal_risk_overrides table.
+type RiskOverrideRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewRiskOverrideRepository(pool PgxPool, tracer trace.Tracer) *RiskOverrideRepository {
+	return &RiskOverrideRepository{pool: pool, tracer: tracer}
+}
+
+// ListRiskOverrides returns every stored override. An empty interval applies
+// to every interval.
+func (r *RiskOverrideRepository) ListRiskOverrides(ctx context.Context) ([]domain.RiskOverride, error) {
+	_, span := r.tracer.Start(ctx, "risk-override-repo.list")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT indicator, interval, risk
+		 FROM signal_risk_overrides
+		 ORDER BY indicator ASC, interval ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.RiskOverride
+	for rows.Next() {
+		var o domain.RiskOverride
+		var risk int
+		if err := rows.Scan(&o.Indicator, &o.Interval, &risk); err != nil {
+			return nil, err
+		}
+		o.Risk = domain.RiskLevel(risk)
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
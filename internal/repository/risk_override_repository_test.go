@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRiskOverrideListReturnsRows(t *testing.T) {
+	pool := &sshStubPool{rowsData: [][]any{
+		{"macd", "", 4},
+		{"rsi", "5m", 3},
+	}}
+	repo := NewRiskOverrideRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	got, err := repo.ListRiskOverrides(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []domain.RiskOverride{
+		{Indicator: "macd", Risk: domain.RiskLevel(4)},
+		{Indicator: "rsi", Interval: "5m", Risk: domain.RiskLevel(3)},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/trace"
@@ -166,11 +166,41 @@ LIMIT $2
 	return out, rows.Err()
 }
 
-func (r *SignalImageRepository) DeleteExpiredSignalImages(ctx context.Context) (int64, error) {
+// DeleteExpiredSignalImages deletes up to limit expired images, oldest
+// expiry first, so cleanup never holds one long lock on a large table. A
+// non-positive limit deletes every expired image.
+func (r *SignalImageRepository) DeleteExpiredSignalImages(ctx context.Context, limit int) (int64, error) {
 	_, span := r.tracer.Start(ctx, "signal-image-repo.delete-expired")
 	defer span.End()
 
-	tag, err := r.pool.Exec(ctx, `DELETE FROM signal_images WHERE expires_at <= NOW()`)
+	if limit <= 0 {
+		tag, err := r.pool.Exec(ctx, `DELETE FROM signal_images WHERE expires_at <= NOW()`)
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	}
+	tag, err := r.pool.Exec(ctx, `
+DELETE FROM signal_images
+WHERE id IN (
+    SELECT id FROM signal_images
+    WHERE expires_at <= NOW()
+    ORDER BY expires_at ASC
+    LIMIT $1
+)
+`, limit)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteAllSignalImages deletes every stored image, ready or failed.
+func (r *SignalImageRepository) DeleteAllSignalImages(ctx context.Context) (int64, error) {
+	_, span := r.tracer.Start(ctx, "signal-image-repo.delete-all")
+	defer span.End()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM signal_images`)
 	if err != nil {
 		return 0, err
 	}
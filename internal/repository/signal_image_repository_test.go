@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -66,23 +66,35 @@ func TestSignalImageRepositoryDeleteExpired(t *testing.T) {
 	pool := &imageRepoStubPool{execRowsAffected: 3}
 	repo := NewSignalImageRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
 
-	deleted, err := repo.DeleteExpiredSignalImages(context.Background())
+	deleted, err := repo.DeleteExpiredSignalImages(context.Background(), 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if deleted != 3 {
 		t.Fatalf("expected 3 deleted rows, got %d", deleted)
 	}
+	if len(pool.execArgs) != 0 {
+		t.Fatalf("expected an unbounded delete, got args %v", pool.execArgs)
+	}
+
+	if _, err := repo.DeleteExpiredSignalImages(context.Background(), 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pool.execArgs) != 1 || pool.execArgs[0] != 500 {
+		t.Fatalf("expected the batch size as the only arg, got %v", pool.execArgs)
+	}
 }
 
 type imageRepoStubPool struct {
 	execRowsAffected int64
+	execArgs         []any
 	rowsData         [][]any
 	queryRowValues   []any
 	queryRowErr      error
 }
 
 func (s *imageRepoStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	s.execArgs = args
 	return pgconn.NewCommandTag(fmt.Sprintf("DELETE %d", s.execRowsAffected)), nil
 }
 
@@ -6,7 +6,7 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/trace"
@@ -32,11 +32,12 @@ func (r *SignalRepository) InsertSignals(ctx context.Context, signals []domain.S
 	batch := &pgx.Batch{}
 	for _, s := range signals {
 		batch.Queue(
-			`INSERT INTO signals (symbol, interval, indicator, direction, risk, timestamp, details)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7)
+			`INSERT INTO signals (symbol, interval, indicator, direction, risk, timestamp, details, version)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 			 ON CONFLICT (symbol, interval, indicator, timestamp, direction) DO UPDATE SET
 			     risk = EXCLUDED.risk,
-			     details = EXCLUDED.details
+			     details = EXCLUDED.details,
+			     version = EXCLUDED.version
 			 RETURNING id`,
 			s.Symbol,
 			s.Interval,
@@ -45,6 +46,7 @@ func (r *SignalRepository) InsertSignals(ctx context.Context, signals []domain.S
 			int16(s.Risk),
 			s.Timestamp.UTC(),
 			s.Details,
+			s.Version,
 		)
 	}
 
@@ -70,7 +72,7 @@ func (r *SignalRepository) ListSignals(ctx context.Context, filter domain.Signal
 
 	args := make([]any, 0, 4)
 	var sb strings.Builder
-	sb.WriteString(`SELECT s.id, s.symbol, s.interval, s.indicator, s.direction, s.risk, s.timestamp, s.details,
+	sb.WriteString(`SELECT s.id, s.symbol, s.interval, s.indicator, s.direction, s.risk, s.timestamp, s.details, s.version,
                COALESCE(si.id, 0), COALESCE(si.mime_type, ''), COALESCE(si.width, 0), COALESCE(si.height, 0),
                COALESCE(si.expires_at, to_timestamp(0))
 		FROM signals s
@@ -92,6 +94,14 @@ func (r *SignalRepository) ListSignals(ctx context.Context, filter domain.Signal
 		args = append(args, strings.ToLower(filter.Indicator))
 		sb.WriteString(fmt.Sprintf(" AND s.indicator = $%d", len(args)))
 	}
+	if filter.Version != "" {
+		args = append(args, filter.Version)
+		sb.WriteString(fmt.Sprintf(" AND s.version = $%d", len(args)))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until.UTC())
+		sb.WriteString(fmt.Sprintf(" AND s.timestamp <= $%d", len(args)))
+	}
 
 	limit := filter.Limit
 	if limit <= 0 {
@@ -130,6 +140,7 @@ func (r *SignalRepository) ListSignals(ctx context.Context, filter domain.Signal
 			&risk,
 			&ts,
 			&s.Details,
+			&s.Version,
 			&imageID,
 			&mimeType,
 			&width,
@@ -155,3 +166,53 @@ func (r *SignalRepository) ListSignals(ctx context.Context, filter domain.Signal
 
 	return signals, rows.Err()
 }
+
+// WinRatesByVersion scores long and short signals against the close horizon
+// candles after the signal candle and groups the outcome by indicator and
+// version. A signal wins when that close moved in its direction. Signals
+// without enough later candles are left out. An empty indicator covers all.
+func (r *SignalRepository) WinRatesByVersion(ctx context.Context, indicator string, horizon int) ([]domain.SignalWinRate, error) {
+	_, span := r.tracer.Start(ctx, "signal-repo.win-rates-by-version")
+	defer span.End()
+
+	if horizon <= 0 {
+		horizon = 1
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT s.indicator, s.version, COUNT(*),
+		        COUNT(*) FILTER (WHERE (s.direction = 'long' AND f.close > c.close)
+		                            OR (s.direction = 'short' AND f.close < c.close))
+		 FROM signals s
+		 JOIN candles c
+		   ON c.symbol = s.symbol AND c.interval = s.interval AND c.open_time = s.timestamp
+		 JOIN LATERAL (
+		     SELECT close FROM candles
+		     WHERE symbol = s.symbol AND interval = s.interval AND open_time > s.timestamp
+		     ORDER BY open_time
+		     OFFSET $2 - 1 LIMIT 1
+		 ) f ON TRUE
+		 WHERE s.direction IN ('long', 'short')
+		   AND ($1 = '' OR s.indicator = $1)
+		 GROUP BY s.indicator, s.version
+		 ORDER BY s.indicator, s.version`,
+		strings.ToLower(indicator), horizon,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.SignalWinRate
+	for rows.Next() {
+		var w domain.SignalWinRate
+		if err := rows.Scan(&w.Indicator, &w.Version, &w.Total, &w.Wins); err != nil {
+			return nil, err
+		}
+		if w.Total > 0 {
+			w.WinRate = float64(w.Wins) / float64(w.Total)
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/loadtest"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// listSignalsBudget covers scanning and mapping a full 200-row page; the
+// query itself is excluded because the pool is stubbed.
+const listSignalsBudget = 500 * time.Microsecond
+
+func BenchmarkListSignals(b *testing.B) {
+	now := time.Now().UTC().Truncate(time.Second)
+	rows := make([][]any, 0, 200)
+	for i := 0; i < 200; i++ {
+		rows = append(rows, []any{
+			int64(i + 1), "BTC", "1h", domain.IndicatorRSI, string(domain.DirectionLong), int16(domain.RiskLevel2),
+			now.Add(-time.Duration(i) * time.Hour), "rsi crossed below 30", "ta-1",
+			int64(i + 1), "image/png", int32(1200), int32(700), now.Add(time.Hour),
+		})
+	}
+	repo := NewSignalRepository(&signalStubPool{rowsData: rows}, trace.NewNoopTracerProvider().Tracer("bench"))
+	filter := domain.SignalFilter{Symbol: "BTC", Limit: 200}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := repo.ListSignals(context.Background(), filter); err != nil {
+			b.Fatalf("list signals: %v", err)
+		}
+	}
+}
+
+func TestListSignalsPerformanceBudget(t *testing.T) {
+	loadtest.EnforceBudget(t, listSignalsBudget, BenchmarkListSignals)
+}
@@ -3,10 +3,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -50,7 +51,7 @@ func TestSignalInsertSignalsBatchesStatements(t *testing.T) {
 func TestSignalListSignalsReturnsRows(t *testing.T) {
 	now := time.Now().UTC().Truncate(time.Second)
 	rows := [][]any{{
-		int64(10), "BTC", "1h", domain.IndicatorRSI, string(domain.DirectionLong), int16(domain.RiskLevel2), now, "rsi crossed below 30",
+		int64(10), "BTC", "1h", domain.IndicatorRSI, string(domain.DirectionLong), int16(domain.RiskLevel2), now, "rsi crossed below 30", "ta-1",
 		int64(0), "", int32(0), int32(0), time.Unix(0, 0).UTC(),
 	}}
 	pool := &signalStubPool{rowsData: rows}
@@ -74,12 +75,64 @@ func TestSignalListSignalsReturnsRows(t *testing.T) {
 	if signals[0].Symbol != "BTC" || signals[0].Direction != domain.DirectionLong || signals[0].Risk != domain.RiskLevel2 {
 		t.Fatalf("unexpected signal payload: %+v", signals[0])
 	}
+	if signals[0].Version != "ta-1" {
+		t.Fatalf("expected version ta-1, got %q", signals[0].Version)
+	}
+}
+
+func TestSignalListSignalsFiltersByVersion(t *testing.T) {
+	pool := &signalStubPool{}
+	repo := NewSignalRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	if _, err := repo.ListSignals(context.Background(), domain.SignalFilter{Version: "ta-2", Limit: 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(pool.lastSQL, "s.version = $1") || pool.lastArgs[0] != "ta-2" {
+		t.Fatalf("expected version filter, got %s %v", pool.lastSQL, pool.lastArgs)
+	}
+}
+
+func TestSignalListSignalsFiltersByUntil(t *testing.T) {
+	pool := &signalStubPool{}
+	repo := NewSignalRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+	until := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, err := repo.ListSignals(context.Background(), domain.SignalFilter{Symbol: "BTC", Until: until}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(pool.lastSQL, "s.timestamp <= $2") || pool.lastArgs[1] != until {
+		t.Fatalf("expected until filter, got %s %v", pool.lastSQL, pool.lastArgs)
+	}
+}
+
+func TestSignalWinRatesByVersion(t *testing.T) {
+	pool := &signalStubPool{rowsData: [][]any{
+		{domain.IndicatorRSI, "", int64(40), int64(18)},
+		{domain.IndicatorRSI, "ta-1", int64(10), int64(6)},
+	}}
+	repo := NewSignalRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	rates, err := repo.WinRatesByVersion(context.Background(), domain.IndicatorRSI, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rates) != 2 || rates[1].Version != "ta-1" || rates[1].Wins != 6 || rates[1].WinRate != 0.6 {
+		t.Fatalf("unexpected win rates: %+v", rates)
+	}
+	if rates[0].WinRate != 0.45 {
+		t.Fatalf("expected legacy win rate 0.45, got %v", rates[0].WinRate)
+	}
+	if pool.lastArgs[0] != domain.IndicatorRSI || pool.lastArgs[1] != 4 {
+		t.Fatalf("unexpected args: %v", pool.lastArgs)
+	}
 }
 
 type signalStubPool struct {
 	batchResults pgx.BatchResults
 	queuedBatch  *pgx.Batch
 	rowsData     [][]any
+	lastSQL      string
+	lastArgs     []any
 }
 
 func (s *signalStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
@@ -95,6 +148,8 @@ func (s *signalStubPool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchR
 }
 
 func (s *signalStubPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	s.lastSQL = sql
+	s.lastArgs = args
 	if s.rowsData == nil {
 		return &signalStubRows{}, nil
 	}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
+	"bug-free-umbrella/pkg/domain"
+
 	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -101,3 +104,78 @@ func (r *SSHUserRepository) ListActive(ctx context.Context) ([]SSHUser, error) {
 	}
 	return users, rows.Err()
 }
+
+// GetWatchlist returns the symbols the user pinned in the TUI. An unknown
+// user has an empty watchlist.
+func (r *SSHUserRepository) GetWatchlist(ctx context.Context, userID int64) ([]string, error) {
+	_, span := r.tracer.Start(ctx, "ssh-user-repo.get-watchlist")
+	defer span.End()
+
+	var symbols []string
+	err := r.pool.QueryRow(ctx,
+		`SELECT watchlist FROM ssh_users WHERE id = $1`,
+		userID,
+	).Scan(&symbols)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return symbols, nil
+}
+
+// SetWatchlist replaces the user's pinned symbols. An empty list clears it.
+func (r *SSHUserRepository) SetWatchlist(ctx context.Context, userID int64, symbols []string) error {
+	_, span := r.tracer.Start(ctx, "ssh-user-repo.set-watchlist")
+	defer span.End()
+
+	if symbols == nil {
+		symbols = []string{}
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE ssh_users SET watchlist = $2, updated_at = NOW() WHERE id = $1`,
+		userID, symbols,
+	)
+	return err
+}
+
+// GetTUIState returns the user's saved TUI state. An unknown user has the
+// zero state.
+func (r *SSHUserRepository) GetTUIState(ctx context.Context, userID int64) (domain.TUIState, error) {
+	_, span := r.tracer.Start(ctx, "ssh-user-repo.get-tui-state")
+	defer span.End()
+
+	var raw string
+	err := r.pool.QueryRow(ctx,
+		`SELECT tui_state::text FROM ssh_users WHERE id = $1`,
+		userID,
+	).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TUIState{}, nil
+	}
+	if err != nil {
+		return domain.TUIState{}, err
+	}
+	var state domain.TUIState
+	if err := json.Unmarshal([]byte(raw), &state); err != nil {
+		return domain.TUIState{}, err
+	}
+	return state, nil
+}
+
+// SetTUIState replaces the user's saved TUI state.
+func (r *SSHUserRepository) SetTUIState(ctx context.Context, userID int64, state domain.TUIState) error {
+	_, span := r.tracer.Start(ctx, "ssh-user-repo.set-tui-state")
+	defer span.End()
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx,
+		`UPDATE ssh_users SET tui_state = $2::jsonb, updated_at = NOW() WHERE id = $1`,
+		userID, string(raw),
+	)
+	return err
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"bug-free-umbrella/pkg/domain"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"go.opentelemetry.io/otel/trace"
@@ -86,10 +88,94 @@ func TestSSHUserListActiveReturnsUsers(t *testing.T) {
 	}
 }
 
+func TestSSHUserGetWatchlist(t *testing.T) {
+	pool := &sshStubPool{queryRowData: []any{[]string{"BTC", "SOL"}}}
+	repo := NewSSHUserRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	symbols, err := repo.GetWatchlist(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(symbols) != 2 || symbols[0] != "BTC" || symbols[1] != "SOL" {
+		t.Fatalf("unexpected watchlist %v", symbols)
+	}
+}
+
+func TestSSHUserGetWatchlistUnknownUser(t *testing.T) {
+	pool := &sshStubPool{}
+	repo := NewSSHUserRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	symbols, err := repo.GetWatchlist(context.Background(), 99)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if symbols != nil {
+		t.Fatalf("expected empty watchlist, got %v", symbols)
+	}
+}
+
+func TestSSHUserSetWatchlistClearsWithEmptyArray(t *testing.T) {
+	pool := &sshStubPool{}
+	repo := NewSSHUserRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	if err := repo.SetWatchlist(context.Background(), 1, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.execCount != 1 {
+		t.Fatalf("expected 1 exec, got %d", pool.execCount)
+	}
+	// NULL would violate the NOT NULL column.
+	if got, ok := pool.lastExecArgs[1].([]string); !ok || got == nil {
+		t.Fatalf("expected non-nil empty slice, got %#v", pool.lastExecArgs[1])
+	}
+}
+
+func TestSSHUserGetTUIState(t *testing.T) {
+	pool := &sshStubPool{queryRowData: []any{`{"tab":"signals","theme":"solarized","signal_risk":"4"}`}}
+	repo := NewSSHUserRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	state, err := repo.GetTUIState(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Tab != "signals" || state.Theme != "solarized" || state.SignalRisk != "4" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
+
+func TestSSHUserGetTUIStateUnknownUser(t *testing.T) {
+	pool := &sshStubPool{}
+	repo := NewSSHUserRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	state, err := repo.GetTUIState(context.Background(), 99)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != (domain.TUIState{}) {
+		t.Fatalf("expected zero state, got %+v", state)
+	}
+}
+
+func TestSSHUserSetTUIStateStoresJSON(t *testing.T) {
+	pool := &sshStubPool{}
+	repo := NewSSHUserRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	if err := repo.SetTUIState(context.Background(), 1, domain.TUIState{Tab: "chat"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.execCount != 1 {
+		t.Fatalf("expected 1 exec, got %d", pool.execCount)
+	}
+	if got := pool.lastExecArgs[1]; got != `{"tab":"chat"}` {
+		t.Fatalf("unexpected stored state %#v", got)
+	}
+}
+
 // --- stubs ---
 
 type sshStubPool struct {
 	execCount    int
+	lastExecArgs []any
 	queryRowData []any
 	queryRowErr  error
 	rowsData     [][]any
@@ -97,6 +183,7 @@ type sshStubPool struct {
 
 func (s *sshStubPool) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
 	s.execCount++
+	s.lastExecArgs = args
 	return pgconn.CommandTag{}, nil
 }
 
@@ -155,10 +242,14 @@ func (r *sshStubRows) Scan(dest ...any) error {
 		switch ptr := d.(type) {
 		case *int64:
 			*ptr = row[i].(int64)
+		case *int:
+			*ptr = row[i].(int)
 		case *string:
 			*ptr = row[i].(string)
 		case *bool:
 			*ptr = row[i].(bool)
+		case *[]int64:
+			*ptr = row[i].([]int64)
 		case **time.Time:
 			if row[i] == nil || row[i] == (*time.Time)(nil) {
 				*ptr = nil
@@ -195,10 +286,14 @@ func (r *sshStubRow) Scan(dest ...any) error {
 		switch ptr := d.(type) {
 		case *int64:
 			*ptr = r.data[i].(int64)
+		case *int:
+			*ptr = r.data[i].(int)
 		case *string:
 			*ptr = r.data[i].(string)
 		case *bool:
 			*ptr = r.data[i].(bool)
+		case *[]string:
+			*ptr = r.data[i].([]string)
 		case **time.Time:
 			if r.data[i] == nil || r.data[i] == (*time.Time)(nil) {
 				*ptr = nil
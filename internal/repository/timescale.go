@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+)
+
+// DetectTimescale reports whether the timescaledb extension is installed in
+// the connected database. Any query error is treated as "not installed".
+func DetectTimescale(ctx context.Context, pool PgxPool) bool {
+	var installed bool
+	err := pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'timescaledb')`,
+	).Scan(&installed)
+	return err == nil && installed
+}
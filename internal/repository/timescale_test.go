@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type timescalePool struct {
+	stubPool
+	installed bool
+	err       error
+}
+
+func (p *timescalePool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &extensionRow{installed: p.installed, err: p.err}
+}
+
+type extensionRow struct {
+	installed bool
+	err       error
+}
+
+func (r *extensionRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*bool) = r.installed
+	return nil
+}
+
+func TestDetectTimescale(t *testing.T) {
+	ctx := context.Background()
+	if !DetectTimescale(ctx, &timescalePool{installed: true}) {
+		t.Fatal("expected timescale to be detected")
+	}
+	if DetectTimescale(ctx, &timescalePool{}) {
+		t.Fatal("expected timescale to be absent")
+	}
+	if DetectTimescale(ctx, &timescalePool{installed: true, err: errors.New("boom")}) {
+		t.Fatal("expected query errors to report absent")
+	}
+}
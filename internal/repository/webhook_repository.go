@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const webhookColumns = `id, tenant_id, label, url, payload_template, headers::text, created_at, disabled_at`
+
+// WebhookRepository stores the HTTP endpoints that receive new signals.
+// Reads and writes are scoped to the tenant bound to ctx, except
+// ListActiveWebhooks, which delivery uses across tenants.
+type WebhookRepository struct {
+	pool   PgxPool
+	tracer trace.Tracer
+}
+
+func NewWebhookRepository(pool PgxPool, tracer trace.Tracer) *WebhookRepository {
+	return &WebhookRepository{pool: pool, tracer: tracer}
+}
+
+func (r *WebhookRepository) CreateWebhook(ctx context.Context, webhook domain.SignalWebhook) (*domain.SignalWebhook, error) {
+	_, span := r.tracer.Start(ctx, "webhook-repo.create")
+	defer span.End()
+
+	if webhook.Headers == nil {
+		webhook.Headers = map[string]string{}
+	}
+	headers, err := json.Marshal(webhook.Headers)
+	if err != nil {
+		return nil, err
+	}
+	webhook.TenantID = domain.TenantFromContext(ctx)
+	webhook.Label = strings.TrimSpace(webhook.Label)
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	err = r.pool.QueryRow(ctx,
+		`INSERT INTO signal_webhooks (tenant_id, label, url, payload_template, headers)
+		 VALUES ($1, $2, $3, $4, $5::jsonb)
+		 RETURNING id, created_at`,
+		webhook.TenantID, webhook.Label, webhook.URL, webhook.PayloadTemplate, string(headers),
+	).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.CreatedAt = webhook.CreatedAt.UTC()
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) DisableWebhook(ctx context.Context, id int64) error {
+	_, span := r.tracer.Start(ctx, "webhook-repo.disable")
+	defer span.End()
+
+	_, err := r.pool.Exec(ctx,
+		`UPDATE signal_webhooks SET disabled_at = NOW()
+		 WHERE id = $1 AND tenant_id = $2 AND disabled_at IS NULL`,
+		id, domain.TenantFromContext(ctx),
+	)
+	return err
+}
+
+// ListWebhooks returns the tenant's webhooks newest first. Disabled ones are
+// included only when includeDisabled is set.
+func (r *WebhookRepository) ListWebhooks(ctx context.Context, includeDisabled bool) ([]domain.SignalWebhook, error) {
+	_, span := r.tracer.Start(ctx, "webhook-repo.list")
+	defer span.End()
+
+	return r.queryWebhooks(ctx,
+		`SELECT `+webhookColumns+`
+		 FROM signal_webhooks
+		 WHERE tenant_id = $1 AND ($2 OR disabled_at IS NULL)
+		 ORDER BY created_at DESC, id DESC`,
+		domain.TenantFromContext(ctx), includeDisabled,
+	)
+}
+
+// ListActiveWebhooks returns every tenant's active webhooks, oldest first.
+func (r *WebhookRepository) ListActiveWebhooks(ctx context.Context) ([]domain.SignalWebhook, error) {
+	_, span := r.tracer.Start(ctx, "webhook-repo.list-active")
+	defer span.End()
+
+	return r.queryWebhooks(ctx,
+		`SELECT `+webhookColumns+`
+		 FROM signal_webhooks
+		 WHERE disabled_at IS NULL
+		 ORDER BY id`,
+	)
+}
+
+func (r *WebhookRepository) queryWebhooks(ctx context.Context, query string, args ...any) ([]domain.SignalWebhook, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []domain.SignalWebhook
+	for rows.Next() {
+		var w domain.SignalWebhook
+		var headers string
+		var disabledAt *time.Time
+		if err := rows.Scan(&w.ID, &w.TenantID, &w.Label, &w.URL, &w.PayloadTemplate, &headers, &w.CreatedAt, &disabledAt); err != nil {
+			return nil, err
+		}
+		if headers != "" {
+			if err := json.Unmarshal([]byte(headers), &w.Headers); err != nil {
+				return nil, err
+			}
+		}
+		w.CreatedAt = w.CreatedAt.UTC()
+		w.DisabledAt = disabledAt
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWebhookCreateStoresHeadersAsJSON(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pool := &apiKeyStubPool{queryRowData: []any{int64(3), now}}
+	repo := NewWebhookRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	webhook, err := repo.CreateWebhook(ctx, domain.SignalWebhook{
+		Label:   " discord ",
+		URL:     " https://discord.example/api/webhooks/1 ",
+		Headers: map[string]string{"X-Token": "secret"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if webhook.ID != 3 || webhook.TenantID != "acme" || webhook.Label != "discord" || webhook.URL != "https://discord.example/api/webhooks/1" {
+		t.Fatalf("unexpected webhook: %+v", webhook)
+	}
+	if len(pool.queryRowArgs) != 5 || pool.queryRowArgs[0] != "acme" || pool.queryRowArgs[4] != `{"X-Token":"secret"}` {
+		t.Fatalf("expected the tenant and headers as JSON, got %v", pool.queryRowArgs)
+	}
+}
+
+func TestWebhookListDecodesHeaders(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pool := &apiKeyStubPool{rowsData: [][]any{
+		{int64(1), "acme", "pagerduty", "https://events.example/v2/enqueue", `{"symbol":"{{.Symbol}}"}`, `{"Authorization":"Token abc"}`, now, nil},
+	}}
+	repo := NewWebhookRepository(pool, trace.NewNoopTracerProvider().Tracer("test"))
+
+	webhooks, err := repo.ListWebhooks(domain.WithTenant(context.Background(), "acme"), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].TenantID != "acme" || webhooks[0].Headers["Authorization"] != "Token abc" || webhooks[0].DisabledAt != nil {
+		t.Fatalf("unexpected webhooks: %+v", webhooks)
+	}
+	if len(pool.queryArgs) != 2 || pool.queryArgs[0] != "acme" {
+		t.Fatalf("expected the list scoped to the tenant, got %v", pool.queryArgs)
+	}
+}
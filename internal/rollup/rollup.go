@@ -0,0 +1,153 @@
+// Package rollup derives higher-interval candles, such as 4h and 1d, from
+// stored 1h candles, for when the price provider does not return them.
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// SourceInterval is the interval derived candles are built from.
+const SourceInterval = "1h"
+
+// DefaultTargets are the intervals derived from SourceInterval: the long
+// polling tier.
+var DefaultTargets = domain.IntervalsInTier(domain.IntervalTierLong)
+
+// Candles rolls source candles up into interval buckets aligned to the Unix
+// epoch, as the providers and the Postgres aggregation align them. Only
+// complete buckets are returned: every source candle in the bucket must be
+// present and the bucket must have closed by now. The result is oldest
+// first.
+func Candles(source []*domain.Candle, interval string, now time.Time) []*domain.Candle {
+	if len(source) == 0 {
+		return nil
+	}
+	sourceStep := domain.IntervalDuration(source[0].Interval)
+	step := domain.IntervalDuration(interval)
+	if sourceStep <= 0 || step <= sourceStep || step%sourceStep != 0 {
+		return nil
+	}
+	want := int(step / sourceStep)
+
+	sorted := append([]*domain.Candle(nil), source...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OpenTime.Before(sorted[j].OpenTime) })
+
+	var (
+		out   []*domain.Candle
+		cur   *domain.Candle
+		count int
+		last  time.Time
+	)
+	flush := func() {
+		if cur != nil && count == want && !cur.OpenTime.Add(step).After(now) {
+			out = append(out, cur)
+		}
+	}
+	for _, c := range sorted {
+		if c.OpenTime.Equal(last) && cur != nil {
+			continue // duplicate row
+		}
+		last = c.OpenTime
+		bucket := c.OpenTime.UTC().Truncate(step)
+		if cur == nil || !cur.OpenTime.Equal(bucket) {
+			flush()
+			cur = &domain.Candle{
+				Symbol:   c.Symbol,
+				Interval: interval,
+				OpenTime: bucket,
+				Open:     c.Open,
+				High:     c.High,
+				Low:      c.Low,
+			}
+			count = 0
+		}
+		count++
+		if c.High > cur.High {
+			cur.High = c.High
+		}
+		if c.Low < cur.Low {
+			cur.Low = c.Low
+		}
+		cur.Close = c.Close
+		cur.Volume += c.Volume
+	}
+	flush()
+	return out
+}
+
+// Store reads and writes candles. Both storage backends satisfy it.
+type Store interface {
+	GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error)
+	UpsertCandles(ctx context.Context, candles []*domain.Candle) error
+}
+
+// Deriver fills gaps in the target intervals with candles built from the
+// stored SourceInterval candles. Candles the provider stored are never
+// overwritten.
+type Deriver struct {
+	store   Store
+	targets []string
+	now     func() time.Time
+}
+
+// NewDeriver derives targets, or DefaultTargets when none are given.
+func NewDeriver(store Store, targets ...string) *Deriver {
+	if len(targets) == 0 {
+		targets = DefaultTargets
+	}
+	return &Deriver{store: store, targets: targets, now: time.Now}
+}
+
+// Derive stores the complete target candles of symbol opening in
+// [from, to] that are missing from the store, and returns how many it
+// stored.
+func (d *Deriver) Derive(ctx context.Context, symbol string, from, to time.Time) (int, error) {
+	if d.store == nil {
+		return 0, fmt.Errorf("rollup store is not initialized")
+	}
+	now := d.now().UTC()
+	if to.After(now) {
+		to = now
+	}
+	var missing []*domain.Candle
+	for _, interval := range d.targets {
+		step := domain.IntervalDuration(interval)
+		if step <= 0 {
+			return 0, fmt.Errorf("unsupported rollup interval %q", interval)
+		}
+		start := from.UTC().Truncate(step)
+		source, err := d.store.GetCandlesInRange(ctx, symbol, SourceInterval, start, to)
+		if err != nil {
+			return 0, fmt.Errorf("get %s candles for %s: %w", SourceInterval, symbol, err)
+		}
+		derived := Candles(source, interval, now)
+		if len(derived) == 0 {
+			continue
+		}
+		existing, err := d.store.GetCandlesInRange(ctx, symbol, interval, start, to)
+		if err != nil {
+			return 0, fmt.Errorf("get %s candles for %s: %w", interval, symbol, err)
+		}
+		stored := make(map[int64]bool, len(existing))
+		for _, c := range existing {
+			stored[c.OpenTime.Unix()] = true
+		}
+		for _, c := range derived {
+			if !stored[c.OpenTime.Unix()] {
+				missing = append(missing, c)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+	if err := d.store.UpsertCandles(ctx, missing); err != nil {
+		return 0, fmt.Errorf("upsert derived candles for %s: %w", symbol, err)
+	}
+	return len(missing), nil
+}
@@ -0,0 +1,110 @@
+package rollup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func hourly(symbol string, start time.Time, n int) []*domain.Candle {
+	out := make([]*domain.Candle, 0, n)
+	for i := 0; i < n; i++ {
+		base := float64(100 + i)
+		out = append(out, &domain.Candle{
+			Symbol:   symbol,
+			Interval: "1h",
+			OpenTime: start.Add(time.Duration(i) * time.Hour),
+			Open:     base,
+			High:     base + 2,
+			Low:      base - 1,
+			Close:    base + 1,
+			Volume:   10,
+		})
+	}
+	return out
+}
+
+func TestCandlesBuildsCompleteBuckets(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	source := hourly("BTC", start, 10) // 00:00-09:00: two full 4h buckets and a partial one
+	// Newest first, as the stores return them.
+	for i, j := 0, len(source)-1; i < j; i, j = i+1, j-1 {
+		source[i], source[j] = source[j], source[i]
+	}
+
+	got := Candles(source, "4h", start.Add(24*time.Hour))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 complete 4h candles, got %d", len(got))
+	}
+	first := got[0]
+	if !first.OpenTime.Equal(start) || first.Interval != "4h" || first.Open != 100 || first.Close != 104 ||
+		first.High != 105 || first.Low != 99 || first.Volume != 40 {
+		t.Fatalf("unexpected first candle: %+v", first)
+	}
+	if !got[1].OpenTime.Equal(start.Add(4 * time.Hour)) {
+		t.Fatalf("unexpected second bucket: %v", got[1].OpenTime)
+	}
+
+	// A bucket missing an hour, or still open, is not derived.
+	gapped := append(hourly("BTC", start, 2), hourly("BTC", start.Add(3*time.Hour), 1)...)
+	if got := Candles(gapped, "4h", start.Add(24*time.Hour)); len(got) != 0 {
+		t.Fatalf("expected no candle from a gapped bucket, got %+v", got)
+	}
+	if got := Candles(hourly("BTC", start, 4), "4h", start.Add(3*time.Hour)); len(got) != 0 {
+		t.Fatalf("expected no candle from an open bucket, got %+v", got)
+	}
+}
+
+type memoryStore struct {
+	candles  map[string][]*domain.Candle
+	upserted []*domain.Candle
+}
+
+func (s *memoryStore) GetCandlesInRange(_ context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error) {
+	var out []*domain.Candle
+	for _, c := range s.candles[interval] {
+		if c.Symbol == symbol && !c.OpenTime.Before(from) && !c.OpenTime.After(to) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *memoryStore) UpsertCandles(_ context.Context, candles []*domain.Candle) error {
+	s.upserted = append(s.upserted, candles...)
+	return nil
+}
+
+func TestDeriverFillsOnlyMissingCandles(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	store := &memoryStore{candles: map[string][]*domain.Candle{
+		"1h": hourly("ETH", start, 30),
+		// The provider returned the first 4h candle itself.
+		"4h": {{Symbol: "ETH", Interval: "4h", OpenTime: start, Open: 1, High: 1, Low: 1, Close: 1}},
+	}}
+	d := NewDeriver(store)
+	d.now = func() time.Time { return start.Add(30 * time.Hour) }
+
+	n, err := d.Derive(context.Background(), "ETH", start, start.Add(48*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 30 hours hold 7 complete 4h buckets, one of them stored, and one day.
+	if n != 7 || len(store.upserted) != 7 {
+		t.Fatalf("expected 6 4h candles and one 1d candle, got %d", n)
+	}
+	var days int
+	for _, c := range store.upserted {
+		if c.Interval == "4h" && c.OpenTime.Equal(start) {
+			t.Fatal("expected the provider's 4h candle kept")
+		}
+		if c.Interval == "1d" {
+			days++
+		}
+	}
+	if days != 1 {
+		t.Fatalf("expected one 1d candle, got %d", days)
+	}
+}
@@ -0,0 +1,74 @@
+package sandbox
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"bug-free-umbrella/internal/alerttemplate"
+	"bug-free-umbrella/pkg/domain"
+)
+
+const alertLogCapacity = 200
+
+// AlertLog replaces the Telegram alert dispatcher in demo mode. It logs
+// signal alerts and broadcasts and keeps the most recent ones in memory.
+type AlertLog struct {
+	mu       sync.Mutex
+	text     *alerttemplate.Renderer
+	signals  []domain.Signal
+	messages []string
+}
+
+func NewAlertLog() *AlertLog {
+	return &AlertLog{text: alerttemplate.Default()}
+}
+
+// SetAlertText sets the templates alerts are logged with, so a deployment's
+// alert wording can be tried out in demo mode.
+func (a *AlertLog) SetAlertText(r *alerttemplate.Renderer) {
+	if r != nil {
+		a.text = r
+	}
+}
+
+func (a *AlertLog) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, s := range signals {
+		log.Printf("[demo alert] %s", a.text.Signal(s))
+	}
+	a.signals = appendCapped(a.signals, signals...)
+	return nil
+}
+
+// Broadcast records message and reports a single recipient: the log.
+func (a *AlertLog) Broadcast(ctx context.Context, message string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	log.Printf("[demo broadcast] %s", message)
+	a.messages = appendCapped(a.messages, message)
+	return 1, nil
+}
+
+// RecentSignals returns the alerted signals, oldest first.
+func (a *AlertLog) RecentSignals() []domain.Signal {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]domain.Signal(nil), a.signals...)
+}
+
+// RecentBroadcasts returns the broadcast messages, oldest first.
+func (a *AlertLog) RecentBroadcasts() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.messages...)
+}
+
+func appendCapped[T any](buf []T, items ...T) []T {
+	buf = append(buf, items...)
+	if len(buf) > alertLogCapacity {
+		buf = append(buf[:0:0], buf[len(buf)-alertLogCapacity:]...)
+	}
+	return buf
+}
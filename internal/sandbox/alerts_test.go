@@ -0,0 +1,43 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestAlertLogRecordsSignalsAndBroadcasts(t *testing.T) {
+	a := NewAlertLog()
+	ctx := context.Background()
+
+	if err := a.NotifySignals(ctx, []domain.Signal{{Symbol: "BTC", Indicator: domain.IndicatorRSI}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n, err := a.Broadcast(ctx, "maintenance at noon")
+	if err != nil || n != 1 {
+		t.Fatalf("unexpected broadcast result: n=%d err=%v", n, err)
+	}
+
+	if got := a.RecentSignals(); len(got) != 1 || got[0].Symbol != "BTC" {
+		t.Fatalf("unexpected signals: %+v", got)
+	}
+	if got := a.RecentBroadcasts(); len(got) != 1 || got[0] != "maintenance at noon" {
+		t.Fatalf("unexpected broadcasts: %+v", got)
+	}
+}
+
+func TestAlertLogIsBounded(t *testing.T) {
+	a := NewAlertLog()
+	for i := 0; i < alertLogCapacity+10; i++ {
+		a.Broadcast(context.Background(), fmt.Sprintf("msg %d", i))
+	}
+	got := a.RecentBroadcasts()
+	if len(got) != alertLogCapacity {
+		t.Fatalf("expected %d messages, got %d", alertLogCapacity, len(got))
+	}
+	if got[0] != "msg 10" {
+		t.Fatalf("expected oldest messages to be dropped, first is %q", got[0])
+	}
+}
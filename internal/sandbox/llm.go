@@ -0,0 +1,86 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/openai/openai-go"
+)
+
+// LLMClient answers advisor questions without calling OpenAI. Replies quote
+// the market context the advisor assembled for the symbols the user asked
+// about, so the whole advisor flow stays exercised end to end.
+type LLMClient struct{}
+
+func NewLLMClient() *LLMClient {
+	return &LLMClient{}
+}
+
+func (c *LLMClient) CreateChatCompletion(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+) (*openai.ChatCompletion, error) {
+	var system, question string
+	for _, msg := range params.Messages {
+		switch {
+		case msg.OfSystem != nil:
+			system = msg.OfSystem.Content.OfString.Value
+		case msg.OfUser != nil:
+			question = msg.OfUser.Content.OfString.Value
+		}
+	}
+
+	return &openai.ChatCompletion{
+		Model: params.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			FinishReason: "stop",
+			Message: openai.ChatCompletionMessage{
+				Role:    "assistant",
+				Content: demoReply(system, question),
+			},
+		}},
+	}, nil
+}
+
+func demoReply(system, question string) string {
+	asked := mentionedSymbols(question)
+
+	var lines []string
+	for _, line := range strings.Split(system, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		symbol := strings.TrimSuffix(fields[0], ":")
+		if _, ok := asked[symbol]; ok {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[demo advisor] Set OPENAI_API_KEY for real analysis.")
+	if len(lines) == 0 {
+		sb.WriteString(" Ask about a tracked symbol (e.g. BTC) to see its prices and signals.")
+		return sb.String()
+	}
+	sb.WriteString(" Here is what the market context shows:\n")
+	for _, line := range lines {
+		fmt.Fprintf(&sb, "- %s\n", line)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func mentionedSymbols(question string) map[string]struct{} {
+	out := make(map[string]struct{})
+	for _, word := range strings.FieldsFunc(strings.ToUpper(question), func(r rune) bool {
+		return r < 'A' || r > 'Z'
+	}) {
+		if domain.IsSupportedSymbol(word) {
+			out[word] = struct{}{}
+		}
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package sandbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+)
+
+func TestLLMClientQuotesContextForAskedSymbols(t *testing.T) {
+	system := "You are an advisor.\n\nCurrent Prices:\n  BTC: $60000.00 (24h: +1.00%, vol: $100)\n  ETH: $3000.00 (24h: -2.00%, vol: $50)\n\nActive Signals:\n  BTC 1h RSI LONG risk=3 rsi 28.00 crossed below 30\n"
+	resp, err := NewLLMClient().CreateChatCompletion(context.Background(), openai.ChatCompletionNewParams{
+		Model: "demo",
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(system),
+			openai.UserMessage("what about btc?"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Choices) != 1 {
+		t.Fatalf("expected one choice, got %d", len(resp.Choices))
+	}
+	reply := resp.Choices[0].Message.Content
+	if !strings.Contains(reply, "BTC: $60000.00") || !strings.Contains(reply, "BTC 1h RSI LONG") {
+		t.Fatalf("expected BTC context in reply, got %q", reply)
+	}
+	if strings.Contains(reply, "ETH") {
+		t.Fatalf("did not expect ETH context in reply, got %q", reply)
+	}
+}
+
+func TestLLMClientHintsWhenNoSymbolAsked(t *testing.T) {
+	resp, err := NewLLMClient().CreateChatCompletion(context.Background(), openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resp.Choices[0].Message.Content, "Ask about a tracked symbol") {
+		t.Fatalf("unexpected reply: %q", resp.Choices[0].Message.Content)
+	}
+}
@@ -0,0 +1,200 @@
+// Package sandbox provides the in-process stand-ins used by demo mode:
+// embedded Postgres and Redis, a canned LLM client and an in-memory alert
+// sink, so cmd/server and cmd/ssh run without any external service.
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/cmd/migrate/migrations"
+
+	"github.com/alicebob/miniredis/v2"
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	pgUser     = "umbrella"
+	pgPassword = "umbrella"
+	pgDatabase = "umbrella"
+)
+
+type StorageConfig struct {
+	// Postgres and Redis select which stores to provide.
+	Postgres bool
+	Redis    bool
+	// DataDir holds the Postgres cluster and binaries between runs.
+	DataDir      string
+	PostgresPort int
+	RedisPort    int
+}
+
+// Storage is the embedded Postgres and Redis pair backing demo mode.
+type Storage struct {
+	DatabaseURL string
+	RedisURL    string
+
+	pg    *embeddedpostgres.EmbeddedPostgres
+	redis *miniredis.Miniredis
+}
+
+// StartStorage starts the selected embedded stores and applies all schema
+// migrations to Postgres. When a port is already accepting connections it is
+// assumed to belong to another demo process (e.g. cmd/server while starting
+// cmd/ssh) and is reused instead of started, so both binaries share one
+// database.
+func StartStorage(ctx context.Context, cfg StorageConfig) (*Storage, error) {
+	if cfg.DataDir == "" {
+		cfg.DataDir = filepath.Join(os.TempDir(), "bug-free-umbrella-demo")
+	}
+	if cfg.PostgresPort <= 0 {
+		cfg.PostgresPort = 54329
+	}
+	if cfg.RedisPort <= 0 {
+		cfg.RedisPort = 63799
+	}
+
+	s := &Storage{
+		DatabaseURL: fmt.Sprintf("postgres://%s:%s@127.0.0.1:%d/%s?sslmode=disable", pgUser, pgPassword, cfg.PostgresPort, pgDatabase),
+		RedisURL:    "127.0.0.1:" + strconv.Itoa(cfg.RedisPort),
+	}
+
+	if !cfg.Postgres {
+		s.DatabaseURL = ""
+	} else if portInUse(cfg.PostgresPort) {
+		log.Printf("Demo Postgres already running on :%d, reusing it", cfg.PostgresPort)
+	} else {
+		s.pg = embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+			Port(uint32(cfg.PostgresPort)).
+			Username(pgUser).
+			Password(pgPassword).
+			Database(pgDatabase).
+			DataPath(filepath.Join(cfg.DataDir, "pgdata")).
+			RuntimePath(filepath.Join(cfg.DataDir, "pgruntime")).
+			BinariesPath(filepath.Join(cfg.DataDir, "pgbin")).
+			StartTimeout(60 * time.Second).
+			Logger(io.Discard))
+		if err := s.pg.Start(); err != nil {
+			return nil, fmt.Errorf("start embedded postgres: %w", err)
+		}
+		log.Printf("Demo Postgres started on :%d (data in %s)", cfg.PostgresPort, cfg.DataDir)
+	}
+
+	if !cfg.Redis {
+		s.RedisURL = ""
+	} else if portInUse(cfg.RedisPort) {
+		log.Printf("Demo Redis already running on :%d, reusing it", cfg.RedisPort)
+	} else {
+		s.redis = miniredis.NewMiniRedis()
+		if err := s.redis.StartAddr(s.RedisURL); err != nil {
+			s.Stop()
+			return nil, fmt.Errorf("start embedded redis: %w", err)
+		}
+		log.Printf("Demo Redis started on :%d", cfg.RedisPort)
+	}
+
+	if s.DatabaseURL != "" {
+		if err := applyMigrations(ctx, s.DatabaseURL); err != nil {
+			s.Stop()
+			return nil, fmt.Errorf("apply migrations: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Stop shuts down whatever this process started.
+func (s *Storage) Stop() {
+	if s.redis != nil {
+		s.redis.Close()
+	}
+	if s.pg != nil {
+		if err := s.pg.Stop(); err != nil {
+			log.Printf("stop embedded postgres: %v", err)
+		}
+	}
+}
+
+// applyMigrations applies pending *.up.sql files and records them in
+// schema_migrations, the same bookkeeping cmd/migrate uses.
+func applyMigrations(ctx context.Context, dsn string) error {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	if _, err := pool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version     BIGINT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    applied_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);`); err != nil {
+		return err
+	}
+
+	paths, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(p, ".up.sql"), "_")
+		if !ok {
+			return fmt.Errorf("invalid migration filename: %s", p)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse version in %s: %w", p, err)
+		}
+
+		var applied bool
+		if err := pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, version).Scan(&applied); err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		sqlBytes, err := fs.ReadFile(migrations.FS, p)
+		if err != nil {
+			return err
+		}
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, string(sqlBytes)); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("version %d up failed: %w", version, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, version, name); err != nil {
+			tx.Rollback(ctx)
+			return fmt.Errorf("record version %d failed: %w", version, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func portInUse(port int) bool {
+	conn, err := net.DialTimeout("tcp", "127.0.0.1:"+strconv.Itoa(port), 300*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// AWSSecretsManagerProvider reads a JSON SecretString from AWS Secrets Manager.
+// Credentials come from the aws.Config, so instance roles, IRSA web identity
+// and SSO sessions are refreshed by the SDK before they expire.
+type AWSSecretsManagerProvider struct {
+	client   *secretsmanager.Client
+	secretID string
+}
+
+func NewAWSSecretsManagerProvider(cfg aws.Config, secretID, endpoint string) *AWSSecretsManagerProvider {
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return &AWSSecretsManagerProvider{client: client, secretID: secretID}
+}
+
+// loadAWSConfig resolves credentials through the SDK default chain: env vars,
+// shared config/SSO profiles, web identity tokens, then ECS/EC2 roles.
+func loadAWSConfig(ctx context.Context, region string) (aws.Config, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("load aws config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (p *AWSSecretsManagerProvider) Name() string { return BackendAWSSM }
+
+func (p *AWSSecretsManagerProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	resp, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(p.secretID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("secrets manager request: %w", err)
+	}
+	if resp.SecretString == nil || *resp.SecretString == "" {
+		return nil, fmt.Errorf("secret %s has no SecretString", p.secretID)
+	}
+
+	var out map[string]string
+	if err := json.Unmarshal([]byte(*resp.SecretString), &out); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object of strings: %w", p.secretID, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	BackendEnv   = "env"
+	BackendVault = "vault"
+	BackendAWSSM = "aws-sm"
+
+	defaultRefreshSecs = 300
+	defaultVaultPath   = "secret/data/bug-free-umbrella"
+)
+
+// ManagedKeys are the sensitive settings that may be sourced from a secrets
+// backend instead of plaintext environment variables.
+var ManagedKeys = []string{"TELEGRAM_BOT_TOKEN", "OPENAI_API_KEY", "DATABASE_URL"}
+
+// Provider fetches the current secret key/value pairs from a backend.
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Config selects and configures a secrets backend.
+type Config struct {
+	Backend     string
+	RefreshSecs int
+
+	VaultAddr      string
+	VaultAuth      VaultAuth
+	VaultNamespace string
+	VaultPath      string
+
+	AWSRegion   string
+	AWSSecretID string
+	AWSEndpoint string
+}
+
+// ConfigFromEnv reads secrets backend settings. It runs before config.Load so
+// that fetched values can be exported into the environment first.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Backend:     strings.ToLower(strings.TrimSpace(os.Getenv("SECRETS_BACKEND"))),
+		RefreshSecs: defaultRefreshSecs,
+		VaultAddr:   strings.TrimRight(strings.TrimSpace(os.Getenv("VAULT_ADDR")), "/"),
+		VaultAuth: VaultAuth{
+			Token:        strings.TrimSpace(os.Getenv("VAULT_TOKEN")),
+			RoleID:       strings.TrimSpace(os.Getenv("VAULT_ROLE_ID")),
+			SecretID:     strings.TrimSpace(os.Getenv("VAULT_SECRET_ID")),
+			K8sRole:      strings.TrimSpace(os.Getenv("VAULT_K8S_ROLE")),
+			K8sTokenPath: strings.TrimSpace(os.Getenv("VAULT_K8S_TOKEN_PATH")),
+		},
+		VaultNamespace: strings.TrimSpace(os.Getenv("VAULT_NAMESPACE")),
+		VaultPath:      strings.Trim(strings.TrimSpace(os.Getenv("VAULT_SECRET_PATH")), "/"),
+		AWSRegion:      strings.TrimSpace(os.Getenv("AWS_REGION")),
+		AWSSecretID:    strings.TrimSpace(os.Getenv("AWS_SECRET_ID")),
+		AWSEndpoint:    strings.TrimRight(strings.TrimSpace(os.Getenv("AWS_SECRETS_ENDPOINT")), "/"),
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = BackendEnv
+	}
+	if raw := strings.TrimSpace(os.Getenv("SECRETS_REFRESH_SECS")); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v >= 0 {
+			cfg.RefreshSecs = v
+		}
+	}
+	if cfg.VaultPath == "" {
+		cfg.VaultPath = defaultVaultPath
+	}
+	if cfg.AWSRegion == "" {
+		cfg.AWSRegion = strings.TrimSpace(os.Getenv("AWS_DEFAULT_REGION"))
+	}
+	return cfg
+}
+
+// NewProvider builds the provider selected by cfg.Backend. The env backend
+// returns a nil provider: values are read directly from the environment.
+func NewProvider(ctx context.Context, cfg Config) (Provider, error) {
+	switch cfg.Backend {
+	case "", BackendEnv:
+		return nil, nil
+	case BackendVault:
+		if cfg.VaultAddr == "" || (cfg.VaultAuth.Token == "" && !cfg.VaultAuth.canLogin()) {
+			return nil, fmt.Errorf("vault backend requires VAULT_ADDR and VAULT_TOKEN, VAULT_ROLE_ID/VAULT_SECRET_ID or VAULT_K8S_ROLE")
+		}
+		return NewVaultProvider(cfg.VaultAddr, cfg.VaultAuth, cfg.VaultNamespace, cfg.VaultPath, nil), nil
+	case BackendAWSSM:
+		if cfg.AWSRegion == "" || cfg.AWSSecretID == "" {
+			return nil, fmt.Errorf("aws-sm backend requires AWS_REGION and AWS_SECRET_ID")
+		}
+		awsCfg, err := loadAWSConfig(ctx, cfg.AWSRegion)
+		if err != nil {
+			return nil, err
+		}
+		return NewAWSSecretsManagerProvider(awsCfg, cfg.AWSSecretID, cfg.AWSEndpoint), nil
+	default:
+		return nil, fmt.Errorf("unsupported SECRETS_BACKEND %q", cfg.Backend)
+	}
+}
+
+// Bootstrap fetches managed secrets from the configured backend and exports
+// them into the process environment. It returns a Watcher that re-fetches on
+// an interval, or nil when secrets come from plain environment variables.
+func Bootstrap(ctx context.Context) (*Watcher, error) {
+	cfg := ConfigFromEnv()
+	provider, err := NewProvider(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if provider == nil {
+		return nil, nil
+	}
+
+	watcher := NewWatcher(provider, time.Duration(cfg.RefreshSecs)*time.Second)
+	if err := watcher.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("load secrets from %s: %w", provider.Name(), err)
+	}
+	log.Printf("Loaded secrets from %s (refresh_secs=%d)", provider.Name(), cfg.RefreshSecs)
+	return watcher, nil
+}
+
+// managedValues keeps only non-empty managed keys from a fetched payload.
+func managedValues(raw map[string]string) map[string]string {
+	out := make(map[string]string, len(ManagedKeys))
+	for _, key := range ManagedKeys {
+		if v := strings.TrimSpace(raw[key]); v != "" {
+			out[key] = v
+		}
+	}
+	return out
+}
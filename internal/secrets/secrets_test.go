@@ -0,0 +1,277 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestVaultProviderReadsKVv2(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			t.Fatalf("missing vault token header")
+		}
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"ttl": 0}})
+		case "/v1/secret/data/app":
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{
+					"data":     map[string]any{"OPENAI_API_KEY": "sk-vault"},
+					"metadata": map[string]any{"version": 3},
+				},
+			})
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := NewVaultProvider(srv.URL, VaultAuth{Token: "root"}, "", "secret/data/app", srv.Client()).Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["OPENAI_API_KEY"] != "sk-vault" {
+		t.Fatalf("expected nested kv v2 value, got %v", got)
+	}
+}
+
+func TestVaultProviderErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	if _, err := NewVaultProvider(srv.URL, VaultAuth{Token: "bad"}, "", "secret/data/app", srv.Client()).Fetch(context.Background()); err == nil {
+		t.Fatal("expected error for forbidden response")
+	}
+}
+
+func TestVaultProviderRenewsStaticToken(t *testing.T) {
+	var renewals int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"ttl": 60, "renewable": true}})
+		case "/v1/auth/token/renew-self":
+			renewals++
+			json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{"client_token": "tok", "lease_duration": 60, "renewable": true}})
+		default:
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"OPENAI_API_KEY": "sk"}})
+		}
+	}))
+	defer srv.Close()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewVaultProvider(srv.URL, VaultAuth{Token: "tok"}, "", "secret/app", srv.Client())
+	p.now = func() time.Time { return clock }
+
+	for _, advance := range []time.Duration{0, 10 * time.Second, 25 * time.Second} {
+		clock = clock.Add(advance)
+		if _, err := p.Fetch(context.Background()); err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+	}
+	if renewals != 1 {
+		t.Fatalf("expected one renewal past half the ttl, got %d", renewals)
+	}
+}
+
+func TestVaultProviderLogsInAgainWhenTokenExpires(t *testing.T) {
+	var logins int
+	revoked := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				http.Error(w, "bad credentials", http.StatusBadRequest)
+				return
+			}
+			logins++
+			json.NewEncoder(w).Encode(map[string]any{"auth": map[string]any{
+				"client_token": fmt.Sprintf("tok-%d", logins), "lease_duration": 60,
+			}})
+		default:
+			if tok := r.Header.Get("X-Vault-Token"); tok == "" || revoked[tok] {
+				http.Error(w, "permission denied", http.StatusForbidden)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"OPENAI_API_KEY": "sk"}})
+		}
+	}))
+	defer srv.Close()
+
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewVaultProvider(srv.URL, VaultAuth{RoleID: "role", SecretID: "secret"}, "", "secret/app", srv.Client())
+	p.now = func() time.Time { return clock }
+
+	if _, err := p.Fetch(context.Background()); err != nil || logins != 1 {
+		t.Fatalf("expected initial login, got logins=%d err=%v", logins, err)
+	}
+	clock = clock.Add(45 * time.Second)
+	if _, err := p.Fetch(context.Background()); err != nil || logins != 2 {
+		t.Fatalf("expected login before the lease runs out, got logins=%d err=%v", logins, err)
+	}
+	revoked["tok-2"] = true
+	if _, err := p.Fetch(context.Background()); err != nil || logins != 3 {
+		t.Fatalf("expected login after the token was rejected, got logins=%d err=%v", logins, err)
+	}
+}
+
+func TestAWSSecretsManagerProviderReadsSecretString(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			t.Fatalf("unexpected target %q", r.Header.Get("X-Amz-Target"))
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Fatalf("expected session token header")
+		}
+		writeSecretString(w, `{"DATABASE_URL":"postgres://rotated","TELEGRAM_BOT_TOKEN":"tg"}`)
+	}))
+	defer srv.Close()
+
+	creds := credentials.NewStaticCredentialsProvider("AKID", "secret", "session")
+	p := NewAWSSecretsManagerProvider(testAWSConfig(srv, creds), "app/prod", srv.URL)
+
+	got, err := p.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["DATABASE_URL"] != "postgres://rotated" || got["TELEGRAM_BOT_TOKEN"] != "tg" {
+		t.Fatalf("unexpected secret values %v", got)
+	}
+}
+
+func TestAWSSecretsManagerProviderRefreshesExpiredCredentials(t *testing.T) {
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		key := strings.TrimPrefix(strings.SplitN(auth, "/", 2)[0], "AWS4-HMAC-SHA256 Credential=")
+		keys = append(keys, key)
+		writeSecretString(w, `{"OPENAI_API_KEY":"sk"}`)
+	}))
+	defer srv.Close()
+
+	// Short-lived role credentials, as issued by IMDS or web identity.
+	var issued int
+	source := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		issued++
+		return aws.Credentials{
+			AccessKeyID:     fmt.Sprintf("ASIA%d", issued),
+			SecretAccessKey: "secret",
+			SessionToken:    "session",
+			CanExpire:       true,
+			Expires:         time.Now().Add(50 * time.Millisecond),
+		}, nil
+	})
+	p := NewAWSSecretsManagerProvider(testAWSConfig(srv, aws.NewCredentialsCache(source)), "app/prod", srv.URL)
+
+	if _, err := p.Fetch(context.Background()); err != nil {
+		t.Fatalf("first fetch: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := p.Fetch(context.Background()); err != nil {
+		t.Fatalf("fetch after expiry: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "ASIA1" || keys[1] != "ASIA2" {
+		t.Fatalf("expected the second request signed with refreshed credentials, got %v", keys)
+	}
+}
+
+func testAWSConfig(srv *httptest.Server, creds aws.CredentialsProvider) aws.Config {
+	return aws.Config{
+		Region:           "us-east-1",
+		Credentials:      creds,
+		HTTPClient:       srv.Client(),
+		RetryMaxAttempts: 1,
+	}
+}
+
+func writeSecretString(w http.ResponseWriter, secret string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(map[string]string{"SecretString": secret})
+}
+
+func TestNewProviderValidatesBackend(t *testing.T) {
+	ctx := context.Background()
+	if p, err := NewProvider(ctx, Config{Backend: BackendEnv}); err != nil || p != nil {
+		t.Fatalf("expected nil provider for env backend, got %v %v", p, err)
+	}
+	if _, err := NewProvider(ctx, Config{Backend: BackendVault, VaultAddr: "http://vault"}); err == nil {
+		t.Fatal("expected error when vault credentials are missing")
+	}
+	if p, err := NewProvider(ctx, Config{Backend: BackendVault, VaultAddr: "http://vault", VaultAuth: VaultAuth{K8sRole: "app"}}); err != nil || p == nil {
+		t.Fatalf("expected kubernetes login to satisfy vault auth, got %v %v", p, err)
+	}
+	if _, err := NewProvider(ctx, Config{Backend: BackendAWSSM, AWSSecretID: "x"}); err == nil {
+		t.Fatal("expected error when aws region is missing")
+	}
+	if _, err := NewProvider(ctx, Config{Backend: "gcp"}); err == nil {
+		t.Fatal("expected error for unsupported backend")
+	}
+}
+
+func TestWatcherExportsAndNotifiesRotation(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	provider := &stubProvider{values: map[string]string{"OPENAI_API_KEY": "sk-1", "UNRELATED": "x"}}
+	w := NewWatcher(provider, time.Minute)
+
+	var rotated []string
+	w.OnChange("OPENAI_API_KEY", func(v string) { rotated = append(rotated, v) })
+
+	if err := w.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if os.Getenv("OPENAI_API_KEY") != "sk-1" {
+		t.Fatalf("expected secret exported to env")
+	}
+	if len(rotated) != 0 {
+		t.Fatalf("initial load should not notify, got %v", rotated)
+	}
+
+	provider.values["OPENAI_API_KEY"] = "sk-2"
+	if err := w.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rotated) != 1 || rotated[0] != "sk-2" || w.Get("OPENAI_API_KEY") != "sk-2" {
+		t.Fatalf("expected rotation notification, got %v", rotated)
+	}
+
+	provider.err = errors.New("unreachable")
+	if err := w.Refresh(context.Background()); err == nil {
+		t.Fatal("expected fetch error")
+	}
+	if w.Get("OPENAI_API_KEY") != "sk-2" {
+		t.Fatal("failed refresh should keep last known value")
+	}
+}
+
+type stubProvider struct {
+	values map[string]string
+	err    error
+}
+
+func (s *stubProvider) Name() string { return "stub" }
+
+func (s *stubProvider) Fetch(context.Context) (map[string]string, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := make(map[string]string, len(s.values))
+	for k, v := range s.values {
+		out[k] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,265 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultVaultK8sTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultAuth selects how VaultProvider obtains its token. A static Token is
+// renewed with renew-self while it is renewable; AppRole and Kubernetes
+// credentials log in again whenever the token nears expiry or is rejected.
+type VaultAuth struct {
+	Token string
+
+	RoleID   string
+	SecretID string
+
+	K8sRole      string
+	K8sTokenPath string
+}
+
+func (a VaultAuth) canLogin() bool {
+	return (a.RoleID != "" && a.SecretID != "") || a.K8sRole != ""
+}
+
+// VaultProvider reads a KV secret (v1 or v2) over the Vault HTTP API.
+type VaultProvider struct {
+	addr       string
+	auth       VaultAuth
+	namespace  string
+	path       string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	renewable bool
+	checked   bool
+	// renewAt is when the token should be renewed or replaced; zero means
+	// the token does not expire.
+	renewAt time.Time
+}
+
+func NewVaultProvider(addr string, auth VaultAuth, namespace, path string, httpClient *http.Client) *VaultProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if auth.K8sRole != "" && auth.K8sTokenPath == "" {
+		auth.K8sTokenPath = defaultVaultK8sTokenPath
+	}
+	return &VaultProvider{
+		addr:       addr,
+		auth:       auth,
+		namespace:  namespace,
+		path:       path,
+		httpClient: httpClient,
+		now:        time.Now,
+		token:      auth.Token,
+	}
+}
+
+func (p *VaultProvider) Name() string { return BackendVault }
+
+func (p *VaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.ensureToken(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := p.do(ctx, http.MethodGet, "/v1/"+p.path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("vault request: %w", err)
+	}
+	if resp.StatusCode == http.StatusForbidden && p.auth.canLogin() {
+		// The token was revoked or expired early; log in once more.
+		resp.Body.Close()
+		if err := p.login(ctx); err != nil {
+			return nil, err
+		}
+		if resp, err = p.do(ctx, http.MethodGet, "/v1/"+p.path, nil); err != nil {
+			return nil, fmt.Errorf("vault request: %w", err)
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vault status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode vault response: %w", err)
+	}
+
+	// KV v2 nests the secret under data.data alongside data.metadata.
+	data := payload.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = nested
+		}
+	}
+
+	out := make(map[string]string, len(data))
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			out[k] = s
+		}
+	}
+	return out, nil
+}
+
+// ensureToken logs in, renews, or looks up the token lease as needed.
+func (p *VaultProvider) ensureToken(ctx context.Context) error {
+	if p.token == "" {
+		return p.login(ctx)
+	}
+	if !p.checked {
+		if err := p.lookupSelf(ctx); err != nil {
+			return err
+		}
+	}
+	if p.renewAt.IsZero() || p.now().Before(p.renewAt) {
+		return nil
+	}
+	if p.auth.canLogin() {
+		return p.login(ctx)
+	}
+	if !p.renewable {
+		return fmt.Errorf("vault token expired and is not renewable")
+	}
+	return p.renewSelf(ctx)
+}
+
+func (p *VaultProvider) login(ctx context.Context) error {
+	var mount string
+	var body map[string]string
+	switch {
+	case p.auth.RoleID != "" && p.auth.SecretID != "":
+		mount = "approle"
+		body = map[string]string{"role_id": p.auth.RoleID, "secret_id": p.auth.SecretID}
+	case p.auth.K8sRole != "":
+		jwt, err := os.ReadFile(p.auth.K8sTokenPath)
+		if err != nil {
+			return fmt.Errorf("read kubernetes service account token: %w", err)
+		}
+		mount = "kubernetes"
+		body = map[string]string{"role": p.auth.K8sRole, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		return fmt.Errorf("vault token expired and no login method is configured")
+	}
+
+	p.token = ""
+	auth, err := p.authCall(ctx, http.MethodPost, "/v1/auth/"+mount+"/login", body)
+	if err != nil {
+		return fmt.Errorf("vault %s login: %w", mount, err)
+	}
+	p.setLease(auth.ClientToken, auth.LeaseDuration, auth.Renewable)
+	return nil
+}
+
+func (p *VaultProvider) renewSelf(ctx context.Context) error {
+	auth, err := p.authCall(ctx, http.MethodPost, "/v1/auth/token/renew-self", map[string]string{})
+	if err != nil {
+		return fmt.Errorf("vault token renew: %w", err)
+	}
+	p.setLease(p.token, auth.LeaseDuration, auth.Renewable)
+	return nil
+}
+
+func (p *VaultProvider) lookupSelf(ctx context.Context) error {
+	resp, err := p.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", nil)
+	if err != nil {
+		return fmt.Errorf("vault token lookup: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		if p.auth.canLogin() {
+			return p.login(ctx)
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("vault token lookup status %d: %s", resp.StatusCode, string(body))
+	}
+	var payload struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("decode vault token lookup: %w", err)
+	}
+	p.setLease(p.token, payload.Data.TTL, payload.Data.Renewable)
+	return nil
+}
+
+// setLease records the token and schedules renewal at half its TTL, leaving
+// room for a missed refresh tick before the token actually expires.
+func (p *VaultProvider) setLease(token string, ttlSecs int, renewable bool) {
+	p.token = token
+	p.renewable = renewable
+	p.checked = true
+	p.renewAt = time.Time{}
+	if ttlSecs > 0 {
+		p.renewAt = p.now().Add(time.Duration(ttlSecs) * time.Second / 2)
+	}
+}
+
+type vaultAuthInfo struct {
+	ClientToken   string `json:"client_token"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
+func (p *VaultProvider) authCall(ctx context.Context, method, path string, body map[string]string) (vaultAuthInfo, error) {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return vaultAuthInfo{}, err
+	}
+	resp, err := p.do(ctx, method, path, raw)
+	if err != nil {
+		return vaultAuthInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return vaultAuthInfo{}, fmt.Errorf("status %d: %s", resp.StatusCode, string(msg))
+	}
+	var payload struct {
+		Auth vaultAuthInfo `json:"auth"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return vaultAuthInfo{}, fmt.Errorf("decode auth response: %w", err)
+	}
+	return payload.Auth, nil
+}
+
+func (p *VaultProvider) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.addr+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Vault-Token", p.token)
+	}
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	return p.httpClient.Do(req)
+}
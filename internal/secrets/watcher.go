@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Watcher keeps managed secrets in sync with a Provider and notifies
+// subscribers when a value is rotated.
+type Watcher struct {
+	provider Provider
+	interval time.Duration
+
+	mu       sync.RWMutex
+	values   map[string]string
+	handlers map[string][]func(string)
+}
+
+func NewWatcher(provider Provider, interval time.Duration) *Watcher {
+	return &Watcher{
+		provider: provider,
+		interval: interval,
+		values:   make(map[string]string),
+		handlers: make(map[string][]func(string)),
+	}
+}
+
+// Get returns the most recently fetched value for key.
+func (w *Watcher) Get(key string) string {
+	if w == nil {
+		return ""
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.values[key]
+}
+
+// OnChange registers fn to be called with the new value whenever key rotates.
+func (w *Watcher) OnChange(key string, fn func(string)) {
+	if w == nil || fn == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[key] = append(w.handlers[key], fn)
+}
+
+// Refresh fetches secrets once, exports managed keys into the environment and
+// fires change handlers for values that differ from the previous fetch.
+func (w *Watcher) Refresh(ctx context.Context) error {
+	raw, err := w.provider.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	fetched := managedValues(raw)
+
+	type change struct {
+		value    string
+		handlers []func(string)
+	}
+	var changes []change
+
+	w.mu.Lock()
+	for key, value := range fetched {
+		prev, seen := w.values[key]
+		if seen && prev == value {
+			continue
+		}
+		w.values[key] = value
+		os.Setenv(key, value)
+		if seen {
+			changes = append(changes, change{value: value, handlers: append([]func(string){}, w.handlers[key]...)})
+			log.Printf("Secret %s rotated via %s", key, w.provider.Name())
+		}
+	}
+	w.mu.Unlock()
+
+	for _, c := range changes {
+		for _, fn := range c.handlers {
+			fn(c.value)
+		}
+	}
+	return nil
+}
+
+// Start re-fetches secrets every interval until ctx is cancelled. A zero
+// interval disables periodic refresh.
+func (w *Watcher) Start(ctx context.Context) {
+	if w == nil || w.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Refresh(ctx); err != nil {
+				log.Printf("secrets refresh error: %v", err)
+			}
+		}
+	}
+}
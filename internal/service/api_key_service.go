@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, tenantID, label, rawKey string) (*repository.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	ListAPIKeys(ctx context.Context, tenantID string) ([]repository.APIKey, error)
+}
+
+// APIKeyService issues and revokes tenant-bound REST API keys.
+type APIKeyService struct {
+	tracer trace.Tracer
+	store  APIKeyStore
+	audit  *AuditService
+}
+
+func NewAPIKeyService(tracer trace.Tracer, store APIKeyStore, audit *AuditService) *APIKeyService {
+	return &APIKeyService{tracer: tracer, store: store, audit: audit}
+}
+
+// Issue creates a new key for tenantID and returns the raw key. The raw key is
+// only available at creation time; the store keeps a hash.
+func (s *APIKeyService) Issue(ctx context.Context, tenantID, label string) (string, *repository.APIKey, error) {
+	ctx, span := s.tracer.Start(ctx, "api-key-service.issue")
+	defer span.End()
+	if s.store == nil {
+		return "", nil, fmt.Errorf("api key service unavailable")
+	}
+
+	rawKey, err := newRawAPIKey()
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := s.store.CreateAPIKey(ctx, tenantID, label, rawKey)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionAPIKeyCreate, domain.AuditEntityAPIKey,
+		strconv.FormatInt(key.ID, 10), nil, key); err != nil {
+		span.RecordError(err)
+	}
+	return rawKey, key, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id int64) error {
+	ctx, span := s.tracer.Start(ctx, "api-key-service.revoke")
+	defer span.End()
+	if s.store == nil {
+		return fmt.Errorf("api key service unavailable")
+	}
+
+	if err := s.store.RevokeAPIKey(ctx, id); err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionAPIKeyRevoke, domain.AuditEntityAPIKey,
+		strconv.FormatInt(id, 10), map[string]any{"revoked": false}, map[string]any{"revoked": true}); err != nil {
+		span.RecordError(err)
+	}
+	return nil
+}
+
+// Rotate revokes the active keys labelled label for tenantID and issues a
+// replacement, so a self-service tenant holds at most one key per label.
+func (s *APIKeyService) Rotate(ctx context.Context, tenantID, label string) (string, *repository.APIKey, error) {
+	ctx, span := s.tracer.Start(ctx, "api-key-service.rotate")
+	defer span.End()
+
+	if _, err := s.revokeActive(ctx, tenantID, label); err != nil {
+		return "", nil, err
+	}
+	return s.Issue(ctx, tenantID, label)
+}
+
+// RevokeTenant revokes every active key of tenantID and returns how many were
+// revoked.
+func (s *APIKeyService) RevokeTenant(ctx context.Context, tenantID string) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "api-key-service.revoke-tenant")
+	defer span.End()
+	return s.revokeActive(ctx, tenantID, "")
+}
+
+func (s *APIKeyService) revokeActive(ctx context.Context, tenantID, label string) (int, error) {
+	keys, err := s.List(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	revoked := 0
+	for _, key := range keys {
+		if key.RevokedAt != nil || (label != "" && key.Label != label) {
+			continue
+		}
+		if err := s.Revoke(ctx, key.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+func (s *APIKeyService) List(ctx context.Context, tenantID string) ([]repository.APIKey, error) {
+	_, span := s.tracer.Start(ctx, "api-key-service.list")
+	defer span.End()
+	if s.store == nil {
+		return nil, fmt.Errorf("api key service unavailable")
+	}
+	return s.store.ListAPIKeys(ctx, tenantID)
+}
+
+func newRawAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "bfu_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
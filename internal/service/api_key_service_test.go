@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type apiKeyStoreStub struct {
+	rawKeys []string
+	revoked []int64
+	keys    []repository.APIKey
+}
+
+func (s *apiKeyStoreStub) CreateAPIKey(ctx context.Context, tenantID, label, rawKey string) (*repository.APIKey, error) {
+	s.rawKeys = append(s.rawKeys, rawKey)
+	return &repository.APIKey{ID: int64(len(s.rawKeys)), TenantID: tenantID, Label: label}, nil
+}
+
+func (s *apiKeyStoreStub) RevokeAPIKey(ctx context.Context, id int64) error {
+	s.revoked = append(s.revoked, id)
+	return nil
+}
+
+func (s *apiKeyStoreStub) ListAPIKeys(ctx context.Context, tenantID string) ([]repository.APIKey, error) {
+	return s.keys, nil
+}
+
+func TestAPIKeyServiceIssueAndRevokeAreAudited(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	store := &apiKeyStoreStub{}
+	audit := &auditStoreStub{}
+	svc := NewAPIKeyService(tracer, store, NewAuditService(tracer, audit))
+
+	rawKey, key, err := svc.Issue(context.Background(), "acme", "ci")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(rawKey, "bfu_") || len(rawKey) < 40 {
+		t.Fatalf("unexpected raw key format: %q", rawKey)
+	}
+	if key.TenantID != "acme" || store.rawKeys[0] != rawKey {
+		t.Fatalf("unexpected stored key: %+v", key)
+	}
+	if strings.Contains(audit.entries[0].AfterJSON, rawKey) {
+		t.Fatal("raw key must not be written to the audit log")
+	}
+
+	if err := svc.Revoke(context.Background(), key.ID); err != nil {
+		t.Fatalf("unexpected revoke error: %v", err)
+	}
+	if len(audit.entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(audit.entries))
+	}
+	if audit.entries[0].Action != domain.AuditActionAPIKeyCreate || audit.entries[1].Action != domain.AuditActionAPIKeyRevoke {
+		t.Fatalf("unexpected audit actions: %+v", audit.entries)
+	}
+}
+
+func TestAPIKeyServiceRotateRevokesActiveKeysWithLabel(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	revokedAt := time.Now()
+	store := &apiKeyStoreStub{keys: []repository.APIKey{
+		{ID: 10, TenantID: "telegram-1", Label: "telegram"},
+		{ID: 11, TenantID: "telegram-1", Label: "telegram", RevokedAt: &revokedAt},
+		{ID: 12, TenantID: "telegram-1", Label: "ci"},
+	}}
+	svc := NewAPIKeyService(tracer, store, nil)
+
+	rawKey, key, err := svc.Rotate(context.Background(), "telegram-1", "telegram")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rawKey == "" || key.Label != "telegram" {
+		t.Fatalf("unexpected key: %q %+v", rawKey, key)
+	}
+	if len(store.revoked) != 1 || store.revoked[0] != 10 {
+		t.Fatalf("expected only key 10 revoked, got %v", store.revoked)
+	}
+
+	store.revoked = nil
+	count, err := svc.RevokeTenant(context.Background(), "telegram-1")
+	if err != nil {
+		t.Fatalf("unexpected revoke error: %v", err)
+	}
+	if count != 2 || len(store.revoked) != 2 {
+		t.Fatalf("expected keys 10 and 12 revoked, got %d %v", count, store.revoked)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuditStore interface {
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) (*domain.AuditEntry, error)
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AuditService records administrative changes to the append-only audit log.
+type AuditService struct {
+	tracer trace.Tracer
+	store  AuditStore
+}
+
+func NewAuditService(tracer trace.Tracer, store AuditStore) *AuditService {
+	return &AuditService{tracer: tracer, store: store}
+}
+
+// Record appends an audit entry attributed to the actor and tenant bound to ctx.
+// before and after are serialized as JSON; nil values are stored as NULL.
+func (s *AuditService) Record(ctx context.Context, action, entityType, entityID string, before, after any) error {
+	if s == nil || s.store == nil {
+		return nil
+	}
+	ctx, span := s.tracer.Start(ctx, "audit-service.record")
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("audit.action", action),
+		attribute.String("audit.entity_type", entityType),
+	)
+
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return fmt.Errorf("marshal audit before state: %w", err)
+	}
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return fmt.Errorf("marshal audit after state: %w", err)
+	}
+
+	_, err = s.store.AppendAuditEntry(ctx, domain.AuditEntry{
+		TenantID:   domain.TenantFromContext(ctx),
+		Actor:      domain.ActorFromContext(ctx),
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		BeforeJSON: beforeJSON,
+		AfterJSON:  afterJSON,
+	})
+	if err != nil {
+		span.RecordError(err)
+	}
+	return err
+}
+
+func (s *AuditService) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	_, span := s.tracer.Start(ctx, "audit-service.list")
+	defer span.End()
+	if s.store == nil {
+		return nil, fmt.Errorf("audit service unavailable")
+	}
+	return s.store.ListAuditEntries(ctx, filter)
+}
+
+func auditJSON(v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	if raw, ok := v.(string); ok {
+		return raw, nil
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
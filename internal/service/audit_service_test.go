@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type auditStoreStub struct {
+	entries []domain.AuditEntry
+	filter  domain.AuditFilter
+}
+
+func (s *auditStoreStub) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) (*domain.AuditEntry, error) {
+	entry.ID = int64(len(s.entries) + 1)
+	s.entries = append(s.entries, entry)
+	return &entry, nil
+}
+
+func (s *auditStoreStub) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	s.filter = filter
+	return s.entries, nil
+}
+
+func TestAuditServiceRecordUsesContextActorAndTenant(t *testing.T) {
+	store := &auditStoreStub{}
+	svc := NewAuditService(trace.NewNoopTracerProvider().Tracer("test"), store)
+	ctx := domain.WithActor(domain.WithTenant(context.Background(), "acme"), "api:acme")
+
+	err := svc.Record(ctx, domain.AuditActionAPIKeyRevoke, domain.AuditEntityAPIKey, "3",
+		map[string]any{"revoked": false}, map[string]any{"revoked": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(store.entries))
+	}
+	got := store.entries[0]
+	if got.TenantID != "acme" || got.Actor != "api:acme" || got.EntityID != "3" {
+		t.Fatalf("unexpected entry: %+v", got)
+	}
+	if got.BeforeJSON != `{"revoked":false}` || got.AfterJSON != `{"revoked":true}` {
+		t.Fatalf("unexpected before/after JSON: %q %q", got.BeforeJSON, got.AfterJSON)
+	}
+}
+
+func TestAuditServiceRecordDefaultsToSystemActor(t *testing.T) {
+	store := &auditStoreStub{}
+	svc := NewAuditService(trace.NewNoopTracerProvider().Tracer("test"), store)
+
+	if err := svc.Record(context.Background(), domain.AuditActionModelActivate, domain.AuditEntityModel, "logreg", nil, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.entries[0].Actor != domain.SystemActor || store.entries[0].BeforeJSON != "" {
+		t.Fatalf("unexpected entry: %+v", store.entries[0])
+	}
+}
+
+func TestAuditServiceNilIsNoop(t *testing.T) {
+	var svc *AuditService
+	if err := svc.Record(context.Background(), "x", "y", "z", nil, nil); err != nil {
+		t.Fatalf("expected nil service to be a no-op, got %v", err)
+	}
+}
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/internal/ml/ensemble"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -14,17 +18,44 @@ type BacktestRepository interface {
 	GetDailyAccuracy(ctx context.Context, modelKey string, days int) ([]repository.DailyAccuracy, error)
 	GetAccuracySummary(ctx context.Context) ([]repository.DailyAccuracy, error)
 	ListRecentPredictions(ctx context.Context, limit int) ([]domain.MLPrediction, error)
+	GetCalibration(ctx context.Context, modelKey string, buckets int) ([]domain.CalibrationBucket, error)
+	GetRiskLevelStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error)
+	GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error)
+	GetDailyCoverage(ctx context.Context, modelKey string, days int) ([]domain.CoverageDay, error)
+	GetPrediction(ctx context.Context, id int64) (*domain.MLPrediction, error)
+}
+
+// ErrBreakdownUnavailable is returned by GetPredictionBreakdown for
+// predictions that are not ensemble calls, or that were stored before the
+// ensemble components were recorded in their details.
+var ErrBreakdownUnavailable = errors.New("prediction has no ensemble breakdown")
+
+// PredictionBreakdown is an ensemble prediction with the contribution of
+// each component to its score.
+type PredictionBreakdown struct {
+	Prediction domain.MLPrediction `json:"prediction"`
+	ensemble.ScoreBreakdown
+}
+
+type CalibrationRenderer interface {
+	RenderCalibrationChart(cal domain.Calibration) ([]byte, error)
 }
 
 type BacktestService struct {
-	tracer trace.Tracer
-	repo   BacktestRepository
+	tracer   trace.Tracer
+	repo     BacktestRepository
+	renderer CalibrationRenderer
 }
 
 func NewBacktestService(tracer trace.Tracer, repo BacktestRepository) *BacktestService {
 	return &BacktestService{tracer: tracer, repo: repo}
 }
 
+// SetCalibrationRenderer enables RenderCalibration.
+func (s *BacktestService) SetCalibrationRenderer(r CalibrationRenderer) {
+	s.renderer = r
+}
+
 func (s *BacktestService) GetSummary(ctx context.Context) ([]repository.DailyAccuracy, error) {
 	_, span := s.tracer.Start(ctx, "backtest-service.get-summary")
 	defer span.End()
@@ -51,3 +82,95 @@ func (s *BacktestService) GetPredictions(ctx context.Context, limit int) ([]doma
 	}
 	return s.repo.ListRecentPredictions(ctx, limit)
 }
+
+func (s *BacktestService) GetRiskStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error) {
+	_, span := s.tracer.Start(ctx, "backtest-service.get-risk-stats")
+	defer span.End()
+	if s.repo == nil {
+		return nil, fmt.Errorf("backtest service unavailable")
+	}
+	return s.repo.GetRiskLevelStats(ctx, days)
+}
+
+func (s *BacktestService) GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error) {
+	_, span := s.tracer.Start(ctx, "backtest-service.get-anomaly-heat")
+	defer span.End()
+	if s.repo == nil {
+		return nil, fmt.Errorf("backtest service unavailable")
+	}
+	return s.repo.GetAnomalyHeat(ctx, days, interval)
+}
+
+// GetCoverage returns daily directional coverage and directional accuracy,
+// newest day first.
+func (s *BacktestService) GetCoverage(ctx context.Context, modelKey string, days int) ([]domain.CoverageDay, error) {
+	_, span := s.tracer.Start(ctx, "backtest-service.get-coverage")
+	defer span.End()
+	if s.repo == nil {
+		return nil, fmt.Errorf("backtest service unavailable")
+	}
+	return s.repo.GetDailyCoverage(ctx, modelKey, days)
+}
+
+func (s *BacktestService) GetCalibration(ctx context.Context, modelKey string, buckets int) (domain.Calibration, error) {
+	_, span := s.tracer.Start(ctx, "backtest-service.get-calibration")
+	defer span.End()
+	if s.repo == nil {
+		return domain.Calibration{}, fmt.Errorf("backtest service unavailable")
+	}
+	rows, err := s.repo.GetCalibration(ctx, modelKey, buckets)
+	if err != nil {
+		return domain.Calibration{}, err
+	}
+	return domain.NewCalibration(modelKey, rows), nil
+}
+
+// RenderCalibration draws cal as a PNG reliability chart.
+func (s *BacktestService) RenderCalibration(cal domain.Calibration) ([]byte, error) {
+	if s.renderer == nil {
+		return nil, fmt.Errorf("calibration chart unavailable")
+	}
+	return s.renderer.RenderCalibrationChart(cal)
+}
+
+// GetPredictionBreakdown rebuilds the ensemble score of prediction id from
+// the components stored in its details.
+func (s *BacktestService) GetPredictionBreakdown(ctx context.Context, id int64) (*PredictionBreakdown, error) {
+	ctx, span := s.tracer.Start(ctx, "backtest-service.get-prediction-breakdown")
+	defer span.End()
+	if s.repo == nil {
+		return nil, fmt.Errorf("backtest service unavailable")
+	}
+	pred, err := s.repo.GetPrediction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pred.ModelKey != common.ModelKeyEnsembleV1 {
+		return nil, ErrBreakdownUnavailable
+	}
+	var details struct {
+		ClassicScore *float64 `json:"classic_score"`
+		LogRegProb   *float64 `json:"logreg_prob"`
+		XGBoostProb  *float64 `json:"xgboost_prob"`
+		FearGreed    *int     `json:"fear_greed"`
+		AnomalyScore float64  `json:"anomaly_score"`
+		DampFactor   *float64 `json:"damp_factor"`
+	}
+	if err := json.Unmarshal([]byte(pred.DetailsJSON), &details); err != nil {
+		return nil, ErrBreakdownUnavailable
+	}
+	if details.ClassicScore == nil || details.LogRegProb == nil || details.XGBoostProb == nil {
+		return nil, ErrBreakdownUnavailable
+	}
+	dampFactor := 1.0
+	if details.DampFactor != nil {
+		dampFactor = *details.DampFactor
+	}
+	breakdown := ensemble.NewService().Breakdown(ensemble.Components{
+		ClassicScore: *details.ClassicScore,
+		LogRegProb:   *details.LogRegProb,
+		XGBoostProb:  *details.XGBoostProb,
+		FearGreed:    details.FearGreed,
+	}, details.AnomalyScore, dampFactor)
+	return &PredictionBreakdown{Prediction: *pred, ScoreBreakdown: breakdown}, nil
+}
@@ -3,10 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -15,6 +16,9 @@ type backtestRepoStub struct {
 	summaryErr error
 	dailyErr   error
 	predErr    error
+	calErr     error
+	riskErr    error
+	prediction *domain.MLPrediction
 }
 
 func (s backtestRepoStub) GetDailyAccuracy(ctx context.Context, modelKey string, days int) ([]repository.DailyAccuracy, error) {
@@ -38,6 +42,44 @@ func (s backtestRepoStub) ListRecentPredictions(ctx context.Context, limit int)
 	return []domain.MLPrediction{{ModelKey: "ml", Symbol: "BTC"}}, nil
 }
 
+func (s backtestRepoStub) GetCalibration(ctx context.Context, modelKey string, buckets int) ([]domain.CalibrationBucket, error) {
+	if s.calErr != nil {
+		return nil, s.calErr
+	}
+	return []domain.CalibrationBucket{
+		{Lower: 0.5, Upper: 0.6, Count: 10, MeanPredicted: 0.55, RealizedFrequency: 0.6},
+	}, nil
+}
+
+func (s backtestRepoStub) GetRiskLevelStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error) {
+	if s.riskErr != nil {
+		return nil, s.riskErr
+	}
+	return []domain.RiskLevelStats{{ModelKey: "ml", Risk: domain.RiskLevel3, Total: 10, Correct: 6, HitRate: 0.6}}, nil
+}
+
+func (s backtestRepoStub) GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error) {
+	return []domain.AnomalyCell{{Symbol: "BTC", Samples: 24, MaxScore: 0.7}}, nil
+}
+
+func (s backtestRepoStub) GetDailyCoverage(ctx context.Context, modelKey string, days int) ([]domain.CoverageDay, error) {
+	return []domain.CoverageDay{{ModelKey: "ml", Total: 10, Directional: 4, Coverage: 0.4}}, nil
+}
+
+func (s backtestRepoStub) GetPrediction(ctx context.Context, id int64) (*domain.MLPrediction, error) {
+	if s.prediction == nil || s.prediction.ID != id {
+		return nil, errors.New("no rows in result set")
+	}
+	return s.prediction, nil
+}
+
+type calibrationRendererStub struct{ got domain.Calibration }
+
+func (r *calibrationRendererStub) RenderCalibrationChart(cal domain.Calibration) ([]byte, error) {
+	r.got = cal
+	return []byte("png"), nil
+}
+
 func TestBacktestServiceGetSummary(t *testing.T) {
 	svc := NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{})
 	items, err := svc.GetSummary(context.Background())
@@ -55,3 +97,91 @@ func TestBacktestServiceGetSummaryError(t *testing.T) {
 		t.Fatal("expected error")
 	}
 }
+
+func TestBacktestServiceGetCalibration(t *testing.T) {
+	svc := NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{})
+	cal, err := svc.GetCalibration(context.Background(), "ensemble_v1", 10)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if cal.ModelKey != "ensemble_v1" || cal.Total != 10 || len(cal.Buckets) != 1 {
+		t.Fatalf("unexpected calibration %+v", cal)
+	}
+}
+
+func TestBacktestServiceGetCalibrationError(t *testing.T) {
+	svc := NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{calErr: errors.New("boom")})
+	if _, err := svc.GetCalibration(context.Background(), "", 10); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBacktestServiceRenderCalibration(t *testing.T) {
+	svc := NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{})
+	cal := domain.Calibration{Total: 10}
+	if _, err := svc.RenderCalibration(cal); err == nil {
+		t.Fatal("expected error without a renderer")
+	}
+
+	renderer := &calibrationRendererStub{}
+	svc.SetCalibrationRenderer(renderer)
+	png, err := svc.RenderCalibration(cal)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if string(png) != "png" || renderer.got.Total != 10 {
+		t.Fatalf("unexpected render %q for %+v", png, renderer.got)
+	}
+}
+
+func TestBacktestServiceGetRiskStats(t *testing.T) {
+	svc := NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{})
+	stats, err := svc.GetRiskStats(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(stats) != 1 || stats[0].Risk != domain.RiskLevel3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	svc = NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{riskErr: errors.New("boom")})
+	if _, err := svc.GetRiskStats(context.Background(), 30); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBacktestServiceGetPredictionBreakdown(t *testing.T) {
+	pred := &domain.MLPrediction{
+		ID:          7,
+		ModelKey:    "ensemble_v1",
+		DetailsJSON: `{"ensemble_score":0.2055,"classic_score":0.5,"logreg_prob":0.7,"xgboost_prob":0.6,"fear_greed":25,"anomaly_score":0.8,"damp_factor":0.75}`,
+	}
+	svc := NewBacktestService(trace.NewNoopTracerProvider().Tracer("test"), backtestRepoStub{prediction: pred})
+
+	out, err := svc.GetPredictionBreakdown(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Prediction.ID != 7 || len(out.Components) != 5 {
+		t.Fatalf("unexpected breakdown: %+v", out)
+	}
+	sum := 0.0
+	for _, c := range out.Components {
+		sum += c.Contribution
+	}
+	if math.Abs(sum-out.Score) > 1e-9 || math.Abs(out.Score-0.2055) > 1e-9 {
+		t.Fatalf("expected contributions to sum to 0.2055, got sum %.4f score %.4f", sum, out.Score)
+	}
+	if damp := out.Components[4]; damp.Component != "anomaly_damp" || damp.Value != 0.8 || damp.Weight != 0.75 {
+		t.Fatalf("unexpected anomaly damp entry: %+v", damp)
+	}
+
+	pred.DetailsJSON = `{"ensemble_score":0.2}`
+	if _, err := svc.GetPredictionBreakdown(context.Background(), 7); !errors.Is(err, ErrBreakdownUnavailable) {
+		t.Fatalf("expected ErrBreakdownUnavailable for details without components, got %v", err)
+	}
+	pred.ModelKey = "ml_logreg_up4h"
+	if _, err := svc.GetPredictionBreakdown(context.Background(), 7); !errors.Is(err, ErrBreakdownUnavailable) {
+		t.Fatalf("expected ErrBreakdownUnavailable for a single model, got %v", err)
+	}
+}
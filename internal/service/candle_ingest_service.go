@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxIngestCandles caps the candles accepted in one ingest request.
+const MaxIngestCandles = 1000
+
+// maxIngestProblems caps the validation problems reported for one request.
+const maxIngestProblems = 20
+
+// ErrInvalidCandles is returned by CandleIngestService.Ingest when the batch
+// fails validation; nothing is stored in that case.
+var ErrInvalidCandles = errors.New("invalid candles")
+
+type CandleIngestStore interface {
+	IngestCandles(ctx context.Context, candles []*domain.Candle) (int, error)
+}
+
+// CandleSymbolStore is implemented by candle stores that can tell which
+// symbols have candles stored, including symbols only known through the
+// ingest API.
+type CandleSymbolStore interface {
+	HasSymbol(ctx context.Context, symbol string) (bool, error)
+	ListSymbols(ctx context.Context) ([]string, error)
+}
+
+// CandleEventPublisher receives a candle-closed event for ingested candles so
+// signal generation and ML inference run on them like on polled candles.
+type CandleEventPublisher interface {
+	PublishCandleClosed(evt events.CandleClosed)
+}
+
+// CandleIngestResult summarises one ingest request.
+type CandleIngestResult struct {
+	Received   int `json:"received"`
+	Stored     int `json:"stored"`
+	Duplicates int `json:"duplicates"`
+}
+
+// CandleIngestService stores OHLCV candles pushed by external collectors for
+// symbols the built-in price provider does not track.
+type CandleIngestService struct {
+	tracer trace.Tracer
+	store  CandleIngestStore
+	events CandleEventPublisher
+	now    func() time.Time
+}
+
+func NewCandleIngestService(tracer trace.Tracer, store CandleIngestStore) *CandleIngestService {
+	return &CandleIngestService{tracer: tracer, store: store, now: time.Now}
+}
+
+// SetCandleEvents publishes a candle-closed event for the newest closed
+// candle of each ingested symbol and interval.
+func (s *CandleIngestService) SetCandleEvents(publisher CandleEventPublisher) {
+	s.events = publisher
+}
+
+// Ingest validates candles and stores them. The whole batch is rejected with
+// ErrInvalidCandles if any candle is invalid. Repeated candles, within the
+// batch or already stored with the same values, count as duplicates.
+func (s *CandleIngestService) Ingest(ctx context.Context, candles []domain.Candle) (CandleIngestResult, error) {
+	ctx, span := s.tracer.Start(ctx, "candle-ingest-service.ingest")
+	defer span.End()
+
+	result := CandleIngestResult{Received: len(candles)}
+	if s.store == nil {
+		return result, fmt.Errorf("candle ingest is not configured")
+	}
+	if len(candles) == 0 {
+		return result, fmt.Errorf("%w: no candles", ErrInvalidCandles)
+	}
+	if len(candles) > MaxIngestCandles {
+		return result, fmt.Errorf("%w: %d candles exceeds the limit of %d", ErrInvalidCandles, len(candles), MaxIngestCandles)
+	}
+
+	now := s.now().UTC()
+	var problems []string
+	byKey := make(map[string]*domain.Candle, len(candles))
+	unique := make([]*domain.Candle, 0, len(candles))
+	for i := range candles {
+		c := candles[i]
+		c.Symbol = strings.ToUpper(strings.TrimSpace(c.Symbol))
+		c.Interval = strings.TrimSpace(c.Interval)
+		c.OpenTime = c.OpenTime.UTC()
+		if err := validateIngestCandle(c, now); err != nil {
+			problems = append(problems, fmt.Sprintf("candle %d: %v", i, err))
+			continue
+		}
+		key := c.Symbol + "|" + c.Interval + "|" + c.OpenTime.Format(time.RFC3339)
+		if prev, ok := byKey[key]; ok {
+			if *prev != c {
+				problems = append(problems, fmt.Sprintf("candle %d: conflicts with an earlier candle for %s %s at %s", i, c.Symbol, c.Interval, c.OpenTime.Format(time.RFC3339)))
+			}
+			continue
+		}
+		byKey[key] = &c
+		unique = append(unique, &c)
+	}
+	if len(problems) > 0 {
+		if len(problems) > maxIngestProblems {
+			problems = append(problems[:maxIngestProblems], fmt.Sprintf("and %d more", len(problems)-maxIngestProblems))
+		}
+		return result, fmt.Errorf("%w: %s", ErrInvalidCandles, strings.Join(problems, "; "))
+	}
+
+	stored, err := s.store.IngestCandles(ctx, unique)
+	if err != nil {
+		return result, fmt.Errorf("store candles: %w", err)
+	}
+	result.Stored = stored
+	result.Duplicates = result.Received - stored
+
+	if stored > 0 {
+		s.publishClosed(unique, now)
+	}
+	return result, nil
+}
+
+func validateIngestCandle(c domain.Candle, now time.Time) error {
+	if domain.IsSupportedSymbol(c.Symbol) {
+		return fmt.Errorf("symbol %s is tracked by the built-in price provider", c.Symbol)
+	}
+	return domain.ValidateCandle(c, now)
+}
+
+// publishClosed emits one event per symbol and interval for the newest
+// candle in the batch that has closed by now.
+func (s *CandleIngestService) publishClosed(candles []*domain.Candle, now time.Time) {
+	if s.events == nil {
+		return
+	}
+	newest := make(map[string]*domain.Candle)
+	for _, c := range candles {
+		if c.OpenTime.Add(domain.IntervalDuration(c.Interval)).After(now) {
+			continue
+		}
+		key := c.Symbol + "|" + c.Interval
+		if prev, ok := newest[key]; !ok || c.OpenTime.After(prev.OpenTime) {
+			newest[key] = c
+		}
+	}
+	keys := make([]string, 0, len(newest))
+	for key := range newest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		c := newest[key]
+		s.events.PublishCandleClosed(events.CandleClosed{
+			Symbol:    c.Symbol,
+			Interval:  c.Interval,
+			OpenTime:  c.OpenTime,
+			CloseTime: c.OpenTime.Add(domain.IntervalDuration(c.Interval)),
+		})
+	}
+}
+
+// knownSymbol reports whether symbol is tracked by the price provider or, if
+// repo can tell, has candles stored through the ingest API.
+func knownSymbol(ctx context.Context, repo any, symbol string) bool {
+	if domain.IsSupportedSymbol(symbol) {
+		return true
+	}
+	store, ok := repo.(CandleSymbolStore)
+	if !ok {
+		return false
+	}
+	exists, err := store.HasSymbol(ctx, symbol)
+	return err == nil && exists
+}
+
+// candleSymbols returns the tracked symbols followed by any other symbols
+// with stored candles.
+func candleSymbols(ctx context.Context, repo any) []string {
+	symbols := domain.SupportedSymbols()
+	store, ok := repo.(CandleSymbolStore)
+	if !ok {
+		return symbols
+	}
+	stored, err := store.ListSymbols(ctx)
+	if err != nil {
+		return symbols
+	}
+	for _, symbol := range stored {
+		if !domain.IsSupportedSymbol(symbol) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type candleIngestStoreStub struct {
+	stored   map[string]domain.Candle
+	symbols  []string
+	batches  int
+	ingestFn func([]*domain.Candle) (int, error)
+}
+
+func (s *candleIngestStoreStub) IngestCandles(ctx context.Context, candles []*domain.Candle) (int, error) {
+	s.batches++
+	if s.ingestFn != nil {
+		return s.ingestFn(candles)
+	}
+	if s.stored == nil {
+		s.stored = make(map[string]domain.Candle)
+	}
+	changed := 0
+	for _, c := range candles {
+		key := c.Symbol + c.Interval + c.OpenTime.String()
+		if prev, ok := s.stored[key]; ok && prev == *c {
+			continue
+		}
+		s.stored[key] = *c
+		changed++
+	}
+	return changed, nil
+}
+
+func (s *candleIngestStoreStub) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
+	return nil, nil
+}
+
+func (s *candleIngestStoreStub) HasSymbol(ctx context.Context, symbol string) (bool, error) {
+	for _, stored := range s.symbols {
+		if stored == symbol {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *candleIngestStoreStub) ListSymbols(ctx context.Context) ([]string, error) {
+	return s.symbols, nil
+}
+
+type candleEventsStub struct {
+	events []events.CandleClosed
+}
+
+func (s *candleEventsStub) PublishCandleClosed(evt events.CandleClosed) {
+	s.events = append(s.events, evt)
+}
+
+func newTestIngestService(store CandleIngestStore, now time.Time) *CandleIngestService {
+	svc := NewCandleIngestService(trace.NewNoopTracerProvider().Tracer("test"), store)
+	svc.now = func() time.Time { return now }
+	return svc
+}
+
+func ingestCandle(symbol string, openTime time.Time, close float64) domain.Candle {
+	return domain.Candle{Symbol: symbol, Interval: "1h", OpenTime: openTime, Open: 1, High: 2, Low: 0.5, Close: close, Volume: 100}
+}
+
+func TestCandleIngestStoresAndPublishesClosedCandles(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	store := &candleIngestStoreStub{}
+	bus := &candleEventsStub{}
+	svc := newTestIngestService(store, now)
+	svc.SetCandleEvents(bus)
+
+	batch := []domain.Candle{
+		ingestCandle(" pepe ", now.Add(-3*time.Hour).Truncate(time.Hour), 1.1),
+		ingestCandle("PEPE", now.Add(-2*time.Hour).Truncate(time.Hour), 1.2),
+		ingestCandle("PEPE", now.Add(-2*time.Hour).Truncate(time.Hour), 1.2), // repeated in batch
+		ingestCandle("PEPE", now.Truncate(time.Hour), 1.3),                   // still open
+	}
+	result, err := svc.Ingest(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if result.Received != 4 || result.Stored != 3 || result.Duplicates != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	wantOpen := now.Add(-2 * time.Hour).Truncate(time.Hour)
+	if len(bus.events) != 1 || bus.events[0].Symbol != "PEPE" || !bus.events[0].OpenTime.Equal(wantOpen) || !bus.events[0].CloseTime.Equal(wantOpen.Add(time.Hour)) {
+		t.Fatalf("expected one event for the newest closed candle, got %+v", bus.events)
+	}
+
+	// Re-sending the same window stores nothing and publishes nothing.
+	result, err = svc.Ingest(context.Background(), batch[:2])
+	if err != nil || result.Stored != 0 || result.Duplicates != 2 {
+		t.Fatalf("unexpected resend result %+v err=%v", result, err)
+	}
+	if len(bus.events) != 1 {
+		t.Fatalf("expected no event for unchanged candles, got %d", len(bus.events))
+	}
+}
+
+func TestCandleIngestRejectsInvalidBatch(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hour := now.Add(-time.Hour)
+	cases := map[string]domain.Candle{
+		"tracked symbol":     ingestCandle("BTC", hour, 1.5),
+		"bad symbol":         ingestCandle("PE-PE", hour, 1.5),
+		"unsupported":        {Symbol: "PEPE", Interval: "2h", OpenTime: hour, Open: 1, High: 2, Low: 0.5, Close: 1.5},
+		"unaligned":          ingestCandle("PEPE", hour.Add(time.Minute), 1.5),
+		"future":             ingestCandle("PEPE", now.Add(time.Hour), 1.5),
+		"non-positive price": ingestCandle("PEPE", hour, 0),
+		"high below close":   ingestCandle("PEPE", hour, 3),
+		"negative volume":    {Symbol: "PEPE", Interval: "1h", OpenTime: hour, Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: -1},
+	}
+	for name, candle := range cases {
+		t.Run(name, func(t *testing.T) {
+			store := &candleIngestStoreStub{}
+			svc := newTestIngestService(store, now)
+			batch := []domain.Candle{ingestCandle("PEPE", hour.Add(-time.Hour), 1.5), candle}
+			if _, err := svc.Ingest(context.Background(), batch); !errors.Is(err, ErrInvalidCandles) {
+				t.Fatalf("expected ErrInvalidCandles, got %v", err)
+			}
+			if store.batches != 0 {
+				t.Fatal("expected nothing stored for an invalid batch")
+			}
+		})
+	}
+}
+
+func TestCandleIngestRejectsConflictsAndOversizedBatches(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc := newTestIngestService(&candleIngestStoreStub{}, now)
+	hour := now.Add(-time.Hour)
+
+	_, err := svc.Ingest(context.Background(), []domain.Candle{ingestCandle("PEPE", hour, 1.5), ingestCandle("PEPE", hour, 1.6)})
+	if !errors.Is(err, ErrInvalidCandles) || !strings.Contains(err.Error(), "conflicts") {
+		t.Fatalf("expected conflicting duplicate rejected, got %v", err)
+	}
+	if _, err := svc.Ingest(context.Background(), make([]domain.Candle, MaxIngestCandles+1)); !errors.Is(err, ErrInvalidCandles) {
+		t.Fatalf("expected oversized batch rejected, got %v", err)
+	}
+	if _, err := svc.Ingest(context.Background(), nil); !errors.Is(err, ErrInvalidCandles) {
+		t.Fatalf("expected empty batch rejected, got %v", err)
+	}
+}
+
+func TestCandleIngestStoreError(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &candleIngestStoreStub{ingestFn: func([]*domain.Candle) (int, error) { return 0, errors.New("db down") }}
+	svc := newTestIngestService(store, now)
+	_, err := svc.Ingest(context.Background(), []domain.Candle{ingestCandle("PEPE", now.Add(-time.Hour), 1.5)})
+	if err == nil || errors.Is(err, ErrInvalidCandles) {
+		t.Fatalf("expected a storage error, got %v", err)
+	}
+}
+
+func TestKnownSymbolAndCandleSymbols(t *testing.T) {
+	store := &candleIngestStoreStub{symbols: []string{"BTC", "PEPE"}}
+	ctx := context.Background()
+	if !knownSymbol(ctx, store, "BTC") || !knownSymbol(ctx, store, "PEPE") || knownSymbol(ctx, store, "WIF") {
+		t.Fatal("unexpected knownSymbol result")
+	}
+	if knownSymbol(ctx, &mockCandleRepo{}, "PEPE") {
+		t.Fatal("expected ingested symbols unknown without a symbol store")
+	}
+	symbols := candleSymbols(ctx, store)
+	if len(symbols) != len(domain.SupportedSymbols())+1 || symbols[len(symbols)-1] != "PEPE" {
+		t.Fatalf("unexpected symbols %v", symbols)
+	}
+}
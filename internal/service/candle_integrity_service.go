@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// integrityShortDays is how far back short-tier candles are sampled. It
+	// matches the window RefreshShortCandles fetches, so CoinGecko rebuilds
+	// the candles from samples at the same granularity as the stored ones.
+	integrityShortDays = 1
+	// integrityAlertCooldown spaces out alerts for the same symbol and
+	// interval while its mismatches persist.
+	integrityAlertCooldown = 6 * time.Hour
+	// integrityRateWindow is the window CandleIntegrityService.Report sums.
+	integrityRateWindow = 7 * 24 * time.Hour
+	// integrityReportChecks bounds the mismatched checks Report returns.
+	integrityReportChecks = 20
+)
+
+// CandleIntegrityConfig sets how many candles are sampled and when they are
+// reported as mismatched.
+type CandleIntegrityConfig struct {
+	// SampleSize is how many stored candles are compared per symbol and
+	// interval on each run.
+	SampleSize int
+	// LookbackDays is how far back long-tier candles are sampled.
+	LookbackDays int
+	// TolerancePct is the largest OHLC difference, in percent of the
+	// provider's value, that still counts as a match.
+	TolerancePct float64
+	// AlertPct is the share of compared candles, in percent, that must
+	// mismatch in one run to alert.
+	AlertPct float64
+}
+
+type CandleIntegrityStore interface {
+	InsertChecks(ctx context.Context, checks []domain.CandleIntegrityCheck) error
+	ListRates(ctx context.Context, since time.Time) ([]domain.CandleIntegrityRate, error)
+	ListMismatchedChecks(ctx context.Context, since time.Time, limit int) ([]domain.CandleIntegrityCheck, error)
+	DeleteChecksBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type StoredCandleReader interface {
+	GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error)
+}
+
+type CandleChartFetcher interface {
+	FetchMarketChart(ctx context.Context, symbol string, days int, intervals []string) ([]*domain.Candle, error)
+}
+
+type IntegrityAlerter interface {
+	Broadcast(ctx context.Context, message string) (int, error)
+}
+
+// CandleIntegrityReport is the mismatch rate of every symbol and interval
+// over the last week, with the latest checks that found mismatches.
+type CandleIntegrityReport struct {
+	Since  time.Time                     `json:"since"`
+	Rates  []domain.CandleIntegrityRate  `json:"rates"`
+	Recent []domain.CandleIntegrityCheck `json:"recent_mismatches"`
+}
+
+// CandleIntegrityService re-fetches a random sample of stored candles from
+// the price provider and compares their OHLC values, to catch corrupted
+// rows and history the provider has since revised. Each run stores one
+// check per symbol and interval and alerts operators when too many sampled
+// candles differ.
+type CandleIntegrityService struct {
+	tracer   trace.Tracer
+	candles  StoredCandleReader
+	provider CandleChartFetcher
+	name     string
+	store    CandleIntegrityStore
+	cfg      CandleIntegrityConfig
+	alerter  IntegrityAlerter
+	now      func() time.Time
+	shuffle  func(n int, swap func(i, j int))
+
+	mu sync.Mutex
+	// alerted maps symbol and interval to the last alert time.
+	alerted map[string]time.Time
+}
+
+// NewCandleIntegrityService checks candles against provider, whose name is
+// recorded on each check.
+func NewCandleIntegrityService(tracer trace.Tracer, candles StoredCandleReader, provider CandleChartFetcher, name string, store CandleIntegrityStore, cfg CandleIntegrityConfig) *CandleIntegrityService {
+	if cfg.SampleSize <= 0 {
+		cfg.SampleSize = 20
+	}
+	if cfg.LookbackDays <= 0 {
+		cfg.LookbackDays = 30
+	}
+	return &CandleIntegrityService{
+		tracer:   tracer,
+		candles:  candles,
+		provider: provider,
+		name:     name,
+		store:    store,
+		cfg:      cfg,
+		now:      time.Now,
+		shuffle:  rand.Shuffle,
+		alerted:  make(map[string]time.Time),
+	}
+}
+
+// SetAlerter sends mismatch alerts to alerter.
+func (s *CandleIntegrityService) SetAlerter(alerter IntegrityAlerter) {
+	s.alerter = alerter
+}
+
+// Capture checks every tracked symbol on every supported interval and
+// returns the number of checks stored. A symbol whose candles cannot be read
+// or re-fetched is logged and skipped.
+func (s *CandleIntegrityService) Capture(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "candle-integrity-service.capture")
+	defer span.End()
+
+	if s.candles == nil || s.provider == nil || s.store == nil {
+		return 0, fmt.Errorf("candle integrity service is not fully initialized")
+	}
+	now := s.now().UTC()
+	var checks []domain.CandleIntegrityCheck
+	for _, asset := range domain.Assets() {
+		for _, tier := range []string{domain.IntervalTierShort, domain.IntervalTierLong} {
+			tierChecks, err := s.checkTier(ctx, asset.Symbol, tier, now)
+			if err != nil {
+				if ctx.Err() != nil {
+					return 0, ctx.Err()
+				}
+				log.Printf("candle integrity check error for %s %s tier: %v", asset.Symbol, tier, err)
+				continue
+			}
+			checks = append(checks, tierChecks...)
+		}
+	}
+
+	mismatched := 0
+	for _, c := range checks {
+		mismatched += c.Mismatched
+	}
+	span.SetAttributes(
+		attribute.Int("candle_integrity.checks", len(checks)),
+		attribute.Int("candle_integrity.mismatched", mismatched),
+	)
+	if err := s.store.InsertChecks(ctx, checks); err != nil {
+		return 0, err
+	}
+	s.alert(ctx, checks, now)
+	return len(checks), nil
+}
+
+// checkTier samples the tier's closed candles of symbol and compares them
+// with one provider fetch covering the tier's window.
+func (s *CandleIntegrityService) checkTier(ctx context.Context, symbol, tier string, now time.Time) ([]domain.CandleIntegrityCheck, error) {
+	days := s.cfg.LookbackDays
+	if tier == domain.IntervalTierShort {
+		days = integrityShortDays
+	}
+	from := now.AddDate(0, 0, -days)
+
+	intervals := domain.IntervalsInTier(tier)
+	samples := make(map[string][]*domain.Candle, len(intervals))
+	total := 0
+	for _, interval := range intervals {
+		stored, err := s.candles.GetCandlesInRange(ctx, symbol, interval, from, now)
+		if err != nil {
+			return nil, fmt.Errorf("read %s candles: %w", interval, err)
+		}
+		samples[interval] = s.sample(stored, domain.IntervalDuration(interval), now)
+		total += len(samples[interval])
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	fetched, err := s.provider.FetchMarketChart(ctx, symbol, days, intervals)
+	if err != nil {
+		return nil, fmt.Errorf("re-fetch candles: %w", err)
+	}
+	byKey := make(map[string]*domain.Candle, len(fetched))
+	for _, c := range fetched {
+		byKey[candleKey(c.Interval, c.OpenTime)] = c
+	}
+
+	var checks []domain.CandleIntegrityCheck
+	for _, interval := range intervals {
+		if len(samples[interval]) == 0 {
+			continue
+		}
+		checks = append(checks, s.compare(symbol, interval, samples[interval], byKey, now))
+	}
+	return checks, nil
+}
+
+// sample returns up to SampleSize random candles that closed before now.
+func (s *CandleIntegrityService) sample(stored []*domain.Candle, step time.Duration, now time.Time) []*domain.Candle {
+	closed := make([]*domain.Candle, 0, len(stored))
+	for _, c := range stored {
+		if !c.OpenTime.Add(step).After(now) {
+			closed = append(closed, c)
+		}
+	}
+	s.shuffle(len(closed), func(i, j int) { closed[i], closed[j] = closed[j], closed[i] })
+	if len(closed) > s.cfg.SampleSize {
+		closed = closed[:s.cfg.SampleSize]
+	}
+	return closed
+}
+
+func (s *CandleIntegrityService) compare(symbol, interval string, sampled []*domain.Candle, fetched map[string]*domain.Candle, now time.Time) domain.CandleIntegrityCheck {
+	check := domain.CandleIntegrityCheck{
+		Symbol:    symbol,
+		Interval:  interval,
+		Provider:  s.name,
+		CheckedAt: now,
+		Sampled:   len(sampled),
+	}
+	for _, stored := range sampled {
+		remote, ok := fetched[candleKey(interval, stored.OpenTime)]
+		if !ok {
+			check.Missing++
+			continue
+		}
+		differs := false
+		for _, field := range []struct {
+			name             string
+			stored, provider float64
+		}{
+			{"open", stored.Open, remote.Open},
+			{"high", stored.High, remote.High},
+			{"low", stored.Low, remote.Low},
+			{"close", stored.Close, remote.Close},
+		} {
+			diff := diffPct(field.stored, field.provider)
+			if diff <= s.cfg.TolerancePct {
+				continue
+			}
+			differs = true
+			check.MaxDiffPct = math.Max(check.MaxDiffPct, diff)
+			check.Mismatches = append(check.Mismatches, domain.CandleMismatch{
+				OpenTime: stored.OpenTime.UTC(),
+				Field:    field.name,
+				Stored:   field.stored,
+				Provider: field.provider,
+				DiffPct:  math.Round(diff*10000) / 10000,
+			})
+		}
+		if differs {
+			check.Mismatched++
+		}
+	}
+	check.MaxDiffPct = math.Round(check.MaxDiffPct*10000) / 10000
+	return check
+}
+
+// diffPct is |stored - provider| in percent of the provider's value. A
+// zero provider value counts as a 100% difference unless both are zero.
+func diffPct(stored, provider float64) float64 {
+	if provider == 0 {
+		if stored == 0 {
+			return 0
+		}
+		return 100
+	}
+	return math.Abs(stored-provider) / math.Abs(provider) * 100
+}
+
+func candleKey(interval string, openTime time.Time) string {
+	return fmt.Sprintf("%s|%d", interval, openTime.Unix())
+}
+
+// alert broadcasts one message per check whose mismatch share reaches
+// AlertPct, at most once per symbol and interval per cooldown.
+func (s *CandleIntegrityService) alert(ctx context.Context, checks []domain.CandleIntegrityCheck, now time.Time) {
+	if s.alerter == nil || s.cfg.AlertPct <= 0 {
+		return
+	}
+	for _, c := range checks {
+		if c.Compared() == 0 || float64(c.Mismatched)*100 < s.cfg.AlertPct*float64(c.Compared()) {
+			continue
+		}
+		if !s.markAlerted(c.Symbol+"|"+c.Interval, now) {
+			continue
+		}
+		message := fmt.Sprintf(
+			"Candle integrity: %d of %d sampled %s %s candles differ from %s by more than %.2f%% (max %.2f%%). Stored candles may be corrupt or the provider revised its history.",
+			c.Mismatched, c.Compared(), c.Symbol, c.Interval, s.name, s.cfg.TolerancePct, c.MaxDiffPct)
+		log.Print(message)
+		if _, err := s.alerter.Broadcast(ctx, message); err != nil {
+			log.Printf("candle integrity alert error: %v", err)
+		}
+	}
+}
+
+func (s *CandleIntegrityService) markAlerted(key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if last, ok := s.alerted[key]; ok && now.Sub(last) < integrityAlertCooldown {
+		return false
+	}
+	s.alerted[key] = now
+	return true
+}
+
+// Prune deletes checks older than retention.
+func (s *CandleIntegrityService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "candle-integrity-service.prune")
+	defer span.End()
+
+	if s.store == nil || retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteChecksBefore(ctx, s.now().UTC().Add(-retention))
+}
+
+// Report returns the mismatch rates of the last week and the latest checks
+// that found mismatches.
+func (s *CandleIntegrityService) Report(ctx context.Context) (*CandleIntegrityReport, error) {
+	ctx, span := s.tracer.Start(ctx, "candle-integrity-service.report")
+	defer span.End()
+
+	if s.store == nil {
+		return nil, fmt.Errorf("candle integrity service is not fully initialized")
+	}
+	since := s.now().UTC().Add(-integrityRateWindow)
+	rates, err := s.store.ListRates(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	recent, err := s.store.ListMismatchedChecks(ctx, since, integrityReportChecks)
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = []domain.CandleIntegrityRate{}
+	}
+	if recent == nil {
+		recent = []domain.CandleIntegrityCheck{}
+	}
+	return &CandleIntegrityReport{Since: since, Rates: rates, Recent: recent}, nil
+}
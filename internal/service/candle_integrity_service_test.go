@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type integrityCandleStub struct {
+	candles map[string][]*domain.Candle
+}
+
+func (s integrityCandleStub) GetCandlesInRange(_ context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error) {
+	var out []*domain.Candle
+	for _, c := range s.candles[symbol+"|"+interval] {
+		if !c.OpenTime.Before(from) && !c.OpenTime.After(to) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+type integrityFetcherStub struct {
+	candles []*domain.Candle
+	days    []int
+}
+
+func (s *integrityFetcherStub) FetchMarketChart(_ context.Context, _ string, days int, intervals []string) ([]*domain.Candle, error) {
+	s.days = append(s.days, days)
+	var out []*domain.Candle
+	for _, c := range s.candles {
+		for _, interval := range intervals {
+			if c.Interval == interval {
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+type integrityStoreStub struct {
+	inserted []domain.CandleIntegrityCheck
+}
+
+func (s *integrityStoreStub) InsertChecks(_ context.Context, checks []domain.CandleIntegrityCheck) error {
+	s.inserted = append(s.inserted, checks...)
+	return nil
+}
+
+func (s *integrityStoreStub) ListRates(context.Context, time.Time) ([]domain.CandleIntegrityRate, error) {
+	return nil, nil
+}
+
+func (s *integrityStoreStub) ListMismatchedChecks(context.Context, time.Time, int) ([]domain.CandleIntegrityCheck, error) {
+	return nil, nil
+}
+
+func (s *integrityStoreStub) DeleteChecksBefore(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+type broadcasterStub struct {
+	messages []string
+}
+
+func (s *broadcasterStub) Broadcast(_ context.Context, message string) (int, error) {
+	s.messages = append(s.messages, message)
+	return 1, nil
+}
+
+func TestCandleIntegrityServiceCaptureRecordsMismatches(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	domain.SetAssets([]domain.Asset{{Symbol: "BTC", CoinGeckoID: "bitcoin", BinancePair: "BTCUSDT"}})
+	now := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	var stored, remote []*domain.Candle
+	for i := 1; i <= 5; i++ {
+		open := now.Truncate(time.Hour).Add(-time.Duration(i) * time.Hour)
+		stored = append(stored, &domain.Candle{Symbol: "BTC", Interval: "1h", OpenTime: open, Open: 100, High: 110, Low: 90, Close: 105})
+		if i == 5 {
+			continue // the provider no longer returns the oldest candle
+		}
+		c := &domain.Candle{Symbol: "BTC", Interval: "1h", OpenTime: open, Open: 100, High: 110, Low: 90, Close: 105.2}
+		if i == 2 {
+			c.Close = 110
+		}
+		remote = append(remote, c)
+	}
+	// The candle still open at now is never sampled.
+	stored = append(stored, &domain.Candle{Symbol: "BTC", Interval: "1h", OpenTime: now.Truncate(time.Hour), Open: 1, High: 1, Low: 1, Close: 1})
+
+	fetcher := &integrityFetcherStub{candles: remote}
+	store := &integrityStoreStub{}
+	alerts := &broadcasterStub{}
+	svc := NewCandleIntegrityService(trace.NewNoopTracerProvider().Tracer("test"),
+		integrityCandleStub{candles: map[string][]*domain.Candle{"BTC|1h": stored}},
+		fetcher, "binance", store,
+		CandleIntegrityConfig{SampleSize: 10, LookbackDays: 30, TolerancePct: 0.5, AlertPct: 20})
+	svc.SetAlerter(alerts)
+	svc.now = func() time.Time { return now }
+	svc.shuffle = func(int, func(i, j int)) {}
+
+	n, err := svc.Capture(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(store.inserted) != 1 {
+		t.Fatalf("expected one check for BTC 1h, got %d %+v", n, store.inserted)
+	}
+	if len(fetcher.days) != 1 || fetcher.days[0] != integrityShortDays {
+		t.Fatalf("expected one short-tier fetch, got %v", fetcher.days)
+	}
+	check := store.inserted[0]
+	if check.Provider != "binance" || check.Sampled != 5 || check.Missing != 1 || check.Mismatched != 1 || check.Compared() != 4 {
+		t.Fatalf("unexpected check: %+v", check)
+	}
+	if len(check.Mismatches) != 1 || check.Mismatches[0].Field != "close" || check.Mismatches[0].Stored != 105 ||
+		check.Mismatches[0].Provider != 110 || check.MaxDiffPct != 4.5455 {
+		t.Fatalf("unexpected mismatches: %+v max %v", check.Mismatches, check.MaxDiffPct)
+	}
+	if len(alerts.messages) != 1 || !strings.Contains(alerts.messages[0], "1 of 4 sampled BTC 1h candles differ from binance") {
+		t.Fatalf("unexpected alerts: %v", alerts.messages)
+	}
+
+	// The same mismatch an hour later is stored again without a second alert.
+	now = now.Add(time.Hour)
+	if _, err := svc.Capture(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.inserted) != 2 || len(alerts.messages) != 1 {
+		t.Fatalf("expected the alert held back during the cooldown, got %d checks %d alerts", len(store.inserted), len(alerts.messages))
+	}
+}
+
+func TestCandleIntegrityServiceSamplesAtMostSampleSize(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewCandleIntegrityService(trace.NewNoopTracerProvider().Tracer("test"), nil, nil, "coingecko", nil,
+		CandleIntegrityConfig{SampleSize: 3})
+	var stored []*domain.Candle
+	for i := 0; i < 10; i++ {
+		stored = append(stored, &domain.Candle{OpenTime: now.Add(-time.Duration(i+1) * time.Hour)})
+	}
+	if got := svc.sample(stored, time.Hour, now); len(got) != 3 {
+		t.Fatalf("expected 3 sampled candles, got %d", len(got))
+	}
+	if _, err := svc.Capture(context.Background()); err == nil {
+		t.Fatal("expected an error without a store and provider")
+	}
+}
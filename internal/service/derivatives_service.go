@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type DerivativesFetcher interface {
+	FetchDerivatives(ctx context.Context, symbol string) (*domain.DerivativesSnapshot, error)
+}
+
+type DerivativesStore interface {
+	InsertSnapshots(ctx context.Context, snapshots []domain.DerivativesSnapshot) error
+	DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// DerivativesService captures funding rates and open interest for every
+// tracked symbol with a perpetual on the fetcher's exchange.
+type DerivativesService struct {
+	tracer  trace.Tracer
+	fetcher DerivativesFetcher
+	store   DerivativesStore
+	now     func() time.Time
+}
+
+func NewDerivativesService(tracer trace.Tracer, fetcher DerivativesFetcher, store DerivativesStore) *DerivativesService {
+	return &DerivativesService{tracer: tracer, fetcher: fetcher, store: store, now: time.Now}
+}
+
+// Capture fetches and stores one snapshot per tracked symbol with a Binance
+// pair. A symbol whose fetch fails, usually because it has no perpetual, is
+// logged and skipped.
+func (s *DerivativesService) Capture(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "derivatives-service.capture")
+	defer span.End()
+
+	if s.fetcher == nil || s.store == nil {
+		return 0, fmt.Errorf("derivatives service is not fully initialized")
+	}
+	var snapshots []domain.DerivativesSnapshot
+	for _, asset := range domain.Assets() {
+		if asset.BinancePair == "" {
+			continue
+		}
+		snap, err := s.fetcher.FetchDerivatives(ctx, asset.Symbol)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			log.Printf("derivatives fetch error for %s: %v", asset.Symbol, err)
+			continue
+		}
+		snapshots = append(snapshots, *snap)
+	}
+	span.SetAttributes(attribute.Int("derivatives.snapshots", len(snapshots)))
+	if err := s.store.InsertSnapshots(ctx, snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// Prune deletes snapshots older than retention.
+func (s *DerivativesService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "derivatives-service.prune")
+	defer span.End()
+
+	if s.store == nil || retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteSnapshotsBefore(ctx, s.now().UTC().Add(-retention))
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type derivativesFetcherStub struct {
+	failFor string
+}
+
+func (s derivativesFetcherStub) FetchDerivatives(_ context.Context, symbol string) (*domain.DerivativesSnapshot, error) {
+	if symbol == s.failFor {
+		return nil, errors.New("no perpetual")
+	}
+	return &domain.DerivativesSnapshot{Symbol: symbol, Source: "binance", FundingRate: 0.0001}, nil
+}
+
+type derivativesStoreStub struct {
+	inserted []domain.DerivativesSnapshot
+	cutoff   time.Time
+}
+
+func (s *derivativesStoreStub) InsertSnapshots(_ context.Context, snapshots []domain.DerivativesSnapshot) error {
+	s.inserted = append(s.inserted, snapshots...)
+	return nil
+}
+
+func (s *derivativesStoreStub) DeleteSnapshotsBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	s.cutoff = cutoff
+	return 2, nil
+}
+
+func TestDerivativesServiceCaptureSkipsSymbolsWithoutPerpetuals(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	domain.SetAssets([]domain.Asset{
+		{Symbol: "BTC", CoinGeckoID: "bitcoin", BinancePair: "BTCUSDT"},
+		{Symbol: "POL", CoinGeckoID: "polygon", BinancePair: "POLUSDT"},
+		{Symbol: "XYZ", CoinGeckoID: "xyz"},
+	})
+	store := &derivativesStoreStub{}
+	svc := NewDerivativesService(trace.NewNoopTracerProvider().Tracer("test"), derivativesFetcherStub{failFor: "POL"}, store)
+
+	n, err := svc.Capture(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(store.inserted) != 1 || store.inserted[0].Symbol != "BTC" {
+		t.Fatalf("expected only BTC stored, got %d %+v", n, store.inserted)
+	}
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	if deleted, err := svc.Prune(context.Background(), 48*time.Hour); err != nil || deleted != 2 || !store.cutoff.Equal(now.Add(-48*time.Hour)) {
+		t.Fatalf("unexpected prune: %d %v cutoff=%s", deleted, err, store.cutoff)
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DexSpreadSignalInterval is the interval cex_dex_spread signals are
+	// stored under. Their timestamp is the start of the UTC hour, so one
+	// signal per symbol and direction is kept each hour.
+	DexSpreadSignalInterval = "1h"
+	dexSpreadSignalVersion  = "cex_dex_v1"
+	// dexSpreadCexMaxAge skips the comparison when the cached exchange
+	// price is older than this.
+	dexSpreadCexMaxAge = 15 * time.Minute
+)
+
+type DexQuoteFetcher interface {
+	HasDexSource(symbol string) bool
+	FetchDexQuote(ctx context.Context, symbol string) (*domain.DexQuote, error)
+}
+
+type CexPriceReader interface {
+	GetCurrentPrice(ctx context.Context, symbol string) (*domain.PriceSnapshot, error)
+}
+
+type DexSpreadStore interface {
+	InsertSpreads(ctx context.Context, spreads []domain.CexDexSpread) error
+	DeleteSpreadsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type DexSpreadSignalStore interface {
+	InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error)
+}
+
+type DexSpreadAlertSink interface {
+	NotifySignals(ctx context.Context, signals []domain.Signal) error
+}
+
+// DexSpreadService compares on-chain DEX prices with the exchange price for
+// every tracked symbol with a DEX source, stores each comparison and emits a
+// cex_dex_spread signal when the two diverge by at least the threshold.
+type DexSpreadService struct {
+	tracer       trace.Tracer
+	fetcher      DexQuoteFetcher
+	prices       CexPriceReader
+	store        DexSpreadStore
+	signals      DexSpreadSignalStore
+	thresholdPct float64
+	alertSink    DexSpreadAlertSink
+	now          func() time.Time
+
+	mu sync.Mutex
+	// alerted maps symbol and direction to the hour last alerted, so a
+	// divergence lasting the hour alerts once.
+	alerted map[string]time.Time
+}
+
+func NewDexSpreadService(tracer trace.Tracer, fetcher DexQuoteFetcher, prices CexPriceReader, store DexSpreadStore, signals DexSpreadSignalStore, thresholdPct float64) *DexSpreadService {
+	return &DexSpreadService{
+		tracer:       tracer,
+		fetcher:      fetcher,
+		prices:       prices,
+		store:        store,
+		signals:      signals,
+		thresholdPct: thresholdPct,
+		now:          time.Now,
+		alerted:      make(map[string]time.Time),
+	}
+}
+
+// SetAlertSink sends new cex_dex_spread signals to sink.
+func (s *DexSpreadService) SetAlertSink(sink DexSpreadAlertSink) {
+	s.alertSink = sink
+}
+
+// Capture compares and stores one DEX quote per tracked symbol with a DEX
+// source. A symbol whose DEX quote fails, or whose exchange price is missing
+// or stale, is logged and skipped. Symbols with signals paused are compared
+// and stored but emit no signal.
+func (s *DexSpreadService) Capture(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "dex-spread-service.capture")
+	defer span.End()
+
+	if s.fetcher == nil || s.prices == nil || s.store == nil {
+		return 0, fmt.Errorf("dex spread service is not fully initialized")
+	}
+	now := s.now().UTC()
+	var (
+		spreads   []domain.CexDexSpread
+		divergent []domain.Signal
+	)
+	for _, asset := range domain.Assets() {
+		if !s.fetcher.HasDexSource(asset.Symbol) {
+			continue
+		}
+		quote, err := s.fetcher.FetchDexQuote(ctx, asset.Symbol)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			log.Printf("dex quote error for %s: %v", asset.Symbol, err)
+			continue
+		}
+		cex, err := s.prices.GetCurrentPrice(ctx, asset.Symbol)
+		if err != nil || cex == nil || cex.PriceUSD <= 0 {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			log.Printf("dex spread: no exchange price for %s: %v", asset.Symbol, err)
+			continue
+		}
+		if cex.LastUpdatedUnix > 0 && now.Sub(time.Unix(cex.LastUpdatedUnix, 0)) > dexSpreadCexMaxAge {
+			log.Printf("dex spread: exchange price for %s is stale, skipping", asset.Symbol)
+			continue
+		}
+
+		spread := domain.CexDexSpread{
+			Symbol:      asset.Symbol,
+			Venue:       quote.Venue,
+			CapturedAt:  quote.CapturedAt,
+			DexPriceUSD: quote.PriceUSD,
+			CexPriceUSD: cex.PriceUSD,
+			SpreadPct:   (quote.PriceUSD - cex.PriceUSD) / cex.PriceUSD * 100,
+		}
+		spreads = append(spreads, spread)
+		if s.thresholdPct > 0 && math.Abs(spread.SpreadPct) >= s.thresholdPct && !domain.SignalsPaused(asset.Symbol) {
+			divergent = append(divergent, spreadSignal(spread, now))
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("dex_spread.spreads", len(spreads)),
+		attribute.Int("dex_spread.signals", len(divergent)),
+	)
+	if err := s.store.InsertSpreads(ctx, spreads); err != nil {
+		return 0, err
+	}
+	if len(divergent) > 0 && s.signals != nil {
+		persisted, err := s.signals.InsertSignals(ctx, divergent)
+		if err != nil {
+			return len(spreads), fmt.Errorf("store cex-dex signals: %w", err)
+		}
+		s.notify(ctx, s.unalerted(persisted))
+	}
+	return len(spreads), nil
+}
+
+// Prune deletes comparisons older than retention.
+func (s *DexSpreadService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "dex-spread-service.prune")
+	defer span.End()
+
+	if s.store == nil || retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteSpreadsBefore(ctx, s.now().UTC().Add(-retention))
+}
+
+func spreadSignal(spread domain.CexDexSpread, now time.Time) domain.Signal {
+	direction := domain.DirectionLong
+	if spread.SpreadPct < 0 {
+		direction = domain.DirectionShort
+	}
+	return domain.Signal{
+		Symbol:    spread.Symbol,
+		Interval:  DexSpreadSignalInterval,
+		Indicator: domain.IndicatorCexDexSpread,
+		Timestamp: now.Truncate(time.Hour),
+		Risk:      domain.RiskLevel4,
+		Direction: direction,
+		Details: fmt.Sprintf("DEX %s %.2f vs CEX %.2f (%+.2f%%)",
+			spread.Venue, spread.DexPriceUSD, spread.CexPriceUSD, spread.SpreadPct),
+		Version: dexSpreadSignalVersion,
+	}
+}
+
+// unalerted returns the signals whose symbol and direction have not been
+// alerted in their hour yet, and marks them alerted.
+func (s *DexSpreadService) unalerted(signals []domain.Signal) []domain.Signal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var fresh []domain.Signal
+	for _, sig := range signals {
+		key := sig.Symbol + "|" + string(sig.Direction)
+		if last, ok := s.alerted[key]; ok && last.Equal(sig.Timestamp) {
+			continue
+		}
+		s.alerted[key] = sig.Timestamp
+		fresh = append(fresh, sig)
+	}
+	return fresh
+}
+
+func (s *DexSpreadService) notify(ctx context.Context, signals []domain.Signal) {
+	if len(signals) == 0 || s.alertSink == nil {
+		return
+	}
+	if err := s.alertSink.NotifySignals(ctx, signals); err != nil {
+		log.Printf("cex-dex spread alert dispatch error: %v", err)
+	}
+}
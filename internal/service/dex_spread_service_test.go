@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type dexFetcherStub struct {
+	prices  map[string]float64
+	failFor string
+}
+
+func (s dexFetcherStub) HasDexSource(symbol string) bool {
+	_, ok := s.prices[symbol]
+	return ok || symbol == s.failFor
+}
+
+func (s dexFetcherStub) FetchDexQuote(_ context.Context, symbol string) (*domain.DexQuote, error) {
+	if symbol == s.failFor {
+		return nil, errors.New("rpc down")
+	}
+	return &domain.DexQuote{Symbol: symbol, Venue: "uniswap_v3", PriceUSD: s.prices[symbol]}, nil
+}
+
+type cexPriceStub struct {
+	prices  map[string]float64
+	updated int64
+}
+
+func (s cexPriceStub) GetCurrentPrice(_ context.Context, symbol string) (*domain.PriceSnapshot, error) {
+	price, ok := s.prices[symbol]
+	if !ok {
+		return nil, errors.New("not cached")
+	}
+	return &domain.PriceSnapshot{Symbol: symbol, PriceUSD: price, LastUpdatedUnix: s.updated}, nil
+}
+
+type dexSpreadStoreStub struct {
+	inserted []domain.CexDexSpread
+	cutoff   time.Time
+}
+
+func (s *dexSpreadStoreStub) InsertSpreads(_ context.Context, spreads []domain.CexDexSpread) error {
+	s.inserted = append(s.inserted, spreads...)
+	return nil
+}
+
+func (s *dexSpreadStoreStub) DeleteSpreadsBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	s.cutoff = cutoff
+	return 3, nil
+}
+
+type dexSignalStoreStub struct {
+	inserted []domain.Signal
+}
+
+func (s *dexSignalStoreStub) InsertSignals(_ context.Context, signals []domain.Signal) ([]domain.Signal, error) {
+	s.inserted = append(s.inserted, signals...)
+	return signals, nil
+}
+
+type dexAlertSinkStub struct {
+	batches [][]domain.Signal
+}
+
+func (s *dexAlertSinkStub) NotifySignals(_ context.Context, signals []domain.Signal) error {
+	s.batches = append(s.batches, signals)
+	return nil
+}
+
+func TestDexSpreadServiceCaptureEmitsSignalsAboveThreshold(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	domain.SetAssets([]domain.Asset{
+		{Symbol: "BTC", CoinGeckoID: "bitcoin", BinancePair: "BTCUSDT"},
+		{Symbol: "ETH", CoinGeckoID: "ethereum", BinancePair: "ETHUSDT"},
+		{Symbol: "SOL", CoinGeckoID: "solana", BinancePair: "SOLUSDT"},
+		{Symbol: "XRP", CoinGeckoID: "ripple", BinancePair: "XRPUSDT"},
+		{Symbol: "ADA", CoinGeckoID: "cardano", BinancePair: "ADAUSDT"},
+	})
+	now := time.Date(2026, 3, 1, 10, 20, 0, 0, time.UTC)
+	fetcher := dexFetcherStub{prices: map[string]float64{"BTC": 100_500, "ETH": 2_940, "SOL": 150}, failFor: "ADA"}
+	prices := cexPriceStub{prices: map[string]float64{"BTC": 100_000, "ETH": 3_000, "SOL": 150, "XRP": 2}, updated: now.Add(-time.Minute).Unix()}
+	store := &dexSpreadStoreStub{}
+	signals := &dexSignalStoreStub{}
+	sink := &dexAlertSinkStub{}
+	svc := NewDexSpreadService(trace.NewNoopTracerProvider().Tracer("test"), fetcher, prices, store, signals, 1.0)
+	svc.SetAlertSink(sink)
+	svc.now = func() time.Time { return now }
+
+	n, err := svc.Capture(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 || len(store.inserted) != 3 {
+		t.Fatalf("expected BTC, ETH and SOL stored, got %d %+v", n, store.inserted)
+	}
+	if math.Abs(store.inserted[0].SpreadPct-0.5) > 1e-9 || math.Abs(store.inserted[1].SpreadPct+2) > 1e-9 {
+		t.Fatalf("unexpected spreads: %+v", store.inserted)
+	}
+	if len(signals.inserted) != 1 {
+		t.Fatalf("expected only ETH above the threshold, got %+v", signals.inserted)
+	}
+	sig := signals.inserted[0]
+	if sig.Symbol != "ETH" || sig.Indicator != domain.IndicatorCexDexSpread || sig.Direction != domain.DirectionShort ||
+		sig.Interval != DexSpreadSignalInterval || !sig.Timestamp.Equal(now.Truncate(time.Hour)) || sig.Risk != domain.RiskLevel4 {
+		t.Fatalf("unexpected signal: %+v", sig)
+	}
+	if sig.Details != "DEX uniswap_v3 2940.00 vs CEX 3000.00 (-2.00%)" {
+		t.Fatalf("unexpected details: %q", sig.Details)
+	}
+	if len(sink.batches) != 1 {
+		t.Fatalf("expected one alert batch, got %d", len(sink.batches))
+	}
+
+	// The same divergence later in the hour is stored again but not re-alerted.
+	now = now.Add(20 * time.Minute)
+	prices.updated = now.Unix()
+	svc.prices = prices
+	if _, err := svc.Capture(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(signals.inserted) != 2 || len(sink.batches) != 1 {
+		t.Fatalf("expected no second alert in the same hour, got %d signals %d batches", len(signals.inserted), len(sink.batches))
+	}
+
+	// The next hour alerts again.
+	now = now.Add(time.Hour)
+	prices.updated = now.Unix()
+	svc.prices = prices
+	if _, err := svc.Capture(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.batches) != 2 {
+		t.Fatalf("expected a new alert in the next hour, got %d batches", len(sink.batches))
+	}
+}
+
+func TestDexSpreadServiceSkipsStaleExchangePrices(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	domain.SetAssets([]domain.Asset{{Symbol: "ETH", CoinGeckoID: "ethereum", BinancePair: "ETHUSDT"}})
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	store := &dexSpreadStoreStub{}
+	signals := &dexSignalStoreStub{}
+	svc := NewDexSpreadService(trace.NewNoopTracerProvider().Tracer("test"),
+		dexFetcherStub{prices: map[string]float64{"ETH": 2_000}},
+		cexPriceStub{prices: map[string]float64{"ETH": 3_000}, updated: now.Add(-time.Hour).Unix()},
+		store, signals, 1.0)
+	svc.now = func() time.Time { return now }
+
+	if n, err := svc.Capture(context.Background()); err != nil || n != 0 || len(signals.inserted) != 0 {
+		t.Fatalf("expected the stale comparison skipped, got n=%d err=%v signals=%+v", n, err, signals.inserted)
+	}
+
+	if deleted, err := svc.Prune(context.Background(), 24*time.Hour); err != nil || deleted != 3 || !store.cutoff.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected prune result %d %v cutoff=%v", deleted, err, store.cutoff)
+	}
+}
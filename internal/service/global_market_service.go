@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type GlobalMarketFetcher interface {
+	FetchGlobal(ctx context.Context) (*domain.GlobalMarketSnapshot, error)
+}
+
+type GlobalMarketStore interface {
+	InsertSnapshot(ctx context.Context, snapshot domain.GlobalMarketSnapshot) error
+	LatestSnapshot(ctx context.Context) (*domain.GlobalMarketSnapshot, error)
+	DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// GlobalMarketService captures total market capitalisation and BTC
+// dominance and serves the latest reading. A service without a fetcher only
+// reads, which is how cmd/ssh uses it.
+type GlobalMarketService struct {
+	tracer  trace.Tracer
+	fetcher GlobalMarketFetcher
+	store   GlobalMarketStore
+	now     func() time.Time
+}
+
+func NewGlobalMarketService(tracer trace.Tracer, fetcher GlobalMarketFetcher, store GlobalMarketStore) *GlobalMarketService {
+	return &GlobalMarketService{tracer: tracer, fetcher: fetcher, store: store, now: time.Now}
+}
+
+// Capture fetches and stores one snapshot.
+func (s *GlobalMarketService) Capture(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "global-market-service.capture")
+	defer span.End()
+
+	if s.fetcher == nil || s.store == nil {
+		return 0, fmt.Errorf("global market service is not fully initialized")
+	}
+	snap, err := s.fetcher.FetchGlobal(ctx)
+	if err != nil {
+		return 0, err
+	}
+	span.SetAttributes(attribute.Float64("global_market.btc_dominance_pct", snap.BTCDominancePct))
+	if err := s.store.InsertSnapshot(ctx, *snap); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// LatestGlobalMarket returns the newest stored snapshot, or nil before the
+// first capture.
+func (s *GlobalMarketService) LatestGlobalMarket(ctx context.Context) (*domain.GlobalMarketSnapshot, error) {
+	ctx, span := s.tracer.Start(ctx, "global-market-service.latest")
+	defer span.End()
+
+	if s.store == nil {
+		return nil, fmt.Errorf("global market service is not fully initialized")
+	}
+	return s.store.LatestSnapshot(ctx)
+}
+
+// Prune deletes snapshots older than retention.
+func (s *GlobalMarketService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "global-market-service.prune")
+	defer span.End()
+
+	if s.store == nil || retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteSnapshotsBefore(ctx, s.now().UTC().Add(-retention))
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type globalMarketFetcherStub struct {
+	err error
+}
+
+func (s globalMarketFetcherStub) FetchGlobal(context.Context) (*domain.GlobalMarketSnapshot, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.GlobalMarketSnapshot{Source: "coingecko", TotalMarketCapUSD: 2.4e12, BTCDominancePct: 54.2}, nil
+}
+
+type globalMarketStoreStub struct {
+	inserted []domain.GlobalMarketSnapshot
+	cutoff   time.Time
+}
+
+func (s *globalMarketStoreStub) InsertSnapshot(_ context.Context, snapshot domain.GlobalMarketSnapshot) error {
+	s.inserted = append(s.inserted, snapshot)
+	return nil
+}
+
+func (s *globalMarketStoreStub) LatestSnapshot(context.Context) (*domain.GlobalMarketSnapshot, error) {
+	if len(s.inserted) == 0 {
+		return nil, nil
+	}
+	latest := s.inserted[len(s.inserted)-1]
+	return &latest, nil
+}
+
+func (s *globalMarketStoreStub) DeleteSnapshotsBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	s.cutoff = cutoff
+	return 3, nil
+}
+
+func TestGlobalMarketServiceCaptureAndLatest(t *testing.T) {
+	store := &globalMarketStoreStub{}
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	svc := NewGlobalMarketService(tracer, globalMarketFetcherStub{}, store)
+
+	if latest, err := svc.LatestGlobalMarket(context.Background()); err != nil || latest != nil {
+		t.Fatalf("expected no snapshot before the first capture, got %+v %v", latest, err)
+	}
+	if n, err := svc.Capture(context.Background()); err != nil || n != 1 {
+		t.Fatalf("unexpected capture: %d %v", n, err)
+	}
+	latest, err := svc.LatestGlobalMarket(context.Background())
+	if err != nil || latest == nil || latest.BTCDominancePct != 54.2 {
+		t.Fatalf("unexpected latest: %+v %v", latest, err)
+	}
+
+	failing := NewGlobalMarketService(tracer, globalMarketFetcherStub{err: errors.New("429")}, store)
+	if _, err := failing.Capture(context.Background()); err == nil || len(store.inserted) != 1 {
+		t.Fatalf("expected a failed fetch to store nothing, got %v and %d rows", err, len(store.inserted))
+	}
+	readOnly := NewGlobalMarketService(tracer, nil, store)
+	if _, err := readOnly.Capture(context.Background()); err == nil {
+		t.Fatal("expected capture without a fetcher to fail")
+	}
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	if deleted, err := svc.Prune(context.Background(), 24*time.Hour); err != nil || deleted != 3 || !store.cutoff.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected prune: %d %v cutoff=%s", deleted, err, store.cutoff)
+	}
+}
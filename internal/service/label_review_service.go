@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxLabelOverrideReason caps the stored reason.
+const maxLabelOverrideReason = 500
+
+// ErrInvalidLabelOverride is returned by LabelReviewService.Override for a
+// malformed override.
+var ErrInvalidLabelOverride = errors.New("invalid label override")
+
+type LabelReviewStore interface {
+	ListLabelReviews(ctx context.Context, filter domain.LabelReviewFilter) ([]domain.LabelReview, error)
+	GetLabelOverride(ctx context.Context, symbol, interval string, openTime time.Time) (*domain.LabelOverride, error)
+	OverrideLabel(ctx context.Context, o domain.LabelOverride) (domain.LabelOverride, int64, error)
+}
+
+// LabelReviewService lists resolved prediction outcomes for review and lets
+// operators correct labels that bad exchange data got wrong. Every override
+// is recorded in the audit log.
+type LabelReviewService struct {
+	tracer trace.Tracer
+	store  LabelReviewStore
+	audit  *AuditService
+}
+
+func NewLabelReviewService(tracer trace.Tracer, store LabelReviewStore, audit *AuditService) *LabelReviewService {
+	return &LabelReviewService{tracer: tracer, store: store, audit: audit}
+}
+
+func (s *LabelReviewService) List(ctx context.Context, filter domain.LabelReviewFilter) ([]domain.LabelReview, error) {
+	ctx, span := s.tracer.Start(ctx, "label-review-service.list")
+	defer span.End()
+	if s.store == nil {
+		return nil, fmt.Errorf("label review unavailable")
+	}
+	return s.store.ListLabelReviews(ctx, filter)
+}
+
+// Override sets the label of every resolved prediction on o's candle,
+// attributed to the actor bound to ctx. It returns the saved override and
+// the number of predictions changed.
+func (s *LabelReviewService) Override(ctx context.Context, o domain.LabelOverride) (domain.LabelOverride, int64, error) {
+	ctx, span := s.tracer.Start(ctx, "label-review-service.override")
+	defer span.End()
+	if s.store == nil {
+		return domain.LabelOverride{}, 0, fmt.Errorf("label review unavailable")
+	}
+
+	o.Symbol = strings.ToUpper(strings.TrimSpace(o.Symbol))
+	o.Interval = strings.TrimSpace(o.Interval)
+	o.Reason = strings.TrimSpace(o.Reason)
+	o.OpenTime = o.OpenTime.UTC()
+	switch {
+	case o.Symbol == "":
+		return domain.LabelOverride{}, 0, fmt.Errorf("%w: symbol is required", ErrInvalidLabelOverride)
+	case domain.IntervalDuration(o.Interval) == 0:
+		return domain.LabelOverride{}, 0, fmt.Errorf("%w: unsupported interval %q", ErrInvalidLabelOverride, o.Interval)
+	case o.OpenTime.IsZero():
+		return domain.LabelOverride{}, 0, fmt.Errorf("%w: open_time is required", ErrInvalidLabelOverride)
+	case o.Reason == "":
+		return domain.LabelOverride{}, 0, fmt.Errorf("%w: reason is required", ErrInvalidLabelOverride)
+	case len(o.Reason) > maxLabelOverrideReason:
+		return domain.LabelOverride{}, 0, fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidLabelOverride, maxLabelOverrideReason)
+	}
+	o.Actor = domain.ActorFromContext(ctx)
+	span.SetAttributes(attribute.String("label.key", o.Key()))
+
+	before, err := s.store.GetLabelOverride(ctx, o.Symbol, o.Interval, o.OpenTime)
+	if err != nil {
+		return domain.LabelOverride{}, 0, err
+	}
+	saved, updated, err := s.store.OverrideLabel(ctx, o)
+	if err != nil {
+		return domain.LabelOverride{}, 0, err
+	}
+	var auditBefore any
+	if before != nil {
+		auditBefore = before
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionLabelOverride, domain.AuditEntityLabel,
+		saved.Key(), auditBefore, saved); err != nil {
+		span.RecordError(err)
+	}
+	return saved, updated, nil
+}
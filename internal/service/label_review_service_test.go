@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type labelReviewStoreStub struct {
+	existing *domain.LabelOverride
+	saved    domain.LabelOverride
+	calls    int
+}
+
+func (s *labelReviewStoreStub) ListLabelReviews(ctx context.Context, filter domain.LabelReviewFilter) ([]domain.LabelReview, error) {
+	return nil, nil
+}
+
+func (s *labelReviewStoreStub) GetLabelOverride(ctx context.Context, symbol, interval string, openTime time.Time) (*domain.LabelOverride, error) {
+	return s.existing, nil
+}
+
+func (s *labelReviewStoreStub) OverrideLabel(ctx context.Context, o domain.LabelOverride) (domain.LabelOverride, int64, error) {
+	s.calls++
+	s.saved = o
+	return o, 3, nil
+}
+
+func TestLabelReviewOverrideRecordsAuditWithActor(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	store := &labelReviewStoreStub{}
+	audit := &auditStoreStub{}
+	svc := NewLabelReviewService(tracer, store, NewAuditService(tracer, audit))
+	ctx := domain.WithActor(context.Background(), "operator")
+
+	saved, updated, err := svc.Override(ctx, domain.LabelOverride{
+		Symbol:              " btc ",
+		Interval:            "1h",
+		OpenTime:            time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		ActualUp:            true,
+		ExcludeFromTraining: true,
+		Reason:              "exchange wick",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated != 3 || saved.Symbol != "BTC" || store.saved.Actor != "operator" {
+		t.Fatalf("unexpected result: %+v updated=%d", store.saved, updated)
+	}
+	if len(audit.entries) != 1 {
+		t.Fatalf("expected one audit entry, got %d", len(audit.entries))
+	}
+	got := audit.entries[0]
+	if got.Action != domain.AuditActionLabelOverride || got.EntityID != "BTC/1h/2026-03-01T12:00:00Z" || got.BeforeJSON != "" {
+		t.Fatalf("unexpected audit entry: %+v", got)
+	}
+}
+
+func TestLabelReviewOverrideRejectsInvalidInput(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	openTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]domain.LabelOverride{
+		"symbol":   {Interval: "1h", OpenTime: openTime, Reason: "x"},
+		"interval": {Symbol: "BTC", Interval: "2m", OpenTime: openTime, Reason: "x"},
+		"time":     {Symbol: "BTC", Interval: "1h", Reason: "x"},
+		"reason":   {Symbol: "BTC", Interval: "1h", OpenTime: openTime, Reason: "  "},
+	}
+	for name, o := range cases {
+		store := &labelReviewStoreStub{}
+		svc := NewLabelReviewService(tracer, store, nil)
+		if _, _, err := svc.Override(context.Background(), o); !errors.Is(err, ErrInvalidLabelOverride) {
+			t.Fatalf("%s: expected ErrInvalidLabelOverride, got %v", name, err)
+		}
+		if store.calls != 0 {
+			t.Fatalf("%s: store should not be called", name)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maintenanceRefresh bounds how long a cached maintenance state is trusted.
+// cmd/ssh can flip the switch in the shared table, so the server re-reads it.
+const maintenanceRefresh = 5 * time.Second
+
+// maxMaintenanceReason caps the stored reason.
+const maxMaintenanceReason = 500
+
+// ErrInvalidMaintenance is returned by MaintenanceService.Set for a reason
+// that is too long.
+var ErrInvalidMaintenance = errors.New("invalid maintenance change")
+
+type MaintenanceStore interface {
+	GetMaintenance(ctx context.Context) (domain.MaintenanceState, error)
+	SetMaintenance(ctx context.Context, state domain.MaintenanceState) (domain.MaintenanceState, error)
+}
+
+// MaintenanceService holds the operator maintenance switch. While it is on,
+// pollers, ML jobs and alert dispatch skip their cycles and read APIs keep
+// serving. Without a store the switch lives in this process only.
+type MaintenanceService struct {
+	tracer trace.Tracer
+	store  MaintenanceStore
+	audit  *AuditService
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    domain.MaintenanceState
+	loadedAt time.Time
+}
+
+func NewMaintenanceService(tracer trace.Tracer, store MaintenanceStore, audit *AuditService) *MaintenanceService {
+	return &MaintenanceService{tracer: tracer, store: store, audit: audit, now: time.Now}
+}
+
+// State returns the current switch, reading the store at most once per
+// maintenanceRefresh. On a store error the last known state is returned
+// with the error.
+func (s *MaintenanceService) State(ctx context.Context) (domain.MaintenanceState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store == nil || (!s.loadedAt.IsZero() && s.now().Sub(s.loadedAt) < maintenanceRefresh) {
+		return s.state, nil
+	}
+	ctx, span := s.tracer.Start(ctx, "maintenance-service.load")
+	defer span.End()
+
+	state, err := s.store.GetMaintenance(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return s.state, err
+	}
+	if state.Enabled != s.state.Enabled && !s.loadedAt.IsZero() {
+		logMaintenanceChange(state)
+	}
+	s.state = state
+	s.loadedAt = s.now()
+	return state, nil
+}
+
+// Paused reports whether background work should pause. It keeps the last
+// known state if the store cannot be read.
+func (s *MaintenanceService) Paused(ctx context.Context) bool {
+	state, err := s.State(ctx)
+	if err != nil {
+		log.Printf("maintenance state read error: %v", err)
+	}
+	return state.Enabled
+}
+
+// Set turns maintenance on or off, attributed to the actor bound to ctx,
+// and records the change in the audit log.
+func (s *MaintenanceService) Set(ctx context.Context, enabled bool, reason string) (domain.MaintenanceState, error) {
+	ctx, span := s.tracer.Start(ctx, "maintenance-service.set")
+	defer span.End()
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxMaintenanceReason {
+		return domain.MaintenanceState{}, fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidMaintenance, maxMaintenanceReason)
+	}
+	if !enabled {
+		reason = ""
+	}
+	next := domain.MaintenanceState{
+		Enabled:   enabled,
+		Reason:    reason,
+		Actor:     domain.ActorFromContext(ctx),
+		UpdatedAt: s.now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := s.state
+	if s.store != nil {
+		stored, err := s.store.SetMaintenance(ctx, next)
+		if err != nil {
+			span.RecordError(err)
+			return domain.MaintenanceState{}, err
+		}
+		next = stored
+	}
+	s.state = next
+	s.loadedAt = s.now()
+	if before.Enabled != next.Enabled {
+		logMaintenanceChange(next)
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionMaintenanceSet, domain.AuditEntityMaintenance, "", before, next); err != nil {
+		log.Printf("audit maintenance change: %v", err)
+	}
+	return next, nil
+}
+
+func logMaintenanceChange(state domain.MaintenanceState) {
+	if state.Enabled {
+		log.Printf("Maintenance mode on (by %s): %s; pollers, ML jobs and alert dispatch paused", state.Actor, state.Reason)
+		return
+	}
+	log.Printf("Maintenance mode off (by %s); background work resumes", state.Actor)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type maintenanceStoreStub struct {
+	state domain.MaintenanceState
+	gets  int
+	err   error
+}
+
+func (s *maintenanceStoreStub) GetMaintenance(ctx context.Context) (domain.MaintenanceState, error) {
+	s.gets++
+	return s.state, s.err
+}
+
+func (s *maintenanceStoreStub) SetMaintenance(ctx context.Context, state domain.MaintenanceState) (domain.MaintenanceState, error) {
+	if s.err != nil {
+		return domain.MaintenanceState{}, s.err
+	}
+	s.state = state
+	return state, nil
+}
+
+func TestMaintenanceServiceSetRecordsAudit(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	audit := &auditStoreStub{}
+	svc := NewMaintenanceService(tracer, &maintenanceStoreStub{}, NewAuditService(tracer, audit))
+	ctx := domain.WithActor(context.Background(), "api:default")
+
+	state, err := svc.Set(ctx, true, "  provider incident  ")
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if !state.Enabled || state.Reason != "provider incident" || state.Actor != "api:default" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if !svc.Paused(ctx) {
+		t.Fatal("expected paused after enabling")
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != domain.AuditActionMaintenanceSet {
+		t.Fatalf("expected maintenance audit entry, got %+v", audit.entries)
+	}
+
+	state, _ = svc.Set(ctx, false, "ignored when turning off")
+	if state.Enabled || state.Reason != "" || svc.Paused(ctx) {
+		t.Fatalf("expected maintenance off, got %+v", state)
+	}
+
+	if _, err := svc.Set(ctx, true, strings.Repeat("x", maxMaintenanceReason+1)); !errors.Is(err, ErrInvalidMaintenance) {
+		t.Fatalf("expected ErrInvalidMaintenance, got %v", err)
+	}
+}
+
+func TestMaintenanceServiceRereadsSharedState(t *testing.T) {
+	store := &maintenanceStoreStub{}
+	svc := NewMaintenanceService(trace.NewNoopTracerProvider().Tracer("test"), store, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if svc.Paused(ctx) {
+		t.Fatal("expected not paused")
+	}
+	// Another process turns maintenance on.
+	store.state = domain.MaintenanceState{Enabled: true, Reason: "migration", Actor: "ssh:alice"}
+	if svc.Paused(ctx) || store.gets != 1 {
+		t.Fatalf("expected the cached state used within the refresh window, gets=%d", store.gets)
+	}
+	now = now.Add(maintenanceRefresh)
+	if !svc.Paused(ctx) || store.gets != 2 {
+		t.Fatalf("expected the shared state re-read, gets=%d", store.gets)
+	}
+
+	// A failing store keeps the last known state.
+	store.err = errors.New("db down")
+	now = now.Add(maintenanceRefresh)
+	if !svc.Paused(ctx) {
+		t.Fatal("expected last known state kept on a read error")
+	}
+}
+
+func TestMaintenanceServiceWithoutStore(t *testing.T) {
+	svc := NewMaintenanceService(trace.NewNoopTracerProvider().Tracer("test"), nil, nil)
+	if _, err := svc.Set(context.Background(), true, "local"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	state, err := svc.State(context.Background())
+	if err != nil || !state.Enabled || state.Actor != domain.SystemActor {
+		t.Fatalf("unexpected in-process state %+v err=%v", state, err)
+	}
+}
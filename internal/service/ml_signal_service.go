@@ -2,19 +2,19 @@ package service
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"log"
 	"sort"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/ml/common"
 	"bug-free-umbrella/internal/ml/features"
 	"bug-free-umbrella/internal/ml/inference"
-	"bug-free-umbrella/internal/ml/predictions"
+	"bug-free-umbrella/internal/ml/similarity"
 	"bug-free-umbrella/internal/ml/training"
+	"bug-free-umbrella/internal/storage"
+	"bug-free-umbrella/pkg/domain"
 
-	"github.com/jackc/pgx/v5"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -23,14 +23,52 @@ type MLCandleRepository interface {
 	GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error)
 }
 
+type MLFeatureStore interface {
+	UpsertRows(ctx context.Context, rows []domain.MLFeatureRow) error
+}
+
+// MLOrderBookSource supplies hourly order book averages for feature rows.
+type MLOrderBookSource interface {
+	HourlyOrderBookFeatures(ctx context.Context, symbol string, from, to time.Time) ([]domain.OrderBookFeatures, error)
+}
+
+// MLDerivativesSource supplies hourly funding and open interest averages
+// for feature rows.
+type MLDerivativesSource interface {
+	HourlyDerivativesFeatures(ctx context.Context, symbol string, from, to time.Time) ([]domain.DerivativesFeatures, error)
+}
+
+// MLGlobalMarketSource supplies hourly BTC dominance and total market cap
+// averages for feature rows.
+type MLGlobalMarketSource interface {
+	HourlyGlobalMarketFeatures(ctx context.Context, from, to time.Time) ([]domain.GlobalMarketFeatures, error)
+}
+
+// MLEventSource supplies the scheduled market events starting in a range
+// for the event proximity feature.
+type MLEventSource interface {
+	ListMarketEvents(ctx context.Context, from, to time.Time) ([]domain.MarketEvent, error)
+}
+
+type MLPredictionStore interface {
+	ListUnresolvedDue(ctx context.Context, cutoff time.Time, limit int) ([]domain.MLPrediction, error)
+	ListLatestBySymbol(ctx context.Context, symbol string, limit int) ([]domain.MLPrediction, error)
+	ResolvePrediction(ctx context.Context, predictionID int64, actualUp bool, isCorrect bool, realizedReturn float64) error
+}
+
 type MLSignalService struct {
 	tracer         trace.Tracer
 	candleRepo     MLCandleRepository
 	featureEngine  *features.Engine
-	featureRepo    *features.Repository
+	featureRepo    MLFeatureStore
 	trainingSvc    *training.Service
 	inferenceSvc   *inference.Service
-	predictionRepo *predictions.Repository
+	predictionRepo MLPredictionStore
+	similarity     *similarity.Service
+	orderBook      MLOrderBookSource
+	derivatives    MLDerivativesSource
+	globalMarket   MLGlobalMarketSource
+	events         MLEventSource
 
 	intervals       []string
 	targetHours     int
@@ -48,10 +86,10 @@ func NewMLSignalService(
 	tracer trace.Tracer,
 	candleRepo MLCandleRepository,
 	featureEngine *features.Engine,
-	featureRepo *features.Repository,
+	featureRepo MLFeatureStore,
 	trainingSvc *training.Service,
 	inferenceSvc *inference.Service,
-	predictionRepo *predictions.Repository,
+	predictionRepo MLPredictionStore,
 	cfg MLSignalServiceConfig,
 ) *MLSignalService {
 	if cfg.Interval == "" {
@@ -83,10 +121,79 @@ func NewMLSignalService(
 	}
 }
 
+// SetSimilaritySearch enables FindSimilar.
+func (s *MLSignalService) SetSimilaritySearch(svc *similarity.Service) {
+	s.similarity = svc
+}
+
+// SetOrderBookSource fills the order book features of refreshed rows from
+// src. Without it they stay 0.
+func (s *MLSignalService) SetOrderBookSource(src MLOrderBookSource) {
+	s.orderBook = src
+}
+
+// SetDerivativesSource fills the funding rate and open interest features of
+// refreshed rows from src. Without it they stay 0.
+func (s *MLSignalService) SetDerivativesSource(src MLDerivativesSource) {
+	s.derivatives = src
+}
+
+// SetGlobalMarketSource fills the BTC dominance and market cap change
+// features of refreshed rows from src. Without it they stay 0.
+func (s *MLSignalService) SetGlobalMarketSource(src MLGlobalMarketSource) {
+	s.globalMarket = src
+}
+
+// SetEventSource fills the event proximity feature of refreshed rows from
+// src. Without it the feature stays 0.
+func (s *MLSignalService) SetEventSource(src MLEventSource) {
+	s.events = src
+}
+
+// MLRefreshResult summarises an on-demand feature refresh and inference run
+// for a single symbol.
+type MLRefreshResult struct {
+	Symbol      string
+	FeatureRows int
+	Inference   inference.RunResult
+	Predictions []domain.MLPrediction
+}
+
 func (s *MLSignalService) RefreshFeatures(ctx context.Context) (int, error) {
 	_, span := s.tracer.Start(ctx, "ml-signal-service.refresh-features")
 	defer span.End()
 
+	return s.refreshFeatures(ctx, candleSymbols(ctx, s.candleRepo))
+}
+
+// RefreshAndInfer rebuilds feature rows for symbol, runs inference on the
+// latest rows and returns the newest predictions stored for that symbol.
+func (s *MLSignalService) RefreshAndInfer(ctx context.Context, symbol string, limit int) (MLRefreshResult, error) {
+	ctx, span := s.tracer.Start(ctx, "ml-signal-service.refresh-and-infer")
+	defer span.End()
+
+	out := MLRefreshResult{Symbol: symbol}
+	rowsCount, err := s.refreshFeatures(ctx, []string{symbol})
+	out.FeatureRows = rowsCount
+	if err != nil {
+		return out, err
+	}
+	if s.inferenceSvc != nil {
+		out.Inference, err = s.inferenceSvc.RunLatest(ctx, time.Now().UTC())
+		if err != nil {
+			return out, err
+		}
+	}
+	if s.predictionRepo != nil {
+		out.Predictions, err = s.predictionRepo.ListLatestBySymbol(ctx, symbol, limit)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+func (s *MLSignalService) refreshFeatures(ctx context.Context, symbols []string) (int, error) {
 	if s.candleRepo == nil || s.featureRepo == nil || s.featureEngine == nil {
 		return 0, fmt.Errorf("ml feature refresh dependencies are not initialized")
 	}
@@ -94,7 +201,7 @@ func (s *MLSignalService) RefreshFeatures(ctx context.Context) (int, error) {
 	rowsCount := 0
 	for _, interval := range s.intervals {
 		limit := candleLimitForInterval(interval, s.trainWindowDays, s.targetHours)
-		for _, symbol := range domain.SupportedSymbols {
+		for _, symbol := range symbols {
 			candles, err := s.candleRepo.GetCandles(ctx, symbol, interval, limit)
 			if err != nil {
 				return rowsCount, fmt.Errorf("get candles for %s %s: %w", symbol, interval, err)
@@ -106,6 +213,10 @@ func (s *MLSignalService) RefreshFeatures(ctx context.Context) (int, error) {
 			if len(rows) == 0 {
 				continue
 			}
+			s.attachOrderBook(ctx, symbol, interval, rows)
+			s.attachDerivatives(ctx, symbol, interval, rows)
+			s.attachGlobalMarket(ctx, symbol, interval, rows)
+			s.attachEvents(ctx, symbol, interval, rows)
 			if err := s.featureRepo.UpsertRows(ctx, rows); err != nil {
 				return rowsCount, fmt.Errorf("upsert feature rows for %s %s: %w", symbol, interval, err)
 			}
@@ -115,6 +226,66 @@ func (s *MLSignalService) RefreshFeatures(ctx context.Context) (int, error) {
 	return rowsCount, nil
 }
 
+// attachOrderBook fills rows' order book features. A failed lookup is
+// logged and leaves them 0, so a missing order book never blocks features.
+func (s *MLSignalService) attachOrderBook(ctx context.Context, symbol, interval string, rows []domain.MLFeatureRow) {
+	if s.orderBook == nil {
+		return
+	}
+	from, to := features.OrderBookWindow(interval, rows[0].OpenTime, rows[len(rows)-1].OpenTime)
+	hourly, err := s.orderBook.HourlyOrderBookFeatures(ctx, symbol, from, to)
+	if err != nil {
+		log.Printf("order book features for %s %s: %v", symbol, interval, err)
+		return
+	}
+	features.ApplyOrderBook(rows, hourly)
+}
+
+// attachDerivatives fills rows' derivatives features, logging a failed
+// lookup like attachOrderBook.
+func (s *MLSignalService) attachDerivatives(ctx context.Context, symbol, interval string, rows []domain.MLFeatureRow) {
+	if s.derivatives == nil {
+		return
+	}
+	from, to := features.DerivativesWindow(interval, rows[0].OpenTime, rows[len(rows)-1].OpenTime)
+	hourly, err := s.derivatives.HourlyDerivativesFeatures(ctx, symbol, from, to)
+	if err != nil {
+		log.Printf("derivatives features for %s %s: %v", symbol, interval, err)
+		return
+	}
+	features.ApplyDerivatives(rows, hourly)
+}
+
+// attachGlobalMarket fills rows' global market features, logging a failed
+// lookup like attachOrderBook.
+func (s *MLSignalService) attachGlobalMarket(ctx context.Context, symbol, interval string, rows []domain.MLFeatureRow) {
+	if s.globalMarket == nil {
+		return
+	}
+	from, to := features.GlobalMarketWindow(interval, rows[0].OpenTime, rows[len(rows)-1].OpenTime)
+	hourly, err := s.globalMarket.HourlyGlobalMarketFeatures(ctx, from, to)
+	if err != nil {
+		log.Printf("global market features for %s %s: %v", symbol, interval, err)
+		return
+	}
+	features.ApplyGlobalMarket(rows, hourly)
+}
+
+// attachEvents fills rows' event proximity, logging a failed lookup like
+// attachOrderBook.
+func (s *MLSignalService) attachEvents(ctx context.Context, symbol, interval string, rows []domain.MLFeatureRow) {
+	if s.events == nil {
+		return
+	}
+	from, to := features.EventWindow(interval, rows[0].OpenTime, rows[len(rows)-1].OpenTime)
+	events, err := s.events.ListMarketEvents(ctx, from, to)
+	if err != nil {
+		log.Printf("market events for %s %s: %v", symbol, interval, err)
+		return
+	}
+	features.ApplyEventProximity(rows, events)
+}
+
 func (s *MLSignalService) RunInference(ctx context.Context) (inference.RunResult, error) {
 	_, span := s.tracer.Start(ctx, "ml-signal-service.run-inference")
 	defer span.End()
@@ -135,6 +306,42 @@ func (s *MLSignalService) RunTraining(ctx context.Context) ([]training.ModelTrai
 	return s.trainingSvc.TrainAll(ctx, time.Now().UTC())
 }
 
+// CompareModelVersions re-scores the last days of labeled feature rows with
+// two registry versions of modelKey.
+func (s *MLSignalService) CompareModelVersions(ctx context.Context, modelKey string, versionA, versionB, days int) (*training.VersionComparison, error) {
+	ctx, span := s.tracer.Start(ctx, "ml-signal-service.compare-model-versions")
+	defer span.End()
+
+	if s.trainingSvc == nil {
+		return nil, fmt.Errorf("ml training service unavailable")
+	}
+	now := time.Now().UTC()
+	return s.trainingSvc.CompareVersions(ctx, modelKey, versionA, versionB, now.AddDate(0, 0, -days), now)
+}
+
+// InferAt replays inference for one past candle without persisting anything.
+func (s *MLSignalService) InferAt(ctx context.Context, symbol, interval string, at time.Time) (*inference.PointInTime, error) {
+	ctx, span := s.tracer.Start(ctx, "ml-signal-service.infer-at")
+	defer span.End()
+
+	if s.inferenceSvc == nil {
+		return nil, fmt.Errorf("ml inference service unavailable")
+	}
+	return s.inferenceSvc.InferAt(ctx, symbol, interval, at)
+}
+
+// FindSimilar returns the k past states nearest to the latest feature row
+// of symbol and their realized outcomes.
+func (s *MLSignalService) FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error) {
+	ctx, span := s.tracer.Start(ctx, "ml-signal-service.find-similar")
+	defer span.End()
+
+	if s.similarity == nil {
+		return nil, fmt.Errorf("ml similarity search unavailable")
+	}
+	return s.similarity.FindSimilar(ctx, symbol, interval, k)
+}
+
 func (s *MLSignalService) ResolveOutcomes(ctx context.Context, limit int) (int, error) {
 	_, span := s.tracer.Start(ctx, "ml-signal-service.resolve-outcomes")
 	defer span.End()
@@ -175,7 +382,7 @@ func (s *MLSignalService) ResolveOutcomes(ctx context.Context, limit int) (int,
 		realized := (targetClose / openClose) - 1
 		isCorrect := predictedUp == actualUp
 		if err := s.predictionRepo.ResolvePrediction(ctx, pred.ID, actualUp, isCorrect, realized); err != nil {
-			if errors.Is(err, pgx.ErrNoRows) {
+			if storage.IsNotFound(err) {
 				continue
 			}
 			return resolved, err
@@ -212,11 +419,8 @@ func uniqueIntervals(intervals []string, fallback string) []string {
 
 func candleLimitForInterval(interval string, windowDays int, targetHours int) int {
 	pointsPerDay := 24
-	switch interval {
-	case "4h":
-		pointsPerDay = 6
-	case "1d":
-		pointsPerDay = 1
+	if info, ok := domain.LookupInterval(interval); ok {
+		pointsPerDay = info.PointsPerDay()
 	}
 	limit := (windowDays * pointsPerDay) + targetHours + 64
 	if limit < 500 {
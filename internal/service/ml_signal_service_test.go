@@ -4,7 +4,7 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestExtractOpenAndTargetClose(t *testing.T) {
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultOrderBookBandPct is how far from the mid price depth is summed.
+	DefaultOrderBookBandPct = 1.0
+	// MaxOrderBookHistory caps the snapshots one history request returns.
+	MaxOrderBookHistory = 1440
+)
+
+type OrderBookFetcher interface {
+	FetchOrderBook(ctx context.Context, symbol string, bandPct float64) (*domain.OrderBookSnapshot, error)
+}
+
+type OrderBookStore interface {
+	InsertSnapshots(ctx context.Context, snapshots []domain.OrderBookSnapshot) error
+	ListSnapshots(ctx context.Context, symbol string, limit int) ([]domain.OrderBookSnapshot, error)
+	DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// OrderBookService captures top-of-book depth for every tracked symbol
+// listed on the fetcher's exchange and serves the stored snapshots.
+type OrderBookService struct {
+	tracer  trace.Tracer
+	fetcher OrderBookFetcher
+	store   OrderBookStore
+	bandPct float64
+	now     func() time.Time
+}
+
+func NewOrderBookService(tracer trace.Tracer, fetcher OrderBookFetcher, store OrderBookStore, bandPct float64) *OrderBookService {
+	if bandPct <= 0 {
+		bandPct = DefaultOrderBookBandPct
+	}
+	return &OrderBookService{tracer: tracer, fetcher: fetcher, store: store, bandPct: bandPct, now: time.Now}
+}
+
+// Capture fetches and stores one snapshot per tracked symbol with a Binance
+// pair. A symbol whose fetch fails is logged and skipped.
+func (s *OrderBookService) Capture(ctx context.Context) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "orderbook-service.capture")
+	defer span.End()
+
+	if s.fetcher == nil || s.store == nil {
+		return 0, fmt.Errorf("order book service is not fully initialized")
+	}
+	var snapshots []domain.OrderBookSnapshot
+	for _, asset := range domain.Assets() {
+		if asset.BinancePair == "" {
+			continue
+		}
+		book, err := s.fetcher.FetchOrderBook(ctx, asset.Symbol, s.bandPct)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			log.Printf("order book fetch error for %s: %v", asset.Symbol, err)
+			continue
+		}
+		snapshots = append(snapshots, *book)
+	}
+	span.SetAttributes(attribute.Int("orderbook.snapshots", len(snapshots)))
+	if err := s.store.InsertSnapshots(ctx, snapshots); err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// History returns up to limit of symbol's snapshots, newest first.
+func (s *OrderBookService) History(ctx context.Context, symbol string, limit int) ([]domain.OrderBookSnapshot, error) {
+	ctx, span := s.tracer.Start(ctx, "orderbook-service.history")
+	defer span.End()
+
+	if s.store == nil {
+		return nil, fmt.Errorf("order book service is not fully initialized")
+	}
+	if limit <= 0 || limit > MaxOrderBookHistory {
+		limit = MaxOrderBookHistory
+	}
+	span.SetAttributes(attribute.String("symbol", symbol))
+	return s.store.ListSnapshots(ctx, symbol, limit)
+}
+
+// Prune deletes snapshots older than retention.
+func (s *OrderBookService) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "orderbook-service.prune")
+	defer span.End()
+
+	if s.store == nil || retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteSnapshotsBefore(ctx, s.now().UTC().Add(-retention))
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type orderBookFetcherStub struct {
+	failFor string
+	bands   []float64
+}
+
+func (s *orderBookFetcherStub) FetchOrderBook(_ context.Context, symbol string, bandPct float64) (*domain.OrderBookSnapshot, error) {
+	s.bands = append(s.bands, bandPct)
+	if symbol == s.failFor {
+		return nil, errors.New("depth unavailable")
+	}
+	return &domain.OrderBookSnapshot{Symbol: symbol, Source: "binance", Imbalance: 0.1}, nil
+}
+
+type orderBookStoreStub struct {
+	inserted  []domain.OrderBookSnapshot
+	listLimit int
+	cutoff    time.Time
+}
+
+func (s *orderBookStoreStub) InsertSnapshots(_ context.Context, snapshots []domain.OrderBookSnapshot) error {
+	s.inserted = append(s.inserted, snapshots...)
+	return nil
+}
+
+func (s *orderBookStoreStub) ListSnapshots(_ context.Context, _ string, limit int) ([]domain.OrderBookSnapshot, error) {
+	s.listLimit = limit
+	return nil, nil
+}
+
+func (s *orderBookStoreStub) DeleteSnapshotsBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	s.cutoff = cutoff
+	return 3, nil
+}
+
+func TestOrderBookServiceCaptureSkipsFailedSymbols(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	domain.SetAssets([]domain.Asset{
+		{Symbol: "BTC", CoinGeckoID: "bitcoin", BinancePair: "BTCUSDT"},
+		{Symbol: "ETH", CoinGeckoID: "ethereum", BinancePair: "ETHUSDT"},
+		{Symbol: "XYZ", CoinGeckoID: "xyz"},
+	})
+	fetcher := &orderBookFetcherStub{failFor: "ETH"}
+	store := &orderBookStoreStub{}
+	svc := NewOrderBookService(trace.NewNoopTracerProvider().Tracer("test"), fetcher, store, 0)
+
+	n, err := svc.Capture(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 1 || len(store.inserted) != 1 || store.inserted[0].Symbol != "BTC" {
+		t.Fatalf("expected only BTC stored, got %d %+v", n, store.inserted)
+	}
+	if len(fetcher.bands) != 2 || fetcher.bands[0] != DefaultOrderBookBandPct {
+		t.Fatalf("expected two fetches at the default band, got %v", fetcher.bands)
+	}
+
+	if _, err := svc.History(context.Background(), "BTC", 0); err != nil || store.listLimit != MaxOrderBookHistory {
+		t.Fatalf("expected the history limit clamped, got %d, %v", store.listLimit, err)
+	}
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	if deleted, err := svc.Prune(context.Background(), 24*time.Hour); err != nil || deleted != 3 || !store.cutoff.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("unexpected prune: %d %v cutoff=%s", deleted, err, store.cutoff)
+	}
+}
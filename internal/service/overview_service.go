@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	overviewCacheKey = "overview"
+	// overviewCacheTTL bounds staleness when an invalidation is missed, for
+	// example for signals written by another process.
+	overviewCacheTTL = 30 * time.Second
+
+	overviewSignalsPerSymbol = 5
+	// overviewSignalScan is how many recent signals are read per symbol to
+	// find the classic ones among ML and fundamentals signals.
+	overviewSignalScan = 40
+	// overviewPredictionScan covers every model and interval of the newest
+	// inference runs.
+	overviewPredictionScan = 40
+)
+
+type OverviewPriceSource interface {
+	GetCurrentPrices(ctx context.Context) ([]*domain.PriceSnapshot, error)
+}
+
+type OverviewSignalSource interface {
+	ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error)
+}
+
+type OverviewPredictionSource interface {
+	ListLatestBySymbol(ctx context.Context, symbol string, limit int) ([]domain.MLPrediction, error)
+}
+
+// OverviewCache stores the assembled overview so that concurrent readers
+// share one build.
+type OverviewCache interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+}
+
+// OverviewService assembles the per-symbol overview: latest price, classic
+// signals, ensemble prediction and anomaly score.
+type OverviewService struct {
+	tracer      trace.Tracer
+	prices      OverviewPriceSource
+	signals     OverviewSignalSource
+	predictions OverviewPredictionSource
+	cache       OverviewCache
+}
+
+func NewOverviewService(tracer trace.Tracer, prices OverviewPriceSource, signals OverviewSignalSource) *OverviewService {
+	return &OverviewService{tracer: tracer, prices: prices, signals: signals}
+}
+
+// SetPredictions adds ensemble predictions and anomaly scores; without it
+// the overview carries prices and classic signals only.
+func (s *OverviewService) SetPredictions(src OverviewPredictionSource) {
+	s.predictions = src
+}
+
+// SetCache caches the overview for 30 seconds. New signals (NotifySignals)
+// and predictions (PredictionsUpdated) drop the cached copy.
+func (s *OverviewService) SetCache(cache OverviewCache) {
+	s.cache = cache
+}
+
+// Overview returns one entry per supported symbol, in SupportedSymbols order.
+// Cache failures are logged and the overview is built directly.
+func (s *OverviewService) Overview(ctx context.Context) ([]domain.SymbolOverview, error) {
+	ctx, span := s.tracer.Start(ctx, "overview-service.overview")
+	defer span.End()
+
+	if cached, ok := s.getCache(ctx); ok {
+		return cached, nil
+	}
+	out, err := s.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.setCache(ctx, out)
+	return out, nil
+}
+
+// NotifySignals drops the cached overview when new signals arrive, so the
+// service can sit among the signal poller's alert sinks.
+func (s *OverviewService) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// PredictionsUpdated drops the cached overview after an inference run.
+func (s *OverviewService) PredictionsUpdated(ctx context.Context) {
+	s.invalidate(ctx)
+}
+
+func (s *OverviewService) build(ctx context.Context) ([]domain.SymbolOverview, error) {
+	if s.prices == nil || s.signals == nil {
+		return nil, fmt.Errorf("overview service is not fully initialized")
+	}
+
+	prices, err := s.prices.GetCurrentPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get prices: %w", err)
+	}
+	priceBySymbol := make(map[string]*domain.PriceSnapshot, len(prices))
+	for _, p := range prices {
+		if p != nil {
+			priceBySymbol[p.Symbol] = p
+		}
+	}
+
+	symbols := domain.SupportedSymbols()
+	out := make([]domain.SymbolOverview, 0, len(symbols))
+	for _, symbol := range symbols {
+		entry := domain.SymbolOverview{Symbol: symbol, Price: priceBySymbol[symbol]}
+
+		recent, err := s.signals.ListSignals(ctx, domain.SignalFilter{Symbol: symbol, Limit: overviewSignalScan})
+		if err != nil {
+			return nil, fmt.Errorf("list signals for %s: %w", symbol, err)
+		}
+		entry.Signals = classicSignals(recent, overviewSignalsPerSymbol)
+
+		if s.predictions != nil {
+			preds, err := s.predictions.ListLatestBySymbol(ctx, symbol, overviewPredictionScan)
+			if err != nil {
+				return nil, fmt.Errorf("list predictions for %s: %w", symbol, err)
+			}
+			entry.Ensemble, entry.AnomalyScore = latestEnsembleAndAnomaly(preds)
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func (s *OverviewService) getCache(ctx context.Context) ([]domain.SymbolOverview, bool) {
+	if s.cache == nil {
+		return nil, false
+	}
+	data, err := s.cache.Get(ctx, overviewCacheKey).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			log.Printf("overview cache read failed: %v", err)
+		}
+		return nil, false
+	}
+	var out []domain.SymbolOverview
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func (s *OverviewService) setCache(ctx context.Context, overview []domain.SymbolOverview) {
+	if s.cache == nil {
+		return
+	}
+	data, err := json.Marshal(overview)
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, overviewCacheKey, data, overviewCacheTTL).Err(); err != nil {
+		log.Printf("overview cache write failed: %v", err)
+	}
+}
+
+func (s *OverviewService) invalidate(ctx context.Context) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Del(ctx, overviewCacheKey).Err(); err != nil {
+		log.Printf("overview cache invalidation failed: %v", err)
+	}
+}
+
+// classicSignals keeps the first limit technical indicator signals, skipping
+// ML and fundamentals signals.
+func classicSignals(signals []domain.Signal, limit int) []domain.Signal {
+	out := make([]domain.Signal, 0, limit)
+	for _, sig := range signals {
+		if !domain.IsClassicIndicator(sig.Indicator) {
+			continue
+		}
+		out = append(out, sig)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+// latestEnsembleAndAnomaly picks the newest ensemble prediction and the
+// newest isolation forest score. The forest stores its score as Confidence.
+func latestEnsembleAndAnomaly(preds []domain.MLPrediction) (*domain.EnsemblePrediction, *float64) {
+	var ensemble, anomaly *domain.MLPrediction
+	for i := range preds {
+		p := &preds[i]
+		switch {
+		case p.ModelKey == common.ModelKeyEnsembleV1:
+			if ensemble == nil || p.OpenTime.After(ensemble.OpenTime) {
+				ensemble = p
+			}
+		case common.IsIForestModelKey(p.ModelKey):
+			if anomaly == nil || p.OpenTime.After(anomaly.OpenTime) {
+				anomaly = p
+			}
+		}
+	}
+
+	var view *domain.EnsemblePrediction
+	if ensemble != nil {
+		view = &domain.EnsemblePrediction{
+			Interval:     ensemble.Interval,
+			OpenTime:     ensemble.OpenTime,
+			TargetTime:   ensemble.TargetTime,
+			ModelVersion: ensemble.ModelVersion,
+			ProbUp:       ensemble.ProbUp,
+			Confidence:   ensemble.Confidence,
+			Direction:    ensemble.Direction,
+			Risk:         ensemble.Risk,
+			HeldReason:   ensemble.HeldReason(),
+			Uncertainty:  ensemble.Uncertainty(),
+		}
+	}
+	var score *float64
+	if anomaly != nil {
+		v := anomaly.Confidence
+		score = &v
+	}
+	return view, score
+}
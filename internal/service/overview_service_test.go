@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type overviewPricesStub struct {
+	prices []*domain.PriceSnapshot
+	err    error
+}
+
+func (s overviewPricesStub) GetCurrentPrices(ctx context.Context) ([]*domain.PriceSnapshot, error) {
+	return s.prices, s.err
+}
+
+type overviewSignalsStub struct {
+	bySymbol map[string][]domain.Signal
+	calls    *int
+}
+
+func (s overviewSignalsStub) ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error) {
+	if s.calls != nil {
+		*s.calls++
+	}
+	return s.bySymbol[filter.Symbol], nil
+}
+
+type overviewPredictionsStub struct {
+	bySymbol map[string][]domain.MLPrediction
+}
+
+func (s overviewPredictionsStub) ListLatestBySymbol(ctx context.Context, symbol string, limit int) ([]domain.MLPrediction, error) {
+	return s.bySymbol[symbol], nil
+}
+
+func TestOverviewServiceAssemblesPerSymbol(t *testing.T) {
+	t0 := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	signals := overviewSignalsStub{bySymbol: map[string][]domain.Signal{
+		"BTC": {
+			{Symbol: "BTC", Indicator: domain.IndicatorMLEnsembleUp4H},
+			{Symbol: "BTC", Indicator: domain.IndicatorRSI},
+			{Symbol: "BTC", Indicator: domain.IndicatorFundSentimentComposite},
+			{Symbol: "BTC", Indicator: domain.IndicatorMACD},
+		},
+	}}
+	preds := overviewPredictionsStub{bySymbol: map[string][]domain.MLPrediction{
+		"BTC": {
+			{Symbol: "BTC", ModelKey: "ensemble_v1", Interval: "1h", OpenTime: t0, ProbUp: 0.54, ModelVersion: 3,
+				Direction: domain.DirectionHold, DetailsJSON: `{"held_reason":"anomaly_damping"}`},
+			{Symbol: "BTC", ModelKey: "iforest_1h", Interval: "1h", OpenTime: t0, Confidence: 0.71},
+			{Symbol: "BTC", ModelKey: "logreg", Interval: "1h", OpenTime: t0, ProbUp: 0.9},
+			{Symbol: "BTC", ModelKey: "ensemble_v1", Interval: "1h", OpenTime: t0.Add(-time.Hour), ProbUp: 0.2},
+		},
+	}}
+	svc := NewOverviewService(trace.NewNoopTracerProvider().Tracer("test"),
+		overviewPricesStub{prices: []*domain.PriceSnapshot{{Symbol: "BTC", PriceUSD: 65000}}}, signals)
+	svc.SetPredictions(preds)
+
+	out, err := svc.Overview(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != len(domain.SupportedSymbols()) || out[0].Symbol != "BTC" {
+		t.Fatalf("expected one entry per supported symbol, got %d", len(out))
+	}
+	btc := out[0]
+	if btc.Price == nil || btc.Price.PriceUSD != 65000 {
+		t.Fatalf("unexpected price %+v", btc.Price)
+	}
+	if len(btc.Signals) != 2 || btc.Signals[0].Indicator != domain.IndicatorRSI || btc.Signals[1].Indicator != domain.IndicatorMACD {
+		t.Fatalf("expected only classic signals, got %+v", btc.Signals)
+	}
+	if btc.Ensemble == nil || btc.Ensemble.ProbUp != 0.54 || btc.Ensemble.ModelVersion != 3 ||
+		btc.Ensemble.HeldReason != domain.HeldReasonAnomalyDamping {
+		t.Fatalf("expected newest ensemble prediction, got %+v", btc.Ensemble)
+	}
+	if btc.AnomalyScore == nil || *btc.AnomalyScore != 0.71 {
+		t.Fatalf("expected anomaly score 0.71, got %v", btc.AnomalyScore)
+	}
+
+	eth := out[1]
+	if eth.Price != nil || eth.Ensemble != nil || eth.AnomalyScore != nil || len(eth.Signals) != 0 {
+		t.Fatalf("expected empty entry for ETH, got %+v", eth)
+	}
+}
+
+func TestOverviewServiceWithoutPredictions(t *testing.T) {
+	svc := NewOverviewService(trace.NewNoopTracerProvider().Tracer("test"), overviewPricesStub{}, overviewSignalsStub{})
+	out, err := svc.Overview(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out[0].Ensemble != nil {
+		t.Fatal("expected no ensemble without a prediction source")
+	}
+
+	svc = NewOverviewService(trace.NewNoopTracerProvider().Tracer("test"), overviewPricesStub{err: errors.New("down")}, overviewSignalsStub{})
+	if _, err := svc.Overview(context.Background()); err == nil {
+		t.Fatal("expected price error to fail the overview")
+	}
+}
+
+func TestOverviewServiceCachesUntilInvalidated(t *testing.T) {
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer mini.Close()
+
+	calls := 0
+	svc := NewOverviewService(trace.NewNoopTracerProvider().Tracer("test"), overviewPricesStub{}, overviewSignalsStub{calls: &calls})
+	svc.SetCache(redis.NewClient(&redis.Options{Addr: mini.Addr()}))
+	ctx := context.Background()
+	perBuild := len(domain.SupportedSymbols())
+
+	if _, err := svc.Overview(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Overview(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != perBuild {
+		t.Fatalf("expected second call to be served from cache, got %d signal queries", calls)
+	}
+	if ttl := mini.TTL(overviewCacheKey); ttl != overviewCacheTTL {
+		t.Fatalf("expected %v ttl, got %v", overviewCacheTTL, ttl)
+	}
+
+	_ = svc.NotifySignals(ctx, nil)
+	svc.Overview(ctx)
+	if calls != perBuild {
+		t.Fatal("expected an empty signal batch to keep the cache")
+	}
+
+	_ = svc.NotifySignals(ctx, []domain.Signal{{Symbol: "BTC"}})
+	svc.Overview(ctx)
+	if calls != 2*perBuild {
+		t.Fatalf("expected rebuild after new signals, got %d signal queries", calls)
+	}
+
+	svc.PredictionsUpdated(ctx)
+	svc.Overview(ctx)
+	if calls != 3*perBuild {
+		t.Fatalf("expected rebuild after new predictions, got %d signal queries", calls)
+	}
+
+	mini.FastForward(overviewCacheTTL)
+	svc.Overview(ctx)
+	if calls != 4*perBuild {
+		t.Fatalf("expected rebuild after ttl, got %d signal queries", calls)
+	}
+}
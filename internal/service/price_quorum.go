@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// quorumFetchTimeout bounds each cross-check provider's fetch, so a slow
+	// provider delays a price refresh by at most this long.
+	quorumFetchTimeout = 10 * time.Second
+	// quorumPruneEvery spaces out deletions of expired discrepancies.
+	quorumPruneEvery = time.Hour
+	// quorumMinToReject is how many providers must quote a symbol before the
+	// primary's price can be outvoted. With two, neither can be told wrong.
+	quorumMinToReject = 3
+)
+
+// PriceQuoteSource is a provider whose current prices are cross-checked
+// against the primary's.
+type PriceQuoteSource interface {
+	FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error)
+}
+
+type PriceDiscrepancyStore interface {
+	InsertDiscrepancies(ctx context.Context, discrepancies []domain.PriceDiscrepancy) error
+	ListDiscrepancies(ctx context.Context, since time.Time, symbol string, limit int) ([]domain.PriceDiscrepancy, error)
+	DeleteDiscrepanciesBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type quorumSource struct {
+	name   string
+	source PriceQuoteSource
+}
+
+// PriceQuorum cross-checks the primary provider's prices against other
+// providers fetched at the same time. When at least three providers quote a
+// symbol, each price is compared with the median of all of them; with two,
+// each is compared with the other. A price that differs by more than the
+// deviation is an outlier and is recorded. A primary price that is the
+// outlier among three or more is rejected and replaced by the median.
+type PriceQuorum struct {
+	tracer       trace.Tracer
+	primary      string
+	sources      []quorumSource
+	deviationPct float64
+	store        PriceDiscrepancyStore
+	retention    time.Duration
+	now          func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewPriceQuorum validates prices from the primary provider, named primary
+// on recorded discrepancies, flagging deviations above deviationPct
+// percent. Discrepancies are stored in store, when set, for retention.
+func NewPriceQuorum(tracer trace.Tracer, primary string, deviationPct float64, store PriceDiscrepancyStore, retention time.Duration) *PriceQuorum {
+	if deviationPct <= 0 {
+		deviationPct = 2
+	}
+	return &PriceQuorum{
+		tracer:       tracer,
+		primary:      primary,
+		deviationPct: deviationPct,
+		store:        store,
+		retention:    retention,
+		now:          time.Now,
+	}
+}
+
+// AddSource cross-checks the primary against source, recorded as name.
+func (q *PriceQuorum) AddSource(name string, source PriceQuoteSource) {
+	q.sources = append(q.sources, quorumSource{name: name, source: source})
+}
+
+// Validate checks prices, fetched from the primary, against the other
+// sources and returns them with rejected prices replaced. A source that
+// fails to answer is logged and left out of this round.
+func (q *PriceQuorum) Validate(ctx context.Context, prices map[string]*domain.PriceSnapshot) map[string]*domain.PriceSnapshot {
+	ctx, span := q.tracer.Start(ctx, "price-quorum.validate")
+	defer span.End()
+
+	if len(q.sources) == 0 || len(prices) == 0 {
+		return prices
+	}
+	quotes := q.fetchQuotes(ctx)
+	now := q.now().UTC()
+
+	symbols := make([]string, 0, len(prices))
+	for symbol := range prices {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var discrepancies []domain.PriceDiscrepancy
+	out := make(map[string]*domain.PriceSnapshot, len(prices))
+	for _, symbol := range symbols {
+		snap := prices[symbol]
+		out[symbol] = snap
+		if snap == nil || snap.PriceUSD <= 0 {
+			continue
+		}
+		byProvider := map[string]float64{q.primary: snap.PriceUSD}
+		for name, quoted := range quotes {
+			if s, ok := quoted[symbol]; ok && s != nil && s.PriceUSD > 0 {
+				byProvider[name] = s.PriceUSD
+			}
+		}
+		found := q.outliers(symbol, byProvider, now)
+		for i, d := range found {
+			if d.Provider != q.primary || len(byProvider) < quorumMinToReject {
+				continue
+			}
+			replaced := *snap
+			replaced.PriceUSD = d.QuorumUSD
+			out[symbol] = &replaced
+			found[i].Rejected = true
+			log.Printf("price quorum: rejected %s %s price %g, %.2f%% from the median %g",
+				q.primary, symbol, d.PriceUSD, d.DeviationPct, d.QuorumUSD)
+		}
+		discrepancies = append(discrepancies, found...)
+	}
+
+	rejected := 0
+	for _, d := range discrepancies {
+		if d.Rejected {
+			rejected++
+		}
+	}
+	span.SetAttributes(
+		attribute.Int("price_quorum.sources", len(quotes)+1),
+		attribute.Int("price_quorum.discrepancies", len(discrepancies)),
+		attribute.Int("price_quorum.rejected", rejected),
+	)
+	q.record(ctx, discrepancies, now)
+	return out
+}
+
+// fetchQuotes fetches every source in parallel and returns the prices of
+// those that answered, by source name.
+func (q *PriceQuorum) fetchQuotes(ctx context.Context) map[string]map[string]*domain.PriceSnapshot {
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		out = make(map[string]map[string]*domain.PriceSnapshot, len(q.sources))
+	)
+	for _, src := range q.sources {
+		wg.Add(1)
+		go func(src quorumSource) {
+			defer wg.Done()
+			fetchCtx, cancel := context.WithTimeout(ctx, quorumFetchTimeout)
+			defer cancel()
+			prices, err := src.source.FetchPrices(fetchCtx)
+			if err != nil {
+				log.Printf("price quorum: %s fetch error: %v", src.name, err)
+				return
+			}
+			mu.Lock()
+			out[src.name] = prices
+			mu.Unlock()
+		}(src)
+	}
+	wg.Wait()
+	return out
+}
+
+// outliers compares each provider's price with the quorum and returns those
+// that differ by more than the deviation, by provider name.
+func (q *PriceQuorum) outliers(symbol string, byProvider map[string]float64, now time.Time) []domain.PriceDiscrepancy {
+	if len(byProvider) < 2 {
+		return nil
+	}
+	names := make([]string, 0, len(byProvider))
+	for name := range byProvider {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	all := make([]float64, 0, len(names))
+	for _, name := range names {
+		all = append(all, byProvider[name])
+	}
+	quorum := median(all)
+
+	var out []domain.PriceDiscrepancy
+	for _, name := range names {
+		reference := quorum
+		if len(names) < quorumMinToReject {
+			// The median of two is their midpoint, which would halve the gap.
+			for _, other := range names {
+				if other != name {
+					reference = byProvider[other]
+				}
+			}
+		}
+		deviation := math.Abs(byProvider[name]-reference) / reference * 100
+		if deviation <= q.deviationPct {
+			continue
+		}
+		out = append(out, domain.PriceDiscrepancy{
+			Symbol:       symbol,
+			Provider:     name,
+			PriceUSD:     byProvider[name],
+			QuorumUSD:    reference,
+			DeviationPct: math.Round(deviation*10000) / 10000,
+			Quotes:       byProvider,
+			ObservedAt:   now,
+		})
+	}
+	return out
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// record stores the discrepancies and, at most hourly, deletes those older
+// than the retention window.
+func (q *PriceQuorum) record(ctx context.Context, discrepancies []domain.PriceDiscrepancy, now time.Time) {
+	if q.store == nil {
+		return
+	}
+	if err := q.store.InsertDiscrepancies(ctx, discrepancies); err != nil {
+		log.Printf("price quorum: store discrepancies error: %v", err)
+	}
+	if q.retention <= 0 {
+		return
+	}
+	q.mu.Lock()
+	due := now.Sub(q.lastPruned) >= quorumPruneEvery
+	if due {
+		q.lastPruned = now
+	}
+	q.mu.Unlock()
+	if !due {
+		return
+	}
+	if _, err := q.store.DeleteDiscrepanciesBefore(ctx, now.Add(-q.retention)); err != nil {
+		log.Printf("price quorum: prune discrepancies error: %v", err)
+	}
+}
+
+// Discrepancies returns up to limit discrepancies recorded within window,
+// newest first, for symbol or, when it is empty, every symbol.
+func (q *PriceQuorum) Discrepancies(ctx context.Context, symbol string, window time.Duration, limit int) ([]domain.PriceDiscrepancy, error) {
+	ctx, span := q.tracer.Start(ctx, "price-quorum.discrepancies")
+	defer span.End()
+
+	if q.store == nil {
+		return nil, fmt.Errorf("price discrepancy store is not configured")
+	}
+	list, err := q.store.ListDiscrepancies(ctx, q.now().UTC().Add(-window), symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []domain.PriceDiscrepancy{}
+	}
+	return list, nil
+}
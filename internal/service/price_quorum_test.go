@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type quoteSourceStub struct {
+	prices map[string]float64
+	err    error
+}
+
+func (s quoteSourceStub) FetchPrices(context.Context) (map[string]*domain.PriceSnapshot, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := make(map[string]*domain.PriceSnapshot, len(s.prices))
+	for symbol, price := range s.prices {
+		out[symbol] = &domain.PriceSnapshot{Symbol: symbol, PriceUSD: price}
+	}
+	return out, nil
+}
+
+type discrepancyStoreStub struct {
+	inserted []domain.PriceDiscrepancy
+	pruned   []time.Time
+}
+
+func (s *discrepancyStoreStub) InsertDiscrepancies(_ context.Context, d []domain.PriceDiscrepancy) error {
+	s.inserted = append(s.inserted, d...)
+	return nil
+}
+
+func (s *discrepancyStoreStub) ListDiscrepancies(context.Context, time.Time, string, int) ([]domain.PriceDiscrepancy, error) {
+	return s.inserted, nil
+}
+
+func (s *discrepancyStoreStub) DeleteDiscrepanciesBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	s.pruned = append(s.pruned, cutoff)
+	return 0, nil
+}
+
+func TestPriceQuorumRejectsOutvotedPrimary(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	store := &discrepancyStoreStub{}
+	q := NewPriceQuorum(trace.NewNoopTracerProvider().Tracer("test"), "coingecko", 2, store, 24*time.Hour)
+	q.now = func() time.Time { return now }
+	q.AddSource("binance", quoteSourceStub{prices: map[string]float64{"BTC": 100, "ETH": 10, "SOL": 5}})
+	q.AddSource("kraken", quoteSourceStub{prices: map[string]float64{"BTC": 101, "ETH": 10.1}})
+	q.AddSource("coinbase", quoteSourceStub{err: errors.New("timeout")})
+
+	primary := map[string]*domain.PriceSnapshot{
+		"BTC": {Symbol: "BTC", PriceUSD: 120, Volume24h: 7},
+		"ETH": {Symbol: "ETH", PriceUSD: 10.05},
+		"SOL": {Symbol: "SOL", PriceUSD: 5.5},
+	}
+	got := q.Validate(context.Background(), primary)
+
+	if got["BTC"].PriceUSD != 101 || got["BTC"].Volume24h != 7 || primary["BTC"].PriceUSD != 120 {
+		t.Fatalf("expected BTC replaced by the median, got %+v", got["BTC"])
+	}
+	if got["ETH"].PriceUSD != 10.05 || got["SOL"].PriceUSD != 5.5 {
+		t.Fatalf("expected ETH and SOL kept, got %+v %+v", got["ETH"], got["SOL"])
+	}
+	if len(store.inserted) != 3 {
+		t.Fatalf("expected three discrepancies, got %+v", store.inserted)
+	}
+	btc, sol := store.inserted[0], store.inserted[2]
+	if btc.Provider != "coingecko" || !btc.Rejected || btc.QuorumUSD != 101 || len(btc.Quotes) != 3 || !btc.ObservedAt.Equal(now) {
+		t.Fatalf("unexpected BTC discrepancy %+v", btc)
+	}
+	if sol.Symbol != "SOL" || sol.Rejected || sol.DeviationPct != 10 {
+		t.Fatalf("expected SOL flagged but kept with two providers, got %+v", sol)
+	}
+	if len(store.pruned) != 1 || !store.pruned[0].Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("expected one prune of day-old discrepancies, got %v", store.pruned)
+	}
+
+	q.Validate(context.Background(), primary)
+	if len(store.pruned) != 1 {
+		t.Fatalf("expected pruning at most hourly, got %v", store.pruned)
+	}
+}
+
+func TestPriceQuorumFlagsSecondaryOutlier(t *testing.T) {
+	store := &discrepancyStoreStub{}
+	q := NewPriceQuorum(trace.NewNoopTracerProvider().Tracer("test"), "coingecko", 2, store, 0)
+	q.AddSource("binance", quoteSourceStub{prices: map[string]float64{"BTC": 100}})
+	q.AddSource("kraken", quoteSourceStub{prices: map[string]float64{"BTC": 90}})
+
+	got := q.Validate(context.Background(), map[string]*domain.PriceSnapshot{"BTC": {Symbol: "BTC", PriceUSD: 100.2}})
+	if got["BTC"].PriceUSD != 100.2 {
+		t.Fatalf("expected the primary kept, got %+v", got["BTC"])
+	}
+	if len(store.inserted) != 1 || store.inserted[0].Provider != "kraken" || store.inserted[0].Rejected {
+		t.Fatalf("expected only kraken flagged, got %+v", store.inserted)
+	}
+}
@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/trace"
@@ -15,6 +17,19 @@ import (
 
 const priceCacheTTL = 90 * time.Second
 
+// fxRatesTTL is how long fetched FX rates are reused. The ECB publishes
+// reference rates once per working day.
+const fxRatesTTL = time.Hour
+
+var (
+	// ErrUnsupportedCurrency is returned for a currency prices cannot be
+	// quoted in.
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	// ErrFXUnavailable is returned when no FX rate source is configured or
+	// no rate has been fetched yet.
+	ErrFXUnavailable = errors.New("fx rates unavailable")
+)
+
 // PriceService orchestrates price data fetching, caching, and retrieval.
 type PriceProvider interface {
 	FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error)
@@ -26,16 +41,39 @@ type CandleRepository interface {
 	UpsertCandles(ctx context.Context, candles []*domain.Candle) error
 }
 
+// CandleAggregator is implemented by candle stores that can roll finer
+// candles up into a coarser interval inside the database.
+type CandleAggregator interface {
+	AggregateCandles(ctx context.Context, symbol, sourceInterval, targetInterval string, limit int) ([]*domain.Candle, error)
+}
+
 type RedisClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
 	Get(ctx context.Context, key string) *redis.StringCmd
 }
 
+// PriceValidator cross-checks freshly fetched prices and returns them with
+// any it rejects replaced.
+type PriceValidator interface {
+	Validate(ctx context.Context, prices map[string]*domain.PriceSnapshot) map[string]*domain.PriceSnapshot
+}
+
+// FXRateSource supplies the units of each currency one US dollar buys.
+type FXRateSource interface {
+	FetchUSDRates(ctx context.Context, currencies []string) (*domain.FXRates, error)
+}
+
 type PriceService struct {
 	tracer   trace.Tracer
 	provider PriceProvider
 	repo     CandleRepository
 	redis    RedisClient
+	fx       FXRateSource
+	quorum   PriceValidator
+
+	fxMu      sync.Mutex
+	fxRates   *domain.FXRates
+	fxFetched time.Time
 }
 
 func NewPriceService(
@@ -52,13 +90,35 @@ func NewPriceService(
 	}
 }
 
+// SetFXSource enables GetCurrentPriceIn and GetCurrentPricesIn for
+// currencies other than USD.
+func (s *PriceService) SetFXSource(src FXRateSource) {
+	s.fx = src
+}
+
+// SetQuorum makes every price fetch from the provider pass through v before
+// it is cached or returned.
+func (s *PriceService) SetQuorum(v PriceValidator) {
+	s.quorum = v
+}
+
+// fetchPrices fetches current prices from the provider and validates them
+// against the quorum, when set.
+func (s *PriceService) fetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error) {
+	prices, err := s.provider.FetchPrices(ctx)
+	if err != nil || s.quorum == nil {
+		return prices, err
+	}
+	return s.quorum.Validate(ctx, prices), nil
+}
+
 // GetCurrentPrice returns the latest cached price for a symbol.
 // Falls back to a live API call if cache is empty/expired.
 func (s *PriceService) GetCurrentPrice(ctx context.Context, symbol string) (*domain.PriceSnapshot, error) {
 	_, span := s.tracer.Start(ctx, "price-service.get-current-price")
 	defer span.End()
 
-	if _, ok := domain.CoinGeckoID[symbol]; !ok {
+	if !domain.IsSupportedSymbol(symbol) {
 		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
 	}
 
@@ -74,7 +134,7 @@ func (s *PriceService) GetCurrentPrice(ctx context.Context, symbol string) (*dom
 	}
 
 	// Cache miss: fetch all prices (single batched API call), cache them
-	prices, err := s.provider.FetchPrices(ctx)
+	prices, err := s.fetchPrices(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +160,7 @@ func (s *PriceService) GetCurrentPrices(ctx context.Context) ([]*domain.PriceSna
 	var snapshots []*domain.PriceSnapshot
 	var missing []string
 
-	for _, symbol := range domain.SupportedSymbols {
+	for _, symbol := range domain.SupportedSymbols() {
 		if s.redis != nil {
 			cached, _ := s.getPriceCache(ctx, symbol)
 			if cached != nil {
@@ -112,7 +172,7 @@ func (s *PriceService) GetCurrentPrices(ctx context.Context) ([]*domain.PriceSna
 	}
 
 	if len(missing) > 0 {
-		prices, err := s.provider.FetchPrices(ctx)
+		prices, err := s.fetchPrices(ctx)
 		if err != nil {
 			return snapshots, err
 		}
@@ -127,9 +187,105 @@ func (s *PriceService) GetCurrentPrices(ctx context.Context) ([]*domain.PriceSna
 	return snapshots, nil
 }
 
+// GetCurrentPriceIn is GetCurrentPrice with the price also quoted in
+// currency. USD or an empty currency returns the plain USD snapshot.
+func (s *PriceService) GetCurrentPriceIn(ctx context.Context, symbol, currency string) (*domain.PriceSnapshot, error) {
+	ctx, span := s.tracer.Start(ctx, "price-service.get-current-price-in")
+	defer span.End()
+
+	currency, ok := domain.NormalizeCurrency(currency)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	snap, err := s.GetCurrentPrice(ctx, symbol)
+	if err != nil || currency == domain.CurrencyUSD {
+		return snap, err
+	}
+	rate, asOf, err := s.usdRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return snap.In(currency, rate, asOf), nil
+}
+
+// GetCurrentPricesIn is GetCurrentPrices with every price also quoted in
+// currency.
+func (s *PriceService) GetCurrentPricesIn(ctx context.Context, currency string) ([]*domain.PriceSnapshot, error) {
+	ctx, span := s.tracer.Start(ctx, "price-service.get-current-prices-in")
+	defer span.End()
+
+	currency, ok := domain.NormalizeCurrency(currency)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	if currency == domain.CurrencyUSD {
+		return s.GetCurrentPrices(ctx)
+	}
+	rate, asOf, err := s.usdRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	snapshots, err := s.GetCurrentPrices(ctx)
+	for i, snap := range snapshots {
+		snapshots[i] = snap.In(currency, rate, asOf)
+	}
+	return snapshots, err
+}
+
+// usdRate returns the units of currency one dollar buys. Rates are fetched
+// for every quote currency at once and reused for fxRatesTTL; when a
+// refresh fails the previous rates are used.
+func (s *PriceService) usdRate(ctx context.Context, currency string) (float64, time.Time, error) {
+	if s.fx == nil {
+		return 0, time.Time{}, ErrFXUnavailable
+	}
+	s.fxMu.Lock()
+	defer s.fxMu.Unlock()
+
+	if s.fxRates == nil || time.Since(s.fxFetched) >= fxRatesTTL {
+		var quotes []string
+		for _, code := range domain.QuoteCurrencies() {
+			if code != domain.CurrencyUSD {
+				quotes = append(quotes, code)
+			}
+		}
+		rates, err := s.fx.FetchUSDRates(ctx, quotes)
+		switch {
+		case err == nil:
+			s.fxRates, s.fxFetched = rates, time.Now()
+		case s.fxRates == nil:
+			return 0, time.Time{}, fmt.Errorf("%w: %v", ErrFXUnavailable, err)
+		default:
+			log.Printf("fx rates refresh error, using rates from %s: %v", s.fxRates.AsOf.Format("2006-01-02"), err)
+		}
+	}
+	rate, ok := s.fxRates.Rates[currency]
+	if !ok || rate <= 0 {
+		return 0, time.Time{}, fmt.Errorf("%w: no rate for %s", ErrFXUnavailable, currency)
+	}
+	return rate, s.fxRates.AsOf, nil
+}
+
 // GetCandles returns historical candles for a symbol and interval from Postgres.
+// When none are stored and the repository supports it, they are aggregated
+// from the next finer interval instead.
 func (s *PriceService) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
-	return s.repo.GetCandles(ctx, symbol, interval, limit)
+	candles, err := s.repo.GetCandles(ctx, symbol, interval, limit)
+	if err != nil || len(candles) > 0 {
+		return candles, err
+	}
+	aggregator, ok := s.repo.(CandleAggregator)
+	info, known := domain.LookupInterval(interval)
+	if !ok || !known || info.AggregateFrom == "" {
+		return candles, nil
+	}
+	return aggregator.AggregateCandles(ctx, symbol, info.AggregateFrom, interval, limit)
+}
+
+// KnownSymbol reports whether candles can be served for symbol: it is
+// tracked by the price provider or has candles ingested from a collector.
+func (s *PriceService) KnownSymbol(ctx context.Context, symbol string) bool {
+	return knownSymbol(ctx, s.repo, symbol)
 }
 
 // RefreshPrices fetches latest prices from CoinGecko and caches in Redis.
@@ -137,12 +293,35 @@ func (s *PriceService) RefreshPrices(ctx context.Context) error {
 	_, span := s.tracer.Start(ctx, "price-service.refresh-prices")
 	defer span.End()
 
-	prices, err := s.provider.FetchPrices(ctx)
+	return s.refreshPrices(ctx, nil)
+}
+
+// RefreshPricesFor fetches latest prices like RefreshPrices but caches only
+// symbols, so prices kept fresh by the stream are not overwritten.
+func (s *PriceService) RefreshPricesFor(ctx context.Context, symbols []string) error {
+	_, span := s.tracer.Start(ctx, "price-service.refresh-prices-for")
+	defer span.End()
+
+	only := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		only[symbol] = true
+	}
+	return s.refreshPrices(ctx, only)
+}
+
+// refreshPrices caches the fetched prices, all of them when only is nil.
+func (s *PriceService) refreshPrices(ctx context.Context, only map[string]bool) error {
+	prices, err := s.fetchPrices(ctx)
 	if err != nil {
 		return err
 	}
 
+	refreshed := 0
 	for _, snap := range prices {
+		if only != nil && !only[snap.Symbol] {
+			continue
+		}
+		refreshed++
 		if s.redis != nil {
 			if err := s.setPriceCache(ctx, snap); err != nil {
 				log.Printf("redis cache write error for %s: %v", snap.Symbol, err)
@@ -150,16 +329,26 @@ func (s *PriceService) RefreshPrices(ctx context.Context) error {
 		}
 	}
 
-	log.Printf("Refreshed prices for %d assets", len(prices))
+	log.Printf("Refreshed prices for %d assets", refreshed)
 	return nil
 }
 
-// RefreshShortCandles fetches market_chart data (days=1) and stores 5m, 15m, 1h candles.
+// ApplyPriceTick caches a streamed price update, as RefreshPrices does for
+// polled ones.
+func (s *PriceService) ApplyPriceTick(ctx context.Context, snapshot *domain.PriceSnapshot) error {
+	if s.redis == nil || snapshot == nil {
+		return nil
+	}
+	return s.setPriceCache(ctx, snapshot)
+}
+
+// RefreshShortCandles fetches market_chart data (days=1) and stores the
+// short-tier intervals (5m, 15m, 1h).
 func (s *PriceService) RefreshShortCandles(ctx context.Context, symbol string) error {
 	_, span := s.tracer.Start(ctx, "price-service.refresh-short-candles")
 	defer span.End()
 
-	candles, err := s.provider.FetchMarketChart(ctx, symbol, 1, []string{"5m", "15m", "1h"})
+	candles, err := s.provider.FetchMarketChart(ctx, symbol, 1, domain.IntervalsInTier(domain.IntervalTierShort))
 	if err != nil {
 		return err
 	}
@@ -172,12 +361,13 @@ func (s *PriceService) RefreshShortCandles(ctx context.Context, symbol string) e
 	return nil
 }
 
-// RefreshLongCandles fetches market_chart data (days=30) and stores 4h, 1d candles.
+// RefreshLongCandles fetches market_chart data (days=30) and stores the
+// long-tier intervals (4h, 1d).
 func (s *PriceService) RefreshLongCandles(ctx context.Context, symbol string) error {
 	_, span := s.tracer.Start(ctx, "price-service.refresh-long-candles")
 	defer span.End()
 
-	candles, err := s.provider.FetchMarketChart(ctx, symbol, 30, []string{"4h", "1d"})
+	candles, err := s.provider.FetchMarketChart(ctx, symbol, 30, domain.IntervalsInTier(domain.IntervalTierLong))
 	if err != nil {
 		return err
 	}
@@ -190,6 +380,23 @@ func (s *PriceService) RefreshLongCandles(ctx context.Context, symbol string) er
 	return nil
 }
 
+// BackfillCandles fetches days of market_chart history for symbol and stores
+// the requested intervals. Used to seed history, e.g. in demo mode.
+func (s *PriceService) BackfillCandles(ctx context.Context, symbol string, days int, intervals []string) (int, error) {
+	_, span := s.tracer.Start(ctx, "price-service.backfill-candles")
+	defer span.End()
+
+	candles, err := s.provider.FetchMarketChart(ctx, symbol, days, intervals)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.repo.UpsertCandles(ctx, candles); err != nil {
+		return 0, fmt.Errorf("upsert backfill candles for %s: %w", symbol, err)
+	}
+	return len(candles), nil
+}
+
 func (s *PriceService) setPriceCache(ctx context.Context, snapshot *domain.PriceSnapshot) error {
 	data, err := json.Marshal(snapshot)
 	if err != nil {
@@ -3,10 +3,11 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel/trace"
@@ -77,7 +78,7 @@ func TestPriceService_GetCurrentPricesUsesCache(t *testing.T) {
 	_ = redis.Set(context.Background(), "price:BTC", data, 0)
 
 	prices := make(map[string]*domain.PriceSnapshot)
-	for _, symbol := range domain.SupportedSymbols {
+	for _, symbol := range domain.SupportedSymbols() {
 		if symbol == "BTC" {
 			continue
 		}
@@ -94,8 +95,8 @@ func TestPriceService_GetCurrentPricesUsesCache(t *testing.T) {
 	if provider.fetchPricesCalls != 1 {
 		t.Fatalf("expected fetch once, got %d", provider.fetchPricesCalls)
 	}
-	if len(snapshots) != len(domain.SupportedSymbols) {
-		t.Fatalf("expected %d snapshots, got %d", len(domain.SupportedSymbols), len(snapshots))
+	if len(snapshots) != len(domain.SupportedSymbols()) {
+		t.Fatalf("expected %d snapshots, got %d", len(domain.SupportedSymbols()), len(snapshots))
 	}
 }
 
@@ -122,6 +123,26 @@ func TestPriceService_RefreshPricesCachesAll(t *testing.T) {
 	}
 }
 
+func TestPriceService_RefreshPricesForCachesOnlyGivenSymbols(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{
+		prices: map[string]*domain.PriceSnapshot{
+			"BTC": {Symbol: "BTC", PriceUSD: 10},
+			"ETH": {Symbol: "ETH", PriceUSD: 20},
+		},
+	}
+	redis := newFakeRedis()
+	svc := NewPriceService(testTracer, provider, &mockCandleRepo{}, redis)
+
+	if err := svc.RefreshPricesFor(context.Background(), []string{"ETH"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := redis.data["price:BTC"]; ok || len(redis.data) != 1 {
+		t.Fatalf("expected only ETH cached, got %v", redis.data)
+	}
+}
+
 func TestPriceService_RefreshShortCandles(t *testing.T) {
 	t.Parallel()
 
@@ -160,6 +181,26 @@ func TestPriceService_RefreshLongCandles(t *testing.T) {
 	}
 }
 
+func TestPriceService_BackfillCandles(t *testing.T) {
+	t.Parallel()
+
+	candles := []*domain.Candle{{Symbol: "ETH", Interval: "1h"}, {Symbol: "ETH", Interval: "4h"}}
+	provider := &mockProvider{marketCandles: candles}
+	repo := &mockCandleRepo{}
+	svc := NewPriceService(testTracer, provider, repo, nil)
+
+	n, err := svc.BackfillCandles(context.Background(), "ETH", 90, []string{"1h", "4h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || repo.upsertCalls != 1 {
+		t.Fatalf("expected 2 candles in one upsert, got n=%d calls=%d", n, repo.upsertCalls)
+	}
+	if provider.lastMarketDays != 90 || len(provider.lastMarketIntervals) != 2 {
+		t.Fatalf("unexpected provider args: days=%d intervals=%v", provider.lastMarketDays, provider.lastMarketIntervals)
+	}
+}
+
 func TestPriceService_GetCandles(t *testing.T) {
 	t.Parallel()
 
@@ -180,6 +221,136 @@ func TestPriceService_GetCandles(t *testing.T) {
 	}
 }
 
+func TestPriceService_GetCandlesAggregatesWhenEmpty(t *testing.T) {
+	t.Parallel()
+
+	repo := &aggregatingCandleRepo{
+		aggResp: []*domain.Candle{{Symbol: "BTC", Interval: "4h"}},
+	}
+	svc := NewPriceService(testTracer, &mockProvider{}, repo, nil)
+
+	candles, err := svc.GetCandles(context.Background(), "BTC", "4h", 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastAggSource != "1h" || repo.lastAggTarget != "4h" || repo.lastAggLimit != 5 {
+		t.Fatalf("unexpected aggregate args: %s %s %d", repo.lastAggSource, repo.lastAggTarget, repo.lastAggLimit)
+	}
+	if len(candles) != 1 || candles[0].Interval != "4h" {
+		t.Fatalf("unexpected candles: %+v", candles)
+	}
+
+	repo.getResp = []*domain.Candle{{Symbol: "BTC", Interval: "4h"}}
+	repo.lastAggSource = ""
+	if _, err := svc.GetCandles(context.Background(), "BTC", "4h", 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastAggSource != "" {
+		t.Fatal("expected stored candles to skip aggregation")
+	}
+}
+
+type aggregatingCandleRepo struct {
+	mockCandleRepo
+	aggResp []*domain.Candle
+
+	lastAggSource string
+	lastAggTarget string
+	lastAggLimit  int
+}
+
+func (m *aggregatingCandleRepo) AggregateCandles(ctx context.Context, symbol, sourceInterval, targetInterval string, limit int) ([]*domain.Candle, error) {
+	m.lastAggSource = sourceInterval
+	m.lastAggTarget = targetInterval
+	m.lastAggLimit = limit
+	return m.aggResp, nil
+}
+
+func TestPriceService_GetCurrentPriceIn(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{prices: map[string]*domain.PriceSnapshot{
+		"BTC": {Symbol: "BTC", PriceUSD: 100_000, Volume24h: 2_000},
+		"ETH": {Symbol: "ETH", PriceUSD: 3_000},
+	}}
+	svc := NewPriceService(testTracer, provider, &mockCandleRepo{}, nil)
+
+	if _, err := svc.GetCurrentPriceIn(context.Background(), "BTC", "EUR"); !errors.Is(err, ErrFXUnavailable) {
+		t.Fatalf("expected ErrFXUnavailable without a source, got %v", err)
+	}
+
+	fx := &mockFXSource{rates: &domain.FXRates{
+		Rates: map[string]float64{"EUR": 0.92, "GBP": 0.79, "JPY": 150},
+		AsOf:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+	}}
+	svc.SetFXSource(fx)
+
+	got, err := svc.GetCurrentPriceIn(context.Background(), "BTC", "eur")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.PriceUSD != 100_000 || got.Quote == nil || got.Quote.Currency != "EUR" || got.Quote.Price != 92_000 ||
+		got.Quote.Volume24h != 1_840 || got.Quote.RateDate != "2026-03-02" {
+		t.Fatalf("unexpected EUR snapshot: %+v %+v", got, got.Quote)
+	}
+	if provider.prices["BTC"].Quote != nil {
+		t.Fatal("expected the cached snapshot left unchanged")
+	}
+
+	all, err := svc.GetCurrentPricesIn(context.Background(), "JPY")
+	if err != nil || len(all) != 2 {
+		t.Fatalf("unexpected JPY prices: %v %+v", err, all)
+	}
+	for _, snap := range all {
+		if snap.Quote == nil || snap.Quote.Price != snap.PriceUSD*150 {
+			t.Fatalf("unexpected JPY quote: %+v", snap)
+		}
+	}
+	if fx.calls != 1 {
+		t.Fatalf("expected the rates fetched once, got %d", fx.calls)
+	}
+
+	if usd, err := svc.GetCurrentPriceIn(context.Background(), "ETH", ""); err != nil || usd.Quote != nil {
+		t.Fatalf("expected a plain USD snapshot, got %+v %v", usd, err)
+	}
+	if _, err := svc.GetCurrentPriceIn(context.Background(), "BTC", "CHF"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Fatalf("expected ErrUnsupportedCurrency, got %v", err)
+	}
+}
+
+func TestPriceService_UsesStaleFXRatesWhenRefreshFails(t *testing.T) {
+	t.Parallel()
+
+	provider := &mockProvider{prices: map[string]*domain.PriceSnapshot{"BTC": {Symbol: "BTC", PriceUSD: 100}}}
+	fx := &mockFXSource{rates: &domain.FXRates{Rates: map[string]float64{"GBP": 0.8}}}
+	svc := NewPriceService(testTracer, provider, &mockCandleRepo{}, nil)
+	svc.SetFXSource(fx)
+
+	if _, err := svc.GetCurrentPriceIn(context.Background(), "BTC", "GBP"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.fxFetched = time.Now().Add(-2 * fxRatesTTL)
+	fx.err = errors.New("fx down")
+	got, err := svc.GetCurrentPriceIn(context.Background(), "BTC", "GBP")
+	if err != nil || got.Quote.Price != 80 || fx.calls != 2 {
+		t.Fatalf("expected the previous rate after a failed refresh, got %+v %v calls=%d", got, err, fx.calls)
+	}
+}
+
+type mockFXSource struct {
+	rates *domain.FXRates
+	err   error
+	calls int
+}
+
+func (m *mockFXSource) FetchUSDRates(ctx context.Context, currencies []string) (*domain.FXRates, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.rates, nil
+}
+
 type mockProvider struct {
 	prices        map[string]*domain.PriceSnapshot
 	marketCandles []*domain.Candle
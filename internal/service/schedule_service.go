@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"bug-free-umbrella/internal/ical"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	scheduleHistoryDays = 30
+	scheduleUIDDomain   = "bug-free-umbrella"
+	// modelEventLength is how long registry events are drawn; they are
+	// instants, but most calendars hide zero-length events.
+	modelEventLength = 15 * time.Minute
+)
+
+// ModelEventSource lists model registry history for the schedule feed.
+type ModelEventSource interface {
+	ListModelEvents(ctx context.Context, since time.Time) ([]domain.ModelEvent, error)
+}
+
+// ScheduleConfig describes the recurring work the feed announces.
+type ScheduleConfig struct {
+	TrainingEnabled bool
+	TrainHourUTC    int
+	TrainWindowDays int
+	Maintenance     []domain.MaintenanceWindow
+}
+
+// ScheduleService builds the iCal feed of training runs, planned maintenance
+// and model promotions.
+type ScheduleService struct {
+	tracer trace.Tracer
+	cfg    ScheduleConfig
+	events ModelEventSource
+	now    func() time.Time
+}
+
+func NewScheduleService(tracer trace.Tracer, cfg ScheduleConfig) *ScheduleService {
+	return &ScheduleService{tracer: tracer, cfg: cfg, now: time.Now}
+}
+
+// SetModelEvents adds past training runs and promotions to the feed.
+func (s *ScheduleService) SetModelEvents(src ModelEventSource) {
+	s.events = src
+}
+
+// Calendar returns the feed as an iCalendar document. Registry history covers
+// the last 30 days; when it cannot be read the feed is served without it.
+func (s *ScheduleService) Calendar(ctx context.Context) ([]byte, error) {
+	ctx, span := s.tracer.Start(ctx, "schedule-service.calendar")
+	defer span.End()
+
+	now := s.now().UTC()
+	since := now.AddDate(0, 0, -scheduleHistoryDays)
+	cal := ical.Calendar{
+		ProdID: "-//bug-free-umbrella//schedule//EN",
+		Name:   "bug-free-umbrella schedule",
+		Stamp:  now,
+	}
+
+	if s.cfg.TrainingEnabled {
+		start := time.Date(since.Year(), since.Month(), since.Day(), s.cfg.TrainHourUTC, 0, 0, 0, time.UTC)
+		cal.Events = append(cal.Events, ical.Event{
+			UID:     "ml-training@" + scheduleUIDDomain,
+			Summary: "ML training",
+			Description: fmt.Sprintf("Daily retrain on the last %d days of candles. "+
+				"New versions are promoted when they beat the active model. Runtime varies.", s.cfg.TrainWindowDays),
+			Start:      start,
+			End:        start.Add(time.Hour),
+			RRule:      "FREQ=DAILY",
+			Categories: []string{"ml-training"},
+		})
+	}
+
+	for _, w := range s.cfg.Maintenance {
+		summary := "Planned maintenance"
+		if w.Note != "" {
+			summary += ": " + w.Note
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:        fmt.Sprintf("maintenance-%d@%s", w.Start.Unix(), scheduleUIDDomain),
+			Summary:    summary,
+			Start:      w.Start,
+			End:        w.Start.Add(w.Duration),
+			Categories: []string{"maintenance"},
+		})
+	}
+
+	if s.events != nil {
+		history, err := s.events.ListModelEvents(ctx, since)
+		if err != nil {
+			log.Printf("schedule feed: model events unavailable: %v", err)
+		}
+		for _, e := range history {
+			cal.Events = append(cal.Events, modelEventToICal(e))
+		}
+	}
+
+	return cal.Encode(), nil
+}
+
+func modelEventToICal(e domain.ModelEvent) ical.Event {
+	window := fmt.Sprintf("Training window %s to %s (UTC).",
+		e.TrainedFrom.Format("2006-01-02"), e.TrainedTo.Format("2006-01-02"))
+	out := ical.Event{
+		UID:         fmt.Sprintf("%s-%s-v%d@%s", e.Kind, e.ModelKey, e.Version, scheduleUIDDomain),
+		Description: window,
+		Start:       e.At,
+		End:         e.At.Add(modelEventLength),
+	}
+	switch e.Kind {
+	case domain.ModelEventPromoted:
+		out.Summary = fmt.Sprintf("Promoted %s v%d", e.ModelKey, e.Version)
+		out.Categories = []string{"model-promotion"}
+	default:
+		out.Summary = fmt.Sprintf("Trained %s v%d", e.ModelKey, e.Version)
+		out.Categories = []string{"ml-training"}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type modelEventSourceStub struct {
+	events []domain.ModelEvent
+	since  time.Time
+	err    error
+}
+
+func (s *modelEventSourceStub) ListModelEvents(ctx context.Context, since time.Time) ([]domain.ModelEvent, error) {
+	s.since = since
+	return s.events, s.err
+}
+
+func TestScheduleServiceCalendar(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	trainedAt := time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC)
+	svc := NewScheduleService(trace.NewNoopTracerProvider().Tracer("test"), ScheduleConfig{
+		TrainingEnabled: true,
+		TrainHourUTC:    3,
+		TrainWindowDays: 90,
+		Maintenance: []domain.MaintenanceWindow{
+			{Start: time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC), Duration: 2 * time.Hour, Note: "Postgres upgrade"},
+		},
+	})
+	svc.now = func() time.Time { return now }
+	src := &modelEventSourceStub{events: []domain.ModelEvent{
+		{Kind: domain.ModelEventTrained, ModelKey: "ml_logreg_up4h", Version: 7, At: trainedAt,
+			TrainedFrom: trainedAt.AddDate(0, 0, -90), TrainedTo: trainedAt},
+		{Kind: domain.ModelEventPromoted, ModelKey: "ml_logreg_up4h", Version: 7, At: trainedAt.Add(time.Minute),
+			TrainedFrom: trainedAt.AddDate(0, 0, -90), TrainedTo: trainedAt},
+	}}
+	svc.SetModelEvents(src)
+
+	data, err := svc.Calendar(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"UID:ml-training@bug-free-umbrella",
+		"DTSTART:20260916T030000Z",
+		"RRULE:FREQ=DAILY",
+		"SUMMARY:Planned maintenance: Postgres upgrade",
+		"DTEND:20261101T040000Z",
+		"SUMMARY:Trained ml_logreg_up4h v7",
+		"SUMMARY:Promoted ml_logreg_up4h v7",
+		"UID:promoted-ml_logreg_up4h-v7@bug-free-umbrella",
+		"CATEGORIES:model-promotion",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in feed:\n%s", want, out)
+		}
+	}
+	if !src.since.Equal(now.AddDate(0, 0, -30)) {
+		t.Fatalf("expected 30 days of history, got since=%s", src.since)
+	}
+}
+
+func TestScheduleServiceCalendarWithoutHistory(t *testing.T) {
+	svc := NewScheduleService(trace.NewNoopTracerProvider().Tracer("test"), ScheduleConfig{})
+	svc.SetModelEvents(&modelEventSourceStub{err: errors.New("db down")})
+
+	data, err := svc.Calendar(context.Background())
+	if err != nil {
+		t.Fatalf("expected feed despite history error, got %v", err)
+	}
+	if out := string(data); strings.Contains(out, "BEGIN:VEVENT") || !strings.Contains(out, "END:VCALENDAR") {
+		t.Fatalf("expected an empty calendar, got:\n%s", out)
+	}
+}
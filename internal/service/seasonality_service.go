@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultSeasonalityDays is the history Seasonality looks at by default.
+	DefaultSeasonalityDays = 365
+	// MaxSeasonalityDays caps the history one request can scan.
+	MaxSeasonalityDays = 1095
+)
+
+// ErrInvalidSeasonalityQuery is returned by SeasonalityService.Seasonality
+// for an unsupported interval or window.
+var ErrInvalidSeasonalityQuery = errors.New("invalid seasonality query")
+
+type SeasonalityCandleReader interface {
+	GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error)
+}
+
+// SeasonalityService reports how a symbol's returns vary with the calendar.
+type SeasonalityService struct {
+	tracer  trace.Tracer
+	candles SeasonalityCandleReader
+	now     func() time.Time
+}
+
+func NewSeasonalityService(tracer trace.Tracer, candles SeasonalityCandleReader) *SeasonalityService {
+	return &SeasonalityService{tracer: tracer, candles: candles, now: time.Now}
+}
+
+// Seasonality averages the close-to-close return of each stored candle of
+// symbol and interval over the last days, bucketed by the UTC hour, weekday
+// and month the candle opened in. A candle counts only when the candle right
+// before it is stored too, so gaps do not show up as one large move.
+func (s *SeasonalityService) Seasonality(ctx context.Context, symbol, interval string, days int) (*domain.Seasonality, error) {
+	ctx, span := s.tracer.Start(ctx, "seasonality-service.seasonality")
+	defer span.End()
+
+	step := domain.IntervalDuration(interval)
+	if step == 0 {
+		return nil, fmt.Errorf("%w: unsupported interval %s", ErrInvalidSeasonalityQuery, interval)
+	}
+	if days <= 0 || days > MaxSeasonalityDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidSeasonalityQuery, MaxSeasonalityDays)
+	}
+
+	to := s.now().UTC()
+	from := to.AddDate(0, 0, -days)
+	candles, err := s.candles.GetCandlesInRange(ctx, symbol, interval, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]*domain.Candle, 0, len(candles))
+	for _, c := range candles {
+		if c != nil {
+			sorted = append(sorted, c)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].OpenTime.Before(sorted[j].OpenTime) })
+
+	hours := newSeasonalityBuckets(24, func(b int) string { return fmt.Sprintf("%02d:00", b) })
+	weekdays := newSeasonalityBuckets(7, func(b int) string { return time.Weekday(b).String()[:3] })
+	months := newSeasonalityBuckets(12, func(b int) string { return time.Month(b + 1).String()[:3] })
+
+	out := &domain.Seasonality{Symbol: symbol, Interval: interval, From: from, To: to}
+	for i := 1; i < len(sorted); i++ {
+		prev, cur := sorted[i-1], sorted[i]
+		if prev.Close == 0 || cur.OpenTime.Sub(prev.OpenTime) != step {
+			continue
+		}
+		ret := cur.Close/prev.Close - 1
+		at := cur.OpenTime.UTC()
+		hours.add(at.Hour(), ret)
+		weekdays.add(int(at.Weekday()), ret)
+		months.add(int(at.Month()-time.January), ret)
+		out.Samples++
+	}
+
+	out.HourOfDay = hours.summary()
+	if step >= 24*time.Hour {
+		out.HourOfDay = []domain.SeasonalityBucket{}
+	}
+	out.DayOfWeek = weekdays.summary()
+	out.Month = months.summary()
+	return out, nil
+}
+
+type seasonalityBuckets struct {
+	buckets []domain.SeasonalityBucket
+	sums    []float64
+	ups     []int
+}
+
+func newSeasonalityBuckets(n int, label func(int) string) *seasonalityBuckets {
+	b := &seasonalityBuckets{
+		buckets: make([]domain.SeasonalityBucket, n),
+		sums:    make([]float64, n),
+		ups:     make([]int, n),
+	}
+	for i := range b.buckets {
+		b.buckets[i] = domain.SeasonalityBucket{Bucket: i, Label: label(i)}
+	}
+	return b
+}
+
+func (b *seasonalityBuckets) add(bucket int, ret float64) {
+	b.buckets[bucket].Samples++
+	b.sums[bucket] += ret
+	if ret > 0 {
+		b.ups[bucket]++
+	}
+}
+
+func (b *seasonalityBuckets) summary() []domain.SeasonalityBucket {
+	for i := range b.buckets {
+		if n := b.buckets[i].Samples; n > 0 {
+			b.buckets[i].MeanReturn = b.sums[i] / float64(n)
+			b.buckets[i].UpRate = float64(b.ups[i]) / float64(n)
+		}
+	}
+	return b.buckets
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type seasonalityCandlesStub struct {
+	candles  []*domain.Candle
+	from, to time.Time
+}
+
+func (s *seasonalityCandlesStub) GetCandlesInRange(_ context.Context, _, _ string, from, to time.Time) ([]*domain.Candle, error) {
+	s.from, s.to = from, to
+	return s.candles, nil
+}
+
+func TestSeasonalityBucketsReturns(t *testing.T) {
+	// Saturday 2026-03-07: 09:00 -> 10:00 up 2%, 10:00 -> 11:00 down 1%,
+	// then a gap before Sunday 14:00 -> 15:00 up 1%.
+	sat := time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)
+	sun := time.Date(2026, 3, 8, 14, 0, 0, 0, time.UTC)
+	candles := &seasonalityCandlesStub{candles: []*domain.Candle{
+		{OpenTime: sun.Add(time.Hour), Close: 101},
+		{OpenTime: sat, Close: 100},
+		{OpenTime: sat.Add(time.Hour), Close: 102},
+		{OpenTime: sat.Add(2 * time.Hour), Close: 100.98},
+		{OpenTime: sun, Close: 100},
+		nil,
+	}}
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	svc := NewSeasonalityService(trace.NewNoopTracerProvider().Tracer("test"), candles)
+	svc.now = func() time.Time { return now }
+
+	out, err := svc.Seasonality(context.Background(), "BTC", "1h", 30)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !candles.from.Equal(now.AddDate(0, 0, -30)) || !candles.to.Equal(now) {
+		t.Fatalf("expected a 30 day window, got %s..%s", candles.from, candles.to)
+	}
+	if out.Samples != 3 || len(out.HourOfDay) != 24 || len(out.DayOfWeek) != 7 || len(out.Month) != 12 {
+		t.Fatalf("unexpected shape: samples=%d hours=%d days=%d months=%d", out.Samples, len(out.HourOfDay), len(out.DayOfWeek), len(out.Month))
+	}
+
+	saturday := out.DayOfWeek[time.Saturday]
+	if saturday.Label != "Sat" || saturday.Samples != 2 || math.Abs(saturday.MeanReturn-0.005) > 1e-9 || saturday.UpRate != 0.5 {
+		t.Fatalf("unexpected Saturday bucket %+v", saturday)
+	}
+	sunday := out.DayOfWeek[time.Sunday]
+	if sunday.Samples != 1 || math.Abs(sunday.MeanReturn-0.01) > 1e-9 || sunday.UpRate != 1 {
+		t.Fatalf("unexpected Sunday bucket %+v", sunday)
+	}
+	if h := out.HourOfDay[10]; h.Label != "10:00" || h.Samples != 1 || math.Abs(h.MeanReturn-0.02) > 1e-9 {
+		t.Fatalf("unexpected 10:00 bucket %+v", h)
+	}
+	if out.HourOfDay[14].Samples != 0 {
+		t.Fatalf("expected the candle after a gap to be skipped, got %+v", out.HourOfDay[14])
+	}
+	if m := out.Month[2]; m.Label != "Mar" || m.Samples != 3 {
+		t.Fatalf("unexpected March bucket %+v", m)
+	}
+}
+
+func TestSeasonalityDailyHasNoHours(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	svc := NewSeasonalityService(trace.NewNoopTracerProvider().Tracer("test"), &seasonalityCandlesStub{candles: []*domain.Candle{
+		{OpenTime: day, Close: 100},
+		{OpenTime: day.Add(24 * time.Hour), Close: 101},
+	}})
+	out, err := svc.Seasonality(context.Background(), "BTC", "1d", DefaultSeasonalityDays)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out.HourOfDay) != 0 || out.Samples != 1 {
+		t.Fatalf("expected daily candles without hour buckets, got %+v", out)
+	}
+}
+
+func TestSeasonalityRejectsInvalidQuery(t *testing.T) {
+	svc := NewSeasonalityService(trace.NewNoopTracerProvider().Tracer("test"), &seasonalityCandlesStub{})
+	for _, tc := range []struct {
+		interval string
+		days     int
+	}{
+		{"2h", 30},
+		{"1h", 0},
+		{"1h", MaxSeasonalityDays + 1},
+	} {
+		if _, err := svc.Seasonality(context.Background(), "BTC", tc.interval, tc.days); !errors.Is(err, ErrInvalidSeasonalityQuery) {
+			t.Fatalf("%s/%d: expected ErrInvalidSeasonalityQuery, got %v", tc.interval, tc.days, err)
+		}
+	}
+}
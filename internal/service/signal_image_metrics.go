@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// signalImageMetrics publishes signal chart render and cleanup counts as
+// OpenTelemetry counters. Instruments that fail to register stay nil and are
+// skipped.
+type signalImageMetrics struct {
+	renders metric.Int64Counter
+	bytes   metric.Int64Counter
+	deletes metric.Int64Counter
+}
+
+func newSignalImageMetrics() signalImageMetrics {
+	meter := otel.Meter("bug-free-umbrella/service")
+	var m signalImageMetrics
+	var err error
+	if m.renders, err = meter.Int64Counter("signal_images.renders",
+		metric.WithDescription("Signal chart renders by status (succeeded, failed)"),
+	); err != nil {
+		log.Printf("signal image renders counter: %v", err)
+	}
+	if m.bytes, err = meter.Int64Counter("signal_images.bytes_stored",
+		metric.WithDescription("Bytes of signal chart images written"),
+		metric.WithUnit("By"),
+	); err != nil {
+		log.Printf("signal image bytes counter: %v", err)
+	}
+	if m.deletes, err = meter.Int64Counter("signal_images.deleted",
+		metric.WithDescription("Signal chart images deleted by reason (expired, purged)"),
+	); err != nil {
+		log.Printf("signal image deletes counter: %v", err)
+	}
+	return m
+}
+
+func (m signalImageMetrics) rendered(ctx context.Context, size int) {
+	if m.renders != nil {
+		m.renders.Add(ctx, 1, metric.WithAttributes(attribute.String("status", "succeeded")))
+	}
+	if m.bytes != nil {
+		m.bytes.Add(ctx, int64(size))
+	}
+}
+
+func (m signalImageMetrics) failed(ctx context.Context) {
+	if m.renders != nil {
+		m.renders.Add(ctx, 1, metric.WithAttributes(attribute.String("status", "failed")))
+	}
+}
+
+func (m signalImageMetrics) deleted(ctx context.Context, n int64, reason string) {
+	if m.deletes != nil && n > 0 {
+		m.deletes.Add(ctx, n, metric.WithAttributes(attribute.String("reason", reason)))
+	}
+}
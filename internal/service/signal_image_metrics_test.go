@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestSignalImageMetricsUseGlobalMeterProvider(t *testing.T) {
+	prev := otel.GetMeterProvider()
+	t.Cleanup(func() { otel.SetMeterProvider(prev) })
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+	m := newSignalImageMetrics()
+	m.rendered(ctx, 1024)
+	m.failed(ctx)
+	m.deleted(ctx, 3, "expired")
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	sums := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, md := range sm.Metrics {
+			if sum, ok := md.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					sums[md.Name] += dp.Value
+				}
+			}
+		}
+	}
+	if sums["signal_images.renders"] != 2 || sums["signal_images.bytes_stored"] != 1024 || sums["signal_images.deleted"] != 3 {
+		t.Fatalf("unexpected sums: %v", sums)
+	}
+}
@@ -2,23 +2,33 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	signalLookbackCandles = 250
-	signalImageTTL        = 24 * time.Hour
-	signalImageRetryDelay = 5 * time.Minute
-	defaultImageRetryMax  = 3
+	signalLookbackCandles = domain.SignalLookbackCandles
+
+	DefaultSignalImageTTL        = 24 * time.Hour
+	DefaultSignalImageRetryDelay = 5 * time.Minute
+	DefaultSignalImageMaxRetries = 3
+
+	DefaultWinRateHorizon = 4
+	MaxWinRateHorizon     = 100
 )
 
+// ErrSignalImagesUnavailable is returned when signal images are not stored,
+// such as on the SQLite backend.
+var ErrSignalImagesUnavailable = errors.New("signal images are not stored")
+
 type SignalCandleRepository interface {
 	GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error)
 }
@@ -28,6 +38,12 @@ type SignalRepository interface {
 	ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error)
 }
 
+// SignalStatsRepository is implemented by signal stores that can score
+// stored signals against the candles that followed them.
+type SignalStatsRepository interface {
+	WinRatesByVersion(ctx context.Context, indicator string, horizon int) ([]domain.SignalWinRate, error)
+}
+
 type SignalEngine interface {
 	Generate(candles []*domain.Candle) []domain.Signal
 }
@@ -50,13 +66,28 @@ type SignalImageRepository interface {
 	) error
 	GetSignalImageBySignalID(ctx context.Context, signalID int64) (*domain.SignalImageData, error)
 	ListRetryCandidates(ctx context.Context, limit int, maxRetryCount int) ([]domain.Signal, error)
-	DeleteExpiredSignalImages(ctx context.Context) (int64, error)
+	DeleteExpiredSignalImages(ctx context.Context, limit int) (int64, error)
+	DeleteAllSignalImages(ctx context.Context) (int64, error)
+}
+
+// SignalImageOptions tune how long rendered charts are kept and how failed
+// renders are retried. Zero fields keep the defaults.
+type SignalImageOptions struct {
+	TTL        time.Duration
+	RetryDelay time.Duration
+	MaxRetries int
 }
 
 type SignalChartRenderer interface {
 	RenderSignalChart(candles []*domain.Candle, signal domain.Signal) (*domain.SignalImageData, error)
 }
 
+// SignalRenderQueue takes chart renders off the generating goroutine. Queued
+// renders come back through RenderSignalImages.
+type SignalRenderQueue interface {
+	EnqueueSignalImages(ctx context.Context, signals []domain.Signal) error
+}
+
 type SignalService struct {
 	tracer        trace.Tracer
 	candleRepo    SignalCandleRepository
@@ -64,7 +95,17 @@ type SignalService struct {
 	engine        SignalEngine
 	imageRepo     SignalImageRepository
 	chartRender   SignalChartRenderer
+	renderQueue   SignalRenderQueue
+	imageTTL      time.Duration
+	retryDelay    time.Duration
 	maxImageRetry int
+	imageMetrics  signalImageMetrics
+	now           func() time.Time
+
+	// processed records the newest candle open time already run through the
+	// engine per symbol and interval, so polling skips unchanged intervals.
+	processedMu sync.Mutex
+	processed   map[string]time.Time
 }
 
 func NewSignalService(
@@ -91,11 +132,58 @@ func NewSignalServiceWithImages(
 		engine:        engine,
 		imageRepo:     imageRepo,
 		chartRender:   chartRender,
-		maxImageRetry: defaultImageRetryMax,
+		imageTTL:      DefaultSignalImageTTL,
+		retryDelay:    DefaultSignalImageRetryDelay,
+		maxImageRetry: DefaultSignalImageMaxRetries,
+		imageMetrics:  newSignalImageMetrics(),
+		now:           time.Now,
+		processed:     make(map[string]time.Time),
+	}
+}
+
+// SetImageOptions replaces the image retention and retry settings.
+func (s *SignalService) SetImageOptions(opts SignalImageOptions) {
+	if opts.TTL > 0 {
+		s.imageTTL = opts.TTL
+	}
+	if opts.RetryDelay > 0 {
+		s.retryDelay = opts.RetryDelay
+	}
+	if opts.MaxRetries > 0 {
+		s.maxImageRetry = opts.MaxRetries
 	}
 }
 
+// SetRenderQueue renders the charts of new signals from q instead of before
+// they are returned, so returned signals carry no image reference. Renders
+// that cannot be queued are done inline.
+func (s *SignalService) SetRenderQueue(q SignalRenderQueue) {
+	s.renderQueue = q
+}
+
+// GenerateForSymbol runs signal detection on every requested interval. Like
+// GenerateForNewCandles, it only looks at closed candles.
 func (s *SignalService) GenerateForSymbol(ctx context.Context, symbol string, intervals []string) ([]domain.Signal, error) {
+	return s.generate(ctx, symbol, intervals, false, time.Time{})
+}
+
+// GenerateForNewCandles runs signal detection only on intervals whose newest
+// closed candle is newer than the last one processed, i.e. where a candle has
+// closed since the last call. It is meant for polling and candle-closed
+// events. A non-zero through is the open time of the candle that closed:
+// candles after it are left out, so a late event is evaluated on its own
+// candle.
+func (s *SignalService) GenerateForNewCandles(ctx context.Context, symbol string, intervals []string, through time.Time) ([]domain.Signal, error) {
+	return s.generate(ctx, symbol, intervals, true, through)
+}
+
+// Symbols returns the tracked symbols followed by any symbols with candles
+// ingested from external collectors.
+func (s *SignalService) Symbols(ctx context.Context) []string {
+	return candleSymbols(ctx, s.candleRepo)
+}
+
+func (s *SignalService) generate(ctx context.Context, symbol string, intervals []string, onlyNew bool, through time.Time) ([]domain.Signal, error) {
 	_, span := s.tracer.Start(ctx, "signal-service.generate-for-symbol")
 	defer span.End()
 
@@ -104,7 +192,7 @@ func (s *SignalService) GenerateForSymbol(ctx context.Context, symbol string, in
 	}
 
 	symbol = strings.ToUpper(strings.TrimSpace(symbol))
-	if _, ok := domain.CoinGeckoID[symbol]; !ok {
+	if !knownSymbol(ctx, s.candleRepo, symbol) {
 		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
 	}
 
@@ -114,15 +202,24 @@ func (s *SignalService) GenerateForSymbol(ctx context.Context, symbol string, in
 
 	generated := make([]domain.Signal, 0, len(intervals)*2)
 	candlesByInterval := make(map[string][]*domain.Candle, len(intervals))
+	latestByInterval := make(map[string]time.Time, len(intervals))
+	now := s.now()
 	for _, interval := range intervals {
 		candles, err := s.candleRepo.GetCandles(ctx, symbol, interval, signalLookbackCandles)
 		if err != nil {
 			return nil, fmt.Errorf("get candles for %s %s: %w", symbol, interval, err)
 		}
+		candles = closedCandles(candles, interval, now, through)
 		if len(candles) == 0 {
 			continue
 		}
 
+		latest := latestOpenTime(candles)
+		if onlyNew && !s.isNewCandle(symbol, interval, latest) {
+			continue
+		}
+		latestByInterval[interval] = latest
+
 		intervalSignals := s.engine.Generate(candles)
 		generated = append(generated, intervalSignals...)
 		candlesByInterval[interval] = candles
@@ -137,9 +234,131 @@ func (s *SignalService) GenerateForSymbol(ctx context.Context, symbol string, in
 		s.attachGeneratedSignalImages(ctx, generated, candlesByInterval)
 	}
 
+	s.markProcessed(symbol, latestByInterval)
 	return generated, nil
 }
 
+// InsertSignals persists signals produced outside the engine, such as ML
+// predictions, and renders their chart images like generated signals.
+func (s *SignalService) InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error) {
+	_, span := s.tracer.Start(ctx, "signal-service.insert-signals")
+	defer span.End()
+
+	if s.signalRepo == nil {
+		return nil, fmt.Errorf("signal service is not fully initialized")
+	}
+	persisted, err := s.signalRepo.InsertSignals(ctx, signals)
+	if err != nil {
+		return nil, err
+	}
+	if s.imageRepo == nil || s.chartRender == nil || s.candleRepo == nil {
+		return persisted, nil
+	}
+	if s.queueImages(ctx, persisted) {
+		return persisted, nil
+	}
+	s.renderImages(ctx, persisted)
+	return persisted, nil
+}
+
+// RenderSignalImages renders and stores the charts of stored signals from
+// their latest candles. Failures are recorded for the image retry job
+// rather than returned.
+func (s *SignalService) RenderSignalImages(ctx context.Context, signals []domain.Signal) error {
+	ctx, span := s.tracer.Start(ctx, "signal-service.render-signal-images")
+	defer span.End()
+
+	if s.imageRepo == nil || s.chartRender == nil || s.candleRepo == nil {
+		return nil
+	}
+	s.renderImages(ctx, signals)
+	return ctx.Err()
+}
+
+// renderImages renders each signal's chart and sets its image reference,
+// loading candles once per symbol and interval.
+func (s *SignalService) renderImages(ctx context.Context, signals []domain.Signal) {
+	candlesByInterval := make(map[string][]*domain.Candle)
+	for i := range signals {
+		sig := signals[i]
+		key := sig.Symbol + "|" + sig.Interval
+		candles, ok := candlesByInterval[key]
+		if !ok {
+			var err error
+			candles, err = s.candleRepo.GetCandles(ctx, sig.Symbol, sig.Interval, signalLookbackCandles)
+			if err != nil {
+				s.recordImageFailure(ctx, sig, fmt.Errorf("get candles for image: %w", err))
+				continue
+			}
+			candlesByInterval[key] = candles
+		}
+		if len(candles) == 0 {
+			s.recordImageFailure(ctx, sig, fmt.Errorf("no candles available for image"))
+			continue
+		}
+		if ref, err := s.renderAndStoreImage(ctx, sig, candles); err == nil {
+			signals[i].Image = ref
+		}
+	}
+}
+
+// queueImages hands the signals' renders to the render queue and reports
+// whether it took them.
+func (s *SignalService) queueImages(ctx context.Context, signals []domain.Signal) bool {
+	if s.renderQueue == nil {
+		return false
+	}
+	if err := s.renderQueue.EnqueueSignalImages(ctx, signals); err != nil {
+		log.Printf("signal image queue error, rendering inline: %v", err)
+		return false
+	}
+	return true
+}
+
+func (s *SignalService) isNewCandle(symbol, interval string, latest time.Time) bool {
+	s.processedMu.Lock()
+	defer s.processedMu.Unlock()
+	return latest.After(s.processed[symbol+"|"+interval])
+}
+
+// markProcessed is called only after signals are persisted, so a failed
+// insert is retried on the next poll.
+func (s *SignalService) markProcessed(symbol string, latestByInterval map[string]time.Time) {
+	s.processedMu.Lock()
+	defer s.processedMu.Unlock()
+	for interval, latest := range latestByInterval {
+		key := symbol + "|" + interval
+		if latest.After(s.processed[key]) {
+			s.processed[key] = latest
+		}
+	}
+}
+
+// closedCandles drops candles still open at now, so the engine never sees a
+// bar whose close can change on the next poll, and candles opened after a
+// non-zero through. Order is kept.
+func closedCandles(candles []*domain.Candle, interval string, now, through time.Time) []*domain.Candle {
+	step := domain.IntervalDuration(interval)
+	closed := make([]*domain.Candle, 0, len(candles))
+	for _, c := range candles {
+		if c.OpenTime.Add(step).After(now) || (!through.IsZero() && c.OpenTime.After(through)) {
+			continue
+		}
+		closed = append(closed, c)
+	}
+	return closed
+}
+
+func latestOpenTime(candles []*domain.Candle) time.Time {
+	var latest time.Time
+	for _, c := range candles {
+		if c.OpenTime.After(latest) {
+			latest = c.OpenTime
+		}
+	}
+	return latest
+}
+
 func (s *SignalService) ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error) {
 	_, span := s.tracer.Start(ctx, "signal-service.list-signals")
 	defer span.End()
@@ -151,10 +370,8 @@ func (s *SignalService) ListSignals(ctx context.Context, filter domain.SignalFil
 	filter.Symbol = strings.ToUpper(strings.TrimSpace(filter.Symbol))
 	filter.Indicator = strings.ToLower(strings.TrimSpace(filter.Indicator))
 
-	if filter.Symbol != "" {
-		if _, ok := domain.CoinGeckoID[filter.Symbol]; !ok {
-			return nil, fmt.Errorf("unsupported symbol: %s", filter.Symbol)
-		}
+	if filter.Symbol != "" && !knownSymbol(ctx, s.candleRepo, filter.Symbol) {
+		return nil, fmt.Errorf("unsupported symbol: %s", filter.Symbol)
 	}
 	if filter.Risk != nil && !filter.Risk.IsValid() {
 		return nil, fmt.Errorf("invalid risk level: %d", *filter.Risk)
@@ -166,6 +383,25 @@ func (s *SignalService) ListSignals(ctx context.Context, filter domain.SignalFil
 	return s.signalRepo.ListSignals(ctx, filter)
 }
 
+// WinRatesByVersion reports per indicator and version how often a signal was
+// followed, horizon candles later, by a close in its direction.
+func (s *SignalService) WinRatesByVersion(ctx context.Context, indicator string, horizon int) ([]domain.SignalWinRate, error) {
+	_, span := s.tracer.Start(ctx, "signal-service.win-rates-by-version")
+	defer span.End()
+
+	stats, ok := s.signalRepo.(SignalStatsRepository)
+	if !ok {
+		return nil, fmt.Errorf("signal statistics are unavailable")
+	}
+	if horizon <= 0 {
+		horizon = DefaultWinRateHorizon
+	}
+	if horizon > MaxWinRateHorizon {
+		return nil, fmt.Errorf("horizon must be at most %d candles", MaxWinRateHorizon)
+	}
+	return stats.WinRatesByVersion(ctx, strings.ToLower(strings.TrimSpace(indicator)), horizon)
+}
+
 func (s *SignalService) GetSignalImage(ctx context.Context, signalID int64) (*domain.SignalImageData, error) {
 	_, span := s.tracer.Start(ctx, "signal-service.get-signal-image")
 	defer span.End()
@@ -214,14 +450,48 @@ func (s *SignalService) RetryFailedImages(ctx context.Context, limit int) (int,
 	return successes, nil
 }
 
-func (s *SignalService) DeleteExpiredSignalImages(ctx context.Context) (int64, error) {
-	_, span := s.tracer.Start(ctx, "signal-service.delete-expired-signal-images")
+// DeleteExpiredSignalImages deletes expired images batch rows at a time
+// until none are left. A non-positive batch deletes them in one statement.
+func (s *SignalService) DeleteExpiredSignalImages(ctx context.Context, batch int) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "signal-service.delete-expired-signal-images")
 	defer span.End()
 
 	if s.imageRepo == nil {
 		return 0, nil
 	}
-	return s.imageRepo.DeleteExpiredSignalImages(ctx)
+	var total int64
+	for {
+		deleted, err := s.imageRepo.DeleteExpiredSignalImages(ctx, batch)
+		total += deleted
+		s.imageMetrics.deleted(ctx, deleted, "expired")
+		if err != nil {
+			return total, err
+		}
+		if batch <= 0 || deleted < int64(batch) {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// PurgeSignalImages deletes every expired image at once, or every stored
+// image when all is set. Purged charts are not rendered again; their
+// signals answer 404 for the image until a new signal is generated.
+func (s *SignalService) PurgeSignalImages(ctx context.Context, all bool) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "signal-service.purge-signal-images")
+	defer span.End()
+
+	if s.imageRepo == nil {
+		return 0, ErrSignalImagesUnavailable
+	}
+	if !all {
+		return s.DeleteExpiredSignalImages(ctx, 0)
+	}
+	deleted, err := s.imageRepo.DeleteAllSignalImages(ctx)
+	s.imageMetrics.deleted(ctx, deleted, "purged")
+	return deleted, err
 }
 
 func (s *SignalService) attachGeneratedSignalImages(
@@ -232,6 +502,9 @@ func (s *SignalService) attachGeneratedSignalImages(
 	if s.imageRepo == nil || s.chartRender == nil {
 		return
 	}
+	if s.queueImages(ctx, generated) {
+		return
+	}
 	for i := range generated {
 		candles := candlesByInterval[generated[i].Interval]
 		if len(candles) == 0 {
@@ -256,7 +529,7 @@ func (s *SignalService) renderAndStoreImage(
 		return nil, err
 	}
 
-	expiresAt := time.Now().UTC().Add(signalImageTTL)
+	expiresAt := time.Now().UTC().Add(s.imageTTL)
 	ref, err := s.imageRepo.UpsertSignalImageReady(
 		ctx,
 		sig.ID,
@@ -270,15 +543,17 @@ func (s *SignalService) renderAndStoreImage(
 		s.recordImageFailure(ctx, sig, fmt.Errorf("persist image: %w", err))
 		return nil, err
 	}
+	s.imageMetrics.rendered(ctx, len(rendered.Bytes))
 	return ref, nil
 }
 
 func (s *SignalService) recordImageFailure(ctx context.Context, sig domain.Signal, err error) {
+	s.imageMetrics.failed(ctx)
 	if s.imageRepo == nil || sig.ID <= 0 {
 		return
 	}
-	expiresAt := time.Now().UTC().Add(signalImageTTL)
-	nextRetry := time.Now().UTC().Add(signalImageRetryDelay)
+	expiresAt := time.Now().UTC().Add(s.imageTTL)
+	nextRetry := time.Now().UTC().Add(s.retryDelay)
 	if upsertErr := s.imageRepo.UpsertSignalImageFailure(ctx, sig.ID, err.Error(), nextRetry, expiresAt); upsertErr != nil {
 		log.Printf("signal image failure upsert error for signal %d: %v", sig.ID, upsertErr)
 	}
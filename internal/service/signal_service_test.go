@@ -6,7 +6,7 @@ import (
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -24,6 +24,26 @@ func TestSignalServiceGenerateForSymbolUnsupported(t *testing.T) {
 	}
 }
 
+func TestSignalServiceGenerateForIngestedSymbol(t *testing.T) {
+	engine := &stubSignalEngine{}
+	svc := NewSignalService(
+		trace.NewNoopTracerProvider().Tracer("test"),
+		&candleIngestStoreStub{symbols: []string{"PEPE"}},
+		&stubSignalRepo{},
+		engine,
+	)
+
+	if _, err := svc.GenerateForSymbol(context.Background(), "pepe", []string{"1h"}); err != nil {
+		t.Fatalf("expected ingested symbol accepted, got %v", err)
+	}
+	if _, err := svc.GenerateForSymbol(context.Background(), "WIF", nil); err == nil {
+		t.Fatal("expected symbol without candles rejected")
+	}
+	if _, err := svc.ListSignals(context.Background(), domain.SignalFilter{Symbol: "PEPE"}); err != nil {
+		t.Fatalf("expected ingested symbol filter accepted, got %v", err)
+	}
+}
+
 func TestSignalServiceGenerateForSymbolPersistsGeneratedSignals(t *testing.T) {
 	tracer := trace.NewNoopTracerProvider().Tracer("test")
 	candleRepo := &stubSignalCandleRepo{
@@ -31,7 +51,7 @@ func TestSignalServiceGenerateForSymbolPersistsGeneratedSignals(t *testing.T) {
 			"1h": {{
 				Symbol:   "BTC",
 				Interval: "1h",
-				OpenTime: time.Now().UTC(),
+				OpenTime: time.Now().UTC().Add(-time.Hour),
 				Close:    101,
 				Volume:   10,
 			}},
@@ -65,6 +85,144 @@ func TestSignalServiceGenerateForSymbolPersistsGeneratedSignals(t *testing.T) {
 	}
 }
 
+func TestSignalServiceGenerateForNewCandlesSkipsUnchangedIntervals(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	open := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	candleRepo := &stubSignalCandleRepo{
+		candles: map[string][]*domain.Candle{
+			"1h": {{Symbol: "BTC", Interval: "1h", OpenTime: open.Add(time.Hour)}, {Symbol: "BTC", Interval: "1h", OpenTime: open}},
+			"1d": {{Symbol: "BTC", Interval: "1d", OpenTime: open}},
+		},
+	}
+	signalRepo := &stubSignalRepo{}
+	engine := &stubSignalEngine{
+		signals: []domain.Signal{{Symbol: "BTC", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong}},
+	}
+	svc := NewSignalService(tracer, candleRepo, signalRepo, engine)
+	ctx := context.Background()
+
+	first, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h", "1d"}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first) != 2 || engine.calls != 2 {
+		t.Fatalf("expected both intervals on first poll, got %d signals from %d runs", len(first), engine.calls)
+	}
+
+	again, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h", "1d"}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(again) != 0 || engine.calls != 2 || signalRepo.insertCalls != 1 {
+		t.Fatalf("expected no work without new candles, got %d signals, %d runs", len(again), engine.calls)
+	}
+
+	candleRepo.candles["1h"] = append([]*domain.Candle{{Symbol: "BTC", Interval: "1h", OpenTime: open.Add(2 * time.Hour)}}, candleRepo.candles["1h"]...)
+	next, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h", "1d"}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next) != 1 || engine.calls != 3 {
+		t.Fatalf("expected only 1h to rerun, got %d signals from %d runs", len(next), engine.calls)
+	}
+
+	if forced, err := svc.GenerateForSymbol(ctx, "BTC", []string{"1h", "1d"}); err != nil || len(forced) != 2 {
+		t.Fatalf("expected GenerateForSymbol to ignore scheduling, got %d signals err=%v", len(forced), err)
+	}
+}
+
+func TestSignalServiceGenerateIgnoresInProgressCandle(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	open := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	candleRepo := &stubSignalCandleRepo{
+		candles: map[string][]*domain.Candle{
+			"1h": {
+				{Symbol: "BTC", Interval: "1h", OpenTime: open.Add(time.Hour), Close: 90},
+				{Symbol: "BTC", Interval: "1h", OpenTime: open, Close: 100},
+			},
+		},
+	}
+	engine := &stubSignalEngine{
+		signals: []domain.Signal{{Symbol: "BTC", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong}},
+	}
+	svc := NewSignalService(tracer, candleRepo, &stubSignalRepo{}, engine)
+	now := open.Add(90 * time.Minute)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h"}, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(engine.seen) != 1 || engine.seen[0].OpenTime != open {
+		t.Fatalf("expected only the closed candle, got %v", engine.seen)
+	}
+
+	// The 01:00 candle closes at 02:00 and must still count as new then.
+	now = open.Add(2 * time.Hour)
+	if _, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h"}, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if engine.calls != 2 || len(engine.seen) != 2 {
+		t.Fatalf("expected a rerun once the candle closed, got %d runs on %d candles", engine.calls, len(engine.seen))
+	}
+}
+
+func TestSignalServiceGenerateForNewCandlesStopsAtClosedCandle(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	open := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	candleRepo := &stubSignalCandleRepo{
+		candles: map[string][]*domain.Candle{
+			"1h": {
+				{Symbol: "BTC", Interval: "1h", OpenTime: open.Add(2 * time.Hour)},
+				{Symbol: "BTC", Interval: "1h", OpenTime: open.Add(time.Hour)},
+				{Symbol: "BTC", Interval: "1h", OpenTime: open},
+			},
+		},
+	}
+	engine := &stubSignalEngine{}
+	svc := NewSignalService(tracer, candleRepo, &stubSignalRepo{}, engine)
+	svc.now = func() time.Time { return open.Add(5 * time.Hour) }
+	ctx := context.Background()
+
+	// A late event for the 00:00 candle is evaluated on that candle, not on
+	// the ones stored after it.
+	if _, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h"}, open); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(engine.seen) != 1 || engine.seen[0].OpenTime != open {
+		t.Fatalf("expected evaluation up to 00:00, got %v", engine.seen)
+	}
+	if _, err := svc.GenerateForNewCandles(ctx, "BTC", []string{"1h"}, open.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if engine.calls != 2 || len(engine.seen) != 2 {
+		t.Fatalf("expected the 01:00 event to run on two candles, got %d runs on %d candles", engine.calls, len(engine.seen))
+	}
+}
+
+func TestSignalServiceGenerateForNewCandlesRetriesFailedInsert(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	candleRepo := &stubSignalCandleRepo{
+		candles: map[string][]*domain.Candle{
+			"4h": {{Symbol: "ETH", Interval: "4h", OpenTime: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}},
+		},
+	}
+	signalRepo := &stubSignalRepo{insertErr: errors.New("db down")}
+	engine := &stubSignalEngine{
+		signals: []domain.Signal{{Symbol: "ETH", Indicator: domain.IndicatorMACD, Direction: domain.DirectionShort}},
+	}
+	svc := NewSignalService(tracer, candleRepo, signalRepo, engine)
+
+	if _, err := svc.GenerateForNewCandles(context.Background(), "ETH", []string{"4h"}, time.Time{}); err == nil {
+		t.Fatal("expected insert error")
+	}
+	signalRepo.insertErr = nil
+	got, err := svc.GenerateForNewCandles(context.Background(), "ETH", []string{"4h"}, time.Time{})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected retry after failed insert, got %d signals err=%v", len(got), err)
+	}
+}
+
 func TestSignalServiceListSignalsValidatesFilter(t *testing.T) {
 	tracer := trace.NewNoopTracerProvider().Tracer("test")
 	signalRepo := &stubSignalRepo{}
@@ -102,7 +260,7 @@ func TestSignalServiceGenerateForSymbolImageFailureIsNonBlocking(t *testing.T) {
 			"1h": {{
 				Symbol:   "BTC",
 				Interval: "1h",
-				OpenTime: time.Now().UTC(),
+				OpenTime: time.Now().UTC().Add(-time.Hour),
 				Open:     100,
 				High:     110,
 				Low:      90,
@@ -138,6 +296,135 @@ func TestSignalServiceGenerateForSymbolImageFailureIsNonBlocking(t *testing.T) {
 	}
 }
 
+func TestWinRatesByVersion(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	repo := &statsSignalRepo{rates: []domain.SignalWinRate{{Indicator: domain.IndicatorRSI, Version: "ta-1", Total: 4, Wins: 3, WinRate: 0.75}}}
+	svc := NewSignalService(tracer, &stubSignalCandleRepo{}, repo, &stubSignalEngine{})
+
+	rates, err := svc.WinRatesByVersion(context.Background(), " RSI ", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rates) != 1 || rates[0].Version != "ta-1" {
+		t.Fatalf("unexpected rates: %+v", rates)
+	}
+	if repo.lastIndicator != domain.IndicatorRSI || repo.lastHorizon != DefaultWinRateHorizon {
+		t.Fatalf("unexpected query: %q %d", repo.lastIndicator, repo.lastHorizon)
+	}
+	if _, err := svc.WinRatesByVersion(context.Background(), "", MaxWinRateHorizon+1); err == nil {
+		t.Fatal("expected horizon error")
+	}
+
+	plain := NewSignalService(tracer, &stubSignalCandleRepo{}, &stubSignalRepo{}, &stubSignalEngine{})
+	if _, err := plain.WinRatesByVersion(context.Background(), "", 4); err == nil {
+		t.Fatal("expected error when the repository has no statistics")
+	}
+}
+
+func TestSignalServiceInsertSignalsRendersImages(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	candleRepo := &stubSignalCandleRepo{
+		candles: map[string][]*domain.Candle{
+			"1h": {
+				{Symbol: "BTC", Interval: "1h", OpenTime: time.Unix(0, 0).UTC(), Close: 100},
+				{Symbol: "BTC", Interval: "1h", OpenTime: time.Unix(3600, 0).UTC(), Close: 101},
+			},
+		},
+	}
+	signalRepo := &stubSignalRepo{}
+	imageRepo := &stubSignalImageRepo{}
+	svc := NewSignalServiceWithImages(tracer, candleRepo, signalRepo, &stubSignalEngine{}, imageRepo, &stubSignalChartRenderer{})
+
+	got, err := svc.InsertSignals(context.Background(), []domain.Signal{
+		{Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorMLEnsembleUp4H, Direction: domain.DirectionLong},
+		{Symbol: "BTC", Interval: "4h", Indicator: domain.IndicatorMLEnsembleUp4H, Direction: domain.DirectionShort},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[0].Image == nil || got[0].Image.ImageID != 1001 {
+		t.Fatalf("expected persisted signal with image, got %+v", got)
+	}
+	if got[1].Image != nil || imageRepo.failureCalls != 1 {
+		t.Fatalf("expected image failure for interval without candles, got %+v failures=%d", got[1], imageRepo.failureCalls)
+	}
+
+	signalRepo.insertErr = errors.New("db down")
+	if _, err := svc.InsertSignals(context.Background(), []domain.Signal{{Symbol: "BTC", Interval: "1h"}}); err == nil {
+		t.Fatal("expected insert error")
+	}
+}
+
+func TestSignalServiceQueuesRendersWhenRenderQueueSet(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	candleRepo := &stubSignalCandleRepo{
+		candles: map[string][]*domain.Candle{
+			"1h": {{Symbol: "BTC", Interval: "1h", OpenTime: time.Now().UTC().Add(-time.Hour), Close: 100}},
+		},
+	}
+	engine := &stubSignalEngine{
+		signals: []domain.Signal{{Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorRSI, Direction: domain.DirectionLong}},
+	}
+	svc := NewSignalServiceWithImages(tracer, candleRepo, &stubSignalRepo{}, engine, &stubSignalImageRepo{}, &stubSignalChartRenderer{})
+	queue := &stubSignalRenderQueue{}
+	svc.SetRenderQueue(queue)
+
+	got, err := svc.GenerateForSymbol(context.Background(), "BTC", []string{"1h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Image != nil {
+		t.Fatalf("expected a signal without an inline image, got %+v", got)
+	}
+	if len(queue.queued) != 1 || queue.queued[0].ID != 1 {
+		t.Fatalf("expected the stored signal queued for rendering, got %+v", queue.queued)
+	}
+
+	rendered := append([]domain.Signal(nil), queue.queued...)
+	if err := svc.RenderSignalImages(context.Background(), rendered); err != nil {
+		t.Fatalf("render queued images: %v", err)
+	}
+	if rendered[0].Image == nil || rendered[0].Image.ImageID != 1001 {
+		t.Fatalf("expected the queued render stored, got %+v", rendered[0])
+	}
+
+	// Renders that cannot be queued are done inline.
+	queue.err = errors.New("redis down")
+	got, err = svc.InsertSignals(context.Background(), []domain.Signal{{Symbol: "BTC", Interval: "1h"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got[0].Image == nil {
+		t.Fatalf("expected an inline render when the queue fails, got %+v", got[0])
+	}
+}
+
+func TestSignalServiceDeleteExpiredImagesRunsBatchesUntilShort(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	imageRepo := &stubSignalImageRepo{expiredBatches: []int64{100, 100, 40}}
+	svc := NewSignalServiceWithImages(tracer, &stubSignalCandleRepo{}, &stubSignalRepo{}, &stubSignalEngine{}, imageRepo, &stubSignalChartRenderer{})
+
+	deleted, err := svc.DeleteExpiredSignalImages(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deleted != 240 || len(imageRepo.deleteLimits) != 3 {
+		t.Fatalf("expected 240 rows over 3 batches, got %d over %v", deleted, imageRepo.deleteLimits)
+	}
+
+	imageRepo.deleteAll = 7
+	if deleted, err := svc.PurgeSignalImages(context.Background(), true); err != nil || deleted != 7 {
+		t.Fatalf("expected purge of 7 images, got %d, %v", deleted, err)
+	}
+	imageRepo.deleteLimits = nil
+	if _, err := svc.PurgeSignalImages(context.Background(), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(imageRepo.deleteLimits) != 1 || imageRepo.deleteLimits[0] != 0 {
+		t.Fatalf("expected one unbounded expired delete, got %v", imageRepo.deleteLimits)
+	}
+}
+
 type stubSignalCandleRepo struct {
 	candles      map[string][]*domain.Candle
 	lastSymbol   string
@@ -157,6 +444,7 @@ func (s *stubSignalCandleRepo) GetCandles(ctx context.Context, symbol, interval
 
 type stubSignalRepo struct {
 	insertCalls int
+	insertErr   error
 	inserted    []domain.Signal
 	lastFilter  domain.SignalFilter
 	listResp    []domain.Signal
@@ -164,6 +452,9 @@ type stubSignalRepo struct {
 
 func (s *stubSignalRepo) InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error) {
 	s.insertCalls++
+	if s.insertErr != nil {
+		return nil, s.insertErr
+	}
 	s.inserted = append([]domain.Signal(nil), signals...)
 	out := append([]domain.Signal(nil), signals...)
 	for i := range out {
@@ -179,15 +470,22 @@ func (s *stubSignalRepo) ListSignals(ctx context.Context, filter domain.SignalFi
 
 type stubSignalEngine struct {
 	signals []domain.Signal
+	calls   int
+	seen    []*domain.Candle
 }
 
 func (s *stubSignalEngine) Generate(candles []*domain.Candle) []domain.Signal {
+	s.calls++
+	s.seen = candles
 	return append([]domain.Signal(nil), s.signals...)
 }
 
 type stubSignalImageRepo struct {
-	failureCalls int
-	imageByID    map[int64]*domain.SignalImageData
+	failureCalls   int
+	imageByID      map[int64]*domain.SignalImageData
+	expiredBatches []int64
+	deleteLimits   []int
+	deleteAll      int64
 }
 
 func (s *stubSignalImageRepo) UpsertSignalImageReady(
@@ -229,8 +527,18 @@ func (s *stubSignalImageRepo) ListRetryCandidates(ctx context.Context, limit int
 	return nil, nil
 }
 
-func (s *stubSignalImageRepo) DeleteExpiredSignalImages(ctx context.Context) (int64, error) {
-	return 0, nil
+func (s *stubSignalImageRepo) DeleteExpiredSignalImages(ctx context.Context, limit int) (int64, error) {
+	s.deleteLimits = append(s.deleteLimits, limit)
+	if len(s.expiredBatches) == 0 {
+		return 0, nil
+	}
+	n := s.expiredBatches[0]
+	s.expiredBatches = s.expiredBatches[1:]
+	return n, nil
+}
+
+func (s *stubSignalImageRepo) DeleteAllSignalImages(ctx context.Context) (int64, error) {
+	return s.deleteAll, nil
 }
 
 type stubSignalChartRenderer struct {
@@ -250,3 +558,29 @@ func (s *stubSignalChartRenderer) RenderSignalChart(candles []*domain.Candle, si
 		Bytes: []byte{0x89, 0x50, 0x4e, 0x47},
 	}, nil
 }
+
+type statsSignalRepo struct {
+	stubSignalRepo
+	rates         []domain.SignalWinRate
+	lastIndicator string
+	lastHorizon   int
+}
+
+func (s *statsSignalRepo) WinRatesByVersion(ctx context.Context, indicator string, horizon int) ([]domain.SignalWinRate, error) {
+	s.lastIndicator = indicator
+	s.lastHorizon = horizon
+	return s.rates, nil
+}
+
+type stubSignalRenderQueue struct {
+	queued []domain.Signal
+	err    error
+}
+
+func (s *stubSignalRenderQueue) EnqueueSignalImages(ctx context.Context, signals []domain.Signal) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.queued = append(s.queued, signals...)
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInvalidAsset is returned by SymbolRegistryService for an asset that
+// cannot be added or removed.
+var ErrInvalidAsset = errors.New("invalid asset")
+
+var (
+	assetSymbolPattern      = regexp.MustCompile(`^[A-Z0-9]{2,10}$`)
+	assetCoinGeckoIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+	assetPairPattern        = regexp.MustCompile(`^[A-Za-z0-9-]{0,20}$`)
+)
+
+type AssetStore interface {
+	ListAssets(ctx context.Context) ([]domain.Asset, error)
+	UpsertAsset(ctx context.Context, a domain.Asset) (domain.Asset, error)
+	DisableAsset(ctx context.Context, symbol string) error
+	SetAssetPauses(ctx context.Context, symbol string, p domain.AssetPauses) (domain.Asset, error)
+}
+
+// SymbolRegistryService keeps the process-wide symbol registry in step with
+// the stored one and lets operators add or remove assets at runtime. Changes
+// apply to this process at once and to other processes on their next
+// Reload. Every change is recorded in the audit log.
+type SymbolRegistryService struct {
+	tracer trace.Tracer
+	store  AssetStore
+	audit  *AuditService
+}
+
+func NewSymbolRegistryService(tracer trace.Tracer, store AssetStore, audit *AuditService) *SymbolRegistryService {
+	return &SymbolRegistryService{tracer: tracer, store: store, audit: audit}
+}
+
+// Reload replaces the tracked assets with the stored ones. An empty store
+// leaves domain.DefaultAssets tracked.
+func (s *SymbolRegistryService) Reload(ctx context.Context) error {
+	ctx, span := s.tracer.Start(ctx, "symbol-registry-service.reload")
+	defer span.End()
+	if s.store == nil {
+		return fmt.Errorf("symbol registry unavailable")
+	}
+	assets, err := s.store.ListAssets(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	span.SetAttributes(attribute.Int("symbol_registry.assets", len(assets)))
+	domain.SetAssets(assets)
+	return nil
+}
+
+// List returns the tracked assets in registry order.
+func (s *SymbolRegistryService) List() []domain.Asset {
+	return domain.Assets()
+}
+
+// Add tracks a, or updates its identifiers if it is already tracked.
+func (s *SymbolRegistryService) Add(ctx context.Context, a domain.Asset) (domain.Asset, error) {
+	ctx, span := s.tracer.Start(ctx, "symbol-registry-service.add")
+	defer span.End()
+	if s.store == nil {
+		return domain.Asset{}, fmt.Errorf("symbol registry unavailable")
+	}
+
+	a.Symbol = strings.ToUpper(strings.TrimSpace(a.Symbol))
+	a.Name = strings.TrimSpace(a.Name)
+	a.CoinGeckoID = strings.ToLower(strings.TrimSpace(a.CoinGeckoID))
+	a.BinancePair = strings.ToUpper(strings.TrimSpace(a.BinancePair))
+	a.KrakenPair = strings.ToUpper(strings.TrimSpace(a.KrakenPair))
+	a.CoinbasePair = strings.ToUpper(strings.TrimSpace(a.CoinbasePair))
+	switch {
+	case !assetSymbolPattern.MatchString(a.Symbol):
+		return domain.Asset{}, fmt.Errorf("%w: symbol must be 2-10 letters or digits", ErrInvalidAsset)
+	case !assetCoinGeckoIDPattern.MatchString(a.CoinGeckoID):
+		return domain.Asset{}, fmt.Errorf("%w: coingecko_id must be a CoinGecko coin id such as %q", ErrInvalidAsset, "bitcoin")
+	case !assetPairPattern.MatchString(a.BinancePair) || !assetPairPattern.MatchString(a.KrakenPair) || !assetPairPattern.MatchString(a.CoinbasePair):
+		return domain.Asset{}, fmt.Errorf("%w: exchange pairs must be up to 20 letters, digits or dashes", ErrInvalidAsset)
+	case a.PriceDecimals < 0 || a.PriceDecimals > 12:
+		return domain.Asset{}, fmt.Errorf("%w: price_decimals must be between 0 and 12", ErrInvalidAsset)
+	case len(a.Name) > 64:
+		return domain.Asset{}, fmt.Errorf("%w: name must be at most 64 characters", ErrInvalidAsset)
+	}
+	if other, ok := domain.SymbolForCoinGeckoID(a.CoinGeckoID); ok && other != a.Symbol {
+		return domain.Asset{}, fmt.Errorf("%w: %s already tracks coingecko_id %s", ErrInvalidAsset, other, a.CoinGeckoID)
+	}
+	span.SetAttributes(attribute.String("symbol", a.Symbol))
+
+	var before any
+	if existing, ok := domain.LookupAsset(a.Symbol); ok {
+		before = existing
+	}
+	saved, err := s.store.UpsertAsset(ctx, a)
+	if err != nil {
+		return domain.Asset{}, err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return domain.Asset{}, fmt.Errorf("reload symbol registry: %w", err)
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionSymbolAdd, domain.AuditEntitySymbol,
+		saved.Symbol, before, saved); err != nil {
+		span.RecordError(err)
+	}
+	return saved, nil
+}
+
+// Remove stops tracking symbol. Its stored candles, signals and predictions
+// are kept, and adding it again resumes tracking. The store's not-found
+// error is returned when symbol is not tracked.
+func (s *SymbolRegistryService) Remove(ctx context.Context, symbol string) error {
+	ctx, span := s.tracer.Start(ctx, "symbol-registry-service.remove")
+	defer span.End()
+	if s.store == nil {
+		return fmt.Errorf("symbol registry unavailable")
+	}
+
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	span.SetAttributes(attribute.String("symbol", symbol))
+	symbols := domain.SupportedSymbols()
+	if len(symbols) == 1 && symbols[0] == symbol {
+		return fmt.Errorf("%w: cannot remove the last tracked symbol", ErrInvalidAsset)
+	}
+	var before any
+	if existing, ok := domain.LookupAsset(symbol); ok {
+		before = existing
+	}
+	if err := s.store.DisableAsset(ctx, symbol); err != nil {
+		return err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return fmt.Errorf("reload symbol registry: %w", err)
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionSymbolRemove, domain.AuditEntitySymbol,
+		symbol, before, nil); err != nil {
+		span.RecordError(err)
+	}
+	return nil
+}
+
+// SetPauses replaces the stored pauses of a tracked symbol. Pauses set in
+// the configuration stay in force whatever is stored. The store's not-found
+// error is returned when symbol is not tracked.
+func (s *SymbolRegistryService) SetPauses(ctx context.Context, symbol string, p domain.AssetPauses) (domain.Asset, error) {
+	ctx, span := s.tracer.Start(ctx, "symbol-registry-service.set-pauses")
+	defer span.End()
+	if s.store == nil {
+		return domain.Asset{}, fmt.Errorf("symbol registry unavailable")
+	}
+
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	span.SetAttributes(attribute.String("symbol", symbol))
+	var before any
+	if existing, ok := domain.LookupAsset(symbol); ok {
+		before = existing.AssetPauses
+	}
+	if _, err := s.store.SetAssetPauses(ctx, symbol, p); err != nil {
+		return domain.Asset{}, err
+	}
+	if err := s.Reload(ctx); err != nil {
+		return domain.Asset{}, fmt.Errorf("reload symbol registry: %w", err)
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionSymbolPause, domain.AuditEntitySymbol,
+		symbol, before, p); err != nil {
+		span.RecordError(err)
+	}
+	saved, _ := domain.LookupAsset(symbol)
+	return saved, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type assetStoreStub struct {
+	assets   []domain.Asset
+	disabled []string
+}
+
+func (s *assetStoreStub) ListAssets(ctx context.Context) ([]domain.Asset, error) {
+	return append([]domain.Asset(nil), s.assets...), nil
+}
+
+func (s *assetStoreStub) UpsertAsset(ctx context.Context, a domain.Asset) (domain.Asset, error) {
+	s.assets = append(s.assets, a)
+	return a, nil
+}
+
+func (s *assetStoreStub) DisableAsset(ctx context.Context, symbol string) error {
+	for i, a := range s.assets {
+		if a.Symbol == symbol {
+			s.assets = append(s.assets[:i], s.assets[i+1:]...)
+			s.disabled = append(s.disabled, symbol)
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (s *assetStoreStub) SetAssetPauses(ctx context.Context, symbol string, p domain.AssetPauses) (domain.Asset, error) {
+	for i, a := range s.assets {
+		if a.Symbol == symbol {
+			s.assets[i].AssetPauses = p
+			return s.assets[i], nil
+		}
+	}
+	return domain.Asset{}, errors.New("not found")
+}
+
+func TestSymbolRegistryAddAndRemove(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	store := &assetStoreStub{assets: append([]domain.Asset(nil), domain.DefaultAssets...)}
+	audit := &auditStoreStub{}
+	svc := NewSymbolRegistryService(tracer, store, NewAuditService(tracer, audit))
+
+	saved, err := svc.Add(context.Background(), domain.Asset{Symbol: " pepe ", CoinGeckoID: "Pepe", BinancePair: "pepeusdt"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Symbol != "PEPE" || saved.CoinGeckoID != "pepe" || saved.BinancePair != "PEPEUSDT" {
+		t.Fatalf("expected normalized asset, got %+v", saved)
+	}
+	if !domain.IsSupportedSymbol("PEPE") {
+		t.Fatal("expected PEPE to be tracked after Add")
+	}
+
+	if err := svc.Remove(context.Background(), "pepe"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if domain.IsSupportedSymbol("PEPE") || len(store.disabled) != 1 {
+		t.Fatalf("expected PEPE to be removed, disabled=%v", store.disabled)
+	}
+	if len(audit.entries) != 2 || audit.entries[0].Action != domain.AuditActionSymbolAdd || audit.entries[1].Action != domain.AuditActionSymbolRemove {
+		t.Fatalf("unexpected audit entries: %+v", audit.entries)
+	}
+}
+
+func TestSymbolRegistryAddRejectsInvalidAsset(t *testing.T) {
+	t.Cleanup(func() { domain.SetAssets(nil) })
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	cases := map[string]domain.Asset{
+		"symbol":       {Symbol: "B", CoinGeckoID: "b"},
+		"coingecko id": {Symbol: "NEW", CoinGeckoID: "not an id"},
+		"pair":         {Symbol: "NEW", CoinGeckoID: "new", KrakenPair: "NEW/USD"},
+		"decimals":     {Symbol: "NEW", CoinGeckoID: "new", PriceDecimals: 20},
+		"duplicate id": {Symbol: "XBT", CoinGeckoID: "bitcoin"},
+	}
+	for name, a := range cases {
+		store := &assetStoreStub{}
+		svc := NewSymbolRegistryService(tracer, store, nil)
+		if _, err := svc.Add(context.Background(), a); !errors.Is(err, ErrInvalidAsset) {
+			t.Fatalf("%s: expected ErrInvalidAsset, got %v", name, err)
+		}
+		if len(store.assets) != 0 {
+			t.Fatalf("%s: store should not be written", name)
+		}
+	}
+}
+
+func TestSymbolRegistrySetPauses(t *testing.T) {
+	t.Cleanup(func() {
+		domain.SetConfiguredPauses(nil)
+		domain.SetAssets(nil)
+	})
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	store := &assetStoreStub{assets: append([]domain.Asset(nil), domain.DefaultAssets...)}
+	audit := &auditStoreStub{}
+	svc := NewSymbolRegistryService(tracer, store, NewAuditService(tracer, audit))
+	domain.SetConfiguredPauses(map[string]domain.AssetPauses{"DOGE": {AlertsPaused: true}})
+
+	saved, err := svc.SetPauses(context.Background(), " doge ", domain.AssetPauses{SignalsPaused: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !saved.SignalsPaused || !saved.AlertsPaused || saved.MLPaused {
+		t.Fatalf("expected stored and configured pauses on the saved asset, got %+v", saved)
+	}
+	if !domain.SignalsPaused("DOGE") || domain.SignalsPaused("BTC") {
+		t.Fatal("expected only DOGE signals paused")
+	}
+	if len(audit.entries) != 1 || audit.entries[0].Action != domain.AuditActionSymbolPause || audit.entries[0].EntityID != "DOGE" {
+		t.Fatalf("unexpected audit entries: %+v", audit.entries)
+	}
+
+	if _, err := svc.SetPauses(context.Background(), "NOPE", domain.AssetPauses{}); err == nil {
+		t.Fatal("expected an error for an untracked symbol")
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"bug-free-umbrella/internal/alerttemplate"
+	"bug-free-umbrella/internal/httpclient"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrInvalidWebhook is returned by WebhookService.Create for a bad URL,
+// template or header.
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, webhook domain.SignalWebhook) (*domain.SignalWebhook, error)
+	DisableWebhook(ctx context.Context, id int64) error
+	ListWebhooks(ctx context.Context, includeDisabled bool) ([]domain.SignalWebhook, error)
+	ListActiveWebhooks(ctx context.Context) ([]domain.SignalWebhook, error)
+}
+
+// WebhookService manages signal webhooks and posts new signals to them. It
+// is a signal alert sink. Create, Disable and List act on the tenant bound
+// to ctx; NotifySignals posts to every tenant's webhooks.
+type WebhookService struct {
+	tracer trace.Tracer
+	store  WebhookStore
+	audit  *AuditService
+	client *http.Client
+	text   *alerttemplate.Renderer
+}
+
+func NewWebhookService(tracer trace.Tracer, store WebhookStore, audit *AuditService) *WebhookService {
+	return &WebhookService{
+		tracer: tracer,
+		store:  store,
+		audit:  audit,
+		client: httpclient.New(httpclient.Webhooks, 10*time.Second),
+		text:   alerttemplate.Default(),
+	}
+}
+
+// SetAlertText sets the deployment's alert templates. Payload templates can
+// then use its messages with {{t "key"}} and its alert line as {{.Text}}.
+func (s *WebhookService) SetAlertText(r *alerttemplate.Renderer) {
+	if r != nil {
+		s.text = r
+	}
+}
+
+// Create validates and stores a webhook. The template is parsed and run
+// against a sample signal so mistakes surface here rather than on the first
+// alert.
+func (s *WebhookService) Create(ctx context.Context, webhook domain.SignalWebhook) (*domain.SignalWebhook, error) {
+	ctx, span := s.tracer.Start(ctx, "webhook-service.create")
+	defer span.End()
+	if s.store == nil {
+		return nil, fmt.Errorf("webhook service unavailable")
+	}
+
+	if err := s.validate(webhook); err != nil {
+		return nil, err
+	}
+	created, err := s.store.CreateWebhook(ctx, webhook)
+	if err != nil {
+		return nil, err
+	}
+	redacted := created.Redacted()
+	if err := s.audit.Record(ctx, domain.AuditActionWebhookCreate, domain.AuditEntityWebhook,
+		strconv.FormatInt(created.ID, 10), nil, redacted); err != nil {
+		span.RecordError(err)
+	}
+	return &redacted, nil
+}
+
+func (s *WebhookService) Disable(ctx context.Context, id int64) error {
+	ctx, span := s.tracer.Start(ctx, "webhook-service.disable")
+	defer span.End()
+	if s.store == nil {
+		return fmt.Errorf("webhook service unavailable")
+	}
+
+	if err := s.store.DisableWebhook(ctx, id); err != nil {
+		return err
+	}
+	if err := s.audit.Record(ctx, domain.AuditActionWebhookDisable, domain.AuditEntityWebhook,
+		strconv.FormatInt(id, 10), map[string]any{"disabled": false}, map[string]any{"disabled": true}); err != nil {
+		span.RecordError(err)
+	}
+	return nil
+}
+
+// List returns webhooks with header values redacted.
+func (s *WebhookService) List(ctx context.Context, includeDisabled bool) ([]domain.SignalWebhook, error) {
+	_, span := s.tracer.Start(ctx, "webhook-service.list")
+	defer span.End()
+	if s.store == nil {
+		return nil, fmt.Errorf("webhook service unavailable")
+	}
+
+	webhooks, err := s.store.ListWebhooks(ctx, includeDisabled)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i] = webhooks[i].Redacted()
+	}
+	return webhooks, nil
+}
+
+// NotifySignals posts every signal to every tenant's active webhooks, one
+// request per signal. All webhooks are tried even if one fails; the first
+// error is returned.
+func (s *WebhookService) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	ctx, span := s.tracer.Start(ctx, "webhook-service.notify-signals")
+	defer span.End()
+	if s.store == nil || len(signals) == 0 {
+		return nil
+	}
+
+	webhooks, err := s.store.ListActiveWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, webhook := range webhooks {
+		tmpl, err := s.parseTemplate(webhook.PayloadTemplate)
+		if err != nil {
+			if first == nil {
+				first = fmt.Errorf("webhook %d: %w", webhook.ID, err)
+			}
+			continue
+		}
+		for _, sig := range signals {
+			if err := s.post(ctx, webhook, tmpl, sig); err != nil && first == nil {
+				first = fmt.Errorf("webhook %d: %w", webhook.ID, err)
+			}
+		}
+	}
+	if first != nil {
+		span.RecordError(first)
+	}
+	return first
+}
+
+func (s *WebhookService) post(ctx context.Context, webhook domain.SignalWebhook, tmpl *template.Template, sig domain.Signal) error {
+	body, err := s.renderPayload(tmpl, sig)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// parseTemplate returns nil for an empty template, which sends the signal
+// as JSON.
+func (s *WebhookService) parseTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	return s.text.Parse("payload", text)
+}
+
+func (s *WebhookService) renderPayload(tmpl *template.Template, sig domain.Signal) ([]byte, error) {
+	if tmpl == nil {
+		return json.Marshal(sig)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s.text.Data(sig)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *WebhookService) validate(webhook domain.SignalWebhook) error {
+	u, err := url.Parse(strings.TrimSpace(webhook.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for name, value := range webhook.Headers {
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " :\r\n") || strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: header %q", ErrInvalidWebhook, name)
+		}
+	}
+	tmpl, err := s.parseTemplate(webhook.PayloadTemplate)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if _, err := s.renderPayload(tmpl, alerttemplate.Sample); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	return nil
+}
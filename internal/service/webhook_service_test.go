@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/alerttemplate"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type webhookStoreStub struct {
+	webhooks []domain.SignalWebhook
+	disabled []int64
+}
+
+func (s *webhookStoreStub) CreateWebhook(ctx context.Context, webhook domain.SignalWebhook) (*domain.SignalWebhook, error) {
+	webhook.ID = int64(len(s.webhooks) + 1)
+	s.webhooks = append(s.webhooks, webhook)
+	return &webhook, nil
+}
+
+func (s *webhookStoreStub) DisableWebhook(ctx context.Context, id int64) error {
+	s.disabled = append(s.disabled, id)
+	return nil
+}
+
+func (s *webhookStoreStub) ListWebhooks(ctx context.Context, includeDisabled bool) ([]domain.SignalWebhook, error) {
+	return append([]domain.SignalWebhook(nil), s.webhooks...), nil
+}
+
+func (s *webhookStoreStub) ListActiveWebhooks(ctx context.Context) ([]domain.SignalWebhook, error) {
+	return append([]domain.SignalWebhook(nil), s.webhooks...), nil
+}
+
+func TestWebhookServiceCreateValidates(t *testing.T) {
+	tracer := trace.NewNoopTracerProvider().Tracer("test")
+	store := &webhookStoreStub{}
+	audit := &auditStoreStub{}
+	svc := NewWebhookService(tracer, store, NewAuditService(tracer, audit))
+
+	for name, webhook := range map[string]domain.SignalWebhook{
+		"relative url":   {URL: "/hooks"},
+		"ftp url":        {URL: "ftp://example.com/hook"},
+		"bad template":   {URL: "https://example.com", PayloadTemplate: "{{.Symbol"},
+		"unknown field":  {URL: "https://example.com", PayloadTemplate: "{{.Nope}}"},
+		"header newline": {URL: "https://example.com", Headers: map[string]string{"X-A": "b\r\nX-B: c"}},
+		"header colon":   {URL: "https://example.com", Headers: map[string]string{"X-A:": "b"}},
+	} {
+		if _, err := svc.Create(context.Background(), webhook); !errors.Is(err, ErrInvalidWebhook) {
+			t.Fatalf("%s: expected ErrInvalidWebhook, got %v", name, err)
+		}
+	}
+	if len(store.webhooks) != 0 {
+		t.Fatalf("invalid webhooks must not be stored: %+v", store.webhooks)
+	}
+
+	created, err := svc.Create(context.Background(), domain.SignalWebhook{
+		URL:             "https://example.com/hook",
+		PayloadTemplate: `{"content":{{json .Symbol}}}`,
+		Headers:         map[string]string{"Authorization": "Bearer secret"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.Headers["Authorization"] != "[redacted]" || store.webhooks[0].Headers["Authorization"] != "Bearer secret" {
+		t.Fatalf("expected stored secret and redacted response, got %+v / %+v", created, store.webhooks[0])
+	}
+	if len(audit.entries) != 1 || strings.Contains(audit.entries[0].AfterJSON, "secret") {
+		t.Fatalf("expected one redacted audit entry, got %+v", audit.entries)
+	}
+}
+
+func TestWebhookServiceNotifySignalsRendersTemplate(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	var auth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		auth = append(auth, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &webhookStoreStub{webhooks: []domain.SignalWebhook{
+		{
+			ID:              1,
+			URL:             server.URL,
+			PayloadTemplate: `{"content":"{{upper .Direction}} {{.Symbol}} {{.Interval}}{{with .Prediction.prob_up}} p={{.}}{{end}}"}`,
+			Headers:         map[string]string{"Authorization": "Token abc"},
+		},
+		{ID: 2, URL: server.URL},
+	}}
+	svc := NewWebhookService(trace.NewNoopTracerProvider().Tracer("test"), store, nil)
+
+	ts := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	err := svc.NotifySignals(context.Background(), []domain.Signal{
+		{ID: 7, Symbol: "BTC", Interval: "1h", Indicator: domain.IndicatorRSI, Timestamp: ts, Direction: domain.DirectionLong, Details: "rsi 28.10 crossed below 30"},
+		{ID: 8, Symbol: "ETH", Interval: "1h", Indicator: "ml_ensemble_up4h", Timestamp: ts, Direction: domain.DirectionShort, Details: "model_key=ensemble_v1;model_version=3;prob_up=0.3100"},
+	})
+	if err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if len(bodies) != 4 {
+		t.Fatalf("expected one request per signal per webhook, got %d", len(bodies))
+	}
+	if bodies[0] != `{"content":"LONG BTC 1h"}` || bodies[1] != `{"content":"SHORT ETH 1h p=0.3100"}` {
+		t.Fatalf("unexpected templated bodies: %q", bodies[:2])
+	}
+	if auth[0] != "Token abc" || auth[2] != "" {
+		t.Fatalf("expected custom headers only on the first webhook, got %q", auth)
+	}
+	if !strings.Contains(bodies[2], `"symbol":"BTC"`) || !strings.Contains(bodies[2], `"id":7`) {
+		t.Fatalf("expected signal JSON without a template, got %s", bodies[2])
+	}
+}
+
+func TestWebhookServiceTemplatesUseAlertText(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := &webhookStoreStub{webhooks: []domain.SignalWebhook{{
+		ID:              1,
+		URL:             server.URL,
+		PayloadTemplate: `{"content":{{json (printf "%s %s" (t "alert.header") .Text)}}}`,
+	}}}
+	svc := NewWebhookService(trace.NewNoopTracerProvider().Tracer("test"), store, nil)
+	text, err := alerttemplate.New(`{{.Symbol}} {{direction .Direction}}`, alerttemplate.Messages{
+		"alert.header":    "Signal:",
+		"direction.short": "short \"sell\"",
+	})
+	if err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	svc.SetAlertText(text)
+
+	err = svc.NotifySignals(context.Background(), []domain.Signal{
+		{ID: 9, Symbol: "ETH", Interval: "1h", Direction: domain.DirectionShort},
+	})
+	if err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if body != `{"content":"Signal: ETH short \"sell\""}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+func TestWebhookServiceNotifySignalsReportsFailures(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if strings.HasSuffix(r.URL.Path, "/down") {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store := &webhookStoreStub{webhooks: []domain.SignalWebhook{
+		{ID: 1, URL: server.URL + "/down"},
+		{ID: 2, URL: server.URL + "/up"},
+	}}
+	svc := NewWebhookService(trace.NewNoopTracerProvider().Tracer("test"), store, nil)
+
+	err := svc.NotifySignals(context.Background(), []domain.Signal{{Symbol: "BTC", Interval: "1h"}})
+	if err == nil || !strings.Contains(err.Error(), "webhook 1: unexpected status 502") {
+		t.Fatalf("expected the failing webhook to be reported, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected the healthy webhook to still be called, got %d calls", calls)
+	}
+}
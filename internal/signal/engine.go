@@ -1,13 +1,15 @@
 package signal
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/ta"
+	"bug-free-umbrella/pkg/domain"
 )
 
 const (
@@ -22,8 +24,15 @@ const (
 	squeezeThreshold = 0.08
 )
 
+// EngineVersion is stamped on every signal the engine emits. Bump it
+// whenever detection logic or thresholds change so win rates can be
+// compared per version.
+const EngineVersion = "ta-1"
+
 type Engine struct {
-	now func() time.Time
+	now       func() time.Time
+	overrides map[string]domain.RiskLevel
+	ichimoku  domain.IchimokuPeriods
 }
 
 type event struct {
@@ -35,7 +44,68 @@ func NewEngine(now func() time.Time) *Engine {
 	if now == nil {
 		now = time.Now
 	}
-	return &Engine{now: now}
+	return &Engine{now: now, ichimoku: domain.DefaultIchimokuPeriods}
+}
+
+// SetIchimokuPeriods replaces the Ichimoku periods. Invalid periods are
+// ignored.
+func (e *Engine) SetIchimokuPeriods(p domain.IchimokuPeriods) {
+	if p.Valid() {
+		e.ichimoku = p
+	}
+}
+
+// SetRiskOverrides replaces the default risk of the matching indicators and
+// intervals. An override for one interval wins over one for every interval.
+func (e *Engine) SetRiskOverrides(overrides []domain.RiskOverride) {
+	e.overrides = make(map[string]domain.RiskLevel, len(overrides))
+	for _, o := range overrides {
+		e.overrides[o.Indicator+"/"+o.Interval] = o.Risk
+	}
+}
+
+// RiskOverrideStore lists the risk overrides operators keep in the database.
+type RiskOverrideStore interface {
+	ListRiskOverrides(ctx context.Context) ([]domain.RiskOverride, error)
+}
+
+// LoadRiskOverrides layers the stored overrides over the configured ones; a
+// stored override wins for the same indicator and interval. Stored rows for
+// indicators or intervals the engine does not produce are dropped. On a
+// store error the configured overrides are returned with the error.
+func LoadRiskOverrides(ctx context.Context, configured []domain.RiskOverride, store RiskOverrideStore) ([]domain.RiskOverride, error) {
+	stored, err := store.ListRiskOverrides(ctx)
+	if err != nil {
+		return configured, err
+	}
+	out := make([]domain.RiskOverride, 0, len(configured)+len(stored))
+	index := make(map[string]int, len(configured)+len(stored))
+	for _, o := range append(append([]domain.RiskOverride(nil), configured...), stored...) {
+		if !domain.IsClassicIndicator(o.Indicator) || !o.Risk.IsValid() ||
+			(o.Interval != "" && domain.IntervalDuration(o.Interval) == 0) {
+			continue
+		}
+		key := o.Indicator + "/" + o.Interval
+		if i, ok := index[key]; ok {
+			out[i] = o
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// RiskFor returns the risk the engine assigns to signals of indicator on
+// interval, after any overrides.
+func (e *Engine) RiskFor(indicator, interval string) domain.RiskLevel {
+	if risk, ok := e.overrides[indicator+"/"+interval]; ok {
+		return risk
+	}
+	if risk, ok := e.overrides[indicator+"/"]; ok {
+		return risk
+	}
+	return riskFor(indicator, interval)
 }
 
 // Generate produces deterministic signals using the most recent completed candle.
@@ -60,6 +130,9 @@ func (e *Engine) Generate(candles []*domain.Candle) []domain.Signal {
 	if ev, ok := detectVolumeAnomaly(normalized); ok {
 		result = append(result, e.newSignal(latest, domain.IndicatorVolumeZ, ev))
 	}
+	if ev, ok := detectIchimoku(normalized, e.ichimoku); ok {
+		result = append(result, e.newSignal(latest, domain.IndicatorIchimoku, ev))
+	}
 
 	return result
 }
@@ -75,9 +148,10 @@ func (e *Engine) newSignal(candle domain.Candle, indicator string, ev event) dom
 		Interval:  candle.Interval,
 		Indicator: indicator,
 		Timestamp: ts,
-		Risk:      riskFor(indicator, candle.Interval),
+		Risk:      e.RiskFor(indicator, candle.Interval),
 		Direction: ev.direction,
 		Details:   ev.details,
+		Version:   EngineVersion,
 	}
 }
 
@@ -205,6 +279,74 @@ func detectVolumeAnomaly(candles []domain.Candle) (event, bool) {
 	return event{direction: direction, details: fmt.Sprintf("volume z-score %.2f", z)}, true
 }
 
+// detectIchimoku fires on a Tenkan/Kijun cross or a close breaking out of
+// the cloud. When both happen in the same direction the details name both;
+// when they disagree the breakout wins.
+func detectIchimoku(candles []domain.Candle, p domain.IchimokuPeriods) (event, bool) {
+	if len(candles) < p.Kijun+1 {
+		return event{}, false
+	}
+	lines := ichimokuSeries(candles, p)
+	n := len(candles)
+
+	var cross, breakout event
+	prevDelta := lines.tenkan[n-2] - lines.kijun[n-2]
+	currDelta := lines.tenkan[n-1] - lines.kijun[n-1]
+	if prevDelta <= 0 && currDelta > 0 {
+		cross = event{direction: domain.DirectionLong, details: "tenkan crossed above kijun"}
+	} else if prevDelta >= 0 && currDelta < 0 {
+		cross = event{direction: domain.DirectionShort, details: "tenkan crossed below kijun"}
+	}
+
+	prevTop, prevBottom, prevOK := lines.cloud(n - 2)
+	currTop, currBottom, currOK := lines.cloud(n - 1)
+	if prevOK && currOK {
+		prevClose, currClose := candles[n-2].Close, candles[n-1].Close
+		if prevClose <= prevTop && currClose > currTop {
+			breakout = event{direction: domain.DirectionLong, details: "close broke above the cloud"}
+		} else if prevClose >= prevBottom && currClose < currBottom {
+			breakout = event{direction: domain.DirectionShort, details: "close broke below the cloud"}
+		}
+	}
+
+	ev := cross
+	switch {
+	case breakout.direction == "":
+	case cross.direction == breakout.direction:
+		ev.details = cross.details + "; " + breakout.details
+	default:
+		ev = breakout
+	}
+	if ev.direction == "" {
+		return event{}, false
+	}
+	ev.details = fmt.Sprintf("ichimoku %s (%s)", ev.details, p)
+	return ev, true
+}
+
+// ichimokuLines are the Ichimoku lines at each candle. spanA and spanB
+// form the cloud under each candle, so they come from the candles Kijun
+// periods earlier.
+type ichimokuLines struct {
+	tenkan, kijun, spanA, spanB []float64
+}
+
+func ichimokuSeries(candles []domain.Candle, p domain.IchimokuPeriods) ichimokuLines {
+	var lines ichimokuLines
+	lines.tenkan, lines.kijun, lines.spanA, lines.spanB = ta.IchimokuSeries(extractHighs(candles), extractLows(candles), p.Tenkan, p.Kijun, p.SenkouB)
+	return lines
+}
+
+// cloud returns the top and bottom of the cloud under candle i, and false
+// while there is too little history to draw it.
+func (l ichimokuLines) cloud(i int) (top, bottom float64, ok bool) {
+	a, b := l.spanA[i], l.spanB[i]
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return 0, 0, false
+	}
+	return math.Max(a, b), math.Min(a, b), true
+}
+
 func extractCloses(candles []domain.Candle) []float64 {
 	values := make([]float64, len(candles))
 	for i := range candles {
@@ -213,6 +355,22 @@ func extractCloses(candles []domain.Candle) []float64 {
 	return values
 }
 
+func extractHighs(candles []domain.Candle) []float64 {
+	values := make([]float64, len(candles))
+	for i := range candles {
+		values[i] = candles[i].High
+	}
+	return values
+}
+
+func extractLows(candles []domain.Candle) []float64 {
+	values := make([]float64, len(candles))
+	for i := range candles {
+		values[i] = candles[i].Low
+	}
+	return values
+}
+
 func extractVolumes(candles []domain.Candle) []float64 {
 	values := make([]float64, len(candles))
 	for i := range candles {
@@ -308,41 +466,54 @@ func meanStd(values []float64) (mean, std float64) {
 	return mean, std
 }
 
+// riskFor rates a signal by candle length, so intervals added to the
+// registry fall into the nearest band: shorter candles are noisier.
 func riskFor(indicator, interval string) domain.RiskLevel {
+	d := domain.IntervalDuration(interval)
+	if d == 0 {
+		return domain.RiskLevel3
+	}
 	switch indicator {
 	case domain.IndicatorRSI:
-		switch interval {
-		case "1d", "4h":
+		switch {
+		case d >= 4*time.Hour:
 			return domain.RiskLevel2
-		case "1h":
+		case d >= time.Hour:
 			return domain.RiskLevel3
-		case "15m", "5m":
+		default:
 			return domain.RiskLevel4
 		}
 	case domain.IndicatorMACD:
-		switch interval {
-		case "5m":
+		switch {
+		case d <= 5*time.Minute:
 			return domain.RiskLevel5
-		case "15m":
+		case d <= 15*time.Minute:
 			return domain.RiskLevel4
 		default:
 			return domain.RiskLevel3
 		}
 	case domain.IndicatorBollinger:
-		switch interval {
-		case "5m":
+		switch {
+		case d <= 5*time.Minute:
 			return domain.RiskLevel5
-		case "15m", "1h":
+		case d <= time.Hour:
 			return domain.RiskLevel4
 		default:
 			return domain.RiskLevel3
 		}
 	case domain.IndicatorVolumeZ:
-		switch interval {
-		case "5m", "15m":
+		if d <= 15*time.Minute {
 			return domain.RiskLevel4
-		default:
+		}
+		return domain.RiskLevel3
+	case domain.IndicatorIchimoku:
+		switch {
+		case d >= 4*time.Hour:
+			return domain.RiskLevel2
+		case d >= time.Hour:
 			return domain.RiskLevel3
+		default:
+			return domain.RiskLevel4
 		}
 	}
 	return domain.RiskLevel3
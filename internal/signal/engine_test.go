@@ -1,10 +1,13 @@
 package signal
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestGenerateVolumeAnomalySignal(t *testing.T) {
@@ -41,6 +44,9 @@ func TestGenerateVolumeAnomalySignal(t *testing.T) {
 			if s.Risk != domain.RiskLevel4 {
 				t.Fatalf("expected risk 4 for 15m volume anomaly, got %d", s.Risk)
 			}
+			if s.Version != EngineVersion {
+				t.Fatalf("expected version %s, got %q", EngineVersion, s.Version)
+			}
 		}
 	}
 	if !found {
@@ -91,6 +97,59 @@ func TestRiskForMapping(t *testing.T) {
 	if got := riskFor(domain.IndicatorBollinger, "5m"); got != domain.RiskLevel5 {
 		t.Fatalf("expected Bollinger 5m risk=5, got %d", got)
 	}
+	if got := riskFor(domain.IndicatorIchimoku, "15m"); got != domain.RiskLevel4 {
+		t.Fatalf("expected Ichimoku 15m risk=4, got %d", got)
+	}
+}
+
+func TestRiskOverrides(t *testing.T) {
+	engine := NewEngine(nil)
+	engine.SetRiskOverrides([]domain.RiskOverride{
+		{Indicator: domain.IndicatorRSI, Risk: domain.RiskLevel1},
+		{Indicator: domain.IndicatorRSI, Interval: "5m", Risk: domain.RiskLevel5},
+	})
+	if got := engine.RiskFor(domain.IndicatorRSI, "1h"); got != domain.RiskLevel1 {
+		t.Fatalf("expected the all-interval override, got %d", got)
+	}
+	if got := engine.RiskFor(domain.IndicatorRSI, "5m"); got != domain.RiskLevel5 {
+		t.Fatalf("expected the 5m override to win, got %d", got)
+	}
+	if got := engine.RiskFor(domain.IndicatorMACD, "15m"); got != domain.RiskLevel4 {
+		t.Fatalf("expected the default for other indicators, got %d", got)
+	}
+}
+
+func TestLoadRiskOverridesLetsStoredRowsWin(t *testing.T) {
+	configured := []domain.RiskOverride{
+		{Indicator: domain.IndicatorRSI, Interval: "5m", Risk: domain.RiskLevel3},
+		{Indicator: domain.IndicatorMACD, Risk: domain.RiskLevel4},
+	}
+	store := riskOverrideStoreStub{rows: []domain.RiskOverride{
+		{Indicator: domain.IndicatorRSI, Interval: "5m", Risk: domain.RiskLevel5},
+		{Indicator: "ml_xgboost", Risk: domain.RiskLevel1},
+	}}
+
+	got, err := LoadRiskOverrides(context.Background(), configured, store)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Risk != domain.RiskLevel5 || got[1].Indicator != domain.IndicatorMACD {
+		t.Fatalf("expected the stored rsi/5m row to replace the configured one, got %+v", got)
+	}
+
+	got, err = LoadRiskOverrides(context.Background(), configured, riskOverrideStoreStub{err: errors.New("db down")})
+	if err == nil || len(got) != len(configured) {
+		t.Fatalf("expected configured overrides with the store error, got %+v err=%v", got, err)
+	}
+}
+
+type riskOverrideStoreStub struct {
+	rows []domain.RiskOverride
+	err  error
+}
+
+func (s riskOverrideStoreStub) ListRiskOverrides(context.Context) ([]domain.RiskOverride, error) {
+	return s.rows, s.err
 }
 
 func TestGenerateReturnsNilForInsufficientCandles(t *testing.T) {
@@ -100,3 +159,82 @@ func TestGenerateReturnsNilForInsufficientCandles(t *testing.T) {
 		t.Fatalf("expected no signals, got %d", len(got))
 	}
 }
+
+// ichimokuCandles returns flat 4h candles around 100 followed by candles
+// closing at each of closes, with a one-point range around the close.
+func ichimokuCandles(flat int, closes ...float64) []domain.Candle {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]domain.Candle, 0, flat+len(closes))
+	for i := 0; i < flat+len(closes); i++ {
+		c := 100.0
+		if i >= flat {
+			c = closes[i-flat]
+		}
+		out = append(out, domain.Candle{
+			Symbol: "BTC", Interval: "4h", OpenTime: base.Add(time.Duration(i) * 4 * time.Hour),
+			Open: c, High: c + 1, Low: c - 1, Close: c,
+		})
+	}
+	return out
+}
+
+func TestDetectIchimokuTenkanKijunCross(t *testing.T) {
+	// A steady rally lifts the Tenkan low off the flat lows nine candles in,
+	// while the Kijun still spans them.
+	var rally []float64
+	for k := 1; k <= 9; k++ {
+		rally = append(rally, 100+2*float64(k))
+	}
+	candles := ichimokuCandles(80, rally...)
+
+	ev, ok := detectIchimoku(candles, domain.DefaultIchimokuPeriods)
+	if !ok || ev.direction != domain.DirectionLong || ev.details != "ichimoku tenkan crossed above kijun (9/26/52)" {
+		t.Fatalf("expected a bullish tenkan/kijun cross, got %+v ok=%v", ev, ok)
+	}
+	if _, ok := detectIchimoku(candles[:len(candles)-1], domain.DefaultIchimokuPeriods); ok {
+		t.Fatal("expected no signal while the tenkan still spans the flat lows")
+	}
+}
+
+func TestDetectIchimokuCloudBreakout(t *testing.T) {
+	ev, ok := detectIchimoku(ichimokuCandles(80, 103), domain.DefaultIchimokuPeriods)
+	if !ok || ev.direction != domain.DirectionLong || !strings.Contains(ev.details, "close broke above the cloud") {
+		t.Fatalf("expected a bullish cloud breakout, got %+v ok=%v", ev, ok)
+	}
+	ev, ok = detectIchimoku(ichimokuCandles(80, 97), domain.DefaultIchimokuPeriods)
+	if !ok || ev.direction != domain.DirectionShort || !strings.Contains(ev.details, "close broke below the cloud") {
+		t.Fatalf("expected a bearish cloud breakdown, got %+v ok=%v", ev, ok)
+	}
+	// The cloud under the previous candle needs 52+26 candles of history.
+	if _, ok := detectIchimoku(ichimokuCandles(70, 103), domain.DefaultIchimokuPeriods); ok {
+		t.Fatal("expected no breakout before the cloud can be drawn")
+	}
+}
+
+func TestSetIchimokuPeriods(t *testing.T) {
+	engine := NewEngine(nil)
+	engine.SetIchimokuPeriods(domain.IchimokuPeriods{Tenkan: 26, Kijun: 9, SenkouB: 52})
+	if engine.ichimoku != domain.DefaultIchimokuPeriods {
+		t.Fatalf("expected invalid periods ignored, got %v", engine.ichimoku)
+	}
+
+	// Shorter periods draw the cloud, and break out of it, sooner.
+	engine.SetIchimokuPeriods(domain.IchimokuPeriods{Tenkan: 5, Kijun: 10, SenkouB: 20})
+	candles := ichimokuCandles(40, 103)
+	in := make([]*domain.Candle, len(candles))
+	for i := range candles {
+		in[i] = &candles[i]
+	}
+	var found bool
+	for _, s := range engine.Generate(in) {
+		if s.Indicator == domain.IndicatorIchimoku {
+			found = true
+			if s.Direction != domain.DirectionLong || s.Risk != domain.RiskLevel2 || !strings.HasSuffix(s.Details, "(5/10/20)") {
+				t.Fatalf("unexpected ichimoku signal %+v", s)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("expected an ichimoku breakout with the shorter periods")
+	}
+}
@@ -0,0 +1,212 @@
+package signal
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// Value is one named intermediate of an indicator check.
+type Value struct {
+	Name  string
+	Value float64
+}
+
+// Check is how one indicator evaluated on the latest candle. Fired and
+// Direction come from the same detector Generate uses, so a check agrees
+// with the live engine; Values are the intermediates that decided it and
+// Reason says in words why the indicator did not fire.
+type Check struct {
+	Indicator string
+	Fired     bool
+	Direction domain.SignalDirection
+	Risk      domain.RiskLevel
+	Details   string
+	Reason    string
+	Values    []Value
+}
+
+// Explanation is the outcome of every engine indicator on one candle.
+type Explanation struct {
+	Symbol   string
+	Interval string
+	OpenTime time.Time
+	Close    float64
+	Candles  int
+	Checks   []Check
+}
+
+// Explain evaluates each indicator on the most recent candle, as Generate
+// would, and reports the intermediate values along with the outcome.
+func (e *Engine) Explain(candles []*domain.Candle) Explanation {
+	normalized := normalizeCandles(candles)
+	out := Explanation{Candles: len(normalized)}
+	if len(normalized) > 0 {
+		latest := normalized[len(normalized)-1]
+		out.Symbol, out.Interval, out.OpenTime, out.Close = latest.Symbol, latest.Interval, latest.OpenTime.UTC(), latest.Close
+	}
+
+	checks := []struct {
+		indicator string
+		detect    func([]domain.Candle) (event, bool)
+		explain   func([]domain.Candle) ([]Value, string)
+	}{
+		{domain.IndicatorRSI, detectRSI, explainRSI},
+		{domain.IndicatorMACD, detectMACD, explainMACD},
+		{domain.IndicatorBollinger, detectBollinger, explainBollinger},
+		{domain.IndicatorVolumeZ, detectVolumeAnomaly, explainVolume},
+		{
+			domain.IndicatorIchimoku,
+			func(c []domain.Candle) (event, bool) { return detectIchimoku(c, e.ichimoku) },
+			func(c []domain.Candle) ([]Value, string) { return explainIchimoku(c, e.ichimoku) },
+		},
+	}
+	for _, c := range checks {
+		check := Check{Indicator: c.indicator}
+		if len(normalized) >= 2 {
+			if ev, ok := c.detect(normalized); ok {
+				check.Fired = true
+				check.Direction = ev.direction
+				check.Details = ev.details
+				check.Risk = e.RiskFor(c.indicator, out.Interval)
+			}
+		}
+		check.Values, check.Reason = c.explain(normalized)
+		if check.Fired {
+			check.Reason = ""
+		}
+		out.Checks = append(out.Checks, check)
+	}
+	return out
+}
+
+func notEnoughCandles(need, have int) string {
+	return fmt.Sprintf("needs %d candles, have %d", need, have)
+}
+
+func explainRSI(candles []domain.Candle) ([]Value, string) {
+	need := rsiPeriod + 2
+	if len(candles) < need {
+		return nil, notEnoughCandles(need, len(candles))
+	}
+	series := rsiSeries(extractCloses(candles), rsiPeriod)
+	prev, curr := series[len(series)-2], series[len(series)-1]
+	values := []Value{{"prev_rsi", prev}, {"rsi", curr}, {"oversold", 30}, {"overbought", 70}}
+	switch {
+	case curr < 30 && prev < 30:
+		return values, "rsi was already below 30 on the previous candle"
+	case curr > 70 && prev > 70:
+		return values, "rsi was already above 70 on the previous candle"
+	}
+	return values, "rsi did not cross 30 or 70"
+}
+
+func explainMACD(candles []domain.Candle) ([]Value, string) {
+	need := macdSlowPeriod + macdSignalPeriod
+	if len(candles) < need {
+		return nil, notEnoughCandles(need, len(candles))
+	}
+	macdLine, signalLine := macdSeries(extractCloses(candles), macdFastPeriod, macdSlowPeriod, macdSignalPeriod)
+	n := len(macdLine)
+	prevDelta := macdLine[n-2] - signalLine[n-2]
+	currDelta := macdLine[n-1] - signalLine[n-1]
+	values := []Value{
+		{"macd", macdLine[n-1]},
+		{"signal", signalLine[n-1]},
+		{"prev_delta", prevDelta},
+		{"delta", currDelta},
+	}
+	if currDelta > 0 {
+		return values, "macd stayed above its signal line"
+	}
+	if currDelta < 0 {
+		return values, "macd stayed below its signal line"
+	}
+	return values, "macd is on its signal line"
+}
+
+func explainBollinger(candles []domain.Candle) ([]Value, string) {
+	need := bollingerPeriod + 1
+	if len(candles) < need {
+		return nil, notEnoughCandles(need, len(candles))
+	}
+	closes := extractCloses(candles)
+	prevIdx, currIdx := len(closes)-2, len(closes)-1
+	prevMean, prevStd := meanStd(closes[prevIdx-bollingerPeriod+1 : prevIdx+1])
+	currMean, currStd := meanStd(closes[currIdx-bollingerPeriod+1 : currIdx+1])
+	if prevMean == 0 || currMean == 0 {
+		return nil, "mean close is 0"
+	}
+	prevWidth := 2 * bollingerStdDevs * prevStd / prevMean
+	values := []Value{
+		{"prev_width", prevWidth},
+		{"squeeze_below", squeezeThreshold},
+		{"upper", currMean + bollingerStdDevs*currStd},
+		{"middle", currMean},
+		{"lower", currMean - bollingerStdDevs*currStd},
+		{"close", closes[currIdx]},
+	}
+	if prevWidth > squeezeThreshold {
+		return values, fmt.Sprintf("no squeeze: previous band width %.3f is above %.3f", prevWidth, squeezeThreshold)
+	}
+	return values, "close did not break out of the bands"
+}
+
+func explainVolume(candles []domain.Candle) ([]Value, string) {
+	need := volumeWindow + 1
+	if len(candles) < need {
+		return nil, notEnoughCandles(need, len(candles))
+	}
+	volumes := extractVolumes(candles)
+	mean, std := meanStd(volumes[len(volumes)-1-volumeWindow : len(volumes)-1])
+	curr := volumes[len(volumes)-1]
+	z := math.NaN()
+	if std > 0 {
+		z = (curr - mean) / std
+	}
+	values := []Value{{"volume", curr}, {"mean", mean}, {"std", std}, {"z", z}, {"threshold", volumeZThreshold}}
+	if std == 0 {
+		return values, fmt.Sprintf("volume was flat over the last %d candles", volumeWindow)
+	}
+	return values, fmt.Sprintf("z-score %.2f is below %.2f", z, volumeZThreshold)
+}
+
+func explainIchimoku(candles []domain.Candle, p domain.IchimokuPeriods) ([]Value, string) {
+	need := p.Kijun + 1
+	if len(candles) < need {
+		return nil, notEnoughCandles(need, len(candles))
+	}
+	lines := ichimokuSeries(candles, p)
+	n := len(candles)
+	prevDelta := lines.tenkan[n-2] - lines.kijun[n-2]
+	currDelta := lines.tenkan[n-1] - lines.kijun[n-1]
+	values := []Value{
+		{"tenkan", lines.tenkan[n-1]},
+		{"kijun", lines.kijun[n-1]},
+		{"prev_delta", prevDelta},
+		{"delta", currDelta},
+	}
+
+	cross := "tenkan is on kijun"
+	if currDelta > 0 {
+		cross = "tenkan stayed above kijun"
+	} else if currDelta < 0 {
+		cross = "tenkan stayed below kijun"
+	}
+	_, _, prevOK := lines.cloud(n - 2)
+	top, bottom, ok := lines.cloud(n - 1)
+	if !prevOK || !ok {
+		return values, fmt.Sprintf("%s; the cloud needs %d candles", cross, p.SenkouB+p.Kijun+1)
+	}
+	closeVal := candles[n-1].Close
+	values = append(values, Value{"cloud_top", top}, Value{"cloud_bottom", bottom}, Value{"close", closeVal})
+	switch {
+	case closeVal > top:
+		return values, cross + "; close stayed above the cloud"
+	case closeVal < bottom:
+		return values, cross + "; close stayed below the cloud"
+	}
+	return values, cross + "; close is inside the cloud"
+}
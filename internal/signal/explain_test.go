@@ -0,0 +1,73 @@
+package signal
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestExplainAgreesWithGenerate(t *testing.T) {
+	engine := NewEngine(nil)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]*domain.Candle, 0, 60)
+	for i := 0; i < 60; i++ {
+		vol := 100.0 + float64(i%5)
+		if i == 59 {
+			vol = 1000
+		}
+		candles = append(candles, &domain.Candle{
+			Symbol:   "BTC",
+			Interval: "1h",
+			OpenTime: base.Add(time.Duration(i) * time.Hour),
+			Close:    100 + 5*math.Sin(float64(i)/3),
+			Volume:   vol,
+		})
+	}
+
+	fired := map[string]domain.Signal{}
+	for _, s := range engine.Generate(candles) {
+		fired[s.Indicator] = s
+	}
+	got := engine.Explain(candles)
+	if got.Candles != 60 || !got.OpenTime.Equal(base.Add(59*time.Hour)) || got.Symbol != "BTC" {
+		t.Fatalf("unexpected explained candle: %+v", got)
+	}
+	if len(got.Checks) != len(EngineIndicators) {
+		t.Fatalf("expected one check per engine indicator, got %d", len(got.Checks))
+	}
+	for _, c := range got.Checks {
+		s, ok := fired[c.Indicator]
+		if c.Fired != ok {
+			t.Fatalf("%s: explain fired=%v, generate fired=%v", c.Indicator, c.Fired, ok)
+		}
+		if ok && (c.Direction != s.Direction || c.Details != s.Details || c.Risk != s.Risk) {
+			t.Fatalf("%s: explain %+v disagrees with signal %+v", c.Indicator, c, s)
+		}
+		if !ok && c.Reason == "" {
+			t.Fatalf("%s: expected a reason it did not fire", c.Indicator)
+		}
+		if len(c.Values) == 0 {
+			t.Fatalf("%s: expected intermediate values", c.Indicator)
+		}
+	}
+	if !fired[domain.IndicatorVolumeZ].Timestamp.Equal(got.OpenTime) {
+		t.Fatal("expected the volume spike to fire on the latest candle")
+	}
+}
+
+func TestExplainReportsMissingHistory(t *testing.T) {
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var candles []*domain.Candle
+	for i := 0; i < 10; i++ {
+		candles = append(candles, &domain.Candle{Symbol: "ETH", Interval: "4h", OpenTime: base.Add(time.Duration(i) * 4 * time.Hour), Close: 10})
+	}
+	got := NewEngine(nil).Explain(candles)
+	for _, c := range got.Checks {
+		if c.Fired || !strings.HasPrefix(c.Reason, "needs ") || !strings.HasSuffix(c.Reason, "have 10") {
+			t.Fatalf("%s: expected a missing-history reason, got %+v", c.Indicator, c)
+		}
+	}
+}
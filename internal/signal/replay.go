@@ -0,0 +1,46 @@
+package signal
+
+import (
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// EngineIndicators lists the indicators Generate can emit. Replays compare
+// only these against the live signals table.
+var EngineIndicators = []string{
+	domain.IndicatorRSI,
+	domain.IndicatorMACD,
+	domain.IndicatorBollinger,
+	domain.IndicatorVolumeZ,
+	domain.IndicatorIchimoku,
+}
+
+// Replay feeds candles through the engine one at a time, as the live poller
+// would have seen them, and returns the signals whose candle opened within
+// [from, to]. Each step sees at most lookback candles ending at the current
+// one. Earlier candles only warm up the indicators.
+func (e *Engine) Replay(candles []*domain.Candle, lookback int, from, to time.Time) []domain.Signal {
+	ordered := normalizeCandles(candles)
+	if lookback <= 0 {
+		lookback = len(ordered)
+	}
+
+	window := make([]*domain.Candle, 0, lookback)
+	var out []domain.Signal
+	for i := range ordered {
+		c := &ordered[i]
+		if c.OpenTime.After(to) {
+			break
+		}
+		if c.OpenTime.Before(from) {
+			continue
+		}
+		window = window[:0]
+		for j := max(0, i-lookback+1); j <= i; j++ {
+			window = append(window, &ordered[j])
+		}
+		out = append(out, e.Generate(window)...)
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package signal
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestReplayMatchesLiveGeneration(t *testing.T) {
+	engine := NewEngine(nil)
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var candles []*domain.Candle
+	for i := 0; i < 60; i++ {
+		vol := 100.0 + float64(i%5)
+		if i == 40 || i == 55 {
+			vol = 1000
+		}
+		candles = append(candles, &domain.Candle{
+			Symbol:   "BTC",
+			Interval: "1h",
+			OpenTime: base.Add(time.Duration(i) * time.Hour),
+			Close:    100 + float64(i%7),
+			Volume:   vol,
+		})
+	}
+	// Newest first, as the repository returns them.
+	reversed := make([]*domain.Candle, len(candles))
+	for i, c := range candles {
+		reversed[len(candles)-1-i] = c
+	}
+
+	from, to := base.Add(30*time.Hour), base.Add(50*time.Hour)
+	replayed := engine.Replay(reversed, 25, from, to)
+
+	var live []domain.Signal
+	for i := 30; i <= 50; i++ {
+		live = append(live, engine.Generate(candles[max(0, i-24):i+1])...)
+	}
+	if len(replayed) != len(live) {
+		t.Fatalf("expected %d replayed signals, got %d", len(live), len(replayed))
+	}
+	for i := range live {
+		if replayed[i] != live[i] {
+			t.Fatalf("signal %d differs: live %+v replay %+v", i, live[i], replayed[i])
+		}
+	}
+
+	foundSpike := false
+	for _, s := range replayed {
+		if s.Timestamp.Before(from) || s.Timestamp.After(to) {
+			t.Fatalf("signal outside replay range: %+v", s)
+		}
+		if s.Indicator == domain.IndicatorVolumeZ && s.Timestamp.Equal(base.Add(40*time.Hour)) {
+			foundSpike = true
+		}
+	}
+	if !foundSpike {
+		t.Fatal("expected the volume spike inside the range to be replayed")
+	}
+}
+
+func TestEngineIndicatorsMatchClassicRegistry(t *testing.T) {
+	var classic []string
+	for _, info := range domain.Indicators {
+		if info.Kind == domain.IndicatorKindClassic {
+			classic = append(classic, info.Key)
+		}
+	}
+	if !slices.Equal(EngineIndicators, classic) {
+		t.Fatalf("engine indicators %v drifted from classic registry %v", EngineIndicators, classic)
+	}
+}
@@ -0,0 +1,121 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Dependency is a backing service that must answer before background jobs
+// start.
+type Dependency struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Gate holds background jobs back until every dependency answers, so a
+// process that boots while Postgres or Redis is briefly down starts its jobs
+// once they recover instead of running them against a dead connection.
+type Gate struct {
+	deps  []Dependency
+	ready chan struct{}
+	once  sync.Once
+
+	mu      sync.Mutex
+	pending []string
+}
+
+// NewGate returns a gate over deps. A gate without dependencies is ready at
+// once.
+func NewGate(deps ...Dependency) *Gate {
+	g := &Gate{deps: deps, ready: make(chan struct{})}
+	for _, dep := range deps {
+		g.pending = append(g.pending, dep.Name)
+	}
+	if len(deps) == 0 {
+		g.open()
+	}
+	return g
+}
+
+// Start checks every dependency once. If any fails, it keeps checking in
+// the background with backoff until all answer or ctx ends.
+func (g *Gate) Start(ctx context.Context) {
+	if g.Ready() {
+		return
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	err := g.checkAll(attemptCtx)
+	cancel()
+	if err == nil {
+		g.open()
+		return
+	}
+	go func() {
+		if Retry(ctx, "background job dependencies", g.checkAll) == nil {
+			g.open()
+		}
+	}()
+}
+
+// Go runs fn once the dependencies answer: right away if they already do,
+// otherwise from a goroutine that waits for them. fn is dropped if ctx ends
+// first.
+func (g *Gate) Go(ctx context.Context, name string, fn func()) {
+	if g.Ready() {
+		fn()
+		return
+	}
+	log.Printf("%s waits for %v before starting", name, g.Pending())
+	go func() {
+		select {
+		case <-g.ready:
+			log.Printf("Starting %s: dependencies are up", name)
+			fn()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// Ready reports whether every dependency has answered.
+func (g *Gate) Ready() bool {
+	select {
+	case <-g.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Pending names the dependencies that have not answered yet.
+func (g *Gate) Pending() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.pending...)
+}
+
+func (g *Gate) checkAll(ctx context.Context) error {
+	var pending []string
+	var errs []error
+	for _, dep := range g.deps {
+		if err := dep.Check(ctx); err != nil {
+			pending = append(pending, dep.Name)
+			errs = append(errs, fmt.Errorf("%s: %w", dep.Name, err))
+		}
+	}
+	g.mu.Lock()
+	g.pending = pending
+	g.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+func (g *Gate) open() {
+	g.once.Do(func() {
+		g.mu.Lock()
+		g.pending = nil
+		g.mu.Unlock()
+		close(g.ready)
+	})
+}
@@ -0,0 +1,68 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGateWithoutDependenciesRunsAtOnce(t *testing.T) {
+	g := NewGate()
+	ran := false
+	g.Go(context.Background(), "job", func() { ran = true })
+	if !ran || !g.Ready() {
+		t.Fatal("expected a gate without dependencies to run jobs at once")
+	}
+}
+
+func TestGateDefersJobsUntilDependenciesAnswer(t *testing.T) {
+	var up atomic.Bool
+	g := NewGate(
+		Dependency{Name: "postgres", Check: func(ctx context.Context) error {
+			if !up.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		}},
+		Dependency{Name: "redis", Check: func(ctx context.Context) error { return nil }},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g.Start(ctx)
+
+	started := make(chan struct{})
+	g.Go(ctx, "price poller", func() { close(started) })
+	if g.Ready() {
+		t.Fatal("expected the gate to wait for postgres")
+	}
+	if pending := g.Pending(); len(pending) != 1 || pending[0] != "postgres" {
+		t.Fatalf("expected postgres pending, got %v", pending)
+	}
+
+	up.Store(true)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("expected the job to start once postgres answers")
+	}
+	if !g.Ready() || len(g.Pending()) != 0 {
+		t.Fatalf("expected the gate to be ready, pending=%v", g.Pending())
+	}
+}
+
+func TestGateDropsJobsWhenContextEnds(t *testing.T) {
+	g := NewGate(Dependency{Name: "postgres", Check: func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	g.Start(ctx)
+	var ran atomic.Bool
+	g.Go(ctx, "job", func() { ran.Store(true) })
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	if ran.Load() || g.Ready() {
+		t.Fatal("expected the job to be dropped after shutdown")
+	}
+}
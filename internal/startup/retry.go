@@ -0,0 +1,45 @@
+// Package startup orders process startup around backing services such as
+// Postgres and Redis: connections are retried with backoff, and background
+// jobs are held back until every dependency answers.
+package startup
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Backoff bounds between attempts, and the timeout of a single attempt.
+const (
+	minBackoff     = 500 * time.Millisecond
+	maxBackoff     = 15 * time.Second
+	attemptTimeout = 5 * time.Second
+)
+
+// Retry calls fn until it succeeds or ctx ends, waiting between attempts
+// with a backoff that doubles from half a second up to 15 seconds. It returns
+// nil on success and the last error otherwise.
+func Retry(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	wait := minBackoff
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, attemptTimeout)
+		err := fn(attemptCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("%s available after %d attempts", name, attempt)
+			}
+			return nil
+		}
+		log.Printf("%s unavailable (attempt %d): %v; retrying in %s", name, attempt, err, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait = min(wait*2, maxBackoff)
+	}
+}
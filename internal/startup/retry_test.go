@@ -0,0 +1,32 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), "postgres", func(ctx context.Context) error {
+		calls++
+		if calls < 2 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("expected success on the second attempt, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestRetryStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	down := errors.New("connection refused")
+	err := Retry(ctx, "redis", func(ctx context.Context) error { return down })
+	if !errors.Is(err, down) {
+		t.Fatalf("expected the last attempt's error, got %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+// Package sqldialect smooths over the SQL differences between Postgres and
+// SQLite so repositories can share query text: placeholder style, the
+// current-time expression, and how timestamps are stored and read back.
+package sqldialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLiteTimeLayout is fixed width so stored timestamps sort and compare
+// correctly as text. It matches SQLite's strftime('%Y-%m-%dT%H:%M:%fZ').
+const SQLiteTimeLayout = "2006-01-02T15:04:05.000Z"
+
+type Dialect struct {
+	name     string
+	numbered bool
+	now      string
+}
+
+var (
+	Postgres = Dialect{name: "postgres", numbered: true, now: "NOW()"}
+	SQLite   = Dialect{name: "sqlite", now: "strftime('%Y-%m-%dT%H:%M:%fZ', 'now')"}
+)
+
+func (d Dialect) Name() string {
+	return d.name
+}
+
+// Rebind rewrites ? placeholders into the dialect's style. Question marks
+// inside single-quoted literals are left alone.
+func (d Dialect) Rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var sb strings.Builder
+	sb.Grow(len(query) + 8)
+	n := 0
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '?' && !inQuote:
+			n++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Now is the SQL expression for the current timestamp.
+func (d Dialect) Now() string {
+	return d.now
+}
+
+// Time converts t into the value stored for a timestamp column.
+func (d Dialect) Time(t time.Time) any {
+	if d.numbered {
+		return t.UTC()
+	}
+	return t.UTC().Format(SQLiteTimeLayout)
+}
+
+// NullableTime is Time for optional columns: nil and zero times become NULL.
+func (d Dialect) NullableTime(t *time.Time) any {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return d.Time(*t)
+}
+
+// Timestamp scans a timestamp column from either dialect into Time, which is
+// always UTC. Valid is false for NULL.
+type Timestamp struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (ts *Timestamp) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		ts.Time, ts.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		ts.Time, ts.Valid = v.UTC(), true
+		return nil
+	case string:
+		return ts.parse(v)
+	case []byte:
+		return ts.parse(string(v))
+	default:
+		return fmt.Errorf("sqldialect: cannot scan %T into Timestamp", src)
+	}
+}
+
+// Ptr returns a copy of the time, or nil when the column was NULL.
+func (ts Timestamp) Ptr() *time.Time {
+	if !ts.Valid {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+func (ts *Timestamp) parse(s string) error {
+	for _, layout := range []string{SQLiteTimeLayout, time.RFC3339Nano, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			ts.Time, ts.Valid = t.UTC(), true
+			return nil
+		}
+	}
+	return fmt.Errorf("sqldialect: invalid timestamp %q", s)
+}
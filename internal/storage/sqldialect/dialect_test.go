@@ -0,0 +1,64 @@
+package sqldialect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	query := `UPDATE t SET a = ?, note = 'why?' WHERE id = ? AND b = ?`
+
+	if got := SQLite.Rebind(query); got != query {
+		t.Fatalf("sqlite should keep ? placeholders, got %q", got)
+	}
+	want := `UPDATE t SET a = $1, note = 'why?' WHERE id = $2 AND b = $3`
+	if got := Postgres.Rebind(query); got != want {
+		t.Fatalf("unexpected postgres query:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestTimeEncodingSortsAsText(t *testing.T) {
+	early := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	late := early.Add(1500 * time.Millisecond)
+
+	a, b := SQLite.Time(early).(string), SQLite.Time(late).(string)
+	if len(a) != len(b) || a >= b {
+		t.Fatalf("expected fixed-width ascending encodings, got %q and %q", a, b)
+	}
+	if a != "2026-01-02T03:04:05.000Z" {
+		t.Fatalf("unexpected encoding %q", a)
+	}
+	if got := Postgres.Time(early.In(time.FixedZone("x", 3600))); got != early {
+		t.Fatalf("expected postgres to pass UTC time through, got %v", got)
+	}
+	if SQLite.NullableTime(nil) != nil || SQLite.NullableTime(&time.Time{}) != nil {
+		t.Fatal("expected nil and zero times to encode as NULL")
+	}
+}
+
+func TestTimestampScan(t *testing.T) {
+	want := time.Date(2026, 1, 2, 3, 4, 5, 123000000, time.UTC)
+	sources := []any{
+		"2026-01-02T03:04:05.123Z",
+		[]byte("2026-01-02T03:04:05.123Z"),
+		"2026-01-02T04:04:05.123+01:00",
+		want.In(time.FixedZone("x", -7200)),
+	}
+	for _, src := range sources {
+		var ts Timestamp
+		if err := ts.Scan(src); err != nil {
+			t.Fatalf("scan %v: %v", src, err)
+		}
+		if !ts.Valid || !ts.Time.Equal(want) || ts.Time.Location() != time.UTC {
+			t.Fatalf("scan %v: got %v valid=%v", src, ts.Time, ts.Valid)
+		}
+	}
+
+	var ts Timestamp
+	if err := ts.Scan(nil); err != nil || ts.Valid || ts.Ptr() != nil {
+		t.Fatalf("expected NULL to scan as invalid, got %+v err=%v", ts, err)
+	}
+	if err := ts.Scan("yesterday"); err == nil {
+		t.Fatal("expected error for unparseable timestamp")
+	}
+}
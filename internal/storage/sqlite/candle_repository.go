@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"bug-free-umbrella/internal/storage/sqldialect"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type CandleRepository struct {
+	db     *sql.DB
+	tracer trace.Tracer
+}
+
+func NewCandleRepository(db *sql.DB, tracer trace.Tracer) *CandleRepository {
+	return &CandleRepository{db: db, tracer: tracer}
+}
+
+func (r *CandleRepository) UpsertCandles(ctx context.Context, candles []*domain.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	_, span := r.tracer.Start(ctx, "sqlite-candle-repo.upsert-candles")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO candles (symbol, interval, open_time, open, high, low, close, volume)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+    open = excluded.open,
+    high = excluded.high,
+    low = excluded.low,
+    close = excluded.close,
+    volume = excluded.volume`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		if _, err := stmt.ExecContext(ctx, c.Symbol, c.Interval, dialect.Time(c.OpenTime), c.Open, c.High, c.Low, c.Close, c.Volume); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// IngestCandles upserts externally supplied candles and reports how many
+// rows were inserted or changed. Rows identical to what is already stored
+// are left untouched.
+func (r *CandleRepository) IngestCandles(ctx context.Context, candles []*domain.Candle) (int, error) {
+	if len(candles) == 0 {
+		return 0, nil
+	}
+
+	_, span := r.tracer.Start(ctx, "sqlite-candle-repo.ingest-candles")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO candles (symbol, interval, open_time, open, high, low, close, volume)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+    open = excluded.open,
+    high = excluded.high,
+    low = excluded.low,
+    close = excluded.close,
+    volume = excluded.volume
+WHERE candles.open IS NOT excluded.open
+   OR candles.high IS NOT excluded.high
+   OR candles.low IS NOT excluded.low
+   OR candles.close IS NOT excluded.close
+   OR candles.volume IS NOT excluded.volume`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	stored := 0
+	for _, c := range candles {
+		res, err := stmt.ExecContext(ctx, c.Symbol, c.Interval, dialect.Time(c.OpenTime), c.Open, c.High, c.Low, c.Close, c.Volume)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		stored += int(n)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return stored, nil
+}
+
+func (r *CandleRepository) HasSymbol(ctx context.Context, symbol string) (bool, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-candle-repo.has-symbol")
+	defer span.End()
+
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM candles WHERE symbol = ?)`, symbol).Scan(&exists)
+	return exists, err
+}
+
+func (r *CandleRepository) ListSymbols(ctx context.Context) ([]string, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-candle-repo.list-symbols")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT symbol FROM candles ORDER BY symbol`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
+func (r *CandleRepository) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-candle-repo.get-candles")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT symbol, interval, open_time, open, high, low, close, volume
+FROM candles
+WHERE symbol = ? AND interval = ?
+ORDER BY open_time DESC
+LIMIT ?`, symbol, interval, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCandles(rows)
+}
+
+func (r *CandleRepository) GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-candle-repo.get-candles-in-range")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT symbol, interval, open_time, open, high, low, close, volume
+FROM candles
+WHERE symbol = ? AND interval = ? AND open_time >= ? AND open_time <= ?
+ORDER BY open_time DESC`, symbol, interval, dialect.Time(from), dialect.Time(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCandles(rows)
+}
+
+func scanCandles(rows *sql.Rows) ([]*domain.Candle, error) {
+	var candles []*domain.Candle
+	for rows.Next() {
+		c := &domain.Candle{}
+		var openTime sqldialect.Timestamp
+		if err := rows.Scan(&c.Symbol, &c.Interval, &openTime, &c.Open, &c.High, &c.Low, &c.Close, &c.Volume); err != nil {
+			return nil, err
+		}
+		c.OpenTime = openTime.Time
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
@@ -0,0 +1,222 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"bug-free-umbrella/internal/storage/sqldialect"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const featureColumns = `symbol, interval, open_time,
+       ret_1h, ret_4h, ret_12h, ret_24h,
+       volatility_6h, volatility_24h, volume_z_24h,
+       rsi_14, macd_line, macd_signal, macd_hist,
+       bb_pos, bb_width, book_imbalance, book_spread_bps, funding_rate, oi_change_24h, btc_dominance, mcap_change_24h, event_proximity, target_up_4h, created_at, updated_at`
+
+type FeatureRepository struct {
+	db     *sql.DB
+	tracer trace.Tracer
+}
+
+func NewFeatureRepository(db *sql.DB, tracer trace.Tracer) *FeatureRepository {
+	return &FeatureRepository{db: db, tracer: tracer}
+}
+
+func (r *FeatureRepository) UpsertRows(ctx context.Context, rows []domain.MLFeatureRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	_, span := r.tracer.Start(ctx, "sqlite-ml-feature-repo.upsert")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO ml_feature_rows (
+    symbol, interval, open_time,
+    ret_1h, ret_4h, ret_12h, ret_24h,
+    volatility_6h, volatility_24h, volume_z_24h,
+    rsi_14, macd_line, macd_signal, macd_hist,
+    bb_pos, bb_width, book_imbalance, book_spread_bps, funding_rate, oi_change_24h, btc_dominance, mcap_change_24h, event_proximity, target_up_4h, updated_at
+) VALUES (
+    ?, ?, ?,
+    ?, ?, ?, ?,
+    ?, ?, ?,
+    ?, ?, ?, ?,
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+dialect.Now()+`
+)
+ON CONFLICT (symbol, interval, open_time) DO UPDATE SET
+    ret_1h = excluded.ret_1h,
+    ret_4h = excluded.ret_4h,
+    ret_12h = excluded.ret_12h,
+    ret_24h = excluded.ret_24h,
+    volatility_6h = excluded.volatility_6h,
+    volatility_24h = excluded.volatility_24h,
+    volume_z_24h = excluded.volume_z_24h,
+    rsi_14 = excluded.rsi_14,
+    macd_line = excluded.macd_line,
+    macd_signal = excluded.macd_signal,
+    macd_hist = excluded.macd_hist,
+    bb_pos = excluded.bb_pos,
+    bb_width = excluded.bb_width,
+    book_imbalance = excluded.book_imbalance,
+    book_spread_bps = excluded.book_spread_bps,
+    funding_rate = excluded.funding_rate,
+    oi_change_24h = excluded.oi_change_24h,
+    btc_dominance = excluded.btc_dominance,
+    mcap_change_24h = excluded.mcap_change_24h,
+    event_proximity = excluded.event_proximity,
+    target_up_4h = excluded.target_up_4h,
+    updated_at = `+dialect.Now())
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx,
+			row.Symbol,
+			row.Interval,
+			dialect.Time(row.OpenTime),
+			row.Ret1H,
+			row.Ret4H,
+			row.Ret12H,
+			row.Ret24H,
+			row.Volatility6H,
+			row.Volatility24H,
+			row.VolumeZ24H,
+			row.RSI14,
+			row.MACDLine,
+			row.MACDSignal,
+			row.MACDHist,
+			row.BBPos,
+			row.BBWidth,
+			row.BookImbalance,
+			row.BookSpreadBps,
+			row.FundingRate,
+			row.OIChange24H,
+			row.BTCDominance,
+			row.MarketCapChange24H,
+			row.EventProximity,
+			row.TargetUp4H,
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *FeatureRepository) ListLabeledRows(ctx context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-feature-repo.list-labeled")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+featureColumns+`
+FROM ml_feature_rows
+WHERE interval = ?
+  AND open_time >= ?
+  AND open_time <= ?
+  AND target_up_4h IS NOT NULL
+ORDER BY open_time ASC`, interval, dialect.Time(from), dialect.Time(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFeatureRows(rows)
+}
+
+func (r *FeatureRepository) ListRows(ctx context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-feature-repo.list")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+featureColumns+`
+FROM ml_feature_rows
+WHERE interval = ?
+  AND open_time >= ?
+  AND open_time <= ?
+ORDER BY open_time ASC`, interval, dialect.Time(from), dialect.Time(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFeatureRows(rows)
+}
+
+// ListLatestByInterval returns the newest row per symbol. SQLite has no
+// DISTINCT ON, so the latest open_time per symbol is joined back instead.
+func (r *FeatureRepository) ListLatestByInterval(ctx context.Context, interval string) ([]domain.MLFeatureRow, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-feature-repo.list-latest")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+featureColumns+`
+FROM ml_feature_rows
+JOIN (
+    SELECT symbol AS latest_symbol, MAX(open_time) AS latest_open_time
+    FROM ml_feature_rows
+    WHERE interval = ?
+    GROUP BY symbol
+) ON symbol = latest_symbol AND open_time = latest_open_time
+WHERE interval = ?
+ORDER BY symbol`, interval, interval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanFeatureRows(rows)
+}
+
+func scanFeatureRows(rows *sql.Rows) ([]domain.MLFeatureRow, error) {
+	result := make([]domain.MLFeatureRow, 0)
+	for rows.Next() {
+		var row domain.MLFeatureRow
+		var openTime, createdAt, updatedAt sqldialect.Timestamp
+		if err := rows.Scan(
+			&row.Symbol,
+			&row.Interval,
+			&openTime,
+			&row.Ret1H,
+			&row.Ret4H,
+			&row.Ret12H,
+			&row.Ret24H,
+			&row.Volatility6H,
+			&row.Volatility24H,
+			&row.VolumeZ24H,
+			&row.RSI14,
+			&row.MACDLine,
+			&row.MACDSignal,
+			&row.MACDHist,
+			&row.BBPos,
+			&row.BBWidth,
+			&row.BookImbalance,
+			&row.BookSpreadBps,
+			&row.FundingRate,
+			&row.OIChange24H,
+			&row.BTCDominance,
+			&row.MarketCapChange24H,
+			&row.EventProximity,
+			&row.TargetUp4H,
+			&createdAt,
+			&updatedAt,
+		); err != nil {
+			return nil, err
+		}
+		row.OpenTime = openTime.Time
+		row.CreatedAt = createdAt.Time
+		row.UpdatedAt = updatedAt.Time
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
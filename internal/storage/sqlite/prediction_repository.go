@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"bug-free-umbrella/internal/storage/sqldialect"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const predictionColumns = `id, symbol, interval, open_time, target_time,
+       model_key, model_version,
+       prob_up, confidence, direction, risk,
+       signal_id, details_json,
+       created_at, resolved_at, actual_up, is_correct, realized_return`
+
+type PredictionRepository struct {
+	db     *sql.DB
+	tracer trace.Tracer
+}
+
+func NewPredictionRepository(db *sql.DB, tracer trace.Tracer) *PredictionRepository {
+	return &PredictionRepository{db: db, tracer: tracer}
+}
+
+func (r *PredictionRepository) UpsertPrediction(ctx context.Context, prediction domain.MLPrediction) (*domain.MLPrediction, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-predictions.upsert")
+	defer span.End()
+
+	details := prediction.DetailsJSON
+	if details == "" {
+		details = "{}"
+	}
+	if !json.Valid([]byte(details)) {
+		details = `{"raw":"invalid"}`
+	}
+
+	row := r.db.QueryRowContext(ctx, `
+INSERT INTO ml_predictions (
+    symbol, interval, open_time, target_time,
+    model_key, model_version,
+    prob_up, confidence, direction, risk,
+    signal_id, details_json
+) VALUES (
+    ?, ?, ?, ?,
+    ?, ?,
+    ?, ?, ?, ?,
+    ?, ?
+)
+ON CONFLICT (symbol, interval, open_time, model_key, model_version) DO UPDATE SET
+    prob_up = excluded.prob_up,
+    confidence = excluded.confidence,
+    direction = excluded.direction,
+    risk = excluded.risk,
+    details_json = excluded.details_json,
+    target_time = excluded.target_time
+RETURNING `+predictionColumns,
+		prediction.Symbol,
+		prediction.Interval,
+		dialect.Time(prediction.OpenTime),
+		dialect.Time(prediction.TargetTime),
+		prediction.ModelKey,
+		prediction.ModelVersion,
+		prediction.ProbUp,
+		prediction.Confidence,
+		string(prediction.Direction),
+		int16(prediction.Risk),
+		prediction.SignalID,
+		details,
+	)
+	return scanPrediction(row)
+}
+
+func (r *PredictionRepository) AttachSignalID(ctx context.Context, predictionID, signalID int64) error {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-predictions.attach-signal")
+	defer span.End()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE ml_predictions SET signal_id = ? WHERE id = ?`, signalID, predictionID)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(res)
+}
+
+func (r *PredictionRepository) ListUnresolvedDue(ctx context.Context, cutoff time.Time, limit int) ([]domain.MLPrediction, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-predictions.list-unresolved-due")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = 200
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+predictionColumns+`
+FROM ml_predictions
+WHERE resolved_at IS NULL
+  AND target_time <= ?
+ORDER BY target_time ASC
+LIMIT ?`, dialect.Time(cutoff), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPredictions(rows, limit)
+}
+
+func (r *PredictionRepository) ListLatestBySymbol(ctx context.Context, symbol string, limit int) ([]domain.MLPrediction, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-predictions.list-latest-by-symbol")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+predictionColumns+`
+FROM ml_predictions
+WHERE symbol = ?
+ORDER BY open_time DESC, interval ASC, model_key ASC
+LIMIT ?`, symbol, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPredictions(rows, limit)
+}
+
+func (r *PredictionRepository) ResolvePrediction(ctx context.Context, predictionID int64, actualUp bool, isCorrect bool, realizedReturn float64) error {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-predictions.resolve")
+	defer span.End()
+
+	res, err := r.db.ExecContext(ctx, `
+UPDATE ml_predictions
+SET resolved_at = `+dialect.Now()+`,
+    actual_up = ?,
+    is_correct = ?,
+    realized_return = ?
+WHERE id = ?
+  AND resolved_at IS NULL`, actualUp, isCorrect, realizedReturn, predictionID)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(res)
+}
+
+func scanPredictions(rows *sql.Rows, capacity int) ([]domain.MLPrediction, error) {
+	out := make([]domain.MLPrediction, 0, capacity)
+	for rows.Next() {
+		p, err := scanPrediction(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPrediction(s scanner) (*domain.MLPrediction, error) {
+	var out domain.MLPrediction
+	var direction string
+	var risk int16
+	var openTime, targetTime, createdAt, resolvedAt sqldialect.Timestamp
+
+	if err := s.Scan(
+		&out.ID,
+		&out.Symbol,
+		&out.Interval,
+		&openTime,
+		&targetTime,
+		&out.ModelKey,
+		&out.ModelVersion,
+		&out.ProbUp,
+		&out.Confidence,
+		&direction,
+		&risk,
+		&out.SignalID,
+		&out.DetailsJSON,
+		&createdAt,
+		&resolvedAt,
+		&out.ActualUp,
+		&out.IsCorrect,
+		&out.RealizedReturn,
+	); err != nil {
+		return nil, err
+	}
+	out.Direction = domain.SignalDirection(direction)
+	out.Risk = domain.RiskLevel(risk)
+	out.OpenTime = openTime.Time
+	out.TargetTime = targetTime.Time
+	out.CreatedAt = createdAt.Time
+	out.ResolvedAt = resolvedAt.Ptr()
+	return &out, nil
+}
+
+// requireRowsAffected maps an UPDATE that matched nothing to sql.ErrNoRows,
+// as the Postgres repositories do with pgx.ErrNoRows.
+func requireRowsAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"bug-free-umbrella/internal/storage/sqldialect"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const modelColumns = `id, model_key, version, feature_spec_version,
+       trained_from, trained_to, trained_at,
+       hyperparams_json, metrics_json,
+       artifact_format, artifact_blob,
+       is_active, activated_at, created_at`
+
+type RegistryRepository struct {
+	db     *sql.DB
+	tracer trace.Tracer
+}
+
+func NewRegistryRepository(db *sql.DB, tracer trace.Tracer) *RegistryRepository {
+	return &RegistryRepository{db: db, tracer: tracer}
+}
+
+func (r *RegistryRepository) NextVersion(ctx context.Context, modelKey string) (int, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.next-version")
+	defer span.End()
+
+	var version int
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) + 1 FROM ml_model_versions WHERE model_key = ?`, modelKey).Scan(&version)
+	return version, err
+}
+
+func (r *RegistryRepository) InsertModelVersion(ctx context.Context, model domain.MLModelVersion) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.insert")
+	defer span.End()
+
+	if model.ModelKey == "" || model.Version <= 0 {
+		return nil, errors.New("invalid model version payload")
+	}
+	row := r.db.QueryRowContext(ctx, `
+INSERT INTO ml_model_versions (
+    model_key, version, feature_spec_version,
+    trained_from, trained_to, trained_at,
+    hyperparams_json, metrics_json,
+    artifact_format, artifact_blob,
+    is_active, activated_at
+) VALUES (
+    ?, ?, ?,
+    ?, ?, COALESCE(?, `+dialect.Now()+`),
+    ?, ?,
+    ?, ?,
+    ?, ?
+)
+RETURNING `+modelColumns,
+		model.ModelKey,
+		model.Version,
+		model.FeatureSpecVersion,
+		dialect.Time(model.TrainedFrom),
+		dialect.Time(model.TrainedTo),
+		dialect.NullableTime(&model.TrainedAt),
+		fallbackJSON(model.HyperparamsJSON),
+		fallbackJSON(model.MetricsJSON),
+		model.ArtifactFormat,
+		model.ArtifactBlob,
+		model.IsActive,
+		dialect.NullableTime(model.ActivatedAt),
+	)
+	return scanModel(row)
+}
+
+func (r *RegistryRepository) GetActiveModel(ctx context.Context, modelKey string) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.get-active")
+	defer span.End()
+
+	return r.getOne(ctx, `
+SELECT `+modelColumns+`
+FROM ml_model_versions
+WHERE model_key = ? AND is_active = 1
+ORDER BY version DESC
+LIMIT 1`, modelKey)
+}
+
+func (r *RegistryRepository) GetLatestModel(ctx context.Context, modelKey string) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.get-latest")
+	defer span.End()
+
+	return r.getOne(ctx, `
+SELECT `+modelColumns+`
+FROM ml_model_versions
+WHERE model_key = ?
+ORDER BY version DESC
+LIMIT 1`, modelKey)
+}
+
+func (r *RegistryRepository) GetModelVersion(ctx context.Context, modelKey string, version int) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.get-version")
+	defer span.End()
+
+	return r.getOne(ctx, `
+SELECT `+modelColumns+`
+FROM ml_model_versions
+WHERE model_key = ? AND version = ?`, modelKey, version)
+}
+
+func (r *RegistryRepository) GetModelActiveAt(ctx context.Context, modelKey string, at time.Time) (*domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.get-active-at")
+	defer span.End()
+
+	return r.getOne(ctx, `
+SELECT `+modelColumns+`
+FROM ml_model_versions
+WHERE model_key = ?
+  AND version = (
+      SELECT version FROM ml_model_promotions
+      WHERE model_key = ? AND promoted_at <= ?
+      ORDER BY promoted_at DESC, id DESC
+      LIMIT 1)`, modelKey, modelKey, dialect.Time(at))
+}
+
+func (r *RegistryRepository) ListPromotedModels(ctx context.Context, modelKey string, limit int) ([]domain.MLModelVersion, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.list-promoted")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT `+modelColumns+`
+FROM ml_model_versions
+WHERE model_key = ?
+  AND version IN (
+      SELECT version FROM ml_model_promotions
+      WHERE model_key = ?
+      GROUP BY version
+      ORDER BY MAX(promoted_at) DESC, MAX(id) DESC
+      LIMIT ?)
+ORDER BY version DESC`, modelKey, modelKey, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.MLModelVersion
+	for rows.Next() {
+		model, err := scanModel(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *model)
+	}
+	return out, rows.Err()
+}
+
+func (r *RegistryRepository) ActivateModel(ctx context.Context, modelKey string, version int) error {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.activate")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE ml_model_versions SET is_active = 0, activated_at = NULL WHERE model_key = ?`, modelKey); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `UPDATE ml_model_versions SET is_active = 1, activated_at = `+dialect.Now()+` WHERE model_key = ? AND version = ?`, modelKey, version)
+	if err != nil {
+		return err
+	}
+	if err := requireRowsAffected(res); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO ml_model_promotions (model_key, version, promoted_at) VALUES (?, ?, `+dialect.Now()+`)`, modelKey, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListModelEvents returns training runs and promotions at or after since,
+// oldest first.
+func (r *RegistryRepository) ListModelEvents(ctx context.Context, since time.Time) ([]domain.ModelEvent, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-ml-model-registry.list-events")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, `
+SELECT 'trained', model_key, version, trained_at, trained_from, trained_to
+FROM ml_model_versions
+WHERE trained_at >= ?
+UNION ALL
+SELECT 'promoted', p.model_key, p.version, p.promoted_at, v.trained_from, v.trained_to
+FROM ml_model_promotions p
+JOIN ml_model_versions v ON v.model_key = p.model_key AND v.version = p.version
+WHERE p.promoted_at >= ?
+ORDER BY 4 ASC`, dialect.Time(since), dialect.Time(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ModelEvent
+	for rows.Next() {
+		var e domain.ModelEvent
+		var at, from, to sqldialect.Timestamp
+		if err := rows.Scan(&e.Kind, &e.ModelKey, &e.Version, &at, &from, &to); err != nil {
+			return nil, err
+		}
+		e.At, e.TrainedFrom, e.TrainedTo = at.Time, from.Time, to.Time
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func (r *RegistryRepository) getOne(ctx context.Context, query string, args ...any) (*domain.MLModelVersion, error) {
+	out, err := scanModel(r.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return out, err
+}
+
+func scanModel(s scanner) (*domain.MLModelVersion, error) {
+	var out domain.MLModelVersion
+	var trainedFrom, trainedTo, trainedAt, activatedAt, createdAt sqldialect.Timestamp
+	if err := s.Scan(
+		&out.ID,
+		&out.ModelKey,
+		&out.Version,
+		&out.FeatureSpecVersion,
+		&trainedFrom,
+		&trainedTo,
+		&trainedAt,
+		&out.HyperparamsJSON,
+		&out.MetricsJSON,
+		&out.ArtifactFormat,
+		&out.ArtifactBlob,
+		&out.IsActive,
+		&activatedAt,
+		&createdAt,
+	); err != nil {
+		return nil, err
+	}
+	out.TrainedFrom = trainedFrom.Time
+	out.TrainedTo = trainedTo.Time
+	out.TrainedAt = trainedAt.Time
+	out.ActivatedAt = activatedAt.Ptr()
+	out.CreatedAt = createdAt.Time
+	return &out, nil
+}
+
+func fallbackJSON(v string) string {
+	if v == "" {
+		return "{}"
+	}
+	return v
+}
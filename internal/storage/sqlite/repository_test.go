@@ -0,0 +1,393 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testTracer() trace.Tracer {
+	return trace.NewNoopTracerProvider().Tracer("test")
+}
+
+func TestOpenIsIdempotent(t *testing.T) {
+	path := t.TempDir() + "/nested/umbrella.db"
+	for i := 0; i < 2; i++ {
+		db, err := Open(context.Background(), path)
+		if err != nil {
+			t.Fatalf("open #%d: %v", i+1, err)
+		}
+		db.Close()
+	}
+}
+
+func TestOpenUpgradesOlderFeatureTable(t *testing.T) {
+	path := t.TempDir() + "/umbrella.db"
+	old, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	if _, err := old.Exec(`CREATE TABLE ml_feature_rows (
+    symbol TEXT NOT NULL, interval TEXT NOT NULL, open_time TEXT NOT NULL,
+    ret_1h REAL NOT NULL, ret_4h REAL NOT NULL, ret_12h REAL NOT NULL, ret_24h REAL NOT NULL,
+    volatility_6h REAL NOT NULL, volatility_24h REAL NOT NULL, volume_z_24h REAL NOT NULL,
+    rsi_14 REAL NOT NULL, macd_line REAL NOT NULL, macd_signal REAL NOT NULL, macd_hist REAL NOT NULL,
+    bb_pos REAL NOT NULL, bb_width REAL NOT NULL, target_up_4h INTEGER,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (symbol, interval, open_time))`); err != nil {
+		t.Fatalf("create old table: %v", err)
+	}
+	old.Close()
+
+	db, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	repo := NewFeatureRepository(db, testTracer())
+	row := domain.MLFeatureRow{Symbol: "BTC", Interval: "1h", OpenTime: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), BookSpreadBps: 1.5, FundingRate: 0.0001, BTCDominance: 0.54, EventProximity: 0.5}
+	if err := repo.UpsertRows(context.Background(), []domain.MLFeatureRow{row}); err != nil {
+		t.Fatalf("upsert into upgraded table: %v", err)
+	}
+}
+
+func TestCandleRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCandleRepository(openTestDB(t), testTracer())
+
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	var candles []*domain.Candle
+	for i := 0; i < 12; i++ {
+		candles = append(candles, &domain.Candle{
+			Symbol: "BTC", Interval: "1h", OpenTime: base.Add(time.Duration(i) * time.Hour),
+			Open: 100, High: 110, Low: 90, Close: 100 + float64(i), Volume: 10,
+		})
+	}
+	if err := repo.UpsertCandles(ctx, candles); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	candles[11].Close = 999
+	if err := repo.UpsertCandles(ctx, candles[11:]); err != nil {
+		t.Fatalf("re-upsert: %v", err)
+	}
+
+	latest, err := repo.GetCandles(ctx, "BTC", "1h", 3)
+	if err != nil {
+		t.Fatalf("get candles: %v", err)
+	}
+	if len(latest) != 3 || latest[0].Close != 999 || !latest[0].OpenTime.Equal(candles[11].OpenTime) {
+		t.Fatalf("unexpected latest candles: %+v", latest)
+	}
+
+	ranged, err := repo.GetCandlesInRange(ctx, "BTC", "1h", base.Add(2*time.Hour), base.Add(5*time.Hour))
+	if err != nil {
+		t.Fatalf("get range: %v", err)
+	}
+	if len(ranged) != 4 || !ranged[3].OpenTime.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("unexpected ranged candles: %d", len(ranged))
+	}
+}
+
+func TestCandleRepositoryIngest(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCandleRepository(openTestDB(t), testTracer())
+
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	candles := []*domain.Candle{
+		{Symbol: "PEPE", Interval: "1h", OpenTime: base, Open: 1, High: 2, Low: 0.5, Close: 1.5, Volume: 10},
+		{Symbol: "PEPE", Interval: "1h", OpenTime: base.Add(time.Hour), Open: 1.5, High: 2, Low: 1, Close: 1.8, Volume: 12},
+	}
+	if stored, err := repo.IngestCandles(ctx, candles); err != nil || stored != 2 {
+		t.Fatalf("ingest: stored=%d err=%v", stored, err)
+	}
+	if stored, err := repo.IngestCandles(ctx, candles); err != nil || stored != 0 {
+		t.Fatalf("re-ingest of identical rows: stored=%d err=%v", stored, err)
+	}
+	candles[1].Close = 1.9
+	if stored, err := repo.IngestCandles(ctx, candles); err != nil || stored != 1 {
+		t.Fatalf("ingest with one change: stored=%d err=%v", stored, err)
+	}
+
+	if ok, err := repo.HasSymbol(ctx, "PEPE"); err != nil || !ok {
+		t.Fatalf("expected PEPE stored, ok=%v err=%v", ok, err)
+	}
+	if ok, err := repo.HasSymbol(ctx, "DOGE"); err != nil || ok {
+		t.Fatalf("expected DOGE missing, ok=%v err=%v", ok, err)
+	}
+	symbols, err := repo.ListSymbols(ctx)
+	if err != nil || len(symbols) != 1 || symbols[0] != "PEPE" {
+		t.Fatalf("unexpected symbols %v err=%v", symbols, err)
+	}
+}
+
+func TestSignalRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewSignalRepository(openTestDB(t), testTracer())
+
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	in := []domain.Signal{
+		{Symbol: "BTC", Interval: "1h", Indicator: "rsi", Direction: domain.DirectionLong, Risk: domain.RiskLevel2, Timestamp: ts, Details: "a"},
+		{Symbol: "ETH", Interval: "1h", Indicator: "macd", Direction: domain.DirectionShort, Risk: domain.RiskLevel3, Timestamp: ts.Add(time.Hour), Details: "b"},
+	}
+	saved, err := repo.InsertSignals(ctx, in)
+	if err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if saved[0].ID == 0 || saved[1].ID == 0 {
+		t.Fatalf("expected ids, got %+v", saved)
+	}
+
+	in[0].Details = "updated"
+	again, err := repo.InsertSignals(ctx, in[:1])
+	if err != nil {
+		t.Fatalf("re-insert: %v", err)
+	}
+	if again[0].ID != saved[0].ID {
+		t.Fatalf("expected upsert to keep id %d, got %d", saved[0].ID, again[0].ID)
+	}
+
+	all, err := repo.ListSignals(ctx, domain.SignalFilter{})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 2 || all[0].Symbol != "ETH" {
+		t.Fatalf("expected newest first, got %+v", all)
+	}
+
+	until, err := repo.ListSignals(ctx, domain.SignalFilter{Until: ts})
+	if err != nil {
+		t.Fatalf("list until: %v", err)
+	}
+	if len(until) != 1 || until[0].Symbol != "BTC" {
+		t.Fatalf("expected only signals at or before %s, got %+v", ts, until)
+	}
+
+	btc, err := repo.ListSignals(ctx, domain.SignalFilter{Symbol: "btc", Indicator: "RSI"})
+	if err != nil {
+		t.Fatalf("list filtered: %v", err)
+	}
+	if len(btc) != 1 || btc[0].Details != "updated" || !btc[0].Timestamp.Equal(ts) || btc[0].Image != nil {
+		t.Fatalf("unexpected filtered signals: %+v", btc)
+	}
+}
+
+func TestFeatureRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFeatureRepository(openTestDB(t), testTracer())
+
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	up := true
+	var rows []domain.MLFeatureRow
+	for _, symbol := range []string{"BTC", "ETH"} {
+		for i := 0; i < 3; i++ {
+			row := domain.MLFeatureRow{Symbol: symbol, Interval: "1h", OpenTime: base.Add(time.Duration(i) * time.Hour), RSI14: float64(i), BookImbalance: 0.25}
+			if i < 2 {
+				row.TargetUp4H = &up
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err := repo.UpsertRows(ctx, rows); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	labeled, err := repo.ListLabeledRows(ctx, "1h", base, base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list labeled: %v", err)
+	}
+	if len(labeled) != 4 || labeled[0].TargetUp4H == nil || !*labeled[0].TargetUp4H {
+		t.Fatalf("unexpected labeled rows: %+v", labeled)
+	}
+
+	all, err := repo.ListRows(ctx, "1h", base, base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list rows: %v", err)
+	}
+	if len(all) != 6 {
+		t.Fatalf("expected 6 rows, got %d", len(all))
+	}
+
+	latest, err := repo.ListLatestByInterval(ctx, "1h")
+	if err != nil {
+		t.Fatalf("list latest: %v", err)
+	}
+	if len(latest) != 2 || latest[0].Symbol != "BTC" || latest[0].RSI14 != 2 || latest[0].BookImbalance != 0.25 || latest[0].TargetUp4H != nil {
+		t.Fatalf("unexpected latest rows: %+v", latest)
+	}
+}
+
+func TestPredictionRepository(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	repo := NewPredictionRepository(db, testTracer())
+
+	open := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	pred := domain.MLPrediction{
+		Symbol: "BTC", Interval: "1h", OpenTime: open, TargetTime: open.Add(4 * time.Hour),
+		ModelKey: "logreg", ModelVersion: 1, ProbUp: 0.7, Confidence: 0.4,
+		Direction: domain.DirectionLong, Risk: domain.RiskLevel2, DetailsJSON: "not json",
+	}
+	saved, err := repo.UpsertPrediction(ctx, pred)
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	if saved.ID == 0 || saved.DetailsJSON != `{"raw":"invalid"}` || saved.SignalID != nil || saved.ResolvedAt != nil {
+		t.Fatalf("unexpected saved prediction: %+v", saved)
+	}
+
+	signals, err := NewSignalRepository(db, testTracer()).InsertSignals(ctx, []domain.Signal{
+		{Symbol: "BTC", Interval: "1h", Indicator: "ml_logreg_up4h", Direction: domain.DirectionLong, Risk: domain.RiskLevel2, Timestamp: open},
+	})
+	if err != nil {
+		t.Fatalf("insert signal: %v", err)
+	}
+	if err := repo.AttachSignalID(ctx, saved.ID, signals[0].ID); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if err := repo.AttachSignalID(ctx, saved.ID+100, signals[0].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for unknown prediction, got %v", err)
+	}
+
+	due, err := repo.ListUnresolvedDue(ctx, open.Add(5*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("list due: %v", err)
+	}
+	if len(due) != 1 || due[0].SignalID == nil || *due[0].SignalID != signals[0].ID {
+		t.Fatalf("unexpected due predictions: %+v", due)
+	}
+	if notDue, _ := repo.ListUnresolvedDue(ctx, open.Add(time.Hour), 10); len(notDue) != 0 {
+		t.Fatalf("expected nothing due yet, got %d", len(notDue))
+	}
+
+	if err := repo.ResolvePrediction(ctx, saved.ID, true, true, 0.02); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if err := repo.ResolvePrediction(ctx, saved.ID, true, true, 0.02); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows resolving twice, got %v", err)
+	}
+
+	latest, err := repo.ListLatestBySymbol(ctx, "BTC", 5)
+	if err != nil {
+		t.Fatalf("list latest: %v", err)
+	}
+	got := latest[0]
+	if got.ResolvedAt == nil || got.ActualUp == nil || !*got.ActualUp || got.IsCorrect == nil || got.RealizedReturn == nil || *got.RealizedReturn != 0.02 {
+		t.Fatalf("expected resolved outcome, got %+v", got)
+	}
+}
+
+func TestRegistryRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewRegistryRepository(openTestDB(t), testTracer())
+
+	if m, err := repo.GetActiveModel(ctx, "logreg"); err != nil || m != nil {
+		t.Fatalf("expected no active model, got %+v err=%v", m, err)
+	}
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for v := 1; v <= 2; v++ {
+		next, err := repo.NextVersion(ctx, "logreg")
+		if err != nil || next != v {
+			t.Fatalf("expected next version %d, got %d err=%v", v, next, err)
+		}
+		saved, err := repo.InsertModelVersion(ctx, domain.MLModelVersion{
+			ModelKey: "logreg", Version: next, FeatureSpecVersion: "v1",
+			TrainedFrom: from, TrainedTo: from.Add(24 * time.Hour),
+			ArtifactFormat: "json", ArtifactBlob: []byte(`{"w":[1]}`),
+		})
+		if err != nil {
+			t.Fatalf("insert v%d: %v", v, err)
+		}
+		if saved.HyperparamsJSON != "{}" || saved.TrainedAt.IsZero() || saved.IsActive || saved.ActivatedAt != nil {
+			t.Fatalf("unexpected saved model: %+v", saved)
+		}
+	}
+
+	if err := repo.ActivateModel(ctx, "logreg", 1); err != nil {
+		t.Fatalf("activate v1: %v", err)
+	}
+	if err := repo.ActivateModel(ctx, "logreg", 2); err != nil {
+		t.Fatalf("activate v2: %v", err)
+	}
+	if err := repo.ActivateModel(ctx, "logreg", 9); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows for unknown version, got %v", err)
+	}
+
+	active, err := repo.GetActiveModel(ctx, "logreg")
+	if err != nil || active == nil {
+		t.Fatalf("get active: %+v err=%v", active, err)
+	}
+	if active.Version != 2 || active.ActivatedAt == nil || string(active.ArtifactBlob) != `{"w":[1]}` || !active.TrainedFrom.Equal(from) {
+		t.Fatalf("unexpected active model: %+v", active)
+	}
+
+	latest, err := repo.GetLatestModel(ctx, "logreg")
+	if err != nil || latest == nil || latest.Version != 2 {
+		t.Fatalf("unexpected latest model: %+v err=%v", latest, err)
+	}
+
+	v1, err := repo.GetModelVersion(ctx, "logreg", 1)
+	if err != nil || v1 == nil || v1.Version != 1 {
+		t.Fatalf("unexpected model version: %+v err=%v", v1, err)
+	}
+	if missing, err := repo.GetModelVersion(ctx, "logreg", 9); err != nil || missing != nil {
+		t.Fatalf("expected nil for a missing version, got %+v err=%v", missing, err)
+	}
+	activeAt, err := repo.GetModelActiveAt(ctx, "logreg", time.Now().Add(time.Minute))
+	if err != nil || activeAt == nil || activeAt.Version != 2 {
+		t.Fatalf("expected v2 active now, got %+v err=%v", activeAt, err)
+	}
+	if before, err := repo.GetModelActiveAt(ctx, "logreg", time.Now().Add(-time.Hour)); err != nil || before != nil {
+		t.Fatalf("expected nothing active before the first promotion, got %+v err=%v", before, err)
+	}
+
+	events, err := repo.ListModelEvents(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	var trained, promoted int
+	for _, e := range events {
+		switch e.Kind {
+		case domain.ModelEventTrained:
+			trained++
+		case domain.ModelEventPromoted:
+			promoted++
+			if !e.TrainedFrom.Equal(from) {
+				t.Fatalf("expected training window on promotion, got %+v", e)
+			}
+		}
+	}
+	if trained != 2 || promoted != 2 {
+		t.Fatalf("expected 2 trained and 2 promoted events, got %+v", events)
+	}
+	if future, err := repo.ListModelEvents(ctx, time.Now().Add(time.Hour)); err != nil || len(future) != 0 {
+		t.Fatalf("expected no events after now, got %+v err=%v", future, err)
+	}
+	if err := repo.ActivateModel(ctx, "logreg", 1); err != nil {
+		t.Fatalf("reactivate v1: %v", err)
+	}
+	promotedModels, err := repo.ListPromotedModels(ctx, "logreg", 5)
+	if err != nil || len(promotedModels) != 2 || promotedModels[0].Version != 2 || promotedModels[1].Version != 1 {
+		t.Fatalf("expected promoted v2 and v1 newest version first, got %+v err=%v", promotedModels, err)
+	}
+	if last, err := repo.ListPromotedModels(ctx, "logreg", 1); err != nil || len(last) != 1 || last[0].Version != 1 {
+		t.Fatalf("expected the most recently promoted v1, got %+v err=%v", last, err)
+	}
+}
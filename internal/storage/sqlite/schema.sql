@@ -0,0 +1,119 @@
+-- SQLite schema for the tables the SQLite backend covers. Mirrors the
+-- Postgres migrations in cmd/migrate/migrations; timestamps are stored as
+-- fixed-width UTC text (see sqldialect.SQLiteTimeLayout).
+
+CREATE TABLE IF NOT EXISTS candles (
+    symbol      TEXT NOT NULL,
+    interval    TEXT NOT NULL,
+    open_time   TEXT NOT NULL,
+    open        REAL NOT NULL,
+    high        REAL NOT NULL,
+    low         REAL NOT NULL,
+    close       REAL NOT NULL,
+    volume      REAL NOT NULL,
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+CREATE TABLE IF NOT EXISTS signals (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol      TEXT    NOT NULL,
+    interval    TEXT    NOT NULL,
+    indicator   TEXT    NOT NULL,
+    direction   TEXT    NOT NULL,
+    risk        INTEGER NOT NULL,
+    timestamp   TEXT    NOT NULL,
+    details     TEXT    NOT NULL DEFAULT '',
+    created_at  TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (symbol, interval, indicator, timestamp, direction)
+);
+
+CREATE INDEX IF NOT EXISTS idx_signals_lookup
+    ON signals (symbol, risk, indicator, timestamp DESC);
+
+CREATE TABLE IF NOT EXISTS ml_feature_rows (
+    symbol         TEXT NOT NULL,
+    interval       TEXT NOT NULL,
+    open_time      TEXT NOT NULL,
+    ret_1h         REAL NOT NULL,
+    ret_4h         REAL NOT NULL,
+    ret_12h        REAL NOT NULL,
+    ret_24h        REAL NOT NULL,
+    volatility_6h  REAL NOT NULL,
+    volatility_24h REAL NOT NULL,
+    volume_z_24h   REAL NOT NULL,
+    rsi_14         REAL NOT NULL,
+    macd_line      REAL NOT NULL,
+    macd_signal    REAL NOT NULL,
+    macd_hist      REAL NOT NULL,
+    bb_pos         REAL NOT NULL,
+    bb_width       REAL NOT NULL,
+    book_imbalance  REAL NOT NULL DEFAULT 0,
+    book_spread_bps REAL NOT NULL DEFAULT 0,
+    funding_rate    REAL NOT NULL DEFAULT 0,
+    oi_change_24h   REAL NOT NULL DEFAULT 0,
+    btc_dominance   REAL NOT NULL DEFAULT 0,
+    mcap_change_24h REAL NOT NULL DEFAULT 0,
+    event_proximity REAL NOT NULL DEFAULT 0,
+    target_up_4h   INTEGER,
+    created_at     TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at     TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (symbol, interval, open_time)
+);
+
+CREATE TABLE IF NOT EXISTS ml_model_versions (
+    id                   INTEGER PRIMARY KEY AUTOINCREMENT,
+    model_key            TEXT    NOT NULL,
+    version              INTEGER NOT NULL,
+    feature_spec_version TEXT    NOT NULL,
+    trained_from         TEXT    NOT NULL,
+    trained_to           TEXT    NOT NULL,
+    trained_at           TEXT    NOT NULL,
+    hyperparams_json     TEXT    NOT NULL DEFAULT '{}',
+    metrics_json         TEXT    NOT NULL DEFAULT '{}',
+    artifact_format      TEXT    NOT NULL,
+    artifact_blob        BLOB    NOT NULL,
+    is_active            INTEGER NOT NULL DEFAULT 0,
+    activated_at         TEXT,
+    created_at           TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (model_key, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ml_model_versions_active_unique
+    ON ml_model_versions (model_key)
+    WHERE is_active = 1;
+
+CREATE TABLE IF NOT EXISTS ml_model_promotions (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    model_key   TEXT    NOT NULL,
+    version     INTEGER NOT NULL,
+    promoted_at TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ml_model_promotions_promoted_at
+    ON ml_model_promotions (promoted_at DESC);
+
+CREATE TABLE IF NOT EXISTS ml_predictions (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    symbol          TEXT    NOT NULL,
+    interval        TEXT    NOT NULL,
+    open_time       TEXT    NOT NULL,
+    target_time     TEXT    NOT NULL,
+    model_key       TEXT    NOT NULL,
+    model_version   INTEGER NOT NULL,
+    prob_up         REAL    NOT NULL,
+    confidence      REAL    NOT NULL,
+    direction       TEXT    NOT NULL,
+    risk            INTEGER NOT NULL,
+    signal_id       INTEGER REFERENCES signals(id) ON DELETE SET NULL,
+    details_json    TEXT    NOT NULL DEFAULT '{}',
+    created_at      TEXT    NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    resolved_at     TEXT,
+    actual_up       INTEGER,
+    is_correct      INTEGER,
+    realized_return REAL,
+    UNIQUE (symbol, interval, open_time, model_key, model_version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ml_predictions_unresolved
+    ON ml_predictions (target_time)
+    WHERE resolved_at IS NULL;
@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"bug-free-umbrella/internal/storage/sqldialect"
+	"bug-free-umbrella/pkg/domain"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SignalRepository stores signals without chart images; ListSignals never
+// sets Signal.Image.
+type SignalRepository struct {
+	db     *sql.DB
+	tracer trace.Tracer
+}
+
+func NewSignalRepository(db *sql.DB, tracer trace.Tracer) *SignalRepository {
+	return &SignalRepository{db: db, tracer: tracer}
+}
+
+func (r *SignalRepository) InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error) {
+	if len(signals) == 0 {
+		return nil, nil
+	}
+
+	_, span := r.tracer.Start(ctx, "sqlite-signal-repo.insert-signals")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO signals (symbol, interval, indicator, direction, risk, timestamp, details)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (symbol, interval, indicator, timestamp, direction) DO UPDATE SET
+    risk = excluded.risk,
+    details = excluded.details
+RETURNING id`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	out := make([]domain.Signal, len(signals))
+	copy(out, signals)
+	for i, s := range signals {
+		if err := stmt.QueryRowContext(ctx,
+			s.Symbol,
+			s.Interval,
+			s.Indicator,
+			string(s.Direction),
+			int16(s.Risk),
+			dialect.Time(s.Timestamp),
+			s.Details,
+		).Scan(&out[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *SignalRepository) ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error) {
+	_, span := r.tracer.Start(ctx, "sqlite-signal-repo.list-signals")
+	defer span.End()
+
+	args := make([]any, 0, 4)
+	var sb strings.Builder
+	sb.WriteString(`SELECT id, symbol, interval, indicator, direction, risk, timestamp, details
+FROM signals
+WHERE 1=1`)
+
+	if filter.Symbol != "" {
+		args = append(args, strings.ToUpper(filter.Symbol))
+		sb.WriteString(" AND symbol = ?")
+	}
+	if filter.Risk != nil {
+		args = append(args, int16(*filter.Risk))
+		sb.WriteString(" AND risk = ?")
+	}
+	if filter.Indicator != "" {
+		args = append(args, strings.ToLower(filter.Indicator))
+		sb.WriteString(" AND indicator = ?")
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, dialect.Time(filter.Until))
+		sb.WriteString(" AND timestamp <= ?")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+	args = append(args, limit)
+	sb.WriteString(" ORDER BY timestamp DESC LIMIT ?")
+
+	rows, err := r.db.QueryContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signals := make([]domain.Signal, 0, limit)
+	for rows.Next() {
+		var s domain.Signal
+		var direction string
+		var risk int16
+		var ts sqldialect.Timestamp
+		if err := rows.Scan(&s.ID, &s.Symbol, &s.Interval, &s.Indicator, &direction, &risk, &ts, &s.Details); err != nil {
+			return nil, err
+		}
+		s.Direction = domain.SignalDirection(direction)
+		s.Risk = domain.RiskLevel(risk)
+		s.Timestamp = ts.Time
+		signals = append(signals, s)
+	}
+	return signals, rows.Err()
+}
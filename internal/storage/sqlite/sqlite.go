@@ -0,0 +1,69 @@
+// Package sqlite implements the storage interfaces on an embedded SQLite
+// database for small or self-hosted deployments without Postgres. It covers
+// candles, signals and the ML feature, prediction and model registry tables;
+// everything else (signal images, backtests, conversations, tenants) still
+// requires Postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bug-free-umbrella/internal/storage/sqldialect"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+var dialect = sqldialect.SQLite
+
+// upgrades add columns introduced after a database file was created; the
+// schema only creates missing tables. SQLite has no ADD COLUMN IF NOT
+// EXISTS, so a duplicate column error means the upgrade already applied.
+var upgrades = []string{
+	`ALTER TABLE ml_feature_rows ADD COLUMN book_imbalance REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE ml_feature_rows ADD COLUMN book_spread_bps REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE ml_feature_rows ADD COLUMN funding_rate REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE ml_feature_rows ADD COLUMN oi_change_24h REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE ml_feature_rows ADD COLUMN btc_dominance REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE ml_feature_rows ADD COLUMN mcap_change_24h REAL NOT NULL DEFAULT 0`,
+	`ALTER TABLE ml_feature_rows ADD COLUMN event_proximity REAL NOT NULL DEFAULT 0`,
+}
+
+// Open opens (creating if needed) the database at path and applies the
+// schema. Use ":memory:" for a throwaway database.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	if path != ":memory:" {
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				return nil, fmt.Errorf("create sqlite dir: %w", err)
+			}
+		}
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY and
+	// keeps ":memory:" databases from being per-connection.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("apply sqlite schema: %w", err)
+	}
+	for _, stmt := range upgrades {
+		if _, err := db.ExecContext(ctx, stmt); err != nil && !strings.Contains(err.Error(), "duplicate column name") {
+			db.Close()
+			return nil, fmt.Errorf("upgrade sqlite schema: %w", err)
+		}
+	}
+	return db, nil
+}
@@ -0,0 +1,58 @@
+// Package storage defines the backend-neutral repository interfaces shared by
+// the Postgres repositories and the SQLite implementations in
+// internal/storage/sqlite.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type CandleStore interface {
+	UpsertCandles(ctx context.Context, candles []*domain.Candle) error
+	GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error)
+	GetCandlesInRange(ctx context.Context, symbol, interval string, from, to time.Time) ([]*domain.Candle, error)
+}
+
+type SignalStore interface {
+	InsertSignals(ctx context.Context, signals []domain.Signal) ([]domain.Signal, error)
+	ListSignals(ctx context.Context, filter domain.SignalFilter) ([]domain.Signal, error)
+}
+
+type FeatureStore interface {
+	UpsertRows(ctx context.Context, rows []domain.MLFeatureRow) error
+	ListLabeledRows(ctx context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error)
+	ListRows(ctx context.Context, interval string, from, to time.Time) ([]domain.MLFeatureRow, error)
+	ListLatestByInterval(ctx context.Context, interval string) ([]domain.MLFeatureRow, error)
+}
+
+type PredictionStore interface {
+	UpsertPrediction(ctx context.Context, prediction domain.MLPrediction) (*domain.MLPrediction, error)
+	AttachSignalID(ctx context.Context, predictionID, signalID int64) error
+	ListUnresolvedDue(ctx context.Context, cutoff time.Time, limit int) ([]domain.MLPrediction, error)
+	ListLatestBySymbol(ctx context.Context, symbol string, limit int) ([]domain.MLPrediction, error)
+	ResolvePrediction(ctx context.Context, predictionID int64, actualUp bool, isCorrect bool, realizedReturn float64) error
+}
+
+type ModelRegistry interface {
+	NextVersion(ctx context.Context, modelKey string) (int, error)
+	InsertModelVersion(ctx context.Context, model domain.MLModelVersion) (*domain.MLModelVersion, error)
+	GetActiveModel(ctx context.Context, modelKey string) (*domain.MLModelVersion, error)
+	GetLatestModel(ctx context.Context, modelKey string) (*domain.MLModelVersion, error)
+	GetModelVersion(ctx context.Context, modelKey string, version int) (*domain.MLModelVersion, error)
+	GetModelActiveAt(ctx context.Context, modelKey string, at time.Time) (*domain.MLModelVersion, error)
+	ListPromotedModels(ctx context.Context, modelKey string, limit int) ([]domain.MLModelVersion, error)
+	ActivateModel(ctx context.Context, modelKey string, version int) error
+}
+
+// IsNotFound reports whether err means the targeted row does not exist, for
+// either backend.
+func IsNotFound(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows)
+}
@@ -0,0 +1,49 @@
+package storage_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"bug-free-umbrella/internal/ml/features"
+	"bug-free-umbrella/internal/ml/predictions"
+	"bug-free-umbrella/internal/ml/registry"
+	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/internal/storage"
+	"bug-free-umbrella/internal/storage/sqlite"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	_ storage.CandleStore     = (*repository.CandleRepository)(nil)
+	_ storage.SignalStore     = (*repository.SignalRepository)(nil)
+	_ storage.FeatureStore    = (*features.Repository)(nil)
+	_ storage.PredictionStore = (*predictions.Repository)(nil)
+	_ storage.ModelRegistry   = (*registry.Repository)(nil)
+
+	_ storage.CandleStore     = (*sqlite.CandleRepository)(nil)
+	_ storage.SignalStore     = (*sqlite.SignalRepository)(nil)
+	_ storage.FeatureStore    = (*sqlite.FeatureRepository)(nil)
+	_ storage.PredictionStore = (*sqlite.PredictionRepository)(nil)
+	_ storage.ModelRegistry   = (*sqlite.RegistryRepository)(nil)
+)
+
+func TestIsNotFound(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{pgx.ErrNoRows, true},
+		{sql.ErrNoRows, true},
+		{fmt.Errorf("resolve: %w", sql.ErrNoRows), true},
+		{errors.New("boom"), false},
+		{nil, false},
+	}
+	for _, tc := range cases {
+		if got := storage.IsNotFound(tc.err); got != tc.want {
+			t.Fatalf("IsNotFound(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
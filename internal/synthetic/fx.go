@@ -0,0 +1,51 @@
+package synthetic
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// typicalFXRates are the units of each quote currency one US dollar buys
+// that synthetic rates wander around.
+var typicalFXRates = map[string]float64{
+	"EUR": 0.92,
+	"GBP": 0.79,
+	"JPY": 150,
+}
+
+// fxDailySwing is the largest fractional move of a synthetic rate away from
+// its typical value.
+const fxDailySwing = 0.02
+
+// FXRates is a drop-in replacement for the Frankfurter rates. Each currency
+// gets one rate per UTC day, within 2% of its typical value, so a seed
+// always quotes the same rate for the same day.
+type FXRates struct {
+	seed int64
+	now  func() time.Time
+}
+
+func NewFXRates(seed int64, now func() time.Time) *FXRates {
+	if now == nil {
+		now = time.Now
+	}
+	return &FXRates{seed: seed, now: now}
+}
+
+func (f *FXRates) FetchUSDRates(ctx context.Context, currencies []string) (*domain.FXRates, error) {
+	day := f.now().UTC().Truncate(24 * time.Hour)
+	rates := make(map[string]float64, len(currencies))
+	for _, code := range currencies {
+		typical, ok := typicalFXRates[code]
+		if !ok {
+			return nil, fmt.Errorf("no synthetic rate for %s", code)
+		}
+		rng := rand.New(rand.NewSource(f.seed ^ symbolSeed(code) ^ day.Unix()))
+		rates[code] = typical * (1 + fxDailySwing*(2*rng.Float64()-1))
+	}
+	return &domain.FXRates{Rates: rates, AsOf: day}, nil
+}
@@ -0,0 +1,41 @@
+package synthetic
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFXRatesAreStablePerDayAndNearTypical(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	fx := NewFXRates(1, func() time.Time { return now })
+	ctx := context.Background()
+
+	first, err := fx.FetchUSDRates(ctx, []string{"EUR", "GBP", "JPY"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for code, typical := range typicalFXRates {
+		if got := first.Rates[code]; math.Abs(got/typical-1) > fxDailySwing {
+			t.Fatalf("%s rate %v strays more than 2%% from %v", code, got, typical)
+		}
+	}
+	if !first.AsOf.Equal(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected the rates dated to the UTC day, got %s", first.AsOf)
+	}
+
+	now = now.Add(6 * time.Hour)
+	again, _ := fx.FetchUSDRates(ctx, []string{"EUR"})
+	if again.Rates["EUR"] != first.Rates["EUR"] {
+		t.Fatalf("expected the same rate within a day, got %v and %v", first.Rates["EUR"], again.Rates["EUR"])
+	}
+	now = now.Add(24 * time.Hour)
+	if next, _ := fx.FetchUSDRates(ctx, []string{"EUR"}); next.Rates["EUR"] == first.Rates["EUR"] {
+		t.Fatal("expected a new rate the next day")
+	}
+
+	if _, err := fx.FetchUSDRates(ctx, []string{"CHF"}); err == nil {
+		t.Fatal("expected an error for a currency without a synthetic rate")
+	}
+}
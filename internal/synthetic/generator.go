@@ -0,0 +1,216 @@
+// Package synthetic generates realistic, reproducible OHLCV series for tests
+// and for running the stack in demo mode without external market data.
+package synthetic
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// Regime is the market behaviour a Walk is currently producing.
+type Regime int
+
+const (
+	RegimeRange Regime = iota
+	RegimeUptrend
+	RegimeDowntrend
+	RegimeSqueeze
+	RegimeBreakout
+)
+
+func (r Regime) String() string {
+	switch r {
+	case RegimeUptrend:
+		return "uptrend"
+	case RegimeDowntrend:
+		return "downtrend"
+	case RegimeSqueeze:
+		return "squeeze"
+	case RegimeBreakout:
+		return "breakout"
+	default:
+		return "range"
+	}
+}
+
+// regimeShape scales the base volatility, drift and volume of a regime.
+type regimeShape struct {
+	volMult    float64
+	driftMult  float64
+	volumeMult float64
+	lenMult    float64
+}
+
+var regimeShapes = map[Regime]regimeShape{
+	RegimeRange:     {volMult: 1.0, driftMult: 0, volumeMult: 1.0, lenMult: 1.0},
+	RegimeUptrend:   {volMult: 1.0, driftMult: 0.25, volumeMult: 1.2, lenMult: 1.0},
+	RegimeDowntrend: {volMult: 1.2, driftMult: -0.25, volumeMult: 1.3, lenMult: 1.0},
+	RegimeSqueeze:   {volMult: 0.25, driftMult: 0, volumeMult: 0.6, lenMult: 0.5},
+	RegimeBreakout:  {volMult: 2.5, driftMult: 0.6, volumeMult: 2.5, lenMult: 0.2},
+}
+
+type Config struct {
+	Symbol   string
+	Interval string
+	// Start is the open time of the first candle.
+	Start      time.Time
+	StartPrice float64
+	BaseVolume float64
+	// Volatility is the per-candle log-return stdev in a ranging market.
+	Volatility float64
+	// SpikeChance is the per-candle probability of a volume spike.
+	SpikeChance float64
+	// MeanRegimeLen is the average number of candles a regime lasts.
+	MeanRegimeLen int
+	Seed          int64
+}
+
+// Walk produces consecutive candles whose behaviour shifts between trends,
+// ranges, Bollinger squeezes and breakouts. The same Config always yields the
+// same series.
+type Walk struct {
+	cfg      Config
+	step     time.Duration
+	rng      *rand.Rand
+	next     time.Time
+	price    float64
+	regime   Regime
+	left     int
+	breakDir float64
+}
+
+func NewWalk(cfg Config) *Walk {
+	if cfg.Interval == "" {
+		cfg.Interval = "1h"
+	}
+	if cfg.StartPrice <= 0 {
+		cfg.StartPrice = 100
+	}
+	if cfg.BaseVolume <= 0 {
+		cfg.BaseVolume = 1000
+	}
+	if cfg.Volatility <= 0 {
+		cfg.Volatility = 0.01
+	}
+	if cfg.SpikeChance < 0 || cfg.SpikeChance > 1 {
+		cfg.SpikeChance = 0.02
+	}
+	if cfg.MeanRegimeLen <= 0 {
+		cfg.MeanRegimeLen = 72
+	}
+	step := domain.IntervalDuration(cfg.Interval)
+	if step == 0 {
+		step = time.Hour
+	}
+	w := &Walk{
+		cfg:   cfg,
+		step:  step,
+		rng:   rand.New(rand.NewSource(cfg.Seed)),
+		next:  cfg.Start.UTC().Truncate(step),
+		price: cfg.StartPrice,
+	}
+	w.enter(RegimeRange)
+	return w
+}
+
+// Regime returns the regime the next candle will be drawn from.
+func (w *Walk) Regime() Regime {
+	return w.regime
+}
+
+// Next returns the following candle in the series.
+func (w *Walk) Next() *domain.Candle {
+	if w.left <= 0 {
+		w.enter(w.pickNext())
+	}
+	w.left--
+
+	shape := regimeShapes[w.regime]
+	vol := w.cfg.Volatility * shape.volMult
+	drift := w.cfg.Volatility * shape.driftMult
+	if w.regime == RegimeBreakout {
+		drift *= w.breakDir
+	}
+
+	ret := drift + vol*w.rng.NormFloat64()
+	open := w.price
+	closePrice := open * math.Exp(ret)
+	wick := vol * 0.5
+	high := math.Max(open, closePrice) * (1 + math.Abs(w.rng.NormFloat64())*wick)
+	low := math.Min(open, closePrice) * (1 - math.Min(0.5, math.Abs(w.rng.NormFloat64())*wick))
+
+	volume := w.cfg.BaseVolume * shape.volumeMult * math.Exp(0.25*w.rng.NormFloat64())
+	volume *= 1 + 0.3*math.Abs(ret)/w.cfg.Volatility
+	if w.rng.Float64() < w.cfg.SpikeChance {
+		volume *= 4 + 4*w.rng.Float64()
+	}
+
+	c := &domain.Candle{
+		Symbol:   w.cfg.Symbol,
+		Interval: w.cfg.Interval,
+		OpenTime: w.next,
+		Open:     open,
+		High:     high,
+		Low:      low,
+		Close:    closePrice,
+		Volume:   volume,
+	}
+	w.price = closePrice
+	w.next = w.next.Add(w.step)
+	return c
+}
+
+func (w *Walk) enter(r Regime) {
+	w.regime = r
+	mean := float64(w.cfg.MeanRegimeLen) * regimeShapes[r].lenMult
+	w.left = 1 + int(w.rng.ExpFloat64()*mean)
+	if r == RegimeBreakout {
+		w.breakDir = 1
+		if w.rng.Intn(2) == 0 {
+			w.breakDir = -1
+		}
+	}
+}
+
+func (w *Walk) pickNext() Regime {
+	// Squeezes resolve into breakouts, like they do on real charts.
+	if w.regime == RegimeSqueeze {
+		return RegimeBreakout
+	}
+	switch n := w.rng.Intn(10); {
+	case n < 3:
+		return RegimeRange
+	case n < 5:
+		return RegimeUptrend
+	case n < 7:
+		return RegimeDowntrend
+	case n < 9:
+		return RegimeSqueeze
+	default:
+		return RegimeBreakout
+	}
+}
+
+// Series returns n consecutive candles for cfg.
+func Series(cfg Config, n int) []*domain.Candle {
+	w := NewWalk(cfg)
+	out := make([]*domain.Candle, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, w.Next())
+	}
+	return out
+}
+
+// SeriesEndingAt returns n candles whose last candle opens at end truncated to
+// the interval. cfg.Start is ignored.
+func SeriesEndingAt(cfg Config, end time.Time, n int) []*domain.Candle {
+	step := domain.IntervalDuration(cfg.Interval)
+	if step == 0 {
+		step = time.Hour
+	}
+	cfg.Start = end.UTC().Truncate(step).Add(-time.Duration(n-1) * step)
+	return Series(cfg, n)
+}
@@ -0,0 +1,67 @@
+package synthetic
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSeriesDeterministic(t *testing.T) {
+	cfg := Config{Symbol: "BTC", Interval: "1h", Start: time.Unix(0, 0), Seed: 42}
+	a := Series(cfg, 500)
+	b := Series(cfg, 500)
+	for i := range a {
+		if *a[i] != *b[i] {
+			t.Fatalf("candle %d differs between runs: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+
+	other := Series(Config{Symbol: "BTC", Interval: "1h", Start: time.Unix(0, 0), Seed: 43}, 500)
+	if a[len(a)-1].Close == other[len(other)-1].Close {
+		t.Fatal("expected different seeds to produce different series")
+	}
+}
+
+func TestSeriesCandlesAreWellFormed(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := Series(Config{Symbol: "ETH", Interval: "4h", Start: start, Seed: 7}, 1000)
+	for i, c := range candles {
+		if want := start.Add(time.Duration(i) * 4 * time.Hour); !c.OpenTime.Equal(want) {
+			t.Fatalf("candle %d open time = %s, want %s", i, c.OpenTime, want)
+		}
+		if c.High < math.Max(c.Open, c.Close) || c.Low > math.Min(c.Open, c.Close) || c.Low <= 0 {
+			t.Fatalf("candle %d has inconsistent OHLC: %+v", i, c)
+		}
+		if c.Volume <= 0 {
+			t.Fatalf("candle %d has non-positive volume", i)
+		}
+		if i > 0 && c.Open != candles[i-1].Close {
+			t.Fatalf("candle %d does not open at previous close", i)
+		}
+	}
+}
+
+func TestWalkVisitsEveryRegime(t *testing.T) {
+	w := NewWalk(Config{Symbol: "SOL", Interval: "5m", MeanRegimeLen: 20, Seed: 1})
+	seen := make(map[Regime]bool)
+	for i := 0; i < 5000; i++ {
+		seen[w.Regime()] = true
+		w.Next()
+	}
+	for _, r := range []Regime{RegimeRange, RegimeUptrend, RegimeDowntrend, RegimeSqueeze, RegimeBreakout} {
+		if !seen[r] {
+			t.Fatalf("regime %s never visited", r)
+		}
+	}
+}
+
+func TestSeriesEndingAt(t *testing.T) {
+	end := time.Date(2026, 3, 1, 12, 34, 0, 0, time.UTC)
+	candles := SeriesEndingAt(Config{Symbol: "BTC", Interval: "1h", Seed: 1}, end, 24)
+	if len(candles) != 24 {
+		t.Fatalf("expected 24 candles, got %d", len(candles))
+	}
+	if want := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC); !candles[23].OpenTime.Equal(want) {
+		t.Fatalf("last open time = %s, want %s", candles[23].OpenTime, want)
+	}
+}
@@ -0,0 +1,194 @@
+package synthetic
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// startPrices keeps demo charts in a believable range per asset.
+var startPrices = map[string]float64{
+	"BTC":   60000,
+	"ETH":   3000,
+	"SOL":   150,
+	"XRP":   0.6,
+	"ADA":   0.45,
+	"DOGE":  0.15,
+	"DOT":   7,
+	"AVAX":  35,
+	"LINK":  15,
+	"MATIC": 0.7,
+}
+
+// Provider is a drop-in replacement for the CoinGecko provider. Every symbol
+// follows its own 5m Walk starting history before construction; coarser
+// intervals are aggregated from it so all intervals agree, and re-fetching a
+// window always returns the same candles.
+type Provider struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	origin time.Time
+	seed   int64
+	series map[string]*baseSeries
+}
+
+type baseSeries struct {
+	walk    *Walk
+	candles []*domain.Candle
+	// pending is the first candle that opens after the last fetch.
+	pending *domain.Candle
+}
+
+func NewProvider(seed int64, history time.Duration, now func() time.Time) *Provider {
+	if now == nil {
+		now = time.Now
+	}
+	if history <= 0 {
+		history = 90 * 24 * time.Hour
+	}
+	return &Provider{
+		now:    now,
+		origin: now().UTC().Add(-history).Truncate(24 * time.Hour),
+		seed:   seed,
+		series: make(map[string]*baseSeries),
+	}
+}
+
+func (p *Provider) FetchPrices(ctx context.Context) (map[string]*domain.PriceSnapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now().UTC()
+	out := make(map[string]*domain.PriceSnapshot, len(domain.SupportedSymbols()))
+	for _, symbol := range domain.SupportedSymbols() {
+		candles := p.extend(symbol, now)
+		if len(candles) == 0 {
+			continue
+		}
+		latest := candles[len(candles)-1]
+		dayAgo := now.Add(-24 * time.Hour)
+
+		var volume24h, prevClose float64
+		for i := len(candles) - 1; i >= 0; i-- {
+			if candles[i].OpenTime.Before(dayAgo) {
+				prevClose = candles[i].Close
+				break
+			}
+			volume24h += candles[i].Volume
+		}
+		change := 0.0
+		if prevClose > 0 {
+			change = (latest.Close/prevClose - 1) * 100
+		}
+		out[symbol] = &domain.PriceSnapshot{
+			Symbol:          symbol,
+			PriceUSD:        latest.Close,
+			Volume24h:       domain.Money(volume24h),
+			Change24hPct:    domain.Percent(change),
+			LastUpdatedUnix: now.Unix(),
+		}
+	}
+	return out, nil
+}
+
+func (p *Provider) FetchMarketChart(ctx context.Context, symbol string, days int, intervals []string) ([]*domain.Candle, error) {
+	if !domain.IsSupportedSymbol(symbol) {
+		return nil, fmt.Errorf("unsupported symbol: %s", symbol)
+	}
+	if days <= 0 {
+		days = 1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now().UTC()
+	from := now.Add(-time.Duration(days) * 24 * time.Hour)
+	base := p.extend(symbol, now)
+
+	var out []*domain.Candle
+	for _, interval := range intervals {
+		out = append(out, aggregate(base, interval, from)...)
+	}
+	return out, nil
+}
+
+// extend grows the 5m series for symbol up to the candle containing now.
+// Callers must hold p.mu.
+func (p *Provider) extend(symbol string, now time.Time) []*domain.Candle {
+	s, ok := p.series[symbol]
+	if !ok {
+		s = &baseSeries{walk: NewWalk(Config{
+			Symbol:        symbol,
+			Interval:      "5m",
+			Start:         p.origin,
+			StartPrice:    startPrices[symbol],
+			Volatility:    0.003,
+			MeanRegimeLen: 288,
+			Seed:          p.seed ^ symbolSeed(symbol),
+		})}
+		p.series[symbol] = s
+	}
+	for {
+		c := s.pending
+		if c == nil {
+			c = s.walk.Next()
+		}
+		if c.OpenTime.After(now) {
+			s.pending = c
+			break
+		}
+		s.pending = nil
+		s.candles = append(s.candles, c)
+	}
+	return s.candles
+}
+
+// aggregate buckets 5m candles from the interval boundary at or before from
+// into interval candles.
+func aggregate(base []*domain.Candle, interval string, from time.Time) []*domain.Candle {
+	step := domain.IntervalDuration(interval)
+	if step == 0 {
+		return nil
+	}
+	// Start on a bucket boundary so the first candle is not a partial one.
+	from = from.Truncate(step)
+	var out []*domain.Candle
+	var cur *domain.Candle
+	for _, c := range base {
+		if c.OpenTime.Before(from) {
+			continue
+		}
+		bucket := c.OpenTime.Truncate(step)
+		if cur == nil || !cur.OpenTime.Equal(bucket) {
+			cur = &domain.Candle{
+				Symbol:   c.Symbol,
+				Interval: interval,
+				OpenTime: bucket,
+				Open:     c.Open,
+				High:     c.High,
+				Low:      c.Low,
+			}
+			out = append(out, cur)
+		}
+		if c.High > cur.High {
+			cur.High = c.High
+		}
+		if c.Low < cur.Low {
+			cur.Low = c.Low
+		}
+		cur.Close = c.Close
+		cur.Volume += c.Volume
+	}
+	return out
+}
+
+func symbolSeed(symbol string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(symbol))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,98 @@
+package synthetic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestProviderFetchPricesCoversSupportedSymbols(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 7, 0, 0, time.UTC)
+	p := NewProvider(1, 3*24*time.Hour, func() time.Time { return now })
+
+	prices, err := p.FetchPrices(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, symbol := range domain.SupportedSymbols() {
+		snap, ok := prices[symbol]
+		if !ok {
+			t.Fatalf("missing price for %s", symbol)
+		}
+		if snap.PriceUSD <= 0 || snap.Volume24h <= 0 {
+			t.Fatalf("unexpected snapshot for %s: %+v", symbol, snap)
+		}
+	}
+}
+
+func TestProviderMarketChartIsStableAcrossFetches(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 7, 0, 0, time.UTC)
+	p := NewProvider(1, 3*24*time.Hour, func() time.Time { return now })
+	ctx := context.Background()
+
+	first, err := p.FetchMarketChart(ctx, "BTC", 1, []string{"1h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	second, err := p.FetchMarketChart(ctx, "BTC", 1, []string{"1h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byOpen := make(map[time.Time]domain.Candle, len(second))
+	for _, c := range second {
+		byOpen[c.OpenTime] = *c
+	}
+	// The last candle of the first fetch was still forming; the rest must match.
+	for _, c := range first[:len(first)-1] {
+		if got, ok := byOpen[c.OpenTime]; ok && got != *c {
+			t.Fatalf("candle %s changed between fetches: %+v vs %+v", c.OpenTime, *c, got)
+		}
+	}
+	if !second[len(second)-1].OpenTime.Equal(time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected latest candle to be the forming 12:00 candle, got %s", second[len(second)-1].OpenTime)
+	}
+}
+
+func TestProviderIntervalsAgree(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProvider(9, 10*24*time.Hour, func() time.Time { return now })
+
+	candles, err := p.FetchMarketChart(context.Background(), "ETH", 2, []string{"1h", "4h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hourly := make(map[time.Time]*domain.Candle)
+	var fourHour []*domain.Candle
+	for _, c := range candles {
+		switch c.Interval {
+		case "1h":
+			hourly[c.OpenTime] = c
+		case "4h":
+			fourHour = append(fourHour, c)
+		}
+	}
+	if len(fourHour) == 0 {
+		t.Fatal("expected 4h candles")
+	}
+	for _, c := range fourHour[:len(fourHour)-1] {
+		first := hourly[c.OpenTime]
+		last := hourly[c.OpenTime.Add(3*time.Hour)]
+		if first == nil || last == nil {
+			t.Fatalf("missing hourly candles for 4h bucket %s", c.OpenTime)
+		}
+		if c.Open != first.Open || c.Close != last.Close {
+			t.Fatalf("4h bucket %s disagrees with hourly candles", c.OpenTime)
+		}
+	}
+}
+
+func TestProviderRejectsUnknownSymbol(t *testing.T) {
+	p := NewProvider(1, time.Hour, nil)
+	if _, err := p.FetchMarketChart(context.Background(), "NOPE", 1, []string{"1h"}); err == nil {
+		t.Fatal("expected error for unsupported symbol")
+	}
+}
@@ -118,3 +118,72 @@ func BollingerSeries(values []float64, period int, stdDevs float64) ([]float64,
 	}
 	return middle, upper, lower
 }
+
+// ATRSeries returns Wilder's average true range. Values before index period
+// are NaN, matching RSISeries.
+func ATRSeries(highs, lows, closes []float64, period int) []float64 {
+	n := len(closes)
+	if period <= 0 || n <= period || len(highs) != n || len(lows) != n {
+		return nil
+	}
+	series := make([]float64, n)
+	for i := range series {
+		series[i] = math.NaN()
+	}
+
+	trueRange := func(i int) float64 {
+		return math.Max(highs[i]-lows[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+	}
+
+	var sum float64
+	for i := 1; i <= period; i++ {
+		sum += trueRange(i)
+	}
+	atr := sum / float64(period)
+	series[period] = atr
+
+	for i := period + 1; i < n; i++ {
+		atr = (atr*float64(period-1) + trueRange(i)) / float64(period)
+		series[i] = atr
+	}
+	return series
+}
+
+// MidpointSeries returns the midpoint of the highest high and lowest low
+// over the period values ending at each index. Values before the first full
+// period are NaN.
+func MidpointSeries(highs, lows []float64, period int) []float64 {
+	out := make([]float64, len(highs))
+	for i := range highs {
+		if i < period-1 || i >= len(lows) {
+			out[i] = math.NaN()
+			continue
+		}
+		high, low := highs[i], lows[i]
+		for j := i - period + 1; j < i; j++ {
+			high = math.Max(high, highs[j])
+			low = math.Min(low, lows[j])
+		}
+		out[i] = (high + low) / 2
+	}
+	return out
+}
+
+// IchimokuSeries returns the Tenkan and Kijun lines and the two Senkou spans
+// at each index. The spans are plotted kijun periods ahead, so the values at
+// index i come from index i-kijun and are NaN before that.
+func IchimokuSeries(highs, lows []float64, tenkan, kijun, senkouB int) (tenkanLine, kijunLine, spanA, spanB []float64) {
+	tenkanLine = MidpointSeries(highs, lows, tenkan)
+	kijunLine = MidpointSeries(highs, lows, kijun)
+	senkouBLine := MidpointSeries(highs, lows, senkouB)
+	spanA = make([]float64, len(highs))
+	spanB = make([]float64, len(highs))
+	for i := range highs {
+		spanA[i], spanB[i] = math.NaN(), math.NaN()
+		if j := i - kijun; j >= 0 {
+			spanA[i] = (tenkanLine[j] + kijunLine[j]) / 2
+			spanB[i] = senkouBLine[j]
+		}
+	}
+	return tenkanLine, kijunLine, spanA, spanB
+}
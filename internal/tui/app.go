@@ -1,6 +1,10 @@
 package tui
 
 import (
+	"fmt"
+
+	"bug-free-umbrella/pkg/domain"
+
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -21,26 +25,55 @@ var tabNames = []string{"1:Dashboard", "2:Chat", "3:Signals", "4:Backtest"}
 // AppModel is the root Bubble Tea model that manages tab navigation and child screens.
 type AppModel struct {
 	services  Services
+	styles    *Styles // shared with every screen
 	activeTab Tab
 	dashboard DashboardModel
 	chat      ChatModel
 	signals   SignalExplorerModel
 	backtest  BacktestModel
+	picker    watchlistPicker
+	watchlist []string
 	width     int
 	height    int
 	quitting  bool
+
+	// watchlistErr is the last failure to load or save the watchlist.
+	watchlistErr error
+
+	// theme is the theme the user picked; empty follows the server default.
+	theme string
+	// Saved session state: pendingState is the latest state handed to a
+	// delayed save, stateSeq numbers those saves and savedSeq is the last
+	// one written.
+	stateLoaded  bool
+	pendingState domain.TUIState
+	stateSeq     int
+	savedSeq     int
+	stateErr     error
+
+	// maintenance is the last read of the operator switch;
+	// maintenanceConfirm is set after the first M press.
+	maintenance        domain.MaintenanceState
+	maintenanceErr     error
+	maintenanceConfirm bool
 }
 
 // NewAppModel creates the root application model with all child screens.
 func NewAppModel(svc Services) AppModel {
-	return AppModel{
+	m := AppModel{
 		services:  svc,
+		styles:    defaultStyles(),
 		activeTab: TabDashboard,
 		dashboard: NewDashboardModel(svc),
 		chat:      NewChatModel(svc),
 		signals:   NewSignalExplorerModel(svc),
 		backtest:  NewBacktestModel(svc),
 	}
+	m.dashboard.styles = m.styles
+	m.chat.styles = m.styles
+	m.signals.styles = m.styles
+	m.backtest.styles = m.styles
+	return m
 }
 
 // Init initializes all child models.
@@ -50,11 +83,21 @@ func (m AppModel) Init() tea.Cmd {
 		m.chat.Init(),
 		m.signals.Init(),
 		m.backtest.Init(),
+		loadWatchlistCmd(m.services),
+		loadStateCmd(m.services),
+		loadMaintenanceCmd(m.services),
+		maintenanceTickCmd(m.services),
 	)
 }
 
-// Update handles incoming messages, routing to the active tab.
+// Update handles incoming messages, routing to the active tab, and saves
+// the session state after changes.
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	m, cmd := m.update(msg)
+	return m, tea.Batch(cmd, m.scheduleStateSave())
+}
+
+func (m AppModel) update(msg tea.Msg) (AppModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -63,6 +106,30 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.picker.open && msg.String() != "ctrl+c" {
+			var symbols []string
+			var saved bool
+			m.picker, symbols, saved = m.picker.Update(msg)
+			if !saved {
+				return m, nil
+			}
+			// Apply at once; the save runs in the background.
+			m.watchlistErr = nil
+			next, cmd := m.update(watchlistMsg(symbols))
+			return next, tea.Batch(cmd, saveWatchlistCmd(m.services, symbols))
+		}
+		if m.maintenanceConfirm {
+			m.maintenanceConfirm = false
+			m.propagateSize()
+			if key.Matches(msg, DefaultKeyMap.Maintenance) {
+				return m, setMaintenanceCmd(m.services, !m.maintenance.Enabled)
+			}
+		}
+		if key.Matches(msg, DefaultKeyMap.Watchlist) && (m.activeTab == TabDashboard || m.activeTab == TabSignals) {
+			m.picker = newWatchlistPicker(m.watchlist)
+			return m, nil
+		}
+
 		// Global key bindings (except in chat when input is focused)
 		if m.activeTab != TabChat || msg.Type == tea.KeyTab || msg.Type == tea.KeyShiftTab ||
 			msg.String() == "ctrl+c" || (msg.String() >= "1" && msg.String() <= "4") {
@@ -74,7 +141,16 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 				m.quitting = true
-				return m, tea.Quit
+				return m, tea.Sequence(m.flushStateCmd(), tea.Quit)
+
+			case key.Matches(msg, DefaultKeyMap.CycleTheme):
+				m.cycleTheme()
+				return m, nil
+
+			case key.Matches(msg, DefaultKeyMap.Maintenance) && m.services.Maintenance != nil && m.services.CanToggleMaintenance:
+				m.maintenanceConfirm = true
+				m.propagateSize()
+				return m, nil
 
 			case key.Matches(msg, DefaultKeyMap.Tab):
 				m.switchTab(Tab((int(m.activeTab) + 1) % len(tabNames)))
@@ -107,8 +183,46 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Route messages to all child models (they filter relevant ones)
 	var cmds []tea.Cmd
 
-	switch msg.(type) {
-	case pricesMsg, pricesErrMsg, signalsMsg, signalsErrMsg, dashTickMsg:
+	switch msg := msg.(type) {
+	case watchlistMsg:
+		m.watchlist = []string(msg)
+		var cmd tea.Cmd
+		m.dashboard, cmd = m.dashboard.Update(msg)
+		cmds = append(cmds, cmd)
+		m.signals, cmd = m.signals.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case watchlistErrMsg:
+		m.watchlistErr = msg.err
+
+	case stateMsg:
+		m.stateErr = nil
+		cmds = append(cmds, m.applyState(domain.TUIState(msg)))
+		m.stateLoaded = true
+		m.pendingState = m.currentState()
+
+	case stateErrMsg:
+		m.stateErr = msg.err
+
+	case maintenanceMsg:
+		m.maintenance = domain.MaintenanceState(msg)
+		m.maintenanceErr = nil
+		m.propagateSize()
+
+	case maintenanceErrMsg:
+		m.maintenanceErr = msg.err
+
+	case maintenanceTickMsg:
+		cmds = append(cmds, loadMaintenanceCmd(m.services), maintenanceTickCmd(m.services))
+
+	case stateSaveMsg:
+		// Only the latest change is written.
+		if msg.seq == m.stateSeq {
+			cmds = append(cmds, m.flushStateCmd())
+		}
+
+	case pricesMsg, pricesErrMsg, signalsMsg, signalsErrMsg, dashTickMsg,
+		marketEventMsg, marketEventsClosedMsg, dashRefreshMsg, heatInputsMsg:
 		var cmd tea.Cmd
 		m.dashboard, cmd = m.dashboard.Update(msg)
 		cmds = append(cmds, cmd)
@@ -118,12 +232,12 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.signals, cmd = m.signals.Update(msg)
 		cmds = append(cmds, cmd)
 
-	case backtestSummaryMsg, backtestDailyMsg, backtestPredictionsMsg, backtestErrMsg:
+	case backtestSummaryMsg, backtestDailyMsg, backtestPredictionsMsg, backtestSeriesMsg, backtestCalibrationMsg, backtestRiskMsg, backtestAnomalyMsg, backtestErrMsg:
 		var cmd tea.Cmd
 		m.backtest, cmd = m.backtest.Update(msg)
 		cmds = append(cmds, cmd)
 
-	case advisorReplyMsg, advisorErrMsg:
+	case advisorReplyMsg, advisorErrMsg, chatHistoryMsg:
 		var cmd tea.Cmd
 		m.chat, cmd = m.chat.Update(msg)
 		cmds = append(cmds, cmd)
@@ -161,6 +275,25 @@ func (m AppModel) View() string {
 
 	tabBar := m.renderTabBar()
 
+	if m.watchlistErr != nil {
+		tabBar = lipgloss.JoinHorizontal(lipgloss.Top, tabBar,
+			m.styles.ErrorStyle.Render(fmt.Sprintf("  watchlist: %v", m.watchlistErr)))
+	}
+	if m.stateErr != nil {
+		tabBar = lipgloss.JoinHorizontal(lipgloss.Top, tabBar,
+			m.styles.ErrorStyle.Render(fmt.Sprintf("  session state: %v", m.stateErr)))
+	}
+	if m.maintenanceErr != nil {
+		tabBar = lipgloss.JoinHorizontal(lipgloss.Top, tabBar,
+			m.styles.ErrorStyle.Render(fmt.Sprintf("  maintenance: %v", m.maintenanceErr)))
+	}
+	if banner := m.maintenanceBanner(); banner != "" {
+		tabBar = lipgloss.JoinVertical(lipgloss.Left, banner, tabBar)
+	}
+	if m.picker.open {
+		return lipgloss.JoinVertical(lipgloss.Left, tabBar, m.picker.View(m.styles))
+	}
+
 	var content string
 	switch m.activeTab {
 	case TabDashboard:
@@ -176,6 +309,32 @@ func (m AppModel) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, tabBar, content)
 }
 
+// maintenanceBanner describes the maintenance switch, or the pending
+// toggle while it awaits confirmation.
+func (m AppModel) maintenanceBanner() string {
+	if m.maintenanceConfirm {
+		action := "on"
+		if m.maintenance.Enabled {
+			action = "off"
+		}
+		return m.styles.WarnStyle.Render(fmt.Sprintf("Press M again to turn maintenance mode %s; any other key cancels", action))
+	}
+	if !m.maintenance.Enabled {
+		return ""
+	}
+	text := "MAINTENANCE: pollers, ML jobs and alerts are paused"
+	if m.maintenance.Reason != "" {
+		text += " (" + m.maintenance.Reason + ")"
+	}
+	if m.maintenance.Actor != "" {
+		text += " by " + m.maintenance.Actor
+	}
+	return m.styles.WarnStyle.Render(text)
+}
+
+// Maintenance returns the last read maintenance state (for testing).
+func (m AppModel) Maintenance() domain.MaintenanceState { return m.maintenance }
+
 // SetSize updates dimensions on the root model and propagates to children.
 func (m *AppModel) SetSize(w, h int) {
 	m.width = w
@@ -186,6 +345,12 @@ func (m *AppModel) SetSize(w, h int) {
 // ActiveTab returns the currently active tab (for testing).
 func (m AppModel) ActiveTab() Tab { return m.activeTab }
 
+// ThemeName returns the active theme (for testing).
+func (m AppModel) ThemeName() string { return m.styles.Theme.Name }
+
+// Watchlist returns the pinned symbols (for testing).
+func (m AppModel) Watchlist() []string { return m.watchlist }
+
 func (m *AppModel) switchTab(tab Tab) {
 	if tab == TabChat && m.activeTab != TabChat {
 		m.chat.Focus()
@@ -197,6 +362,9 @@ func (m *AppModel) switchTab(tab Tab) {
 
 func (m *AppModel) propagateSize() {
 	contentHeight := m.height - 2 // account for tab bar
+	if m.maintenanceBanner() != "" {
+		contentHeight--
+	}
 	m.dashboard.SetSize(m.width, contentHeight)
 	m.chat.SetSize(m.width, contentHeight)
 	m.signals.SetSize(m.width, contentHeight)
@@ -207,9 +375,9 @@ func (m AppModel) renderTabBar() string {
 	var tabs []string
 	for i, name := range tabNames {
 		if Tab(i) == m.activeTab {
-			tabs = append(tabs, ActiveTabStyle.Render(name))
+			tabs = append(tabs, m.styles.ActiveTabStyle.Render(name))
 		} else {
-			tabs = append(tabs, InactiveTabStyle.Render(name))
+			tabs = append(tabs, m.styles.InactiveTabStyle.Render(name))
 		}
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
@@ -2,10 +2,11 @@ package tui
 
 import (
 	"context"
+	"strings"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -43,6 +44,11 @@ type stubBacktestQuerier struct {
 	summary     []repository.DailyAccuracy
 	daily       []repository.DailyAccuracy
 	predictions []domain.MLPrediction
+	series      []repository.AccuracySeries
+	calibration []domain.CalibrationBucket
+	calModel    string
+	risk        []domain.RiskLevelStats
+	anomalies   []domain.AnomalyCell
 	err         error
 }
 
@@ -54,10 +60,42 @@ func (s *stubBacktestQuerier) GetAccuracySummary(ctx context.Context) ([]reposit
 	return s.summary, s.err
 }
 
+func (s *stubBacktestQuerier) GetDailyAccuracySeries(ctx context.Context, days int) ([]repository.AccuracySeries, error) {
+	return s.series, s.err
+}
+
+func (s *stubBacktestQuerier) GetCalibration(ctx context.Context, modelKey string, buckets int) ([]domain.CalibrationBucket, error) {
+	s.calModel = modelKey
+	return s.calibration, s.err
+}
+
+func (s *stubBacktestQuerier) GetRiskLevelStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error) {
+	return s.risk, s.err
+}
+
+func (s *stubBacktestQuerier) GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error) {
+	return s.anomalies, s.err
+}
+
 func (s *stubBacktestQuerier) ListRecentPredictions(ctx context.Context, limit int) ([]domain.MLPrediction, error) {
 	return s.predictions, s.err
 }
 
+type stubWatchlistStore struct {
+	symbols []string
+	saved   []string
+	err     error
+}
+
+func (s *stubWatchlistStore) GetWatchlist(ctx context.Context, userID int64) ([]string, error) {
+	return s.symbols, s.err
+}
+
+func (s *stubWatchlistStore) SetWatchlist(ctx context.Context, userID int64, symbols []string) error {
+	s.saved = symbols
+	return s.err
+}
+
 func testServices() Services {
 	return Services{
 		Prices:   &stubPriceQuerier{},
@@ -159,3 +197,274 @@ func TestServicesChatID(t *testing.T) {
 		t.Fatalf("expected chat ID %d, got %d", expected, svc.ChatID())
 	}
 }
+
+func TestServicesChatIDWithoutUserIsPerSession(t *testing.T) {
+	a := Services{SessionID: 1}
+	b := Services{SessionID: 2}
+	if a.ChatID() == b.ChatID() {
+		t.Fatalf("expected distinct chat IDs, both %d", a.ChatID())
+	}
+	if a.ChatID() == (Services{UserID: 1}).ChatID() {
+		t.Fatal("session chat ID collides with a user chat ID")
+	}
+}
+
+func TestAppModelWatchlistPickerSaves(t *testing.T) {
+	store := &stubWatchlistStore{}
+	svc := testServices()
+	svc.Watchlist = store
+	m := NewAppModel(svc)
+	m.SetSize(120, 40)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	app := updated.(AppModel)
+	if !app.picker.open {
+		t.Fatal("expected w to open the watchlist picker")
+	}
+
+	// Pin BTC (first) and ETH (second), then save.
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeySpace, Runes: []rune{' '}},
+		{Type: tea.KeyRunes, Runes: []rune{'j'}},
+		{Type: tea.KeySpace, Runes: []rune{' '}},
+	} {
+		updated, _ = app.Update(msg)
+		app = updated.(AppModel)
+	}
+	updated, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = updated.(AppModel)
+	if app.picker.open {
+		t.Fatal("expected picker closed after save")
+	}
+	if got := app.Watchlist(); len(got) != 2 || got[0] != "BTC" || got[1] != "ETH" {
+		t.Fatalf("unexpected watchlist %v", got)
+	}
+	if app.signals.symbolOption() != pinnedOption {
+		t.Fatalf("expected explorer to default to pinned symbols, got %s", app.signals.symbolOption())
+	}
+	if cmd == nil {
+		t.Fatal("expected save command")
+	}
+	runBatch(cmd)
+	if len(store.saved) != 2 {
+		t.Fatalf("expected watchlist persisted, got %v", store.saved)
+	}
+}
+
+func TestAppModelWatchlistPickerCapturesQuit(t *testing.T) {
+	m := NewAppModel(testServices())
+	m.SetSize(120, 40)
+	m.picker = newWatchlistPicker(nil)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	app := updated.(AppModel)
+	if app.quitting {
+		t.Fatal("q must not quit while the picker is open")
+	}
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(AppModel).picker.open {
+		t.Fatal("expected esc to close the picker")
+	}
+}
+
+// runBatch runs cmd and any commands it batches. Tick commands would block.
+func runBatch(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runBatch(c)
+		}
+	}
+}
+
+type stubStateStore struct {
+	state domain.TUIState
+	saved []domain.TUIState
+}
+
+func (s *stubStateStore) GetTUIState(ctx context.Context, userID int64) (domain.TUIState, error) {
+	return s.state, nil
+}
+
+func (s *stubStateStore) SetTUIState(ctx context.Context, userID int64, state domain.TUIState) error {
+	s.saved = append(s.saved, state)
+	return nil
+}
+
+func TestAppModelRestoresSavedState(t *testing.T) {
+	store := &stubStateStore{state: domain.TUIState{
+		Tab:             "signals",
+		Theme:           "light",
+		SignalSymbol:    "SOL",
+		SignalRisk:      "4",
+		SignalIndicator: "macd",
+		HeatMetric:      "volume",
+		BacktestView:    "calibration",
+	}}
+	svc := testServices()
+	svc.State = store
+	m := NewAppModel(svc)
+
+	updated, _ := m.Update(loadStateCmd(svc)())
+	app := updated.(AppModel)
+	if app.ActiveTab() != TabSignals {
+		t.Fatalf("expected signals tab, got %d", app.ActiveTab())
+	}
+	if app.ThemeName() != "light" || app.signals.styles.Theme.Name != "light" {
+		t.Fatalf("expected light theme on every screen, got %s", app.signals.styles.Theme.Name)
+	}
+	if symbol, risk, ind := app.signals.filterValues(); symbol != "SOL" || risk != "4" || ind != "macd" {
+		t.Fatalf("unexpected filters %s %s %s", symbol, risk, ind)
+	}
+	if app.dashboard.HeatMetric() != HeatVolume {
+		t.Fatalf("expected volume heat map, got %v", app.dashboard.HeatMetric())
+	}
+	if app.backtest.ActiveView() != backtestViewCalibration {
+		t.Fatalf("expected calibration view, got %d", app.backtest.ActiveView())
+	}
+	if len(store.saved) != 0 {
+		t.Fatalf("restoring must not save, got %v", store.saved)
+	}
+}
+
+func TestAppModelRestoreIgnoresUnknownValues(t *testing.T) {
+	store := &stubStateStore{state: domain.TUIState{Tab: "portfolio", Theme: "neon", SignalRisk: "9"}}
+	svc := testServices()
+	svc.State = store
+	m := NewAppModel(svc)
+
+	updated, _ := m.Update(loadStateCmd(svc)())
+	app := updated.(AppModel)
+	if app.ActiveTab() != TabDashboard || app.ThemeName() != DefaultThemeName {
+		t.Fatalf("expected defaults, got tab %d theme %s", app.ActiveTab(), app.ThemeName())
+	}
+	if _, risk, _ := app.signals.filterValues(); risk != "ALL" {
+		t.Fatalf("expected risk ALL, got %s", risk)
+	}
+}
+
+func TestAppModelRestoresPinnedSymbolAfterWatchlistLoads(t *testing.T) {
+	svc := testServices()
+	m := NewAppModel(svc)
+
+	updated, _ := m.Update(stateMsg{SignalSymbol: "SOL"})
+	updated, _ = updated.Update(watchlistMsg{"BTC", "SOL"})
+	if symbol, _, _ := updated.(AppModel).signals.filterValues(); symbol != "SOL" {
+		t.Fatalf("expected SOL to survive the watchlist load, got %s", symbol)
+	}
+}
+
+func TestAppModelSavesStateAfterDelay(t *testing.T) {
+	store := &stubStateStore{}
+	svc := testServices()
+	svc.State = store
+	m := NewAppModel(svc)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'4'}})
+	if updated.(AppModel).stateSeq != 0 {
+		t.Fatal("expected no save before the stored state loads")
+	}
+
+	updated, _ = updated.Update(stateMsg{})
+	updated, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+	if cmd == nil {
+		t.Fatal("expected a delayed save after switching tabs")
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	app := updated.(AppModel)
+
+	// The first delay expired after a newer change; only the latest saves.
+	updated, cmd = app.Update(stateSaveMsg{seq: 1})
+	runBatch(cmd)
+	if len(store.saved) != 0 {
+		t.Fatalf("expected stale save skipped, got %v", store.saved)
+	}
+	updated, cmd = updated.Update(stateSaveMsg{seq: 2})
+	runBatch(cmd)
+	if len(store.saved) != 1 {
+		t.Fatalf("expected one save, got %v", store.saved)
+	}
+	if got := store.saved[0]; got.Tab != "signals" || got.SignalRisk != "1" {
+		t.Fatalf("unexpected saved state %+v", got)
+	}
+}
+
+func TestAppModelCycleTheme(t *testing.T) {
+	m := NewAppModel(testServices())
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	app := updated.(AppModel)
+	names := ThemeNames()
+	want := names[(indexOf(names, DefaultThemeName)+1)%len(names)]
+	if app.ThemeName() != want || app.backtest.styles.Theme.Name != want {
+		t.Fatalf("expected %s, got %s", want, app.ThemeName())
+	}
+	if app.currentState().Theme != want {
+		t.Fatalf("expected theme choice in saved state, got %q", app.currentState().Theme)
+	}
+}
+
+type stubMaintenanceSwitch struct {
+	state domain.MaintenanceState
+	actor string
+}
+
+func (s *stubMaintenanceSwitch) State(ctx context.Context) (domain.MaintenanceState, error) {
+	return s.state, nil
+}
+
+func (s *stubMaintenanceSwitch) Set(ctx context.Context, enabled bool, reason string) (domain.MaintenanceState, error) {
+	s.actor = domain.ActorFromContext(ctx)
+	s.state = domain.MaintenanceState{Enabled: enabled, Reason: reason, Actor: s.actor}
+	return s.state, nil
+}
+
+func TestAppModelMaintenanceToggle(t *testing.T) {
+	sw := &stubMaintenanceSwitch{}
+	svc := testServices()
+	svc.Maintenance = sw
+	m := NewAppModel(svc)
+	m.SetSize(120, 40)
+	press := func(m AppModel, r rune) (AppModel, tea.Cmd) {
+		updated, cmd := m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		return updated, cmd
+	}
+
+	// Without the operator flag M does nothing.
+	m, _ = press(m, 'M')
+	if m.maintenanceConfirm {
+		t.Fatal("expected M to be ignored for non-operators")
+	}
+
+	m.services.CanToggleMaintenance = true
+	m, _ = press(m, 'M')
+	if !m.maintenanceConfirm || !strings.Contains(m.View(), "Press M again") {
+		t.Fatal("expected a confirmation prompt after the first M")
+	}
+	m, _ = press(m, 'x')
+	if m.maintenanceConfirm || sw.state.Enabled {
+		t.Fatal("expected another key to cancel the toggle")
+	}
+
+	m, _ = press(m, 'M')
+	m, cmd := press(m, 'M')
+	if cmd == nil {
+		t.Fatal("expected a command to set maintenance")
+	}
+	m, _ = m.update(cmd())
+	if !sw.state.Enabled || sw.actor != "ssh:testuser" {
+		t.Fatalf("expected maintenance on by ssh:testuser, got %+v actor=%q", sw.state, sw.actor)
+	}
+	if !m.Maintenance().Enabled || !strings.Contains(m.View(), "MAINTENANCE") {
+		t.Fatal("expected the maintenance banner")
+	}
+
+	sw.state = domain.MaintenanceState{}
+	m, _ = m.update(loadMaintenanceCmd(m.services)())
+	if m.Maintenance().Enabled || strings.Contains(m.View(), "MAINTENANCE") {
+		t.Fatal("expected the banner to clear after a refresh")
+	}
+}
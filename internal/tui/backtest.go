@@ -3,10 +3,12 @@ package tui
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,19 +18,52 @@ import (
 type backtestSummaryMsg []repository.DailyAccuracy
 type backtestDailyMsg []repository.DailyAccuracy
 type backtestPredictionsMsg []domain.MLPrediction
+type backtestSeriesMsg []repository.AccuracySeries
+type backtestCalibrationMsg domain.Calibration
+type backtestRiskMsg []domain.RiskLevelStats
+type backtestAnomalyMsg []domain.AnomalyCell
 type backtestErrMsg struct{ err error }
 
 const (
 	backtestViewAccuracy    = 0
 	backtestViewPredictions = 1
+	backtestViewCalibration = 2
+	backtestViewRisk        = 3
+	backtestViewAnomaly     = 4
 )
 
+// backtestViewKeys name the views in saved TUI state.
+var backtestViewKeys = []string{"accuracy", "predictions", "calibration", "risk", "anomaly"}
+
+const (
+	// sparklineDays is the window of the per-model sparklines.
+	sparklineDays = 30
+	// trendRecentDays is compared against the rest of the window.
+	trendRecentDays = 7
+	// trendThreshold is the accuracy change shown as up or down (2 points).
+	trendThreshold = 0.02
+	// riskMinSamples is the fewest predictions a risk level needs to count
+	// toward the ordering check.
+	riskMinSamples = 20
+	// anomalyDays is the window of the anomaly heat map.
+	anomalyDays = 14
+)
+
+// anomalyShades draws a day's peak anomaly score from calm to extreme.
+var anomalyShades = []rune(" ░▒▓█")
+
 // BacktestModel is the Bubble Tea model for the backtest viewer screen.
 type BacktestModel struct {
 	services    Services
+	styles      *Styles
 	summary     []repository.DailyAccuracy
 	daily       []repository.DailyAccuracy
 	predictions []domain.MLPrediction
+	series      map[string][]repository.DailyAccuracy
+	calibration domain.Calibration
+	calModel    string // model key shown in the calibration view; empty pools all
+	risk        []domain.RiskLevelStats
+	anomalies   []domain.AnomalyCell
 	activeView  int
 	loading     bool
 	err         error
@@ -40,6 +75,7 @@ type BacktestModel struct {
 func NewBacktestModel(svc Services) BacktestModel {
 	return BacktestModel{
 		services: svc,
+		styles:   defaultStyles(),
 		loading:  true,
 	}
 }
@@ -50,6 +86,10 @@ func (m BacktestModel) Init() tea.Cmd {
 		m.fetchSummaryCmd(),
 		m.fetchDailyCmd(),
 		m.fetchPredictionsCmd(),
+		m.fetchSeriesCmd(),
+		m.fetchCalibrationCmd(),
+		m.fetchRiskCmd(),
+		m.fetchAnomalyCmd(),
 	)
 }
 
@@ -69,6 +109,28 @@ func (m BacktestModel) Update(msg tea.Msg) (BacktestModel, tea.Cmd) {
 		m.predictions = []domain.MLPrediction(msg)
 		return m, nil
 
+	case backtestSeriesMsg:
+		m.series = make(map[string][]repository.DailyAccuracy, len(msg))
+		for _, s := range msg {
+			m.series[s.ModelKey] = s.Days
+		}
+		return m, nil
+
+	case backtestCalibrationMsg:
+		// Drop replies for a model the user has already cycled past.
+		if msg.ModelKey == m.calModel {
+			m.calibration = domain.Calibration(msg)
+		}
+		return m, nil
+
+	case backtestRiskMsg:
+		m.risk = []domain.RiskLevelStats(msg)
+		return m, nil
+
+	case backtestAnomalyMsg:
+		m.anomalies = []domain.AnomalyCell(msg)
+		return m, nil
+
 	case backtestErrMsg:
 		m.err = msg.err
 		m.loading = false
@@ -77,15 +139,56 @@ func (m BacktestModel) Update(msg tea.Msg) (BacktestModel, tea.Cmd) {
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, DefaultKeyMap.ToggleView):
-			m.activeView = 1 - m.activeView
+			if m.activeView == backtestViewAccuracy {
+				m.activeView = backtestViewPredictions
+			} else {
+				m.activeView = backtestViewAccuracy
+			}
 			return m, nil
 
+		case key.Matches(msg, DefaultKeyMap.Calibration):
+			if m.activeView == backtestViewCalibration {
+				m.activeView = backtestViewAccuracy
+			} else {
+				m.activeView = backtestViewCalibration
+			}
+			return m, nil
+
+		case key.Matches(msg, DefaultKeyMap.RiskView):
+			if m.activeView == backtestViewRisk {
+				m.activeView = backtestViewAccuracy
+			} else {
+				m.activeView = backtestViewRisk
+			}
+			return m, nil
+
+		case key.Matches(msg, DefaultKeyMap.AnomalyView):
+			if m.activeView == backtestViewAnomaly {
+				m.activeView = backtestViewAccuracy
+			} else {
+				m.activeView = backtestViewAnomaly
+			}
+			return m, nil
+
+		case key.Matches(msg, DefaultKeyMap.CycleModel):
+			if m.activeView != backtestViewCalibration {
+				return m, nil
+			}
+			m.calModel = m.nextCalibrationModel()
+			m.calibration = domain.Calibration{ModelKey: m.calModel}
+			return m, m.fetchCalibrationCmd()
+
 		case key.Matches(msg, DefaultKeyMap.Refresh):
 			m.loading = true
 			return m, tea.Batch(
 				m.fetchSummaryCmd(),
 				m.fetchDailyCmd(),
 				m.fetchPredictionsCmd(),
+				m.fetchSeriesCmd(),
+				m.fetchCalibrationCmd(),
+				m.fetchRiskCmd(),
+				m.fetchAnomalyCmd(),
+				m.fetchAnomalyCmd(),
 			)
 		}
 	}
@@ -98,31 +201,47 @@ func (m BacktestModel) View() string {
 	var sections []string
 
 	// Header with view toggle
-	viewLabel := "[Accuracy]  Predictions"
-	if m.activeView == backtestViewPredictions {
-		viewLabel = " Accuracy  [Predictions]"
+	labels := []string{"Accuracy", "Predictions", "Calibration", "Risk", "Anomalies"}
+	for i, l := range labels {
+		if i == m.activeView {
+			labels[i] = "[" + l + "]"
+		} else {
+			labels[i] = " " + l + " "
+		}
 	}
-	sections = append(sections, HeaderStyle.Render("  Backtest Viewer")+"  "+SubtextStyle.Render(viewLabel))
+	viewLabel := strings.Join(labels, " ")
+	sections = append(sections, m.styles.HeaderStyle.Render("  Backtest Viewer")+"  "+m.styles.SubtextStyle.Render(viewLabel))
 	sections = append(sections, "")
 
 	if m.loading {
-		sections = append(sections, SubtextStyle.Render("  Loading backtest data..."))
+		sections = append(sections, m.styles.SubtextStyle.Render("  Loading backtest data..."))
 		return strings.Join(sections, "\n")
 	}
 
 	if m.err != nil {
-		sections = append(sections, ErrorStyle.Render(fmt.Sprintf("  Error: %v", m.err)))
+		sections = append(sections, m.styles.ErrorStyle.Render(fmt.Sprintf("  Error: %v", m.err)))
 		return strings.Join(sections, "\n")
 	}
 
-	if m.activeView == backtestViewAccuracy {
-		sections = append(sections, m.renderAccuracyView()...)
-	} else {
+	help := "  [v] toggle view  [c] calibration  [r] risk levels  [a] anomalies  [R] refresh"
+	switch m.activeView {
+	case backtestViewPredictions:
 		sections = append(sections, m.renderPredictionsView()...)
+	case backtestViewCalibration:
+		sections = append(sections, m.renderCalibrationView()...)
+		help = "  [c] back  [m] cycle model  [R] refresh"
+	case backtestViewRisk:
+		sections = append(sections, m.renderRiskView()...)
+		help = "  [r] back  [R] refresh"
+	case backtestViewAnomaly:
+		sections = append(sections, m.renderAnomalyView()...)
+		help = "  [a] back  [R] refresh"
+	default:
+		sections = append(sections, m.renderAccuracyView()...)
 	}
 
 	sections = append(sections, "")
-	sections = append(sections, SubtextStyle.Render("  [v] toggle view  [R] refresh"))
+	sections = append(sections, m.styles.SubtextStyle.Render(help))
 
 	return strings.Join(sections, "\n")
 }
@@ -145,13 +264,13 @@ func (m BacktestModel) renderAccuracyView() []string {
 	var lines []string
 
 	if len(m.summary) == 0 && len(m.daily) == 0 {
-		lines = append(lines, SubtextStyle.Render("  No backtest data available. Enable ML models (Phase 6) to see prediction accuracy."))
+		lines = append(lines, m.styles.SubtextStyle.Render("  No backtest data available. Enable ML models (Phase 6) to see prediction accuracy."))
 		return lines
 	}
 
 	// Overall model accuracy
 	if len(m.summary) > 0 {
-		lines = append(lines, HeaderStyle.Render("  Model Accuracy (All-Time)"))
+		lines = append(lines, m.styles.HeaderStyle.Render("  Model Accuracy (All-Time)"))
 		lines = append(lines, "")
 
 		barWidth := m.width/3 - 5
@@ -163,15 +282,27 @@ func (m BacktestModel) renderAccuracyView() []string {
 		}
 
 		for _, s := range m.summary {
-			bar := RenderBarChart(s.ModelKey, s.Accuracy, barWidth)
-			lines = append(lines, fmt.Sprintf("  %s  (%d)", bar, s.Total))
+			bar := m.styles.RenderBarChart(s.ModelKey, s.Accuracy, barWidth)
+			line := fmt.Sprintf("  %s  %-8s", bar, fmt.Sprintf("(%d)", s.Total))
+			if days := m.series[s.ModelKey]; len(days) > 0 {
+				line += "  " + RenderSparkline(dailyAccuracies(days))
+				if delta, ok := accuracyTrend(days, trendRecentDays); ok {
+					line += " " + m.styles.RenderTrendArrow(delta, trendThreshold)
+				}
+			}
+			lines = append(lines, line)
+		}
+		if len(m.series) > 0 {
+			lines = append(lines, m.styles.SubtextStyle.Render(fmt.Sprintf(
+				"  Sparklines: daily accuracy, last %d days. Arrow: last %d days vs the rest.",
+				sparklineDays, trendRecentDays)))
 		}
 		lines = append(lines, "")
 	}
 
 	// Daily breakdown
 	if len(m.daily) > 0 {
-		lines = append(lines, HeaderStyle.Render("  Daily Accuracy (Last 30 Days)"))
+		lines = append(lines, m.styles.HeaderStyle.Render("  Daily Accuracy (Last 30 Days)"))
 		lines = append(lines, "")
 
 		barWidth := m.width/3 - 5
@@ -194,7 +325,7 @@ func (m BacktestModel) renderAccuracyView() []string {
 		for i := 0; i < count; i++ {
 			d := m.daily[i]
 			label := d.DayUTC.Format("2006-01-02")
-			bar := RenderBarChart(label, d.Accuracy, barWidth)
+			bar := m.styles.RenderBarChart(label, d.Accuracy, barWidth)
 			lines = append(lines, fmt.Sprintf("  %s  (%d/%d)", bar, d.Correct, d.Total))
 		}
 	}
@@ -206,17 +337,17 @@ func (m BacktestModel) renderPredictionsView() []string {
 	var lines []string
 
 	if len(m.predictions) == 0 {
-		lines = append(lines, SubtextStyle.Render("  No resolved predictions available."))
+		lines = append(lines, m.styles.SubtextStyle.Render("  No resolved predictions available."))
 		return lines
 	}
 
-	lines = append(lines, HeaderStyle.Render("  Recent Resolved Predictions"))
+	lines = append(lines, m.styles.HeaderStyle.Render("  Recent Resolved Predictions"))
 	lines = append(lines, "")
-	lines = append(lines, SubtextStyle.Render(
+	lines = append(lines, m.styles.SubtextStyle.Render(
 		fmt.Sprintf("  %-6s %-4s %-18s %-6s %-5s %-8s %-8s",
 			"Symbol", "Int", "Model", "Dir", "Risk", "Correct", "Return"),
 	))
-	lines = append(lines, SubtextStyle.Render("  "+strings.Repeat("─", 65)))
+	lines = append(lines, m.styles.SubtextStyle.Render("  "+strings.Repeat("─", 65)))
 
 	maxRows := m.height - 10
 	if maxRows < 5 {
@@ -233,27 +364,23 @@ func (m BacktestModel) renderPredictionsView() []string {
 		correctStr := "?"
 		if p.IsCorrect != nil {
 			if *p.IsCorrect {
-				correctStr = PriceUpStyle.Render("YES")
+				correctStr = m.styles.PriceUpStyle.Render("YES")
 			} else {
-				correctStr = PriceDownStyle.Render("NO")
+				correctStr = m.styles.PriceDownStyle.Render("NO")
 			}
 		}
 
 		returnStr := "n/a"
 		if p.RealizedReturn != nil {
-			sign := ""
-			if *p.RealizedReturn > 0 {
-				sign = "+"
-			}
-			returnStr = fmt.Sprintf("%s%.2f%%", sign, *p.RealizedReturn*100)
+			returnStr = domain.PercentFromRatio(*p.RealizedReturn).String()
 		}
 
-		dirStyle := DirectionHoldStyle
+		dirStyle := m.styles.DirectionHoldStyle
 		switch p.Direction {
 		case domain.DirectionLong:
-			dirStyle = DirectionLongStyle
+			dirStyle = m.styles.DirectionLongStyle
 		case domain.DirectionShort:
-			dirStyle = DirectionShortStyle
+			dirStyle = m.styles.DirectionShortStyle
 		}
 
 		lines = append(lines, fmt.Sprintf("  %-6s %-4s %-18s %s %-5d %-8s %-8s",
@@ -268,7 +395,7 @@ func (m BacktestModel) renderPredictionsView() []string {
 	}
 
 	if len(m.predictions) > maxRows {
-		lines = append(lines, SubtextStyle.Render(
+		lines = append(lines, m.styles.SubtextStyle.Render(
 			fmt.Sprintf("  Showing %d of %d predictions", count, len(m.predictions)),
 		))
 	}
@@ -276,6 +403,150 @@ func (m BacktestModel) renderPredictionsView() []string {
 	return lines
 }
 
+func (m BacktestModel) renderCalibrationView() []string {
+	model := m.calModel
+	if model == "" {
+		model = "all models"
+	}
+	lines := []string{m.styles.HeaderStyle.Render("  Calibration: " + model), ""}
+
+	cal := m.calibration
+	if cal.Total == 0 {
+		return append(lines, m.styles.SubtextStyle.Render("  No resolved predictions for this model yet."))
+	}
+
+	barWidth := m.width/2 - 30
+	barWidth = max(10, min(40, barWidth))
+
+	lines = append(lines, m.styles.SubtextStyle.Render(fmt.Sprintf("  %-9s  %-*s  %9s  %8s  %5s",
+		"Predicted", barWidth, "Realized (│ = predicted)", "Mean pred", "Realized", "N")))
+	for _, b := range cal.Buckets {
+		lines = append(lines, fmt.Sprintf("  %3.0f-%3.0f%%  %s  %8.1f%%  %7.1f%%  %5d",
+			b.Lower*100, b.Upper*100,
+			m.styles.RenderReliabilityBar(b.MeanPredicted, b.RealizedFrequency, barWidth),
+			b.MeanPredicted*100, b.RealizedFrequency*100, b.Count))
+	}
+	lines = append(lines, "", m.styles.SubtextStyle.Render(fmt.Sprintf(
+		"  %d resolved predictions. Expected calibration error %.1fpp; bars ending at their marker are well calibrated.",
+		cal.Total, cal.ECE*100)))
+	return lines
+}
+
+func (m BacktestModel) renderRiskView() []string {
+	lines := []string{m.styles.HeaderStyle.Render("  Performance by Risk Level"), ""}
+	if len(m.risk) == 0 {
+		return append(lines, m.styles.SubtextStyle.Render("  No resolved predictions yet."))
+	}
+
+	barWidth := max(10, min(30, m.width/3-5))
+	for i := 0; i < len(m.risk); {
+		model := m.risk[i].ModelKey
+		j := i
+		for j < len(m.risk) && m.risk[j].ModelKey == model {
+			j++
+		}
+		levels := m.risk[i:j]
+		i = j
+
+		verdict := m.styles.SubtextStyle.Render(fmt.Sprintf("not enough data (need %d per level)", riskMinSamples))
+		if ordered, ok := domain.RiskOrdersHitRate(levels, riskMinSamples); ok && ordered {
+			verdict = m.styles.PriceUpStyle.Render("✓ hit rate falls as risk rises")
+		} else if ok {
+			verdict = m.styles.PriceDownStyle.Render("✗ a riskier level beat a safer one")
+		}
+		lines = append(lines, "  "+m.styles.HeaderStyle.Render(model)+"  "+verdict)
+		for _, s := range levels {
+			bar := m.styles.RenderBarChart(fmt.Sprintf("  risk %d", s.Risk), s.HitRate, barWidth)
+			lines = append(lines, fmt.Sprintf("  %s  avg %s  (%d)", bar, domain.PercentFromRatio(s.AvgReturn), s.Total))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, m.styles.SubtextStyle.Render(
+		"  Hit rate over all resolved predictions; average return is signed by the predicted direction, longs and shorts only."))
+	return lines
+}
+
+// renderAnomalyView draws one row per symbol and one column per day, shaded
+// by the day's peak isolation forest score. Days on which the ensemble was
+// damped are drawn in the warning color.
+func (m BacktestModel) renderAnomalyView() []string {
+	lines := []string{m.styles.HeaderStyle.Render(fmt.Sprintf("  Anomaly Intensity (last %d days, UTC)", anomalyDays)), ""}
+	if len(m.anomalies) == 0 {
+		return append(lines, m.styles.SubtextStyle.Render("  No anomaly scores recorded yet."))
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	first := today.AddDate(0, 0, -(anomalyDays - 1))
+	bySymbol := make(map[string]map[int]domain.AnomalyCell)
+	var symbols []string
+	strongest := domain.AnomalyCell{MinDampFactor: 1}
+	for _, c := range m.anomalies {
+		day := int(c.Day.UTC().Truncate(24*time.Hour).Sub(first) / (24 * time.Hour))
+		if day < 0 || day >= anomalyDays {
+			continue
+		}
+		if bySymbol[c.Symbol] == nil {
+			bySymbol[c.Symbol] = make(map[int]domain.AnomalyCell)
+			symbols = append(symbols, c.Symbol)
+		}
+		bySymbol[c.Symbol][day] = c
+		if c.MinDampFactor < strongest.MinDampFactor {
+			strongest = c
+		}
+	}
+
+	header := "        "
+	for d := 0; d < anomalyDays; d++ {
+		header += fmt.Sprintf("%-3s", first.AddDate(0, 0, d).Format("02"))
+	}
+	lines = append(lines, m.styles.SubtextStyle.Render(header))
+	for _, symbol := range symbols {
+		var row strings.Builder
+		fmt.Fprintf(&row, "  %-6s", symbol)
+		for d := 0; d < anomalyDays; d++ {
+			c, ok := bySymbol[symbol][d]
+			if !ok {
+				row.WriteString(m.styles.SubtextStyle.Render(" · "))
+				continue
+			}
+			shade := strings.Repeat(string(anomalyShade(c.MaxScore)), 2) + " "
+			if c.MinDampFactor < 1 {
+				row.WriteString(m.styles.PriceDownStyle.Render(shade))
+			} else {
+				row.WriteString(shade)
+			}
+		}
+		lines = append(lines, row.String())
+	}
+
+	lines = append(lines, "", m.styles.SubtextStyle.Render("  Shade is the day's peak score ("+string(anomalyShades[1:])+" low to high); red days had ensemble damping."))
+	if strongest.MinDampFactor < 1 {
+		lines = append(lines, m.styles.SubtextStyle.Render(fmt.Sprintf("  Strongest damping: %s on %s, confidence scaled by %.2f.",
+			strongest.Symbol, strongest.Day.Format("Jan 02"), strongest.MinDampFactor)))
+	}
+	return lines
+}
+
+func anomalyShade(score float64) rune {
+	i := int(math.Round(math.Max(0, math.Min(1, score)) * float64(len(anomalyShades)-1)))
+	return anomalyShades[i]
+}
+
+// nextCalibrationModel cycles through all models pooled, then each model key
+// in the summary.
+func (m BacktestModel) nextCalibrationModel() string {
+	keys := []string{""}
+	for _, s := range m.summary {
+		keys = append(keys, s.ModelKey)
+	}
+	for i, k := range keys {
+		if k == m.calModel {
+			return keys[(i+1)%len(keys)]
+		}
+	}
+	return ""
+}
+
 func (m BacktestModel) fetchSummaryCmd() tea.Cmd {
 	return func() tea.Msg {
 		if m.services.Backtest == nil {
@@ -303,6 +574,19 @@ func (m BacktestModel) fetchDailyCmd() tea.Cmd {
 	}
 }
 
+func (m BacktestModel) fetchSeriesCmd() tea.Cmd {
+	return func() tea.Msg {
+		if m.services.Backtest == nil {
+			return nil
+		}
+		series, err := m.services.Backtest.GetDailyAccuracySeries(context.Background(), sparklineDays)
+		if err != nil {
+			return nil // Non-critical
+		}
+		return backtestSeriesMsg(series)
+	}
+}
+
 func (m BacktestModel) fetchPredictionsCmd() tea.Cmd {
 	return func() tea.Msg {
 		if m.services.Backtest == nil {
@@ -315,3 +599,80 @@ func (m BacktestModel) fetchPredictionsCmd() tea.Cmd {
 		return backtestPredictionsMsg(preds)
 	}
 }
+
+func (m BacktestModel) fetchCalibrationCmd() tea.Cmd {
+	modelKey := m.calModel
+	return func() tea.Msg {
+		if m.services.Backtest == nil {
+			return nil
+		}
+		buckets, err := m.services.Backtest.GetCalibration(context.Background(), modelKey, domain.DefaultCalibrationBuckets)
+		if err != nil {
+			return nil // Non-critical
+		}
+		return backtestCalibrationMsg(domain.NewCalibration(modelKey, buckets))
+	}
+}
+
+func (m BacktestModel) fetchRiskCmd() tea.Cmd {
+	return func() tea.Msg {
+		if m.services.Backtest == nil {
+			return nil
+		}
+		stats, err := m.services.Backtest.GetRiskLevelStats(context.Background(), 0)
+		if err != nil {
+			return nil // Non-critical
+		}
+		return backtestRiskMsg(stats)
+	}
+}
+
+func (m BacktestModel) fetchAnomalyCmd() tea.Cmd {
+	return func() tea.Msg {
+		if m.services.Backtest == nil {
+			return nil
+		}
+		cells, err := m.services.Backtest.GetAnomalyHeat(context.Background(), anomalyDays, "")
+		if err != nil {
+			return nil // Non-critical
+		}
+		return backtestAnomalyMsg(cells)
+	}
+}
+
+func dailyAccuracies(days []repository.DailyAccuracy) []float64 {
+	out := make([]float64, len(days))
+	for i, d := range days {
+		out[i] = d.Accuracy
+	}
+	return out
+}
+
+// accuracyTrend compares pooled accuracy over the last recent days of the
+// series (oldest first) with the days before them.
+func accuracyTrend(days []repository.DailyAccuracy, recent int) (float64, bool) {
+	if len(days) <= recent {
+		return 0, false
+	}
+	pooled := func(window []repository.DailyAccuracy) (float64, bool) {
+		total, correct := 0, 0
+		for _, d := range window {
+			total += d.Total
+			correct += d.Correct
+		}
+		if total == 0 {
+			return 0, false
+		}
+		return float64(correct) / float64(total), true
+	}
+	split := len(days) - recent
+	before, ok := pooled(days[:split])
+	if !ok {
+		return 0, false
+	}
+	after, ok := pooled(days[split:])
+	if !ok {
+		return 0, false
+	}
+	return after - before, true
+}
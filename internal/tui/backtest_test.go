@@ -1,10 +1,12 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -88,3 +90,173 @@ func TestBacktestModelViewWithData(t *testing.T) {
 		t.Fatal("expected non-empty view with data")
 	}
 }
+
+func TestBacktestModelRendersSparklineAndTrend(t *testing.T) {
+	m := NewBacktestModel(testServices())
+	m.SetSize(120, 40)
+	m.loading = false
+	m.summary = []repository.DailyAccuracy{{ModelKey: "ml_logreg_up4h", Total: 100, Correct: 60, Accuracy: 0.6}}
+
+	start := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	var days []repository.DailyAccuracy
+	for i := 0; i < 10; i++ {
+		correct := 5
+		if i >= 3 {
+			correct = 8 // last 7 days improve
+		}
+		days = append(days, repository.DailyAccuracy{
+			ModelKey: "ml_logreg_up4h", DayUTC: start.AddDate(0, 0, i),
+			Total: 10, Correct: correct, Accuracy: float64(correct) / 10,
+		})
+	}
+	updated, _ := m.Update(backtestSeriesMsg{{ModelKey: "ml_logreg_up4h", Days: days}})
+
+	view := updated.View()
+	if !strings.Contains(view, "▁▁▁█") {
+		t.Fatalf("expected sparkline in view:\n%s", view)
+	}
+	if !strings.Contains(view, "▲ +30.0pp") {
+		t.Fatalf("expected upward trend in view:\n%s", view)
+	}
+}
+
+func TestAccuracyTrendNeedsBothWindows(t *testing.T) {
+	days := []repository.DailyAccuracy{{Total: 10, Correct: 5}, {Total: 10, Correct: 5}}
+	if _, ok := accuracyTrend(days, 7); ok {
+		t.Fatal("expected no trend without an earlier window")
+	}
+	days = append([]repository.DailyAccuracy{{Total: 0}}, make([]repository.DailyAccuracy, 7)...)
+	if _, ok := accuracyTrend(days, 7); ok {
+		t.Fatal("expected no trend when a window has no resolved predictions")
+	}
+}
+
+func TestRenderSparklineFlatSeries(t *testing.T) {
+	if got := RenderSparkline([]float64{0.5, 0.5}); got != "▅▅" {
+		t.Fatalf("expected mid-height flat line, got %q", got)
+	}
+}
+
+func TestBacktestModelCalibrationView(t *testing.T) {
+	stub := &stubBacktestQuerier{
+		summary: []repository.DailyAccuracy{{ModelKey: "ml_logreg_up4h", Total: 40, Accuracy: 0.6}},
+		calibration: []domain.CalibrationBucket{
+			{Lower: 0.6, Upper: 0.7, Count: 40, MeanPredicted: 0.65, RealizedFrequency: 0.55},
+		},
+	}
+	svc := testServices()
+	svc.Backtest = stub
+	m := NewBacktestModel(svc)
+	m.SetSize(120, 40)
+	m.loading = false
+	m.summary = stub.summary
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if m.ActiveView() != backtestViewCalibration {
+		t.Fatalf("expected calibration view, got %d", m.ActiveView())
+	}
+	if !strings.Contains(m.View(), "No resolved predictions") {
+		t.Fatalf("expected empty calibration view:\n%s", m.View())
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if cmd == nil {
+		t.Fatal("expected calibration fetch after cycling model")
+	}
+	m, _ = m.Update(cmd())
+	if stub.calModel != "ml_logreg_up4h" {
+		t.Fatalf("expected fetch for ml_logreg_up4h, got %q", stub.calModel)
+	}
+	view := m.View()
+	for _, want := range []string{"Calibration: ml_logreg_up4h", " 60- 70%", "65.0%", "55.0%", "10.0pp"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	// A late reply for the pooled view must not replace the model's curve.
+	m, _ = m.Update(backtestCalibrationMsg(domain.NewCalibration("", nil)))
+	if !strings.Contains(m.View(), "65.0%") {
+		t.Fatal("expected stale reply to be ignored")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if m.ActiveView() != backtestViewAccuracy {
+		t.Fatalf("expected accuracy view after closing calibration, got %d", m.ActiveView())
+	}
+}
+
+func TestRenderReliabilityBarMarksPrediction(t *testing.T) {
+	got := defaultStyles().RenderReliabilityBar(0.5, 0.3, 10)
+	if got != "███░░│░░░░" {
+		t.Fatalf("unexpected bar %q", got)
+	}
+}
+
+func TestBacktestModelRiskView(t *testing.T) {
+	m := NewBacktestModel(testServices())
+	m.SetSize(120, 40)
+	m.loading = false
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if m.ActiveView() != backtestViewRisk {
+		t.Fatalf("expected risk view, got %d", m.ActiveView())
+	}
+	m, _ = m.Update(backtestRiskMsg{
+		{ModelKey: "ml_logreg_up4h", Risk: domain.RiskLevel2, Total: 40, Correct: 28, HitRate: 0.7, AvgReturn: 0.004},
+		{ModelKey: "ml_logreg_up4h", Risk: domain.RiskLevel4, Total: 40, Correct: 22, HitRate: 0.55, AvgReturn: -0.001},
+		{ModelKey: "ml_xgboost_up4h", Risk: domain.RiskLevel3, Total: 5, Correct: 3, HitRate: 0.6},
+	})
+	view := m.View()
+	for _, want := range []string{"risk 2", "avg +0.40%", "avg -0.10%", "hit rate falls as risk rises", "not enough data"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if m.ActiveView() != backtestViewAccuracy {
+		t.Fatalf("expected accuracy view after closing risk view, got %d", m.ActiveView())
+	}
+}
+
+func TestBacktestModelAnomalyView(t *testing.T) {
+	m := NewBacktestModel(testServices())
+	m.SetSize(120, 40)
+	m.loading = false
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if m.ActiveView() != backtestViewAnomaly {
+		t.Fatalf("expected anomaly view, got %d", m.ActiveView())
+	}
+	if !strings.Contains(m.View(), "No anomaly scores recorded yet") {
+		t.Fatalf("expected empty state:\n%s", m.View())
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	m, _ = m.Update(backtestAnomalyMsg{
+		{Symbol: "BTC", Day: today.AddDate(0, 0, -2), Samples: 24, MaxScore: 0.9, MeanScore: 0.5, MinDampFactor: 0.48},
+		{Symbol: "BTC", Day: today, Samples: 10, MaxScore: 0.3, MeanScore: 0.2, MinDampFactor: 1},
+		{Symbol: "ETH", Day: today, Samples: 10, MaxScore: 0.55, MeanScore: 0.4, MinDampFactor: 0.9},
+		{Symbol: "ETH", Day: today.AddDate(0, 0, -anomalyDays), MaxScore: 1, MinDampFactor: 0.1},
+	})
+	view := m.View()
+	for _, want := range []string{"BTC", "ETH", "██", "░░", "Strongest damping: BTC", "scaled by 0.48"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in view:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if m.ActiveView() != backtestViewAccuracy {
+		t.Fatalf("expected accuracy view after closing anomaly view, got %d", m.ActiveView())
+	}
+}
+
+func TestAnomalyShade(t *testing.T) {
+	for score, want := range map[float64]rune{-1: ' ', 0: ' ', 0.5: '▒', 1: '█', 2: '█'} {
+		if got := anomalyShade(score); got != want {
+			t.Fatalf("anomalyShade(%v) = %q, want %q", score, got, want)
+		}
+	}
+}
@@ -16,6 +16,10 @@ import (
 // Chat message types.
 type advisorReplyMsg string
 type advisorErrMsg struct{ err error }
+type chatHistoryMsg []chatMessage
+
+// chatHistoryLimit is how many earlier messages the chat screen opens with.
+const chatHistoryLimit = 50
 
 type chatMessage struct {
 	Role    string
@@ -26,6 +30,7 @@ type chatMessage struct {
 // ChatModel is the Bubble Tea model for the advisor chat screen.
 type ChatModel struct {
 	services Services
+	styles   *Styles
 	messages []chatMessage
 	input    textinput.Model
 	viewport viewport.Model
@@ -46,18 +51,18 @@ func NewChatModel(svc Services) ChatModel {
 
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
-	sp.Style = lipgloss.NewStyle().Foreground(SpinnerColor)
 
 	return ChatModel{
 		services: svc,
+		styles:   defaultStyles(),
 		input:    ti,
 		spinner:  sp,
 	}
 }
 
-// Init initializes the chat model.
+// Init initializes the chat model and loads the earlier conversation.
 func (m ChatModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.loadHistoryCmd())
 }
 
 // Update handles incoming messages.
@@ -77,6 +82,25 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 		m.viewport.GotoBottom()
 		return m, nil
 
+	case chatHistoryMsg:
+		// Anything typed while the history loaded stays after it.
+		m.messages = append([]chatMessage(msg), m.messages...)
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+
+	case commandReplyMsg:
+		m.messages = append(m.messages, chatMessage{
+			Role:    "command",
+			Content: string(msg),
+			Time:    time.Now(),
+		})
+		m.waiting = false
+		m.err = nil
+		m.viewport.SetContent(m.renderMessages())
+		m.viewport.GotoBottom()
+		return m, nil
+
 	case advisorErrMsg:
 		m.waiting = false
 		m.err = msg.err
@@ -92,9 +116,19 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 					Time:    time.Now(),
 				})
 				m.input.SetValue("")
-				m.waiting = true
+
+				// Slash commands are answered locally from live data.
+				cmd, isCommand, err := parseChatCommand(text)
+				m.waiting = err == nil
+				m.err = err
 				m.viewport.SetContent(m.renderMessages())
 				m.viewport.GotoBottom()
+				if err != nil {
+					return m, nil
+				}
+				if isCommand {
+					return m, tea.Batch(m.runCommandCmd(cmd), m.spinner.Tick)
+				}
 				return m, tea.Batch(
 					m.askAdvisorCmd(text),
 					m.spinner.Tick,
@@ -127,19 +161,13 @@ func (m ChatModel) Update(msg tea.Msg) (ChatModel, tea.Cmd) {
 
 // View renders the chat screen.
 func (m ChatModel) View() string {
-	if m.services.Advisor == nil {
-		return lipgloss.JoinVertical(lipgloss.Left,
-			"",
-			HeaderStyle.Render("  Chat with Trading Advisor"),
-			"",
-			SubtextStyle.Render("  Advisor not available. Set OPENAI_API_KEY to enable."),
-		)
-	}
-
 	var sections []string
 
-	sections = append(sections, HeaderStyle.Render("  Chat with Trading Advisor"))
-	sections = append(sections, SubtextStyle.Render(strings.Repeat("─", m.width-2)))
+	sections = append(sections, m.styles.HeaderStyle.Render("  Chat with Trading Advisor"))
+	if m.services.Advisor == nil {
+		sections = append(sections, m.styles.SubtextStyle.Render("  Advisor not available. Set OPENAI_API_KEY to enable. Slash commands still work, see /help."))
+	}
+	sections = append(sections, m.styles.SubtextStyle.Render(strings.Repeat("─", m.width-2)))
 
 	// Message viewport
 	if !m.ready {
@@ -147,14 +175,17 @@ func (m ChatModel) View() string {
 	}
 	sections = append(sections, m.viewport.View())
 
-	sections = append(sections, SubtextStyle.Render(strings.Repeat("─", m.width-2)))
+	sections = append(sections, m.styles.SubtextStyle.Render(strings.Repeat("─", m.width-2)))
 
 	// Input bar
 	if m.waiting {
-		sections = append(sections, fmt.Sprintf("  %s Thinking...", m.spinner.View()))
+		// Styled here so a theme change reaches the spinner too.
+		sp := m.spinner
+		sp.Style = lipgloss.NewStyle().Foreground(m.styles.SpinnerColor)
+		sections = append(sections, fmt.Sprintf("  %s Thinking...", sp.View()))
 	} else {
 		if m.err != nil {
-			sections = append(sections, ErrorStyle.Render(fmt.Sprintf("  Error: %v", m.err)))
+			sections = append(sections, m.styles.ErrorStyle.Render(fmt.Sprintf("  Error: %v", m.err)))
 		}
 		sections = append(sections, "  "+m.input.View())
 	}
@@ -206,36 +237,50 @@ func (m *ChatModel) initViewport() {
 
 func (m ChatModel) renderMessages() string {
 	if len(m.messages) == 0 {
-		return SubtextStyle.Render("  Start a conversation by typing a question below.")
+		return m.styles.SubtextStyle.Render("  Start a conversation by typing a question below, or /help for data commands.")
 	}
 
 	var lines []string
+	loc := m.services.Timezone()
+	today := time.Now().In(loc).Format("2006-01-02")
 	for _, msg := range m.messages {
-		timestamp := SubtextStyle.Render(msg.Time.Format("15:04"))
+		layout := "15:04"
+		if msg.Time.In(loc).Format("2006-01-02") != today {
+			layout = "Jan 2 15:04" // earlier sessions
+		}
+		timestamp := m.styles.SubtextStyle.Render(msg.Time.In(loc).Format(layout))
 		switch msg.Role {
 		case "user":
 			lines = append(lines, fmt.Sprintf("  %s  %s %s",
 				timestamp,
-				UserMsgStyle.Render("You:"),
+				m.styles.UserMsgStyle.Render("You:"),
 				msg.Content,
 			))
 		case "assistant":
 			lines = append(lines, fmt.Sprintf("  %s  %s",
 				timestamp,
-				AssistantMsgStyle.Render("Advisor:"),
+				m.styles.AssistantMsgStyle.Render("Advisor:"),
 			))
 			// Wrap long advisor responses
 			for _, line := range strings.Split(msg.Content, "\n") {
 				lines = append(lines, "         "+line)
 			}
+		case "command":
+			lines = append(lines, fmt.Sprintf("  %s  %s",
+				timestamp,
+				m.styles.AssistantMsgStyle.Render("Data:"),
+			))
+			for _, line := range strings.Split(msg.Content, "\n") {
+				lines = append(lines, "         "+line)
+			}
 		}
 		lines = append(lines, "")
 	}
 
 	if m.waiting {
 		lines = append(lines, fmt.Sprintf("  %s  %s",
-			SubtextStyle.Render(time.Now().Format("15:04")),
-			SubtextStyle.Render("Advisor is thinking..."),
+			m.styles.SubtextStyle.Render(time.Now().In(loc).Format("15:04")),
+			m.styles.SubtextStyle.Render("Working..."),
 		))
 	}
 
@@ -255,3 +300,26 @@ func (m ChatModel) askAdvisorCmd(question string) tea.Cmd {
 		return advisorReplyMsg(reply)
 	}
 }
+
+// loadHistoryCmd fetches the conversation this session continues. Only
+// user and advisor turns are stored; slash command replies are not.
+func (m ChatModel) loadHistoryCmd() tea.Cmd {
+	querier, ok := m.services.Advisor.(ChatHistoryQuerier)
+	if !ok {
+		return nil
+	}
+	chatID := m.services.ChatID()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		history, err := querier.History(ctx, chatID, chatHistoryLimit)
+		if err != nil {
+			return advisorErrMsg{err: fmt.Errorf("load chat history: %w", err)}
+		}
+		msgs := make([]chatMessage, 0, len(history))
+		for _, h := range history {
+			msgs = append(msgs, chatMessage{Role: h.Role, Content: h.Content, Time: h.CreatedAt.Local()})
+		}
+		return chatHistoryMsg(msgs)
+	}
+}
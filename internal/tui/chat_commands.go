@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandReplyMsg carries the output of a slash command answered locally.
+type commandReplyMsg string
+
+const chatCommandHelp = `Commands (answered from live data, no LLM):
+  /price BTC      current price, 24h change and volume
+  /price          all tracked prices
+  /signals [BTC]  latest technical signals
+  /predict [BTC]  latest ML ensemble predictions
+  /similar BTC    past setups most like BTC now, and what followed
+  /help           this list
+Anything else is sent to the advisor.`
+
+// chatCommand is a parsed slash command.
+type chatCommand struct {
+	name   string
+	symbol string
+}
+
+// parseChatCommand parses text starting with "/". The second result is false
+// when text is not a slash command.
+func parseChatCommand(text string) (chatCommand, bool, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return chatCommand{}, false, nil
+	}
+	cmd := chatCommand{name: strings.ToLower(strings.TrimPrefix(fields[0], "/"))}
+	switch cmd.name {
+	case "help":
+		return cmd, true, nil
+	case "price", "signals", "predict", "similar":
+	default:
+		return cmd, true, fmt.Errorf("unknown command /%s, try /help", cmd.name)
+	}
+	if len(fields) > 2 {
+		return cmd, true, fmt.Errorf("usage: /%s [SYMBOL]", cmd.name)
+	}
+	if len(fields) == 2 {
+		symbol := strings.ToUpper(fields[1])
+		if !domain.IsSupportedSymbol(symbol) {
+			return cmd, true, fmt.Errorf("unknown symbol %s, supported: %s",
+				symbol, strings.Join(domain.SupportedSymbols(), ", "))
+		}
+		cmd.symbol = symbol
+	}
+	if cmd.name == "similar" && cmd.symbol == "" {
+		return cmd, true, fmt.Errorf("usage: /similar SYMBOL")
+	}
+	return cmd, true, nil
+}
+
+func (m ChatModel) runCommandCmd(cmd chatCommand) tea.Cmd {
+	svc := m.services
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var (
+			reply string
+			err   error
+		)
+		switch cmd.name {
+		case "help":
+			reply = chatCommandHelp
+		case "price":
+			reply, err = priceCommand(ctx, svc.Prices, cmd.symbol)
+		case "signals":
+			reply, err = signalsCommand(ctx, svc.Signals, domain.SignalFilter{Symbol: cmd.symbol, Limit: 5}, svc.Timezone())
+		case "predict":
+			reply, err = signalsCommand(ctx, svc.Signals, domain.SignalFilter{
+				Symbol:    cmd.symbol,
+				Indicator: domain.IndicatorMLEnsembleUp4H,
+				Limit:     5,
+			}, svc.Timezone())
+		case "similar":
+			reply, err = similarCommand(ctx, svc.Similar, cmd.symbol, svc.SimilarInterval, svc.Timezone())
+		}
+		if err != nil {
+			return advisorErrMsg{err: err}
+		}
+		return commandReplyMsg(reply)
+	}
+}
+
+func priceCommand(ctx context.Context, prices PriceQuerier, symbol string) (string, error) {
+	if prices == nil {
+		return "", fmt.Errorf("price data not available")
+	}
+	snapshots, err := prices.GetCurrentPrices(ctx)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, p := range snapshots {
+		if p == nil || (symbol != "" && p.Symbol != symbol) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-5s %s  24h %s  vol %s",
+			p.Symbol, domain.FormatPrice(p.Symbol, p.PriceUSD), p.Change24hPct, p.Volume24h.Compact()))
+	}
+	if len(lines) == 0 {
+		if symbol != "" {
+			return fmt.Sprintf("No price for %s yet.", symbol), nil
+		}
+		return "No prices yet.", nil
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func signalsCommand(ctx context.Context, signals SignalQuerier, filter domain.SignalFilter, loc *time.Location) (string, error) {
+	if signals == nil {
+		return "", fmt.Errorf("signal data not available")
+	}
+	list, err := signals.ListSignals(ctx, filter)
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		return "No matching signals right now.", nil
+	}
+	lines := make([]string, 0, len(list))
+	for _, s := range list {
+		line := fmt.Sprintf("%-5s %-3s %-14s %-5s risk %d  %s",
+			s.Symbol, s.Interval, s.Indicator, strings.ToUpper(string(s.Direction)),
+			s.Risk, s.Timestamp.In(loc).Format("Jan 02 15:04"))
+		if s.Details != "" {
+			line += "\n      " + s.Details
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func similarCommand(ctx context.Context, similar SimilarSetupQuerier, symbol, interval string, loc *time.Location) (string, error) {
+	if similar == nil {
+		return "", fmt.Errorf("similar setups not available")
+	}
+	if interval == "" {
+		interval = "1h"
+	}
+	result, err := similar.FindSimilar(ctx, symbol, interval, 8)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Matches) == 0 {
+		return fmt.Sprintf("No labeled history to compare %s with yet.", symbol), nil
+	}
+	lines := []string{fmt.Sprintf("%s %s at %s: %d of %d similar setups went up over %d bars",
+		result.Symbol, result.Interval, result.OpenTime.In(loc).Format("Jan 02 15:04"),
+		countUp(result.Matches), len(result.Matches), result.TargetHours)}
+	if result.MeanReturn != nil {
+		lines[0] += fmt.Sprintf(", mean %+.2f%%", *result.MeanReturn*100)
+	}
+	for _, m := range result.Matches {
+		outcome := "DOWN"
+		if m.WentUp {
+			outcome = "UP"
+		}
+		line := fmt.Sprintf("%-5s %s  sim %.2f  %-4s", m.Symbol, m.OpenTime.In(loc).Format("2006-01-02 15:04"), m.Similarity, outcome)
+		if m.RealizedReturn != nil {
+			line += fmt.Sprintf(" %+.2f%%", *m.RealizedReturn*100)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func countUp(matches []domain.SimilarSetup) int {
+	up := 0
+	for _, m := range matches {
+		if m.WentUp {
+			up++
+		}
+	}
+	return up
+}
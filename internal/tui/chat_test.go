@@ -1,7 +1,13 @@
 package tui
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -16,6 +22,48 @@ func TestChatModelInitialState(t *testing.T) {
 	}
 }
 
+type stubHistoryAdvisor struct {
+	stubAdvisorQuerier
+	chatID  int64
+	history []domain.ConversationMessage
+}
+
+func (s *stubHistoryAdvisor) History(ctx context.Context, chatID int64, limit int) ([]domain.ConversationMessage, error) {
+	s.chatID = chatID
+	return s.history, nil
+}
+
+func TestChatModelLoadsHistoryForUser(t *testing.T) {
+	advisor := &stubHistoryAdvisor{history: []domain.ConversationMessage{
+		{Role: "user", Content: "How is BTC?", CreatedAt: time.Now().Add(-48 * time.Hour)},
+		{Role: "assistant", Content: "Up 2% today.", CreatedAt: time.Now().Add(-48 * time.Hour)},
+	}}
+	svc := testServices()
+	svc.Advisor = advisor
+	m := NewChatModel(svc)
+	m.SetSize(120, 40)
+
+	msg := m.loadHistoryCmd()()
+	if advisor.chatID != svc.ChatID() {
+		t.Fatalf("expected history for chat %d, got %d", svc.ChatID(), advisor.chatID)
+	}
+	m.messages = []chatMessage{{Role: "user", Content: "typed early", Time: time.Now()}}
+	updated, _ := m.Update(msg)
+	if updated.MessageCount() != 3 || updated.messages[2].Content != "typed early" {
+		t.Fatalf("expected history before the new message, got %+v", updated.messages)
+	}
+	if !strings.Contains(updated.renderMessages(), "Up 2% today.") {
+		t.Fatal("expected history in the viewport")
+	}
+}
+
+func TestChatModelWithoutHistorySupport(t *testing.T) {
+	m := NewChatModel(testServices())
+	if m.loadHistoryCmd() != nil {
+		t.Fatal("expected no history load without ChatHistoryQuerier")
+	}
+}
+
 func TestChatModelSendMessage(t *testing.T) {
 	m := NewChatModel(testServices())
 	m.SetSize(120, 40)
@@ -76,3 +124,136 @@ func TestChatModelEmptyMessageIgnored(t *testing.T) {
 		t.Fatalf("expected 0 messages, got %d", updated.MessageCount())
 	}
 }
+
+func TestChatModelSlashCommandBypassesAdvisor(t *testing.T) {
+	svc := testServices()
+	svc.Prices = &stubPriceQuerier{prices: []*domain.PriceSnapshot{
+		{Symbol: "BTC", PriceUSD: 50000, Change24hPct: 1.5, Volume24h: 2e9},
+		{Symbol: "ETH", PriceUSD: 3000},
+	}}
+	svc.Advisor = &stubAdvisorQuerier{err: errors.New("advisor must not be called")}
+	m := NewChatModel(svc)
+	m.SetSize(120, 40)
+	m.input.SetValue("/price btc")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !updated.IsWaiting() {
+		t.Fatal("expected waiting while the command runs")
+	}
+	msg := updated.runCommandCmd(chatCommand{name: "price", symbol: "BTC"})()
+	reply, ok := msg.(commandReplyMsg)
+	if !ok {
+		t.Fatalf("expected command reply, got %T", msg)
+	}
+	if !strings.Contains(string(reply), "BTC") || !strings.Contains(string(reply), "$50,000") || strings.Contains(string(reply), "ETH") {
+		t.Fatalf("unexpected price reply %q", reply)
+	}
+
+	updated, _ = updated.Update(reply)
+	if updated.IsWaiting() || updated.MessageCount() != 2 {
+		t.Fatalf("expected reply appended, waiting=%v count=%d", updated.IsWaiting(), updated.MessageCount())
+	}
+}
+
+func TestChatModelUnknownSlashCommand(t *testing.T) {
+	m := NewChatModel(testServices())
+	m.SetSize(120, 40)
+	m.input.SetValue("/moon")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if updated.IsWaiting() || cmd != nil {
+		t.Fatal("expected unknown command to be rejected locally")
+	}
+	if updated.err == nil || !strings.Contains(updated.err.Error(), "/help") {
+		t.Fatalf("expected help hint, got %v", updated.err)
+	}
+}
+
+func TestParseChatCommand(t *testing.T) {
+	cmd, ok, err := parseChatCommand("/Predict sol")
+	if err != nil || !ok || cmd.name != "predict" || cmd.symbol != "SOL" {
+		t.Fatalf("unexpected parse: %+v %v %v", cmd, ok, err)
+	}
+	if _, ok, _ := parseChatCommand("what about BTC?"); ok {
+		t.Fatal("free-form text must go to the advisor")
+	}
+	if _, _, err := parseChatCommand("/signals XYZ"); err == nil {
+		t.Fatal("expected unknown symbol error")
+	}
+	if _, _, err := parseChatCommand("/similar"); err == nil {
+		t.Fatal("expected /similar to require a symbol")
+	}
+}
+
+func TestSimilarCommand(t *testing.T) {
+	if _, err := similarCommand(context.Background(), nil, "BTC", "", time.UTC); err == nil {
+		t.Fatal("expected an error without a similarity source")
+	}
+
+	ret := -0.012
+	querier := &stubSimilarQuerier{result: &domain.SimilarSetups{
+		Symbol:      "BTC",
+		Interval:    "1h",
+		OpenTime:    time.Date(2026, 3, 10, 11, 0, 0, 0, time.UTC),
+		TargetHours: 4,
+		Matches: []domain.SimilarSetup{
+			{Symbol: "ETH", OpenTime: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Similarity: 0.97, WentUp: true},
+			{Symbol: "SOL", OpenTime: time.Date(2026, 2, 20, 6, 0, 0, 0, time.UTC), Similarity: 0.91, RealizedReturn: &ret},
+		},
+	}}
+	reply, err := similarCommand(context.Background(), querier, "BTC", "", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if querier.interval != "1h" {
+		t.Fatalf("expected the default interval, got %q", querier.interval)
+	}
+	for _, want := range []string{"1 of 2 similar setups went up over 4 bars", "ETH   2026-03-02 00:00  sim 0.97  UP", "DOWN -1.20%"} {
+		if !strings.Contains(reply, want) {
+			t.Fatalf("expected %q in reply:\n%s", want, reply)
+		}
+	}
+
+	querier.result = &domain.SimilarSetups{Symbol: "BTC"}
+	if reply, _ := similarCommand(context.Background(), querier, "BTC", "4h", time.UTC); !strings.Contains(reply, "No labeled history") {
+		t.Fatalf("unexpected empty reply %q", reply)
+	}
+}
+
+type stubSimilarQuerier struct {
+	result   *domain.SimilarSetups
+	interval string
+}
+
+func (s *stubSimilarQuerier) FindSimilar(_ context.Context, _ string, interval string, _ int) (*domain.SimilarSetups, error) {
+	s.interval = interval
+	return s.result, nil
+}
+
+func TestSignalsCommandFormatsPredictions(t *testing.T) {
+	signals := &stubSignalQuerier{signals: []domain.Signal{{
+		Symbol:    "BTC",
+		Interval:  "1h",
+		Indicator: domain.IndicatorMLEnsembleUp4H,
+		Direction: domain.DirectionLong,
+		Risk:      domain.RiskLevel2,
+		Details:   "prob_up=0.71",
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+	}}}
+	reply, err := signalsCommand(context.Background(), signals, domain.SignalFilter{Indicator: domain.IndicatorMLEnsembleUp4H}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(reply, "LONG") || !strings.Contains(reply, "prob_up=0.71") {
+		t.Fatalf("unexpected reply %q", reply)
+	}
+	if !strings.Contains(reply, "Mar 01 12:00") {
+		t.Fatalf("expected the time in UTC, got %q", reply)
+	}
+
+	tokyo, _ := domain.LoadTimezone("Asia/Tokyo")
+	reply, _ = signalsCommand(context.Background(), signals, domain.SignalFilter{}, Services{Location: tokyo}.Timezone())
+	if !strings.Contains(reply, "Mar 01 21:00") {
+		t.Fatalf("expected the time in Tokyo time, got %q", reply)
+	}
+}
@@ -6,48 +6,60 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 // FormatPrice renders a price snapshot as a single line.
-func FormatPrice(p *domain.PriceSnapshot) string {
-	changeStyle := PriceZeroStyle
-	if p.Change24hPct > 0 {
-		changeStyle = PriceUpStyle
-	} else if p.Change24hPct < 0 {
-		changeStyle = PriceDownStyle
-	}
-
-	sign := ""
-	if p.Change24hPct > 0 {
-		sign = "+"
-	}
-
+func (st *Styles) FormatPrice(p *domain.PriceSnapshot) string {
 	return fmt.Sprintf("%-6s %10s  %s  Vol: %s",
 		p.Symbol,
-		formatUSD(p.PriceUSD),
-		changeStyle.Render(fmt.Sprintf("%s%.1f%%", sign, p.Change24hPct)),
-		formatVolume(p.Volume24h),
+		domain.FormatPrice(p.Symbol, p.PriceUSD),
+		st.formatChange(p.Change24hPct),
+		p.Volume24h.Compact(),
 	)
 }
 
-// FormatSignal renders a signal as a single line.
-func FormatSignal(s domain.Signal) string {
-	dirStyle := DirectionHoldStyle
+// FormatGlobalMarket renders the whole-market snapshot as a single line.
+func (st *Styles) FormatGlobalMarket(g *domain.GlobalMarketSnapshot) string {
+	return fmt.Sprintf("Mcap %s  %s  BTC dom %.1f%%  ETH dom %.1f%%",
+		domain.Money(g.TotalMarketCapUSD).Compact(),
+		st.formatChange(domain.Percent(g.MarketCapChange24hPct)),
+		g.BTCDominancePct,
+		g.ETHDominancePct,
+	)
+}
+
+// formatChange renders a 24h change colored by its rounded sign, so a move
+// shown as 0.0% is not colored.
+func (st *Styles) formatChange(p domain.Percent) string {
+	change := p.Format(1)
+	changeStyle := st.PriceZeroStyle
+	switch change[0] {
+	case '+':
+		changeStyle = st.PriceUpStyle
+	case '-':
+		changeStyle = st.PriceDownStyle
+	}
+	return changeStyle.Render(change)
+}
+
+// FormatSignal renders a signal as a single line, with its time in loc.
+func (st *Styles) FormatSignal(s domain.Signal, loc *time.Location) string {
+	dirStyle := st.DirectionHoldStyle
 	switch s.Direction {
 	case domain.DirectionLong:
-		dirStyle = DirectionLongStyle
+		dirStyle = st.DirectionLongStyle
 	case domain.DirectionShort:
-		dirStyle = DirectionShortStyle
+		dirStyle = st.DirectionShortStyle
 	}
 
-	riskStyle := RiskLowStyle
+	riskStyle := st.RiskLowStyle
 	if s.Risk >= 4 {
-		riskStyle = RiskHighStyle
+		riskStyle = st.RiskHighStyle
 	} else if s.Risk >= 3 {
-		riskStyle = RiskMedStyle
+		riskStyle = st.RiskMedStyle
 	}
 
 	return fmt.Sprintf("#%-4d %-5s %-3s %-10s %s risk %s  %s",
@@ -57,14 +69,20 @@ func FormatSignal(s domain.Signal) string {
 		strings.ToUpper(s.Indicator),
 		dirStyle.Render(strings.ToUpper(string(s.Direction))),
 		riskStyle.Render(fmt.Sprintf("%d", s.Risk)),
-		s.Timestamp.Format(time.RFC822),
+		s.Timestamp.In(loc).Format(time.RFC822),
 	)
 }
 
 // RenderHeatMap renders a colored grid showing 24h change for each symbol.
-func RenderHeatMap(prices []*domain.PriceSnapshot, width int) string {
+func (st *Styles) RenderHeatMap(prices []*domain.PriceSnapshot, width int) string {
+	return st.RenderHeatMapBy(prices, width, HeatChange, HeatInputs{})
+}
+
+// RenderHeatMapBy renders the heat map colored by metric. Metrics other than
+// HeatChange also order the cells, most significant first.
+func (st *Styles) RenderHeatMapBy(prices []*domain.PriceSnapshot, width int, metric HeatMetric, in HeatInputs) string {
 	if len(prices) == 0 {
-		return SubtextStyle.Render("No price data")
+		return st.SubtextStyle.Render("No price data")
 	}
 
 	cellWidth := 8
@@ -73,26 +91,39 @@ func RenderHeatMap(prices []*domain.PriceSnapshot, width int) string {
 		cols = 1
 	}
 
+	cells := heatCells(prices, metric, in)
 	var rows []string
 	var row []string
-	for i, p := range prices {
-		bg := HeatNeutral
-		if p.Change24hPct > 0 {
-			bg = heatColorScale(p.Change24hPct, 10, HeatGreen)
-		} else if p.Change24hPct < 0 {
-			bg = heatColorScale(-p.Change24hPct, 10, HeatRed)
+	for i, c := range cells {
+		bg := st.HeatNeutral
+		if c.ok && c.score > 0 {
+			bg = st.heatColorScale(c.score, c.scale, st.HeatGreen)
+		} else if c.ok && c.score < 0 {
+			bg = st.heatColorScale(-c.score, c.scale, st.HeatRed)
 		}
 
+		label := c.symbol
+		if st.heatMarkers {
+			// Without colors the direction has to be spelled out.
+			switch {
+			case !c.ok:
+				label += "?"
+			case c.score > 0:
+				label += "▲"
+			case c.score < 0:
+				label += "▼"
+			}
+		}
 		cell := lipgloss.NewStyle().
 			Background(bg).
-			Foreground(lipgloss.Color("#000000")).
+			Foreground(st.HeatTextColor).
 			Bold(true).
 			Width(cellWidth - 1).
 			Align(lipgloss.Center).
-			Render(p.Symbol)
+			Render(label)
 
 		row = append(row, cell)
-		if (i+1)%cols == 0 || i == len(prices)-1 {
+		if (i+1)%cols == 0 || i == len(cells)-1 {
 			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, row...))
 			row = nil
 		}
@@ -102,7 +133,7 @@ func RenderHeatMap(prices []*domain.PriceSnapshot, width int) string {
 }
 
 // RenderBarChart renders an ASCII bar chart of accuracy values.
-func RenderBarChart(label string, accuracy float64, barWidth int) string {
+func (st *Styles) RenderBarChart(label string, accuracy float64, barWidth int) string {
 	if barWidth <= 0 {
 		barWidth = 20
 	}
@@ -112,65 +143,97 @@ func RenderBarChart(label string, accuracy float64, barWidth int) string {
 	}
 	empty := barWidth - filled
 
-	style := AccuracyGoodStyle
+	style := st.AccuracyGoodStyle
 	if accuracy < 0.6 {
-		style = AccuracyBadStyle
+		style = st.AccuracyBadStyle
 	} else if accuracy < 0.75 {
-		style = AccuracyOkStyle
+		style = st.AccuracyOkStyle
 	}
 
-	bar := style.Render(strings.Repeat("█", filled)) + SubtextStyle.Render(strings.Repeat("░", empty))
+	bar := style.Render(strings.Repeat("█", filled)) + st.SubtextStyle.Render(strings.Repeat("░", empty))
 	return fmt.Sprintf("%-20s %s %.1f%%", label, bar, accuracy*100)
 }
 
-// heatColorScale produces a color scaled by magnitude.
-func heatColorScale(magnitude, maxMagnitude float64, baseColor lipgloss.Color) lipgloss.Color {
-	intensity := magnitude / maxMagnitude
-	if intensity > 1 {
-		intensity = 1
+// RenderReliabilityBar draws one calibration bucket: the bar fills to the
+// realized frequency and │ marks the mean predicted probability, so a well
+// calibrated bucket ends right at its marker.
+func (st *Styles) RenderReliabilityBar(predicted, realized float64, barWidth int) string {
+	if barWidth <= 0 {
+		barWidth = 20
 	}
-	if intensity < 0.1 {
-		return HeatNeutral
+	clamp := func(v float64) int {
+		n := int(math.Round(v * float64(barWidth)))
+		return max(0, min(barWidth, n))
+	}
+	filled := clamp(realized)
+	marker := min(clamp(predicted), barWidth-1)
+
+	style := st.AccuracyGoodStyle
+	if gap := math.Abs(realized - predicted); gap >= 0.1 {
+		style = st.AccuracyBadStyle
+	} else if gap >= 0.05 {
+		style = st.AccuracyOkStyle
+	}
+
+	var sb strings.Builder
+	for i := 0; i < barWidth; i++ {
+		switch {
+		case i == marker:
+			sb.WriteString(st.HeaderStyle.Render("│"))
+		case i < filled:
+			sb.WriteString(style.Render("█"))
+		default:
+			sb.WriteString(st.SubtextStyle.Render("░"))
+		}
 	}
-	return baseColor
+	return sb.String()
 }
 
-func formatUSD(v float64) string {
-	if v >= 1000 {
-		return "$" + addCommas(fmt.Sprintf("%.0f", v))
-	}
-	if v >= 1 {
-		return fmt.Sprintf("$%.2f", v)
-	}
-	return fmt.Sprintf("$%.4f", v)
-}
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
 
-func addCommas(s string) string {
-	n := len(s)
-	if n <= 3 {
-		return s
+// RenderSparkline draws values as a one-line block chart scaled between their
+// minimum and maximum. A flat series sits mid-height.
+func RenderSparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
 	}
-	var result strings.Builder
-	for i, ch := range s {
-		if i > 0 && (n-i)%3 == 0 {
-			result.WriteByte(',')
+	var sb strings.Builder
+	for _, v := range values {
+		idx := len(sparkBlocks) / 2
+		if hi > lo {
+			idx = int(math.Round((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1)))
 		}
-		result.WriteRune(ch)
+		sb.WriteRune(sparkBlocks[idx])
 	}
-	return result.String()
+	return sb.String()
 }
 
-func formatVolume(v float64) string {
+// RenderTrendArrow marks a change in accuracy (fraction, e.g. 0.03 for three
+// points); moves under threshold show as flat.
+func (st *Styles) RenderTrendArrow(delta, threshold float64) string {
 	switch {
-	case v >= 1e12:
-		return fmt.Sprintf("$%.1fT", v/1e12)
-	case v >= 1e9:
-		return fmt.Sprintf("$%.1fB", v/1e9)
-	case v >= 1e6:
-		return fmt.Sprintf("$%.1fM", v/1e6)
-	case v >= 1e3:
-		return fmt.Sprintf("$%.1fK", v/1e3)
+	case delta >= threshold:
+		return st.AccuracyGoodStyle.Render(fmt.Sprintf("▲ %+.1fpp", delta*100))
+	case delta <= -threshold:
+		return st.AccuracyBadStyle.Render(fmt.Sprintf("▼ %+.1fpp", delta*100))
 	default:
-		return fmt.Sprintf("$%.0f", v)
+		return st.SubtextStyle.Render(fmt.Sprintf("▶ %+.1fpp", delta*100))
 	}
 }
+
+// heatColorScale produces a color scaled by magnitude.
+func (st *Styles) heatColorScale(magnitude, maxMagnitude float64, baseColor lipgloss.TerminalColor) lipgloss.TerminalColor {
+	intensity := magnitude / maxMagnitude
+	if intensity > 1 {
+		intensity = 1
+	}
+	if intensity < 0.1 {
+		return st.HeatNeutral
+	}
+	return baseColor
+}
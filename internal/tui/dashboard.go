@@ -6,8 +6,10 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -17,24 +19,47 @@ type pricesMsg []*domain.PriceSnapshot
 type pricesErrMsg struct{ err error }
 type signalsMsg []domain.Signal
 type signalsErrMsg struct{ err error }
+type globalMarketMsg struct{ snap *domain.GlobalMarketSnapshot }
 type dashTickMsg time.Time
+type marketEventMsg events.MarketEvent
+type marketEventsClosedMsg struct{}
+type dashRefreshMsg struct{}
+
+const (
+	// dashPollInterval refreshes the dashboard when no event stream is set.
+	dashPollInterval = 10 * time.Second
+	// dashFallbackInterval catches anything missed while events are flowing.
+	dashFallbackInterval = time.Minute
+	// dashEventDebounce folds the burst of events after a candle close (one
+	// per symbol and interval) into a single refetch.
+	dashEventDebounce = 500 * time.Millisecond
+)
 
 // DashboardModel is the Bubble Tea model for the live dashboard screen.
 type DashboardModel struct {
-	services Services
-	prices   []*domain.PriceSnapshot
-	signals  []domain.Signal
-	loading  bool
-	err      error
-	width    int
-	height   int
+	services  Services
+	styles    *Styles
+	prices    []*domain.PriceSnapshot
+	signals   []domain.Signal
+	global    *domain.GlobalMarketSnapshot
+	watchlist []string // empty shows all symbols
+	heat      HeatMetric
+	heatIn    HeatInputs
+	loading   bool
+	live      bool // receiving market events
+	pending   bool // refetch scheduled after an event
+	err       error
+	width     int
+	height    int
 }
 
 // NewDashboardModel creates a new dashboard model.
 func NewDashboardModel(svc Services) DashboardModel {
 	return DashboardModel{
 		services: svc,
+		styles:   defaultStyles(),
 		loading:  true,
+		live:     svc.MarketEvents != nil,
 	}
 }
 
@@ -43,7 +68,9 @@ func (m DashboardModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.fetchPricesCmd(),
 		m.fetchSignalsCmd(),
+		m.fetchGlobalMarketCmd(),
 		m.tickCmd(),
+		m.waitForEventCmd(),
 	)
 }
 
@@ -69,12 +96,53 @@ func (m DashboardModel) Update(msg tea.Msg) (DashboardModel, tea.Cmd) {
 		// Non-critical; prices are more important.
 		return m, nil
 
+	case globalMarketMsg:
+		m.global = msg.snap
+		return m, nil
+
+	case watchlistMsg:
+		m.watchlist = []string(msg)
+		return m, tea.Batch(m.fetchSignalsCmd(), m.fetchHeatInputsCmd())
+
 	case dashTickMsg:
 		return m, tea.Batch(
 			m.fetchPricesCmd(),
 			m.fetchSignalsCmd(),
+			m.fetchHeatInputsCmd(),
+			m.fetchGlobalMarketCmd(),
 			m.tickCmd(),
 		)
+
+	case marketEventMsg:
+		if m.pending {
+			return m, m.waitForEventCmd()
+		}
+		m.pending = true
+		return m, tea.Batch(
+			tea.Tick(dashEventDebounce, func(time.Time) tea.Msg { return dashRefreshMsg{} }),
+			m.waitForEventCmd(),
+		)
+
+	case dashRefreshMsg:
+		m.pending = false
+		return m, tea.Batch(m.fetchPricesCmd(), m.fetchSignalsCmd())
+
+	case heatInputsMsg:
+		if msg.metric == m.heat {
+			m.heatIn = msg.inputs
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if key.Matches(msg, DefaultKeyMap.HeatMetric) {
+			return m, m.setHeatMetric(m.heat.Next())
+		}
+		return m, nil
+
+	case marketEventsClosedMsg:
+		// Back to plain polling; the next tick uses the short interval.
+		m.live = false
+		return m, nil
 	}
 
 	return m, nil
@@ -83,10 +151,10 @@ func (m DashboardModel) Update(msg tea.Msg) (DashboardModel, tea.Cmd) {
 // View renders the dashboard.
 func (m DashboardModel) View() string {
 	if m.loading && len(m.prices) == 0 {
-		return SubtextStyle.Render("Loading prices...")
+		return m.styles.SubtextStyle.Render("Loading prices...")
 	}
 	if m.err != nil && len(m.prices) == 0 {
-		return ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+		return m.styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err))
 	}
 
 	var sections []string
@@ -104,15 +172,15 @@ func (m DashboardModel) View() string {
 		heatWidth = 15
 	}
 
-	priceBox := BorderStyle.Width(priceWidth).Render(priceTable)
-	heatBox := BorderStyle.Width(heatWidth).Render(heatMap)
+	priceBox := m.styles.BorderStyle.Width(priceWidth).Render(priceTable)
+	heatBox := m.styles.BorderStyle.Width(heatWidth).Render(heatMap)
 
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top, priceBox, heatBox)
 	sections = append(sections, topRow)
 
 	// Active signals
 	signalSection := m.renderSignals()
-	signalBox := BorderStyle.Width(m.width - 2).Render(signalSection)
+	signalBox := m.styles.BorderStyle.Width(m.width - 2).Render(signalSection)
 	sections = append(sections, signalBox)
 
 	return lipgloss.JoinVertical(lipgloss.Left, sections...)
@@ -130,36 +198,61 @@ func (m DashboardModel) Prices() []*domain.PriceSnapshot { return m.prices }
 // Signals returns the current signals (for testing).
 func (m DashboardModel) Signals() []domain.Signal { return m.signals }
 
+// setHeatMetric switches the heat map to metric and fetches what it needs.
+func (m *DashboardModel) setHeatMetric(metric HeatMetric) tea.Cmd {
+	if metric == m.heat {
+		return nil
+	}
+	m.heat = metric
+	m.heatIn = HeatInputs{}
+	return m.fetchHeatInputsCmd()
+}
+
+// HeatMetric returns the active heat map metric (for testing).
+func (m DashboardModel) HeatMetric() HeatMetric { return m.heat }
+
 func (m DashboardModel) renderPriceTable() string {
-	header := HeaderStyle.Render("  Live Prices")
+	header := m.styles.HeaderStyle.Render("  Live Prices")
+	if len(m.watchlist) > 0 {
+		header += m.styles.SubtextStyle.Render("  watchlist [w] edit")
+	} else {
+		header += m.styles.SubtextStyle.Render("  [w] pin symbols")
+	}
 	var lines []string
 	lines = append(lines, header)
-	lines = append(lines, SubtextStyle.Render("  Symbol       Price      24h       Volume"))
-	lines = append(lines, SubtextStyle.Render(strings.Repeat("─", 55)))
+	if m.global != nil {
+		lines = append(lines, "  "+m.styles.FormatGlobalMarket(m.global))
+	}
+	lines = append(lines, m.styles.SubtextStyle.Render("  Symbol       Price      24h       Volume"))
+	lines = append(lines, m.styles.SubtextStyle.Render(strings.Repeat("─", 55)))
 
-	for _, p := range m.prices {
-		lines = append(lines, "  "+FormatPrice(p))
+	prices := filterPrices(m.prices, m.watchlist)
+	for _, p := range prices {
+		lines = append(lines, "  "+m.styles.FormatPrice(p))
 	}
 
-	if len(m.prices) == 0 {
-		lines = append(lines, SubtextStyle.Render("  No price data available"))
+	if len(prices) == 0 {
+		lines = append(lines, m.styles.SubtextStyle.Render("  No price data available"))
 	}
 
 	return strings.Join(lines, "\n")
 }
 
 func (m DashboardModel) renderHeatMapSection() string {
-	header := HeaderStyle.Render("  Heat Map")
+	header := m.styles.HeaderStyle.Render("  Heat Map") + m.styles.SubtextStyle.Render("  "+m.heat.String())
 	heatWidth := m.width/3 - 4
 	if heatWidth < 15 {
 		heatWidth = 15
 	}
-	heatMap := RenderHeatMap(m.prices, heatWidth)
-	return header + "\n" + heatMap
+	heatMap := m.styles.RenderHeatMapBy(filterPrices(m.prices, m.watchlist), heatWidth, m.heat, m.heatIn)
+	return header + "\n" + heatMap + "\n" + m.styles.SubtextStyle.Render("  [h] weighting")
 }
 
 func (m DashboardModel) renderSignals() string {
-	header := HeaderStyle.Render("  Active Signals")
+	header := m.styles.HeaderStyle.Render("  Active Signals")
+	if m.live {
+		header += m.styles.SubtextStyle.Render("  live")
+	}
 	var lines []string
 	lines = append(lines, header)
 
@@ -169,11 +262,11 @@ func (m DashboardModel) renderSignals() string {
 	}
 
 	for i := 0; i < count; i++ {
-		lines = append(lines, "  "+FormatSignal(m.signals[i]))
+		lines = append(lines, "  "+m.styles.FormatSignal(m.signals[i], m.services.Timezone()))
 	}
 
 	if len(m.signals) == 0 {
-		lines = append(lines, SubtextStyle.Render("  No active signals"))
+		lines = append(lines, m.styles.SubtextStyle.Render("  No active signals"))
 	}
 
 	return strings.Join(lines, "\n")
@@ -193,20 +286,69 @@ func (m DashboardModel) fetchPricesCmd() tea.Cmd {
 }
 
 func (m DashboardModel) fetchSignalsCmd() tea.Cmd {
+	watchlist := m.watchlist
 	return func() tea.Msg {
 		if m.services.Signals == nil {
 			return signalsErrMsg{err: fmt.Errorf("signal service not available")}
 		}
-		signals, err := m.services.Signals.ListSignals(context.Background(), domain.SignalFilter{Limit: 10})
+		// The filter takes one symbol, so widen the query and narrow locally.
+		limit := 10
+		if len(watchlist) > 0 {
+			limit = 100
+		}
+		signals, err := m.services.Signals.ListSignals(context.Background(), domain.SignalFilter{Limit: limit})
 		if err != nil {
 			return signalsErrMsg{err: err}
 		}
-		return signalsMsg(signals)
+		return signalsMsg(filterSignals(signals, watchlist))
+	}
+}
+
+// fetchGlobalMarketCmd keeps the last snapshot when the lookup fails, so
+// a database hiccup does not blank the line.
+func (m DashboardModel) fetchGlobalMarketCmd() tea.Cmd {
+	if m.services.GlobalMarket == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		snap, err := m.services.GlobalMarket.LatestGlobalMarket(context.Background())
+		if err != nil || snap == nil {
+			return nil
+		}
+		return globalMarketMsg{snap: snap}
 	}
 }
 
+func (m DashboardModel) fetchHeatInputsCmd() tea.Cmd {
+	symbols := m.watchlist
+	if len(symbols) == 0 {
+		symbols = domain.SupportedSymbols()
+	}
+	return fetchHeatInputsCmd(m.services, m.heat, symbols)
+}
+
 func (m DashboardModel) tickCmd() tea.Cmd {
-	return tea.Tick(10*time.Second, func(t time.Time) tea.Msg {
+	interval := dashPollInterval
+	if m.live {
+		interval = dashFallbackInterval
+	}
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
 		return dashTickMsg(t)
 	})
 }
+
+// waitForEventCmd blocks for the next market event. It is re-issued after
+// every event so exactly one read is outstanding.
+func (m DashboardModel) waitForEventCmd() tea.Cmd {
+	ch := m.services.MarketEvents
+	if ch == nil || !m.live {
+		return nil
+	}
+	return func() tea.Msg {
+		evt, ok := <-ch
+		if !ok {
+			return marketEventsClosedMsg{}
+		}
+		return marketEventMsg(evt)
+	}
+}
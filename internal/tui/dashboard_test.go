@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"context"
+	"strings"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
+	"bug-free-umbrella/pkg/domain"
 )
 
 func TestDashboardUpdatePricesMsg(t *testing.T) {
@@ -66,3 +69,94 @@ func TestDashboardViewWithData(t *testing.T) {
 		t.Fatal("expected non-empty view with data")
 	}
 }
+
+func TestDashboardShowsGlobalMarket(t *testing.T) {
+	svc := testServices()
+	svc.GlobalMarket = stubGlobalMarket{snap: &domain.GlobalMarketSnapshot{
+		TotalMarketCapUSD:     2.41e12,
+		BTCDominancePct:       54.23,
+		ETHDominancePct:       17.3,
+		MarketCapChange24hPct: -1.25,
+	}}
+	m := NewDashboardModel(svc)
+	m.SetSize(120, 40)
+	m.loading = false
+	m.prices = []*domain.PriceSnapshot{{Symbol: "BTC", PriceUSD: 98000}}
+	if strings.Contains(m.View(), "BTC dom") {
+		t.Fatal("expected no global market line before it is fetched")
+	}
+
+	updated, _ := m.Update(m.fetchGlobalMarketCmd()())
+	view := updated.View()
+	if !strings.Contains(view, "Mcap $2.4T") || !strings.Contains(view, "-1.3%") || !strings.Contains(view, "BTC dom 54.2%") {
+		t.Fatalf("expected the global market line, got:\n%s", view)
+	}
+}
+
+type stubGlobalMarket struct {
+	snap *domain.GlobalMarketSnapshot
+}
+
+func (s stubGlobalMarket) LatestGlobalMarket(context.Context) (*domain.GlobalMarketSnapshot, error) {
+	return s.snap, nil
+}
+
+func TestDashboardWatchlistFiltersPrices(t *testing.T) {
+	m := NewDashboardModel(testServices())
+	m.SetSize(120, 40)
+	m.loading = false
+	m.prices = []*domain.PriceSnapshot{
+		{Symbol: "BTC", PriceUSD: 98000},
+		{Symbol: "DOGE", PriceUSD: 0.12},
+	}
+
+	updated, _ := m.Update(watchlistMsg{"DOGE"})
+	view := updated.renderPriceTable()
+	if !strings.Contains(view, "DOGE") || strings.Contains(view, "BTC") {
+		t.Fatalf("expected only DOGE in price table:\n%s", view)
+	}
+}
+
+func TestDashboardMarketEventsDebounceRefresh(t *testing.T) {
+	ch := make(chan events.MarketEvent, 2)
+	svc := testServices()
+	svc.MarketEvents = ch
+	m := NewDashboardModel(svc)
+
+	ch <- events.MarketEvent{Type: events.MarketSignals}
+	msg := m.waitForEventCmd()()
+	if _, ok := msg.(marketEventMsg); !ok {
+		t.Fatalf("expected market event, got %T", msg)
+	}
+
+	updated, cmd := m.Update(msg)
+	if !updated.pending || cmd == nil {
+		t.Fatal("expected a debounced refresh to be scheduled")
+	}
+	// A second event inside the debounce window only re-arms the listener.
+	updated, _ = updated.Update(marketEventMsg{Type: events.MarketCandleClosed})
+	if !updated.pending {
+		t.Fatal("expected refresh to stay pending")
+	}
+	updated, cmd = updated.Update(dashRefreshMsg{})
+	if updated.pending || cmd == nil {
+		t.Fatal("expected refresh to fetch and clear pending")
+	}
+}
+
+func TestDashboardMarketEventsClosedFallsBackToPolling(t *testing.T) {
+	ch := make(chan events.MarketEvent)
+	close(ch)
+	svc := testServices()
+	svc.MarketEvents = ch
+	m := NewDashboardModel(svc)
+	if !m.live {
+		t.Fatal("expected live dashboard with an event stream")
+	}
+
+	msg := m.waitForEventCmd()()
+	updated, _ := m.Update(msg)
+	if updated.live || updated.waitForEventCmd() != nil {
+		t.Fatal("expected polling only after the stream closed")
+	}
+}
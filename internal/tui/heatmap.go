@@ -0,0 +1,219 @@
+package tui
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/pkg/domain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HeatMetric selects what the heat map colors represent.
+type HeatMetric int
+
+const (
+	// HeatChange colors by raw 24h change.
+	HeatChange HeatMetric = iota
+	// HeatVolume damps moves on thin volume and orders cells by volume.
+	HeatVolume
+	// HeatVolatility colors by the 24h change in units of the symbol's usual
+	// daily move, so a 3% BTC move outranks a 3% DOGE move.
+	HeatVolatility
+	// HeatConfidence colors by the latest ML ensemble probability of a rise.
+	HeatConfidence
+)
+
+var heatMetricNames = []string{"24h change", "volume-weighted", "volatility-adjusted", "ML confidence"}
+
+// heatMetricKeys name the metrics in saved TUI state.
+var heatMetricKeys = []string{"change", "volume", "volatility", "confidence"}
+
+func (h HeatMetric) String() string {
+	if h < 0 || int(h) >= len(heatMetricNames) {
+		return "unknown"
+	}
+	return heatMetricNames[h]
+}
+
+// Next cycles to the following metric.
+func (h HeatMetric) Next() HeatMetric {
+	return HeatMetric((int(h) + 1) % len(heatMetricNames))
+}
+
+// HeatInputs carries the per-symbol data the non-default metrics need.
+// Symbols missing from a map are drawn neutral.
+type HeatInputs struct {
+	// DailyVolPct is the typical daily move in percent.
+	DailyVolPct map[string]float64
+	// ProbUp is the latest ensemble probability of a rise over 4h.
+	ProbUp map[string]float64
+}
+
+// CandleQuerier is optionally implemented by the price service. The
+// volatility heat map needs it.
+type CandleQuerier interface {
+	GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error)
+}
+
+type heatInputsMsg struct {
+	metric HeatMetric
+	inputs HeatInputs
+}
+
+// Scales at which a cell reaches full intensity.
+const (
+	heatChangeScale     = 10.0 // percent
+	heatVolatilityScale = 3.0  // daily moves
+	heatConfidenceScale = 0.5  // prob_up 0.75 or 0.25
+)
+
+type heatCell struct {
+	symbol string
+	score  float64 // signed; the sign picks the color
+	scale  float64
+	rank   float64 // larger first when the metric orders cells
+	ok     bool    // false when the metric has no data for the symbol
+}
+
+func heatCells(prices []*domain.PriceSnapshot, metric HeatMetric, in HeatInputs) []heatCell {
+	maxVolume := 0.0
+	for _, p := range prices {
+		if p != nil && float64(p.Volume24h) > maxVolume {
+			maxVolume = float64(p.Volume24h)
+		}
+	}
+
+	cells := make([]heatCell, 0, len(prices))
+	for _, p := range prices {
+		if p == nil {
+			continue
+		}
+		change := float64(p.Change24hPct)
+		c := heatCell{symbol: p.Symbol, score: change, scale: heatChangeScale, ok: true}
+		switch metric {
+		case HeatVolume:
+			share := 0.0
+			if maxVolume > 0 {
+				share = float64(p.Volume24h) / maxVolume
+			}
+			c.score = change * math.Sqrt(share)
+			c.rank = float64(p.Volume24h)
+		case HeatVolatility:
+			vol, ok := in.DailyVolPct[p.Symbol]
+			c.ok = ok && vol > 0
+			if c.ok {
+				c.score = change / vol
+			}
+			c.scale = heatVolatilityScale
+			c.rank = math.Abs(c.score)
+		case HeatConfidence:
+			prob, ok := in.ProbUp[p.Symbol]
+			c.ok = ok
+			c.score = 0
+			if ok {
+				c.score = prob - 0.5
+			}
+			c.scale = heatConfidenceScale
+			c.rank = math.Abs(c.score)
+		}
+		if !c.ok {
+			c.rank = -1
+		}
+		cells = append(cells, c)
+	}
+
+	if metric != HeatChange {
+		sort.SliceStable(cells, func(i, j int) bool { return cells[i].rank > cells[j].rank })
+	}
+	return cells
+}
+
+// fetchHeatInputsCmd loads what metric needs beyond the price snapshots, or
+// returns nil when it needs nothing.
+func fetchHeatInputsCmd(svc Services, metric HeatMetric, symbols []string) tea.Cmd {
+	switch metric {
+	case HeatVolatility:
+		candles, ok := svc.Prices.(CandleQuerier)
+		if !ok {
+			return nil
+		}
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			vols := make(map[string]float64, len(symbols))
+			for _, symbol := range symbols {
+				list, err := candles.GetCandles(ctx, symbol, "1h", 25)
+				if err != nil {
+					continue
+				}
+				if vol, ok := dailyVolatilityPct(list); ok {
+					vols[symbol] = vol
+				}
+			}
+			return heatInputsMsg{metric: metric, inputs: HeatInputs{DailyVolPct: vols}}
+		}
+	case HeatConfidence:
+		if svc.Backtest == nil {
+			return nil
+		}
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			preds, err := svc.Backtest.ListRecentPredictions(ctx, 200)
+			if err != nil {
+				return heatInputsMsg{metric: metric}
+			}
+			return heatInputsMsg{metric: metric, inputs: HeatInputs{ProbUp: latestProbUp(preds)}}
+		}
+	}
+	return nil
+}
+
+// dailyVolatilityPct estimates the typical daily move from hourly candles as
+// the standard deviation of hourly returns scaled by sqrt(24).
+func dailyVolatilityPct(candles []*domain.Candle) (float64, bool) {
+	var returns []float64
+	for i := 1; i < len(candles); i++ {
+		prev, cur := candles[i-1], candles[i]
+		if prev == nil || cur == nil || prev.Close <= 0 || cur.Close <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(cur.Close/prev.Close))
+	}
+	if len(returns) < 2 {
+		return 0, false
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+	vol := math.Sqrt(variance) * math.Sqrt(24) * 100
+	return vol, vol > 0
+}
+
+// latestProbUp keeps the newest ensemble prediction per symbol.
+func latestProbUp(preds []domain.MLPrediction) map[string]float64 {
+	out := make(map[string]float64)
+	newest := make(map[string]time.Time)
+	for _, p := range preds {
+		if p.ModelKey != common.ModelKeyEnsembleV1 {
+			continue
+		}
+		if seen, ok := newest[p.Symbol]; ok && !p.OpenTime.After(seen) {
+			continue
+		}
+		newest[p.Symbol] = p.OpenTime
+		out[p.Symbol] = p.ProbUp
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/internal/ml/common"
+	"bug-free-umbrella/pkg/domain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type stubCandlePrices struct {
+	stubPriceQuerier
+	candles map[string][]*domain.Candle
+}
+
+func (s *stubCandlePrices) GetCandles(ctx context.Context, symbol, interval string, limit int) ([]*domain.Candle, error) {
+	return s.candles[symbol], nil
+}
+
+func TestHeatCellsVolumeOrdersByVolume(t *testing.T) {
+	cells := heatCells([]*domain.PriceSnapshot{
+		{Symbol: "DOGE", Change24hPct: 12, Volume24h: 1e8},
+		{Symbol: "BTC", Change24hPct: 2, Volume24h: 4e10},
+	}, HeatVolume, HeatInputs{})
+	if cells[0].symbol != "BTC" {
+		t.Fatalf("expected BTC first, got %s", cells[0].symbol)
+	}
+	// A 12% move on 0.25% of BTC's volume is damped to well under 1%.
+	if cells[1].score >= 1 {
+		t.Fatalf("expected thin-volume move damped, got %.2f", cells[1].score)
+	}
+}
+
+func TestHeatCellsVolatilityAdjusts(t *testing.T) {
+	cells := heatCells([]*domain.PriceSnapshot{
+		{Symbol: "DOGE", Change24hPct: 3},
+		{Symbol: "BTC", Change24hPct: 3},
+		{Symbol: "SOL", Change24hPct: 3},
+	}, HeatVolatility, HeatInputs{DailyVolPct: map[string]float64{"DOGE": 6, "BTC": 1.5}})
+	if cells[0].symbol != "BTC" || cells[0].score != 2 {
+		t.Fatalf("expected BTC first at 2 daily moves, got %+v", cells[0])
+	}
+	if cells[2].symbol != "SOL" || cells[2].ok {
+		t.Fatalf("expected SOL without data last, got %+v", cells[2])
+	}
+}
+
+func TestHeatCellsConfidence(t *testing.T) {
+	cells := heatCells([]*domain.PriceSnapshot{{Symbol: "ETH", Change24hPct: 5}},
+		HeatConfidence, HeatInputs{ProbUp: map[string]float64{"ETH": 0.3}})
+	if math.Abs(cells[0].score-(-0.2)) > 1e-9 {
+		t.Fatalf("expected bearish score, got %.2f", cells[0].score)
+	}
+}
+
+func TestDailyVolatilityPct(t *testing.T) {
+	flat := []*domain.Candle{{Close: 100}, {Close: 100}, {Close: 100}}
+	if _, ok := dailyVolatilityPct(flat); ok {
+		t.Fatal("expected no volatility for flat candles")
+	}
+	moving := []*domain.Candle{{Close: 100}, {Close: 101}, {Close: 100}, {Close: 101}}
+	vol, ok := dailyVolatilityPct(moving)
+	if !ok || vol < 4 || vol > 6 {
+		t.Fatalf("unexpected daily volatility %.2f", vol)
+	}
+}
+
+func TestLatestProbUpKeepsNewestEnsemble(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	got := latestProbUp([]domain.MLPrediction{
+		{Symbol: "BTC", ModelKey: common.ModelKeyEnsembleV1, ProbUp: 0.6, OpenTime: now.Add(-time.Hour)},
+		{Symbol: "BTC", ModelKey: common.ModelKeyEnsembleV1, ProbUp: 0.7, OpenTime: now},
+		{Symbol: "BTC", ModelKey: common.ModelKeyLogReg, ProbUp: 0.9, OpenTime: now.Add(time.Hour)},
+	})
+	if got["BTC"] != 0.7 {
+		t.Fatalf("expected newest ensemble prob 0.7, got %v", got["BTC"])
+	}
+}
+
+func TestDashboardHeatMetricToggle(t *testing.T) {
+	svc := testServices()
+	svc.Prices = &stubCandlePrices{candles: map[string][]*domain.Candle{
+		"BTC": {{Close: 100}, {Close: 101}, {Close: 100}},
+	}}
+	m := NewDashboardModel(svc)
+	m.SetSize(120, 40)
+	m.watchlist = []string{"BTC"}
+
+	h := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}}
+	updated, cmd := m.Update(h)
+	if updated.HeatMetric() != HeatVolume || cmd != nil {
+		t.Fatalf("expected volume metric without fetch, got %s", updated.HeatMetric())
+	}
+	updated, cmd = updated.Update(h)
+	if updated.HeatMetric() != HeatVolatility || cmd == nil {
+		t.Fatalf("expected volatility metric with fetch, got %s", updated.HeatMetric())
+	}
+	updated, _ = updated.Update(cmd())
+	if _, ok := updated.heatIn.DailyVolPct["BTC"]; !ok {
+		t.Fatal("expected BTC volatility loaded")
+	}
+	for i := 0; i < 2; i++ {
+		updated, _ = updated.Update(h)
+	}
+	if updated.HeatMetric() != HeatChange {
+		t.Fatalf("expected cycle back to 24h change, got %s", updated.HeatMetric())
+	}
+}
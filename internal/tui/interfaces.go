@@ -2,9 +2,11 @@ package tui
 
 import (
 	"context"
+	"time"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/internal/events"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 )
 
 // PriceQuerier provides price data to the TUI.
@@ -22,11 +24,51 @@ type AdvisorQuerier interface {
 	Ask(ctx context.Context, chatID int64, message string) (string, error)
 }
 
+// ChatHistoryQuerier is optionally implemented by the advisor. With it the
+// chat screen opens on the user's earlier conversation.
+type ChatHistoryQuerier interface {
+	History(ctx context.Context, chatID int64, limit int) ([]domain.ConversationMessage, error)
+}
+
 // BacktestQuerier provides ML backtest data to the TUI.
 type BacktestQuerier interface {
 	GetDailyAccuracy(ctx context.Context, modelKey string, days int) ([]repository.DailyAccuracy, error)
 	GetAccuracySummary(ctx context.Context) ([]repository.DailyAccuracy, error)
+	GetDailyAccuracySeries(ctx context.Context, days int) ([]repository.AccuracySeries, error)
 	ListRecentPredictions(ctx context.Context, limit int) ([]domain.MLPrediction, error)
+	GetCalibration(ctx context.Context, modelKey string, buckets int) ([]domain.CalibrationBucket, error)
+	GetRiskLevelStats(ctx context.Context, days int) ([]domain.RiskLevelStats, error)
+	GetAnomalyHeat(ctx context.Context, days int, interval string) ([]domain.AnomalyCell, error)
+}
+
+// SimilarSetupQuerier finds past market states like a symbol's current one.
+type SimilarSetupQuerier interface {
+	FindSimilar(ctx context.Context, symbol, interval string, k int) (*domain.SimilarSetups, error)
+}
+
+// GlobalMarketQuerier provides the latest total market cap and BTC
+// dominance.
+type GlobalMarketQuerier interface {
+	LatestGlobalMarket(ctx context.Context) (*domain.GlobalMarketSnapshot, error)
+}
+
+// WatchlistStore persists the symbols an SSH user pinned.
+type WatchlistStore interface {
+	GetWatchlist(ctx context.Context, userID int64) ([]string, error)
+	SetWatchlist(ctx context.Context, userID int64, symbols []string) error
+}
+
+// TUIStateStore persists an SSH user's tab, filters and theme between
+// sessions.
+type TUIStateStore interface {
+	GetTUIState(ctx context.Context, userID int64) (domain.TUIState, error)
+	SetTUIState(ctx context.Context, userID int64, state domain.TUIState) error
+}
+
+// MaintenanceSwitch reads and flips the operator maintenance switch.
+type MaintenanceSwitch interface {
+	State(ctx context.Context) (domain.MaintenanceState, error)
+	Set(ctx context.Context, enabled bool, reason string) (domain.MaintenanceState, error)
 }
 
 // SSHChatIDOffset is the base offset for generating synthetic chat IDs
@@ -34,17 +76,51 @@ type BacktestQuerier interface {
 // This avoids collisions with Telegram chat IDs.
 const SSHChatIDOffset int64 = -1_000_000
 
+// SSHSessionChatIDOffset is the base for sessions without a user row, such
+// as demo logins: the chat ID is SSHSessionChatIDOffset - SessionID. It sits
+// far below both user and Telegram chat IDs.
+const SSHSessionChatIDOffset int64 = -1 << 50
+
 // Services bundles all service dependencies injected into the TUI.
 type Services struct {
-	Prices   PriceQuerier
-	Signals  SignalQuerier
-	Advisor  AdvisorQuerier
-	Backtest BacktestQuerier
-	UserID   int64
-	Username string
+	Prices       PriceQuerier
+	Signals      SignalQuerier
+	Advisor      AdvisorQuerier
+	Backtest     BacktestQuerier
+	Watchlist    WatchlistStore            // optional; without it pins last for the session only
+	State        TUIStateStore             // optional; without it each session starts fresh
+	MarketEvents <-chan events.MarketEvent // optional; the dashboard refreshes on each event
+	Maintenance  MaintenanceSwitch         // optional; shows a banner while maintenance is on
+	Similar      SimilarSetupQuerier       // optional; answers /similar
+	GlobalMarket GlobalMarketQuerier       // optional; shows market cap and dominance above the prices
+	// Location is the time zone times are shown in; UTC when nil.
+	Location *time.Location
+	// SimilarInterval is the feature interval /similar searches; "1h" when
+	// empty.
+	SimilarInterval string
+	// CanToggleMaintenance lets this user flip Maintenance with M.
+	CanToggleMaintenance bool
+	UserID               int64
+	Username             string
+	// SessionID keys the advisor conversation when UserID is 0, so users
+	// without a row do not share one. It must be unique per session.
+	SessionID int64
+}
+
+// Timezone returns the time zone the session shows times in.
+func (s Services) Timezone() *time.Location {
+	if s.Location == nil {
+		return time.UTC
+	}
+	return s.Location
 }
 
-// ChatID returns the synthetic chat ID for this SSH session.
+// ChatID returns the synthetic advisor chat ID: one per SSH user, so a
+// user's conversation continues across sessions and never mixes with
+// another user's.
 func (s Services) ChatID() int64 {
+	if s.UserID == 0 {
+		return SSHSessionChatIDOffset - s.SessionID
+	}
 	return SSHChatIDOffset - s.UserID
 }
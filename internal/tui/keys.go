@@ -9,13 +9,34 @@ type KeyMap struct {
 	Quit     key.Binding
 	Refresh  key.Binding
 
+	// Color theme
+	CycleTheme key.Binding
+
+	// Operator maintenance switch
+	Maintenance key.Binding
+
 	// Signal explorer filters
 	FilterSymbol    key.Binding
 	FilterRisk      key.Binding
 	FilterIndicator key.Binding
 
-	// Backtest view toggle
-	ToggleView key.Binding
+	// Backtest views
+	ToggleView  key.Binding
+	Calibration key.Binding
+	RiskView    key.Binding
+	AnomalyView key.Binding
+	CycleModel  key.Binding
+
+	// Dashboard heat map weighting
+	HeatMetric key.Binding
+
+	// Watchlist picker
+	Watchlist   key.Binding
+	PickerUp    key.Binding
+	PickerDown  key.Binding
+	PickerPick  key.Binding
+	PickerSave  key.Binding
+	PickerClose key.Binding
 }
 
 // DefaultKeyMap provides the default key bindings for the TUI.
@@ -25,9 +46,26 @@ var DefaultKeyMap = KeyMap{
 	Quit:     key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
 	Refresh:  key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "refresh")),
 
+	CycleTheme: key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "cycle theme")),
+
+	Maintenance: key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "toggle maintenance mode")),
+
 	FilterSymbol:    key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "cycle symbol")),
 	FilterRisk:      key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "cycle risk")),
 	FilterIndicator: key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "cycle indicator")),
 
-	ToggleView: key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "toggle view")),
+	ToggleView:  key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "toggle view")),
+	Calibration: key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "calibration")),
+	RiskView:    key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "risk levels")),
+	AnomalyView: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "anomaly heat map")),
+	CycleModel:  key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "cycle model")),
+
+	HeatMetric: key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "heat map weighting")),
+
+	Watchlist:   key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "edit watchlist")),
+	PickerUp:    key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k", "up")),
+	PickerDown:  key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j", "down")),
+	PickerPick:  key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space", "pin/unpin")),
+	PickerSave:  key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save")),
+	PickerClose: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
 }
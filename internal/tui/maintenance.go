@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maintenanceRefresh is how often the banner re-reads the switch, which
+// the API or another session may flip.
+const maintenanceRefresh = 15 * time.Second
+
+// Maintenance message types.
+type maintenanceMsg domain.MaintenanceState
+type maintenanceErrMsg struct{ err error }
+type maintenanceTickMsg struct{}
+
+func loadMaintenanceCmd(svc Services) tea.Cmd {
+	if svc.Maintenance == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		state, err := svc.Maintenance.State(ctx)
+		if err != nil {
+			return maintenanceErrMsg{err: err}
+		}
+		return maintenanceMsg(state)
+	}
+}
+
+func maintenanceTickCmd(svc Services) tea.Cmd {
+	if svc.Maintenance == nil {
+		return nil
+	}
+	return tea.Tick(maintenanceRefresh, func(time.Time) tea.Msg { return maintenanceTickMsg{} })
+}
+
+// setMaintenanceCmd flips the switch on behalf of the SSH user, who is
+// recorded as the actor in the audit log.
+func setMaintenanceCmd(svc Services, enabled bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		ctx = domain.WithActor(ctx, "ssh:"+svc.Username)
+		state, err := svc.Maintenance.Set(ctx, enabled, "set from the SSH console")
+		if err != nil {
+			return maintenanceErrMsg{err: err}
+		}
+		return maintenanceMsg(state)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"strings"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,37 +16,50 @@ import (
 type filteredSignalsMsg []domain.Signal
 type filteredSignalsErrMsg struct{ err error }
 
+// pinnedOption filters the explorer to the user's watchlist.
+const pinnedOption = "PINNED"
+
 var (
-	symbolOptions = []string{
-		"ALL", "BTC", "ETH", "SOL", "XRP", "ADA", "DOGE", "DOT", "AVAX", "LINK", "MATIC",
-	}
-	riskOptions = []string{"ALL", "1", "2", "3", "4", "5"}
-	indicatorOptions = []string{
-		"ALL", "rsi", "macd", "bollinger", "volume_zscore",
-		"ml_logreg_up4h", "ml_xgboost_up4h", "ml_ensemble_up4h",
-		"fund_sentiment_composite",
-	}
+	riskOptions      = []string{"ALL", "1", "2", "3", "4", "5"}
+	indicatorOptions = append([]string{"ALL"}, domain.IndicatorKeys()...)
 )
 
+// symbolOptionsFor lists the symbol filter choices. With a watchlist the
+// explorer starts on the pinned symbols and "ALL" comes last.
+func symbolOptionsFor(watchlist []string) []string {
+	if len(watchlist) == 0 {
+		return append([]string{"ALL"}, domain.SupportedSymbols()...)
+	}
+	opts := append([]string{pinnedOption}, watchlist...)
+	return append(opts, "ALL")
+}
+
 // SignalExplorerModel is the Bubble Tea model for the signal explorer screen.
 type SignalExplorerModel struct {
 	services     Services
+	styles       *Styles
 	signals      []domain.Signal
+	watchlist    []string
+	symbolOpts   []string
 	symbolIdx    int
 	riskIdx      int
 	indicatorIdx int
-	scrollOffset int
-	loading      bool
-	err          error
-	width        int
-	height       int
+	// restoreSymbol is a saved symbol option waiting for the watchlist.
+	restoreSymbol string
+	scrollOffset  int
+	loading       bool
+	err           error
+	width         int
+	height        int
 }
 
 // NewSignalExplorerModel creates a new signal explorer model.
 func NewSignalExplorerModel(svc Services) SignalExplorerModel {
 	return SignalExplorerModel{
-		services: svc,
-		loading:  true,
+		services:   svc,
+		styles:     defaultStyles(),
+		symbolOpts: symbolOptionsFor(nil),
+		loading:    true,
 	}
 }
 
@@ -70,10 +83,19 @@ func (m SignalExplorerModel) Update(msg tea.Msg) (SignalExplorerModel, tea.Cmd)
 		m.loading = false
 		return m, nil
 
+	case watchlistMsg:
+		m.watchlist = []string(msg)
+		m.symbolOpts = symbolOptionsFor(m.watchlist)
+		m.symbolIdx = max(0, indexOf(m.symbolOpts, m.restoreSymbol))
+		m.restoreSymbol = ""
+		m.loading = true
+		return m, m.fetchSignalsCmd()
+
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, DefaultKeyMap.FilterSymbol):
-			m.symbolIdx = (m.symbolIdx + 1) % len(symbolOptions)
+			m.symbolIdx = (m.symbolIdx + 1) % len(m.symbolOpts)
+			m.restoreSymbol = ""
 			m.loading = true
 			return m, m.fetchSignalsCmd()
 
@@ -114,30 +136,30 @@ func (m SignalExplorerModel) View() string {
 	var sections []string
 
 	// Header
-	sections = append(sections, HeaderStyle.Render("  Signal Explorer"))
+	sections = append(sections, m.styles.HeaderStyle.Render("  Signal Explorer"))
 	sections = append(sections, "")
 
 	// Filter chips
 	sections = append(sections, m.renderFilters())
-	sections = append(sections, SubtextStyle.Render(strings.Repeat("─", m.width-2)))
+	sections = append(sections, m.styles.SubtextStyle.Render(strings.Repeat("─", m.width-2)))
 
 	if m.loading {
-		sections = append(sections, SubtextStyle.Render("  Loading..."))
+		sections = append(sections, m.styles.SubtextStyle.Render("  Loading..."))
 		return strings.Join(sections, "\n")
 	}
 
 	if m.err != nil {
-		sections = append(sections, ErrorStyle.Render(fmt.Sprintf("  Error: %v", m.err)))
+		sections = append(sections, m.styles.ErrorStyle.Render(fmt.Sprintf("  Error: %v", m.err)))
 		return strings.Join(sections, "\n")
 	}
 
 	if len(m.signals) == 0 {
-		sections = append(sections, SubtextStyle.Render("  No signals match the current filters"))
+		sections = append(sections, m.styles.SubtextStyle.Render("  No signals match the current filters"))
 		return strings.Join(sections, "\n")
 	}
 
 	// Table header
-	sections = append(sections, SubtextStyle.Render(
+	sections = append(sections, m.styles.SubtextStyle.Render(
 		fmt.Sprintf("  %-5s %-6s %-4s %-12s %-6s %-5s  %s",
 			"ID", "Symbol", "Int", "Indicator", "Dir", "Risk", "Time"),
 	))
@@ -150,19 +172,19 @@ func (m SignalExplorerModel) View() string {
 	}
 
 	for i := m.scrollOffset; i < end; i++ {
-		sections = append(sections, "  "+FormatSignal(m.signals[i]))
+		sections = append(sections, "  "+m.styles.FormatSignal(m.signals[i], m.services.Timezone()))
 	}
 
 	// Scroll indicator
 	if len(m.signals) > maxVisible {
-		sections = append(sections, SubtextStyle.Render(
+		sections = append(sections, m.styles.SubtextStyle.Render(
 			fmt.Sprintf("  Showing %d-%d of %d (j/k to scroll)", m.scrollOffset+1, end, len(m.signals)),
 		))
 	}
 
 	// Help
 	sections = append(sections, "")
-	sections = append(sections, SubtextStyle.Render("  [s] symbol  [r] risk  [i] indicator  [R] refresh  [j/k] scroll"))
+	sections = append(sections, m.styles.SubtextStyle.Render("  [s] symbol  [r] risk  [i] indicator  [w] watchlist  [R] refresh  [j/k] scroll"))
 
 	return strings.Join(sections, "\n")
 }
@@ -178,11 +200,38 @@ func (m SignalExplorerModel) FilterState() (symbolIdx, riskIdx, indicatorIdx int
 	return m.symbolIdx, m.riskIdx, m.indicatorIdx
 }
 
+// filterValues returns the selected filter options by name.
+func (m SignalExplorerModel) filterValues() (symbol, risk, indicator string) {
+	return m.symbolOption(), riskOptions[m.riskIdx], indicatorOptions[m.indicatorIdx]
+}
+
+// restoreFilters selects the named filter options, skipping names that are
+// no longer offered. The symbol is kept until the watchlist loads, since
+// that replaces the symbol options.
+func (m *SignalExplorerModel) restoreFilters(symbol, risk, indicator string) tea.Cmd {
+	prev := [3]int{m.symbolIdx, m.riskIdx, m.indicatorIdx}
+	if i := indexOf(m.symbolOpts, symbol); i >= 0 {
+		m.symbolIdx = i
+	}
+	m.restoreSymbol = symbol
+	if i := indexOf(riskOptions, risk); i >= 0 {
+		m.riskIdx = i
+	}
+	if i := indexOf(indicatorOptions, indicator); i >= 0 {
+		m.indicatorIdx = i
+	}
+	if prev == [3]int{m.symbolIdx, m.riskIdx, m.indicatorIdx} {
+		return nil
+	}
+	m.loading = true
+	return m.fetchSignalsCmd()
+}
+
 // SignalCount returns the number of loaded signals (for testing).
 func (m SignalExplorerModel) SignalCount() int { return len(m.signals) }
 
 func (m SignalExplorerModel) renderFilters() string {
-	symbolChip := m.renderChip("Symbol", symbolOptions, m.symbolIdx)
+	symbolChip := m.renderChip("Symbol", m.symbolOpts, m.symbolIdx)
 	riskChip := m.renderChip("Risk", riskOptions, m.riskIdx)
 	indChip := m.renderChip("Type", indicatorOptions, m.indicatorIdx)
 	return "  " + lipgloss.JoinHorizontal(lipgloss.Top, symbolChip, "  ", riskChip, "  ", indChip)
@@ -190,16 +239,16 @@ func (m SignalExplorerModel) renderFilters() string {
 
 func (m SignalExplorerModel) renderChip(label string, options []string, active int) string {
 	var parts []string
-	parts = append(parts, SubtextStyle.Render(label+": "))
+	parts = append(parts, m.styles.SubtextStyle.Render(label+": "))
 	for i, opt := range options {
 		display := strings.ToUpper(opt)
 		if len(display) > 6 {
 			display = display[:6]
 		}
 		if i == active {
-			parts = append(parts, ActiveTabStyle.Render(display))
+			parts = append(parts, m.styles.ActiveTabStyle.Render(display))
 		} else {
-			parts = append(parts, SubtextStyle.Render(display))
+			parts = append(parts, m.styles.SubtextStyle.Render(display))
 		}
 		parts = append(parts, " ")
 	}
@@ -209,8 +258,8 @@ func (m SignalExplorerModel) renderChip(label string, options []string, active i
 func (m SignalExplorerModel) buildFilter() domain.SignalFilter {
 	filter := domain.SignalFilter{Limit: 100}
 
-	if m.symbolIdx > 0 && m.symbolIdx < len(symbolOptions) {
-		filter.Symbol = symbolOptions[m.symbolIdx]
+	if symbol := m.symbolOption(); symbol != "ALL" && symbol != pinnedOption {
+		filter.Symbol = symbol
 	}
 
 	if m.riskIdx > 0 && m.riskIdx < len(riskOptions) {
@@ -225,8 +274,19 @@ func (m SignalExplorerModel) buildFilter() domain.SignalFilter {
 	return filter
 }
 
+func (m SignalExplorerModel) symbolOption() string {
+	if m.symbolIdx < 0 || m.symbolIdx >= len(m.symbolOpts) {
+		return "ALL"
+	}
+	return m.symbolOpts[m.symbolIdx]
+}
+
 func (m SignalExplorerModel) fetchSignalsCmd() tea.Cmd {
 	filter := m.buildFilter()
+	var pinned []string
+	if m.symbolOption() == pinnedOption {
+		pinned = m.watchlist
+	}
 	return func() tea.Msg {
 		if m.services.Signals == nil {
 			return filteredSignalsErrMsg{err: fmt.Errorf("signal service not available")}
@@ -235,7 +295,7 @@ func (m SignalExplorerModel) fetchSignalsCmd() tea.Cmd {
 		if err != nil {
 			return filteredSignalsErrMsg{err: err}
 		}
-		return filteredSignalsMsg(signals)
+		return filteredSignalsMsg(filterSignals(signals, pinned))
 	}
 }
 
@@ -3,7 +3,7 @@ package tui
 import (
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
+	"bug-free-umbrella/pkg/domain"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -95,3 +95,21 @@ func TestSignalExplorerScrolling(t *testing.T) {
 		t.Fatalf("expected scroll offset 0, got %d", updated.scrollOffset)
 	}
 }
+
+func TestSignalExplorerDefaultsToWatchlist(t *testing.T) {
+	m := NewSignalExplorerModel(testServices())
+	m.SetSize(120, 40)
+
+	updated, cmd := m.Update(watchlistMsg{"BTC", "SOL"})
+	if cmd == nil {
+		t.Fatal("expected refetch after watchlist change")
+	}
+	if updated.symbolOption() != pinnedOption || updated.buildFilter().Symbol != "" {
+		t.Fatalf("expected pinned filter, got %s", updated.symbolOption())
+	}
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if updated.buildFilter().Symbol != "BTC" {
+		t.Fatalf("expected BTC after cycling, got %q", updated.buildFilter().Symbol)
+	}
+}
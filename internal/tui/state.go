@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stateSaveDelay batches quick changes, such as cycling through a filter,
+// into a single write.
+const stateSaveDelay = time.Second
+
+// Session state message types.
+type stateMsg domain.TUIState
+type stateErrMsg struct{ err error }
+type stateSaveMsg struct{ seq int }
+
+// tabKeys name the tabs in saved TUI state.
+var tabKeys = []string{"dashboard", "chat", "signals", "backtest"}
+
+func indexOf(options []string, v string) int {
+	for i, opt := range options {
+		if opt == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func loadStateCmd(svc Services) tea.Cmd {
+	return func() tea.Msg {
+		if svc.State == nil || svc.UserID == 0 {
+			return stateMsg{}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		state, err := svc.State.GetTUIState(ctx, svc.UserID)
+		if err != nil {
+			return stateErrMsg{err: err}
+		}
+		return stateMsg(state)
+	}
+}
+
+func saveStateCmd(svc Services, state domain.TUIState) tea.Cmd {
+	if svc.State == nil || svc.UserID == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := svc.State.SetTUIState(ctx, svc.UserID, state); err != nil {
+			return stateErrMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// currentState snapshots what the next session should reopen.
+func (m AppModel) currentState() domain.TUIState {
+	symbol, risk, indicator := m.signals.filterValues()
+	return domain.TUIState{
+		Tab:             tabKeys[m.activeTab],
+		Theme:           m.theme,
+		SignalSymbol:    symbol,
+		SignalRisk:      risk,
+		SignalIndicator: indicator,
+		HeatMetric:      heatMetricKeys[m.dashboard.heat],
+		BacktestView:    backtestViewKeys[m.backtest.activeView],
+	}
+}
+
+// applyState restores saved state, ignoring values this build does not
+// know. Under a plain (NO_COLOR) default the saved theme is kept for later
+// sessions but not applied.
+func (m *AppModel) applyState(state domain.TUIState) tea.Cmd {
+	if i := indexOf(tabKeys, state.Tab); i >= 0 {
+		m.switchTab(Tab(i))
+	}
+	if theme, ok := themes[state.Theme]; ok {
+		m.theme = state.Theme
+		if !defaultTheme.Plain {
+			*m.styles = *NewStyles(theme)
+		}
+	}
+	if i := indexOf(backtestViewKeys, state.BacktestView); i >= 0 {
+		m.backtest.activeView = i
+	}
+	var cmds []tea.Cmd
+	if i := indexOf(heatMetricKeys, state.HeatMetric); i >= 0 {
+		cmds = append(cmds, m.dashboard.setHeatMetric(HeatMetric(i)))
+	}
+	cmds = append(cmds, m.signals.restoreFilters(state.SignalSymbol, state.SignalRisk, state.SignalIndicator))
+	return tea.Batch(cmds...)
+}
+
+// cycleTheme switches to the next theme and remembers the choice.
+func (m *AppModel) cycleTheme() {
+	names := ThemeNames()
+	next := names[(indexOf(names, m.styles.Theme.Name)+1)%len(names)]
+	m.theme = next
+	*m.styles = *NewStyles(themes[next])
+}
+
+// scheduleStateSave queues a save once the state has settled. Nothing is
+// saved until the stored state has loaded, so a slow load cannot overwrite
+// it with defaults.
+func (m *AppModel) scheduleStateSave() tea.Cmd {
+	if !m.stateLoaded || m.services.State == nil || m.services.UserID == 0 {
+		return nil
+	}
+	state := m.currentState()
+	if state == m.pendingState {
+		return nil
+	}
+	m.pendingState = state
+	m.stateSeq++
+	seq := m.stateSeq
+	return tea.Tick(stateSaveDelay, func(time.Time) tea.Msg { return stateSaveMsg{seq: seq} })
+}
+
+// flushStateCmd saves a change still waiting on its delay.
+func (m *AppModel) flushStateCmd() tea.Cmd {
+	if m.savedSeq == m.stateSeq {
+		return nil
+	}
+	m.savedSeq = m.stateSeq
+	return saveStateCmd(m.services, m.pendingState)
+}
@@ -1,49 +1,224 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultThemeName is used when no theme is configured.
+const DefaultThemeName = "dark"
+
+// NoColorThemeName is forced when NO_COLOR is set (https://no-color.org).
+const NoColorThemeName = "no-color"
+
+// Theme is a color palette for the TUI. Styles are derived from it by
+// NewStyles so every screen changes together.
+type Theme struct {
+	Name     string
+	Accent   lipgloss.TerminalColor // active tab, user messages, spinner
+	OnAccent lipgloss.TerminalColor // text drawn on Accent
+	Text     lipgloss.TerminalColor // headers and advisor replies
+	Muted    lipgloss.TerminalColor // inactive tabs, subtext, unchanged prices
+	Border   lipgloss.TerminalColor
+	Up       lipgloss.TerminalColor // gains, long signals, low risk, good accuracy
+	Down     lipgloss.TerminalColor // losses, short signals, high risk, errors
+	Warn     lipgloss.TerminalColor // hold signals, medium risk
+	HeatText lipgloss.TerminalColor // symbol text on heat map cells
+	// Plain marks a palette without colors: emphasis falls back to bold and
+	// reverse video, and the heat map shows arrows instead of backgrounds.
+	Plain bool
+}
+
+var themes = map[string]Theme{
+	"dark": {
+		Name:     "dark",
+		Accent:   lipgloss.Color("#7D56F4"),
+		OnAccent: lipgloss.Color("#FAFAFA"),
+		Text:     lipgloss.Color("#FAFAFA"),
+		Muted:    lipgloss.Color("#888888"),
+		Border:   lipgloss.Color("#555555"),
+		Up:       lipgloss.Color("#00FF00"),
+		Down:     lipgloss.Color("#FF0000"),
+		Warn:     lipgloss.Color("#FFFF00"),
+		HeatText: lipgloss.Color("#000000"),
+	},
+	"light": {
+		Name:     "light",
+		Accent:   lipgloss.Color("#5A3FC0"),
+		OnAccent: lipgloss.Color("#FFFFFF"),
+		Text:     lipgloss.Color("#1A1A1A"),
+		Muted:    lipgloss.Color("#6B6B6B"),
+		Border:   lipgloss.Color("#AAAAAA"),
+		Up:       lipgloss.Color("#1B7F1B"),
+		Down:     lipgloss.Color("#C62828"),
+		Warn:     lipgloss.Color("#9A6700"),
+		HeatText: lipgloss.Color("#FFFFFF"),
+	},
+	"high-contrast": {
+		Name:     "high-contrast",
+		Accent:   lipgloss.Color("#FFFF00"),
+		OnAccent: lipgloss.Color("#000000"),
+		Text:     lipgloss.Color("#FFFFFF"),
+		Muted:    lipgloss.Color("#D0D0D0"),
+		Border:   lipgloss.Color("#FFFFFF"),
+		Up:       lipgloss.Color("#00FF00"),
+		Down:     lipgloss.Color("#FF5555"),
+		Warn:     lipgloss.Color("#FFFF00"),
+		HeatText: lipgloss.Color("#000000"),
+	},
+	NoColorThemeName: {
+		Name:     NoColorThemeName,
+		Accent:   lipgloss.NoColor{},
+		OnAccent: lipgloss.NoColor{},
+		Text:     lipgloss.NoColor{},
+		Muted:    lipgloss.NoColor{},
+		Border:   lipgloss.NoColor{},
+		Up:       lipgloss.NoColor{},
+		Down:     lipgloss.NoColor{},
+		Warn:     lipgloss.NoColor{},
+		HeatText: lipgloss.NoColor{},
+		Plain:    true,
+	},
+}
+
+// ThemeNames lists the selectable themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme returns the theme called name. An empty name selects
+// DefaultThemeName; noColor (the NO_COLOR convention) overrides it with the
+// no-color theme.
+func LookupTheme(name string, noColor bool) (Theme, error) {
+	if noColor {
+		return themes[NoColorThemeName], nil
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultThemeName
+	}
+	theme, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
+
+// Styles are the lipgloss styles derived from a Theme. Each session owns
+// one, so users on the same server can pick different themes.
+type Styles struct {
+	Theme Theme
 
-var (
 	// Tab bar styles
-	TabStyle       = lipgloss.NewStyle().Padding(0, 2)
-	ActiveTabStyle = TabStyle.Bold(true).
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4"))
-	InactiveTabStyle = TabStyle.
-				Foreground(lipgloss.Color("#888888"))
+	TabStyle         lipgloss.Style
+	ActiveTabStyle   lipgloss.Style
+	InactiveTabStyle lipgloss.Style
 
 	// Price colors
-	PriceUpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
-	PriceDownStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
-	PriceZeroStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	PriceUpStyle   lipgloss.Style
+	PriceDownStyle lipgloss.Style
+	PriceZeroStyle lipgloss.Style
 
 	// Signal direction colors
-	DirectionLongStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Bold(true)
-	DirectionShortStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Bold(true)
-	DirectionHoldStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00"))
+	DirectionLongStyle  lipgloss.Style
+	DirectionShortStyle lipgloss.Style
+	DirectionHoldStyle  lipgloss.Style
 
 	// Risk level colors
-	RiskLowStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
-	RiskMedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00"))
-	RiskHighStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
+	RiskLowStyle  lipgloss.Style
+	RiskMedStyle  lipgloss.Style
+	RiskHighStyle lipgloss.Style
 
 	// General styles
-	HeaderStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FAFAFA"))
-	SubtextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
-	BorderStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#555555"))
-	ErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
-	SpinnerColor = lipgloss.Color("#7D56F4")
+	HeaderStyle  lipgloss.Style
+	SubtextStyle lipgloss.Style
+	BorderStyle  lipgloss.Style
+	ErrorStyle   lipgloss.Style
+	WarnStyle    lipgloss.Style
+	SpinnerColor lipgloss.TerminalColor
 
 	// Chat styles
-	UserMsgStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4")).Bold(true)
-	AssistantMsgStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FAFAFA"))
+	UserMsgStyle      lipgloss.Style
+	AssistantMsgStyle lipgloss.Style
 
 	// Heat map colors
-	HeatGreen   = lipgloss.Color("#00FF00")
-	HeatRed     = lipgloss.Color("#FF0000")
-	HeatNeutral = lipgloss.Color("#555555")
+	HeatGreen     lipgloss.TerminalColor
+	HeatRed       lipgloss.TerminalColor
+	HeatNeutral   lipgloss.TerminalColor
+	HeatTextColor lipgloss.TerminalColor
+	heatMarkers   bool
 
 	// Accuracy bar colors
-	AccuracyGoodStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
-	AccuracyOkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00"))
-	AccuracyBadStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
-)
+	AccuracyGoodStyle lipgloss.Style
+	AccuracyOkStyle   lipgloss.Style
+	AccuracyBadStyle  lipgloss.Style
+}
+
+// defaultTheme is the theme sessions start with until the user picks one.
+var defaultTheme = themes[DefaultThemeName]
+
+// ApplyTheme sets the theme new sessions start with; call it once at startup
+// before serving. Users can switch themes per session, and their choice is
+// kept in their TUI state.
+func ApplyTheme(t Theme) {
+	defaultTheme = t
+}
+
+// defaultStyles returns styles for the default theme, for screens created
+// on their own rather than by AppModel.
+func defaultStyles() *Styles {
+	return NewStyles(defaultTheme)
+}
+
+// NewStyles derives the styles for t.
+func NewStyles(t Theme) *Styles {
+	st := &Styles{Theme: t}
+	st.TabStyle = lipgloss.NewStyle().Padding(0, 2)
+	st.ActiveTabStyle = st.TabStyle.Bold(true).
+		Foreground(t.OnAccent).
+		Background(t.Accent).
+		Reverse(t.Plain)
+	st.InactiveTabStyle = st.TabStyle.
+		Foreground(t.Muted)
+
+	st.PriceUpStyle = lipgloss.NewStyle().Foreground(t.Up)
+	st.PriceDownStyle = lipgloss.NewStyle().Foreground(t.Down)
+	st.PriceZeroStyle = lipgloss.NewStyle().Foreground(t.Muted)
+
+	st.DirectionLongStyle = lipgloss.NewStyle().Foreground(t.Up).Bold(true)
+	st.DirectionShortStyle = lipgloss.NewStyle().Foreground(t.Down).Bold(true)
+	st.DirectionHoldStyle = lipgloss.NewStyle().Foreground(t.Warn)
+
+	st.RiskLowStyle = lipgloss.NewStyle().Foreground(t.Up)
+	st.RiskMedStyle = lipgloss.NewStyle().Foreground(t.Warn)
+	st.RiskHighStyle = lipgloss.NewStyle().Foreground(t.Down).Bold(t.Plain)
+
+	st.HeaderStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Text)
+	st.SubtextStyle = lipgloss.NewStyle().Foreground(t.Muted)
+	st.BorderStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(t.Border)
+	st.ErrorStyle = lipgloss.NewStyle().Foreground(t.Down).Bold(t.Plain)
+	st.WarnStyle = lipgloss.NewStyle().Foreground(t.Warn).Bold(true)
+	st.SpinnerColor = t.Accent
+
+	st.UserMsgStyle = lipgloss.NewStyle().Foreground(t.Accent).Bold(true)
+	st.AssistantMsgStyle = lipgloss.NewStyle().Foreground(t.Text)
+
+	st.HeatGreen = t.Up
+	st.HeatRed = t.Down
+	st.HeatNeutral = t.Border
+	st.HeatTextColor = t.HeatText
+	st.heatMarkers = t.Plain
+
+	st.AccuracyGoodStyle = lipgloss.NewStyle().Foreground(t.Up)
+	st.AccuracyOkStyle = lipgloss.NewStyle().Foreground(t.Warn)
+	st.AccuracyBadStyle = lipgloss.NewStyle().Foreground(t.Down)
+	return st
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestLookupTheme(t *testing.T) {
+	for _, name := range ThemeNames() {
+		theme, err := LookupTheme(strings.ToUpper(name), false)
+		if err != nil || theme.Name != name {
+			t.Fatalf("LookupTheme(%q) = %q, %v", name, theme.Name, err)
+		}
+	}
+	if theme, _ := LookupTheme("", false); theme.Name != DefaultThemeName {
+		t.Fatalf("expected default theme, got %q", theme.Name)
+	}
+	if theme, _ := LookupTheme("light", true); theme.Name != NoColorThemeName {
+		t.Fatalf("expected NO_COLOR to force no-color, got %q", theme.Name)
+	}
+	if _, err := LookupTheme("neon", false); err == nil {
+		t.Fatal("expected unknown theme error")
+	}
+}
+
+func TestNoColorStylesMarkHeatMap(t *testing.T) {
+	theme, _ := LookupTheme(NoColorThemeName, false)
+	st := NewStyles(theme)
+	if _, ok := st.PriceUpStyle.GetForeground().(lipgloss.NoColor); !ok {
+		t.Fatalf("expected no foreground color, got %#v", st.PriceUpStyle.GetForeground())
+	}
+	out := st.RenderHeatMap([]*domain.PriceSnapshot{
+		{Symbol: "BTC", Change24hPct: 2},
+		{Symbol: "ETH", Change24hPct: -2},
+	}, 80)
+	if !strings.Contains(out, "BTC▲") || !strings.Contains(out, "ETH▼") {
+		t.Fatalf("expected direction markers without color, got %q", out)
+	}
+
+	if got := NewStyles(themes["light"]).PriceUpStyle.GetForeground(); got != lipgloss.Color("#1B7F1B") {
+		t.Fatalf("expected light palette, got %#v", got)
+	}
+}
+
+func TestApplyThemeSetsDefaultForNewSessions(t *testing.T) {
+	defer ApplyTheme(themes[DefaultThemeName])
+
+	ApplyTheme(themes["light"])
+	m := NewAppModel(Services{})
+	if m.styles.Theme.Name != "light" || m.dashboard.styles != m.styles || m.backtest.styles != m.styles {
+		t.Fatalf("expected every screen to share the light styles, got %q", m.styles.Theme.Name)
+	}
+}
@@ -0,0 +1,182 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Watchlist message types. An empty watchlistMsg means no symbols are
+// pinned and screens show every supported symbol.
+type watchlistMsg []string
+type watchlistErrMsg struct{ err error }
+
+// watchlistPicker toggles pinned symbols. It is opened with 'w' from the
+// dashboard or signal explorer and owned by AppModel.
+type watchlistPicker struct {
+	open     bool
+	cursor   int
+	selected map[string]bool
+}
+
+func newWatchlistPicker(current []string) watchlistPicker {
+	p := watchlistPicker{open: true, selected: make(map[string]bool, len(current))}
+	for _, s := range current {
+		p.selected[s] = true
+	}
+	return p
+}
+
+// Update handles a key while the picker is open. saved is true when the user
+// confirmed, in which case symbols holds the new watchlist.
+func (p watchlistPicker) Update(msg tea.KeyMsg) (next watchlistPicker, symbols []string, saved bool) {
+	switch {
+	case key.Matches(msg, DefaultKeyMap.PickerUp):
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case key.Matches(msg, DefaultKeyMap.PickerDown):
+		if p.cursor < len(domain.SupportedSymbols())-1 {
+			p.cursor++
+		}
+	case key.Matches(msg, DefaultKeyMap.PickerPick):
+		// The registry can shrink while the picker is open.
+		if symbols := domain.SupportedSymbols(); p.cursor < len(symbols) {
+			p.selected[symbols[p.cursor]] = !p.selected[symbols[p.cursor]]
+		}
+	case key.Matches(msg, DefaultKeyMap.PickerSave):
+		p.open = false
+		return p, p.symbols(), true
+	case key.Matches(msg, DefaultKeyMap.PickerClose):
+		p.open = false
+	}
+	return p, nil, false
+}
+
+// symbols returns the selection in SupportedSymbols order.
+func (p watchlistPicker) symbols() []string {
+	var out []string
+	for _, s := range domain.SupportedSymbols() {
+		if p.selected[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// View renders the picker.
+func (p watchlistPicker) View(st *Styles) string {
+	lines := []string{
+		st.HeaderStyle.Render("  Watchlist"),
+		st.SubtextStyle.Render("  Pinned symbols are shown on the dashboard and signal explorer."),
+		"",
+	}
+	for i, s := range domain.SupportedSymbols() {
+		mark := "[ ]"
+		if p.selected[s] {
+			mark = "[x]"
+		}
+		line := fmt.Sprintf(" %s %s ", mark, s)
+		if i == p.cursor {
+			line = st.ActiveTabStyle.Render(line)
+		}
+		lines = append(lines, " "+line)
+	}
+	lines = append(lines, "",
+		st.SubtextStyle.Render("  [j/k] move  [space] pin/unpin  [enter] save  [esc] cancel"),
+		st.SubtextStyle.Render("  Save with nothing pinned to show all symbols."),
+	)
+	return st.BorderStyle.Render(strings.Join(lines, "\n"))
+}
+
+func loadWatchlistCmd(svc Services) tea.Cmd {
+	return func() tea.Msg {
+		if svc.Watchlist == nil || svc.UserID == 0 {
+			return watchlistMsg(nil)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		symbols, err := svc.Watchlist.GetWatchlist(ctx, svc.UserID)
+		if err != nil {
+			return watchlistErrMsg{err: err}
+		}
+		return watchlistMsg(normalizeWatchlist(symbols))
+	}
+}
+
+func saveWatchlistCmd(svc Services, symbols []string) tea.Cmd {
+	if svc.Watchlist == nil || svc.UserID == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := svc.Watchlist.SetWatchlist(ctx, svc.UserID, symbols); err != nil {
+			return watchlistErrMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// normalizeWatchlist drops symbols that are no longer supported and orders
+// the rest like SupportedSymbols.
+func normalizeWatchlist(symbols []string) []string {
+	pinned := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		pinned[strings.ToUpper(s)] = true
+	}
+	var out []string
+	for _, s := range domain.SupportedSymbols() {
+		if pinned[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func watchlistSet(watchlist []string) map[string]bool {
+	if len(watchlist) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(watchlist))
+	for _, s := range watchlist {
+		set[s] = true
+	}
+	return set
+}
+
+// filterPrices keeps watchlist symbols; an empty watchlist keeps everything.
+func filterPrices(prices []*domain.PriceSnapshot, watchlist []string) []*domain.PriceSnapshot {
+	set := watchlistSet(watchlist)
+	if set == nil {
+		return prices
+	}
+	var out []*domain.PriceSnapshot
+	for _, p := range prices {
+		if p != nil && set[p.Symbol] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// filterSignals keeps watchlist symbols; an empty watchlist keeps everything.
+func filterSignals(signals []domain.Signal, watchlist []string) []domain.Signal {
+	set := watchlistSet(watchlist)
+	if set == nil {
+		return signals
+	}
+	var out []domain.Signal
+	for _, s := range signals {
+		if set[s.Symbol] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+func TestNormalizeWatchlist(t *testing.T) {
+	got := normalizeWatchlist([]string{"sol", "BTC", "NOPE", "BTC"})
+	if len(got) != 2 || got[0] != "BTC" || got[1] != "SOL" {
+		t.Fatalf("unexpected watchlist %v", got)
+	}
+}
+
+func TestFilterPricesAndSignals(t *testing.T) {
+	prices := []*domain.PriceSnapshot{{Symbol: "BTC"}, {Symbol: "ETH"}, nil}
+	if got := filterPrices(prices, nil); len(got) != 3 {
+		t.Fatalf("empty watchlist must keep everything, got %d", len(got))
+	}
+	if got := filterPrices(prices, []string{"ETH"}); len(got) != 1 || got[0].Symbol != "ETH" {
+		t.Fatalf("unexpected prices %v", got)
+	}
+
+	signals := []domain.Signal{{Symbol: "BTC"}, {Symbol: "SOL"}}
+	if got := filterSignals(signals, []string{"SOL"}); len(got) != 1 || got[0].Symbol != "SOL" {
+		t.Fatalf("unexpected signals %v", got)
+	}
+}
+
+func TestLoadWatchlistCmd(t *testing.T) {
+	svc := testServices()
+	svc.Watchlist = &stubWatchlistStore{symbols: []string{"ETH", "BTC"}}
+	msg, ok := loadWatchlistCmd(svc)().(watchlistMsg)
+	if !ok || len(msg) != 2 || msg[0] != "BTC" {
+		t.Fatalf("unexpected load result %v", msg)
+	}
+
+	svc.Watchlist = &stubWatchlistStore{err: errors.New("db down")}
+	if _, ok := loadWatchlistCmd(svc)().(watchlistErrMsg); !ok {
+		t.Fatal("expected error message")
+	}
+}
+
+func TestSaveWatchlistCmdSessionOnly(t *testing.T) {
+	svc := testServices()
+	if cmd := saveWatchlistCmd(svc, []string{"BTC"}); cmd != nil {
+		t.Fatal("expected no save without a store")
+	}
+	svc.Watchlist = &stubWatchlistStore{}
+	svc.UserID = 0
+	if cmd := saveWatchlistCmd(svc, []string{"BTC"}); cmd != nil {
+		t.Fatal("expected no save for an unregistered user")
+	}
+}
@@ -10,8 +10,8 @@ import (
 	"strings"
 	"time"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 
 	"go.opentelemetry.io/otel/trace"
 )
@@ -308,11 +308,7 @@ func formatPrice(price *domain.PriceSnapshot) string {
 	if price == nil {
 		return "n/a"
 	}
-	sign := ""
-	if price.Change24hPct > 0 {
-		sign = "+"
-	}
-	return fmt.Sprintf("%s price=$%.4f change24h=%s%.2f%% volume24h=$%.0f", price.Symbol, price.PriceUSD, sign, price.Change24hPct, price.Volume24h)
+	return fmt.Sprintf("%s price=%s change24h=%s volume24h=%s", price.Symbol, domain.FormatPrice(price.Symbol, price.PriceUSD), price.Change24hPct, price.Volume24h.Compact())
 }
 
 func formatSignal(signal domain.Signal) string {
@@ -5,8 +5,8 @@ import (
 	"errors"
 	"testing"
 
-	"bug-free-umbrella/internal/domain"
 	"bug-free-umbrella/internal/repository"
+	"bug-free-umbrella/pkg/domain"
 )
 
 type advisorStub struct {
@@ -0,0 +1,416 @@
+package workqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// DefaultStream is the Redis stream tasks are added to.
+	DefaultStream = "work-queue"
+	// Group is the consumer group every process reads the stream in, so
+	// each task goes to one of them.
+	Group = "workers"
+
+	// DefaultClaimAfter is how long a task may stay unacknowledged before
+	// the pending monitor hands it to another consumer.
+	DefaultClaimAfter = time.Minute
+	// DefaultMaxDeliveries is how many times a task is delivered before it
+	// is moved to the dead-letter stream.
+	DefaultMaxDeliveries = 5
+
+	// maxStreamLen caps the stream, approximately; acknowledged tasks are
+	// only trimmed, never deleted.
+	maxStreamLen = 10000
+	readBatch    = 16
+	readBlock    = 5 * time.Second
+	retryAfter   = 5 * time.Second
+	claimBatch   = 32
+)
+
+// Handler runs one task. A nil error acknowledges it; any other leaves it
+// pending, to be delivered again once the pending monitor claims it.
+type Handler func(ctx context.Context, payload []byte) error
+
+// Stats describes the stream at one point in time.
+type Stats struct {
+	Stream    string           `json:"stream"`
+	Group     string           `json:"group"`
+	Length    int64            `json:"length"`
+	Pending   int64            `json:"pending"`
+	Consumers map[string]int64 `json:"pending_by_consumer"`
+	Dead      int64            `json:"dead_letters"`
+}
+
+// Queue is a work queue on a Redis stream read through a consumer group.
+// Tasks survive a crash of the process running them: one that is never
+// acknowledged stays in the group's pending entries until the pending
+// monitor claims it for a live consumer, so every task runs at least once.
+// Handlers must therefore tolerate running a task twice.
+//
+// Run handles tasks one at a time, in the order they were added, so a task
+// runs after those enqueued before it. Claimed tasks run on the monitor.
+type Queue struct {
+	client        *redis.Client
+	stream        string
+	consumer      string
+	claimAfter    time.Duration
+	maxDeliveries int64
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	pending atomic.Int64
+	metrics queueMetrics
+}
+
+// New returns a queue on stream, read as consumer. The consumer name should
+// be stable across restarts so a restarted process resumes its own pending
+// tasks at once instead of waiting for the monitor.
+func New(client *redis.Client, stream, consumer string) *Queue {
+	if strings.TrimSpace(stream) == "" {
+		stream = DefaultStream
+	}
+	q := &Queue{
+		client:        client,
+		stream:        stream,
+		consumer:      consumer,
+		claimAfter:    DefaultClaimAfter,
+		maxDeliveries: DefaultMaxDeliveries,
+		handlers:      make(map[string]Handler),
+	}
+	q.metrics = newQueueMetrics(q)
+	return q
+}
+
+// SetClaimAfter sets how long a task stays pending before it is claimed.
+func (q *Queue) SetClaimAfter(d time.Duration) {
+	if d > 0 {
+		q.claimAfter = d
+	}
+}
+
+// SetMaxDeliveries sets how many deliveries a task gets before it is
+// dead-lettered.
+func (q *Queue) SetMaxDeliveries(n int) {
+	if n > 0 {
+		q.maxDeliveries = int64(n)
+	}
+}
+
+// Handle runs tasks of kind with h. Register every kind before Run.
+func (q *Queue) Handle(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+// DeadLetterStream is where tasks go once they run out of deliveries.
+func (q *Queue) DeadLetterStream() string {
+	return q.stream + ":dead"
+}
+
+// Enqueue adds a task of kind with v, encoded as JSON, as its payload.
+func (q *Queue) Enqueue(ctx context.Context, kind string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encode %s task: %w", kind, err)
+	}
+	err = q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream,
+		MaxLen: maxStreamLen,
+		Approx: true,
+		Values: map[string]any{"kind": kind, "payload": payload},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("enqueue %s task: %w", kind, err)
+	}
+	q.metrics.task(ctx, kind, "enqueued")
+	return nil
+}
+
+// Run consumes tasks until ctx is done. It first reruns the tasks this
+// consumer left pending, such as those in flight when it last stopped.
+func (q *Queue) Run(ctx context.Context) {
+	if !q.waitForGroup(ctx) {
+		return
+	}
+	// Our own pending tasks, oldest first, then new ones.
+	start := "0"
+	for ctx.Err() == nil {
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    Group,
+			Consumer: q.consumer,
+			Streams:  []string{q.stream, start},
+			Count:    readBatch,
+			Block:    readBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("work queue: read %s failed: %v", q.stream, err)
+			if isNoGroup(err) {
+				q.waitForGroup(ctx)
+			} else {
+				sleep(ctx, retryAfter)
+			}
+			continue
+		}
+		var last string
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				q.process(ctx, msg)
+				last = msg.ID
+			}
+		}
+		if start != ">" {
+			// Move past the pending tasks just run, failed ones included;
+			// the monitor claims those again later.
+			start = last
+			if last == "" {
+				start = ">"
+			}
+		}
+	}
+}
+
+// MonitorPending checks the group's pending entries every interval until
+// ctx is done: tasks idle longer than the claim delay are claimed and run
+// by this consumer, or dead-lettered once out of deliveries.
+func (q *Queue) MonitorPending(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		every = q.claimAfter / 2
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := q.ReclaimIdle(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("work queue: pending check on %s failed: %v", q.stream, err)
+			}
+		}
+	}
+}
+
+// ReclaimIdle runs one pending check and returns how many tasks it claimed
+// or dead-lettered.
+func (q *Queue) ReclaimIdle(ctx context.Context) (int, error) {
+	summary, err := q.client.XPending(ctx, q.stream, Group).Result()
+	if err != nil {
+		if isNoGroup(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	q.pending.Store(summary.Count)
+	if summary.Count == 0 {
+		return 0, nil
+	}
+
+	idle, err := q.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: q.stream,
+		Group:  Group,
+		Idle:   q.claimAfter,
+		Start:  "-",
+		End:    "+",
+		Count:  claimBatch,
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+	var claim []string
+	handled := 0
+	for _, entry := range idle {
+		if entry.RetryCount < q.maxDeliveries {
+			claim = append(claim, entry.ID)
+			continue
+		}
+		if err := q.deadLetter(ctx, entry); err != nil {
+			log.Printf("work queue: dead-letter %s failed: %v", entry.ID, err)
+			continue
+		}
+		handled++
+	}
+	if len(claim) == 0 {
+		return handled, nil
+	}
+	msgs, err := q.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   q.stream,
+		Group:    Group,
+		Consumer: q.consumer,
+		MinIdle:  q.claimAfter,
+		Messages: claim,
+	}).Result()
+	if err != nil {
+		return handled, err
+	}
+	for _, msg := range msgs {
+		log.Printf("work queue: claimed %s task %s, idle over %s", msgKind(msg), msg.ID, q.claimAfter)
+		q.process(ctx, msg)
+		handled++
+	}
+	return handled, nil
+}
+
+// Stats returns the stream's length, its pending entries by consumer and
+// the dead-letter count.
+func (q *Queue) Stats(ctx context.Context) (Stats, error) {
+	stats := Stats{Stream: q.stream, Group: Group, Consumers: map[string]int64{}}
+	length, err := q.client.XLen(ctx, q.stream).Result()
+	if err != nil {
+		return stats, err
+	}
+	stats.Length = length
+	summary, err := q.client.XPending(ctx, q.stream, Group).Result()
+	if err != nil && !isNoGroup(err) {
+		return stats, err
+	}
+	if summary != nil {
+		stats.Pending = summary.Count
+		stats.Consumers = summary.Consumers
+		q.pending.Store(summary.Count)
+	}
+	if stats.Dead, err = q.client.XLen(ctx, q.DeadLetterStream()).Result(); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+func (q *Queue) process(ctx context.Context, msg redis.XMessage) {
+	kind := msgKind(msg)
+	q.mu.RLock()
+	h, ok := q.handlers[kind]
+	q.mu.RUnlock()
+	if !ok {
+		// Left pending: a consumer that knows the kind may claim it, and
+		// otherwise it ends up dead-lettered.
+		log.Printf("work queue: no handler for %s task %s", kind, msg.ID)
+		q.metrics.task(ctx, kind, "unhandled")
+		return
+	}
+	payload, _ := msg.Values["payload"].(string)
+	if err := h(ctx, []byte(payload)); err != nil {
+		log.Printf("work queue: %s task %s failed, will retry: %v", kind, msg.ID, err)
+		q.metrics.task(ctx, kind, "failed")
+		return
+	}
+	if err := q.client.XAck(ctx, q.stream, Group, msg.ID).Err(); err != nil {
+		log.Printf("work queue: ack %s failed: %v", msg.ID, err)
+	}
+	q.metrics.task(ctx, kind, "done")
+}
+
+func (q *Queue) deadLetter(ctx context.Context, entry redis.XPendingExt) error {
+	msgs, err := q.client.XRange(ctx, q.stream, entry.ID, entry.ID).Result()
+	if err != nil {
+		return err
+	}
+	// A task trimmed from the stream has nothing left to keep.
+	if len(msgs) == 1 {
+		values := msgs[0].Values
+		values["id"] = entry.ID
+		values["deliveries"] = entry.RetryCount
+		if err := q.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.DeadLetterStream(),
+			MaxLen: maxStreamLen,
+			Approx: true,
+			Values: values,
+		}).Err(); err != nil {
+			return err
+		}
+		log.Printf("work queue: %s task %s dead-lettered after %d deliveries", msgKind(msgs[0]), entry.ID, entry.RetryCount)
+		q.metrics.task(ctx, msgKind(msgs[0]), "dead")
+	}
+	return q.client.XAck(ctx, q.stream, Group, entry.ID).Err()
+}
+
+// waitForGroup creates the consumer group, and the stream with it, retrying
+// until it succeeds or ctx is done.
+func (q *Queue) waitForGroup(ctx context.Context) bool {
+	for {
+		err := q.client.XGroupCreateMkStream(ctx, q.stream, Group, "0").Err()
+		if err == nil || strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return true
+		}
+		log.Printf("work queue: create group on %s failed: %v", q.stream, err)
+		if !sleep(ctx, retryAfter) {
+			return false
+		}
+	}
+}
+
+func isNoGroup(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "NOGROUP")
+}
+
+func msgKind(msg redis.XMessage) string {
+	kind, _ := msg.Values["kind"].(string)
+	return kind
+}
+
+// sleep waits for d and reports whether ctx is still live.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+type queueMetrics struct {
+	tasks metric.Int64Counter
+}
+
+func newQueueMetrics(q *Queue) queueMetrics {
+	meter := otel.Meter("bug-free-umbrella/workqueue")
+	var m queueMetrics
+	var err error
+	if m.tasks, err = meter.Int64Counter("workqueue.tasks",
+		metric.WithDescription("Work queue tasks by kind and outcome"),
+	); err != nil {
+		log.Printf("work queue tasks counter: %v", err)
+	}
+	_, err = meter.Int64ObservableGauge("workqueue.pending",
+		metric.WithDescription("Tasks delivered but not yet acknowledged, as of the last pending check"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(q.pending.Load(), metric.WithAttributes(attribute.String("stream", q.stream)))
+			return nil
+		}),
+	)
+	if err != nil {
+		log.Printf("work queue pending gauge: %v", err)
+	}
+	return m
+}
+
+func (m queueMetrics) task(ctx context.Context, kind, outcome string) {
+	if m.tasks == nil {
+		return
+	}
+	m.tasks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("kind", kind),
+		attribute.String("outcome", outcome),
+	))
+}
@@ -0,0 +1,267 @@
+package workqueue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	mini, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(mini.Close)
+	client := redis.NewClient(&redis.Options{Addr: mini.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func receive(t *testing.T, ch <-chan string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for task")
+		return ""
+	}
+}
+
+// strand delivers the stream's next task to consumer without acknowledging
+// it, as if that consumer crashed while running it.
+func strand(t *testing.T, client *redis.Client, stream, consumer string) {
+	t.Helper()
+	ctx := context.Background()
+	if err := client.XGroupCreateMkStream(ctx, stream, Group, "0").Err(); err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: Group, Consumer: consumer, Streams: []string{stream, ">"}, Count: 1,
+	}).Err(); err != nil {
+		t.Fatalf("read group: %v", err)
+	}
+}
+
+func TestQueueRunsTasksInOrderAndAcks(t *testing.T) {
+	client := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(client, "", "worker-1")
+	got := make(chan string, 4)
+	q.Handle("echo", func(_ context.Context, payload []byte) error {
+		got <- string(payload)
+		return nil
+	})
+	if err := q.Enqueue(ctx, "echo", "first"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if err := q.Enqueue(ctx, "echo", "second"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	go q.Run(ctx)
+
+	if v := receive(t, got); v != `"first"` {
+		t.Fatalf("expected first task first, got %s", v)
+	}
+	if v := receive(t, got); v != `"second"` {
+		t.Fatalf("expected second task, got %s", v)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats, err := q.Stats(ctx)
+		if err != nil {
+			t.Fatalf("stats: %v", err)
+		}
+		if stats.Pending == 0 && stats.Length == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected both tasks acknowledged, got %+v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueueResumesItsOwnPendingTasks(t *testing.T) {
+	client := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(client, "renders", "worker-1")
+	if err := q.Enqueue(ctx, "echo", "in flight"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	strand(t, client, "renders", "worker-1")
+
+	got := make(chan string, 1)
+	q.Handle("echo", func(_ context.Context, payload []byte) error {
+		got <- string(payload)
+		return nil
+	})
+	go q.Run(ctx)
+
+	if v := receive(t, got); v != `"in flight"` {
+		t.Fatalf("expected the stranded task to rerun, got %s", v)
+	}
+}
+
+func TestReclaimIdleClaimsStrandedTasks(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	q := New(client, "renders", "worker-2")
+	q.SetClaimAfter(time.Millisecond)
+	attempts := 0
+	q.Handle("echo", func(context.Context, []byte) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("renderer busy")
+		}
+		return nil
+	})
+	if err := q.Enqueue(ctx, "echo", "stranded"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	strand(t, client, "renders", "worker-1")
+	time.Sleep(5 * time.Millisecond)
+
+	// The first claim fails and leaves the task pending; the next one runs it.
+	if n, err := q.ReclaimIdle(ctx); err != nil || n != 1 {
+		t.Fatalf("expected one claimed task, got %d %v", n, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if n, err := q.ReclaimIdle(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the failed task claimed again, got %d %v", n, err)
+	}
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if attempts != 2 || stats.Pending != 0 || stats.Dead != 0 {
+		t.Fatalf("expected the task done on its second claim, got attempts=%d %+v", attempts, stats)
+	}
+}
+
+func TestReclaimIdleDeadLettersExhaustedTasks(t *testing.T) {
+	client := newTestRedis(t)
+	ctx := context.Background()
+
+	q := New(client, "renders", "worker-2")
+	q.SetClaimAfter(time.Millisecond)
+	q.SetMaxDeliveries(1)
+	q.Handle("echo", func(context.Context, []byte) error {
+		t.Fatal("an exhausted task must not run again")
+		return nil
+	})
+	if err := q.Enqueue(ctx, "echo", "poison"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	strand(t, client, "renders", "worker-1")
+	time.Sleep(5 * time.Millisecond)
+
+	if n, err := q.ReclaimIdle(ctx); err != nil || n != 1 {
+		t.Fatalf("expected one dead-lettered task, got %d %v", n, err)
+	}
+	stats, err := q.Stats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Pending != 0 || stats.Dead != 1 {
+		t.Fatalf("expected the task moved to dead letters, got %+v", stats)
+	}
+	dead, err := client.XRange(ctx, q.DeadLetterStream(), "-", "+").Result()
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("read dead letters: %v %v", dead, err)
+	}
+	if dead[0].Values["kind"] != "echo" || dead[0].Values["payload"] != `"poison"` {
+		t.Fatalf("expected the task kept in the dead letter, got %v", dead[0].Values)
+	}
+}
+
+type alertRecorder struct {
+	got chan []domain.Signal
+}
+
+func (r alertRecorder) NotifySignals(_ context.Context, signals []domain.Signal) error {
+	r.got <- signals
+	return errors.New("telegram unavailable")
+}
+
+func TestSignalAlertsDeliverQueuedBatches(t *testing.T) {
+	client := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := New(client, "", "worker-1")
+	sink := alertRecorder{got: make(chan []domain.Signal, 1)}
+	alerts := NewSignalAlerts(q, sink)
+	signals := []domain.Signal{{ID: 7, Symbol: "BTC", Interval: "1h", Indicator: "rsi"}}
+	if err := alerts.NotifySignals(ctx, signals); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	go q.Run(ctx)
+
+	select {
+	case got := <-sink.got:
+		if len(got) != 1 || got[0].ID != 7 || got[0].Symbol != "BTC" {
+			t.Fatalf("unexpected batch: %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the alert batch")
+	}
+	// A sink error is logged, not retried, so the batch is acknowledged.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats, err := q.Stats(ctx)
+		if err == nil && stats.Pending == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the batch acknowledged, got %+v %v", stats, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestQueueMetricsUseGlobalMeterProvider(t *testing.T) {
+	prev := otel.GetMeterProvider()
+	t.Cleanup(func() { otel.SetMeterProvider(prev) })
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	client := newTestRedis(t)
+	ctx := context.Background()
+	q := New(client, "", "worker-1")
+	if err := q.Enqueue(ctx, "echo", "first"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if _, err := q.Stats(ctx); err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	found := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, md := range sm.Metrics {
+			found[md.Name] = true
+		}
+	}
+	if !found["workqueue.tasks"] || !found["workqueue.pending"] {
+		t.Fatalf("expected workqueue.tasks and workqueue.pending, got %v", found)
+	}
+}
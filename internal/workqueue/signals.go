@@ -0,0 +1,97 @@
+package workqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// Task kinds for signal work.
+const (
+	KindSignalImages = "signal_images"
+	KindSignalAlerts = "signal_alerts"
+)
+
+type signalsTask struct {
+	Signals []domain.Signal `json:"signals"`
+}
+
+func decodeSignals(payload []byte) ([]domain.Signal, error) {
+	var task signalsTask
+	if err := json.Unmarshal(payload, &task); err != nil {
+		return nil, fmt.Errorf("decode signals task: %w", err)
+	}
+	return task.Signals, nil
+}
+
+// SignalImageRenderer renders and stores the charts of stored signals.
+type SignalImageRenderer interface {
+	RenderSignalImages(ctx context.Context, signals []domain.Signal) error
+}
+
+// SignalImages queues chart renders for new signals and runs them with the
+// renderer.
+type SignalImages struct {
+	queue *Queue
+}
+
+// NewSignalImages registers renderer as the queue's signal image handler.
+func NewSignalImages(q *Queue, renderer SignalImageRenderer) *SignalImages {
+	q.Handle(KindSignalImages, func(ctx context.Context, payload []byte) error {
+		signals, err := decodeSignals(payload)
+		if err != nil {
+			return err
+		}
+		return renderer.RenderSignalImages(ctx, signals)
+	})
+	return &SignalImages{queue: q}
+}
+
+// EnqueueSignalImages queues one task rendering the charts of signals.
+func (s *SignalImages) EnqueueSignalImages(ctx context.Context, signals []domain.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	return s.queue.Enqueue(ctx, KindSignalImages, signalsTask{Signals: signals})
+}
+
+// SignalAlertSink delivers signal alerts, such as the Telegram dispatcher.
+type SignalAlertSink interface {
+	NotifySignals(ctx context.Context, signals []domain.Signal) error
+}
+
+// SignalAlerts is a signal poller sink that queues each batch of signals
+// and delivers it to the wrapped sink from the queue. A batch queued after
+// its charts' render task is delivered once they are stored.
+type SignalAlerts struct {
+	queue *Queue
+}
+
+// NewSignalAlerts registers sink as the queue's signal alert handler. The
+// queue makes sure a batch reaches the sink even if the process stops; a
+// sink error is only logged, since a retry would resend the batch to every
+// chat and the Telegram dispatcher retries its failed sends itself.
+func NewSignalAlerts(q *Queue, sink SignalAlertSink) *SignalAlerts {
+	q.Handle(KindSignalAlerts, func(ctx context.Context, payload []byte) error {
+		signals, err := decodeSignals(payload)
+		if err != nil {
+			return err
+		}
+		if err := sink.NotifySignals(ctx, signals); err != nil {
+			log.Printf("work queue: signal alert dispatch error: %v", err)
+		}
+		return nil
+	})
+	return &SignalAlerts{queue: q}
+}
+
+// NotifySignals queues signals for delivery.
+func (a *SignalAlerts) NotifySignals(ctx context.Context, signals []domain.Signal) error {
+	if len(signals) == 0 {
+		return nil
+	}
+	return a.queue.Enqueue(ctx, KindSignalAlerts, signalsTask{Signals: signals})
+}
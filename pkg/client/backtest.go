@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// DailyAccuracy is one model's hit rate on one UTC day.
+type DailyAccuracy struct {
+	ModelKey string
+	DayUTC   time.Time
+	Total    int
+	Correct  int
+	Accuracy float64
+}
+
+// Contribution is one component's share of an ensemble score.
+type Contribution struct {
+	Component    string  `json:"component"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// PredictionBreakdown splits an ensemble prediction's score into its
+// components' contributions, which sum to Score.
+type PredictionBreakdown struct {
+	Prediction    domain.MLPrediction `json:"prediction"`
+	Components    []Contribution      `json:"components"`
+	UndampedScore float64             `json:"undamped_score"`
+	Score         float64             `json:"score"`
+}
+
+// BacktestSummary returns each model's all-time accuracy, dated now.
+func (c *Client) BacktestSummary(ctx context.Context) ([]DailyAccuracy, error) {
+	var out struct {
+		Summary []DailyAccuracy `json:"summary"`
+	}
+	if err := c.getJSON(ctx, "/api/backtest/summary", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Summary, nil
+}
+
+// BacktestDaily returns daily accuracy over the last days, 30 when zero, for
+// model or for every model when it is empty.
+func (c *Client) BacktestDaily(ctx context.Context, model string, days int) ([]DailyAccuracy, error) {
+	var out struct {
+		Daily []DailyAccuracy `json:"daily"`
+	}
+	if err := c.getJSON(ctx, "/api/backtest/daily", modelDaysQuery(model, days), &out); err != nil {
+		return nil, err
+	}
+	return out.Daily, nil
+}
+
+// BacktestPredictions returns the most recent resolved ML predictions, 50
+// when limit is zero and at most 200.
+func (c *Client) BacktestPredictions(ctx context.Context, limit int) ([]domain.MLPrediction, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out struct {
+		Predictions []domain.MLPrediction `json:"predictions"`
+	}
+	if err := c.getJSON(ctx, "/api/backtest/predictions", query, &out); err != nil {
+		return nil, err
+	}
+	return out.Predictions, nil
+}
+
+// BacktestRisk returns each model's hit rate and average return per risk
+// level over the last days, or all time when days is zero.
+func (c *Client) BacktestRisk(ctx context.Context, days int) ([]domain.RiskLevelStats, error) {
+	var out struct {
+		Risk []domain.RiskLevelStats `json:"risk"`
+	}
+	if err := c.getJSON(ctx, "/api/backtest/risk", modelDaysQuery("", days), &out); err != nil {
+		return nil, err
+	}
+	return out.Risk, nil
+}
+
+// BacktestCoverage returns the daily share of directional calls over the
+// last days, 30 when zero, for model or for every model when it is empty.
+func (c *Client) BacktestCoverage(ctx context.Context, model string, days int) ([]domain.CoverageDay, error) {
+	var out struct {
+		Coverage []domain.CoverageDay `json:"coverage"`
+	}
+	if err := c.getJSON(ctx, "/api/backtest/coverage", modelDaysQuery(model, days), &out); err != nil {
+		return nil, err
+	}
+	return out.Coverage, nil
+}
+
+// PredictionBreakdown returns the score breakdown of the ensemble prediction
+// with id. The API answers 404 for an unknown prediction and 422 for one
+// stored without its components.
+func (c *Client) PredictionBreakdown(ctx context.Context, id int64) (*PredictionBreakdown, error) {
+	var out PredictionBreakdown
+	if err := c.getJSON(ctx, "/api/predictions/"+strconv.FormatInt(id, 10)+"/breakdown", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func modelDaysQuery(model string, days int) url.Values {
+	query := url.Values{}
+	if model != "" {
+		query.Set("model", model)
+	}
+	if days > 0 {
+		query.Set("days", strconv.Itoa(days))
+	}
+	return query
+}
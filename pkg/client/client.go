@@ -0,0 +1,281 @@
+// Package client is a Go SDK for the bug-free-umbrella REST API. It returns
+// the same domain types the server uses, retries requests that failed for
+// transient reasons, and honours the caller's context throughout.
+//
+//	c := client.New("https://api.example.com", os.Getenv("REST_API_KEY"))
+//	btc, err := c.Price(ctx, "BTC", "")
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	mrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is how many times a failed request is retried.
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the pause before the first retry. It doubles
+	// per retry up to MaxRetryBackoff.
+	DefaultRetryBackoff = 500 * time.Millisecond
+	// MaxRetryBackoff caps the pause between retries. A Retry-After longer
+	// than this, such as during maintenance, is returned as an error
+	// instead of waited out.
+	MaxRetryBackoff = 10 * time.Second
+
+	// Each attempt gets the server's own deadline plus a margin: the REST
+	// timeout for reads, and the long one for the POST triggers.
+	requestTimeout     = 35 * time.Second
+	longRequestTimeout = 605 * time.Second
+
+	apiKeyHeader  = "X-API-Key"
+	idemKeyHeader = "Idempotency-Key"
+)
+
+// APIError is a response the API answered with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	// Message is the "error" field of the body, or the body itself when it
+	// is not the API's JSON error.
+	Message string
+	// RetryAfter is the server's Retry-After, when it sent one.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an API 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client calls the REST API at one base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// New returns a client for the API at baseURL, such as
+// "http://localhost:8080", authenticating with apiKey. An empty key sends
+// none, for servers running without REST_API_KEY.
+func New(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		apiKey:     strings.TrimSpace(apiKey),
+		httpClient: &http.Client{},
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultRetryBackoff,
+		sleep:      sleepContext,
+	}
+}
+
+// SetHTTPClient replaces the HTTP client, for custom transports or proxies.
+// Each attempt already has a deadline, so hc needs no Timeout.
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	if hc != nil {
+		c.httpClient = hc
+	}
+}
+
+// SetRetries sets how many times a request is retried and the pause before
+// the first retry. Zero retries turns retrying off.
+func (c *Client) SetRetries(maxRetries int, backoff time.Duration) {
+	c.maxRetries = max(maxRetries, 0)
+	if backoff > 0 {
+		c.backoff = backoff
+	}
+}
+
+// getJSON decodes the JSON answer to a GET of path with query into out.
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out any) error {
+	body, _, err := c.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	return decode(body, out)
+}
+
+// postJSON sends in as JSON to path and decodes the answer into out.
+func (c *Client) postJSON(ctx context.Context, path string, in, out any) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+	body, _, err := c.do(ctx, http.MethodPost, path, nil, payload)
+	if err != nil {
+		return err
+	}
+	return decode(body, out)
+}
+
+// do sends the request, retrying network errors, attempts that ran out of
+// time, 429s and 502-504s until the retries or ctx run out. POSTs carry one
+// Idempotency-Key across their retries, so a retry of a request the server
+// did run returns the first answer instead of running it again.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, payload []byte) ([]byte, http.Header, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	idemKey := ""
+	if method == http.MethodPost {
+		idemKey = newIdempotencyKey()
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, header, err := c.attempt(ctx, method, target, payload, idemKey)
+		if err == nil {
+			return body, header, nil
+		}
+		wait, retry := c.retryAfter(attempt, err)
+		if !retry || ctx.Err() != nil {
+			return nil, nil, err
+		}
+		if c.sleep(ctx, wait) != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, target string, payload []byte, idemKey string) ([]byte, http.Header, error) {
+	timeout := requestTimeout
+	if method == http.MethodPost {
+		timeout = longRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if idemKey != "" {
+		req.Header.Set(idemKeyHeader, idemKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, resp.Header, nil
+	}
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(body)), RetryAfter: parseRetryAfter(resp.Header)}
+	var payloadErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &payloadErr) == nil && payloadErr.Error != "" {
+		apiErr.Message = payloadErr.Error
+	}
+	return nil, nil, apiErr
+}
+
+// retryAfter returns the pause before retrying a request that failed with
+// err on attempt, counted from 0, and whether to retry at all.
+func (c *Client) retryAfter(attempt int, err error) (time.Duration, bool) {
+	if attempt >= c.maxRetries {
+		return 0, false
+	}
+	wait := c.backoff
+	for i := 0; i < attempt && wait < MaxRetryBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, MaxRetryBackoff)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		if apiErr.RetryAfter > MaxRetryBackoff {
+			return 0, false
+		}
+		wait = max(wait, apiErr.RetryAfter)
+	}
+	// Up to a quarter of jitter keeps clients that failed together from
+	// retrying in lockstep.
+	return wait + mrand.N(wait/4+1), true
+}
+
+func decode(body []byte, out any) error {
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is missing or unreadable.
+func parseRetryAfter(h http.Header) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+func newIdempotencyKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// sleepContext waits for d, failing at once when ctx would expire first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return fmt.Errorf("retry in %s would pass the context deadline", d.Round(time.Millisecond))
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,254 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// newTestClient returns a client for srv whose retries don't sleep, and the
+// pauses it would have slept.
+func newTestClient(t *testing.T, srv *httptest.Server) (*Client, *[]time.Duration) {
+	t.Helper()
+	t.Cleanup(srv.Close)
+	c := New(srv.URL+"/", "secret")
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return c, &waits
+}
+
+func TestPriceSendsKeyAndDecodesSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/prices/BTC" || r.URL.Query().Get("currency") != "EUR" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("X-API-Key") != "secret" {
+			t.Errorf("expected the API key header, got %q", r.Header.Get("X-API-Key"))
+		}
+		_, _ = io.WriteString(w, `{"symbol":"BTC","price_usd":65000.5,"last_updated_unix":1791504000}`)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	got, err := c.Price(context.Background(), "btc", "eur")
+	if err != nil {
+		t.Fatalf("price: %v", err)
+	}
+	if got.Symbol != "BTC" || got.PriceUSD != 65000.5 {
+		t.Fatalf("unexpected snapshot: %+v", got)
+	}
+}
+
+func TestRetriesTransientErrorsThenSucceeds(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, `{"error":"maintenance"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"prices":[{"symbol":"ETH","price_usd":3000}]}`)
+	}))
+	c, waits := newTestClient(t, srv)
+
+	prices, err := c.Prices(context.Background(), "")
+	if err != nil {
+		t.Fatalf("prices: %v", err)
+	}
+	if calls != 3 || len(prices) != 1 || prices[0].Symbol != "ETH" {
+		t.Fatalf("expected success on the third call, got calls=%d %+v", calls, prices)
+	}
+	if len(*waits) != 2 || (*waits)[0] < 2*time.Second {
+		t.Fatalf("expected two pauses honouring Retry-After, got %v", *waits)
+	}
+}
+
+func TestDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":"symbol not found"}`)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	_, err := c.Price(context.Background(), "NOPE", "")
+	if !IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "symbol not found" {
+		t.Fatalf("expected the API message, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected no retry, got %d calls", calls)
+	}
+}
+
+func TestGivesUpWhenRetryAfterIsTooLong(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	c, waits := newTestClient(t, srv)
+
+	_, err := c.Prices(context.Background(), "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 10*time.Minute {
+		t.Fatalf("expected the 503 with its Retry-After, got %v", err)
+	}
+	if calls != 1 || len(*waits) != 0 {
+		t.Fatalf("expected no retry, got calls=%d waits=%v", calls, *waits)
+	}
+}
+
+func TestStopsRetryingAtMaxRetries(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	c, waits := newTestClient(t, srv)
+	c.SetRetries(2, time.Second)
+
+	if _, err := c.Prices(context.Background(), ""); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 3 {
+		t.Fatalf("expected the first call and two retries, got %d", calls)
+	}
+	if w := *waits; len(w) != 2 || w[0] < time.Second || w[1] < 2*time.Second {
+		t.Fatalf("expected doubling pauses, got %v", w)
+	}
+}
+
+func TestCancelledContextStopsRetries(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	if _, err := c.Prices(ctx, ""); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected no retry after cancel, got %d calls", calls)
+	}
+}
+
+func TestGenerateSignalsReusesIdempotencyKeyAcrossRetries(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/signals/generate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var in struct {
+			Symbols   []string `json:"symbols"`
+			Intervals []string `json:"intervals"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Symbols) != 1 || in.Symbols[0] != "BTC" {
+			t.Errorf("unexpected body %+v %v", in, err)
+		}
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		first := len(keys) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		_, _ = io.WriteString(w, `{"results":[{"symbol":"BTC","signals":[{"symbol":"BTC","interval":"1h","indicator":"rsi","direction":"long","risk":3}]}],"succeeded":1,"failed":0}`)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	results, err := c.GenerateSignals(context.Background(), []string{"BTC"}, []string{"1h"})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if len(results) != 1 || len(results[0].Signals) != 1 || results[0].Signals[0].Risk != domain.RiskLevel3 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected one idempotency key across both attempts, got %q", keys)
+	}
+}
+
+func TestSignalsEncodesFilters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("symbol") != "ETH" || q.Get("risk") != "2" || q.Get("indicator") != "macd" || q.Get("limit") != "10" || q.Has("version") {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		_, _ = io.WriteString(w, `{"signals":[{"id":4,"symbol":"ETH","interval":"4h","indicator":"macd","direction":"short","risk":2}]}`)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	signals, err := c.Signals(context.Background(), SignalQuery{Symbol: "eth", Risk: domain.RiskLevel2, Indicator: "macd", Limit: 10})
+	if err != nil {
+		t.Fatalf("signals: %v", err)
+	}
+	if len(signals) != 1 || signals[0].ID != 4 || signals[0].Direction != domain.DirectionShort {
+		t.Fatalf("unexpected signals: %+v", signals)
+	}
+}
+
+func TestCandlesDecodesSessions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/candles/SOL" || r.URL.Query().Get("interval") != "4h" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = io.WriteString(w, `{"symbol":"SOL","interval":"4h","price_decimals":2,"candles":[{"symbol":"SOL","interval":"4h","open_time":"2026-10-01T00:00:00Z","open":150,"high":155,"low":149,"close":154,"volume":1000,"sessions":["asia"]}]}`)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	series, err := c.Candles(context.Background(), "sol", CandleQuery{Interval: "4h"})
+	if err != nil {
+		t.Fatalf("candles: %v", err)
+	}
+	if series.PriceDecimals != 2 || len(series.Candles) != 1 {
+		t.Fatalf("unexpected series: %+v", series)
+	}
+	candle := series.Candles[0]
+	if candle.Close != 154 || !candle.OpenTime.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) || len(candle.Sessions) != 1 {
+		t.Fatalf("unexpected candle: %+v", candle)
+	}
+}
+
+func TestPredictionBreakdownDecodesComponents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/predictions/9/breakdown" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		_, _ = io.WriteString(w, `{"prediction":{"ID":9,"ModelKey":"ensemble_v1"},"components":[{"component":"classic","value":0.4,"weight":0.5,"contribution":0.2}],"undamped_score":0.2,"score":0.2}`)
+	}))
+	c, _ := newTestClient(t, srv)
+
+	got, err := c.PredictionBreakdown(context.Background(), 9)
+	if err != nil {
+		t.Fatalf("breakdown: %v", err)
+	}
+	if got.Prediction.ID != 9 || len(got.Components) != 1 || got.Components[0].Contribution != 0.2 {
+		t.Fatalf("unexpected breakdown: %+v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// CandleQuery selects candles. Zero fields use the API defaults: the 1h
+// interval and 100 candles.
+type CandleQuery struct {
+	Interval string
+	// Limit is at most 500.
+	Limit int
+}
+
+// Candle is a candle with the trading sessions (asia, eu, us, weekend) it
+// overlaps.
+type Candle struct {
+	domain.Candle
+	Sessions []string `json:"sessions"`
+}
+
+// CandleSeries is one symbol's candles on one interval, oldest first.
+type CandleSeries struct {
+	Symbol        string   `json:"symbol"`
+	Interval      string   `json:"interval"`
+	PriceDecimals int      `json:"price_decimals"`
+	Candles       []Candle `json:"candles"`
+}
+
+// Price returns symbol's latest price. A non-empty currency (EUR, GBP, JPY)
+// adds a quote in that currency.
+func (c *Client) Price(ctx context.Context, symbol, currency string) (*domain.PriceSnapshot, error) {
+	var out domain.PriceSnapshot
+	if err := c.getJSON(ctx, "/api/prices/"+url.PathEscape(strings.ToUpper(symbol)), currencyQuery(currency), &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Prices returns the latest price of every tracked symbol.
+func (c *Client) Prices(ctx context.Context, currency string) ([]domain.PriceSnapshot, error) {
+	var out struct {
+		Prices []domain.PriceSnapshot `json:"prices"`
+	}
+	if err := c.getJSON(ctx, "/api/prices", currencyQuery(currency), &out); err != nil {
+		return nil, err
+	}
+	return out.Prices, nil
+}
+
+// Candles returns symbol's most recent candles.
+func (c *Client) Candles(ctx context.Context, symbol string, q CandleQuery) (*CandleSeries, error) {
+	query := url.Values{}
+	if q.Interval != "" {
+		query.Set("interval", q.Interval)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var out CandleSeries
+	if err := c.getJSON(ctx, "/api/candles/"+url.PathEscape(strings.ToUpper(symbol)), query, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func currencyQuery(currency string) url.Values {
+	if currency = strings.TrimSpace(currency); currency == "" {
+		return nil
+	}
+	return url.Values{"currency": {strings.ToUpper(currency)}}
+}
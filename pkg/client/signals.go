@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"bug-free-umbrella/pkg/domain"
+)
+
+// SignalQuery filters signals. Zero fields match every signal; Limit
+// defaults to 50 and is at most 200.
+type SignalQuery struct {
+	Symbol    string
+	Risk      domain.RiskLevel
+	Indicator string
+	Version   string
+	Limit     int
+}
+
+// SignalImage is a signal's rendered chart.
+type SignalImage struct {
+	MimeType string
+	Bytes    []byte
+}
+
+// Signals returns recent signals matching q, newest first.
+func (c *Client) Signals(ctx context.Context, q SignalQuery) ([]domain.Signal, error) {
+	query := url.Values{}
+	if q.Symbol != "" {
+		query.Set("symbol", strings.ToUpper(q.Symbol))
+	}
+	if q.Risk != 0 {
+		query.Set("risk", strconv.Itoa(int(q.Risk)))
+	}
+	if q.Indicator != "" {
+		query.Set("indicator", q.Indicator)
+	}
+	if q.Version != "" {
+		query.Set("version", q.Version)
+	}
+	if q.Limit > 0 {
+		query.Set("limit", strconv.Itoa(q.Limit))
+	}
+	var out struct {
+		Signals []domain.Signal `json:"signals"`
+	}
+	if err := c.getJSON(ctx, "/api/signals", query, &out); err != nil {
+		return nil, err
+	}
+	return out.Signals, nil
+}
+
+// SignalImageByID returns the chart of the signal with id. The API answers
+// 404, see IsNotFound, when the chart is missing or expired.
+func (c *Client) SignalImageByID(ctx context.Context, id int64) (*SignalImage, error) {
+	body, header, err := c.do(ctx, http.MethodGet, "/api/signals/"+strconv.FormatInt(id, 10)+"/image", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SignalImage{MimeType: header.Get("Content-Type"), Bytes: body}, nil
+}
+
+// GenerateSignals runs signal detection now for each symbol on intervals,
+// or every interval when it is empty. Each result carries that symbol's
+// signals or error. It needs an operator key.
+func (c *Client) GenerateSignals(ctx context.Context, symbols, intervals []string) ([]domain.SignalGenerationResult, error) {
+	in := struct {
+		Symbols   []string `json:"symbols"`
+		Intervals []string `json:"intervals,omitempty"`
+	}{Symbols: symbols, Intervals: intervals}
+	var out struct {
+		Results []domain.SignalGenerationResult `json:"results"`
+	}
+	if err := c.postJSON(ctx, "/api/signals/generate", in, &out); err != nil {
+		return nil, err
+	}
+	return out.Results, nil
+}
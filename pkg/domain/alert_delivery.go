@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+type AlertDeliveryStatus string
+
+const (
+	AlertDeliveryPending AlertDeliveryStatus = "pending"
+	AlertDeliverySent    AlertDeliveryStatus = "sent"
+	AlertDeliveryFailed  AlertDeliveryStatus = "failed"
+	AlertDeliveryDead    AlertDeliveryStatus = "dead"
+)
+
+// AlertDelivery is one outgoing Telegram notification to one chat. Failed
+// deliveries are retried until they succeed or become dead letters.
+// SignalID is zero for operator broadcasts. Signals that fire together are
+// grouped into one delivery: SignalIDs lists them all, for an album of their
+// charts, and SignalID is the first.
+type AlertDelivery struct {
+	ID            int64               `json:"id"`
+	ChatID        int64               `json:"chat_id"`
+	SignalID      int64               `json:"signal_id,omitempty"`
+	SignalIDs     []int64             `json:"signal_ids,omitempty"`
+	Message       string              `json:"message"`
+	Status        AlertDeliveryStatus `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"last_error,omitempty"`
+	NextAttemptAt time.Time           `json:"next_attempt_at"`
+	CreatedAt     time.Time           `json:"created_at"`
+	DeliveredAt   *time.Time          `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	AuditActionModelActivate  = "model.activate"
+	AuditActionSymbolAdd      = "symbol.add"
+	AuditActionSymbolRemove   = "symbol.remove"
+	AuditActionSymbolPause    = "symbol.pause"
+	AuditActionBroadcastSend  = "broadcast.send"
+	AuditActionAPIKeyCreate   = "api_key.create"
+	AuditActionAPIKeyRevoke   = "api_key.revoke"
+	AuditActionAlertRedeliver = "alert.redeliver"
+	AuditActionWebhookCreate  = "webhook.create"
+	AuditActionWebhookDisable = "webhook.disable"
+	AuditActionMaintenanceSet = "maintenance.set"
+	AuditActionLabelOverride  = "label.override"
+	AuditActionImagesPurge    = "signal_images.purge"
+)
+
+const (
+	AuditEntityModel         = "ml_model"
+	AuditEntitySymbol        = "symbol"
+	AuditEntityBroadcast     = "broadcast"
+	AuditEntityAPIKey        = "api_key"
+	AuditEntityAlertDelivery = "alert_delivery"
+	AuditEntityWebhook       = "signal_webhook"
+	AuditEntityMaintenance   = "maintenance"
+	AuditEntityLabel         = "ml_label"
+	AuditEntitySignalImages  = "signal_images"
+)
+
+// SystemActor is recorded for changes made by background jobs.
+const SystemActor = "system"
+
+// AuditEntry is one append-only record of an administrative change.
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	TenantID   string    `json:"tenant_id"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	BeforeJSON string    `json:"before,omitempty"`
+	AfterJSON  string    `json:"after,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AuditFilter struct {
+	TenantID   string
+	Actor      string
+	Action     string
+	EntityType string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+type actorContextKey struct{}
+
+// WithActor returns a copy of ctx attributed to the given actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, strings.TrimSpace(actor))
+}
+
+// ActorFromContext returns the actor bound to ctx, or SystemActor.
+func ActorFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return SystemActor
+	}
+	if actor, ok := ctx.Value(actorContextKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "math"
+
+// DefaultCalibrationBuckets is the number of equal-width probability buckets
+// used when none is requested.
+const DefaultCalibrationBuckets = 10
+
+// CalibrationBucket groups resolved predictions whose prob_up fell in
+// [Lower, Upper) and compares their mean prediction with how often the price
+// actually rose.
+type CalibrationBucket struct {
+	Lower             float64 `json:"lower"`
+	Upper             float64 `json:"upper"`
+	Count             int     `json:"count"`
+	MeanPredicted     float64 `json:"mean_predicted"`
+	RealizedFrequency float64 `json:"realized_frequency"`
+}
+
+// Calibration is a reliability curve for one model key, or all models when
+// ModelKey is empty. A well calibrated model has MeanPredicted close to
+// RealizedFrequency in every bucket.
+type Calibration struct {
+	ModelKey string              `json:"model_key,omitempty"`
+	Buckets  []CalibrationBucket `json:"buckets"`
+	Total    int                 `json:"total"`
+	// ECE is the expected calibration error: the count-weighted mean gap
+	// between predicted and realized frequency.
+	ECE float64 `json:"ece"`
+}
+
+// NewCalibration totals buckets and computes the expected calibration error.
+func NewCalibration(modelKey string, buckets []CalibrationBucket) Calibration {
+	c := Calibration{ModelKey: modelKey, Buckets: buckets}
+	for _, b := range buckets {
+		c.Total += b.Count
+	}
+	if c.Total == 0 {
+		return c
+	}
+	for _, b := range buckets {
+		c.ECE += float64(b.Count) / float64(c.Total) * math.Abs(b.MeanPredicted-b.RealizedFrequency)
+	}
+	return c
+}
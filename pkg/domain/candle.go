@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Candle represents a single OHLCV candle for an asset at a given interval.
+type Candle struct {
+	Symbol   string    `json:"symbol"`
+	Interval string    `json:"interval"`
+	OpenTime time.Time `json:"open_time"`
+	Open     float64   `json:"open"`
+	High     float64   `json:"high"`
+	Low      float64   `json:"low"`
+	Close    float64   `json:"close"`
+	Volume   float64   `json:"volume"`
+}
+
+var candleSymbolPattern = regexp.MustCompile(`^[A-Z0-9]{2,15}$`)
+
+// ValidateCandle checks a candle from outside the price providers: the
+// symbol is 2-15 upper-case letters or digits, the interval is supported,
+// open_time is aligned to it and not after now, prices are positive and
+// finite with high and low bounding open and close, and volume is not
+// negative.
+func ValidateCandle(c Candle, now time.Time) error {
+	if !candleSymbolPattern.MatchString(c.Symbol) {
+		return fmt.Errorf("symbol %q must be 2-15 letters or digits", c.Symbol)
+	}
+	length := IntervalDuration(c.Interval)
+	if length == 0 {
+		return fmt.Errorf("unsupported interval %q (supported: %s)", c.Interval, strings.Join(SupportedIntervals, ", "))
+	}
+	if c.OpenTime.IsZero() || !c.OpenTime.Equal(c.OpenTime.Truncate(length)) {
+		return fmt.Errorf("open_time %s is not aligned to the %s interval", c.OpenTime.Format(time.RFC3339), c.Interval)
+	}
+	if c.OpenTime.After(now) {
+		return fmt.Errorf("open_time %s is in the future", c.OpenTime.Format(time.RFC3339))
+	}
+	for _, v := range []float64{c.Open, c.High, c.Low, c.Close, c.Volume} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.New("prices and volume must be finite")
+		}
+	}
+	if c.Open <= 0 || c.High <= 0 || c.Low <= 0 || c.Close <= 0 {
+		return errors.New("prices must be positive")
+	}
+	if c.High < math.Max(c.Open, c.Close) || c.Low > math.Min(c.Open, c.Close) {
+		return errors.New("high and low must bound open and close")
+	}
+	if c.Volume < 0 {
+		return errors.New("volume must not be negative")
+	}
+	return nil
+}
+
+// PriceSnapshot represents the latest price data for an asset.
+// PriceDecimals is set when the snapshot is served over the API, with
+// PriceUSD rounded to it.
+type PriceSnapshot struct {
+	Symbol          string  `json:"symbol"`
+	PriceUSD        float64 `json:"price_usd"`
+	Volume24h       Money   `json:"volume_24h"`
+	Change24hPct    Percent `json:"change_24h_pct"`
+	LastUpdatedUnix int64   `json:"last_updated_unix"`
+	PriceDecimals   int     `json:"price_decimals,omitempty"`
+	// Quote is the price in another currency, when one was asked for.
+	Quote *PriceQuote `json:"quote,omitempty"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+// ConfigSetting is one effective runtime setting as reported to operators.
+// Secret values are redacted; Changed compares the unredacted value with
+// the built-in default.
+type ConfigSetting struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Default  any    `json:"default"`
+	Source   string `json:"source"`
+	Changed  bool   `json:"changed"`
+	Redacted bool   `json:"redacted,omitempty"`
+}
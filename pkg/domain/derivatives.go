@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// DerivativesSnapshot is one symbol's perpetual futures positioning at a
+// moment. FundingRate is the rate for the current funding period (8h on
+// Binance) as a fraction; positive means longs pay shorts. OpenInterest
+// counts contracts in the base asset and OpenInterestUSD values them at the
+// mark price.
+type DerivativesSnapshot struct {
+	Symbol          string    `json:"symbol"`
+	Source          string    `json:"source"`
+	CapturedAt      time.Time `json:"captured_at"`
+	MarkPrice       float64   `json:"mark_price"`
+	FundingRate     float64   `json:"funding_rate"`
+	NextFundingTime time.Time `json:"next_funding_time"`
+	OpenInterest    float64   `json:"open_interest"`
+	OpenInterestUSD float64   `json:"open_interest_usd"`
+}
+
+// DerivativesFeatures averages the snapshots captured during one hour, as
+// the ML feature engine consumes them.
+type DerivativesFeatures struct {
+	Hour         time.Time
+	FundingRate  float64
+	OpenInterest float64
+	Snapshots    int
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// DexQuote is an on-chain USD price for a tracked symbol, read from a
+// decentralised exchange pool or aggregator.
+type DexQuote struct {
+	Symbol     string    `json:"symbol"`
+	Venue      string    `json:"venue"`
+	PriceUSD   float64   `json:"price_usd"`
+	CapturedAt time.Time `json:"captured_at"`
+}
+
+// CexDexSpread compares a DEX quote with the centralised exchange price at
+// the same moment. SpreadPct is (DEX - CEX) / CEX in percent, so a positive
+// spread means the DEX trades at a premium.
+type CexDexSpread struct {
+	Symbol      string    `json:"symbol"`
+	Venue       string    `json:"venue"`
+	CapturedAt  time.Time `json:"captured_at"`
+	DexPriceUSD float64   `json:"dex_price_usd"`
+	CexPriceUSD float64   `json:"cex_price_usd"`
+	SpreadPct   float64   `json:"spread_pct"`
+}
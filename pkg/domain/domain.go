@@ -0,0 +1,439 @@
+package domain
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+type SignalDirection string
+
+const (
+	DirectionLong  SignalDirection = "long"
+	DirectionShort SignalDirection = "short"
+	DirectionHold  SignalDirection = "hold"
+)
+
+const (
+	IndicatorRSI                    = "rsi"
+	IndicatorMACD                   = "macd"
+	IndicatorBollinger              = "bollinger"
+	IndicatorVolumeZ                = "volume_zscore"
+	IndicatorIchimoku               = "ichimoku"
+	IndicatorMLLogRegUp4H           = "ml_logreg_up4h"
+	IndicatorMLXGBoostUp4H          = "ml_xgboost_up4h"
+	IndicatorMLEnsembleUp4H         = "ml_ensemble_up4h"
+	IndicatorFundSentimentComposite = "fund_sentiment_composite"
+	IndicatorCexDexSpread           = "cex_dex_spread"
+)
+
+// Signal is a detected trading opportunity. Version identifies the
+// detection logic that produced it, so statistics are never mixed across
+// changes to an indicator.
+type Signal struct {
+	ID        int64           `json:"id"`
+	Symbol    string          `json:"symbol"`
+	Interval  string          `json:"interval"`
+	Indicator string          `json:"indicator"`
+	Timestamp time.Time       `json:"timestamp"`
+	Risk      RiskLevel       `json:"risk"`
+	Direction SignalDirection `json:"direction"`
+	Details   string          `json:"details,omitempty"`
+	Version   string          `json:"version,omitempty"`
+	Image     *SignalImageRef `json:"image,omitempty"`
+}
+
+type SignalImageRef struct {
+	ImageID   int64     `json:"image_id"`
+	MimeType  string    `json:"mime_type"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type SignalImageData struct {
+	Ref   SignalImageRef
+	Bytes []byte
+}
+
+type SignalFilter struct {
+	Symbol    string
+	Risk      *RiskLevel
+	Indicator string
+	Version   string
+	// Until keeps signals at or before this time; zero means no bound.
+	Until time.Time
+	Limit int
+}
+
+// SignalGenerationResult is the outcome of generating signals for one symbol
+// in a bulk request. Error is set instead of Signals when that symbol failed.
+type SignalGenerationResult struct {
+	Symbol  string   `json:"symbol"`
+	Signals []Signal `json:"signals"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// SymbolOverview gathers the latest state of one symbol: its price, the
+// newest classic indicator signals, the newest ensemble prediction and the
+// newest anomaly score. Ensemble and AnomalyScore are nil when ML has not
+// produced them.
+type SymbolOverview struct {
+	Symbol       string              `json:"symbol"`
+	Price        *PriceSnapshot      `json:"price"`
+	Signals      []Signal            `json:"signals"`
+	Ensemble     *EnsemblePrediction `json:"ensemble,omitempty"`
+	AnomalyScore *float64            `json:"anomaly_score,omitempty"`
+}
+
+// EnsemblePrediction is the public view of an ensemble model prediction.
+type EnsemblePrediction struct {
+	Interval     string          `json:"interval"`
+	OpenTime     time.Time       `json:"open_time"`
+	TargetTime   time.Time       `json:"target_time"`
+	ModelVersion int             `json:"model_version"`
+	ProbUp       float64         `json:"prob_up"`
+	Confidence   float64         `json:"confidence"`
+	Direction    SignalDirection `json:"direction"`
+	Risk         RiskLevel       `json:"risk"`
+	HeldReason   string          `json:"held_reason,omitempty"`
+	// Uncertainty is how much the models behind the call disagreed, as the
+	// standard deviation of their scores; 0 when only one model scored.
+	Uncertainty float64 `json:"uncertainty"`
+}
+
+// SignalWinRate reports how often signals of one indicator version were
+// followed, a fixed number of candles later, by a close in their direction.
+type SignalWinRate struct {
+	Indicator string  `json:"indicator"`
+	Version   string  `json:"version"`
+	Total     int64   `json:"total"`
+	Wins      int64   `json:"wins"`
+	WinRate   float64 `json:"win_rate"`
+}
+
+// RiskLevelStats reports how one model's resolved predictions fared at one
+// risk level. AvgReturn is the realized return in the predicted direction,
+// averaged over long and short predictions only.
+type RiskLevelStats struct {
+	ModelKey  string    `json:"model_key"`
+	Risk      RiskLevel `json:"risk"`
+	Total     int64     `json:"total"`
+	Correct   int64     `json:"correct"`
+	HitRate   float64   `json:"hit_rate"`
+	AvgReturn float64   `json:"avg_return"`
+}
+
+// AccuracyBreakdown is the hit rate of one model's resolved predictions for
+// one symbol, risk level and horizon. Directional counts the long and short
+// calls, whose returns, signed by the predicted direction, AvgReturn averages.
+type AccuracyBreakdown struct {
+	ModelKey     string    `json:"model_key"`
+	Symbol       string    `json:"symbol"`
+	Risk         RiskLevel `json:"risk"`
+	HorizonHours int       `json:"horizon_hours"`
+	Total        int64     `json:"total"`
+	Correct      int64     `json:"correct"`
+	Directional  int64     `json:"directional"`
+	HitRate      float64   `json:"hit_rate"`
+	AvgReturn    float64   `json:"avg_return"`
+}
+
+// CoverageDay reports how often one model made a directional call on one UTC
+// day, next to the accuracy of those calls alone. A model can raise plain
+// accuracy by holding more often, so coverage and accuracy are read together.
+type CoverageDay struct {
+	ModelKey            string    `json:"model_key"`
+	Day                 time.Time `json:"day_utc"`
+	Total               int64     `json:"total"`
+	Directional         int64     `json:"directional"`
+	Holds               int64     `json:"holds"`
+	HeldAnomaly         int64     `json:"held_anomaly"`
+	HeldUncertainty     int64     `json:"held_uncertainty"`
+	Coverage            float64   `json:"coverage"`
+	ResolvedDirectional int64     `json:"resolved_directional"`
+	CorrectDirectional  int64     `json:"correct_directional"`
+	DirectionalAccuracy float64   `json:"directional_accuracy"`
+}
+
+// RiskOrdersHitRate reports whether hit rate never rises with risk across the
+// levels in stats (one model) that have at least minSamples predictions. ok is
+// false when fewer than two levels qualify.
+func RiskOrdersHitRate(stats []RiskLevelStats, minSamples int64) (ordered, ok bool) {
+	var levels []RiskLevelStats
+	for _, s := range stats {
+		if s.Total >= minSamples && s.Total > 0 {
+			levels = append(levels, s)
+		}
+	}
+	if len(levels) < 2 {
+		return false, false
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Risk < levels[j].Risk })
+	for i := 1; i < len(levels); i++ {
+		if levels[i].HitRate > levels[i-1].HitRate {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+type Recommendation struct {
+	Signal Signal
+	Text   string
+}
+
+type RiskLevel int
+
+const (
+	RiskLevel1 RiskLevel = 1
+	RiskLevel2 RiskLevel = 2
+	RiskLevel3 RiskLevel = 3
+	RiskLevel4 RiskLevel = 4
+	RiskLevel5 RiskLevel = 5
+)
+
+func (r RiskLevel) IsValid() bool {
+	return r >= RiskLevel1 && r <= RiskLevel5
+}
+
+type ConversationMessage struct {
+	Role      string
+	Content   string
+	CreatedAt time.Time
+}
+
+type MLFeatureRow struct {
+	Symbol        string
+	Interval      string
+	OpenTime      time.Time
+	Ret1H         float64
+	Ret4H         float64
+	Ret12H        float64
+	Ret24H        float64
+	Volatility6H  float64
+	Volatility24H float64
+	VolumeZ24H    float64
+	RSI14         float64
+	MACDLine      float64
+	MACDSignal    float64
+	MACDHist      float64
+	BBPos         float64
+	BBWidth       float64
+	// BookImbalance and BookSpreadBps average the order book snapshots
+	// captured during the candle; 0 when none were.
+	BookImbalance float64
+	BookSpreadBps float64
+	// FundingRate averages the perpetual funding rate over the candle and
+	// OIChange24H is the fractional change in open interest from the same
+	// window a day earlier; 0 when no snapshots cover them.
+	FundingRate float64
+	OIChange24H float64
+	// BTCDominance is BTC's share of the total crypto market cap over the
+	// candle as a fraction, and MarketCapChange24H the fractional change in
+	// total market cap from the same window a day earlier; 0 when no
+	// snapshots cover them.
+	BTCDominance       float64
+	MarketCapChange24H float64
+	// EventProximity is how close the next scheduled market event is at the
+	// candle's close, from 0 with none in the next 72 hours to 1 for a high
+	// importance event starting within the candle.
+	EventProximity float64
+	TargetUp4H     *bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type MLModelVersion struct {
+	ID                 int64
+	ModelKey           string
+	Version            int
+	FeatureSpecVersion string
+	TrainedFrom        time.Time
+	TrainedTo          time.Time
+	TrainedAt          time.Time
+	HyperparamsJSON    string
+	MetricsJSON        string
+	ArtifactFormat     string
+	ArtifactBlob       []byte
+	IsActive           bool
+	ActivatedAt        *time.Time
+	CreatedAt          time.Time
+}
+
+// Model event kinds.
+const (
+	ModelEventTrained  = "trained"
+	ModelEventPromoted = "promoted"
+)
+
+// ModelEvent is a training run or a promotion recorded by the model registry.
+// TrainedFrom and TrainedTo are the data window the version was fit on.
+type ModelEvent struct {
+	Kind        string
+	ModelKey    string
+	Version     int
+	At          time.Time
+	TrainedFrom time.Time
+	TrainedTo   time.Time
+}
+
+// MaintenanceWindow is planned downtime announced to operators.
+type MaintenanceWindow struct {
+	Start    time.Time
+	Duration time.Duration
+	Note     string
+}
+
+type MLPrediction struct {
+	ID             int64
+	Symbol         string
+	Interval       string
+	OpenTime       time.Time
+	TargetTime     time.Time
+	ModelKey       string
+	ModelVersion   int
+	ProbUp         float64
+	Confidence     float64
+	Direction      SignalDirection
+	Risk           RiskLevel
+	SignalID       *int64
+	DetailsJSON    string
+	CreatedAt      time.Time
+	ResolvedAt     *time.Time
+	ActualUp       *bool
+	IsCorrect      *bool
+	RealizedReturn *float64
+}
+
+// HeldReasonAnomalyDamping marks an ensemble Hold that would have been a
+// directional call without anomaly damping.
+const HeldReasonAnomalyDamping = "anomaly_damping"
+
+// HeldReasonUncertainty marks an ensemble Hold whose score cleared the
+// default hold band but not the band widened by model disagreement.
+const HeldReasonUncertainty = "uncertainty"
+
+// HeldReason reports why a Hold prediction suppressed a directional call, as
+// recorded in its details. It is empty for directional predictions and for
+// a Hold that had no call to suppress.
+func (p MLPrediction) HeldReason() string {
+	if p.Direction != DirectionHold || p.DetailsJSON == "" {
+		return ""
+	}
+	var details struct {
+		HeldReason string `json:"held_reason"`
+	}
+	if err := json.Unmarshal([]byte(p.DetailsJSON), &details); err != nil {
+		return ""
+	}
+	return details.HeldReason
+}
+
+// Uncertainty reports the disagreement between the models or versions that
+// scored the prediction, as recorded in its details. It is 0 when a single
+// version scored or the details predate uncertainty estimates.
+func (p MLPrediction) Uncertainty() float64 {
+	if p.DetailsJSON == "" {
+		return 0
+	}
+	var details struct {
+		Uncertainty float64 `json:"uncertainty"`
+	}
+	if err := json.Unmarshal([]byte(p.DetailsJSON), &details); err != nil {
+		return 0
+	}
+	return details.Uncertainty
+}
+
+// AnomalyScore is one isolation forest score for a symbol's candle, with the
+// damping it applied to that candle's ensemble prediction.
+type AnomalyScore struct {
+	Symbol     string
+	Interval   string
+	OpenTime   time.Time
+	Score      float64
+	DampFactor float64
+}
+
+// AnomalyCell summarises one symbol's anomaly scores over one UTC day.
+// MinDampFactor is the strongest damping applied that day.
+type AnomalyCell struct {
+	Symbol        string    `json:"symbol"`
+	Day           time.Time `json:"day"`
+	Samples       int64     `json:"samples"`
+	MaxScore      float64   `json:"max_score"`
+	MeanScore     float64   `json:"mean_score"`
+	MinDampFactor float64   `json:"min_damp_factor"`
+}
+
+type MarketIntelItem struct {
+	ID                  int64
+	Source              string
+	SourceItemID        string
+	Title               string
+	URL                 string
+	Excerpt             string
+	Author              string
+	PublishedAt         time.Time
+	FetchedAt           time.Time
+	MetadataJSON        string
+	SentimentScore      *float64
+	SentimentConfidence *float64
+	SentimentLabel      *string
+	SentimentModel      *string
+	SentimentReason     *string
+	ScoredAt            *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Symbols             []string
+}
+
+type MarketOnChainSnapshot struct {
+	Symbol       string
+	Interval     string
+	BucketTime   time.Time
+	ProviderKey  string
+	OnChainScore float64
+	Confidence   float64
+	DetailsJSON  string
+	CreatedAt    time.Time
+}
+
+// FearGreedReading is one day's Crypto Fear & Greed index value, from 0
+// (extreme fear) to 100 (extreme greed).
+type FearGreedReading struct {
+	Timestamp      time.Time
+	Value          int
+	Classification string
+}
+
+type MarketCompositeSnapshot struct {
+	Symbol               string
+	Interval             string
+	OpenTime             time.Time
+	FearGreedValue       *int
+	FearGreedScore       *float64
+	NewsScore            *float64
+	RedditScore          *float64
+	OnChainScore         *float64
+	CompositeScore       float64
+	Confidence           float64
+	Direction            SignalDirection
+	Risk                 RiskLevel
+	ComponentWeightsJSON string
+	DetailsJSON          string
+	SignalID             *int64
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
+
+type MarketIntelRunResult struct {
+	ItemsIngested     int      `json:"items_ingested"`
+	ItemsScored       int      `json:"items_scored"`
+	OnChainSnapshots  int      `json:"onchain_snapshots"`
+	CompositesWritten int      `json:"composites_written"`
+	SignalsWritten    int      `json:"signals_written"`
+	EventsIngested    int      `json:"events_ingested"`
+	Errors            []string `json:"errors,omitempty"`
+}
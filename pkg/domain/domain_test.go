@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRiskLevelConstants(t *testing.T) {
+	if RiskLevel1 != 1 || RiskLevel5 != 5 {
+		t.Errorf("RiskLevel constants not set correctly: got %d, %d", RiskLevel1, RiskLevel5)
+	}
+}
+
+func TestAssetFields(t *testing.T) {
+	a := Asset{Symbol: "BTC", Name: "Bitcoin"}
+	if a.Symbol != "BTC" || a.Name != "Bitcoin" {
+		t.Errorf("Asset fields not set correctly: %+v", a)
+	}
+}
+
+func TestSignalFields(t *testing.T) {
+	ts := time.Unix(1234567890, 0).UTC()
+	s := Signal{
+		Symbol:    "ETH",
+		Interval:  "4h",
+		Indicator: IndicatorRSI,
+		Timestamp: ts,
+		Risk:      RiskLevel3,
+		Direction: DirectionLong,
+	}
+	if s.Symbol != "ETH" || s.Interval != "4h" || s.Indicator != IndicatorRSI || !s.Timestamp.Equal(ts) || s.Risk != RiskLevel3 || s.Direction != DirectionLong {
+		t.Errorf("Signal fields not set correctly: %+v", s)
+	}
+}
+
+func TestRecommendationFields(t *testing.T) {
+	s := Signal{Symbol: "SOL", Indicator: IndicatorMACD}
+	r := Recommendation{Signal: s, Text: "Buy"}
+	if r.Signal.Indicator != IndicatorMACD || r.Text != "Buy" {
+		t.Errorf("Recommendation fields not set correctly: %+v", r)
+	}
+}
+
+func TestRiskLevelIsValid(t *testing.T) {
+	if !RiskLevel1.IsValid() || !RiskLevel5.IsValid() {
+		t.Fatal("expected boundary values to be valid")
+	}
+	if RiskLevel(0).IsValid() || RiskLevel(6).IsValid() {
+		t.Fatal("expected out-of-range risk levels to be invalid")
+	}
+}
+
+func TestMLIndicatorConstants(t *testing.T) {
+	if IndicatorMLLogRegUp4H == "" || IndicatorMLXGBoostUp4H == "" || IndicatorMLEnsembleUp4H == "" {
+		t.Fatal("expected ML indicator constants to be non-empty")
+	}
+}
+
+func TestIntervalDuration(t *testing.T) {
+	for _, interval := range SupportedIntervals {
+		if IntervalDuration(interval) <= 0 {
+			t.Errorf("expected a duration for %s", interval)
+		}
+	}
+	if IntervalDuration("4h") != 4*time.Hour {
+		t.Errorf("unexpected 4h duration: %v", IntervalDuration("4h"))
+	}
+	if IntervalDuration("3w") != 0 {
+		t.Errorf("expected 0 for unknown interval")
+	}
+}
+
+func TestProviderPairsCoverSupportedSymbols(t *testing.T) {
+	for _, a := range DefaultAssets {
+		if a.CoinGeckoID == "" || a.BinancePair == "" || a.KrakenPair == "" || a.CoinbasePair == "" {
+			t.Errorf("expected every provider to map %s", a.Symbol)
+		}
+	}
+}
+
+func TestNewCalibrationComputesECE(t *testing.T) {
+	c := NewCalibration("ensemble_v1", []CalibrationBucket{
+		{Lower: 0.4, Upper: 0.5, Count: 30, MeanPredicted: 0.45, RealizedFrequency: 0.45},
+		{Lower: 0.6, Upper: 0.7, Count: 10, MeanPredicted: 0.65, RealizedFrequency: 0.45},
+	})
+	if c.Total != 40 {
+		t.Fatalf("expected 40 predictions, got %d", c.Total)
+	}
+	if math.Abs(c.ECE-0.05) > 1e-9 {
+		t.Fatalf("expected ECE 0.05, got %v", c.ECE)
+	}
+	if empty := NewCalibration("", nil); empty.Total != 0 || empty.ECE != 0 {
+		t.Fatalf("expected zero calibration, got %+v", empty)
+	}
+}
+
+func TestRiskOrdersHitRate(t *testing.T) {
+	stats := []RiskLevelStats{
+		{Risk: RiskLevel4, Total: 50, HitRate: 0.52},
+		{Risk: RiskLevel2, Total: 50, HitRate: 0.70},
+		{Risk: RiskLevel3, Total: 50, HitRate: 0.61},
+		{Risk: RiskLevel5, Total: 3, HitRate: 0.90}, // too few to count
+	}
+	if ordered, ok := RiskOrdersHitRate(stats, 20); !ok || !ordered {
+		t.Fatalf("expected ordered, got ordered=%v ok=%v", ordered, ok)
+	}
+
+	stats[0].HitRate = 0.65
+	if ordered, ok := RiskOrdersHitRate(stats, 20); !ok || ordered {
+		t.Fatalf("expected out of order, got ordered=%v ok=%v", ordered, ok)
+	}
+
+	if _, ok := RiskOrdersHitRate(stats[:1], 20); ok {
+		t.Fatal("expected no verdict with one level")
+	}
+}
+
+func TestMLPredictionHeldReason(t *testing.T) {
+	held := MLPrediction{Direction: DirectionHold, DetailsJSON: `{"held_reason":"anomaly_damping"}`}
+	if got := held.HeldReason(); got != HeldReasonAnomalyDamping {
+		t.Fatalf("expected %q, got %q", HeldReasonAnomalyDamping, got)
+	}
+	for _, p := range []MLPrediction{
+		{Direction: DirectionLong, DetailsJSON: `{"held_reason":"anomaly_damping"}`},
+		{Direction: DirectionHold, DetailsJSON: `{"ensemble_score":0.01}`},
+		{Direction: DirectionHold, DetailsJSON: `not json`},
+		{Direction: DirectionHold},
+	} {
+		if got := p.HeldReason(); got != "" {
+			t.Fatalf("expected no held reason for %+v, got %q", p, got)
+		}
+	}
+}
+
+func TestMLPredictionUncertainty(t *testing.T) {
+	if got := (MLPrediction{DetailsJSON: `{"uncertainty":0.42}`}).Uncertainty(); got != 0.42 {
+		t.Fatalf("expected 0.42, got %v", got)
+	}
+	for _, p := range []MLPrediction{{DetailsJSON: `{"prob_up":0.6}`}, {DetailsJSON: `not json`}, {}} {
+		if got := p.Uncertainty(); got != 0 {
+			t.Fatalf("expected no uncertainty for %+v, got %v", p, got)
+		}
+	}
+}
+
+func TestSetAssetsReplacesRegistry(t *testing.T) {
+	t.Cleanup(func() { SetAssets(nil) })
+	version := AssetsVersion()
+
+	SetAssets([]Asset{
+		{Symbol: "btc", CoinGeckoID: "bitcoin", BinancePair: "BTCUSDT"},
+		{Symbol: "PEPE", CoinGeckoID: "pepe", PriceDecimals: 8},
+		{Symbol: "NOID"},
+	})
+	if got := SupportedSymbols(); len(got) != 2 || got[0] != "BTC" || got[1] != "PEPE" {
+		t.Fatalf("unexpected symbols: %v", got)
+	}
+	if AssetsVersion() == version {
+		t.Fatal("expected the registry version to change")
+	}
+	if symbol, ok := SymbolForCoinGeckoID("pepe"); !ok || symbol != "PEPE" {
+		t.Fatalf("expected pepe to map to PEPE, got %q", symbol)
+	}
+	if _, ok := BinancePairs()["PEPE"]; ok {
+		t.Fatal("expected PEPE to have no Binance pair")
+	}
+	if PricePrecision("PEPE") != 8 || IsSupportedSymbol("ETH") {
+		t.Fatalf("unexpected precision %d or ETH still tracked", PricePrecision("PEPE"))
+	}
+
+	SetAssets(nil)
+	if len(SupportedSymbols()) != len(DefaultAssets) {
+		t.Fatalf("expected an empty registry to restore the defaults")
+	}
+}
+
+func TestAssetPausesLayerConfiguredOverStored(t *testing.T) {
+	t.Cleanup(func() {
+		SetConfiguredPauses(nil)
+		SetAssets(nil)
+	})
+	SetAssets([]Asset{
+		{Symbol: "BTC", CoinGeckoID: "bitcoin"},
+		{Symbol: "DOGE", CoinGeckoID: "dogecoin", AssetPauses: AssetPauses{AlertsPaused: true}},
+	})
+	if SignalsPaused("DOGE") || MLPaused("DOGE") || !AlertsPaused("DOGE") || AlertsPaused("BTC") {
+		t.Fatalf("unexpected stored pauses: %+v", Assets())
+	}
+
+	SetConfiguredPauses(map[string]AssetPauses{"doge": {SignalsPaused: true}, "NOPE": {MLPaused: true}})
+	if !SignalsPaused("DOGE") || !AlertsPaused("DOGE") || MLPaused("DOGE") {
+		t.Fatalf("expected configured pauses layered over stored ones, got %+v", Assets())
+	}
+	if MLPaused("NOPE") {
+		t.Fatal("expected untracked symbols never paused")
+	}
+
+	SetAssets([]Asset{{Symbol: "DOGE", CoinGeckoID: "dogecoin"}})
+	if a, _ := LookupAsset("DOGE"); !a.SignalsPaused || a.AlertsPaused {
+		t.Fatalf("expected configured pauses kept across reloads, got %+v", a)
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// CurrencyUSD is the currency every stored price is quoted in.
+const CurrencyUSD = "USD"
+
+// quoteCurrencySigns maps the currencies prices can be converted to onto
+// the sign they are rendered with.
+var quoteCurrencySigns = map[string]string{
+	CurrencyUSD: "$",
+	"EUR":       "€",
+	"GBP":       "£",
+	"JPY":       "¥",
+}
+
+// QuoteCurrencies lists the currencies prices can be quoted in.
+func QuoteCurrencies() []string {
+	return []string{CurrencyUSD, "EUR", "GBP", "JPY"}
+}
+
+// NormalizeCurrency upper-cases code and reports whether prices can be
+// quoted in it. An empty code is USD.
+func NormalizeCurrency(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return CurrencyUSD, true
+	}
+	_, ok := quoteCurrencySigns[code]
+	return code, ok
+}
+
+// FXRates are the units of each quote currency one US dollar buys.
+type FXRates struct {
+	Rates map[string]float64
+	AsOf  time.Time
+}
+
+// PriceQuote is a USD price converted to another currency.
+type PriceQuote struct {
+	Currency  string  `json:"currency"`
+	Rate      float64 `json:"rate"`
+	Price     float64 `json:"price"`
+	Volume24h float64 `json:"volume_24h"`
+	// RateDate is the day the FX rate was published.
+	RateDate string `json:"rate_date,omitempty"`
+}
+
+// In returns a copy of s with its price and volume converted at rate units
+// of currency per dollar.
+func (s PriceSnapshot) In(currency string, rate float64, asOf time.Time) *PriceSnapshot {
+	quote := &PriceQuote{
+		Currency:  currency,
+		Rate:      rate,
+		Price:     s.PriceUSD * rate,
+		Volume24h: float64(s.Volume24h) * rate,
+	}
+	if !asOf.IsZero() {
+		quote.RateDate = asOf.UTC().Format("2006-01-02")
+	}
+	s.Quote = quote
+	return &s
+}
+
+// FormatPriceIn is FormatPrice for a price in currency, e.g. "€89,411.20".
+func FormatPriceIn(symbol string, v float64, currency string) string {
+	sign, ok := quoteCurrencySigns[currency]
+	if !ok {
+		return FormatPriceValue(symbol, v) + " " + currency
+	}
+	if v < 0 {
+		return "-" + sign + FormatPriceValue(symbol, -v)
+	}
+	return sign + FormatPriceValue(symbol, v)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// GlobalMarketSnapshot is the whole crypto market at a moment: total market
+// capitalisation and 24h volume in USD, and the share of the market cap held
+// by BTC and ETH in percent.
+type GlobalMarketSnapshot struct {
+	Source                string    `json:"source"`
+	CapturedAt            time.Time `json:"captured_at"`
+	TotalMarketCapUSD     float64   `json:"total_market_cap_usd"`
+	TotalVolumeUSD        float64   `json:"total_volume_usd"`
+	BTCDominancePct       float64   `json:"btc_dominance_pct"`
+	ETHDominancePct       float64   `json:"eth_dominance_pct"`
+	MarketCapChange24hPct float64   `json:"market_cap_change_24h_pct"`
+}
+
+// GlobalMarketFeatures averages the snapshots captured during one hour, as
+// the ML feature engine consumes them.
+type GlobalMarketFeatures struct {
+	Hour              time.Time
+	BTCDominancePct   float64
+	TotalMarketCapUSD float64
+	Snapshots         int
+}
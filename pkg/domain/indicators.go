@@ -0,0 +1,222 @@
+package domain
+
+import "fmt"
+
+// IndicatorKind groups indicators by what produces them.
+type IndicatorKind string
+
+const (
+	IndicatorKindClassic   IndicatorKind = "classic"
+	IndicatorKindML        IndicatorKind = "ml"
+	IndicatorKindSentiment IndicatorKind = "sentiment"
+	// IndicatorKindMarket indicators compare prices across venues rather
+	// than reading candles.
+	IndicatorKindMarket IndicatorKind = "market"
+)
+
+// IndicatorInfo describes a signal indicator for option lists and the
+// GET /api/indicators endpoint.
+type IndicatorInfo struct {
+	Key         string        `json:"key"`
+	Kind        IndicatorKind `json:"kind"`
+	Description string        `json:"description"`
+	// Direction explains what makes a signal long or short.
+	Direction string    `json:"direction"`
+	MinRisk   RiskLevel `json:"min_risk"`
+	MaxRisk   RiskLevel `json:"max_risk"`
+	// Charts reports whether signal charts can be rendered.
+	Charts bool `json:"charts"`
+	// RiskByInterval is the risk the signal engine assigns on each interval,
+	// after any deployment overrides. Only set for classic indicators served
+	// by GET /api/indicators.
+	RiskByInterval map[string]RiskLevel `json:"risk_by_interval,omitempty"`
+}
+
+// RiskOverride replaces the risk the signal engine assigns to a classic
+// indicator, on one interval or on every interval when Interval is empty.
+type RiskOverride struct {
+	Indicator string    `json:"indicator"`
+	Interval  string    `json:"interval,omitempty"`
+	Risk      RiskLevel `json:"risk"`
+}
+
+// IchimokuPeriods are the look-back lengths, in candles, of the Ichimoku
+// lines. The leading spans that form the cloud are plotted Kijun candles
+// ahead of the candles they are computed from.
+type IchimokuPeriods struct {
+	Tenkan  int `json:"tenkan"`
+	Kijun   int `json:"kijun"`
+	SenkouB int `json:"senkou_b"`
+}
+
+// SignalLookbackCandles is how many of the newest candles signal generation
+// loads per symbol and interval. The last one may still be in progress and
+// is then left out.
+const SignalLookbackCandles = 250
+
+// DefaultIchimokuPeriods are the classic 9/26/52 settings.
+var DefaultIchimokuPeriods = IchimokuPeriods{Tenkan: 9, Kijun: 26, SenkouB: 52}
+
+// Valid reports whether the periods are positive and each line looks back
+// further than the one before it.
+func (p IchimokuPeriods) Valid() bool {
+	return p.Tenkan > 0 && p.Tenkan < p.Kijun && p.Kijun < p.SenkouB
+}
+
+// Lookback is the number of candles a cloud breakout needs: Senkou B
+// candles for the span, Kijun more for the forward shift and one more for
+// the previous close.
+func (p IchimokuPeriods) Lookback() int {
+	return p.SenkouB + p.Kijun + 1
+}
+
+// String formats the periods as tenkan/kijun/senkou_b.
+func (p IchimokuPeriods) String() string {
+	return fmt.Sprintf("%d/%d/%d", p.Tenkan, p.Kijun, p.SenkouB)
+}
+
+// Indicators is the registry of every indicator a signal may carry, in
+// display order. Add new indicators here so the bot, TUI, MCP and API
+// option lists pick them up.
+var Indicators = []IndicatorInfo{
+	{
+		Key:         IndicatorRSI,
+		Kind:        IndicatorKindClassic,
+		Description: "14-period relative strength index crossing the 30/70 bands",
+		Direction:   "long when RSI falls below 30 (oversold), short when it rises above 70 (overbought)",
+		MinRisk:     RiskLevel2,
+		MaxRisk:     RiskLevel4,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorMACD,
+		Kind:        IndicatorKindClassic,
+		Description: "MACD line crossing its signal line",
+		Direction:   "long on a bullish crossover, short on a bearish one",
+		MinRisk:     RiskLevel3,
+		MaxRisk:     RiskLevel5,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorBollinger,
+		Kind:        IndicatorKindClassic,
+		Description: "Close breaking out of the Bollinger bands after a squeeze",
+		Direction:   "long on a breakout above the upper band, short on a breakdown below the lower band",
+		MinRisk:     RiskLevel3,
+		MaxRisk:     RiskLevel5,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorVolumeZ,
+		Kind:        IndicatorKindClassic,
+		Description: "Volume spike measured as a z-score against recent candles",
+		Direction:   "follows the candle: long if it closed up, short if down, hold if flat",
+		MinRisk:     RiskLevel3,
+		MaxRisk:     RiskLevel4,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorIchimoku,
+		Kind:        IndicatorKindClassic,
+		Description: "Ichimoku Tenkan-sen crossing the Kijun-sen, or close breaking out of the cloud",
+		Direction:   "long when the Tenkan crosses above the Kijun or the close breaks above the cloud, short on the opposite moves",
+		MinRisk:     RiskLevel2,
+		MaxRisk:     RiskLevel4,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorMLLogRegUp4H,
+		Kind:        IndicatorKindML,
+		Description: "Logistic regression probability that price is higher in 4 hours",
+		Direction:   "long above the model's long threshold, short below its short threshold, hold between",
+		MinRisk:     RiskLevel2,
+		MaxRisk:     RiskLevel5,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorMLXGBoostUp4H,
+		Kind:        IndicatorKindML,
+		Description: "Gradient-boosted trees probability that price is higher in 4 hours",
+		Direction:   "long above the model's long threshold, short below its short threshold, hold between",
+		MinRisk:     RiskLevel2,
+		MaxRisk:     RiskLevel5,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorMLEnsembleUp4H,
+		Kind:        IndicatorKindML,
+		Description: "Weighted ensemble of the 4-hour models, damped by the anomaly score",
+		Direction:   "long above the ensemble's long threshold, short below its short threshold, hold between",
+		MinRisk:     RiskLevel2,
+		MaxRisk:     RiskLevel5,
+		Charts:      true,
+	},
+	{
+		Key:         IndicatorFundSentimentComposite,
+		Kind:        IndicatorKindSentiment,
+		Description: "Weighted composite of fear & greed, news, Reddit and on-chain sentiment",
+		Direction:   "long when the composite score clears the long threshold, short below the short threshold",
+		MinRisk:     RiskLevel2,
+		MaxRisk:     RiskLevel5,
+		Charts:      false,
+	},
+	{
+		Key:         IndicatorCexDexSpread,
+		Kind:        IndicatorKindMarket,
+		Description: "On-chain DEX price diverging from the exchange price by more than the spread threshold",
+		Direction:   "long when the DEX trades at a premium to the exchanges, short when it trades at a discount",
+		MinRisk:     RiskLevel4,
+		MaxRisk:     RiskLevel4,
+		Charts:      false,
+	},
+}
+
+// IndicatorsWithRisk returns a copy of Indicators where each classic
+// indicator carries the risk riskFor assigns on every supported interval,
+// with MinRisk and MaxRisk set to match.
+func IndicatorsWithRisk(riskFor func(indicator, interval string) RiskLevel) []IndicatorInfo {
+	out := make([]IndicatorInfo, len(Indicators))
+	for i, info := range Indicators {
+		if info.Kind == IndicatorKindClassic {
+			info.RiskByInterval = make(map[string]RiskLevel, len(SupportedIntervals))
+			for j, interval := range SupportedIntervals {
+				risk := riskFor(info.Key, interval)
+				info.RiskByInterval[interval] = risk
+				if j == 0 || risk < info.MinRisk {
+					info.MinRisk = risk
+				}
+				if j == 0 || risk > info.MaxRisk {
+					info.MaxRisk = risk
+				}
+			}
+		}
+		out[i] = info
+	}
+	return out
+}
+
+// LookupIndicator returns the registry entry for key.
+func LookupIndicator(key string) (IndicatorInfo, bool) {
+	for _, info := range Indicators {
+		if info.Key == key {
+			return info, true
+		}
+	}
+	return IndicatorInfo{}, false
+}
+
+// IndicatorKeys lists every registered indicator key in display order.
+func IndicatorKeys() []string {
+	keys := make([]string, len(Indicators))
+	for i, info := range Indicators {
+		keys[i] = info.Key
+	}
+	return keys
+}
+
+// IsClassicIndicator reports whether key is a candle-derived technical
+// indicator rather than a model or sentiment score.
+func IsClassicIndicator(key string) bool {
+	info, ok := LookupIndicator(key)
+	return ok && info.Kind == IndicatorKindClassic
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "testing"
+
+func TestIndicatorRegistryIsConsistent(t *testing.T) {
+	seen := make(map[string]bool, len(Indicators))
+	for _, info := range Indicators {
+		if seen[info.Key] {
+			t.Fatalf("duplicate indicator %s", info.Key)
+		}
+		seen[info.Key] = true
+		if info.Description == "" || info.Direction == "" {
+			t.Fatalf("indicator %s needs a description and direction", info.Key)
+		}
+		if !info.MinRisk.IsValid() || !info.MaxRisk.IsValid() || info.MinRisk > info.MaxRisk {
+			t.Fatalf("indicator %s has invalid risk range %d-%d", info.Key, info.MinRisk, info.MaxRisk)
+		}
+		switch info.Kind {
+		case IndicatorKindClassic, IndicatorKindML, IndicatorKindSentiment, IndicatorKindMarket:
+		default:
+			t.Fatalf("indicator %s has unknown kind %q", info.Key, info.Kind)
+		}
+	}
+}
+
+func TestLookupIndicator(t *testing.T) {
+	info, ok := LookupIndicator(IndicatorMACD)
+	if !ok || info.Kind != IndicatorKindClassic {
+		t.Fatalf("expected classic macd entry, got %+v ok=%v", info, ok)
+	}
+	if _, ok := LookupIndicator("stochastic"); ok {
+		t.Fatal("expected unknown indicator to be missing")
+	}
+}
+
+func TestIsClassicIndicator(t *testing.T) {
+	if !IsClassicIndicator(IndicatorVolumeZ) {
+		t.Fatal("expected volume_zscore to be classic")
+	}
+	if IsClassicIndicator(IndicatorMLEnsembleUp4H) || IsClassicIndicator(IndicatorFundSentimentComposite) {
+		t.Fatal("expected ML and sentiment indicators not to be classic")
+	}
+}
+
+func TestIndicatorsWithRisk(t *testing.T) {
+	out := IndicatorsWithRisk(func(indicator, interval string) RiskLevel {
+		if indicator == IndicatorMACD && interval == "1d" {
+			return RiskLevel1
+		}
+		return RiskLevel4
+	})
+	if len(out) != len(Indicators) {
+		t.Fatalf("expected %d indicators, got %d", len(Indicators), len(out))
+	}
+	for i, info := range out {
+		switch {
+		case info.Key == IndicatorMACD:
+			if info.RiskByInterval["1d"] != RiskLevel1 || info.MinRisk != RiskLevel1 || info.MaxRisk != RiskLevel4 {
+				t.Fatalf("unexpected macd entry %+v", info)
+			}
+		case info.Kind == IndicatorKindClassic:
+			if len(info.RiskByInterval) != len(SupportedIntervals) || info.MinRisk != RiskLevel4 || info.MaxRisk != RiskLevel4 {
+				t.Fatalf("unexpected %s entry %+v", info.Key, info)
+			}
+		default:
+			if info.RiskByInterval != nil || info.MinRisk != Indicators[i].MinRisk {
+				t.Fatalf("expected %s unchanged, got %+v", info.Key, info)
+			}
+		}
+	}
+	if Indicators[1].RiskByInterval != nil {
+		t.Fatal("expected the registry left untouched")
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "time"
+
+// CandleIntegrityCheck is one comparison of a random sample of stored
+// candles of a symbol and interval with the same candles re-fetched from
+// the price provider. Missing counts sampled candles the provider no longer
+// returned; they are not compared. Mismatched counts candles with an OHLC
+// value off by more than the tolerance.
+type CandleIntegrityCheck struct {
+	Symbol     string           `json:"symbol"`
+	Interval   string           `json:"interval"`
+	Provider   string           `json:"provider"`
+	CheckedAt  time.Time        `json:"checked_at"`
+	Sampled    int              `json:"sampled"`
+	Missing    int              `json:"missing"`
+	Mismatched int              `json:"mismatched"`
+	MaxDiffPct float64          `json:"max_diff_pct"`
+	Mismatches []CandleMismatch `json:"mismatches,omitempty"`
+}
+
+// Compared is the number of sampled candles the provider also returned.
+func (c CandleIntegrityCheck) Compared() int {
+	return c.Sampled - c.Missing
+}
+
+// CandleMismatch is a stored candle value that differs from the provider's
+// by DiffPct percent of the provider's value.
+type CandleMismatch struct {
+	OpenTime time.Time `json:"open_time"`
+	Field    string    `json:"field"`
+	Stored   float64   `json:"stored"`
+	Provider float64   `json:"provider"`
+	DiffPct  float64   `json:"diff_pct"`
+}
+
+// CandleIntegrityRate sums the checks of one symbol and interval.
+// MismatchRate is Mismatched / Compared, from 0 to 1.
+type CandleIntegrityRate struct {
+	Symbol         string     `json:"symbol"`
+	Interval       string     `json:"interval"`
+	Checks         int        `json:"checks"`
+	Compared       int        `json:"compared"`
+	Mismatched     int        `json:"mismatched"`
+	MismatchRate   float64    `json:"mismatch_rate"`
+	LastCheckedAt  time.Time  `json:"last_checked_at"`
+	LastMismatchAt *time.Time `json:"last_mismatch_at,omitempty"`
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Candle polling tiers. Short intervals are refreshed every few minutes
+// from a day of history, long ones every half hour from a month.
+const (
+	IntervalTierShort = "short"
+	IntervalTierLong  = "long"
+)
+
+// IntervalInfo describes one supported candle interval. Interval-dependent
+// behavior, such as polling tiers, aggregation, ML candle limits and signal
+// risk, is derived from these fields, so a new interval only needs an entry
+// in Intervals (and in the candle maps of providers that name intervals
+// differently).
+type IntervalInfo struct {
+	Name     string
+	Duration time.Duration
+	Label    string
+	Tier     string
+	// AggregateFrom is the finer interval these candles are rolled up from
+	// when none are stored directly, or empty.
+	AggregateFrom string
+}
+
+// PointsPerDay is how many candles of the interval fit in a day, at least 1.
+func (i IntervalInfo) PointsPerDay() int {
+	if i.Duration <= 0 || i.Duration >= 24*time.Hour {
+		return 1
+	}
+	return int(24 * time.Hour / i.Duration)
+}
+
+// Intervals is the interval registry, shortest first.
+var Intervals = []IntervalInfo{
+	{Name: "5m", Duration: 5 * time.Minute, Label: "5 minutes", Tier: IntervalTierShort},
+	{Name: "15m", Duration: 15 * time.Minute, Label: "15 minutes", Tier: IntervalTierShort, AggregateFrom: "5m"},
+	{Name: "1h", Duration: time.Hour, Label: "1 hour", Tier: IntervalTierShort, AggregateFrom: "15m"},
+	{Name: "4h", Duration: 4 * time.Hour, Label: "4 hours", Tier: IntervalTierLong, AggregateFrom: "1h"},
+	{Name: "1d", Duration: 24 * time.Hour, Label: "1 day", Tier: IntervalTierLong, AggregateFrom: "1h"},
+}
+
+// SupportedIntervals defines the candle intervals we store.
+var SupportedIntervals = intervalNames(Intervals)
+
+func intervalNames(infos []IntervalInfo) []string {
+	out := make([]string, 0, len(infos))
+	for _, info := range infos {
+		out = append(out, info.Name)
+	}
+	return out
+}
+
+// LookupInterval returns the registry entry for interval.
+func LookupInterval(interval string) (IntervalInfo, bool) {
+	for _, info := range Intervals {
+		if info.Name == interval {
+			return info, true
+		}
+	}
+	return IntervalInfo{}, false
+}
+
+// IntervalDuration returns the length of one candle for a supported
+// interval, or 0 if the interval is unknown.
+func IntervalDuration(interval string) time.Duration {
+	info, _ := LookupInterval(interval)
+	return info.Duration
+}
+
+// IntervalsInTier returns the supported intervals polled in tier, shortest
+// first.
+func IntervalsInTier(tier string) []string {
+	var out []string
+	for _, info := range Intervals {
+		if info.Tier == tier {
+			out = append(out, info.Name)
+		}
+	}
+	return out
+}
+
+// ValidateIntervals checks that infos are ordered shortest first, have
+// unique names and durations, a known tier, and aggregate from a shorter
+// registered interval that divides them evenly.
+func ValidateIntervals(infos []IntervalInfo) error {
+	seen := make(map[string]time.Duration, len(infos))
+	var prev time.Duration
+	for _, info := range infos {
+		if info.Name == "" || info.Duration <= 0 {
+			return fmt.Errorf("interval %q needs a name and a positive duration", info.Name)
+		}
+		if _, dup := seen[info.Name]; dup {
+			return fmt.Errorf("interval %q is registered twice", info.Name)
+		}
+		if info.Duration <= prev {
+			return fmt.Errorf("interval %q is not longer than the one before it", info.Name)
+		}
+		if info.Tier != IntervalTierShort && info.Tier != IntervalTierLong {
+			return fmt.Errorf("interval %q has unknown tier %q", info.Name, info.Tier)
+		}
+		if info.AggregateFrom != "" {
+			source, ok := seen[info.AggregateFrom]
+			if !ok || info.Duration%source != 0 {
+				return fmt.Errorf("interval %q cannot be aggregated from %q", info.Name, info.AggregateFrom)
+			}
+		}
+		seen[info.Name] = info.Duration
+		prev = info.Duration
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIntervalRegistry(t *testing.T) {
+	if err := ValidateIntervals(Intervals); err != nil {
+		t.Fatalf("built-in registry is invalid: %v", err)
+	}
+	if !reflect.DeepEqual(SupportedIntervals, []string{"5m", "15m", "1h", "4h", "1d"}) {
+		t.Fatalf("unexpected supported intervals %v", SupportedIntervals)
+	}
+	if got := IntervalsInTier(IntervalTierShort); !reflect.DeepEqual(got, []string{"5m", "15m", "1h"}) {
+		t.Fatalf("unexpected short tier %v", got)
+	}
+	if got := IntervalsInTier(IntervalTierLong); !reflect.DeepEqual(got, []string{"4h", "1d"}) {
+		t.Fatalf("unexpected long tier %v", got)
+	}
+	if IntervalDuration("4h") != 4*time.Hour || IntervalDuration("2h") != 0 {
+		t.Fatal("unexpected interval durations")
+	}
+	for interval, want := range map[string]int{"5m": 288, "15m": 96, "1h": 24, "4h": 6, "1d": 1} {
+		info, _ := LookupInterval(interval)
+		if got := info.PointsPerDay(); got != want {
+			t.Fatalf("PointsPerDay(%s) = %d, want %d", interval, got, want)
+		}
+	}
+}
+
+func TestValidateIntervalsRejectsBadEntries(t *testing.T) {
+	tests := map[string][]IntervalInfo{
+		"out of order": {
+			{Name: "1h", Duration: time.Hour, Tier: IntervalTierShort},
+			{Name: "30m", Duration: 30 * time.Minute, Tier: IntervalTierShort},
+		},
+		"unknown source": {
+			{Name: "8h", Duration: 8 * time.Hour, Tier: IntervalTierLong, AggregateFrom: "1h"},
+		},
+		"uneven source": {
+			{Name: "15m", Duration: 15 * time.Minute, Tier: IntervalTierShort},
+			{Name: "20m", Duration: 20 * time.Minute, Tier: IntervalTierShort, AggregateFrom: "15m"},
+		},
+		"no tier": {
+			{Name: "1h", Duration: time.Hour},
+		},
+	}
+	for name, infos := range tests {
+		if err := ValidateIntervals(infos); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
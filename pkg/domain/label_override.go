@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// LabelOverride is an operator's correction of the outcome of every
+// prediction on one candle, for labels produced by bad exchange data.
+// OriginalActualUp is the label the outcome resolver computed before the
+// first override.
+type LabelOverride struct {
+	Symbol              string    `json:"symbol"`
+	Interval            string    `json:"interval"`
+	OpenTime            time.Time `json:"open_time"`
+	ActualUp            bool      `json:"actual_up"`
+	OriginalActualUp    *bool     `json:"original_actual_up,omitempty"`
+	ExcludeFromTraining bool      `json:"exclude_from_training"`
+	Reason              string    `json:"reason"`
+	Actor               string    `json:"actor,omitempty"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// Key identifies the overridden candle, as used in the audit log.
+func (o LabelOverride) Key() string {
+	return fmt.Sprintf("%s/%s/%s", o.Symbol, o.Interval, o.OpenTime.UTC().Format(time.RFC3339))
+}
+
+// LabelReviewFilter narrows the resolved predictions listed for review.
+// Empty fields match everything.
+type LabelReviewFilter struct {
+	Symbol         string
+	Interval       string
+	ModelKey       string
+	OverriddenOnly bool
+	Limit          int
+}
+
+// LabelReview is one resolved prediction with the override, if any, of its
+// candle's label.
+type LabelReview struct {
+	ID             int64           `json:"id"`
+	Symbol         string          `json:"symbol"`
+	Interval       string          `json:"interval"`
+	OpenTime       time.Time       `json:"open_time"`
+	TargetTime     time.Time       `json:"target_time"`
+	ModelKey       string          `json:"model_key"`
+	ModelVersion   int             `json:"model_version"`
+	ProbUp         float64         `json:"prob_up"`
+	Direction      SignalDirection `json:"direction"`
+	ResolvedAt     time.Time       `json:"resolved_at"`
+	ActualUp       bool            `json:"actual_up"`
+	IsCorrect      bool            `json:"is_correct"`
+	RealizedReturn *float64        `json:"realized_return,omitempty"`
+	Override       *LabelOverride  `json:"override,omitempty"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// MaintenanceState is the operator maintenance switch. While Enabled,
+// pollers, ML jobs and alert dispatch pause and read APIs keep serving.
+type MaintenanceState struct {
+	Enabled   bool      `json:"enabled"`
+	Reason    string    `json:"reason,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
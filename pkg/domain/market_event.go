@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Market event kinds. Macro events apply to every symbol; unlocks name the
+// token being released.
+const (
+	MarketEventFOMC   = "fomc"
+	MarketEventCPI    = "cpi"
+	MarketEventUnlock = "unlock"
+	MarketEventOther  = "other"
+)
+
+// Market event importance, from a minor print to a decision that routinely
+// moves the whole market.
+const (
+	EventImportanceLow    = 1
+	EventImportanceMedium = 2
+	EventImportanceHigh   = 3
+)
+
+// MarketEvent is a scheduled event on the economic or token calendar.
+type MarketEvent struct {
+	Kind       string    `json:"kind"`
+	Symbol     string    `json:"symbol,omitempty"`
+	StartsAt   time.Time `json:"starts_at"`
+	Title      string    `json:"title"`
+	Importance int       `json:"importance"`
+	Source     string    `json:"source"`
+}
+
+// AppliesTo reports whether the event concerns symbol: macro events concern
+// every symbol, unlocks only their token.
+func (e MarketEvent) AppliesTo(symbol string) bool {
+	return e.Symbol == "" || strings.EqualFold(e.Symbol, symbol)
+}
+
+// ValidateMarketEvent checks an event read from a calendar source.
+func ValidateMarketEvent(e MarketEvent) error {
+	switch e.Kind {
+	case MarketEventFOMC, MarketEventCPI, MarketEventUnlock, MarketEventOther:
+	default:
+		return fmt.Errorf("unknown event kind %q", e.Kind)
+	}
+	if strings.TrimSpace(e.Title) == "" {
+		return fmt.Errorf("event title is required")
+	}
+	if e.StartsAt.IsZero() {
+		return fmt.Errorf("event time is required")
+	}
+	if e.Importance < EventImportanceLow || e.Importance > EventImportanceHigh {
+		return fmt.Errorf("importance must be between %d and %d", EventImportanceLow, EventImportanceHigh)
+	}
+	if e.Kind == MarketEventUnlock && e.Symbol == "" {
+		return fmt.Errorf("unlock events need a symbol")
+	}
+	return nil
+}
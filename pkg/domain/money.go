@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in US dollars.
+type Money float64
+
+// Format renders m with decimals places and thousands separators, e.g.
+// "$97,012.50" or "-$1,500.00". Amounts that round to zero have no sign.
+func (m Money) Format(decimals int) string {
+	v := roundTo(float64(m), decimals)
+	if v < 0 {
+		return "-$" + groupThousands(strconv.FormatFloat(-v, 'f', decimals, 64))
+	}
+	return "$" + groupThousands(strconv.FormatFloat(v, 'f', decimals, 64))
+}
+
+// String renders m in dollars and cents.
+func (m Money) String() string { return m.Format(2) }
+
+// Compact renders m with a magnitude suffix, e.g. "$45.1B", for notional
+// amounts such as 24h volume.
+func (m Money) Compact() string {
+	v := float64(m)
+	if v < 0 {
+		return "-" + Money(-v).Compact()
+	}
+	switch {
+	case v >= 1e12:
+		return fmt.Sprintf("$%.1fT", v/1e12)
+	case v >= 1e9:
+		return fmt.Sprintf("$%.1fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("$%.1fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("$%.1fK", v/1e3)
+	default:
+		return m.Format(0)
+	}
+}
+
+// MarshalJSON writes m as a number rounded to 8 decimals, dropping binary
+// float noise such as 0.30000000000000004.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return marshalRounded(float64(m), 8)
+}
+
+// Percent is a percentage in percent units: 2.5 means 2.5%.
+type Percent float64
+
+// PercentFromRatio converts a ratio such as a 0.025 return to a Percent.
+func PercentFromRatio(r float64) Percent { return Percent(r * 100) }
+
+// Format renders p with decimals places and an explicit sign, e.g. "+2.50%"
+// or "-1.20%". Values that round to zero render unsigned as "0.00%", never
+// "-0.00%".
+func (p Percent) Format(decimals int) string {
+	v := roundTo(float64(p), decimals)
+	s := strconv.FormatFloat(v, 'f', decimals, 64) + "%"
+	if v > 0 {
+		return "+" + s
+	}
+	return s
+}
+
+// String renders p with two decimals.
+func (p Percent) String() string { return p.Format(2) }
+
+// MarshalJSON writes p as a number rounded to 4 decimals.
+func (p Percent) MarshalJSON() ([]byte, error) {
+	return marshalRounded(float64(p), 4)
+}
+
+// roundTo rounds v half away from zero to decimals places. A result of
+// negative zero becomes zero.
+func roundTo(v float64, decimals int) float64 {
+	scale := math.Pow10(decimals)
+	r := math.Round(v*scale) / scale
+	if r == 0 {
+		return 0
+	}
+	return r
+}
+
+func marshalRounded(v float64, decimals int) ([]byte, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("unsupported value %v", v)
+	}
+	return strconv.AppendFloat(nil, roundTo(v, decimals), 'f', -1, 64), nil
+}
+
+// groupThousands inserts commas into the integer part of an unsigned
+// decimal string.
+func groupThousands(s string) string {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, ch := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(ch)
+	}
+	if hasFrac {
+		b.WriteString("." + frac)
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestPercentFormatNeverShowsNegativeZero(t *testing.T) {
+	tests := []struct {
+		p    Percent
+		want string
+	}{
+		{2.345, "+2.35%"},
+		{-1.2, "-1.20%"},
+		{-0.004, "0.00%"},
+		{Percent(math.Copysign(0, -1)), "0.00%"},
+		{0.004, "0.00%"},
+	}
+	for _, tt := range tests {
+		if got := tt.p.String(); got != tt.want {
+			t.Fatalf("Percent(%v).String() = %s, want %s", float64(tt.p), got, tt.want)
+		}
+	}
+	if got := PercentFromRatio(-0.0004).Format(1); got != "0.0%" {
+		t.Fatalf("expected a rounded-away loss to render as 0.0%%, got %s", got)
+	}
+	if got := PercentFromRatio(0.0251).Format(1); got != "+2.5%" {
+		t.Fatalf("expected +2.5%%, got %s", got)
+	}
+}
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		m        Money
+		decimals int
+		want     string
+	}{
+		{1234567.891, 2, "$1,234,567.89"},
+		{-1500, 2, "-$1,500.00"},
+		{-0.001, 2, "$0.00"},
+		{0.162341, 5, "$0.16234"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Format(tt.decimals); got != tt.want {
+			t.Fatalf("Money(%v).Format(%d) = %s, want %s", float64(tt.m), tt.decimals, got, tt.want)
+		}
+	}
+	for v, want := range map[Money]string{
+		45_123_000_000: "$45.1B",
+		2_500_000:      "$2.5M",
+		950:            "$950",
+		1.2e12:         "$1.2T",
+		-2_500_000:     "-$2.5M",
+	} {
+		if got := v.Compact(); got != want {
+			t.Fatalf("Money(%v).Compact() = %s, want %s", float64(v), got, want)
+		}
+	}
+}
+
+func TestMoneyAndPercentJSON(t *testing.T) {
+	snapshot := PriceSnapshot{
+		Symbol:       "BTC",
+		PriceUSD:     97000,
+		Volume24h:    Money(0.1 + 0.2),
+		Change24hPct: Percent(math.Copysign(0.00001, -1)),
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"symbol":"BTC","price_usd":97000,"volume_24h":0.3,"change_24h_pct":0,"last_updated_unix":0}`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON:\n got %s\nwant %s", data, want)
+	}
+
+	var decoded PriceSnapshot
+	if err := json.Unmarshal([]byte(`{"volume_24h":45.5,"change_24h_pct":-1.25}`), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Volume24h != 45.5 || decoded.Change24hPct != -1.25 {
+		t.Fatalf("unexpected decoded snapshot: %+v", decoded)
+	}
+	if _, err := json.Marshal(Percent(math.NaN())); err == nil {
+		t.Fatal("expected NaN to be rejected")
+	}
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// OrderBookSnapshot is the top of one symbol's order book at a moment.
+// Depths are the USD notional resting within DepthBandPct of the mid price
+// on each side. Imbalance is (bid - ask) / (bid + ask), from -1 when only
+// asks rest near the mid to 1 when only bids do.
+type OrderBookSnapshot struct {
+	Symbol       string    `json:"symbol"`
+	Source       string    `json:"source"`
+	CapturedAt   time.Time `json:"captured_at"`
+	BestBid      float64   `json:"best_bid"`
+	BestAsk      float64   `json:"best_ask"`
+	SpreadBps    float64   `json:"spread_bps"`
+	DepthBandPct float64   `json:"depth_band_pct"`
+	BidDepthUSD  float64   `json:"bid_depth_usd"`
+	AskDepthUSD  float64   `json:"ask_depth_usd"`
+	Imbalance    float64   `json:"imbalance"`
+}
+
+// OrderBookLevel is one price level of an order book side.
+type OrderBookLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+// OrderBookFeatures averages the snapshots captured during one hour, as
+// the ML feature engine consumes them.
+type OrderBookFeatures struct {
+	Hour      time.Time
+	Imbalance float64
+	SpreadBps float64
+	Snapshots int
+}
+
+// SummarizeOrderBook builds a snapshot from raw book sides. Bids must be
+// best (highest) first and asks best (lowest) first. It returns false when
+// either side is empty or the book is crossed.
+func SummarizeOrderBook(bids, asks []OrderBookLevel, bandPct float64) (OrderBookSnapshot, bool) {
+	if len(bids) == 0 || len(asks) == 0 {
+		return OrderBookSnapshot{}, false
+	}
+	bestBid, bestAsk := bids[0].Price, asks[0].Price
+	if bestBid <= 0 || bestAsk <= bestBid {
+		return OrderBookSnapshot{}, false
+	}
+	mid := (bestBid + bestAsk) / 2
+	out := OrderBookSnapshot{
+		BestBid:      bestBid,
+		BestAsk:      bestAsk,
+		SpreadBps:    (bestAsk - bestBid) / mid * 10_000,
+		DepthBandPct: bandPct,
+	}
+	floor := mid * (1 - bandPct/100)
+	for _, l := range bids {
+		if l.Price < floor {
+			break
+		}
+		out.BidDepthUSD += l.Price * l.Quantity
+	}
+	ceiling := mid * (1 + bandPct/100)
+	for _, l := range asks {
+		if l.Price > ceiling {
+			break
+		}
+		out.AskDepthUSD += l.Price * l.Quantity
+	}
+	if total := out.BidDepthUSD + out.AskDepthUSD; total > 0 {
+		out.Imbalance = (out.BidDepthUSD - out.AskDepthUSD) / total
+	}
+	return out, true
+}
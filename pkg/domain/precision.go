@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PriceDecimals is the number of decimal places each symbol's USD price is
+// quoted with. Sub-dollar assets move in fractions of a cent, so cents alone
+// would hide most of their moves.
+var PriceDecimals = map[string]int{
+	"BTC":   2,
+	"ETH":   2,
+	"SOL":   2,
+	"AVAX":  2,
+	"LINK":  3,
+	"DOT":   3,
+	"XRP":   4,
+	"ADA":   4,
+	"MATIC": 4,
+	"DOGE":  5,
+}
+
+const defaultPriceDecimals = 2
+
+// PricePrecision returns the decimal places for symbol's price: the tracked
+// asset's own setting, then PriceDecimals, then cents.
+func PricePrecision(symbol string) int {
+	symbol = strings.ToUpper(symbol)
+	if a, ok := LookupAsset(symbol); ok && a.PriceDecimals > 0 {
+		return a.PriceDecimals
+	}
+	if d, ok := PriceDecimals[symbol]; ok {
+		return d
+	}
+	return defaultPriceDecimals
+}
+
+// RoundPrice rounds v to symbol's price precision.
+func RoundPrice(symbol string, v float64) float64 {
+	return roundTo(v, PricePrecision(symbol))
+}
+
+// FormatPrice renders a USD price at symbol's precision with thousands
+// separators, e.g. "$97,012.50" or "$0.16234".
+func FormatPrice(symbol string, v float64) string {
+	return Money(v).Format(PricePrecision(symbol))
+}
+
+// FormatPriceValue is FormatPrice without the currency sign, for tables and
+// chart axes.
+func FormatPriceValue(symbol string, v float64) string {
+	v = RoundPrice(symbol, v)
+	s := groupThousands(strconv.FormatFloat(math.Abs(v), 'f', PricePrecision(symbol), 64))
+	if v < 0 {
+		return "-" + s
+	}
+	return s
+}
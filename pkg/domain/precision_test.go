@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestFormatPriceUsesSymbolPrecision(t *testing.T) {
+	tests := []struct {
+		symbol string
+		value  float64
+		want   string
+	}{
+		{"BTC", 97012.5, "$97,012.50"},
+		{"DOGE", 0.162341, "$0.16234"},
+		{"ADA", 0.45678, "$0.4568"},
+		{"LINK", 14.2345, "$14.235"},
+		{"eth", 1234567.891, "$1,234,567.89"},
+		{"UNKNOWN", 0.5, "$0.50"},
+		{"BTC", -1500, "-$1,500.00"},
+	}
+	for _, tt := range tests {
+		if got := FormatPrice(tt.symbol, tt.value); got != tt.want {
+			t.Fatalf("FormatPrice(%s, %v) = %s, want %s", tt.symbol, tt.value, got, tt.want)
+		}
+	}
+	if got := RoundPrice("DOGE", 0.1623449); got != 0.16234 {
+		t.Fatalf("expected DOGE rounded to 5 decimals, got %v", got)
+	}
+	for _, a := range DefaultAssets {
+		if _, ok := PriceDecimals[a.Symbol]; !ok {
+			t.Fatalf("no price precision for %s", a.Symbol)
+		}
+	}
+}
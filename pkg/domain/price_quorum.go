@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// PriceDiscrepancy is a provider price that strayed from the quorum price
+// for the symbol: the median of every provider's price fetched in the same
+// refresh, or the other provider's price when there are two. Quotes holds
+// every provider's price.
+type PriceDiscrepancy struct {
+	Symbol       string             `json:"symbol"`
+	Provider     string             `json:"provider"`
+	PriceUSD     float64            `json:"price_usd"`
+	QuorumUSD    float64            `json:"quorum_usd"`
+	DeviationPct float64            `json:"deviation_pct"`
+	Quotes       map[string]float64 `json:"quotes"`
+	Rejected     bool               `json:"rejected"`
+	ObservedAt   time.Time          `json:"observed_at"`
+}
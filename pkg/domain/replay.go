@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// ReplayRun describes one historical regeneration of signals. Its signals
+// are stored apart from the live signals table under the run ID.
+type ReplayRun struct {
+	ID          string     `json:"id"`
+	From        time.Time  `json:"from"`
+	To          time.Time  `json:"to"`
+	Symbols     []string   `json:"symbols"`
+	Intervals   []string   `json:"intervals"`
+	Notes       string     `json:"notes,omitempty"`
+	SignalCount int64      `json:"signal_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// ReplayComparison counts, per indicator, how the signals of a replay run
+// line up with the live signals for the same symbols, intervals and range.
+type ReplayComparison struct {
+	Indicator  string `json:"indicator"`
+	Matched    int64  `json:"matched"`
+	ReplayOnly int64  `json:"replay_only"`
+	LiveOnly   int64  `json:"live_only"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// SeasonalityBucket summarises the one-candle close-to-close returns of the
+// candles that opened in one calendar bucket, such as 14:00 UTC or Sunday.
+type SeasonalityBucket struct {
+	Bucket     int     `json:"bucket"`
+	Label      string  `json:"label"`
+	Samples    int     `json:"samples"`
+	MeanReturn float64 `json:"mean_return"`
+	UpRate     float64 `json:"up_rate"`
+}
+
+// Seasonality is the average return of a symbol's candles per UTC hour of
+// day, day of week and month. HourOfDay is empty for daily candles.
+type Seasonality struct {
+	Symbol    string              `json:"symbol"`
+	Interval  string              `json:"interval"`
+	From      time.Time           `json:"from"`
+	To        time.Time           `json:"to"`
+	Samples   int                 `json:"samples"`
+	HourOfDay []SeasonalityBucket `json:"hour_of_day"`
+	DayOfWeek []SeasonalityBucket `json:"day_of_week"`
+	Month     []SeasonalityBucket `json:"month"`
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Trading session labels, in the order CandleSessions reports them.
+const (
+	SessionAsia    = "asia"
+	SessionEU      = "eu"
+	SessionUS      = "us"
+	SessionWeekend = "weekend"
+)
+
+// TradingSession is a traditional market's trading day in fixed UTC hours.
+// Crypto trades around the clock, but its volume still follows them.
+type TradingSession struct {
+	Name  string
+	Start time.Duration // since 00:00 UTC
+	End   time.Duration
+}
+
+// TradingSessions lists the weekday sessions. The hours ignore daylight
+// saving, so they are approximate by an hour for part of the year.
+var TradingSessions = []TradingSession{
+	{Name: SessionAsia, Start: 0, End: 9 * time.Hour},
+	{Name: SessionEU, Start: 7 * time.Hour, End: 16 * time.Hour},
+	{Name: SessionUS, Start: 13 * time.Hour, End: 21 * time.Hour},
+}
+
+// IsWeekend reports whether t falls on a Saturday or Sunday in UTC.
+func IsWeekend(t time.Time) bool {
+	day := t.UTC().Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
+
+// CandleSessions returns the labels of the weekday sessions that overlap the
+// candle opening at openTime and lasting length, followed by SessionWeekend
+// when any part of it falls on a weekend.
+func CandleSessions(openTime time.Time, length time.Duration) []string {
+	if length <= 0 {
+		length = time.Nanosecond
+	}
+	start := openTime.UTC()
+	end := start.Add(length)
+
+	active := make(map[string]bool, len(TradingSessions)+1)
+	for day := start.Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		if IsWeekend(day) {
+			active[SessionWeekend] = true
+			continue
+		}
+		for _, s := range TradingSessions {
+			if day.Add(s.Start).Before(end) && start.Before(day.Add(s.End)) {
+				active[s.Name] = true
+			}
+		}
+	}
+
+	out := make([]string, 0, len(active))
+	for _, s := range TradingSessions {
+		if active[s.Name] {
+			out = append(out, s.Name)
+		}
+	}
+	if active[SessionWeekend] {
+		out = append(out, SessionWeekend)
+	}
+	return out
+}
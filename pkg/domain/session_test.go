@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCandleSessions(t *testing.T) {
+	// Friday 2026-03-06.
+	friday := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		open   time.Time
+		length time.Duration
+		want   []string
+	}{
+		{"asia only", friday.Add(2 * time.Hour), time.Hour, []string{SessionAsia}},
+		{"asia and eu overlap", friday.Add(8 * time.Hour), time.Hour, []string{SessionAsia, SessionEU}},
+		{"session end is exclusive", friday.Add(9 * time.Hour), time.Hour, []string{SessionEU}},
+		{"eu and us overlap", friday.Add(15*time.Hour + 55*time.Minute), 5 * time.Minute, []string{SessionEU, SessionUS}},
+		{"between sessions", friday.Add(22 * time.Hour), time.Hour, []string{}},
+		{"into the weekend", friday.Add(20 * time.Hour), 4 * time.Hour, []string{SessionUS}},
+		{"saturday", friday.Add(36 * time.Hour), time.Hour, []string{SessionWeekend}},
+		{"daily weekday", friday, 24 * time.Hour, []string{SessionAsia, SessionEU, SessionUS}},
+		{"sunday into monday", friday.Add(68 * time.Hour), 8 * time.Hour, []string{SessionAsia, SessionWeekend}},
+		{"unknown length", friday.Add(14 * time.Hour), 0, []string{SessionEU, SessionUS}},
+		{"other zone", time.Date(2026, 3, 6, 10, 0, 0, 0, time.FixedZone("JST", 9*3600)), time.Hour, []string{SessionAsia}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CandleSessions(tc.open, tc.length); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestIsWeekend(t *testing.T) {
+	if !IsWeekend(time.Date(2026, 3, 8, 23, 59, 0, 0, time.UTC)) {
+		t.Fatal("expected Sunday to be a weekend")
+	}
+	// 01:00 Monday in Tokyo is still Sunday in UTC.
+	if !IsWeekend(time.Date(2026, 3, 9, 1, 0, 0, 0, time.FixedZone("JST", 9*3600))) {
+		t.Fatal("expected the weekend to be judged in UTC")
+	}
+	if IsWeekend(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("expected Monday not to be a weekend")
+	}
+}
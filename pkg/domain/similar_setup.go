@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// SimilarSetup is a past feature row close to a query state, with what the
+// market did next. RealizedReturn is nil when the candles up to the target
+// were not all stored.
+type SimilarSetup struct {
+	Symbol         string    `json:"symbol"`
+	OpenTime       time.Time `json:"open_time"`
+	Similarity     float64   `json:"similarity"`
+	WentUp         bool      `json:"went_up"`
+	RealizedReturn *float64  `json:"realized_return,omitempty"`
+}
+
+// SimilarSetups answers "when did the market last look like this": the k
+// past states nearest to the latest feature row of Symbol and a summary of
+// their outcomes over TargetHours.
+type SimilarSetups struct {
+	Symbol      string             `json:"symbol"`
+	Interval    string             `json:"interval"`
+	OpenTime    time.Time          `json:"open_time"`
+	TargetHours int                `json:"target_hours"`
+	Features    map[string]float64 `json:"features"`
+	Candidates  int                `json:"candidates"`
+	Matches     []SimilarSetup     `json:"matches"`
+	UpRate      float64            `json:"up_rate"`
+	MeanReturn  *float64           `json:"mean_return,omitempty"`
+}
@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Asset is one tracked crypto asset and its identifiers at each price
+// source. An empty pair means the asset is not listed on that exchange.
+type Asset struct {
+	Symbol        string `json:"symbol"`
+	Name          string `json:"name,omitempty"`
+	CoinGeckoID   string `json:"coingecko_id"`
+	BinancePair   string `json:"binance_pair,omitempty"`
+	KrakenPair    string `json:"kraken_pair,omitempty"`
+	CoinbasePair  string `json:"coinbase_pair,omitempty"`
+	PriceDecimals int    `json:"price_decimals,omitempty"`
+	AssetPauses
+}
+
+// AssetPauses switch parts of the pipeline off for one tracked asset, for
+// example during a data-quality issue, without untracking it. Prices and
+// candles are still collected while paused.
+type AssetPauses struct {
+	// SignalsPaused stops signal generation: engine, composite and
+	// cex-dex spread signals.
+	SignalsPaused bool `json:"signals_paused"`
+	// MLPaused stops ML inference, so no predictions or ML signals.
+	MLPaused bool `json:"ml_paused"`
+	// AlertsPaused stores signals as usual but sends no alerts for them.
+	AlertsPaused bool `json:"alerts_paused"`
+}
+
+// merge pauses everything paused in either p or other.
+func (p AssetPauses) merge(other AssetPauses) AssetPauses {
+	return AssetPauses{
+		SignalsPaused: p.SignalsPaused || other.SignalsPaused,
+		MLPaused:      p.MLPaused || other.MLPaused,
+		AlertsPaused:  p.AlertsPaused || other.AlertsPaused,
+	}
+}
+
+// DefaultAssets are tracked until a stored registry is loaded, and whenever
+// none is available. Binance pairs are quoted in USDT, Kraken pairs use the
+// full pair names Kraken keys its responses by, and MATIC trades as POL since
+// the token migration.
+var DefaultAssets = []Asset{
+	{Symbol: "BTC", Name: "Bitcoin", CoinGeckoID: "bitcoin", BinancePair: "BTCUSDT", KrakenPair: "XXBTZUSD", CoinbasePair: "BTC-USD"},
+	{Symbol: "ETH", Name: "Ethereum", CoinGeckoID: "ethereum", BinancePair: "ETHUSDT", KrakenPair: "XETHZUSD", CoinbasePair: "ETH-USD"},
+	{Symbol: "SOL", Name: "Solana", CoinGeckoID: "solana", BinancePair: "SOLUSDT", KrakenPair: "SOLUSD", CoinbasePair: "SOL-USD"},
+	{Symbol: "XRP", Name: "XRP", CoinGeckoID: "ripple", BinancePair: "XRPUSDT", KrakenPair: "XXRPZUSD", CoinbasePair: "XRP-USD"},
+	{Symbol: "ADA", Name: "Cardano", CoinGeckoID: "cardano", BinancePair: "ADAUSDT", KrakenPair: "ADAUSD", CoinbasePair: "ADA-USD"},
+	{Symbol: "DOGE", Name: "Dogecoin", CoinGeckoID: "dogecoin", BinancePair: "DOGEUSDT", KrakenPair: "XDGUSD", CoinbasePair: "DOGE-USD"},
+	{Symbol: "DOT", Name: "Polkadot", CoinGeckoID: "polkadot", BinancePair: "DOTUSDT", KrakenPair: "DOTUSD", CoinbasePair: "DOT-USD"},
+	{Symbol: "AVAX", Name: "Avalanche", CoinGeckoID: "avalanche-2", BinancePair: "AVAXUSDT", KrakenPair: "AVAXUSD", CoinbasePair: "AVAX-USD"},
+	{Symbol: "LINK", Name: "Chainlink", CoinGeckoID: "chainlink", BinancePair: "LINKUSDT", KrakenPair: "LINKUSD", CoinbasePair: "LINK-USD"},
+	{Symbol: "MATIC", Name: "Polygon", CoinGeckoID: "matic-network", BinancePair: "POLUSDT", KrakenPair: "POLUSD", CoinbasePair: "POL-USD"},
+}
+
+// symbolRegistry is the process-wide set of tracked assets. Readers get
+// copies, so a reload never changes a slice or map a caller holds.
+type symbolRegistry struct {
+	mu            sync.RWMutex
+	raw           []Asset
+	configured    map[string]AssetPauses
+	assets        []Asset
+	bySymbol      map[string]Asset
+	byCoinGeckoID map[string]string
+	version       atomic.Uint64
+}
+
+var registry = newSymbolRegistry(DefaultAssets)
+
+func newSymbolRegistry(assets []Asset) *symbolRegistry {
+	r := &symbolRegistry{}
+	r.set(assets)
+	return r
+}
+
+func (r *symbolRegistry) set(assets []Asset) {
+	r.mu.RLock()
+	configured := r.configured
+	r.mu.RUnlock()
+
+	bySymbol := make(map[string]Asset, len(assets))
+	byID := make(map[string]string, len(assets))
+	kept := make([]Asset, 0, len(assets))
+	for _, a := range assets {
+		a.Symbol = strings.ToUpper(strings.TrimSpace(a.Symbol))
+		if a.Symbol == "" || a.CoinGeckoID == "" {
+			continue
+		}
+		if _, dup := bySymbol[a.Symbol]; dup {
+			continue
+		}
+		a.AssetPauses = a.AssetPauses.merge(configured[a.Symbol])
+		bySymbol[a.Symbol] = a
+		byID[a.CoinGeckoID] = a.Symbol
+		kept = append(kept, a)
+	}
+	r.mu.Lock()
+	r.raw = assets
+	r.assets = kept
+	r.bySymbol = bySymbol
+	r.byCoinGeckoID = byID
+	r.mu.Unlock()
+	r.version.Add(1)
+}
+
+// SetAssets replaces the tracked assets, keeping their order. Entries without
+// a symbol or CoinGecko ID are dropped, and an empty list restores
+// DefaultAssets so the process always tracks something.
+func SetAssets(assets []Asset) {
+	if len(assets) == 0 {
+		assets = DefaultAssets
+	}
+	registry.set(assets)
+}
+
+// SetConfiguredPauses pauses parts of the pipeline for the symbols in
+// pauses on top of whatever the stored registry pauses, so a deployment can
+// pause a symbol from its configuration. It applies to the tracked assets at
+// once and to every later SetAssets.
+func SetConfiguredPauses(pauses map[string]AssetPauses) {
+	configured := make(map[string]AssetPauses, len(pauses))
+	for symbol, p := range pauses {
+		configured[strings.ToUpper(strings.TrimSpace(symbol))] = p
+	}
+	registry.mu.Lock()
+	registry.configured = configured
+	raw := registry.raw
+	registry.mu.Unlock()
+	registry.set(raw)
+}
+
+// Assets returns the tracked assets in registry order.
+func Assets() []Asset {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return append([]Asset(nil), registry.assets...)
+}
+
+// AssetsVersion changes every time the tracked assets are replaced, so
+// long-lived consumers such as streaming connections can tell when to
+// resubscribe.
+func AssetsVersion() uint64 {
+	return registry.version.Load()
+}
+
+// SupportedSymbols lists all tracked crypto symbols in registry order.
+func SupportedSymbols() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	out := make([]string, len(registry.assets))
+	for i, a := range registry.assets {
+		out[i] = a.Symbol
+	}
+	return out
+}
+
+// LookupAsset returns the tracked asset for an upper-case symbol.
+func LookupAsset(symbol string) (Asset, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	a, ok := registry.bySymbol[symbol]
+	return a, ok
+}
+
+// IsSupportedSymbol reports whether an upper-case symbol is tracked.
+func IsSupportedSymbol(symbol string) bool {
+	_, ok := LookupAsset(symbol)
+	return ok
+}
+
+// SignalsPaused reports whether signal generation is paused for symbol.
+// Untracked symbols are never paused.
+func SignalsPaused(symbol string) bool {
+	a, ok := LookupAsset(symbol)
+	return ok && a.SignalsPaused
+}
+
+// MLPaused reports whether ML inference is paused for symbol.
+func MLPaused(symbol string) bool {
+	a, ok := LookupAsset(symbol)
+	return ok && a.MLPaused
+}
+
+// AlertsPaused reports whether alerts are paused for symbol.
+func AlertsPaused(symbol string) bool {
+	a, ok := LookupAsset(symbol)
+	return ok && a.AlertsPaused
+}
+
+// CoinGeckoIDFor returns the CoinGecko API identifier of a tracked symbol.
+func CoinGeckoIDFor(symbol string) (string, bool) {
+	a, ok := LookupAsset(symbol)
+	return a.CoinGeckoID, ok
+}
+
+// SymbolForCoinGeckoID is the reverse of CoinGeckoIDFor.
+func SymbolForCoinGeckoID(id string) (string, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	symbol, ok := registry.byCoinGeckoID[id]
+	return symbol, ok
+}
+
+// BinancePairs maps tracked symbols to their Binance spot pairs.
+func BinancePairs() map[string]string {
+	return pairsBy(func(a Asset) string { return a.BinancePair })
+}
+
+// KrakenPairs maps tracked symbols to their Kraken USD pairs.
+func KrakenPairs() map[string]string {
+	return pairsBy(func(a Asset) string { return a.KrakenPair })
+}
+
+// CoinbasePairs maps tracked symbols to their Coinbase USD product IDs.
+func CoinbasePairs() map[string]string {
+	return pairsBy(func(a Asset) string { return a.CoinbasePair })
+}
+
+func pairsBy(pair func(Asset) string) map[string]string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	out := make(map[string]string, len(registry.assets))
+	for _, a := range registry.assets {
+		if p := pair(a); p != "" {
+			out[a.Symbol] = p
+		}
+	}
+	return out
+}
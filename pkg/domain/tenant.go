@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// DefaultTenantID is the tenant assigned to requests that are not bound to a
+// specific tenant (the operator key, SSH, the web console, background jobs).
+const DefaultTenantID = "default"
+
+// LegacyKeyTenantID is the tenant of REST_API_KEYS entries written without
+// one. It is an ordinary tenant: only DefaultTenantID has operator rights.
+const LegacyKeyTenantID = "legacy"
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx scoped to the given tenant. Empty tenant IDs
+// resolve to DefaultTenantID.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, NormalizeTenantID(tenantID))
+}
+
+// TenantFromContext returns the tenant bound to ctx, or DefaultTenantID.
+func TenantFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return DefaultTenantID
+	}
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
+
+// NormalizeTenantID lowercases and trims a tenant identifier.
+func NormalizeTenantID(tenantID string) string {
+	tenantID = strings.ToLower(strings.TrimSpace(tenantID))
+	if tenantID == "" {
+		return DefaultTenantID
+	}
+	return tenantID
+}
+
+// TelegramTenantID returns the tenant of a Telegram chat. The chat's advisor
+// conversation and the self-service API keys it issues belong to it.
+func TelegramTenantID(chatID int64) string {
+	return "telegram-" + strconv.FormatInt(chatID, 10)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTenantFromContextDefaults(t *testing.T) {
+	if got := TenantFromContext(context.Background()); got != DefaultTenantID {
+		t.Fatalf("expected default tenant, got %q", got)
+	}
+}
+
+func TestWithTenantNormalizes(t *testing.T) {
+	ctx := WithTenant(context.Background(), "  Acme ")
+	if got := TenantFromContext(ctx); got != "acme" {
+		t.Fatalf("expected acme tenant, got %q", got)
+	}
+	ctx = WithTenant(context.Background(), "")
+	if got := TenantFromContext(ctx); got != DefaultTenantID {
+		t.Fatalf("expected default tenant for empty id, got %q", got)
+	}
+}
+
+func TestTelegramTenantID(t *testing.T) {
+	if got := TelegramTenantID(123456); got != "telegram-123456" {
+		t.Fatalf("unexpected tenant %q", got)
+	}
+	if got := NormalizeTenantID(TelegramTenantID(42)); got != TelegramTenantID(42) {
+		t.Fatalf("telegram tenant should already be normalized, got %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	// The runtime image has no zoneinfo, so the database is embedded.
+	_ "time/tzdata"
+)
+
+// LoadTimezone resolves an IANA time zone name such as Europe/Berlin, for
+// showing stored UTC times in a user's local time. An empty name is UTC.
+// "Local" is rejected: the server's own zone means nothing to a user.
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.EqualFold(name, "UTC") {
+		return time.UTC, nil
+	}
+	if strings.EqualFold(name, "Local") {
+		return nil, fmt.Errorf("unknown time zone: %s", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone: %s", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	for _, name := range []string{"", " utc ", "UTC"} {
+		if loc, err := LoadTimezone(name); err != nil || loc != time.UTC {
+			t.Fatalf("%q: expected UTC, got %v %v", name, loc, err)
+		}
+	}
+	loc, err := LoadTimezone("America/New_York")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := time.Date(2026, 7, 1, 16, 0, 0, 0, time.UTC).In(loc).Format("15:04 MST"); got != "12:00 EDT" {
+		t.Fatalf("unexpected local time %s", got)
+	}
+	for _, name := range []string{"Local", "Mars/Olympus", "../etc/passwd"} {
+		if _, err := LoadTimezone(name); err == nil {
+			t.Fatalf("expected %q rejected", name)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package domain
+
+// TUIState is the SSH TUI screen state kept per user, so a new session opens
+// where the last one left off. Fields hold option names rather than
+// positions, so reordering tabs or filters does not scramble saved state;
+// values the TUI no longer knows fall back to its defaults. The watchlist
+// is stored on its own.
+type TUIState struct {
+	Tab             string `json:"tab,omitempty"`
+	Theme           string `json:"theme,omitempty"`
+	SignalSymbol    string `json:"signal_symbol,omitempty"`
+	SignalRisk      string `json:"signal_risk,omitempty"`
+	SignalIndicator string `json:"signal_indicator,omitempty"`
+	HeatMetric      string `json:"heat_metric,omitempty"`
+	BacktestView    string `json:"backtest_view,omitempty"`
+}
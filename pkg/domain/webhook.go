@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// SignalWebhook is an HTTP endpoint that receives new signals. The body is
+// PayloadTemplate, a Go text/template executed with the Signal, or the
+// signal's JSON when the template is empty. Headers are sent with every
+// request and may carry credentials, so API responses redact their values.
+// Each webhook belongs to the tenant that created it; every tenant's
+// webhooks receive every signal.
+type SignalWebhook struct {
+	ID              int64             `json:"id"`
+	TenantID        string            `json:"tenant_id"`
+	Label           string            `json:"label"`
+	URL             string            `json:"url"`
+	PayloadTemplate string            `json:"payload_template,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	DisabledAt      *time.Time        `json:"disabled_at,omitempty"`
+}
+
+// Redacted returns a copy of w with header values hidden.
+func (w SignalWebhook) Redacted() SignalWebhook {
+	if len(w.Headers) == 0 {
+		return w
+	}
+	headers := make(map[string]string, len(w.Headers))
+	for name := range w.Headers {
+		headers[name] = "[redacted]"
+	}
+	w.Headers = headers
+	return w
+}
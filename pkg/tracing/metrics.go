@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+var newMetricExporter = func(ctx context.Context, endpoint string) (sdkmetric.Exporter, error) {
+	return otlpmetricgrpc.New(ctx,
+		otlpmetricgrpc.WithEndpoint(endpoint),
+		otlpmetricgrpc.WithInsecure(),
+	)
+}
+
+// InitMeter installs the global MeterProvider behind otel.Meter, exporting
+// over OTLP to the same collector as traces. The interval follows
+// OTEL_METRIC_EXPORT_INTERVAL (60s by default). METRICS_ENABLED=false, or
+// TRACING_ENABLED=false when METRICS_ENABLED is unset, installs a provider
+// without an exporter, so instruments record nothing.
+func InitMeter(ctx context.Context) (*sdkmetric.MeterProvider, error) {
+	enabled := os.Getenv("METRICS_ENABLED")
+	if enabled == "" {
+		enabled = os.Getenv("TRACING_ENABLED")
+	}
+	if enabled == "false" {
+		mp := sdkmetric.NewMeterProvider()
+		otel.SetMeterProvider(mp)
+		return mp, nil
+	}
+
+	otelEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if otelEndpoint == "" {
+		otelEndpoint = "localhost:4317"
+	}
+
+	exporter, err := newMetricExporter(ctx, otelEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := newResource(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(mp)
+	return mp, nil
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInitMeterExportsGlobalInstruments(t *testing.T) {
+	t.Setenv("METRICS_ENABLED", "true")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "collector:4317")
+
+	orig := newMetricExporter
+	defer func() { newMetricExporter = orig }()
+
+	stub := &stubMetricExporter{}
+	newMetricExporter = func(ctx context.Context, endpoint string) (sdkmetric.Exporter, error) {
+		stub.endpoint = endpoint
+		return stub, nil
+	}
+
+	mp, err := InitMeter(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stub.endpoint != "collector:4317" {
+		t.Fatalf("expected endpoint to be propagated, got %s", stub.endpoint)
+	}
+
+	counter, err := otel.Meter("tracing-test").Int64Counter("test.count")
+	if err != nil {
+		t.Fatalf("counter: %v", err)
+	}
+	counter.Add(context.Background(), 2)
+
+	// Shutdown flushes the periodic reader.
+	if err := mp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown error: %v", err)
+	}
+	if !stub.exported("test.count") {
+		t.Fatal("expected the global instrument to reach the exporter")
+	}
+}
+
+func TestInitMeterFollowsTracingEnabled(t *testing.T) {
+	t.Setenv("TRACING_ENABLED", "false")
+
+	orig := newMetricExporter
+	defer func() { newMetricExporter = orig }()
+	newMetricExporter = func(ctx context.Context, endpoint string) (sdkmetric.Exporter, error) {
+		t.Fatal("exporter should not be created when tracing is disabled")
+		return nil, nil
+	}
+
+	mp, err := InitMeter(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mp == nil {
+		t.Fatal("expected meter provider")
+	}
+}
+
+type stubMetricExporter struct {
+	endpoint string
+	metrics  []metricdata.ResourceMetrics
+}
+
+func (s *stubMetricExporter) Temporality(k sdkmetric.InstrumentKind) metricdata.Temporality {
+	return sdkmetric.DefaultTemporalitySelector(k)
+}
+
+func (s *stubMetricExporter) Aggregation(k sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	return sdkmetric.DefaultAggregationSelector(k)
+}
+
+func (s *stubMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	s.metrics = append(s.metrics, *rm)
+	return nil
+}
+
+func (s *stubMetricExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (s *stubMetricExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (s *stubMetricExporter) exported(name string) bool {
+	for _, rm := range s.metrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if m.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
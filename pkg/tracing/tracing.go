@@ -37,12 +37,7 @@ func InitTracer(ctx context.Context) (*sdktrace.TracerProvider, trace.Tracer, er
 		return nil, nil, err
 	}
 
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName("bug-free-umbrella"),
-			semconv.ServiceVersion("1.0.0"),
-		),
-	)
+	res, err := newResource(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -62,3 +57,12 @@ func InitTracer(ctx context.Context) (*sdktrace.TracerProvider, trace.Tracer, er
 
 	return tp, tracer, nil
 }
+
+func newResource(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName("bug-free-umbrella"),
+			semconv.ServiceVersion("1.0.0"),
+		),
+	)
+}
@@ -28,6 +28,7 @@ const INDICATOR_TLDR: Record<string, string> = {
   macd: 'Trend and momentum crossover. Positive separation leans bullish; negative separation leans bearish.',
   bollinger: 'Volatility bands around price. Moves near outer bands can signal stretch and possible mean reversion.',
   volume_zscore: 'Volume anomaly detector. Unusual volume spikes can validate or warn against weak moves.',
+  ichimoku: 'Ichimoku cloud. Tenkan/Kijun crosses and closes breaking out of the cloud mark trend shifts.',
   ml_logreg_up4h: 'ML logistic model probability of upside over ~4h using engineered features.',
   ml_xgboost_up4h: 'ML boosted-tree probability of upside over ~4h with nonlinear feature interactions.',
   ml_ensemble_up4h: 'Ensemble of ML models; generally more stable than single-model signals.',
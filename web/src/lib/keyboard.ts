@@ -10,6 +10,7 @@ export const SIGNAL_INDICATOR_OPTIONS = [
   'macd',
   'bollinger',
   'volume_zscore',
+  'ichimoku',
   'ml_logreg_up4h',
   'ml_xgboost_up4h',
   'ml_ensemble_up4h',